@@ -1,14 +1,22 @@
 package main
 
 import (
+	"errors"
 	"fmt"
 	"os"
 
 	"github.com/isaacgarza/dev-stack/internal/cli"
+	"github.com/isaacgarza/dev-stack/internal/pkg/types"
 )
 
 func main() {
 	if err := cli.ExecuteFactory(); err != nil {
+		// The failure was already reported, by the command run in a
+		// container or in the --error-format
+		var exitErr *types.ExitError
+		if errors.As(err, &exitErr) {
+			os.Exit(exitErr.Code)
+		}
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
 	}
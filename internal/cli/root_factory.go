@@ -1,16 +1,24 @@
 package cli
 
 import (
+	"context"
 	"fmt"
 	"os"
+	"os/signal"
+	"syscall"
 
+	"github.com/isaacgarza/dev-stack/internal/core/docker"
 	"github.com/isaacgarza/dev-stack/internal/pkg/cli"
 	"github.com/isaacgarza/dev-stack/internal/pkg/config"
+	"github.com/isaacgarza/dev-stack/internal/pkg/constants"
+	"github.com/isaacgarza/dev-stack/internal/pkg/types"
+	"github.com/isaacgarza/dev-stack/internal/pkg/version"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
 
-// CreateRootCommand creates the root command using the functional builder
+// CreateRootCommand creates the root command using the functional builder,
+// for running the arguments of the process
 func CreateRootCommand() (*cobra.Command, error) {
 	loader := config.NewLoader("")
 	commandConfig, err := loader.Load()
@@ -18,7 +26,11 @@ func CreateRootCommand() (*cobra.Command, error) {
 		return nil, fmt.Errorf("failed to load command configuration: %w", err)
 	}
 
-	validationResult := commandConfig.Validate()
+	// Only valid configurations are cached
+	validationResult := &config.ValidationResult{Valid: true}
+	if !loader.FromCache() {
+		validationResult = commandConfig.Validate()
+	}
 	if !validationResult.Valid {
 		fmt.Fprintf(os.Stderr, "Warning: Command configuration has validation errors:\n")
 		for _, err := range validationResult.Errors {
@@ -32,7 +44,8 @@ func CreateRootCommand() (*cobra.Command, error) {
 		}
 	}
 
-	rootCmd, err := cli.BuildRootCommand(commandConfig)
+	// Only the command being run is fully built
+	rootCmd, err := cli.BuildRootCommandForArgs(commandConfig, os.Args[1:])
 	if err != nil {
 		return nil, fmt.Errorf("failed to build root command: %w", err)
 	}
@@ -44,14 +57,51 @@ func CreateRootCommand() (*cobra.Command, error) {
 	return rootCmd, nil
 }
 
-// ExecuteFactory executes the root command using the functional builder
+// ExecuteFactory executes the root command using the functional builder.
+// Commands run with a context cancelled by the first interrupt or SIGTERM,
+// so they stop what they are doing and clean up; a second one exits at once.
+// A failed command is reported in the --error-format and returned as a
+// types.ExitError carrying the exit code of its kind.
 func ExecuteFactory() error {
+	if err := delegateToProjectVersion(); err != nil {
+		return err
+	}
+
 	rootCmd, err := CreateRootCommand()
 	if err != nil {
 		return fmt.Errorf("failed to create CLI: %w", err)
 	}
+	// The service manager and the handlers share one Docker connection
+	defer func() { _ = docker.CloseShared() }()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
+
+	if err := rootCmd.ExecuteContext(ctx); err != nil {
+		return &types.ExitError{Command: constants.AppName, Code: cli.ReportError(rootCmd, err)}
+	}
+	return nil
+}
 
-	return rootCmd.Execute()
+// delegateToProjectVersion re-executes the command with the installed
+// version of dev-stack the project pins in .dev-stack-version or its
+// configuration, when this binary doesn't satisfy it. It returns nil when
+// this binary runs the command.
+func delegateToProjectVersion() error {
+	installDir, err := version.GetDefaultInstallDir()
+	if err != nil {
+		return nil
+	}
+	configDir, err := version.GetDefaultConfigDir()
+	if err != nil {
+		return nil
+	}
+	switcher := version.NewVersionSwitcher(version.NewDefaultVersionManager(installDir, configDir))
+	return switcher.DelegateToCorrectVersion(os.Args)
 }
 
 // initFactoryConfig reads in config file and ENV variables if set
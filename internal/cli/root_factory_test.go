@@ -1,12 +1,22 @@
 package cli
 
 import (
+	"bytes"
+	"fmt"
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
+	"github.com/spf13/cobra"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	"github.com/isaacgarza/dev-stack/internal/core/docker"
+	"github.com/isaacgarza/dev-stack/internal/pkg/cli"
+	"github.com/isaacgarza/dev-stack/internal/pkg/config"
+	"github.com/isaacgarza/dev-stack/internal/pkg/constants"
+	"github.com/isaacgarza/dev-stack/internal/pkg/types"
 )
 
 func TestCreateRootCommand(t *testing.T) {
@@ -173,3 +183,88 @@ help: {}
 		})
 	})
 }
+
+func TestBuildRootCommandForArgs(t *testing.T) {
+	commandConfig, err := config.LoadDefault()
+	require.NoError(t, err)
+
+	built := func(rootCmd *cobra.Command, name string) bool {
+		cmd, _, err := rootCmd.Find([]string{name})
+		require.NoError(t, err)
+		return cmd.Runnable()
+	}
+
+	// Only the command being run is built, found past the global flags
+	rootCmd, err := cli.BuildRootCommandForArgs(commandConfig, []string{"--timeout", "5m", "-q", "ps", "--watch"})
+	require.NoError(t, err)
+	assert.True(t, built(rootCmd, "status"))
+	assert.False(t, built(rootCmd, "up"))
+
+	// The help of the root lists every command
+	for _, args := range [][]string{{}, {"--help"}, {"help", "up"}, {"bogus"}, {"completion", "zsh"}} {
+		rootCmd, err := cli.BuildRootCommandForArgs(commandConfig, args)
+		require.NoError(t, err)
+		assert.True(t, built(rootCmd, "status"), "%v", args)
+		assert.True(t, built(rootCmd, "up"), "%v", args)
+	}
+}
+
+func TestGlobalTimeout(t *testing.T) {
+	t.Chdir(t.TempDir())
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_CONFIG_HOME", "")
+	t.Cleanup(func() { docker.SetTimeouts(types.TimeoutConfig{}) })
+	commandConfig, err := config.LoadDefault()
+	require.NoError(t, err)
+
+	// The global --timeout reaches the Docker operations of the lifecycle
+	// commands, next to their own wait and grace period
+	for _, args := range [][]string{
+		{"up", "--timeout", "90s", "--wait-timeout", "2m", "postgres"},
+		{"down", "--timeout", "90s", "--stop-timeout", "5"},
+		{"--timeout", "90s", "restart", "postgres"},
+	} {
+		rootCmd, err := cli.BuildRootCommandForArgs(commandConfig, args)
+		require.NoError(t, err)
+		cmd, rest, err := rootCmd.Find(args)
+		require.NoError(t, err)
+		require.NoError(t, cmd.ParseFlags(rest), "%v", args)
+
+		docker.SetTimeouts(types.TimeoutConfig{})
+		require.NoError(t, rootCmd.PersistentPreRunE(cmd, cmd.Flags().Args()), "%v", args)
+		timeout := 90 * time.Second
+		assert.Equal(t, types.TimeoutConfig{Start: timeout, Stop: timeout, Exec: timeout, Pull: timeout}, docker.Timeouts(), "%v", args)
+	}
+
+	// No command has a --timeout of its own hiding the global one
+	rootCmd, err := cli.BuildRootCommandForArgs(commandConfig, nil)
+	require.NoError(t, err)
+	var walk func(cmd *cobra.Command)
+	walk = func(cmd *cobra.Command) {
+		assert.Nil(t, cmd.LocalNonPersistentFlags().Lookup(constants.FlagTimeout), cmd.CommandPath())
+		for _, child := range cmd.Commands() {
+			walk(child)
+		}
+	}
+	walk(rootCmd)
+}
+
+func TestReportError(t *testing.T) {
+	rootCmd, err := CreateRootCommand()
+	require.NoError(t, err)
+	var stderr bytes.Buffer
+	rootCmd.SetErr(&stderr)
+
+	conflict := fmt.Errorf("failed to start services: %w", &types.PortConflictError{Port: "5432"})
+	assert.Equal(t, constants.ExitPortConflict, cli.ReportError(rootCmd, conflict))
+	assert.Equal(t, "Error: failed to start services: port 5432 is already in use\n", stderr.String())
+
+	stderr.Reset()
+	require.NoError(t, rootCmd.PersistentFlags().Set(constants.FlagErrorFormat, "json"))
+	assert.Equal(t, constants.ExitPortConflict, cli.ReportError(rootCmd, conflict))
+	assert.JSONEq(t, `{"error":"failed to start services: port 5432 is already in use","kind":"port_conflict","exit_code":11,"details":{"port":"5432"}}`, stderr.String())
+
+	stderr.Reset()
+	assert.Equal(t, 3, cli.ReportError(rootCmd, &types.ExitError{Command: "psql", Code: 3}))
+	assert.Empty(t, stderr.String(), "exit errors were already reported")
+}
@@ -18,3 +18,9 @@ var EmbeddedEnvTemplate []byte
 
 //go:embed services
 var EmbeddedServicesFS embed.FS
+
+// EmbeddedDashboardsFS holds the starter Grafana dashboards, one per
+// service, named <service>.json
+//
+//go:embed dashboards
+var EmbeddedDashboardsFS embed.FS
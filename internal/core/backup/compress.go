@@ -0,0 +1,99 @@
+package backup
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os/exec"
+
+	"github.com/isaacgarza/dev-stack/internal/pkg/types"
+)
+
+// Compressions returns the supported compression algorithms
+func Compressions() []string {
+	return []string{types.CompressionGzip, types.CompressionZstd}
+}
+
+// ValidateCompression checks that an algorithm is supported; an empty
+// algorithm disables compression
+func ValidateCompression(compression string) error {
+	switch compression {
+	case types.CompressionNone, types.CompressionGzip, types.CompressionZstd:
+		return nil
+	default:
+		return fmt.Errorf("unsupported compression %q (supported: gzip, zstd)", compression)
+	}
+}
+
+// Extension returns the file suffix added for a compression algorithm
+func Extension(compression string) string {
+	switch compression {
+	case types.CompressionGzip:
+		return ".gz"
+	case types.CompressionZstd:
+		return ".zst"
+	default:
+		return ""
+	}
+}
+
+// NewCompressor wraps w so that everything written is compressed with the
+// given algorithm. Closing the compressor flushes it but leaves w open. An
+// empty algorithm writes through unchanged.
+func NewCompressor(w io.Writer, compression string) (io.WriteCloser, error) {
+	switch compression {
+	case types.CompressionNone:
+		return nopWriteCloser{w}, nil
+	case types.CompressionGzip:
+		return gzip.NewWriter(w), nil
+	case types.CompressionZstd:
+		return newZstdWriter(w)
+	default:
+		return nil, ValidateCompression(compression)
+	}
+}
+
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error { return nil }
+
+// zstdWriter pipes data through the zstd binary; there is no zstd encoder in
+// the standard library
+type zstdWriter struct {
+	cmd   *exec.Cmd
+	stdin io.WriteCloser
+}
+
+func newZstdWriter(w io.Writer) (*zstdWriter, error) {
+	path, err := exec.LookPath("zstd")
+	if err != nil {
+		return nil, fmt.Errorf("zstd compression requires the zstd binary on PATH: %w", err)
+	}
+
+	cmd := exec.Command(path, "-q", "-c", "-")
+	cmd.Stdout = w
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open zstd input: %w", err)
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("failed to start zstd: %w", err)
+	}
+	return &zstdWriter{cmd: cmd, stdin: stdin}, nil
+}
+
+func (z *zstdWriter) Write(p []byte) (int, error) {
+	return z.stdin.Write(p)
+}
+
+func (z *zstdWriter) Close() error {
+	if err := z.stdin.Close(); err != nil {
+		return err
+	}
+	if err := z.cmd.Wait(); err != nil {
+		return fmt.Errorf("zstd failed: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,556 @@
+package backup
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/isaacgarza/dev-stack/internal/pkg/types"
+)
+
+// Backup file formats
+const (
+	FormatSQL    = "sql"
+	FormatCustom = "custom"
+	FormatRDB    = "rdb"
+	FormatBSON   = "bson"
+	FormatJSON   = "json"
+	FormatTar    = "tar"
+)
+
+// Database engines a backup can belong to
+const (
+	EnginePostgres      = "postgres"
+	EngineMySQL         = "mysql"
+	EngineRedis         = "redis"
+	EngineMongoDB       = "mongodb"
+	EngineRabbitMQ      = "rabbitmq"
+	EngineElasticsearch = "elasticsearch"
+	EngineMinIO         = "minio"
+	EngineClickHouse    = "clickhouse"
+	EngineNATS          = "nats"
+)
+
+// peekSize is how much of the decompressed backup is read to detect its format
+const peekSize = 64 * 1024
+
+// minimumEstimate is the shortest restore duration ever reported
+const minimumEstimate = time.Second
+
+var (
+	magicGzip         = []byte{0x1f, 0x8b}
+	magicZstd         = []byte{0x28, 0xb5, 0x2f, 0xfd}
+	magicMongoArchive = []byte{0x6d, 0xe2, 0x99, 0x81}
+
+	postgresSQLVersion = regexp.MustCompile(`(?m)^-- Dumped from database version (\d+(?:\.\d+)?)`)
+	mysqlSQLVersion    = regexp.MustCompile(`(?m)^-- Server version\s+(\d+\.\d+(?:\.\d+)?)`)
+	customVersion      = regexp.MustCompile(`^\d{1,2}\.\d+`)
+	sqlStatement       = regexp.MustCompile(`(?im)^\s*(create|insert|alter|drop|set|begin|copy|select|use)\b`)
+	rabbitDefinitions  = regexp.MustCompile(`"(?:rabbit_version|rabbitmq_version|exchanges|bindings|vhosts)"\s*:`)
+	rabbitVersion      = regexp.MustCompile(`"rabbit(?:mq)?_version"\s*:\s*"([^"]+)"`)
+
+	// restoreThroughput is the rough uncompressed bytes per second restored
+	// on a laptop for each format
+	restoreThroughput = map[string]float64{
+		FormatSQL:    15 << 20,
+		FormatCustom: 30 << 20,
+		FormatRDB:    150 << 20,
+		FormatBSON:   20 << 20,
+		FormatJSON:   5 << 20,
+		FormatTar:    100 << 20,
+	}
+
+	// serviceEngines maps services to the engine their backups come from
+	serviceEngines = map[string]string{
+		"postgres":      EnginePostgres,
+		"mysql":         EngineMySQL,
+		"redis":         EngineRedis,
+		"mongodb":       EngineMongoDB,
+		"rabbitmq":      EngineRabbitMQ,
+		"elasticsearch": EngineElasticsearch,
+		"minio":         EngineMinIO,
+		"clickhouse":    EngineClickHouse,
+		"nats":          EngineNATS,
+	}
+
+	// archiveEngines maps the top-level directory of a tar backup to the
+	// engine whose data directory it is
+	archiveEngines = map[string]string{
+		"snapshots":  EngineElasticsearch,
+		"data":       EngineMinIO,
+		"clickhouse": EngineClickHouse,
+		"jetstream":  EngineNATS,
+	}
+)
+
+// Inspection describes a backup file
+type Inspection struct {
+	Path        string        `json:"path"`
+	Format      string        `json:"format"`
+	Compression string        `json:"compression,omitempty"`
+	Engine      string        `json:"engine,omitempty"`
+	Version     string        `json:"version,omitempty"`
+	Size        int64         `json:"size"`
+	RawSize     int64         `json:"raw_size"`
+	Estimate    time.Duration `json:"estimate"`
+}
+
+// Inspect detects the compression, format, source engine and version of a
+// backup file and estimates how long restoring it will take
+func Inspect(path string) (*Inspection, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if info.IsDir() {
+		return nil, fmt.Errorf("%s is a directory", path)
+	}
+
+	inspection := &Inspection{Path: path, Size: info.Size()}
+	if inspection.Compression, err = detectCompression(path); err != nil {
+		return nil, err
+	}
+
+	reader, err := Open(path)
+	if err != nil {
+		return nil, err
+	}
+	head := make([]byte, peekSize)
+	n, err := io.ReadFull(reader, head)
+	_ = reader.Close()
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	head = head[:n]
+
+	inspection.Format, inspection.Engine, inspection.Version = detectFormat(head)
+	if inspection.Format == "" {
+		return nil, fmt.Errorf("unrecognised backup format in %s", path)
+	}
+
+	inspection.RawSize = rawSize(path, inspection.Compression, inspection.Size)
+	inspection.Estimate = estimate(inspection.Format, inspection.RawSize)
+	return inspection, nil
+}
+
+// Check verifies the backup can be restored into a service running
+// serviceVersion. Errors make the restore fail; warnings only inform.
+func (i *Inspection) Check(serviceName, serviceVersion string) (errs []string, warnings []string) {
+	engine, ok := serviceEngines[serviceName]
+	if !ok {
+		return []string{fmt.Sprintf("%s does not support restores", serviceName)}, nil
+	}
+
+	if i.Engine == "" {
+		if i.Format == FormatSQL && (engine == EnginePostgres || engine == EngineMySQL) {
+			warnings = append(warnings, fmt.Sprintf("could not tell which database produced this SQL dump; assuming %s", engine))
+			return errs, warnings
+		}
+		return []string{fmt.Sprintf("%s backups cannot be restored into %s", i.Format, serviceName)}, nil
+	}
+	if i.Engine != engine {
+		return []string{fmt.Sprintf("backup was taken from %s and cannot be restored into %s", i.Engine, serviceName)}, nil
+	}
+
+	if engine == EnginePostgres && i.Format == FormatCustom {
+		errs = append(errs, "custom-format archives need pg_restore; re-create the backup as plain SQL to restore it with dev-stack")
+	}
+
+	if i.Version == "" || serviceVersion == "" {
+		warnings = append(warnings, "could not determine versions; skipping version check")
+		return errs, warnings
+	}
+
+	switch engine {
+	case EnginePostgres:
+		if compareVersions(majorVersion(i.Version), majorVersion(serviceVersion)) > 0 {
+			message := fmt.Sprintf("backup from PostgreSQL %s is newer than the service (%s)", i.Version, serviceVersion)
+			if i.Format == FormatCustom {
+				errs = append(errs, message+"; pg_restore cannot read newer archives")
+			} else {
+				warnings = append(warnings, message+"; newer syntax may fail to load")
+			}
+		}
+	case EngineMySQL:
+		if compareVersions(i.Version, serviceVersion) > 0 {
+			warnings = append(warnings, fmt.Sprintf("backup from MySQL %s is newer than the service (%s)", i.Version, serviceVersion))
+		}
+	case EngineRabbitMQ:
+		if compareVersions(i.Version, serviceVersion) > 0 {
+			warnings = append(warnings, fmt.Sprintf("definitions exported from RabbitMQ %s are newer than the service (%s)", i.Version, serviceVersion))
+		}
+	case EngineRedis:
+		rdb, _ := strconv.Atoi(i.Version)
+		if supported := maxRDBVersion(serviceVersion); supported > 0 && rdb > supported {
+			errs = append(errs, fmt.Sprintf("RDB version %d needs a newer Redis than %s (supports up to RDB %d)", rdb, serviceVersion, supported))
+		}
+	}
+	return errs, warnings
+}
+
+// Open opens a backup file for reading, transparently decompressing it
+func Open(path string) (io.ReadCloser, error) {
+	compression, err := detectCompression(path)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	switch compression {
+	case types.CompressionGzip:
+		reader, err := gzip.NewReader(bufio.NewReader(file))
+		if err != nil {
+			_ = file.Close()
+			return nil, fmt.Errorf("failed to read gzip backup: %w", err)
+		}
+		return &stackedReadCloser{Reader: reader, closers: []io.Closer{reader, file}}, nil
+	case types.CompressionZstd:
+		return newZstdReader(file)
+	default:
+		return file, nil
+	}
+}
+
+// detectCompression identifies gzip and zstd files by their magic bytes
+func detectCompression(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = file.Close() }()
+
+	magic := make([]byte, 4)
+	n, _ := io.ReadFull(file, magic)
+	magic = magic[:n]
+
+	switch {
+	case bytes.HasPrefix(magic, magicGzip):
+		return types.CompressionGzip, nil
+	case bytes.HasPrefix(magic, magicZstd):
+		return types.CompressionZstd, nil
+	default:
+		return types.CompressionNone, nil
+	}
+}
+
+// detectFormat identifies the backup format, the engine that produced it
+// and that engine's version from the start of the decompressed file
+func detectFormat(head []byte) (format, engine, version string) {
+	switch {
+	case bytes.HasPrefix(head, []byte("REDIS")) && len(head) >= 9:
+		rdb := strings.TrimLeft(string(head[5:9]), "0")
+		return FormatRDB, EngineRedis, rdb
+	case bytes.HasPrefix(head, []byte("PGDMP")):
+		return FormatCustom, EnginePostgres, customArchiveVersion(head)
+	case bytes.HasPrefix(head, magicMongoArchive), isBSON(head):
+		return FormatBSON, EngineMongoDB, ""
+	case isTar(head):
+		return FormatTar, archiveEngine(head), ""
+	}
+
+	text := string(head)
+	if match := postgresSQLVersion.FindStringSubmatch(text); match != nil {
+		return FormatSQL, EnginePostgres, match[1]
+	}
+	if strings.Contains(text, "-- PostgreSQL database dump") {
+		return FormatSQL, EnginePostgres, ""
+	}
+	if strings.Contains(text, "-- MySQL dump") || strings.Contains(text, "-- MariaDB dump") {
+		if match := mysqlSQLVersion.FindStringSubmatch(text); match != nil {
+			return FormatSQL, EngineMySQL, match[1]
+		}
+		return FormatSQL, EngineMySQL, ""
+	}
+	if bytes.HasPrefix(bytes.TrimSpace(head), []byte("{")) {
+		if !rabbitDefinitions.Match(head) {
+			return FormatJSON, "", ""
+		}
+		if match := rabbitVersion.FindStringSubmatch(text); match != nil {
+			return FormatJSON, EngineRabbitMQ, match[1]
+		}
+		return FormatJSON, EngineRabbitMQ, ""
+	}
+	if isText(head) && sqlStatement.Match(head) {
+		return FormatSQL, "", ""
+	}
+	return "", "", ""
+}
+
+// customArchiveVersion finds the server version string in the header of a
+// pg_dump custom archive; it is the first printable run that looks like one
+func customArchiveVersion(head []byte) string {
+	header := head[:min(len(head), 512)]
+	for _, run := range bytes.FieldsFunc(header, func(r rune) bool { return r < 0x20 || r > 0x7e }) {
+		if version := customVersion.Find(run); version != nil {
+			return string(version)
+		}
+	}
+	return ""
+}
+
+// isBSON reports whether head starts with a plausible BSON document
+func isBSON(head []byte) bool {
+	if len(head) < 5 {
+		return false
+	}
+	length := binary.LittleEndian.Uint32(head)
+	if length < 5 || length > 16<<20 {
+		return false
+	}
+	elementType := head[4]
+	if !(elementType >= 0x01 && elementType <= 0x13) && elementType != 0x7f && elementType != 0xff {
+		return false
+	}
+	return int(length) > len(head) || head[length-1] == 0x00
+}
+
+// isTar reports whether head starts with a POSIX or GNU tar header
+func isTar(head []byte) bool {
+	return len(head) >= 512 && bytes.HasPrefix(head[257:], []byte("ustar"))
+}
+
+// archiveEngine identifies the engine of a tar backup from the top-level
+// directory of its first entry
+func archiveEngine(head []byte) string {
+	header, err := tar.NewReader(bytes.NewReader(head)).Next()
+	if err != nil {
+		return ""
+	}
+	root, _, _ := strings.Cut(strings.TrimPrefix(header.Name, "./"), "/")
+	return archiveEngines[root]
+}
+
+// isText reports whether head looks like text rather than binary data
+func isText(head []byte) bool {
+	return len(head) > 0 && !bytes.ContainsRune(head, 0)
+}
+
+// rawSize returns the uncompressed size of a backup. Gzip and zstd record it
+// in the file; when they do not, a typical compression ratio is assumed.
+func rawSize(path, compression string, size int64) int64 {
+	const assumedRatio = 4
+
+	switch compression {
+	case types.CompressionGzip:
+		if raw := gzipSize(path, size); raw > 0 {
+			return raw
+		}
+	case types.CompressionZstd:
+		if raw := zstdContentSize(path); raw > 0 {
+			return raw
+		}
+	default:
+		return size
+	}
+	return size * assumedRatio
+}
+
+// gzipSize reads the ISIZE trailer, which holds the uncompressed size modulo
+// 2^32. It is only trusted when it is at least the compressed size.
+func gzipSize(path string, size int64) int64 {
+	if size < 18 {
+		return 0
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return 0
+	}
+	defer func() { _ = file.Close() }()
+
+	trailer := make([]byte, 4)
+	if _, err := file.ReadAt(trailer, size-4); err != nil {
+		return 0
+	}
+	raw := int64(binary.LittleEndian.Uint32(trailer))
+	if raw < size {
+		return 0
+	}
+	return raw
+}
+
+// zstdContentSize reads the frame content size from a zstd frame header
+func zstdContentSize(path string) int64 {
+	file, err := os.Open(path)
+	if err != nil {
+		return 0
+	}
+	defer func() { _ = file.Close() }()
+
+	header := make([]byte, 18)
+	n, _ := io.ReadFull(file, header)
+	if n < 6 {
+		return 0
+	}
+
+	descriptor := header[4]
+	singleSegment := descriptor&0x20 != 0
+	offset := 5
+	if !singleSegment {
+		offset++ // window descriptor
+	}
+	offset += []int{0, 1, 2, 4}[descriptor&0x03] // dictionary id
+
+	switch descriptor >> 6 {
+	case 0:
+		if singleSegment && offset < n {
+			return int64(header[offset])
+		}
+	case 1:
+		if offset+2 <= n {
+			return int64(binary.LittleEndian.Uint16(header[offset:])) + 256
+		}
+	case 2:
+		if offset+4 <= n {
+			return int64(binary.LittleEndian.Uint32(header[offset:]))
+		}
+	case 3:
+		if offset+8 <= n {
+			return int64(binary.LittleEndian.Uint64(header[offset:]))
+		}
+	}
+	return 0
+}
+
+// estimate returns the expected restore duration for a backup
+func estimate(format string, rawSize int64) time.Duration {
+	throughput, ok := restoreThroughput[format]
+	if !ok {
+		throughput = restoreThroughput[FormatSQL]
+	}
+	duration := time.Duration(float64(rawSize) / throughput * float64(time.Second))
+	return max(duration, minimumEstimate).Round(time.Second)
+}
+
+// maxRDBVersion returns the newest RDB format a Redis version can load. A
+// bare major version is taken to be that major's latest release.
+func maxRDBVersion(version string) int {
+	parts := strings.SplitN(version, ".", 3)
+	major, _ := strconv.Atoi(parts[0])
+	minor := -1
+	if len(parts) > 1 {
+		minor, _ = strconv.Atoi(parts[1])
+	}
+
+	switch {
+	case major >= 8:
+		return 12
+	case major == 7 && (minor < 0 || minor >= 4):
+		return 12
+	case major == 7 && minor >= 2:
+		return 11
+	case major == 7:
+		return 10
+	case major >= 5:
+		return 9
+	case major == 4:
+		return 8
+	case major > 0:
+		return 7
+	default:
+		return 0
+	}
+}
+
+// ImageVersion extracts the version from an image tag such as
+// postgres:15-alpine or mysql:8.0.36-oracle
+func ImageVersion(image string) string {
+	i := strings.LastIndex(image, ":")
+	if i < 0 || strings.Contains(image[i:], "/") {
+		return ""
+	}
+	tag := image[i+1:]
+
+	end := 0
+	for end < len(tag) && (tag[end] == '.' || (tag[end] >= '0' && tag[end] <= '9')) {
+		end++
+	}
+	return strings.Trim(tag[:end], ".")
+}
+
+// majorVersion returns the first component of a dotted version
+func majorVersion(version string) string {
+	major, _, _ := strings.Cut(version, ".")
+	return major
+}
+
+// compareVersions compares dotted numeric versions, treating missing
+// components as equal so "8" matches "8.0.36"
+func compareVersions(a, b string) int {
+	as, bs := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < min(len(as), len(bs)); i++ {
+		x, _ := strconv.Atoi(as[i])
+		y, _ := strconv.Atoi(bs[i])
+		if x != y {
+			if x < y {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
+
+// stackedReadCloser closes several layers of a reader in order
+type stackedReadCloser struct {
+	io.Reader
+	closers []io.Closer
+}
+
+func (s *stackedReadCloser) Close() error {
+	var first error
+	for _, closer := range s.closers {
+		if err := closer.Close(); err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
+
+// zstdReader decompresses through the zstd binary
+type zstdReader struct {
+	io.Reader
+	cmd  *exec.Cmd
+	file *os.File
+}
+
+func newZstdReader(file *os.File) (*zstdReader, error) {
+	path, err := exec.LookPath("zstd")
+	if err != nil {
+		_ = file.Close()
+		return nil, fmt.Errorf("reading zstd backups requires the zstd binary on PATH: %w", err)
+	}
+
+	cmd := exec.Command(path, "-q", "-d", "-c")
+	cmd.Stdin = file
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		_ = file.Close()
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		_ = file.Close()
+		return nil, fmt.Errorf("failed to start zstd: %w", err)
+	}
+	return &zstdReader{Reader: stdout, cmd: cmd, file: file}, nil
+}
+
+func (z *zstdReader) Close() error {
+	// Readers may stop early, so the process is killed rather than drained
+	_ = z.cmd.Process.Kill()
+	_ = z.cmd.Wait()
+	return z.file.Close()
+}
@@ -0,0 +1,251 @@
+package backup
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/isaacgarza/dev-stack/internal/pkg/types"
+)
+
+func TestDetectFormat(t *testing.T) {
+	tests := []struct {
+		name    string
+		head    []byte
+		format  string
+		engine  string
+		version string
+	}{
+		{
+			name:    "pg_dump plain",
+			head:    []byte("--\n-- PostgreSQL database dump\n--\n\n-- Dumped from database version 15.4\n"),
+			format:  FormatSQL,
+			engine:  EnginePostgres,
+			version: "15.4",
+		},
+		{
+			name:    "mysqldump",
+			head:    []byte("-- MySQL dump 10.13  Distrib 8.0.35, for Linux (x86_64)\n--\n-- Server version\t8.0.35\n"),
+			format:  FormatSQL,
+			engine:  EngineMySQL,
+			version: "8.0.35",
+		},
+		{
+			name:   "generic sql",
+			head:   []byte("CREATE TABLE users (id int);\nINSERT INTO users VALUES (1);\n"),
+			format: FormatSQL,
+		},
+		{
+			name:    "redis rdb",
+			head:    []byte("REDIS0011\xfa\tredis-ver\x057.2.4"),
+			format:  FormatRDB,
+			engine:  EngineRedis,
+			version: "11",
+		},
+		{
+			name:   "pg_dump custom",
+			head:   []byte("PGDMP\x01\x0e\x00\x04\x08\x01\x01"),
+			format: FormatCustom,
+			engine: EnginePostgres,
+		},
+		{
+			name:    "rabbitmq definitions",
+			head:    []byte(`{"bindings":[],"exchanges":[],"queues":[{"name":"jobs"}],"rabbit_version":"3.13.7"}`),
+			format:  FormatJSON,
+			engine:  EngineRabbitMQ,
+			version: "3.13.7",
+		},
+		{
+			name:   "other json",
+			head:   []byte(`{"users":[{"id":1}]}`),
+			format: FormatJSON,
+		},
+		{
+			name:   "minio data archive",
+			head:   tarHead(t, "data/.minio.sys/format.json"),
+			format: FormatTar,
+			engine: EngineMinIO,
+		},
+		{
+			name:   "jetstream archive",
+			head:   tarHead(t, "jetstream/$G/streams/ORDERS/meta.inf"),
+			format: FormatTar,
+			engine: EngineNATS,
+		},
+		{
+			name:   "unknown archive",
+			head:   tarHead(t, "home/user/notes.txt"),
+			format: FormatTar,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			format, engine, version := detectFormat(tt.head)
+			assert.Equal(t, tt.format, format)
+			assert.Equal(t, tt.engine, engine)
+			if tt.version != "" {
+				assert.Equal(t, tt.version, version)
+			}
+		})
+	}
+}
+
+func TestInspect_Gzip(t *testing.T) {
+	dump := "--\n-- PostgreSQL database dump\n--\n-- Dumped from database version 16.1\n" + strings.Repeat("INSERT INTO t VALUES (1);\n", 1000)
+	path := filepath.Join(t.TempDir(), "app.sql.gz")
+
+	file, err := os.Create(path)
+	require.NoError(t, err)
+	writer := gzip.NewWriter(file)
+	_, err = writer.Write([]byte(dump))
+	require.NoError(t, err)
+	require.NoError(t, writer.Close())
+	require.NoError(t, file.Close())
+
+	inspection, err := Inspect(path)
+	require.NoError(t, err)
+	assert.Equal(t, FormatSQL, inspection.Format)
+	assert.Equal(t, types.CompressionGzip, inspection.Compression)
+	assert.Equal(t, EnginePostgres, inspection.Engine)
+	assert.Equal(t, "16.1", inspection.Version)
+	assert.Equal(t, int64(len(dump)), inspection.RawSize)
+	assert.GreaterOrEqual(t, inspection.Estimate, time.Second)
+
+	reader, err := Open(path)
+	require.NoError(t, err)
+	defer func() { _ = reader.Close() }()
+	head := make([]byte, 2)
+	_, err = reader.Read(head)
+	require.NoError(t, err)
+	assert.Equal(t, "--", string(head))
+}
+
+func TestInspection_Check(t *testing.T) {
+	tests := []struct {
+		name       string
+		inspection Inspection
+		service    string
+		version    string
+		errors     int
+		warnings   int
+	}{
+		{
+			name:       "matching postgres",
+			inspection: Inspection{Format: FormatSQL, Engine: EnginePostgres, Version: "15.4"},
+			service:    "postgres",
+			version:    "15",
+		},
+		{
+			name:       "newer postgres plain dump warns",
+			inspection: Inspection{Format: FormatSQL, Engine: EnginePostgres, Version: "16.1"},
+			service:    "postgres",
+			version:    "15",
+			warnings:   1,
+		},
+		{
+			name:       "custom archive needs pg_restore",
+			inspection: Inspection{Format: FormatCustom, Engine: EnginePostgres, Version: "15.4"},
+			service:    "postgres",
+			version:    "15",
+			errors:     1,
+		},
+		{
+			name:       "wrong engine",
+			inspection: Inspection{Format: FormatRDB, Engine: EngineRedis, Version: "11"},
+			service:    "postgres",
+			version:    "15",
+			errors:     1,
+		},
+		{
+			name:       "unidentified sql dump",
+			inspection: Inspection{Format: FormatSQL},
+			service:    "mysql",
+			version:    "8",
+			warnings:   1,
+		},
+		{
+			name:       "rdb too new for redis",
+			inspection: Inspection{Format: FormatRDB, Engine: EngineRedis, Version: "11"},
+			service:    "redis",
+			version:    "7.0",
+			errors:     1,
+		},
+		{
+			name:       "rabbitmq definitions from a newer broker",
+			inspection: Inspection{Format: FormatJSON, Engine: EngineRabbitMQ, Version: "4.0.2"},
+			service:    "rabbitmq",
+			version:    "3.13",
+			warnings:   1,
+		},
+		{
+			name:       "clickhouse archive",
+			inspection: Inspection{Format: FormatTar, Engine: EngineClickHouse},
+			service:    "clickhouse",
+			version:    "24.8",
+			warnings:   1,
+		},
+		{
+			name:       "archive from another service",
+			inspection: Inspection{Format: FormatTar, Engine: EngineMinIO},
+			service:    "nats",
+			version:    "2.10",
+			errors:     1,
+		},
+		{
+			name:       "unidentified archive",
+			inspection: Inspection{Format: FormatTar},
+			service:    "minio",
+			errors:     1,
+		},
+		{
+			name:       "service without restores",
+			inspection: Inspection{Format: FormatSQL},
+			service:    "kafka",
+			errors:     1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs, warnings := tt.inspection.Check(tt.service, tt.version)
+			assert.Len(t, errs, tt.errors, "errors: %v", errs)
+			assert.Len(t, warnings, tt.warnings, "warnings: %v", warnings)
+		})
+	}
+}
+
+func TestImageVersion(t *testing.T) {
+	tests := map[string]string{
+		"postgres:15-alpine":           "15",
+		"mysql:8.0.35":                 "8.0.35",
+		"redis:7.2-alpine":             "7.2",
+		"redis":                        "",
+		"redis:latest":                 "",
+		"localhost:5000/postgres":      "",
+		"localhost:5000/postgres:16.1": "16.1",
+	}
+	for image, expected := range tests {
+		assert.Equal(t, expected, ImageVersion(image), image)
+	}
+}
+
+// tarHead returns the start of a tar archive holding a single file
+func tarHead(t *testing.T, name string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	writer := tar.NewWriter(&buf)
+	require.NoError(t, writer.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: 2}))
+	_, err := writer.Write([]byte("{}"))
+	require.NoError(t, err)
+	require.NoError(t, writer.Close())
+	return buf.Bytes()
+}
@@ -0,0 +1,106 @@
+package backup
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/isaacgarza/dev-stack/internal/pkg/types"
+)
+
+// Target is a remote location backups are uploaded to
+type Target interface {
+	// Upload copies the local backup file to the target under key and
+	// returns a location describing where it was stored
+	Upload(ctx context.Context, key, path string) (string, error)
+}
+
+// targetFactory builds a target from its configuration
+type targetFactory func(remote types.BackupRemote) (Target, error)
+
+// targetFactories maps remote types to their implementations
+var targetFactories = map[string]targetFactory{
+	types.RemoteS3:  newS3Target,
+	types.RemoteDir: newDirTarget,
+}
+
+// RemoteTypes returns the supported remote target types
+func RemoteTypes() []string {
+	names := make([]string, 0, len(targetFactories))
+	for name := range targetFactories {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// NewTarget creates the remote target described by remote
+func NewTarget(remote types.BackupRemote) (Target, error) {
+	factory, ok := targetFactories[remote.Type]
+	if !ok {
+		return nil, fmt.Errorf("unsupported remote type %q (supported: %s)", remote.Type, strings.Join(RemoteTypes(), ", "))
+	}
+	return factory(remote)
+}
+
+// dirTarget copies backups into a directory
+type dirTarget struct {
+	path string
+}
+
+func newDirTarget(remote types.BackupRemote) (Target, error) {
+	if remote.Path == "" {
+		return nil, fmt.Errorf("dir remote requires a path")
+	}
+	return &dirTarget{path: remote.Path}, nil
+}
+
+// Upload copies the file into the target directory
+func (d *dirTarget) Upload(ctx context.Context, key, path string) (string, error) {
+	destination := filepath.Join(d.path, filepath.FromSlash(key))
+	if err := os.MkdirAll(filepath.Dir(destination), 0755); err != nil {
+		return "", fmt.Errorf("failed to create %s: %w", filepath.Dir(destination), err)
+	}
+
+	source, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = source.Close() }()
+
+	target, err := os.Create(destination)
+	if err != nil {
+		return "", err
+	}
+	if _, err := copyWithContext(ctx, target, source); err != nil {
+		_ = target.Close()
+		_ = os.Remove(destination)
+		return "", fmt.Errorf("failed to copy backup to %s: %w", destination, err)
+	}
+	if err := target.Close(); err != nil {
+		return "", err
+	}
+	return destination, nil
+}
+
+// contextReader stops a copy as soon as the context is cancelled
+type contextReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+func (c contextReader) Read(p []byte) (int, error) {
+	if err := c.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return c.r.Read(p)
+}
+
+// copyWithContext is io.Copy that honours context cancellation
+func copyWithContext(ctx context.Context, w io.Writer, r io.Reader) (int64, error) {
+	return io.Copy(w, contextReader{ctx: ctx, r: r})
+}
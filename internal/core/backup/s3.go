@@ -0,0 +1,250 @@
+package backup
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/isaacgarza/dev-stack/internal/pkg/types"
+)
+
+const (
+	defaultS3Region  = "us-east-1"
+	unsignedPayload  = "UNSIGNED-PAYLOAD"
+	sigV4Algorithm   = "AWS4-HMAC-SHA256"
+	amzDateFormat    = "20060102T150405Z"
+	amzShortFormat   = "20060102"
+	s3ServiceName    = "s3"
+	s3UploadTimeout  = 30 * time.Minute
+	maxErrorBodySize = 4096
+)
+
+// s3Target uploads backups to S3 or an S3-compatible store such as MinIO
+// with a single signed PUT request
+type s3Target struct {
+	endpoint     *url.URL
+	region       string
+	bucket       string
+	prefix       string
+	accessKey    string
+	secretKey    string
+	sessionToken string
+	pathStyle    bool
+	client       *http.Client
+	now          func() time.Time
+}
+
+func newS3Target(remote types.BackupRemote) (Target, error) {
+	if remote.Bucket == "" {
+		return nil, fmt.Errorf("s3 remote requires a bucket")
+	}
+
+	region := remote.Region
+	if region == "" {
+		region = defaultS3Region
+	}
+
+	endpoint := remote.Endpoint
+	if endpoint == "" {
+		endpoint = fmt.Sprintf("https://s3.%s.amazonaws.com", region)
+	}
+	parsed, err := url.Parse(endpoint)
+	if err != nil || parsed.Scheme == "" || parsed.Host == "" {
+		return nil, fmt.Errorf("invalid s3 endpoint %q", endpoint)
+	}
+
+	target := &s3Target{
+		endpoint:     parsed,
+		region:       region,
+		bucket:       remote.Bucket,
+		prefix:       remote.Prefix,
+		accessKey:    firstNonEmpty(remote.AccessKey, os.Getenv("AWS_ACCESS_KEY_ID")),
+		secretKey:    firstNonEmpty(remote.SecretKey, os.Getenv("AWS_SECRET_ACCESS_KEY")),
+		sessionToken: firstNonEmpty(remote.SessionToken, os.Getenv("AWS_SESSION_TOKEN")),
+		pathStyle:    remote.PathStyle || remote.Endpoint != "",
+		client:       &http.Client{Timeout: s3UploadTimeout},
+		now:          time.Now,
+	}
+	if target.accessKey == "" || target.secretKey == "" {
+		return nil, fmt.Errorf("s3 remote requires access_key and secret_key (or AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY)")
+	}
+	return target, nil
+}
+
+// Upload streams the file to the bucket. The payload is sent unsigned so the
+// file never has to be read twice.
+func (s *s3Target) Upload(ctx context.Context, key, filePath string) (string, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = file.Close() }()
+
+	info, err := file.Stat()
+	if err != nil {
+		return "", err
+	}
+
+	objectURL := s.objectURL(path.Join(s.prefix, key))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, objectURL.String(), file)
+	if err != nil {
+		return "", err
+	}
+	req.ContentLength = info.Size()
+	req.Header.Set("Content-Type", "application/octet-stream")
+	s.sign(req, unsignedPayload)
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to upload to %s: %w", objectURL.Redacted(), err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode/100 != 2 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBodySize))
+		return "", fmt.Errorf("upload to %s failed: %s: %s", objectURL.Redacted(), resp.Status, strings.TrimSpace(string(body)))
+	}
+
+	return fmt.Sprintf("s3://%s/%s", s.bucket, path.Join(s.prefix, key)), nil
+}
+
+// objectURL builds the path-style or virtual-hosted URL of an object
+func (s *s3Target) objectURL(key string) *url.URL {
+	u := *s.endpoint
+	if s.pathStyle {
+		u.Path = path.Join("/", u.Path, s.bucket, key)
+	} else {
+		u.Host = s.bucket + "." + u.Host
+		u.Path = path.Join("/", u.Path, key)
+	}
+	return &u
+}
+
+// sign adds AWS Signature Version 4 headers to the request
+func (s *s3Target) sign(req *http.Request, payloadHash string) {
+	now := s.now().UTC()
+	amzDate := now.Format(amzDateFormat)
+	shortDate := now.Format(amzShortFormat)
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	if s.sessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", s.sessionToken)
+	}
+
+	headers := map[string]string{"host": req.URL.Host}
+	for name, values := range req.Header {
+		lower := strings.ToLower(name)
+		if lower == "host" || lower == "range" || strings.HasPrefix(lower, "x-amz-") {
+			headers[lower] = strings.TrimSpace(strings.Join(values, ","))
+		}
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		canonicalURI(req.URL),
+		canonicalQuery(req.URL),
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := strings.Join([]string{shortDate, s.region, s3ServiceName, "aws4_request"}, "/")
+	stringToSign := strings.Join([]string{sigV4Algorithm, amzDate, scope, hashHex(canonicalRequest)}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+s.secretKey), shortDate)
+	key = hmacSHA256(key, s.region)
+	key = hmacSHA256(key, s3ServiceName)
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("%s Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		sigV4Algorithm, s.accessKey, scope, signedHeaders, signature))
+}
+
+// canonicalURI percent-encodes each path segment as S3 expects
+func canonicalURI(u *url.URL) string {
+	if u.Path == "" {
+		return "/"
+	}
+	segments := strings.Split(u.Path, "/")
+	for i, segment := range segments {
+		segments[i] = awsEscape(segment)
+	}
+	return strings.Join(segments, "/")
+}
+
+// canonicalQuery sorts and encodes the query string
+func canonicalQuery(u *url.URL) string {
+	query := u.Query()
+	keys := make([]string, 0, len(query))
+	for key := range query {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var parts []string
+	for _, key := range keys {
+		values := query[key]
+		sort.Strings(values)
+		for _, value := range values {
+			parts = append(parts, awsEscape(key)+"="+awsEscape(value))
+		}
+	}
+	return strings.Join(parts, "&")
+}
+
+// awsEscape percent-encodes everything except the RFC 3986 unreserved characters
+func awsEscape(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if (c >= 'A' && c <= 'Z') || (c >= 'a' && c <= 'z') || (c >= '0' && c <= '9') || c == '-' || c == '_' || c == '.' || c == '~' {
+			b.WriteByte(c)
+			continue
+		}
+		fmt.Fprintf(&b, "%%%02X", c)
+	}
+	return b.String()
+}
+
+func hashHex(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
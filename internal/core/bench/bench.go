@@ -0,0 +1,169 @@
+// Package bench summarizes the runs of a startup benchmark, compares the
+// result with an earlier one and keeps the reports of a project.
+package bench
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/isaacgarza/dev-stack/internal/pkg/types"
+)
+
+// MinRegression is how much slower than the baseline a phase has to get to
+// be flagged however large the ratio, so the noise of fast phases such as
+// a 50ms create isn't reported
+const MinRegression = 250 * time.Millisecond
+
+// reportTimeFormat names the report files, which sort by when they ran
+const reportTimeFormat = "20060102-150405"
+
+// Run is what one run of a benchmark measured
+type Run struct {
+	Services []types.BenchTiming
+	// Total is the time the run took to bring the stack up
+	Total time.Duration
+}
+
+// Summarize returns the report of runs: the median of each phase of each
+// service and of the total. Services are in the order of the first run.
+func Summarize(runs []Run) *types.BenchReport {
+	report := &types.BenchReport{Runs: len(runs), Services: []types.BenchTiming{}}
+	if len(runs) == 0 {
+		return report
+	}
+
+	totals := make([]time.Duration, 0, len(runs))
+	for _, run := range runs {
+		totals = append(totals, run.Total)
+	}
+	report.Total = median(totals)
+
+	for _, first := range runs[0].Services {
+		timing := types.BenchTiming{Service: first.Service}
+		for _, phase := range types.BenchPhases {
+			var durations []time.Duration
+			for _, run := range runs {
+				for _, service := range run.Services {
+					if service.Service == first.Service {
+						durations = append(durations, service.Phase(phase))
+					}
+				}
+			}
+			timing.SetPhase(phase, median(durations))
+		}
+		report.Services = append(report.Services, timing)
+	}
+	return report
+}
+
+// median returns the middle of durations, or the mean of the two middle
+// ones of an even count
+func median(durations []time.Duration) time.Duration {
+	if len(durations) == 0 {
+		return 0
+	}
+	sorted := slices.Clone(durations)
+	slices.Sort(sorted)
+	middle := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[middle-1] + sorted[middle]) / 2
+	}
+	return sorted[middle]
+}
+
+// Compare returns the phases of current more than threshold, a fraction
+// such as 0.2, and at least MinRegression slower than in baseline. Services
+// missing from either report aren't compared.
+func Compare(baseline, current *types.BenchReport, threshold float64) []types.BenchRegression {
+	var regressions []types.BenchRegression
+	for _, timing := range current.Services {
+		before, ok := baseline.Service(timing.Service)
+		if !ok {
+			continue
+		}
+		for _, phase := range append(slices.Clone(types.BenchPhases), types.BenchPhaseTotal) {
+			if regressed(before.Phase(phase), timing.Phase(phase), threshold) {
+				regressions = append(regressions, types.BenchRegression{
+					Service:  timing.Service,
+					Phase:    phase,
+					Baseline: before.Phase(phase),
+					Current:  timing.Phase(phase),
+				})
+			}
+		}
+	}
+	if regressed(baseline.Total, current.Total, threshold) {
+		regressions = append(regressions, types.BenchRegression{
+			Phase:    types.BenchPhaseTotal,
+			Baseline: baseline.Total,
+			Current:  current.Total,
+		})
+	}
+	return regressions
+}
+
+// regressed reports whether current is slower than baseline by more than
+// threshold and MinRegression
+func regressed(baseline, current time.Duration, threshold float64) bool {
+	delta := current - baseline
+	return delta >= MinRegression && float64(delta) > float64(baseline)*threshold
+}
+
+// Save writes report to dir, named after when it ran, and returns its path
+func Save(dir string, report *types.BenchReport) (string, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create %s: %w", dir, err)
+	}
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	path := filepath.Join(dir, report.CreatedAt.UTC().Format(reportTimeFormat)+".json")
+	if err := os.WriteFile(path, append(data, '\n'), 0644); err != nil {
+		return "", fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return path, nil
+}
+
+// Load reads the report at path
+func Load(path string) (*types.BenchReport, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	report := &types.BenchReport{}
+	if err := json.Unmarshal(data, report); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return report, nil
+}
+
+// Latest returns the most recent report saved in dir and its path, or nil
+// when there is none
+func Latest(dir string) (*types.BenchReport, string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, "", nil
+		}
+		return nil, "", fmt.Errorf("failed to read %s: %w", dir, err)
+	}
+	var latest string
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.HasSuffix(entry.Name(), ".json") && entry.Name() > latest {
+			latest = entry.Name()
+		}
+	}
+	if latest == "" {
+		return nil, "", nil
+	}
+	path := filepath.Join(dir, latest)
+	report, err := Load(path)
+	return report, path, err
+}
@@ -0,0 +1,78 @@
+package bench
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/isaacgarza/dev-stack/internal/pkg/types"
+)
+
+func run(total time.Duration, timings ...types.BenchTiming) Run {
+	return Run{Services: timings, Total: total}
+}
+
+func TestSummarize(t *testing.T) {
+	report := Summarize([]Run{
+		run(10*time.Second, types.BenchTiming{Service: "postgres", Create: 100 * time.Millisecond, Start: time.Second, Healthy: 4 * time.Second}),
+		run(30*time.Second, types.BenchTiming{Service: "postgres", Create: 300 * time.Millisecond, Start: 3 * time.Second, Healthy: 20 * time.Second}),
+		run(12*time.Second, types.BenchTiming{Service: "postgres", Create: 200 * time.Millisecond, Start: 2 * time.Second, Healthy: 5 * time.Second}),
+	})
+
+	assert.Equal(t, 3, report.Runs)
+	assert.Equal(t, 12*time.Second, report.Total)
+	require.Len(t, report.Services, 1)
+	assert.Equal(t, types.BenchTiming{Service: "postgres", Create: 200 * time.Millisecond, Start: 2 * time.Second, Healthy: 5 * time.Second}, report.Services[0])
+
+	assert.Equal(t, 15*time.Second, median([]time.Duration{10 * time.Second, 20 * time.Second}))
+	assert.Empty(t, Summarize(nil).Services)
+}
+
+func TestCompare(t *testing.T) {
+	baseline := &types.BenchReport{Total: 10 * time.Second, Services: []types.BenchTiming{
+		{Service: "postgres", Create: 50 * time.Millisecond, Start: time.Second, Healthy: 4 * time.Second},
+		{Service: "redis", Start: time.Second},
+	}}
+	current := &types.BenchReport{Total: 11 * time.Second, Services: []types.BenchTiming{
+		{Service: "postgres", Create: 150 * time.Millisecond, Start: time.Second, Healthy: 6 * time.Second},
+		{Service: "kafka", Start: 10 * time.Second},
+	}}
+
+	// The create tripled but by less than MinRegression, and kafka has no
+	// baseline
+	assert.Equal(t, []types.BenchRegression{
+		{Service: "postgres", Phase: types.BenchPhaseHealthy, Baseline: 4 * time.Second, Current: 6 * time.Second},
+		{Service: "postgres", Phase: types.BenchPhaseTotal, Baseline: 5050 * time.Millisecond, Current: 7150 * time.Millisecond},
+	}, Compare(baseline, current, 0.2))
+
+	current.Total = 13 * time.Second
+	regressions := Compare(baseline, current, 0.2)
+	assert.Equal(t, types.BenchRegression{Phase: types.BenchPhaseTotal, Baseline: 10 * time.Second, Current: 13 * time.Second}, regressions[len(regressions)-1])
+
+	assert.Empty(t, Compare(baseline, current, 1))
+}
+
+func TestSaveLatest(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "bench")
+
+	report, path, err := Latest(dir)
+	require.NoError(t, err)
+	assert.Nil(t, report)
+	assert.Empty(t, path)
+
+	first := &types.BenchReport{Project: "shop", CreatedAt: time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC), Runs: 3}
+	second := &types.BenchReport{Project: "shop", CreatedAt: time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC), Runs: 5,
+		Services: []types.BenchTiming{{Service: "postgres", Start: time.Second}}}
+	_, err = Save(dir, second)
+	require.NoError(t, err)
+	_, err = Save(dir, first)
+	require.NoError(t, err)
+
+	report, path, err = Latest(dir)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, "20260302-090000.json"), path)
+	assert.Equal(t, second, report)
+}
@@ -0,0 +1,614 @@
+package daemon
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/textproto"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/isaacgarza/dev-stack/internal/pkg/types"
+)
+
+// RPCProtocolVersion is reported by initialize; it changes when a method or
+// notification changes incompatibly
+const RPCProtocolVersion = "1.0"
+
+// JSON-RPC methods served by the IDE server
+const (
+	MethodInitialize        = "initialize"
+	MethodShutdown          = "shutdown"
+	MethodStatus            = "status"
+	MethodStart             = "start"
+	MethodStop              = "stop"
+	MethodPorts             = "ports"
+	MethodStatusSubscribe   = "status/subscribe"
+	MethodStatusUnsubscribe = "status/unsubscribe"
+	MethodLogsSubscribe     = "logs/subscribe"
+	MethodLogsUnsubscribe   = "logs/unsubscribe"
+)
+
+// JSON-RPC notifications sent by the IDE server
+const (
+	NotificationStatusChanged = "status/changed"
+	NotificationLogsLine      = "logs/line"
+	NotificationLogsEnd       = "logs/end"
+)
+
+// JSON-RPC error codes. The first five are defined by JSON-RPC 2.0;
+// codeNotInitialized follows the Language Server Protocol.
+const (
+	codeParseError     = -32700
+	codeInvalidRequest = -32600
+	codeMethodNotFound = -32601
+	codeInvalidParams  = -32602
+	codeInternalError  = -32603
+	codeNotInitialized = -32002
+)
+
+// minStatusInterval bounds how often a status subscription polls Docker
+const minStatusInterval = 500 * time.Millisecond
+
+// rpcMessage is a JSON-RPC 2.0 request, response or notification
+type rpcMessage struct {
+	JSONRPC string           `json:"jsonrpc"`
+	ID      *json.RawMessage `json:"id,omitempty"`
+	Method  string           `json:"method,omitempty"`
+	Params  json.RawMessage  `json:"params,omitempty"`
+	Result  any              `json:"result,omitempty"`
+	Error   *rpcError        `json:"error,omitempty"`
+}
+
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *rpcError) Error() string {
+	return e.Message
+}
+
+// InitializeResult is the capability handshake returned by initialize
+type InitializeResult struct {
+	ProtocolVersion string       `json:"protocol_version"`
+	Server          ServerInfo   `json:"server"`
+	Project         string       `json:"project"`
+	Capabilities    Capabilities `json:"capabilities"`
+}
+
+// ServerInfo identifies the IDE server
+type ServerInfo struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+// Capabilities lists what a client may call and should expect to receive
+type Capabilities struct {
+	Methods       []string `json:"methods"`
+	Notifications []string `json:"notifications"`
+}
+
+// ServicesParams selects services; none means every service
+type ServicesParams struct {
+	Services []string `json:"services"`
+}
+
+// StartParams are the params of start
+type StartParams struct {
+	Services []string `json:"services"`
+	Build    bool     `json:"build"`
+	Profiles []string `json:"profiles"`
+	// Wait delays the response until the services are healthy
+	Wait bool `json:"wait"`
+}
+
+// StopParams are the params of stop
+type StopParams struct {
+	Services []string `json:"services"`
+	Timeout  int      `json:"timeout"`
+}
+
+// StatusSubscribeParams are the params of status/subscribe
+type StatusSubscribeParams struct {
+	Services []string `json:"services"`
+	// Interval is how often to poll, as a duration such as 2s
+	Interval string `json:"interval"`
+}
+
+// LogsSubscribeParams are the params of logs/subscribe
+type LogsSubscribeParams struct {
+	Services []string `json:"services"`
+	Tail     string   `json:"tail"`
+	Since    string   `json:"since"`
+	Follow   bool     `json:"follow"`
+}
+
+// SubscriptionParams identify a subscription to cancel
+type SubscriptionParams struct {
+	Subscription int `json:"subscription"`
+}
+
+// SubscriptionResult is returned by the subscribe methods
+type SubscriptionResult struct {
+	Subscription int `json:"subscription"`
+}
+
+// ServicePort is a published port of a service, as returned by ports
+type ServicePort struct {
+	Service   string `json:"service"`
+	Host      string `json:"host"`
+	Container string `json:"container"`
+	Protocol  string `json:"protocol,omitempty"`
+}
+
+// StatusChanged is the params of status/changed
+type StatusChanged struct {
+	Subscription int                   `json:"subscription"`
+	Services     []types.ServiceStatus `json:"services"`
+}
+
+// LogsLine is the params of logs/line
+type LogsLine struct {
+	Subscription int    `json:"subscription"`
+	Line         string `json:"line"`
+}
+
+// LogsEnd is the params of logs/end, sent once when a log stream stops
+type LogsEnd struct {
+	Subscription int    `json:"subscription"`
+	Error        string `json:"error,omitempty"`
+}
+
+// RPCServer speaks JSON-RPC 2.0 over a pair of streams, framed with
+// Content-Length headers as in the Language Server Protocol, so editor
+// plugins can reuse their LSP client libraries. Clients must call initialize
+// before anything else.
+type RPCServer struct {
+	backend     Backend
+	projectName string
+	version     string
+	logger      *slog.Logger
+
+	writeMu sync.Mutex
+	w       io.Writer
+
+	mu            sync.Mutex
+	initialized   bool
+	nextID        int
+	subscriptions map[int]context.CancelFunc
+	lifecycle     sync.Mutex
+	wg            sync.WaitGroup
+}
+
+// NewRPCServer creates a new JSON-RPC server reporting version in its
+// handshake
+func NewRPCServer(backend Backend, projectName, version string, logger *slog.Logger) *RPCServer {
+	return &RPCServer{
+		backend:       backend,
+		projectName:   projectName,
+		version:       version,
+		logger:        logger,
+		subscriptions: make(map[int]context.CancelFunc),
+	}
+}
+
+// Serve reads requests from r and writes responses and notifications to w
+// until r is exhausted, ctx is cancelled or the client calls shutdown.
+// Requests other than initialize and shutdown are handled concurrently.
+func (s *RPCServer) Serve(ctx context.Context, r io.Reader, w io.Writer) error {
+	s.w = w
+	ctx, cancel := context.WithCancel(ctx)
+	defer func() {
+		cancel()
+		s.wg.Wait()
+	}()
+
+	messages := make(chan []byte)
+	readErr := make(chan error, 1)
+	go func() {
+		reader := bufio.NewReader(r)
+		for {
+			body, err := readFrame(reader)
+			if err != nil {
+				readErr <- err
+				return
+			}
+			select {
+			case messages <- body:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	for {
+		var body []byte
+		select {
+		case <-ctx.Done():
+			return nil
+		case err := <-readErr:
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return err
+		case body = <-messages:
+		}
+
+		var msg rpcMessage
+		if err := json.Unmarshal(body, &msg); err != nil {
+			s.reply(nil, nil, &rpcError{Code: codeParseError, Message: err.Error()})
+			continue
+		}
+		if msg.JSONRPC != "2.0" || msg.Method == "" {
+			s.reply(msg.ID, nil, &rpcError{Code: codeInvalidRequest, Message: "expected a JSON-RPC 2.0 request"})
+			continue
+		}
+
+		switch msg.Method {
+		case MethodInitialize, MethodShutdown:
+			result, err := s.call(ctx, msg)
+			s.reply(msg.ID, result, err)
+			if msg.Method == MethodShutdown {
+				return nil
+			}
+		default:
+			s.wg.Add(1)
+			go func() {
+				defer s.wg.Done()
+				result, err := s.call(ctx, msg)
+				s.reply(msg.ID, result, err)
+				if sub, ok := result.(subscription); ok {
+					sub.start()
+				}
+			}()
+		}
+	}
+}
+
+// call dispatches a request to its method
+func (s *RPCServer) call(ctx context.Context, msg rpcMessage) (any, *rpcError) {
+	if msg.Method == MethodInitialize {
+		s.mu.Lock()
+		s.initialized = true
+		s.mu.Unlock()
+		return s.capabilities(), nil
+	}
+
+	s.mu.Lock()
+	initialized := s.initialized
+	s.mu.Unlock()
+	if !initialized {
+		return nil, &rpcError{Code: codeNotInitialized, Message: "initialize must be called first"}
+	}
+
+	switch msg.Method {
+	case MethodShutdown:
+		return nil, nil
+	case MethodStatus:
+		var params ServicesParams
+		if err := decodeParams(msg.Params, &params); err != nil {
+			return nil, err
+		}
+		statuses, err := s.backend.GetServiceStatus(ctx, params.Services)
+		if err != nil {
+			return nil, internalError(err)
+		}
+		return withoutNil(statuses), nil
+	case MethodStart:
+		var params StartParams
+		if err := decodeParams(msg.Params, &params); err != nil {
+			return nil, err
+		}
+		s.lifecycle.Lock()
+		defer s.lifecycle.Unlock()
+		if err := s.backend.StartServices(ctx, params.Services, types.StartOptions{
+			Build:    params.Build,
+			Detach:   !params.Wait,
+			Profiles: params.Profiles,
+		}); err != nil {
+			return nil, internalError(err)
+		}
+		statuses, err := s.backend.GetServiceStatus(ctx, params.Services)
+		if err != nil {
+			return nil, internalError(err)
+		}
+		return withoutNil(statuses), nil
+	case MethodStop:
+		var params StopParams
+		if err := decodeParams(msg.Params, &params); err != nil {
+			return nil, err
+		}
+		s.lifecycle.Lock()
+		defer s.lifecycle.Unlock()
+		if err := s.backend.StopServices(ctx, params.Services, types.StopOptions{Timeout: params.Timeout}); err != nil {
+			return nil, internalError(err)
+		}
+		return nil, nil
+	case MethodPorts:
+		var params ServicesParams
+		if err := decodeParams(msg.Params, &params); err != nil {
+			return nil, err
+		}
+		statuses, err := s.backend.GetServiceStatus(ctx, params.Services)
+		if err != nil {
+			return nil, internalError(err)
+		}
+		return servicePorts(statuses), nil
+	case MethodStatusSubscribe:
+		var params StatusSubscribeParams
+		if err := decodeParams(msg.Params, &params); err != nil {
+			return nil, err
+		}
+		interval := 2 * time.Second
+		if params.Interval != "" {
+			parsed, err := time.ParseDuration(params.Interval)
+			if err != nil || parsed <= 0 {
+				return nil, &rpcError{Code: codeInvalidParams, Message: fmt.Sprintf("invalid interval %q", params.Interval)}
+			}
+			interval = max(parsed, minStatusInterval)
+		}
+		return s.subscribe(ctx, func(ctx context.Context, id int) {
+			s.watchStatus(ctx, id, params.Services, interval)
+		}), nil
+	case MethodLogsSubscribe:
+		var params LogsSubscribeParams
+		if err := decodeParams(msg.Params, &params); err != nil {
+			return nil, err
+		}
+		return s.subscribe(ctx, func(ctx context.Context, id int) {
+			s.streamLogs(ctx, id, params)
+		}), nil
+	case MethodStatusUnsubscribe, MethodLogsUnsubscribe:
+		var params SubscriptionParams
+		if err := decodeParams(msg.Params, &params); err != nil {
+			return nil, err
+		}
+		if !s.unsubscribe(params.Subscription) {
+			return nil, &rpcError{Code: codeInvalidParams, Message: fmt.Sprintf("no subscription %d", params.Subscription)}
+		}
+		return nil, nil
+	}
+	return nil, &rpcError{Code: codeMethodNotFound, Message: fmt.Sprintf("unknown method %q", msg.Method)}
+}
+
+// capabilities builds the initialize handshake
+func (s *RPCServer) capabilities() InitializeResult {
+	return InitializeResult{
+		ProtocolVersion: RPCProtocolVersion,
+		Server:          ServerInfo{Name: "dev-stack", Version: s.version},
+		Project:         s.projectName,
+		Capabilities: Capabilities{
+			Methods: []string{
+				MethodInitialize, MethodShutdown, MethodStatus, MethodStart, MethodStop, MethodPorts,
+				MethodStatusSubscribe, MethodStatusUnsubscribe, MethodLogsSubscribe, MethodLogsUnsubscribe,
+			},
+			Notifications: []string{NotificationStatusChanged, NotificationLogsLine, NotificationLogsEnd},
+		},
+	}
+}
+
+// subscription is the result of the subscribe methods. It is started once
+// the response is sent, so no notification reaches the client before the
+// subscription ID does.
+type subscription struct {
+	SubscriptionResult
+	start func()
+}
+
+// subscribe registers a subscription that runs fn in the background until
+// it is cancelled or the server stops
+func (s *RPCServer) subscribe(ctx context.Context, fn func(ctx context.Context, id int)) subscription {
+	ctx, cancel := context.WithCancel(ctx)
+
+	s.mu.Lock()
+	s.nextID++
+	id := s.nextID
+	s.subscriptions[id] = cancel
+	s.mu.Unlock()
+
+	return subscription{
+		SubscriptionResult: SubscriptionResult{Subscription: id},
+		start: func() {
+			s.wg.Add(1)
+			go func() {
+				defer s.wg.Done()
+				defer s.unsubscribe(id)
+				fn(ctx, id)
+			}()
+		},
+	}
+}
+
+// unsubscribe cancels a subscription, reporting whether it existed
+func (s *RPCServer) unsubscribe(id int) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	cancel, ok := s.subscriptions[id]
+	if ok {
+		cancel()
+		delete(s.subscriptions, id)
+	}
+	return ok
+}
+
+// watchStatus sends status/changed with the current status, then again
+// whenever a service's state, health or ports change
+func (s *RPCServer) watchStatus(ctx context.Context, id int, serviceNames []string, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	last := ""
+	for {
+		statuses, err := s.backend.GetServiceStatus(ctx, serviceNames)
+		if ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			s.logger.Warn("Status subscription failed to read status", "subscription", id, "error", err)
+		} else if key := statusKey(statuses); key != last {
+			last = key
+			s.notify(NotificationStatusChanged, StatusChanged{Subscription: id, Services: withoutNil(statuses)})
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// streamLogs sends each log line as logs/line, then logs/end once the
+// stream stops for any reason other than an unsubscribe
+func (s *RPCServer) streamLogs(ctx context.Context, id int, params LogsSubscribeParams) {
+	lines := &lineWriter{send: func(line string) error {
+		return s.notify(NotificationLogsLine, LogsLine{Subscription: id, Line: line})
+	}}
+	err := s.backend.StreamLogs(ctx, params.Services, types.LogOptions{
+		Follow: params.Follow,
+		Tail:   params.Tail,
+		Since:  params.Since,
+	}, lines)
+	if flushErr := lines.Flush(); err == nil {
+		err = flushErr
+	}
+	if ctx.Err() != nil {
+		return
+	}
+
+	end := LogsEnd{Subscription: id}
+	if err != nil {
+		end.Error = err.Error()
+	}
+	_ = s.notify(NotificationLogsEnd, end)
+}
+
+// reply sends the response to a request; notifications get none
+func (s *RPCServer) reply(id *json.RawMessage, result any, rpcErr *rpcError) {
+	if id == nil && rpcErr == nil {
+		return
+	}
+	msg := rpcMessage{JSONRPC: "2.0", ID: id, Error: rpcErr}
+	if rpcErr == nil {
+		msg.Result = result
+		if result == nil {
+			// A successful response must carry a result
+			msg.Result = json.RawMessage("null")
+		}
+	}
+	if id == nil {
+		null := json.RawMessage("null")
+		msg.ID = &null
+	}
+	if err := s.send(msg); err != nil {
+		s.logger.Error("Failed to send JSON-RPC response", "error", err)
+	}
+}
+
+// notify sends a notification to the client
+func (s *RPCServer) notify(method string, params any) error {
+	data, err := json.Marshal(params)
+	if err != nil {
+		return err
+	}
+	return s.send(rpcMessage{JSONRPC: "2.0", Method: method, Params: data})
+}
+
+// send writes one framed message
+func (s *RPCServer) send(msg rpcMessage) error {
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+	_, err = fmt.Fprintf(s.w, "Content-Length: %d\r\n\r\n%s", len(body), body)
+	return err
+}
+
+// readFrame reads the body of one Content-Length framed message
+func readFrame(r *bufio.Reader) ([]byte, error) {
+	headers, err := textproto.NewReader(r).ReadMIMEHeader()
+	if err != nil {
+		if errors.Is(err, io.EOF) && len(headers) == 0 {
+			return nil, io.EOF
+		}
+		return nil, fmt.Errorf("failed to read message header: %w", err)
+	}
+
+	length, err := strconv.Atoi(headers.Get("Content-Length"))
+	if err != nil || length < 0 || length > maxRequestBody {
+		return nil, fmt.Errorf("invalid Content-Length %q", headers.Get("Content-Length"))
+	}
+	body := make([]byte, length)
+	if _, err := io.ReadFull(r, body); err != nil {
+		return nil, fmt.Errorf("failed to read message body: %w", err)
+	}
+	return body, nil
+}
+
+// decodeParams decodes request params into v. Missing params leave v
+// unchanged.
+func decodeParams(params json.RawMessage, v any) *rpcError {
+	if len(params) == 0 || string(params) == "null" {
+		return nil
+	}
+	if err := json.Unmarshal(params, v); err != nil {
+		return &rpcError{Code: codeInvalidParams, Message: err.Error()}
+	}
+	return nil
+}
+
+func internalError(err error) *rpcError {
+	return &rpcError{Code: codeInternalError, Message: err.Error()}
+}
+
+// withoutNil returns statuses, never encoding them as null
+func withoutNil(statuses []types.ServiceStatus) []types.ServiceStatus {
+	if statuses == nil {
+		return []types.ServiceStatus{}
+	}
+	return statuses
+}
+
+// servicePorts flattens the published ports of every service
+func servicePorts(statuses []types.ServiceStatus) []ServicePort {
+	ports := []ServicePort{}
+	for _, status := range statuses {
+		for _, port := range status.Ports {
+			ports = append(ports, ServicePort{
+				Service:   status.Name,
+				Host:      port.Host,
+				Container: port.Container,
+				Protocol:  port.Protocol,
+			})
+		}
+	}
+	return ports
+}
+
+// statusKey summarises what a status subscriber cares about, leaving out
+// fields such as uptime that change on every poll
+func statusKey(statuses []types.ServiceStatus) string {
+	entries := make([]string, 0, len(statuses))
+	for _, status := range statuses {
+		ports := make([]string, 0, len(status.Ports))
+		for _, port := range status.Ports {
+			ports = append(ports, port.Host+":"+port.Container+"/"+port.Protocol)
+		}
+		slices.Sort(ports)
+		entries = append(entries, fmt.Sprintf("%s=%s/%s[%s]", status.Name, status.State, status.Health, strings.Join(ports, ",")))
+	}
+	slices.Sort(entries)
+	return strings.Join(entries, ";")
+}
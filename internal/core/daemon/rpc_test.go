@@ -0,0 +1,263 @@
+package daemon
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/isaacgarza/dev-stack/internal/pkg/types"
+)
+
+// rpcClient drives an RPCServer over in-memory pipes
+type rpcClient struct {
+	t        *testing.T
+	requests *io.PipeWriter
+	messages chan map[string]json.RawMessage
+	pending  []map[string]json.RawMessage
+	done     chan error
+	nextID   int
+}
+
+func newRPCClient(t *testing.T, backend Backend) *rpcClient {
+	t.Helper()
+	serverIn, requests := io.Pipe()
+	responses, serverOut := io.Pipe()
+
+	client := &rpcClient{
+		t:        t,
+		requests: requests,
+		messages: make(chan map[string]json.RawMessage, 64),
+		done:     make(chan error, 1),
+	}
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	server := NewRPCServer(backend, "test", "1.2.3", logger)
+	go func() {
+		client.done <- server.Serve(context.Background(), serverIn, serverOut)
+		_ = serverOut.Close()
+	}()
+	go func() {
+		reader := bufio.NewReader(responses)
+		for {
+			body, err := readFrame(reader)
+			if err != nil {
+				close(client.messages)
+				return
+			}
+			var msg map[string]json.RawMessage
+			if err := json.Unmarshal(body, &msg); err == nil {
+				client.messages <- msg
+			}
+		}
+	}()
+	t.Cleanup(func() { _ = requests.Close() })
+	return client
+}
+
+func (c *rpcClient) send(method string, params any) int {
+	c.t.Helper()
+	c.nextID++
+	body, err := json.Marshal(map[string]any{"jsonrpc": "2.0", "id": c.nextID, "method": method, "params": params})
+	require.NoError(c.t, err)
+	_, err = fmt.Fprintf(c.requests, "Content-Length: %d\r\n\r\n%s", len(body), body)
+	require.NoError(c.t, err)
+	return c.nextID
+}
+
+// next returns the first message matching accept, keeping the others for
+// later calls
+func (c *rpcClient) next(accept func(map[string]json.RawMessage) bool) map[string]json.RawMessage {
+	c.t.Helper()
+	for i, msg := range c.pending {
+		if accept(msg) {
+			c.pending = append(c.pending[:i], c.pending[i+1:]...)
+			return msg
+		}
+	}
+
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case msg, ok := <-c.messages:
+			require.True(c.t, ok, "server closed the stream")
+			if accept(msg) {
+				return msg
+			}
+			c.pending = append(c.pending, msg)
+		case <-timeout:
+			c.t.Fatal("timed out waiting for a message")
+		}
+	}
+}
+
+// call sends a request and returns its response
+func (c *rpcClient) call(method string, params any) map[string]json.RawMessage {
+	c.t.Helper()
+	id := c.send(method, params)
+	return c.next(func(msg map[string]json.RawMessage) bool {
+		return string(msg["id"]) == fmt.Sprint(id)
+	})
+}
+
+// notification waits for a notification of the given method
+func (c *rpcClient) notification(method string) json.RawMessage {
+	c.t.Helper()
+	msg := c.next(func(msg map[string]json.RawMessage) bool {
+		return string(msg["method"]) == `"`+method+`"`
+	})
+	return msg["params"]
+}
+
+func errorCode(t *testing.T, msg map[string]json.RawMessage) int {
+	t.Helper()
+	var rpcErr rpcError
+	require.Contains(t, msg, "error")
+	require.NoError(t, json.Unmarshal(msg["error"], &rpcErr))
+	return rpcErr.Code
+}
+
+// statusBackend serves a status that can change between polls
+type statusBackend struct {
+	fakeBackend
+	mu       sync.Mutex
+	statuses []types.ServiceStatus
+}
+
+func (b *statusBackend) GetServiceStatus(ctx context.Context, serviceNames []string) ([]types.ServiceStatus, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return append([]types.ServiceStatus(nil), b.statuses...), nil
+}
+
+func (b *statusBackend) set(statuses ...types.ServiceStatus) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.statuses = statuses
+}
+
+func TestRPCServerHandshake(t *testing.T) {
+	client := newRPCClient(t, &fakeBackend{})
+
+	assert.Equal(t, codeNotInitialized, errorCode(t, client.call(MethodStatus, nil)))
+
+	resp := client.call(MethodInitialize, nil)
+	var result InitializeResult
+	require.NoError(t, json.Unmarshal(resp["result"], &result))
+	assert.Equal(t, RPCProtocolVersion, result.ProtocolVersion)
+	assert.Equal(t, ServerInfo{Name: "dev-stack", Version: "1.2.3"}, result.Server)
+	assert.Equal(t, "test", result.Project)
+	assert.Contains(t, result.Capabilities.Methods, MethodLogsSubscribe)
+	assert.Contains(t, result.Capabilities.Notifications, NotificationStatusChanged)
+
+	assert.Equal(t, codeMethodNotFound, errorCode(t, client.call("restart", nil)))
+	assert.Equal(t, codeInvalidParams, errorCode(t, client.call(MethodStatus, "postgres")))
+
+	resp = client.call(MethodShutdown, nil)
+	assert.Equal(t, "null", string(resp["result"]))
+	select {
+	case err := <-client.done:
+		assert.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("server did not stop after shutdown")
+	}
+}
+
+func TestRPCServerOperations(t *testing.T) {
+	backend := &fakeBackend{}
+	client := newRPCClient(t, backend)
+	client.call(MethodInitialize, nil)
+
+	resp := client.call(MethodStart, StartParams{Services: []string{"postgres"}, Build: true})
+	var statuses []types.ServiceStatus
+	require.NoError(t, json.Unmarshal(resp["result"], &statuses))
+	assert.Equal(t, []string{"postgres"}, backend.started)
+	assert.True(t, backend.startOpts.Build)
+	assert.True(t, backend.startOpts.Detach)
+	require.Len(t, statuses, 1)
+
+	resp = client.call(MethodStop, StopParams{Services: []string{"redis"}, Timeout: 5})
+	assert.NotContains(t, resp, "error")
+	assert.Equal(t, []string{"redis"}, backend.stopped)
+	assert.Equal(t, 5, backend.stopOpts.Timeout)
+
+	resp = client.call(MethodPorts, nil)
+	assert.Equal(t, "[]", string(resp["result"]))
+}
+
+func TestRPCServerStatusSubscription(t *testing.T) {
+	backend := &statusBackend{}
+	backend.set(types.ServiceStatus{Name: "postgres", State: types.ServiceStateCreated})
+	client := newRPCClient(t, backend)
+	client.call(MethodInitialize, nil)
+
+	resp := client.call(MethodStatusSubscribe, StatusSubscribeParams{Interval: "10ms"})
+	var sub SubscriptionResult
+	require.NoError(t, json.Unmarshal(resp["result"], &sub))
+
+	var changed StatusChanged
+	require.NoError(t, json.Unmarshal(client.notification(NotificationStatusChanged), &changed))
+	assert.Equal(t, sub.Subscription, changed.Subscription)
+	assert.Equal(t, types.ServiceStateCreated, changed.Services[0].State)
+
+	backend.set(types.ServiceStatus{
+		Name:  "postgres",
+		State: types.ServiceStateRunning,
+		Ports: []types.PortMapping{{Host: "5432", Container: "5432", Protocol: "tcp"}},
+	})
+	require.NoError(t, json.Unmarshal(client.notification(NotificationStatusChanged), &changed))
+	assert.Equal(t, types.ServiceStateRunning, changed.Services[0].State)
+
+	resp = client.call(MethodPorts, nil)
+	var ports []ServicePort
+	require.NoError(t, json.Unmarshal(resp["result"], &ports))
+	assert.Equal(t, []ServicePort{{Service: "postgres", Host: "5432", Container: "5432", Protocol: "tcp"}}, ports)
+
+	resp = client.call(MethodStatusUnsubscribe, SubscriptionParams{Subscription: sub.Subscription})
+	assert.NotContains(t, resp, "error")
+	resp = client.call(MethodStatusUnsubscribe, SubscriptionParams{Subscription: sub.Subscription})
+	assert.Equal(t, codeInvalidParams, errorCode(t, resp))
+}
+
+func TestRPCServerLogsSubscription(t *testing.T) {
+	client := newRPCClient(t, &fakeBackend{logs: "postgres-1  | ready\nredis-1  | ready\n"})
+	client.call(MethodInitialize, nil)
+
+	resp := client.call(MethodLogsSubscribe, LogsSubscribeParams{Tail: "10"})
+	var sub SubscriptionResult
+	require.NoError(t, json.Unmarshal(resp["result"], &sub))
+
+	var lines []string
+	for range 2 {
+		var line LogsLine
+		require.NoError(t, json.Unmarshal(client.notification(NotificationLogsLine), &line))
+		assert.Equal(t, sub.Subscription, line.Subscription)
+		lines = append(lines, line.Line)
+	}
+	assert.Equal(t, []string{"postgres-1  | ready", "redis-1  | ready"}, lines)
+
+	var end LogsEnd
+	require.NoError(t, json.Unmarshal(client.notification(NotificationLogsEnd), &end))
+	assert.Equal(t, LogsEnd{Subscription: sub.Subscription}, end)
+}
+
+func TestReadFrame(t *testing.T) {
+	body, err := readFrame(bufio.NewReader(strings.NewReader("Content-Length: 2\r\nContent-Type: application/json\r\n\r\n{}")))
+	require.NoError(t, err)
+	assert.Equal(t, "{}", string(body))
+
+	_, err = readFrame(bufio.NewReader(strings.NewReader("")))
+	assert.ErrorIs(t, err, io.EOF)
+
+	_, err = readFrame(bufio.NewReader(strings.NewReader("Content-Length: many\r\n\r\n")))
+	assert.Error(t, err)
+}
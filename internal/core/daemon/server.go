@@ -0,0 +1,393 @@
+package daemon
+
+import (
+	"bytes"
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/net/websocket"
+
+	"github.com/isaacgarza/dev-stack/internal/pkg/types"
+)
+
+// APIVersion prefixes every route served by the daemon
+const APIVersion = "v1"
+
+// maxRequestBody bounds the JSON bodies accepted by the daemon
+const maxRequestBody = 1 << 20
+
+// Backend is the subset of service manager operations exposed by the
+// daemon; it is satisfied by services.Manager
+type Backend interface {
+	StartServices(ctx context.Context, serviceNames []string, options types.StartOptions) error
+	StopServices(ctx context.Context, serviceNames []string, options types.StopOptions) error
+	GetServiceStatus(ctx context.Context, serviceNames []string) ([]types.ServiceStatus, error)
+	StreamLogs(ctx context.Context, serviceNames []string, options types.LogOptions, w io.Writer) error
+	ExecCapture(ctx context.Context, serviceName string, cmd []string) (*types.ExecResult, error)
+}
+
+// UpRequest is the body of POST /v1/up
+type UpRequest struct {
+	Services      []string `json:"services"`
+	Build         bool     `json:"build"`
+	ForceRecreate bool     `json:"force_recreate"`
+	NoDeps        bool     `json:"no_deps"`
+	Profiles      []string `json:"profiles"`
+	// Wait blocks the request until the services are healthy
+	Wait bool `json:"wait"`
+	// Timeout bounds the wait, as a duration such as 90s
+	Timeout string `json:"timeout"`
+}
+
+// DownRequest is the body of POST /v1/down
+type DownRequest struct {
+	Services      []string `json:"services"`
+	Timeout       int      `json:"timeout"`
+	Remove        bool     `json:"remove"`
+	RemoveVolumes bool     `json:"remove_volumes"`
+}
+
+// ExecRequest is the body of POST /v1/exec
+type ExecRequest struct {
+	Service string   `json:"service"`
+	Command []string `json:"command"`
+}
+
+// ExecResponse is the body returned by POST /v1/exec
+type ExecResponse struct {
+	ExitCode int    `json:"exit_code"`
+	Stdout   string `json:"stdout"`
+	Stderr   string `json:"stderr"`
+}
+
+// StatusResponse is the body returned by GET /v1/status and POST /v1/up
+type StatusResponse struct {
+	Project  string                `json:"project"`
+	Services []types.ServiceStatus `json:"services"`
+}
+
+// errorResponse is the body returned for every failed request
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+// Server exposes a Backend over a local REST and WebSocket API. Every route
+// except /v1/health requires the bearer token the server was created with.
+type Server struct {
+	backend     Backend
+	projectName string
+	token       string
+	logger      *slog.Logger
+
+	// lifecycle serialises up and down so concurrent clients cannot
+	// interleave compose runs on the same project
+	lifecycle sync.Mutex
+}
+
+// NewServer creates a new daemon server
+func NewServer(backend Backend, projectName, token string, logger *slog.Logger) *Server {
+	return &Server{backend: backend, projectName: projectName, token: token, logger: logger}
+}
+
+// Handler returns the HTTP handler serving the API.
+//
+// GET  /v1/health  reports that the daemon is up, without authentication
+// GET  /v1/status  returns service status; filter with ?service=a,b
+// POST /v1/up      starts services (UpRequest)
+// POST /v1/down    stops services (DownRequest)
+// POST /v1/exec    runs a command in a service container (ExecRequest)
+// GET  /v1/logs    streams logs, over a WebSocket when the request upgrades,
+// otherwise as chunked text. Takes ?service=, tail, since and follow.
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /"+APIVersion+"/health", s.serveHealth)
+	mux.Handle("GET /"+APIVersion+"/status", s.authenticate(http.HandlerFunc(s.serveStatus)))
+	mux.Handle("POST /"+APIVersion+"/up", s.authenticate(http.HandlerFunc(s.serveUp)))
+	mux.Handle("POST /"+APIVersion+"/down", s.authenticate(http.HandlerFunc(s.serveDown)))
+	mux.Handle("POST /"+APIVersion+"/exec", s.authenticate(http.HandlerFunc(s.serveExec)))
+	mux.Handle("GET /"+APIVersion+"/logs", s.authenticate(http.HandlerFunc(s.serveLogs)))
+	return mux
+}
+
+// authenticate rejects requests that do not carry the server's bearer token
+func (s *Server) authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(token), []byte(s.token)) != 1 {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="dev-stack"`)
+			writeError(w, http.StatusUnauthorized, errors.New("missing or invalid token"))
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (s *Server) serveHealth(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok", "project": s.projectName})
+}
+
+func (s *Server) serveStatus(w http.ResponseWriter, r *http.Request) {
+	statuses, err := s.backend.GetServiceStatus(r.Context(), queryServices(r))
+	if err != nil {
+		writeError(w, http.StatusBadGateway, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, s.statusResponse(statuses))
+}
+
+func (s *Server) serveUp(w http.ResponseWriter, r *http.Request) {
+	var req UpRequest
+	if err := decodeBody(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	options := types.StartOptions{
+		Build:         req.Build,
+		ForceRecreate: req.ForceRecreate,
+		NoDeps:        req.NoDeps,
+		Detach:        !req.Wait,
+		Profiles:      req.Profiles,
+	}
+	if req.Timeout != "" {
+		timeout, err := time.ParseDuration(req.Timeout)
+		if err != nil || timeout <= 0 {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("invalid timeout %q", req.Timeout))
+			return
+		}
+		options.Timeout = timeout
+	}
+
+	s.lifecycle.Lock()
+	err := s.backend.StartServices(r.Context(), req.Services, options)
+	s.lifecycle.Unlock()
+	if err != nil {
+		writeError(w, http.StatusBadGateway, err)
+		return
+	}
+	s.logger.Info("Started services via daemon", "services", req.Services)
+
+	statuses, err := s.backend.GetServiceStatus(r.Context(), req.Services)
+	if err != nil {
+		writeError(w, http.StatusBadGateway, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, s.statusResponse(statuses))
+}
+
+func (s *Server) serveDown(w http.ResponseWriter, r *http.Request) {
+	var req DownRequest
+	if err := decodeBody(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	if req.Timeout < 0 {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid timeout %d", req.Timeout))
+		return
+	}
+
+	s.lifecycle.Lock()
+	err := s.backend.StopServices(r.Context(), req.Services, types.StopOptions{
+		Timeout:       req.Timeout,
+		Remove:        req.Remove,
+		RemoveVolumes: req.RemoveVolumes,
+	})
+	s.lifecycle.Unlock()
+	if err != nil {
+		writeError(w, http.StatusBadGateway, err)
+		return
+	}
+	s.logger.Info("Stopped services via daemon", "services", req.Services)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) serveExec(w http.ResponseWriter, r *http.Request) {
+	var req ExecRequest
+	if err := decodeBody(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	if req.Service == "" || len(req.Command) == 0 {
+		writeError(w, http.StatusBadRequest, errors.New("service and command are required"))
+		return
+	}
+
+	result, err := s.backend.ExecCapture(r.Context(), req.Service, req.Command)
+	if err != nil {
+		writeError(w, http.StatusBadGateway, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, ExecResponse{ExitCode: result.ExitCode, Stdout: result.Stdout, Stderr: result.Stderr})
+}
+
+func (s *Server) serveLogs(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	serviceNames := queryServices(r)
+	options := types.LogOptions{
+		Follow:     query.Get("follow") == "true" || query.Get("follow") == "1",
+		Timestamps: query.Get("timestamps") == "true" || query.Get("timestamps") == "1",
+		Tail:       query.Get("tail"),
+		Since:      query.Get("since"),
+	}
+
+	if strings.EqualFold(r.Header.Get("Upgrade"), "websocket") {
+		// Authentication already happened, so accept any origin
+		websocket.Server{Handler: func(ws *websocket.Conn) {
+			s.streamLogsWebSocket(ws, serviceNames, options)
+		}}.ServeHTTP(w, r)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	out := &flushWriter{w: w}
+	if flusher, ok := w.(http.Flusher); ok {
+		out.flusher = flusher
+	}
+	if err := s.backend.StreamLogs(r.Context(), serviceNames, options, out); err != nil {
+		if !out.written {
+			writeError(w, http.StatusBadGateway, err)
+			return
+		}
+		s.logger.Warn("Log stream failed", "error", err)
+	}
+}
+
+// streamLogsWebSocket sends each log line as a text message until the logs
+// end or the client goes away. A final message starting with "error: "
+// reports a failed stream before the socket is closed.
+func (s *Server) streamLogsWebSocket(ws *websocket.Conn, serviceNames []string, options types.LogOptions) {
+	defer func() { _ = ws.Close() }()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// The client never sends anything; a failed read means it disconnected
+	go func() {
+		var discard string
+		for websocket.Message.Receive(ws, &discard) == nil {
+		}
+		cancel()
+	}()
+
+	lines := &lineWriter{send: func(line string) error { return websocket.Message.Send(ws, line) }}
+	err := s.backend.StreamLogs(ctx, serviceNames, options, lines)
+	if flushErr := lines.Flush(); err == nil {
+		err = flushErr
+	}
+	if err != nil && ctx.Err() == nil {
+		_ = websocket.Message.Send(ws, "error: "+err.Error())
+	}
+}
+
+// statusResponse wraps statuses, never encoding them as null
+func (s *Server) statusResponse(statuses []types.ServiceStatus) StatusResponse {
+	if statuses == nil {
+		statuses = []types.ServiceStatus{}
+	}
+	return StatusResponse{Project: s.projectName, Services: statuses}
+}
+
+// queryServices returns the services named by repeated or comma separated
+// ?service= parameters
+func queryServices(r *http.Request) []string {
+	var serviceNames []string
+	for _, value := range r.URL.Query()["service"] {
+		for _, name := range strings.Split(value, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				serviceNames = append(serviceNames, name)
+			}
+		}
+	}
+	return serviceNames
+}
+
+// decodeBody decodes a JSON request body into v. An empty body leaves v
+// unchanged.
+func decodeBody(r *http.Request, v any) error {
+	decoder := json.NewDecoder(io.LimitReader(r.Body, maxRequestBody))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(v); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("invalid request body: %w", err)
+	}
+	return nil
+}
+
+func writeJSON(w http.ResponseWriter, code int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, code int, err error) {
+	writeJSON(w, code, errorResponse{Error: err.Error()})
+}
+
+// flushWriter flushes every write so streamed logs reach the client
+// immediately
+type flushWriter struct {
+	w       io.Writer
+	flusher http.Flusher
+	written bool
+}
+
+func (f *flushWriter) Write(p []byte) (int, error) {
+	// An empty write would still commit the response status
+	if len(p) == 0 {
+		return 0, nil
+	}
+	n, err := f.w.Write(p)
+	f.written = f.written || n > 0
+	if f.flusher != nil {
+		f.flusher.Flush()
+	}
+	return n, err
+}
+
+// lineWriter splits written output into lines, passing each one to send
+// without its trailing newline
+type lineWriter struct {
+	mu      sync.Mutex
+	send    func(line string) error
+	pending []byte
+}
+
+func (l *lineWriter) Write(p []byte) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.pending = append(l.pending, p...)
+	for {
+		i := bytes.IndexByte(l.pending, '\n')
+		if i < 0 {
+			return len(p), nil
+		}
+		line := strings.TrimSuffix(string(l.pending[:i]), "\r")
+		l.pending = l.pending[i+1:]
+		if err := l.send(line); err != nil {
+			return 0, err
+		}
+	}
+}
+
+// Flush sends any incomplete last line
+func (l *lineWriter) Flush() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if len(l.pending) == 0 {
+		return nil
+	}
+	line := string(l.pending)
+	l.pending = nil
+	return l.send(line)
+}
@@ -0,0 +1,237 @@
+package daemon
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/websocket"
+
+	"github.com/isaacgarza/dev-stack/internal/pkg/types"
+)
+
+const testToken = "secret"
+
+type fakeBackend struct {
+	started   []string
+	startOpts types.StartOptions
+	stopped   []string
+	stopOpts  types.StopOptions
+	logs      string
+	logsErr   error
+	exec      []string
+}
+
+func (f *fakeBackend) StartServices(ctx context.Context, serviceNames []string, options types.StartOptions) error {
+	f.started, f.startOpts = serviceNames, options
+	return nil
+}
+
+func (f *fakeBackend) StopServices(ctx context.Context, serviceNames []string, options types.StopOptions) error {
+	f.stopped, f.stopOpts = serviceNames, options
+	return nil
+}
+
+func (f *fakeBackend) GetServiceStatus(ctx context.Context, serviceNames []string) ([]types.ServiceStatus, error) {
+	var statuses []types.ServiceStatus
+	for _, name := range []string{"postgres", "redis"} {
+		if len(serviceNames) == 0 || containsName(serviceNames, name) {
+			statuses = append(statuses, types.ServiceStatus{Name: name, State: types.ServiceStateRunning})
+		}
+	}
+	return statuses, nil
+}
+
+func (f *fakeBackend) StreamLogs(ctx context.Context, serviceNames []string, options types.LogOptions, w io.Writer) error {
+	_, _ = io.WriteString(w, f.logs)
+	return f.logsErr
+}
+
+func (f *fakeBackend) ExecCapture(ctx context.Context, serviceName string, cmd []string) (*types.ExecResult, error) {
+	f.exec = append([]string{serviceName}, cmd...)
+	return &types.ExecResult{ExitCode: 3, Stdout: "out", Stderr: "err"}, nil
+}
+
+func containsName(names []string, name string) bool {
+	for _, n := range names {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+func newTestServer(t *testing.T, backend Backend) *httptest.Server {
+	t.Helper()
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	server := httptest.NewServer(NewServer(backend, "test", testToken, logger).Handler())
+	t.Cleanup(server.Close)
+	return server
+}
+
+func doRequest(t *testing.T, method, url, token, body string) *http.Response {
+	t.Helper()
+	req, err := http.NewRequest(method, url, strings.NewReader(body))
+	require.NoError(t, err)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = resp.Body.Close() })
+	return resp
+}
+
+func TestServerAuthentication(t *testing.T) {
+	server := newTestServer(t, &fakeBackend{})
+
+	tests := []struct {
+		name     string
+		path     string
+		token    string
+		expected int
+	}{
+		{name: "health is public", path: "/v1/health", expected: http.StatusOK},
+		{name: "missing token", path: "/v1/status", expected: http.StatusUnauthorized},
+		{name: "wrong token", path: "/v1/status", token: "guess", expected: http.StatusUnauthorized},
+		{name: "valid token", path: "/v1/status", token: testToken, expected: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := doRequest(t, http.MethodGet, server.URL+tt.path, tt.token, "")
+			assert.Equal(t, tt.expected, resp.StatusCode)
+		})
+	}
+}
+
+func TestServerStatus(t *testing.T) {
+	server := newTestServer(t, &fakeBackend{})
+
+	resp := doRequest(t, http.MethodGet, server.URL+"/v1/status?service=redis", testToken, "")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var status StatusResponse
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&status))
+	assert.Equal(t, "test", status.Project)
+	require.Len(t, status.Services, 1)
+	assert.Equal(t, "redis", status.Services[0].Name)
+}
+
+func TestServerUpAndDown(t *testing.T) {
+	backend := &fakeBackend{}
+	server := newTestServer(t, backend)
+
+	resp := doRequest(t, http.MethodPost, server.URL+"/v1/up", testToken,
+		`{"services":["postgres"],"build":true,"wait":true,"timeout":"90s"}`)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, []string{"postgres"}, backend.started)
+	assert.True(t, backend.startOpts.Build)
+	assert.False(t, backend.startOpts.Detach)
+	assert.Equal(t, "1m30s", backend.startOpts.Timeout.String())
+
+	resp = doRequest(t, http.MethodPost, server.URL+"/v1/down", testToken, `{"remove_volumes":true}`)
+	require.Equal(t, http.StatusNoContent, resp.StatusCode)
+	assert.Nil(t, backend.stopped)
+	assert.True(t, backend.stopOpts.RemoveVolumes)
+
+	resp = doRequest(t, http.MethodPost, server.URL+"/v1/up", testToken, `{"timeout":"soon"}`)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	resp = doRequest(t, http.MethodPost, server.URL+"/v1/up", testToken, `{"unknown":true}`)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	resp = doRequest(t, http.MethodGet, server.URL+"/v1/up", testToken, "")
+	assert.Equal(t, http.StatusMethodNotAllowed, resp.StatusCode)
+}
+
+func TestServerExec(t *testing.T) {
+	backend := &fakeBackend{}
+	server := newTestServer(t, backend)
+
+	resp := doRequest(t, http.MethodPost, server.URL+"/v1/exec", testToken, `{"service":"postgres","command":["psql","-c","select 1"]}`)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, []string{"postgres", "psql", "-c", "select 1"}, backend.exec)
+
+	var result ExecResponse
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&result))
+	assert.Equal(t, ExecResponse{ExitCode: 3, Stdout: "out", Stderr: "err"}, result)
+
+	resp = doRequest(t, http.MethodPost, server.URL+"/v1/exec", testToken, `{"service":"postgres"}`)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
+
+func TestServerLogs(t *testing.T) {
+	server := newTestServer(t, &fakeBackend{logs: "postgres-1  | ready\n"})
+
+	resp := doRequest(t, http.MethodGet, server.URL+"/v1/logs?service=postgres", testToken, "")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.Equal(t, "postgres-1  | ready\n", string(body))
+
+	failing := newTestServer(t, &fakeBackend{logsErr: errors.New("no such service: mysql")})
+	resp = doRequest(t, http.MethodGet, failing.URL+"/v1/logs?service=mysql", testToken, "")
+	assert.Equal(t, http.StatusBadGateway, resp.StatusCode)
+}
+
+func TestServerLogsWebSocket(t *testing.T) {
+	server := newTestServer(t, &fakeBackend{
+		logs:    "postgres-1  | starting\r\npostgres-1  | ready\npartial",
+		logsErr: errors.New("compose exited"),
+	})
+	wsURL := "ws" + strings.TrimPrefix(server.URL, "http") + "/v1/logs?follow=true"
+
+	config, err := websocket.NewConfig(wsURL, server.URL)
+	require.NoError(t, err)
+	_, err = websocket.DialConfig(config)
+	assert.Error(t, err, "dialing without a token must fail")
+
+	config.Header.Set("Authorization", "Bearer "+testToken)
+	ws, err := websocket.DialConfig(config)
+	require.NoError(t, err)
+	defer func() { _ = ws.Close() }()
+
+	var messages []string
+	for {
+		var message string
+		if err := websocket.Message.Receive(ws, &message); err != nil {
+			break
+		}
+		messages = append(messages, message)
+	}
+	assert.Equal(t, []string{
+		"postgres-1  | starting",
+		"postgres-1  | ready",
+		"partial",
+		"error: compose exited",
+	}, messages)
+}
+
+func TestState(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tmp", "daemon.json")
+	token, err := GenerateToken()
+	require.NoError(t, err)
+	assert.Len(t, token, 64)
+
+	require.NoError(t, WriteState(path, State{Addr: "127.0.0.1:8097", Token: token, PID: 42}))
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	state, err := ReadState(path)
+	require.NoError(t, err)
+	assert.Equal(t, "127.0.0.1:8097", state.Addr)
+	assert.Equal(t, token, state.Token)
+	assert.Equal(t, 42, state.PID)
+}
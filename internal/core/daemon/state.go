@@ -0,0 +1,67 @@
+package daemon
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/isaacgarza/dev-stack/internal/pkg/constants"
+)
+
+// State describes a running daemon so that clients in the project can find
+// it and authenticate without being handed the token out of band
+type State struct {
+	Addr      string    `json:"addr"`
+	Token     string    `json:"token"`
+	PID       int       `json:"pid"`
+	Project   string    `json:"project"`
+	StartedAt time.Time `json:"started_at"`
+}
+
+// StateFile returns the path of the state file of the project's daemon
+func StateFile() string {
+	return filepath.Join(constants.DevStackDir, constants.TmpDir, constants.DaemonStateFileName)
+}
+
+// GenerateToken returns a random API token
+func GenerateToken() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate token: %w", err)
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// WriteState writes state to path, readable by the current user only since
+// it holds the token
+func WriteState(path string, state State) error {
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", filepath.Dir(path), err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0600); err != nil {
+		return fmt.Errorf("failed to write daemon state: %w", err)
+	}
+	// WriteFile keeps the mode of an existing file
+	return os.Chmod(path, 0600)
+}
+
+// ReadState reads the state written by a running daemon
+func ReadState(path string) (*State, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var state State
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return &state, nil
+}
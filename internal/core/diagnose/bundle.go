@@ -0,0 +1,112 @@
+package diagnose
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strings"
+	"time"
+)
+
+// ManifestFileName lists the contents of a bundle and what was left out
+const ManifestFileName = "MANIFEST.txt"
+
+// Bundle writes redacted files into a tar.gz under a single directory,
+// bounded in size: files longer than the per-file limit are truncated, and
+// files past the total limit are left out. Both are recorded in the
+// manifest written on Close.
+type Bundle struct {
+	tar          *tar.Writer
+	gzip         *gzip.Writer
+	dir          string
+	maxFileSize  int
+	maxTotalSize int
+	total        int
+	modTime      time.Time
+	manifest     []string
+}
+
+// NewBundle creates a bundle writing to w, with its files under dir
+func NewBundle(w io.Writer, dir string, maxFileSize, maxTotalSize int) *Bundle {
+	gz := gzip.NewWriter(w)
+	return &Bundle{
+		tar:          tar.NewWriter(gz),
+		gzip:         gz,
+		dir:          dir,
+		maxFileSize:  maxFileSize,
+		maxTotalSize: maxTotalSize,
+		modTime:      time.Now(),
+	}
+}
+
+// Add writes content as name, after redacting it
+func (b *Bundle) Add(name, content string) error {
+	content = Redact(content)
+	note := ""
+	if len(content) > b.maxFileSize {
+		content = content[:b.maxFileSize] + "\n[truncated]\n"
+		note = fmt.Sprintf(" (truncated to %d bytes)", b.maxFileSize)
+	}
+	if b.total+len(content) > b.maxTotalSize {
+		b.manifest = append(b.manifest, fmt.Sprintf("%s: left out, the bundle reached %d bytes", name, b.maxTotalSize))
+		return nil
+	}
+
+	if err := b.write(name, content); err != nil {
+		return err
+	}
+	b.total += len(content)
+	b.manifest = append(b.manifest, fmt.Sprintf("%s: %d bytes%s", name, len(content), note))
+	return nil
+}
+
+// AddFile adds the file at filePath as name. Missing files are recorded as
+// such in the manifest.
+func (b *Bundle) AddFile(name, filePath string) error {
+	data, err := os.ReadFile(filePath)
+	if os.IsNotExist(err) {
+		b.manifest = append(b.manifest, fmt.Sprintf("%s: %s does not exist", name, filePath))
+		return nil
+	}
+	if err != nil {
+		b.Skip(name, err)
+		return nil
+	}
+	return b.Add(name, string(data))
+}
+
+// Skip records in the manifest that name couldn't be collected
+func (b *Bundle) Skip(name string, err error) {
+	b.manifest = append(b.manifest, fmt.Sprintf("%s: not collected: %v", name, err))
+}
+
+// Close writes the manifest and finishes the archive
+func (b *Bundle) Close() error {
+	manifest := "Collected " + b.modTime.Format(time.RFC3339) + "\n\n" + strings.Join(b.manifest, "\n") + "\n"
+	if err := b.write(ManifestFileName, manifest); err != nil {
+		return err
+	}
+	if err := b.tar.Close(); err != nil {
+		return err
+	}
+	return b.gzip.Close()
+}
+
+func (b *Bundle) write(name, content string) error {
+	header := &tar.Header{
+		Name:    path.Join(b.dir, name),
+		Mode:    0644,
+		Size:    int64(len(content)),
+		ModTime: b.modTime,
+	}
+	if err := b.tar.WriteHeader(header); err != nil {
+		return fmt.Errorf("failed to add %s to the bundle: %w", name, err)
+	}
+	if _, err := io.WriteString(b.tar, content); err != nil {
+		return fmt.Errorf("failed to add %s to the bundle: %w", name, err)
+	}
+	return nil
+}
@@ -0,0 +1,153 @@
+package docker
+
+import (
+	"bufio"
+	"context"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/filters"
+
+	"github.com/isaacgarza/dev-stack/internal/pkg/constants"
+	"github.com/isaacgarza/dev-stack/internal/pkg/types"
+)
+
+// procNetTCP prints the TCP sockets of the network namespace of a
+// container. tcp6 is missing where IPv6 is disabled, which only fails cat
+// once tcp was printed.
+var procNetTCP = []string{"cat", "/proc/net/tcp", "/proc/net/tcp6"}
+
+// tcpEstablished is the state of established sockets in /proc/net/tcp
+const tcpEstablished = "01"
+
+// Activity samples the established connections to the published TCP ports
+// of the running containers of each service of a project. Services without
+// published ports are left out. Connections from inside a container, such
+// as those of its health check, don't count.
+func (cl *ContainerLister) Activity(ctx context.Context, projectName string) ([]types.ServiceActivity, error) {
+	var result []types.ServiceActivity
+	err := withTimeout(ctx, opExec, "sampling connections", func(ctx context.Context) error {
+		var err error
+		result, err = cl.activity(ctx, projectName)
+		return err
+	})
+	return result, err
+}
+
+func (cl *ContainerLister) activity(ctx context.Context, projectName string) ([]types.ServiceActivity, error) {
+	filters := filters.NewArgs()
+	filters.Add("label", projectLabel(projectName))
+	filters.Add("status", constants.StateRunning)
+	containers, err := cl.client.api().ContainerList(ctx, container.ListOptions{Filters: filters})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list containers: %w", err)
+	}
+
+	executor := NewContainerExecutor(cl.client)
+	byService := make(map[string]*types.ServiceActivity)
+	for _, c := range containers {
+		serviceName := c.Labels[constants.ComposeServiceLabel]
+		if serviceName == "" || c.Labels[constants.ComposeOneoffLabel] == "True" {
+			continue
+		}
+		ports, addresses := publishedTCPPorts(c.Ports)
+		if len(ports) == 0 {
+			continue
+		}
+
+		activity, ok := byService[serviceName]
+		if !ok {
+			activity = &types.ServiceActivity{Service: serviceName}
+			byService[serviceName] = activity
+		}
+		activity.Ports = append(activity.Ports, addresses...)
+
+		output, err := executor.captureContainer(ctx, c.ID, procNetTCP)
+		if err != nil || output.Stdout == "" {
+			cl.client.logger.Debug("Failed to read the connections of a container", "service", serviceName, "container", c.ID, "error", err)
+			activity.Unknown = true
+			continue
+		}
+		activity.Connections += countConnections(output.Stdout, ports)
+	}
+
+	result := make([]types.ServiceActivity, 0, len(byService))
+	for _, activity := range byService {
+		slices.Sort(activity.Ports)
+		activity.Ports = slices.Compact(activity.Ports)
+		result = append(result, *activity)
+	}
+	slices.SortFunc(result, func(a, b types.ServiceActivity) int { return strings.Compare(a.Service, b.Service) })
+	return result, nil
+}
+
+// publishedTCPPorts returns the container ports published on the host and
+// the host addresses they are published on
+func publishedTCPPorts(ports []container.Port) ([]uint16, []string) {
+	var private []uint16
+	var addresses []string
+	for _, port := range ports {
+		if port.PublicPort == 0 || port.Type != "tcp" {
+			continue
+		}
+		if !slices.Contains(private, port.PrivatePort) {
+			private = append(private, port.PrivatePort)
+		}
+		addresses = append(addresses, hostAddress(port.IP, port.PublicPort))
+	}
+	return private, addresses
+}
+
+// hostAddress returns the address to listen on for a port published on ip.
+// Ports published on every interface are, for IPv4 and IPv6 alike.
+func hostAddress(ip string, port uint16) string {
+	if ip == "" || ip == "0.0.0.0" || ip == "::" {
+		ip = ""
+	}
+	return net.JoinHostPort(ip, strconv.Itoa(int(port)))
+}
+
+// countConnections counts the established connections to ports in the
+// socket tables of /proc/net/tcp and tcp6, leaving out loopback peers
+func countConnections(table string, ports []uint16) int {
+	count := 0
+	scanner := bufio.NewScanner(strings.NewReader(table))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 4 || fields[3] != tcpEstablished {
+			continue
+		}
+		_, localPort, ok := strings.Cut(fields[1], ":")
+		if !ok {
+			continue
+		}
+		port, err := strconv.ParseUint(localPort, 16, 16)
+		if err != nil || !slices.Contains(ports, uint16(port)) {
+			continue
+		}
+		remote, _, _ := strings.Cut(fields[2], ":")
+		if ip := procAddress(remote); ip == nil || ip.IsLoopback() {
+			continue
+		}
+		count++
+	}
+	return count
+}
+
+// procAddress decodes an address of /proc/net/tcp, written as hex words in
+// host byte order, as on every architecture Docker runs on
+func procAddress(value string) net.IP {
+	data, err := hex.DecodeString(value)
+	if err != nil || (len(data) != net.IPv4len && len(data) != net.IPv6len) {
+		return nil
+	}
+	for word := 0; word < len(data); word += 4 {
+		slices.Reverse(data[word : word+4])
+	}
+	return net.IP(data)
+}
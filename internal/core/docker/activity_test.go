@@ -0,0 +1,44 @@
+package docker
+
+import (
+	"testing"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCountConnections(t *testing.T) {
+	// postgres listening on 5432 (0x1538), with a client from the bridge
+	// gateway, its own health check over loopback and an outgoing
+	// connection from an ephemeral port
+	table := `  sl  local_address rem_address   st tx_queue rx_queue tr tm->when retrnsmt   uid  timeout inode
+   0: 00000000:1538 00000000:0000 0A 00000000:00000000 00:00000000 00000000   999        0 1 1
+   1: 020012AC:1538 010012AC:D2F0 01 00000000:00000000 00:00000000 00000000   999        0 2 1
+   2: 0100007F:1538 0100007F:A1B2 01 00000000:00000000 00:00000000 00000000   999        0 3 1
+   3: 020012AC:8F3A 030012AC:1F90 01 00000000:00000000 00:00000000 00000000   999        0 4 1
+   4: 020012AC:1538 040012AC:D2F2 06 00000000:00000000 00:00000000 00000000   999        0 5 1
+  sl  local_address                         remote_address                        st
+   0: 0000000000000000FFFF0000020012AC:1538 0000000000000000FFFF0000050012AC:C001 01 00000000:00000000
+   1: 00000000000000000000000001000000:1538 00000000000000000000000001000000:C002 01 00000000:00000000
+`
+	assert.Equal(t, 2, countConnections(table, []uint16{5432}))
+	assert.Equal(t, 0, countConnections(table, []uint16{6379}))
+}
+
+func TestProcAddress(t *testing.T) {
+	assert.Equal(t, "172.18.0.1", procAddress("010012AC").String())
+	assert.Equal(t, "::1", procAddress("00000000000000000000000001000000").String())
+	assert.Nil(t, procAddress("zz"))
+}
+
+func TestPublishedTCPPorts(t *testing.T) {
+	ports, addresses := publishedTCPPorts([]container.Port{
+		{IP: "0.0.0.0", PrivatePort: 5432, PublicPort: 15432, Type: "tcp"},
+		{IP: "::", PrivatePort: 5432, PublicPort: 15432, Type: "tcp"},
+		{IP: "127.0.0.1", PrivatePort: 8080, PublicPort: 8080, Type: "tcp"},
+		{PrivatePort: 9000, Type: "tcp"},
+		{IP: "0.0.0.0", PrivatePort: 53, PublicPort: 5353, Type: "udp"},
+	})
+	assert.Equal(t, []uint16{5432, 8080}, ports)
+	assert.Equal(t, []string{":15432", ":15432", "127.0.0.1:8080"}, addresses)
+}
@@ -11,13 +11,17 @@ import (
 type Client struct {
 	cli    *client.Client
 	logger *slog.Logger
+	// shared is set for the clients Shared returns, whose connection
+	// outlives them
+	shared bool
 }
 
-// NewClient creates a new Docker client instance
+// NewClient creates a new Docker client instance connected to the engine
+// ResolveEndpoint finds
 func NewClient(logger *slog.Logger) (*Client, error) {
-	cli, err := client.NewClientWithOpts(client.FromEnv, client.WithAPIVersionNegotiation())
+	cli, _, err := connect()
 	if err != nil {
-		return nil, fmt.Errorf("failed to create Docker client: %w", err)
+		return nil, err
 	}
 
 	return &Client{
@@ -26,8 +30,46 @@ func NewClient(logger *slog.Logger) (*Client, error) {
 	}, nil
 }
 
-// Close closes the Docker client connection
+// connect creates an SDK client for the engine ResolveEndpoint finds
+func connect() (*client.Client, Endpoint, error) {
+	endpoint, err := ResolveEndpoint()
+	if err != nil {
+		return nil, endpoint, fmt.Errorf("failed to create Docker client: %w", err)
+	}
+
+	opts := append([]client.Opt{client.FromEnv, client.WithAPIVersionNegotiation()}, endpoint.options()...)
+	cli, err := client.NewClientWithOpts(opts...)
+	if err != nil {
+		return nil, endpoint, fmt.Errorf("failed to create Docker client: %w", err)
+	}
+	return cli, endpoint, nil
+}
+
+// api returns the SDK client to run an operation on: the current connection
+// of the process for a shared client, in case it was replaced since the
+// client was created, and otherwise the client's own
+func (c *Client) api() *client.Client {
+	if !c.shared {
+		return c.cli
+	}
+	cli, err := sharedClient(c.logger)
+	if err != nil {
+		// The operation reports the engine being unreachable on the last
+		// connection instead
+		if c.logger != nil {
+			c.logger.Debug("Failed to reconnect to Docker", "error", err)
+		}
+		return c.cli
+	}
+	return cli
+}
+
+// Close closes the Docker client connection. The connection of a shared
+// client stays open for the others, until CloseShared.
 func (c *Client) Close() error {
+	if c.shared {
+		return nil
+	}
 	return c.cli.Close()
 }
 
@@ -4,6 +4,7 @@ import (
 	"log/slog"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -117,3 +118,43 @@ func TestClient_WithNilLogger(t *testing.T) {
 		assert.Nil(t, client.logger)
 	})
 }
+
+func TestShared(t *testing.T) {
+	t.Setenv("DOCKER_HOST", "unix:///nonexistent/first.sock")
+	t.Cleanup(func() { _ = CloseShared() })
+
+	first, err := Shared(nil)
+	require.NoError(t, err)
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	second, err := Shared(logger)
+	require.NoError(t, err)
+
+	// The clients share the connection but log to their own logger, and
+	// closing one leaves the connection open for the other
+	conn := first.api()
+	assert.Same(t, conn, second.api())
+	assert.Equal(t, logger, second.logger)
+	require.NoError(t, first.Close())
+	third, err := Shared(nil)
+	require.NoError(t, err)
+	assert.Same(t, conn, third.api())
+
+	// An engine that stops answering on the same endpoint keeps the
+	// connection, one that moved gets a new one
+	sharedConn.pinged = time.Time{}
+	assert.Same(t, conn, third.api())
+
+	t.Setenv("DOCKER_HOST", "unix:///nonexistent/second.sock")
+	assert.Same(t, conn, third.api(), "checked within pingTTL")
+	sharedConn.pinged = time.Time{}
+	moved := third.api()
+	assert.NotSame(t, conn, moved)
+	assert.Equal(t, "unix:///nonexistent/second.sock", sharedConn.endpoint.Host)
+
+	// Clients created before the move follow it
+	assert.Same(t, moved, first.api())
+	assert.Same(t, moved, second.api())
+
+	require.NoError(t, CloseShared())
+	assert.Nil(t, sharedConn.cli)
+}
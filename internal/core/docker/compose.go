@@ -0,0 +1,179 @@
+package docker
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"regexp"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/isaacgarza/dev-stack/internal/pkg/compose"
+	"github.com/isaacgarza/dev-stack/internal/pkg/constants"
+)
+
+// composeStopGrace is how long docker compose gets to wind down after an
+// interrupt before it is killed
+const composeStopGrace = 10 * time.Second
+
+// invalidProjectChars matches what docker compose strips from project names
+var invalidProjectChars = regexp.MustCompile(`[^-_a-z0-9]+`)
+
+// NormalizeProjectName returns a project name the way docker compose writes
+// it into container names and labels: lowercased, with characters other
+// than letters, digits, dashes and underscores removed
+func NormalizeProjectName(name string) string {
+	name = invalidProjectChars.ReplaceAllString(strings.ToLower(name), "")
+	return strings.TrimLeft(name, "_-")
+}
+
+// projectLabel returns the label filter matching a project's containers
+func projectLabel(projectName string) string {
+	return fmt.Sprintf("%s=%s", constants.ComposeProjectLabel, NormalizeProjectName(projectName))
+}
+
+// ComposeFiles returns the compose files of the project in the order they
+// are merged: the generated file, then dev-stack/docker-compose.override.yml,
+// the bind mounts, security profile and trust stores of the project, the
+// file labelling its resources and the workspace, ephemeral stack and CI
+// port files when they exist. Unlike a bare `docker compose`, the override file has to
+// be passed explicitly because the generated file is always named with -f.
+func ComposeFiles() []string {
+	files := []string{constants.DockerComposeFile}
+	if fileExists(constants.DockerComposeOverrideFile) {
+		files = append(files, constants.DockerComposeOverrideFile)
+	}
+	if fileExists(compose.MountsFile()) {
+		files = append(files, compose.MountsFile())
+	}
+	if fileExists(compose.SecurityFile()) {
+		files = append(files, compose.SecurityFile())
+	}
+	if fileExists(compose.TrustFile()) {
+		files = append(files, compose.TrustFile())
+	}
+	if fileExists(compose.LabelsFile()) {
+		files = append(files, compose.LabelsFile())
+	}
+	if fileExists(compose.WorkspaceFile()) {
+		files = append(files, compose.WorkspaceFile())
+	}
+	if fileExists(compose.EphemeralFile()) {
+		files = append(files, compose.EphemeralFile())
+	}
+	if fileExists(compose.CIFile()) {
+		files = append(files, compose.CIFile())
+	}
+	return files
+}
+
+// fileExists reports whether path exists
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// profileComposeFiles returns the compose files of the project with the
+// resource limits file of each active profile merged last
+func profileComposeFiles(profiles []string) []string {
+	files := ComposeFiles()
+	for _, profile := range profiles {
+		if file := compose.ProfileFile(profile); fileExists(file) {
+			files = append(files, file)
+		}
+	}
+	return files
+}
+
+// composeArgs builds the arguments of a docker compose invocation for the
+// project, with the compose files and profiles ahead of the subcommand
+func composeArgs(projectName string, profiles []string, args ...string) []string {
+	result := []string{constants.DockerComposeCmd}
+	for _, file := range profileComposeFiles(profiles) {
+		result = append(result, "-f", file)
+	}
+	result = append(result, "-p", NormalizeProjectName(projectName))
+	for _, profile := range profiles {
+		result = append(result, "--profile", profile)
+	}
+	return append(result, args...)
+}
+
+// composeCommand returns a docker command that, once ctx is cancelled, is
+// interrupted the way Ctrl+C would, so docker compose can stop what it was
+// doing cleanly. It is killed if it hasn't exited after composeStopGrace.
+func composeCommand(ctx context.Context, args ...string) *exec.Cmd {
+	cmd := exec.CommandContext(ctx, constants.DockerCmd, args...)
+	cmd.Cancel = func() error {
+		if err := cmd.Process.Signal(os.Interrupt); err != nil {
+			return cmd.Process.Kill()
+		}
+		return nil
+	}
+	cmd.WaitDelay = composeStopGrace
+	return cmd
+}
+
+// runCompose runs docker compose and returns its combined output
+func (c *Client) runCompose(ctx context.Context, projectName string, profiles []string, args ...string) ([]byte, error) {
+	cmd := composeCommand(ctx, composeArgs(projectName, profiles, args...)...)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		c.logger.Error("docker compose failed", "command", args[0], "error", err, "output", string(output))
+		if message := strings.TrimSpace(string(output)); message != "" {
+			return output, fmt.Errorf("docker compose %s failed: %w: %s", args[0], err, message)
+		}
+		return output, fmt.Errorf("docker compose %s failed: %w", args[0], err)
+	}
+	return output, nil
+}
+
+// streamCompose runs docker compose with its output connected to stdout and
+// stderr. Cancelling ctx stops it without an error.
+func (c *Client) streamCompose(ctx context.Context, projectName string, stdout, stderr io.Writer, args ...string) error {
+	cmd := composeCommand(ctx, composeArgs(projectName, nil, args...)...)
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() != nil {
+			return nil
+		}
+		return fmt.Errorf("docker compose %s failed: %w", args[0], err)
+	}
+	return nil
+}
+
+// composeServices filters serviceNames down to the services defined in the
+// project's compose files, since docker compose rejects unknown names
+func composeServices(serviceNames []string) ([]string, []string) {
+	defined := map[string]bool{}
+	for _, file := range ComposeFiles() {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			continue
+		}
+		var compose struct {
+			Services map[string]any `yaml:"services"`
+		}
+		if err := yaml.Unmarshal(data, &compose); err != nil {
+			continue
+		}
+		for name := range compose.Services {
+			defined[name] = true
+		}
+	}
+
+	var known, unknown []string
+	for _, serviceName := range serviceNames {
+		if defined[serviceName] {
+			known = append(known, serviceName)
+		} else {
+			unknown = append(unknown, serviceName)
+		}
+	}
+	return known, unknown
+}
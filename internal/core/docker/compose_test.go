@@ -0,0 +1,96 @@
+package docker
+
+import (
+	"bufio"
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/isaacgarza/dev-stack/internal/pkg/compose"
+	"github.com/isaacgarza/dev-stack/internal/pkg/constants"
+)
+
+func TestNormalizeProjectName(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"myapp", "myapp"},
+		{"MyApp", "myapp"},
+		{"my app.v2", "myappv2"},
+		{"my-app_2", "my-app_2"},
+		{"_-leading", "leading"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			assert.Equal(t, tt.expected, NormalizeProjectName(tt.input))
+		})
+	}
+
+	assert.Equal(t, constants.ComposeProjectLabel+"=myapp", projectLabel("MyApp"))
+}
+
+func TestComposeArgs(t *testing.T) {
+	t.Chdir(t.TempDir())
+	require.NoError(t, os.MkdirAll(constants.DevStackDir, 0755))
+
+	assert.Equal(t,
+		[]string{"compose", "-f", constants.DockerComposeFile, "-p", "myapp", "up", "-d"},
+		composeArgs("MyApp", nil, "up", "-d"))
+
+	require.NoError(t, os.WriteFile(constants.DockerComposeOverrideFile, []byte("services: {}\n"), 0644))
+	assert.Equal(t,
+		[]string{"compose", "-f", constants.DockerComposeFile, "-f", constants.DockerComposeOverrideFile,
+			"-p", "myapp", "--profile", "tools", "logs", "postgres"},
+		composeArgs("myapp", []string{"tools"}, "logs", "postgres"))
+
+	require.NoError(t, os.WriteFile(compose.ProfileFile("ci"), []byte("services: {}\n"), 0644))
+	assert.Equal(t,
+		[]string{"compose", "-f", constants.DockerComposeFile, "-f", constants.DockerComposeOverrideFile,
+			"-f", compose.ProfileFile("ci"), "-p", "myapp", "--profile", "tools", "--profile", "ci", "up"},
+		composeArgs("myapp", []string{"tools", "ci"}, "up"))
+}
+
+func TestComposeServices(t *testing.T) {
+	t.Chdir(t.TempDir())
+	require.NoError(t, os.MkdirAll(constants.DevStackDir, 0755))
+	require.NoError(t, os.WriteFile(constants.DockerComposeFile, []byte("services:\n  postgres:\n    image: postgres\n  redis:\n    image: redis\n"), 0644))
+	require.NoError(t, os.WriteFile(constants.DockerComposeOverrideFile, []byte("services:\n  api:\n    build: ..\n"), 0644))
+
+	known, unknown := composeServices([]string{"postgres", "localstack-s3", "api"})
+	assert.Equal(t, []string{"postgres", "api"}, known)
+	assert.Equal(t, []string{"localstack-s3"}, unknown)
+}
+
+func TestComposeCommand_InterruptsOnCancel(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the fake docker is a shell script")
+	}
+
+	dir := t.TempDir()
+	script := "#!/bin/sh\ntrap 'echo interrupted; exit 0' INT\necho ready\nwhile :; do sleep 0.05; done\n"
+	require.NoError(t, os.WriteFile(filepath.Join(dir, constants.DockerCmd), []byte(script), 0755))
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cmd := composeCommand(ctx, constants.DockerComposeCmd, "up")
+	stdout, err := cmd.StdoutPipe()
+	require.NoError(t, err)
+	require.NoError(t, cmd.Start())
+
+	lines := bufio.NewScanner(stdout)
+	require.True(t, lines.Scan())
+	assert.Equal(t, "ready", lines.Text())
+	cancel()
+
+	// Interrupted rather than killed, docker compose gets to clean up
+	require.True(t, lines.Scan())
+	assert.Equal(t, "interrupted", lines.Text())
+	_ = cmd.Wait()
+}
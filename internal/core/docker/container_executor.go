@@ -1,10 +1,16 @@
 package docker
 
 import (
+	"archive/tar"
+	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"os"
+	"path"
+	"strings"
+	"time"
 
 	"github.com/docker/docker/api/types/container"
 	"github.com/docker/docker/api/types/filters"
@@ -26,133 +32,334 @@ func NewContainerExecutor(client *Client) *ContainerExecutor {
 	}
 }
 
-// Exec executes a command in a running container
-func (ce *ContainerExecutor) Exec(ctx context.Context, projectName, serviceName string, cmd []string, options types.ExecOptions) error {
+// ExecCapture runs a non-interactive command in a running container and
+// returns its exit code and captured output, within the exec timeout
+func (ce *ContainerExecutor) ExecCapture(ctx context.Context, projectName, serviceName string, cmd []string) (*types.ExecResult, error) {
+	var result *types.ExecResult
+	err := withTimeout(ctx, opExec, execOperation(serviceName, cmd), func(ctx context.Context) error {
+		var err error
+		result, err = ce.execCapture(ctx, projectName, serviceName, cmd)
+		return err
+	})
+	return result, err
+}
+
+func (ce *ContainerExecutor) execCapture(ctx context.Context, projectName, serviceName string, cmd []string) (*types.ExecResult, error) {
 	containerID, err := ce.findServiceContainer(ctx, projectName, serviceName)
 	if err != nil {
-		return err
+		return nil, err
 	}
+	return ce.captureContainer(ctx, containerID, cmd)
+}
 
-	config := container.ExecOptions{
+// captureContainer runs a non-interactive command in a container and
+// returns its exit code and captured output
+func (ce *ContainerExecutor) captureContainer(ctx context.Context, containerID string, cmd []string) (*types.ExecResult, error) {
+	exec, err := ce.client.api().ContainerExecCreate(ctx, containerID, container.ExecOptions{
 		Cmd:          cmd,
 		AttachStdout: true,
 		AttachStderr: true,
-		Tty:          options.TTY,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create exec instance: %w", err)
 	}
 
-	if options.Interactive {
-		config.AttachStdin = true
+	resp, err := ce.client.api().ContainerExecAttach(ctx, exec.ID, container.ExecAttachOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to attach to exec instance: %w", err)
 	}
+	defer resp.Close()
 
-	if options.User != "" {
-		config.User = options.User
+	var stdout, stderr bytes.Buffer
+	if _, err := stdcopy.StdCopy(&stdout, &stderr, resp.Reader); err != nil {
+		return nil, fmt.Errorf("failed to read exec output: %w", err)
 	}
 
-	if options.WorkingDir != "" {
-		config.WorkingDir = options.WorkingDir
+	inspect, err := ce.client.api().ContainerExecInspect(ctx, exec.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to inspect exec instance: %w", err)
 	}
 
-	if len(options.Env) > 0 {
-		config.Env = options.Env
+	return &types.ExecResult{
+		ExitCode: inspect.ExitCode,
+		Stdout:   stdout.String(),
+		Stderr:   stderr.String(),
+	}, nil
+}
+
+// ExecStream runs a non-interactive command in a running container and
+// streams its stdout to w. Stderr is collected and included in the error if
+// the command exits non-zero. The command is bounded by the exec timeout.
+func (ce *ContainerExecutor) ExecStream(ctx context.Context, projectName, serviceName string, cmd []string, w io.Writer, options types.ExecOptions) error {
+	return withTimeout(ctx, opExec, execOperation(serviceName, cmd), func(ctx context.Context) error {
+		containerID, err := ce.findServiceContainer(ctx, projectName, serviceName)
+		if err != nil {
+			return err
+		}
+		return ce.execAttached(ctx, containerID, cmd, nil, w, options)
+	})
+}
+
+// ExecStdin runs a non-interactive command in a running container, feeding
+// r to its stdin, within the exec timeout
+func (ce *ContainerExecutor) ExecStdin(ctx context.Context, projectName, serviceName string, cmd []string, r io.Reader, options types.ExecOptions) error {
+	return withTimeout(ctx, opExec, execOperation(serviceName, cmd), func(ctx context.Context) error {
+		containerID, err := ce.findServiceContainer(ctx, projectName, serviceName)
+		if err != nil {
+			return err
+		}
+		return ce.execAttached(ctx, containerID, cmd, r, io.Discard, options)
+	})
+}
+
+// execOperation describes a command run in a service container for errors
+func execOperation(serviceName string, cmd []string) string {
+	if len(cmd) == 0 {
+		return "exec in " + serviceName
 	}
+	return fmt.Sprintf("%s in %s", cmd[0], serviceName)
+}
 
-	exec, err := ce.client.cli.ContainerExecCreate(ctx, containerID, config)
+// execAttached runs cmd in a container, optionally feeding stdin, and
+// streams stdout to w
+func (ce *ContainerExecutor) execAttached(ctx context.Context, containerID string, cmd []string, stdin io.Reader, w io.Writer, options types.ExecOptions) error {
+	exec, err := ce.client.api().ContainerExecCreate(ctx, containerID, container.ExecOptions{
+		Cmd:          cmd,
+		User:         options.User,
+		WorkingDir:   options.WorkingDir,
+		Env:          options.Env,
+		AttachStdin:  stdin != nil,
+		AttachStdout: true,
+		AttachStderr: true,
+	})
 	if err != nil {
 		return fmt.Errorf("failed to create exec instance: %w", err)
 	}
 
-	resp, err := ce.client.cli.ContainerExecAttach(ctx, exec.ID, container.ExecAttachOptions{
-		Tty: options.TTY,
-	})
+	resp, err := ce.client.api().ContainerExecAttach(ctx, exec.ID, container.ExecAttachOptions{})
 	if err != nil {
 		return fmt.Errorf("failed to attach to exec instance: %w", err)
 	}
 	defer resp.Close()
 
-	if options.TTY {
-		if _, err := io.Copy(os.Stdout, resp.Reader); err != nil {
-			ce.client.logger.Error("Failed to copy output", "error", err)
-		}
+	stdinErr := make(chan error, 1)
+	if stdin != nil {
+		go func() {
+			_, err := io.Copy(resp.Conn, stdin)
+			if closeErr := resp.CloseWrite(); err == nil {
+				err = closeErr
+			}
+			stdinErr <- err
+		}()
 	} else {
-		if _, err := stdcopy.StdCopy(os.Stdout, os.Stderr, resp.Reader); err != nil {
-			ce.client.logger.Error("Failed to copy output", "error", err)
-		}
+		stdinErr <- nil
+	}
+
+	var stderr bytes.Buffer
+	if _, err := stdcopy.StdCopy(w, &stderr, resp.Reader); err != nil {
+		return fmt.Errorf("failed to stream exec output: %w", err)
+	}
+
+	inspect, err := ce.client.api().ContainerExecInspect(ctx, exec.ID)
+	if err != nil {
+		return fmt.Errorf("failed to inspect exec instance: %w", err)
+	}
+	if inspect.ExitCode != 0 {
+		return fmt.Errorf("%s exited with code %d: %s", cmd[0], inspect.ExitCode, strings.TrimSpace(stderr.String()))
+	}
+	if err := <-stdinErr; err != nil {
+		return fmt.Errorf("failed to send input to %s: %w", cmd[0], err)
 	}
 
 	return nil
 }
 
-// Logs retrieves logs from containers
-func (ce *ContainerExecutor) Logs(ctx context.Context, projectName string, serviceNames []string, options types.LogOptions) error {
-	filters := filters.NewArgs()
-	filters.Add("label", fmt.Sprintf("%s=%s", constants.ComposeProjectLabel, projectName))
-
-	containers, err := ce.client.cli.ContainerList(ctx, container.ListOptions{
-		All:     true,
-		Filters: filters,
-	})
+// CopyFrom streams a single file out of a service's container to w
+func (ce *ContainerExecutor) CopyFrom(ctx context.Context, projectName, serviceName, path string, w io.Writer) error {
+	containerID, err := ce.findServiceContainer(ctx, projectName, serviceName)
 	if err != nil {
-		return fmt.Errorf("failed to list containers: %w", err)
+		return err
 	}
 
-	for _, c := range containers {
-		serviceName := c.Labels[constants.ComposeServiceLabel]
+	reader, _, err := ce.client.api().CopyFromContainer(ctx, containerID, path)
+	if err != nil {
+		return fmt.Errorf("failed to copy %s from %s: %w", path, serviceName, err)
+	}
+	defer func() { _ = reader.Close() }()
 
-		if len(serviceNames) > 0 && !contains(serviceNames, serviceName) {
-			continue
+	// The daemon wraps the copied path in a tar archive
+	archive := tar.NewReader(reader)
+	for {
+		header, err := archive.Next()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return fmt.Errorf("%s is not a regular file in %s", path, serviceName)
+			}
+			return fmt.Errorf("failed to read archive of %s: %w", path, err)
 		}
-
-		logOptions := container.LogsOptions{
-			ShowStdout: true,
-			ShowStderr: true,
-			Follow:     options.Follow,
-			Timestamps: options.Timestamps,
+		if header.Typeflag == tar.TypeReg {
+			if _, err := io.Copy(w, archive); err != nil {
+				return fmt.Errorf("failed to copy %s: %w", path, err)
+			}
+			return nil
 		}
+	}
+}
 
-		if options.Since != "" {
-			logOptions.Since = options.Since
-		}
+// CopyArchiveFrom streams path out of a service's container as the tar
+// archive the daemon produces, with path's base name as its top-level entry
+func (ce *ContainerExecutor) CopyArchiveFrom(ctx context.Context, projectName, serviceName, path string, w io.Writer) error {
+	containerID, err := ce.findServiceContainer(ctx, projectName, serviceName)
+	if err != nil {
+		return err
+	}
 
-		if options.Tail != "" {
-			logOptions.Tail = options.Tail
-		}
+	reader, _, err := ce.client.api().CopyFromContainer(ctx, containerID, path)
+	if err != nil {
+		return fmt.Errorf("failed to copy %s from %s: %w", path, serviceName, err)
+	}
+	defer func() { _ = reader.Close() }()
 
-		logs, err := ce.client.cli.ContainerLogs(ctx, c.ID, logOptions)
-		if err != nil {
-			ce.client.logger.Error("Failed to get logs", "container", c.ID, "service", serviceName, "error", err)
-			continue
+	if _, err := io.Copy(w, reader); err != nil {
+		return fmt.Errorf("failed to copy archive of %s: %w", path, err)
+	}
+	return nil
+}
+
+// CopyArchiveTo extracts the tar archive r into dir inside a service's
+// container. Like CopyTo, it works on stopped containers.
+func (ce *ContainerExecutor) CopyArchiveTo(ctx context.Context, projectName, serviceName, dir string, r io.Reader) error {
+	containerID, err := ce.findContainer(ctx, projectName, serviceName, true)
+	if err != nil {
+		return err
+	}
+	return ce.copyArchiveToContainer(ctx, containerID, dir, r)
+}
+
+func (ce *ContainerExecutor) copyArchiveToContainer(ctx context.Context, containerID, dir string, r io.Reader) error {
+	if err := ce.client.api().CopyToContainer(ctx, containerID, dir, r, container.CopyToContainerOptions{}); err != nil {
+		return fmt.Errorf("failed to extract archive into %s: %w", dir, err)
+	}
+	return nil
+}
+
+// CopyTo writes the contents of r to target inside a service's container.
+// The container may be stopped, which lets callers replace data files the
+// service would otherwise overwrite on shutdown.
+func (ce *ContainerExecutor) CopyTo(ctx context.Context, projectName, serviceName, target string, r io.Reader, size int64) error {
+	containerID, err := ce.findContainer(ctx, projectName, serviceName, true)
+	if err != nil {
+		return err
+	}
+	return ce.copyToContainer(ctx, containerID, target, r, size)
+}
+
+// copyToContainer wraps r in a single-file tar archive, which is what the
+// daemon expects, and extracts it next to target
+func (ce *ContainerExecutor) copyToContainer(ctx context.Context, containerID, target string, r io.Reader, size int64) error {
+	archive, writer := io.Pipe()
+	go func() {
+		tw := tar.NewWriter(writer)
+		err := tw.WriteHeader(&tar.Header{
+			Name:    path.Base(target),
+			Mode:    0644,
+			Size:    size,
+			ModTime: time.Now(),
+		})
+		if err == nil {
+			_, err = io.Copy(tw, r)
 		}
+		if err == nil {
+			err = tw.Close()
+		}
+		_ = writer.CloseWithError(err)
+	}()
 
-		go func(serviceName string, logs io.ReadCloser) {
-			defer func() {
-				if closeErr := logs.Close(); closeErr != nil {
-					ce.client.logger.Error("Failed to close logs", "error", closeErr)
-				}
-			}()
-			if options.Follow {
-				fmt.Printf("==> Following logs for %s <==\n", serviceName)
-			}
-			if _, err := stdcopy.StdCopy(os.Stdout, os.Stderr, logs); err != nil {
-				ce.client.logger.Error("Failed to copy logs", "error", err)
-			}
-		}(serviceName, logs)
+	if err := ce.client.api().CopyToContainer(ctx, containerID, path.Dir(target), archive, container.CopyToContainerOptions{}); err != nil {
+		_ = archive.Close()
+		return fmt.Errorf("failed to copy to %s: %w", target, err)
 	}
+	return nil
+}
 
+// Logs prints the logs of the specified services, or of the whole project,
+// with docker compose logs. Following stops when ctx is cancelled.
+func (ce *ContainerExecutor) Logs(ctx context.Context, projectName string, serviceNames []string, options types.LogOptions) error {
+	return ce.StreamLogs(ctx, projectName, serviceNames, options, os.Stdout, os.Stderr)
+}
+
+// StreamLogs is Logs with the output of docker compose written to stdout
+// and stderr instead of the terminal
+func (ce *ContainerExecutor) StreamLogs(ctx context.Context, projectName string, serviceNames []string, options types.LogOptions, stdout, stderr io.Writer) error {
+	args := []string{"logs"}
 	if options.Follow {
-		select {}
+		args = append(args, "--follow")
+	}
+	if options.Timestamps {
+		args = append(args, "--timestamps")
+	}
+	if options.Tail != "" {
+		args = append(args, "--tail", options.Tail)
+	}
+	if options.Since != "" {
+		args = append(args, "--since", options.Since)
+	}
+	if options.NoColor {
+		args = append(args, "--no-color")
+	}
+	if options.NoPrefix {
+		args = append(args, "--no-log-prefix")
 	}
 
-	return nil
+	if len(serviceNames) > 0 {
+		known, unknown := composeServices(serviceNames)
+		if len(known) == 0 {
+			return fmt.Errorf("no such service: %s", strings.Join(unknown, ", "))
+		}
+		args = append(args, known...)
+	}
+
+	return ce.client.streamCompose(ctx, projectName, stdout, stderr, args...)
+}
+
+// Env returns the environment the running primary container of a service
+// was created with
+func (ce *ContainerExecutor) Env(ctx context.Context, projectName, serviceName string) (map[string]string, error) {
+	containerID, err := ce.findServiceContainer(ctx, projectName, serviceName)
+	if err != nil {
+		return nil, err
+	}
+
+	inspect, err := ce.client.api().ContainerInspect(ctx, containerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to inspect container: %w", err)
+	}
+
+	env := make(map[string]string)
+	if inspect.Config != nil {
+		for _, entry := range inspect.Config.Env {
+			if key, value, ok := strings.Cut(entry, "="); ok {
+				env[key] = value
+			}
+		}
+	}
+	return env, nil
 }
 
-// findServiceContainer finds a running container for a specific service
+// findServiceContainer finds the running primary container for a specific service
 func (ce *ContainerExecutor) findServiceContainer(ctx context.Context, projectName, serviceName string) (string, error) {
+	return ce.findContainer(ctx, projectName, serviceName, false)
+}
+
+// findContainer finds the primary container for a service, optionally
+// including stopped containers
+func (ce *ContainerExecutor) findContainer(ctx context.Context, projectName, serviceName string, all bool) (string, error) {
 	filters := filters.NewArgs()
-	filters.Add("label", fmt.Sprintf("%s=%s", constants.ComposeProjectLabel, projectName))
+	filters.Add("label", projectLabel(projectName))
 	filters.Add("label", fmt.Sprintf("%s=%s", constants.ComposeServiceLabel, serviceName))
 
-	containers, err := ce.client.cli.ContainerList(ctx, container.ListOptions{
-		All:     false,
+	containers, err := ce.client.api().ContainerList(ctx, container.ListOptions{
+		All:     all,
 		Filters: filters,
 	})
 	if err != nil {
@@ -160,12 +367,19 @@ func (ce *ContainerExecutor) findServiceContainer(ctx context.Context, projectNa
 	}
 
 	if len(containers) == 0 {
+		if all {
+			return "", fmt.Errorf("no container found for service %s", serviceName)
+		}
 		return "", fmt.Errorf("no running container found for service %s", serviceName)
 	}
 
-	if len(containers) > 1 {
-		return "", fmt.Errorf("multiple containers found for service %s", serviceName)
+	// Scaled services run several replicas; commands target the primary
+	primary := containers[0]
+	for _, c := range containers[1:] {
+		if ContainerNumber(c.Labels) < ContainerNumber(primary.Labels) {
+			primary = c
+		}
 	}
 
-	return containers[0].ID, nil
+	return primary.ID, nil
 }
@@ -4,7 +4,8 @@ import (
 	"context"
 	"fmt"
 	"os"
-	"os/exec"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
 
@@ -27,11 +28,24 @@ func NewContainerLifecycle(client *Client) *ContainerLifecycle {
 	}
 }
 
-// Start starts containers for the specified services
+// Start creates and starts the specified services with docker compose up,
+// within the start timeout
 func (cl *ContainerLifecycle) Start(ctx context.Context, projectName string, serviceNames []string, options types.StartOptions) error {
-	cl.client.logger.Info("Starting services", "project", projectName, "services", serviceNames)
+	return withTimeout(ctx, opStart, "docker compose up", func(ctx context.Context) error {
+		return cl.start(ctx, projectName, serviceNames, options)
+	})
+}
+
+// start runs docker compose up for the whole project, or starts the named
+// services in dependency order
+func (cl *ContainerLifecycle) start(ctx context.Context, projectName string, serviceNames []string, options types.StartOptions) error {
+	if len(serviceNames) > 0 {
+		return cl.startWaves(ctx, projectName, serviceNames, options)
+	}
 
-	args := []string{"compose", "-f", constants.DockerComposeFile, "-p", projectName, "up", "-d"}
+	cl.client.logger.Info("Starting services", "project", projectName)
+
+	args := []string{"up", "-d"}
 
 	if options.Build {
 		args = append(args, "--build")
@@ -41,37 +55,97 @@ func (cl *ContainerLifecycle) Start(ctx context.Context, projectName string, ser
 		args = append(args, "--force-recreate")
 	}
 
-	args = append(args, serviceNames...)
+	if options.NoDeps {
+		args = append(args, "--no-deps")
+	}
 
-	cmd := exec.CommandContext(ctx, "docker", args...)
+	if options.RenewAnonVolumes {
+		args = append(args, "--renew-anon-volumes")
+	}
+
+	if options.Pull != "" {
+		args = append(args, "--pull", options.Pull)
+	}
+
+	cmd := composeCommand(ctx, composeArgs(projectName, options.Profiles, args...)...)
 	output, err := cmd.CombinedOutput()
 
 	if err != nil {
 		cl.client.logger.Error("Failed to start services", "error", err, "output", string(output))
+		cl.reportFailure(string(output))
+		return fmt.Errorf("failed to start services: %w", composeFailure(err, output))
+	}
+
+	cl.client.logger.Info("Services started successfully", "project", projectName)
+	return nil
+}
 
-		if len(output) > 0 {
-			fmt.Printf("\n🔍 Docker output:\n%s\n", string(output))
+// Stop stops the specified services with docker compose, removing their
+// containers when options.Remove is set. Stopping and removing the whole
+// project runs docker compose down, which also removes the project network
+// and containers of services no longer in the compose file. Stopping is
+// bounded by the stop timeout.
+func (cl *ContainerLifecycle) Stop(ctx context.Context, projectName string, serviceNames []string, options types.StopOptions) error {
+	return withTimeout(ctx, opStop, "stopping services", func(ctx context.Context) error {
+		return cl.stop(ctx, projectName, serviceNames, options)
+	})
+}
+
+func (cl *ContainerLifecycle) stop(ctx context.Context, projectName string, serviceNames []string, options types.StopOptions) error {
+	cl.client.logger.Info("Stopping services", "project", projectName, "services", serviceNames)
+
+	// Without a compose file there is nothing for docker compose to go on
+	if _, err := os.Stat(constants.DockerComposeFile); err != nil {
+		return cl.stopContainers(ctx, projectName, serviceNames, options)
+	}
+
+	timeout := strconv.Itoa(options.Timeout)
+
+	if len(serviceNames) == 0 && options.Remove {
+		args := []string{"down", "--timeout", timeout, "--remove-orphans"}
+		if options.RemoveVolumes {
+			args = append(args, "--volumes")
 		}
+		_, err := cl.client.runCompose(ctx, projectName, nil, args...)
+		return err
+	}
 
-		if saveErr := cl.saveErrorLogs(string(output)); saveErr != nil {
-			cl.client.logger.Error("Failed to save error logs", "error", saveErr)
+	if len(serviceNames) > 0 {
+		known, unknown := composeServices(serviceNames)
+		if len(unknown) > 0 {
+			cl.client.logger.Info("Skipping services not in the compose file", "services", unknown)
+		}
+		if len(known) == 0 {
+			return nil
 		}
+		serviceNames = known
+	}
+
+	args := append([]string{"stop", "--timeout", timeout}, serviceNames...)
+	if _, err := cl.client.runCompose(ctx, projectName, nil, args...); err != nil {
+		return err
+	}
 
-		return fmt.Errorf("failed to start services: %w", err)
+	if options.Remove {
+		args := []string{"rm", "--force"}
+		if options.RemoveVolumes {
+			args = append(args, "--volumes")
+		}
+		if _, err := cl.client.runCompose(ctx, projectName, nil, append(args, serviceNames...)...); err != nil {
+			return err
+		}
 	}
 
-	cl.client.logger.Info("Services started successfully", "services", serviceNames)
 	return nil
 }
 
-// Stop stops containers for the specified services
-func (cl *ContainerLifecycle) Stop(ctx context.Context, projectName string, serviceNames []string, options types.StopOptions) error {
-	cl.client.logger.Info("Stopping services", "project", projectName, "services", serviceNames)
-
+// stopContainers stops and removes a project's containers through the
+// Docker API, for projects whose compose file is gone
+func (cl *ContainerLifecycle) stopContainers(ctx context.Context, projectName string, serviceNames []string, options types.StopOptions) error {
 	filters := filters.NewArgs()
-	filters.Add("label", fmt.Sprintf("%s=%s", constants.ComposeProjectLabel, projectName))
+	filters.Add("label", projectLabel(projectName))
 
-	containers, err := cl.client.cli.ContainerList(ctx, container.ListOptions{
+	containers, err := cl.client.api().ContainerList(ctx, container.ListOptions{
 		All:     true,
 		Filters: filters,
 	})
@@ -88,7 +162,7 @@ func (cl *ContainerLifecycle) Stop(ctx context.Context, projectName string, serv
 
 		if c.State == constants.StateRunning {
 			timeoutSecs := options.Timeout
-			if err := cl.client.cli.ContainerStop(ctx, c.ID, container.StopOptions{
+			if err := cl.client.api().ContainerStop(ctx, c.ID, container.StopOptions{
 				Timeout: &timeoutSecs,
 			}); err != nil {
 				cl.client.logger.Error("Failed to stop container", "container", c.ID, "service", serviceName, "error", err)
@@ -98,7 +172,7 @@ func (cl *ContainerLifecycle) Stop(ctx context.Context, projectName string, serv
 		}
 
 		if options.Remove {
-			if err := cl.client.cli.ContainerRemove(ctx, c.ID, container.RemoveOptions{
+			if err := cl.client.api().ContainerRemove(ctx, c.ID, container.RemoveOptions{
 				RemoveVolumes: options.RemoveVolumes,
 				Force:         true,
 			}); err != nil {
@@ -112,6 +186,82 @@ func (cl *ContainerLifecycle) Stop(ctx context.Context, projectName string, serv
 	return nil
 }
 
+// Restart restarts the containers of the specified services in place with
+// docker compose restart, keeping their filesystem. Restarting is bounded by
+// the start timeout.
+func (cl *ContainerLifecycle) Restart(ctx context.Context, projectName string, serviceNames []string, timeout int) error {
+	cl.client.logger.Info("Restarting services", "project", projectName, "services", serviceNames)
+
+	args := append([]string{"restart", "--timeout", strconv.Itoa(timeout)}, serviceNames...)
+	return withTimeout(ctx, opStart, "docker compose restart", func(ctx context.Context) error {
+		if _, err := cl.client.runCompose(ctx, projectName, nil, args...); err != nil {
+			return fmt.Errorf("failed to restart %s: %w", strings.Join(serviceNames, ", "), err)
+		}
+		return nil
+	})
+}
+
+// Build builds the images of the specified services, or of every service
+// that builds one, reporting the build steps as they run
+func (cl *ContainerLifecycle) Build(ctx context.Context, projectName string, serviceNames []string, options types.BuildOptions) error {
+	cl.client.logger.Info("Building images", "project", projectName, "services", serviceNames)
+
+	// A plain log has a line per build step to report
+	args := []string{"--progress", "plain", "build"}
+	if options.NoCache {
+		args = append(args, "--no-cache")
+	}
+	if options.Pull {
+		args = append(args, "--pull")
+	}
+	args = append(args, serviceNames...)
+
+	return withTimeout(ctx, opStart, "docker compose build", func(ctx context.Context) error {
+		log := &buildLog{progress: progressOf(options.Progress)}
+		cmd := composeCommand(ctx, composeArgs(projectName, options.Profiles, args...)...)
+		cmd.Stdout, cmd.Stderr = log, log
+		err := cmd.Run()
+		log.finish(err)
+		if err != nil {
+			output := log.output.String()
+			cl.client.logger.Error("Failed to build images", "error", err, "output", output)
+			cl.reportFailure(output)
+			return fmt.Errorf("failed to build images: %w", err)
+		}
+		cl.client.logger.Info("Images built successfully", "project", projectName)
+		return nil
+	})
+}
+
+// reportFailure prints the output of a failed docker compose run and saves
+// it to a log file
+func (cl *ContainerLifecycle) reportFailure(output string) {
+	if len(output) > 0 {
+		fmt.Printf("\n🔍 Docker output:\n%s\n", output)
+	}
+	if err := cl.saveErrorLogs(output); err != nil {
+		cl.client.logger.Error("Failed to save error logs", "error", err)
+	}
+}
+
+// saveFailureLogs saves the docker compose output of each service that
+// failed to start to a log file
+func (cl *ContainerLifecycle) saveFailureLogs(outputs map[string]string) {
+	names := make([]string, 0, len(outputs))
+	for name := range outputs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var content strings.Builder
+	for _, name := range names {
+		fmt.Fprintf(&content, "--- %s ---\n%s\n", name, outputs[name])
+	}
+	if err := cl.saveErrorLogs(content.String()); err != nil {
+		cl.client.logger.Error("Failed to save error logs", "error", err)
+	}
+}
+
 // saveErrorLogs saves error output to a log file
 func (cl *ContainerLifecycle) saveErrorLogs(output string) error {
 	logsDir := fmt.Sprintf("%s/%s", constants.DevStackDir, constants.LogsDir)
@@ -132,6 +282,6 @@ func (cl *ContainerLifecycle) saveErrorLogs(output string) error {
 		return fmt.Errorf("failed to write error log: %w", err)
 	}
 
-	fmt.Printf("📝 Error details saved to: %s\n", logFile)
+	cl.client.logger.Info("Error details saved", "path", logFile)
 	return nil
 }
@@ -29,10 +29,10 @@ func (cl *ContainerLister) List(ctx context.Context, projectName string, service
 	filters := filters.NewArgs()
 
 	if projectName != "" {
-		filters.Add("label", fmt.Sprintf("%s=%s", constants.ComposeProjectLabel, projectName))
+		filters.Add("label", projectLabel(projectName))
 	}
 
-	containers, err := cl.client.cli.ContainerList(ctx, container.ListOptions{
+	containers, err := cl.client.api().ContainerList(ctx, container.ListOptions{
 		All:     true,
 		Filters: filters,
 	})
@@ -41,6 +41,8 @@ func (cl *ContainerLister) List(ctx context.Context, projectName string, service
 	}
 
 	var services []types.ServiceStatus
+	var running []container.Summary
+	var runningIndex []int
 	for _, c := range containers {
 		serviceName := c.Labels[constants.ComposeServiceLabel]
 
@@ -58,13 +60,11 @@ func (cl *ContainerLister) List(ctx context.Context, projectName string, service
 		if c.State == constants.StateRunning {
 			status.StartedAt = &status.CreatedAt
 		}
+		cl.inspectRestarts(ctx, c, &status)
 
 		if c.State == constants.StateRunning {
-			stats, err := cl.getContainerStats(ctx, c.ID)
-			if err == nil {
-				status.CPUUsage = stats.CPUUsage
-				status.Memory = stats.Memory
-			}
+			running = append(running, c)
+			runningIndex = append(runningIndex, len(services))
 		}
 
 		for _, port := range c.Ports {
@@ -82,26 +82,33 @@ func (cl *ContainerLister) List(ctx context.Context, projectName string, service
 		services = append(services, status)
 	}
 
+	// Sampling takes the daemon about a second per container, so the
+	// running ones are sampled together. A status without a sample shows no
+	// usage.
+	for i, result := range cl.sampleStats(ctx, running) {
+		if result.err == nil {
+			services[runningIndex[i]].CPUUsage = result.stats.CPUPercent
+			services[runningIndex[i]].Memory = result.stats.Memory
+		}
+	}
+
 	return services, nil
 }
 
-// getContainerStats retrieves container statistics
-func (cl *ContainerLister) getContainerStats(ctx context.Context, containerID string) (*ContainerStats, error) {
-	stats, err := cl.client.cli.ContainerStats(ctx, containerID, false)
-	if err != nil {
-		return nil, err
+// inspectRestarts fills in the restart count and last exit code of a
+// container, which only inspect reports, along with when it last started.
+// A container that can't be inspected keeps its listed status.
+func (cl *ContainerLister) inspectRestarts(ctx context.Context, c container.Summary, status *types.ServiceStatus) {
+	info, err := cl.client.api().ContainerInspect(ctx, c.ID)
+	if err != nil || info.ContainerJSONBase == nil || info.State == nil {
+		return
+	}
+	status.RestartCount = info.RestartCount
+	status.ExitCode = info.State.ExitCode
+	if c.State != constants.StateRunning {
+		return
+	}
+	if startedAt, err := time.Parse(time.RFC3339Nano, info.State.StartedAt); err == nil {
+		status.StartedAt = &startedAt
 	}
-	defer func() {
-		if closeErr := stats.Body.Close(); closeErr != nil {
-			cl.client.logger.Error("Failed to close stats body", "error", closeErr)
-		}
-	}()
-
-	return &ContainerStats{
-		CPUUsage: 0.0,
-		Memory: types.MemoryUsage{
-			Used:  0,
-			Limit: 0,
-		},
-	}, nil
 }
@@ -0,0 +1,272 @@
+package docker
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/api/types/mount"
+	"github.com/docker/docker/api/types/network"
+
+	"github.com/isaacgarza/dev-stack/internal/pkg/compose"
+	"github.com/isaacgarza/dev-stack/internal/pkg/constants"
+	"github.com/isaacgarza/dev-stack/internal/pkg/types"
+)
+
+// ContainerScaler adds and removes replicas of compose services. The
+// generated compose file pins container names and host ports, and replicas
+// made by `docker compose --scale` would share the named volumes of a
+// stateful service, so replicas are cloned from the service's primary
+// container instead. Replicas keep the compose labels, join the same
+// networks under the service alias and get their own named volumes; host
+// port bindings stay with the primary container.
+type ContainerScaler struct {
+	client *Client
+}
+
+// replica is a container belonging to a scaled service
+type replica struct {
+	id      string
+	number  int
+	running bool
+}
+
+// NewContainerScaler creates a new container scaler
+func NewContainerScaler(client *Client) *ContainerScaler {
+	return &ContainerScaler{
+		client: client,
+	}
+}
+
+// Scale brings the number of containers for a service to replicas. The
+// service must already have a running primary container to clone from.
+func (cs *ContainerScaler) Scale(ctx context.Context, projectName, serviceName string, replicas int, options types.ScaleOptions) error {
+	if replicas < 0 {
+		return fmt.Errorf("replica count cannot be negative")
+	}
+
+	existing, err := cs.replicas(ctx, projectName, serviceName)
+	if err != nil {
+		return err
+	}
+	if len(existing) == 0 {
+		return fmt.Errorf("no container found for service %s", serviceName)
+	}
+
+	cs.client.logger.Info("Scaling service", "project", projectName, "service", serviceName, "from", len(existing), "to", replicas)
+
+	// Remove the highest numbered replicas first so the primary goes last
+	for i := len(existing) - 1; i >= replicas; i-- {
+		if err := cs.remove(ctx, existing[i], options); err != nil {
+			return fmt.Errorf("failed to remove %s replica %d: %w", serviceName, existing[i].number, err)
+		}
+	}
+
+	if replicas == 0 {
+		return nil
+	}
+
+	primary, err := cs.client.api().ContainerInspect(ctx, existing[0].id)
+	if err != nil {
+		return fmt.Errorf("failed to inspect %s: %w", serviceName, err)
+	}
+
+	// Bring back stopped replicas that are kept, recreating them from the
+	// primary unless asked not to
+	for _, r := range existing[1:min(replicas, len(existing))] {
+		if r.running {
+			continue
+		}
+		if options.NoRecreate {
+			if err := cs.client.api().ContainerStart(ctx, r.id, container.StartOptions{}); err != nil {
+				return fmt.Errorf("failed to start %s replica %d: %w", serviceName, r.number, err)
+			}
+			continue
+		}
+		if err := cs.remove(ctx, r, options); err != nil {
+			return fmt.Errorf("failed to recreate %s replica %d: %w", serviceName, r.number, err)
+		}
+		if err := cs.clone(ctx, primary, projectName, serviceName, r.number); err != nil {
+			return fmt.Errorf("failed to recreate %s replica %d: %w", serviceName, r.number, err)
+		}
+	}
+
+	if replicas <= len(existing) {
+		return nil
+	}
+
+	for _, number := range nextReplicaNumbers(existing, replicas-len(existing)) {
+		if err := cs.clone(ctx, primary, projectName, serviceName, number); err != nil {
+			return fmt.Errorf("failed to create %s replica %d: %w", serviceName, number, err)
+		}
+	}
+
+	return nil
+}
+
+// replicas returns the containers of a service ordered by container number
+func (cs *ContainerScaler) replicas(ctx context.Context, projectName, serviceName string) ([]replica, error) {
+	filters := filters.NewArgs()
+	filters.Add("label", projectLabel(projectName))
+	filters.Add("label", fmt.Sprintf("%s=%s", constants.ComposeServiceLabel, serviceName))
+
+	containers, err := cs.client.api().ContainerList(ctx, container.ListOptions{
+		All:     true,
+		Filters: filters,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list containers: %w", err)
+	}
+
+	result := make([]replica, 0, len(containers))
+	for _, c := range containers {
+		result = append(result, replica{
+			id:      c.ID,
+			number:  ContainerNumber(c.Labels),
+			running: c.State == constants.StateRunning,
+		})
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].number < result[j].number })
+	return result, nil
+}
+
+// remove stops and deletes a replica, keeping its named volumes so data
+// survives scaling back up
+func (cs *ContainerScaler) remove(ctx context.Context, r replica, options types.ScaleOptions) error {
+	if r.running {
+		timeout := int(options.Timeout.Seconds())
+		if err := cs.client.api().ContainerStop(ctx, r.id, container.StopOptions{Timeout: &timeout}); err != nil {
+			return err
+		}
+	}
+	if err := cs.client.api().ContainerRemove(ctx, r.id, container.RemoveOptions{Force: true}); err != nil {
+		return err
+	}
+	cs.client.logger.Info("Removed replica", "container", r.id[:12], "number", r.number)
+	return nil
+}
+
+// clone creates and starts a numbered replica from the primary container
+func (cs *ContainerScaler) clone(ctx context.Context, primary container.InspectResponse, projectName, serviceName string, number int) error {
+	config := *primary.Config
+	config.Hostname = ""
+	config.Labels = make(map[string]string, len(primary.Config.Labels))
+	for key, value := range primary.Config.Labels {
+		config.Labels[key] = value
+	}
+	config.Labels[constants.ComposeNumberLabel] = strconv.Itoa(number)
+
+	hostConfig := *primary.HostConfig
+	hostConfig.PortBindings = nil
+	hostConfig.PublishAllPorts = false
+	hostConfig.Binds = replicaBinds(primary.HostConfig.Binds, number)
+	hostConfig.Mounts = replicaMounts(primary.HostConfig.Mounts, number)
+
+	networks := sortedNetworks(primary.NetworkSettings)
+	var networking *network.NetworkingConfig
+	if len(networks) > 0 {
+		networking = &network.NetworkingConfig{
+			EndpointsConfig: map[string]*network.EndpointSettings{
+				networks[0]: {Aliases: []string{serviceName}},
+			},
+		}
+	}
+
+	name := fmt.Sprintf("%s-%s-%d", NormalizeProjectName(projectName), serviceName, number)
+	created, err := cs.client.api().ContainerCreate(ctx, &config, &hostConfig, networking, nil, name)
+	if err != nil {
+		return err
+	}
+
+	for _, extra := range networks[min(1, len(networks)):] {
+		if err := cs.client.api().NetworkConnect(ctx, extra, created.ID, &network.EndpointSettings{Aliases: []string{serviceName}}); err != nil {
+			return fmt.Errorf("failed to connect to network %s: %w", extra, err)
+		}
+	}
+
+	if err := cs.client.api().ContainerStart(ctx, created.ID, container.StartOptions{}); err != nil {
+		return err
+	}
+
+	cs.client.logger.Info("Started replica", "container", name, "number", number)
+	return nil
+}
+
+// ContainerNumber reads the compose container number label, treating
+// containers without one as the primary
+func ContainerNumber(labels map[string]string) int {
+	if number, err := strconv.Atoi(labels[constants.ComposeNumberLabel]); err == nil && number > 0 {
+		return number
+	}
+	return 1
+}
+
+// nextReplicaNumbers returns count unused container numbers, filling gaps
+// left by earlier scale downs first
+func nextReplicaNumbers(existing []replica, count int) []int {
+	used := make(map[int]bool, len(existing))
+	for _, r := range existing {
+		used[r.number] = true
+	}
+
+	var numbers []int
+	for number := 1; len(numbers) < count; number++ {
+		if !used[number] {
+			numbers = append(numbers, number)
+		}
+	}
+	return numbers
+}
+
+// replicaVolumeName returns the per-replica name of a named volume
+func replicaVolumeName(volume string, number int) string {
+	return fmt.Sprintf("%s-%d", volume, number)
+}
+
+// replicaBinds gives each named volume in a bind list a per-replica name so
+// replicas never share a data directory. Host paths are kept as is.
+func replicaBinds(binds []string, number int) []string {
+	if binds == nil {
+		return nil
+	}
+	result := make([]string, 0, len(binds))
+	for _, bind := range binds {
+		source, rest, found := compose.SplitVolumeSpec(bind)
+		if found && !compose.IsHostPath(source) {
+			bind = replicaVolumeName(source, number) + ":" + rest
+		}
+		result = append(result, bind)
+	}
+	return result
+}
+
+// replicaMounts gives each named volume mount a per-replica name
+func replicaMounts(mounts []mount.Mount, number int) []mount.Mount {
+	if mounts == nil {
+		return nil
+	}
+	result := make([]mount.Mount, 0, len(mounts))
+	for _, m := range mounts {
+		if m.Type == mount.TypeVolume && m.Source != "" {
+			m.Source = replicaVolumeName(m.Source, number)
+		}
+		result = append(result, m)
+	}
+	return result
+}
+
+// sortedNetworks returns the names of the networks a container is attached to
+func sortedNetworks(settings *container.NetworkSettings) []string {
+	if settings == nil {
+		return nil
+	}
+	names := make([]string, 0, len(settings.Networks))
+	for name := range settings.Networks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,51 @@
+package docker
+
+import (
+	"testing"
+
+	"github.com/docker/docker/api/types/mount"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/isaacgarza/dev-stack/internal/pkg/constants"
+)
+
+func TestContainerNumber(t *testing.T) {
+	assert.Equal(t, 3, ContainerNumber(map[string]string{constants.ComposeNumberLabel: "3"}))
+	assert.Equal(t, 1, ContainerNumber(map[string]string{}))
+	assert.Equal(t, 1, ContainerNumber(map[string]string{constants.ComposeNumberLabel: "bogus"}))
+}
+
+func TestNextReplicaNumbers(t *testing.T) {
+	existing := []replica{{number: 1}, {number: 3}}
+	assert.Equal(t, []int{2, 4, 5}, nextReplicaNumbers(existing, 3))
+	assert.Empty(t, nextReplicaNumbers(existing, 0))
+}
+
+func TestReplicaBinds(t *testing.T) {
+	binds := []string{
+		"app-postgres-data:/var/lib/postgresql/data",
+		"/var/run/docker.sock:/var/run/docker.sock",
+		"./prometheus.yml:/etc/prometheus/prometheus.yml:ro",
+		`C:\Users\dev\seed:/docker-entrypoint-initdb.d:ro`,
+	}
+
+	assert.Equal(t, []string{
+		"app-postgres-data-2:/var/lib/postgresql/data",
+		"/var/run/docker.sock:/var/run/docker.sock",
+		"./prometheus.yml:/etc/prometheus/prometheus.yml:ro",
+		`C:\Users\dev\seed:/docker-entrypoint-initdb.d:ro`,
+	}, replicaBinds(binds, 2))
+	assert.Nil(t, replicaBinds(nil, 2))
+}
+
+func TestReplicaMounts(t *testing.T) {
+	mounts := []mount.Mount{
+		{Type: mount.TypeVolume, Source: "app-redis-data", Target: "/data"},
+		{Type: mount.TypeBind, Source: "/etc/app", Target: "/etc/app"},
+	}
+
+	result := replicaMounts(mounts, 3)
+	assert.Equal(t, "app-redis-data-3", result[0].Source)
+	assert.Equal(t, "/etc/app", result[1].Source)
+	assert.Equal(t, "app-redis-data", mounts[0].Source, "primary mounts must not be modified")
+}
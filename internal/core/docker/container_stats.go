@@ -0,0 +1,184 @@
+package docker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/filters"
+
+	"github.com/isaacgarza/dev-stack/internal/pkg/constants"
+	"github.com/isaacgarza/dev-stack/internal/pkg/types"
+)
+
+// Stats samples the resource usage of the running containers of the given
+// services, or of every service of the project when none are given. Each
+// container is sampled once; the daemon waits for a second reading so CPU
+// usage can be computed.
+func (cl *ContainerLister) Stats(ctx context.Context, projectName string, serviceNames []string) ([]types.ServiceStats, error) {
+	args := filters.NewArgs(filters.Arg("label", projectLabel(projectName)))
+	containers, err := cl.client.api().ContainerList(ctx, container.ListOptions{Filters: args})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list containers: %w", err)
+	}
+
+	var sampled []container.Summary
+	for _, c := range containers {
+		serviceName := c.Labels[constants.ComposeServiceLabel]
+		if len(serviceNames) > 0 && !contains(serviceNames, serviceName) {
+			continue
+		}
+		sampled = append(sampled, c)
+	}
+
+	var samples []types.ServiceStats
+	var errs []error
+	for i, result := range cl.sampleStats(ctx, sampled) {
+		serviceName := sampled[i].Labels[constants.ComposeServiceLabel]
+		if result.err != nil {
+			errs = append(errs, fmt.Errorf("failed to read stats of %s: %w", serviceName, result.err))
+			continue
+		}
+		result.stats.Service = serviceName
+		result.stats.Container = containerName(sampled[i])
+		samples = append(samples, result.stats)
+	}
+
+	if len(errs) > 0 && len(samples) == 0 {
+		return nil, errs[0]
+	}
+	for _, err := range errs {
+		cl.client.logger.Warn("Skipping container stats", "error", err)
+	}
+
+	sort.Slice(samples, func(i, j int) bool { return samples[i].Container < samples[j].Container })
+	return samples, nil
+}
+
+// statsConcurrency bounds the containers sampled at once, so a large stack
+// doesn't open a stats stream per container on the daemon together
+const statsConcurrency = 8
+
+// statsTimeout bounds the sample of a container. The daemon takes about a
+// second to answer, waiting for the second reading CPU usage is computed
+// from; one that hangs on a container doesn't hold up the others.
+const statsTimeout = 5 * time.Second
+
+// statsResult is the sample of a container, or why it couldn't be read
+type statsResult struct {
+	stats types.ServiceStats
+	err   error
+}
+
+// sampleStats reads a stats sample of each container, statsConcurrency at
+// a time, returning the results in the order of containers
+func (cl *ContainerLister) sampleStats(ctx context.Context, containers []container.Summary) []statsResult {
+	results := make([]statsResult, len(containers))
+	semaphore := make(chan struct{}, statsConcurrency)
+	var wg sync.WaitGroup
+	for i, c := range containers {
+		wg.Add(1)
+		go func(i int, containerID string) {
+			defer wg.Done()
+			select {
+			case semaphore <- struct{}{}:
+				defer func() { <-semaphore }()
+			case <-ctx.Done():
+				results[i].err = ctx.Err()
+				return
+			}
+
+			sampleCtx, cancel := context.WithTimeout(ctx, statsTimeout)
+			defer cancel()
+			results[i].stats, results[i].err = cl.getContainerStats(sampleCtx, containerID)
+		}(i, c.ID)
+	}
+	wg.Wait()
+	return results
+}
+
+// getContainerStats reads a single stats sample of a container
+func (cl *ContainerLister) getContainerStats(ctx context.Context, containerID string) (types.ServiceStats, error) {
+	stats, err := cl.client.api().ContainerStats(ctx, containerID, false)
+	if err != nil {
+		return types.ServiceStats{}, err
+	}
+	defer func() {
+		if closeErr := stats.Body.Close(); closeErr != nil {
+			cl.client.logger.Error("Failed to close stats body", "error", closeErr)
+		}
+	}()
+
+	var response container.StatsResponse
+	if err := json.NewDecoder(stats.Body).Decode(&response); err != nil {
+		return types.ServiceStats{}, fmt.Errorf("failed to decode stats: %w", err)
+	}
+	return decodeStats(response), nil
+}
+
+// decodeStats converts a stats response the way docker stats reports it:
+// CPU usage relative to a single CPU, and memory without the page cache
+func decodeStats(response container.StatsResponse) types.ServiceStats {
+	stats := types.ServiceStats{
+		CPUPercent: cpuPercent(response.CPUStats, response.PreCPUStats),
+		Memory: types.MemoryUsage{
+			Used:  memoryUsed(response.MemoryStats),
+			Limit: response.MemoryStats.Limit,
+		},
+		PIDs: response.PidsStats.Current,
+	}
+
+	for _, network := range response.Networks {
+		stats.NetworkRx += network.RxBytes
+		stats.NetworkTx += network.TxBytes
+	}
+	for _, entry := range response.BlkioStats.IoServiceBytesRecursive {
+		switch strings.ToLower(entry.Op) {
+		case "read":
+			stats.BlockRead += entry.Value
+		case "write":
+			stats.BlockWrite += entry.Value
+		}
+	}
+	return stats
+}
+
+// cpuPercent returns the CPU usage between two readings, where 100% is one
+// fully used CPU
+func cpuPercent(current, previous container.CPUStats) float64 {
+	cpuDelta := float64(current.CPUUsage.TotalUsage) - float64(previous.CPUUsage.TotalUsage)
+	systemDelta := float64(current.SystemUsage) - float64(previous.SystemUsage)
+	onlineCPUs := float64(current.OnlineCPUs)
+	if onlineCPUs == 0 {
+		onlineCPUs = float64(len(current.CPUUsage.PercpuUsage))
+	}
+	if cpuDelta <= 0 || systemDelta <= 0 {
+		return 0
+	}
+	return cpuDelta / systemDelta * onlineCPUs * 100
+}
+
+// memoryUsed returns the memory usage without the inactive page cache,
+// which the kernel reclaims before the container hits its limit. cgroup v1
+// reports it as total_inactive_file and cgroup v2 as inactive_file.
+func memoryUsed(memory container.MemoryStats) uint64 {
+	for _, key := range []string{"total_inactive_file", "inactive_file"} {
+		if inactive, ok := memory.Stats[key]; ok && inactive < memory.Usage {
+			return memory.Usage - inactive
+		}
+	}
+	return memory.Usage
+}
+
+// containerName returns the name of a container without its leading slash
+func containerName(c container.Summary) string {
+	if len(c.Names) == 0 {
+		return c.ID[:min(12, len(c.ID))]
+	}
+	return strings.TrimPrefix(c.Names[0], "/")
+}
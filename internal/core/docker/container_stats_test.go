@@ -0,0 +1,112 @@
+package docker
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/isaacgarza/dev-stack/internal/pkg/types"
+)
+
+func TestDecodeStats(t *testing.T) {
+	response := container.StatsResponse{
+		CPUStats: container.CPUStats{
+			CPUUsage:    container.CPUUsage{TotalUsage: 3_000_000},
+			SystemUsage: 20_000_000,
+			OnlineCPUs:  4,
+		},
+		PreCPUStats: container.CPUStats{
+			CPUUsage:    container.CPUUsage{TotalUsage: 1_000_000},
+			SystemUsage: 10_000_000,
+		},
+		MemoryStats: container.MemoryStats{
+			Usage: 300 << 20,
+			Limit: 512 << 20,
+			Stats: map[string]uint64{"inactive_file": 100 << 20},
+		},
+		PidsStats: container.PidsStats{Current: 12},
+		Networks: map[string]container.NetworkStats{
+			"eth0": {RxBytes: 1000, TxBytes: 200},
+			"eth1": {RxBytes: 24, TxBytes: 56},
+		},
+		BlkioStats: container.BlkioStats{
+			IoServiceBytesRecursive: []container.BlkioStatEntry{
+				{Op: "Read", Value: 4096},
+				{Op: "write", Value: 8192},
+				{Op: "read", Value: 4096},
+				{Op: "Total", Value: 16384},
+			},
+		},
+	}
+
+	assert.Equal(t, types.ServiceStats{
+		CPUPercent: 80,
+		Memory:     types.MemoryUsage{Used: 200 << 20, Limit: 512 << 20},
+		NetworkRx:  1024,
+		NetworkTx:  256,
+		BlockRead:  8192,
+		BlockWrite: 8192,
+		PIDs:       12,
+	}, decodeStats(response))
+}
+
+func TestCPUPercent(t *testing.T) {
+	// An idle container has not used CPU time since the previous reading
+	idle := container.CPUStats{CPUUsage: container.CPUUsage{TotalUsage: 5}, SystemUsage: 10, OnlineCPUs: 2}
+	assert.Zero(t, cpuPercent(idle, idle))
+
+	// Older daemons report the CPUs only through per-CPU usage
+	current := container.CPUStats{
+		CPUUsage:    container.CPUUsage{TotalUsage: 200, PercpuUsage: []uint64{100, 100}},
+		SystemUsage: 1000,
+	}
+	previous := container.CPUStats{CPUUsage: container.CPUUsage{TotalUsage: 100}, SystemUsage: 800}
+	assert.InDelta(t, 100.0, cpuPercent(current, previous), 0.001)
+}
+
+func TestSampleStats(t *testing.T) {
+	// A daemon taking 300ms to answer each stats request, failing for
+	// "broken"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(300 * time.Millisecond)
+		if strings.Contains(r.URL.Path, "/broken/") {
+			http.Error(w, `{"message":"no such container"}`, http.StatusNotFound)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(container.StatsResponse{
+			MemoryStats: container.MemoryStats{Usage: 64 << 20},
+			PidsStats:   container.PidsStats{Current: 3},
+		})
+	}))
+	defer server.Close()
+	cli, err := client.NewClientWithOpts(client.WithHost("tcp://"+strings.TrimPrefix(server.URL, "http://")), client.WithVersion("1.45"))
+	require.NoError(t, err)
+	lister := NewContainerLister(&Client{cli: cli})
+
+	containers := []container.Summary{{ID: "a"}, {ID: "broken"}, {ID: "c"}, {ID: "d"}, {ID: "e"}, {ID: "f"}}
+	start := time.Now()
+	results := lister.sampleStats(context.Background(), containers)
+	assert.Less(t, time.Since(start), time.Second, "sampled together")
+
+	require.Len(t, results, len(containers))
+	assert.Error(t, results[1].err)
+	for _, i := range []int{0, 2, 3, 4, 5} {
+		require.NoError(t, results[i].err)
+		assert.Equal(t, uint64(64<<20), results[i].stats.Memory.Used)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	for _, result := range lister.sampleStats(ctx, containers) {
+		assert.Error(t, result.err)
+	}
+}
@@ -4,23 +4,17 @@ import (
 	"strings"
 
 	"github.com/isaacgarza/dev-stack/internal/pkg/constants"
-	"github.com/isaacgarza/dev-stack/internal/pkg/types"
 )
 
-// ContainerStats holds container statistics
-type ContainerStats struct {
-	CPUUsage float64
-	Memory   types.MemoryUsage
-}
-
 // getHealthStatus extracts health status from container status string
 func getHealthStatus(status string) string {
-	if strings.Contains(status, constants.HealthHealthy) {
-		return constants.HealthHealthy
-	}
+	// check unhealthy first since it contains "healthy"
 	if strings.Contains(status, constants.HealthUnhealthy) {
 		return constants.HealthUnhealthy
 	}
+	if strings.Contains(status, constants.HealthHealthy) {
+		return constants.HealthHealthy
+	}
 	if strings.Contains(status, constants.HealthStarting) {
 		return constants.HealthStarting
 	}
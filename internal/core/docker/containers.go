@@ -2,6 +2,7 @@ package docker
 
 import (
 	"context"
+	"io"
 
 	"github.com/isaacgarza/dev-stack/internal/pkg/types"
 )
@@ -12,6 +13,7 @@ type ContainerService struct {
 	lister    *ContainerLister
 	lifecycle *ContainerLifecycle
 	executor  *ContainerExecutor
+	scaler    *ContainerScaler
 }
 
 // NewContainerService creates a new container service
@@ -21,6 +23,7 @@ func NewContainerService(client *Client) *ContainerService {
 		lister:    NewContainerLister(client),
 		lifecycle: NewContainerLifecycle(client),
 		executor:  NewContainerExecutor(client),
+		scaler:    NewContainerScaler(client),
 	}
 }
 
@@ -29,6 +32,24 @@ func (cs *ContainerService) List(ctx context.Context, projectName string, servic
 	return cs.lister.List(ctx, projectName, serviceNames)
 }
 
+// Stats samples the resource usage of the running containers of the
+// specified services
+func (cs *ContainerService) Stats(ctx context.Context, projectName string, serviceNames []string) ([]types.ServiceStats, error) {
+	return cs.lister.Stats(ctx, projectName, serviceNames)
+}
+
+// Drift compares the running containers of the specified services, or of
+// every service, with their definitions in the compose files
+func (cs *ContainerService) Drift(ctx context.Context, projectName string, serviceNames []string) ([]types.ServiceDrift, error) {
+	return cs.lister.Drift(ctx, projectName, serviceNames)
+}
+
+// Reconcile works out which of the specified services up has to create,
+// recreate or start, and which it can leave running
+func (cs *ContainerService) Reconcile(ctx context.Context, projectName string, profiles, serviceNames []string) ([]types.ServiceReconcile, error) {
+	return cs.lister.Reconcile(ctx, projectName, profiles, serviceNames)
+}
+
 // Start starts containers for the specified services
 func (cs *ContainerService) Start(ctx context.Context, projectName string, serviceNames []string, options types.StartOptions) error {
 	return cs.lifecycle.Start(ctx, projectName, serviceNames, options)
@@ -39,12 +60,147 @@ func (cs *ContainerService) Stop(ctx context.Context, projectName string, servic
 	return cs.lifecycle.Stop(ctx, projectName, serviceNames, options)
 }
 
+// Shutdown stops the running containers of the specified services, or of
+// every service, in reverse dependency order without removing them
+func (cs *ContainerService) Shutdown(ctx context.Context, projectName string, serviceNames []string, options types.ShutdownOptions) (*types.ShutdownReport, error) {
+	return cs.lifecycle.Shutdown(ctx, projectName, serviceNames, options)
+}
+
+// Restart restarts the running containers of the specified services
+func (cs *ContainerService) Restart(ctx context.Context, projectName string, serviceNames []string, timeout int) error {
+	return cs.lifecycle.Restart(ctx, projectName, serviceNames, timeout)
+}
+
+// Activity samples the connections to the published ports of each service
+func (cs *ContainerService) Activity(ctx context.Context, projectName string) ([]types.ServiceActivity, error) {
+	return cs.lister.Activity(ctx, projectName)
+}
+
+// Pause freezes the running containers of the specified services, or of
+// every service, returning the services paused
+func (cs *ContainerService) Pause(ctx context.Context, projectName string, serviceNames []string) ([]string, error) {
+	return cs.lifecycle.Pause(ctx, projectName, serviceNames)
+}
+
+// Unpause resumes the paused containers of the specified services, or of
+// every service, returning the services resumed
+func (cs *ContainerService) Unpause(ctx context.Context, projectName string, serviceNames []string) ([]string, error) {
+	return cs.lifecycle.Unpause(ctx, projectName, serviceNames)
+}
+
+// Build builds the images of the specified services
+func (cs *ContainerService) Build(ctx context.Context, projectName string, serviceNames []string, options types.BuildOptions) error {
+	return cs.lifecycle.Build(ctx, projectName, serviceNames, options)
+}
+
 // Exec executes a command in a running container
 func (cs *ContainerService) Exec(ctx context.Context, projectName, serviceName string, cmd []string, options types.ExecOptions) error {
 	return cs.executor.Exec(ctx, projectName, serviceName, cmd, options)
 }
 
+// ExecCapture runs a command in a running container and captures its result
+func (cs *ContainerService) ExecCapture(ctx context.Context, projectName, serviceName string, cmd []string) (*types.ExecResult, error) {
+	return cs.executor.ExecCapture(ctx, projectName, serviceName, cmd)
+}
+
+// EphemeralStacks returns the ephemeral stacks of every project
+func (cs *ContainerService) EphemeralStacks(ctx context.Context) ([]types.EphemeralStack, error) {
+	return cs.lifecycle.EphemeralStacks(ctx)
+}
+
+// RemoveEphemeral removes an ephemeral stack with its anonymous volumes
+func (cs *ContainerService) RemoveEphemeral(ctx context.Context, stack types.EphemeralStack) error {
+	return cs.lifecycle.RemoveEphemeral(ctx, stack)
+}
+
+// StartShared starts a shared service unless it is running already
+func (cs *ContainerService) StartShared(ctx context.Context, serviceName string) (bool, error) {
+	return cs.lifecycle.StartShared(ctx, serviceName)
+}
+
+// ConnectShared connects a shared service to a project's networks
+func (cs *ContainerService) ConnectShared(ctx context.Context, projectName, serviceName string) error {
+	return cs.lifecycle.ConnectShared(ctx, projectName, serviceName)
+}
+
+// DisconnectShared disconnects a shared service from a project's networks
+func (cs *ContainerService) DisconnectShared(ctx context.Context, projectName, serviceName string) error {
+	return cs.lifecycle.DisconnectShared(ctx, projectName, serviceName)
+}
+
+// RemoveShared removes the container of a shared service
+func (cs *ContainerService) RemoveShared(ctx context.Context, serviceName string) error {
+	return cs.lifecycle.RemoveShared(ctx, serviceName)
+}
+
+// Env returns the environment of a service's running container
+func (cs *ContainerService) Env(ctx context.Context, projectName, serviceName string) (map[string]string, error) {
+	return cs.executor.Env(ctx, projectName, serviceName)
+}
+
+// ExecStream runs a command in a running container, streaming its stdout to w
+func (cs *ContainerService) ExecStream(ctx context.Context, projectName, serviceName string, cmd []string, w io.Writer, options types.ExecOptions) error {
+	return cs.executor.ExecStream(ctx, projectName, serviceName, cmd, w, options)
+}
+
+// CopyFrom streams a file out of a service's container
+func (cs *ContainerService) CopyFrom(ctx context.Context, projectName, serviceName, path string, w io.Writer) error {
+	return cs.executor.CopyFrom(ctx, projectName, serviceName, path, w)
+}
+
+// ExecStdin runs a command in a running container, feeding r to its stdin
+func (cs *ContainerService) ExecStdin(ctx context.Context, projectName, serviceName string, cmd []string, r io.Reader, options types.ExecOptions) error {
+	return cs.executor.ExecStdin(ctx, projectName, serviceName, cmd, r, options)
+}
+
+// CopyTo writes a file into a service's container
+func (cs *ContainerService) CopyTo(ctx context.Context, projectName, serviceName, target string, r io.Reader, size int64) error {
+	return cs.executor.CopyTo(ctx, projectName, serviceName, target, r, size)
+}
+
+// CopyArchiveFrom streams a directory out of a service's container as a tar
+// archive
+func (cs *ContainerService) CopyArchiveFrom(ctx context.Context, projectName, serviceName, path string, w io.Writer) error {
+	return cs.executor.CopyArchiveFrom(ctx, projectName, serviceName, path, w)
+}
+
+// CopyArchiveTo extracts a tar archive into a directory of a service's
+// container
+func (cs *ContainerService) CopyArchiveTo(ctx context.Context, projectName, serviceName, dir string, r io.Reader) error {
+	return cs.executor.CopyArchiveTo(ctx, projectName, serviceName, dir, r)
+}
+
+// VolumeMounts returns the volumes mounted into a service's container
+func (cs *ContainerService) VolumeMounts(ctx context.Context, projectName, serviceName string) ([]types.VolumeMount, error) {
+	return cs.executor.VolumeMounts(ctx, projectName, serviceName)
+}
+
+// ExportVolume streams a volume of a service's container as a tar archive
+func (cs *ContainerService) ExportVolume(ctx context.Context, projectName, serviceName, destination string, w io.Writer) error {
+	return cs.executor.ExportVolume(ctx, projectName, serviceName, destination, w)
+}
+
+// Create creates the specified services without starting them
+func (cs *ContainerService) Create(ctx context.Context, projectName string, serviceNames []string) error {
+	return cs.lifecycle.Create(ctx, projectName, serviceNames)
+}
+
+// NewScratch starts a disposable copy of a running service with empty storage
+func (cs *ContainerService) NewScratch(ctx context.Context, projectName, serviceName, purpose string) (*ScratchContainer, error) {
+	return cs.executor.NewScratch(ctx, projectName, serviceName, purpose)
+}
+
 // Logs retrieves logs from containers
 func (cs *ContainerService) Logs(ctx context.Context, projectName string, serviceNames []string, options types.LogOptions) error {
 	return cs.executor.Logs(ctx, projectName, serviceNames, options)
 }
+
+// StreamLogs retrieves logs from containers, writing them to stdout and stderr
+func (cs *ContainerService) StreamLogs(ctx context.Context, projectName string, serviceNames []string, options types.LogOptions, stdout, stderr io.Writer) error {
+	return cs.executor.StreamLogs(ctx, projectName, serviceNames, options, stdout, stderr)
+}
+
+// Scale sets the number of containers running for a service
+func (cs *ContainerService) Scale(ctx context.Context, projectName, serviceName string, replicas int, options types.ScaleOptions) error {
+	return cs.scaler.Scale(ctx, projectName, serviceName, replicas, options)
+}
@@ -0,0 +1,363 @@
+package docker
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/api/types/mount"
+
+	"github.com/isaacgarza/dev-stack/internal/pkg/compose"
+	"github.com/isaacgarza/dev-stack/internal/pkg/constants"
+	"github.com/isaacgarza/dev-stack/internal/pkg/types"
+)
+
+// containerSpec is the part of a container's configuration checked for
+// drift, normalized so that definitions and containers compare equal
+type containerSpec struct {
+	image string
+	// env is NAME=value; containers also carry the variables of their image,
+	// so only the names a definition sets are compared
+	env map[string]string
+	// ports are published ports as [host_ip:]published->target/protocol
+	ports []string
+	// mounts maps each mount target to its source in compose short syntax
+	mounts map[string]mountSpec
+}
+
+// mountSpec is a volume, bind or tmpfs mount
+type mountSpec struct {
+	kind     string
+	source   string
+	target   string
+	readOnly bool
+}
+
+// String returns the mount in compose short syntax
+func (m mountSpec) String() string {
+	var parts []string
+	switch {
+	case m.kind == string(mount.TypeTmpfs):
+		parts = []string{"tmpfs", m.target}
+	case m.source != "":
+		parts = []string{m.source, m.target}
+	default:
+		parts = []string{m.target}
+	}
+	if m.readOnly {
+		parts = append(parts, "ro")
+	}
+	return strings.Join(parts, ":")
+}
+
+// composeConfig is the part of `docker compose config --format json` the
+// drift check reads
+type composeConfig struct {
+	Services map[string]struct {
+		Image       string             `json:"image"`
+		Environment map[string]*string `json:"environment"`
+		Ports       []struct {
+			HostIP    string `json:"host_ip"`
+			Target    int    `json:"target"`
+			Published string `json:"published"`
+			Protocol  string `json:"protocol"`
+		} `json:"ports"`
+		Volumes []struct {
+			Type     string `json:"type"`
+			Source   string `json:"source"`
+			Target   string `json:"target"`
+			ReadOnly bool   `json:"read_only"`
+		} `json:"volumes"`
+	} `json:"services"`
+	Volumes map[string]struct {
+		Name string `json:"name"`
+	} `json:"volumes"`
+}
+
+// Drift compares the running containers of a project with what its compose
+// files define for them, for serviceNames or every service. Services are
+// only reported as missing when they are named or belong to no compose
+// profile, since up leaves profile services out unless asked for.
+func (cl *ContainerLister) Drift(ctx context.Context, projectName string, serviceNames []string) ([]types.ServiceDrift, error) {
+	serviceProfiles, err := compose.ServiceProfiles(ComposeFiles()...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read compose profiles: %w", err)
+	}
+	var profiles []string
+	for _, names := range serviceProfiles {
+		for _, profile := range names {
+			if !slices.Contains(profiles, profile) {
+				profiles = append(profiles, profile)
+			}
+		}
+	}
+	sort.Strings(profiles)
+
+	desired, err := composeSpecs(ctx, projectName, profiles)
+	if err != nil {
+		return nil, err
+	}
+
+	actual, err := cl.runningSpecs(ctx, projectName)
+	if err != nil {
+		return nil, err
+	}
+
+	var drifts []types.ServiceDrift
+	for _, drift := range compareSpecs(desired, actual) {
+		if len(serviceNames) > 0 && !slices.Contains(serviceNames, drift.Service) {
+			continue
+		}
+		if _, profiled := serviceProfiles[drift.Service]; drift.Status == types.DriftMissing && profiled && len(serviceNames) == 0 {
+			continue
+		}
+		drifts = append(drifts, drift)
+	}
+	return drifts, nil
+}
+
+// composeSpecs returns the spec of each service the compose files define
+// with profiles active
+func composeSpecs(ctx context.Context, projectName string, profiles []string) (map[string]containerSpec, error) {
+	output, err := runComposeConfig(ctx, projectName, profiles, "--format", "json")
+	if err != nil {
+		return nil, err
+	}
+	return parseComposeConfig(output)
+}
+
+// runComposeConfig runs docker compose config with args and returns its
+// output. Warnings go to stderr, so only stdout is returned.
+func runComposeConfig(ctx context.Context, projectName string, profiles []string, args ...string) ([]byte, error) {
+	var stderr bytes.Buffer
+	cmd := composeCommand(ctx, composeArgs(projectName, profiles, append([]string{"config"}, args...)...)...)
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	if err != nil {
+		if message := strings.TrimSpace(stderr.String()); message != "" {
+			return nil, fmt.Errorf("docker compose config failed: %w: %s", err, message)
+		}
+		return nil, fmt.Errorf("docker compose config failed: %w", err)
+	}
+	return output, nil
+}
+
+// runningSpecs returns the spec of the primary running container of each
+// service of a project
+func (cl *ContainerLister) runningSpecs(ctx context.Context, projectName string) (map[string]containerSpec, error) {
+	filters := filters.NewArgs()
+	filters.Add("label", projectLabel(projectName))
+
+	containers, err := cl.client.api().ContainerList(ctx, container.ListOptions{Filters: filters})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list containers: %w", err)
+	}
+
+	primaries := make(map[string]container.Summary)
+	for _, c := range containers {
+		serviceName := c.Labels[constants.ComposeServiceLabel]
+		if serviceName == "" || c.Labels[constants.ComposeOneoffLabel] == "True" {
+			continue
+		}
+		if primary, ok := primaries[serviceName]; !ok || ContainerNumber(c.Labels) < ContainerNumber(primary.Labels) {
+			primaries[serviceName] = c
+		}
+	}
+
+	specs := make(map[string]containerSpec, len(primaries))
+	for serviceName, c := range primaries {
+		inspect, err := cl.client.api().ContainerInspect(ctx, c.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to inspect %s: %w", serviceName, err)
+		}
+		specs[serviceName] = inspectSpec(inspect)
+	}
+	return specs, nil
+}
+
+// parseComposeConfig reads the spec of each service from the output of
+// `docker compose config --format json`
+func parseComposeConfig(data []byte) (map[string]containerSpec, error) {
+	var config composeConfig
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("failed to parse the compose configuration: %w", err)
+	}
+
+	specs := make(map[string]containerSpec, len(config.Services))
+	for serviceName, service := range config.Services {
+		spec := containerSpec{image: service.Image, env: map[string]string{}, mounts: map[string]mountSpec{}}
+		if spec.image != "" {
+			spec.image = normalizeImage(spec.image)
+		}
+		for name, value := range service.Environment {
+			// Variables passed through from an unset host variable aren't set
+			if value != nil {
+				spec.env[name] = *value
+			}
+		}
+		for _, port := range service.Ports {
+			spec.ports = append(spec.ports, portSpec(port.HostIP, port.Published, fmt.Sprint(port.Target), port.Protocol))
+		}
+		for _, volume := range service.Volumes {
+			source := volume.Source
+			if named, ok := config.Volumes[source]; ok && volume.Type == string(mount.TypeVolume) && named.Name != "" {
+				source = named.Name
+			}
+			spec.mounts[volume.Target] = mountSpec{kind: volume.Type, source: source, target: volume.Target, readOnly: volume.ReadOnly}
+		}
+		specs[serviceName] = spec
+	}
+	return specs, nil
+}
+
+// inspectSpec reads the spec of a container
+func inspectSpec(inspect container.InspectResponse) containerSpec {
+	spec := containerSpec{env: map[string]string{}, mounts: map[string]mountSpec{}}
+	if inspect.Config != nil {
+		spec.image = normalizeImage(inspect.Config.Image)
+		for _, entry := range inspect.Config.Env {
+			if name, value, ok := strings.Cut(entry, "="); ok {
+				spec.env[name] = value
+			}
+		}
+	}
+	if inspect.HostConfig != nil {
+		for port, bindings := range inspect.HostConfig.PortBindings {
+			for _, binding := range bindings {
+				spec.ports = append(spec.ports, portSpec(binding.HostIP, binding.HostPort, port.Port(), port.Proto()))
+			}
+		}
+	}
+	for _, m := range inspect.Mounts {
+		source := m.Source
+		if m.Type == mount.TypeVolume {
+			source = m.Name
+		}
+		spec.mounts[m.Destination] = mountSpec{kind: string(m.Type), source: source, target: m.Destination, readOnly: !m.RW}
+	}
+	return spec
+}
+
+// compareSpecs returns the drift of every service, sorted by name. Services
+// without drift are left out.
+func compareSpecs(desired, actual map[string]containerSpec) []types.ServiceDrift {
+	var drifts []types.ServiceDrift
+	for serviceName, want := range desired {
+		got, running := actual[serviceName]
+		if !running {
+			drifts = append(drifts, types.ServiceDrift{Service: serviceName, Status: types.DriftMissing})
+			continue
+		}
+		if changes := specChanges(want, got); len(changes) > 0 {
+			drifts = append(drifts, types.ServiceDrift{Service: serviceName, Status: types.DriftChanged, Changes: changes})
+		}
+	}
+	for serviceName := range actual {
+		if _, defined := desired[serviceName]; !defined {
+			drifts = append(drifts, types.ServiceDrift{Service: serviceName, Status: types.DriftOrphaned})
+		}
+	}
+
+	sort.Slice(drifts, func(i, j int) bool {
+		return drifts[i].Service < drifts[j].Service
+	})
+	return drifts
+}
+
+// specChanges lists the settings of a container that differ from its
+// definition
+func specChanges(want, got containerSpec) []types.DriftChange {
+	var changes []types.DriftChange
+
+	// Built images are named by compose, so only images given are compared
+	if want.image != "" && want.image != got.image {
+		changes = append(changes, types.DriftChange{Field: "image", Expected: want.image, Actual: got.image})
+	}
+
+	for _, name := range sortedKeys(want.env) {
+		if value, ok := got.env[name]; !ok || value != want.env[name] {
+			changes = append(changes, types.DriftChange{Field: "env." + name, Expected: want.env[name], Actual: value})
+		}
+	}
+
+	for _, port := range want.ports {
+		if !slices.Contains(got.ports, port) {
+			changes = append(changes, types.DriftChange{Field: "port", Expected: port})
+		}
+	}
+	for _, port := range got.ports {
+		if !slices.Contains(want.ports, port) {
+			changes = append(changes, types.DriftChange{Field: "port", Actual: port})
+		}
+	}
+
+	for _, target := range sortedKeys(want.mounts) {
+		wantMount := want.mounts[target]
+		gotMount, ok := got.mounts[target]
+		switch {
+		case !ok:
+			changes = append(changes, types.DriftChange{Field: "volume:" + target, Expected: wantMount.String()})
+		case wantMount.kind == string(mount.TypeVolume) && wantMount.source == "" && gotMount.kind == wantMount.kind:
+			// Anonymous volumes get a generated name
+			if gotMount.readOnly != wantMount.readOnly {
+				changes = append(changes, types.DriftChange{Field: "volume:" + target, Expected: wantMount.String(), Actual: gotMount.String()})
+			}
+		case gotMount != wantMount:
+			changes = append(changes, types.DriftChange{Field: "volume:" + target, Expected: wantMount.String(), Actual: gotMount.String()})
+		}
+	}
+	for _, target := range sortedKeys(got.mounts) {
+		// Images declare volumes of their own, which get anonymous volumes
+		if _, ok := want.mounts[target]; !ok && got.mounts[target].kind != string(mount.TypeVolume) {
+			changes = append(changes, types.DriftChange{Field: "volume:" + target, Actual: got.mounts[target].String()})
+		}
+	}
+	return changes
+}
+
+// portSpec formats a published port the way docker ps does. Ports bound on
+// every interface leave the host IP out.
+func portSpec(hostIP, published, target, protocol string) string {
+	if protocol == "" {
+		protocol = "tcp"
+	}
+	spec := target + "/" + protocol
+	if published != "" {
+		spec = published + "->" + spec
+		if hostIP != "" && hostIP != "0.0.0.0" {
+			spec = hostIP + ":" + spec
+		}
+	}
+	return spec
+}
+
+// normalizeImage writes an image reference the way compose files usually
+// do: without the Docker Hub registry and with the latest tag made explicit
+func normalizeImage(image string) string {
+	for _, prefix := range []string{"docker.io/library/", "index.docker.io/library/", "docker.io/", "index.docker.io/"} {
+		if strings.HasPrefix(image, prefix) {
+			image = strings.TrimPrefix(image, prefix)
+			break
+		}
+	}
+	if !strings.Contains(image, "@") && !strings.Contains(image[strings.LastIndex(image, "/")+1:], ":") {
+		image += ":latest"
+	}
+	return image
+}
+
+// sortedKeys returns the keys of m in order
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,141 @@
+package docker
+
+import (
+	"testing"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/mount"
+	"github.com/docker/go-connections/nat"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/isaacgarza/dev-stack/internal/pkg/types"
+)
+
+const testComposeConfig = `{
+  "name": "shop",
+  "services": {
+    "postgres": {
+      "image": "postgres:15-alpine",
+      "environment": {"POSTGRES_PASSWORD": "secret", "POSTGRES_DB": "shop", "PGOPTIONS": null},
+      "ports": [{"mode": "ingress", "host_ip": "127.0.0.1", "target": 5432, "published": "5432", "protocol": "tcp"}],
+      "volumes": [
+        {"type": "volume", "source": "postgres_data", "target": "/var/lib/postgresql/data", "volume": {}},
+        {"type": "bind", "source": "/home/dev/shop/dev-stack/seeds", "target": "/docker-entrypoint-initdb.d", "read_only": true}
+      ]
+    },
+    "redis": {
+      "image": "redis",
+      "ports": [{"target": 6379, "published": "6379"}],
+      "volumes": [{"type": "volume", "target": "/data"}]
+    },
+    "api": {
+      "build": {"context": "/home/dev/shop"},
+      "environment": {"PORT": "8080"}
+    }
+  },
+  "volumes": {"postgres_data": {"name": "shop_postgres_data"}}
+}`
+
+// testContainer builds the inspect response of a container
+func testContainer(image string, env []string, ports nat.PortMap, mounts ...container.MountPoint) container.InspectResponse {
+	return container.InspectResponse{
+		ContainerJSONBase: &container.ContainerJSONBase{HostConfig: &container.HostConfig{PortBindings: ports}},
+		Config:            &container.Config{Image: image, Env: env},
+		Mounts:            mounts,
+	}
+}
+
+func TestParseComposeConfig(t *testing.T) {
+	specs, err := parseComposeConfig([]byte(testComposeConfig))
+	require.NoError(t, err)
+	require.Len(t, specs, 3)
+
+	postgres := specs["postgres"]
+	assert.Equal(t, "postgres:15-alpine", postgres.image)
+	assert.Equal(t, map[string]string{"POSTGRES_PASSWORD": "secret", "POSTGRES_DB": "shop"}, postgres.env)
+	assert.Equal(t, []string{"127.0.0.1:5432->5432/tcp"}, postgres.ports)
+	assert.Equal(t, "shop_postgres_data:/var/lib/postgresql/data", postgres.mounts["/var/lib/postgresql/data"].String())
+	assert.Equal(t, "/home/dev/shop/dev-stack/seeds:/docker-entrypoint-initdb.d:ro", postgres.mounts["/docker-entrypoint-initdb.d"].String())
+
+	assert.Equal(t, "redis:latest", specs["redis"].image)
+	assert.Equal(t, []string{"6379->6379/tcp"}, specs["redis"].ports)
+	assert.Empty(t, specs["api"].image)
+
+	_, err = parseComposeConfig([]byte("WARN[0000] not json"))
+	assert.Error(t, err)
+}
+
+func TestCompareSpecs(t *testing.T) {
+	desired, err := parseComposeConfig([]byte(testComposeConfig))
+	require.NoError(t, err)
+
+	dataVolume := container.MountPoint{Type: mount.TypeVolume, Name: "shop_postgres_data", Destination: "/var/lib/postgresql/data", RW: true}
+	seeds := container.MountPoint{Type: mount.TypeBind, Source: "/home/dev/shop/dev-stack/seeds", Destination: "/docker-entrypoint-initdb.d"}
+	actual := map[string]containerSpec{
+		// In sync, with variables from the image and an anonymous volume it declares
+		"postgres": inspectSpec(testContainer("postgres:15-alpine",
+			[]string{"POSTGRES_PASSWORD=secret", "POSTGRES_DB=shop", "PATH=/usr/bin"},
+			nat.PortMap{"5432/tcp": {{HostIP: "127.0.0.1", HostPort: "5432"}}},
+			dataVolume, seeds,
+			container.MountPoint{Type: mount.TypeVolume, Name: "3f9a", Destination: "/var/run/postgresql", RW: true})),
+		// Old image, published on another port, with a bind mount left over
+		"redis": inspectSpec(testContainer("docker.io/library/redis:7",
+			nil,
+			nat.PortMap{"6379/tcp": {{HostIP: "0.0.0.0", HostPort: "16379"}}},
+			container.MountPoint{Type: mount.TypeVolume, Name: "a1b2", Destination: "/data", RW: true},
+			container.MountPoint{Type: mount.TypeBind, Source: "/tmp/redis.conf", Destination: "/etc/redis.conf"})),
+		"worker": inspectSpec(testContainer("shop-worker", nil, nil)),
+	}
+
+	drifts := compareSpecs(desired, actual)
+	assert.Equal(t, []types.ServiceDrift{
+		{Service: "api", Status: types.DriftMissing},
+		{Service: "redis", Status: types.DriftChanged, Changes: []types.DriftChange{
+			{Field: "image", Expected: "redis:latest", Actual: "redis:7"},
+			{Field: "port", Expected: "6379->6379/tcp"},
+			{Field: "port", Actual: "16379->6379/tcp"},
+			{Field: "volume:/etc/redis.conf", Actual: "/tmp/redis.conf:/etc/redis.conf:ro"},
+		}},
+		{Service: "worker", Status: types.DriftOrphaned},
+	}, drifts)
+}
+
+func TestSpecChanges_EnvAndVolumes(t *testing.T) {
+	want := containerSpec{
+		env: map[string]string{"A": "1", "B": "2"},
+		mounts: map[string]mountSpec{
+			"/data": {kind: "volume", source: "shop_data", target: "/data"},
+			"/tmp":  {kind: "tmpfs", target: "/tmp"},
+		},
+	}
+	got := containerSpec{
+		env: map[string]string{"A": "1", "B": "3"},
+		mounts: map[string]mountSpec{
+			"/data": {kind: "volume", source: "shop_data", target: "/data", readOnly: true},
+		},
+	}
+
+	assert.Equal(t, []types.DriftChange{
+		{Field: "env.B", Expected: "2", Actual: "3"},
+		{Field: "volume:/data", Expected: "shop_data:/data", Actual: "shop_data:/data:ro"},
+		{Field: "volume:/tmp", Expected: "tmpfs:/tmp"},
+	}, specChanges(want, got))
+	assert.Empty(t, specChanges(want, want))
+}
+
+func TestNormalizeImage(t *testing.T) {
+	tests := map[string]string{
+		"postgres":                          "postgres:latest",
+		"postgres:15":                       "postgres:15",
+		"docker.io/library/postgres:15":     "postgres:15",
+		"docker.io/bitnami/kafka":           "bitnami/kafka:latest",
+		"localhost:5000/team/api":           "localhost:5000/team/api:latest",
+		"ghcr.io/org/app:1.2":               "ghcr.io/org/app:1.2",
+		"redis@sha256:abc":                  "redis@sha256:abc",
+		"index.docker.io/library/redis:7.2": "redis:7.2",
+	}
+	for image, want := range tests {
+		assert.Equal(t, want, normalizeImage(image), image)
+	}
+}
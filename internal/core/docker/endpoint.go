@@ -0,0 +1,170 @@
+package docker
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/docker/docker/client"
+
+	"github.com/isaacgarza/dev-stack/internal/pkg/utils"
+)
+
+// defaultContext is the context of the engine the platform's default
+// endpoint reaches: the unix socket, or the docker_engine named pipe on
+// Windows
+const defaultContext = "default"
+
+// Endpoint is the Docker engine the client connects to
+type Endpoint struct {
+	// Host is the engine's address, such as unix:///var/run/docker.sock or
+	// npipe:////./pipe/dockerDesktopLinuxEngine. It is empty for the
+	// platform's default.
+	Host string
+	// Context is the docker CLI context the endpoint comes from, empty when
+	// DOCKER_HOST sets it
+	Context string
+	// TLSDir holds the ca.pem, cert.pem and key.pem of the context, if it
+	// has any
+	TLSDir string
+}
+
+// contextMeta is the part of a context's meta.json dev-stack reads
+type contextMeta struct {
+	Endpoints map[string]struct {
+		Host string `json:"Host"`
+	} `json:"Endpoints"`
+}
+
+// ResolveEndpoint returns the engine the docker CLI talks to: DOCKER_HOST
+// if set, otherwise the endpoint of DOCKER_CONTEXT or of the current
+// context in the docker config. Docker Desktop, Colima and OrbStack all
+// switch contexts rather than the default socket, so following them keeps
+// dev-stack and docker compose on the same engine.
+func ResolveEndpoint() (Endpoint, error) {
+	if host := os.Getenv(client.EnvOverrideHost); host != "" {
+		return Endpoint{Host: host}, checkHost(host, runtime.GOOS)
+	}
+
+	configDir := dockerConfigDir()
+	name := os.Getenv("DOCKER_CONTEXT")
+	if name == "" {
+		name = currentContext(configDir)
+	}
+	endpoint, err := contextEndpoint(configDir, name)
+	if err != nil {
+		return Endpoint{}, err
+	}
+	return endpoint, checkHost(endpoint.Host, runtime.GOOS)
+}
+
+// dockerConfigDir returns DOCKER_CONFIG, or ~/.docker
+func dockerConfigDir() string {
+	if dir := os.Getenv("DOCKER_CONFIG"); dir != "" {
+		return dir
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".docker")
+}
+
+// currentContext returns the context config.json in configDir selects, or
+// an empty string when it selects none
+func currentContext(configDir string) string {
+	data, err := os.ReadFile(filepath.Join(configDir, "config.json"))
+	if err != nil {
+		return ""
+	}
+	var config struct {
+		CurrentContext string `json:"currentContext"`
+	}
+	if err := json.Unmarshal(data, &config); err != nil {
+		return ""
+	}
+	return config.CurrentContext
+}
+
+// contextEndpoint returns the docker endpoint of a context stored under
+// configDir. Contexts are kept in directories named by the SHA-256 of
+// their name; the default context has none and its host is empty.
+func contextEndpoint(configDir, name string) (Endpoint, error) {
+	if name == "" || name == defaultContext {
+		return Endpoint{}, nil
+	}
+
+	digest := sha256.Sum256([]byte(name))
+	id := hex.EncodeToString(digest[:])
+	data, err := os.ReadFile(filepath.Join(configDir, "contexts", "meta", id, "meta.json"))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return Endpoint{}, fmt.Errorf("docker context %q not found: run 'docker context ls' to list contexts", name)
+		}
+		return Endpoint{}, fmt.Errorf("failed to read docker context %s: %w", name, err)
+	}
+
+	var meta contextMeta
+	if err := json.Unmarshal(data, &meta); err != nil {
+		return Endpoint{}, fmt.Errorf("failed to parse docker context %s: %w", name, err)
+	}
+	host := meta.Endpoints["docker"].Host
+	if host == "" {
+		return Endpoint{}, fmt.Errorf("docker context %q has no docker endpoint", name)
+	}
+
+	endpoint := Endpoint{Host: host, Context: name}
+	if tlsDir := filepath.Join(configDir, "contexts", "tls", id, "docker"); utils.DirExists(tlsDir) {
+		endpoint.TLSDir = tlsDir
+	}
+	return endpoint, nil
+}
+
+// checkHost rejects hosts the client can't connect to on goos: named pipes
+// outside Windows, and ssh endpoints, which need the docker CLI to tunnel
+func checkHost(host, goos string) error {
+	scheme, _, _ := strings.Cut(host, "://")
+	switch scheme {
+	case "npipe":
+		if goos != utils.OSWindows {
+			return fmt.Errorf("docker endpoint %s is a named pipe, which only exists on Windows", host)
+		}
+	case "ssh":
+		return fmt.Errorf("docker endpoint %s connects over ssh, which dev-stack doesn't support: set DOCKER_HOST to a tcp, unix or npipe endpoint", host)
+	}
+	return nil
+}
+
+// options returns the client options that connect to the endpoint
+func (e Endpoint) options() []client.Opt {
+	var opts []client.Opt
+	if e.Host != "" && e.Context != "" {
+		opts = append(opts, client.WithHost(e.Host))
+	}
+	if e.TLSDir != "" {
+		opts = append(opts, client.WithTLSClientConfig(
+			filepath.Join(e.TLSDir, "ca.pem"),
+			filepath.Join(e.TLSDir, "cert.pem"),
+			filepath.Join(e.TLSDir, "key.pem"),
+		))
+	}
+	return opts
+}
+
+// String describes the endpoint for diagnostics
+func (e Endpoint) String() string {
+	host := e.Host
+	if host == "" {
+		host = client.DefaultDockerHost
+	}
+	if e.Context != "" {
+		return fmt.Sprintf("%s (context %s)", host, e.Context)
+	}
+	return host
+}
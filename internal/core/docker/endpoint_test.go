@@ -0,0 +1,111 @@
+package docker
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeContext stores a docker CLI context in configDir as docker context
+// create does
+func writeContext(t *testing.T, configDir, name, meta string) string {
+	digest := sha256.Sum256([]byte(name))
+	id := hex.EncodeToString(digest[:])
+	dir := filepath.Join(configDir, "contexts", "meta", id)
+	require.NoError(t, os.MkdirAll(dir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "meta.json"), []byte(meta), 0644))
+	return id
+}
+
+func TestCurrentContext(t *testing.T) {
+	configDir := t.TempDir()
+	assert.Empty(t, currentContext(configDir))
+
+	require.NoError(t, os.WriteFile(filepath.Join(configDir, "config.json"), []byte(`{"auths":{},"currentContext":"desktop-linux"}`), 0644))
+	assert.Equal(t, "desktop-linux", currentContext(configDir))
+}
+
+func TestContextEndpoint(t *testing.T) {
+	configDir := t.TempDir()
+	writeContext(t, configDir, "desktop-linux", `{"Name":"desktop-linux","Endpoints":{"docker":{"Host":"npipe:////./pipe/dockerDesktopLinuxEngine","SkipTLSVerify":false}}}`)
+	id := writeContext(t, configDir, "remote", `{"Name":"remote","Endpoints":{"docker":{"Host":"tcp://build.internal:2376"}}}`)
+	writeContext(t, configDir, "empty", `{"Name":"empty","Endpoints":{}}`)
+	require.NoError(t, os.MkdirAll(filepath.Join(configDir, "contexts", "tls", id, "docker"), 0755))
+
+	endpoint, err := contextEndpoint(configDir, "")
+	require.NoError(t, err)
+	assert.Equal(t, Endpoint{}, endpoint)
+	endpoint, err = contextEndpoint(configDir, defaultContext)
+	require.NoError(t, err)
+	assert.Equal(t, Endpoint{}, endpoint)
+
+	endpoint, err = contextEndpoint(configDir, "desktop-linux")
+	require.NoError(t, err)
+	assert.Equal(t, Endpoint{Host: "npipe:////./pipe/dockerDesktopLinuxEngine", Context: "desktop-linux"}, endpoint)
+	assert.Len(t, endpoint.options(), 1)
+
+	endpoint, err = contextEndpoint(configDir, "remote")
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(configDir, "contexts", "tls", id, "docker"), endpoint.TLSDir)
+	assert.Len(t, endpoint.options(), 2)
+	assert.Equal(t, "tcp://build.internal:2376 (context remote)", endpoint.String())
+
+	_, err = contextEndpoint(configDir, "empty")
+	assert.ErrorContains(t, err, "no docker endpoint")
+	_, err = contextEndpoint(configDir, "missing")
+	assert.ErrorContains(t, err, "not found")
+}
+
+func TestCheckHost(t *testing.T) {
+	tests := []struct {
+		host    string
+		goos    string
+		wantErr string
+	}{
+		{host: "unix:///var/run/docker.sock", goos: "linux"},
+		{host: "tcp://localhost:2375", goos: "darwin"},
+		{host: "npipe:////./pipe/docker_engine", goos: "windows"},
+		{host: "npipe:////./pipe/docker_engine", goos: "linux", wantErr: "only exists on Windows"},
+		{host: "ssh://me@build", goos: "linux", wantErr: "ssh"},
+		{host: "", goos: "windows"},
+	}
+
+	for _, tt := range tests {
+		err := checkHost(tt.host, tt.goos)
+		if tt.wantErr == "" {
+			assert.NoError(t, err, tt.host)
+		} else {
+			assert.ErrorContains(t, err, tt.wantErr, tt.host)
+		}
+	}
+}
+
+func TestResolveEndpoint(t *testing.T) {
+	configDir := t.TempDir()
+	writeContext(t, configDir, "colima", `{"Name":"colima","Endpoints":{"docker":{"Host":"unix:///home/me/.colima/default/docker.sock"}}}`)
+	require.NoError(t, os.WriteFile(filepath.Join(configDir, "config.json"), []byte(`{"currentContext":"colima"}`), 0644))
+	t.Setenv("DOCKER_CONFIG", configDir)
+	t.Setenv("DOCKER_HOST", "")
+	t.Setenv("DOCKER_CONTEXT", "")
+
+	endpoint, err := ResolveEndpoint()
+	require.NoError(t, err)
+	assert.Equal(t, "unix:///home/me/.colima/default/docker.sock", endpoint.Host)
+
+	// DOCKER_CONTEXT wins over the current context, DOCKER_HOST over both
+	t.Setenv("DOCKER_CONTEXT", defaultContext)
+	endpoint, err = ResolveEndpoint()
+	require.NoError(t, err)
+	assert.Empty(t, endpoint.Host)
+
+	t.Setenv("DOCKER_HOST", "tcp://localhost:2375")
+	endpoint, err = ResolveEndpoint()
+	require.NoError(t, err)
+	assert.Equal(t, Endpoint{Host: "tcp://localhost:2375"}, endpoint)
+	assert.Empty(t, endpoint.options())
+}
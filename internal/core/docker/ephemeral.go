@@ -0,0 +1,81 @@
+package docker
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/filters"
+
+	"github.com/isaacgarza/dev-stack/internal/pkg/compose"
+	"github.com/isaacgarza/dev-stack/internal/pkg/constants"
+	"github.com/isaacgarza/dev-stack/internal/pkg/types"
+)
+
+// EphemeralStacks returns the ephemeral stacks on the Docker host, of every
+// project, sorted by expiry. A stack expires with its earliest container.
+func (cl *ContainerLifecycle) EphemeralStacks(ctx context.Context) ([]types.EphemeralStack, error) {
+	containers, err := cl.client.api().ContainerList(ctx, container.ListOptions{
+		All:     true,
+		Filters: filters.NewArgs(filters.Arg("label", compose.EphemeralLabel+"=true")),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list containers: %w", err)
+	}
+
+	stacks := make(map[string]*types.EphemeralStack)
+	for _, c := range containers {
+		project := c.Labels[constants.ComposeProjectLabel]
+		expiresAt, ok := compose.ParseExpiresAt(c.Labels)
+		if project == "" || !ok {
+			continue
+		}
+
+		stack, exists := stacks[project]
+		if !exists {
+			stack = &types.EphemeralStack{Project: project, ExpiresAt: expiresAt}
+			stacks[project] = stack
+		}
+		if expiresAt.Before(stack.ExpiresAt) {
+			stack.ExpiresAt = expiresAt
+		}
+		stack.Containers = append(stack.Containers, c.ID)
+	}
+
+	result := make([]types.EphemeralStack, 0, len(stacks))
+	for _, stack := range stacks {
+		result = append(result, *stack)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if !result[i].ExpiresAt.Equal(result[j].ExpiresAt) {
+			return result[i].ExpiresAt.Before(result[j].ExpiresAt)
+		}
+		return result[i].Project < result[j].Project
+	})
+	return result, nil
+}
+
+// RemoveEphemeral removes the containers of an ephemeral stack together with
+// their anonymous volumes, then the project's networks. Named volumes are
+// left alone: ephemeral services don't mount them, and they may hold the
+// data of the project's regular stack.
+func (cl *ContainerLifecycle) RemoveEphemeral(ctx context.Context, stack types.EphemeralStack) error {
+	cl.client.logger.Info("Removing ephemeral stack", "project", stack.Project, "expires_at", stack.ExpiresAt)
+
+	var failed int
+	for _, id := range stack.Containers {
+		if err := cl.client.api().ContainerRemove(ctx, id, container.RemoveOptions{
+			RemoveVolumes: true,
+			Force:         true,
+		}); err != nil {
+			cl.client.logger.Error("Failed to remove container", "container", id, "error", err)
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("failed to remove %d of %d containers of %s", failed, len(stack.Containers), stack.Project)
+	}
+
+	return cl.client.Networks().Remove(ctx, stack.Project)
+}
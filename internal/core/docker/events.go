@@ -0,0 +1,104 @@
+package docker
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/api/types/events"
+	"github.com/docker/docker/api/types/filters"
+
+	"github.com/isaacgarza/dev-stack/internal/pkg/constants"
+	"github.com/isaacgarza/dev-stack/internal/pkg/types"
+)
+
+// Events streams the lifecycle events of the containers of the given
+// services, or of every service of the project when none are given, from
+// since when it is set. Both channels are closed once ctx is done or the
+// stream fails, in which case the error is sent first.
+func (cs *ContainerService) Events(ctx context.Context, projectName string, serviceNames []string, since time.Time) (<-chan types.ServiceEvent, <-chan error) {
+	args := filters.NewArgs(
+		filters.Arg("type", string(events.ContainerEventType)),
+		filters.Arg("label", projectLabel(projectName)),
+	)
+	for _, action := range types.ServiceEventActions() {
+		args.Add("event", action)
+	}
+	options := events.ListOptions{Filters: args}
+	if !since.IsZero() {
+		options.Since = strconv.FormatInt(since.Unix(), 10)
+	}
+
+	messages, errs := cs.client.api().Events(ctx, options)
+	out := make(chan types.ServiceEvent)
+	outErrs := make(chan error, 1)
+	go func() {
+		defer close(out)
+		defer close(outErrs)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case err := <-errs:
+				if ctx.Err() == nil {
+					outErrs <- fmt.Errorf("failed to read Docker events: %w", err)
+				}
+				return
+			case message := <-messages:
+				event, ok := toServiceEvent(message)
+				if !ok || (len(serviceNames) > 0 && !slices.Contains(serviceNames, event.Service)) {
+					continue
+				}
+				select {
+				case out <- event:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+	return out, outErrs
+}
+
+// toServiceEvent converts a Docker container event, reporting false for
+// events of other kinds or of containers compose didn't create
+func toServiceEvent(message events.Message) (types.ServiceEvent, bool) {
+	attributes := message.Actor.Attributes
+	serviceName := attributes[constants.ComposeServiceLabel]
+	if message.Type != events.ContainerEventType || serviceName == "" {
+		return types.ServiceEvent{}, false
+	}
+
+	event := types.ServiceEvent{
+		Time:      time.Unix(0, message.TimeNano),
+		Project:   attributes[constants.ComposeProjectLabel],
+		Service:   serviceName,
+		Container: attributes["name"],
+		Action:    string(message.Action),
+	}
+	if message.TimeNano == 0 {
+		event.Time = time.Unix(message.Time, 0)
+	}
+	if event.Container == "" {
+		event.Container = message.Actor.ID[:min(12, len(message.Actor.ID))]
+	}
+
+	// Health events carry the new status in the action, as in
+	// "health_status: unhealthy"
+	if health, ok := strings.CutPrefix(event.Action, types.EventHealthStatus+":"); ok {
+		event.Action = types.EventHealthStatus
+		event.Health = strings.TrimSpace(health)
+	}
+	if !slices.Contains(types.ServiceEventActions(), event.Action) {
+		return types.ServiceEvent{}, false
+	}
+	if event.Action == types.EventDie {
+		if code, err := strconv.Atoi(attributes["exitCode"]); err == nil {
+			event.ExitCode = &code
+		}
+	}
+	return event, true
+}
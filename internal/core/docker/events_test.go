@@ -0,0 +1,52 @@
+package docker
+
+import (
+	"testing"
+	"time"
+
+	"github.com/docker/docker/api/types/events"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/isaacgarza/dev-stack/internal/pkg/types"
+)
+
+func TestToServiceEvent(t *testing.T) {
+	at := time.Date(2026, 10, 14, 12, 0, 0, 0, time.UTC)
+	message := func(action string, attributes map[string]string) events.Message {
+		attributes["com.docker.compose.project"] = "shop"
+		attributes["com.docker.compose.service"] = "postgres"
+		attributes["name"] = "shop-postgres-1"
+		return events.Message{
+			Type:     events.ContainerEventType,
+			Action:   events.Action(action),
+			Actor:    events.Actor{ID: "0123456789abcdef", Attributes: attributes},
+			TimeNano: at.UnixNano(),
+		}
+	}
+
+	event, ok := toServiceEvent(message("die", map[string]string{"exitCode": "137"}))
+	require.True(t, ok)
+	require.NotNil(t, event.ExitCode)
+	assert.Equal(t, 137, *event.ExitCode)
+	assert.Equal(t, types.ServiceEvent{
+		Time:      at.Local(),
+		Project:   "shop",
+		Service:   "postgres",
+		Container: "shop-postgres-1",
+		Action:    types.EventDie,
+		ExitCode:  event.ExitCode,
+	}, event)
+
+	event, ok = toServiceEvent(message("health_status: unhealthy", map[string]string{}))
+	require.True(t, ok)
+	assert.Equal(t, types.EventHealthStatus, event.Action)
+	assert.Equal(t, "unhealthy", event.Health)
+	assert.Nil(t, event.ExitCode)
+
+	_, ok = toServiceEvent(message("exec_start: sh", map[string]string{}))
+	assert.False(t, ok)
+
+	_, ok = toServiceEvent(events.Message{Type: events.ContainerEventType, Action: events.ActionStart})
+	assert.False(t, ok, "containers compose didn't create are skipped")
+}
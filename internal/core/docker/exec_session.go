@@ -0,0 +1,283 @@
+package docker
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/pkg/stdcopy"
+	"golang.org/x/term"
+
+	"github.com/isaacgarza/dev-stack/internal/pkg/types"
+)
+
+// signalTimeout bounds the helper commands that deliver a forwarded signal
+const signalTimeout = 5 * time.Second
+
+// execSession is an exec attached to the terminal of the dev-stack process
+type execSession struct {
+	executor    *ContainerExecutor
+	containerID string
+	execID      string
+	cmd         []string
+	tty         bool
+	conn        io.Writer
+}
+
+// Exec runs a command in a running container attached to the terminal. With
+// options.TTY the local terminal is put in raw mode and its size follows the
+// window; signals received by dev-stack are forwarded to the command. A
+// non-zero exit is returned as a *types.ExitError carrying the exit code.
+func (ce *ContainerExecutor) Exec(ctx context.Context, projectName, serviceName string, cmd []string, options types.ExecOptions) error {
+	containerID, err := ce.findServiceContainer(ctx, projectName, serviceName)
+	if err != nil {
+		return err
+	}
+
+	// Interrupts are forwarded to the command, which decides when the
+	// session ends, so they must not cut it short
+	ctx = context.WithoutCancel(ctx)
+
+	stdinFd := int(os.Stdin.Fd())
+	terminal := options.TTY && term.IsTerminal(stdinFd)
+
+	config := container.ExecOptions{
+		Cmd:          cmd,
+		User:         options.User,
+		WorkingDir:   options.WorkingDir,
+		Env:          options.Env,
+		Tty:          options.TTY,
+		AttachStdin:  options.Interactive && !options.Detach,
+		AttachStdout: !options.Detach,
+		AttachStderr: !options.Detach,
+	}
+	if terminal {
+		config.ConsoleSize = terminalSize(stdinFd)
+	}
+
+	exec, err := ce.client.api().ContainerExecCreate(ctx, containerID, config)
+	if err != nil {
+		return fmt.Errorf("failed to create exec instance: %w", err)
+	}
+
+	if options.Detach {
+		if err := ce.client.api().ContainerExecStart(ctx, exec.ID, container.ExecStartOptions{Detach: true}); err != nil {
+			return fmt.Errorf("failed to start exec instance: %w", err)
+		}
+		return nil
+	}
+
+	resp, err := ce.client.api().ContainerExecAttach(ctx, exec.ID, container.ExecAttachOptions{
+		Tty:         options.TTY,
+		ConsoleSize: config.ConsoleSize,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to attach to exec instance: %w", err)
+	}
+	defer resp.Close()
+
+	if terminal && options.Interactive {
+		state, err := term.MakeRaw(stdinFd)
+		if err != nil {
+			return fmt.Errorf("failed to set terminal to raw mode: %w", err)
+		}
+		defer func() {
+			if err := term.Restore(stdinFd, state); err != nil {
+				ce.client.logger.Error("Failed to restore terminal", "error", err)
+			}
+		}()
+	}
+
+	session := &execSession{executor: ce, containerID: containerID, execID: exec.ID, cmd: cmd, tty: options.TTY, conn: resp.Conn}
+	stopSignals := session.forwardSignals(ctx, terminal)
+	defer stopSignals()
+
+	if options.Interactive {
+		// Never waited for: stdin may stay open after the command exits
+		go func() {
+			if _, err := io.Copy(resp.Conn, os.Stdin); err == nil {
+				_ = resp.CloseWrite()
+			}
+		}()
+	}
+
+	if options.TTY {
+		_, err = io.Copy(os.Stdout, resp.Reader)
+	} else {
+		_, err = stdcopy.StdCopy(os.Stdout, os.Stderr, resp.Reader)
+	}
+	if err != nil && ctx.Err() == nil {
+		ce.client.logger.Error("Failed to copy output", "error", err)
+	}
+
+	exitCode, err := ce.execExitCode(ctx, exec.ID)
+	if err != nil {
+		return err
+	}
+	if exitCode != 0 {
+		return &types.ExitError{Command: cmd[0], Code: exitCode}
+	}
+	return nil
+}
+
+// execExitCode waits for an exec whose output has ended to be reported as
+// finished and returns its exit code
+func (ce *ContainerExecutor) execExitCode(ctx context.Context, execID string) (int, error) {
+	for attempt := 0; ; attempt++ {
+		inspect, err := ce.client.api().ContainerExecInspect(ctx, execID)
+		if err != nil {
+			return 0, fmt.Errorf("failed to inspect exec instance: %w", err)
+		}
+		if !inspect.Running || attempt >= 50 {
+			return inspect.ExitCode, nil
+		}
+		select {
+		case <-ctx.Done():
+			return 0, ctx.Err()
+		case <-time.After(20 * time.Millisecond):
+		}
+	}
+}
+
+// forwardSignals relays signals received by dev-stack to the command until
+// the returned function is called. When attached to a terminal, window size
+// changes are relayed too.
+func (s *execSession) forwardSignals(ctx context.Context, terminal bool) func() {
+	signals := make(chan os.Signal, 8)
+	signal.Notify(signals, forwardedSignals...)
+	if terminal && resizeSignal != nil {
+		signal.Notify(signals, resizeSignal)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case <-done:
+				return
+			case sig := <-signals:
+				if sig == resizeSignal {
+					s.resize(ctx)
+					continue
+				}
+				if err := s.signal(sig); err != nil {
+					s.executor.client.logger.Warn("Failed to forward signal", "signal", sig, "error", err)
+				}
+			}
+		}
+	}()
+
+	return func() {
+		signal.Stop(signals)
+		close(done)
+	}
+}
+
+// resize sets the exec's terminal to the size of the local one
+func (s *execSession) resize(ctx context.Context) {
+	size := terminalSize(int(os.Stdin.Fd()))
+	if size == nil {
+		return
+	}
+	if err := s.executor.client.api().ContainerExecResize(ctx, s.execID, container.ResizeOptions{Height: size[0], Width: size[1]}); err != nil {
+		s.executor.client.logger.Debug("Failed to resize exec terminal", "error", err)
+	}
+}
+
+// signal delivers sig to the command. On a TTY, signals with a terminal
+// control character are typed into it, so the container's line discipline
+// signals the foreground process group the same way a local terminal would.
+// Otherwise the command's process is looked up in the container and killed
+// with the signal, which needs sh and kill in the image.
+func (s *execSession) signal(sig os.Signal) error {
+	if s.tty {
+		if char, ok := controlCharacter(sig); ok {
+			_, err := s.conn.Write([]byte{char})
+			return err
+		}
+	}
+
+	name := signalName(sig)
+	if name == "" {
+		return fmt.Errorf("signal %v cannot be forwarded", sig)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), signalTimeout)
+	defer cancel()
+
+	pid, err := s.findPID(ctx)
+	if err != nil {
+		return err
+	}
+	if err := s.executor.runHelper(ctx, s.containerID, []string{"kill", "-s", name, strconv.Itoa(pid)}); err != nil {
+		return fmt.Errorf("failed to send SIG%s to %s: %w", name, s.cmd[0], err)
+	}
+	return nil
+}
+
+// findPID returns the PID, in the container's namespace, of the command.
+// Exec inspect reports the host PID, which is of no use on Docker Desktop,
+// so /proc is read inside the container instead.
+func (s *execSession) findPID(ctx context.Context) (int, error) {
+	output, err := s.executor.captureHelper(ctx, s.containerID, []string{"sh", "-c", procListScript})
+	if err != nil {
+		return 0, fmt.Errorf("failed to list processes of the container: %w", err)
+	}
+	pid := matchProcess(output, s.cmd)
+	if pid == 0 {
+		return 0, fmt.Errorf("process of %s not found in the container", s.cmd[0])
+	}
+	return pid, nil
+}
+
+// procListScript prints "<pid> <argv...>" for each process of the container
+const procListScript = `for d in /proc/[0-9]*; do printf '%s ' "${d#/proc/}"; tr '\0' ' ' < "$d/cmdline" 2>/dev/null; echo; done`
+
+// matchProcess returns the newest PID in a procListScript listing whose
+// command line is exactly cmd, or 0 when there is none
+func matchProcess(listing string, cmd []string) int {
+	want := strings.Join(cmd, " ")
+	newest := 0
+	for _, line := range strings.Split(listing, "\n") {
+		pidField, args, ok := strings.Cut(line, " ")
+		if !ok || strings.TrimRight(args, " ") != want {
+			continue
+		}
+		if pid, err := strconv.Atoi(pidField); err == nil && pid > newest {
+			newest = pid
+		}
+	}
+	return newest
+}
+
+// runHelper runs a short command in a container and fails if it exits
+// non-zero
+func (ce *ContainerExecutor) runHelper(ctx context.Context, containerID string, cmd []string) error {
+	_, err := ce.captureHelper(ctx, containerID, cmd)
+	return err
+}
+
+// captureHelper runs a short command in a container and returns its stdout
+func (ce *ContainerExecutor) captureHelper(ctx context.Context, containerID string, cmd []string) (string, error) {
+	var stdout strings.Builder
+	if err := ce.execAttached(ctx, containerID, cmd, nil, &stdout, types.ExecOptions{}); err != nil {
+		return "", err
+	}
+	return stdout.String(), nil
+}
+
+// terminalSize returns the [height, width] of the terminal on fd, or nil
+// when it cannot be read
+func terminalSize(fd int) *[2]uint {
+	width, height, err := term.GetSize(fd)
+	if err != nil || width <= 0 || height <= 0 {
+		return nil
+	}
+	return &[2]uint{uint(height), uint(width)}
+}
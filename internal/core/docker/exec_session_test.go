@@ -0,0 +1,20 @@
+package docker
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMatchProcess(t *testing.T) {
+	listing := "1 postgres -D /var/lib/postgresql/data \n" +
+		"57 sleep 300 \n" +
+		"64 sleep 30 \n" +
+		"71 sleep 300 \n" +
+		"80 sh -c for d in /proc/[0-9]*; do ... done \n" +
+		"81 \n"
+
+	assert.Equal(t, 71, matchProcess(listing, []string{"sleep", "300"}))
+	assert.Equal(t, 64, matchProcess(listing, []string{"sleep", "30"}))
+	assert.Equal(t, 0, matchProcess(listing, []string{"psql"}))
+}
@@ -0,0 +1,53 @@
+//go:build !windows
+
+package docker
+
+import (
+	"os"
+	"syscall"
+)
+
+// forwardedSignals are relayed from dev-stack to a command run with exec
+var forwardedSignals = []os.Signal{
+	syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP, syscall.SIGQUIT,
+	syscall.SIGTSTP, syscall.SIGCONT, syscall.SIGUSR1, syscall.SIGUSR2,
+}
+
+// resizeSignal reports that the terminal window changed size
+var resizeSignal os.Signal = syscall.SIGWINCH
+
+// controlCharacter returns the character a terminal turns into sig
+func controlCharacter(sig os.Signal) (byte, bool) {
+	switch sig {
+	case syscall.SIGINT:
+		return 0x03, true // Ctrl+C
+	case syscall.SIGQUIT:
+		return 0x1c, true // Ctrl+\
+	case syscall.SIGTSTP:
+		return 0x1a, true // Ctrl+Z
+	}
+	return 0, false
+}
+
+// signalName returns the name kill -s accepts for sig
+func signalName(sig os.Signal) string {
+	switch sig {
+	case syscall.SIGINT:
+		return "INT"
+	case syscall.SIGTERM:
+		return "TERM"
+	case syscall.SIGHUP:
+		return "HUP"
+	case syscall.SIGQUIT:
+		return "QUIT"
+	case syscall.SIGTSTP:
+		return "TSTP"
+	case syscall.SIGCONT:
+		return "CONT"
+	case syscall.SIGUSR1:
+		return "USR1"
+	case syscall.SIGUSR2:
+		return "USR2"
+	}
+	return ""
+}
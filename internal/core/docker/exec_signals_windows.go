@@ -0,0 +1,28 @@
+//go:build windows
+
+package docker
+
+import "os"
+
+// forwardedSignals are relayed from dev-stack to a command run with exec
+var forwardedSignals = []os.Signal{os.Interrupt}
+
+// resizeSignal is nil on Windows, which has no window size signal; the
+// terminal keeps the size it had when the command started
+var resizeSignal os.Signal
+
+// controlCharacter returns the character a terminal turns into sig
+func controlCharacter(sig os.Signal) (byte, bool) {
+	if sig == os.Interrupt {
+		return 0x03, true // Ctrl+C
+	}
+	return 0, false
+}
+
+// signalName returns the name kill -s accepts for sig
+func signalName(sig os.Signal) string {
+	if sig == os.Interrupt {
+		return "INT"
+	}
+	return ""
+}
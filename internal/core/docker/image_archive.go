@@ -0,0 +1,89 @@
+package docker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/docker/docker/client"
+)
+
+// loadMessage is the subset of the daemon's JSON stream inspected while
+// loading an image archive
+type loadMessage struct {
+	Stream string `json:"stream,omitempty"`
+	Error  *struct {
+		Message string `json:"message"`
+	} `json:"errorDetail,omitempty"`
+}
+
+// Missing returns the images in images that are not present locally
+func (is *ImageService) Missing(ctx context.Context, images []string) ([]string, error) {
+	var missing []string
+	for _, ref := range uniqueImages(images) {
+		if _, err := is.client.api().ImageInspect(ctx, ref); err != nil {
+			if client.IsErrNotFound(err) {
+				missing = append(missing, ref)
+				continue
+			}
+			return nil, fmt.Errorf("failed to inspect image %s: %w", ref, err)
+		}
+	}
+	return missing, nil
+}
+
+// Save writes the given images to w as a single archive in the format of
+// docker save, which Load and docker load read back
+func (is *ImageService) Save(ctx context.Context, images []string, w io.Writer) error {
+	reader, err := is.client.api().ImageSave(ctx, uniqueImages(images))
+	if err != nil {
+		return fmt.Errorf("failed to save images: %w", err)
+	}
+	defer func() { _ = reader.Close() }()
+
+	if _, err := io.Copy(w, reader); err != nil {
+		return fmt.Errorf("failed to write image archive: %w", err)
+	}
+	return nil
+}
+
+// Load loads the images in an archive written by Save or docker save and
+// returns their references
+func (is *ImageService) Load(ctx context.Context, r io.Reader) ([]string, error) {
+	resp, err := is.client.api().ImageLoad(ctx, r, client.ImageLoadWithQuiet(true))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load images: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	return loadedImages(resp.Body)
+}
+
+// loadedImages reads the references of the loaded images from the daemon's
+// load stream
+func loadedImages(r io.Reader) ([]string, error) {
+	var loaded []string
+	decoder := json.NewDecoder(r)
+	for {
+		var msg loadMessage
+		if err := decoder.Decode(&msg); err != nil {
+			if errors.Is(err, io.EOF) {
+				return loaded, nil
+			}
+			return loaded, fmt.Errorf("failed to read load output: %w", err)
+		}
+		if msg.Error != nil {
+			return loaded, errors.New(msg.Error.Message)
+		}
+		for _, line := range strings.Split(msg.Stream, "\n") {
+			for _, prefix := range []string{"Loaded image: ", "Loaded image ID: "} {
+				if ref, ok := strings.CutPrefix(strings.TrimSpace(line), prefix); ok {
+					loaded = append(loaded, ref)
+				}
+			}
+		}
+	}
+}
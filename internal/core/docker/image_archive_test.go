@@ -0,0 +1,28 @@
+package docker
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadedImages(t *testing.T) {
+	stream := `{"stream":"Loaded image: postgres:15-alpine\n"}
+{"stream":"Loaded image: redis:7-alpine\n"}
+{"stream":"Loaded image ID: sha256:0123\n"}
+`
+	loaded, err := loadedImages(strings.NewReader(stream))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"postgres:15-alpine", "redis:7-alpine", "sha256:0123"}, loaded)
+}
+
+func TestLoadedImages_Error(t *testing.T) {
+	stream := `{"stream":"Loaded image: postgres:15-alpine\n"}
+{"errorDetail":{"message":"unexpected EOF"},"error":"unexpected EOF"}
+`
+	loaded, err := loadedImages(strings.NewReader(stream))
+	assert.EqualError(t, err, "unexpected EOF")
+	assert.Equal(t, []string{"postgres:15-alpine"}, loaded)
+}
@@ -0,0 +1,414 @@
+package docker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/docker/docker/api/types/image"
+
+	"github.com/isaacgarza/dev-stack/internal/pkg/types"
+	"github.com/isaacgarza/dev-stack/internal/pkg/utils"
+)
+
+const (
+	defaultPullConcurrency = 3
+	defaultPullRetries     = 3
+	defaultPullRetryDelay  = 2 * time.Second
+	dockerHubRegistry      = "docker.io"
+)
+
+// pullMessage is the subset of the daemon's JSON progress stream inspected
+// while draining a pull
+type pullMessage struct {
+	ID             string `json:"id,omitempty"`
+	Status         string `json:"status,omitempty"`
+	ProgressDetail struct {
+		Current int64 `json:"current"`
+		Total   int64 `json:"total"`
+	} `json:"progressDetail"`
+	Error *struct {
+		Message string `json:"message"`
+	} `json:"errorDetail,omitempty"`
+}
+
+// PullResult describes the outcome of pulling a single image
+type PullResult struct {
+	Image  string `json:"image"`
+	Source string `json:"source,omitempty"`
+	// Digest is the content digest the registry served the image as, which
+	// pins it as image@digest
+	Digest   string        `json:"digest,omitempty"`
+	Attempts int           `json:"attempts"`
+	Duration time.Duration `json:"duration"`
+	Error    string        `json:"error,omitempty"`
+}
+
+// PullSummary consolidates the results of a batch of image pulls
+type PullSummary struct {
+	Results  []PullResult  `json:"results"`
+	Pulled   int           `json:"pulled"`
+	Failed   int           `json:"failed"`
+	Duration time.Duration `json:"duration"`
+}
+
+// String returns a one-line human readable summary
+func (s *PullSummary) String() string {
+	return fmt.Sprintf("%d pulled, %d failed in %s", s.Pulled, s.Failed, s.Duration.Round(time.Millisecond))
+}
+
+// FailedImages returns the sorted list of images that could not be pulled
+func (s *PullSummary) FailedImages() []string {
+	var failed []string
+	for _, result := range s.Results {
+		if result.Error != "" {
+			failed = append(failed, result.Image)
+		}
+	}
+	sort.Strings(failed)
+	return failed
+}
+
+// ImagePuller pulls images through the Docker API with bounded concurrency,
+// retries on transient failures and failover between registry mirrors
+type ImagePuller struct {
+	client *Client
+}
+
+// NewImagePuller creates a new image puller
+func NewImagePuller(client *Client) *ImagePuller {
+	return &ImagePuller{client: client}
+}
+
+// Pull pulls all images and returns a consolidated summary. An error is only
+// returned when the context is cancelled; per-image failures are reported in
+// the summary so callers can decide whether to continue.
+func (ip *ImagePuller) Pull(ctx context.Context, images []string, options types.PullOptions) (*PullSummary, error) {
+	concurrency := options.Concurrency
+	if concurrency <= 0 {
+		concurrency = defaultPullConcurrency
+	}
+
+	start := time.Now()
+	images = uniqueImages(images)
+	results := make([]PullResult, len(images))
+	semaphore := make(chan struct{}, concurrency)
+
+	var wg sync.WaitGroup
+	for i, ref := range images {
+		wg.Add(1)
+		go func(i int, ref string) {
+			defer wg.Done()
+
+			select {
+			case semaphore <- struct{}{}:
+				defer func() { <-semaphore }()
+			case <-ctx.Done():
+				results[i] = PullResult{Image: ref, Error: ctx.Err().Error()}
+				return
+			}
+
+			results[i] = ip.pullImage(ctx, ref, options)
+		}(i, ref)
+	}
+	wg.Wait()
+
+	summary := &PullSummary{Results: results, Duration: time.Since(start)}
+	for _, result := range results {
+		if result.Error != "" {
+			summary.Failed++
+		} else {
+			summary.Pulled++
+		}
+	}
+
+	ip.client.logger.Info("Image pull completed", "pulled", summary.Pulled, "failed", summary.Failed, "duration", summary.Duration)
+	return summary, ctx.Err()
+}
+
+// pullImage pulls a single image, trying each candidate source in turn,
+// within the pull timeout
+func (ip *ImagePuller) pullImage(ctx context.Context, ref string, options types.PullOptions) PullResult {
+	start := time.Now()
+	result := PullResult{Image: ref}
+
+	progress := progressOf(options.Progress)
+	progress.Update(ref, "pulling", 0, 0)
+	err := withTimeout(ctx, opPull, "pulling "+ref, func(ctx context.Context) error {
+		return ip.pullSources(ctx, ref, options, &result)
+	})
+	result.Duration = time.Since(start)
+	switch {
+	case err != nil:
+		result.Error = err.Error()
+		progress.Fail(ref, result.Error)
+	case result.Source != ref:
+		progress.Done(ref, fmt.Sprintf("pulled from %s in %s", result.Source, utils.FormatDuration(result.Duration)))
+	default:
+		progress.Done(ref, "pulled in "+utils.FormatDuration(result.Duration))
+	}
+	return result
+}
+
+// pullSources pulls ref from the first candidate source that has it,
+// recording the attempts and source in result
+func (ip *ImagePuller) pullSources(ctx context.Context, ref string, options types.PullOptions, result *PullResult) error {
+	var lastErr error
+	for _, source := range pullCandidates(ref, options.Mirrors) {
+		attempts, err := ip.pullWithRetry(ctx, ref, source, options)
+		result.Attempts += attempts
+		if err == nil {
+			if source != ref {
+				if err := ip.client.api().ImageTag(ctx, source, ref); err != nil {
+					lastErr = fmt.Errorf("failed to tag %s as %s: %w", source, ref, err)
+					continue
+				}
+			}
+			result.Source = source
+			result.Digest = ip.pulledDigest(ctx, ref, source)
+			return nil
+		}
+
+		lastErr = err
+		if ctx.Err() != nil {
+			break
+		}
+		ip.client.logger.Debug("Pull source failed, trying next", "image", ref, "source", source, "error", err)
+	}
+	return lastErr
+}
+
+// pulledDigest returns the digest source was pulled as, or "" when the
+// daemon can't tell, as for images that were never pushed
+func (ip *ImagePuller) pulledDigest(ctx context.Context, ref, source string) string {
+	inspect, err := ip.client.api().ImageInspect(ctx, ref)
+	if err != nil {
+		ip.client.logger.Debug("Failed to inspect pulled image", "image", ref, "error", err)
+		return ""
+	}
+	return repoDigest(source, inspect.RepoDigests)
+}
+
+// repoDigest picks the digest of image out of the repository digests of
+// its local copy, preferring the repository it was pulled from
+func repoDigest(image string, repoDigests []string) string {
+	name, _, _ := strings.Cut(image, "@")
+	if i := strings.LastIndex(name, ":"); i > strings.LastIndex(name, "/") {
+		name = name[:i]
+	}
+
+	fallback := ""
+	for _, repoDigest := range repoDigests {
+		repo, digest, ok := strings.Cut(repoDigest, "@")
+		if !ok {
+			continue
+		}
+		if repo == name {
+			return digest
+		}
+		if fallback == "" {
+			fallback = digest
+		}
+	}
+	return fallback
+}
+
+// pullWithRetry pulls from a single source, retrying transient failures with
+// exponential backoff. Layers completed by earlier attempts are reused by the
+// daemon, so a retry resumes rather than restarts the download.
+func (ip *ImagePuller) pullWithRetry(ctx context.Context, ref, source string, options types.PullOptions) (int, error) {
+	retries := options.Retries
+	if retries <= 0 {
+		retries = defaultPullRetries
+	}
+	delay := options.RetryDelay
+	if delay <= 0 {
+		delay = defaultPullRetryDelay
+	}
+
+	var err error
+	for attempt := 1; attempt <= retries; attempt++ {
+		if err = ip.pullOnce(ctx, ref, source, options); err == nil {
+			return attempt, nil
+		}
+
+		if !isTransientPullError(err) || attempt == retries {
+			return attempt, err
+		}
+
+		ip.client.logger.Info("Retrying image pull", "image", source, "attempt", attempt+1, "error", err)
+		progressOf(options.Progress).Update(ref, fmt.Sprintf("retrying, attempt %d of %d", attempt+1, retries), 0, 0)
+		select {
+		case <-time.After(delay):
+			delay *= 2
+		case <-ctx.Done():
+			return attempt, ctx.Err()
+		}
+	}
+	return retries, err
+}
+
+// pullOnce performs a single pull and drains the progress stream, reporting
+// the layers downloaded as the task of ref and surfacing errors the daemon
+// reports mid-stream
+func (ip *ImagePuller) pullOnce(ctx context.Context, ref, source string, options types.PullOptions) error {
+	reader, err := ip.client.api().ImagePull(ctx, source, image.PullOptions{Platform: options.Platform})
+	if err != nil {
+		return err
+	}
+	defer func() { _ = reader.Close() }()
+
+	progress := progressOf(options.Progress)
+	layers := newPullLayers()
+	decoder := json.NewDecoder(reader)
+	for {
+		var message pullMessage
+		if err := decoder.Decode(&message); err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return err
+		}
+		if message.Error != nil {
+			return errors.New(message.Error.Message)
+		}
+		if layers.record(message) {
+			status, current, total := layers.summary()
+			progress.Update(ref, status, current, total)
+		}
+	}
+}
+
+// pullLayers tracks the layers of an image through a pull
+type pullLayers struct {
+	layers map[string]*pullLayer
+}
+
+type pullLayer struct {
+	current, total int64
+	done           bool
+}
+
+func newPullLayers() *pullLayers {
+	return &pullLayers{layers: make(map[string]*pullLayer)}
+}
+
+// record applies a message of the pull stream, reporting whether it was
+// about a layer
+func (p *pullLayers) record(message pullMessage) bool {
+	if message.ID == "" {
+		return false
+	}
+	layer, ok := p.layers[message.ID]
+	switch message.Status {
+	case "Pulling fs layer", "Waiting":
+		if !ok {
+			p.layers[message.ID] = &pullLayer{}
+		}
+		return true
+	case "Downloading":
+		if !ok {
+			layer = &pullLayer{}
+			p.layers[message.ID] = layer
+		}
+		layer.current, layer.total = message.ProgressDetail.Current, message.ProgressDetail.Total
+		return true
+	case "Verifying Checksum", "Download complete", "Extracting":
+		if ok {
+			layer.current = layer.total
+		}
+		return ok
+	case "Pull complete", "Already exists":
+		if !ok {
+			layer = &pullLayer{}
+			p.layers[message.ID] = layer
+		}
+		layer.current, layer.done = layer.total, true
+		return true
+	}
+	return false
+}
+
+// summary returns the layers pulled as a status, with the bytes
+// downloaded out of those known so far
+func (p *pullLayers) summary() (string, int64, int64) {
+	var done int
+	var current, total int64
+	for _, layer := range p.layers {
+		if layer.done {
+			done++
+		}
+		current += layer.current
+		total += layer.total
+	}
+	return fmt.Sprintf("%d/%d layers", done, len(p.layers)), current, total
+}
+
+// pullCandidates returns the references to try for an image: each mirror in
+// order for Docker Hub images, followed by the original reference
+func pullCandidates(ref string, mirrors []string) []string {
+	var candidates []string
+	if registry, path := splitRegistry(ref); registry == dockerHubRegistry {
+		for _, mirror := range mirrors {
+			mirror = strings.TrimSuffix(strings.TrimPrefix(strings.TrimPrefix(mirror, "https://"), "http://"), "/")
+			if mirror != "" {
+				candidates = append(candidates, mirror+"/"+path)
+			}
+		}
+	}
+	return append(candidates, ref)
+}
+
+// splitRegistry splits an image reference into its registry host and the
+// remaining repository path, normalising Docker Hub official images
+func splitRegistry(ref string) (string, string) {
+	first, rest, found := strings.Cut(ref, "/")
+	if found && (strings.ContainsAny(first, ".:") || first == "localhost") {
+		if first == "index.docker.io" {
+			first = dockerHubRegistry
+		}
+		return first, rest
+	}
+	if !found {
+		return dockerHubRegistry, "library/" + ref
+	}
+	return dockerHubRegistry, ref
+}
+
+// isTransientPullError reports whether a pull error is worth retrying
+func isTransientPullError(err error) bool {
+	if errors.Is(err, context.Canceled) {
+		return false
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+
+	message := strings.ToLower(err.Error())
+	for _, permanent := range []string{"not found", "manifest unknown", "unauthorized", "denied", "invalid reference"} {
+		if strings.Contains(message, permanent) {
+			return false
+		}
+	}
+	return true
+}
+
+// uniqueImages removes empty and duplicate references while keeping order stable
+func uniqueImages(images []string) []string {
+	seen := make(map[string]bool, len(images))
+	var result []string
+	for _, ref := range images {
+		if ref == "" || seen[ref] {
+			continue
+		}
+		seen[ref] = true
+		result = append(result, ref)
+	}
+	return result
+}
@@ -0,0 +1,80 @@
+package docker
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPullCandidates(t *testing.T) {
+	mirrors := []string{"https://mirror.example.com/", "cache.local:5000"}
+
+	tests := []struct {
+		name     string
+		ref      string
+		expected []string
+	}{
+		{
+			name:     "official image",
+			ref:      "postgres:15-alpine",
+			expected: []string{"mirror.example.com/library/postgres:15-alpine", "cache.local:5000/library/postgres:15-alpine", "postgres:15-alpine"},
+		},
+		{
+			name:     "namespaced hub image",
+			ref:      "provectuslabs/kafka-ui:latest",
+			expected: []string{"mirror.example.com/provectuslabs/kafka-ui:latest", "cache.local:5000/provectuslabs/kafka-ui:latest", "provectuslabs/kafka-ui:latest"},
+		},
+		{
+			name:     "third-party registry is not mirrored",
+			ref:      "quay.io/prometheus/prometheus:v2.45.0",
+			expected: []string{"quay.io/prometheus/prometheus:v2.45.0"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, pullCandidates(tt.ref, mirrors))
+		})
+	}
+}
+
+func TestIsTransientPullError(t *testing.T) {
+	assert.True(t, isTransientPullError(errors.New("net/http: TLS handshake timeout")))
+	assert.True(t, isTransientPullError(context.DeadlineExceeded))
+	assert.False(t, isTransientPullError(context.Canceled))
+	assert.False(t, isTransientPullError(errors.New("manifest unknown: manifest unknown")))
+	assert.False(t, isTransientPullError(errors.New("pull access denied for foo")))
+}
+
+func TestUniqueImages(t *testing.T) {
+	assert.Equal(t, []string{"redis:7", "postgres:15"}, uniqueImages([]string{"redis:7", "", "postgres:15", "redis:7"}))
+}
+
+func TestPullSummary(t *testing.T) {
+	summary := &PullSummary{
+		Results: []PullResult{
+			{Image: "redis:7"},
+			{Image: "postgres:15", Error: "timeout"},
+		},
+		Pulled: 1,
+		Failed: 1,
+	}
+
+	assert.Equal(t, []string{"postgres:15"}, summary.FailedImages())
+	assert.Contains(t, summary.String(), "1 pulled, 1 failed")
+}
+
+func TestRepoDigest(t *testing.T) {
+	digests := []string{
+		"mirror.gcr.io/library/redis@sha256:aaa",
+		"redis@sha256:bbb",
+	}
+
+	assert.Equal(t, "sha256:bbb", repoDigest("redis:7", digests))
+	assert.Equal(t, "sha256:aaa", repoDigest("mirror.gcr.io/library/redis:7", digests))
+	assert.Equal(t, "sha256:aaa", repoDigest("docker.io/library/redis:7", digests))
+	assert.Equal(t, "sha256:bbb", repoDigest("redis@sha256:bbb", digests))
+	assert.Equal(t, "", repoDigest("localhost:5000/app", nil))
+}
@@ -0,0 +1,228 @@
+package docker
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/api/types/image"
+	"github.com/docker/docker/api/types/network"
+	"github.com/docker/docker/api/types/volume"
+
+	"github.com/isaacgarza/dev-stack/internal/pkg/compose"
+	"github.com/isaacgarza/dev-stack/internal/pkg/types"
+)
+
+// Kinds of labelled resources
+const (
+	kindContainer = "container"
+	kindVolume    = "volume"
+	kindNetwork   = "network"
+	kindImage     = "image"
+)
+
+// labelledResource is a container, volume, network or image carrying the
+// labels dev-stack sets on the resources of a project
+type labelledResource struct {
+	kind   string
+	name   string
+	labels map[string]string
+}
+
+// OwnedResources returns the resources dev-stack labelled for projectName.
+// Resources of another compose project of the same name, and those created
+// before dev-stack labelled them, aren't listed.
+func (c *Client) OwnedResources(ctx context.Context, projectName string) (*types.OwnedResources, error) {
+	resources, err := c.listLabelled(ctx, compose.ProjectLabel+"="+projectName)
+	if err != nil {
+		return nil, err
+	}
+	owned := &types.OwnedResources{Project: projectName}
+	for _, group := range groupOwned(resources) {
+		owned.Containers = append(owned.Containers, group.Containers...)
+		owned.Volumes = append(owned.Volumes, group.Volumes...)
+		owned.Networks = append(owned.Networks, group.Networks...)
+		owned.Images = append(owned.Images, group.Images...)
+	}
+	return owned, nil
+}
+
+// OrphanedResources returns, per project, the resources dev-stack created
+// for a project directory that no longer exists. A project still labelling
+// resources with a directory that exists, as after moving it, has none.
+func (c *Client) OrphanedResources(ctx context.Context) ([]types.OwnedResources, error) {
+	resources, err := c.listLabelled(ctx, compose.ProjectLabel)
+	if err != nil {
+		return nil, err
+	}
+	return orphanedGroups(groupOwned(resources), dirExists), nil
+}
+
+// RemoveOwned removes the containers of resources with, as options request,
+// its volumes, images and networks. Containers are removed even while
+// running. Failures to remove the other resources are logged, since they
+// may still be in use.
+func (c *Client) RemoveOwned(ctx context.Context, resources types.OwnedResources, options types.CleanupOptions) error {
+	c.logger.Info("Removing project resources", "project", resources.Project, "dir", resources.Dir)
+
+	var failed int
+	for _, name := range resources.Containers {
+		if err := c.api().ContainerRemove(ctx, name, container.RemoveOptions{
+			RemoveVolumes: options.RemoveVolumes,
+			Force:         true,
+		}); err != nil {
+			c.logger.Error("Failed to remove container", "container", name, "error", err)
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("failed to remove %d of %d containers of %s", failed, len(resources.Containers), resources.Project)
+	}
+
+	if options.RemoveVolumes {
+		for _, name := range resources.Volumes {
+			if err := c.api().VolumeRemove(ctx, name, false); err != nil {
+				c.logger.Error("Failed to remove volume", "volume", name, "error", err)
+			}
+		}
+	}
+	if options.RemoveImages {
+		for _, name := range resources.Images {
+			if _, err := c.api().ImageRemove(ctx, name, image.RemoveOptions{Force: true}); err != nil {
+				c.logger.Error("Failed to remove image", "image", name, "error", err)
+			}
+		}
+	}
+	if options.RemoveNetworks {
+		for _, name := range resources.Networks {
+			if err := c.api().NetworkRemove(ctx, name); err != nil {
+				c.logger.Error("Failed to remove network", "network", name, "error", err)
+			}
+		}
+	}
+	return nil
+}
+
+// listLabelled lists the containers, volumes, networks and images matching
+// a label filter, either a key or key=value
+func (c *Client) listLabelled(ctx context.Context, label string) ([]labelledResource, error) {
+	args := filters.NewArgs(filters.Arg("label", label))
+
+	var resources []labelledResource
+	containers, err := c.api().ContainerList(ctx, container.ListOptions{All: true, Filters: args})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list containers: %w", err)
+	}
+	for _, ctr := range containers {
+		name := ctr.ID
+		if len(ctr.Names) > 0 {
+			name = strings.TrimPrefix(ctr.Names[0], "/")
+		}
+		resources = append(resources, labelledResource{kind: kindContainer, name: name, labels: ctr.Labels})
+	}
+
+	volumes, err := c.api().VolumeList(ctx, volume.ListOptions{Filters: args})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list volumes: %w", err)
+	}
+	for _, v := range volumes.Volumes {
+		resources = append(resources, labelledResource{kind: kindVolume, name: v.Name, labels: v.Labels})
+	}
+
+	networks, err := c.api().NetworkList(ctx, network.ListOptions{Filters: args})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list networks: %w", err)
+	}
+	for _, n := range networks {
+		resources = append(resources, labelledResource{kind: kindNetwork, name: n.Name, labels: n.Labels})
+	}
+
+	images, err := c.api().ImageList(ctx, image.ListOptions{Filters: args})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list images: %w", err)
+	}
+	for _, img := range images {
+		names := img.RepoTags
+		if len(names) == 0 {
+			names = []string{img.ID}
+		}
+		for _, name := range names {
+			resources = append(resources, labelledResource{kind: kindImage, name: name, labels: img.Labels})
+		}
+	}
+	return resources, nil
+}
+
+// groupOwned groups resources by the project and directory they are
+// labelled with, sorted by project then directory
+func groupOwned(resources []labelledResource) []types.OwnedResources {
+	type key struct{ project, dir string }
+	groups := make(map[key]*types.OwnedResources)
+	var keys []key
+	for _, resource := range resources {
+		k := key{resource.labels[compose.ProjectLabel], resource.labels[compose.ProjectDirLabel]}
+		if k.project == "" {
+			continue
+		}
+		group, ok := groups[k]
+		if !ok {
+			group = &types.OwnedResources{Project: k.project, Dir: k.dir}
+			groups[k] = group
+			keys = append(keys, k)
+		}
+		switch resource.kind {
+		case kindContainer:
+			group.Containers = append(group.Containers, resource.name)
+		case kindVolume:
+			group.Volumes = append(group.Volumes, resource.name)
+		case kindNetwork:
+			group.Networks = append(group.Networks, resource.name)
+		case kindImage:
+			group.Images = append(group.Images, resource.name)
+		}
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].project != keys[j].project {
+			return keys[i].project < keys[j].project
+		}
+		return keys[i].dir < keys[j].dir
+	})
+	result := make([]types.OwnedResources, 0, len(keys))
+	for _, k := range keys {
+		result = append(result, *groups[k])
+	}
+	return result
+}
+
+// orphanedGroups returns the groups whose directory doesn't exist, leaving
+// out every group of a project that has a group whose directory exists
+func orphanedGroups(groups []types.OwnedResources, exists func(string) bool) []types.OwnedResources {
+	alive := make(map[string]bool)
+	for _, group := range groups {
+		if group.Dir == "" || exists(group.Dir) {
+			alive[group.Project] = true
+		}
+	}
+	var orphans []types.OwnedResources
+	for _, group := range groups {
+		if !alive[group.Project] {
+			orphans = append(orphans, group)
+		}
+	}
+	return orphans
+}
+
+// dirExists reports whether path is an existing directory. A path that
+// can't be checked is taken to exist, so its resources are kept.
+func dirExists(path string) bool {
+	info, err := os.Stat(path)
+	if err != nil {
+		return !os.IsNotExist(err)
+	}
+	return info.IsDir()
+}
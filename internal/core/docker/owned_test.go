@@ -0,0 +1,43 @@
+package docker
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/isaacgarza/dev-stack/internal/pkg/compose"
+	"github.com/isaacgarza/dev-stack/internal/pkg/types"
+)
+
+func TestGroupOwned(t *testing.T) {
+	labels := func(project, dir string) map[string]string {
+		return map[string]string{compose.ProjectLabel: project, compose.ProjectDirLabel: dir}
+	}
+	groups := groupOwned([]labelledResource{
+		{kind: kindContainer, name: "web-api-1", labels: labels("web", "/src/web")},
+		{kind: kindVolume, name: "api_data", labels: labels("api", "/src/api")},
+		{kind: kindNetwork, name: "web_default", labels: labels("web", "/src/web")},
+		{kind: kindImage, name: "web-api:latest", labels: labels("web", "/src/web")},
+		{kind: kindContainer, name: "unlabelled", labels: map[string]string{}},
+	})
+
+	assert.Equal(t, []types.OwnedResources{
+		{Project: "api", Dir: "/src/api", Volumes: []string{"api_data"}},
+		{Project: "web", Dir: "/src/web", Containers: []string{"web-api-1"}, Networks: []string{"web_default"}, Images: []string{"web-api:latest"}},
+	}, groups)
+}
+
+func TestOrphanedGroups(t *testing.T) {
+	groups := []types.OwnedResources{
+		{Project: "api", Dir: "/gone/api", Volumes: []string{"api_data"}},
+		{Project: "moved", Dir: "/old/moved", Volumes: []string{"moved_data"}},
+		{Project: "moved", Dir: "/new/moved", Containers: []string{"moved-app-1"}},
+		{Project: "shared", Containers: []string{"dev-stack-shared-postgres"}},
+		{Project: "web", Dir: "/src/web", Containers: []string{"web-api-1"}},
+	}
+	exists := func(dir string) bool { return dir == "/src/web" || dir == "/new/moved" }
+
+	assert.Equal(t, []types.OwnedResources{
+		{Project: "api", Dir: "/gone/api", Volumes: []string{"api_data"}},
+	}, orphanedGroups(groups, exists), "a project with a directory that exists keeps the resources of its old one")
+}
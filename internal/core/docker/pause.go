@@ -0,0 +1,81 @@
+package docker
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/filters"
+
+	"github.com/isaacgarza/dev-stack/internal/pkg/constants"
+)
+
+// Pause freezes the running containers of serviceNames, or of every
+// service, with the Docker pause API. Their processes keep their memory and
+// connections but get no CPU until they are resumed. It returns the services
+// paused.
+func (cl *ContainerLifecycle) Pause(ctx context.Context, projectName string, serviceNames []string) ([]string, error) {
+	return cl.setPaused(ctx, projectName, serviceNames, true)
+}
+
+// Unpause resumes the paused containers of serviceNames, or of every
+// service. It returns the services resumed.
+func (cl *ContainerLifecycle) Unpause(ctx context.Context, projectName string, serviceNames []string) ([]string, error) {
+	return cl.setPaused(ctx, projectName, serviceNames, false)
+}
+
+func (cl *ContainerLifecycle) setPaused(ctx context.Context, projectName string, serviceNames []string, pause bool) ([]string, error) {
+	state, action := constants.StatePaused, "unpause"
+	if pause {
+		state, action = constants.StateRunning, "pause"
+	}
+	containers, err := cl.containersInState(ctx, projectName, serviceNames, state)
+	if err != nil {
+		return nil, err
+	}
+
+	var changed []string
+	for serviceName, ids := range containers {
+		for _, id := range ids {
+			if pause {
+				err = cl.client.api().ContainerPause(ctx, id)
+			} else {
+				err = cl.client.api().ContainerUnpause(ctx, id)
+			}
+			if err != nil {
+				return nil, fmt.Errorf("failed to %s %s: %w", action, serviceName, err)
+			}
+		}
+		changed = append(changed, serviceName)
+	}
+	slices.Sort(changed)
+	cl.client.logger.Info("Changed paused services", "project", projectName, "action", action, "services", changed)
+	return changed, nil
+}
+
+// containersInState returns the IDs of the containers of each of
+// serviceNames, or of every service, that Docker reports in state. One-off
+// containers of run are left out.
+func (cl *ContainerLifecycle) containersInState(ctx context.Context, projectName string, serviceNames []string, state string) (map[string][]string, error) {
+	filters := filters.NewArgs()
+	filters.Add("label", projectLabel(projectName))
+	filters.Add("status", state)
+	containers, err := cl.client.api().ContainerList(ctx, container.ListOptions{All: true, Filters: filters})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list containers: %w", err)
+	}
+
+	byService := make(map[string][]string)
+	for _, c := range containers {
+		serviceName := c.Labels[constants.ComposeServiceLabel]
+		if serviceName == "" || c.Labels[constants.ComposeOneoffLabel] == "True" {
+			continue
+		}
+		if len(serviceNames) > 0 && !slices.Contains(serviceNames, serviceName) {
+			continue
+		}
+		byService[serviceName] = append(byService[serviceName], c.ID)
+	}
+	return byService, nil
+}
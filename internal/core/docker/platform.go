@@ -0,0 +1,53 @@
+package docker
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/filters"
+
+	"github.com/isaacgarza/dev-stack/internal/pkg/constants"
+)
+
+// EmulatedServices returns the running services of the project whose image
+// is built for another architecture than the Docker host, such as amd64
+// images on Apple Silicon, with the architecture of the image. Those run
+// under emulation, which explains a service being slow.
+func (c *Client) EmulatedServices(ctx context.Context, projectName string) (map[string]string, error) {
+	version, err := c.api().ServerVersion(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the Docker host architecture: %w", err)
+	}
+	args := filters.NewArgs(
+		filters.Arg("label", projectLabel(projectName)),
+		filters.Arg("status", "running"),
+	)
+	containers, err := c.api().ContainerList(ctx, container.ListOptions{Filters: args})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list containers: %w", err)
+	}
+
+	architectures := make(map[string]string)
+	emulated := make(map[string]string)
+	for _, ctr := range containers {
+		arch, inspected := architectures[ctr.ImageID]
+		if !inspected {
+			// An image removed since the container started can't be inspected
+			if inspect, err := c.api().ImageInspect(ctx, ctr.ImageID); err == nil {
+				arch = inspect.Architecture
+			}
+			architectures[ctr.ImageID] = arch
+		}
+		if isEmulated(version.Arch, arch) {
+			emulated[ctr.Labels[constants.ComposeServiceLabel]] = arch
+		}
+	}
+	return emulated, nil
+}
+
+// isEmulated reports whether an image built for arch runs under emulation
+// on a hostArch Docker host
+func isEmulated(hostArch, arch string) bool {
+	return hostArch != "" && arch != "" && arch != hostArch
+}
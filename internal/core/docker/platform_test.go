@@ -0,0 +1,15 @@
+package docker
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsEmulated(t *testing.T) {
+	assert.True(t, isEmulated("arm64", "amd64"))
+	assert.False(t, isEmulated("arm64", "arm64"))
+	// Unknown architectures are never reported
+	assert.False(t, isEmulated("", "amd64"))
+	assert.False(t, isEmulated("arm64", ""))
+}
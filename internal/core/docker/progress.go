@@ -0,0 +1,85 @@
+package docker
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"sync"
+
+	"github.com/isaacgarza/dev-stack/internal/pkg/types"
+)
+
+// buildStep matches a step of a plain BuildKit log, such as
+// "#8 [api 2/5] RUN go build ./..."
+var buildStep = regexp.MustCompile(`^#\d+ \[(\S+) (\d+)/(\d+)\] (.+)$`)
+
+// noProgress discards progress, for callers that don't show any
+type noProgress struct{}
+
+func (noProgress) Update(string, string, int64, int64) {}
+func (noProgress) Done(string, string)                 {}
+func (noProgress) Fail(string, string)                 {}
+
+// progressOf returns p, or a reporter discarding progress when it is nil
+func progressOf(p types.ProgressReporter) types.ProgressReporter {
+	if p == nil {
+		return noProgress{}
+	}
+	return p
+}
+
+// buildLog collects the output of docker compose, reporting the steps of
+// the images it builds as they go by
+type buildLog struct {
+	mu       sync.Mutex
+	progress types.ProgressReporter
+	output   bytes.Buffer
+	pending  []byte
+	built    []string
+}
+
+func (l *buildLog) Write(p []byte) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.output.Write(p)
+	l.pending = append(l.pending, p...)
+	for {
+		end := bytes.IndexByte(l.pending, '\n')
+		if end < 0 {
+			return len(p), nil
+		}
+		l.step(string(bytes.TrimSpace(l.pending[:end])))
+		l.pending = l.pending[end+1:]
+	}
+}
+
+// step reports a line of the log that is a build step
+func (l *buildLog) step(line string) {
+	match := buildStep.FindStringSubmatch(line)
+	if match == nil {
+		return
+	}
+	service := match[1]
+	current, _ := strconv.ParseInt(match[2], 10, 64)
+	total, _ := strconv.ParseInt(match[3], 10, 64)
+	if !slices.Contains(l.built, service) {
+		l.built = append(l.built, service)
+	}
+	l.progress.Update(service, fmt.Sprintf("building [%d/%d] %s", current, total, match[4]), current, total)
+}
+
+// finish reports the images built, or their builds failing with err
+func (l *buildLog) finish(err error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for _, service := range l.built {
+		if err != nil {
+			l.progress.Fail(service, "build failed")
+		} else {
+			l.progress.Update(service, "built", 0, 0)
+		}
+	}
+}
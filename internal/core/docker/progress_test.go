@@ -0,0 +1,97 @@
+package docker
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// recordedProgress keeps every report made of each task
+type recordedProgress struct {
+	mu      sync.Mutex
+	reports map[string][]string
+}
+
+func (p *recordedProgress) record(task, report string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.reports == nil {
+		p.reports = make(map[string][]string)
+	}
+	p.reports[task] = append(p.reports[task], report)
+}
+
+func (p *recordedProgress) Update(task, status string, current, total int64) {
+	p.record(task, fmt.Sprintf("%s %d/%d", status, current, total))
+}
+
+func (p *recordedProgress) Done(task, status string) { p.record(task, "done: "+status) }
+func (p *recordedProgress) Fail(task, status string) { p.record(task, "failed: "+status) }
+
+// last returns the latest report of a task
+func (p *recordedProgress) last(task string) string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	reports := p.reports[task]
+	if len(reports) == 0 {
+		return ""
+	}
+	return reports[len(reports)-1]
+}
+
+func TestBuildLog(t *testing.T) {
+	progress := &recordedProgress{}
+	log := &buildLog{progress: progress}
+
+	// Lines may be split across writes
+	_, _ = io.WriteString(log, "#1 [internal] load build definition\n#5 [api 1/3] FROM golang")
+	_, _ = io.WriteString(log, ":1.24\n#6 [api 2/3] RUN go build ./...\n#7 [web 1/2] COPY . .\n")
+	log.finish(nil)
+
+	assert.Equal(t, []string{
+		"building [1/3] FROM golang:1.24 1/3",
+		"building [2/3] RUN go build ./... 2/3",
+		"built 0/0",
+	}, progress.reports["api"])
+	assert.Equal(t, []string{"building [1/2] COPY . . 1/2", "built 0/0"}, progress.reports["web"])
+	assert.NotContains(t, progress.reports, "internal")
+	assert.Contains(t, log.output.String(), "load build definition")
+
+	failed := &recordedProgress{}
+	log = &buildLog{progress: failed}
+	_, _ = io.WriteString(log, "#5 [api 1/3] FROM golang:1.24\n")
+	log.finish(errors.New("exit status 1"))
+	assert.Equal(t, "failed: build failed", failed.last("api"))
+}
+
+func TestPullLayers(t *testing.T) {
+	layers := newPullLayers()
+	message := func(id, status string, current, total int64) pullMessage {
+		m := pullMessage{ID: id, Status: status}
+		m.ProgressDetail.Current, m.ProgressDetail.Total = current, total
+		return m
+	}
+
+	assert.False(t, layers.record(message("16", "Pulling from library/redis", 0, 0)))
+	assert.False(t, layers.record(pullMessage{Status: "Digest: sha256:abc"}))
+	assert.True(t, layers.record(message("a1", "Already exists", 0, 0)))
+	assert.True(t, layers.record(message("b2", "Pulling fs layer", 0, 0)))
+	assert.True(t, layers.record(message("c3", "Downloading", 40, 100)))
+	assert.True(t, layers.record(message("b2", "Downloading", 10, 50)))
+
+	status, current, total := layers.summary()
+	assert.Equal(t, "1/3 layers", status)
+	assert.Equal(t, int64(50), current)
+	assert.Equal(t, int64(150), total)
+
+	assert.True(t, layers.record(message("c3", "Download complete", 0, 0)))
+	assert.True(t, layers.record(message("c3", "Pull complete", 0, 0)))
+	status, current, total = layers.summary()
+	assert.Equal(t, "2/3 layers", status)
+	assert.Equal(t, int64(110), current)
+	assert.Equal(t, int64(150), total)
+}
@@ -0,0 +1,112 @@
+package docker
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/api/types/image"
+
+	"github.com/isaacgarza/dev-stack/internal/core/registry"
+	"github.com/isaacgarza/dev-stack/internal/pkg/compose"
+	"github.com/isaacgarza/dev-stack/internal/pkg/types"
+)
+
+// StoppedContainers returns the containers dev-stack labelled, for any
+// project, that aren't running, with the space their writable layer takes
+func (c *Client) StoppedContainers(ctx context.Context) ([]types.Reclaimable, error) {
+	args := filters.NewArgs(
+		filters.Arg("label", compose.ProjectLabel),
+		filters.Arg("status", "created"),
+		filters.Arg("status", "exited"),
+		filters.Arg("status", "dead"),
+	)
+	containers, err := c.api().ContainerList(ctx, container.ListOptions{All: true, Size: true, Filters: args})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list containers: %w", err)
+	}
+
+	items := make([]types.Reclaimable, 0, len(containers))
+	for _, ctr := range containers {
+		items = append(items, types.Reclaimable{
+			Category:  types.PruneContainers,
+			Name:      containerName(ctr),
+			Detail:    ctr.Labels[compose.ProjectLabel],
+			Size:      ctr.SizeRw,
+			CreatedAt: time.Unix(ctr.Created, 0),
+		})
+	}
+	return items, nil
+}
+
+// DanglingImages returns the untagged images left behind once a newer image
+// took their tag: those compose built for a project, and those pulled from
+// the repository of one of images
+func (c *Client) DanglingImages(ctx context.Context, images []string) ([]types.Reclaimable, error) {
+	list, err := c.api().ImageList(ctx, image.ListOptions{Filters: filters.NewArgs(filters.Arg("dangling", "true"))})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list images: %w", err)
+	}
+
+	repositories := make(map[registry.Reference]bool, len(images))
+	for _, img := range images {
+		repositories[repositoryOf(img)] = true
+	}
+	var items []types.Reclaimable
+	for _, img := range list {
+		source := danglingSource(img.Labels, img.RepoDigests, repositories)
+		if source == "" {
+			continue
+		}
+		items = append(items, types.Reclaimable{
+			Category:  types.PruneImages,
+			Name:      img.ID,
+			Detail:    source,
+			Size:      img.Size,
+			CreatedAt: time.Unix(img.Created, 0),
+		})
+	}
+	return items, nil
+}
+
+// RemoveReclaimable removes a stopped container or a dangling image
+func (c *Client) RemoveReclaimable(ctx context.Context, item types.Reclaimable) error {
+	switch item.Category {
+	case types.PruneContainers:
+		if err := c.api().ContainerRemove(ctx, item.Name, container.RemoveOptions{}); err != nil {
+			return fmt.Errorf("failed to remove container %s: %w", item.Name, err)
+		}
+	case types.PruneImages:
+		if _, err := c.api().ImageRemove(ctx, item.Name, image.RemoveOptions{PruneChildren: true}); err != nil {
+			return fmt.Errorf("failed to remove image %s: %w", item.Name, err)
+		}
+	default:
+		return fmt.Errorf("%s aren't Docker resources", item.Category)
+	}
+	return nil
+}
+
+// danglingSource returns the project a dangling image was built for or the
+// repository it was pulled from, or an empty string for images dev-stack
+// had nothing to do with
+func danglingSource(labels map[string]string, repoDigests []string, repositories map[registry.Reference]bool) string {
+	if project := labels[compose.ProjectLabel]; project != "" {
+		return project
+	}
+	for _, digest := range repoDigests {
+		if ref := repositoryOf(digest); repositories[ref] {
+			return ref.Registry + "/" + ref.Repository
+		}
+	}
+	return ""
+}
+
+// repositoryOf returns the registry and repository of an image reference,
+// without its tag or digest
+func repositoryOf(image string) registry.Reference {
+	ref := registry.ParseReference(image)
+	ref.Tag = ""
+	return ref
+}
@@ -0,0 +1,23 @@
+package docker
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/isaacgarza/dev-stack/internal/core/registry"
+	"github.com/isaacgarza/dev-stack/internal/pkg/compose"
+)
+
+func TestDanglingSource(t *testing.T) {
+	repositories := map[registry.Reference]bool{
+		repositoryOf("postgres:16-alpine"):                  true,
+		repositoryOf("quay.io/prometheus/prometheus:v2.45"): true,
+	}
+
+	assert.Equal(t, "shop", danglingSource(map[string]string{compose.ProjectLabel: "shop"}, nil, repositories))
+	assert.Equal(t, "docker.io/library/postgres", danglingSource(nil, []string{"postgres@sha256:4f2c"}, repositories))
+	assert.Equal(t, "quay.io/prometheus/prometheus", danglingSource(nil, []string{"quay.io/prometheus/prometheus@sha256:9e1a"}, repositories))
+	assert.Empty(t, danglingSource(nil, []string{"node@sha256:77aa"}, repositories), "images dev-stack never pulled are left alone")
+	assert.Empty(t, danglingSource(nil, nil, repositories))
+}
@@ -0,0 +1,153 @@
+package docker
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/filters"
+
+	"github.com/isaacgarza/dev-stack/internal/pkg/constants"
+	"github.com/isaacgarza/dev-stack/internal/pkg/types"
+)
+
+// Reconcile works out what up has to do to each of serviceNames, with
+// profiles active as up runs them: nothing for running containers created
+// from the current definition and image, a start for stopped ones, and a
+// new container for the others. Definitions are compared by the config
+// hash docker compose labels its containers with.
+func (cl *ContainerLister) Reconcile(ctx context.Context, projectName string, profiles, serviceNames []string) ([]types.ServiceReconcile, error) {
+	output, err := runComposeConfig(ctx, projectName, profiles, "--hash", strings.Join(serviceNames, ","))
+	if err != nil {
+		return nil, err
+	}
+	hashes := parseConfigHashes(output)
+	desired, err := composeSpecs(ctx, projectName, profiles)
+	if err != nil {
+		return nil, err
+	}
+	containers, err := cl.serviceContainers(ctx, projectName)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]types.ServiceReconcile, 0, len(serviceNames))
+	for _, serviceName := range serviceNames {
+		hash, ok := hashes[serviceName]
+		if !ok {
+			return nil, fmt.Errorf("service %s is not defined in the compose files", serviceName)
+		}
+		// Built images are named by compose and rebuilt with --build, so
+		// only the images given are checked for a newer pull
+		imageID := ""
+		if image := desired[serviceName].image; image != "" {
+			if inspect, err := cl.client.api().ImageInspect(ctx, image); err == nil {
+				imageID = inspect.ID
+			}
+		}
+
+		step := types.ServiceReconcile{Service: serviceName, Action: reconcileAction(hash, imageID, containers[serviceName])}
+		if step.Action == types.ReconcileRecreate {
+			step.Changes = cl.recreateChanges(ctx, desired[serviceName], imageID, containers[serviceName])
+		}
+		result = append(result, step)
+	}
+	return result, nil
+}
+
+// serviceContainers returns the containers of each service of a project,
+// stopped ones included. One-off containers of run are left out.
+func (cl *ContainerLister) serviceContainers(ctx context.Context, projectName string) (map[string][]container.Summary, error) {
+	filters := filters.NewArgs()
+	filters.Add("label", projectLabel(projectName))
+
+	containers, err := cl.client.api().ContainerList(ctx, container.ListOptions{All: true, Filters: filters})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list containers: %w", err)
+	}
+	byService := make(map[string][]container.Summary)
+	for _, c := range containers {
+		serviceName := c.Labels[constants.ComposeServiceLabel]
+		if serviceName == "" || c.Labels[constants.ComposeOneoffLabel] == "True" {
+			continue
+		}
+		byService[serviceName] = append(byService[serviceName], c)
+	}
+	return byService, nil
+}
+
+// recreateChanges lists what changed for a service to be recreated,
+// comparing its definition with its first container
+func (cl *ContainerLister) recreateChanges(ctx context.Context, want containerSpec, imageID string, containers []container.Summary) []types.DriftChange {
+	primary := containers[0]
+	for _, c := range containers[1:] {
+		if ContainerNumber(c.Labels) < ContainerNumber(primary.Labels) {
+			primary = c
+		}
+	}
+
+	var changes []types.DriftChange
+	// The details only explain the recreation, so a failed inspect leaves
+	// them out
+	if inspect, err := cl.client.api().ContainerInspect(ctx, primary.ID); err == nil {
+		changes = specChanges(want, inspectSpec(inspect))
+	}
+	if imageID != "" && primary.ImageID != imageID && !hasChange(changes, "image") {
+		changes = append(changes, types.DriftChange{Field: "image", Expected: want.image + "@" + shortImageID(imageID), Actual: want.image + "@" + shortImageID(primary.ImageID)})
+	}
+	return changes
+}
+
+// reconcileAction returns what up has to do to a service whose definition
+// hashes to hash, given its containers. imageID is the image the service
+// runs, when it is pulled rather than built.
+func reconcileAction(hash, imageID string, containers []container.Summary) string {
+	if len(containers) == 0 {
+		return types.ReconcileCreate
+	}
+	action := types.ReconcileUnchanged
+	for _, c := range containers {
+		if c.Labels[constants.ComposeConfigHashLabel] != hash || (imageID != "" && c.ImageID != imageID) {
+			return types.ReconcileRecreate
+		}
+		if c.State != container.StateRunning {
+			action = types.ReconcileStart
+		}
+	}
+	return action
+}
+
+// parseConfigHashes reads the output of docker compose config --hash, a
+// service and the hash of its definition per line
+func parseConfigHashes(output []byte) map[string]string {
+	hashes := make(map[string]string)
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	for scanner.Scan() {
+		if serviceName, hash, ok := strings.Cut(strings.TrimSpace(scanner.Text()), " "); ok {
+			hashes[serviceName] = strings.TrimSpace(hash)
+		}
+	}
+	return hashes
+}
+
+// hasChange reports whether changes include one of field
+func hasChange(changes []types.DriftChange, field string) bool {
+	for _, change := range changes {
+		if change.Field == field {
+			return true
+		}
+	}
+	return false
+}
+
+// shortImageID abbreviates an image ID the way docker images does
+func shortImageID(id string) string {
+	id = strings.TrimPrefix(id, "sha256:")
+	if len(id) > 12 {
+		return id[:12]
+	}
+	return id
+}
@@ -0,0 +1,85 @@
+package docker
+
+import (
+	"testing"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/isaacgarza/dev-stack/internal/pkg/constants"
+	"github.com/isaacgarza/dev-stack/internal/pkg/types"
+)
+
+// hashedContainer builds the summary of a container created from a
+// definition hashing to hash
+func hashedContainer(hash, imageID string, state container.ContainerState) container.Summary {
+	return container.Summary{
+		ImageID: imageID,
+		State:   state,
+		Labels:  map[string]string{constants.ComposeConfigHashLabel: hash},
+	}
+}
+
+func TestParseConfigHashes(t *testing.T) {
+	output := "postgres 3f1c9a\nredis 8e2b7d\n\n"
+	assert.Equal(t, map[string]string{"postgres": "3f1c9a", "redis": "8e2b7d"}, parseConfigHashes([]byte(output)))
+}
+
+func TestReconcileAction(t *testing.T) {
+	tests := []struct {
+		name       string
+		imageID    string
+		containers []container.Summary
+		want       string
+	}{
+		{name: "no container", want: types.ReconcileCreate},
+		{
+			name:       "running current",
+			imageID:    "sha256:aaa",
+			containers: []container.Summary{hashedContainer("abc", "sha256:aaa", container.StateRunning)},
+			want:       types.ReconcileUnchanged,
+		},
+		{
+			name:       "built image",
+			containers: []container.Summary{hashedContainer("abc", "sha256:old", container.StateRunning)},
+			want:       types.ReconcileUnchanged,
+		},
+		{
+			name:       "stopped",
+			imageID:    "sha256:aaa",
+			containers: []container.Summary{hashedContainer("abc", "sha256:aaa", container.StateExited)},
+			want:       types.ReconcileStart,
+		},
+		{
+			name:       "definition changed",
+			containers: []container.Summary{hashedContainer("old", "sha256:aaa", container.StateRunning)},
+			want:       types.ReconcileRecreate,
+		},
+		{
+			name:       "newer image pulled",
+			imageID:    "sha256:bbb",
+			containers: []container.Summary{hashedContainer("abc", "sha256:aaa", container.StateRunning)},
+			want:       types.ReconcileRecreate,
+		},
+		{
+			name:    "stale replica",
+			imageID: "sha256:aaa",
+			containers: []container.Summary{
+				hashedContainer("abc", "sha256:aaa", container.StateExited),
+				hashedContainer("old", "sha256:aaa", container.StateRunning),
+			},
+			want: types.ReconcileRecreate,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, reconcileAction("abc", tt.imageID, tt.containers))
+		})
+	}
+}
+
+func TestShortImageID(t *testing.T) {
+	assert.Equal(t, "0123456789ab", shortImageID("sha256:0123456789abcdef"))
+	assert.Equal(t, "abc", shortImageID("abc"))
+}
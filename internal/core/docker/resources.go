@@ -8,8 +8,9 @@ import (
 	"github.com/docker/docker/api/types/image"
 	"github.com/docker/docker/api/types/network"
 	"github.com/docker/docker/api/types/volume"
+	"github.com/docker/docker/client"
 
-	"github.com/isaacgarza/dev-stack/internal/pkg/constants"
+	"github.com/isaacgarza/dev-stack/internal/pkg/types"
 )
 
 // VolumeService provides volume management operations
@@ -32,9 +33,9 @@ type ImageService struct {
 // List returns a list of volumes for the project
 func (vs *VolumeService) List(ctx context.Context, projectName string) ([]string, error) {
 	filters := filters.NewArgs()
-	filters.Add("label", fmt.Sprintf("%s=%s", constants.ComposeProjectLabel, projectName))
+	filters.Add("label", projectLabel(projectName))
 
-	volumes, err := vs.client.cli.VolumeList(ctx, volume.ListOptions{
+	volumes, err := vs.client.api().VolumeList(ctx, volume.ListOptions{
 		Filters: filters,
 	})
 	if err != nil {
@@ -57,7 +58,7 @@ func (vs *VolumeService) Remove(ctx context.Context, projectName string) error {
 		return err
 	}
 	for _, volumeName := range volumeNames {
-		if err := vs.client.cli.VolumeRemove(ctx, volumeName, false); err != nil {
+		if err := vs.client.api().VolumeRemove(ctx, volumeName, false); err != nil {
 			vs.client.logger.Error("Failed to remove volume", "volume", volumeName, "error", err)
 			continue
 		}
@@ -72,9 +73,9 @@ func (vs *VolumeService) Remove(ctx context.Context, projectName string) error {
 // List returns a list of networks for the project
 func (ns *NetworkService) List(ctx context.Context, projectName string) ([]string, error) {
 	filters := filters.NewArgs()
-	filters.Add("label", fmt.Sprintf("%s=%s", constants.ComposeProjectLabel, projectName))
+	filters.Add("label", projectLabel(projectName))
 
-	networks, err := ns.client.cli.NetworkList(ctx, network.ListOptions{
+	networks, err := ns.client.api().NetworkList(ctx, network.ListOptions{
 		Filters: filters,
 	})
 	if err != nil {
@@ -97,7 +98,7 @@ func (ns *NetworkService) Remove(ctx context.Context, projectName string) error
 		return err
 	}
 	for _, networkName := range networkNames {
-		if err := ns.client.cli.NetworkRemove(ctx, networkName); err != nil {
+		if err := ns.client.api().NetworkRemove(ctx, networkName); err != nil {
 			ns.client.logger.Error("Failed to remove network", "network", networkName, "error", err)
 			continue
 		}
@@ -107,14 +108,29 @@ func (ns *NetworkService) Remove(ctx context.Context, projectName string) error
 	return nil
 }
 
+// Ensure creates a bridge network with the given name unless it exists
+func (ns *NetworkService) Ensure(ctx context.Context, name string) error {
+	if _, err := ns.client.api().NetworkInspect(ctx, name, network.InspectOptions{}); err == nil {
+		return nil
+	} else if !client.IsErrNotFound(err) {
+		return fmt.Errorf("failed to inspect network %s: %w", name, err)
+	}
+
+	if _, err := ns.client.api().NetworkCreate(ctx, name, network.CreateOptions{Driver: "bridge"}); err != nil {
+		return fmt.Errorf("failed to create network %s: %w", name, err)
+	}
+	ns.client.logger.Info("Created network", "network", name)
+	return nil
+}
+
 // Image operations
 
 // List returns a list of images for the project
 func (is *ImageService) List(ctx context.Context, projectName string) ([]string, error) {
 	filters := filters.NewArgs()
-	filters.Add("label", fmt.Sprintf("%s=%s", constants.ComposeProjectLabel, projectName))
+	filters.Add("label", projectLabel(projectName))
 
-	images, err := is.client.cli.ImageList(ctx, image.ListOptions{
+	images, err := is.client.api().ImageList(ctx, image.ListOptions{
 		Filters: filters,
 	})
 	if err != nil {
@@ -129,6 +145,11 @@ func (is *ImageService) List(ctx context.Context, projectName string) ([]string,
 	return imageNames, nil
 }
 
+// Pull pulls the given images with retries and mirror failover
+func (is *ImageService) Pull(ctx context.Context, images []string, options types.PullOptions) (*PullSummary, error) {
+	return NewImagePuller(is.client).Pull(ctx, images, options)
+}
+
 // Remove removes images for the project
 func (is *ImageService) Remove(ctx context.Context, projectName string) error {
 	// Get all project images
@@ -137,7 +158,7 @@ func (is *ImageService) Remove(ctx context.Context, projectName string) error {
 		return err
 	}
 	for _, imageName := range imageNames {
-		if _, err := is.client.cli.ImageRemove(ctx, imageName, image.RemoveOptions{
+		if _, err := is.client.api().ImageRemove(ctx, imageName, image.RemoveOptions{
 			Force: true,
 		}); err != nil {
 			is.client.logger.Error("Failed to remove image", "image", imageName, "error", err)
@@ -148,3 +169,18 @@ func (is *ImageService) Remove(ctx context.Context, projectName string) error {
 
 	return nil
 }
+
+// RemoveImages removes the named images, so the next pull downloads them
+// again. Images that aren't present are skipped.
+func (is *ImageService) RemoveImages(ctx context.Context, images []string) error {
+	for _, imageName := range images {
+		if _, err := is.client.api().ImageRemove(ctx, imageName, image.RemoveOptions{Force: true}); err != nil {
+			if client.IsErrNotFound(err) {
+				continue
+			}
+			return fmt.Errorf("failed to remove image %s: %w", imageName, err)
+		}
+		is.client.logger.Info("Removed image", "image", imageName)
+	}
+	return nil
+}
@@ -0,0 +1,141 @@
+package docker
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/docker/docker/api/types/container"
+
+	"github.com/isaacgarza/dev-stack/internal/pkg/types"
+)
+
+// ScratchLabel marks throwaway containers created by dev-stack
+const ScratchLabel = "dev-stack.scratch"
+
+// scratchPollInterval is how often a scratch container's health is checked
+const scratchPollInterval = time.Second
+
+// ScratchContainer is a disposable copy of a service's container with empty
+// storage, used to try out operations without touching the live data
+type ScratchContainer struct {
+	ID       string
+	Name     string
+	executor *ContainerExecutor
+}
+
+// NewScratch creates and starts a scratch copy of a running service. The copy
+// uses the same image, environment and healthcheck but no volumes, networks
+// or published ports, and carries no compose labels so it never shows up as
+// part of the stack.
+func (ce *ContainerExecutor) NewScratch(ctx context.Context, projectName, serviceName, purpose string) (*ScratchContainer, error) {
+	containerID, err := ce.findServiceContainer(ctx, projectName, serviceName)
+	if err != nil {
+		return nil, err
+	}
+
+	primary, err := ce.client.api().ContainerInspect(ctx, containerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to inspect %s: %w", serviceName, err)
+	}
+
+	config := &container.Config{
+		Image:       primary.Config.Image,
+		Env:         primary.Config.Env,
+		Cmd:         primary.Config.Cmd,
+		Entrypoint:  primary.Config.Entrypoint,
+		User:        primary.Config.User,
+		WorkingDir:  primary.Config.WorkingDir,
+		Healthcheck: primary.Config.Healthcheck,
+		Labels:      map[string]string{ScratchLabel: purpose},
+	}
+
+	name := fmt.Sprintf("%s-%s-%s-%d", NormalizeProjectName(projectName), serviceName, purpose, time.Now().Unix())
+	created, err := ce.client.api().ContainerCreate(ctx, config, &container.HostConfig{}, nil, nil, name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create scratch container: %w", err)
+	}
+
+	scratch := &ScratchContainer{ID: created.ID, Name: name, executor: ce}
+	if err := ce.client.api().ContainerStart(ctx, created.ID, container.StartOptions{}); err != nil {
+		_ = scratch.Remove(context.WithoutCancel(ctx))
+		return nil, fmt.Errorf("failed to start scratch container: %w", err)
+	}
+
+	ce.client.logger.Info("Started scratch container", "name", name, "service", serviceName)
+	return scratch, nil
+}
+
+// WaitReady blocks until the container reports healthy, or is running when
+// the image has no healthcheck
+func (s *ScratchContainer) WaitReady(ctx context.Context) error {
+	ticker := time.NewTicker(scratchPollInterval)
+	defer ticker.Stop()
+
+	for {
+		inspect, err := s.executor.client.api().ContainerInspect(ctx, s.ID)
+		if err != nil {
+			return fmt.Errorf("failed to inspect scratch container: %w", err)
+		}
+
+		state := inspect.State
+		switch {
+		case state == nil:
+		case !state.Running && state.Status != "created" && state.Status != "restarting":
+			return fmt.Errorf("scratch container stopped: %s (exit code %d)", state.Status, state.ExitCode)
+		case state.Health == nil && state.Running:
+			return nil
+		case state.Health != nil && state.Health.Status == container.Healthy:
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("scratch container did not become ready: %w", ctx.Err())
+		case <-ticker.C:
+		}
+	}
+}
+
+// Exec runs a command in the scratch container, feeding stdin if given and
+// streaming stdout to w
+func (s *ScratchContainer) Exec(ctx context.Context, cmd []string, stdin io.Reader, w io.Writer, options types.ExecOptions) error {
+	return s.executor.execAttached(ctx, s.ID, cmd, stdin, w, options)
+}
+
+// CopyTo writes the contents of r to target inside the scratch container
+func (s *ScratchContainer) CopyTo(ctx context.Context, target string, r io.Reader, size int64) error {
+	return s.executor.copyToContainer(ctx, s.ID, target, r, size)
+}
+
+// CopyArchiveTo extracts the tar archive r into dir inside the scratch
+// container
+func (s *ScratchContainer) CopyArchiveTo(ctx context.Context, dir string, r io.Reader) error {
+	return s.executor.copyArchiveToContainer(ctx, s.ID, dir, r)
+}
+
+// Stop stops the scratch container without removing it
+func (s *ScratchContainer) Stop(ctx context.Context) error {
+	if err := s.executor.client.api().ContainerStop(ctx, s.ID, container.StopOptions{}); err != nil {
+		return fmt.Errorf("failed to stop scratch container %s: %w", s.Name, err)
+	}
+	return nil
+}
+
+// Start starts the scratch container again and waits for it to become ready
+func (s *ScratchContainer) Start(ctx context.Context) error {
+	if err := s.executor.client.api().ContainerStart(ctx, s.ID, container.StartOptions{}); err != nil {
+		return fmt.Errorf("failed to start scratch container %s: %w", s.Name, err)
+	}
+	return s.WaitReady(ctx)
+}
+
+// Remove deletes the scratch container together with its anonymous volumes
+func (s *ScratchContainer) Remove(ctx context.Context) error {
+	err := s.executor.client.api().ContainerRemove(ctx, s.ID, container.RemoveOptions{Force: true, RemoveVolumes: true})
+	if err != nil {
+		return fmt.Errorf("failed to remove scratch container %s: %w", s.Name, err)
+	}
+	return nil
+}
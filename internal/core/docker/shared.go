@@ -0,0 +1,90 @@
+package docker
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/network"
+	"github.com/docker/docker/client"
+
+	"github.com/isaacgarza/dev-stack/internal/pkg/compose"
+	"github.com/isaacgarza/dev-stack/internal/pkg/constants"
+)
+
+// StartShared starts a shared service from the project's compose files,
+// merged with the shared file, unless its container is running already.
+// It reports whether the service was started.
+func (cl *ContainerLifecycle) StartShared(ctx context.Context, serviceName string) (bool, error) {
+	inspect, err := cl.client.api().ContainerInspect(ctx, compose.SharedContainerName(serviceName))
+	if err == nil && inspect.State != nil && inspect.State.Running {
+		return false, nil
+	}
+	if err != nil && !client.IsErrNotFound(err) {
+		return false, fmt.Errorf("failed to inspect shared %s: %w", serviceName, err)
+	}
+
+	cl.client.logger.Info("Starting shared service", "service", serviceName)
+	args := []string{constants.DockerComposeCmd}
+	for _, file := range append(ComposeFiles(), compose.SharedFile()) {
+		args = append(args, "-f", file)
+	}
+	args = append(args, "-p", compose.SharedProject, "up", "-d", "--no-deps", serviceName)
+
+	output, err := composeCommand(ctx, args...).CombinedOutput()
+	if err != nil {
+		if message := strings.TrimSpace(string(output)); message != "" {
+			return false, fmt.Errorf("failed to start shared %s: %w: %s", serviceName, err, message)
+		}
+		return false, fmt.Errorf("failed to start shared %s: %w", serviceName, err)
+	}
+	return true, nil
+}
+
+// ConnectShared connects the container of a shared service to the networks
+// of a project, where the project's services reach it by its service name
+func (cl *ContainerLifecycle) ConnectShared(ctx context.Context, projectName, serviceName string) error {
+	networks, err := cl.client.Networks().List(ctx, projectName)
+	if err != nil {
+		return err
+	}
+
+	containerName := compose.SharedContainerName(serviceName)
+	for _, networkName := range networks {
+		err := cl.client.api().NetworkConnect(ctx, networkName, containerName, &network.EndpointSettings{
+			Aliases: []string{serviceName},
+		})
+		if err != nil && !strings.Contains(err.Error(), "already exists") {
+			return fmt.Errorf("failed to connect shared %s to %s: %w", serviceName, networkName, err)
+		}
+	}
+	return nil
+}
+
+// DisconnectShared disconnects the container of a shared service from the
+// networks of a project, which can then be removed
+func (cl *ContainerLifecycle) DisconnectShared(ctx context.Context, projectName, serviceName string) error {
+	networks, err := cl.client.Networks().List(ctx, projectName)
+	if err != nil {
+		return err
+	}
+
+	containerName := compose.SharedContainerName(serviceName)
+	for _, networkName := range networks {
+		if err := cl.client.api().NetworkDisconnect(ctx, networkName, containerName, true); err != nil && !client.IsErrNotFound(err) {
+			cl.client.logger.Debug("Shared service not connected", "service", serviceName, "network", networkName, "error", err)
+		}
+	}
+	return nil
+}
+
+// RemoveShared stops and removes the container of a shared service. Its
+// named volumes are kept for the next project that starts it.
+func (cl *ContainerLifecycle) RemoveShared(ctx context.Context, serviceName string) error {
+	err := cl.client.api().ContainerRemove(ctx, compose.SharedContainerName(serviceName), container.RemoveOptions{Force: true})
+	if err != nil && !client.IsErrNotFound(err) {
+		return fmt.Errorf("failed to remove shared %s: %w", serviceName, err)
+	}
+	return nil
+}
@@ -0,0 +1,114 @@
+package docker
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/docker/docker/client"
+)
+
+// pingTTL is how long a connection that answered a ping is trusted before
+// it is checked again
+const pingTTL = 5 * time.Second
+
+// pingTimeout bounds the ping checking the shared connection
+const pingTimeout = 2 * time.Second
+
+// sharedConn is the connection to the engine the clients Shared returns
+// use, so the service manager and the handlers of a command, the steps of a
+// workflow and the requests of the daemon reuse its pool of connections
+var sharedConn struct {
+	sync.Mutex
+	cli      *client.Client
+	endpoint Endpoint
+	// pinged is when the connection was opened or last checked
+	pinged time.Time
+}
+
+// Shared returns a client logging to logger on the connection of the
+// process, which is created on first use. The client looks the connection
+// up on every operation rather than holding on to it, so long-running
+// processes such as the daemon follow it when it is replaced.
+func Shared(logger *slog.Logger) (*Client, error) {
+	cli, err := sharedClient(logger)
+	if err != nil {
+		return nil, err
+	}
+	return &Client{cli: cli, logger: logger, shared: true}, nil
+}
+
+// sharedClient returns the connection of the process, opening it on first
+// use. A connection that hasn't been checked for pingTTL is pinged first,
+// and replaced when the engine stopped answering because the endpoint
+// changed, such as when Docker Desktop was restarted on another socket or
+// the docker context was switched.
+func sharedClient(logger *slog.Logger) (*client.Client, error) {
+	sharedConn.Lock()
+	cli, endpoint := sharedConn.cli, sharedConn.endpoint
+	check := cli != nil && time.Since(sharedConn.pinged) >= pingTTL
+	if check {
+		// Set before pinging, so the clients asking meanwhile don't ping
+		// too. A daemon that is down on the same endpoint is reported by the
+		// operations on the connection, which recovers once it is back, so
+		// it is only checked again after pingTTL.
+		sharedConn.pinged = time.Now()
+	}
+	sharedConn.Unlock()
+
+	if check {
+		ctx, cancel := context.WithTimeout(context.Background(), pingTimeout)
+		_, err := cli.Ping(ctx)
+		cancel()
+		if err != nil {
+			if current, resolveErr := ResolveEndpoint(); resolveErr != nil || current != endpoint {
+				if logger != nil {
+					logger.Debug("Reconnecting to Docker", "endpoint", endpoint.String(), "error", err)
+				}
+				dropShared(cli)
+			}
+		}
+	}
+
+	sharedConn.Lock()
+	defer sharedConn.Unlock()
+	if sharedConn.cli == nil {
+		cli, endpoint, err := connect()
+		if err != nil {
+			return nil, err
+		}
+		sharedConn.cli = cli
+		sharedConn.endpoint = endpoint
+		sharedConn.pinged = time.Now()
+	}
+	return sharedConn.cli, nil
+}
+
+// dropShared closes cli, a connection that can't reach the engine anymore,
+// unless another client already replaced it
+func dropShared(cli *client.Client) {
+	sharedConn.Lock()
+	current := sharedConn.cli == cli
+	if current {
+		sharedConn.cli = nil
+	}
+	sharedConn.Unlock()
+	if current {
+		_ = cli.Close()
+	}
+}
+
+// CloseShared closes the connection of the process, for the next Shared to
+// open a new one
+func CloseShared() error {
+	sharedConn.Lock()
+	defer sharedConn.Unlock()
+
+	if sharedConn.cli == nil {
+		return nil
+	}
+	err := sharedConn.cli.Close()
+	sharedConn.cli = nil
+	return err
+}
@@ -0,0 +1,228 @@
+package docker
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/filters"
+
+	"github.com/isaacgarza/dev-stack/internal/pkg/compose"
+	"github.com/isaacgarza/dev-stack/internal/pkg/constants"
+	"github.com/isaacgarza/dev-stack/internal/pkg/types"
+)
+
+// killWait bounds the wait for a container to exit once it was killed
+const killWait = 10 * time.Second
+
+// Shutdown stops the running containers of serviceNames, or of every
+// service, without removing them. Services stop in reverse dependency
+// order, so applications stop before the databases they use; the services
+// of a level stop together. Each service first runs its pre-stop commands,
+// then gets its grace period to exit after its stop signal. Services still
+// running then are killed with options.Force and otherwise left running.
+// Stopping is bounded by the stop timeout.
+func (cl *ContainerLifecycle) Shutdown(ctx context.Context, projectName string, serviceNames []string, options types.ShutdownOptions) (*types.ShutdownReport, error) {
+	var report *types.ShutdownReport
+	err := withTimeout(ctx, opStop, "stopping services", func(ctx context.Context) error {
+		var err error
+		report, err = cl.shutdown(ctx, projectName, serviceNames, options)
+		return err
+	})
+	return report, err
+}
+
+func (cl *ContainerLifecycle) shutdown(ctx context.Context, projectName string, serviceNames []string, options types.ShutdownOptions) (*types.ShutdownReport, error) {
+	running, err := cl.runningContainers(ctx, projectName)
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for name := range running {
+		if len(serviceNames) == 0 || slices.Contains(serviceNames, name) {
+			names = append(names, name)
+		}
+	}
+
+	// Paused containers neither run pre-stop commands nor handle their stop
+	// signal, so they are resumed to stop like the others
+	if len(names) > 0 {
+		if _, err := cl.Unpause(ctx, projectName, names); err != nil {
+			return nil, err
+		}
+	}
+
+	// Without a compose file the dependencies are unknown, and every
+	// service stops at once
+	dependencies := map[string]map[string]string{}
+	if _, err := os.Stat(constants.DockerComposeFile); err == nil {
+		if dependencies, err = compose.ServiceDependencies(ComposeFiles()...); err != nil {
+			return nil, fmt.Errorf("failed to read service dependencies: %w", err)
+		}
+	}
+	waves, err := compose.StartupWaves(names, dependencies, false)
+	if err != nil {
+		return nil, err
+	}
+	slices.Reverse(waves)
+	cl.client.logger.Info("Stopping services", "project", projectName, "services", names, "waves", len(waves))
+
+	progress := progressOf(options.Progress)
+	executor := NewContainerExecutor(cl.client)
+	report := &types.ShutdownReport{}
+	for _, wave := range waves {
+		results := make([]types.ServiceShutdown, len(wave))
+		var wg sync.WaitGroup
+		for i, name := range wave {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				results[i] = cl.shutdownService(ctx, executor, projectName, name, running[name], options, progress)
+			}()
+		}
+		wg.Wait()
+		if ctx.Err() != nil {
+			return report, ctx.Err()
+		}
+		report.Services = append(report.Services, results...)
+	}
+	return report, nil
+}
+
+// runningContainers returns the IDs of the running containers of each
+// service of a project. One-off containers of run are left out.
+func (cl *ContainerLifecycle) runningContainers(ctx context.Context, projectName string) (map[string][]string, error) {
+	filters := filters.NewArgs()
+	filters.Add("label", projectLabel(projectName))
+	containers, err := cl.client.api().ContainerList(ctx, container.ListOptions{Filters: filters})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list containers: %w", err)
+	}
+
+	running := make(map[string][]string)
+	for _, c := range containers {
+		serviceName := c.Labels[constants.ComposeServiceLabel]
+		if serviceName == "" || c.Labels[constants.ComposeOneoffLabel] == "True" {
+			continue
+		}
+		running[serviceName] = append(running[serviceName], c.ID)
+	}
+	return running, nil
+}
+
+// shutdownService runs the pre-stop commands of a service and stops its
+// containers
+func (cl *ContainerLifecycle) shutdownService(ctx context.Context, executor *ContainerExecutor, projectName, name string, containerIDs []string, options types.ShutdownOptions, progress types.ProgressReporter) types.ServiceShutdown {
+	started := time.Now()
+	config := options.Services[name]
+	result := types.ServiceShutdown{Service: name, Result: types.ShutdownStopped}
+
+	for _, command := range config.PreStop {
+		progress.Update(name, "running pre-stop: "+strings.Join(command, " "), 0, 0)
+		if err := runPreStop(ctx, executor, projectName, name, command); err != nil {
+			// The data may not be flushed, but the service still has to stop
+			cl.client.logger.Warn("Pre-stop command failed", "service", name, "command", command, "error", err)
+			result.PreStopError = err.Error()
+			break
+		}
+	}
+
+	grace := time.Duration(options.Timeout) * time.Second
+	if config.GracePeriod != "" {
+		// Callers validate the grace periods they pass
+		if parsed, err := time.ParseDuration(config.GracePeriod); err == nil {
+			grace = parsed
+		}
+	}
+
+	progress.Update(name, "stopping", 0, 0)
+	for _, id := range containerIDs {
+		switch cl.stopContainer(ctx, id, grace, options.Force) {
+		case types.ShutdownKilled:
+			result.Result = types.ShutdownKilled
+		case types.ShutdownRunning:
+			if result.Result != types.ShutdownKilled {
+				result.Result = types.ShutdownRunning
+			}
+		}
+	}
+	result.Duration = time.Since(started)
+
+	switch result.Result {
+	case types.ShutdownKilled:
+		progress.Fail(name, fmt.Sprintf("killed after %s", grace))
+	case types.ShutdownRunning:
+		progress.Fail(name, fmt.Sprintf("still running after %s", grace))
+	default:
+		progress.Done(name, "stopped")
+	}
+	return result
+}
+
+// runPreStop runs a pre-stop command inside the container of a service
+func runPreStop(ctx context.Context, executor *ContainerExecutor, projectName, name string, command []string) error {
+	result, err := executor.ExecCapture(ctx, projectName, name, command)
+	if err != nil {
+		return err
+	}
+	if result.ExitCode != 0 {
+		output := strings.TrimSpace(result.Stderr)
+		if output == "" {
+			output = strings.TrimSpace(result.Stdout)
+		}
+		return fmt.Errorf("%s exited with %d: %s", strings.Join(command, " "), result.ExitCode, output)
+	}
+	return nil
+}
+
+// stopContainer sends a container its stop signal and waits up to grace for
+// it to exit, killing it afterwards when force is set. It returns the
+// shutdown result of the container.
+func (cl *ContainerLifecycle) stopContainer(ctx context.Context, id string, grace time.Duration, force bool) string {
+	signal := "SIGTERM"
+	if inspect, err := cl.client.api().ContainerInspect(ctx, id); err == nil && inspect.Config != nil && inspect.Config.StopSignal != "" {
+		signal = inspect.Config.StopSignal
+	}
+	if err := cl.client.api().ContainerKill(ctx, id, signal); err != nil {
+		// The container exited in the meantime
+		cl.client.logger.Debug("Failed to signal container", "container", id, "error", err)
+	}
+	if cl.waitExited(ctx, id, grace) {
+		return types.ShutdownStopped
+	}
+	if !force {
+		return types.ShutdownRunning
+	}
+
+	if err := cl.client.api().ContainerKill(ctx, id, "SIGKILL"); err != nil {
+		cl.client.logger.Debug("Failed to kill container", "container", id, "error", err)
+	}
+	if !cl.waitExited(ctx, id, killWait) {
+		return types.ShutdownRunning
+	}
+	return types.ShutdownKilled
+}
+
+// waitExited reports whether the container stopped running within timeout
+func (cl *ContainerLifecycle) waitExited(ctx context.Context, id string, timeout time.Duration) bool {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	statusCh, errCh := cl.client.api().ContainerWait(ctx, id, container.WaitConditionNotRunning)
+	select {
+	case <-statusCh:
+		return true
+	case err := <-errCh:
+		// A container removed meanwhile can't be waited for, but is gone
+		if inspect, inspectErr := cl.client.api().ContainerInspect(context.WithoutCancel(ctx), id); inspectErr == nil && inspect.State != nil {
+			return !inspect.State.Running
+		}
+		cl.client.logger.Debug("Failed to wait for container", "container", id, "error", err)
+		return true
+	}
+}
@@ -0,0 +1,90 @@
+package docker
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/docker/docker/api/types/mount"
+	"github.com/docker/docker/client"
+
+	"github.com/isaacgarza/dev-stack/internal/pkg/types"
+)
+
+// ComposeVolumeLabel is set by docker compose on the named volumes it creates
+const ComposeVolumeLabel = "com.docker.compose.volume"
+
+// VolumeMounts returns the volumes mounted into a service's container, which
+// may be stopped. Bind mounts and tmpfs are left out: their data does not
+// live in Docker.
+func (ce *ContainerExecutor) VolumeMounts(ctx context.Context, projectName, serviceName string) ([]types.VolumeMount, error) {
+	containerID, err := ce.findContainer(ctx, projectName, serviceName, true)
+	if err != nil {
+		return nil, err
+	}
+
+	inspect, err := ce.client.api().ContainerInspect(ctx, containerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to inspect %s: %w", serviceName, err)
+	}
+
+	var mounts []types.VolumeMount
+	for _, m := range inspect.Mounts {
+		if m.Type != mount.TypeVolume || m.Name == "" {
+			continue
+		}
+		volume, err := ce.client.api().VolumeInspect(ctx, m.Name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to inspect volume %s: %w", m.Name, err)
+		}
+		_, named := volume.Labels[ComposeVolumeLabel]
+		mounts = append(mounts, types.VolumeMount{Name: m.Name, Destination: m.Destination, Named: named})
+	}
+	return mounts, nil
+}
+
+// ExportVolume streams the contents of a volume mounted at destination in a
+// service's container to w as a tar archive. Unlike CopyArchiveFrom it works
+// on stopped containers, so the data can be read while nothing writes to it.
+func (ce *ContainerExecutor) ExportVolume(ctx context.Context, projectName, serviceName, destination string, w io.Writer) error {
+	containerID, err := ce.findContainer(ctx, projectName, serviceName, true)
+	if err != nil {
+		return err
+	}
+
+	reader, _, err := ce.client.api().CopyFromContainer(ctx, containerID, destination)
+	if err != nil {
+		return fmt.Errorf("failed to copy %s from %s: %w", destination, serviceName, err)
+	}
+	defer func() { _ = reader.Close() }()
+
+	if _, err := io.Copy(w, reader); err != nil {
+		return fmt.Errorf("failed to copy archive of %s: %w", destination, err)
+	}
+	return nil
+}
+
+// Create creates the containers of the specified services, and their
+// volumes, without starting them
+func (cl *ContainerLifecycle) Create(ctx context.Context, projectName string, serviceNames []string) error {
+	cl.client.logger.Info("Creating services", "project", projectName, "services", serviceNames)
+
+	args := append([]string{"create"}, serviceNames...)
+	_, err := cl.client.runCompose(ctx, projectName, nil, args...)
+	return err
+}
+
+// RemoveVolumes removes the named volumes. Volumes that are already gone
+// are skipped.
+func (vs *VolumeService) RemoveVolumes(ctx context.Context, volumeNames []string) error {
+	for _, volumeName := range volumeNames {
+		if err := vs.client.api().VolumeRemove(ctx, volumeName, false); err != nil {
+			if client.IsErrNotFound(err) {
+				continue
+			}
+			return fmt.Errorf("failed to remove volume %s: %w", volumeName, err)
+		}
+		vs.client.logger.Info("Removed volume", "volume", volumeName)
+	}
+	return nil
+}
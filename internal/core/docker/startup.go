@@ -0,0 +1,260 @@
+package docker
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/isaacgarza/dev-stack/internal/pkg/compose"
+	"github.com/isaacgarza/dev-stack/internal/pkg/constants"
+	"github.com/isaacgarza/dev-stack/internal/pkg/types"
+)
+
+// defaultStartParallelism is how many services of a wave start at once
+// unless StartOptions.MaxParallel says otherwise
+const defaultStartParallelism = 4
+
+// StartupError reports the services that failed to start, each with its
+// reason, and the services left stopped because a dependency failed
+type StartupError struct {
+	Failed  map[string]error
+	Skipped []string
+}
+
+func (e *StartupError) Error() string {
+	names := make([]string, 0, len(e.Failed))
+	for name := range e.Failed {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	reasons := make([]string, 0, len(names))
+	for _, name := range names {
+		reasons = append(reasons, fmt.Sprintf("%s: %v", name, e.Failed[name]))
+	}
+	if len(e.Skipped) > 0 {
+		reasons = append(reasons, "not started because a dependency failed: "+strings.Join(e.Skipped, ", "))
+	}
+	return strings.Join(reasons, "; ")
+}
+
+// Unwrap returns the failures, so errors.As finds the kind of error a
+// service failed with
+func (e *StartupError) Unwrap() []error {
+	names := make([]string, 0, len(e.Failed))
+	for name := range e.Failed {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	errs := make([]error, 0, len(names))
+	for _, name := range names {
+		errs = append(errs, e.Failed[name])
+	}
+	return errs
+}
+
+// startWaves starts serviceNames, and the services they depend on unless
+// options.NoDeps is set, in waves of the dependency graph. The containers
+// are created first in one go, so the concurrent starts don't race to
+// create the project network and volumes. The services of a wave then
+// start together, up to options.MaxParallel at once; a service that others
+// depend on is waited for as its depends_on condition asks. Services whose
+// dependencies failed are skipped, and every failure is reported at the
+// end.
+func (cl *ContainerLifecycle) startWaves(ctx context.Context, projectName string, serviceNames []string, options types.StartOptions) error {
+	dependencies, err := compose.ServiceDependencies(profileComposeFiles(options.Profiles)...)
+	if err != nil {
+		return fmt.Errorf("failed to read service dependencies: %w", err)
+	}
+	waves, err := compose.StartupWaves(serviceNames, dependencies, !options.NoDeps)
+	if err != nil {
+		return err
+	}
+
+	var all []string
+	for _, wave := range waves {
+		all = append(all, wave...)
+	}
+	cl.client.logger.Info("Starting services", "project", projectName, "services", all, "waves", len(waves))
+
+	progress := progressOf(options.Progress)
+	create := []string{"create"}
+	if options.Build {
+		// A plain log has a line per build step to report
+		create = []string{"--progress", "plain", "create", "--build"}
+	}
+	if options.ForceRecreate {
+		create = append(create, "--force-recreate")
+	}
+	if options.Pull != "" {
+		create = append(create, "--pull", options.Pull)
+	}
+	log := &buildLog{progress: progress}
+	cmd := composeCommand(ctx, composeArgs(projectName, options.Profiles, append(create, all...)...)...)
+	cmd.Stdout, cmd.Stderr = log, log
+	err = cmd.Run()
+	log.finish(err)
+	if err != nil {
+		output := log.output.String()
+		cl.client.logger.Error("Failed to create services", "error", err, "output", output)
+		cl.reportFailure(output)
+		return fmt.Errorf("failed to create services: %w", err)
+	}
+
+	parallelism := options.MaxParallel
+	if parallelism <= 0 {
+		parallelism = defaultStartParallelism
+	}
+	awaited := awaitedConditions(dependencies, all)
+
+	var mu sync.Mutex
+	failed := make(map[string]error)
+	outputs := make(map[string]string)
+	var skipped []string
+
+	for _, wave := range waves {
+		// The dependencies of a wave are all in earlier ones, so a copy
+		// taken before it starts knows every failure that can block it,
+		// without reading the map its goroutines write to
+		mu.Lock()
+		failedBefore := maps.Clone(failed)
+		mu.Unlock()
+
+		semaphore := make(chan struct{}, parallelism)
+		var wg sync.WaitGroup
+		for _, name := range wave {
+			if blockedBy(name, dependencies, failedBefore, skipped) {
+				skipped = append(skipped, name)
+				progress.Fail(name, "skipped: a service it depends on failed to start")
+				continue
+			}
+
+			wg.Add(1)
+			go func(name string) {
+				defer wg.Done()
+				select {
+				case semaphore <- struct{}{}:
+					defer func() { <-semaphore }()
+				case <-ctx.Done():
+					return
+				}
+
+				output, err := cl.startService(ctx, projectName, name, awaited[name], options, progress)
+				if err != nil {
+					mu.Lock()
+					failed[name] = err
+					outputs[name] = output
+					mu.Unlock()
+					progress.Fail(name, err.Error())
+					return
+				}
+				progress.Done(name, "started")
+			}(name)
+		}
+		wg.Wait()
+
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+	}
+
+	if len(failed) > 0 {
+		cl.client.logger.Error("Services failed to start", "failed", len(failed), "skipped", skipped)
+		cl.saveFailureLogs(outputs)
+		return &StartupError{Failed: failed, Skipped: skipped}
+	}
+	cl.client.logger.Info("Services started successfully", "services", all)
+	return nil
+}
+
+// startService starts the created container of a service, then waits for
+// it to meet condition, the strictest depends_on condition of the services
+// depending on it. It returns the docker compose output along with the
+// error of a failed start.
+func (cl *ContainerLifecycle) startService(ctx context.Context, projectName, name, condition string, options types.StartOptions, progress types.ProgressReporter) (string, error) {
+	progress.Update(name, "starting", 0, 0)
+	args := []string{"up", "-d", "--no-deps"}
+	if options.RenewAnonVolumes {
+		args = append(args, "--renew-anon-volumes")
+	}
+	if condition == constants.ConditionServiceHealthy {
+		progress.Update(name, "starting, waiting until healthy", 0, 0)
+		args = append(args, "--wait")
+	}
+	output, err := composeCommand(ctx, composeArgs(projectName, options.Profiles, append(args, name)...)...).CombinedOutput()
+	if err != nil {
+		return string(output), composeFailure(err, output)
+	}
+
+	// docker compose wait exits with the status of the container
+	if condition == constants.ConditionServiceCompleted {
+		progress.Update(name, "running, waiting until it completes", 0, 0)
+		output, err := composeCommand(ctx, composeArgs(projectName, options.Profiles, "wait", name)...).CombinedOutput()
+		if err != nil {
+			return string(output), fmt.Errorf("did not complete successfully: %w", composeFailure(err, output))
+		}
+	}
+	return "", nil
+}
+
+// awaitedConditions returns, for each of services that another of them
+// depends on, the strictest condition a dependent waits for
+func awaitedConditions(dependencies map[string]map[string]string, services []string) map[string]string {
+	rank := map[string]int{
+		constants.ConditionServiceStarted:   1,
+		constants.ConditionServiceHealthy:   2,
+		constants.ConditionServiceCompleted: 3,
+	}
+	awaited := make(map[string]string)
+	for _, name := range services {
+		for dep, condition := range dependencies[name] {
+			if rank[condition] > rank[awaited[dep]] {
+				awaited[dep] = condition
+			}
+		}
+	}
+	return awaited
+}
+
+// blockedBy reports whether a dependency of a service failed or was skipped
+func blockedBy(name string, dependencies map[string]map[string]string, failed map[string]error, skipped []string) bool {
+	for dep := range dependencies[name] {
+		if _, ok := failed[dep]; ok {
+			return true
+		}
+		if slices.Contains(skipped, dep) {
+			return true
+		}
+	}
+	return false
+}
+
+// portConflictPatterns match the port in the errors Docker reports for a
+// host port something else listens on
+var portConflictPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`Bind for \S*:(\d+) failed: port is already allocated`),
+	regexp.MustCompile(`listen tcp\d?\s\S*:(\d+): bind: address already in use`),
+	regexp.MustCompile(`ports are not available: exposing port \w+ \S*:(\d+)`),
+}
+
+// composeFailure turns a failed docker compose run into an error carrying
+// the last line of its output, which holds the reason. A port conflict in
+// the output is a PortConflictError.
+func composeFailure(err error, output []byte) error {
+	for _, pattern := range portConflictPatterns {
+		if match := pattern.FindSubmatch(output); match != nil {
+			return fmt.Errorf("%w: %w", err, &types.PortConflictError{Port: string(match[1])})
+		}
+	}
+	lines := strings.Split(strings.TrimSpace(string(output)), "\n")
+	if last := strings.TrimSpace(lines[len(lines)-1]); last != "" {
+		return fmt.Errorf("%w: %s", err, last)
+	}
+	return err
+}
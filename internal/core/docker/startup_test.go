@@ -0,0 +1,150 @@
+package docker
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"maps"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/isaacgarza/dev-stack/internal/pkg/constants"
+	"github.com/isaacgarza/dev-stack/internal/pkg/types"
+)
+
+const startupComposeFile = `services:
+  postgres:
+    image: postgres:16
+  redis:
+    image: redis:7
+  migrate:
+    image: migrate
+    depends_on:
+      postgres:
+        condition: service_healthy
+  app:
+    image: app
+    depends_on:
+      migrate:
+        condition: service_completed_successfully
+      redis:
+        condition: service_started
+  worker:
+    image: worker
+    depends_on:
+      redis:
+        condition: service_started
+`
+
+// fakeCompose puts a docker on PATH that records its subcommands, minus the
+// compose files and project, and fails to start failing services
+func fakeCompose(t *testing.T, failing string) string {
+	if runtime.GOOS == "windows" {
+		t.Skip("the fake docker is a shell script")
+	}
+
+	t.Chdir(t.TempDir())
+	require.NoError(t, os.MkdirAll(constants.DevStackDir, 0755))
+	require.NoError(t, os.WriteFile(constants.DockerComposeFile, []byte(startupComposeFile), 0644))
+
+	dir := t.TempDir()
+	log := filepath.Join(dir, "calls")
+	script := `#!/bin/sh
+shift
+while [ "$1" = "-f" ] || [ "$1" = "-p" ]; do shift 2; done
+echo "$*" >> ` + log + `
+case "$*" in
+  "up "*" ` + failing + `") echo "Error response from daemon: port is already allocated" >&2; exit 1;;
+esac
+`
+	require.NoError(t, os.WriteFile(filepath.Join(dir, constants.DockerCmd), []byte(script), 0755))
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	return log
+}
+
+func TestStartWaves(t *testing.T) {
+	log := fakeCompose(t, "none")
+	lifecycle := NewContainerLifecycle(&Client{logger: slog.New(slog.NewTextHandler(os.Stdout, nil))})
+
+	progress := &recordedProgress{}
+	err := lifecycle.Start(context.Background(), "demo", []string{"app"}, types.StartOptions{Progress: progress})
+	require.NoError(t, err)
+
+	data, err := os.ReadFile(log)
+	require.NoError(t, err)
+	calls := strings.Split(strings.TrimSpace(string(data)), "\n")
+	require.Len(t, calls, 6)
+	assert.Equal(t, "create postgres redis migrate app", calls[0])
+	// postgres and redis start together, in either order
+	assert.ElementsMatch(t, []string{"up -d --no-deps --wait postgres", "up -d --no-deps redis"}, calls[1:3])
+	assert.Equal(t, []string{"up -d --no-deps migrate", "wait migrate", "up -d --no-deps app"}, calls[3:])
+
+	for _, name := range []string{"postgres", "redis", "migrate", "app"} {
+		assert.Equal(t, "done: started", progress.last(name), name)
+	}
+}
+
+func TestStartWaves_ReportsFailures(t *testing.T) {
+	fakeCompose(t, "redis")
+	lifecycle := NewContainerLifecycle(&Client{logger: slog.New(slog.NewTextHandler(os.Stdout, nil))})
+
+	err := lifecycle.Start(context.Background(), "demo", []string{"app"}, types.StartOptions{MaxParallel: 1})
+	var startupErr *StartupError
+	require.ErrorAs(t, err, &startupErr)
+	assert.Equal(t, []string{"redis"}, slices.Sorted(maps.Keys(startupErr.Failed)))
+	assert.Equal(t, []string{"app"}, startupErr.Skipped)
+	assert.Equal(t, "redis: exit status 1: Error response from daemon: port is already allocated; not started because a dependency failed: app", err.Error())
+}
+
+func TestStartWaves_FailureInParallelWave(t *testing.T) {
+	log := fakeCompose(t, "migrate")
+	lifecycle := NewContainerLifecycle(&Client{logger: slog.New(slog.NewTextHandler(os.Stdout, nil))})
+
+	// migrate fails while worker, in the same wave, is checked for failed
+	// dependencies
+	err := lifecycle.Start(context.Background(), "demo", []string{"migrate", "worker"}, types.StartOptions{})
+	var startupErr *StartupError
+	require.ErrorAs(t, err, &startupErr)
+	assert.Equal(t, []string{"migrate"}, slices.Sorted(maps.Keys(startupErr.Failed)))
+	assert.Empty(t, startupErr.Skipped)
+
+	data, err := os.ReadFile(log)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "up -d --no-deps worker\n")
+}
+
+func TestBuild(t *testing.T) {
+	log := fakeCompose(t, "none")
+	lifecycle := NewContainerLifecycle(&Client{logger: slog.New(slog.NewTextHandler(os.Stdout, nil))})
+
+	err := lifecycle.Build(context.Background(), "demo", []string{"app", "migrate"}, types.BuildOptions{NoCache: true, Pull: true})
+	require.NoError(t, err)
+
+	data, err := os.ReadFile(log)
+	require.NoError(t, err)
+	assert.Equal(t, "--progress plain build --no-cache --pull app migrate\n", string(data))
+}
+
+func TestComposeFailure(t *testing.T) {
+	exitErr := errors.New("exit status 1")
+
+	err := composeFailure(exitErr, []byte(" Container demo-redis-1  Starting\nError response from daemon: driver failed: Bind for 0.0.0.0:6379 failed: port is already allocated\n"))
+	var conflict *types.PortConflictError
+	require.ErrorAs(t, err, &conflict)
+	assert.Equal(t, "6379", conflict.Port)
+	assert.ErrorIs(t, err, exitErr)
+
+	startupErr := &StartupError{Failed: map[string]error{"redis": err}}
+	assert.Equal(t, types.ErrorKindPortConflict, types.ErrorKindOf(startupErr))
+
+	err = composeFailure(exitErr, []byte("pulling\nno such image\n"))
+	assert.EqualError(t, err, "exit status 1: no such image")
+	assert.Equal(t, types.ErrorKindGeneric, types.ErrorKindOf(err))
+}
@@ -0,0 +1,94 @@
+package docker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/isaacgarza/dev-stack/internal/pkg/constants"
+	"github.com/isaacgarza/dev-stack/internal/pkg/types"
+)
+
+// Classes of Docker operations, each bounded by its own timeout
+const (
+	opStart = "start"
+	opStop  = "stop"
+	opExec  = "exec"
+	opPull  = "pull"
+)
+
+var (
+	timeoutsMu sync.RWMutex
+	timeouts   = types.TimeoutConfig{}.WithDefaults()
+)
+
+// SetTimeouts sets how long Docker operations may run; unset timeouts keep
+// their defaults
+func SetTimeouts(config types.TimeoutConfig) {
+	timeoutsMu.Lock()
+	defer timeoutsMu.Unlock()
+	timeouts = config.WithDefaults()
+}
+
+// Timeouts returns how long Docker operations may run
+func Timeouts() types.TimeoutConfig {
+	timeoutsMu.RLock()
+	defer timeoutsMu.RUnlock()
+	return timeouts
+}
+
+// timeoutFor returns the timeout of an operation class
+func timeoutFor(class string) time.Duration {
+	timeoutsMu.RLock()
+	defer timeoutsMu.RUnlock()
+	switch class {
+	case opStart:
+		return timeouts.Start
+	case opStop:
+		return timeouts.Stop
+	case opExec:
+		return timeouts.Exec
+	default:
+		return timeouts.Pull
+	}
+}
+
+// TimeoutError reports a Docker operation that didn't finish within the
+// timeout of its class
+type TimeoutError struct {
+	Class     string
+	Operation string
+	Timeout   time.Duration
+}
+
+func (e *TimeoutError) Error() string {
+	return fmt.Sprintf("%s timed out after %s; if it needs longer, run '%s config set timeouts.%s <duration>'",
+		e.Operation, e.Timeout, constants.AppName, e.Class)
+}
+
+// Unwrap lets errors.Is match context.DeadlineExceeded
+func (e *TimeoutError) Unwrap() error {
+	return context.DeadlineExceeded
+}
+
+// Kind returns types.ErrorKindTimeout
+func (e *TimeoutError) Kind() types.ErrorKind {
+	return types.ErrorKindTimeout
+}
+
+// withTimeout runs fn with ctx bounded by the timeout of class. When that
+// deadline, rather than ctx itself, ends the operation, the error is a
+// TimeoutError naming the operation.
+func withTimeout(ctx context.Context, class, operation string, fn func(ctx context.Context) error) error {
+	timeout := timeoutFor(class)
+	bounded, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	err := fn(bounded)
+	if err != nil && ctx.Err() == nil && errors.Is(bounded.Err(), context.DeadlineExceeded) {
+		return &TimeoutError{Class: class, Operation: operation, Timeout: timeout}
+	}
+	return err
+}
@@ -0,0 +1,46 @@
+package docker
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/isaacgarza/dev-stack/internal/pkg/types"
+)
+
+func TestWithTimeout(t *testing.T) {
+	SetTimeouts(types.TimeoutConfig{Exec: 20 * time.Millisecond})
+	t.Cleanup(func() { SetTimeouts(types.TimeoutConfig{}) })
+
+	assert.Equal(t, 20*time.Millisecond, timeoutFor(opExec))
+	assert.Equal(t, types.DefaultStartTimeout, timeoutFor(opStart))
+
+	wait := func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+
+	err := withTimeout(context.Background(), opExec, "pg_dump in postgres", wait)
+	var timeoutErr *TimeoutError
+	require.ErrorAs(t, err, &timeoutErr)
+	assert.Equal(t, opExec, timeoutErr.Class)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Contains(t, err.Error(), "pg_dump in postgres timed out after 20ms")
+	assert.Contains(t, err.Error(), "config set timeouts.exec")
+
+	// A caller's own cancellation or deadline isn't reported as a timeout
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Millisecond)
+	defer cancel()
+	err = withTimeout(ctx, opExec, "pg_dump in postgres", wait)
+	assert.False(t, errors.As(err, &timeoutErr))
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	failure := errors.New("exit status 1")
+	assert.Equal(t, failure, withTimeout(context.Background(), opExec, "pg_dump in postgres", func(context.Context) error {
+		return failure
+	}))
+}
@@ -0,0 +1,165 @@
+package docker
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"slices"
+	"time"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/api/types/mount"
+	"github.com/docker/docker/client"
+
+	"github.com/isaacgarza/dev-stack/internal/pkg/constants"
+	"github.com/isaacgarza/dev-stack/internal/pkg/types"
+)
+
+// VolumeHelperImage is the image of the helper containers volumes are
+// copied through. It is pulled the first time it is needed.
+const VolumeHelperImage = "busybox:1.36"
+
+// VolumeArchiveRoot is the top-level directory of volume archives, where
+// helper containers mount the volume
+const VolumeArchiveRoot = "volume"
+
+// volumeHelperPurpose labels the helper containers of volume archives
+const volumeHelperPurpose = "volume-archive"
+
+// Exists reports whether a volume exists
+func (vs *VolumeService) Exists(ctx context.Context, volumeName string) (bool, error) {
+	if _, err := vs.client.api().VolumeInspect(ctx, volumeName); err != nil {
+		if client.IsErrNotFound(err) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to inspect volume %s: %w", volumeName, err)
+	}
+	return true, nil
+}
+
+// RunningServices returns the services of the project running with the
+// volume mounted
+func (vs *VolumeService) RunningServices(ctx context.Context, projectName, volumeName string) ([]string, error) {
+	args := filters.NewArgs(
+		filters.Arg("label", projectLabel(projectName)),
+		filters.Arg("volume", volumeName),
+		filters.Arg("status", "running"),
+	)
+	containers, err := vs.client.api().ContainerList(ctx, container.ListOptions{Filters: args})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list containers using %s: %w", volumeName, err)
+	}
+
+	var names []string
+	for _, c := range containers {
+		if name := c.Labels[constants.ComposeServiceLabel]; name != "" && !slices.Contains(names, name) {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+	return names, nil
+}
+
+// Export streams the contents of a volume to w as a tar archive whose
+// top-level directory is VolumeArchiveRoot. The volume is read through a
+// helper container that is never started, so it works for any volume,
+// whatever the service using it.
+func (vs *VolumeService) Export(ctx context.Context, volumeName string, w io.Writer) error {
+	return withTimeout(ctx, opExec, "exporting volume "+volumeName, func(ctx context.Context) error {
+		helperID, err := vs.createHelper(ctx, volumeName, true, nil)
+		if err != nil {
+			return err
+		}
+		defer vs.removeHelper(context.WithoutCancel(ctx), helperID)
+
+		reader, _, err := vs.client.api().CopyFromContainer(ctx, helperID, "/"+VolumeArchiveRoot)
+		if err != nil {
+			return fmt.Errorf("failed to copy volume %s: %w", volumeName, err)
+		}
+		defer func() { _ = reader.Close() }()
+
+		if _, err := io.Copy(w, reader); err != nil {
+			return fmt.Errorf("failed to copy archive of volume %s: %w", volumeName, err)
+		}
+		return nil
+	})
+}
+
+// Import replaces the contents of a volume with those of a tar archive
+// written by Export. The helper container empties the volume first, so
+// files the archive doesn't hold are gone afterwards.
+func (vs *VolumeService) Import(ctx context.Context, volumeName string, r io.Reader) error {
+	return withTimeout(ctx, opExec, "importing volume "+volumeName, func(ctx context.Context) error {
+		helperID, err := vs.createHelper(ctx, volumeName, false, []string{"find", "/" + VolumeArchiveRoot, "-mindepth", "1", "-delete"})
+		if err != nil {
+			return err
+		}
+		defer vs.removeHelper(context.WithoutCancel(ctx), helperID)
+
+		if err := vs.client.api().ContainerStart(ctx, helperID, container.StartOptions{}); err != nil {
+			return fmt.Errorf("failed to start helper container: %w", err)
+		}
+		statusCh, errCh := vs.client.api().ContainerWait(ctx, helperID, container.WaitConditionNotRunning)
+		select {
+		case err := <-errCh:
+			return fmt.Errorf("failed to empty volume %s: %w", volumeName, err)
+		case status := <-statusCh:
+			if status.StatusCode != 0 {
+				return fmt.Errorf("failed to empty volume %s: exit code %d", volumeName, status.StatusCode)
+			}
+		}
+
+		if err := vs.client.api().CopyToContainer(ctx, helperID, "/", r, container.CopyToContainerOptions{}); err != nil {
+			return fmt.Errorf("failed to extract archive into volume %s: %w", volumeName, err)
+		}
+		return nil
+	})
+}
+
+// createHelper creates a helper container with the volume mounted at
+// VolumeArchiveRoot, pulling the helper image if it isn't present
+func (vs *VolumeService) createHelper(ctx context.Context, volumeName string, readOnly bool, cmd []string) (string, error) {
+	missing, err := vs.client.Images().Missing(ctx, []string{VolumeHelperImage})
+	if err != nil {
+		return "", err
+	}
+	if len(missing) > 0 {
+		vs.client.logger.Info("Pulling helper image", "image", VolumeHelperImage)
+		summary, err := NewImagePuller(vs.client).Pull(ctx, missing, types.PullOptions{})
+		if err != nil {
+			return "", err
+		}
+		if summary.Failed > 0 {
+			return "", fmt.Errorf("failed to pull helper image %s: %s", VolumeHelperImage, summary.Results[0].Error)
+		}
+	}
+
+	config := &container.Config{
+		Image:  VolumeHelperImage,
+		Cmd:    cmd,
+		Labels: map[string]string{ScratchLabel: volumeHelperPurpose},
+	}
+	hostConfig := &container.HostConfig{
+		Mounts: []mount.Mount{{
+			Type:     mount.TypeVolume,
+			Source:   volumeName,
+			Target:   "/" + VolumeArchiveRoot,
+			ReadOnly: readOnly,
+		}},
+	}
+	name := fmt.Sprintf("%s-%s-%d", volumeName, volumeHelperPurpose, time.Now().UnixNano())
+	created, err := vs.client.api().ContainerCreate(ctx, config, hostConfig, nil, nil, name)
+	if err != nil {
+		return "", fmt.Errorf("failed to create helper container for %s: %w", volumeName, err)
+	}
+	return created.ID, nil
+}
+
+// removeHelper removes a helper container, logging failures since the
+// copy itself is done
+func (vs *VolumeService) removeHelper(ctx context.Context, helperID string) {
+	if err := vs.client.api().ContainerRemove(ctx, helperID, container.RemoveOptions{Force: true}); err != nil {
+		vs.client.logger.Error("Failed to remove helper container", "container", helperID, "error", err)
+	}
+}
@@ -0,0 +1,128 @@
+package events
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/isaacgarza/dev-stack/internal/pkg/types"
+)
+
+// Alert is a notification about a service raised by an event
+type Alert struct {
+	Service string
+	Title   string
+	Message string
+}
+
+// AlertRules turns the event stream into alerts: a service becoming
+// unhealthy or healthy again, running out of memory, or exiting more than
+// the restart threshold within the window. Paused services raise none
+// until they are resumed.
+type AlertRules struct {
+	config types.NotificationsConfig
+	health map[string]string
+	exits  map[string][]time.Time
+	paused map[string]bool
+}
+
+// NewAlertRules creates the alert rules of a notifications configuration
+func NewAlertRules(config types.NotificationsConfig) *AlertRules {
+	return &AlertRules{
+		config: config,
+		health: make(map[string]string),
+		exits:  make(map[string][]time.Time),
+		paused: make(map[string]bool),
+	}
+}
+
+// MarkPaused records services already paused when the event stream starts
+func (r *AlertRules) MarkPaused(serviceNames ...string) {
+	for _, serviceName := range serviceNames {
+		r.paused[serviceName] = true
+	}
+}
+
+// Observe records event and returns the alert it raises, if any. Muted
+// services never raise alerts.
+func (r *AlertRules) Observe(event types.ServiceEvent) (Alert, bool) {
+	if r.config.Muted(event.Service) {
+		return Alert{}, false
+	}
+
+	switch event.Action {
+	case types.EventPause:
+		r.paused[event.Service] = true
+		return Alert{}, false
+	case types.EventUnpause, types.EventStart:
+		delete(r.paused, event.Service)
+	}
+	if r.paused[event.Service] {
+		// The health status is still recorded, so resuming a service that
+		// was unhealthy before isn't reported as a change
+		if event.Action == types.EventHealthStatus {
+			r.health[event.Service] = event.Health
+		}
+		return Alert{}, false
+	}
+
+	switch event.Action {
+	case types.EventOOM:
+		return Alert{
+			Service: event.Service,
+			Title:   event.Service + " ran out of memory",
+			Message: fmt.Sprintf("Container %s was killed for exceeding its memory limit", event.Container),
+		}, true
+	case types.EventHealthStatus:
+		previous := r.health[event.Service]
+		r.health[event.Service] = event.Health
+		if event.Health == previous {
+			return Alert{}, false
+		}
+		if event.Health == types.HealthStatusUnhealthy.String() {
+			return Alert{
+				Service: event.Service,
+				Title:   event.Service + " is unhealthy",
+				Message: fmt.Sprintf("Container %s is failing its health check", event.Container),
+			}, true
+		}
+		if event.Health == types.HealthStatusHealthy.String() && previous == types.HealthStatusUnhealthy.String() {
+			return Alert{
+				Service: event.Service,
+				Title:   event.Service + " recovered",
+				Message: fmt.Sprintf("Container %s is healthy again", event.Container),
+			}, true
+		}
+	case types.EventDie:
+		return r.observeExit(event)
+	}
+	return Alert{}, false
+}
+
+// observeExit counts the exits of a service within the window. Reaching
+// the threshold raises an alert and starts the count over, so a service
+// that keeps crashing isn't reported on every exit.
+func (r *AlertRules) observeExit(event types.ServiceEvent) (Alert, bool) {
+	window := r.config.Window()
+	var recent []time.Time
+	for _, at := range r.exits[event.Service] {
+		if event.Time.Sub(at) < window {
+			recent = append(recent, at)
+		}
+	}
+	recent = append(recent, event.Time)
+	if len(recent) < r.config.Threshold() {
+		r.exits[event.Service] = recent
+		return Alert{}, false
+	}
+
+	delete(r.exits, event.Service)
+	message := fmt.Sprintf("Exited %d times in %s", len(recent), window)
+	if event.ExitCode != nil {
+		message += fmt.Sprintf(", last with exit code %d", *event.ExitCode)
+	}
+	return Alert{
+		Service: event.Service,
+		Title:   event.Service + " keeps restarting",
+		Message: message,
+	}, true
+}
@@ -0,0 +1,99 @@
+package events
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/isaacgarza/dev-stack/internal/pkg/types"
+)
+
+func TestAlertRules_Observe(t *testing.T) {
+	rules := NewAlertRules(types.NotificationsConfig{RestartThreshold: 3, RestartWindow: "5m", Mute: []string{"worker"}})
+	at := time.Date(2026, 10, 14, 12, 0, 0, 0, time.UTC)
+	event := func(service, action string, offset time.Duration) types.ServiceEvent {
+		return types.ServiceEvent{Time: at.Add(offset), Service: service, Container: service + "-1", Action: action}
+	}
+	health := func(service, status string) types.ServiceEvent {
+		e := event(service, types.EventHealthStatus, 0)
+		e.Health = status
+		return e
+	}
+
+	_, ok := rules.Observe(health("postgres", "healthy"))
+	assert.False(t, ok)
+	alert, ok := rules.Observe(health("postgres", "unhealthy"))
+	require.True(t, ok)
+	assert.Equal(t, "postgres is unhealthy", alert.Title)
+	_, ok = rules.Observe(health("postgres", "unhealthy"))
+	assert.False(t, ok, "repeated statuses are reported once")
+	alert, ok = rules.Observe(health("postgres", "healthy"))
+	require.True(t, ok)
+	assert.Equal(t, "postgres recovered", alert.Title)
+
+	alert, ok = rules.Observe(event("api", types.EventOOM, 0))
+	require.True(t, ok)
+	assert.Equal(t, Alert{Service: "api", Title: "api ran out of memory", Message: "Container api-1 was killed for exceeding its memory limit"}, alert)
+
+	code := 1
+	for i, offset := range []time.Duration{0, 6 * time.Minute, 8 * time.Minute} {
+		_, ok = rules.Observe(event("redis", types.EventDie, offset))
+		assert.False(t, ok, "exit %d", i)
+	}
+	die := event("redis", types.EventDie, 9*time.Minute)
+	die.ExitCode = &code
+	alert, ok = rules.Observe(die)
+	require.True(t, ok)
+	assert.Equal(t, "redis keeps restarting", alert.Title)
+	assert.Equal(t, "Exited 3 times in 5m0s, last with exit code 1", alert.Message)
+	_, ok = rules.Observe(event("redis", types.EventDie, 10*time.Minute))
+	assert.False(t, ok, "the count starts over after an alert")
+
+	_, ok = rules.Observe(event("worker", types.EventOOM, 0))
+	assert.False(t, ok, "muted services raise no alerts")
+}
+
+func TestAlertRules_Paused(t *testing.T) {
+	rules := NewAlertRules(types.NotificationsConfig{})
+	event := func(service, action, health string) types.ServiceEvent {
+		return types.ServiceEvent{Time: time.Now(), Service: service, Container: service + "-1", Action: action, Health: health}
+	}
+
+	_, ok := rules.Observe(event("postgres", types.EventPause, ""))
+	assert.False(t, ok)
+	_, ok = rules.Observe(event("postgres", types.EventHealthStatus, "unhealthy"))
+	assert.False(t, ok, "paused services raise no alerts")
+	_, ok = rules.Observe(event("postgres", types.EventOOM, ""))
+	assert.False(t, ok, "paused services raise no alerts")
+
+	_, ok = rules.Observe(event("postgres", types.EventUnpause, ""))
+	assert.False(t, ok)
+	_, ok = rules.Observe(event("postgres", types.EventHealthStatus, "unhealthy"))
+	assert.False(t, ok, "the status recorded while paused is kept")
+	_, ok = rules.Observe(event("postgres", types.EventOOM, ""))
+	assert.True(t, ok, "resumed services raise alerts again")
+
+	rules.MarkPaused("redis")
+	_, ok = rules.Observe(event("redis", types.EventOOM, ""))
+	assert.False(t, ok, "services paused before the stream raise no alerts")
+	_, ok = rules.Observe(event("redis", types.EventStart, ""))
+	assert.False(t, ok)
+	_, ok = rules.Observe(event("redis", types.EventOOM, ""))
+	assert.True(t, ok, "a started service is no longer paused")
+}
+
+func TestNotifyCommand(t *testing.T) {
+	name, args := notifyCommand("linux", "postgres is unhealthy", "Container postgres-1 is failing")
+	assert.Equal(t, "notify-send", name)
+	assert.Equal(t, []string{"--app-name=dev-stack", "postgres is unhealthy", "Container postgres-1 is failing"}, args)
+
+	name, args = notifyCommand("darwin", `say "hi"`, `a\b`)
+	assert.Equal(t, "osascript", name)
+	assert.Equal(t, []string{"-e", `display notification "a\\b" with title "dev-stack" subtitle "say \"hi\""`}, args)
+
+	name, args = notifyCommand("windows", "it's down", "message")
+	assert.Equal(t, "powershell", name)
+	assert.Contains(t, args[len(args)-1], "ShowBalloonTip(10000, 'it''s down', 'message', 'Warning')")
+}
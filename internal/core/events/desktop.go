@@ -0,0 +1,72 @@
+package events
+
+import (
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	"github.com/isaacgarza/dev-stack/internal/pkg/utils"
+)
+
+// appName is the application desktop notifications are shown for
+const appName = "dev-stack"
+
+// Notifier shows alerts to the user
+type Notifier interface {
+	Notify(alert Alert) error
+}
+
+// DesktopNotifier shows alerts as native notifications: through
+// notify-send on Linux, osascript on macOS and a PowerShell balloon tip on
+// Windows
+type DesktopNotifier struct {
+	goos string
+}
+
+// NewDesktopNotifier creates a notifier for the current platform
+func NewDesktopNotifier() *DesktopNotifier {
+	return &DesktopNotifier{goos: runtime.GOOS}
+}
+
+// Notify shows an alert without waiting for the notification to close
+func (n *DesktopNotifier) Notify(alert Alert) error {
+	name, args := notifyCommand(n.goos, alert.Title, alert.Message)
+	cmd := exec.Command(name, args...)
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to show a notification with %s: %w", name, err)
+	}
+	go func() { _ = cmd.Wait() }()
+	return nil
+}
+
+// notifyCommand returns the command showing a notification on goos
+func notifyCommand(goos, title, message string) (string, []string) {
+	switch goos {
+	case utils.OSDarwin:
+		script := fmt.Sprintf("display notification %s with title %s subtitle %s",
+			appleScriptString(message), appleScriptString(appName), appleScriptString(title))
+		return "osascript", []string{"-e", script}
+	case utils.OSWindows:
+		// The balloon tip disappears once PowerShell exits, so keep it
+		// running for as long as the tip is shown
+		script := fmt.Sprintf("Add-Type -AssemblyName System.Windows.Forms; "+
+			"$n = New-Object System.Windows.Forms.NotifyIcon; "+
+			"$n.Icon = [System.Drawing.SystemIcons]::Warning; $n.Visible = $true; "+
+			"$n.ShowBalloonTip(10000, %s, %s, 'Warning'); Start-Sleep -Seconds 10; $n.Dispose()",
+			powerShellString(title), powerShellString(message))
+		return "powershell", []string{"-NoProfile", "-NonInteractive", "-Command", script}
+	default:
+		return "notify-send", []string{"--app-name=" + appName, title, message}
+	}
+}
+
+// appleScriptString quotes s as an AppleScript string literal
+func appleScriptString(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
+
+// powerShellString quotes s as a single quoted PowerShell string literal
+func powerShellString(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
@@ -0,0 +1,71 @@
+// Package events delivers the lifecycle events of the stack's containers to
+// the webhooks of the project configuration
+package events
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/isaacgarza/dev-stack/internal/pkg/types"
+)
+
+// Dispatcher posts events to the webhooks they match
+type Dispatcher struct {
+	httpClient *http.Client
+	webhooks   []types.WebhookConfig
+	userAgent  string
+}
+
+// NewDispatcher creates a dispatcher over webhooks
+func NewDispatcher(httpClient *http.Client, webhooks []types.WebhookConfig, userAgent string) *Dispatcher {
+	return &Dispatcher{httpClient: httpClient, webhooks: webhooks, userAgent: userAgent}
+}
+
+// Dispatch posts event as JSON to every webhook it matches. A failing
+// webhook doesn't keep the others from being sent the event; the errors
+// of all of them are returned together.
+func (d *Dispatcher) Dispatch(ctx context.Context, event types.ServiceEvent) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, webhook := range d.webhooks {
+		if !webhook.Matches(event) {
+			continue
+		}
+		if err := d.post(ctx, webhook, body); err != nil {
+			errs = append(errs, fmt.Errorf("webhook %s: %w", webhook.URL, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (d *Dispatcher) post(ctx context.Context, webhook types.WebhookConfig, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if d.userAgent != "" {
+		req.Header.Set("User-Agent", d.userAgent)
+	}
+	for name, value := range webhook.Headers {
+		req.Header.Set(name, value)
+	}
+
+	resp, err := d.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,49 @@
+package events
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/isaacgarza/dev-stack/internal/pkg/types"
+)
+
+func TestDispatcher_Dispatch(t *testing.T) {
+	var received []types.ServiceEvent
+	var headers []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var event types.ServiceEvent
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&event))
+		received = append(received, event)
+		headers = append(headers, r.Header.Get("X-Token"))
+	}))
+	defer server.Close()
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer failing.Close()
+
+	dispatcher := NewDispatcher(server.Client(), []types.WebhookConfig{
+		{URL: server.URL + "/all", Headers: map[string]string{"X-Token": "secret"}},
+		{URL: server.URL + "/crashes", Events: []string{types.EventDie, types.EventOOM}},
+		{URL: failing.URL, Services: []string{"redis"}},
+	}, "dev-stack")
+
+	code := 137
+	die := types.ServiceEvent{Time: time.Date(2026, 10, 14, 12, 0, 0, 0, time.UTC), Service: "postgres", Action: types.EventDie, ExitCode: &code}
+	require.NoError(t, dispatcher.Dispatch(t.Context(), die))
+	require.Len(t, received, 2)
+	assert.Equal(t, []string{"secret", ""}, headers)
+	assert.Equal(t, "postgres", received[1].Service)
+	require.NotNil(t, received[1].ExitCode)
+	assert.Equal(t, 137, *received[1].ExitCode)
+
+	err := dispatcher.Dispatch(t.Context(), types.ServiceEvent{Service: "redis", Action: types.EventStart})
+	assert.ErrorContains(t, err, "webhook "+failing.URL+": unexpected status 500")
+	assert.Len(t, received, 3)
+}
@@ -0,0 +1,201 @@
+package healthz
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/isaacgarza/dev-stack/internal/pkg/types"
+)
+
+// Overall stack states reported by the aggregator
+const (
+	StatusOK       = "ok"
+	StatusStarting = "starting"
+	StatusDegraded = "degraded"
+)
+
+// cacheTTL bounds how often the daemon is queried when many dev servers
+// poll /healthz at once
+const cacheTTL = 2 * time.Second
+
+// StatusLister is the subset of container operations the aggregator needs;
+// it is satisfied by docker.ContainerService
+type StatusLister interface {
+	List(ctx context.Context, projectName string, serviceNames []string) ([]types.ServiceStatus, error)
+}
+
+// ServiceHealth is the health of a single service as reported by /healthz
+type ServiceHealth struct {
+	State  string `json:"state"`
+	Health string `json:"health"`
+	Ready  bool   `json:"ready"`
+}
+
+// Report is the body returned by /healthz
+type Report struct {
+	Status    string                   `json:"status"`
+	Project   string                   `json:"project"`
+	Services  map[string]ServiceHealth `json:"services"`
+	Missing   []string                 `json:"missing,omitempty"`
+	CheckedAt time.Time                `json:"checked_at"`
+}
+
+// Aggregator summarises the health of every service in a project behind a
+// single HTTP endpoint, using container state from the Docker daemon
+type Aggregator struct {
+	containers  StatusLister
+	projectName string
+	exclude     map[string]bool
+
+	mu       sync.Mutex
+	cached   []types.ServiceStatus
+	cachedAt time.Time
+}
+
+// NewAggregator creates a new health aggregator. Services listed in exclude
+// (typically the aggregator's own service) are left out of the report.
+func NewAggregator(containers StatusLister, projectName string, exclude ...string) *Aggregator {
+	excluded := make(map[string]bool, len(exclude))
+	for _, name := range exclude {
+		excluded[name] = true
+	}
+	return &Aggregator{containers: containers, projectName: projectName, exclude: excluded}
+}
+
+// Handler returns the HTTP handler serving /healthz and /livez.
+//
+// GET /healthz returns 200 when every service is running and healthy and 503
+// otherwise. Repeat ?service=NAME (or pass a comma separated list) to gate on
+// a subset; requested services that have no container are reported missing.
+// GET /livez always returns 200 and is used for the aggregator's own health check.
+func (a *Aggregator) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", a.serveHealthz)
+	mux.HandleFunc("/livez", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte("ok\n"))
+	})
+	return mux
+}
+
+// Check builds a health report for the requested services, or for every
+// service in the project when none are given
+func (a *Aggregator) Check(ctx context.Context, serviceNames []string) (*Report, error) {
+	statuses, err := a.listStatuses(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &Report{
+		Status:    StatusOK,
+		Project:   a.projectName,
+		Services:  make(map[string]ServiceHealth),
+		CheckedAt: time.Now().UTC(),
+	}
+
+	starting := false
+	for _, status := range statuses {
+		if a.exclude[status.Name] || (len(serviceNames) > 0 && !containsName(serviceNames, status.Name)) {
+			continue
+		}
+
+		ready := status.State.IsRunning() && (status.Health.IsHealthy() || status.Health == types.HealthStatusNone || status.Health == "")
+		report.Services[status.Name] = ServiceHealth{
+			State:  status.State.String(),
+			Health: status.Health.String(),
+			Ready:  ready,
+		}
+
+		if !ready {
+			if status.Health.IsStarting() {
+				starting = true
+			} else {
+				report.Status = StatusDegraded
+			}
+		}
+	}
+
+	for _, name := range serviceNames {
+		if _, ok := report.Services[name]; !ok {
+			report.Missing = append(report.Missing, name)
+		}
+	}
+	sort.Strings(report.Missing)
+
+	if len(report.Missing) > 0 {
+		report.Status = StatusDegraded
+	} else if starting && report.Status == StatusOK {
+		report.Status = StatusStarting
+	}
+
+	return report, nil
+}
+
+// listStatuses returns the project's container statuses, reusing a recent
+// result when one is available
+func (a *Aggregator) listStatuses(ctx context.Context) ([]types.ServiceStatus, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.cached != nil && time.Since(a.cachedAt) < cacheTTL {
+		return a.cached, nil
+	}
+
+	statuses, err := a.containers.List(ctx, a.projectName, nil)
+	if err != nil {
+		return nil, err
+	}
+	if statuses == nil {
+		statuses = []types.ServiceStatus{}
+	}
+
+	a.cached = statuses
+	a.cachedAt = time.Now()
+	return statuses, nil
+}
+
+func (a *Aggregator) serveHealthz(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	var serviceNames []string
+	for _, value := range r.URL.Query()["service"] {
+		for _, name := range strings.Split(value, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				serviceNames = append(serviceNames, name)
+			}
+		}
+	}
+
+	report, err := a.Check(r.Context(), serviceNames)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+
+	code := http.StatusOK
+	if report.Status != StatusOK {
+		code = http.StatusServiceUnavailable
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(report)
+}
+
+func containsName(names []string, name string) bool {
+	for _, n := range names {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,86 @@
+package healthz
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/isaacgarza/dev-stack/internal/pkg/types"
+)
+
+type fakeLister struct {
+	statuses []types.ServiceStatus
+	calls    int
+}
+
+func (f *fakeLister) List(ctx context.Context, projectName string, serviceNames []string) ([]types.ServiceStatus, error) {
+	f.calls++
+	return f.statuses, nil
+}
+
+func newTestLister() *fakeLister {
+	return &fakeLister{statuses: []types.ServiceStatus{
+		{Name: "postgres", State: types.ServiceStateRunning, Health: types.HealthStatusHealthy},
+		{Name: "redis", State: types.ServiceStateRunning, Health: types.HealthStatusNone},
+		{Name: "kafka-broker", State: types.ServiceStateRunning, Health: types.HealthStatusStarting},
+		{Name: "healthz", State: types.ServiceStateRunning, Health: types.HealthStatusHealthy},
+	}}
+}
+
+func TestAggregatorCheck(t *testing.T) {
+	tests := []struct {
+		name            string
+		services        []string
+		expectedStatus  string
+		expectedMissing []string
+	}{
+		{name: "all services", expectedStatus: StatusStarting},
+		{name: "ready subset", services: []string{"postgres", "redis"}, expectedStatus: StatusOK},
+		{name: "missing service", services: []string{"postgres", "mysql"}, expectedStatus: StatusDegraded, expectedMissing: []string{"mysql"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			aggregator := NewAggregator(newTestLister(), "test", "healthz")
+			report, err := aggregator.Check(context.Background(), tt.services)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedStatus, report.Status)
+			assert.Equal(t, tt.expectedMissing, report.Missing)
+			assert.NotContains(t, report.Services, "healthz")
+		})
+	}
+}
+
+func TestAggregatorHandler(t *testing.T) {
+	lister := newTestLister()
+	server := httptest.NewServer(NewAggregator(lister, "test", "healthz").Handler())
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/healthz")
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+
+	resp, err = http.Get(server.URL + "/healthz?service=postgres&service=redis")
+	require.NoError(t, err)
+	defer func() { _ = resp.Body.Close() }()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var report Report
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&report))
+	assert.Equal(t, StatusOK, report.Status)
+	assert.Len(t, report.Services, 2)
+
+	// Both requests fall within the cache window
+	assert.Equal(t, 1, lister.calls)
+
+	resp, err = http.Get(server.URL + "/livez")
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+}
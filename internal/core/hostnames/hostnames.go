@@ -0,0 +1,156 @@
+package hostnames
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strings"
+
+	"github.com/isaacgarza/dev-stack/internal/pkg/utils"
+)
+
+// DefaultDomain is the domain project hostnames are registered under. The
+// .test top-level domain is reserved and never resolves on the internet.
+const DefaultDomain = "test"
+
+// loopback is the address every hostname points to
+const loopback = "127.0.0.1"
+
+// invalidLabelChars matches what may not appear in a DNS label
+var invalidLabelChars = regexp.MustCompile(`[^a-z0-9-]+`)
+
+// localhostHost matches localhost as the host of an env value, but not as
+// part of a longer name such as jaeger.localhost
+var localhostHost = regexp.MustCompile(`(^|[^a-zA-Z0-9_.-])localhost($|[^a-zA-Z0-9_.-])`)
+
+// Hostname returns the hostname of a project's service, such as
+// postgres.myproject.test
+func Hostname(serviceName, projectName, domain string) string {
+	if domain == "" {
+		domain = DefaultDomain
+	}
+	return label(serviceName) + "." + label(projectName) + "." + strings.Trim(domain, ".")
+}
+
+// label turns a name into a valid DNS label
+func label(name string) string {
+	name = strings.ReplaceAll(strings.ToLower(name), "_", "-")
+	return strings.Trim(invalidLabelChars.ReplaceAllString(name, ""), "-")
+}
+
+// Rewrite replaces localhost as the host of an env value, such as in
+// POSTGRES_HOST=localhost or http://localhost:16686, with hostname
+func Rewrite(value, hostname string) string {
+	// Adjacent matches share a separator, so replace until nothing changes
+	for {
+		rewritten := localhostHost.ReplaceAllString(value, "${1}"+hostname+"${2}")
+		if rewritten == value {
+			return value
+		}
+		value = rewritten
+	}
+}
+
+// HostsFile returns the path of the system hosts file
+func HostsFile() string {
+	if runtime.GOOS == "windows" {
+		root := os.Getenv("SystemRoot")
+		if root == "" {
+			root = `C:\Windows`
+		}
+		return filepath.Join(root, "System32", "drivers", "etc", "hosts")
+	}
+	return "/etc/hosts"
+}
+
+// blockMarkers returns the comment lines delimiting a project's entries
+func blockMarkers(projectName string) (string, string) {
+	return "# BEGIN dev-stack " + label(projectName), "# END dev-stack " + label(projectName)
+}
+
+// Apply returns hosts file content with the project's block of entries
+// replaced by one pointing hostnames at the loopback address. Entries
+// outside the block are kept as they are, as is the file's line ending,
+// CRLF on Windows. No hostnames removes the block, and content without a
+// block is returned unchanged.
+func Apply(content, projectName string, hostnames []string) string {
+	begin, end := blockMarkers(projectName)
+
+	var lines []string
+	inBlock, found := false, false
+	for _, line := range utils.SplitLines(content) {
+		switch strings.TrimSpace(line) {
+		case begin:
+			inBlock, found = true, true
+			continue
+		case end:
+			inBlock = false
+			continue
+		}
+		if !inBlock {
+			lines = append(lines, line)
+		}
+	}
+	if !found && len(hostnames) == 0 {
+		return content
+	}
+	for len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) == "" {
+		lines = lines[:len(lines)-1]
+	}
+
+	if len(hostnames) > 0 {
+		lines = append(lines, "", begin)
+		for _, hostname := range hostnames {
+			lines = append(lines, loopback+"\t"+hostname)
+		}
+		lines = append(lines, end)
+	}
+	return utils.JoinLines(lines, utils.LineEnding(content))
+}
+
+// Registered returns the hostnames of the project's block in hosts file
+// content
+func Registered(content, projectName string) []string {
+	begin, end := blockMarkers(projectName)
+
+	var hostnames []string
+	inBlock := false
+	for _, line := range strings.Split(content, "\n") {
+		switch strings.TrimSpace(line) {
+		case begin:
+			inBlock = true
+			continue
+		case end:
+			inBlock = false
+			continue
+		}
+		if fields := strings.Fields(line); inBlock && len(fields) >= 2 {
+			hostnames = append(hostnames, fields[1:]...)
+		}
+	}
+	return hostnames
+}
+
+// Sync writes the project's hostnames to the hosts file at path and reports
+// whether it changed. The file is left alone when it is already up to date,
+// so only changes need elevated privileges.
+func Sync(path, projectName string, hostnames []string) (bool, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return false, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	content := Apply(string(data), projectName, hostnames)
+	if content == string(data) {
+		return false, nil
+	}
+
+	// Write in place rather than replacing the file, which keeps its owner
+	// and permissions and works where the hosts file is bind-mounted
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		return false, fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return true, nil
+}
@@ -0,0 +1,86 @@
+package hostnames
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHostname(t *testing.T) {
+	assert.Equal(t, "postgres.myproject.test", Hostname("postgres", "myproject", ""))
+	assert.Equal(t, "kafka-ui.my-app.dev.internal", Hostname("kafka-ui", "My_App!", ".dev.internal."))
+}
+
+func TestRewrite(t *testing.T) {
+	host := "postgres.shop.test"
+	tests := map[string]string{
+		"localhost":                          host,
+		"http://localhost:16686":             "http://" + host + ":16686",
+		"postgresql://u:p@localhost:5432/db": "postgresql://u:p@" + host + ":5432/db",
+		"localhost:9092,localhost:9093":      host + ":9092," + host + ":9093",
+		"http://jaeger.localhost":            "http://jaeger.localhost",
+		"localhost.localdomain":              "localhost.localdomain",
+		"${POSTGRES_PORT:-5432}":             "${POSTGRES_PORT:-5432}",
+		"redis://notlocalhost:6379":          "redis://notlocalhost:6379",
+	}
+	for value, want := range tests {
+		assert.Equal(t, want, Rewrite(value, host), value)
+	}
+}
+
+func TestApply(t *testing.T) {
+	original := "127.0.0.1\tlocalhost\n::1\tlocalhost\n"
+
+	content := Apply(original, "shop", []string{"postgres.shop.test", "redis.shop.test"})
+	assert.Equal(t, original+"\n# BEGIN dev-stack shop\n127.0.0.1\tpostgres.shop.test\n127.0.0.1\tredis.shop.test\n# END dev-stack shop\n", content)
+	assert.Equal(t, []string{"postgres.shop.test", "redis.shop.test"}, Registered(content, "shop"))
+	assert.Empty(t, Registered(content, "billing"))
+
+	// Other projects' blocks are kept, and applying twice changes nothing
+	content = Apply(content, "billing", []string{"mysql.billing.test"})
+	assert.Equal(t, content, Apply(content, "billing", []string{"mysql.billing.test"}))
+
+	content = Apply(content, "shop", []string{"postgres.shop.test"})
+	assert.Equal(t, []string{"postgres.shop.test"}, Registered(content, "shop"))
+	assert.Equal(t, []string{"mysql.billing.test"}, Registered(content, "billing"))
+
+	content = Apply(Apply(content, "shop", nil), "billing", nil)
+	assert.Equal(t, original, content)
+
+	// Without a block to add or remove, the file is left as it is
+	padded := original + "\n\n"
+	assert.Equal(t, padded, Apply(padded, "shop", nil))
+}
+
+func TestApply_CRLF(t *testing.T) {
+	// Windows hosts files end their lines with CRLF
+	original := "127.0.0.1\tlocalhost\r\n"
+
+	content := Apply(original, "shop", []string{"postgres.shop.test"})
+	assert.Equal(t, original+"\r\n# BEGIN dev-stack shop\r\n127.0.0.1\tpostgres.shop.test\r\n# END dev-stack shop\r\n", content)
+	assert.Equal(t, []string{"postgres.shop.test"}, Registered(content, "shop"))
+	assert.Equal(t, original, Apply(content, "shop", nil))
+}
+
+func TestSync(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "hosts")
+	require.NoError(t, os.WriteFile(path, []byte("127.0.0.1\tlocalhost\n"), 0644))
+
+	changed, err := Sync(path, "shop", []string{"postgres.shop.test"})
+	require.NoError(t, err)
+	assert.True(t, changed)
+
+	changed, err = Sync(path, "shop", []string{"postgres.shop.test"})
+	require.NoError(t, err)
+	assert.False(t, changed)
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"postgres.shop.test"}, Registered(string(data), "shop"))
+
+	_, err = Sync(filepath.Join(t.TempDir(), "missing"), "shop", nil)
+	assert.Error(t, err)
+}
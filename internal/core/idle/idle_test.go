@@ -0,0 +1,99 @@
+package idle
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"log/slog"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/isaacgarza/dev-stack/internal/pkg/types"
+)
+
+func TestTracker_Observe(t *testing.T) {
+	tracker := NewTracker(10 * time.Minute)
+	at := time.Date(2026, 10, 14, 12, 0, 0, 0, time.UTC)
+	sample := func(connections map[string]int, unknown ...string) []types.ServiceActivity {
+		var activity []types.ServiceActivity
+		for service, count := range connections {
+			activity = append(activity, types.ServiceActivity{Service: service, Connections: count})
+		}
+		for _, service := range unknown {
+			activity = append(activity, types.ServiceActivity{Service: service, Unknown: true})
+		}
+		return activity
+	}
+
+	assert.Empty(t, tracker.Observe(at, sample(map[string]int{"postgres": 0, "redis": 0, "kafka": 0})))
+	assert.Empty(t, tracker.Observe(at.Add(5*time.Minute), sample(map[string]int{"postgres": 2, "redis": 0}, "kafka")))
+	assert.Equal(t, 5*time.Minute, tracker.IdleFor("redis", at.Add(5*time.Minute)))
+
+	idle := tracker.Observe(at.Add(10*time.Minute), sample(map[string]int{"postgres": 0, "redis": 0}, "kafka"))
+	assert.Equal(t, []string{"redis"}, idle, "connections and unknown samples count as activity")
+
+	// redis stopped, so it starts over once it runs again
+	assert.Empty(t, tracker.Observe(at.Add(11*time.Minute), sample(map[string]int{"postgres": 0})))
+	assert.Empty(t, tracker.Observe(at.Add(30*time.Minute), sample(map[string]int{"redis": 0})))
+	assert.Equal(t, time.Duration(0), tracker.IdleFor("postgres", at.Add(30*time.Minute)))
+}
+
+func TestWaker(t *testing.T) {
+	probe, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	address := probe.Addr().String()
+	require.NoError(t, probe.Close())
+
+	// Waking starts an echo server on the port the waker held
+	woken := make(chan string, 1)
+	wake := func(ctx context.Context, service string) error {
+		listener, err := net.Listen("tcp", address)
+		if err != nil {
+			return err
+		}
+		go func() {
+			conn, err := listener.Accept()
+			_ = listener.Close()
+			if err != nil {
+				return
+			}
+			defer func() { _ = conn.Close() }()
+			_, _ = io.Copy(conn, conn)
+		}()
+		woken <- service
+		return nil
+	}
+
+	waker := NewWaker(context.Background(), wake, slog.New(slog.DiscardHandler))
+	require.NoError(t, waker.Hold("redis", []string{address}))
+	assert.True(t, waker.Held("redis"))
+
+	conn, err := net.Dial("tcp", address)
+	require.NoError(t, err)
+	_, err = conn.Write([]byte("PING\n"))
+	require.NoError(t, err)
+	line, err := bufio.NewReader(conn).ReadString('\n')
+	require.NoError(t, err)
+	assert.Equal(t, "PING\n", line, "the first connection is passed on once the service listens")
+	assert.Equal(t, "redis", <-woken)
+	assert.False(t, waker.Held("redis"))
+	require.NoError(t, conn.Close())
+	waker.Close()
+}
+
+func TestWaker_Release(t *testing.T) {
+	waker := NewWaker(context.Background(), func(context.Context, string) error { return nil }, slog.New(slog.DiscardHandler))
+	require.NoError(t, waker.Hold("postgres", []string{"127.0.0.1:0"}))
+	assert.True(t, waker.Release("postgres"))
+	assert.False(t, waker.Release("postgres"))
+
+	taken, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer func() { _ = taken.Close() }()
+	assert.Error(t, waker.Hold("api", []string{taken.Addr().String()}), "ports in use can't be held")
+	waker.Close()
+}
@@ -0,0 +1,59 @@
+// Package idle puts the services nothing connects to to sleep: it tracks
+// when each service last had a connection, and holds the ports of stopped
+// services to start them again on the next connection.
+package idle
+
+import (
+	"slices"
+	"time"
+
+	"github.com/isaacgarza/dev-stack/internal/pkg/types"
+)
+
+// Tracker records when each running service last had a connection to its
+// published ports
+type Tracker struct {
+	timeout time.Duration
+	active  map[string]time.Time
+}
+
+// NewTracker creates a tracker reporting services idle for timeout
+func NewTracker(timeout time.Duration) *Tracker {
+	return &Tracker{timeout: timeout, active: make(map[string]time.Time)}
+}
+
+// Observe records a sample taken at now and returns the services without
+// connections for the timeout, sorted. A service first sampled, or whose
+// connections couldn't be read, counts as active. Services missing from
+// the sample aren't running and are forgotten, so they get a full timeout
+// once they run again.
+func (t *Tracker) Observe(now time.Time, activity []types.ServiceActivity) []string {
+	sampled := make(map[string]bool, len(activity))
+	var idle []string
+	for _, service := range activity {
+		sampled[service.Service] = true
+		last, seen := t.active[service.Service]
+		if !seen || service.Connections > 0 || service.Unknown {
+			t.active[service.Service] = now
+			continue
+		}
+		if now.Sub(last) >= t.timeout {
+			idle = append(idle, service.Service)
+		}
+	}
+	for service := range t.active {
+		if !sampled[service] {
+			delete(t.active, service)
+		}
+	}
+	slices.Sort(idle)
+	return idle
+}
+
+// IdleFor returns how long a service has had no connections as of now
+func (t *Tracker) IdleFor(service string, now time.Time) time.Duration {
+	if last, ok := t.active[service]; ok {
+		return now.Sub(last)
+	}
+	return 0
+}
@@ -0,0 +1,218 @@
+package idle
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net"
+	"sync"
+	"time"
+)
+
+// Timing of passing on the connection that woke a service
+const (
+	// WakeTimeout bounds how long a service has to listen once started
+	WakeTimeout = 2 * time.Minute
+	dialRetry   = 250 * time.Millisecond
+)
+
+// WakeFunc starts a service the reaper stopped
+type WakeFunc func(ctx context.Context, service string) error
+
+// Waker listens on the host ports of stopped services. The first
+// connection to one of them releases the ports of its service and starts
+// it; the connection is then passed on to the service once it listens.
+type Waker struct {
+	ctx    context.Context
+	wake   WakeFunc
+	logger *slog.Logger
+
+	mu   sync.Mutex
+	held map[string][]net.Listener
+	// waking are the services being started by a connection
+	waking map[string]bool
+	wg     sync.WaitGroup
+}
+
+// NewWaker creates a waker starting services with wake until ctx is done
+func NewWaker(ctx context.Context, wake WakeFunc, logger *slog.Logger) *Waker {
+	return &Waker{
+		ctx:    ctx,
+		wake:   wake,
+		logger: logger,
+		held:   make(map[string][]net.Listener),
+		waking: make(map[string]bool),
+	}
+}
+
+// Hold listens on the host addresses of a stopped service, unless they are
+// held already or a connection is starting it. An address another process
+// took in the meantime is skipped; the error reports that none could be
+// listened on.
+func (w *Waker) Hold(service string, addresses []string) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if _, ok := w.held[service]; ok || w.waking[service] {
+		return nil
+	}
+
+	var listeners []net.Listener
+	var errs []error
+	for _, address := range addresses {
+		listener, err := net.Listen("tcp", address)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		listeners = append(listeners, listener)
+	}
+	if len(listeners) == 0 {
+		return errors.Join(errs...)
+	}
+
+	w.held[service] = listeners
+	for _, listener := range listeners {
+		w.wg.Add(1)
+		go w.accept(service, listener)
+	}
+	return nil
+}
+
+// Release stops listening on the ports of a service, reporting whether
+// they were held
+func (w *Waker) Release(service string) bool {
+	return w.release(service, false)
+}
+
+// release stops listening on the ports of a service, marking it waking
+// when they were held and waking is set
+func (w *Waker) release(service string, waking bool) bool {
+	w.mu.Lock()
+	listeners, ok := w.held[service]
+	delete(w.held, service)
+	if ok && waking {
+		w.waking[service] = true
+	}
+	w.mu.Unlock()
+
+	for _, listener := range listeners {
+		_ = listener.Close()
+	}
+	return ok
+}
+
+// Held reports whether the ports of a service are held
+func (w *Waker) Held(service string) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	_, ok := w.held[service]
+	return ok
+}
+
+// Services returns the services whose ports are held
+func (w *Waker) Services() []string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	services := make([]string, 0, len(w.held))
+	for service := range w.held {
+		services = append(services, service)
+	}
+	return services
+}
+
+// Close releases every service and waits for the connections being passed
+// on to finish
+func (w *Waker) Close() {
+	for _, service := range w.Services() {
+		w.Release(service)
+	}
+	w.wg.Wait()
+}
+
+// accept waits for the first connection to a held port
+func (w *Waker) accept(service string, listener net.Listener) {
+	defer w.wg.Done()
+	conn, err := listener.Accept()
+	if err != nil {
+		// Released
+		return
+	}
+
+	// The service binds the ports itself once started, so they are
+	// released first. Only the connection releasing them starts it.
+	woke := w.release(service, true)
+	w.wg.Add(1)
+	go func() {
+		defer w.wg.Done()
+		w.pass(service, listener.Addr().String(), conn, woke)
+	}()
+}
+
+// pass starts service when wake is set and passes conn on to address once
+// the service listens there
+func (w *Waker) pass(service, address string, conn net.Conn, wake bool) {
+	defer func() { _ = conn.Close() }()
+	if wake {
+		w.logger.Info("Waking idle service", "service", service, "address", address)
+		err := w.wake(w.ctx, service)
+		w.mu.Lock()
+		delete(w.waking, service)
+		w.mu.Unlock()
+		if err != nil {
+			w.logger.Error("Failed to wake idle service", "service", service, "error", err)
+			return
+		}
+	}
+
+	upstream, err := dialService(w.ctx, address)
+	if err != nil {
+		w.logger.Error("Woken service isn't listening", "service", service, "address", address, "error", err)
+		return
+	}
+	defer func() { _ = upstream.Close() }()
+
+	done := make(chan struct{}, 2)
+	go proxy(upstream, conn, done)
+	go proxy(conn, upstream, done)
+	<-done
+	<-done
+}
+
+// dialService connects to a service starting to listen on address
+func dialService(ctx context.Context, address string) (net.Conn, error) {
+	host, port, err := net.SplitHostPort(address)
+	if err != nil {
+		return nil, err
+	}
+	if ip := net.ParseIP(host); ip == nil || ip.IsUnspecified() {
+		host = "127.0.0.1"
+	}
+	address = net.JoinHostPort(host, port)
+
+	ctx, cancel := context.WithTimeout(ctx, WakeTimeout)
+	defer cancel()
+	var dialer net.Dialer
+	for {
+		conn, err := dialer.DialContext(ctx, "tcp", address)
+		if err == nil {
+			return conn, nil
+		}
+		select {
+		case <-ctx.Done():
+			return nil, err
+		case <-time.After(dialRetry):
+		}
+	}
+}
+
+// proxy copies from src to dst, then closes the writing side of dst
+func proxy(dst, src net.Conn, done chan<- struct{}) {
+	_, _ = io.Copy(dst, src)
+	if tcp, ok := dst.(*net.TCPConn); ok {
+		_ = tcp.CloseWrite()
+	} else {
+		_ = dst.Close()
+	}
+	done <- struct{}{}
+}
@@ -0,0 +1,355 @@
+// Package kafka manages the topics of a project's Kafka broker with the
+// Kafka CLI tools shipped in the broker's container
+package kafka
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/isaacgarza/dev-stack/internal/pkg/types"
+)
+
+// ServiceName is the service running the Kafka broker
+const ServiceName = "kafka-broker"
+
+// DefaultBootstrapServer is the listener the tools reach the broker on from
+// inside its container
+const DefaultBootstrapServer = "localhost:9092"
+
+// ContainerRunner runs commands in the broker's container
+type ContainerRunner interface {
+	ExecCapture(ctx context.Context, projectName, serviceName string, cmd []string) (*types.ExecResult, error)
+}
+
+// Admin creates, describes and deletes the topics of a broker
+type Admin struct {
+	runner          ContainerRunner
+	projectName     string
+	bootstrapServer string
+}
+
+// NewAdmin creates an admin for the broker of a compose project, reaching
+// it on bootstrapServer or DefaultBootstrapServer when it is empty
+func NewAdmin(runner ContainerRunner, projectName, bootstrapServer string) *Admin {
+	if bootstrapServer == "" {
+		bootstrapServer = DefaultBootstrapServer
+	}
+	return &Admin{runner: runner, projectName: projectName, bootstrapServer: bootstrapServer}
+}
+
+// BootstrapServer returns the listener the admin reaches the broker on
+func (a *Admin) BootstrapServer() string {
+	return a.bootstrapServer
+}
+
+// Topic is a topic as the broker describes it
+type Topic struct {
+	Name              string
+	Partitions        int
+	ReplicationFactor int
+	// Configs holds the configs set on the topic, not the broker defaults
+	Configs    map[string]string
+	Assignment []Partition
+}
+
+// Partition is where a partition of a topic lives
+type Partition struct {
+	ID       int
+	Leader   string
+	Replicas string
+	ISR      string
+}
+
+// Topics lists the topics of the broker, leaving out internal ones such as
+// __consumer_offsets
+func (a *Admin) Topics(ctx context.Context) ([]string, error) {
+	output, err := a.run(ctx, "kafka-topics", "--list", "--exclude-internal")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list topics: %w", err)
+	}
+	var topics []string
+	for _, line := range strings.Split(output, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			topics = append(topics, line)
+		}
+	}
+	slices.Sort(topics)
+	return topics, nil
+}
+
+// Describe returns the partitions and configs of a topic
+func (a *Admin) Describe(ctx context.Context, name string) (*Topic, error) {
+	output, err := a.run(ctx, "kafka-topics", "--describe", "--topic", name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to describe topic %s: %w", name, err)
+	}
+	topic, err := ParseDescription(output)
+	if err != nil {
+		return nil, fmt.Errorf("failed to describe topic %s: %w", name, err)
+	}
+	return topic, nil
+}
+
+// Create creates a topic unless it exists
+func (a *Admin) Create(ctx context.Context, topic types.KafkaTopicConfig) error {
+	configs, err := topic.Configs()
+	if err != nil {
+		return err
+	}
+	args := []string{"--create", "--if-not-exists", "--topic", topic.Name}
+	if topic.Partitions > 0 {
+		args = append(args, "--partitions", strconv.Itoa(topic.Partitions))
+	}
+	if topic.ReplicationFactor > 0 {
+		args = append(args, "--replication-factor", strconv.Itoa(topic.ReplicationFactor))
+	}
+	for _, key := range slices.Sorted(maps.Keys(configs)) {
+		args = append(args, "--config", key+"="+configs[key])
+	}
+	if _, err := a.run(ctx, "kafka-topics", args...); err != nil {
+		return fmt.Errorf("failed to create topic %s: %w", topic.Name, err)
+	}
+	return nil
+}
+
+// Delete deletes a topic and its messages
+func (a *Admin) Delete(ctx context.Context, name string) error {
+	if _, err := a.run(ctx, "kafka-topics", "--delete", "--topic", name); err != nil {
+		return fmt.Errorf("failed to delete topic %s: %w", name, err)
+	}
+	return nil
+}
+
+// Reconcile brings the broker in line with the declared topics, reporting
+// each on progress with what changed: missing topics are created,
+// partitions are added where fewer exist than declared and differing
+// configs are set. It stops at the first topic that can't be reconciled.
+func (a *Admin) Reconcile(ctx context.Context, topics []types.KafkaTopicConfig, progress types.ProgressReporter) error {
+	existing, err := a.Topics(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, topic := range topics {
+		progress.Update(topic.Name, "reconciling", 0, 0)
+		change, err := a.reconcile(ctx, topic, slices.Contains(existing, topic.Name))
+		if err != nil {
+			progress.Fail(topic.Name, err.Error())
+			return err
+		}
+		progress.Done(topic.Name, change)
+	}
+	return nil
+}
+
+func (a *Admin) reconcile(ctx context.Context, topic types.KafkaTopicConfig, exists bool) (string, error) {
+	if !exists {
+		if err := a.Create(ctx, topic); err != nil {
+			return "", err
+		}
+		return "created", nil
+	}
+
+	current, err := a.Describe(ctx, topic.Name)
+	if err != nil {
+		return "", err
+	}
+	plan, err := Plan(topic, current)
+	if err != nil {
+		return "", err
+	}
+
+	if plan.Partitions > 0 {
+		if _, err := a.run(ctx, "kafka-topics", "--alter", "--topic", topic.Name, "--partitions", strconv.Itoa(plan.Partitions)); err != nil {
+			return "", fmt.Errorf("failed to add partitions to topic %s: %w", topic.Name, err)
+		}
+	}
+	if len(plan.Configs) > 0 {
+		var configs []string
+		for _, key := range slices.Sorted(maps.Keys(plan.Configs)) {
+			configs = append(configs, key+"="+plan.Configs[key])
+		}
+		if _, err := a.run(ctx, "kafka-configs", "--alter", "--entity-type", "topics", "--entity-name", topic.Name, "--add-config", strings.Join(configs, ",")); err != nil {
+			return "", fmt.Errorf("failed to update the configs of topic %s: %w", topic.Name, err)
+		}
+	}
+	return plan.String(), nil
+}
+
+// Change is what reconciling a declared topic changes on an existing one
+type Change struct {
+	// Partitions is the partition count to grow to, 0 to keep it
+	Partitions int
+	// Configs are the configs to set
+	Configs map[string]string
+	// Notes explain declared settings that can't be applied
+	Notes []string
+}
+
+// Plan compares a declared topic with the topic on the broker
+func Plan(topic types.KafkaTopicConfig, current *Topic) (Change, error) {
+	var change Change
+	switch {
+	case topic.Partitions > current.Partitions:
+		change.Partitions = topic.Partitions
+	case topic.Partitions > 0 && topic.Partitions < current.Partitions:
+		change.Notes = append(change.Notes, fmt.Sprintf("keeps its %d partitions, Kafka can't remove partitions", current.Partitions))
+	}
+	if topic.ReplicationFactor > 0 && topic.ReplicationFactor != current.ReplicationFactor {
+		change.Notes = append(change.Notes, fmt.Sprintf("keeps replication factor %d, recreate the topic to change it", current.ReplicationFactor))
+	}
+
+	configs, err := topic.Configs()
+	if err != nil {
+		return change, err
+	}
+	for key, value := range configs {
+		if current.Configs[key] != value {
+			if change.Configs == nil {
+				change.Configs = make(map[string]string)
+			}
+			change.Configs[key] = value
+		}
+	}
+	return change, nil
+}
+
+// String summarizes the change for a progress report
+func (c Change) String() string {
+	var parts []string
+	if c.Partitions > 0 {
+		parts = append(parts, fmt.Sprintf("grown to %d partitions", c.Partitions))
+	}
+	if len(c.Configs) > 0 {
+		parts = append(parts, "set "+strings.Join(slices.Sorted(maps.Keys(c.Configs)), ", "))
+	}
+	if len(parts) == 0 {
+		parts = append(parts, "up to date")
+	}
+	return strings.Join(append(parts, c.Notes...), "; ")
+}
+
+// ProduceCommand returns the console producer command writing the lines
+// of its stdin to a topic. Keyed lines hold the key and the message
+// separated by a tab.
+func (a *Admin) ProduceCommand(topic string, keyed bool) []string {
+	command := []string{"kafka-console-producer", "--bootstrap-server", a.bootstrapServer, "--topic", topic}
+	if keyed {
+		command = append(command, "--property", "parse.key=true", "--property", "key.separator=\t")
+	}
+	return command
+}
+
+// ConsumeOptions selects the messages a consumer prints
+type ConsumeOptions struct {
+	// FromBeginning reads the topic from its first message instead of
+	// only new ones
+	FromBeginning bool
+	// MaxMessages stops the consumer after that many messages; 0 runs it
+	// until interrupted
+	MaxMessages int
+	// Group is the consumer group to consume as, committing its offsets
+	Group string
+}
+
+// ConsumeCommand returns the console consumer command printing the
+// messages of a topic with their keys
+func (a *Admin) ConsumeCommand(topic string, options ConsumeOptions) []string {
+	command := []string{"kafka-console-consumer", "--bootstrap-server", a.bootstrapServer, "--topic", topic, "--property", "print.key=true"}
+	if options.FromBeginning {
+		command = append(command, "--from-beginning")
+	}
+	if options.MaxMessages > 0 {
+		command = append(command, "--max-messages", strconv.Itoa(options.MaxMessages))
+	}
+	if options.Group != "" {
+		command = append(command, "--group", options.Group)
+	}
+	return command
+}
+
+// ParseDescription parses the output of kafka-topics --describe for a
+// single topic: a summary line followed by a line per partition, each made
+// of tab separated "Key: value" fields
+func ParseDescription(output string) (*Topic, error) {
+	var topic *Topic
+	for _, line := range strings.Split(output, "\n") {
+		fields := describeFields(line)
+		if _, ok := fields["PartitionCount"]; ok {
+			partitions, _ := strconv.Atoi(fields["PartitionCount"])
+			replicationFactor, _ := strconv.Atoi(fields["ReplicationFactor"])
+			topic = &Topic{
+				Name:              fields["Topic"],
+				Partitions:        partitions,
+				ReplicationFactor: replicationFactor,
+				Configs:           make(map[string]string),
+			}
+			for _, config := range strings.Split(fields["Configs"], ",") {
+				if key, value, ok := strings.Cut(config, "="); ok {
+					topic.Configs[key] = value
+				}
+			}
+			continue
+		}
+		if id, ok := fields["Partition"]; ok && topic != nil {
+			partition, _ := strconv.Atoi(id)
+			topic.Assignment = append(topic.Assignment, Partition{
+				ID:       partition,
+				Leader:   fields["Leader"],
+				Replicas: fields["Replicas"],
+				ISR:      fields["Isr"],
+			})
+		}
+	}
+	if topic == nil {
+		return nil, fmt.Errorf("unexpected kafka-topics output: %s", strings.TrimSpace(output))
+	}
+	return topic, nil
+}
+
+// describeFields splits a line of kafka-topics --describe into its fields
+func describeFields(line string) map[string]string {
+	fields := make(map[string]string)
+	for _, field := range strings.Split(line, "\t") {
+		if key, value, ok := strings.Cut(field, ":"); ok {
+			fields[strings.TrimSpace(key)] = strings.TrimSpace(value)
+		}
+	}
+	return fields
+}
+
+// run runs a Kafka tool against the broker and returns its output, failing
+// with its stderr when it exits non-zero
+func (a *Admin) run(ctx context.Context, tool string, args ...string) (string, error) {
+	command := append([]string{tool, "--bootstrap-server", a.bootstrapServer}, args...)
+	result, err := a.runner.ExecCapture(ctx, a.projectName, ServiceName, command)
+	if err != nil {
+		return "", err
+	}
+	if result.ExitCode != 0 {
+		return "", fmt.Errorf("%s exited with code %d: %s", tool, result.ExitCode, errorLine(result.Stderr, result.Stdout))
+	}
+	return result.Stdout, nil
+}
+
+// errorLine picks the reason out of the output of a failed Kafka tool: the
+// line reporting the error, which a stack trace may follow, or else the
+// last line of the first output that has one
+func errorLine(outputs ...string) string {
+	for _, output := range outputs {
+		lines := strings.Split(strings.TrimSpace(output), "\n")
+		for _, line := range lines {
+			if strings.Contains(line, "Error") {
+				return strings.TrimSpace(line)
+			}
+		}
+		if last := strings.TrimSpace(lines[len(lines)-1]); last != "" {
+			return last
+		}
+	}
+	return ""
+}
@@ -0,0 +1,127 @@
+package kafka
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/isaacgarza/dev-stack/internal/pkg/types"
+)
+
+const ordersDescription = "Topic: orders\tTopicId: 5x2Lr0lTQ4uV3r6yGf1xMw\tPartitionCount: 2\tReplicationFactor: 1\tConfigs: retention.ms=86400000,cleanup.policy=delete\n" +
+	"\tTopic: orders\tPartition: 0\tLeader: 1\tReplicas: 1\tIsr: 1\n" +
+	"\tTopic: orders\tPartition: 1\tLeader: 1\tReplicas: 1\tIsr: 1\n"
+
+// fakeRunner answers Kafka tool calls by their tool and first argument
+// after the bootstrap server, and records every call
+type fakeRunner struct {
+	outputs  map[string]*types.ExecResult
+	commands []string
+}
+
+func (f *fakeRunner) ExecCapture(ctx context.Context, projectName, serviceName string, cmd []string) (*types.ExecResult, error) {
+	f.commands = append(f.commands, strings.Join(cmd, " "))
+	if result, ok := f.outputs[cmd[0]+" "+cmd[3]]; ok {
+		return result, nil
+	}
+	return &types.ExecResult{}, nil
+}
+
+// recordedProgress keeps the last status reported for each task
+type recordedProgress map[string]string
+
+func (r recordedProgress) Update(task, status string, current, total int64) { r[task] = status }
+func (r recordedProgress) Done(task, status string)                         { r[task] = "done: " + status }
+func (r recordedProgress) Fail(task, status string)                         { r[task] = "failed: " + status }
+
+func TestParseDescription(t *testing.T) {
+	topic, err := ParseDescription(ordersDescription)
+	require.NoError(t, err)
+	assert.Equal(t, &Topic{
+		Name:              "orders",
+		Partitions:        2,
+		ReplicationFactor: 1,
+		Configs:           map[string]string{"retention.ms": "86400000", "cleanup.policy": "delete"},
+		Assignment: []Partition{
+			{ID: 0, Leader: "1", Replicas: "1", ISR: "1"},
+			{ID: 1, Leader: "1", Replicas: "1", ISR: "1"},
+		},
+	}, topic)
+
+	// Older brokers leave out the space after each colon and the topic ID
+	topic, err = ParseDescription("Topic:orders\tPartitionCount:3\tReplicationFactor:1\tConfigs:\n")
+	require.NoError(t, err)
+	assert.Equal(t, 3, topic.Partitions)
+	assert.Empty(t, topic.Configs)
+
+	_, err = ParseDescription("")
+	assert.Error(t, err)
+}
+
+func TestPlan(t *testing.T) {
+	current, err := ParseDescription(ordersDescription)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name  string
+		topic types.KafkaTopicConfig
+		want  string
+	}{
+		{"matching", types.KafkaTopicConfig{Name: "orders", Partitions: 2, Retention: "1d"}, "up to date"},
+		{"more partitions", types.KafkaTopicConfig{Name: "orders", Partitions: 6}, "grown to 6 partitions"},
+		{"fewer partitions", types.KafkaTopicConfig{Name: "orders", Partitions: 1}, "up to date; keeps its 2 partitions, Kafka can't remove partitions"},
+		{"configs", types.KafkaTopicConfig{Name: "orders", Retention: "7d", Config: map[string]string{"cleanup.policy": "compact"}}, "set cleanup.policy, retention.ms"},
+		{"replication factor", types.KafkaTopicConfig{Name: "orders", ReplicationFactor: 3}, "up to date; keeps replication factor 1, recreate the topic to change it"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			change, err := Plan(tt.topic, current)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, change.String())
+		})
+	}
+}
+
+func TestReconcile(t *testing.T) {
+	runner := &fakeRunner{outputs: map[string]*types.ExecResult{
+		"kafka-topics --list":     {Stdout: "orders\n"},
+		"kafka-topics --describe": {Stdout: ordersDescription},
+	}}
+	topics := []types.KafkaTopicConfig{
+		{Name: "orders", Partitions: 4, Retention: "1d"},
+		{Name: "payments", Partitions: 3, Retention: "forever"},
+	}
+
+	progress := recordedProgress{}
+	require.NoError(t, NewAdmin(runner, "shop", "").Reconcile(context.Background(), topics, progress))
+	assert.Equal(t, recordedProgress{"orders": "done: grown to 4 partitions", "payments": "done: created"}, progress)
+	assert.Equal(t, []string{
+		"kafka-topics --bootstrap-server localhost:9092 --list --exclude-internal",
+		"kafka-topics --bootstrap-server localhost:9092 --describe --topic orders",
+		"kafka-topics --bootstrap-server localhost:9092 --alter --topic orders --partitions 4",
+		"kafka-topics --bootstrap-server localhost:9092 --create --if-not-exists --topic payments --partitions 3 --config retention.ms=-1",
+	}, runner.commands)
+}
+
+func TestReconcile_Failure(t *testing.T) {
+	runner := &fakeRunner{outputs: map[string]*types.ExecResult{
+		"kafka-topics --create": {ExitCode: 1, Stderr: "Error while executing topic command : Replication factor: 3 larger than available brokers: 1.\n\tat kafka.admin.TopicCommand$.main(TopicCommand.scala)\n"},
+	}}
+	topics := []types.KafkaTopicConfig{{Name: "payments", ReplicationFactor: 3}}
+
+	progress := recordedProgress{}
+	err := NewAdmin(runner, "shop", "").Reconcile(context.Background(), topics, progress)
+	assert.EqualError(t, err, "failed to create topic payments: kafka-topics exited with code 1: Error while executing topic command : Replication factor: 3 larger than available brokers: 1.")
+	assert.Contains(t, progress["payments"], "failed: ")
+}
+
+func TestConsumeCommand(t *testing.T) {
+	admin := NewAdmin(&fakeRunner{}, "shop", "")
+	assert.Equal(t, "kafka-console-consumer --bootstrap-server localhost:9092 --topic orders --property print.key=true --from-beginning --max-messages 5 --group debug",
+		strings.Join(admin.ConsumeCommand("orders", ConsumeOptions{FromBeginning: true, MaxMessages: 5, Group: "debug"}), " "))
+	assert.Equal(t, []string{"kafka-console-producer", "--bootstrap-server", "localhost:9092", "--topic", "orders", "--property", "parse.key=true", "--property", "key.separator=\t"},
+		admin.ProduceCommand("orders", true))
+}
@@ -0,0 +1,304 @@
+// Package localstack provisions the AWS resources a project declares in
+// LocalStack and lists the resources it holds. Everything runs through
+// awslocal inside the LocalStack container, so no AWS CLI is needed on the
+// host.
+package localstack
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/isaacgarza/dev-stack/internal/pkg/types"
+)
+
+// ServiceName is the service running LocalStack
+const ServiceName = "localstack-core"
+
+// DefaultRegion is the region resources are created in unless the project
+// names one
+const DefaultRegion = "us-east-1"
+
+// accountID is the account LocalStack owns every resource with
+const accountID = "000000000000"
+
+// Resource kinds, as listed by aws ls
+const (
+	KindBucket       = "bucket"
+	KindQueue        = "queue"
+	KindTable        = "table"
+	KindTopic        = "topic"
+	KindSubscription = "subscription"
+)
+
+// ContainerRunner runs commands in the LocalStack container
+type ContainerRunner interface {
+	ExecCapture(ctx context.Context, projectName, serviceName string, cmd []string) (*types.ExecResult, error)
+}
+
+// Provisioner creates and lists the resources of a project's LocalStack
+type Provisioner struct {
+	runner      ContainerRunner
+	projectName string
+	region      string
+}
+
+// NewProvisioner creates a provisioner for the LocalStack container of a
+// compose project, working in region or DefaultRegion when it is empty
+func NewProvisioner(runner ContainerRunner, projectName, region string) *Provisioner {
+	if region == "" {
+		region = DefaultRegion
+	}
+	return &Provisioner{runner: runner, projectName: projectName, region: region}
+}
+
+// Subscription delivers the messages of a topic to a queue
+type Subscription struct {
+	Topic string
+	Queue string
+}
+
+// Name identifies the subscription in listings
+func (s Subscription) Name() string {
+	return s.Topic + " -> " + s.Queue
+}
+
+// Resources are the resources LocalStack holds, by name
+type Resources struct {
+	Buckets       []string
+	Queues        []string
+	Tables        []string
+	Topics        []string
+	Subscriptions []Subscription
+}
+
+// Step creates one resource. Each command is the arguments of an awslocal
+// call.
+type Step struct {
+	Kind     string
+	Name     string
+	Commands [][]string
+}
+
+// Task names the step on a progress board
+func (s Step) Task() string {
+	return s.Kind + " " + s.Name
+}
+
+// Resources lists the buckets, queues, tables, topics and queue
+// subscriptions held by LocalStack
+func (p *Provisioner) Resources(ctx context.Context) (*Resources, error) {
+	var resources Resources
+
+	var buckets struct {
+		Buckets []struct {
+			Name string `json:"Name"`
+		} `json:"Buckets"`
+	}
+	if err := p.list(ctx, &buckets, "s3api", "list-buckets"); err != nil {
+		return nil, err
+	}
+	for _, bucket := range buckets.Buckets {
+		resources.Buckets = append(resources.Buckets, bucket.Name)
+	}
+
+	var queues struct {
+		QueueURLs []string `json:"QueueUrls"`
+	}
+	if err := p.list(ctx, &queues, "sqs", "list-queues"); err != nil {
+		return nil, err
+	}
+	for _, url := range queues.QueueURLs {
+		resources.Queues = append(resources.Queues, lastSegment(url, "/"))
+	}
+
+	var tables struct {
+		TableNames []string `json:"TableNames"`
+	}
+	if err := p.list(ctx, &tables, "dynamodb", "list-tables"); err != nil {
+		return nil, err
+	}
+	resources.Tables = tables.TableNames
+
+	var topics struct {
+		Topics []struct {
+			TopicArn string `json:"TopicArn"`
+		} `json:"Topics"`
+	}
+	if err := p.list(ctx, &topics, "sns", "list-topics"); err != nil {
+		return nil, err
+	}
+	for _, topic := range topics.Topics {
+		resources.Topics = append(resources.Topics, lastSegment(topic.TopicArn, ":"))
+	}
+
+	var subscriptions struct {
+		Subscriptions []struct {
+			TopicArn string `json:"TopicArn"`
+			Protocol string `json:"Protocol"`
+			Endpoint string `json:"Endpoint"`
+		} `json:"Subscriptions"`
+	}
+	if err := p.list(ctx, &subscriptions, "sns", "list-subscriptions"); err != nil {
+		return nil, err
+	}
+	for _, subscription := range subscriptions.Subscriptions {
+		if subscription.Protocol == "sqs" {
+			resources.Subscriptions = append(resources.Subscriptions, Subscription{
+				Topic: lastSegment(subscription.TopicArn, ":"),
+				Queue: lastSegment(subscription.Endpoint, ":"),
+			})
+		}
+	}
+
+	sort.Strings(resources.Buckets)
+	sort.Strings(resources.Queues)
+	sort.Strings(resources.Tables)
+	sort.Strings(resources.Topics)
+	return &resources, nil
+}
+
+// Provision creates the declared resources LocalStack doesn't hold yet,
+// reporting each on progress, and returns the steps it ran. It stops at
+// the first resource that can't be created.
+func (p *Provisioner) Provision(ctx context.Context, config types.AWSConfig, progress types.ProgressReporter) ([]Step, error) {
+	existing, err := p.Resources(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	steps := p.Plan(config, existing)
+	for i, step := range steps {
+		progress.Update(step.Task(), "creating", 0, 0)
+		for _, command := range step.Commands {
+			if _, err := p.run(ctx, command); err != nil {
+				progress.Fail(step.Task(), err.Error())
+				return steps[:i], fmt.Errorf("failed to create %s: %w", step.Task(), err)
+			}
+		}
+		progress.Done(step.Task(), "created")
+	}
+	return steps, nil
+}
+
+// Plan returns the steps creating the resources of config missing from
+// existing. Queues and topics come before the subscriptions between them.
+func (p *Provisioner) Plan(config types.AWSConfig, existing *Resources) []Step {
+	var steps []Step
+	for _, bucket := range config.Buckets {
+		if slices.Contains(existing.Buckets, bucket.Name) {
+			continue
+		}
+		create := []string{"s3api", "create-bucket", "--bucket", bucket.Name}
+		if p.region != DefaultRegion {
+			create = append(create, "--create-bucket-configuration", "LocationConstraint="+p.region)
+		}
+		commands := [][]string{create}
+		if bucket.Versioning {
+			commands = append(commands, []string{"s3api", "put-bucket-versioning", "--bucket", bucket.Name, "--versioning-configuration", "Status=Enabled"})
+		}
+		steps = append(steps, Step{Kind: KindBucket, Name: bucket.Name, Commands: commands})
+	}
+
+	for _, queue := range config.Queues {
+		if slices.Contains(existing.Queues, queue.Name) {
+			continue
+		}
+		create := []string{"sqs", "create-queue", "--queue-name", queue.Name}
+		var attributes []string
+		if queue.FIFO {
+			attributes = append(attributes, "FifoQueue=true")
+		}
+		if queue.VisibilityTimeout > 0 {
+			attributes = append(attributes, fmt.Sprintf("VisibilityTimeout=%d", queue.VisibilityTimeout))
+		}
+		if len(attributes) > 0 {
+			create = append(create, "--attributes", strings.Join(attributes, ","))
+		}
+		steps = append(steps, Step{Kind: KindQueue, Name: queue.Name, Commands: [][]string{create}})
+	}
+
+	for _, table := range config.Tables {
+		if slices.Contains(existing.Tables, table.Name) {
+			continue
+		}
+		create := []string{"dynamodb", "create-table", "--table-name", table.Name, "--billing-mode", "PAY_PER_REQUEST", "--attribute-definitions"}
+		keys := []types.AWSKey{table.PartitionKey}
+		if table.SortKey.Name != "" {
+			keys = append(keys, table.SortKey)
+		}
+		for _, key := range keys {
+			create = append(create, fmt.Sprintf("AttributeName=%s,AttributeType=%s", key.Name, key.KeyType()))
+		}
+		create = append(create, "--key-schema", fmt.Sprintf("AttributeName=%s,KeyType=HASH", table.PartitionKey.Name))
+		if table.SortKey.Name != "" {
+			create = append(create, fmt.Sprintf("AttributeName=%s,KeyType=RANGE", table.SortKey.Name))
+		}
+		steps = append(steps, Step{Kind: KindTable, Name: table.Name, Commands: [][]string{create}})
+	}
+
+	for _, topic := range config.Topics {
+		if slices.Contains(existing.Topics, topic.Name) {
+			continue
+		}
+		create := []string{"sns", "create-topic", "--name", topic.Name}
+		if strings.HasSuffix(topic.Name, ".fifo") {
+			create = append(create, "--attributes", "FifoTopic=true")
+		}
+		steps = append(steps, Step{Kind: KindTopic, Name: topic.Name, Commands: [][]string{create}})
+	}
+
+	for _, topic := range config.Topics {
+		for _, queue := range topic.Subscriptions {
+			subscription := Subscription{Topic: topic.Name, Queue: queue}
+			if slices.Contains(existing.Subscriptions, subscription) {
+				continue
+			}
+			subscribe := []string{"sns", "subscribe", "--topic-arn", p.arn("sns", topic.Name), "--protocol", "sqs", "--notification-endpoint", p.arn("sqs", queue)}
+			steps = append(steps, Step{Kind: KindSubscription, Name: subscription.Name(), Commands: [][]string{subscribe}})
+		}
+	}
+	return steps
+}
+
+// list runs an awslocal list command and decodes its JSON output into v.
+// Commands with nothing to list may print nothing at all.
+func (p *Provisioner) list(ctx context.Context, v any, args ...string) error {
+	stdout, err := p.run(ctx, args)
+	if err != nil {
+		return fmt.Errorf("failed to list %s resources: %w", args[0], err)
+	}
+	if strings.TrimSpace(stdout) == "" {
+		return nil
+	}
+	if err := json.Unmarshal([]byte(stdout), v); err != nil {
+		return fmt.Errorf("failed to parse %s %s output: %w", args[0], args[1], err)
+	}
+	return nil
+}
+
+// run runs awslocal with args in the LocalStack container and returns its
+// output, failing with its stderr when it exits non-zero
+func (p *Provisioner) run(ctx context.Context, args []string) (string, error) {
+	command := append([]string{"awslocal", "--region", p.region, "--output", "json"}, args...)
+	result, err := p.runner.ExecCapture(ctx, p.projectName, ServiceName, command)
+	if err != nil {
+		return "", err
+	}
+	if result.ExitCode != 0 {
+		return "", fmt.Errorf("awslocal %s %s exited with code %d: %s", args[0], args[1], result.ExitCode, strings.TrimSpace(result.Stderr))
+	}
+	return result.Stdout, nil
+}
+
+// arn returns the ARN of a queue or topic
+func (p *Provisioner) arn(service, name string) string {
+	return fmt.Sprintf("arn:aws:%s:%s:%s:%s", service, p.region, accountID, name)
+}
+
+func lastSegment(value, separator string) string {
+	return value[strings.LastIndex(value, separator)+1:]
+}
@@ -0,0 +1,131 @@
+package localstack
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/isaacgarza/dev-stack/internal/pkg/types"
+)
+
+// fakeRunner answers awslocal calls by their service and operation, and
+// records every call
+type fakeRunner struct {
+	outputs  map[string]string
+	failing  string
+	commands []string
+}
+
+func (f *fakeRunner) ExecCapture(ctx context.Context, projectName, serviceName string, cmd []string) (*types.ExecResult, error) {
+	f.commands = append(f.commands, strings.Join(cmd, " "))
+	operation := cmd[5] + " " + cmd[6]
+	if operation == f.failing {
+		return &types.ExecResult{ExitCode: 254, Stderr: "An error occurred (InvalidParameterValue)\n"}, nil
+	}
+	return &types.ExecResult{Stdout: f.outputs[operation]}, nil
+}
+
+// recordedProgress keeps the last status reported for each task
+type recordedProgress map[string]string
+
+func (r recordedProgress) Update(task, status string, current, total int64) { r[task] = status }
+func (r recordedProgress) Done(task, status string)                         { r[task] = "done: " + status }
+func (r recordedProgress) Fail(task, status string)                         { r[task] = "failed: " + status }
+
+func TestResources(t *testing.T) {
+	runner := &fakeRunner{outputs: map[string]string{
+		"s3api list-buckets":     `{"Buckets": [{"Name": "uploads"}, {"Name": "assets"}], "Owner": {}}`,
+		"sqs list-queues":        `{"QueueUrls": ["http://sqs.us-east-1.localhost.localstack.cloud:4566/000000000000/jobs"]}`,
+		"dynamodb list-tables":   `{"TableNames": ["users"]}`,
+		"sns list-topics":        `{"Topics": [{"TopicArn": "arn:aws:sns:us-east-1:000000000000:events"}]}`,
+		"sns list-subscriptions": `{"Subscriptions": [{"TopicArn": "arn:aws:sns:us-east-1:000000000000:events", "Protocol": "sqs", "Endpoint": "arn:aws:sqs:us-east-1:000000000000:jobs"}, {"TopicArn": "arn:aws:sns:us-east-1:000000000000:events", "Protocol": "email", "Endpoint": "me@example.com"}]}`,
+	}}
+
+	resources, err := NewProvisioner(runner, "shop", "").Resources(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, &Resources{
+		Buckets:       []string{"assets", "uploads"},
+		Queues:        []string{"jobs"},
+		Tables:        []string{"users"},
+		Topics:        []string{"events"},
+		Subscriptions: []Subscription{{Topic: "events", Queue: "jobs"}},
+	}, resources)
+	assert.Equal(t, "awslocal --region us-east-1 --output json s3api list-buckets", runner.commands[0])
+}
+
+func TestResources_Empty(t *testing.T) {
+	// list-queues prints nothing when there are no queues
+	resources, err := NewProvisioner(&fakeRunner{}, "shop", "").Resources(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, &Resources{}, resources)
+}
+
+func TestPlan(t *testing.T) {
+	config := types.AWSConfig{
+		Buckets: []types.AWSBucket{{Name: "uploads", Versioning: true}, {Name: "assets"}},
+		Queues:  []types.AWSQueue{{Name: "jobs", VisibilityTimeout: 30}, {Name: "orders.fifo", FIFO: true}},
+		Tables: []types.AWSTable{{
+			Name:         "users",
+			PartitionKey: types.AWSKey{Name: "id"},
+			SortKey:      types.AWSKey{Name: "created", Type: "N"},
+		}},
+		Topics: []types.AWSTopic{{Name: "events", Subscriptions: []string{"jobs", "audit"}}},
+	}
+	existing := &Resources{
+		Buckets:       []string{"assets"},
+		Subscriptions: []Subscription{{Topic: "events", Queue: "audit"}},
+	}
+
+	var got []string
+	for _, step := range NewProvisioner(&fakeRunner{}, "shop", "eu-west-1").Plan(config, existing) {
+		for _, command := range step.Commands {
+			got = append(got, step.Task()+": "+strings.Join(command, " "))
+		}
+	}
+	assert.Equal(t, []string{
+		"bucket uploads: s3api create-bucket --bucket uploads --create-bucket-configuration LocationConstraint=eu-west-1",
+		"bucket uploads: s3api put-bucket-versioning --bucket uploads --versioning-configuration Status=Enabled",
+		"queue jobs: sqs create-queue --queue-name jobs --attributes VisibilityTimeout=30",
+		"queue orders.fifo: sqs create-queue --queue-name orders.fifo --attributes FifoQueue=true",
+		"table users: dynamodb create-table --table-name users --billing-mode PAY_PER_REQUEST --attribute-definitions AttributeName=id,AttributeType=S AttributeName=created,AttributeType=N --key-schema AttributeName=id,KeyType=HASH AttributeName=created,KeyType=RANGE",
+		"topic events: sns create-topic --name events",
+		"subscription events -> jobs: sns subscribe --topic-arn arn:aws:sns:eu-west-1:000000000000:events --protocol sqs --notification-endpoint arn:aws:sqs:eu-west-1:000000000000:jobs",
+	}, got)
+}
+
+func TestProvision(t *testing.T) {
+	config := types.AWSConfig{
+		Buckets: []types.AWSBucket{{Name: "uploads"}},
+		Queues:  []types.AWSQueue{{Name: "jobs"}},
+		Topics:  []types.AWSTopic{{Name: "events"}},
+	}
+
+	t.Run("creates missing resources", func(t *testing.T) {
+		runner := &fakeRunner{outputs: map[string]string{
+			"s3api list-buckets": `{"Buckets": [{"Name": "uploads"}]}`,
+		}}
+		progress := recordedProgress{}
+		steps, err := NewProvisioner(runner, "shop", "").Provision(context.Background(), config, progress)
+		require.NoError(t, err)
+		require.Len(t, steps, 2)
+		assert.Equal(t, "queue jobs", steps[0].Task())
+		assert.Equal(t, "topic events", steps[1].Task())
+		assert.Equal(t, "awslocal --region us-east-1 --output json sns create-topic --name events", runner.commands[len(runner.commands)-1])
+		assert.Equal(t, recordedProgress{"queue jobs": "done: created", "topic events": "done: created"}, progress)
+	})
+
+	t.Run("stops at a failure", func(t *testing.T) {
+		runner := &fakeRunner{failing: "sqs create-queue"}
+		progress := recordedProgress{}
+		steps, err := NewProvisioner(runner, "shop", "").Provision(context.Background(), config, progress)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to create queue jobs: awslocal sqs create-queue exited with code 254: An error occurred (InvalidParameterValue)")
+		require.Len(t, steps, 1)
+		assert.Equal(t, "bucket uploads", steps[0].Task())
+		assert.Contains(t, progress["queue jobs"], "failed: ")
+		assert.NotContains(t, progress, "topic events")
+	})
+}
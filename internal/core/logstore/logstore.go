@@ -0,0 +1,183 @@
+// Package logstore reads the logs the log-collector service persists under
+// dev-stack/logs: a directory per service holding a file of JSON lines per
+// day
+package logstore
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ServiceName is the service persisting the logs of the stack
+const ServiceName = "log-collector"
+
+// dayLayout is the date in the names of the log files
+const dayLayout = "2006-01-02"
+
+// maxLineSize bounds the length of a log line, in bytes
+const maxLineSize = 1024 * 1024
+
+// Entry is a line a container wrote
+type Entry struct {
+	Timestamp time.Time `json:"timestamp"`
+	Service   string    `json:"service"`
+	Container string    `json:"container"`
+	Stream    string    `json:"stream"`
+	Message   string    `json:"message"`
+}
+
+// Store reads the logs persisted under a directory
+type Store struct {
+	dir string
+}
+
+// NewStore creates a store over the logs under dir
+func NewStore(dir string) *Store {
+	return &Store{dir: dir}
+}
+
+// Services lists the services the store holds logs of
+func (s *Store) Services() ([]string, error) {
+	entries, err := os.ReadDir(s.dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", s.dir, err)
+	}
+	var services []string
+	for _, entry := range entries {
+		if entry.IsDir() {
+			services = append(services, entry.Name())
+		}
+	}
+	return services, nil
+}
+
+// Read returns the entries of serviceNames, or of every service when none
+// are given, written at or after since, oldest first. A positive tail
+// keeps the last tail entries of each service.
+func (s *Store) Read(serviceNames []string, since time.Time, tail int) ([]Entry, error) {
+	if len(serviceNames) == 0 {
+		all, err := s.Services()
+		if err != nil {
+			return nil, err
+		}
+		serviceNames = all
+	}
+
+	var entries []Entry
+	for _, serviceName := range serviceNames {
+		serviceEntries, err := s.readService(serviceName, since)
+		if err != nil {
+			return nil, err
+		}
+		if tail > 0 && len(serviceEntries) > tail {
+			serviceEntries = serviceEntries[len(serviceEntries)-tail:]
+		}
+		entries = append(entries, serviceEntries...)
+	}
+	slices.SortStableFunc(entries, func(a, b Entry) int {
+		return a.Timestamp.Compare(b.Timestamp)
+	})
+	return entries, nil
+}
+
+// readService reads the entries of a service from the files of the days
+// since covers
+func (s *Store) readService(serviceName string, since time.Time) ([]Entry, error) {
+	files, err := filepath.Glob(filepath.Join(s.dir, serviceName, "*.log"))
+	if err != nil {
+		return nil, err
+	}
+	slices.Sort(files)
+
+	var entries []Entry
+	for _, file := range files {
+		day, err := time.Parse(dayLayout, strings.TrimSuffix(filepath.Base(file), ".log"))
+		if err != nil {
+			continue
+		}
+		// Files are named after the UTC day of their entries
+		if !since.IsZero() && day.Add(24*time.Hour).Before(since) {
+			continue
+		}
+		fileEntries, err := readFile(file, since)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, fileEntries...)
+	}
+	slices.SortStableFunc(entries, func(a, b Entry) int {
+		return a.Timestamp.Compare(b.Timestamp)
+	})
+	return entries, nil
+}
+
+// readFile reads the entries of a log file written at or after since,
+// skipping lines that aren't entries, such as a line cut short while the
+// collector writes it
+func readFile(path string, since time.Time) ([]Entry, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	defer func() { _ = file.Close() }()
+
+	var entries []Entry
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), maxLineSize)
+	for scanner.Scan() {
+		var entry Entry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			continue
+		}
+		if entry.Timestamp.Before(since) {
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	return entries, nil
+}
+
+// Write prints entries the way docker compose logs does: each line after
+// the service it comes from, unless noPrefix, and its timestamp when
+// timestamps is set
+func Write(w io.Writer, entries []Entry, timestamps, noPrefix bool) error {
+	width := 0
+	for _, entry := range entries {
+		width = max(width, len(entry.Service))
+	}
+	for _, entry := range entries {
+		var line strings.Builder
+		if !noPrefix {
+			line.WriteString(entry.Service + strings.Repeat(" ", width-len(entry.Service)) + "  | ")
+		}
+		if timestamps {
+			line.WriteString(entry.Timestamp.Format(time.RFC3339Nano) + " ")
+		}
+		line.WriteString(strings.TrimRight(entry.Message, "\n"))
+		if _, err := fmt.Fprintln(w, line.String()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// PruneCommand returns the command the log-collector container runs to
+// delete the log files of dir last written more than retention ago
+func PruneCommand(dir string, retention time.Duration) []string {
+	minutes := max(int(retention/time.Minute), 1)
+	return []string{"find", dir, "-type", "f", "-name", "*.log", "-mmin", "+" + strconv.Itoa(minutes), "-delete"}
+}
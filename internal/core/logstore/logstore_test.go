@@ -0,0 +1,62 @@
+package logstore
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeLog(t *testing.T, dir, service, day string, lines ...string) {
+	t.Helper()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, service), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, service, day+".log"), []byte(strings.Join(lines, "\n")+"\n"), 0644))
+}
+
+func TestStore_Read(t *testing.T) {
+	dir := t.TempDir()
+	writeLog(t, dir, "postgres", "2026-10-01",
+		`{"timestamp":"2026-10-01T23:59:00Z","service":"postgres","stream":"stdout","message":"starting"}`,
+	)
+	writeLog(t, dir, "postgres", "2026-10-02",
+		`{"timestamp":"2026-10-02T08:00:00.5Z","service":"postgres","stream":"stdout","message":"ready\n"}`,
+		`{"timestamp":"2026-10-02T09:00:00Z","service":"postgres","stream":"stderr","message":"checkpoint"}`,
+		`{"timestamp":"2026-10-02T09:00`,
+	)
+	writeLog(t, dir, "redis", "2026-10-02",
+		`{"timestamp":"2026-10-02T08:30:00Z","service":"redis","stream":"stdout","message":"Ready to accept connections"}`,
+	)
+
+	store := NewStore(dir)
+	services, err := store.Services()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"postgres", "redis"}, services)
+
+	entries, err := store.Read(nil, time.Date(2026, 10, 2, 0, 0, 0, 0, time.UTC), 0)
+	require.NoError(t, err)
+	var buf bytes.Buffer
+	require.NoError(t, Write(&buf, entries, false, false))
+	assert.Equal(t, "postgres  | ready\n"+
+		"redis     | Ready to accept connections\n"+
+		"postgres  | checkpoint\n", buf.String())
+
+	entries, err = store.Read([]string{"postgres"}, time.Time{}, 2)
+	require.NoError(t, err)
+	buf.Reset()
+	require.NoError(t, Write(&buf, entries, true, true))
+	assert.Equal(t, "2026-10-02T08:00:00.5Z ready\n2026-10-02T09:00:00Z checkpoint\n", buf.String())
+
+	entries, err = NewStore(filepath.Join(dir, "missing")).Read(nil, time.Time{}, 0)
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+}
+
+func TestPruneCommand(t *testing.T) {
+	assert.Equal(t, []string{"find", "/var/log/dev-stack", "-type", "f", "-name", "*.log", "-mmin", "+2880", "-delete"},
+		PruneCommand("/var/log/dev-stack", 48*time.Hour))
+}
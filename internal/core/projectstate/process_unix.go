@@ -0,0 +1,10 @@
+//go:build !windows
+
+package projectstate
+
+import "syscall"
+
+// ProcessAlive reports whether a process with the pid is running
+func ProcessAlive(pid int) bool {
+	return syscall.Kill(pid, 0) == nil
+}
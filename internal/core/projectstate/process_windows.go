@@ -0,0 +1,19 @@
+//go:build windows
+
+package projectstate
+
+import "syscall"
+
+// ProcessAlive reports whether a process with the pid is running
+func ProcessAlive(pid int) bool {
+	const processQueryLimitedInformation = 0x1000
+	handle, err := syscall.OpenProcess(processQueryLimitedInformation, false, uint32(pid))
+	if err != nil {
+		return false
+	}
+	defer func() { _ = syscall.CloseHandle(handle) }()
+
+	var code uint32
+	const stillActive = 259
+	return syscall.GetExitCodeProcess(handle, &code) == nil && code == stillActive
+}
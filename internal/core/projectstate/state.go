@@ -0,0 +1,201 @@
+// Package projectstate keeps the state file of a project: what the last up
+// applied, and the lock that keeps two dev-stack commands from changing
+// the stack at the same time.
+package projectstate
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/isaacgarza/dev-stack/internal/pkg/constants"
+	"github.com/isaacgarza/dev-stack/internal/pkg/utils"
+)
+
+// Timing of the file lock guarding updates of the state file, which is
+// held only while the file is read and written
+const (
+	fileLockRetry   = 50 * time.Millisecond
+	fileLockTimeout = 5 * time.Second
+	// fileLockStale is how old a file lock left by a crashed process may
+	// get before it is broken
+	fileLockStale = 10 * time.Second
+)
+
+// stateLock guards the state file
+var stateLock = utils.FileLock{Retry: fileLockRetry, Timeout: fileLockTimeout, Stale: fileLockStale}
+
+// State is what dev-stack knows about the stack of a project between runs
+type State struct {
+	// ConfigHash is the hash of the project configuration the last up
+	// applied
+	ConfigHash string `json:"config_hash,omitempty"`
+	// Profile is the profile the stack runs
+	Profile string `json:"profile,omitempty"`
+	// Services are the services the last up started
+	Services  []string  `json:"services,omitempty"`
+	AppliedAt time.Time `json:"applied_at,omitzero"`
+	// Idle are the services the idle reaper put to sleep, by name
+	Idle map[string]IdleService `json:"idle,omitempty"`
+	// Lock is held by the command changing the stack, if any
+	Lock *Lock `json:"lock,omitempty"`
+}
+
+// IdleService records a service the idle reaper stopped or paused
+type IdleService struct {
+	Action string    `json:"action"`
+	Since  time.Time `json:"since"`
+	// Ports are the host addresses the service was published on, which
+	// the reaper listens on while it is stopped
+	Ports []string `json:"ports,omitempty"`
+}
+
+// Lock records the command holding the operation lock of a project
+type Lock struct {
+	Operation string    `json:"operation"`
+	PID       int       `json:"pid"`
+	Host      string    `json:"host"`
+	StartedAt time.Time `json:"started_at"`
+}
+
+// Stale reports whether the process holding the lock has exited. A lock
+// taken on another host, through a shared directory, is never assumed
+// stale since its process can't be seen from here.
+func (l *Lock) Stale() bool {
+	host, _ := os.Hostname()
+	return l.Host == host && !ProcessAlive(l.PID)
+}
+
+// LockedError is returned when another running command holds the lock
+type LockedError struct {
+	Lock Lock
+}
+
+func (e *LockedError) Error() string {
+	return fmt.Sprintf("%s %s is already running on this project (pid %d on %s, since %s); wait for it to finish, or run '%s --force' if it is no longer running",
+		constants.AppName, e.Lock.Operation, e.Lock.PID, e.Lock.Host, e.Lock.StartedAt.Local().Format(time.Kitchen), constants.CmdUnlock)
+}
+
+// Path returns the state file of the project in the working directory
+func Path() string {
+	return filepath.Join(constants.DevStackDir, constants.StateFileName)
+}
+
+// HashConfig returns the hash of the project configuration file at path,
+// which tells whether it changed since the last up
+func HashConfig(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// Load reads the state at path. A missing file is an empty state.
+func Load(path string) (*State, error) {
+	state := &State{}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return state, nil
+		}
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	if err := json.Unmarshal(data, state); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return state, nil
+}
+
+// Save writes the state to path, replacing the file atomically
+func (s *State) Save(path string) error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	return utils.WriteFileAtomic(path, append(data, '\n'), 0644)
+}
+
+// Update applies fn to the state at path and saves the result, holding a
+// lock file next to it so concurrent commands don't lose each other's
+// updates. The state is not saved when fn fails.
+func Update(path string, fn func(*State) error) error {
+	unlock, err := stateLock.Acquire(path + ".lock")
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	state, err := Load(path)
+	if err != nil {
+		return err
+	}
+	if err := fn(state); err != nil {
+		return err
+	}
+	return state.Save(path)
+}
+
+// Handle is the operation lock held by the running command
+type Handle struct {
+	path string
+	lock Lock
+}
+
+// Acquire takes the operation lock of the project for operation, such as
+// up. It fails with a *LockedError while a running command holds it; the
+// lock of a command that exited without releasing it is taken over.
+func Acquire(path, operation string) (*Handle, error) {
+	host, _ := os.Hostname()
+	lock := Lock{Operation: operation, PID: os.Getpid(), Host: host, StartedAt: time.Now().UTC()}
+	err := Update(path, func(state *State) error {
+		if state.Lock != nil && !state.Lock.Stale() && state.Lock.PID != lock.PID {
+			return &LockedError{Lock: *state.Lock}
+		}
+		state.Lock = &lock
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &Handle{path: path, lock: lock}, nil
+}
+
+// Release gives up the lock, first applying fn, when not nil, to record
+// what the operation changed. A lock broken by unlock --force in the
+// meantime is left to its new holder.
+func (h *Handle) Release(fn func(*State)) error {
+	return Update(h.path, func(state *State) error {
+		if fn != nil {
+			fn(state)
+		}
+		if state.Lock != nil && state.Lock.PID == h.lock.PID && state.Lock.StartedAt.Equal(h.lock.StartedAt) {
+			state.Lock = nil
+		}
+		return nil
+	})
+}
+
+// Unlock removes the operation lock and returns the one removed, or nil
+// when none was held. Without force only a stale lock is removed; a lock
+// held by a running command fails with a *LockedError.
+func Unlock(path string, force bool) (*Lock, error) {
+	var removed *Lock
+	err := Update(path, func(state *State) error {
+		if state.Lock == nil {
+			return nil
+		}
+		if !force && !state.Lock.Stale() {
+			return &LockedError{Lock: *state.Lock}
+		}
+		removed, state.Lock = state.Lock, nil
+		return nil
+	})
+	return removed, err
+}
@@ -0,0 +1,168 @@
+package projectstate
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// deadPID returns the pid of a process that has exited
+func deadPID(t *testing.T) int {
+	t.Helper()
+	// Pids near the top of the range are practically never in use
+	for pid := 1 << 22; pid > 1<<21; pid-- {
+		if !ProcessAlive(pid) {
+			return pid
+		}
+	}
+	t.Skip("no free pid found")
+	return 0
+}
+
+// lockedBy writes a state at path locked by pid on this host
+func lockedBy(t *testing.T, path string, pid int) {
+	t.Helper()
+	host, _ := os.Hostname()
+	state := &State{Profile: "dev", Lock: &Lock{Operation: "up", PID: pid, Host: host, StartedAt: time.Now().UTC()}}
+	require.NoError(t, state.Save(path))
+}
+
+func TestAcquireRelease(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+
+	handle, err := Acquire(path, "up")
+	require.NoError(t, err)
+
+	state, err := Load(path)
+	require.NoError(t, err)
+	require.NotNil(t, state.Lock)
+	assert.Equal(t, "up", state.Lock.Operation)
+	assert.Equal(t, os.Getpid(), state.Lock.PID)
+
+	require.NoError(t, handle.Release(func(state *State) {
+		state.ConfigHash = "abc"
+		state.Services = []string{"postgres"}
+	}))
+	state, err = Load(path)
+	require.NoError(t, err)
+	assert.Nil(t, state.Lock)
+	assert.Equal(t, "abc", state.ConfigHash)
+	assert.Equal(t, []string{"postgres"}, state.Services)
+}
+
+func TestAcquire_Locked(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	// The parent of the test process outlives it
+	lockedBy(t, path, os.Getppid())
+
+	_, err := Acquire(path, "down")
+	var locked *LockedError
+	require.ErrorAs(t, err, &locked)
+	assert.Equal(t, "up", locked.Lock.Operation)
+	assert.Contains(t, err.Error(), "unlock --force")
+}
+
+func TestAcquire_Stale(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	lockedBy(t, path, deadPID(t))
+
+	handle, err := Acquire(path, "down")
+	require.NoError(t, err)
+	require.NoError(t, handle.Release(nil))
+
+	state, err := Load(path)
+	require.NoError(t, err)
+	assert.Nil(t, state.Lock)
+	assert.Equal(t, "dev", state.Profile)
+}
+
+func TestRelease_LockTakenOver(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	handle, err := Acquire(path, "up")
+	require.NoError(t, err)
+
+	// unlock --force broke the lock and another command took it
+	_, err = Unlock(path, true)
+	require.NoError(t, err)
+	lockedBy(t, path, os.Getppid())
+
+	require.NoError(t, handle.Release(nil))
+	state, err := Load(path)
+	require.NoError(t, err)
+	require.NotNil(t, state.Lock)
+	assert.Equal(t, os.Getppid(), state.Lock.PID)
+}
+
+func TestUnlock(t *testing.T) {
+	t.Run("not locked", func(t *testing.T) {
+		removed, err := Unlock(filepath.Join(t.TempDir(), "state.json"), false)
+		require.NoError(t, err)
+		assert.Nil(t, removed)
+	})
+
+	t.Run("stale", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "state.json")
+		pid := deadPID(t)
+		lockedBy(t, path, pid)
+
+		removed, err := Unlock(path, false)
+		require.NoError(t, err)
+		require.NotNil(t, removed)
+		assert.Equal(t, pid, removed.PID)
+	})
+
+	t.Run("alive", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "state.json")
+		lockedBy(t, path, os.Getppid())
+
+		_, err := Unlock(path, false)
+		var locked *LockedError
+		require.ErrorAs(t, err, &locked)
+
+		removed, err := Unlock(path, true)
+		require.NoError(t, err)
+		require.NotNil(t, removed)
+
+		state, err := Load(path)
+		require.NoError(t, err)
+		assert.Nil(t, state.Lock)
+		assert.Equal(t, "dev", state.Profile)
+	})
+}
+
+func TestUpdate_Concurrent(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+
+	done := make(chan error)
+	for i := range 20 {
+		go func() {
+			done <- Update(path, func(state *State) error {
+				state.Services = append(state.Services, string(rune('a'+i)))
+				return nil
+			})
+		}()
+	}
+	for range 20 {
+		require.NoError(t, <-done)
+	}
+
+	state, err := Load(path)
+	require.NoError(t, err)
+	assert.Len(t, state.Services, 20)
+}
+
+func TestHashConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "dev-stack-config.yml")
+	require.NoError(t, os.WriteFile(path, []byte("project:\n  name: demo\n"), 0644))
+	first, err := HashConfig(path)
+	require.NoError(t, err)
+
+	require.NoError(t, os.WriteFile(path, []byte("project:\n  name: other\n"), 0644))
+	second, err := HashConfig(path)
+	require.NoError(t, err)
+	assert.NotEqual(t, first, second)
+}
@@ -0,0 +1,140 @@
+// Package prune finds what dev-stack leaves on disk that is safe to remove:
+// old backups, old snapshots and installs of versions no longer in use
+package prune
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/isaacgarza/dev-stack/internal/core/snapshot"
+	"github.com/isaacgarza/dev-stack/internal/pkg/types"
+)
+
+// Backups returns the backups of project in dir last written before cutoff.
+// Backups are the files named <project>-<service>-<timestamp>; anything
+// else in dir is left alone.
+func Backups(dir, project string, cutoff time.Time) ([]types.Reclaimable, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read %s: %w", dir, err)
+	}
+
+	var items []types.Reclaimable
+	for _, entry := range entries {
+		if !entry.Type().IsRegular() || !strings.HasPrefix(entry.Name(), project+"-") {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil || !info.ModTime().Before(cutoff) {
+			continue
+		}
+		items = append(items, types.Reclaimable{
+			Category:  types.PruneBackups,
+			Name:      filepath.Join(dir, entry.Name()),
+			Detail:    project,
+			Size:      info.Size(),
+			CreatedAt: info.ModTime(),
+		})
+	}
+	return items, nil
+}
+
+// Snapshots returns the snapshots of the project taken before cutoff
+func Snapshots(cutoff time.Time) ([]types.Reclaimable, error) {
+	manifests, err := snapshot.List()
+	if err != nil {
+		return nil, err
+	}
+
+	var items []types.Reclaimable
+	for _, manifest := range manifests {
+		if !manifest.CreatedAt.Before(cutoff) {
+			continue
+		}
+		dir := snapshot.Dir(manifest.Name)
+		items = append(items, types.Reclaimable{
+			Category:  types.PruneSnapshots,
+			Name:      dir,
+			Detail:    manifest.Project,
+			Size:      dirSize(dir),
+			CreatedAt: manifest.CreatedAt,
+		})
+	}
+	return items, nil
+}
+
+// Versions returns the installs in dir, a directory per version, of the
+// versions other than keep, such as the running and active versions.
+// Versions are compared with or without their leading v.
+func Versions(dir string, keep ...string) ([]types.Reclaimable, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read %s: %w", dir, err)
+	}
+
+	kept := make([]string, 0, len(keep))
+	for _, version := range keep {
+		kept = append(kept, strings.TrimPrefix(version, "v"))
+	}
+	var items []types.Reclaimable
+	for _, entry := range entries {
+		if !entry.IsDir() || slices.Contains(kept, strings.TrimPrefix(entry.Name(), "v")) {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		path := filepath.Join(dir, entry.Name())
+		items = append(items, types.Reclaimable{
+			Category:  types.PruneVersions,
+			Name:      path,
+			Detail:    entry.Name(),
+			Size:      dirSize(path),
+			CreatedAt: info.ModTime(),
+		})
+	}
+	return items, nil
+}
+
+// Remove deletes the file or directory of a backup, snapshot or version
+// install
+func Remove(item types.Reclaimable) error {
+	switch item.Category {
+	case types.PruneBackups, types.PruneSnapshots, types.PruneVersions:
+	default:
+		return fmt.Errorf("%s aren't stored on disk", item.Category)
+	}
+	if err := os.RemoveAll(item.Name); err != nil {
+		return fmt.Errorf("failed to remove %s: %w", item.Name, err)
+	}
+	return nil
+}
+
+// dirSize returns the total size of the files under dir. Files that can't
+// be read are left out.
+func dirSize(dir string) int64 {
+	var size int64
+	_ = filepath.WalkDir(dir, func(_ string, entry fs.DirEntry, err error) error {
+		if err != nil || !entry.Type().IsRegular() {
+			return nil
+		}
+		if info, err := entry.Info(); err == nil {
+			size += info.Size()
+		}
+		return nil
+	})
+	return size
+}
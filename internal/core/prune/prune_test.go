@@ -0,0 +1,74 @@
+package prune
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/isaacgarza/dev-stack/internal/core/snapshot"
+	"github.com/isaacgarza/dev-stack/internal/pkg/types"
+)
+
+func TestBackups(t *testing.T) {
+	dir := t.TempDir()
+	cutoff := time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)
+	write := func(name string, modTime time.Time) {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(path, []byte("dump"), 0644))
+		require.NoError(t, os.Chtimes(path, modTime, modTime))
+	}
+	write("shop-postgres-20260101-120000.sql.gz", cutoff.AddDate(0, -5, 0))
+	write("shop-redis-20260701-120000.rdb", cutoff.AddDate(0, 1, 0))
+	write("blog-postgres-20260101-120000.sql", cutoff.AddDate(0, -5, 0))
+	write("notes.txt", cutoff.AddDate(0, -5, 0))
+
+	items, err := Backups(dir, "shop", cutoff)
+	require.NoError(t, err)
+	require.Len(t, items, 1, "newer backups, and files of other projects, are kept")
+	assert.Equal(t, filepath.Join(dir, "shop-postgres-20260101-120000.sql.gz"), items[0].Name)
+	assert.Equal(t, types.PruneBackups, items[0].Category)
+	assert.Equal(t, int64(4), items[0].Size)
+
+	items, err = Backups(filepath.Join(dir, "missing"), "shop", cutoff)
+	require.NoError(t, err)
+	assert.Empty(t, items)
+}
+
+func TestSnapshots(t *testing.T) {
+	t.Chdir(t.TempDir())
+	cutoff := time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)
+	for name, createdAt := range map[string]time.Time{"old": cutoff.AddDate(0, -1, 0), "recent": cutoff.AddDate(0, 0, 1)} {
+		require.NoError(t, os.MkdirAll(filepath.Join(snapshot.Dir(name), "postgres"), 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(snapshot.Dir(name), "postgres", "data.tar"), []byte("archive"), 0644))
+		manifest := &snapshot.Manifest{Name: name, Project: "shop", CreatedAt: createdAt}
+		require.NoError(t, manifest.Save(snapshot.Dir(name)))
+	}
+
+	items, err := Snapshots(cutoff)
+	require.NoError(t, err)
+	require.Len(t, items, 1)
+	assert.Equal(t, snapshot.Dir("old"), items[0].Name)
+	assert.Greater(t, items[0].Size, int64(len("archive")), "the size includes the manifest")
+}
+
+func TestVersions(t *testing.T) {
+	dir := t.TempDir()
+	for _, version := range []string{"v1.2.0", "1.3.0", "1.4.0"} {
+		require.NoError(t, os.MkdirAll(filepath.Join(dir, version), 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, version, "dev-stack"), []byte("binary"), 0755))
+	}
+
+	items, err := Versions(dir, "1.2.0", "v1.4.0")
+	require.NoError(t, err)
+	require.Len(t, items, 1)
+	assert.Equal(t, filepath.Join(dir, "1.3.0"), items[0].Name)
+	assert.Equal(t, int64(len("binary")), items[0].Size)
+
+	require.NoError(t, Remove(items[0]))
+	assert.NoDirExists(t, items[0].Name)
+	assert.Error(t, Remove(types.Reclaimable{Category: types.PruneContainers, Name: "shop-postgres-1"}))
+}
@@ -0,0 +1,245 @@
+package readiness
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/isaacgarza/dev-stack/internal/pkg/types"
+)
+
+const (
+	defaultProbeTimeout         = 2 * time.Second
+	defaultPollInterval         = time.Second
+	defaultHost                 = "localhost"
+	defaultKafkaBootstrapServer = "localhost:9092"
+)
+
+// ContainerRunner is the subset of container operations the checker needs;
+// it is satisfied by docker.ContainerService
+type ContainerRunner interface {
+	List(ctx context.Context, projectName string, serviceNames []string) ([]types.ServiceStatus, error)
+	ExecCapture(ctx context.Context, projectName, serviceName string, cmd []string) (*types.ExecResult, error)
+}
+
+// Target is a service to wait for together with its readiness configuration
+type Target struct {
+	Service   string
+	Readiness types.ReadinessConfig
+	Timeout   time.Duration
+}
+
+// Result describes the outcome of waiting for a single service
+type Result struct {
+	Service  string
+	Ready    bool
+	Duration time.Duration
+	Err      error
+}
+
+// Checker runs readiness probes against the services of a project
+type Checker struct {
+	containers   ContainerRunner
+	projectName  string
+	httpClient   *http.Client
+	pollInterval time.Duration
+}
+
+// NewChecker creates a new readiness checker
+func NewChecker(containers ContainerRunner, projectName string) *Checker {
+	return &Checker{
+		containers:   containers,
+		projectName:  projectName,
+		httpClient:   &http.Client{Timeout: defaultProbeTimeout},
+		pollInterval: defaultPollInterval,
+	}
+}
+
+// WaitForAll waits for every target concurrently and returns one result per
+// target in the order given
+func (c *Checker) WaitForAll(ctx context.Context, targets []Target) []Result {
+	results := make([]Result, len(targets))
+
+	var wg sync.WaitGroup
+	for i, target := range targets {
+		wg.Add(1)
+		go func(i int, target Target) {
+			defer wg.Done()
+			results[i] = c.WaitFor(ctx, target)
+		}(i, target)
+	}
+	wg.Wait()
+
+	return results
+}
+
+// WaitFor polls the target's probes until they all pass or its timeout
+// expires. Services without probes fall back to the container health status.
+func (c *Checker) WaitFor(ctx context.Context, target Target) Result {
+	start := time.Now()
+	if target.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, target.Timeout)
+		defer cancel()
+	}
+
+	result := Result{Service: target.Service}
+	for {
+		err := c.checkTarget(ctx, target)
+		if err == nil {
+			result.Ready = true
+			result.Duration = time.Since(start)
+			return result
+		}
+
+		select {
+		case <-ctx.Done():
+			result.Duration = time.Since(start)
+			result.Err = fmt.Errorf("not ready after %s: %w", result.Duration.Round(time.Second), err)
+			return result
+		case <-time.After(c.pollInterval):
+		}
+	}
+}
+
+// checkTarget runs every probe once, returning the first failure
+func (c *Checker) checkTarget(ctx context.Context, target Target) error {
+	if len(target.Readiness.Probes) == 0 {
+		return c.checkContainer(ctx, target.Service)
+	}
+
+	for _, probe := range target.Readiness.Probes {
+		if err := c.Check(ctx, target.Service, probe); err != nil {
+			return fmt.Errorf("%s probe: %w", probe.Type, err)
+		}
+	}
+	return nil
+}
+
+// Check runs a single probe against a service
+func (c *Checker) Check(ctx context.Context, serviceName string, probe types.ReadinessProbe) error {
+	switch probe.Type {
+	case types.ProbeTCP:
+		return c.checkTCP(ctx, probe)
+	case types.ProbeHTTP:
+		return c.checkHTTP(ctx, probe)
+	case types.ProbeExec, types.ProbeSQL:
+		return c.checkExec(ctx, serviceName, probe.Command)
+	case types.ProbeKafkaTopic:
+		return c.checkKafkaTopic(ctx, serviceName, probe)
+	default:
+		return fmt.Errorf("unknown probe type %q", probe.Type)
+	}
+}
+
+func (c *Checker) checkTCP(ctx context.Context, probe types.ReadinessProbe) error {
+	if probe.Port == "" {
+		return fmt.Errorf("port is required")
+	}
+	port, err := strconv.Atoi(probe.Port)
+	if err != nil || port <= 0 {
+		return fmt.Errorf("invalid port %q", probe.Port)
+	}
+	host := probe.Host
+	if host == "" {
+		host = defaultHost
+	}
+
+	dialer := net.Dialer{Timeout: defaultProbeTimeout}
+	conn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(host, strconv.Itoa(port)))
+	if err != nil {
+		return err
+	}
+	return conn.Close()
+}
+
+func (c *Checker) checkHTTP(ctx context.Context, probe types.ReadinessProbe) error {
+	if probe.URL == "" {
+		return fmt.Errorf("url is required")
+	}
+	expected := probe.ExpectedStatus
+	if expected == 0 {
+		expected = http.StatusOK
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, probe.URL, nil)
+	if err != nil {
+		return fmt.Errorf("invalid url: %w", err)
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != expected {
+		return fmt.Errorf("GET %s returned %d, expected %d", probe.URL, resp.StatusCode, expected)
+	}
+	return nil
+}
+
+func (c *Checker) checkExec(ctx context.Context, serviceName string, command []string) error {
+	if len(command) == 0 {
+		return fmt.Errorf("command is required")
+	}
+
+	result, err := c.containers.ExecCapture(ctx, c.projectName, serviceName, command)
+	if err != nil {
+		return err
+	}
+	if result.ExitCode != 0 {
+		return fmt.Errorf("%s exited with code %d: %s", command[0], result.ExitCode, strings.TrimSpace(result.Stderr))
+	}
+	return nil
+}
+
+func (c *Checker) checkKafkaTopic(ctx context.Context, serviceName string, probe types.ReadinessProbe) error {
+	if probe.Topic == "" {
+		return fmt.Errorf("topic is required")
+	}
+	bootstrap := probe.BootstrapServer
+	if bootstrap == "" {
+		bootstrap = defaultKafkaBootstrapServer
+	}
+
+	command := probe.Command
+	if len(command) == 0 {
+		command = []string{"kafka-topics", "--bootstrap-server", bootstrap, "--describe", "--topic", probe.Topic}
+	}
+
+	result, err := c.containers.ExecCapture(ctx, c.projectName, serviceName, command)
+	if err != nil {
+		return err
+	}
+	if result.ExitCode != 0 || !strings.Contains(result.Stdout, "Topic: "+probe.Topic) {
+		return fmt.Errorf("topic %s does not exist yet", probe.Topic)
+	}
+	return nil
+}
+
+// checkContainer passes when the service container is running and, if it
+// defines a health check, healthy
+func (c *Checker) checkContainer(ctx context.Context, serviceName string) error {
+	statuses, err := c.containers.List(ctx, c.projectName, []string{serviceName})
+	if err != nil {
+		return err
+	}
+	if len(statuses) == 0 {
+		return fmt.Errorf("no container found")
+	}
+
+	for _, status := range statuses {
+		if !status.State.IsRunning() {
+			return fmt.Errorf("container is %s", status.State)
+		}
+		if status.Health != types.HealthStatusHealthy && status.Health != types.HealthStatusNone && status.Health != "" {
+			return fmt.Errorf("container is %s", status.Health)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,128 @@
+package readiness
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/isaacgarza/dev-stack/internal/pkg/types"
+)
+
+type fakeRunner struct {
+	statuses []types.ServiceStatus
+	results  []*types.ExecResult
+	commands [][]string
+}
+
+func (f *fakeRunner) List(ctx context.Context, projectName string, serviceNames []string) ([]types.ServiceStatus, error) {
+	return f.statuses, nil
+}
+
+func (f *fakeRunner) ExecCapture(ctx context.Context, projectName, serviceName string, cmd []string) (*types.ExecResult, error) {
+	f.commands = append(f.commands, cmd)
+	result := f.results[0]
+	if len(f.results) > 1 {
+		f.results = f.results[1:]
+	}
+	return result, nil
+}
+
+func TestCheckTCP(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	port := listener.Addr().(*net.TCPAddr).Port
+
+	checker := NewChecker(&fakeRunner{}, "test")
+	probe := types.ReadinessProbe{Type: types.ProbeTCP, Host: "127.0.0.1", Port: strconv.Itoa(port)}
+	assert.NoError(t, checker.Check(context.Background(), "svc", probe))
+
+	require.NoError(t, listener.Close())
+	assert.Error(t, checker.Check(context.Background(), "svc", probe))
+}
+
+func TestCheckHTTP(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/ready" {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	checker := NewChecker(&fakeRunner{}, "test")
+
+	assert.NoError(t, checker.Check(context.Background(), "svc", types.ReadinessProbe{
+		Type: types.ProbeHTTP, URL: server.URL + "/ready", ExpectedStatus: http.StatusNoContent,
+	}))
+	assert.Error(t, checker.Check(context.Background(), "svc", types.ReadinessProbe{
+		Type: types.ProbeHTTP, URL: server.URL + "/other",
+	}))
+}
+
+func TestCheckKafkaTopic(t *testing.T) {
+	runner := &fakeRunner{results: []*types.ExecResult{
+		{ExitCode: 1, Stderr: "Topic 'orders' does not exist"},
+		{ExitCode: 0, Stdout: "Topic: orders\tTopicId: abc\tPartitionCount: 3"},
+	}}
+	checker := NewChecker(runner, "test")
+	probe := types.ReadinessProbe{Type: types.ProbeKafkaTopic, Topic: "orders"}
+
+	assert.Error(t, checker.Check(context.Background(), "kafka-broker", probe))
+	assert.NoError(t, checker.Check(context.Background(), "kafka-broker", probe))
+	assert.Equal(t, []string{"kafka-topics", "--bootstrap-server", "localhost:9092", "--describe", "--topic", "orders"}, runner.commands[0])
+}
+
+func TestCheckUnknownProbe(t *testing.T) {
+	checker := NewChecker(&fakeRunner{}, "test")
+	assert.Error(t, checker.Check(context.Background(), "svc", types.ReadinessProbe{Type: "carrier-pigeon"}))
+}
+
+func TestWaitFor(t *testing.T) {
+	t.Run("passes once probes succeed", func(t *testing.T) {
+		runner := &fakeRunner{results: []*types.ExecResult{{ExitCode: 1}, {ExitCode: 0}}}
+		checker := NewChecker(runner, "test")
+		checker.pollInterval = time.Millisecond
+
+		result := checker.WaitFor(context.Background(), Target{
+			Service:   "postgres",
+			Readiness: types.ReadinessConfig{Probes: []types.ReadinessProbe{{Type: types.ProbeSQL, Command: []string{"pg_isready"}}}},
+			Timeout:   time.Second,
+		})
+		assert.True(t, result.Ready)
+		assert.NoError(t, result.Err)
+		assert.Len(t, runner.commands, 2)
+	})
+
+	t.Run("times out", func(t *testing.T) {
+		runner := &fakeRunner{results: []*types.ExecResult{{ExitCode: 1}}}
+		checker := NewChecker(runner, "test")
+		checker.pollInterval = time.Millisecond
+
+		result := checker.WaitFor(context.Background(), Target{
+			Service:   "postgres",
+			Readiness: types.ReadinessConfig{Probes: []types.ReadinessProbe{{Type: types.ProbeExec, Command: []string{"false"}}}},
+			Timeout:   20 * time.Millisecond,
+		})
+		assert.False(t, result.Ready)
+		assert.Error(t, result.Err)
+	})
+
+	t.Run("falls back to container health", func(t *testing.T) {
+		runner := &fakeRunner{statuses: []types.ServiceStatus{
+			{Name: "redis", State: types.ServiceStateRunning, Health: types.HealthStatusHealthy},
+		}}
+		checker := NewChecker(runner, "test")
+
+		results := checker.WaitForAll(context.Background(), []Target{{Service: "redis", Timeout: time.Second}})
+		require.Len(t, results, 1)
+		assert.True(t, results[0].Ready)
+	})
+}
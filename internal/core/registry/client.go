@@ -0,0 +1,196 @@
+package registry
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// maxTagPages bounds how many pages of tags are read for one repository;
+// Docker Hub official images have a few thousand tags
+const maxTagPages = 50
+
+// Client lists image tags through the registry HTTP API, fetching anonymous
+// pull tokens for registries that require them
+type Client struct {
+	httpClient *http.Client
+	// endpoints overrides the base URL used for a registry
+	endpoints map[string]string
+}
+
+// NewClient creates a registry client. A nil httpClient uses
+// http.DefaultClient.
+func NewClient(httpClient *http.Client) *Client {
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	return &Client{httpClient: httpClient}
+}
+
+// Tags lists the tags of the repository ref belongs to
+func (c *Client) Tags(ctx context.Context, ref Reference) ([]string, error) {
+	base := c.baseURL(ref.Registry)
+	next := fmt.Sprintf("%s/v2/%s/tags/list?n=1000", base, ref.Repository)
+
+	var tags []string
+	token := ""
+	for page := 0; next != "" && page < maxTagPages; page++ {
+		resp, err := c.get(ctx, next, token)
+		if err != nil {
+			return nil, err
+		}
+		if resp.StatusCode == http.StatusUnauthorized && token == "" {
+			challenge := resp.Header.Get("WWW-Authenticate")
+			_ = resp.Body.Close()
+			token, err = c.fetchToken(ctx, challenge, ref.Repository)
+			if err != nil {
+				return nil, fmt.Errorf("failed to authenticate to %s: %w", ref.Registry, err)
+			}
+			resp, err = c.get(ctx, next, token)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		var body struct {
+			Tags []string `json:"tags"`
+		}
+		err = decodeResponse(resp, &body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list tags of %s/%s: %w", ref.Registry, ref.Repository, err)
+		}
+		tags = append(tags, body.Tags...)
+		next = nextPage(base, resp.Header.Get("Link"))
+	}
+	return tags, nil
+}
+
+// Ping checks that the API of a registry answers, which it does with 401
+// Unauthorized to anonymous clients of registries that require a token
+func (c *Client) Ping(ctx context.Context, registry string) error {
+	resp, err := c.get(ctx, c.baseURL(registry)+"/v2/", "")
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusUnauthorized {
+		return fmt.Errorf("%s answered %s", registry, resp.Status)
+	}
+	return nil
+}
+
+func (c *Client) baseURL(registry string) string {
+	if endpoint, ok := c.endpoints[registry]; ok {
+		return endpoint
+	}
+	if registry == dockerHubRegistry {
+		return "https://" + dockerHubEndpoint
+	}
+	return "https://" + registry
+}
+
+func (c *Client) get(ctx context.Context, target, token string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	return c.httpClient.Do(req)
+}
+
+// fetchToken gets an anonymous pull token from the realm named in a Bearer
+// WWW-Authenticate challenge
+func (c *Client) fetchToken(ctx context.Context, challenge, repository string) (string, error) {
+	params := parseChallenge(challenge)
+	realm := params["realm"]
+	if realm == "" {
+		return "", fmt.Errorf("registry requires authentication")
+	}
+
+	realmURL, err := url.Parse(realm)
+	if err != nil {
+		return "", fmt.Errorf("invalid token realm %q: %w", realm, err)
+	}
+	query := realmURL.Query()
+	if service := params["service"]; service != "" {
+		query.Set("service", service)
+	}
+	scope := params["scope"]
+	if scope == "" {
+		scope = "repository:" + repository + ":pull"
+	}
+	query.Set("scope", scope)
+	realmURL.RawQuery = query.Encode()
+
+	resp, err := c.get(ctx, realmURL.String(), "")
+	if err != nil {
+		return "", err
+	}
+	var body struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}
+	if err := decodeResponse(resp, &body); err != nil {
+		return "", err
+	}
+	if body.Token != "" {
+		return body.Token, nil
+	}
+	return body.AccessToken, nil
+}
+
+// decodeResponse decodes a JSON response body into v and closes it
+func decodeResponse(resp *http.Response, v interface{}) error {
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("%s: %s", resp.Status, strings.TrimSpace(string(message)))
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+// parseChallenge parses the parameters of a WWW-Authenticate Bearer
+// challenge such as: Bearer realm="https://auth.docker.io/token",service="registry.docker.io"
+func parseChallenge(challenge string) map[string]string {
+	params := make(map[string]string)
+	scheme, rest, _ := strings.Cut(challenge, " ")
+	if !strings.EqualFold(scheme, "Bearer") {
+		return params
+	}
+	for rest != "" {
+		var key, value string
+		key, rest, _ = strings.Cut(strings.TrimLeft(rest, " ,"), "=")
+		if strings.HasPrefix(rest, `"`) {
+			value, rest, _ = strings.Cut(rest[1:], `"`)
+		} else {
+			value, rest, _ = strings.Cut(rest, ",")
+		}
+		if key != "" {
+			params[strings.ToLower(strings.TrimSpace(key))] = value
+		}
+	}
+	return params
+}
+
+// nextPage returns the URL of the next page named in a Link header, or ""
+func nextPage(base, link string) string {
+	for _, part := range strings.Split(link, ",") {
+		target, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		if !strings.Contains(params, `rel="next"`) {
+			continue
+		}
+		target = strings.Trim(strings.TrimSpace(target), "<>")
+		if strings.HasPrefix(target, "/") {
+			return base + target
+		}
+		return target
+	}
+	return ""
+}
@@ -0,0 +1,90 @@
+package registry
+
+import "strings"
+
+const (
+	dockerHubRegistry = "docker.io"
+	dockerHubEndpoint = "registry-1.docker.io"
+	defaultTag        = "latest"
+)
+
+// Reference is an image reference split into the registry it lives in, the
+// repository path within that registry and its tag
+type Reference struct {
+	Registry   string
+	Repository string
+	Tag        string
+}
+
+// ParseReference parses an image reference such as postgres:15-alpine or
+// quay.io/prometheus/prometheus:v2.45.0. Docker Hub official images get the
+// library/ namespace and references without a tag get latest.
+func ParseReference(image string) Reference {
+	name, tag := splitTag(image)
+	if tag == "" {
+		tag = defaultTag
+	}
+
+	ref := Reference{Registry: dockerHubRegistry, Repository: name, Tag: tag}
+	first, rest, found := strings.Cut(name, "/")
+	switch {
+	case found && (strings.ContainsAny(first, ".:") || first == "localhost"):
+		ref.Registry, ref.Repository = first, rest
+		if first == "index.docker.io" {
+			ref.Registry = dockerHubRegistry
+		}
+	case !found:
+		ref.Repository = "library/" + name
+	}
+	return ref
+}
+
+// WithTag returns image with its tag replaced by tag, keeping the rest of
+// the reference as written
+func WithTag(image, tag string) string {
+	name, _ := splitTag(image)
+	return name + ":" + tag
+}
+
+// Rewrite points image at the mirror configured for its registry in
+// mirrors, keyed by registry host such as docker.io or quay.io. Images from
+// other registries are returned unchanged.
+func Rewrite(image string, mirrors map[string]string) string {
+	ref := ParseReference(image)
+	mirror, ok := mirrors[ref.Registry]
+	if !ok {
+		return image
+	}
+	mirror = strings.TrimSuffix(strings.TrimPrefix(strings.TrimPrefix(mirror, "https://"), "http://"), "/")
+	if mirror == "" {
+		return image
+	}
+
+	nameAndTag, digest, hasDigest := strings.Cut(image, "@")
+	rewritten := mirror + "/" + ref.Repository
+	if _, tag := splitTag(nameAndTag); tag != "" {
+		rewritten += ":" + tag
+	}
+	if hasDigest {
+		rewritten += "@" + digest
+	}
+	return rewritten
+}
+
+// TagOf returns the tag of image, or latest if it has none
+func TagOf(image string) string {
+	if _, tag := splitTag(image); tag != "" {
+		return tag
+	}
+	return defaultTag
+}
+
+// splitTag splits image into its name and tag, dropping any digest. A colon
+// before the last slash belongs to a registry port, not a tag.
+func splitTag(image string) (string, string) {
+	image, _, _ = strings.Cut(image, "@")
+	if i := strings.LastIndex(image, ":"); i > strings.LastIndex(image, "/") {
+		return image[:i], image[i+1:]
+	}
+	return image, ""
+}
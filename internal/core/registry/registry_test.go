@@ -0,0 +1,159 @@
+package registry
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseReference(t *testing.T) {
+	tests := []struct {
+		image    string
+		expected Reference
+	}{
+		{image: "postgres:15-alpine", expected: Reference{Registry: "docker.io", Repository: "library/postgres", Tag: "15-alpine"}},
+		{image: "provectuslabs/kafka-ui", expected: Reference{Registry: "docker.io", Repository: "provectuslabs/kafka-ui", Tag: "latest"}},
+		{image: "quay.io/prometheus/prometheus:v2.45.0", expected: Reference{Registry: "quay.io", Repository: "prometheus/prometheus", Tag: "v2.45.0"}},
+		{image: "localhost:5000/app:1.2", expected: Reference{Registry: "localhost:5000", Repository: "app", Tag: "1.2"}},
+		{image: "index.docker.io/library/redis:7@sha256:abc", expected: Reference{Registry: "docker.io", Repository: "library/redis", Tag: "7"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.image, func(t *testing.T) {
+			assert.Equal(t, tt.expected, ParseReference(tt.image))
+		})
+	}
+}
+
+func TestWithTag(t *testing.T) {
+	assert.Equal(t, "postgres:16.2", WithTag("postgres:15-alpine", "16.2"))
+	assert.Equal(t, "minio/minio:RELEASE.2024-10-02", WithTag("minio/minio", "RELEASE.2024-10-02"))
+	assert.Equal(t, "localhost:5000/app:2", WithTag("localhost:5000/app:1", "2"))
+	assert.Equal(t, "15-alpine", TagOf("postgres:15-alpine"))
+	assert.Equal(t, "latest", TagOf("localhost:5000/app"))
+}
+
+func TestNewerTags(t *testing.T) {
+	tags := []string{"latest", "alpine", "14-alpine", "15-alpine", "16-alpine", "17-alpine", "16.1-alpine", "16", "17", "9.6-alpine"}
+
+	tests := []struct {
+		current  string
+		expected []string
+	}{
+		{current: "15-alpine", expected: []string{"16-alpine", "17-alpine"}},
+		{current: "17-alpine", expected: []string{}},
+		{current: "16", expected: []string{"17"}},
+		{current: "16.0-alpine", expected: []string{"16.1-alpine"}},
+		{current: "latest", expected: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.current, func(t *testing.T) {
+			assert.Equal(t, tt.expected, NewerTags(tt.current, tags))
+		})
+	}
+
+	assert.Equal(t, "v2.54.1", LatestTag("v2.45.0", []string{"v2.45.0", "v2.54.1", "v2.9.0", "2.60.0"}))
+	assert.Equal(t, "", LatestTag("17-alpine", tags))
+}
+
+func TestClient_Tags(t *testing.T) {
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "registry.test", r.URL.Query().Get("service"))
+		assert.Equal(t, "repository:library/postgres:pull", r.URL.Query().Get("scope"))
+		_ = json.NewEncoder(w).Encode(map[string]string{"token": "secret"})
+	})
+	mux.HandleFunc("/v2/library/postgres/tags/list", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="`+server.URL+`/token",service="registry.test",scope="repository:library/postgres:pull"`)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if r.URL.Query().Get("last") == "" {
+			w.Header().Set("Link", `</v2/library/postgres/tags/list?last=15-alpine&n=1000>; rel="next"`)
+			_ = json.NewEncoder(w).Encode(map[string][]string{"tags": {"14-alpine", "15-alpine"}})
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string][]string{"tags": {"16-alpine"}})
+	})
+
+	client := NewClient(server.Client())
+	client.endpoints = map[string]string{dockerHubRegistry: server.URL}
+
+	tags, err := client.Tags(context.Background(), ParseReference("postgres:15-alpine"))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"14-alpine", "15-alpine", "16-alpine"}, tags)
+}
+
+func TestClient_Tags_NotFound(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"errors":[{"code":"NAME_UNKNOWN"}]}`, http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	client := NewClient(server.Client())
+	client.endpoints = map[string]string{"quay.io": server.URL}
+
+	_, err := client.Tags(context.Background(), ParseReference("quay.io/missing/image:1"))
+	assert.ErrorContains(t, err, "404")
+}
+
+func TestClient_Ping(t *testing.T) {
+	status := http.StatusUnauthorized
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v2/", r.URL.Path)
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	client := NewClient(server.Client())
+	client.endpoints = map[string]string{"ghcr.io": server.URL}
+
+	assert.NoError(t, client.Ping(context.Background(), "ghcr.io"))
+	status = http.StatusForbidden
+	assert.ErrorContains(t, client.Ping(context.Background(), "ghcr.io"), "403")
+}
+
+func TestParseChallenge(t *testing.T) {
+	params := parseChallenge(`Bearer realm="https://auth.docker.io/token",service="registry.docker.io",scope="repository:library/redis:pull"`)
+	assert.Equal(t, "https://auth.docker.io/token", params["realm"])
+	assert.Equal(t, "registry.docker.io", params["service"])
+	assert.Equal(t, "repository:library/redis:pull", params["scope"])
+
+	assert.Empty(t, parseChallenge(`Basic realm="registry"`))
+}
+
+func TestRewrite(t *testing.T) {
+	mirrors := map[string]string{
+		"docker.io": "https://registry.internal:5000/",
+		"quay.io":   "registry.internal:5001",
+	}
+
+	tests := []struct {
+		image    string
+		expected string
+	}{
+		{image: "postgres:15-alpine", expected: "registry.internal:5000/library/postgres:15-alpine"},
+		{image: "provectuslabs/kafka-ui", expected: "registry.internal:5000/provectuslabs/kafka-ui"},
+		{image: "docker.io/bitnami/redis:7@sha256:abc", expected: "registry.internal:5000/bitnami/redis:7@sha256:abc"},
+		{image: "quay.io/prometheus/prometheus:v2.45.0", expected: "registry.internal:5001/prometheus/prometheus:v2.45.0"},
+		{image: "ghcr.io/org/app:1", expected: "ghcr.io/org/app:1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.image, func(t *testing.T) {
+			assert.Equal(t, tt.expected, Rewrite(tt.image, mirrors))
+		})
+	}
+
+	assert.Equal(t, "postgres:15", Rewrite("postgres:15", nil))
+}
@@ -0,0 +1,93 @@
+package registry
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// version is a tag of the form [v]MAJOR[.MINOR[.PATCH...]][-VARIANT], such as
+// 15-alpine, 3.13-management-alpine or v2.45.0
+type version struct {
+	prefix  string
+	numbers []int
+	variant string
+}
+
+// parseVersion parses tag as a version. Tags without a leading number, such
+// as latest or alpine, are not versions.
+func parseVersion(tag string) (version, bool) {
+	var v version
+	rest := tag
+	if strings.HasPrefix(rest, "v") {
+		v.prefix, rest = "v", rest[1:]
+	}
+
+	numeric, variant, _ := strings.Cut(rest, "-")
+	v.variant = variant
+	for _, part := range strings.Split(numeric, ".") {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return version{}, false
+		}
+		v.numbers = append(v.numbers, n)
+	}
+	return v, true
+}
+
+// sameTrack reports whether v and other are versions of the same image
+// variant at the same precision, so 15-alpine follows 16-alpine but not
+// 16.1-alpine or 16
+func (v version) sameTrack(other version) bool {
+	return v.prefix == other.prefix && v.variant == other.variant && len(v.numbers) == len(other.numbers)
+}
+
+// less reports whether v is lower than other; both must be on the same track
+func (v version) less(other version) bool {
+	for i := range v.numbers {
+		if v.numbers[i] != other.numbers[i] {
+			return v.numbers[i] < other.numbers[i]
+		}
+	}
+	return false
+}
+
+// NewerTags returns the tags in tags that are newer versions of current on
+// the same track, lowest first. Nothing is newer than a tag that is not a
+// version, such as latest.
+func NewerTags(current string, tags []string) []string {
+	currentVersion, ok := parseVersion(current)
+	if !ok {
+		return nil
+	}
+
+	type candidate struct {
+		tag     string
+		version version
+	}
+	var newer []candidate
+	for _, tag := range tags {
+		v, ok := parseVersion(tag)
+		if !ok || !currentVersion.sameTrack(v) || !currentVersion.less(v) {
+			continue
+		}
+		newer = append(newer, candidate{tag: tag, version: v})
+	}
+	sort.Slice(newer, func(i, j int) bool { return newer[i].version.less(newer[j].version) })
+
+	result := make([]string, 0, len(newer))
+	for _, c := range newer {
+		result = append(result, c.tag)
+	}
+	return result
+}
+
+// LatestTag returns the newest tag on the same track as current, or "" if
+// current is already the newest
+func LatestTag(current string, tags []string) string {
+	newer := NewerTags(current, tags)
+	if len(newer) == 0 {
+		return ""
+	}
+	return newer[len(newer)-1]
+}
@@ -0,0 +1,193 @@
+package seed
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// defaultBootstrapServer is used when the seed operation does not name one
+const defaultBootstrapServer = "localhost:9092"
+
+// kafkaKeySeparator separates keys from values on the console producer's stdin
+const kafkaKeySeparator = "\t"
+
+// KafkaFixture declares topics to create and messages to produce. Fixtures
+// are YAML or JSON files.
+type KafkaFixture struct {
+	Topics []KafkaTopic `yaml:"topics" json:"topics"`
+}
+
+// KafkaTopic is a topic to create together with messages to produce to it
+type KafkaTopic struct {
+	Name              string            `yaml:"name" json:"name"`
+	Partitions        int               `yaml:"partitions,omitempty" json:"partitions,omitempty"`
+	ReplicationFactor int               `yaml:"replication_factor,omitempty" json:"replication_factor,omitempty"`
+	Config            map[string]string `yaml:"config,omitempty" json:"config,omitempty"`
+	Messages          []KafkaMessage    `yaml:"messages,omitempty" json:"messages,omitempty"`
+}
+
+// KafkaMessage is a single message. Values that are not strings are encoded
+// as JSON.
+type KafkaMessage struct {
+	Key   string `yaml:"key,omitempty" json:"key,omitempty"`
+	Value any    `yaml:"value" json:"value"`
+}
+
+// Step is a command to run in the service's container, optionally fed stdin
+type Step struct {
+	Command []string
+	Stdin   string
+}
+
+// LoadKafkaFixture reads and validates a Kafka fixture file
+func LoadKafkaFixture(path string) (*KafkaFixture, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read seed %s: %w", path, err)
+	}
+
+	// YAML is a superset of JSON, so one decoder covers both
+	var fixture KafkaFixture
+	if err := yaml.Unmarshal(data, &fixture); err != nil {
+		return nil, fmt.Errorf("failed to parse seed %s: %w", path, err)
+	}
+
+	if len(fixture.Topics) == 0 {
+		return nil, fmt.Errorf("seed %s declares no topics", path)
+	}
+	for _, topic := range fixture.Topics {
+		if topic.Name == "" {
+			return nil, fmt.Errorf("seed %s has a topic without a name", path)
+		}
+	}
+	return &fixture, nil
+}
+
+// Steps returns the commands that create the fixture's topics and produce
+// its messages. Consecutive keyed and unkeyed messages are produced in
+// separate batches so message order within a topic is preserved.
+func (k *KafkaFixture) Steps(bootstrapServer string) ([]Step, error) {
+	if bootstrapServer == "" {
+		bootstrapServer = defaultBootstrapServer
+	}
+
+	var steps []Step
+	for _, topic := range k.Topics {
+		create := []string{"kafka-topics", "--bootstrap-server", bootstrapServer, "--create", "--if-not-exists", "--topic", topic.Name}
+		if topic.Partitions > 0 {
+			create = append(create, "--partitions", strconv.Itoa(topic.Partitions))
+		}
+		if topic.ReplicationFactor > 0 {
+			create = append(create, "--replication-factor", strconv.Itoa(topic.ReplicationFactor))
+		}
+		for _, key := range sortedConfigKeys(topic.Config) {
+			create = append(create, "--config", key+"="+topic.Config[key])
+		}
+		steps = append(steps, Step{Command: create})
+
+		batches, err := messageBatches(topic)
+		if err != nil {
+			return nil, err
+		}
+		for _, batch := range batches {
+			produce := []string{"kafka-console-producer", "--bootstrap-server", bootstrapServer, "--topic", topic.Name}
+			if batch.keyed {
+				produce = append(produce, "--property", "parse.key=true", "--property", "key.separator="+kafkaKeySeparator)
+			}
+			steps = append(steps, Step{Command: produce, Stdin: batch.lines.String()})
+		}
+	}
+	return steps, nil
+}
+
+type messageBatch struct {
+	keyed bool
+	lines strings.Builder
+}
+
+func messageBatches(topic KafkaTopic) ([]*messageBatch, error) {
+	var batches []*messageBatch
+	for i, message := range topic.Messages {
+		value, err := encodeValue(message.Value)
+		if err != nil {
+			return nil, fmt.Errorf("topic %s message %d: %w", topic.Name, i+1, err)
+		}
+
+		keyed := message.Key != ""
+		if strings.Contains(message.Key, kafkaKeySeparator) || strings.ContainsAny(message.Key, "\r\n") {
+			return nil, fmt.Errorf("topic %s message %d: key must not contain tabs or newlines", topic.Name, i+1)
+		}
+
+		if len(batches) == 0 || batches[len(batches)-1].keyed != keyed {
+			batches = append(batches, &messageBatch{keyed: keyed})
+		}
+		batch := batches[len(batches)-1]
+		if keyed {
+			batch.lines.WriteString(message.Key + kafkaKeySeparator)
+		}
+		batch.lines.WriteString(value + "\n")
+	}
+	return batches, nil
+}
+
+// encodeValue renders a message value as a single line
+func encodeValue(value any) (string, error) {
+	var encoded string
+	switch v := value.(type) {
+	case nil:
+		return "", fmt.Errorf("value is required")
+	case string:
+		encoded = v
+	default:
+		data, err := json.Marshal(normalizeYAML(v))
+		if err != nil {
+			return "", fmt.Errorf("failed to encode value: %w", err)
+		}
+		encoded = string(data)
+	}
+
+	if strings.ContainsAny(encoded, "\r\n") {
+		return "", fmt.Errorf("value must fit on a single line")
+	}
+	return encoded, nil
+}
+
+// normalizeYAML converts map[any]any values, which encoding/json cannot
+// marshal, into map[string]any
+func normalizeYAML(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		for key, item := range v {
+			v[key] = normalizeYAML(item)
+		}
+		return v
+	case map[any]any:
+		result := make(map[string]any, len(v))
+		for key, item := range v {
+			result[fmt.Sprint(key)] = normalizeYAML(item)
+		}
+		return result
+	case []any:
+		for i, item := range v {
+			v[i] = normalizeYAML(item)
+		}
+		return v
+	default:
+		return v
+	}
+}
+
+func sortedConfigKeys(config map[string]string) []string {
+	keys := make([]string, 0, len(config))
+	for key := range config {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
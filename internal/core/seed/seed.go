@@ -0,0 +1,149 @@
+package seed
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Seed statuses
+const (
+	// StatusPending means the fixture has never been applied
+	StatusPending = "pending"
+	// StatusApplied means the fixture was applied before and is unchanged
+	StatusApplied = "applied"
+	// StatusChanged means the fixture was applied before but has since been edited
+	StatusChanged = "changed"
+	// StatusSeeded means the fixture was applied by this run
+	StatusSeeded = "seeded"
+)
+
+// checksumLength is the number of hex characters kept from a fixture's hash
+const checksumLength = 12
+
+// Fixture is a single seed file for a service
+type Fixture struct {
+	Service  string
+	Name     string
+	Path     string
+	Checksum string
+}
+
+// Entry pairs a fixture with its status against the service's markers
+type Entry struct {
+	Fixture
+	Status string
+}
+
+// Discover finds seed fixtures in dir. Each subdirectory is named after the
+// service it seeds and its files are applied in lexical order; hidden files
+// are ignored.
+func Discover(dir string) (map[string][]Fixture, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	fixtures := make(map[string][]Fixture)
+	for _, entry := range entries {
+		if !entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+
+		service := entry.Name()
+		files, err := os.ReadDir(filepath.Join(dir, service))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read seeds for %s: %w", service, err)
+		}
+
+		for _, file := range files {
+			if file.IsDir() || strings.HasPrefix(file.Name(), ".") {
+				continue
+			}
+
+			path := filepath.Join(dir, service, file.Name())
+			checksum, err := fileChecksum(path)
+			if err != nil {
+				return nil, err
+			}
+			fixtures[service] = append(fixtures[service], Fixture{
+				Service:  service,
+				Name:     file.Name(),
+				Path:     path,
+				Checksum: checksum,
+			})
+		}
+	}
+
+	for service := range fixtures {
+		sort.Slice(fixtures[service], func(i, j int) bool {
+			return fixtures[service][i].Name < fixtures[service][j].Name
+		})
+	}
+	return fixtures, nil
+}
+
+// Filter returns the fixtures whose extension is one of extensions, given
+// without the leading dot
+func Filter(fixtures []Fixture, extensions []string) []Fixture {
+	var result []Fixture
+	for _, fixture := range fixtures {
+		ext := strings.TrimPrefix(filepath.Ext(fixture.Name), ".")
+		if slices.Contains(extensions, strings.ToLower(ext)) {
+			result = append(result, fixture)
+		}
+	}
+	return result
+}
+
+// Plan compares fixtures against the markers recorded in the service and
+// returns each fixture's status
+func Plan(fixtures []Fixture, markers map[string]string) []Entry {
+	entries := make([]Entry, 0, len(fixtures))
+	for _, fixture := range fixtures {
+		status := StatusPending
+		if checksum, ok := markers[fixture.Name]; ok {
+			status = StatusApplied
+			if checksum != fixture.Checksum {
+				status = StatusChanged
+			}
+		}
+		entries = append(entries, Entry{Fixture: fixture, Status: status})
+	}
+	return entries
+}
+
+// ParseMarkers parses a marker file into a map of fixture name to the
+// checksum it had when applied. Later lines win, so re-applied fixtures
+// record their newest checksum.
+func ParseMarkers(data string) map[string]string {
+	markers := make(map[string]string)
+	for _, line := range strings.Split(data, "\n") {
+		fields := strings.Split(strings.TrimSpace(line), "\t")
+		if len(fields) < 2 || fields[0] == "" {
+			continue
+		}
+		markers[fields[0]] = fields[1]
+	}
+	return markers
+}
+
+// MarkerLine returns the marker file line recording that fixture was applied
+func MarkerLine(fixture Fixture, appliedAt time.Time) string {
+	return fmt.Sprintf("%s\t%s\t%s\n", fixture.Name, fixture.Checksum, appliedAt.UTC().Format(time.RFC3339))
+}
+
+func fileChecksum(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read seed %s: %w", path, err)
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])[:checksumLength], nil
+}
@@ -0,0 +1,134 @@
+package seed
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+}
+
+func TestDiscover(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "postgres", "002-users.sql"), "INSERT INTO users VALUES (1);")
+	writeFile(t, filepath.Join(dir, "postgres", "001-schema.sql"), "CREATE TABLE users (id int);")
+	writeFile(t, filepath.Join(dir, "postgres", ".DS_Store"), "")
+	writeFile(t, filepath.Join(dir, "redis", "cache.redis"), "SET greeting hello")
+	writeFile(t, filepath.Join(dir, "README.md"), "seeds")
+
+	fixtures, err := Discover(dir)
+	require.NoError(t, err)
+	assert.Len(t, fixtures, 2)
+
+	postgres := fixtures["postgres"]
+	require.Len(t, postgres, 2)
+	assert.Equal(t, "001-schema.sql", postgres[0].Name)
+	assert.Equal(t, "002-users.sql", postgres[1].Name)
+	assert.Equal(t, "postgres", postgres[0].Service)
+	assert.Len(t, postgres[0].Checksum, checksumLength)
+	assert.NotEqual(t, postgres[0].Checksum, postgres[1].Checksum)
+
+	_, err = Discover(filepath.Join(dir, "missing"))
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestFilter(t *testing.T) {
+	fixtures := []Fixture{{Name: "001.sql"}, {Name: "002.SQL"}, {Name: "notes.txt"}}
+	assert.Equal(t, []Fixture{{Name: "001.sql"}, {Name: "002.SQL"}}, Filter(fixtures, []string{"sql"}))
+	assert.Empty(t, Filter(fixtures, []string{"redis"}))
+}
+
+func TestPlanAndMarkers(t *testing.T) {
+	fixtures := []Fixture{
+		{Name: "001-schema.sql", Checksum: "aaa"},
+		{Name: "002-users.sql", Checksum: "bbb"},
+		{Name: "003-orders.sql", Checksum: "ccc"},
+	}
+
+	appliedAt := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	markerFile := MarkerLine(fixtures[0], appliedAt) +
+		MarkerLine(Fixture{Name: "002-users.sql", Checksum: "old"}, appliedAt) +
+		"\n" + "garbage\n"
+	assert.Contains(t, markerFile, "001-schema.sql\taaa\t2024-01-02T03:04:05Z\n")
+
+	markers := ParseMarkers(markerFile)
+	assert.Equal(t, map[string]string{"001-schema.sql": "aaa", "002-users.sql": "old"}, markers)
+
+	entries := Plan(fixtures, markers)
+	require.Len(t, entries, 3)
+	assert.Equal(t, StatusApplied, entries[0].Status)
+	assert.Equal(t, StatusChanged, entries[1].Status)
+	assert.Equal(t, StatusPending, entries[2].Status)
+
+	// A re-applied fixture appends a newer marker which wins
+	markers = ParseMarkers(markerFile + MarkerLine(fixtures[1], appliedAt))
+	assert.Equal(t, "bbb", markers["002-users.sql"])
+}
+
+func TestKafkaFixture_Steps(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "topics.yaml")
+	writeFile(t, path, `topics:
+  - name: orders
+    partitions: 3
+    replication_factor: 1
+    config:
+      retention.ms: "60000"
+    messages:
+      - key: order-1
+        value:
+          id: 1
+          items: [a, b]
+      - key: order-2
+        value: '{"id": 2}'
+      - value: heartbeat
+  - name: empty
+`)
+
+	fixture, err := LoadKafkaFixture(path)
+	require.NoError(t, err)
+
+	steps, err := fixture.Steps("")
+	require.NoError(t, err)
+	require.Len(t, steps, 4)
+
+	assert.Equal(t, []string{"kafka-topics", "--bootstrap-server", "localhost:9092", "--create", "--if-not-exists", "--topic", "orders", "--partitions", "3", "--replication-factor", "1", "--config", "retention.ms=60000"}, steps[0].Command)
+	assert.Empty(t, steps[0].Stdin)
+
+	assert.Contains(t, steps[1].Command, "parse.key=true")
+	assert.Equal(t, "order-1\t{\"id\":1,\"items\":[\"a\",\"b\"]}\norder-2\t{\"id\": 2}\n", steps[1].Stdin)
+
+	assert.NotContains(t, steps[2].Command, "parse.key=true")
+	assert.Equal(t, "heartbeat\n", steps[2].Stdin)
+
+	assert.Equal(t, []string{"kafka-topics", "--bootstrap-server", "localhost:9092", "--create", "--if-not-exists", "--topic", "empty"}, steps[3].Command)
+}
+
+func TestLoadKafkaFixture_Invalid(t *testing.T) {
+	tests := map[string]string{
+		"no topics":     `{"topics": []}`,
+		"unnamed topic": `{"topics": [{"partitions": 1}]}`,
+		"not a fixture": `[1, 2, 3]`,
+	}
+	for name, content := range tests {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "topics.json")
+			writeFile(t, path, content)
+			_, err := LoadKafkaFixture(path)
+			assert.Error(t, err)
+		})
+	}
+
+	t.Run("multi-line value", func(t *testing.T) {
+		fixture := &KafkaFixture{Topics: []KafkaTopic{{Name: "t", Messages: []KafkaMessage{{Value: "a\nb"}}}}}
+		_, err := fixture.Steps("")
+		assert.Error(t, err)
+	})
+}
@@ -21,37 +21,41 @@ func NewCleanupManager(manager *Manager) *CleanupManager {
 func (cm *CleanupManager) CleanupResources(ctx context.Context, options types.CleanupOptions) error {
 	cm.manager.logger.Info("Cleaning up resources", "volumes", options.RemoveVolumes, "images", options.RemoveImages)
 
-	projectName := cm.manager.getProjectName()
+	// A dry run is only planned, by the cleanup command
+	if options.DryRun {
+		cm.manager.logger.Info("Dry run: no resources removed")
+		return nil
+	}
 
-	// Stop and remove containers
-	if err := cm.manager.docker.Containers().Stop(ctx, projectName, []string{}, types.StopOptions{
-		Remove:        true,
-		RemoveVolumes: options.RemoveVolumes,
-	}); err != nil {
+	// Only resources labelled for the project are removed, never those of
+	// another compose project of the same name
+	owned, err := cm.manager.docker.OwnedResources(ctx, cm.manager.getProjectName())
+	if err != nil {
+		return err
+	}
+	if err := cm.manager.docker.RemoveOwned(ctx, *owned, options); err != nil {
 		return fmt.Errorf("failed to remove containers: %w", err)
 	}
 
-	// Remove volumes if requested
-	if options.RemoveVolumes {
-		if err := cm.manager.docker.Volumes().Remove(ctx, projectName); err != nil {
-			cm.manager.logger.Error("Failed to remove volumes", "error", err)
-		}
-	}
+	cm.manager.logger.Info("Cleanup completed successfully")
+	return nil
+}
 
-	// Remove images if requested
-	if options.RemoveImages {
-		if err := cm.manager.docker.Images().Remove(ctx, projectName); err != nil {
-			cm.manager.logger.Error("Failed to remove images", "error", err)
-		}
-	}
+// OrphanedResources returns the resources dev-stack created for projects
+// whose directory no longer exists
+func (cm *CleanupManager) OrphanedResources(ctx context.Context) ([]types.OwnedResources, error) {
+	return cm.manager.docker.OrphanedResources(ctx)
+}
 
-	// Remove networks if requested
-	if options.RemoveNetworks {
-		if err := cm.manager.docker.Networks().Remove(ctx, projectName); err != nil {
-			cm.manager.logger.Error("Failed to remove networks", "error", err)
+// RemoveOrphans removes the resources of orphaned projects: their
+// containers, volumes and networks, and their images when options request
+func (cm *CleanupManager) RemoveOrphans(ctx context.Context, orphans []types.OwnedResources, options types.CleanupOptions) error {
+	options.RemoveVolumes = true
+	options.RemoveNetworks = true
+	for _, orphan := range orphans {
+		if err := cm.manager.docker.RemoveOwned(ctx, orphan, options); err != nil {
+			return fmt.Errorf("failed to remove the resources of %s: %w", orphan.Project, err)
 		}
 	}
-
-	cm.manager.logger.Info("Cleanup completed successfully")
 	return nil
 }
@@ -0,0 +1,114 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/isaacgarza/dev-stack/internal/pkg/constants"
+	"github.com/isaacgarza/dev-stack/internal/pkg/types"
+)
+
+// CrashLoop describes a crash-looping service found by CheckCrashLoops
+type CrashLoop struct {
+	Service      string
+	RestartCount int
+	ExitCode     int
+	// Diagnostics is the file holding the service's last log lines, empty
+	// when they couldn't be saved
+	Diagnostics string
+	// Stopped is set when the service reached the maximum restarts and was
+	// stopped
+	Stopped bool
+}
+
+// String describes the crash loop for error messages
+func (c CrashLoop) String() string {
+	description := fmt.Sprintf("%s is crash-looping (restarted %d times, last exit code %d)", c.Service, c.RestartCount, c.ExitCode)
+	if c.Stopped {
+		description += ", stopped"
+	}
+	if c.Diagnostics != "" {
+		description += "; see " + c.Diagnostics
+	}
+	return description
+}
+
+// SetCrashLoopPolicy sets how crash-looping containers are detected and
+// handled
+func (m *Manager) SetCrashLoopPolicy(policy types.CrashLoopConfig) {
+	m.crashLoop = policy
+}
+
+// CheckCrashLoops finds the crash-looping services among serviceNames, or
+// among every service when none are given. The last log lines of each are
+// saved under dev-stack/logs, and services Docker restarted more than the
+// policy's maximum are stopped.
+func (m *Manager) CheckCrashLoops(ctx context.Context, serviceNames []string) ([]CrashLoop, error) {
+	statuses, err := m.GetServiceStatus(ctx, serviceNames)
+	if err != nil {
+		return nil, err
+	}
+	return m.handleCrashLoops(ctx, statuses), nil
+}
+
+// handleCrashLoops saves the diagnostics of the crash-looping statuses and
+// applies the maximum restarts, once per service
+func (m *Manager) handleCrashLoops(ctx context.Context, statuses []types.ServiceStatus) []CrashLoop {
+	policy := m.crashLoop.WithDefaults()
+	var loops []CrashLoop
+	seen := make(map[string]bool)
+	for _, status := range statuses {
+		if status.State != types.ServiceStateCrashLooping || seen[status.Name] {
+			continue
+		}
+		seen[status.Name] = true
+
+		loop := CrashLoop{Service: status.Name, RestartCount: status.RestartCount, ExitCode: status.ExitCode}
+		path, err := m.saveCrashDiagnostics(ctx, status, policy.LogLines)
+		if err != nil {
+			m.logger.Warn("Failed to save crash-loop diagnostics", "service", status.Name, "error", err)
+		}
+		loop.Diagnostics = path
+
+		if policy.MaxRestarts > 0 && status.RestartCount >= policy.MaxRestarts {
+			if err := m.docker.Containers().Stop(ctx, m.getProjectName(), []string{status.Name}, types.StopOptions{}); err != nil {
+				m.logger.Warn("Failed to stop crash-looping service", "service", status.Name, "error", err)
+			} else {
+				m.logger.Info("Stopped crash-looping service", "service", status.Name, "restarts", status.RestartCount)
+				loop.Stopped = true
+				m.statusCache.invalidate()
+			}
+		}
+		loops = append(loops, loop)
+	}
+	return loops
+}
+
+// saveCrashDiagnostics writes the restart count, exit code and last log
+// lines of a crash-looping service to a file under dev-stack/logs
+func (m *Manager) saveCrashDiagnostics(ctx context.Context, status types.ServiceStatus, logLines int) (string, error) {
+	var logs bytes.Buffer
+	options := types.LogOptions{Tail: strconv.Itoa(logLines), Timestamps: true, NoColor: true, NoPrefix: true}
+	if err := m.StreamLogs(ctx, []string{status.Name}, options, &logs); err != nil {
+		return "", err
+	}
+
+	logsDir := filepath.Join(constants.DevStackDir, constants.LogsDir)
+	if err := os.MkdirAll(logsDir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create logs directory: %w", err)
+	}
+	now := time.Now()
+	path := filepath.Join(logsDir, fmt.Sprintf("crash-loop-%s-%s.log", status.Name, now.Format("2006-01-02_15-04-05")))
+	content := fmt.Sprintf("Crash loop of %s - %s\n%s\nRestarts: %d\nLast exit code: %d\n\nLast %d log lines:\n%s",
+		status.Name, now.Format(time.RFC3339), strings.Repeat("=", 50), status.RestartCount, status.ExitCode, logLines, logs.String())
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		return "", fmt.Errorf("failed to write crash-loop diagnostics: %w", err)
+	}
+	return path, nil
+}
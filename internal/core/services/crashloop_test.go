@@ -0,0 +1,31 @@
+package services
+
+import (
+	"io"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/isaacgarza/dev-stack/internal/pkg/types"
+)
+
+func TestCrashLoop_String(t *testing.T) {
+	loop := CrashLoop{Service: "api", RestartCount: 5, ExitCode: 1}
+	assert.Equal(t, "api is crash-looping (restarted 5 times, last exit code 1)", loop.String())
+
+	loop.Stopped = true
+	loop.Diagnostics = "dev-stack/logs/crash-loop-api-2026-10-14_12-00-00.log"
+	assert.Equal(t, "api is crash-looping (restarted 5 times, last exit code 1), stopped; see dev-stack/logs/crash-loop-api-2026-10-14_12-00-00.log", loop.String())
+}
+
+func TestManager_HandleCrashLoopsWithoutCrashLoops(t *testing.T) {
+	m := &Manager{logger: slog.New(slog.NewTextHandler(io.Discard, nil)), projectName: "demo"}
+	m.SetCrashLoopPolicy(types.CrashLoopConfig{MaxRestarts: 5})
+
+	loops := m.handleCrashLoops(t.Context(), []types.ServiceStatus{
+		{Name: "api", State: types.ServiceStateRunning, RestartCount: 9},
+		{Name: "db", State: types.ServiceStateRestarting, RestartCount: 1},
+	})
+	assert.Empty(t, loops)
+}
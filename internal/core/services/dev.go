@@ -0,0 +1,88 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/isaacgarza/dev-stack/internal/core/watch"
+	"github.com/isaacgarza/dev-stack/internal/pkg/types"
+)
+
+// watchRestartTimeout is how many seconds a container may take to stop when
+// a watch rule restarts it
+const watchRestartTimeout = 10
+
+// ApplyWatchBatch applies a batch of file changes to its service: rebuilds
+// recreate the container from a fresh image, syncs copy files into the
+// running container and restarts restart it in place
+func (so *ServiceOperations) ApplyWatchBatch(ctx context.Context, batch watch.Batch) error {
+	projectName := so.manager.getProjectName()
+	containers := so.manager.docker.Containers()
+	so.manager.logger.Info("Applying file changes", "service", batch.Service, "action", batch.Action, "files", len(batch.Paths))
+
+	if batch.Action == types.WatchActionRebuild {
+		options := types.StartOptions{Build: true, NoDeps: true, Detach: true}
+		if err := containers.Start(ctx, projectName, []string{batch.Service}, options); err != nil {
+			return fmt.Errorf("failed to rebuild %s: %w", batch.Service, err)
+		}
+		return nil
+	}
+
+	if batch.NeedsSync() {
+		for _, sync := range batch.Syncs {
+			if err := so.syncFile(ctx, projectName, batch.Service, sync); err != nil {
+				return err
+			}
+		}
+	}
+
+	if batch.NeedsRestart() {
+		if err := containers.Restart(ctx, projectName, []string{batch.Service}, watchRestartTimeout); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// syncFile mirrors a single host file change into the container
+func (so *ServiceOperations) syncFile(ctx context.Context, projectName, serviceName string, sync watch.Sync) error {
+	containers := so.manager.docker.Containers()
+
+	if sync.Removed {
+		result, err := containers.ExecCapture(ctx, projectName, serviceName, []string{"rm", "-rf", sync.Target})
+		if err != nil {
+			return fmt.Errorf("failed to remove %s from %s: %w", sync.Target, serviceName, err)
+		}
+		if result.ExitCode != 0 {
+			return fmt.Errorf("failed to remove %s from %s: %s", sync.Target, serviceName, strings.TrimSpace(result.Stderr))
+		}
+		return nil
+	}
+
+	file, err := os.Open(sync.Source)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = file.Close() }()
+
+	info, err := file.Stat()
+	if err != nil {
+		return err
+	}
+
+	result, err := containers.ExecCapture(ctx, projectName, serviceName, []string{"mkdir", "-p", path.Dir(sync.Target)})
+	if err != nil {
+		return fmt.Errorf("failed to sync %s to %s: %w", sync.Source, serviceName, err)
+	}
+	if result.ExitCode != 0 {
+		return fmt.Errorf("failed to create %s in %s: %s", path.Dir(sync.Target), serviceName, strings.TrimSpace(result.Stderr))
+	}
+
+	if err := containers.CopyTo(ctx, projectName, serviceName, sync.Target, file, info.Size()); err != nil {
+		return fmt.Errorf("failed to sync %s to %s: %w", sync.Source, serviceName, err)
+	}
+	return nil
+}
@@ -0,0 +1,237 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/kballard/go-shellquote"
+
+	"github.com/isaacgarza/dev-stack/internal/pkg/types"
+)
+
+const (
+	// HookEnvVar is set to the running event for hook processes. Hooks are
+	// not run again by dev-stack commands invoked from a hook.
+	HookEnvVar = "DEV_STACK_HOOK"
+
+	// WorkflowEnvVar is set to the running workflow for its steps
+	WorkflowEnvVar = "DEV_STACK_WORKFLOW"
+
+	// defaultHookTimeout bounds a hook step without its own timeout
+	defaultHookTimeout = 5 * time.Minute
+
+	// hookWaitDelay is how long output pipes may stay open after a timed
+	// out step has been killed
+	hookWaitDelay = 2 * time.Second
+)
+
+// SetHooks configures the lifecycle hooks run by RunHooks and the
+// environment, typically service connection details, passed to every step
+func (m *Manager) SetHooks(hooks types.HooksConfig, env map[string]string) {
+	m.hooks = hooks
+	m.hookEnv = env
+}
+
+// RunHooks runs the steps configured for event in order. A failing step
+// aborts the remaining steps and returns an error unless its failure policy
+// is continue. Steps see the configured environment plus DEV_STACK_HOOK,
+// DEV_STACK_PROJECT, DEV_STACK_SERVICES and any extra variables. A nil
+// manager has no hooks.
+func (m *Manager) RunHooks(ctx context.Context, event string, serviceNames []string, extra map[string]string) error {
+	if m == nil {
+		return nil
+	}
+	steps := m.hooks[event]
+	if len(steps) == 0 {
+		return nil
+	}
+	if parent := os.Getenv(HookEnvVar); parent != "" {
+		m.logger.Debug("Skipping nested hooks", "event", event, "parent", parent)
+		return nil
+	}
+
+	env := os.Environ()
+	for _, values := range []map[string]string{m.hookEnv, extra, {
+		HookEnvVar:           event,
+		"DEV_STACK_PROJECT":  m.getProjectName(),
+		"DEV_STACK_SERVICES": strings.Join(serviceNames, ","),
+	}} {
+		env = appendEnv(env, values)
+	}
+
+	for i, step := range steps {
+		name := hookStepName(step, i)
+		m.logger.Info("Running hook", "event", event, "step", name)
+
+		if err := m.runHookStep(ctx, step, appendEnv(env, step.Env)); err != nil {
+			if step.OnFailure == types.HookFailureContinue {
+				m.logger.Warn("Hook failed, continuing", "event", event, "step", name, "error", err)
+				continue
+			}
+			return fmt.Errorf("%s hook %q failed: %w", event, name, err)
+		}
+	}
+	return nil
+}
+
+// RunWorkflow runs the steps of a workflow in order, with the environment
+// of the terminal plus env and DEV_STACK_WORKFLOW. Failures are handled as
+// for hooks. Unlike hook steps, the dev-stack commands of a workflow run
+// their own hooks.
+func (m *Manager) RunWorkflow(ctx context.Context, name string, steps []types.HookStep, env map[string]string) error {
+	base := appendEnv(os.Environ(), env)
+	base = appendEnv(base, map[string]string{WorkflowEnvVar: name})
+	for i, step := range steps {
+		stepName := hookStepName(step, i)
+		m.logger.Info("Running workflow step", "workflow", name, "step", stepName)
+
+		if err := m.runHookStep(ctx, step, appendEnv(base, step.Env)); err != nil {
+			if step.OnFailure == types.HookFailureContinue {
+				m.logger.Warn("Workflow step failed, continuing", "workflow", name, "step", stepName, "error", err)
+				continue
+			}
+			return fmt.Errorf("workflow %s step %q failed: %w", name, stepName, err)
+		}
+	}
+	return nil
+}
+
+// runHookStep runs a single step from the project directory, streaming its
+// output to the terminal. Steps may change the containers, such as the
+// dev-stack commands of a workflow, so the cached statuses are dropped.
+func (m *Manager) runHookStep(ctx context.Context, step types.HookStep, env []string) error {
+	defer m.statusCache.invalidate()
+
+	timeout := defaultHookTimeout
+	if step.Timeout != "" {
+		parsed, err := time.ParseDuration(step.Timeout)
+		if err != nil {
+			return fmt.Errorf("invalid timeout %q: %w", step.Timeout, err)
+		}
+		timeout = parsed
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	cmd, err := hookCommand(ctx, step)
+	if err != nil {
+		return err
+	}
+	cmd.Dir = m.projectDir
+	cmd.Env = env
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	cmd.WaitDelay = hookWaitDelay
+
+	if err := cmd.Run(); err != nil {
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return fmt.Errorf("timed out after %s", timeout)
+		}
+		return err
+	}
+	return nil
+}
+
+// hookCommand builds the process for a step: a shell for run steps and this
+// dev-stack binary for command steps
+func hookCommand(ctx context.Context, step types.HookStep) (*exec.Cmd, error) {
+	if step.Run != "" {
+		return exec.CommandContext(ctx, "sh", "-c", step.Run), nil
+	}
+
+	args, err := shellquote.Split(step.Command)
+	if err != nil {
+		return nil, fmt.Errorf("invalid command %q: %w", step.Command, err)
+	}
+	executable, err := os.Executable()
+	if err != nil {
+		return nil, fmt.Errorf("failed to locate dev-stack executable: %w", err)
+	}
+	return exec.CommandContext(ctx, executable, args...), nil
+}
+
+// ValidateHooks checks hook configuration for unknown events and malformed
+// steps so mistakes surface before any command runs
+func ValidateHooks(hooks types.HooksConfig) error {
+	events := make([]string, 0, len(hooks))
+	for event := range hooks {
+		events = append(events, event)
+	}
+	sort.Strings(events)
+
+	for _, event := range events {
+		if !slices.Contains(types.HookEvents(), event) {
+			return fmt.Errorf("unknown hook %q (supported: %s)", event, strings.Join(types.HookEvents(), ", "))
+		}
+
+		if err := validateSteps(event+" hook", hooks[event]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ValidateWorkflow checks the steps of a workflow as ValidateHooks checks
+// those of hooks
+func ValidateWorkflow(name string, steps []types.HookStep) error {
+	return validateSteps(name+" workflow step", steps)
+}
+
+// validateSteps checks that each step sets exactly one of run or command,
+// with a valid command line, timeout and failure policy. label names what
+// the steps belong to in errors, such as "post_up hook".
+func validateSteps(label string, steps []types.HookStep) error {
+	for i, step := range steps {
+		name := hookStepName(step, i)
+		if (step.Run == "") == (step.Command == "") {
+			return fmt.Errorf("%s %q must set exactly one of run or command", label, name)
+		}
+		if step.Command != "" {
+			if _, err := shellquote.Split(step.Command); err != nil {
+				return fmt.Errorf("%s %q has an invalid command: %w", label, name, err)
+			}
+		}
+		if step.Timeout != "" {
+			if _, err := time.ParseDuration(step.Timeout); err != nil {
+				return fmt.Errorf("%s %q has an invalid timeout %q", label, name, step.Timeout)
+			}
+		}
+		switch step.OnFailure {
+		case "", types.HookFailureAbort, types.HookFailureContinue:
+		default:
+			return fmt.Errorf("%s %q has an invalid on_failure %q (supported: %s, %s)", label, name, step.OnFailure, types.HookFailureAbort, types.HookFailureContinue)
+		}
+	}
+	return nil
+}
+
+// hookStepName returns the step's name, falling back to its position
+func hookStepName(step types.HookStep, index int) string {
+	if step.Name != "" {
+		return step.Name
+	}
+	return fmt.Sprintf("step %d", index+1)
+}
+
+// appendEnv appends values to env in a stable order; later entries override
+// earlier ones for exec
+func appendEnv(env []string, values map[string]string) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		env = append(env, key+"="+values[key])
+	}
+	return env
+}
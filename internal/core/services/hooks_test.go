@@ -0,0 +1,191 @@
+package services
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/isaacgarza/dev-stack/internal/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newHookManager(t *testing.T, hooks types.HooksConfig, env map[string]string) *Manager {
+	t.Helper()
+	t.Setenv(HookEnvVar, "")
+
+	m := &Manager{
+		logger:      slog.New(slog.NewTextHandler(io.Discard, nil)),
+		projectDir:  t.TempDir(),
+		projectName: "demo",
+	}
+	m.SetHooks(hooks, env)
+	return m
+}
+
+func TestValidateHooks(t *testing.T) {
+	tests := []struct {
+		name     string
+		hooks    types.HooksConfig
+		errorMsg string
+	}{
+		{
+			name: "valid hooks",
+			hooks: types.HooksConfig{
+				types.HookPostUp: {
+					{Name: "migrate", Run: "make migrate", Timeout: "2m"},
+					{Command: "seed postgres", OnFailure: types.HookFailureContinue},
+				},
+			},
+		},
+		{
+			name:     "unknown event",
+			hooks:    types.HooksConfig{"after_up": {{Run: "true"}}},
+			errorMsg: `unknown hook "after_up"`,
+		},
+		{
+			name:     "neither run nor command",
+			hooks:    types.HooksConfig{types.HookPreUp: {{Name: "empty"}}},
+			errorMsg: "must set exactly one of run or command",
+		},
+		{
+			name:     "both run and command",
+			hooks:    types.HooksConfig{types.HookPreUp: {{Run: "true", Command: "status"}}},
+			errorMsg: `pre_up hook "step 1" must set exactly one of run or command`,
+		},
+		{
+			name:     "invalid command quoting",
+			hooks:    types.HooksConfig{types.HookPreUp: {{Command: `seed "postgres`}}},
+			errorMsg: "invalid command",
+		},
+		{
+			name:     "invalid timeout",
+			hooks:    types.HooksConfig{types.HookPreDown: {{Run: "true", Timeout: "soon"}}},
+			errorMsg: `invalid timeout "soon"`,
+		},
+		{
+			name:     "invalid failure policy",
+			hooks:    types.HooksConfig{types.HookPreBackup: {{Run: "true", OnFailure: "ignore"}}},
+			errorMsg: `invalid on_failure "ignore"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateHooks(tt.hooks)
+			if tt.errorMsg == "" {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.errorMsg)
+		})
+	}
+}
+
+func TestRunHooks_Environment(t *testing.T) {
+	m := newHookManager(t, types.HooksConfig{
+		types.HookPostUp: {{
+			Run: `printf '%s|%s|%s|%s|%s|%s' "$DEV_STACK_HOOK" "$DEV_STACK_PROJECT" "$DEV_STACK_SERVICES" "$DATABASE_URL" "$EXTRA" "$STEP" > env.txt`,
+			Env: map[string]string{"STEP": "step"},
+		}},
+	}, map[string]string{"DATABASE_URL": "postgres://localhost/demo"})
+
+	err := m.RunHooks(context.Background(), types.HookPostUp, []string{"postgres", "redis"}, map[string]string{"EXTRA": "extra"})
+	require.NoError(t, err)
+
+	data, err := os.ReadFile(filepath.Join(m.projectDir, "env.txt"))
+	require.NoError(t, err)
+	assert.Equal(t, "post_up|demo|postgres,redis|postgres://localhost/demo|extra|step", string(data))
+}
+
+func TestRunHooks_Failures(t *testing.T) {
+	tests := []struct {
+		name     string
+		steps    []types.HookStep
+		errorMsg string
+		ran      bool
+	}{
+		{
+			name:     "failing step aborts",
+			steps:    []types.HookStep{{Name: "fail", Run: "exit 3"}, {Run: "touch ran"}},
+			errorMsg: `pre_up hook "fail" failed`,
+		},
+		{
+			name:  "failing step continues",
+			steps: []types.HookStep{{Run: "exit 3", OnFailure: types.HookFailureContinue}, {Run: "touch ran"}},
+			ran:   true,
+		},
+		{
+			name:     "step times out",
+			steps:    []types.HookStep{{Name: "slow", Run: "sleep 5", Timeout: "100ms"}, {Run: "touch ran"}},
+			errorMsg: "timed out after 100ms",
+		},
+		{
+			name:  "no steps for event",
+			steps: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := newHookManager(t, types.HooksConfig{types.HookPreUp: tt.steps}, nil)
+
+			err := m.RunHooks(context.Background(), types.HookPreUp, nil, nil)
+			if tt.errorMsg == "" {
+				assert.NoError(t, err)
+			} else {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.errorMsg)
+			}
+
+			_, statErr := os.Stat(filepath.Join(m.projectDir, "ran"))
+			assert.Equal(t, tt.ran, statErr == nil)
+		})
+	}
+}
+
+func TestRunHooks_SkipsNested(t *testing.T) {
+	m := newHookManager(t, types.HooksConfig{types.HookPreUp: {{Run: "touch ran"}}}, nil)
+	t.Setenv(HookEnvVar, types.HookPostUp)
+
+	require.NoError(t, m.RunHooks(context.Background(), types.HookPreUp, nil, nil))
+
+	_, err := os.Stat(filepath.Join(m.projectDir, "ran"))
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestRunHooks_NilManager(t *testing.T) {
+	var m *Manager
+	assert.NoError(t, m.RunHooks(context.Background(), types.HookPreUp, nil, nil))
+}
+
+func TestValidateWorkflow(t *testing.T) {
+	assert.NoError(t, ValidateWorkflow("reset-db", []types.HookStep{{Command: "down"}, {Run: "echo up"}}))
+
+	err := ValidateWorkflow("reset-db", []types.HookStep{{Name: "both", Run: "true", Command: "down"}})
+	assert.EqualError(t, err, `reset-db workflow step "both" must set exactly one of run or command`)
+}
+
+func TestRunWorkflow(t *testing.T) {
+	m := newHookManager(t, nil, nil)
+
+	steps := []types.HookStep{
+		{Run: "exit 3", OnFailure: types.HookFailureContinue},
+		{Run: `printf '%s|%s|%s' "$DEV_STACK_WORKFLOW" "$DEV_STACK_HOOK" "$DATABASE" > env.txt`},
+		{Name: "fail", Run: "exit 3"},
+		{Run: "touch ran"},
+	}
+	err := m.RunWorkflow(context.Background(), "reset-db", steps, map[string]string{"DATABASE": "orders"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `workflow reset-db step "fail" failed`)
+
+	data, err := os.ReadFile(filepath.Join(m.projectDir, "env.txt"))
+	require.NoError(t, err)
+	assert.Equal(t, "reset-db||orders", string(data))
+	_, err = os.Stat(filepath.Join(m.projectDir, "ran"))
+	assert.True(t, os.IsNotExist(err))
+}
@@ -3,6 +3,7 @@ package services
 import (
 	"context"
 	"fmt"
+	"io"
 	"log/slog"
 	"os"
 	"path/filepath"
@@ -10,6 +11,8 @@ import (
 	"time"
 
 	"github.com/isaacgarza/dev-stack/internal/core/docker"
+	"github.com/isaacgarza/dev-stack/internal/core/seed"
+	"github.com/isaacgarza/dev-stack/internal/core/watch"
 	"github.com/isaacgarza/dev-stack/internal/pkg/constants"
 	"github.com/isaacgarza/dev-stack/internal/pkg/types"
 	"gopkg.in/yaml.v3"
@@ -17,27 +20,35 @@ import (
 
 // Manager provides high-level service management operations
 type Manager struct {
-	docker     *docker.Client
-	logger     *slog.Logger
-	projectDir string
-	config     *types.Config
+	docker      *docker.Client
+	logger      *slog.Logger
+	projectDir  string
+	projectName string
+	config      *types.Config
+	hooks       types.HooksConfig
+	hookEnv     map[string]string
+	shutdown    map[string]types.ShutdownConfig
+	crashLoop   types.CrashLoopConfig
+	statusCache statusCache
 
 	// Sub-managers
 	operations *ServiceOperations
 	cleanup    *CleanupManager
 }
 
-// NewManager creates a new service manager instance
+// NewManager creates a new service manager instance on the shared Docker
+// connection of the process
 func NewManager(logger *slog.Logger, projectDir string) (*Manager, error) {
-	dockerClient, err := docker.NewClient(logger)
+	dockerClient, err := docker.Shared(logger)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create Docker client: %w", err)
 	}
 
 	manager := &Manager{
-		docker:     dockerClient,
-		logger:     logger,
-		projectDir: projectDir,
+		docker:      dockerClient,
+		logger:      logger,
+		projectDir:  projectDir,
+		statusCache: statusCache{ttl: StatusCacheTTL},
 	}
 
 	// Initialize sub-managers
@@ -52,6 +63,12 @@ func (m *Manager) SetConfig(config *types.Config) {
 	m.config = config
 }
 
+// SetProjectName sets the compose project name used to scope operations,
+// overriding the name derived from the project directory
+func (m *Manager) SetProjectName(name string) {
+	m.projectName = name
+}
+
 // Close closes the service manager and its resources
 func (m *Manager) Close() error {
 	return m.docker.Close()
@@ -61,6 +78,8 @@ func (m *Manager) Close() error {
 
 // StartServices starts the specified services or all services if none specified
 func (m *Manager) StartServices(ctx context.Context, serviceNames []string, options types.StartOptions) error {
+	defer m.statusCache.invalidate()
+
 	m.logger.Info("Starting services", "services", serviceNames, "detach", options.Detach)
 
 	projectName := m.getProjectName()
@@ -93,12 +112,42 @@ func (m *Manager) StartServices(ctx context.Context, serviceNames []string, opti
 	return nil
 }
 
-// StopServices stops the specified services or all services if none specified
+// ResumeServices resumes the paused containers of the specified services,
+// or of every service, returning the services resumed
+func (m *Manager) ResumeServices(ctx context.Context, serviceNames []string) ([]string, error) {
+	defer m.statusCache.invalidate()
+	return m.docker.Containers().Unpause(ctx, m.getProjectName(), serviceNames)
+}
+
+// SetShutdown configures the grace periods and pre-stop commands
+// StopServices applies, by service name
+func (m *Manager) SetShutdown(settings map[string]types.ShutdownConfig) {
+	m.shutdown = settings
+}
+
+// StopServices stops the specified services or all services if none
+// specified, in reverse dependency order after their pre-stop commands.
+// Services still running once their grace period, or options.Timeout, is
+// over are killed.
 func (m *Manager) StopServices(ctx context.Context, serviceNames []string, options types.StopOptions) error {
+	defer m.statusCache.invalidate()
+
 	m.logger.Info("Stopping services", "services", serviceNames, "timeout", options.Timeout)
 
 	projectName := m.getProjectName()
 
+	report, err := m.docker.Containers().Shutdown(ctx, projectName, serviceNames, types.ShutdownOptions{
+		Timeout:  options.Timeout,
+		Services: m.shutdown,
+		Force:    true,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to stop services: %w", err)
+	}
+	if killed := report.Named(types.ShutdownKilled); len(killed) > 0 {
+		m.logger.Warn("Services were killed after their grace period", "services", killed)
+	}
+
 	if err := m.docker.Containers().Stop(ctx, projectName, serviceNames, options); err != nil {
 		return fmt.Errorf("failed to stop services: %w", err)
 	}
@@ -110,6 +159,10 @@ func (m *Manager) StopServices(ctx context.Context, serviceNames []string, optio
 // GetServiceStatus returns the status of all services or specified services
 func (m *Manager) GetServiceStatus(ctx context.Context, serviceNames []string) ([]types.ServiceStatus, error) {
 	projectName := m.getProjectName()
+	key := statusKey(projectName, serviceNames)
+	if services, ok := m.statusCache.get(key, time.Now()); ok {
+		return services, nil
+	}
 
 	services, err := m.docker.Containers().List(ctx, projectName, serviceNames)
 	if err != nil {
@@ -122,6 +175,8 @@ func (m *Manager) GetServiceStatus(ctx context.Context, serviceNames []string) (
 			services[i].Uptime = time.Since(*services[i].StartedAt)
 		}
 	}
+	m.crashLoop.MarkCrashLoops(services, time.Now())
+	m.statusCache.put(key, services, time.Now())
 
 	return services, nil
 }
@@ -148,6 +203,31 @@ func (m *Manager) GetLogs(ctx context.Context, serviceNames []string, options ty
 	return nil
 }
 
+// StreamLogs retrieves logs from services, writing them to w instead of the
+// terminal
+func (m *Manager) StreamLogs(ctx context.Context, serviceNames []string, options types.LogOptions, w io.Writer) error {
+	projectName := m.getProjectName()
+
+	if err := m.docker.Containers().StreamLogs(ctx, projectName, serviceNames, options, w, w); err != nil {
+		return fmt.Errorf("failed to get logs: %w", err)
+	}
+
+	return nil
+}
+
+// ExecCapture runs a non-interactive command in a service container and
+// returns its exit code and output
+func (m *Manager) ExecCapture(ctx context.Context, serviceName string, cmd []string) (*types.ExecResult, error) {
+	projectName := m.getProjectName()
+
+	result, err := m.docker.Containers().ExecCapture(ctx, projectName, serviceName, cmd)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute command in %s: %w", serviceName, err)
+	}
+
+	return result, nil
+}
+
 // Service operations (delegated to operations manager)
 
 // ConnectToService provides convenient connection to services
@@ -155,18 +235,59 @@ func (m *Manager) ConnectToService(ctx context.Context, serviceName string, opti
 	return m.operations.ConnectToService(ctx, serviceName, options)
 }
 
-// BackupService creates a backup of service data
-func (m *Manager) BackupService(ctx context.Context, serviceName, backupName string, options types.BackupOptions) error {
+// ConnectionInfo resolves how to connect to a running service
+func (m *Manager) ConnectionInfo(ctx context.Context, serviceName string, options types.ConnectOptions) (*types.ConnectionInfo, error) {
+	return m.operations.ConnectionInfo(ctx, serviceName, options)
+}
+
+// BackupService creates a backup of service data and returns the backup file path
+func (m *Manager) BackupService(ctx context.Context, serviceName, backupName string, options types.BackupOptions) (string, error) {
 	return m.operations.BackupService(ctx, serviceName, backupName, options)
 }
 
 // RestoreService restores service data from a backup
 func (m *Manager) RestoreService(ctx context.Context, serviceName, backupFile string, options types.RestoreOptions) error {
+	defer m.statusCache.invalidate()
 	return m.operations.RestoreService(ctx, serviceName, backupFile, options)
 }
 
+// ResolveVolumes returns the volumes a service mounts, or the volume named
+// target
+func (m *Manager) ResolveVolumes(ctx context.Context, target string) ([]string, error) {
+	return m.operations.ResolveVolumes(ctx, target)
+}
+
+// BackupVolume archives the contents of a volume and returns the backup
+// file path
+func (m *Manager) BackupVolume(ctx context.Context, volumeName, backupName string, options types.VolumeBackupOptions) (string, error) {
+	return m.operations.BackupVolume(ctx, volumeName, backupName, options)
+}
+
+// RestoreVolume replaces the contents of a volume with a volume backup
+func (m *Manager) RestoreVolume(ctx context.Context, volumeName, backupFile string, options types.VolumeBackupOptions) error {
+	return m.operations.RestoreVolume(ctx, volumeName, backupFile, options)
+}
+
+// TestRestore restores a backup into a throwaway copy of a service and
+// returns how long the restore took
+func (m *Manager) TestRestore(ctx context.Context, serviceName, backupFile string, options types.RestoreOptions) (time.Duration, error) {
+	return m.operations.TestRestore(ctx, serviceName, backupFile, options)
+}
+
+// SeedService applies seed fixtures to a service and returns their statuses
+func (m *Manager) SeedService(ctx context.Context, serviceName string, fixtures []seed.Fixture, options types.SeedOptions) ([]seed.Entry, error) {
+	return m.operations.SeedService(ctx, serviceName, fixtures, options)
+}
+
+// ApplyWatchBatch applies a batch of watched file changes to its service
+func (m *Manager) ApplyWatchBatch(ctx context.Context, batch watch.Batch) error {
+	defer m.statusCache.invalidate()
+	return m.operations.ApplyWatchBatch(ctx, batch)
+}
+
 // ScaleService scales a service to the specified number of replicas
 func (m *Manager) ScaleService(ctx context.Context, serviceName string, replicas int, options types.ScaleOptions) error {
+	defer m.statusCache.invalidate()
 	return m.operations.ScaleService(ctx, serviceName, replicas, options)
 }
 
@@ -174,12 +295,27 @@ func (m *Manager) ScaleService(ctx context.Context, serviceName string, replicas
 
 // CleanupResources removes project resources
 func (m *Manager) CleanupResources(ctx context.Context, options types.CleanupOptions) error {
+	defer m.statusCache.invalidate()
 	return m.cleanup.CleanupResources(ctx, options)
 }
 
+// OrphanedResources returns the resources of projects whose directory is gone
+func (m *Manager) OrphanedResources(ctx context.Context) ([]types.OwnedResources, error) {
+	return m.cleanup.OrphanedResources(ctx)
+}
+
+// RemoveOrphans removes the resources of orphaned projects
+func (m *Manager) RemoveOrphans(ctx context.Context, orphans []types.OwnedResources, options types.CleanupOptions) error {
+	defer m.statusCache.invalidate()
+	return m.cleanup.RemoveOrphans(ctx, orphans, options)
+}
+
 // Helper methods (package-private for sub-managers)
 
 func (m *Manager) getProjectName() string {
+	if m.projectName != "" {
+		return m.projectName
+	}
 	if m.config != nil && m.config.Global.DefaultProjectType != "" {
 		return m.config.Global.DefaultProjectType
 	}
@@ -217,7 +353,7 @@ func (m *Manager) validateServices(serviceNames []string) error {
 			for serviceName := range services {
 				availableServices = append(availableServices, serviceName)
 			}
-			return fmt.Errorf("unknown service '%s'. Available services: %v", name, availableServices)
+			return &types.ServiceNotFoundError{Service: name, Available: availableServices}
 		}
 	}
 	return nil
@@ -259,6 +395,11 @@ func (m *Manager) waitForHealthy(ctx context.Context, projectName string, servic
 				continue
 			}
 
+			// A crash-looping service won't become healthy by waiting longer
+			if loops := m.handleCrashLoops(ctx, statuses); len(loops) > 0 {
+				return fmt.Errorf("%s", loops[0])
+			}
+
 			for _, status := range statuses {
 				if status.State != constants.StateRunning || (status.Health != constants.HealthHealthy && status.Health != "") {
 					allHealthy = false
@@ -1,9 +1,11 @@
 package services
 
 import (
+	"context"
 	"log/slog"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/isaacgarza/dev-stack/internal/pkg/types"
 	"github.com/stretchr/testify/assert"
@@ -204,3 +206,38 @@ func TestManager_SubManagers(t *testing.T) {
 		assert.NotNil(t, manager.cleanup)
 	})
 }
+
+func TestManager_StatusCache(t *testing.T) {
+	t.Setenv("DOCKER_HOST", "unix:///nonexistent/docker.sock")
+	manager, err := NewManager(slog.New(slog.NewTextHandler(os.Stdout, nil)), t.TempDir())
+	require.NoError(t, err)
+	manager.SetProjectName("shop")
+	ctx := context.Background()
+
+	// Answers read within the TTL are reused, whatever the order of the
+	// services asked for
+	cached := []types.ServiceStatus{{Name: "postgres", State: types.ServiceStateRunning}, {Name: "redis", State: types.ServiceStateRunning}}
+	manager.statusCache.put(statusKey("shop", []string{"redis", "postgres"}), cached, time.Now())
+	statuses, err := manager.GetServiceStatus(ctx, []string{"postgres", "redis"})
+	require.NoError(t, err)
+	assert.Equal(t, cached, statuses)
+
+	// Changing the answer doesn't change the cache
+	statuses[0].State = types.ServiceStateStopped
+	statuses, err = manager.GetServiceStatus(ctx, []string{"postgres", "redis"})
+	require.NoError(t, err)
+	assert.Equal(t, types.ServiceStateRunning, statuses[0].State)
+
+	// Lifecycle operations drop the cache, even when they fail
+	_ = manager.StopServices(ctx, []string{"redis"}, types.StopOptions{})
+	_, err = manager.GetServiceStatus(ctx, []string{"postgres", "redis"})
+	assert.Error(t, err)
+
+	_, ok := (&statusCache{ttl: time.Second, entries: map[string]statusEntry{"k": {readAt: time.Now().Add(-time.Second)}}}).get("k", time.Now())
+	assert.False(t, ok, "expired")
+
+	manager.SetStatusCacheTTL(0)
+	manager.statusCache.put(statusKey("shop", nil), cached, time.Now())
+	_, err = manager.GetServiceStatus(ctx, nil)
+	assert.Error(t, err, "caching disabled")
+}
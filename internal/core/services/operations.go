@@ -2,12 +2,16 @@ package services
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"os"
+	"os/exec"
+	"os/signal"
 	"path/filepath"
-	"strings"
 	"time"
 
+	"github.com/isaacgarza/dev-stack/internal/core/backup"
 	"github.com/isaacgarza/dev-stack/internal/pkg/services"
 	"github.com/isaacgarza/dev-stack/internal/pkg/types"
 )
@@ -22,50 +26,168 @@ func NewServiceOperations(manager *Manager) *ServiceOperations {
 	return &ServiceOperations{manager: manager}
 }
 
-// ConnectToService provides convenient connection to services using dynamic configuration
+// ConnectToService runs the service's client attached to the terminal,
+// with the settings the container was started with. Clients run in the
+// container unless the service prefers one installed on the host.
 func (so *ServiceOperations) ConnectToService(ctx context.Context, serviceName string, options types.ConnectOptions) error {
+	info, err := so.ConnectionInfo(ctx, serviceName, options)
+	if err != nil {
+		return err
+	}
+
+	if info.Host {
+		return runHostClient(ctx, info)
+	}
+
+	execOptions := types.ExecOptions{
+		Interactive: true,
+		TTY:         true,
+		Env:         info.Env,
+	}
+
+	if err := so.manager.docker.Containers().Exec(ctx, so.manager.getProjectName(), serviceName, info.Command, execOptions); err != nil {
+		return fmt.Errorf("failed to connect to %s: %w", serviceName, err)
+	}
+
+	return nil
+}
+
+// ConnectionInfo resolves how to connect to a running service. Settings
+// such as the user and password are read from the container's environment,
+// falling back to options.Env, and host clients, the connection string and
+// the web interface use the host ports the service is actually published
+// on.
+func (so *ServiceOperations) ConnectionInfo(ctx context.Context, serviceName string, options types.ConnectOptions) (*types.ConnectionInfo, error) {
 	projectName := so.manager.getProjectName()
 
-	// Load service operations
 	ops, err := services.LoadServiceOperations(serviceName)
 	if err != nil {
-		return fmt.Errorf("failed to load service operations for %s: %w", serviceName, err)
+		return nil, fmt.Errorf("failed to load service operations for %s: %w", serviceName, err)
+	}
+	if ops == nil || ops.Connect == nil {
+		return nil, fmt.Errorf("no connect operation defined for service %s", serviceName)
 	}
+	connect := ops.Connect
 
-	if ops.Connect == nil {
-		return fmt.Errorf("no connect operation defined for service %s", serviceName)
+	containerEnv, err := so.manager.docker.Containers().Env(ctx, projectName, serviceName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the environment of %s: %w", serviceName, err)
+	}
+	lookup := func(name string) (string, bool) {
+		if value, ok := containerEnv[name]; ok {
+			return value, true
+		}
+		value, ok := options.Env[name]
+		return value, ok
 	}
 
-	// Build connection parameters
-	params := map[string]string{
+	params := connect.Params(map[string]string{
 		"user":     options.User,
 		"database": options.Database,
 		"host":     options.Host,
 		"port":     options.Port,
+		"topic":    options.Topic,
+		"path":     options.Path,
+	}, lookup)
+
+	ports, err := so.publishedPorts(ctx, serviceName)
+	if err != nil {
+		return nil, err
+	}
+	if hostPort := ports[connect.Port]; connect.Port != "" && hostPort != "" {
+		params["hostPort"] = hostPort
 	}
 
-	// Build command
-	cmd := ops.Connect.BuildCommand(params)
-	if len(cmd) == 0 {
-		return fmt.Errorf("failed to build connect command for %s", serviceName)
+	info := &types.ConnectionInfo{Client: services.ConnectClientContainer}
+	if params["hostPort"] != "" {
+		info.URL = connect.BuildURL(params)
+	}
+	if connect.UI != nil && ports[connect.UI.Port] != "" {
+		info.UIURL = connect.BuildUIURL(params, ports[connect.UI.Port])
 	}
 
-	// Execute the connection command
-	execOptions := types.ExecOptions{
-		Interactive: true,
-		TTY:         true,
-		User:        options.User,
+	client, err := connect.SelectClient(options.Client, params, isInstalled)
+	if err != nil {
+		return nil, fmt.Errorf("failed to select a client for %s: %w", serviceName, err)
+	}
+	if client != nil {
+		if client.Host && params["hostPort"] == "" && connect.Port != "" {
+			return nil, fmt.Errorf("port %s of %s is not published", connect.Port, serviceName)
+		}
+		info.Client = client.Name
+		info.Host = client.Host
+		info.Command = client.BuildCommand(params)
+		info.Env = client.BuildEnv(params)
+	} else {
+		info.Command = connect.BuildCommand(params)
+		info.Env = connect.BuildEnv(params)
 	}
 
-	if err := so.manager.docker.Containers().Exec(ctx, projectName, serviceName, cmd, execOptions); err != nil {
-		return fmt.Errorf("failed to connect to %s: %w", serviceName, err)
+	if len(info.Command) == 0 {
+		return nil, fmt.Errorf("failed to build connect command for %s", serviceName)
+	}
+	return info, nil
+}
+
+// publishedPorts maps the container ports of a service to the host ports
+// they are published on, which differ from the defaults when a port was
+// remapped to avoid a conflict
+func (so *ServiceOperations) publishedPorts(ctx context.Context, serviceName string) (map[string]string, error) {
+	statuses, err := so.manager.GetServiceStatus(ctx, []string{serviceName})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get status of %s: %w", serviceName, err)
 	}
 
+	ports := make(map[string]string)
+	for _, status := range statuses {
+		if status.Name != serviceName {
+			continue
+		}
+		for _, port := range status.Ports {
+			if port.Host != "" {
+				ports[port.Container] = port.Host
+			}
+		}
+	}
+	return ports, nil
+}
+
+// isInstalled reports whether an executable is on the PATH
+func isInstalled(name string) bool {
+	_, err := exec.LookPath(name)
+	return err == nil
+}
+
+// runHostClient runs a client installed on the host attached to the
+// terminal. Interrupts are left to the client, which shares the terminal,
+// instead of stopping dev-stack or cancelling ctx while it runs.
+func runHostClient(ctx context.Context, info *types.ConnectionInfo) error {
+	interrupts := make(chan os.Signal, 1)
+	signal.Notify(interrupts, os.Interrupt)
+	defer signal.Stop(interrupts)
+
+	cmd := exec.CommandContext(context.WithoutCancel(ctx), info.Command[0], info.Command[1:]...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	cmd.Env = append(os.Environ(), info.Env...)
+
+	err := cmd.Run()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && exitErr.ExitCode() > 0 {
+		return &types.ExitError{Command: info.Command[0], Code: exitErr.ExitCode()}
+	}
+	if err != nil {
+		return fmt.Errorf("failed to run %s: %w", info.Command[0], err)
+	}
 	return nil
 }
 
-// BackupService creates a backup of service data using dynamic configuration
-func (so *ServiceOperations) BackupService(ctx context.Context, serviceName, backupName string, options types.BackupOptions) error {
+// BackupService creates a backup of service data using dynamic configuration.
+// The dump is streamed out of the container into the backup directory,
+// compressed on the way if requested, and the path of the written file is
+// returned.
+func (so *ServiceOperations) BackupService(ctx context.Context, serviceName, backupName string, options types.BackupOptions) (string, error) {
 	so.manager.logger.Info("Creating backup", "service", serviceName, "backup", backupName)
 
 	projectName := so.manager.getProjectName()
@@ -76,148 +198,175 @@ func (so *ServiceOperations) BackupService(ctx context.Context, serviceName, bac
 
 	// Ensure backup directory exists
 	if err := os.MkdirAll(backupDir, 0755); err != nil {
-		return fmt.Errorf("failed to create backup directory: %w", err)
+		return "", fmt.Errorf("failed to create backup directory: %w", err)
 	}
 
 	// Load service operations
 	ops, err := services.LoadServiceOperations(serviceName)
 	if err != nil {
-		return fmt.Errorf("failed to load service operations for %s: %w", serviceName, err)
+		return "", fmt.Errorf("failed to load service operations for %s: %w", serviceName, err)
 	}
 
-	if ops.Backup == nil {
-		return fmt.Errorf("no backup operation defined for service %s", serviceName)
+	if ops == nil || ops.Backup == nil {
+		return "", fmt.Errorf("no backup operation defined for service %s", serviceName)
 	}
 
 	// Build backup file path
 	extension := ops.Backup.GetBackupExtension()
-	backupPath := filepath.Join(backupDir, fmt.Sprintf("%s.%s", backupName, extension))
+	backupPath := filepath.Join(backupDir, fmt.Sprintf("%s.%s%s", backupName, extension, backup.Extension(options.Compress)))
 
 	// Build backup parameters
 	params := map[string]string{
-		"database":   options.Database,
-		"user":       options.User,
-		"timestamp":  time.Now().Format("20060102_150405"),
-		"backupFile": backupPath,
+		"database":  options.Database,
+		"user":      options.User,
+		"timestamp": time.Now().Format("20060102_150405"),
 	}
 
 	// Build and execute backup commands
 	commands, err := ops.Backup.BuildCommand(params)
 	if err != nil {
-		return fmt.Errorf("failed to build backup command for %s: %w", serviceName, err)
+		return "", fmt.Errorf("failed to build backup command for %s: %w", serviceName, err)
+	}
+	if len(commands) == 0 && ops.Backup.File == "" {
+		return "", fmt.Errorf("backup operation for %s has no command", serviceName)
 	}
 
 	execOptions := types.ExecOptions{
 		User: options.User,
 	}
 
-	for _, cmd := range commands {
-		if err := so.manager.docker.Containers().Exec(ctx, projectName, serviceName, cmd, execOptions); err != nil {
+	file, err := os.Create(backupPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to create backup file: %w", err)
+	}
+
+	if err := so.streamBackup(ctx, projectName, serviceName, ops.Backup, commands, file, options.Compress, execOptions); err != nil {
+		_ = file.Close()
+		_ = os.Remove(backupPath)
+		return "", err
+	}
+	if err := file.Close(); err != nil {
+		_ = os.Remove(backupPath)
+		return "", fmt.Errorf("failed to write backup file: %w", err)
+	}
+
+	so.manager.logger.Info("Backup created successfully", "service", serviceName, "backup", backupPath)
+	return backupPath, nil
+}
+
+// streamBackup runs the backup commands and writes the dump to w. The last
+// command's stdout is the dump unless the operation names a file, or with
+// archive a directory, to copy out of the container afterwards.
+func (so *ServiceOperations) streamBackup(ctx context.Context, projectName, serviceName string, op *services.BackupOperation, commands [][]string, w io.Writer, compression string, execOptions types.ExecOptions) error {
+	compressor, err := backup.NewCompressor(w, compression)
+	if err != nil {
+		return err
+	}
+
+	containers := so.manager.docker.Containers()
+	for i, cmd := range commands {
+		output := io.Discard
+		if op.File == "" && i == len(commands)-1 {
+			output = compressor
+		}
+		if err := containers.ExecStream(ctx, projectName, serviceName, cmd, output, execOptions); err != nil {
+			_ = compressor.Close()
 			return fmt.Errorf("failed to execute backup command for %s: %w", serviceName, err)
 		}
 	}
 
-	so.manager.logger.Info("Backup created successfully", "service", serviceName, "backup", backupPath)
+	if op.File != "" {
+		copyFrom := containers.CopyFrom
+		if op.Archive {
+			copyFrom = containers.CopyArchiveFrom
+		}
+		if err := copyFrom(ctx, projectName, serviceName, op.File, compressor); err != nil {
+			_ = compressor.Close()
+			return fmt.Errorf("failed to copy backup of %s: %w", serviceName, err)
+		}
+	}
+
+	if err := compressor.Close(); err != nil {
+		return fmt.Errorf("failed to compress backup of %s: %w", serviceName, err)
+	}
 	return nil
 }
 
-// RestoreService restores service data from a backup using dynamic configuration
+// RestoreService restores service data from a backup using dynamic
+// configuration. The backup is decompressed on the fly and either piped to
+// the restore command or copied into the container, depending on the
+// service's restore operation.
 func (so *ServiceOperations) RestoreService(ctx context.Context, serviceName, backupFile string, options types.RestoreOptions) error {
 	so.manager.logger.Info("Restoring from backup", "service", serviceName, "backup", backupFile)
 
-	projectName := so.manager.getProjectName()
-
-	// Validate backup file exists
-	if _, err := os.Stat(backupFile); os.IsNotExist(err) {
-		return fmt.Errorf("backup file not found: %s", backupFile)
-	}
-
-	// Load service operations
-	ops, err := services.LoadServiceOperations(serviceName)
+	op, err := so.restoreOperation(serviceName, backupFile)
 	if err != nil {
-		return fmt.Errorf("failed to load service operations for %s: %w", serviceName, err)
+		return err
 	}
 
-	if ops.Restore == nil {
-		return fmt.Errorf("no restore operation defined for service %s", serviceName)
+	target := &liveRestoreTarget{
+		manager:     so.manager,
+		projectName: so.manager.getProjectName(),
+		serviceName: serviceName,
 	}
-
-	// Build restore parameters
-	params := map[string]string{
-		"database":   options.Database,
-		"user":       options.User,
-		"backupFile": backupFile,
+	if err := runRestore(ctx, target, op, backupFile, options); err != nil {
+		return fmt.Errorf("failed to restore %s: %w", serviceName, err)
 	}
 
-	execOptions := types.ExecOptions{
-		User: options.User,
-	}
+	so.manager.logger.Info("Restore completed successfully", "service", serviceName, "backup", backupFile)
+	return nil
+}
 
-	// Execute pre-commands if clean is requested
-	if options.Clean && ops.Restore.PreCommands != nil {
-		if cleanCommands, exists := ops.Restore.PreCommands["clean"]; exists {
-			for _, cmdTemplate := range cleanCommands {
-				cmd := make([]string, len(cmdTemplate))
-				for i, part := range cmdTemplate {
-					cmd[i] = renderTemplate(part, params)
-				}
-				if err := so.manager.docker.Containers().Exec(ctx, projectName, serviceName, cmd, execOptions); err != nil {
-					return fmt.Errorf("failed to execute pre-command for %s: %w", serviceName, err)
-				}
-			}
-		}
+// TestRestore restores a backup into a throwaway copy of the service so the
+// backup can be verified without touching live data. It returns how long the
+// restore itself took.
+func (so *ServiceOperations) TestRestore(ctx context.Context, serviceName, backupFile string, options types.RestoreOptions) (time.Duration, error) {
+	so.manager.logger.Info("Testing restore in scratch container", "service", serviceName, "backup", backupFile)
+
+	op, err := so.restoreOperation(serviceName, backupFile)
+	if err != nil {
+		return 0, err
 	}
 
-	// Execute restore commands
-	if ops.Restore.Type == "custom" && len(ops.Restore.Commands) > 0 {
-		for _, cmdTemplate := range ops.Restore.Commands {
-			cmd := make([]string, len(cmdTemplate))
-			for i, part := range cmdTemplate {
-				cmd[i] = renderTemplate(part, params)
-			}
-			if err := so.manager.docker.Containers().Exec(ctx, projectName, serviceName, cmd, execOptions); err != nil {
-				return fmt.Errorf("failed to execute restore command for %s: %w", serviceName, err)
-			}
-		}
-	} else if len(ops.Restore.Command) > 0 {
-		cmd := make([]string, len(ops.Restore.Command))
-		copy(cmd, ops.Restore.Command)
-
-		// Add arguments
-		for param, value := range params {
-			if argTemplate, exists := ops.Restore.Args[param]; exists && value != "" {
-				for _, arg := range argTemplate {
-					rendered := renderTemplate(arg, params)
-					cmd = append(cmd, rendered)
-				}
-			}
+	scratch, err := so.manager.docker.Containers().NewScratch(ctx, so.manager.getProjectName(), serviceName, "restore-check")
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		if err := scratch.Remove(context.WithoutCancel(ctx)); err != nil {
+			so.manager.logger.Warn("Failed to remove scratch container", "name", scratch.Name, "error", err)
 		}
+	}()
 
-		if err := so.manager.docker.Containers().Exec(ctx, projectName, serviceName, cmd, execOptions); err != nil {
-			return fmt.Errorf("failed to restore %s: %w", serviceName, err)
-		}
+	readyCtx, cancel := context.WithTimeout(ctx, scratchReadyTimeout)
+	defer cancel()
+	if err := scratch.WaitReady(readyCtx); err != nil {
+		return 0, err
 	}
 
-	// Restart service if required
-	if ops.Restore.RequiresRestart {
-		if err := so.manager.StopServices(ctx, []string{serviceName}, types.StopOptions{Timeout: 10}); err != nil {
-			return fmt.Errorf("failed to stop %s for restart: %w", serviceName, err)
-		}
+	start := time.Now()
+	if err := runRestore(ctx, &scratchRestoreTarget{scratch: scratch}, op, backupFile, options); err != nil {
+		return 0, fmt.Errorf("test restore of %s failed: %w", serviceName, err)
+	}
+	return time.Since(start), nil
+}
 
-		startOptions := types.StartOptions{
-			Build:         false,
-			ForceRecreate: false,
-			Detach:        true,
-			Timeout:       30 * time.Second,
-		}
+// restoreOperation loads a service's restore operation after checking the
+// backup file exists
+func (so *ServiceOperations) restoreOperation(serviceName, backupFile string) (*services.RestoreOperation, error) {
+	if _, err := os.Stat(backupFile); os.IsNotExist(err) {
+		return nil, fmt.Errorf("backup file not found: %s", backupFile)
+	}
 
-		if err := so.manager.StartServices(ctx, []string{serviceName}, startOptions); err != nil {
-			return fmt.Errorf("failed to restart %s after restore: %w", serviceName, err)
-		}
+	ops, err := services.LoadServiceOperations(serviceName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load service operations for %s: %w", serviceName, err)
 	}
 
-	so.manager.logger.Info("Restore completed successfully", "service", serviceName, "backup", backupFile)
-	return nil
+	if ops == nil || ops.Restore == nil {
+		return nil, fmt.Errorf("no restore operation defined for service %s", serviceName)
+	}
+	return ops.Restore, nil
 }
 
 // ScaleService scales a service to the specified number of replicas
@@ -243,16 +392,24 @@ func (so *ServiceOperations) ScaleService(ctx context.Context, serviceName strin
 		return so.manager.StopServices(ctx, []string{serviceName}, stopOptions)
 	}
 
-	// For replicas > 0, ensure service is running
+	// Replicas are cloned from the primary container, so make sure it is up
 	statuses, err := so.manager.GetServiceStatus(ctx, []string{serviceName})
 	if err != nil {
 		return fmt.Errorf("failed to get service status: %w", err)
 	}
 
-	if len(statuses) == 0 || !statuses[0].State.IsRunning() {
+	running := false
+	for _, status := range statuses {
+		if status.State.IsRunning() {
+			running = true
+			break
+		}
+	}
+
+	if !running {
 		startOptions := types.StartOptions{
 			Build:         false,
-			ForceRecreate: options.NoRecreate,
+			ForceRecreate: false,
 			Detach:        true,
 			Timeout:       options.Timeout,
 		}
@@ -262,16 +419,10 @@ func (so *ServiceOperations) ScaleService(ctx context.Context, serviceName strin
 		}
 	}
 
+	if err := so.manager.docker.Containers().Scale(ctx, so.manager.getProjectName(), serviceName, replicas, options); err != nil {
+		return fmt.Errorf("failed to scale %s: %w", serviceName, err)
+	}
+
 	so.manager.logger.Info("Service scaling completed", "service", serviceName, "replicas", replicas)
 	return nil
 }
-
-// renderTemplate renders a template string with parameters
-func renderTemplate(templateStr string, params map[string]string) string {
-	result := templateStr
-	for key, value := range params {
-		placeholder := "{{." + strings.ToUpper(key[:1]) + key[1:] + "}}"
-		result = strings.ReplaceAll(result, placeholder, value)
-	}
-	return result
-}
@@ -0,0 +1,243 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"time"
+
+	"github.com/isaacgarza/dev-stack/internal/core/backup"
+	"github.com/isaacgarza/dev-stack/internal/core/docker"
+	"github.com/isaacgarza/dev-stack/internal/pkg/services"
+	"github.com/isaacgarza/dev-stack/internal/pkg/types"
+)
+
+// scratchReadyTimeout bounds how long a scratch container may take to start
+const scratchReadyTimeout = 2 * time.Minute
+
+// restoreTarget is the container a restore runs against: the live service or
+// a scratch copy of it
+type restoreTarget interface {
+	Exec(ctx context.Context, cmd []string, stdin io.Reader, options types.ExecOptions) error
+	CopyTo(ctx context.Context, target string, r io.Reader, size int64) error
+	CopyArchiveTo(ctx context.Context, dir string, r io.Reader) error
+	Stop(ctx context.Context) error
+	Start(ctx context.Context) error
+}
+
+// runRestore executes a restore operation against target. Pre-commands run
+// first when a clean restore is requested. Operations with a file copy the
+// backup into the container, stopping it around the copy if the service
+// needs a restart to pick the data up; otherwise the backup is piped to the
+// last command's stdin. Archive operations extract the backup over the file's
+// parent directory instead of copying it.
+func runRestore(ctx context.Context, target restoreTarget, op *services.RestoreOperation, backupFile string, options types.RestoreOptions) error {
+	params := map[string]string{
+		"database":   options.Database,
+		"user":       options.User,
+		"backupFile": backupFile,
+	}
+	execOptions := types.ExecOptions{
+		User: options.User,
+	}
+
+	if options.Clean {
+		for _, cmd := range op.BuildPreCommands("clean", params) {
+			if err := target.Exec(ctx, cmd, nil, execOptions); err != nil {
+				return fmt.Errorf("pre-command failed: %w", err)
+			}
+		}
+	}
+
+	commands, err := op.BuildCommand(params)
+	if err != nil {
+		return err
+	}
+
+	if op.File == "" {
+		if len(commands) == 0 {
+			return fmt.Errorf("restore operation has no command")
+		}
+		for i, cmd := range commands {
+			if i < len(commands)-1 {
+				if err := target.Exec(ctx, cmd, nil, execOptions); err != nil {
+					return err
+				}
+				continue
+			}
+			if err := pipeBackup(ctx, target, cmd, backupFile, execOptions); err != nil {
+				return err
+			}
+		}
+		if op.RequiresRestart {
+			return restartTarget(ctx, target)
+		}
+		return nil
+	}
+
+	if !op.RequiresRestart {
+		if err := copyBackup(ctx, target, op, backupFile); err != nil {
+			return err
+		}
+		return execAll(ctx, target, commands, execOptions)
+	}
+
+	// The service may write its data file on shutdown, so the backup is only
+	// copied in once it has stopped
+	if err := execAll(ctx, target, commands, execOptions); err != nil {
+		return err
+	}
+	if err := target.Stop(ctx); err != nil {
+		return err
+	}
+	if err := copyBackup(ctx, target, op, backupFile); err != nil {
+		return err
+	}
+	return target.Start(ctx)
+}
+
+func execAll(ctx context.Context, target restoreTarget, commands [][]string, options types.ExecOptions) error {
+	for _, cmd := range commands {
+		if err := target.Exec(ctx, cmd, nil, options); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func restartTarget(ctx context.Context, target restoreTarget) error {
+	if err := target.Stop(ctx); err != nil {
+		return err
+	}
+	return target.Start(ctx)
+}
+
+// pipeBackup runs cmd with the decompressed backup on its stdin
+func pipeBackup(ctx context.Context, target restoreTarget, cmd []string, backupFile string, options types.ExecOptions) error {
+	reader, err := backup.Open(backupFile)
+	if err != nil {
+		return fmt.Errorf("failed to open backup: %w", err)
+	}
+	defer func() { _ = reader.Close() }()
+
+	return target.Exec(ctx, cmd, reader, options)
+}
+
+// copyBackup copies the decompressed backup to the operation's file inside
+// the container. The copy needs the file size up front, so compressed
+// backups are decompressed to a temporary file first; archives are streamed.
+func copyBackup(ctx context.Context, target restoreTarget, op *services.RestoreOperation, backupFile string) error {
+	reader, err := backup.Open(backupFile)
+	if err != nil {
+		return fmt.Errorf("failed to open backup: %w", err)
+	}
+	defer func() { _ = reader.Close() }()
+
+	if op.Archive {
+		dir := path.Dir(op.File)
+		if err := target.CopyArchiveTo(ctx, dir, reader); err != nil {
+			return fmt.Errorf("failed to extract backup into %s: %w", dir, err)
+		}
+		return nil
+	}
+
+	file, ok := reader.(*os.File)
+	if !ok {
+		file, err = os.CreateTemp("", "dev-stack-restore-*")
+		if err != nil {
+			return fmt.Errorf("failed to create temporary file: %w", err)
+		}
+		defer func() {
+			_ = file.Close()
+			_ = os.Remove(file.Name())
+		}()
+
+		if _, err := io.Copy(file, reader); err != nil {
+			return fmt.Errorf("failed to decompress backup: %w", err)
+		}
+		if _, err := file.Seek(0, io.SeekStart); err != nil {
+			return fmt.Errorf("failed to rewind backup: %w", err)
+		}
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		return fmt.Errorf("failed to stat backup: %w", err)
+	}
+
+	if err := target.CopyTo(ctx, op.File, file, info.Size()); err != nil {
+		return fmt.Errorf("failed to copy backup to %s: %w", op.File, err)
+	}
+	return nil
+}
+
+// liveRestoreTarget restores into the running service
+type liveRestoreTarget struct {
+	manager     *Manager
+	projectName string
+	serviceName string
+}
+
+func (t *liveRestoreTarget) Exec(ctx context.Context, cmd []string, stdin io.Reader, options types.ExecOptions) error {
+	containers := t.manager.docker.Containers()
+	if stdin != nil {
+		return containers.ExecStdin(ctx, t.projectName, t.serviceName, cmd, stdin, options)
+	}
+	return containers.ExecStream(ctx, t.projectName, t.serviceName, cmd, io.Discard, options)
+}
+
+func (t *liveRestoreTarget) CopyTo(ctx context.Context, target string, r io.Reader, size int64) error {
+	return t.manager.docker.Containers().CopyTo(ctx, t.projectName, t.serviceName, target, r, size)
+}
+
+func (t *liveRestoreTarget) CopyArchiveTo(ctx context.Context, dir string, r io.Reader) error {
+	return t.manager.docker.Containers().CopyArchiveTo(ctx, t.projectName, t.serviceName, dir, r)
+}
+
+func (t *liveRestoreTarget) Stop(ctx context.Context) error {
+	if err := t.manager.StopServices(ctx, []string{t.serviceName}, types.StopOptions{Timeout: 10}); err != nil {
+		return fmt.Errorf("failed to stop %s for restart: %w", t.serviceName, err)
+	}
+	return nil
+}
+
+func (t *liveRestoreTarget) Start(ctx context.Context) error {
+	startOptions := types.StartOptions{
+		Build:         false,
+		ForceRecreate: false,
+		Detach:        true,
+		Timeout:       30 * time.Second,
+	}
+
+	if err := t.manager.StartServices(ctx, []string{t.serviceName}, startOptions); err != nil {
+		return fmt.Errorf("failed to restart %s after restore: %w", t.serviceName, err)
+	}
+	return nil
+}
+
+// scratchRestoreTarget restores into a throwaway copy of the service
+type scratchRestoreTarget struct {
+	scratch *docker.ScratchContainer
+}
+
+func (t *scratchRestoreTarget) Exec(ctx context.Context, cmd []string, stdin io.Reader, options types.ExecOptions) error {
+	return t.scratch.Exec(ctx, cmd, stdin, io.Discard, options)
+}
+
+func (t *scratchRestoreTarget) CopyTo(ctx context.Context, target string, r io.Reader, size int64) error {
+	return t.scratch.CopyTo(ctx, target, r, size)
+}
+
+func (t *scratchRestoreTarget) CopyArchiveTo(ctx context.Context, dir string, r io.Reader) error {
+	return t.scratch.CopyArchiveTo(ctx, dir, r)
+}
+
+func (t *scratchRestoreTarget) Stop(ctx context.Context) error {
+	return t.scratch.Stop(ctx)
+}
+
+func (t *scratchRestoreTarget) Start(ctx context.Context) error {
+	return t.scratch.Start(ctx)
+}
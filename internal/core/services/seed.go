@@ -0,0 +1,143 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/isaacgarza/dev-stack/internal/core/seed"
+	"github.com/isaacgarza/dev-stack/internal/pkg/services"
+	"github.com/isaacgarza/dev-stack/internal/pkg/types"
+)
+
+// seedTypeKafka marks seed operations whose fixtures declare topics and
+// messages rather than being piped to a command
+const seedTypeKafka = "kafka"
+
+// SeedService applies a service's fixtures in order. Fixtures recorded in the
+// service's marker file are skipped unless forced, so seeding can be re-run
+// safely. The returned entries carry each fixture's final status.
+func (so *ServiceOperations) SeedService(ctx context.Context, serviceName string, fixtures []seed.Fixture, options types.SeedOptions) ([]seed.Entry, error) {
+	so.manager.logger.Info("Seeding service", "service", serviceName, "fixtures", len(fixtures))
+
+	ops, err := services.LoadServiceOperations(serviceName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load service operations for %s: %w", serviceName, err)
+	}
+	if ops == nil || ops.Seed == nil {
+		return nil, fmt.Errorf("no seed operation defined for service %s", serviceName)
+	}
+	op := ops.Seed
+
+	projectName := so.manager.getProjectName()
+	markers, err := so.readSeedMarkers(ctx, projectName, serviceName, op.MarkerFile)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := seed.Plan(seed.Filter(fixtures, op.Extensions), markers)
+	if options.DryRun {
+		return entries, nil
+	}
+
+	for i := range entries {
+		entry := &entries[i]
+		if entry.Status != seed.StatusPending && !options.Force {
+			continue
+		}
+
+		if err := so.applySeed(ctx, projectName, serviceName, op, entry.Fixture); err != nil {
+			return entries, fmt.Errorf("failed to apply seed %s to %s: %w", entry.Name, serviceName, err)
+		}
+		if err := so.writeSeedMarker(ctx, projectName, serviceName, op.MarkerFile, entry.Fixture); err != nil {
+			return entries, err
+		}
+		entry.Status = seed.StatusSeeded
+	}
+
+	so.manager.logger.Info("Seeding completed", "service", serviceName)
+	return entries, nil
+}
+
+// applySeed runs a single fixture against the service
+func (so *ServiceOperations) applySeed(ctx context.Context, projectName, serviceName string, op *services.SeedOperation, fixture seed.Fixture) error {
+	containers := so.manager.docker.Containers()
+
+	if op.Type == seedTypeKafka {
+		kafka, err := seed.LoadKafkaFixture(fixture.Path)
+		if err != nil {
+			return err
+		}
+		steps, err := kafka.Steps(op.Defaults["bootstrap_server"])
+		if err != nil {
+			return err
+		}
+		for _, step := range steps {
+			if step.Stdin == "" {
+				err = containers.ExecStream(ctx, projectName, serviceName, step.Command, io.Discard, types.ExecOptions{})
+			} else {
+				err = containers.ExecStdin(ctx, projectName, serviceName, step.Command, strings.NewReader(step.Stdin), types.ExecOptions{})
+			}
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	cmd := op.BuildCommand(map[string]string{
+		"file": fixture.Name,
+		"name": strings.TrimSuffix(fixture.Name, filepath.Ext(fixture.Name)),
+	})
+	if len(cmd) == 0 {
+		return fmt.Errorf("seed operation has no command")
+	}
+
+	file, err := os.Open(fixture.Path)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = file.Close() }()
+
+	return containers.ExecStdin(ctx, projectName, serviceName, cmd, file, types.ExecOptions{})
+}
+
+// readSeedMarkers reads the fixtures already applied to a service; a missing
+// marker file means nothing has been applied yet
+func (so *ServiceOperations) readSeedMarkers(ctx context.Context, projectName, serviceName, markerFile string) (map[string]string, error) {
+	if markerFile == "" {
+		return map[string]string{}, nil
+	}
+
+	result, err := so.manager.docker.Containers().ExecCapture(ctx, projectName, serviceName, []string{"sh", "-c", `cat "$1" 2>/dev/null || true`, "sh", markerFile})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read seed markers for %s: %w", serviceName, err)
+	}
+	if result.ExitCode != 0 {
+		return nil, fmt.Errorf("failed to read seed markers for %s: %s", serviceName, strings.TrimSpace(result.Stderr))
+	}
+	return seed.ParseMarkers(result.Stdout), nil
+}
+
+// seedMarkerScript appends stdin to the marker file $1. Exec runs as root,
+// so the file is handed to the owner of its directory, the user the service
+// runs as, rather than leaving a root-owned file on the data volume.
+const seedMarkerScript = `dir="$(dirname "$1")" && mkdir -p "$dir" && cat >> "$1" && chown "$(stat -c %u:%g "$dir")" "$1"`
+
+// writeSeedMarker records that a fixture was applied
+func (so *ServiceOperations) writeSeedMarker(ctx context.Context, projectName, serviceName, markerFile string, fixture seed.Fixture) error {
+	if markerFile == "" {
+		return nil
+	}
+
+	cmd := []string{"sh", "-c", seedMarkerScript, "sh", markerFile}
+	line := strings.NewReader(seed.MarkerLine(fixture, time.Now()))
+	if err := so.manager.docker.Containers().ExecStdin(ctx, projectName, serviceName, cmd, line, types.ExecOptions{}); err != nil {
+		return fmt.Errorf("failed to record seed %s for %s: %w", fixture.Name, serviceName, err)
+	}
+	return nil
+}
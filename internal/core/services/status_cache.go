@@ -0,0 +1,78 @@
+package services
+
+import (
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/isaacgarza/dev-stack/internal/pkg/types"
+)
+
+// StatusCacheTTL is how long GetServiceStatus answers with the statuses it
+// last read, so monitor and watch loops, the daemon and the editor plugins
+// asking many times a second don't each list and sample every container
+const StatusCacheTTL = time.Second
+
+// statusCache holds the recent answers of GetServiceStatus, by project and
+// services asked for
+type statusCache struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	entries map[string]statusEntry
+}
+
+// statusEntry is an answer of GetServiceStatus and when it was read
+type statusEntry struct {
+	readAt   time.Time
+	statuses []types.ServiceStatus
+}
+
+// statusKey identifies an answer by its project and services, in any order
+func statusKey(projectName string, serviceNames []string) string {
+	sorted := slices.Clone(serviceNames)
+	slices.Sort(sorted)
+	return projectName + "\x00" + strings.Join(sorted, ",")
+}
+
+// get returns a copy of the answer for key read within the TTL
+func (c *statusCache) get(key string, now time.Time) ([]types.ServiceStatus, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[key]
+	if !ok || c.ttl <= 0 || now.Sub(entry.readAt) >= c.ttl {
+		return nil, false
+	}
+	return slices.Clone(entry.statuses), true
+}
+
+// put records the answer for key
+func (c *statusCache) put(key string, statuses []types.ServiceStatus, now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.ttl <= 0 {
+		return
+	}
+	if c.entries == nil {
+		c.entries = make(map[string]statusEntry)
+	}
+	c.entries[key] = statusEntry{readAt: now, statuses: slices.Clone(statuses)}
+}
+
+// invalidate drops every answer, after an operation changed the containers
+func (c *statusCache) invalidate() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries = nil
+}
+
+// SetStatusCacheTTL sets how long GetServiceStatus reuses the statuses it
+// read; zero reads them on every call. It defaults to StatusCacheTTL.
+func (m *Manager) SetStatusCacheTTL(ttl time.Duration) {
+	m.statusCache.mu.Lock()
+	defer m.statusCache.mu.Unlock()
+	m.statusCache.ttl = ttl
+	m.statusCache.entries = nil
+}
@@ -0,0 +1,169 @@
+package services
+
+import (
+	"archive/tar"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/isaacgarza/dev-stack/internal/core/backup"
+	"github.com/isaacgarza/dev-stack/internal/core/docker"
+	"github.com/isaacgarza/dev-stack/internal/pkg/types"
+)
+
+// ResolveVolumes returns the volumes target names: those mounted into the
+// container of a service of the project, or one volume, named as Docker or
+// as the compose file names it
+func (so *ServiceOperations) ResolveVolumes(ctx context.Context, target string) ([]string, error) {
+	projectName := so.manager.getProjectName()
+	if mounts, err := so.manager.docker.Containers().VolumeMounts(ctx, projectName, target); err == nil {
+		if len(mounts) == 0 {
+			return nil, fmt.Errorf("service %s has no volumes", target)
+		}
+		names := make([]string, 0, len(mounts))
+		for _, mount := range mounts {
+			names = append(names, mount.Name)
+		}
+		return names, nil
+	}
+
+	for _, name := range []string{docker.NormalizeProjectName(projectName) + "_" + target, target} {
+		exists, err := so.manager.docker.Volumes().Exists(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+		if exists {
+			return []string{name}, nil
+		}
+	}
+	return nil, fmt.Errorf("no service or volume named %s", target)
+}
+
+// BackupVolume writes the contents of a volume to a tar archive named
+// backupName in the backup directory, compressed as options request, and
+// returns the path of the archive
+func (so *ServiceOperations) BackupVolume(ctx context.Context, volumeName, backupName string, options types.VolumeBackupOptions) (string, error) {
+	so.manager.logger.Info("Backing up volume", "volume", volumeName, "backup", backupName)
+
+	backupDir := options.OutputDir
+	if backupDir == "" {
+		backupDir = "./backups"
+	}
+	if err := os.MkdirAll(backupDir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create backup directory: %w", err)
+	}
+	backupPath := filepath.Join(backupDir, backupName+"."+backup.FormatTar+backup.Extension(options.Compress))
+
+	if options.StopServices {
+		start, err := so.stopVolumeServices(ctx, volumeName, options.Timeout)
+		if err != nil {
+			return "", err
+		}
+		defer start()
+	}
+
+	file, err := os.Create(backupPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to create backup file: %w", err)
+	}
+	compressor, err := backup.NewCompressor(file, options.Compress)
+	if err != nil {
+		_ = file.Close()
+		_ = os.Remove(backupPath)
+		return "", err
+	}
+	if err := so.manager.docker.Volumes().Export(ctx, volumeName, compressor); err != nil {
+		_ = file.Close()
+		_ = os.Remove(backupPath)
+		return "", err
+	}
+	if err := compressor.Close(); err != nil {
+		_ = file.Close()
+		_ = os.Remove(backupPath)
+		return "", fmt.Errorf("failed to compress backup of %s: %w", volumeName, err)
+	}
+	if err := file.Close(); err != nil {
+		_ = os.Remove(backupPath)
+		return "", fmt.Errorf("failed to write backup file: %w", err)
+	}
+
+	so.manager.logger.Info("Volume backed up", "volume", volumeName, "backup", backupPath)
+	return backupPath, nil
+}
+
+// RestoreVolume replaces the contents of a volume with a backup written by
+// BackupVolume. The services of the project using the volume are stopped
+// while it is restored and started again afterwards.
+func (so *ServiceOperations) RestoreVolume(ctx context.Context, volumeName, backupFile string, options types.VolumeBackupOptions) error {
+	so.manager.logger.Info("Restoring volume", "volume", volumeName, "backup", backupFile)
+
+	// The volume is emptied before the archive is extracted, so the archive
+	// is checked first
+	if err := checkVolumeArchive(backupFile); err != nil {
+		return err
+	}
+
+	start, err := so.stopVolumeServices(ctx, volumeName, options.Timeout)
+	if err != nil {
+		return err
+	}
+	defer start()
+
+	reader, err := backup.Open(backupFile)
+	if err != nil {
+		return fmt.Errorf("failed to open backup: %w", err)
+	}
+	defer func() { _ = reader.Close() }()
+
+	if err := so.manager.docker.Volumes().Import(ctx, volumeName, reader); err != nil {
+		return err
+	}
+	so.manager.logger.Info("Volume restored", "volume", volumeName, "backup", backupFile)
+	return nil
+}
+
+// stopVolumeServices stops the running services using a volume and returns
+// a function starting them again
+func (so *ServiceOperations) stopVolumeServices(ctx context.Context, volumeName string, timeout int) (func(), error) {
+	projectName := so.manager.getProjectName()
+	serviceNames, err := so.manager.docker.Volumes().RunningServices(ctx, projectName, volumeName)
+	if err != nil {
+		return nil, err
+	}
+	if len(serviceNames) == 0 {
+		return func() {}, nil
+	}
+
+	so.manager.logger.Info("Stopping services using volume", "volume", volumeName, "services", serviceNames)
+	containers := so.manager.docker.Containers()
+	if err := containers.Stop(ctx, projectName, serviceNames, types.StopOptions{Timeout: timeout}); err != nil {
+		return nil, fmt.Errorf("failed to stop %s: %w", strings.Join(serviceNames, ", "), err)
+	}
+	return func() {
+		if err := containers.Start(context.WithoutCancel(ctx), projectName, serviceNames, types.StartOptions{Detach: true}); err != nil {
+			so.manager.logger.Error("Failed to start services again", "services", serviceNames, "error", err)
+		}
+	}, nil
+}
+
+// checkVolumeArchive checks that a backup is a tar archive holding the
+// contents of a volume under docker.VolumeArchiveRoot
+func checkVolumeArchive(backupFile string) error {
+	reader, err := backup.Open(backupFile)
+	if err != nil {
+		return fmt.Errorf("failed to open backup: %w", err)
+	}
+	defer func() { _ = reader.Close() }()
+
+	header, err := tar.NewReader(reader).Next()
+	if err != nil {
+		return fmt.Errorf("%s is not a volume backup: %w", backupFile, err)
+	}
+	root, _, _ := strings.Cut(strings.TrimPrefix(header.Name, "./"), "/")
+	if root != docker.VolumeArchiveRoot {
+		return fmt.Errorf("%s is not a volume backup: it holds %s rather than %s/", backupFile, root, docker.VolumeArchiveRoot)
+	}
+	return nil
+}
@@ -0,0 +1,56 @@
+package services
+
+import (
+	"archive/tar"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/isaacgarza/dev-stack/internal/core/backup"
+	"github.com/isaacgarza/dev-stack/internal/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeTestArchive(t *testing.T, name, compression string, entries ...string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	file, err := os.Create(path)
+	require.NoError(t, err)
+	defer func() { _ = file.Close() }()
+
+	compressor, err := backup.NewCompressor(file, compression)
+	require.NoError(t, err)
+	tw := tar.NewWriter(compressor)
+	for _, entry := range entries {
+		require.NoError(t, tw.WriteHeader(&tar.Header{Name: entry, Typeflag: tar.TypeDir, Mode: 0755}))
+	}
+	require.NoError(t, tw.Close())
+	require.NoError(t, compressor.Close())
+	return path
+}
+
+func TestCheckVolumeArchive(t *testing.T) {
+	t.Run("volume archive", func(t *testing.T) {
+		path := writeTestArchive(t, "data.tar", types.CompressionNone, "volume/", "volume/objects/")
+		assert.NoError(t, checkVolumeArchive(path))
+	})
+
+	t.Run("compressed volume archive", func(t *testing.T) {
+		path := writeTestArchive(t, "data.tar.gz", types.CompressionGzip, "volume/")
+		assert.NoError(t, checkVolumeArchive(path))
+	})
+
+	t.Run("other archive", func(t *testing.T) {
+		path := writeTestArchive(t, "data.tar", types.CompressionNone, "data/")
+		err := checkVolumeArchive(path)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "not a volume backup")
+	})
+
+	t.Run("not an archive", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "dump.sql")
+		require.NoError(t, os.WriteFile(path, []byte("select 1;\n"), 0644))
+		assert.Error(t, checkVolumeArchive(path))
+	})
+}
@@ -0,0 +1,142 @@
+package shared
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/isaacgarza/dev-stack/internal/pkg/utils"
+)
+
+// stateFileName records which projects use each shared service
+const stateFileName = "shared-services.json"
+
+// Lock timing for concurrent updates of the state file
+const (
+	lockRetry   = 100 * time.Millisecond
+	lockTimeout = 10 * time.Second
+	// lockStale is how old a lock left by a crashed process may get before
+	// it is broken
+	lockStale = 30 * time.Second
+)
+
+// stateLock guards the state file
+var stateLock = utils.FileLock{Retry: lockRetry, Timeout: lockTimeout, Stale: lockStale}
+
+// State tracks the projects attached to each shared service on the host.
+// A shared service keeps running until its last project detaches.
+type State struct {
+	Services map[string]*Attachment `json:"services"`
+}
+
+// Attachment lists the projects using a shared service
+type Attachment struct {
+	Projects  []string  `json:"projects"`
+	StartedBy string    `json:"started_by"`
+	StartedAt time.Time `json:"started_at"`
+}
+
+// StateFile returns the host-level state file, shared by every project of
+// the current user
+func StateFile() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	return filepath.Join(home, ".dev-stack", stateFileName), nil
+}
+
+// Load reads the state at path. A missing file is an empty state.
+func Load(path string) (*State, error) {
+	state := &State{Services: make(map[string]*Attachment)}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return state, nil
+		}
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	if err := json.Unmarshal(data, state); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	if state.Services == nil {
+		state.Services = make(map[string]*Attachment)
+	}
+	return state, nil
+}
+
+// Save writes the state to path, replacing the file atomically
+func (s *State) Save(path string) error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	return utils.WriteFileAtomic(path, append(data, '\n'), 0644)
+}
+
+// Attach records that project uses a shared service and reports whether it
+// is the first project to do so
+func (s *State) Attach(serviceName, project string) bool {
+	attachment, ok := s.Services[serviceName]
+	if !ok || len(attachment.Projects) == 0 {
+		s.Services[serviceName] = &Attachment{Projects: []string{project}, StartedBy: project, StartedAt: time.Now().UTC()}
+		return true
+	}
+	if !slices.Contains(attachment.Projects, project) {
+		attachment.Projects = append(attachment.Projects, project)
+		sort.Strings(attachment.Projects)
+	}
+	return false
+}
+
+// Detach records that project no longer uses a shared service and returns
+// how many projects still do. The service is forgotten once none does.
+func (s *State) Detach(serviceName, project string) int {
+	attachment, ok := s.Services[serviceName]
+	if !ok {
+		return 0
+	}
+	attachment.Projects = slices.DeleteFunc(attachment.Projects, func(p string) bool { return p == project })
+	if len(attachment.Projects) == 0 {
+		delete(s.Services, serviceName)
+		return 0
+	}
+	return len(attachment.Projects)
+}
+
+// ServicesOf returns the shared services project is attached to, sorted
+func (s *State) ServicesOf(project string) []string {
+	var names []string
+	for name, attachment := range s.Services {
+		if slices.Contains(attachment.Projects, project) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Update applies fn to the state at path and saves the result. A lock file
+// next to the state keeps concurrent dev-stack runs from losing each
+// other's updates. The state is not saved when fn fails.
+func Update(path string, fn func(*State) error) error {
+	unlock, err := stateLock.Acquire(path + ".lock")
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	state, err := Load(path)
+	if err != nil {
+		return err
+	}
+	if err := fn(state); err != nil {
+		return err
+	}
+	return state.Save(path)
+}
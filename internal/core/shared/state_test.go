@@ -0,0 +1,76 @@
+package shared
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAttachDetach(t *testing.T) {
+	state := &State{Services: make(map[string]*Attachment)}
+
+	assert.True(t, state.Attach("postgres", "shop"))
+	assert.False(t, state.Attach("postgres", "billing"))
+	assert.False(t, state.Attach("postgres", "shop"))
+	assert.True(t, state.Attach("redis", "billing"))
+
+	assert.Equal(t, []string{"billing", "shop"}, state.Services["postgres"].Projects)
+	assert.Equal(t, "shop", state.Services["postgres"].StartedBy)
+	assert.Equal(t, []string{"postgres", "redis"}, state.ServicesOf("billing"))
+	assert.Equal(t, []string{"postgres"}, state.ServicesOf("shop"))
+
+	assert.Equal(t, 1, state.Detach("postgres", "shop"))
+	assert.Equal(t, 0, state.Detach("postgres", "billing"))
+	assert.NotContains(t, state.Services, "postgres")
+	assert.Equal(t, 0, state.Detach("postgres", "billing"))
+	assert.Empty(t, state.ServicesOf("shop"))
+}
+
+func TestLoadMissing(t *testing.T) {
+	state, err := Load(filepath.Join(t.TempDir(), "shared-services.json"))
+	require.NoError(t, err)
+	assert.Empty(t, state.Services)
+}
+
+func TestUpdate(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "shared-services.json")
+
+	require.NoError(t, Update(path, func(state *State) error {
+		state.Attach("postgres", "shop")
+		return nil
+	}))
+
+	state, err := Load(path)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"shop"}, state.Services["postgres"].Projects)
+	assert.NoFileExists(t, path+".lock")
+
+	failure := errors.New("boom")
+	err = Update(path, func(state *State) error {
+		state.Attach("postgres", "billing")
+		return failure
+	})
+	assert.ErrorIs(t, err, failure)
+
+	state, err = Load(path)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"shop"}, state.Services["postgres"].Projects)
+}
+
+func TestUpdateBreaksStaleLock(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "shared-services.json")
+	require.NoError(t, os.WriteFile(path+".lock", nil, 0644))
+	old := time.Now().Add(-2 * lockStale)
+	require.NoError(t, os.Chtimes(path+".lock", old, old))
+
+	require.NoError(t, Update(path, func(state *State) error {
+		state.Attach("redis", "shop")
+		return nil
+	}))
+	assert.NoFileExists(t, path+".lock")
+}
@@ -0,0 +1,156 @@
+package snapshot
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/isaacgarza/dev-stack/internal/pkg/constants"
+	"github.com/isaacgarza/dev-stack/internal/pkg/types"
+)
+
+// ManifestFileName describes the contents of a snapshot directory
+const ManifestFileName = "manifest.json"
+
+// validName matches snapshot names, which double as directory names
+var validName = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_.-]*$`)
+
+// Manifest records what a snapshot holds: the volumes of each service that
+// was running when it was taken, and where their archives are stored
+type Manifest struct {
+	Name      string    `json:"name"`
+	Project   string    `json:"project"`
+	CreatedAt time.Time `json:"created_at"`
+	Services  []Service `json:"services"`
+}
+
+// Service holds the volume archives of one service
+type Service struct {
+	Name    string   `json:"name"`
+	Volumes []Volume `json:"volumes"`
+}
+
+// Volume is the archive of a single volume, relative to the snapshot
+// directory. The archive holds the volume's mount point as its top-level
+// directory, in the format of docker cp.
+type Volume struct {
+	types.VolumeMount
+	Archive string `json:"archive"`
+	Size    int64  `json:"size"`
+}
+
+// Root returns the directory snapshots are stored in
+func Root() string {
+	return filepath.Join(constants.DevStackDir, constants.SnapshotsDir)
+}
+
+// Dir returns the directory of a snapshot
+func Dir(name string) string {
+	return filepath.Join(Root(), name)
+}
+
+// ValidateName checks that name can be used as a snapshot name
+func ValidateName(name string) error {
+	if !validName.MatchString(name) {
+		return fmt.Errorf("invalid snapshot name %q: use letters, digits, '.', '_' and '-'", name)
+	}
+	return nil
+}
+
+// ArchiveName returns the path, relative to the snapshot directory, of the
+// archive of the volume mounted at destination in a service
+func ArchiveName(serviceName, destination string) string {
+	slug := strings.Trim(strings.ReplaceAll(filepath.ToSlash(destination), "/", "_"), "_")
+	if slug == "" {
+		slug = "root"
+	}
+	return filepath.Join(serviceName, slug+".tar")
+}
+
+// Save writes the manifest into dir
+func (m *Manifest) Save(dir string) error {
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode snapshot manifest: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, ManifestFileName), append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write snapshot manifest: %w", err)
+	}
+	return nil
+}
+
+// ServiceNames returns the services in the snapshot
+func (m *Manifest) ServiceNames() []string {
+	names := make([]string, 0, len(m.Services))
+	for _, svc := range m.Services {
+		names = append(names, svc.Name)
+	}
+	return names
+}
+
+// Size returns the total size of the snapshot's archives
+func (m *Manifest) Size() int64 {
+	var size int64
+	for _, svc := range m.Services {
+		for _, volume := range svc.Volumes {
+			size += volume.Size
+		}
+	}
+	return size
+}
+
+// Load reads the manifest of a snapshot
+func Load(name string) (*Manifest, error) {
+	if err := ValidateName(name); err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(filepath.Join(Dir(name), ManifestFileName))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("snapshot %s not found", name)
+		}
+		return nil, fmt.Errorf("failed to read snapshot %s: %w", name, err)
+	}
+
+	var manifest Manifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("failed to parse snapshot %s: %w", name, err)
+	}
+	return &manifest, nil
+}
+
+// List returns the snapshots of the project, oldest first. Directories
+// without a manifest, such as snapshots that failed half-way, are skipped.
+func List() ([]Manifest, error) {
+	entries, err := os.ReadDir(Root())
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read %s: %w", Root(), err)
+	}
+
+	var manifests []Manifest
+	for _, entry := range entries {
+		if !entry.IsDir() || ValidateName(entry.Name()) != nil {
+			continue
+		}
+		manifest, err := Load(entry.Name())
+		if err != nil {
+			continue
+		}
+		manifests = append(manifests, *manifest)
+	}
+
+	sort.Slice(manifests, func(i, j int) bool {
+		return manifests[i].CreatedAt.Before(manifests[j].CreatedAt)
+	})
+	return manifests, nil
+}
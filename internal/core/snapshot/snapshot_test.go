@@ -0,0 +1,84 @@
+package snapshot
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/isaacgarza/dev-stack/internal/pkg/types"
+)
+
+func TestValidateName(t *testing.T) {
+	for _, name := range []string{"known-good", "before_migration", "20260101-120000", "v1.2"} {
+		assert.NoError(t, ValidateName(name), name)
+	}
+	for _, name := range []string{"", ".hidden", "../escape", "with space", "a/b"} {
+		assert.Error(t, ValidateName(name), name)
+	}
+}
+
+func TestArchiveName(t *testing.T) {
+	assert.Equal(t, filepath.Join("postgres", "var_lib_postgresql_data.tar"), ArchiveName("postgres", "/var/lib/postgresql/data"))
+	assert.Equal(t, filepath.Join("redis", "data.tar"), ArchiveName("redis", "/data/"))
+	assert.Equal(t, filepath.Join("app", "root.tar"), ArchiveName("app", "/"))
+}
+
+func TestManifest_SaveLoadList(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	older := &Manifest{
+		Name:      "older",
+		Project:   "shop",
+		CreatedAt: time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC),
+		Services: []Service{{
+			Name: "postgres",
+			Volumes: []Volume{{
+				VolumeMount: types.VolumeMount{Name: "shop_postgres_data", Destination: "/var/lib/postgresql/data", Named: true},
+				Archive:     ArchiveName("postgres", "/var/lib/postgresql/data"),
+				Size:        2048,
+			}},
+		}},
+	}
+	newer := &Manifest{
+		Name:      "newer",
+		Project:   "shop",
+		CreatedAt: older.CreatedAt.Add(time.Hour),
+		Services: []Service{
+			{Name: "postgres", Volumes: []Volume{{Size: 1024}}},
+			{Name: "redis", Volumes: []Volume{{Size: 512}}},
+		},
+	}
+	for _, manifest := range []*Manifest{newer, older} {
+		require.NoError(t, os.MkdirAll(Dir(manifest.Name), 0755))
+		require.NoError(t, manifest.Save(Dir(manifest.Name)))
+	}
+	// Half-written snapshots have no manifest and are not listed
+	require.NoError(t, os.MkdirAll(Dir("newer")+".partial", 0755))
+
+	loaded, err := Load("older")
+	require.NoError(t, err)
+	assert.Equal(t, older, loaded)
+	assert.Equal(t, int64(1536), newer.Size())
+	assert.Equal(t, []string{"postgres", "redis"}, newer.ServiceNames())
+
+	manifests, err := List()
+	require.NoError(t, err)
+	require.Len(t, manifests, 2)
+	assert.Equal(t, "older", manifests[0].Name)
+	assert.Equal(t, "newer", manifests[1].Name)
+
+	_, err = Load("missing")
+	assert.ErrorContains(t, err, "snapshot missing not found")
+}
+
+func TestList_NoSnapshots(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	manifests, err := List()
+	require.NoError(t, err)
+	assert.Empty(t, manifests)
+}
@@ -0,0 +1,144 @@
+// Package tasks imports the targets of Makefiles and the recipes of
+// justfiles as tasks, so projects that already drive their builds with make
+// or just can run them through dev-stack run
+package tasks
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/isaacgarza/dev-stack/internal/pkg/types"
+)
+
+// The names make and just find on their own, which imported commands can
+// leave out
+var (
+	makefileNames = []string{"GNUmakefile", "makefile", "Makefile"}
+	justfileNames = []string{"justfile", "Justfile", ".justfile", "JUSTFILE"}
+)
+
+var (
+	// makeRule matches the targets of a rule, before its colon
+	makeRule = regexp.MustCompile(`^([A-Za-z0-9_][A-Za-z0-9_.-]*(?:[ \t]+[A-Za-z0-9_][A-Za-z0-9_.-]*)*)[ \t]*::?(.*)$`)
+	// justRecipe matches the name of a recipe, before its parameters
+	justRecipe = regexp.MustCompile(`^@?([A-Za-z_][A-Za-z0-9_-]*)(?:[ \t]+[^:]*)?[ \t]*:(.*)$`)
+)
+
+// justKeywords start the lines of a justfile that aren't recipes
+var justKeywords = []string{"alias", "export", "import", "mod", "set"}
+
+// Import returns the tasks of a Makefile, or of a justfile when the file is
+// named like one, such as justfile or build.just. Tasks run make or just
+// from the directory of the file, relative to root.
+func Import(root, path string) (types.TasksConfig, error) {
+	data, err := os.ReadFile(filepath.Join(root, path))
+	if err != nil {
+		return nil, err
+	}
+
+	dir := filepath.Dir(path)
+	base := filepath.Base(path)
+	var tasks types.TasksConfig
+	if slices.Contains(justfileNames, base) || strings.HasSuffix(base, ".just") {
+		tasks = ParseJustfile(data, toolCommand("just", base, justfileNames))
+	} else {
+		tasks = ParseMakefile(data, toolCommand("make", base, makefileNames))
+	}
+	if len(tasks) == 0 {
+		return nil, fmt.Errorf("%s: no targets found", path)
+	}
+	if dir != "." {
+		for name, task := range tasks {
+			task.WorkingDir = dir
+			tasks[name] = task
+		}
+	}
+	return tasks, nil
+}
+
+// toolCommand returns the command line running tool with the file named
+// base, which only names the file when tool wouldn't find it on its own
+func toolCommand(tool, base string, defaults []string) string {
+	if slices.Contains(defaults, base) {
+		return tool
+	}
+	return tool + " -f " + base
+}
+
+// ParseMakefile returns a task running program with each explicit target of
+// a Makefile. Special targets such as .PHONY, pattern rules, file targets
+// and variable assignments are left out. A target is described by the ##
+// comment after its prerequisites or by the comment line above it.
+func ParseMakefile(data []byte, program string) types.TasksConfig {
+	tasks := make(types.TasksConfig)
+	comment := ""
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if text, ok := strings.CutPrefix(line, "#"); ok {
+			comment = strings.TrimSpace(strings.TrimLeft(text, "#"))
+			continue
+		}
+		// Special targets such as .PHONY often sit between a target and
+		// the comment describing it
+		if strings.HasPrefix(line, ".") {
+			continue
+		}
+		match := makeRule.FindStringSubmatch(line)
+		if match == nil || strings.HasPrefix(match[2], "=") {
+			comment = ""
+			continue
+		}
+
+		description := comment
+		if _, after, ok := strings.Cut(match[2], "##"); ok {
+			description = strings.TrimSpace(after)
+		}
+		for _, target := range strings.Fields(match[1]) {
+			if _, exists := tasks[target]; exists {
+				continue
+			}
+			tasks[target] = types.TaskConfig{Description: description, Command: program + " " + target}
+		}
+		comment = ""
+	}
+	return tasks
+}
+
+// ParseJustfile returns a task running program with each public recipe of
+// a justfile, described by the comment line above it as just --list does.
+// Arguments given to dev-stack run fill in the parameters of recipes.
+func ParseJustfile(data []byte, program string) types.TasksConfig {
+	tasks := make(types.TasksConfig)
+	comment := ""
+	private := false
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case strings.HasPrefix(line, "#"):
+			comment = strings.TrimSpace(strings.TrimPrefix(line, "#"))
+			continue
+		case strings.HasPrefix(line, "["):
+			// Attributes such as [private] apply to the recipe below
+			private = private || strings.Contains(line, "private")
+			continue
+		}
+
+		match := justRecipe.FindStringSubmatch(line)
+		keyword, _, _ := strings.Cut(line, " ")
+		if match != nil && !strings.HasPrefix(match[2], "=") && !slices.Contains(justKeywords, keyword) &&
+			!private && !strings.HasPrefix(match[1], "_") {
+			tasks[match[1]] = types.TaskConfig{Description: comment, Command: program + " " + match[1]}
+		}
+		comment = ""
+		private = false
+	}
+	return tasks
+}
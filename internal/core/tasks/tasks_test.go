@@ -0,0 +1,96 @@
+package tasks
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/isaacgarza/dev-stack/internal/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testMakefile = `GO ?= go
+VERSION := $(shell git describe)
+LDFLAGS = -X main.version=$(VERSION)
+
+.DEFAULT_GOAL := build
+
+# Build the binary
+.PHONY: build
+build:
+	$(GO) build -ldflags "$(LDFLAGS)" ./...
+
+test lint: build ## Run the checks
+	$(GO) test ./...
+
+integration-test::
+	$(GO) test -tags integration ./...
+
+%.o: %.c
+	cc -c $<
+
+bin/app: main.go
+	$(GO) build -o $@
+`
+
+const testJustfile = `set dotenv-load
+alias b := build
+version := "1.0"
+
+# Build the binary
+build:
+    go build ./...
+
+# Run the tests matching filter
+test filter='': build
+    go test -run '{{filter}}' ./...
+
+[private]
+helper:
+    echo helper
+
+_setup:
+    echo setup
+
+@deploy env="staging":
+    ./deploy.sh {{env}}
+`
+
+func TestParseMakefile(t *testing.T) {
+	assert.Equal(t, types.TasksConfig{
+		"build":            {Description: "Build the binary", Command: "make build"},
+		"test":             {Description: "Run the checks", Command: "make test"},
+		"lint":             {Description: "Run the checks", Command: "make lint"},
+		"integration-test": {Command: "make integration-test"},
+	}, ParseMakefile([]byte(testMakefile), "make"))
+}
+
+func TestParseJustfile(t *testing.T) {
+	assert.Equal(t, types.TasksConfig{
+		"build":  {Description: "Build the binary", Command: "just build"},
+		"test":   {Description: "Run the tests matching filter", Command: "just test"},
+		"deploy": {Command: "just deploy"},
+	}, ParseJustfile([]byte(testJustfile), "just"))
+}
+
+func TestImport(t *testing.T) {
+	root := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "web"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "Makefile"), []byte(testMakefile), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "web", "tasks.just"), []byte(testJustfile), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "empty.mk"), []byte("X = 1\n"), 0644))
+
+	tasks, err := Import(root, "Makefile")
+	require.NoError(t, err)
+	assert.Equal(t, types.TaskConfig{Description: "Build the binary", Command: "make build"}, tasks["build"])
+
+	tasks, err = Import(root, "web/tasks.just")
+	require.NoError(t, err)
+	assert.Equal(t, types.TaskConfig{Description: "Build the binary", Command: "just -f tasks.just build", WorkingDir: "web"}, tasks["build"])
+
+	_, err = Import(root, "empty.mk")
+	assert.EqualError(t, err, "empty.mk: no targets found")
+	_, err = Import(root, "missing/Makefile")
+	assert.Error(t, err)
+}
@@ -0,0 +1,223 @@
+// Package telemetry records the opt-in, anonymous usage metrics of
+// dev-stack: which commands run, how long they take, how they fail and on
+// which platform. Metrics are buffered in a local file and sent to the
+// configured endpoint in batches. Arguments, service names, paths and
+// error messages are never recorded.
+package telemetry
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/isaacgarza/dev-stack/internal/pkg/constants"
+	"github.com/isaacgarza/dev-stack/internal/pkg/types"
+)
+
+// MaxBufferedEvents bounds the buffer while the endpoint can't be reached;
+// the oldest events are dropped first
+const MaxBufferedEvents = 500
+
+// Error categories of a failed command
+const (
+	ErrorInterrupted    = "interrupted"
+	ErrorTimeout        = "timeout"
+	ErrorNotInitialized = "not_initialized"
+	ErrorDocker         = "docker"
+	ErrorConfig         = "config"
+	ErrorPortConflict   = "port_conflict"
+	ErrorServiceUnknown = "service_not_found"
+	ErrorOther          = "other"
+)
+
+// Event is the record of one command run
+type Event struct {
+	Time       time.Time `json:"time"`
+	Command    string    `json:"command"`
+	DurationMs int64     `json:"duration_ms"`
+	// Error is the category of the error the command failed with, empty
+	// when it succeeded
+	Error   string `json:"error,omitempty"`
+	OS      string `json:"os"`
+	Arch    string `json:"arch"`
+	Version string `json:"version"`
+}
+
+// NewEvent creates the event of a command run that took duration and
+// returned err
+func NewEvent(command string, duration time.Duration, err error, version string) Event {
+	return Event{
+		Time:       time.Now().UTC(),
+		Command:    command,
+		DurationMs: duration.Milliseconds(),
+		Error:      ErrorCategory(err),
+		OS:         runtime.GOOS,
+		Arch:       runtime.GOARCH,
+		Version:    version,
+	}
+}
+
+// ErrorCategory classifies err without keeping its message, which may
+// mention services, paths or hosts
+func ErrorCategory(err error) string {
+	if err == nil {
+		return ""
+	}
+	switch types.ErrorKindOf(err) {
+	case types.ErrorKindInterrupted:
+		return ErrorInterrupted
+	case types.ErrorKindTimeout:
+		return ErrorTimeout
+	case types.ErrorKindDockerUnavailable:
+		return ErrorDocker
+	case types.ErrorKindConfigInvalid:
+		return ErrorConfig
+	case types.ErrorKindPortConflict:
+		return ErrorPortConflict
+	case types.ErrorKindServiceNotFound:
+		return ErrorServiceUnknown
+	}
+	message := strings.ToLower(err.Error())
+	switch {
+	case errors.Is(err, context.Canceled):
+		return ErrorInterrupted
+	case errors.Is(err, context.DeadlineExceeded) || strings.Contains(message, "timed out") || strings.Contains(message, "timeout"):
+		return ErrorTimeout
+	case strings.Contains(message, strings.ToLower(constants.ErrNotInitialized)):
+		return ErrorNotInitialized
+	case strings.Contains(message, "docker"):
+		return ErrorDocker
+	case strings.Contains(message, "config"):
+		return ErrorConfig
+	default:
+		return ErrorOther
+	}
+}
+
+// Buffer is the local file of the events waiting to be sent, one JSON
+// object per line
+type Buffer struct {
+	path string
+}
+
+// NewBuffer creates a buffer stored at path
+func NewBuffer(path string) *Buffer {
+	return &Buffer{path: path}
+}
+
+// Path returns the file of the buffer
+func (b *Buffer) Path() string {
+	return b.path
+}
+
+// Append adds event to the buffer, dropping the oldest events past
+// MaxBufferedEvents
+func (b *Buffer) Append(event Event) error {
+	events, err := b.Events()
+	if err != nil {
+		return err
+	}
+	events = append(events, event)
+	if len(events) > MaxBufferedEvents {
+		events = events[len(events)-MaxBufferedEvents:]
+	}
+	return b.write(events)
+}
+
+// Events returns the buffered events, oldest first. Lines that don't
+// parse are skipped.
+func (b *Buffer) Events() ([]Event, error) {
+	data, err := os.ReadFile(b.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read usage metrics: %w", err)
+	}
+
+	var events []Event
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		var event Event
+		if err := json.Unmarshal(scanner.Bytes(), &event); err == nil {
+			events = append(events, event)
+		}
+	}
+	return events, nil
+}
+
+// Clear removes the buffered events
+func (b *Buffer) Clear() error {
+	if err := os.Remove(b.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to remove usage metrics: %w", err)
+	}
+	return nil
+}
+
+// Flush posts the buffered events to endpoint as a JSON array and removes
+// the ones sent. It returns how many were sent.
+func (b *Buffer) Flush(ctx context.Context, httpClient *http.Client, endpoint, userAgent string) (int, error) {
+	events, err := b.Events()
+	if err != nil || len(events) == 0 {
+		return 0, err
+	}
+	body, err := json.Marshal(events)
+	if err != nil {
+		return 0, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if userAgent != "" {
+		req.Header.Set("User-Agent", userAgent)
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return 0, fmt.Errorf("usage metrics endpoint returned %s", resp.Status)
+	}
+
+	// Keep what other commands buffered while the request was in flight
+	current, err := b.Events()
+	if err != nil {
+		return len(events), err
+	}
+	if len(current) > len(events) {
+		return len(events), b.write(current[len(events):])
+	}
+	return len(events), b.Clear()
+}
+
+func (b *Buffer) write(events []Event) error {
+	var buf bytes.Buffer
+	for _, event := range events {
+		data, err := json.Marshal(event)
+		if err != nil {
+			return err
+		}
+		buf.Write(data)
+		buf.WriteByte('\n')
+	}
+	if err := os.MkdirAll(filepath.Dir(b.path), 0755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", filepath.Dir(b.path), err)
+	}
+	if err := os.WriteFile(b.path, buf.Bytes(), 0600); err != nil {
+		return fmt.Errorf("failed to write usage metrics: %w", err)
+	}
+	return nil
+}
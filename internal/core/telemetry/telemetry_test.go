@@ -0,0 +1,96 @@
+package telemetry
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/isaacgarza/dev-stack/internal/pkg/constants"
+	"github.com/isaacgarza/dev-stack/internal/pkg/types"
+)
+
+func TestErrorCategory(t *testing.T) {
+	tests := []struct {
+		err      error
+		expected string
+	}{
+		{nil, ""},
+		{fmt.Errorf("pull: %w", context.Canceled), ErrorInterrupted},
+		{context.DeadlineExceeded, ErrorTimeout},
+		{errors.New("services did not become healthy: timed out"), ErrorTimeout},
+		{errors.New(constants.ErrNotInitialized), ErrorNotInitialized},
+		{errors.New("failed to create Docker client: no socket"), ErrorDocker},
+		{errors.New("failed to load configuration: bad yaml"), ErrorConfig},
+		{fmt.Errorf("up: %w", &types.PortConflictError{Port: "5432"}), ErrorPortConflict},
+		{&types.ServiceNotFoundError{Service: "mongo"}, ErrorServiceUnknown},
+		{&types.ConfigInvalidError{Path: "dev-stack-config.yml", Err: errors.New("bad")}, ErrorConfig},
+		{errors.New("postgres exploded"), ErrorOther},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, ErrorCategory(tt.err), "%v", tt.err)
+	}
+}
+
+func TestBuffer(t *testing.T) {
+	buffer := NewBuffer(filepath.Join(t.TempDir(), "metrics", "usage.jsonl"))
+	events, err := buffer.Events()
+	require.NoError(t, err)
+	assert.Empty(t, events)
+
+	for i := 0; i < MaxBufferedEvents+2; i++ {
+		require.NoError(t, buffer.Append(Event{Command: fmt.Sprintf("dev-stack cmd%d", i)}))
+	}
+	events, err = buffer.Events()
+	require.NoError(t, err)
+	require.Len(t, events, MaxBufferedEvents)
+	assert.Equal(t, "dev-stack cmd2", events[0].Command, "the oldest events are dropped")
+
+	require.NoError(t, buffer.Clear())
+	require.NoError(t, buffer.Clear())
+	events, err = buffer.Events()
+	require.NoError(t, err)
+	assert.Empty(t, events)
+}
+
+func TestBuffer_Flush(t *testing.T) {
+	var received []Event
+	status := http.StatusAccepted
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "dev-stack", r.Header.Get("User-Agent"))
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	buffer := NewBuffer(filepath.Join(t.TempDir(), "usage.jsonl"))
+	event := NewEvent("dev-stack up", 1500*time.Millisecond, errors.New("docker daemon not running"), "v1.0.0")
+	require.NoError(t, buffer.Append(event))
+	assert.Equal(t, int64(1500), event.DurationMs)
+	assert.Equal(t, ErrorDocker, event.Error)
+
+	status = http.StatusInternalServerError
+	_, err := buffer.Flush(context.Background(), server.Client(), server.URL, "dev-stack")
+	assert.ErrorContains(t, err, "500")
+	events, err := buffer.Events()
+	require.NoError(t, err)
+	assert.Len(t, events, 1, "events are kept until they are sent")
+
+	status = http.StatusAccepted
+	sent, err := buffer.Flush(context.Background(), server.Client(), server.URL, "dev-stack")
+	require.NoError(t, err)
+	assert.Equal(t, 1, sent)
+	require.Len(t, received, 1)
+	assert.Equal(t, "dev-stack up", received[0].Command)
+	events, err = buffer.Events()
+	require.NoError(t, err)
+	assert.Empty(t, events)
+}
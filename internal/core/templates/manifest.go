@@ -0,0 +1,257 @@
+package templates
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"text/template"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/isaacgarza/dev-stack/internal/pkg/types"
+)
+
+// ManifestFileName is the manifest at the root of a template
+const ManifestFileName = "devstack-template.yaml"
+
+// RenderSuffix marks template files rendered with the variables; the
+// suffix is dropped from the file they are written to. Other files are
+// copied as they are.
+const RenderSuffix = ".tmpl"
+
+// Built-in variables, set from the init answers
+const (
+	VarProjectName = "project_name"
+	VarEnvironment = "environment"
+)
+
+// variableName matches valid variable names, which are used as
+// {{ .name }} in templates
+var variableName = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+
+// Manifest describes what a template adds to a new project
+type Manifest struct {
+	Name        string     `yaml:"name"`
+	Description string     `yaml:"description"`
+	Variables   []Variable `yaml:"variables"`
+	// Services and Environment answer the init prompts, unless given
+	Services    []string `yaml:"services"`
+	Environment string   `yaml:"environment"`
+	// Overlays are files and directories copied into the project, such as
+	// dev-stack/docker-compose.override.yml or application config
+	Overlays []Overlay `yaml:"overlays"`
+	// Seeds is the directory of seed data copied to dev-stack/seeds
+	Seeds string `yaml:"seeds"`
+	// Hooks are added to the lifecycle hooks of the project configuration
+	Hooks types.HooksConfig `yaml:"hooks"`
+}
+
+// Variable is a value asked for when the template is applied
+type Variable struct {
+	Name        string `yaml:"name"`
+	Description string `yaml:"description"`
+	// Default is rendered with the variables before it, so it can refer to
+	// them, such as "{{ .project_name }}_db"
+	Default  string `yaml:"default"`
+	Required bool   `yaml:"required"`
+}
+
+// Overlay copies Source, a path in the template, to Target, a path in the
+// project
+type Overlay struct {
+	Source string `yaml:"source"`
+	Target string `yaml:"target"`
+}
+
+// LoadManifest reads the manifest of the template at dir, without rendering
+// it, which is enough to learn its variables and default answers
+func LoadManifest(dir string) (*Manifest, error) {
+	data, err := os.ReadFile(filepath.Join(dir, ManifestFileName))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("%s has no %s; it is not a dev-stack template", dir, ManifestFileName)
+		}
+		return nil, fmt.Errorf("failed to read the template manifest: %w", err)
+	}
+	return parseManifest(data)
+}
+
+// RenderManifest reads the manifest of the template at dir with the
+// variables substituted
+func RenderManifest(dir string, vars map[string]string) (*Manifest, error) {
+	data, err := os.ReadFile(filepath.Join(dir, ManifestFileName))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the template manifest: %w", err)
+	}
+	rendered, err := Render(ManifestFileName, string(data), vars)
+	if err != nil {
+		return nil, err
+	}
+	return parseManifest([]byte(rendered))
+}
+
+// parseManifest parses and checks manifest content
+func parseManifest(data []byte) (*Manifest, error) {
+	var manifest Manifest
+	if err := yaml.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", ManifestFileName, err)
+	}
+	for _, variable := range manifest.Variables {
+		if !variableName.MatchString(variable.Name) {
+			return nil, fmt.Errorf("template variable %q must be letters, digits and underscores", variable.Name)
+		}
+	}
+	for _, overlay := range manifest.Overlays {
+		if overlay.Source == "" || overlay.Target == "" {
+			return nil, errors.New("template overlays must set source and target")
+		}
+	}
+	return &manifest, nil
+}
+
+// ResolveVariables returns the value of every variable: the given one,
+// otherwise the one ask returns, otherwise the rendered default. ask may be
+// nil to take the defaults. builtins are available to every default.
+func (m *Manifest) ResolveVariables(builtins, given map[string]string, ask func(variable Variable, defaultValue string) (string, error)) (map[string]string, error) {
+	vars := make(map[string]string, len(builtins)+len(m.Variables))
+	for name, value := range builtins {
+		vars[name] = value
+	}
+
+	for _, variable := range m.Variables {
+		value, ok := given[variable.Name]
+		if !ok {
+			defaultValue, err := Render(variable.Name, variable.Default, vars)
+			if err != nil {
+				return nil, err
+			}
+			value = defaultValue
+			if ask != nil {
+				if value, err = ask(variable, defaultValue); err != nil {
+					return nil, err
+				}
+			}
+		}
+		if value == "" && variable.Required {
+			return nil, fmt.Errorf("template variable %s is required", variable.Name)
+		}
+		vars[variable.Name] = value
+	}
+
+	for name := range given {
+		if _, ok := vars[name]; !ok {
+			return nil, fmt.Errorf("the template has no variable %s", name)
+		}
+	}
+	return vars, nil
+}
+
+// Render substitutes the variables in content. Referring to a variable
+// that doesn't exist is an error.
+func Render(name, content string, vars map[string]string) (string, error) {
+	tmpl, err := template.New(name).Option("missingkey=error").Parse(content)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse template %s: %w", name, err)
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, vars); err != nil {
+		return "", fmt.Errorf("failed to render template %s: %w", name, err)
+	}
+	return buf.String(), nil
+}
+
+// Apply copies the overlays and seeds of the template at dir into the
+// project at root and returns the paths written, relative to root.
+// Existing files are only replaced with force.
+func (m *Manifest) Apply(dir, root, seedsDir string, vars map[string]string, force bool) ([]string, error) {
+	copies := append([]Overlay{}, m.Overlays...)
+	if m.Seeds != "" {
+		copies = append(copies, Overlay{Source: m.Seeds, Target: seedsDir})
+	}
+
+	var written []string
+	for _, overlay := range copies {
+		source, err := within(dir, overlay.Source)
+		if err != nil {
+			return written, err
+		}
+		target, err := within(root, overlay.Target)
+		if err != nil {
+			return written, err
+		}
+
+		err = filepath.WalkDir(source, func(path string, entry fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if entry.IsDir() {
+				if entry.Name() == ".git" {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			rel, err := filepath.Rel(source, path)
+			if err != nil {
+				return err
+			}
+			dest := target
+			if rel != "." {
+				dest = filepath.Join(target, rel)
+			}
+			dest, err = copyFile(path, dest, vars, force)
+			if err != nil {
+				return err
+			}
+			if dest, err = filepath.Rel(root, dest); err == nil {
+				written = append(written, dest)
+			}
+			return err
+		})
+		if err != nil {
+			return written, fmt.Errorf("failed to apply %s: %w", overlay.Source, err)
+		}
+	}
+	return written, nil
+}
+
+// copyFile copies a template file to dest, rendering it when it has the
+// render suffix, and returns the path written
+func copyFile(path, dest string, vars map[string]string, force bool) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	if strings.HasSuffix(path, RenderSuffix) {
+		dest = strings.TrimSuffix(dest, RenderSuffix)
+		rendered, err := Render(filepath.Base(path), string(data), vars)
+		if err != nil {
+			return "", err
+		}
+		data = []byte(rendered)
+	}
+
+	if _, err := os.Stat(dest); err == nil && !force {
+		return "", fmt.Errorf("%s already exists; use --force to overwrite it", dest)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+		return "", err
+	}
+	return dest, os.WriteFile(dest, data, info.Mode().Perm())
+}
+
+// within joins path to root, refusing paths that lead outside of it
+func within(root, path string) (string, error) {
+	if filepath.IsAbs(path) || !filepath.IsLocal(filepath.Clean(path)) {
+		return "", fmt.Errorf("template path %s must stay within %s", path, root)
+	}
+	return filepath.Join(root, path), nil
+}
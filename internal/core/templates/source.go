@@ -0,0 +1,146 @@
+// Package templates fetches project templates from Git repositories and
+// applies their manifest to a new project.
+package templates
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// gitCmd clones and updates template repositories
+const gitCmd = "git"
+
+// cacheKeyChars matches what may not appear in a cache directory name
+var cacheKeyChars = regexp.MustCompile(`[^a-zA-Z0-9._-]+`)
+
+// Source is where a template comes from
+type Source struct {
+	// URL is the repository to clone; empty for a local directory
+	URL string
+	// Revision is the branch or tag to check out; empty for the default
+	// branch
+	Revision string
+	// Dir is the local directory of the template, when it isn't cloned
+	Dir string
+}
+
+// ParseRef parses a template reference. Existing directories are used as
+// they are. Anything else is a repository: a URL, an scp-like address such
+// as git@github.com:org/repo, or a host and path such as
+// github.com/org/repo, which is cloned over HTTPS. A trailing @ref selects
+// a branch or tag.
+func ParseRef(ref string) (Source, error) {
+	if ref == "" {
+		return Source{}, errors.New("empty template reference")
+	}
+	if info, err := os.Stat(ref); err == nil && info.IsDir() {
+		return Source{Dir: ref}, nil
+	}
+
+	url, revision := ref, ""
+	if at := strings.LastIndex(ref, "@"); at > strings.LastIndexAny(ref, "/:") {
+		url, revision = ref[:at], ref[at+1:]
+		if revision == "" {
+			return Source{}, fmt.Errorf("template reference %q has an empty revision", ref)
+		}
+	}
+	if !strings.Contains(url, "://") && !strings.HasPrefix(url, "git@") {
+		if !strings.Contains(url, "/") {
+			return Source{}, fmt.Errorf("template %q is neither a directory nor a repository", ref)
+		}
+		url = "https://" + url
+	}
+	return Source{URL: url, Revision: revision}, nil
+}
+
+// CacheDir returns the host-level directory templates are cloned to
+func CacheDir() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	return filepath.Join(home, ".dev-stack", "templates"), nil
+}
+
+// cacheKey returns the cache directory name of a repository source
+func (s Source) cacheKey() string {
+	key := s.URL
+	if i := strings.Index(key, "://"); i >= 0 {
+		key = key[i+3:]
+	}
+	key = strings.TrimSuffix(strings.Trim(key, "/"), ".git")
+	if s.Revision != "" {
+		key += "@" + s.Revision
+	}
+	return strings.Trim(cacheKeyChars.ReplaceAllString(key, "-"), "-")
+}
+
+// Cached returns the directory of the cached clone of a repository source
+// and whether there is one
+func Cached(source Source, cacheDir string) (string, bool) {
+	dir := filepath.Join(cacheDir, source.cacheKey())
+	_, err := os.Stat(filepath.Join(dir, ".git"))
+	return dir, err == nil
+}
+
+// Clone makes a shallow clone of a repository source in cacheDir and
+// returns its directory. It clones next to the cache entry first, so a
+// failed clone never leaves a broken one.
+func Clone(ctx context.Context, source Source, cacheDir string) (string, error) {
+	if _, err := exec.LookPath(gitCmd); err != nil {
+		return "", errors.New("templates from repositories need git; install it and try again")
+	}
+	dir := filepath.Join(cacheDir, source.cacheKey())
+	if err := os.MkdirAll(cacheDir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create %s: %w", cacheDir, err)
+	}
+	tmp, err := os.MkdirTemp(cacheDir, filepath.Base(dir)+".tmp-")
+	if err != nil {
+		return "", fmt.Errorf("failed to create a clone directory: %w", err)
+	}
+	defer func() { _ = os.RemoveAll(tmp) }()
+
+	args := []string{"clone", "--quiet", "--depth", "1"}
+	if source.Revision != "" {
+		args = append(args, "--branch", source.Revision)
+	}
+	if err := git(ctx, append(args, source.URL, tmp)...); err != nil {
+		return "", fmt.Errorf("failed to clone template %s: %w", source.URL, err)
+	}
+
+	_ = os.RemoveAll(dir)
+	if err := os.Rename(tmp, dir); err != nil {
+		return "", fmt.Errorf("failed to cache template %s: %w", source.URL, err)
+	}
+	return dir, nil
+}
+
+// Update moves a cached clone to the latest commit of its revision
+func Update(ctx context.Context, dir, revision string) error {
+	if revision == "" {
+		revision = "HEAD"
+	}
+	if err := git(ctx, "-C", dir, "fetch", "--quiet", "--depth", "1", "origin", revision); err != nil {
+		return fmt.Errorf("failed to update the cached template: %w", err)
+	}
+	if err := git(ctx, "-C", dir, "reset", "--quiet", "--hard", "FETCH_HEAD"); err != nil {
+		return fmt.Errorf("failed to update the cached template: %w", err)
+	}
+	return nil
+}
+
+// git runs a git command, keeping it from prompting for credentials
+func git(ctx context.Context, args ...string) error {
+	cmd := exec.CommandContext(ctx, gitCmd, args...)
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("%w: %s", err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
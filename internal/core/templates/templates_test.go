@@ -0,0 +1,194 @@
+package templates
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testManifest = `name: spring
+description: Spring Boot service
+variables:
+  - name: package
+    default: com.example
+  - name: database
+    default: "{{ .project_name }}_db"
+services: [postgres, kafka]
+environment: local
+overlays:
+  - source: overlays
+    target: dev-stack
+seeds: seeds
+hooks:
+  post_up:
+    - name: migrate
+      run: ./mvnw -Ddb={{ .database }} flyway:migrate
+`
+
+// writeTemplate creates a template with the test manifest and returns its
+// directory
+func writeTemplate(t *testing.T) string {
+	dir := t.TempDir()
+	files := map[string]string{
+		ManifestFileName: testManifest,
+		"overlays/docker-compose.override.yml.tmpl": "# {{ .package }}\nservices: {}\n",
+		"overlays/readme.txt":                       "{{ not rendered }}\n",
+		"seeds/postgres/001_schema.sql.tmpl":        "CREATE DATABASE {{ .database }};\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+	return dir
+}
+
+func TestParseRef(t *testing.T) {
+	local := t.TempDir()
+	tests := map[string]Source{
+		local:                                    {Dir: local},
+		"github.com/org/devstack-template":       {URL: "https://github.com/org/devstack-template"},
+		"github.com/org/devstack-template@v2":    {URL: "https://github.com/org/devstack-template", Revision: "v2"},
+		"https://gitlab.com/org/tpl.git":         {URL: "https://gitlab.com/org/tpl.git"},
+		"https://user@gitlab.com/org/tpl.git@v1": {URL: "https://user@gitlab.com/org/tpl.git", Revision: "v1"},
+		"git@github.com:org/tpl":                 {URL: "git@github.com:org/tpl"},
+		"git@github.com:org/tpl@main":            {URL: "git@github.com:org/tpl", Revision: "main"},
+	}
+	for ref, want := range tests {
+		source, err := ParseRef(ref)
+		require.NoError(t, err, ref)
+		assert.Equal(t, want, source, ref)
+	}
+
+	for _, ref := range []string{"", "spring", "github.com/org/tpl@"} {
+		_, err := ParseRef(ref)
+		assert.Error(t, err, ref)
+	}
+}
+
+func TestCacheKey(t *testing.T) {
+	assert.Equal(t, "github.com-org-tpl", Source{URL: "https://github.com/org/tpl.git"}.cacheKey())
+	assert.Equal(t, "git-github.com-org-tpl-v2", Source{URL: "git@github.com:org/tpl", Revision: "v2"}.cacheKey())
+}
+
+func TestResolveVariables(t *testing.T) {
+	manifest, err := LoadManifest(writeTemplate(t))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"postgres", "kafka"}, manifest.Services)
+
+	builtins := map[string]string{VarProjectName: "shop", VarEnvironment: "local"}
+	vars, err := manifest.ResolveVariables(builtins, map[string]string{"package": "com.acme"}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "com.acme", vars["package"])
+	assert.Equal(t, "shop_db", vars["database"])
+
+	var asked []string
+	vars, err = manifest.ResolveVariables(builtins, nil, func(variable Variable, defaultValue string) (string, error) {
+		asked = append(asked, variable.Name+"="+defaultValue)
+		return "answered", nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"package=com.example", "database=shop_db"}, asked)
+	assert.Equal(t, "answered", vars["database"])
+
+	_, err = manifest.ResolveVariables(builtins, map[string]string{"port": "8080"}, nil)
+	assert.ErrorContains(t, err, "no variable port")
+
+	manifest.Variables = append(manifest.Variables, Variable{Name: "team", Required: true})
+	_, err = manifest.ResolveVariables(builtins, nil, nil)
+	assert.ErrorContains(t, err, "team is required")
+}
+
+func TestRender(t *testing.T) {
+	rendered, err := Render("test", "db={{ .database }} ${PORT:-5432}", map[string]string{"database": "shop"})
+	require.NoError(t, err)
+	assert.Equal(t, "db=shop ${PORT:-5432}", rendered)
+
+	_, err = Render("test", "{{ .missing }}", map[string]string{})
+	assert.Error(t, err)
+}
+
+func TestApply(t *testing.T) {
+	dir := writeTemplate(t)
+	vars := map[string]string{VarProjectName: "shop", "package": "com.acme", "database": "shop_db"}
+	manifest, err := RenderManifest(dir, vars)
+	require.NoError(t, err)
+	assert.Equal(t, "./mvnw -Ddb=shop_db flyway:migrate", manifest.Hooks["post_up"][0].Run)
+
+	root := t.TempDir()
+	written, err := manifest.Apply(dir, root, filepath.Join("dev-stack", "seeds"), vars, false)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{
+		filepath.Join("dev-stack", "docker-compose.override.yml"),
+		filepath.Join("dev-stack", "readme.txt"),
+		filepath.Join("dev-stack", "seeds", "postgres", "001_schema.sql"),
+	}, written)
+
+	content, err := os.ReadFile(filepath.Join(root, "dev-stack", "docker-compose.override.yml"))
+	require.NoError(t, err)
+	assert.Equal(t, "# com.acme\nservices: {}\n", string(content))
+	content, err = os.ReadFile(filepath.Join(root, "dev-stack", "readme.txt"))
+	require.NoError(t, err)
+	assert.Equal(t, "{{ not rendered }}\n", string(content))
+	content, err = os.ReadFile(filepath.Join(root, "dev-stack", "seeds", "postgres", "001_schema.sql"))
+	require.NoError(t, err)
+	assert.Equal(t, "CREATE DATABASE shop_db;\n", string(content))
+
+	// Existing files are kept unless forced
+	_, err = manifest.Apply(dir, root, filepath.Join("dev-stack", "seeds"), vars, false)
+	assert.ErrorContains(t, err, "already exists")
+	_, err = manifest.Apply(dir, root, filepath.Join("dev-stack", "seeds"), vars, true)
+	assert.NoError(t, err)
+
+	manifest.Overlays = []Overlay{{Source: "overlays", Target: "../outside"}}
+	_, err = manifest.Apply(dir, root, "seeds", vars, true)
+	assert.ErrorContains(t, err, "must stay within")
+}
+
+func TestCloneAndUpdate(t *testing.T) {
+	if _, err := exec.LookPath(gitCmd); err != nil {
+		t.Skip("git is not installed")
+	}
+	ctx := context.Background()
+
+	repo := writeTemplate(t)
+	runGit := func(args ...string) {
+		cmd := exec.Command(gitCmd, append([]string{"-C", repo, "-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...)
+		output, err := cmd.CombinedOutput()
+		require.NoError(t, err, string(output))
+	}
+	runGit("init", "--quiet")
+	runGit("add", ".")
+	runGit("commit", "--quiet", "-m", "template")
+
+	source := Source{URL: "file://" + repo}
+	cacheDir := t.TempDir()
+	_, cached := Cached(source, cacheDir)
+	assert.False(t, cached)
+
+	dir, err := Clone(ctx, source, cacheDir)
+	require.NoError(t, err)
+	cachedDir, cached := Cached(source, cacheDir)
+	assert.True(t, cached)
+	assert.Equal(t, dir, cachedDir)
+	assert.FileExists(t, filepath.Join(dir, ManifestFileName))
+
+	// Later commits reach the cache on update
+	require.NoError(t, os.WriteFile(filepath.Join(repo, "NEW.md"), []byte("new\n"), 0644))
+	runGit("add", ".")
+	runGit("commit", "--quiet", "-m", "update")
+	require.NoError(t, Update(ctx, dir, ""))
+	assert.FileExists(t, filepath.Join(dir, "NEW.md"))
+
+	// A failed clone leaves no cache entry
+	_, err = Clone(ctx, Source{URL: "file://" + filepath.Join(t.TempDir(), "missing")}, cacheDir)
+	assert.Error(t, err)
+	entries, err := os.ReadDir(cacheDir)
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+}
@@ -0,0 +1,245 @@
+// Package tracing checks the tracing pipeline of a stack by sending test
+// spans to its OpenTelemetry collector and looking them up in Jaeger
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ServiceName is the service collecting and serving the stack's traces
+const ServiceName = "jaeger"
+
+// CollectorEndpoint is the OTLP/HTTP endpoint of the collector on the
+// stack's network, as containers reach it
+const CollectorEndpoint = "http://" + ServiceName + ":4318"
+
+// DefaultPingServiceName is the service test spans are reported as
+const DefaultPingServiceName = "dev-stack-ping"
+
+// pollInterval is how often Jaeger is asked for a span sent by Ping
+const pollInterval = 500 * time.Millisecond
+
+// ExporterEnv returns the OpenTelemetry SDK variables making a service
+// export its traces to the collector over OTLP/HTTP
+func ExporterEnv(serviceName string) map[string]string {
+	return map[string]string{
+		"OTEL_EXPORTER_OTLP_ENDPOINT": CollectorEndpoint,
+		"OTEL_EXPORTER_OTLP_PROTOCOL": "http/protobuf",
+		"OTEL_TRACES_EXPORTER":        "otlp",
+		"OTEL_SERVICE_NAME":           serviceName,
+	}
+}
+
+// Client sends spans to a collector and looks traces up in Jaeger
+type Client struct {
+	httpClient *http.Client
+	// collectorURL is the OTLP/HTTP endpoint spans are sent to
+	collectorURL string
+	// queryURL is the base URL of the Jaeger UI and its API
+	queryURL string
+}
+
+// NewClient creates a client for the collector at collectorURL and the
+// Jaeger UI at queryURL. A nil httpClient uses http.DefaultClient.
+func NewClient(httpClient *http.Client, collectorURL, queryURL string) *Client {
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	return &Client{
+		httpClient:   httpClient,
+		collectorURL: strings.TrimSuffix(collectorURL, "/"),
+		queryURL:     strings.TrimSuffix(queryURL, "/"),
+	}
+}
+
+// Span is a single span of its own trace
+type Span struct {
+	TraceID     string
+	SpanID      string
+	ServiceName string
+	Name        string
+	Start       time.Time
+	End         time.Time
+}
+
+// NewSpan creates a span of a new trace reported by serviceName, ending at
+// end
+func NewSpan(serviceName, name string, end time.Time) (Span, error) {
+	traceID, err := randomID(16)
+	if err != nil {
+		return Span{}, err
+	}
+	spanID, err := randomID(8)
+	if err != nil {
+		return Span{}, err
+	}
+	return Span{
+		TraceID:     traceID,
+		SpanID:      spanID,
+		ServiceName: serviceName,
+		Name:        name,
+		Start:       end.Add(-time.Millisecond),
+		End:         end,
+	}, nil
+}
+
+func randomID(size int) (string, error) {
+	id := make([]byte, size)
+	if _, err := rand.Read(id); err != nil {
+		return "", fmt.Errorf("failed to generate an ID: %w", err)
+	}
+	return hex.EncodeToString(id), nil
+}
+
+// Payload renders the span as an OTLP/HTTP JSON export request
+func (s Span) Payload() ([]byte, error) {
+	type attribute struct {
+		Key   string            `json:"key"`
+		Value map[string]string `json:"value"`
+	}
+	span := map[string]any{
+		"traceId":           s.TraceID,
+		"spanId":            s.SpanID,
+		"name":              s.Name,
+		"kind":              1,
+		"startTimeUnixNano": strconv.FormatInt(s.Start.UnixNano(), 10),
+		"endTimeUnixNano":   strconv.FormatInt(s.End.UnixNano(), 10),
+		"attributes":        []attribute{{Key: "dev-stack.ping", Value: map[string]string{"stringValue": "true"}}},
+	}
+	request := map[string]any{
+		"resourceSpans": []map[string]any{{
+			"resource": map[string]any{
+				"attributes": []attribute{{Key: "service.name", Value: map[string]string{"stringValue": s.ServiceName}}},
+			},
+			"scopeSpans": []map[string]any{{
+				"scope": map[string]string{"name": "dev-stack"},
+				"spans": []map[string]any{span},
+			}},
+		}},
+	}
+	return json.Marshal(request)
+}
+
+// Send exports a span to the collector
+func (c *Client) Send(ctx context.Context, span Span) error {
+	payload, err := span.Payload()
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.collectorURL+"/v1/traces", bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send a span to the collector at %s: %w", c.collectorURL, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("collector at %s rejected the span: %s", c.collectorURL, responseError(resp))
+	}
+	return nil
+}
+
+// Find reports whether Jaeger holds the trace with the given ID
+func (c *Client) Find(ctx context.Context, traceID string) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.TraceURL(traceID, true), nil)
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return false, fmt.Errorf("failed to query Jaeger at %s: %w", c.queryURL, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return false, nil
+	case resp.StatusCode != http.StatusOK:
+		return false, fmt.Errorf("jaeger at %s failed to look up trace %s: %s", c.queryURL, traceID, responseError(resp))
+	}
+	var body struct {
+		Data []struct {
+			Spans []json.RawMessage `json:"spans"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return false, fmt.Errorf("failed to decode the Jaeger response: %w", err)
+	}
+	return len(body.Data) > 0 && len(body.Data[0].Spans) > 0, nil
+}
+
+// TraceURL returns the page of a trace in the Jaeger UI, or its API
+// endpoint
+func (c *Client) TraceURL(traceID string, api bool) string {
+	if api {
+		return c.queryURL + "/api/traces/" + traceID
+	}
+	return c.queryURL + "/trace/" + traceID
+}
+
+// PingResult is a span that went through the pipeline
+type PingResult struct {
+	TraceID string
+	// Latency is how long the span took to become searchable in Jaeger
+	// after it was sent
+	Latency time.Duration
+}
+
+// Ping sends a span reported by serviceName to the collector and waits up
+// to wait for Jaeger to hold it
+func (c *Client) Ping(ctx context.Context, serviceName string, wait time.Duration) (*PingResult, error) {
+	span, err := NewSpan(serviceName, "ping", time.Now())
+	if err != nil {
+		return nil, err
+	}
+	sent := time.Now()
+	if err := c.Send(ctx, span); err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, wait)
+	defer cancel()
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+	for {
+		found, err := c.Find(ctx, span.TraceID)
+		if found {
+			return &PingResult{TraceID: span.TraceID, Latency: time.Since(sent)}, nil
+		}
+		if err != nil && ctx.Err() == nil {
+			return nil, err
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("the collector accepted trace %s but Jaeger didn't return it within %s", span.TraceID, wait)
+		case <-ticker.C:
+		}
+	}
+}
+
+// responseError returns the status of a failed response with the start of
+// its body
+func responseError(resp *http.Response) string {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	if message := strings.TrimSpace(string(body)); message != "" {
+		return resp.Status + ": " + message
+	}
+	return resp.Status
+}
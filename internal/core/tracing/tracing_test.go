@@ -0,0 +1,106 @@
+package tracing
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeJaeger accepts OTLP spans and serves the traces it received after
+// delay has passed
+type fakeJaeger struct {
+	mu       sync.Mutex
+	received map[string]time.Time
+	delay    time.Duration
+	reject   bool
+}
+
+func (f *fakeJaeger) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	switch {
+	case r.Method == http.MethodPost && r.URL.Path == "/v1/traces":
+		if f.reject {
+			http.Error(w, "unsupported content type", http.StatusUnsupportedMediaType)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		var request struct {
+			ResourceSpans []struct {
+				ScopeSpans []struct {
+					Spans []struct {
+						TraceID string `json:"traceId"`
+					} `json:"spans"`
+				} `json:"scopeSpans"`
+			} `json:"resourceSpans"`
+		}
+		if err := json.Unmarshal(body, &request); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		f.received[request.ResourceSpans[0].ScopeSpans[0].Spans[0].TraceID] = time.Now()
+		w.WriteHeader(http.StatusOK)
+	case strings.HasPrefix(r.URL.Path, "/api/traces/"):
+		received, ok := f.received[strings.TrimPrefix(r.URL.Path, "/api/traces/")]
+		if !ok || time.Since(received) < f.delay {
+			http.Error(w, `{"errors":[{"code":404,"msg":"trace not found"}]}`, http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte(`{"data":[{"spans":[{"spanID":"1"}]}]}`))
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func TestSpan_Payload(t *testing.T) {
+	end := time.Unix(1700000000, 0)
+	span, err := NewSpan("api", "ping", end)
+	require.NoError(t, err)
+	assert.Len(t, span.TraceID, 32)
+	assert.Len(t, span.SpanID, 16)
+
+	payload, err := span.Payload()
+	require.NoError(t, err)
+	assert.Contains(t, string(payload), `"attributes":[{"key":"service.name","value":{"stringValue":"api"}}]`)
+	assert.Contains(t, string(payload), `"endTimeUnixNano":"1700000000000000000"`)
+	assert.Contains(t, string(payload), `"traceId":"`+span.TraceID+`"`)
+}
+
+func TestClient_Ping(t *testing.T) {
+	jaeger := &fakeJaeger{received: make(map[string]time.Time), delay: 600 * time.Millisecond}
+	server := httptest.NewServer(jaeger)
+	defer server.Close()
+
+	client := NewClient(server.Client(), server.URL, server.URL+"/")
+	result, err := client.Ping(context.Background(), DefaultPingServiceName, 5*time.Second)
+	require.NoError(t, err)
+	assert.Contains(t, jaeger.received, result.TraceID)
+	assert.GreaterOrEqual(t, result.Latency, jaeger.delay)
+	assert.Equal(t, server.URL+"/trace/"+result.TraceID, client.TraceURL(result.TraceID, false))
+}
+
+func TestClient_Ping_Failures(t *testing.T) {
+	jaeger := &fakeJaeger{received: make(map[string]time.Time), reject: true}
+	server := httptest.NewServer(jaeger)
+	defer server.Close()
+
+	client := NewClient(server.Client(), server.URL, server.URL)
+	_, err := client.Ping(context.Background(), DefaultPingServiceName, time.Second)
+	assert.EqualError(t, err, "collector at "+server.URL+" rejected the span: 415 Unsupported Media Type: unsupported content type")
+
+	// Spans the collector accepts but never stores
+	jaeger.reject, jaeger.delay = false, time.Hour
+	_, err = client.Ping(context.Background(), DefaultPingServiceName, time.Second)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "but Jaeger didn't return it within 1s")
+}
@@ -0,0 +1,193 @@
+// Package trust builds the certificate stores containers of a stack trust:
+// a PEM bundle for OpenSSL and the TLS libraries of most languages, and a
+// Java keystore for JVMs, each holding the public roots of the host and the
+// certificate authorities the project adds
+package trust
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"crypto/x509"
+	"encoding/binary"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"unicode/utf16"
+)
+
+// Families of trust stores, by where the distribution of an image keeps the
+// PEM bundle of the certificates it trusts
+const (
+	StoreDebian = "debian"
+	StoreAlpine = "alpine"
+	StoreRHEL   = "rhel"
+)
+
+// storePaths are the PEM bundles of each family of trust stores
+var storePaths = map[string]string{
+	StoreDebian: "/etc/ssl/certs/ca-certificates.crt",
+	StoreAlpine: "/etc/ssl/certs/ca-certificates.crt",
+	StoreRHEL:   "/etc/pki/tls/certs/ca-bundle.crt",
+}
+
+// KeystorePassword is the password of the Java keystore, the one JDKs ship
+// their cacerts with
+const KeystorePassword = "changeit"
+
+// systemBundles are where Linux distributions and macOS keep the PEM bundle
+// of the public roots they trust, the same files crypto/x509 reads
+var systemBundles = []string{
+	"/etc/ssl/certs/ca-certificates.crt",
+	"/etc/pki/tls/certs/ca-bundle.crt",
+	"/etc/ssl/ca-bundle.pem",
+	"/etc/pki/tls/cacert.pem",
+	"/etc/pki/ca-trust/extracted/pem/tls-ca-bundle.pem",
+	"/etc/ssl/cert.pem",
+}
+
+// StorePath returns the PEM bundle of a family of trust stores
+func StorePath(store string) (string, bool) {
+	path, ok := storePaths[store]
+	return path, ok
+}
+
+// GuessStore returns the family of the trust store of an image from the
+// variant its tag names, such as alpine or oracle, and Debian, the base of
+// most images, otherwise
+func GuessStore(image string) string {
+	name := image[strings.LastIndex(image, "/")+1:]
+	switch {
+	case strings.Contains(name, "alpine"):
+		return StoreAlpine
+	case strings.Contains(name, "oracle"), strings.Contains(name, "ubi"):
+		return StoreRHEL
+	}
+	return StoreDebian
+}
+
+// Env returns the variables pointing the TLS libraries that ignore the
+// system store of the image, such as those of Python, Node.js and the AWS
+// CLI, at the bundle mounted at path
+func Env(path string) map[string]string {
+	return map[string]string{
+		"SSL_CERT_FILE":       path,
+		"REQUESTS_CA_BUNDLE":  path,
+		"CURL_CA_BUNDLE":      path,
+		"NODE_EXTRA_CA_CERTS": path,
+		"AWS_CA_BUNDLE":       path,
+	}
+}
+
+// SystemBundle returns the path of the PEM bundle of the host's public
+// roots, or an empty string on hosts that keep none, such as Windows
+func SystemBundle() string {
+	for _, path := range systemBundles {
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			return path
+		}
+	}
+	return ""
+}
+
+// ReadBundles reads the certificates of PEM files, skipping those listed
+// more than once. Each file must hold at least one certificate.
+func ReadBundles(paths ...string) ([]*x509.Certificate, error) {
+	var certs []*x509.Certificate
+	seen := make(map[string]bool)
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		bundle, err := ParseBundle(data)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		for _, cert := range bundle {
+			if !seen[string(cert.Raw)] {
+				seen[string(cert.Raw)] = true
+				certs = append(certs, cert)
+			}
+		}
+	}
+	return certs, nil
+}
+
+// ParseBundle parses the certificates of a PEM bundle, ignoring blocks of
+// other types
+func ParseBundle(data []byte) ([]*x509.Certificate, error) {
+	var certs []*x509.Certificate
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("invalid certificate: %w", err)
+		}
+		certs = append(certs, cert)
+	}
+	if len(certs) == 0 {
+		return nil, errors.New("no PEM certificate found")
+	}
+	return certs, nil
+}
+
+// EncodePEM returns certificates as a PEM bundle
+func EncodePEM(certs []*x509.Certificate) []byte {
+	var buf bytes.Buffer
+	for _, cert := range certs {
+		_ = pem.Encode(&buf, &pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw})
+	}
+	return buf.Bytes()
+}
+
+// JKS format constants, as the JDK's JavaKeyStore reads them
+const (
+	jksMagic        = 0xFEEDFEED
+	jksVersion      = 2
+	jksTrustedCert  = 2
+	jksDigestSecret = "Mighty Aphrodite"
+)
+
+// EncodeJKS returns certificates as a Java keystore of trusted certificate
+// entries protected by password, which JDKs read in place of their cacerts
+// whatever keystore type they default to. Entries are dated from the start
+// of the validity of their certificate, so the same certificates always
+// give the same keystore.
+func EncodeJKS(certs []*x509.Certificate, password string) []byte {
+	var buf bytes.Buffer
+	write := func(v any) { _ = binary.Write(&buf, binary.BigEndian, v) }
+	writeUTF := func(s string) {
+		write(uint16(len(s)))
+		buf.WriteString(s)
+	}
+
+	write(uint32(jksMagic))
+	write(uint32(jksVersion))
+	write(uint32(len(certs)))
+	for i, cert := range certs {
+		write(uint32(jksTrustedCert))
+		writeUTF(fmt.Sprintf("dev-stack-%d", i))
+		write(cert.NotBefore.UnixMilli())
+		writeUTF("X.509")
+		write(uint32(len(cert.Raw)))
+		buf.Write(cert.Raw)
+	}
+
+	digest := sha1.New()
+	for _, unit := range utf16.Encode([]rune(password)) {
+		_ = binary.Write(digest, binary.BigEndian, unit)
+	}
+	digest.Write([]byte(jksDigestSecret))
+	digest.Write(buf.Bytes())
+	buf.Write(digest.Sum(nil))
+	return buf.Bytes()
+}
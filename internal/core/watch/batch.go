@@ -0,0 +1,142 @@
+package watch
+
+import (
+	"os"
+	"path"
+	"sort"
+
+	"github.com/isaacgarza/dev-stack/internal/pkg/types"
+)
+
+// Batch is the work for one service after a burst of changes
+type Batch struct {
+	Service string
+	Action  string
+	Paths   []string
+	Syncs   []Sync
+}
+
+// Sync copies a changed file into the container, or removes it there when
+// it was deleted on the host
+type Sync struct {
+	Source  string
+	Target  string
+	Removed bool
+}
+
+// NeedsSync reports whether the batch copies files into the container
+func (b Batch) NeedsSync() bool {
+	return b.Action == types.WatchActionSync || b.Action == types.WatchActionSyncRestart
+}
+
+// NeedsRestart reports whether the batch restarts the container
+func (b Batch) NeedsRestart() bool {
+	return b.Action == types.WatchActionRestart || b.Action == types.WatchActionSyncRestart
+}
+
+// collector accumulates changes per service until they are flushed
+type collector struct {
+	rules   []Rule
+	pending map[string]*Batch
+	seen    map[string]map[string]bool
+}
+
+func newCollector(rules []Rule) *collector {
+	return &collector{rules: rules, pending: map[string]*Batch{}, seen: map[string]map[string]bool{}}
+}
+
+// add records a changed path against every rule that covers it and reports
+// whether any did
+func (c *collector) add(changed string) bool {
+	matched := false
+	for _, rule := range c.rules {
+		rel, ok := rule.Match(changed)
+		if !ok {
+			continue
+		}
+		matched = true
+
+		batch := c.pending[rule.Service]
+		if batch == nil {
+			batch = &Batch{Service: rule.Service, Action: rule.Action}
+			c.pending[rule.Service] = batch
+			c.seen[rule.Service] = map[string]bool{}
+		}
+		batch.Action = combineActions(batch.Action, rule.Action)
+
+		if !c.seen[rule.Service][changed] {
+			c.seen[rule.Service][changed] = true
+			batch.Paths = append(batch.Paths, changed)
+		}
+		if rule.Action == types.WatchActionSync || rule.Action == types.WatchActionSyncRestart {
+			target := rule.Target
+			if rel != "." {
+				target = path.Join(rule.Target, rel)
+			}
+			batch.Syncs = append(batch.Syncs, Sync{Source: changed, Target: target})
+		}
+	}
+	return matched
+}
+
+// flush returns the pending batches ordered by service and resets the
+// collector. Syncs are deduplicated, directories are left to the files
+// inside them, and sources that no longer exist become removals.
+func (c *collector) flush() []Batch {
+	batches := make([]Batch, 0, len(c.pending))
+	for _, batch := range c.pending {
+		if batch.Action == types.WatchActionRebuild {
+			batch.Syncs = nil
+		}
+		batch.Syncs = dedupeSyncs(batch.Syncs)
+		sort.Strings(batch.Paths)
+		batches = append(batches, *batch)
+	}
+	sort.Slice(batches, func(i, j int) bool { return batches[i].Service < batches[j].Service })
+
+	c.pending = map[string]*Batch{}
+	c.seen = map[string]map[string]bool{}
+	return batches
+}
+
+func dedupeSyncs(syncs []Sync) []Sync {
+	seen := make(map[string]bool, len(syncs))
+	result := syncs[:0]
+	for _, sync := range syncs {
+		if seen[sync.Target] {
+			continue
+		}
+		seen[sync.Target] = true
+		info, err := os.Stat(sync.Source)
+		switch {
+		case os.IsNotExist(err):
+			sync.Removed = true
+		case err != nil, info.IsDir():
+			continue
+		}
+		result = append(result, sync)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Target < result[j].Target })
+	return result
+}
+
+// combineActions merges the actions of rules triggered in the same batch: a
+// rebuild covers everything, otherwise syncs and restarts are both kept
+func combineActions(a, b string) string {
+	if a == types.WatchActionRebuild || b == types.WatchActionRebuild {
+		return types.WatchActionRebuild
+	}
+	sync := a == types.WatchActionSync || a == types.WatchActionSyncRestart ||
+		b == types.WatchActionSync || b == types.WatchActionSyncRestart
+	restart := a == types.WatchActionRestart || a == types.WatchActionSyncRestart ||
+		b == types.WatchActionRestart || b == types.WatchActionSyncRestart
+
+	switch {
+	case sync && restart:
+		return types.WatchActionSyncRestart
+	case restart:
+		return types.WatchActionRestart
+	default:
+		return types.WatchActionSync
+	}
+}
@@ -0,0 +1,129 @@
+// Package watch watches service source paths and batches file changes into
+// the sync, restart or rebuild actions of compose develop.watch rules
+package watch
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/isaacgarza/dev-stack/internal/pkg/types"
+)
+
+// Rule is a watch rule of a service with an absolute host path
+type Rule struct {
+	Service string
+	types.WatchRule
+}
+
+// LoadCompose reads the develop.watch rules of every service in a compose
+// file. Paths are relative to the compose file's directory.
+func LoadCompose(composeFile string) ([]Rule, error) {
+	data, err := os.ReadFile(composeFile)
+	if err != nil {
+		return nil, err
+	}
+
+	var compose struct {
+		Services map[string]struct {
+			Develop types.DevelopConfig `yaml:"develop"`
+		} `yaml:"services"`
+	}
+	if err := yaml.Unmarshal(data, &compose); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", composeFile, err)
+	}
+
+	watch := make(map[string][]types.WatchRule, len(compose.Services))
+	for serviceName, service := range compose.Services {
+		watch[serviceName] = service.Develop.Watch
+	}
+	return FromConfig(watch, filepath.Dir(composeFile))
+}
+
+// FromConfig binds watch rules to their services, resolving relative paths
+// against baseDir. Rules are ordered by service.
+func FromConfig(watch map[string][]types.WatchRule, baseDir string) ([]Rule, error) {
+	serviceNames := make([]string, 0, len(watch))
+	for serviceName := range watch {
+		serviceNames = append(serviceNames, serviceName)
+	}
+	sort.Strings(serviceNames)
+
+	var rules []Rule
+	for _, serviceName := range serviceNames {
+		for _, rule := range watch[serviceName] {
+			if rule.Path != "" && !filepath.IsAbs(rule.Path) {
+				rule.Path = filepath.Join(baseDir, rule.Path)
+			}
+			abs, err := filepath.Abs(rule.Path)
+			if err != nil {
+				return nil, err
+			}
+			rule.Path = abs
+			rules = append(rules, Rule{Service: serviceName, WatchRule: rule})
+		}
+	}
+	return rules, nil
+}
+
+// Validate checks that every rule has a path, a known action and, for sync
+// actions, a target
+func Validate(rules []Rule) error {
+	for _, rule := range rules {
+		if rule.WatchRule.Path == "" {
+			return fmt.Errorf("watch rule for %s has no path", rule.Service)
+		}
+		switch rule.Action {
+		case types.WatchActionSync, types.WatchActionSyncRestart:
+			if rule.Target == "" {
+				return fmt.Errorf("%s watch rule for %s needs a target", rule.Action, rule.Service)
+			}
+		case types.WatchActionRestart, types.WatchActionRebuild:
+		default:
+			return fmt.Errorf("watch rule for %s has unsupported action %q (supported: %s, %s, %s, %s)", rule.Service, rule.Action,
+				types.WatchActionSync, types.WatchActionRestart, types.WatchActionSyncRestart, types.WatchActionRebuild)
+		}
+	}
+	return nil
+}
+
+// Match reports whether a changed host path is covered by the rule and
+// returns it relative to the rule's path
+func (r Rule) Match(changed string) (string, bool) {
+	rel, err := filepath.Rel(r.WatchRule.Path, changed)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", false
+	}
+	rel = filepath.ToSlash(rel)
+	if rel != "." && (isIgnored(defaultIgnore, rel) || isIgnored(r.Ignore, rel)) {
+		return "", false
+	}
+	return rel, true
+}
+
+// defaultIgnore skips version control data and editor temporary files
+var defaultIgnore = []string{".git", "*~", "*.swp", "*.swx", ".#*"}
+
+// isIgnored matches patterns against the relative path, each of its parent
+// directories and its base name, so "node_modules" also ignores everything
+// below node_modules
+func isIgnored(patterns []string, rel string) bool {
+	segments := strings.Split(rel, "/")
+	for _, pattern := range patterns {
+		pattern = strings.TrimSuffix(strings.TrimPrefix(pattern, "./"), "/")
+		for i := range segments {
+			if ok, _ := path.Match(pattern, strings.Join(segments[:i+1], "/")); ok {
+				return true
+			}
+		}
+		if ok, _ := path.Match(pattern, segments[len(segments)-1]); ok {
+			return true
+		}
+	}
+	return false
+}
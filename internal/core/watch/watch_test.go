@@ -0,0 +1,242 @@
+package watch
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/isaacgarza/dev-stack/internal/pkg/types"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+}
+
+func TestRuleMatch(t *testing.T) {
+	rule := Rule{Service: "api", WatchRule: types.WatchRule{
+		Path:   "/src/app",
+		Action: types.WatchActionSync,
+		Target: "/app",
+		Ignore: []string{"node_modules/", "*.log"},
+	}}
+
+	tests := []struct {
+		name    string
+		changed string
+		rel     string
+		matched bool
+	}{
+		{"root", "/src/app", ".", true},
+		{"file", "/src/app/main.go", "main.go", true},
+		{"nested", "/src/app/pkg/server/server.go", "pkg/server/server.go", true},
+		{"outside", "/src/other/main.go", "", false},
+		{"sibling prefix", "/src/application/main.go", "", false},
+		{"ignored directory", "/src/app/node_modules/lib/index.js", "", false},
+		{"ignored pattern", "/src/app/logs/debug.log", "", false},
+		{"git", "/src/app/.git/HEAD", "", false},
+		{"swap file", "/src/app/.main.go.swp", "", false},
+		{"backup file", "/src/app/main.go~", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rel, ok := rule.Match(tt.changed)
+			assert.Equal(t, tt.matched, ok)
+			assert.Equal(t, tt.rel, rel)
+		})
+	}
+}
+
+func TestLoadCompose(t *testing.T) {
+	dir := t.TempDir()
+	composeFile := filepath.Join(dir, "dev-stack", "docker-compose.yml")
+	writeFile(t, composeFile, `services:
+  worker:
+    image: worker:dev
+    develop:
+      watch:
+        - action: rebuild
+          path: ../go.mod
+  api:
+    image: api:dev
+    develop:
+      watch:
+        - action: sync
+          path: ../src
+          target: /app/src
+          ignore:
+            - node_modules/
+  postgres:
+    image: postgres:15
+`)
+
+	rules, err := LoadCompose(composeFile)
+	require.NoError(t, err)
+	require.Len(t, rules, 2)
+
+	assert.Equal(t, "api", rules[0].Service)
+	assert.Equal(t, filepath.Join(dir, "src"), rules[0].WatchRule.Path)
+	assert.Equal(t, "/app/src", rules[0].Target)
+	assert.Equal(t, []string{"node_modules/"}, rules[0].Ignore)
+
+	assert.Equal(t, "worker", rules[1].Service)
+	assert.Equal(t, filepath.Join(dir, "go.mod"), rules[1].WatchRule.Path)
+	assert.Equal(t, types.WatchActionRebuild, rules[1].Action)
+
+	_, err = LoadCompose(filepath.Join(dir, "missing.yml"))
+	assert.Error(t, err)
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		rule    types.WatchRule
+		wantErr string
+	}{
+		{"sync", types.WatchRule{Path: "/src", Action: types.WatchActionSync, Target: "/app"}, ""},
+		{"restart", types.WatchRule{Path: "/src", Action: types.WatchActionRestart}, ""},
+		{"rebuild", types.WatchRule{Path: "/src", Action: types.WatchActionRebuild}, ""},
+		{"missing path", types.WatchRule{Action: types.WatchActionRestart}, "has no path"},
+		{"missing target", types.WatchRule{Path: "/src", Action: types.WatchActionSyncRestart}, "needs a target"},
+		{"unknown action", types.WatchRule{Path: "/src", Action: "reload"}, "unsupported action"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Validate([]Rule{{Service: "api", WatchRule: tt.rule}})
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
+func TestCombineActions(t *testing.T) {
+	tests := []struct {
+		a, b, want string
+	}{
+		{types.WatchActionSync, types.WatchActionSync, types.WatchActionSync},
+		{types.WatchActionSync, types.WatchActionRestart, types.WatchActionSyncRestart},
+		{types.WatchActionRestart, types.WatchActionRestart, types.WatchActionRestart},
+		{types.WatchActionSyncRestart, types.WatchActionSync, types.WatchActionSyncRestart},
+		{types.WatchActionSync, types.WatchActionRebuild, types.WatchActionRebuild},
+		{types.WatchActionRebuild, types.WatchActionRestart, types.WatchActionRebuild},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.a+"+"+tt.b, func(t *testing.T) {
+			assert.Equal(t, tt.want, combineActions(tt.a, tt.b))
+		})
+	}
+}
+
+func TestCollector(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "src")
+	writeFile(t, filepath.Join(src, "main.go"), "package main")
+	writeFile(t, filepath.Join(src, "config.yaml"), "port: 8080")
+
+	rules := []Rule{
+		{Service: "api", WatchRule: types.WatchRule{Path: src, Action: types.WatchActionSync, Target: "/app"}},
+		{Service: "api", WatchRule: types.WatchRule{Path: filepath.Join(src, "config.yaml"), Action: types.WatchActionRestart}},
+		{Service: "worker", WatchRule: types.WatchRule{Path: filepath.Join(dir, "go.mod"), Action: types.WatchActionRebuild}},
+		{Service: "worker", WatchRule: types.WatchRule{Path: src, Action: types.WatchActionSync, Target: "/worker"}},
+	}
+
+	t.Run("sync and restart", func(t *testing.T) {
+		c := newCollector(rules[:2])
+		assert.True(t, c.add(filepath.Join(src, "main.go")))
+		assert.True(t, c.add(filepath.Join(src, "main.go")))
+		assert.True(t, c.add(filepath.Join(src, "config.yaml")))
+		assert.True(t, c.add(filepath.Join(src, "deleted.go")))
+		assert.True(t, c.add(src))
+		assert.False(t, c.add(filepath.Join(dir, "README.md")))
+
+		batches := c.flush()
+		require.Len(t, batches, 1)
+		batch := batches[0]
+		assert.Equal(t, "api", batch.Service)
+		assert.Equal(t, types.WatchActionSyncRestart, batch.Action)
+		assert.True(t, batch.NeedsSync())
+		assert.True(t, batch.NeedsRestart())
+		assert.Len(t, batch.Paths, 4)
+		assert.Equal(t, []Sync{
+			{Source: filepath.Join(src, "config.yaml"), Target: "/app/config.yaml"},
+			{Source: filepath.Join(src, "deleted.go"), Target: "/app/deleted.go", Removed: true},
+			{Source: filepath.Join(src, "main.go"), Target: "/app/main.go"},
+		}, batch.Syncs)
+
+		assert.Empty(t, c.flush())
+	})
+
+	t.Run("rebuild drops syncs", func(t *testing.T) {
+		c := newCollector(rules)
+		c.add(filepath.Join(src, "main.go"))
+		c.add(filepath.Join(dir, "go.mod"))
+
+		batches := c.flush()
+		require.Len(t, batches, 2)
+		assert.Equal(t, "api", batches[0].Service)
+		assert.Equal(t, types.WatchActionSync, batches[0].Action)
+		assert.Equal(t, "worker", batches[1].Service)
+		assert.Equal(t, types.WatchActionRebuild, batches[1].Action)
+		assert.Empty(t, batches[1].Syncs)
+		assert.False(t, batches[1].NeedsSync())
+	})
+}
+
+func TestWatcherRun(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "src")
+	require.NoError(t, os.MkdirAll(filepath.Join(src, "ignored"), 0755))
+
+	rules := []Rule{{Service: "api", WatchRule: types.WatchRule{
+		Path:   src,
+		Action: types.WatchActionSync,
+		Target: "/app",
+		Ignore: []string{"ignored"},
+	}}}
+
+	watcher, err := New(rules, 50*time.Millisecond)
+	require.NoError(t, err)
+	defer func() { _ = watcher.Close() }()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	received := make(chan []Batch, 1)
+	done := make(chan error, 1)
+	go func() {
+		done <- watcher.Run(ctx, func(batches []Batch) {
+			select {
+			case received <- batches:
+			default:
+			}
+		})
+	}()
+
+	writeFile(t, filepath.Join(src, "ignored", "skip.txt"), "skip")
+	writeFile(t, filepath.Join(src, "main.go"), "package main")
+
+	select {
+	case batches := <-received:
+		require.Len(t, batches, 1)
+		assert.Equal(t, "api", batches[0].Service)
+		assert.Equal(t, []Sync{{Source: filepath.Join(src, "main.go"), Target: "/app/main.go"}}, batches[0].Syncs)
+	case <-time.After(5 * time.Second):
+		t.Fatal("no batch received")
+	}
+
+	cancel()
+	assert.NoError(t, <-done)
+}
@@ -0,0 +1,141 @@
+package watch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// DefaultDebounce is how long changes must settle before a batch is run
+const DefaultDebounce = 500 * time.Millisecond
+
+// Watcher watches the paths of a set of rules and hands debounced batches
+// of changes to a callback
+type Watcher struct {
+	rules    []Rule
+	debounce time.Duration
+	fs       *fsnotify.Watcher
+	watched  map[string]bool
+}
+
+// New creates a watcher for the given rules. Directories are watched
+// recursively, and directories created later are picked up as they appear.
+func New(rules []Rule, debounce time.Duration) (*Watcher, error) {
+	if debounce <= 0 {
+		debounce = DefaultDebounce
+	}
+
+	fsWatcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create file watcher: %w", err)
+	}
+
+	w := &Watcher{rules: rules, debounce: debounce, fs: fsWatcher, watched: map[string]bool{}}
+	for _, rule := range rules {
+		if err := w.addPath(rule.WatchRule.Path); err != nil {
+			_ = fsWatcher.Close()
+			return nil, fmt.Errorf("failed to watch %s for %s: %w", rule.WatchRule.Path, rule.Service, err)
+		}
+	}
+	return w, nil
+}
+
+// Run delivers batches to handle until ctx is cancelled. Batches are handled
+// one at a time; changes made meanwhile are collected for the next batch.
+func (w *Watcher) Run(ctx context.Context, handle func([]Batch)) error {
+	collected := newCollector(w.rules)
+	timer := time.NewTimer(w.debounce)
+	timer.Stop()
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case event, ok := <-w.fs.Events:
+			if !ok {
+				return nil
+			}
+			if event.Has(fsnotify.Create) {
+				if info, err := os.Stat(event.Name); err == nil && info.IsDir() && w.covered(event.Name) {
+					_ = w.addPath(event.Name)
+				}
+			}
+			if event.Has(fsnotify.Chmod) && !event.Has(fsnotify.Write) {
+				continue
+			}
+			if collected.add(event.Name) {
+				timer.Reset(w.debounce)
+			}
+		case err, ok := <-w.fs.Errors:
+			if !ok {
+				return nil
+			}
+			return fmt.Errorf("file watcher failed: %w", err)
+		case <-timer.C:
+			if batches := collected.flush(); len(batches) > 0 {
+				handle(batches)
+			}
+		}
+	}
+}
+
+// Close stops watching
+func (w *Watcher) Close() error {
+	return w.fs.Close()
+}
+
+// addPath watches a directory tree, skipping ignored directories. A file is
+// watched through its directory so that editors replacing it are seen.
+func (w *Watcher) addPath(root string) error {
+	info, err := os.Stat(root)
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() {
+		return w.add(filepath.Dir(root))
+	}
+
+	return filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		if !entry.IsDir() {
+			return nil
+		}
+		if path != root && !w.covered(path) {
+			return filepath.SkipDir
+		}
+		return w.add(path)
+	})
+}
+
+func (w *Watcher) add(dir string) error {
+	if w.watched[dir] {
+		return nil
+	}
+	if err := w.fs.Add(dir); err != nil {
+		return err
+	}
+	w.watched[dir] = true
+	return nil
+}
+
+// covered reports whether any rule matches the path
+func (w *Watcher) covered(path string) bool {
+	for _, rule := range w.rules {
+		if _, ok := rule.Match(path); ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,335 @@
+// Package adopt detects legacy, script based development stacks and
+// translates them into a proposed dev-stack configuration.
+package adopt
+
+import (
+	"bufio"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/isaacgarza/dev-stack/internal/pkg/utils"
+)
+
+// maxScanDepth bounds how deep Scan descends below the root directory
+const maxScanDepth = 3
+
+// skippedDirs are never scanned
+var skippedDirs = map[string]bool{
+	".git":         true,
+	"node_modules": true,
+	"vendor":       true,
+	"dev-stack":    true,
+	".dev-stack":   true,
+}
+
+// PortMapping is a published port found in a legacy setup
+type PortMapping struct {
+	Host      int
+	Container int
+}
+
+// Service is a container found in a legacy setup
+type Service struct {
+	// Name is the matching dev-stack service, or empty when there is none
+	Name   string
+	Image  string
+	Ports  []PortMapping
+	Env    map[string]string
+	Source string
+}
+
+// Note records something found in a legacy setup that could not be translated
+type Note struct {
+	Source string
+	Text   string
+}
+
+// Report is the result of scanning a project for legacy setup files
+type Report struct {
+	Services []Service
+	EnvFiles map[string]map[string]string
+	Notes    []Note
+}
+
+// ServiceNames returns the sorted, unique dev-stack services in the report
+func (r *Report) ServiceNames() []string {
+	seen := make(map[string]bool)
+	var names []string
+	for _, service := range r.Services {
+		if service.Name != "" && !seen[service.Name] {
+			seen[service.Name] = true
+			names = append(names, service.Name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (r *Report) note(source, format string, args ...any) {
+	r.Notes = append(r.Notes, Note{Source: source, Text: fmt.Sprintf(format, args...)})
+}
+
+// Scan walks root looking for Makefiles, shell scripts invoking docker run,
+// compose files and .env files, and extracts what it can from each
+func Scan(root string) (*Report, error) {
+	report := &Report{EnvFiles: make(map[string]map[string]string)}
+
+	err := filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		rel, _ := filepath.Rel(root, path)
+		if entry.IsDir() {
+			if path != root && (skippedDirs[entry.Name()] || strings.Count(rel, string(filepath.Separator)) >= maxScanDepth) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		switch kind := classify(path, entry.Name()); kind {
+		case kindMakefile, kindScript:
+			return scanScript(report, path, rel, kind)
+		case kindCompose:
+			report.note(rel, "existing compose file; its services were not translated, compare it with the generated dev-stack compose file")
+		case kindEnv:
+			values, err := utils.ParseEnvFile(path)
+			if err != nil {
+				report.note(rel, "could not parse env file: %v", err)
+				return nil
+			}
+			report.EnvFiles[rel] = values
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan %s: %w", root, err)
+	}
+
+	return report, nil
+}
+
+type fileKind int
+
+const (
+	kindOther fileKind = iota
+	kindMakefile
+	kindScript
+	kindCompose
+	kindEnv
+)
+
+func classify(path, name string) fileKind {
+	switch {
+	case name == "Makefile" || name == "makefile" || name == "GNUmakefile" || strings.HasSuffix(name, ".mk"):
+		return kindMakefile
+	case strings.HasSuffix(name, ".sh") || strings.HasSuffix(name, ".bash"):
+		return kindScript
+	case name == "docker-compose.yml" || name == "docker-compose.yaml" || name == "compose.yml" || name == "compose.yaml":
+		return kindCompose
+	case name == ".env" || (strings.HasPrefix(name, ".env.") && name != ".env.generated" && name != ".env.example"):
+		return kindEnv
+	case !strings.Contains(name, "."):
+		if hasShellShebang(path) {
+			return kindScript
+		}
+	}
+	return kindOther
+}
+
+func hasShellShebang(path string) bool {
+	file, err := os.Open(path)
+	if err != nil {
+		return false
+	}
+	defer func() { _ = file.Close() }()
+
+	line, err := bufio.NewReader(file).ReadString('\n')
+	if err != nil && line == "" {
+		return false
+	}
+	return strings.HasPrefix(line, "#!") && (strings.Contains(line, "sh") || strings.Contains(line, "bash"))
+}
+
+// scanScript extracts docker run invocations from a Makefile or shell script
+func scanScript(report *Report, path, rel string, kind fileKind) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", rel, err)
+	}
+
+	for _, line := range logicalLines(string(data)) {
+		source := fmt.Sprintf("%s:%d", rel, line.number)
+		text := strings.TrimSpace(line.text)
+		if kind == kindMakefile {
+			text = strings.TrimLeft(text, "@-+ ")
+		}
+		if strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		for _, command := range splitCommands(text) {
+			if !strings.Contains(command, "docker") {
+				continue
+			}
+
+			args := tokenize(command)
+			switch {
+			case isDockerRun(args):
+				service, notes, err := parseDockerRun(args)
+				if err != nil {
+					report.note(source, "could not translate docker run: %v", err)
+					continue
+				}
+				for _, text := range notes {
+					report.note(source, "%s", text)
+				}
+				service.Source = source
+				report.addService(service)
+			case isCompose(args):
+				report.note(source, "docker compose invocation; dev-stack up replaces it")
+			}
+		}
+	}
+	return nil
+}
+
+// addService resolves a parsed container to a dev-stack service and records
+// anything about it that has no dev-stack equivalent
+func (r *Report) addService(service Service) {
+	service.Name = matchService(service.Image)
+	if service.Name == "" {
+		r.note(service.Source, "image %s has no dev-stack equivalent", service.Image)
+	}
+	for key := range service.Env {
+		if _, ok := envOverrides[service.Name][key]; !ok && service.Name != "" {
+			r.note(service.Source, "environment variable %s for %s has no dev-stack override", key, service.Name)
+		}
+	}
+	r.Services = append(r.Services, service)
+}
+
+type logicalLine struct {
+	number int
+	text   string
+}
+
+// logicalLines joins backslash-continued lines, keeping the starting line number
+func logicalLines(content string) []logicalLine {
+	var lines []logicalLine
+	var current strings.Builder
+	start := 0
+	for i, raw := range strings.Split(content, "\n") {
+		if current.Len() == 0 {
+			start = i + 1
+		}
+		trimmed := strings.TrimRight(raw, " \t\r")
+		if strings.HasSuffix(trimmed, "\\") {
+			current.WriteString(strings.TrimSuffix(trimmed, "\\"))
+			current.WriteString(" ")
+			continue
+		}
+		current.WriteString(trimmed)
+		lines = append(lines, logicalLine{number: start, text: current.String()})
+		current.Reset()
+	}
+	if current.Len() > 0 {
+		lines = append(lines, logicalLine{number: start, text: current.String()})
+	}
+	return lines
+}
+
+// splitCommands splits a shell line on command separators outside quotes
+func splitCommands(line string) []string {
+	var commands []string
+	var current strings.Builder
+	var quote rune
+	runes := []rune(line)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+			current.WriteRune(r)
+		case r == '\'' || r == '"':
+			quote = r
+			current.WriteRune(r)
+		case r == ';' || r == '|' || r == '&':
+			commands = append(commands, current.String())
+			current.Reset()
+			if i+1 < len(runes) && runes[i+1] == r {
+				i++
+			}
+		default:
+			current.WriteRune(r)
+		}
+	}
+	return append(commands, current.String())
+}
+
+// tokenize splits a shell command into words, honoring single and double quotes
+func tokenize(command string) []string {
+	var tokens []string
+	var current strings.Builder
+	var quote rune
+	inToken := false
+	for _, r := range command {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else {
+				current.WriteRune(r)
+			}
+		case r == '\'' || r == '"':
+			quote = r
+			inToken = true
+		case r == ' ' || r == '\t':
+			if inToken {
+				tokens = append(tokens, current.String())
+				current.Reset()
+				inToken = false
+			}
+		default:
+			current.WriteRune(r)
+			inToken = true
+		}
+	}
+	if inToken {
+		tokens = append(tokens, current.String())
+	}
+	return tokens
+}
+
+func dockerIndex(args []string) int {
+	for i, arg := range args {
+		if arg == "docker" || strings.HasSuffix(arg, "/docker") || arg == "$(DOCKER)" || arg == "${DOCKER}" {
+			return i
+		}
+	}
+	return -1
+}
+
+func isDockerRun(args []string) bool {
+	i := dockerIndex(args)
+	if i < 0 || i+1 >= len(args) {
+		return false
+	}
+	next := args[i+1]
+	return next == "run" || (next == "container" && i+2 < len(args) && args[i+2] == "run")
+}
+
+func isCompose(args []string) bool {
+	for i, arg := range args {
+		if arg == "docker-compose" || (arg == "docker" && i+1 < len(args) && args[i+1] == "compose") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,108 @@
+package adopt
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testMakefile = `DOCKER ?= docker
+
+db:
+	@docker run -d --name app-db \
+		-p 5433:5432 \
+		-e POSTGRES_PASSWORD=secret \
+		-e POSTGRES_DB=app \
+		-v pgdata:/var/lib/postgresql/data \
+		postgres:15
+
+cache:
+	docker run --rm -p 6379:6379 redis:7-alpine && echo started
+
+# docker run ignored:latest
+`
+
+const testScript = `#!/usr/bin/env bash
+set -e
+docker run -d -p 8025:8025 axllent/mailpit --verbose
+docker compose up -d
+`
+
+func writeFixture(t *testing.T, root, name, content string) {
+	t.Helper()
+	path := filepath.Join(root, name)
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+}
+
+func TestScan(t *testing.T) {
+	root := t.TempDir()
+	writeFixture(t, root, "Makefile", testMakefile)
+	writeFixture(t, root, "scripts/start-deps", testScript)
+	writeFixture(t, root, ".env", "API_KEY=abc\nexport DEBUG=true\n")
+	writeFixture(t, root, "node_modules/pkg/run.sh", "docker run mongo\n")
+
+	report, err := Scan(root)
+	require.NoError(t, err)
+
+	require.Len(t, report.Services, 3)
+	assert.Equal(t, []string{"postgres", "redis"}, report.ServiceNames())
+
+	postgres := report.Services[0]
+	assert.Equal(t, "postgres:15", postgres.Image)
+	assert.Equal(t, "Makefile:4", postgres.Source)
+	assert.Equal(t, []PortMapping{{Host: 5433, Container: 5432}}, postgres.Ports)
+
+	assert.Equal(t, map[string]string{"API_KEY": "abc", "DEBUG": "true"}, report.EnvFiles[".env"])
+
+	var notes []string
+	for _, note := range report.Notes {
+		notes = append(notes, note.Text)
+	}
+	assert.Contains(t, notes, "volume pgdata:/var/lib/postgresql/data was not translated; dev-stack manages named volumes per service")
+	assert.Contains(t, notes, "image axllent/mailpit has no dev-stack equivalent")
+	assert.Contains(t, notes, `custom command "--verbose" for axllent/mailpit was not translated`)
+	assert.Contains(t, notes, "docker compose invocation; dev-stack up replaces it")
+
+	overrides := report.Overrides()
+	assert.Equal(t, map[string]interface{}{"port": 5433, "password": "secret", "database": "app"}, overrides["postgres"])
+	assert.NotContains(t, overrides, "redis")
+
+	proposal := report.Propose("legacy", "local")
+	assert.Contains(t, proposal, "    - postgres\n    - redis\n")
+	assert.Contains(t, proposal, "overrides:\n  postgres:\n    database: app\n    password: secret\n    port: 5433\n")
+}
+
+func TestParsePublish(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected PortMapping
+		ok       bool
+	}{
+		{"8080:80", PortMapping{Host: 8080, Container: 80}, true},
+		{"127.0.0.1:5433:5432/tcp", PortMapping{Host: 5433, Container: 5432}, true},
+		{"5432", PortMapping{}, false},
+		{"${PORT}:5432", PortMapping{}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			port, ok := parsePublish(tt.value)
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.expected, port)
+		})
+	}
+}
+
+func TestMatchService(t *testing.T) {
+	assert.Equal(t, "kafka-broker", matchService("confluentinc/cp-kafka:7.5.0"))
+	assert.Equal(t, "jaeger", matchService("jaegertracing/all-in-one:1.51"))
+	assert.Equal(t, "mysql", matchService("docker.io/library/mariadb:11"))
+	assert.Equal(t, "minio", matchService("minio/minio"))
+	assert.Equal(t, "clickhouse", matchService("clickhouse/clickhouse-server:24.8"))
+	assert.Equal(t, "elasticsearch", matchService("docker.elastic.co/elasticsearch/elasticsearch:8.15.3"))
+	assert.Equal(t, "", matchService("axllent/mailpit"))
+}
@@ -0,0 +1,192 @@
+package adopt
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// valueFlags are docker run flags that consume the following argument
+var valueFlags = map[string]bool{
+	"-p": true, "--publish": true,
+	"-e": true, "--env": true, "--env-file": true,
+	"--name": true, "-v": true, "--volume": true, "--mount": true,
+	"--network": true, "--net": true, "-w": true, "--workdir": true,
+	"-u": true, "--user": true, "--entrypoint": true, "--restart": true,
+	"-m": true, "--memory": true, "--platform": true, "-h": true, "--hostname": true,
+	"-l": true, "--label": true, "--add-host": true, "--cpus": true,
+	"--health-cmd": true, "--health-interval": true, "--health-retries": true,
+	"--health-timeout": true, "--network-alias": true, "--shm-size": true,
+	"--ulimit": true, "--log-driver": true, "--log-opt": true,
+}
+
+// imageServices maps image repository names to dev-stack services
+var imageServices = map[string]string{
+	"postgres":          "postgres",
+	"postgis":           "postgres",
+	"mysql":             "mysql",
+	"mariadb":           "mysql",
+	"redis":             "redis",
+	"redis-stack":       "redis",
+	"cp-kafka":          "kafka-broker",
+	"kafka":             "kafka-broker",
+	"cp-zookeeper":      "zookeeper",
+	"zookeeper":         "zookeeper",
+	"kafka-ui":          "kafka-ui",
+	"all-in-one":        "jaeger",
+	"jaeger":            "jaeger",
+	"prometheus":        "prometheus",
+	"localstack":        "localstack-core",
+	"localstack-pro":    "localstack-core",
+	"rabbitmq":          "rabbitmq",
+	"elasticsearch":     "elasticsearch",
+	"kibana":            "kibana",
+	"minio":             "minio",
+	"clickhouse-server": "clickhouse",
+	"clickhouse":        "clickhouse",
+	"nats":              "nats",
+}
+
+// servicePorts are the container ports dev-stack publishes by default
+var servicePorts = map[string]int{
+	"postgres":        5432,
+	"mysql":           3306,
+	"redis":           6379,
+	"kafka-broker":    9092,
+	"zookeeper":       2181,
+	"kafka-ui":        8080,
+	"jaeger":          16686,
+	"prometheus":      9090,
+	"localstack-core": 4566,
+	"rabbitmq":        5672,
+	"elasticsearch":   9200,
+	"kibana":          5601,
+	"minio":           9000,
+	"clickhouse":      8123,
+	"nats":            4222,
+}
+
+// envOverrides maps well-known image environment variables to dev-stack
+// service overrides
+var envOverrides = map[string]map[string]string{
+	"postgres": {
+		"POSTGRES_DB":       "database",
+		"POSTGRES_USER":     "username",
+		"POSTGRES_PASSWORD": "password",
+	},
+	"mysql": {
+		"MYSQL_DATABASE":      "database",
+		"MYSQL_USER":          "username",
+		"MYSQL_PASSWORD":      "password",
+		"MYSQL_ROOT_PASSWORD": "password",
+		"MARIADB_DATABASE":    "database",
+		"MARIADB_USER":        "username",
+		"MARIADB_PASSWORD":    "password",
+	},
+	"redis": {
+		"REDIS_PASSWORD": "password",
+	},
+	"rabbitmq": {
+		"RABBITMQ_DEFAULT_USER": "username",
+		"RABBITMQ_DEFAULT_PASS": "password",
+	},
+	"minio": {
+		"MINIO_ROOT_USER":     "username",
+		"MINIO_ROOT_PASSWORD": "password",
+	},
+	"clickhouse": {
+		"CLICKHOUSE_DB":       "database",
+		"CLICKHOUSE_USER":     "username",
+		"CLICKHOUSE_PASSWORD": "password",
+	},
+}
+
+// parseDockerRun extracts the image, published ports and environment from a
+// docker run invocation. The returned notes describe options that were
+// recognised but cannot be translated.
+func parseDockerRun(args []string) (Service, []string, error) {
+	service := Service{Env: make(map[string]string)}
+	var notes []string
+
+	i := dockerIndex(args) + 2
+	if args[i-1] == "container" {
+		i++
+	}
+
+	for ; i < len(args); i++ {
+		arg := args[i]
+		if !strings.HasPrefix(arg, "-") {
+			break
+		}
+
+		name, value, inline := strings.Cut(arg, "=")
+		if !inline && valueFlags[name] {
+			if i+1 >= len(args) {
+				return service, notes, fmt.Errorf("flag %s is missing a value", name)
+			}
+			i++
+			value = args[i]
+		}
+
+		switch name {
+		case "-p", "--publish":
+			port, ok := parsePublish(value)
+			if !ok {
+				notes = append(notes, fmt.Sprintf("port mapping %s could not be translated", value))
+				continue
+			}
+			service.Ports = append(service.Ports, port)
+		case "-e", "--env":
+			key, val, found := strings.Cut(value, "=")
+			if !found {
+				notes = append(notes, fmt.Sprintf("environment variable %s is passed through from the host", key))
+				continue
+			}
+			service.Env[key] = val
+		case "--env-file":
+			notes = append(notes, fmt.Sprintf("env file %s should be reviewed and merged into the project .env", value))
+		case "-v", "--volume", "--mount":
+			notes = append(notes, fmt.Sprintf("volume %s was not translated; dev-stack manages named volumes per service", value))
+		}
+	}
+
+	if i >= len(args) {
+		return service, notes, fmt.Errorf("no image found")
+	}
+	service.Image = args[i]
+	if extra := args[i+1:]; len(extra) > 0 {
+		notes = append(notes, fmt.Sprintf("custom command %q for %s was not translated", strings.Join(extra, " "), service.Image))
+	}
+
+	return service, notes, nil
+}
+
+// parsePublish parses [ip:]host:container[/proto] port mappings
+func parsePublish(value string) (PortMapping, bool) {
+	value, _, _ = strings.Cut(value, "/")
+	parts := strings.Split(value, ":")
+	if len(parts) < 2 {
+		return PortMapping{}, false
+	}
+
+	host, err := strconv.Atoi(parts[len(parts)-2])
+	if err != nil {
+		return PortMapping{}, false
+	}
+	container, err := strconv.Atoi(parts[len(parts)-1])
+	if err != nil {
+		return PortMapping{}, false
+	}
+	return PortMapping{Host: host, Container: container}, true
+}
+
+// matchService returns the dev-stack service providing an image, if any
+func matchService(image string) string {
+	name := image
+	if slash := strings.LastIndex(name, "/"); slash >= 0 {
+		name = name[slash+1:]
+	}
+	name, _, _ = strings.Cut(name, "@")
+	name, _, _ = strings.Cut(name, ":")
+	return imageServices[strings.ToLower(name)]
+}
@@ -0,0 +1,50 @@
+package adopt
+
+import (
+	"sort"
+
+	"github.com/isaacgarza/dev-stack/internal/pkg/config"
+)
+
+// Overrides derives dev-stack service overrides from the report: published
+// host ports that differ from the dev-stack default and well-known
+// environment variables such as database credentials
+func (r *Report) Overrides() map[string]map[string]interface{} {
+	overrides := make(map[string]map[string]interface{})
+	set := func(service, key string, value interface{}) {
+		if overrides[service] == nil {
+			overrides[service] = make(map[string]interface{})
+		}
+		overrides[service][key] = value
+	}
+
+	for _, service := range r.Services {
+		if service.Name == "" {
+			continue
+		}
+
+		for _, port := range service.Ports {
+			if port.Container == servicePorts[service.Name] && port.Host != port.Container {
+				set(service.Name, "port", port.Host)
+			}
+		}
+
+		keys := make([]string, 0, len(service.Env))
+		for key := range service.Env {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			if option, ok := envOverrides[service.Name][key]; ok {
+				set(service.Name, option, service.Env[key])
+			}
+		}
+	}
+
+	return overrides
+}
+
+// Propose renders the dev-stack configuration proposed for the report
+func (r *Report) Propose(projectName, environment string) string {
+	return config.GenerateConfigWithOverrides(projectName, environment, r.ServiceNames(), r.Overrides(), nil, nil)
+}
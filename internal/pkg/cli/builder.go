@@ -15,7 +15,13 @@ import (
 // BuildRootCommand creates the root command with all subcommands using YAML configuration
 func BuildRootCommand(config *config.CommandConfig) (*cobra.Command, error) {
 	// Use dynamic builder that reads from commands.yaml
-	return BuildDynamicRootCommand(config)
+	return BuildDynamicRootCommand(config, nil)
+}
+
+// BuildRootCommandForArgs creates the root command for running args, only
+// fully building the command they run
+func BuildRootCommandForArgs(config *config.CommandConfig, args []string) (*cobra.Command, error) {
+	return BuildDynamicRootCommand(config, args)
 }
 
 // createServiceManager creates and initializes the service manager
@@ -0,0 +1,103 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/isaacgarza/dev-stack/internal/pkg/config"
+	"github.com/isaacgarza/dev-stack/internal/pkg/constants"
+	"github.com/isaacgarza/dev-stack/internal/pkg/logger"
+	"github.com/isaacgarza/dev-stack/internal/pkg/ui"
+	"github.com/muesli/termenv"
+	"github.com/spf13/cobra"
+)
+
+// applyLogFormat configures the logger package from the --log-format flag
+func applyLogFormat(cmd *cobra.Command) error {
+	format, _ := cmd.Flags().GetString(constants.FlagLogFormat)
+	return logger.SetFormat(format)
+}
+
+// applyUserConfig applies the settings of the user configuration that
+// concern every command
+func applyUserConfig(cmd *cobra.Command) error {
+	if path, _ := cmd.Flags().GetString(constants.FlagConfig); path != "" {
+		config.SetUserConfigPath(path)
+	}
+	user, err := config.LoadUserConfig()
+	if err != nil {
+		return err
+	}
+	applyColor(cmd, user)
+	return nil
+}
+
+// applyColor configures colored output from --no-color, then the color
+// user setting, which NO_COLOR overrides
+func applyColor(cmd *cobra.Command, user *config.UserConfig) {
+	mode := user.ColorMode()
+	if noColor, _ := cmd.Flags().GetBool(constants.FlagNoColor); noColor {
+		mode = constants.ColorNever
+	}
+
+	switch mode {
+	case constants.ColorNever:
+		ui.DefaultOutput.NoColor = true
+		lipgloss.SetColorProfile(termenv.Ascii)
+	case constants.ColorAlways:
+		lipgloss.SetColorProfile(termenv.ANSI256)
+	}
+}
+
+// applyProgress turns progress boards into plain lines from --no-progress
+func applyProgress(cmd *cobra.Command) {
+	if noProgress, _ := cmd.Flags().GetBool(constants.FlagNoProgress); noProgress {
+		ui.DefaultOutput.NoProgress = true
+	}
+}
+
+// applyGitHubActions turns on workflow commands inside GitHub Actions jobs
+func applyGitHubActions() {
+	ui.DefaultOutput.GitHub = ui.GitHubActions()
+}
+
+// startCommandLog returns the logger handed to a command's handler, tagged
+// with the command path and a correlation ID, and a function that records
+// the command's duration and exit status once it returns. In JSON mode the
+// completion record is emitted at info level so it can be shipped as
+// telemetry, unless the user opted out; otherwise it is only visible at
+// debug level.
+func startCommandLog(cmd *cobra.Command, fallback *slog.Logger, telemetry bool) (*slog.Logger, func(error)) {
+	level := slog.LevelDebug
+	runLogger := fallback
+	if logger.Format() == logger.FormatJSON {
+		runLogger = logger.New(slog.LevelInfo)
+		if telemetry {
+			level = slog.LevelInfo
+		}
+	}
+
+	runLogger = runLogger.With(
+		"command", cmd.CommandPath(),
+		"correlation_id", logger.NewCorrelationID(),
+	)
+
+	start := time.Now()
+	return runLogger, func(err error) {
+		attrs := []any{
+			"duration_ms", time.Since(start).Milliseconds(),
+			"exit_status", constants.ExitSuccess,
+		}
+		if err != nil {
+			attrs = []any{
+				"duration_ms", time.Since(start).Milliseconds(),
+				"exit_status", constants.ExitError,
+				"error", fmt.Sprint(err),
+			}
+		}
+		runLogger.Log(context.Background(), level, "Command completed", attrs...)
+	}
+}
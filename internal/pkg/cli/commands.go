@@ -18,7 +18,7 @@ import (
 
 // NewUpCommand creates the up command
 func NewUpCommand(serviceManager *services.Manager, logger *slog.Logger) *cobra.Command {
-	handler := core.NewUpHandler()
+	handler := core.NewUpHandler(serviceManager)
 
 	cmd := &cobra.Command{
 		Use:   "up [services...]",
@@ -26,7 +26,7 @@ func NewUpCommand(serviceManager *services.Manager, logger *slog.Logger) *cobra.
 		Long:  "Start the specified services or all services if none specified",
 		RunE: func(cmd *cobra.Command, args []string) error {
 			base := createBaseCommand(serviceManager, logger)
-			return handler.Handle(context.Background(), cmd, args, base)
+			return handler.Handle(cmd.Context(), cmd, args, base)
 		},
 	}
 
@@ -38,7 +38,7 @@ func NewUpCommand(serviceManager *services.Manager, logger *slog.Logger) *cobra.
 
 // NewDownCommand creates the down command
 func NewDownCommand(serviceManager *services.Manager, logger *slog.Logger) *cobra.Command {
-	handler := core.NewDownHandler()
+	handler := core.NewDownHandler(serviceManager)
 
 	cmd := &cobra.Command{
 		Use:   "down [services...]",
@@ -46,11 +46,11 @@ func NewDownCommand(serviceManager *services.Manager, logger *slog.Logger) *cobr
 		Long:  "Stop the specified services or all services if none specified",
 		RunE: func(cmd *cobra.Command, args []string) error {
 			base := createBaseCommand(serviceManager, logger)
-			return handler.Handle(context.Background(), cmd, args, base)
+			return handler.Handle(cmd.Context(), cmd, args, base)
 		},
 	}
 
-	cmd.Flags().Int("timeout", 10, "Timeout in seconds")
+	cmd.Flags().Int("stop-timeout", 10, "Timeout in seconds")
 	cmd.Flags().Bool("remove", true, "Remove containers")
 	cmd.Flags().Bool("volumes", false, "Remove volumes")
 
@@ -59,7 +59,7 @@ func NewDownCommand(serviceManager *services.Manager, logger *slog.Logger) *cobr
 
 // NewStatusCommand creates the status command
 func NewStatusCommand(serviceManager *services.Manager, logger *slog.Logger) *cobra.Command {
-	handler := core.NewStatusHandler()
+	handler := core.NewStatusHandler(serviceManager)
 
 	cmd := &cobra.Command{
 		Use:   "status [services...]",
@@ -67,7 +67,7 @@ func NewStatusCommand(serviceManager *services.Manager, logger *slog.Logger) *co
 		Long:  "Show the status of specified services or all services if none specified",
 		RunE: func(cmd *cobra.Command, args []string) error {
 			base := createBaseCommand(serviceManager, logger)
-			return handler.Handle(context.Background(), cmd, args, base)
+			return handler.Handle(cmd.Context(), cmd, args, base)
 		},
 	}
 
@@ -86,11 +86,11 @@ func NewRestartCommand(serviceManager *services.Manager, logger *slog.Logger) *c
 		Long:  "Restart the specified services or all services if none specified",
 		RunE: func(cmd *cobra.Command, args []string) error {
 			base := createBaseCommand(serviceManager, logger)
-			return handler.Handle(context.Background(), cmd, args, base)
+			return handler.Handle(cmd.Context(), cmd, args, base)
 		},
 	}
 
-	cmd.Flags().Int("timeout", 10, "Timeout in seconds")
+	cmd.Flags().Int("stop-timeout", 10, "Timeout in seconds")
 
 	return cmd
 }
@@ -105,7 +105,7 @@ func NewLogsCommand(serviceManager *services.Manager, logger *slog.Logger) *cobr
 			follow, _ := cmd.Flags().GetBool("follow")
 			tail, _ := cmd.Flags().GetString("tail")
 
-			ctx := context.Background()
+			ctx := cmd.Context()
 			options := pkgTypes.LogOptions{
 				Follow:     follow,
 				Tail:       tail,
@@ -140,7 +140,7 @@ func NewExecCommand(serviceManager *services.Manager, logger *slog.Logger) *cobr
 			interactive, _ := cmd.Flags().GetBool("interactive")
 			tty, _ := cmd.Flags().GetBool("tty")
 
-			ctx := context.Background()
+			ctx := cmd.Context()
 			options := pkgTypes.ExecOptions{
 				Interactive: interactive,
 				TTY:         tty,
@@ -166,7 +166,7 @@ func NewInitCommand(logger *slog.Logger) *cobra.Command {
 		Long:  "Initialize a new dev-stack project with optional template",
 		RunE: func(cmd *cobra.Command, args []string) error {
 			base := &cliTypes.BaseCommand{Logger: &loggerAdapter{logger: logger}}
-			return handler.Handle(context.Background(), cmd, args, base)
+			return handler.Handle(cmd.Context(), cmd, args, base)
 		},
 	}
 
@@ -209,7 +209,7 @@ func NewServicesCommand(serviceManager *services.Manager, logger *slog.Logger) *
 				Manager: &serviceManagerAdapter{manager: serviceManager},
 				Logger:  &loggerAdapter{logger: logger},
 			}
-			return handler.Handle(context.Background(), cmd, args, base)
+			return handler.Handle(cmd.Context(), cmd, args, base)
 		},
 	}
 
@@ -289,6 +289,12 @@ func (l *loggerAdapter) Debug(msg string, args ...interface{}) {
 	l.logger.Debug(msg, args...)
 }
 
+// SlogLogger exposes the underlying logger to handlers that construct
+// their own Docker clients
+func (l *loggerAdapter) SlogLogger() *slog.Logger {
+	return l.logger
+}
+
 // NewDoctorCommand creates the doctor command
 func NewDoctorCommand(logger *slog.Logger) *cobra.Command {
 	handler := doctor.NewDoctorHandler()
@@ -301,7 +307,7 @@ func NewDoctorCommand(logger *slog.Logger) *cobra.Command {
 			base := &cliTypes.BaseCommand{
 				Logger: &loggerAdapter{logger: logger},
 			}
-			return handler.Handle(context.Background(), cmd, args, base)
+			return handler.Handle(cmd.Context(), cmd, args, base)
 		},
 	}
 
@@ -322,7 +328,7 @@ func NewCompletionCommand(logger *slog.Logger) *cobra.Command {
 			base := &cliTypes.BaseCommand{
 				Logger: &loggerAdapter{logger: logger},
 			}
-			return handler.Handle(context.Background(), cmd, args, base)
+			return handler.Handle(cmd.Context(), cmd, args, base)
 		},
 	}
 
@@ -0,0 +1,290 @@
+package cli
+
+import (
+	"context"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/isaacgarza/dev-stack/internal/core/services"
+	"github.com/isaacgarza/dev-stack/internal/core/snapshot"
+	"github.com/isaacgarza/dev-stack/internal/pkg/cli/handlers/core"
+	"github.com/isaacgarza/dev-stack/internal/pkg/config"
+	"github.com/isaacgarza/dev-stack/internal/pkg/constants"
+	pkgServices "github.com/isaacgarza/dev-stack/internal/pkg/services"
+	pkgTypes "github.com/isaacgarza/dev-stack/internal/pkg/types"
+	"github.com/spf13/cobra"
+)
+
+// What arguments and flags complete to, as named by completion in
+// commands.yaml
+const (
+	completeServices   = "services"        // every service of the catalog
+	completeEnabled    = "enabled"         // the services the project enables
+	completeRunning    = "running"         // the project's running services
+	completeBackups    = "backups"         // files in the backup directory
+	completeSnapshots  = "snapshots"       // the project's snapshots
+	completeProfiles   = "profiles"        // the project's profiles
+	completeConfigKeys = "config-keys"     // the settings of the project configuration
+	completeTopics     = "topics"          // the Kafka topics the project declares
+	completeCategories = "categories"      // what prune reclaims
+	completeWorkflows  = "workflows"       // the built-in and project workflows
+	completeParams     = "workflow-params" // the parameters of the workflow named first
+	completeTasks      = "tasks"           // the project's declared and imported tasks
+	completeShells     = "shells"          // the shells completion supports
+	completeNone       = "none"
+)
+
+// completionTimeout bounds the Docker queries of a completion, so a
+// daemon that doesn't answer never hangs the shell
+const completionTimeout = 2 * time.Second
+
+// defaultBackupDir is where backups go when neither --output nor the
+// configuration names a directory
+const defaultBackupDir = "./backups"
+
+// argsCompletion completes the positional arguments of a command with
+// kinds, by position; the last kind also applies to the arguments after it.
+// Services already on the command line aren't offered again.
+func argsCompletion(kinds []string, manager *services.Manager) cobra.CompletionFunc {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		kind := kinds[min(len(args), len(kinds)-1)]
+		values, directive := completionValues(cmd.Context(), kind, args, manager)
+		if kind == completeServices || kind == completeEnabled || kind == completeRunning || kind == completeCategories {
+			// The values can be shared, like the prune categories, so the
+			// given ones are removed from a copy
+			values = slices.DeleteFunc(slices.Clone(values), func(value string) bool {
+				name, _, _ := strings.Cut(value, "\t")
+				return slices.Contains(args, name)
+			})
+		}
+		return matching(values, toComplete), directive
+	}
+}
+
+// flagCompletion completes a flag value with kind
+func flagCompletion(kind string, manager *services.Manager) cobra.CompletionFunc {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		values, directive := completionValues(cmd.Context(), kind, nil, manager)
+		return matching(values, toComplete), directive
+	}
+}
+
+// completionValues returns the values of kind. Completions read the live
+// state of the project, so a project or Docker daemon that isn't there
+// means no values rather than an error.
+func completionValues(ctx context.Context, kind string, args []string, manager *services.Manager) ([]string, cobra.ShellCompDirective) {
+	switch kind {
+	case completeServices:
+		return catalogServices(), cobra.ShellCompDirectiveNoFileComp
+	case completeEnabled:
+		if cfg, err := loadCompletionConfig(); err == nil {
+			return cfg.Stack.Enabled, cobra.ShellCompDirectiveNoFileComp
+		}
+	case completeRunning:
+		return runningServices(ctx, manager), cobra.ShellCompDirectiveNoFileComp
+	case completeBackups:
+		if backups := backupFiles(args); len(backups) > 0 {
+			return backups, cobra.ShellCompDirectiveNoFileComp
+		}
+		// Backups can live anywhere, so fall back to the shell's files
+		return nil, cobra.ShellCompDirectiveDefault
+	case completeSnapshots:
+		manifests, _ := snapshot.List()
+		names := make([]string, 0, len(manifests))
+		for _, manifest := range manifests {
+			names = append(names, manifest.Name+"\t"+manifest.CreatedAt.Local().Format("2006-01-02 15:04"))
+		}
+		return names, cobra.ShellCompDirectiveNoFileComp
+	case completeProfiles:
+		if cfg, err := loadCompletionConfig(); err == nil {
+			names := make([]string, 0, len(cfg.Profiles))
+			for name := range cfg.Profiles {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			return names, cobra.ShellCompDirectiveNoFileComp
+		}
+	case completeConfigKeys:
+		return configKeys(), cobra.ShellCompDirectiveNoFileComp
+	case completeTopics:
+		if cfg, err := loadCompletionConfig(); err == nil {
+			names := make([]string, 0, len(cfg.Kafka.Topics))
+			for _, topic := range cfg.Kafka.Topics {
+				names = append(names, topic.Name)
+			}
+			return names, cobra.ShellCompDirectiveNoFileComp
+		}
+	case completeCategories:
+		return pkgTypes.PruneCategories, cobra.ShellCompDirectiveNoFileComp
+	case completeShells:
+		return pkgTypes.AllShellTypeStrings(), cobra.ShellCompDirectiveNoFileComp
+	case completeWorkflows:
+		return workflowNames(), cobra.ShellCompDirectiveNoFileComp
+	case completeParams:
+		return workflowParams(args), cobra.ShellCompDirectiveNoFileComp
+	case completeTasks:
+		if cfg, err := loadCompletionConfig(); err == nil {
+			return projectTasks(cfg), cobra.ShellCompDirectiveNoFileComp
+		}
+	}
+	return nil, cobra.ShellCompDirectiveNoFileComp
+}
+
+// workflowNames returns the workflows, described by their description
+func workflowNames() []string {
+	cfg, _ := loadCompletionConfig()
+	workflows, err := core.Workflows(cfg)
+	if err != nil {
+		return nil
+	}
+	names := make([]string, 0, len(workflows))
+	for _, name := range slices.Sorted(maps.Keys(workflows)) {
+		names = append(names, name+"\t"+workflows[name].Description)
+	}
+	return names
+}
+
+// workflowParams returns the parameters of the workflow named by the first
+// argument as flags, leaving out those already given
+func workflowParams(args []string) []string {
+	if len(args) == 0 {
+		return nil
+	}
+	cfg, _ := loadCompletionConfig()
+	workflows, err := core.Workflows(cfg)
+	if err != nil {
+		return nil
+	}
+	workflow := workflows[args[0]]
+	var params []string
+	for _, name := range workflow.ParamNames() {
+		flag := "--" + name
+		if slices.ContainsFunc(args[1:], func(arg string) bool {
+			return arg == flag || strings.HasPrefix(arg, flag+"=")
+		}) {
+			continue
+		}
+		params = append(params, flag+"\t"+workflow.Params[name].Description)
+	}
+	return params
+}
+
+// projectTasks returns the tasks of the project, described by their
+// description
+func projectTasks(cfg *core.ProjectConfig) []string {
+	tasks, err := core.ProjectTasks(cfg)
+	if err != nil {
+		return nil
+	}
+	names := make([]string, 0, len(tasks))
+	for _, name := range tasks.Names() {
+		names = append(names, name+"\t"+tasks[name].Description)
+	}
+	return names
+}
+
+// catalogServices returns the built-in and project services, described by
+// their category
+func catalogServices() []string {
+	files, err := pkgServices.ListServiceFiles()
+	if err != nil {
+		return nil
+	}
+	names := make([]string, 0, len(files))
+	for _, file := range files {
+		names = append(names, file.Name+"\t"+file.Category)
+	}
+	return names
+}
+
+// runningServices returns the running services of the project
+func runningServices(ctx context.Context, manager *services.Manager) []string {
+	cfg, err := loadCompletionConfig()
+	if err != nil || manager == nil {
+		return nil
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	ctx, cancel := context.WithTimeout(ctx, completionTimeout)
+	defer cancel()
+
+	manager.SetProjectName(cfg.Project.Name)
+	running, err := core.RunningServices(ctx, manager, cfg.Stack.Enabled)
+	if err != nil {
+		return nil
+	}
+	return running
+}
+
+// backupFiles returns the files in the project's backup directory. When the
+// service to restore is on the command line, only its backups are offered,
+// as long as it has any.
+func backupFiles(args []string) []string {
+	dir := defaultBackupDir
+	project := ""
+	if cfg, err := loadCompletionConfig(); err == nil {
+		if cfg.Backup.Directory != "" {
+			dir = cfg.Backup.Directory
+		}
+		project = cfg.Project.Name
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+	var files, matched []string
+	for _, entry := range entries {
+		if !entry.Type().IsRegular() {
+			continue
+		}
+		path := filepath.Join(dir, entry.Name())
+		files = append(files, path)
+		// Backups are named <project>-<service>-<timestamp>
+		if len(args) > 0 && strings.HasPrefix(entry.Name(), project+"-"+args[0]+"-") {
+			matched = append(matched, path)
+		}
+	}
+	if len(matched) > 0 {
+		return matched
+	}
+	return files
+}
+
+// configKeys returns the settings of the project configuration
+func configKeys() []string {
+	data, err := os.ReadFile(filepath.Join(constants.DevStackDir, constants.ConfigFileName))
+	if err != nil {
+		return nil
+	}
+	entries, err := config.ListConfigValues(data)
+	if err != nil {
+		return nil
+	}
+	keys := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		keys = append(keys, entry.Path)
+	}
+	return keys
+}
+
+func loadCompletionConfig() (*core.ProjectConfig, error) {
+	return core.LoadProjectConfig(filepath.Join(constants.DevStackDir, constants.ConfigFileName))
+}
+
+// matching returns the values that start with prefix
+func matching(values []string, prefix string) []string {
+	var result []string
+	for _, value := range values {
+		if strings.HasPrefix(value, prefix) {
+			result = append(result, value)
+		}
+	}
+	return result
+}
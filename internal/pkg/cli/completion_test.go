@@ -0,0 +1,220 @@
+package cli
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/isaacgarza/dev-stack/internal/pkg/constants"
+	pkgTypes "github.com/isaacgarza/dev-stack/internal/pkg/types"
+)
+
+// writeCompletionProject changes to a new project directory with config as
+// its project configuration, or none when config is empty
+func writeCompletionProject(t *testing.T, config string) string {
+	t.Helper()
+	dir := t.TempDir()
+	t.Chdir(dir)
+	if config != "" {
+		require.NoError(t, os.MkdirAll(constants.DevStackDir, 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(constants.DevStackDir, constants.ConfigFileName), []byte(config), 0644))
+	}
+	return dir
+}
+
+func TestMatching(t *testing.T) {
+	tests := []struct {
+		name   string
+		values []string
+		prefix string
+		want   []string
+	}{
+		{name: "empty prefix", values: []string{"postgres", "redis"}, prefix: "", want: []string{"postgres", "redis"}},
+		{name: "prefix", values: []string{"postgres", "prometheus", "redis"}, prefix: "p", want: []string{"postgres", "prometheus"}},
+		{name: "description", values: []string{"postgres\tdatabase"}, prefix: "post", want: []string{"postgres\tdatabase"}},
+		{name: "no match", values: []string{"postgres", "redis"}, prefix: "k", want: nil},
+		{name: "no values", values: nil, prefix: "p", want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, matching(tt.values, tt.prefix))
+		})
+	}
+}
+
+func TestArgsCompletion(t *testing.T) {
+	writeCompletionProject(t, "project:\n  name: shop\nstack:\n  enabled: [postgres, redis]\n")
+
+	tests := []struct {
+		name          string
+		kinds         []string
+		args          []string
+		toComplete    string
+		want          []string
+		wantDirective cobra.ShellCompDirective
+	}{
+		{
+			name:          "first kind",
+			kinds:         []string{completeShells, completeCategories},
+			want:          []string{"bash", "zsh", "fish", "powershell"},
+			wantDirective: cobra.ShellCompDirectiveNoFileComp,
+		},
+		{
+			name:          "last kind repeats",
+			kinds:         []string{completeShells, completeCategories},
+			args:          []string{"bash", "images"},
+			want:          []string{"containers", "backups", "snapshots", "versions"},
+			wantDirective: cobra.ShellCompDirectiveNoFileComp,
+		},
+		{
+			name:          "given categories are left out",
+			kinds:         []string{completeCategories},
+			args:          []string{"images", "backups"},
+			toComplete:    "",
+			want:          []string{"containers", "snapshots", "versions"},
+			wantDirective: cobra.ShellCompDirectiveNoFileComp,
+		},
+		{
+			name:          "given enabled services are left out",
+			kinds:         []string{completeEnabled},
+			args:          []string{"postgres"},
+			want:          []string{"redis"},
+			wantDirective: cobra.ShellCompDirectiveNoFileComp,
+		},
+		{
+			name:          "described services are left out by name",
+			kinds:         []string{completeServices},
+			args:          []string{"postgres"},
+			toComplete:    "postgres",
+			want:          nil,
+			wantDirective: cobra.ShellCompDirectiveNoFileComp,
+		},
+		{
+			name:          "prefix",
+			kinds:         []string{completeEnabled},
+			toComplete:    "re",
+			want:          []string{"redis"},
+			wantDirective: cobra.ShellCompDirectiveNoFileComp,
+		},
+		{
+			name:          "no manager means nothing running",
+			kinds:         []string{completeRunning},
+			want:          nil,
+			wantDirective: cobra.ShellCompDirectiveNoFileComp,
+		},
+		{
+			name:          "no backups falls back to files",
+			kinds:         []string{completeBackups},
+			want:          nil,
+			wantDirective: cobra.ShellCompDirectiveDefault,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			values, directive := argsCompletion(tt.kinds, nil)(&cobra.Command{}, tt.args, tt.toComplete)
+			assert.Equal(t, tt.want, values)
+			assert.Equal(t, tt.wantDirective, directive)
+		})
+	}
+
+	// Leaving out the given values doesn't touch the shared lists
+	assert.Equal(t, []string{"containers", "images", "backups", "snapshots", "versions"}, pkgTypes.PruneCategories)
+}
+
+func TestArgsCompletion_ServicesKeepDescriptions(t *testing.T) {
+	writeCompletionProject(t, "")
+
+	values, _ := argsCompletion([]string{completeServices}, nil)(&cobra.Command{}, []string{"redis"}, "")
+	assert.Contains(t, values, "postgres\tdatabase")
+	assert.False(t, slices.ContainsFunc(values, func(value string) bool {
+		return strings.HasPrefix(value, "redis\t")
+	}), "redis is already given")
+}
+
+func TestBackupFiles(t *testing.T) {
+	dir := writeCompletionProject(t, "project:\n  name: shop\nbackup:\n  directory: dumps\n")
+	backups := filepath.Join(dir, "dumps")
+	require.NoError(t, os.MkdirAll(filepath.Join(backups, "shop-postgres-old"), 0755))
+	for _, name := range []string{"shop-postgres-20240101.sql", "shop-postgres-20240102.sql", "shop-redis-20240101.rdb", "notes.txt"} {
+		require.NoError(t, os.WriteFile(filepath.Join(backups, name), nil, 0644))
+	}
+	all := []string{
+		filepath.Join("dumps", "notes.txt"),
+		filepath.Join("dumps", "shop-postgres-20240101.sql"),
+		filepath.Join("dumps", "shop-postgres-20240102.sql"),
+		filepath.Join("dumps", "shop-redis-20240101.rdb"),
+	}
+
+	tests := []struct {
+		name string
+		args []string
+		want []string
+	}{
+		{name: "no service", want: all},
+		{name: "service backups", args: []string{"postgres"}, want: all[1:3]},
+		{name: "prefix is the whole service name", args: []string{"post"}, want: all},
+		{name: "service without backups", args: []string{"mysql"}, want: all},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, backupFiles(tt.args))
+		})
+	}
+}
+
+func TestBackupFiles_EmptyDirectory(t *testing.T) {
+	dir := writeCompletionProject(t, "")
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, defaultBackupDir), 0755))
+	assert.Empty(t, backupFiles([]string{"postgres"}))
+
+	// The shell completes files instead
+	values, directive := completionValues(context.Background(), completeBackups, []string{"postgres"}, nil)
+	assert.Nil(t, values)
+	assert.Equal(t, cobra.ShellCompDirectiveDefault, directive)
+}
+
+func TestWorkflowParams(t *testing.T) {
+	writeCompletionProject(t, `project:
+  name: shop
+workflows:
+  seed:
+    description: Seed the database
+    params:
+      tenant:
+        description: Tenant to seed
+      size:
+        description: Rows per table
+    steps:
+      - command: echo {{.tenant}} {{.size}}
+`)
+
+	tests := []struct {
+		name string
+		args []string
+		want []string
+	}{
+		{name: "no workflow", want: nil},
+		{name: "unknown workflow", args: []string{"missing"}, want: nil},
+		{name: "all params", args: []string{"seed"}, want: []string{"--size\tRows per table", "--tenant\tTenant to seed"}},
+		{name: "given param", args: []string{"seed", "--tenant", "acme"}, want: []string{"--size\tRows per table"}},
+		{name: "given param with value", args: []string{"seed", "--size=10"}, want: []string{"--tenant\tTenant to seed"}},
+		{name: "all given", args: []string{"seed", "--size=10", "--tenant", "acme"}, want: nil},
+		{name: "prefix of a param is not the param", args: []string{"seed", "--ten"}, want: []string{"--size\tRows per table", "--tenant\tTenant to seed"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, workflowParams(tt.args))
+		})
+	}
+}
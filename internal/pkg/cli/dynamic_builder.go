@@ -2,31 +2,60 @@ package cli
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
+	"strings"
+	"time"
 
 	"github.com/isaacgarza/dev-stack/internal/core/services"
+	"github.com/isaacgarza/dev-stack/internal/pkg/cli/handlers/adopt"
+	"github.com/isaacgarza/dev-stack/internal/pkg/cli/handlers/cleanup"
 	"github.com/isaacgarza/dev-stack/internal/pkg/cli/handlers/completion"
+	configHandler "github.com/isaacgarza/dev-stack/internal/pkg/cli/handlers/config"
 	"github.com/isaacgarza/dev-stack/internal/pkg/cli/handlers/core"
+	"github.com/isaacgarza/dev-stack/internal/pkg/cli/handlers/data"
+	"github.com/isaacgarza/dev-stack/internal/pkg/cli/handlers/docs"
 	"github.com/isaacgarza/dev-stack/internal/pkg/cli/handlers/doctor"
+	"github.com/isaacgarza/dev-stack/internal/pkg/cli/handlers/generate"
 	initHandler "github.com/isaacgarza/dev-stack/internal/pkg/cli/handlers/init"
+	"github.com/isaacgarza/dev-stack/internal/pkg/cli/handlers/release"
 	cliServices "github.com/isaacgarza/dev-stack/internal/pkg/cli/handlers/services"
+	"github.com/isaacgarza/dev-stack/internal/pkg/cli/handlers/shell"
+	telemetryHandler "github.com/isaacgarza/dev-stack/internal/pkg/cli/handlers/telemetry"
 	"github.com/isaacgarza/dev-stack/internal/pkg/cli/handlers/validate"
+	versionHandler "github.com/isaacgarza/dev-stack/internal/pkg/cli/handlers/version"
 	cliTypes "github.com/isaacgarza/dev-stack/internal/pkg/cli/types"
 	"github.com/isaacgarza/dev-stack/internal/pkg/config"
 	"github.com/isaacgarza/dev-stack/internal/pkg/constants"
+	pkgTypes "github.com/isaacgarza/dev-stack/internal/pkg/types"
+	"github.com/isaacgarza/dev-stack/internal/pkg/ui"
+	"github.com/isaacgarza/dev-stack/internal/pkg/version"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 )
 
-// BuildDynamicRootCommand creates commands from YAML configuration
-func BuildDynamicRootCommand(config *config.CommandConfig) (*cobra.Command, error) {
+// BuildDynamicRootCommand creates commands from YAML configuration. When
+// args are given, only the command they run is fully built, with its flags,
+// handler and subcommands; the others are stubs naming them. Nil args build
+// every command.
+func BuildDynamicRootCommand(config *config.CommandConfig, args []string) (*cobra.Command, error) {
 	log := slog.Default()
 
+	// Released binaries carry the version they were built as; development
+	// builds report the one of the command metadata
+	cliVersion := config.Metadata.CLIVersion
+	if !version.IsDevBuild() {
+		cliVersion = version.GetShortVersion()
+	}
+
 	rootCmd := &cobra.Command{
 		Use:     constants.AppName,
 		Short:   config.Metadata.Description,
-		Version: config.Metadata.CLIVersion,
-		Long:    fmt.Sprintf("%s\n\nVersion: %s", config.Metadata.Description, config.Metadata.CLIVersion),
+		Version: cliVersion,
+		Long:    fmt.Sprintf("%s\n\nVersion: %s", config.Metadata.Description, cliVersion),
+		// Errors are printed by ReportError, in the --error-format
+		SilenceErrors: true,
 	}
 
 	// Add global flags from config
@@ -34,48 +63,184 @@ func BuildDynamicRootCommand(config *config.CommandConfig) (*cobra.Command, erro
 		return nil, fmt.Errorf("failed to add global flags: %w", err)
 	}
 
+	// Apply the log format, the user configuration and the Docker timeouts
+	// before any command runs
+	rootCmd.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
+		if err := applyLogFormat(cmd); err != nil {
+			return err
+		}
+		if err := applyErrorFormat(cmd); err != nil {
+			return err
+		}
+		applyProgress(cmd)
+		applyGitHubActions()
+		if err := applyUserConfig(cmd); err != nil {
+			return err
+		}
+		return applyTimeouts(cmd)
+	}
+
 	serviceManager, err := createServiceManager()
 	if err != nil {
 		return nil, fmt.Errorf("failed to create service manager: %w", err)
 	}
 
+	// Global shorthands win over command-local ones, since cobra cannot
+	// merge persistent flags into a flagset that reuses the same shorthand
+	reserved := make(map[string]bool)
+	for _, flag := range config.Global.Flags {
+		if flag.Short != "" {
+			reserved[flag.Short] = true
+		}
+	}
+
 	// Build commands dynamically from config
+	stubs := make(map[*cobra.Command]string, len(config.Commands))
 	for cmdName, cmdConfig := range config.Commands {
-		cmd, err := buildCommandFromConfig(cmdName, cmdConfig, serviceManager, log)
-		if err != nil {
+		cmd := newCommandStub(cmdConfig)
+		stubs[cmd] = cmdName
+		rootCmd.AddCommand(cmd)
+	}
+
+	rootCmd.AddCommand(newExitCodesTopic())
+
+	for cmd, cmdName := range commandsToBuild(rootCmd, stubs, args) {
+		if err := populateCommand(cmd, cmdName, config.Commands[cmdName], serviceManager, log, reserved); err != nil {
 			return nil, fmt.Errorf("failed to build command %s: %w", cmdName, err)
 		}
-		rootCmd.AddCommand(cmd)
 	}
 
 	return rootCmd, nil
 }
 
-func buildCommandFromConfig(name string, cmdConfig config.Command, serviceManager *services.Manager, logger *slog.Logger) (*cobra.Command, error) {
+// fullTreeCommands are the commands that walk the whole command tree, such
+// as to print the help of the root or generate completion scripts and docs
+var fullTreeCommands = map[string]bool{
+	"help":                          true,
+	constants.CmdNameCompletion:     true,
+	constants.CmdNameDocs:           true,
+	cobra.ShellCompRequestCmd:       true,
+	cobra.ShellCompNoDescRequestCmd: true,
+}
+
+// commandsToBuild returns the stubs args need fully built: the top-level
+// command they run, or every one when they run the root or a command
+// walking the tree, or don't name a known command
+func commandsToBuild(rootCmd *cobra.Command, stubs map[*cobra.Command]string, args []string) map[*cobra.Command]string {
+	if args == nil {
+		return stubs
+	}
+	// cobra's Find merges the persistent flags into the flags of the
+	// commands it visits, which then clash with the flags of the command,
+	// so the first argument not a global flag or its value names it
+	name := commandArg(rootCmd, args)
+	if name == "" || fullTreeCommands[name] {
+		return stubs
+	}
+	for cmd, cmdName := range stubs {
+		if cmd.Name() == name || cmd.HasAlias(name) {
+			return map[*cobra.Command]string{cmd: cmdName}
+		}
+	}
+	return stubs
+}
+
+// commandArg returns the first of args that isn't a global flag or the
+// value of one, or "" when there is none
+func commandArg(rootCmd *cobra.Command, args []string) string {
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" {
+			return ""
+		}
+		if !strings.HasPrefix(arg, "-") || arg == "-" {
+			return arg
+		}
+		if strings.Contains(arg, "=") {
+			continue
+		}
+		var flag *pflag.Flag
+		if strings.HasPrefix(arg, "--") {
+			flag = rootCmd.PersistentFlags().Lookup(arg[2:])
+		} else {
+			flag = rootCmd.PersistentFlags().ShorthandLookup(arg[len(arg)-1:])
+		}
+		// Flags other than booleans take the next argument as their value
+		if flag != nil && flag.NoOptDefVal == "" {
+			i++
+		}
+	}
+	return ""
+}
+
+// newCommandStub returns a command with what naming it needs, for Find to
+// resolve it and the others to be built lazily
+func newCommandStub(cmdConfig config.Command) *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   cmdConfig.Usage,
 		Short: cmdConfig.Description,
-		Long:  cmdConfig.LongDescription,
 	}
 
 	// Add aliases
 	if len(cmdConfig.Aliases) > 0 {
 		cmd.Aliases = cmdConfig.Aliases
 	}
+	return cmd
+}
+
+func buildCommandFromConfig(name string, cmdConfig config.Command, serviceManager *services.Manager, logger *slog.Logger, reserved map[string]bool) (*cobra.Command, error) {
+	cmd := newCommandStub(cmdConfig)
+	if err := populateCommand(cmd, name, cmdConfig, serviceManager, logger, reserved); err != nil {
+		return nil, err
+	}
+	return cmd, nil
+}
+
+// populateCommand builds the stub cmd into the command name: its flags,
+// completions, handler, examples and subcommands
+func populateCommand(cmd *cobra.Command, name string, cmdConfig config.Command, serviceManager *services.Manager, logger *slog.Logger, reserved map[string]bool) error {
+	cmd.Long = cmdConfig.LongDescription
+
+	if cmdConfig.PassthroughArgs {
+		cmd.Flags().SetInterspersed(false)
+	}
 
 	// Add flags from config
 	for flagName, flagConfig := range cmdConfig.Flags {
+		if reserved[flagConfig.Short] {
+			flagConfig.Short = ""
+		}
 		addFlagFromConfig(cmd, flagName, flagConfig)
+		switch {
+		case flagConfig.Completion != "":
+			_ = cmd.RegisterFlagCompletionFunc(flagName, flagCompletion(flagConfig.Completion, serviceManager))
+		case len(flagConfig.Options) > 0:
+			_ = cmd.RegisterFlagCompletionFunc(flagName, cobra.FixedCompletions(flagConfig.Options, cobra.ShellCompDirectiveNoFileComp))
+		}
+	}
+
+	if len(cmdConfig.Completion) > 0 {
+		cmd.ValidArgsFunction = argsCompletion(cmdConfig.Completion, serviceManager)
 	}
 
 	// Set up command handler based on name
 	handler := getHandlerForCommand(name, serviceManager)
 	if handler != nil {
 		cmd.RunE = func(cmd *cobra.Command, args []string) error {
+			telemetry := true
+			if user, err := config.LoadUserConfig(); err == nil {
+				telemetry = user.TelemetryEnabled()
+			}
+			runLogger, finish := startCommandLog(cmd, logger, telemetry)
 			base := &cliTypes.BaseCommand{
-				Logger: &loggerAdapter{logger: logger},
+				Logger: &loggerAdapter{logger: runLogger},
 			}
-			return handler.Handle(context.Background(), cmd, args, base)
+			ctx := cmd.Context()
+			start := time.Now()
+			err := interrupted(ctx, cmd, handler.Handle(ctx, cmd, args, base))
+			finish(err)
+			recordUsage(cmd, time.Since(start), err, runLogger)
+			return err
 		}
 	}
 
@@ -84,61 +249,236 @@ func buildCommandFromConfig(name string, cmdConfig config.Command, serviceManage
 		cmd.Example = buildExamplesString(cmdConfig.Examples)
 	}
 
-	return cmd, nil
+	// Add subcommands, addressed by their space-separated command path
+	for subName, subConfig := range cmdConfig.Subcommands {
+		subCmd, err := buildCommandFromConfig(name+" "+subName, subConfig, serviceManager, logger, reserved)
+		if err != nil {
+			return fmt.Errorf("failed to build subcommand %s: %w", subName, err)
+		}
+		cmd.AddCommand(subCmd)
+	}
+
+	return nil
 }
 
 func getHandlerForCommand(name string, serviceManager *services.Manager) cliTypes.CommandHandler {
 	switch name {
 	case constants.CmdNameUp:
-		return core.NewUpHandler()
+		return core.NewUpHandler(serviceManager)
 	case constants.CmdNameDown:
-		return core.NewDownHandler()
+		return core.NewDownHandler(serviceManager)
 	case constants.CmdNameRestart:
 		return core.NewRestartHandler()
+	case constants.CmdNamePause:
+		return core.NewPauseHandler()
+	case constants.CmdNameResume:
+		return core.NewResumeHandler()
 	case constants.CmdNameStatus:
-		return core.NewStatusHandler()
+		return core.NewStatusHandler(serviceManager)
+	case constants.CmdNameTop:
+		return core.NewTopHandler()
+	case constants.CmdNameLogs:
+		return core.NewLogsHandler(serviceManager)
 	case constants.CmdNameInit:
 		return initHandler.NewInitHandler()
 	case constants.CmdNameDoctor:
 		return doctor.NewDoctorHandler()
+	case constants.CmdNameDiagnose:
+		return core.NewDiagnoseHandler()
+	case constants.CmdNameHealthz:
+		return core.NewHealthzHandler()
+	case constants.CmdNameEvents:
+		return core.NewEventsHandler()
+	case constants.CmdNameDaemon:
+		return core.NewDaemonHandler(serviceManager)
+	case constants.CmdNameIDEServer:
+		return core.NewIDEServerHandler(serviceManager)
+	case constants.CmdNameScale:
+		return core.NewScaleHandler(serviceManager)
+	case constants.CmdNameExec:
+		return core.NewExecHandler(serviceManager)
+	case constants.CmdNameWorkflow:
+		return core.NewWorkflowHandler(serviceManager)
+	case constants.CmdNameRun:
+		return core.NewRunHandler(serviceManager)
+	case constants.CmdNameCIUp:
+		return core.NewCIUpHandler(serviceManager)
+	case constants.CmdNameExportKubernetes:
+		return core.NewExportKubernetesHandler()
+	case constants.CmdNameExportDevcontainer:
+		return core.NewExportDevcontainerHandler()
+	case constants.CmdNameExportNix:
+		return core.NewExportNixHandler()
+	case constants.CmdNameDev:
+		return core.NewDevHandler(serviceManager)
+	case constants.CmdNameEnv:
+		return core.NewEnvHandler(serviceManager)
+	case constants.CmdNameURLs:
+		return core.NewURLsHandler()
+	case constants.CmdNameDiff:
+		return core.NewDiffHandler()
+	case constants.CmdNameShellInit:
+		return shell.NewShellInitHandler(serviceManager)
+	case constants.CmdNameAdopt:
+		return adopt.NewAdoptHandler()
 	case constants.CmdNameCompletion:
 		return completion.NewCompletionHandler()
-	case constants.CmdNameServices:
+	case constants.CmdNameCompletionInstall:
+		return completion.NewInstallHandler()
+	case constants.CmdNameServices, constants.CmdNameServicesList:
 		return cliServices.NewServicesHandler()
+	case constants.CmdNameServicesInfo:
+		return cliServices.NewInfoHandler()
+	case constants.CmdNameServicesSearch:
+		return cliServices.NewSearchHandler()
+	case constants.CmdNameServicesAdd:
+		return cliServices.NewAddHandler()
+	case constants.CmdNameServicesRemove:
+		return cliServices.NewRemoveHandler()
 	case constants.CmdNameDeps:
 		return cliServices.NewDepsHandler()
 	case constants.CmdNameConflicts:
 		return cliServices.NewConflictsHandler()
 	case constants.CmdNameValidate:
 		return validate.NewValidateHandler()
+	case constants.CmdNameBackup:
+		return data.NewBackupHandler(serviceManager)
+	case constants.CmdNameRestore:
+		return data.NewRestoreHandler(serviceManager)
+	case constants.CmdNameVolumeBackup:
+		return data.NewVolumeBackupHandler(serviceManager)
+	case constants.CmdNameVolumeRestore:
+		return data.NewVolumeRestoreHandler(serviceManager)
+	case constants.CmdNameSeed:
+		return data.NewSeedHandler(serviceManager)
+	case constants.CmdNameConnect:
+		return data.NewConnectHandler(serviceManager)
+	case constants.CmdNameGC:
+		return core.NewGCHandler()
+	case constants.CmdNameIdle:
+		return core.NewIdleHandler()
+	case constants.CmdNameBenchUp:
+		return core.NewBenchUpHandler()
+	case constants.CmdNameUnlock:
+		return core.NewUnlockHandler()
+	case constants.CmdNamePrune:
+		return core.NewPruneHandler()
+	case constants.CmdNameCleanup:
+		return cleanup.NewCleanupHandler(serviceManager)
+	case constants.CmdNameDocs:
+		return docs.NewDocsHandler()
+	case constants.CmdNameDocsCLI:
+		return docs.NewCLIHandler()
+	case constants.CmdNameReleaseManifests:
+		return release.NewManifestsHandler()
+	case constants.CmdNameVersion:
+		return versionHandler.NewVersionHandler()
+	case constants.CmdNamePull:
+		return core.NewPullHandler()
+	case constants.CmdNameBuild:
+		return core.NewBuildHandler()
+	case constants.CmdNameAWSLs:
+		return core.NewAWSLsHandler()
+	case constants.CmdNameKafkaTopics, constants.CmdNameKafkaTopicsList:
+		return core.NewKafkaTopicsListHandler()
+	case constants.CmdNameKafkaTopicsCreate:
+		return core.NewKafkaTopicsCreateHandler()
+	case constants.CmdNameKafkaTopicsDelete:
+		return core.NewKafkaTopicsDeleteHandler()
+	case constants.CmdNameKafkaTopicsDescribe:
+		return core.NewKafkaTopicsDescribeHandler()
+	case constants.CmdNameKafkaProduce:
+		return core.NewKafkaProduceHandler()
+	case constants.CmdNameKafkaConsume:
+		return core.NewKafkaConsumeHandler()
+	case constants.CmdNameTracesOpen:
+		return core.NewTracesOpenHandler()
+	case constants.CmdNameTracesPing:
+		return core.NewTracesPingHandler()
+	case constants.CmdNameImagesOutdated:
+		return core.NewImagesOutdatedHandler()
+	case constants.CmdNameImagesPin:
+		return core.NewImagesPinHandler()
+	case constants.CmdNameImagesUpdate:
+		return core.NewImagesUpdateHandler()
+	case constants.CmdNameImagesExport:
+		return core.NewImagesExportHandler()
+	case constants.CmdNameImagesImport:
+		return core.NewImagesImportHandler()
+	case constants.CmdNameSnapshotCreate:
+		return core.NewSnapshotCreateHandler()
+	case constants.CmdNameSnapshotRestore:
+		return core.NewSnapshotRestoreHandler()
+	case constants.CmdNameSnapshotList:
+		return core.NewSnapshotListHandler()
+	case constants.CmdNameNetworkMap:
+		return core.NewNetworkMapHandler()
+	case constants.CmdNameHostsSync:
+		return core.NewHostsSyncHandler()
+	case constants.CmdNameHostsRemove:
+		return core.NewHostsRemoveHandler()
+	case constants.CmdNameHostsList:
+		return core.NewHostsListHandler()
+	case constants.CmdNameConfigMigrate:
+		return configHandler.NewMigrateHandler()
+	case constants.CmdNameConfigGet:
+		return configHandler.NewGetHandler()
+	case constants.CmdNameConfigSet:
+		return configHandler.NewSetHandler()
+	case constants.CmdNameConfigUnset:
+		return configHandler.NewUnsetHandler()
+	case constants.CmdNameConfigList:
+		return configHandler.NewListHandler()
+	case constants.CmdNameTelemetryOn:
+		return telemetryHandler.NewOnHandler()
+	case constants.CmdNameTelemetryOff:
+		return telemetryHandler.NewOffHandler()
+	case constants.CmdNameTelemetryStatus:
+		return telemetryHandler.NewStatusHandler()
+	case constants.CmdNameGenerateDiagram:
+		return generate.NewDiagramHandler()
+	case constants.CmdNameGenerateCompose:
+		return generate.NewComposeHandler()
 	default:
 		return nil
 	}
 }
 
+// interrupted turns the error of a command stopped by an interrupt into a
+// quiet exit with ExitInterrupted, instead of reporting the cancelled
+// operation it was in the middle of as a failure
+func interrupted(ctx context.Context, cmd *cobra.Command, err error) error {
+	var exitErr *pkgTypes.ExitError
+	if err == nil || ctx.Err() == nil || errors.As(err, &exitErr) {
+		return err
+	}
+	ui.Warning("Interrupted")
+	cmd.SilenceErrors = true
+	cmd.SilenceUsage = true
+	return &pkgTypes.ExitError{Command: cmd.CommandPath(), Code: constants.ExitInterrupted}
+}
+
 func addGlobalFlagsFromConfig(cmd *cobra.Command, config *config.CommandConfig) error {
 	for name, flag := range config.Global.Flags {
 		switch flag.Type {
 		case "bool":
-			cmd.PersistentFlags().Bool(name, flag.Default.(bool), flag.Description)
+			defaultVal := false
+			if flag.Default != nil {
+				defaultVal = flag.Default.(bool)
+			}
+			cmd.PersistentFlags().BoolP(name, flag.Short, defaultVal, flag.Description)
 		case "string":
 			defaultVal := ""
 			if flag.Default != nil {
 				defaultVal = flag.Default.(string)
 			}
-			cmd.PersistentFlags().String(name, defaultVal, flag.Description)
+			cmd.PersistentFlags().StringP(name, flag.Short, defaultVal, flag.Description)
 		case "int":
 			defaultVal := 0
 			if flag.Default != nil {
 				defaultVal = flag.Default.(int)
 			}
-			cmd.PersistentFlags().Int(name, defaultVal, flag.Description)
-		}
-
-		if flag.Short != "" {
-			if pf := cmd.PersistentFlags().Lookup(name); pf != nil {
-				pf.Shorthand = flag.Short
-			}
+			cmd.PersistentFlags().IntP(name, flag.Short, defaultVal, flag.Description)
 		}
 	}
 	return nil
@@ -151,25 +491,19 @@ func addFlagFromConfig(cmd *cobra.Command, name string, flag config.Flag) {
 		if flag.Default != nil {
 			defaultVal = flag.Default.(bool)
 		}
-		cmd.Flags().Bool(name, defaultVal, flag.Description)
+		cmd.Flags().BoolP(name, flag.Short, defaultVal, flag.Description)
 	case "string":
 		defaultVal := ""
 		if flag.Default != nil {
 			defaultVal = flag.Default.(string)
 		}
-		cmd.Flags().String(name, defaultVal, flag.Description)
+		cmd.Flags().StringP(name, flag.Short, defaultVal, flag.Description)
 	case "int":
 		defaultVal := 0
 		if flag.Default != nil {
 			defaultVal = flag.Default.(int)
 		}
-		cmd.Flags().Int(name, defaultVal, flag.Description)
-	}
-
-	if flag.Short != "" {
-		if f := cmd.Flags().Lookup(name); f != nil {
-			f.Shorthand = flag.Short
-		}
+		cmd.Flags().IntP(name, flag.Short, defaultVal, flag.Description)
 	}
 }
 
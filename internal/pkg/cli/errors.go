@@ -0,0 +1,103 @@
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/isaacgarza/dev-stack/internal/pkg/constants"
+	"github.com/isaacgarza/dev-stack/internal/pkg/types"
+	"github.com/spf13/cobra"
+)
+
+// Values of --error-format
+const (
+	errorFormatText = "text"
+	errorFormatJSON = "json"
+)
+
+// errorReport is a failed command as --error-format json prints it
+type errorReport struct {
+	Error    string          `json:"error"`
+	Kind     types.ErrorKind `json:"kind"`
+	ExitCode int             `json:"exit_code"`
+	// Details are the fields of the error of a known kind, such as the
+	// port of a port conflict
+	Details types.KindedError `json:"details,omitempty"`
+}
+
+// applyErrorFormat checks --error-format and, for json, keeps the usage
+// text out of the output of failed commands
+func applyErrorFormat(cmd *cobra.Command) error {
+	format, _ := cmd.Flags().GetString(constants.FlagErrorFormat)
+	switch format {
+	case errorFormatText:
+	case errorFormatJSON:
+		cmd.SilenceUsage = true
+	default:
+		return fmt.Errorf("invalid --%s %q: must be text or json", constants.FlagErrorFormat, format)
+	}
+	return nil
+}
+
+// ReportError prints the error a command of root failed with to its error
+// output, as text or as the JSON object of --error-format json, and
+// returns the exit code of its kind. A types.ExitError was already
+// reported by what failed, so only its code is returned.
+func ReportError(root *cobra.Command, err error) int {
+	var exitErr *types.ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.Code
+	}
+
+	kind := types.ErrorKindOf(err)
+	format, _ := root.PersistentFlags().GetString(constants.FlagErrorFormat)
+	if format != errorFormatJSON {
+		_, _ = fmt.Fprintf(root.ErrOrStderr(), "Error: %v\n", err)
+		return kind.ExitCode()
+	}
+
+	report := errorReport{Error: err.Error(), Kind: kind, ExitCode: kind.ExitCode()}
+	var kinded types.KindedError
+	if errors.As(err, &kinded) {
+		report.Details = kinded
+	}
+	_ = json.NewEncoder(root.ErrOrStderr()).Encode(report)
+	return kind.ExitCode()
+}
+
+// newExitCodesTopic creates the help topic read with dev-stack help
+// exit-codes
+func newExitCodesTopic() *cobra.Command {
+	return &cobra.Command{
+		Use:   "exit-codes",
+		Short: "Exit codes and machine-readable errors",
+		Long:  exitCodesHelp(),
+	}
+}
+
+// exitCodesHelp lists the exit code of every error kind
+func exitCodesHelp() string {
+	var b strings.Builder
+	b.WriteString("dev-stack exits with a status telling scripts why a command failed:\n\n")
+	w := tabwriter.NewWriter(&b, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintf(w, "  %d\t%s\t%s\n", constants.ExitSuccess, "success", "The command succeeded")
+	for _, kind := range types.ErrorKinds() {
+		_, _ = fmt.Fprintf(w, "  %d\t%s\t%s\n", kind.ExitCode(), kind, kind.Description())
+	}
+	_ = w.Flush()
+	b.WriteString(`
+exec, connect and the other commands running a program in a container exit
+with the status of that program.
+
+With --error-format json, a failed command prints a JSON object on stderr
+instead of the error text, such as:
+
+  {"error":"port 5432 is already in use","kind":"port_conflict","exit_code":11,"details":{"port":"5432"}}
+
+details holds the fields of the error of a known kind and is left out for
+other errors.`)
+	return b.String()
+}
@@ -0,0 +1,136 @@
+package adopt
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/isaacgarza/dev-stack/internal/pkg/adopt"
+	"github.com/isaacgarza/dev-stack/internal/pkg/cli/types"
+	"github.com/isaacgarza/dev-stack/internal/pkg/constants"
+	"github.com/isaacgarza/dev-stack/internal/pkg/ui"
+	"github.com/isaacgarza/dev-stack/internal/pkg/utils"
+	"github.com/spf13/cobra"
+)
+
+// AdoptHandler handles the adopt command
+type AdoptHandler struct{}
+
+// NewAdoptHandler creates a new adopt handler
+func NewAdoptHandler() *AdoptHandler {
+	return &AdoptHandler{}
+}
+
+// Handle executes the adopt command
+func (h *AdoptHandler) Handle(ctx context.Context, cmd *cobra.Command, args []string, base *types.BaseCommand) error {
+	if err := h.ValidateArgs(args); err != nil {
+		return err
+	}
+
+	root := "."
+	if len(args) == 1 {
+		root = args[0]
+	}
+	name, _ := cmd.Flags().GetString("name")
+	output, _ := cmd.Flags().GetString("output")
+	force, _ := cmd.Flags().GetBool("force")
+
+	absRoot, err := filepath.Abs(root)
+	if err != nil {
+		return fmt.Errorf("failed to resolve %s: %w", root, err)
+	}
+	if name == "" {
+		name = filepath.Base(absRoot)
+	}
+
+	ui.Header("Adopting Legacy Setup")
+
+	report, err := adopt.Scan(absRoot)
+	if err != nil {
+		return err
+	}
+
+	h.printReport(report)
+
+	if len(report.ServiceNames()) == 0 {
+		ui.Warning("No services were detected; run '%s' to choose services interactively", constants.CmdInit)
+		return nil
+	}
+
+	proposal := report.Propose(name, constants.DefaultEnvironment)
+	if output == "" {
+		ui.SubHeader("Proposed %s", constants.ConfigFileName)
+		fmt.Print(proposal)
+		ui.Info("Save it with --output %s", filepath.Join(constants.DevStackDir, constants.ConfigFileName))
+		return nil
+	}
+
+	if utils.FileExists(output) && !force {
+		return fmt.Errorf("%s already exists; use --force to overwrite", output)
+	}
+	if err := os.MkdirAll(filepath.Dir(output), 0755); err != nil {
+		return fmt.Errorf("failed to create directory for %s: %w", output, err)
+	}
+	if err := os.WriteFile(output, []byte(proposal), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", output, err)
+	}
+
+	ui.Success("Wrote %s", output)
+	return nil
+}
+
+// printReport summarises what was detected and what could not be translated
+func (h *AdoptHandler) printReport(report *adopt.Report) {
+	if len(report.Services) > 0 {
+		ui.SubHeader("Detected containers")
+		var items []string
+		for _, service := range report.Services {
+			target := service.Name
+			if target == "" {
+				target = "no equivalent"
+			}
+			items = append(items, fmt.Sprintf("%s → %s (%s)", service.Image, target, service.Source))
+		}
+		ui.List(items)
+	}
+
+	if len(report.EnvFiles) > 0 {
+		ui.SubHeader("Env files")
+		paths := make([]string, 0, len(report.EnvFiles))
+		for path := range report.EnvFiles {
+			paths = append(paths, path)
+		}
+		sort.Strings(paths)
+
+		var items []string
+		for _, path := range paths {
+			items = append(items, fmt.Sprintf("%s (%d variables)", path, len(report.EnvFiles[path])))
+		}
+		ui.List(items)
+		ui.Muted("Variables in the project %s are available to the config through ${VAR} interpolation", constants.DotEnvFileName)
+	}
+
+	if len(report.Notes) > 0 {
+		ui.SubHeader("Could not translate")
+		var items []string
+		for _, note := range report.Notes {
+			items = append(items, fmt.Sprintf("%s: %s", note.Source, note.Text))
+		}
+		ui.List(items)
+	}
+}
+
+// ValidateArgs validates the command arguments
+func (h *AdoptHandler) ValidateArgs(args []string) error {
+	if len(args) > 1 {
+		return fmt.Errorf("adopt accepts at most one directory")
+	}
+	return nil
+}
+
+// GetRequiredFlags returns required flags for this command
+func (h *AdoptHandler) GetRequiredFlags() []string {
+	return []string{}
+}
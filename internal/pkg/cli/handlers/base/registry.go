@@ -52,10 +52,10 @@ func (r *Registry) HasHandler(name string) bool {
 
 // registerDefaultHandlers registers all default command handlers
 func (r *Registry) registerDefaultHandlers() {
-	r.RegisterHandler("up", core.NewUpHandler())
-	r.RegisterHandler("down", core.NewDownHandler())
+	r.RegisterHandler("up", core.NewUpHandler(nil))
+	r.RegisterHandler("down", core.NewDownHandler(nil))
 	r.RegisterHandler("restart", core.NewRestartHandler())
-	r.RegisterHandler("status", core.NewStatusHandler())
+	r.RegisterHandler("status", core.NewStatusHandler(nil))
 	r.RegisterHandler("deps", services.NewDepsHandler())
 	r.RegisterHandler("conflicts", services.NewConflictsHandler())
 	r.RegisterHandler("services", services.NewServicesHandler())
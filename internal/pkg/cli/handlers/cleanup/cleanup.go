@@ -0,0 +1,185 @@
+package cleanup
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/isaacgarza/dev-stack/internal/core/services"
+	"github.com/isaacgarza/dev-stack/internal/pkg/cli/handlers/core"
+	handlerUtils "github.com/isaacgarza/dev-stack/internal/pkg/cli/handlers/utils"
+	"github.com/isaacgarza/dev-stack/internal/pkg/cli/types"
+	"github.com/isaacgarza/dev-stack/internal/pkg/constants"
+	pkgTypes "github.com/isaacgarza/dev-stack/internal/pkg/types"
+	"github.com/isaacgarza/dev-stack/internal/pkg/ui"
+	"github.com/isaacgarza/dev-stack/internal/pkg/utils"
+	"github.com/spf13/cobra"
+)
+
+// CleanupHandler handles the cleanup command
+type CleanupHandler struct {
+	manager *services.Manager
+}
+
+// NewCleanupHandler creates a new cleanup handler
+func NewCleanupHandler(manager *services.Manager) *CleanupHandler {
+	return &CleanupHandler{manager: manager}
+}
+
+// Handle executes the cleanup command
+func (h *CleanupHandler) Handle(ctx context.Context, cmd *cobra.Command, args []string, base *types.BaseCommand) error {
+	// Orphans belong to projects that are gone, so no project is needed
+	if orphans, _ := cmd.Flags().GetBool("orphans"); orphans {
+		return h.cleanupOrphans(ctx, cmd)
+	}
+
+	configPath := filepath.Join(constants.DevStackDir, constants.ConfigFileName)
+	if !utils.FileExists(configPath) {
+		return errors.New(constants.ErrNotInitialized)
+	}
+
+	cfg, err := core.LoadProjectConfig(configPath)
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	all, _ := cmd.Flags().GetBool("all")
+	volumes, _ := cmd.Flags().GetBool("volumes")
+	images, _ := cmd.Flags().GetBool("images")
+	networks, _ := cmd.Flags().GetBool("networks")
+	force, _ := cmd.Flags().GetBool("force")
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+
+	options := pkgTypes.CleanupOptions{
+		RemoveVolumes:  all || volumes,
+		RemoveImages:   all || images,
+		RemoveNetworks: all || networks,
+		All:            all,
+		DryRun:         dryRun,
+	}
+
+	targets := []string{"containers"}
+	if options.RemoveVolumes {
+		targets = append(targets, "volumes")
+	}
+	if options.RemoveImages {
+		targets = append(targets, "images")
+	}
+	if options.RemoveNetworks {
+		targets = append(targets, "networks")
+	}
+	action := fmt.Sprintf("remove %s for project %s", strings.Join(targets, ", "), cfg.Project.Name)
+
+	if dryRun {
+		plan, err := core.CleanupPlan(cfg, configPath, options)
+		if err != nil {
+			return err
+		}
+		return core.WritePlan(cmd.OutOrStdout(), handlerUtils.GetCIFlags(cmd).JSON, plan)
+	}
+
+	ui.Header("Cleaning Up Resources")
+
+	if err := core.ConfirmProtected(cmd, cfg, action); err != nil {
+		return err
+	}
+	if !force && !ui.DefaultOutput.ConfirmDestructive(action) {
+		ui.Info("Cleanup cancelled")
+		return nil
+	}
+
+	h.manager.SetProjectName(cfg.Project.Name)
+	if err := h.manager.CleanupResources(ctx, options); err != nil {
+		return fmt.Errorf("cleanup failed: %w", err)
+	}
+
+	ui.Success("Cleanup completed")
+	return nil
+}
+
+// cleanupOrphans lists the resources dev-stack created for projects whose
+// directory no longer exists and removes them once confirmed
+func (h *CleanupHandler) cleanupOrphans(ctx context.Context, cmd *cobra.Command) error {
+	all, _ := cmd.Flags().GetBool("all")
+	images, _ := cmd.Flags().GetBool("images")
+	force, _ := cmd.Flags().GetBool("force")
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+
+	orphans, err := h.manager.OrphanedResources(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to find orphaned resources: %w", err)
+	}
+	if handlerUtils.GetCIFlags(cmd).JSON {
+		if orphans == nil {
+			orphans = []pkgTypes.OwnedResources{}
+		}
+		encoder := json.NewEncoder(cmd.OutOrStdout())
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(orphans); err != nil {
+			return err
+		}
+		if dryRun {
+			return nil
+		}
+	} else {
+		ui.Header("Orphaned Resources")
+		if len(orphans) == 0 {
+			ui.Success("No orphaned resources")
+			return nil
+		}
+		writeOrphans(cmd.OutOrStdout(), orphans)
+	}
+	if len(orphans) == 0 {
+		return nil
+	}
+	if dryRun {
+		ui.Info("Dry run: nothing was removed")
+		return nil
+	}
+
+	action := fmt.Sprintf("remove the containers, volumes and networks of %d deleted project(s)", len(orphans))
+	if !force && !ui.DefaultOutput.ConfirmDestructive(action) {
+		ui.Info("Cleanup cancelled")
+		return nil
+	}
+	options := pkgTypes.CleanupOptions{RemoveImages: all || images}
+	if err := h.manager.RemoveOrphans(ctx, orphans, options); err != nil {
+		return fmt.Errorf("cleanup failed: %w", err)
+	}
+	ui.Success("Removed the resources of %d deleted project(s)", len(orphans))
+	return nil
+}
+
+// writeOrphans prints the resources of each orphaned project
+func writeOrphans(w io.Writer, orphans []pkgTypes.OwnedResources) {
+	for _, orphan := range orphans {
+		_, _ = fmt.Fprintf(w, "%s (%s)\n", orphan.Project, orphan.Dir)
+		for _, kind := range []struct {
+			name  string
+			names []string
+		}{
+			{"containers", orphan.Containers},
+			{"volumes", orphan.Volumes},
+			{"networks", orphan.Networks},
+			{"images", orphan.Images},
+		} {
+			if len(kind.names) > 0 {
+				_, _ = fmt.Fprintf(w, "  %-11s %s\n", kind.name+":", strings.Join(kind.names, ", "))
+			}
+		}
+	}
+}
+
+// ValidateArgs validates the command arguments
+func (h *CleanupHandler) ValidateArgs(args []string) error {
+	return nil
+}
+
+// GetRequiredFlags returns required flags for this command
+func (h *CleanupHandler) GetRequiredFlags() []string {
+	return []string{}
+}
@@ -3,7 +3,7 @@ package completion
 import (
 	"context"
 	"fmt"
-	"os"
+	"io"
 
 	"github.com/isaacgarza/dev-stack/internal/pkg/cli/types"
 	pkgTypes "github.com/isaacgarza/dev-stack/internal/pkg/types"
@@ -36,20 +36,26 @@ func (h *CompletionHandler) GetRequiredFlags() []string {
 }
 
 func (h *CompletionHandler) Handle(ctx context.Context, cmd *cobra.Command, args []string, base *types.BaseCommand) error {
-	shell := pkgTypes.ShellType(args[0])
-
-	// Get the root command to generate completion for
-	rootCmd := cmd.Root()
+	if err := h.ValidateArgs(args); err != nil {
+		return err
+	}
+	// Generate completion for the root command
+	return generateScript(cmd.Root(), pkgTypes.ShellType(args[0]), cmd.OutOrStdout())
+}
 
+// generateScript writes the completion script of shell for root to out
+func generateScript(root *cobra.Command, shell pkgTypes.ShellType, out io.Writer) error {
+	// Every script completes through the hidden __complete command, so
+	// the shells offer the same services, profiles and flags
 	switch shell {
 	case pkgTypes.ShellTypeBash:
-		return rootCmd.GenBashCompletion(os.Stdout)
+		return root.GenBashCompletion(out)
 	case pkgTypes.ShellTypeZsh:
-		return rootCmd.GenZshCompletion(os.Stdout)
+		return root.GenZshCompletion(out)
 	case pkgTypes.ShellTypeFish:
-		return rootCmd.GenFishCompletion(os.Stdout, true)
+		return root.GenFishCompletion(out, true)
 	case pkgTypes.ShellTypePowerShell:
-		return rootCmd.GenPowerShellCompletion(os.Stdout)
+		return root.GenPowerShellCompletionWithDesc(out)
 	default:
 		return fmt.Errorf("unsupported shell: %s", shell)
 	}
@@ -0,0 +1,67 @@
+package completion
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	pkgTypes "github.com/isaacgarza/dev-stack/internal/pkg/types"
+)
+
+// generate runs the completion handler for shell under a root command
+// named dev-stack and returns the script it writes
+func generate(t *testing.T, shell string) string {
+	root := &cobra.Command{Use: "dev-stack"}
+	up := &cobra.Command{
+		Use: "up",
+		ValidArgsFunction: func(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective) {
+			return []string{"postgres\tPostgreSQL database"}, cobra.ShellCompDirectiveNoFileComp
+		},
+		Run: func(*cobra.Command, []string) {},
+	}
+	completion := &cobra.Command{Use: "completion"}
+	root.AddCommand(up, completion)
+
+	var out bytes.Buffer
+	completion.SetOut(&out)
+	handler := NewCompletionHandler()
+	require.NoError(t, handler.ValidateArgs([]string{shell}))
+	require.NoError(t, handler.Handle(context.Background(), completion, []string{shell}, nil))
+	return out.String()
+}
+
+func TestHandle(t *testing.T) {
+	markers := map[pkgTypes.ShellType]string{
+		pkgTypes.ShellTypeBash:       "# bash completion for dev-stack",
+		pkgTypes.ShellTypeZsh:        "#compdef dev-stack",
+		pkgTypes.ShellTypeFish:       "complete -c dev-stack",
+		pkgTypes.ShellTypePowerShell: "Register-ArgumentCompleter -CommandName 'dev-stack'",
+	}
+
+	for _, shell := range pkgTypes.AllShellTypes() {
+		t.Run(shell.String(), func(t *testing.T) {
+			script := generate(t, shell.String())
+			assert.Contains(t, script, markers[shell])
+			// Completions come from the same place in every shell
+			assert.Contains(t, script, cobra.ShellCompRequestCmd)
+		})
+	}
+}
+
+func TestHandle_PowerShell(t *testing.T) {
+	script := generate(t, pkgTypes.ShellTypePowerShell.String())
+
+	// Descriptions are shown, as in zsh and fish
+	assert.NotContains(t, script, cobra.ShellCompNoDescRequestCmd)
+}
+
+func TestValidateArgs(t *testing.T) {
+	handler := NewCompletionHandler()
+	assert.NoError(t, handler.ValidateArgs([]string{"powershell"}))
+	assert.ErrorContains(t, handler.ValidateArgs([]string{"cmd"}), "unsupported shell")
+	assert.Error(t, handler.ValidateArgs(nil))
+}
@@ -0,0 +1,327 @@
+package completion
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/isaacgarza/dev-stack/internal/pkg/cli/types"
+	"github.com/isaacgarza/dev-stack/internal/pkg/constants"
+	pkgTypes "github.com/isaacgarza/dev-stack/internal/pkg/types"
+	"github.com/isaacgarza/dev-stack/internal/pkg/ui"
+	"github.com/spf13/cobra"
+)
+
+// Markers around the lines install adds to the startup file of a shell,
+// so installing again replaces them and uninstalling removes them
+const (
+	blockStart = "# >>> dev-stack completion >>>"
+	blockEnd   = "# <<< dev-stack completion <<<"
+)
+
+// installEnv is what the install paths depend on
+type installEnv struct {
+	home   string
+	goos   string
+	getenv func(string) string
+}
+
+// dir returns the directory variable names, or fallback under the home
+// directory when it is unset
+func (e installEnv) dir(variable string, fallback ...string) string {
+	if dir := e.getenv(variable); dir != "" {
+		return dir
+	}
+	return filepath.Join(append([]string{e.home}, fallback...)...)
+}
+
+// brewDir returns dir under the Homebrew prefix, when Homebrew is set up
+// in the environment and has created it
+func (e installEnv) brewDir(dir ...string) (string, bool) {
+	prefix := e.getenv("HOMEBREW_PREFIX")
+	if prefix == "" {
+		return "", false
+	}
+	path := filepath.Join(append([]string{prefix}, dir...)...)
+	if info, err := os.Stat(path); err != nil || !info.IsDir() {
+		return "", false
+	}
+	return path, true
+}
+
+// installTarget is where the completion script of a shell goes, and the
+// startup file lines loading it. RCFile is empty for shells that load the
+// script from its directory on their own.
+type installTarget struct {
+	Script  string
+	RCFile  string
+	RCLines []string
+}
+
+// installTargetFor returns where the completion of shell is installed.
+// Scripts go where Homebrew's shells look when Homebrew is there, and into
+// the user's completion directories otherwise.
+func installTargetFor(shell pkgTypes.ShellType, env installEnv) installTarget {
+	switch shell {
+	case pkgTypes.ShellTypeBash:
+		dir, ok := env.brewDir("etc", "bash_completion.d")
+		if !ok {
+			userDir := env.getenv("BASH_COMPLETION_USER_DIR")
+			if userDir == "" {
+				userDir = filepath.Join(env.dir("XDG_DATA_HOME", ".local", "share"), "bash-completion")
+			}
+			dir = filepath.Join(userDir, "completions")
+		}
+		script := filepath.Join(dir, constants.AppName)
+		// Terminals on macOS start login shells, which read .bash_profile
+		rcFile := filepath.Join(env.home, ".bashrc")
+		if env.goos == "darwin" {
+			rcFile = filepath.Join(env.home, ".bash_profile")
+		}
+		return installTarget{
+			Script:  script,
+			RCFile:  rcFile,
+			RCLines: []string{fmt.Sprintf("[ -f %[1]s ] && . %[1]s", shellQuote(script))},
+		}
+	case pkgTypes.ShellTypeZsh:
+		dir, ok := env.brewDir("share", "zsh", "site-functions")
+		if !ok {
+			dir = filepath.Join(env.home, ".zsh", "completions")
+		}
+		// compinit runs again after fpath changes, so the block works
+		// wherever it sits in .zshrc
+		return installTarget{
+			Script: filepath.Join(dir, "_"+constants.AppName),
+			RCFile: filepath.Join(env.dir("ZDOTDIR"), ".zshrc"),
+			RCLines: []string{
+				fmt.Sprintf("fpath=(%s $fpath)", shellQuote(dir)),
+				"autoload -Uz compinit && compinit",
+			},
+		}
+	case pkgTypes.ShellTypeFish:
+		return installTarget{
+			Script: filepath.Join(env.dir("XDG_CONFIG_HOME", ".config"), "fish", "completions", constants.AppName+".fish"),
+		}
+	case pkgTypes.ShellTypePowerShell:
+		profileDir := filepath.Join(env.dir("XDG_CONFIG_HOME", ".config"), "powershell")
+		if env.goos == "windows" {
+			profileDir = filepath.Join(env.home, "Documents", "PowerShell")
+		}
+		script := filepath.Join(profileDir, constants.AppName+"-completion.ps1")
+		return installTarget{
+			Script:  script,
+			RCFile:  filepath.Join(profileDir, "Microsoft.PowerShell_profile.ps1"),
+			RCLines: []string{". " + powerShellQuote(script)},
+		}
+	}
+	return installTarget{}
+}
+
+// detectShell returns the shell named by args, or else the user's login
+// shell
+func detectShell(args []string, env installEnv) (pkgTypes.ShellType, error) {
+	if len(args) > 0 {
+		return pkgTypes.ShellType(args[0]), nil
+	}
+	name := strings.TrimSuffix(filepath.Base(env.getenv("SHELL")), ".exe")
+	switch {
+	case name == "pwsh":
+		return pkgTypes.ShellTypePowerShell, nil
+	case pkgTypes.ShellType(name).IsValid():
+		return pkgTypes.ShellType(name), nil
+	case env.getenv("SHELL") == "" && env.goos == "windows":
+		return pkgTypes.ShellTypePowerShell, nil
+	}
+	return "", fmt.Errorf("couldn't detect the shell; name it (%v)", pkgTypes.AllShellTypeStrings())
+}
+
+// InstallHandler handles the completion install command
+type InstallHandler struct {
+	env installEnv
+}
+
+// NewInstallHandler creates a new completion install handler
+func NewInstallHandler() *InstallHandler {
+	home, _ := os.UserHomeDir()
+	return &InstallHandler{env: installEnv{home: home, goos: runtime.GOOS, getenv: os.Getenv}}
+}
+
+// Handle executes the completion install command. It writes the completion
+// script of the shell where the shell looks for it and, for shells that
+// need it, adds the lines loading it to the shell's startup file. With
+// --uninstall it removes both again.
+func (h *InstallHandler) Handle(ctx context.Context, cmd *cobra.Command, args []string, base *types.BaseCommand) error {
+	if err := h.ValidateArgs(args); err != nil {
+		return err
+	}
+	uninstall, _ := cmd.Flags().GetBool("uninstall")
+	shell, err := detectShell(args, h.env)
+	if err != nil {
+		return err
+	}
+	if h.env.home == "" {
+		return errors.New("couldn't find the home directory")
+	}
+	target := installTargetFor(shell, h.env)
+
+	if uninstall {
+		return h.uninstall(shell, target)
+	}
+
+	var script bytes.Buffer
+	if err := generateScript(cmd.Root(), shell, &script); err != nil {
+		return fmt.Errorf("failed to generate the %s completion: %w", shell, err)
+	}
+	if err := os.MkdirAll(filepath.Dir(target.Script), 0755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", filepath.Dir(target.Script), err)
+	}
+	if err := os.WriteFile(target.Script, script.Bytes(), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", target.Script, err)
+	}
+	ui.Success("Installed the %s completion to %s", shell, target.Script)
+
+	if target.RCFile == "" {
+		ui.Info("New %s sessions load it", shell)
+		return nil
+	}
+	if err := editRCFile(target.RCFile, func(content string) string {
+		return upsertBlock(content, target.RCLines)
+	}); err != nil {
+		return err
+	}
+	ui.Info("Loaded from %s; open a new shell to use it", target.RCFile)
+	return nil
+}
+
+// uninstall removes the completion script and startup file lines of shell
+func (h *InstallHandler) uninstall(shell pkgTypes.ShellType, target installTarget) error {
+	removed := false
+	if err := os.Remove(target.Script); err == nil {
+		removed = true
+		ui.Success("Removed %s", target.Script)
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to remove %s: %w", target.Script, err)
+	}
+
+	if target.RCFile != "" {
+		content, err := os.ReadFile(target.RCFile)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("failed to read %s: %w", target.RCFile, err)
+		}
+		if strings.Contains(string(content), blockStart) {
+			if err := editRCFile(target.RCFile, removeBlock); err != nil {
+				return err
+			}
+			removed = true
+			ui.Success("Removed the completion lines from %s", target.RCFile)
+		}
+	}
+
+	if !removed {
+		ui.Info("The %s completion isn't installed", shell)
+	}
+	return nil
+}
+
+// ValidateArgs validates the command arguments
+func (h *InstallHandler) ValidateArgs(args []string) error {
+	if len(args) > 1 {
+		return fmt.Errorf("completion install takes at most one shell (%v)", pkgTypes.AllShellTypeStrings())
+	}
+	if len(args) == 1 && !pkgTypes.ShellType(args[0]).IsValid() {
+		return fmt.Errorf("unsupported shell: %s (supported: %v)", args[0], pkgTypes.AllShellTypeStrings())
+	}
+	return nil
+}
+
+// GetRequiredFlags returns required flags for this command
+func (h *InstallHandler) GetRequiredFlags() []string {
+	return []string{}
+}
+
+// editRCFile rewrites the startup file with edit, creating it when it
+// doesn't exist and leaving it untouched when nothing changes
+func editRCFile(file string, edit func(string) string) error {
+	content, err := os.ReadFile(file)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to read %s: %w", file, err)
+	}
+	updated := edit(string(content))
+	if updated == string(content) {
+		return nil
+	}
+
+	mode := os.FileMode(0644)
+	if info, err := os.Stat(file); err == nil {
+		mode = info.Mode().Perm()
+	}
+	if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", filepath.Dir(file), err)
+	}
+	if err := os.WriteFile(file, []byte(updated), mode); err != nil {
+		return fmt.Errorf("failed to write %s: %w", file, err)
+	}
+	return nil
+}
+
+// upsertBlock returns content with the marked block holding lines,
+// replacing the block in place when there is one and appending it otherwise
+func upsertBlock(content string, lines []string) string {
+	block := blockStart + "\n" + strings.Join(lines, "\n") + "\n" + blockEnd + "\n"
+	if before, after, ok := cutBlock(content); ok {
+		return before + block + after
+	}
+	if content != "" && !strings.HasSuffix(content, "\n") {
+		content += "\n"
+	}
+	if content != "" {
+		content += "\n"
+	}
+	return content + block
+}
+
+// removeBlock returns content without the marked block
+func removeBlock(content string) string {
+	before, after, ok := cutBlock(content)
+	if !ok {
+		return content
+	}
+	// Drop the blank line upsertBlock put before the block
+	if after == "" {
+		before = strings.TrimSuffix(before, "\n")
+	}
+	return before + after
+}
+
+// cutBlock returns the content before and after the marked block, and
+// whether there is one
+func cutBlock(content string) (before, after string, found bool) {
+	start := strings.Index(content, blockStart)
+	if start < 0 {
+		return content, "", false
+	}
+	end := strings.Index(content[start:], blockEnd)
+	if end < 0 {
+		return content, "", false
+	}
+	end += start + len(blockEnd)
+	if end < len(content) && content[end] == '\n' {
+		end++
+	}
+	return content[:start], content[end:], true
+}
+
+// shellQuote quotes s for bash and zsh
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// powerShellQuote quotes s for PowerShell
+func powerShellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
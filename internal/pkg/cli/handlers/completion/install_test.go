@@ -0,0 +1,162 @@
+package completion
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	pkgTypes "github.com/isaacgarza/dev-stack/internal/pkg/types"
+)
+
+// testEnv returns an install environment with home under a temporary
+// directory and the variables of vars
+func testEnv(t *testing.T, goos string, vars map[string]string) installEnv {
+	return installEnv{
+		home:   t.TempDir(),
+		goos:   goos,
+		getenv: func(key string) string { return vars[key] },
+	}
+}
+
+// install runs the completion install command with args under a root
+// command named dev-stack
+func install(t *testing.T, env installEnv, args ...string) error {
+	root := &cobra.Command{Use: "dev-stack"}
+	completion := &cobra.Command{Use: "completion"}
+	cmd := &cobra.Command{Use: "install"}
+	cmd.Flags().Bool("uninstall", false, "")
+	completion.AddCommand(cmd)
+	root.AddCommand(completion)
+	require.NoError(t, cmd.ParseFlags(args))
+
+	handler := &InstallHandler{env: env}
+	return handler.Handle(context.Background(), cmd, cmd.Flags().Args(), nil)
+}
+
+func TestInstallTargetFor(t *testing.T) {
+	env := testEnv(t, "linux", nil)
+
+	bash := installTargetFor(pkgTypes.ShellTypeBash, env)
+	assert.Equal(t, filepath.Join(env.home, ".local", "share", "bash-completion", "completions", "dev-stack"), bash.Script)
+	assert.Equal(t, filepath.Join(env.home, ".bashrc"), bash.RCFile)
+
+	zsh := installTargetFor(pkgTypes.ShellTypeZsh, env)
+	assert.Equal(t, filepath.Join(env.home, ".zsh", "completions", "_dev-stack"), zsh.Script)
+	assert.Equal(t, filepath.Join(env.home, ".zshrc"), zsh.RCFile)
+
+	fish := installTargetFor(pkgTypes.ShellTypeFish, env)
+	assert.Equal(t, filepath.Join(env.home, ".config", "fish", "completions", "dev-stack.fish"), fish.Script)
+	assert.Empty(t, fish.RCFile, "fish loads its completions directory on its own")
+
+	powershell := installTargetFor(pkgTypes.ShellTypePowerShell, testEnv(t, "windows", nil))
+	assert.Equal(t, "Microsoft.PowerShell_profile.ps1", filepath.Base(powershell.RCFile))
+	assert.Equal(t, filepath.Dir(powershell.RCFile), filepath.Dir(powershell.Script))
+
+	// macOS terminals start login shells
+	assert.Equal(t, ".bash_profile", filepath.Base(installTargetFor(pkgTypes.ShellTypeBash, testEnv(t, "darwin", nil)).RCFile))
+}
+
+func TestInstallTargetFor_Homebrew(t *testing.T) {
+	prefix := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(prefix, "share", "zsh", "site-functions"), 0755))
+	env := testEnv(t, "darwin", map[string]string{"HOMEBREW_PREFIX": prefix})
+
+	zsh := installTargetFor(pkgTypes.ShellTypeZsh, env)
+	assert.Equal(t, filepath.Join(prefix, "share", "zsh", "site-functions", "_dev-stack"), zsh.Script)
+
+	// Without a bash_completion.d, bash falls back to the user directory
+	bash := installTargetFor(pkgTypes.ShellTypeBash, env)
+	assert.Equal(t, filepath.Join(env.home, ".local", "share", "bash-completion", "completions", "dev-stack"), bash.Script)
+}
+
+func TestDetectShell(t *testing.T) {
+	shell, err := detectShell(nil, testEnv(t, "linux", map[string]string{"SHELL": "/usr/bin/zsh"}))
+	require.NoError(t, err)
+	assert.Equal(t, pkgTypes.ShellTypeZsh, shell)
+
+	shell, err = detectShell(nil, testEnv(t, "linux", map[string]string{"SHELL": "/opt/microsoft/powershell/7/pwsh"}))
+	require.NoError(t, err)
+	assert.Equal(t, pkgTypes.ShellTypePowerShell, shell)
+
+	shell, err = detectShell(nil, testEnv(t, "windows", nil))
+	require.NoError(t, err)
+	assert.Equal(t, pkgTypes.ShellTypePowerShell, shell)
+
+	shell, err = detectShell([]string{"fish"}, testEnv(t, "linux", map[string]string{"SHELL": "/bin/bash"}))
+	require.NoError(t, err)
+	assert.Equal(t, pkgTypes.ShellTypeFish, shell)
+
+	_, err = detectShell(nil, testEnv(t, "linux", map[string]string{"SHELL": "/bin/tcsh"}))
+	assert.Error(t, err)
+}
+
+func TestInstall(t *testing.T) {
+	env := testEnv(t, "linux", map[string]string{"SHELL": "/bin/zsh"})
+	rcFile := filepath.Join(env.home, ".zshrc")
+	require.NoError(t, os.WriteFile(rcFile, []byte("export EDITOR=vim"), 0600))
+
+	require.NoError(t, install(t, env))
+	script, err := os.ReadFile(filepath.Join(env.home, ".zsh", "completions", "_dev-stack"))
+	require.NoError(t, err)
+	assert.Contains(t, string(script), "#compdef dev-stack")
+
+	first, err := os.ReadFile(rcFile)
+	require.NoError(t, err)
+	assert.Contains(t, string(first), "export EDITOR=vim\n\n"+blockStart+"\n")
+	assert.Contains(t, string(first), "autoload -Uz compinit && compinit\n"+blockEnd+"\n")
+
+	// Installing again leaves a single block
+	require.NoError(t, install(t, env))
+	second, err := os.ReadFile(rcFile)
+	require.NoError(t, err)
+	assert.Equal(t, string(first), string(second))
+
+	info, err := os.Stat(rcFile)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm(), "the mode of the startup file is kept")
+
+	require.NoError(t, install(t, env, "--uninstall"))
+	assert.NoFileExists(t, filepath.Join(env.home, ".zsh", "completions", "_dev-stack"))
+	after, err := os.ReadFile(rcFile)
+	require.NoError(t, err)
+	assert.Equal(t, "export EDITOR=vim\n", string(after))
+}
+
+func TestInstall_Fish(t *testing.T) {
+	env := testEnv(t, "linux", nil)
+
+	require.NoError(t, install(t, env, "fish"))
+	assert.FileExists(t, filepath.Join(env.home, ".config", "fish", "completions", "dev-stack.fish"))
+	assert.NoFileExists(t, filepath.Join(env.home, ".config", "fish", "config.fish"))
+
+	require.NoError(t, install(t, env, "fish", "--uninstall"))
+	assert.NoFileExists(t, filepath.Join(env.home, ".config", "fish", "completions", "dev-stack.fish"))
+
+	// Nothing left to remove isn't an error
+	require.NoError(t, install(t, env, "fish", "--uninstall"))
+}
+
+func TestUpsertBlock(t *testing.T) {
+	content := "alias ll='ls -l'\n\n" + blockStart + "\nold\n" + blockEnd + "\nexport PATH\n"
+
+	updated := upsertBlock(content, []string{"new"})
+	assert.Equal(t, "alias ll='ls -l'\n\n"+blockStart+"\nnew\n"+blockEnd+"\nexport PATH\n", updated)
+	assert.Equal(t, "alias ll='ls -l'\n\nexport PATH\n", removeBlock(updated))
+
+	assert.Equal(t, blockStart+"\nnew\n"+blockEnd+"\n", upsertBlock("", []string{"new"}))
+	assert.Equal(t, "", removeBlock(upsertBlock("", []string{"new"})))
+}
+
+func TestInstallValidateArgs(t *testing.T) {
+	handler := NewInstallHandler()
+
+	assert.NoError(t, handler.ValidateArgs(nil))
+	assert.NoError(t, handler.ValidateArgs([]string{"bash"}))
+	assert.Error(t, handler.ValidateArgs([]string{"tcsh"}))
+	assert.Error(t, handler.ValidateArgs([]string{"bash", "zsh"}))
+}
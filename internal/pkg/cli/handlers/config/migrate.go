@@ -0,0 +1,156 @@
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/pmezard/go-difflib/difflib"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+
+	"github.com/isaacgarza/dev-stack/internal/pkg/cli/handlers/core"
+	"github.com/isaacgarza/dev-stack/internal/pkg/cli/handlers/utils"
+	cliTypes "github.com/isaacgarza/dev-stack/internal/pkg/cli/types"
+	pkgConfig "github.com/isaacgarza/dev-stack/internal/pkg/config"
+	"github.com/isaacgarza/dev-stack/internal/pkg/constants"
+	"github.com/isaacgarza/dev-stack/internal/pkg/ui"
+	pkgUtils "github.com/isaacgarza/dev-stack/internal/pkg/utils"
+)
+
+// backupTimeFormat stamps the name of configuration backups
+const backupTimeFormat = "20060102-150405"
+
+// MigrateHandler handles the config migrate command
+type MigrateHandler struct{}
+
+// NewMigrateHandler creates a new config migrate handler
+func NewMigrateHandler() *MigrateHandler {
+	return &MigrateHandler{}
+}
+
+// migrateResult is the outcome of a migration, as printed with --json
+type migrateResult struct {
+	Path    string          `json:"path"`
+	From    int             `json:"from"`
+	To      int             `json:"to"`
+	Changes []pkgConfig.Fix `json:"changes"`
+	Diff    string          `json:"diff,omitempty"`
+	Backup  string          `json:"backup,omitempty"`
+	Written bool            `json:"written"`
+}
+
+// Handle executes the config migrate command
+func (h *MigrateHandler) Handle(ctx context.Context, cmd *cobra.Command, args []string, base *cliTypes.BaseCommand) error {
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+	flags := utils.GetCIFlags(cmd)
+
+	path := filepath.Join(constants.DevStackDir, constants.ConfigFileName)
+	if !pkgUtils.FileExists(path) {
+		return errors.New(constants.ErrNotInitialized)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	content, report, err := pkgConfig.MigrateProjectConfig(data)
+	if err != nil {
+		return fmt.Errorf("failed to migrate %s: %w", path, err)
+	}
+	result := &migrateResult{Path: path, From: report.From, To: report.To, Changes: report.Changes}
+	if result.Changes == nil {
+		result.Changes = []pkgConfig.Fix{}
+	}
+
+	if len(report.Changes) > 0 {
+		// The migrated file must still load before it replaces the original
+		var cfg core.ProjectConfig
+		if err := yaml.Unmarshal(content, &cfg); err != nil {
+			return fmt.Errorf("the migrated configuration does not parse: %w", err)
+		}
+
+		if result.Diff, err = difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+			A:        difflib.SplitLines(string(data)),
+			B:        difflib.SplitLines(string(content)),
+			FromFile: path,
+			ToFile:   path + " (migrated)",
+			Context:  3,
+		}); err != nil {
+			return fmt.Errorf("failed to diff the migration: %w", err)
+		}
+
+		if !dryRun {
+			if result.Backup, err = writeMigration(path, data, content, report.From); err != nil {
+				return err
+			}
+			result.Written = true
+		}
+	}
+
+	if flags.JSON {
+		encoder := json.NewEncoder(cmd.OutOrStdout())
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(result)
+	}
+	printMigration(result, dryRun)
+	return nil
+}
+
+// ValidateArgs validates the command arguments
+func (h *MigrateHandler) ValidateArgs(args []string) error {
+	if len(args) > 0 {
+		return fmt.Errorf("config migrate takes no arguments")
+	}
+	return nil
+}
+
+// GetRequiredFlags returns required flags for this command
+func (h *MigrateHandler) GetRequiredFlags() []string {
+	return []string{}
+}
+
+// writeMigration backs up the original configuration next to it, named
+// after its schema version, then writes the migrated one in its place. It
+// returns the path of the backup.
+func writeMigration(path string, original, migrated []byte, version int) (string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	backup := fmt.Sprintf("%s.v%d-%s.bak", path, version, time.Now().Format(backupTimeFormat))
+	if err := os.WriteFile(backup, original, info.Mode().Perm()); err != nil {
+		return "", fmt.Errorf("failed to back up %s: %w", path, err)
+	}
+	if err := os.WriteFile(path, migrated, info.Mode().Perm()); err != nil {
+		return "", fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return backup, nil
+}
+
+// printMigration prints the changes of a migration
+func printMigration(result *migrateResult, dryRun bool) {
+	if len(result.Changes) == 0 {
+		ui.Success("%s is already at schema version %d", result.Path, result.To)
+		return
+	}
+
+	fmt.Printf("🔧 Migrating %s from schema version %d to %d:\n", result.Path, result.From, result.To)
+	for _, change := range result.Changes {
+		fmt.Printf("  - %s: %s\n", change.Field, change.Message)
+	}
+
+	if dryRun {
+		fmt.Println()
+		fmt.Print(result.Diff)
+		fmt.Println()
+		ui.Info("Dry run: nothing was written; run without --dry-run to migrate")
+		return
+	}
+	ui.Success("Backed up the original to %s", result.Backup)
+	ui.Success("Wrote %s", result.Path)
+}
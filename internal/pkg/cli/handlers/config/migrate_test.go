@@ -0,0 +1,38 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteMigration(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "dev-stack-config.yml")
+	require.NoError(t, os.WriteFile(path, []byte("services: [redis]\n"), 0600))
+
+	backup, err := writeMigration(path, []byte("services: [redis]\n"), []byte("schema_version: 2\n"), 1)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(filepath.Base(backup), "dev-stack-config.yml.v1-"), backup)
+	assert.True(t, strings.HasSuffix(backup, ".bak"), backup)
+
+	data, err := os.ReadFile(backup)
+	require.NoError(t, err)
+	assert.Equal(t, "services: [redis]\n", string(data))
+	data, err = os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "schema_version: 2\n", string(data))
+
+	info, err := os.Stat(backup)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+}
+
+func TestMigrateHandler_ValidateArgs(t *testing.T) {
+	handler := NewMigrateHandler()
+	assert.NoError(t, handler.ValidateArgs(nil))
+	assert.Error(t, handler.ValidateArgs([]string{"extra"}))
+}
@@ -0,0 +1,394 @@
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+
+	"github.com/isaacgarza/dev-stack/internal/pkg/cli/handlers/core"
+	"github.com/isaacgarza/dev-stack/internal/pkg/cli/handlers/utils"
+	cliTypes "github.com/isaacgarza/dev-stack/internal/pkg/cli/types"
+	pkgConfig "github.com/isaacgarza/dev-stack/internal/pkg/config"
+	"github.com/isaacgarza/dev-stack/internal/pkg/constants"
+	"github.com/isaacgarza/dev-stack/internal/pkg/ui"
+	pkgUtils "github.com/isaacgarza/dev-stack/internal/pkg/utils"
+)
+
+// flagGlobal selects the user configuration instead of the project's
+const flagGlobal = "global"
+
+// Output formats of config get
+const (
+	formatText = "text"
+	formatYAML = "yaml"
+	formatJSON = "json"
+)
+
+// GetHandler handles the config get command
+type GetHandler struct{}
+
+// NewGetHandler creates a new config get handler
+func NewGetHandler() *GetHandler {
+	return &GetHandler{}
+}
+
+// Handle executes the config get command
+func (h *GetHandler) Handle(ctx context.Context, cmd *cobra.Command, args []string, base *cliTypes.BaseCommand) error {
+	format, _ := cmd.Flags().GetString("format")
+	if utils.GetCIFlags(cmd).JSON {
+		format = formatJSON
+	}
+
+	if isGlobal(cmd) {
+		user, _, err := loadGlobal()
+		if err != nil {
+			return err
+		}
+		setting, err := user.Get(args[0])
+		if err != nil {
+			return err
+		}
+		if format == formatJSON {
+			return writeJSON(cmd.OutOrStdout(), setting)
+		}
+		_, _ = fmt.Fprintln(cmd.OutOrStdout(), setting.Value)
+		return nil
+	}
+
+	path, data, err := readProjectConfig()
+	if err != nil {
+		return err
+	}
+	keys, err := pkgConfig.ParseConfigPath(args[0])
+	if err != nil {
+		return err
+	}
+	node, err := pkgConfig.ConfigValue(data, keys)
+	if err != nil {
+		return err
+	}
+	if node == nil {
+		return fmt.Errorf("%s is not set in %s", args[0], path)
+	}
+	return writeNode(cmd.OutOrStdout(), node, format)
+}
+
+// ValidateArgs validates the command arguments
+func (h *GetHandler) ValidateArgs(args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("config get takes exactly one key")
+	}
+	return nil
+}
+
+// GetRequiredFlags returns required flags for this command
+func (h *GetHandler) GetRequiredFlags() []string {
+	return []string{}
+}
+
+// SetHandler handles the config set command
+type SetHandler struct{}
+
+// NewSetHandler creates a new config set handler
+func NewSetHandler() *SetHandler {
+	return &SetHandler{}
+}
+
+// Handle executes the config set command
+func (h *SetHandler) Handle(ctx context.Context, cmd *cobra.Command, args []string, base *cliTypes.BaseCommand) error {
+	key, value := args[0], args[1]
+
+	if isGlobal(cmd) {
+		user, path, err := loadGlobal()
+		if err != nil {
+			return err
+		}
+		if err := user.Set(key, value); err != nil {
+			return err
+		}
+		if err := user.Save(path); err != nil {
+			return err
+		}
+
+		if value == "" {
+			ui.Success("Cleared %s in %s", key, path)
+		} else {
+			ui.Success("Set %s to %s in %s", key, value, path)
+		}
+		if setting, err := user.Get(key); err == nil && setting.Source != pkgConfig.SourceGlobal && value != "" {
+			ui.Warning("%s is overridden by %s, which is set to %s", key, setting.Source, setting.Value)
+		}
+		return nil
+	}
+
+	keys, err := pkgConfig.ParseConfigPath(key)
+	if err != nil {
+		return err
+	}
+	node, err := parseValue(keys, value)
+	if err != nil {
+		return fmt.Errorf("invalid value for %s: %w", key, err)
+	}
+	path, err := editProjectConfig(ctx, func(data []byte) ([]byte, error) {
+		return pkgConfig.SetConfigValue(data, keys, node)
+	})
+	if err != nil {
+		return err
+	}
+	ui.Success("Set %s to %s in %s", key, value, path)
+	return nil
+}
+
+// ValidateArgs validates the command arguments
+func (h *SetHandler) ValidateArgs(args []string) error {
+	if len(args) != 2 {
+		return fmt.Errorf("config set takes a key and a value")
+	}
+	return nil
+}
+
+// GetRequiredFlags returns required flags for this command
+func (h *SetHandler) GetRequiredFlags() []string {
+	return []string{}
+}
+
+// UnsetHandler handles the config unset command
+type UnsetHandler struct{}
+
+// NewUnsetHandler creates a new config unset handler
+func NewUnsetHandler() *UnsetHandler {
+	return &UnsetHandler{}
+}
+
+// Handle executes the config unset command
+func (h *UnsetHandler) Handle(ctx context.Context, cmd *cobra.Command, args []string, base *cliTypes.BaseCommand) error {
+	key := args[0]
+
+	if isGlobal(cmd) {
+		user, path, err := loadGlobal()
+		if err != nil {
+			return err
+		}
+		if err := user.Set(key, ""); err != nil {
+			return err
+		}
+		if err := user.Save(path); err != nil {
+			return err
+		}
+		ui.Success("Cleared %s in %s", key, path)
+		return nil
+	}
+
+	keys, err := pkgConfig.ParseConfigPath(key)
+	if err != nil {
+		return err
+	}
+	path, err := editProjectConfig(ctx, func(data []byte) ([]byte, error) {
+		return pkgConfig.UnsetConfigValue(data, keys)
+	})
+	if err != nil {
+		return err
+	}
+	ui.Success("Removed %s from %s", key, path)
+	return nil
+}
+
+// ValidateArgs validates the command arguments
+func (h *UnsetHandler) ValidateArgs(args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("config unset takes exactly one key")
+	}
+	return nil
+}
+
+// GetRequiredFlags returns required flags for this command
+func (h *UnsetHandler) GetRequiredFlags() []string {
+	return []string{}
+}
+
+// ListHandler handles the config list command
+type ListHandler struct{}
+
+// NewListHandler creates a new config list handler
+func NewListHandler() *ListHandler {
+	return &ListHandler{}
+}
+
+// Handle executes the config list command
+func (h *ListHandler) Handle(ctx context.Context, cmd *cobra.Command, args []string, base *cliTypes.BaseCommand) error {
+	jsonOutput := utils.GetCIFlags(cmd).JSON
+
+	if isGlobal(cmd) {
+		user, path, err := loadGlobal()
+		if err != nil {
+			return err
+		}
+		settings := user.Settings()
+		if jsonOutput {
+			return writeJSON(cmd.OutOrStdout(), settings)
+		}
+		ui.Muted("User configuration: %s", path)
+		return writeSettings(cmd.OutOrStdout(), settings)
+	}
+
+	_, data, err := readProjectConfig()
+	if err != nil {
+		return err
+	}
+	entries, err := pkgConfig.ListConfigValues(data)
+	if err != nil {
+		return err
+	}
+	if entries == nil {
+		entries = []pkgConfig.ConfigEntry{}
+	}
+	if jsonOutput {
+		return writeJSON(cmd.OutOrStdout(), entries)
+	}
+	return writeEntries(cmd.OutOrStdout(), entries)
+}
+
+// ValidateArgs validates the command arguments
+func (h *ListHandler) ValidateArgs(args []string) error {
+	if len(args) > 0 {
+		return fmt.Errorf("config list takes no arguments")
+	}
+	return nil
+}
+
+// GetRequiredFlags returns required flags for this command
+func (h *ListHandler) GetRequiredFlags() []string {
+	return []string{}
+}
+
+// isGlobal reports whether a command works with the user configuration
+func isGlobal(cmd *cobra.Command) bool {
+	global, _ := cmd.Flags().GetBool(flagGlobal)
+	return global
+}
+
+// loadGlobal loads the user configuration, returning it with its path
+func loadGlobal() (*pkgConfig.UserConfig, string, error) {
+	path, err := pkgConfig.UserConfigPath()
+	if err != nil {
+		return nil, "", err
+	}
+	user, err := pkgConfig.LoadUserConfigFile(path)
+	if err != nil {
+		return nil, "", err
+	}
+	return user, path, nil
+}
+
+// readProjectConfig reads the project configuration, returning its path
+// and content
+func readProjectConfig() (string, []byte, error) {
+	path := filepath.Join(constants.DevStackDir, constants.ConfigFileName)
+	if !pkgUtils.FileExists(path) {
+		return "", nil, errors.New(constants.ErrNotInitialized)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	return path, data, nil
+}
+
+// editProjectConfig writes the project configuration as edited, then
+// regenerates the compose files from it. An edit that leaves a
+// configuration dev-stack can't load is rolled back.
+func editProjectConfig(ctx context.Context, edit func(data []byte) ([]byte, error)) (string, error) {
+	path, data, err := readProjectConfig()
+	if err != nil {
+		return "", err
+	}
+	content, err := edit(data)
+	if err != nil {
+		return "", err
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	if err := os.WriteFile(path, content, info.Mode().Perm()); err != nil {
+		return "", fmt.Errorf("failed to write %s: %w", path, err)
+	}
+
+	cfg, err := core.LoadProjectConfig(path)
+	if err != nil {
+		if restoreErr := os.WriteFile(path, data, info.Mode().Perm()); restoreErr != nil {
+			return "", fmt.Errorf("failed to restore %s after an invalid change: %w", path, restoreErr)
+		}
+		return "", fmt.Errorf("the change was not applied: %w", err)
+	}
+	if err := core.RegenerateCompose(ctx, cfg); err != nil {
+		return "", fmt.Errorf("failed to regenerate compose files: %w", err)
+	}
+	return path, nil
+}
+
+// writeNode prints a configuration value: plain values as they are and
+// anything else as YAML in text format, or as YAML or JSON
+func writeNode(out io.Writer, node *yaml.Node, format string) error {
+	switch format {
+	case formatJSON:
+		var value any
+		if err := node.Decode(&value); err != nil {
+			return fmt.Errorf("failed to decode value: %w", err)
+		}
+		return writeJSON(out, value)
+	case formatText, formatYAML:
+		if format == formatText && node.Kind == yaml.ScalarNode {
+			_, _ = fmt.Fprintln(out, node.Value)
+			return nil
+		}
+		encoder := yaml.NewEncoder(out)
+		encoder.SetIndent(2)
+		if err := encoder.Encode(node); err != nil {
+			return fmt.Errorf("failed to encode value: %w", err)
+		}
+		return encoder.Close()
+	}
+	return fmt.Errorf("invalid format %q: must be %s, %s or %s", format, formatText, formatYAML, formatJSON)
+}
+
+// writeSettings prints settings as a table with the source of each value
+func writeSettings(out io.Writer, settings []pkgConfig.UserSetting) error {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "KEY\tVALUE\tSOURCE")
+	for _, setting := range settings {
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\n", setting.Key, orDash(setting.Value), setting.Source)
+	}
+	return w.Flush()
+}
+
+// writeEntries prints the settings of the project configuration as a table
+func writeEntries(out io.Writer, entries []pkgConfig.ConfigEntry) error {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "KEY\tVALUE")
+	for _, entry := range entries {
+		_, _ = fmt.Fprintf(w, "%s\t%s\n", entry.Path, orDash(entry.Value))
+	}
+	return w.Flush()
+}
+
+func orDash(value string) string {
+	if value == "" {
+		return "-"
+	}
+	return value
+}
+
+func writeJSON(out io.Writer, value any) error {
+	encoder := json.NewEncoder(out)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(value)
+}
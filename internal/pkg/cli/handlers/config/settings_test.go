@@ -0,0 +1,110 @@
+package config
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+
+	pkgConfig "github.com/isaacgarza/dev-stack/internal/pkg/config"
+)
+
+func TestWriteSettings(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, writeSettings(&buf, []pkgConfig.UserSetting{
+		{Key: "registry_mirrors.docker.io", Value: "mirror.example", Source: pkgConfig.SourceGlobal},
+		{Key: "default_profile", Source: pkgConfig.SourceDefault},
+		{Key: "color", Value: "never", Source: "NO_COLOR"},
+	}))
+
+	assert.Equal(t, `KEY                         VALUE           SOURCE
+registry_mirrors.docker.io  mirror.example  global
+default_profile             -               default
+color                       never           NO_COLOR
+`, buf.String())
+}
+
+func TestWriteEntries(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, writeEntries(&buf, []pkgConfig.ConfigEntry{
+		{Path: "project.name", Value: "shop"},
+		{Path: "profiles.dev.services", Value: "[postgres, redis]"},
+		{Path: "overrides.redis"},
+	}))
+
+	assert.Equal(t, `KEY                    VALUE
+project.name           shop
+profiles.dev.services  [postgres, redis]
+overrides.redis        -
+`, buf.String())
+}
+
+func TestWriteNode(t *testing.T) {
+	var document yaml.Node
+	require.NoError(t, yaml.Unmarshal([]byte("services: [postgres, redis]\nname: shop\n"), &document))
+	services := document.Content[0].Content[1]
+	name := document.Content[0].Content[3]
+
+	var buf bytes.Buffer
+	require.NoError(t, writeNode(&buf, name, formatText))
+	assert.Equal(t, "shop\n", buf.String())
+
+	buf.Reset()
+	require.NoError(t, writeNode(&buf, services, formatText))
+	assert.Equal(t, "[postgres, redis]\n", buf.String())
+
+	buf.Reset()
+	require.NoError(t, writeNode(&buf, services, formatJSON))
+	assert.JSONEq(t, `["postgres", "redis"]`, buf.String())
+
+	assert.Error(t, writeNode(&buf, name, "xml"))
+}
+
+func TestParseValue(t *testing.T) {
+	tests := []struct {
+		name    string
+		path    string
+		value   string
+		kind    yaml.Kind
+		tag     string
+		wantErr string
+	}{
+		{name: "version stays a string", path: "services.postgres.version", value: "16", kind: yaml.ScalarNode, tag: "!!str"},
+		{name: "boolean", path: "advanced.env_files", value: "true", kind: yaml.ScalarNode, tag: "!!bool"},
+		{name: "invalid boolean", path: "profiles.ci.protected", value: "maybe", wantErr: "invalid boolean"},
+		{name: "number", path: "images.pull_concurrency", value: "4", kind: yaml.ScalarNode, tag: "!!int"},
+		{name: "invalid number", path: "images.pull_retries", value: "many", wantErr: "invalid number"},
+		{name: "comma-separated list", path: "profiles.dev.services", value: "postgres, redis", kind: yaml.SequenceNode, tag: "!!seq"},
+		{name: "flow list", path: "stack.enabled", value: "[postgres]", kind: yaml.SequenceNode, tag: "!!seq"},
+		{name: "outside the schema", path: "overrides.redis.ports", value: "[6379]", kind: yaml.SequenceNode, tag: "!!seq"},
+		{name: "number outside the schema", path: "overrides.redis.memory_mb", value: "256", kind: yaml.ScalarNode, tag: "!!int"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keys, err := pkgConfig.ParseConfigPath(tt.path)
+			require.NoError(t, err)
+			node, err := parseValue(keys, tt.value)
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.kind, node.Kind)
+			assert.Equal(t, tt.tag, node.ShortTag())
+		})
+	}
+}
+
+func TestSettingsHandlers_ValidateArgs(t *testing.T) {
+	assert.NoError(t, NewGetHandler().ValidateArgs([]string{"color"}))
+	assert.Error(t, NewGetHandler().ValidateArgs(nil))
+	assert.NoError(t, NewSetHandler().ValidateArgs([]string{"color", "never"}))
+	assert.Error(t, NewSetHandler().ValidateArgs([]string{"color"}))
+	assert.NoError(t, NewUnsetHandler().ValidateArgs([]string{"overrides.redis.ports"}))
+	assert.Error(t, NewUnsetHandler().ValidateArgs(nil))
+	assert.NoError(t, NewListHandler().ValidateArgs(nil))
+	assert.Error(t, NewListHandler().ValidateArgs([]string{"extra"}))
+}
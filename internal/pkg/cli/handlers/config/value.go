@@ -0,0 +1,144 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/isaacgarza/dev-stack/internal/pkg/cli/handlers/core"
+)
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// schemaType returns the Go type the project configuration decodes path
+// into, or nil when the path is outside the typed part of the schema
+func schemaType(path []string) reflect.Type {
+	t := reflect.TypeOf(core.ProjectConfig{})
+	for _, key := range path {
+		for t.Kind() == reflect.Pointer {
+			t = t.Elem()
+		}
+		switch t.Kind() {
+		case reflect.Struct:
+			field, ok := yamlField(t, key)
+			if !ok {
+				return nil
+			}
+			t = field
+		case reflect.Map, reflect.Slice:
+			t = t.Elem()
+		default:
+			return nil
+		}
+	}
+	return t
+}
+
+// yamlField returns the type of the field of struct type t with yaml key
+// key, looking into inlined structs
+func yamlField(t reflect.Type, key string) (reflect.Type, bool) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, options, _ := strings.Cut(field.Tag.Get("yaml"), ",")
+		if strings.Contains(options, "inline") && field.Type.Kind() == reflect.Struct {
+			if inner, ok := yamlField(field.Type, key); ok {
+				return inner, true
+			}
+			continue
+		}
+		if name == "" {
+			name = strings.ToLower(field.Name)
+		}
+		if name == key && field.IsExported() {
+			return field.Type, true
+		}
+	}
+	return nil, false
+}
+
+// parseValue turns the text of a value into a YAML node of the type the
+// schema has at path: strings stay strings, numbers and booleans are
+// checked, and lists of plain values take a comma-separated list or YAML
+// flow list. Values outside the schema, and mappings, are read as YAML.
+func parseValue(path []string, text string) (*yaml.Node, error) {
+	t := schemaType(path)
+	if t == nil {
+		return parseYAML(text)
+	}
+	return typedValue(t, text)
+}
+
+// typedValue returns text as a node of Go type t
+func typedValue(t reflect.Type, text string) (*yaml.Node, error) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == durationType {
+		if _, err := time.ParseDuration(text); err != nil {
+			return nil, fmt.Errorf("invalid duration %q, such as 30s or 2m", text)
+		}
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: text}, nil
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: text}, nil
+	case reflect.Bool:
+		value, err := strconv.ParseBool(text)
+		if err != nil {
+			return nil, fmt.Errorf("invalid boolean %q: must be true or false", text)
+		}
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!bool", Value: strconv.FormatBool(value)}, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if _, err := strconv.ParseInt(text, 10, 64); err != nil {
+			return nil, fmt.Errorf("invalid number %q: must be a whole number", text)
+		}
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!int", Value: text}, nil
+	case reflect.Float32, reflect.Float64:
+		if _, err := strconv.ParseFloat(text, 64); err != nil {
+			return nil, fmt.Errorf("invalid number %q", text)
+		}
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!float", Value: text}, nil
+	case reflect.Slice:
+		if !plainKind(t.Elem()) || strings.HasPrefix(strings.TrimSpace(text), "[") {
+			return parseYAML(text)
+		}
+		list := &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+		for _, item := range strings.Split(text, ",") {
+			node, err := typedValue(t.Elem(), strings.TrimSpace(item))
+			if err != nil {
+				return nil, err
+			}
+			list.Content = append(list.Content, node)
+		}
+		return list, nil
+	}
+	return parseYAML(text)
+}
+
+// plainKind reports whether values of type t are written as plain values
+func plainKind(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.String, reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+// parseYAML reads text as a YAML value
+func parseYAML(text string) (*yaml.Node, error) {
+	var document yaml.Node
+	if err := yaml.Unmarshal([]byte(text), &document); err != nil {
+		return nil, fmt.Errorf("invalid value %q: %w", text, err)
+	}
+	if len(document.Content) == 0 {
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: text}, nil
+	}
+	return document.Content[0], nil
+}
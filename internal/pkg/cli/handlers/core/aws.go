@@ -0,0 +1,183 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"slices"
+	"text/tabwriter"
+	"time"
+
+	"github.com/isaacgarza/dev-stack/internal/core/docker"
+	"github.com/isaacgarza/dev-stack/internal/core/localstack"
+	cliTypes "github.com/isaacgarza/dev-stack/internal/pkg/cli/types"
+	"github.com/isaacgarza/dev-stack/internal/pkg/constants"
+	"github.com/isaacgarza/dev-stack/internal/pkg/ui"
+	"github.com/spf13/cobra"
+)
+
+// AWSLsHandler handles the aws ls command
+type AWSLsHandler struct{}
+
+// NewAWSLsHandler creates a new aws ls handler
+func NewAWSLsHandler() *AWSLsHandler {
+	return &AWSLsHandler{}
+}
+
+// Handle executes the aws ls command
+func (h *AWSLsHandler) Handle(ctx context.Context, cmd *cobra.Command, args []string, base *cliTypes.BaseCommand) error {
+	cfg, _, err := loadImagesConfig()
+	if err != nil {
+		return err
+	}
+
+	dockerClient, err := newImagesDockerClient(base)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := dockerClient.Close(); err != nil {
+			base.Logger.Error("Failed to close Docker client", "error", err)
+		}
+	}()
+
+	resources, err := localstackProvisioner(dockerClient, cfg).Resources(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list LocalStack resources: %w", err)
+	}
+	return writeAWSResources(cmd.OutOrStdout(), awsResourceRows(cfg, resources))
+}
+
+// ValidateArgs validates the command arguments
+func (h *AWSLsHandler) ValidateArgs(args []string) error {
+	if len(args) > 0 {
+		return fmt.Errorf("aws ls takes no arguments")
+	}
+	return nil
+}
+
+// GetRequiredFlags returns required flags for this command
+func (h *AWSLsHandler) GetRequiredFlags() []string {
+	return []string{}
+}
+
+// provisionAWSResources creates the AWS resources the project declares once
+// LocalStack is ready, when any of serviceNames runs on it
+func provisionAWSResources(ctx context.Context, dockerClient *docker.Client, cfg *ProjectConfig, serviceNames []string, timeout time.Duration) error {
+	if cfg.AWS.IsZero() || !startsService(serviceNames, localstack.ServiceName) {
+		return nil
+	}
+	if err := waitForServices(ctx, dockerClient, cfg, []string{localstack.ServiceName}, timeout); err != nil {
+		return err
+	}
+
+	board := ui.NewProgressBoard()
+	steps, err := localstackProvisioner(dockerClient, cfg).Provision(ctx, cfg.AWS, board)
+	board.Stop()
+	if err != nil {
+		return fmt.Errorf("failed to provision AWS resources: %w", err)
+	}
+	if len(steps) > 0 {
+		ui.Success("Created %d AWS resource(s) in LocalStack", len(steps))
+	}
+	return nil
+}
+
+// localstackProvisioner returns a provisioner for the project's LocalStack
+func localstackProvisioner(dockerClient *docker.Client, cfg *ProjectConfig) *localstack.Provisioner {
+	return localstack.NewProvisioner(dockerClient.Containers(), serviceProject(cfg, localstack.ServiceName), cfg.AWS.Region)
+}
+
+// awsResourceRow is a line of aws ls
+type awsResourceRow struct {
+	kind, name, status string
+}
+
+// awsResourceRows lists the resources LocalStack holds, by kind, noting
+// whether the project declares them, followed by the declared resources it
+// doesn't hold
+func awsResourceRows(cfg *ProjectConfig, resources *localstack.Resources) []awsResourceRow {
+	var declaredBuckets, declaredQueues, declaredTables, declaredTopics []string
+	var declaredSubscriptions []localstack.Subscription
+	for _, bucket := range cfg.AWS.Buckets {
+		declaredBuckets = append(declaredBuckets, bucket.Name)
+	}
+	for _, queue := range cfg.AWS.Queues {
+		declaredQueues = append(declaredQueues, queue.Name)
+	}
+	for _, table := range cfg.AWS.Tables {
+		declaredTables = append(declaredTables, table.Name)
+	}
+	for _, topic := range cfg.AWS.Topics {
+		declaredTopics = append(declaredTopics, topic.Name)
+		for _, queue := range topic.Subscriptions {
+			declaredSubscriptions = append(declaredSubscriptions, localstack.Subscription{Topic: topic.Name, Queue: queue})
+		}
+	}
+
+	var rows []awsResourceRow
+	add := func(kind string, existing, declared []string) {
+		for _, resource := range declaredStatuses(existing, declared) {
+			rows = append(rows, awsResourceRow{kind, resource.name, resource.status})
+		}
+	}
+	add(localstack.KindBucket, resources.Buckets, declaredBuckets)
+	add(localstack.KindQueue, resources.Queues, declaredQueues)
+	add(localstack.KindTable, resources.Tables, declaredTables)
+	add(localstack.KindTopic, resources.Topics, declaredTopics)
+	add(localstack.KindSubscription, subscriptionNames(resources.Subscriptions), subscriptionNames(declaredSubscriptions))
+	return rows
+}
+
+// declaredResource is a resource with whether the project declares it
+type declaredResource struct {
+	name, status string
+}
+
+// Statuses of resources against the project's declarations
+const (
+	statusProvisioned = "provisioned"
+	statusMissing     = "missing"
+	statusUndeclared  = "undeclared"
+)
+
+// declaredStatuses marks the existing resources provisioned when declared
+// and undeclared otherwise, followed by the declared resources missing
+func declaredStatuses(existing, declared []string) []declaredResource {
+	var resources []declaredResource
+	for _, name := range existing {
+		status := statusUndeclared
+		if slices.Contains(declared, name) {
+			status = statusProvisioned
+		}
+		resources = append(resources, declaredResource{name, status})
+	}
+	for _, name := range declared {
+		if !slices.Contains(existing, name) {
+			resources = append(resources, declaredResource{name, statusMissing})
+		}
+	}
+	return resources
+}
+
+func subscriptionNames(subscriptions []localstack.Subscription) []string {
+	names := make([]string, 0, len(subscriptions))
+	for _, subscription := range subscriptions {
+		names = append(names, subscription.Name())
+	}
+	return names
+}
+
+// writeAWSResources prints the rows of aws ls as a table
+func writeAWSResources(out io.Writer, rows []awsResourceRow) error {
+	if len(rows) == 0 {
+		ui.Info("LocalStack holds no resources; declare them under aws in %s", constants.ConfigFileName)
+		return nil
+	}
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "TYPE\tNAME\tSTATUS")
+	for _, row := range rows {
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\n", row.kind, row.name, row.status)
+	}
+	return w.Flush()
+}
@@ -0,0 +1,382 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"slices"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/isaacgarza/dev-stack/internal/core/bench"
+	"github.com/isaacgarza/dev-stack/internal/core/docker"
+	"github.com/isaacgarza/dev-stack/internal/core/projectstate"
+	"github.com/isaacgarza/dev-stack/internal/core/readiness"
+	handlerUtils "github.com/isaacgarza/dev-stack/internal/pkg/cli/handlers/utils"
+	cliTypes "github.com/isaacgarza/dev-stack/internal/pkg/cli/types"
+	"github.com/isaacgarza/dev-stack/internal/pkg/compose"
+	"github.com/isaacgarza/dev-stack/internal/pkg/constants"
+	"github.com/isaacgarza/dev-stack/internal/pkg/types"
+	"github.com/isaacgarza/dev-stack/internal/pkg/ui"
+	"github.com/isaacgarza/dev-stack/internal/pkg/utils"
+	"github.com/spf13/cobra"
+)
+
+// benchDir keeps the reports of the project's startup benchmarks
+var benchDir = filepath.Join(constants.DevStackDir, constants.BenchDir)
+
+// BenchUpHandler handles the bench up command, timing how long each
+// service takes to come up over repeated downs and ups
+type BenchUpHandler struct{}
+
+// NewBenchUpHandler creates a new bench up handler
+func NewBenchUpHandler() *BenchUpHandler {
+	return &BenchUpHandler{}
+}
+
+// benchOptions controls a benchmark
+type benchOptions struct {
+	cold    bool
+	volumes bool
+	timeout time.Duration
+}
+
+// benchResult is the JSON output of a benchmark
+type benchResult struct {
+	Report      *types.BenchReport      `json:"report"`
+	Baseline    string                  `json:"baseline,omitempty"`
+	Regressions []types.BenchRegression `json:"regressions"`
+}
+
+// Handle executes the bench up command
+func (h *BenchUpHandler) Handle(ctx context.Context, cmd *cobra.Command, args []string, base *cliTypes.BaseCommand) error {
+	runs, _ := cmd.Flags().GetInt("runs")
+	cold, _ := cmd.Flags().GetBool("cold")
+	volumes, _ := cmd.Flags().GetBool("volumes")
+	timeoutValue, _ := cmd.Flags().GetString("wait-timeout")
+	baselinePath, _ := cmd.Flags().GetString("baseline")
+	threshold, _ := cmd.Flags().GetInt("threshold")
+	noSave, _ := cmd.Flags().GetBool("no-save")
+	failOnRegression, _ := cmd.Flags().GetBool("fail-on-regression")
+	jsonOutput := handlerUtils.GetCIFlags(cmd).JSON
+
+	if runs <= 0 {
+		return fmt.Errorf("invalid runs %d: must be at least 1", runs)
+	}
+	if threshold < 0 {
+		return fmt.Errorf("invalid threshold %d: must be a percentage of at least 0", threshold)
+	}
+	timeout, err := time.ParseDuration(timeoutValue)
+	if err != nil || timeout <= 0 {
+		return fmt.Errorf("invalid timeout %q: must be a positive duration", timeoutValue)
+	}
+	// Only the report goes to stdout in JSON mode
+	if jsonOutput {
+		ui.DefaultOutput.Quiet = true
+	}
+
+	configPath := filepath.Join(constants.DevStackDir, constants.ConfigFileName)
+	if !utils.FileExists(configPath) {
+		return errors.New(constants.ErrNotInitialized)
+	}
+	cfg, err := LoadProjectConfig(configPath)
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+	for _, serviceName := range args {
+		if !slices.Contains(cfg.Stack.Enabled, serviceName) {
+			return &types.ServiceNotFoundError{Service: serviceName, NotEnabled: true}
+		}
+	}
+
+	// Compare with the given report, or the last one saved
+	var baseline *types.BenchReport
+	if baselinePath != "" {
+		if baseline, err = bench.Load(baselinePath); err != nil {
+			return err
+		}
+	} else if baseline, baselinePath, err = bench.Latest(benchDir); err != nil {
+		return err
+	}
+
+	activeProfile := ActiveProfile(cmd)
+	serviceNames, err := selectedServices(cfg, args, activeProfile)
+	if err != nil {
+		return err
+	}
+	order, err := benchOrder(cfg, serviceNames)
+	if err != nil {
+		return err
+	}
+	if len(order) == 0 {
+		return errors.New("no services to benchmark")
+	}
+
+	// Every run starts from scratch, so the stack goes down first
+	if volumes {
+		if err := ConfirmProtected(cmd, cfg, "remove service volumes"); err != nil {
+			return err
+		}
+	}
+	lock, err := projectstate.Acquire(projectstate.Path(), constants.CmdNameBench)
+	if err != nil {
+		return err
+	}
+	defer func() { releaseProjectLock(lock, base, nil) }()
+
+	logger := base.Logger.(loggerAdapter)
+	dockerClient, err := docker.Shared(logger.SlogLogger())
+	if err != nil {
+		return fmt.Errorf("failed to create Docker client: %w", err)
+	}
+	defer func() {
+		if err := dockerClient.Close(); err != nil {
+			base.Logger.Error("Failed to close Docker client", "error", err)
+		}
+	}()
+
+	options := benchOptions{cold: cold, volumes: volumes, timeout: timeout}
+	benchRuns := make([]bench.Run, 0, runs)
+	for i := range runs {
+		endGroup := ui.Group("Run %d of %d", i+1, runs)
+		run, err := benchRun(ctx, dockerClient, cfg, order, options)
+		endGroup()
+		if err != nil {
+			return fmt.Errorf("run %d: %w", i+1, err)
+		}
+		ui.Success("Run %d: stack up in %s", i+1, run.Total.Round(time.Millisecond))
+		benchRuns = append(benchRuns, run)
+	}
+
+	report := bench.Summarize(benchRuns)
+	report.Project = cfg.Project.Name
+	report.Profile = activeProfile
+	report.CreatedAt = time.Now().UTC()
+	report.Cold = cold
+
+	var regressions []types.BenchRegression
+	if baseline != nil {
+		regressions = bench.Compare(baseline, report, float64(threshold)/100)
+	}
+	if !noSave {
+		path, err := bench.Save(benchDir, report)
+		if err != nil {
+			return err
+		}
+		if !jsonOutput {
+			ui.Muted("Saved report to %s", path)
+		}
+	}
+
+	if jsonOutput {
+		if regressions == nil {
+			regressions = []types.BenchRegression{}
+		}
+		encoder := json.NewEncoder(cmd.OutOrStdout())
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(benchResult{Report: report, Baseline: baselinePath, Regressions: regressions}); err != nil {
+			return err
+		}
+	} else if err := writeBenchReport(cmd.OutOrStdout(), report, baseline, baselinePath, regressions); err != nil {
+		return err
+	}
+
+	if failOnRegression && len(regressions) > 0 {
+		return fmt.Errorf("%d regression(s) against %s", len(regressions), baselinePath)
+	}
+	return nil
+}
+
+// benchOrder returns serviceNames and the services they depend on in
+// startup order, leaving out the shared services, which run outside the
+// project
+func benchOrder(cfg *ProjectConfig, serviceNames []string) ([]string, error) {
+	dependencies, err := compose.ServiceDependencies(docker.ComposeFiles()...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read service dependencies: %w", err)
+	}
+	waves, err := compose.StartupWaves(serviceNames, dependencies, true)
+	if err != nil {
+		return nil, err
+	}
+	shared := sharedServices(cfg, serviceNames)
+	return slices.DeleteFunc(slices.Concat(waves...), func(name string) bool {
+		return slices.Contains(shared, name)
+	}), nil
+}
+
+// benchRun takes the services down, or the whole stack with its volumes
+// when options.volumes is set, then brings the services up one at a time
+// in startup order, timing each phase of each
+func benchRun(ctx context.Context, client *docker.Client, cfg *ProjectConfig, order []string, options benchOptions) (bench.Run, error) {
+	containers := client.Containers()
+	down := order
+	if options.volumes {
+		down = nil
+	}
+	if err := containers.Stop(ctx, cfg.Project.Name, down, types.StopOptions{Remove: true, RemoveVolumes: options.volumes}); err != nil {
+		return bench.Run{}, fmt.Errorf("failed to take the stack down: %w", err)
+	}
+	if options.cold {
+		images := collectServiceImages(order, cfg.Services.Versions(), cfg.Images.RegistryMirrors)
+		if err := client.Images().RemoveImages(ctx, images); err != nil {
+			return bench.Run{}, err
+		}
+	}
+
+	targets, err := readinessTargets(cfg, order, options.timeout)
+	if err != nil {
+		return bench.Run{}, err
+	}
+	checker := readiness.NewChecker(containers, cfg.Project.Name)
+
+	run := bench.Run{Services: make([]types.BenchTiming, 0, len(order))}
+	started := time.Now()
+	for i, name := range order {
+		timing := types.BenchTiming{Service: name}
+		board := ui.NewProgressBoard()
+		err := benchPhases(ctx, client, cfg, &timing, board, func(ctx context.Context) error {
+			result := checker.WaitFor(ctx, targets[i])
+			if !result.Ready {
+				return fmt.Errorf("not ready: %w", result.Err)
+			}
+			return nil
+		})
+		board.Stop()
+		if err != nil {
+			return bench.Run{}, fmt.Errorf("%s: %w", name, err)
+		}
+		run.Services = append(run.Services, timing)
+	}
+	run.Total = time.Since(started)
+	return run, nil
+}
+
+// benchPhases times pulling, creating, starting and waiting for the
+// readiness of a service, reporting each phase on progress
+func benchPhases(ctx context.Context, client *docker.Client, cfg *ProjectConfig, timing *types.BenchTiming, progress types.ProgressReporter, ready func(context.Context) error) error {
+	name := timing.Service
+	phases := map[string]func(context.Context) error{
+		types.BenchPhasePull: func(ctx context.Context) error {
+			if cfg.PullPolicy() == constants.PullPolicyNever {
+				return nil
+			}
+			images := collectServiceImages([]string{name}, cfg.Services.Versions(), cfg.Images.RegistryMirrors)
+			summary, err := client.Images().Pull(ctx, images, types.PullOptions{
+				Concurrency: cfg.Images.PullConcurrency,
+				Retries:     cfg.Images.PullRetries,
+				Mirrors:     cfg.Images.Mirrors,
+			})
+			if err != nil {
+				return err
+			}
+			for _, result := range summary.Results {
+				if result.Error != "" {
+					return fmt.Errorf("failed to pull %s: %s", result.Image, result.Error)
+				}
+			}
+			return nil
+		},
+		types.BenchPhaseCreate: func(ctx context.Context) error {
+			return client.Containers().Create(ctx, cfg.Project.Name, []string{name})
+		},
+		types.BenchPhaseStart: func(ctx context.Context) error {
+			return client.Containers().Start(ctx, cfg.Project.Name, []string{name}, types.StartOptions{NoDeps: true, Detach: true})
+		},
+		types.BenchPhaseHealthy: ready,
+	}
+
+	for _, phase := range types.BenchPhases {
+		progress.Update(name, phase, 0, 0)
+		began := time.Now()
+		if err := phases[phase](ctx); err != nil {
+			progress.Fail(name, fmt.Sprintf("%s failed", phase))
+			return fmt.Errorf("%s: %w", phase, err)
+		}
+		timing.SetPhase(phase, time.Since(began))
+	}
+	progress.Done(name, "up in "+timing.Phase(types.BenchPhaseTotal).Round(time.Millisecond).String())
+	return nil
+}
+
+// writeBenchReport prints the median timings of each service, next to the
+// baseline's when there is one, and the regressions
+func writeBenchReport(w io.Writer, report, baseline *types.BenchReport, baselinePath string, regressions []types.BenchRegression) error {
+	ui.Header("Startup benchmark (median of %d runs)", report.Runs)
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "SERVICE\tPULL\tCREATE\tSTART\tHEALTHY\tTOTAL")
+	for _, timing := range report.Services {
+		var before *types.BenchTiming
+		if baseline != nil {
+			if previous, ok := baseline.Service(timing.Service); ok {
+				before = &previous
+			}
+		}
+		cells := []string{timing.Service}
+		for _, phase := range append(slices.Clone(types.BenchPhases), types.BenchPhaseTotal) {
+			cell := formatBenchDuration(timing.Phase(phase))
+			if before != nil {
+				cell += " (" + formatBenchDelta(timing.Phase(phase)-before.Phase(phase)) + ")"
+			}
+			cells = append(cells, cell)
+		}
+		_, _ = fmt.Fprintln(tw, strings.Join(cells, "\t"))
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	total := "Stack up in " + formatBenchDuration(report.Total)
+	if baseline != nil {
+		total += " (" + formatBenchDelta(report.Total-baseline.Total) + ")"
+	}
+	ui.Info("%s", total)
+
+	switch {
+	case baseline == nil:
+		ui.Muted("No earlier report to compare with; the next run compares with this one")
+	case len(regressions) == 0:
+		ui.Success("No regressions against %s", baselinePath)
+	default:
+		ui.Warning("Regressions against %s:", baselinePath)
+		for _, regression := range regressions {
+			subject := "stack"
+			if regression.Service != "" {
+				subject = regression.Service
+			}
+			ui.Warning("  %s %s: %s -> %s", subject, regression.Phase, formatBenchDuration(regression.Baseline), formatBenchDuration(regression.Current))
+		}
+	}
+	return nil
+}
+
+// formatBenchDuration rounds a duration to what a benchmark can tell apart
+func formatBenchDuration(d time.Duration) string {
+	if d < time.Second {
+		return d.Round(time.Millisecond).String()
+	}
+	return d.Round(10 * time.Millisecond).String()
+}
+
+// formatBenchDelta shows a change from the baseline with its sign
+func formatBenchDelta(d time.Duration) string {
+	if d < 0 {
+		return "-" + formatBenchDuration(-d)
+	}
+	return "+" + formatBenchDuration(d)
+}
+
+// ValidateArgs validates the command arguments
+func (h *BenchUpHandler) ValidateArgs(args []string) error {
+	return nil
+}
+
+// GetRequiredFlags returns required flags for this command
+func (h *BenchUpHandler) GetRequiredFlags() []string {
+	return []string{}
+}
@@ -0,0 +1,113 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/isaacgarza/dev-stack/internal/pkg/cli/handlers/utils"
+	cliTypes "github.com/isaacgarza/dev-stack/internal/pkg/cli/types"
+	"github.com/isaacgarza/dev-stack/internal/pkg/types"
+	"github.com/isaacgarza/dev-stack/internal/pkg/ui"
+	"github.com/spf13/cobra"
+)
+
+// BuildHandler handles the build command
+type BuildHandler struct{}
+
+// NewBuildHandler creates a new build handler
+func NewBuildHandler() *BuildHandler {
+	return &BuildHandler{}
+}
+
+// Handle executes the build command
+func (h *BuildHandler) Handle(ctx context.Context, cmd *cobra.Command, args []string, base *cliTypes.BaseCommand) error {
+	noCache, _ := cmd.Flags().GetBool("no-cache")
+	pull, _ := cmd.Flags().GetBool("pull")
+
+	cfg, _, err := loadImagesConfig()
+	if err != nil {
+		return err
+	}
+	activeProfile := ActiveProfile(cmd)
+	serviceNames, err := selectedServices(cfg, args, activeProfile)
+	if err != nil {
+		return err
+	}
+	containers, err := buildContainers(serviceNames, len(args) > 0)
+	if err != nil {
+		return err
+	}
+	if len(containers) == 0 {
+		ui.Info("No services build an image")
+		return nil
+	}
+
+	dockerClient, err := newImagesDockerClient(base)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := dockerClient.Close(); err != nil {
+			base.Logger.Error("Failed to close Docker client", "error", err)
+		}
+	}()
+
+	options := types.BuildOptions{NoCache: noCache, Pull: pull}
+	if activeProfile != "" {
+		options.Profiles = []string{activeProfile}
+	}
+
+	ui.Info("Building %d image(s)...", len(containers))
+	board := ui.NewProgressBoard()
+	options.Progress = board
+	err = dockerClient.Containers().Build(ctx, cfg.Project.Name, containers, options)
+	board.Stop()
+	if err != nil {
+		return err
+	}
+	ui.Success("Built %d image(s)", len(containers))
+	return nil
+}
+
+// ValidateArgs validates the command arguments
+func (h *BuildHandler) ValidateArgs(args []string) error {
+	return nil
+}
+
+// GetRequiredFlags returns required flags for this command
+func (h *BuildHandler) GetRequiredFlags() []string {
+	return []string{}
+}
+
+// buildContainers returns the containers of the given services that build
+// their image, as named in the compose file. With named set, a service that
+// builds no image is an error rather than skipped.
+func buildContainers(serviceNames []string, named bool) ([]string, error) {
+	serviceUtils := utils.NewServiceUtils()
+
+	var containers []string
+	for _, serviceName := range serviceNames {
+		serviceConfig, err := serviceUtils.LoadServiceConfig(serviceName)
+		if err != nil {
+			return nil, err
+		}
+
+		found := false
+		if serviceConfig.Docker.Build.Context != "" {
+			containers = append(containers, serviceName)
+			found = true
+		}
+		for _, name := range slices.Sorted(maps.Keys(serviceConfig.Docker.Services)) {
+			if serviceConfig.Docker.Services[name].Build.Context != "" {
+				containers = append(containers, name)
+				found = true
+			}
+		}
+		if named && !found {
+			return nil, fmt.Errorf("service %s doesn't build an image; set docker.build.context in its definition", serviceName)
+		}
+	}
+	return containers, nil
+}
@@ -0,0 +1,278 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/isaacgarza/dev-stack/internal/core/docker"
+	"github.com/isaacgarza/dev-stack/internal/core/services"
+	cliTypes "github.com/isaacgarza/dev-stack/internal/pkg/cli/types"
+	"github.com/isaacgarza/dev-stack/internal/pkg/compose"
+	"github.com/isaacgarza/dev-stack/internal/pkg/constants"
+	"github.com/isaacgarza/dev-stack/internal/pkg/types"
+	"github.com/isaacgarza/dev-stack/internal/pkg/ui"
+	"github.com/isaacgarza/dev-stack/internal/pkg/utils"
+	"github.com/muesli/termenv"
+	"github.com/spf13/cobra"
+)
+
+// ciTeardownTimeout bounds the teardown of a CI stack, which still runs
+// once the job has been cancelled
+const ciTeardownTimeout = 2 * time.Minute
+
+// ciStopTimeout is how long, in seconds, containers of a CI stack get to
+// stop before they are killed
+const ciStopTimeout = 10
+
+// ciEvent is a milestone of ci up, printed as a JSON line next to the
+// progress events
+type ciEvent struct {
+	Time     time.Time           `json:"time"`
+	Event    string              `json:"event"`
+	Services []string            `json:"services,omitempty"`
+	Ports    map[string][]string `json:"ports,omitempty"`
+	Error    string              `json:"error,omitempty"`
+}
+
+// CIUpHandler handles the ci up command
+type CIUpHandler struct {
+	manager *services.Manager
+	up      *UpHandler
+}
+
+// NewCIUpHandler creates a new ci up handler
+func NewCIUpHandler(manager *services.Manager) *CIUpHandler {
+	return &CIUpHandler{manager: manager, up: NewUpHandler(manager)}
+}
+
+// Handle executes the ci up command. It starts the stack like up, without
+// prompts, icons or colors, with progress printed as JSON events and the
+// published ports pinned, and waits for the services to be ready. When
+// starting fails the logs of the failing services are printed and the stack
+// is torn down. A command given after -- runs once the stack is ready, with
+// the connection variables of the services, and the stack is torn down when
+// it exits, whatever its result; its exit code becomes the exit code of
+// dev-stack.
+func (h *CIUpHandler) Handle(ctx context.Context, cmd *cobra.Command, args []string, base *cliTypes.BaseCommand) (err error) {
+	applyCIMode(cmd)
+
+	serviceArgs, command := args, []string(nil)
+	if dash := cmd.ArgsLenAtDash(); dash >= 0 {
+		serviceArgs, command = args[:dash], args[dash:]
+	}
+
+	configPath := filepath.Join(constants.DevStackDir, constants.ConfigFileName)
+	if !utils.FileExists(configPath) {
+		return errors.New(constants.ErrNotInitialized)
+	}
+	cfg, err := LoadProjectConfig(configPath)
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	ports, err := writeCIFile(configPath)
+	if err != nil {
+		return err
+	}
+	serviceNames, err := selectedServices(cfg, serviceArgs, ActiveProfile(cmd))
+	if err != nil {
+		return err
+	}
+	if !cmd.Flags().Changed("wait-for") {
+		if err := cmd.Flags().Set("wait-for", strings.Join(serviceNames, ",")); err != nil {
+			return err
+		}
+	}
+
+	keep, _ := cmd.Flags().GetBool("keep")
+	if err := h.up.Handle(ctx, cmd, serviceArgs, base); err != nil {
+		writeCIEvent(ciEvent{Event: "failed", Services: serviceNames, Error: err.Error()})
+		h.dumpFailingLogs(ctx, cfg, serviceNames)
+		if !keep {
+			if teardownErr := teardownCIStack(ctx, cfg, base); teardownErr != nil {
+				ui.Warning("%v", teardownErr)
+			}
+		}
+		return err
+	}
+	writeCIEvent(ciEvent{Event: "ready", Services: serviceNames, Ports: ports})
+
+	// Without a command the stack stays up for the steps that follow
+	if len(command) == 0 {
+		return nil
+	}
+	if !keep {
+		defer func() {
+			if teardownErr := teardownCIStack(ctx, cfg, base); teardownErr != nil {
+				if err != nil {
+					ui.Warning("%v", teardownErr)
+					return
+				}
+				err = teardownErr
+			}
+		}()
+	}
+
+	env, err := ServiceConnectionEnv(configPath, cfg.Stack.Enabled)
+	if err != nil {
+		return err
+	}
+	run := exec.CommandContext(ctx, command[0], command[1:]...)
+	run.Env = os.Environ()
+	for _, key := range slices.Sorted(maps.Keys(env)) {
+		run.Env = append(run.Env, key+"="+env[key])
+	}
+	run.Stdin = os.Stdin
+	run.Stdout = os.Stdout
+	run.Stderr = os.Stderr
+
+	ui.Info("Running %s", command[0])
+	err = run.Run()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		ui.Error("%s exited with code %d", command[0], exitErr.ExitCode())
+		// The command has already reported its failure on its own output
+		cmd.SilenceErrors = true
+		cmd.SilenceUsage = true
+		return &types.ExitError{Command: command[0], Code: exitErr.ExitCode()}
+	}
+	if err != nil {
+		return fmt.Errorf("failed to run %s: %w", command[0], err)
+	}
+	return nil
+}
+
+// ValidateArgs validates the command arguments
+func (h *CIUpHandler) ValidateArgs(args []string) error {
+	return nil
+}
+
+// GetRequiredFlags returns required flags for this command
+func (h *CIUpHandler) GetRequiredFlags() []string {
+	return []string{}
+}
+
+// applyCIMode turns prompts off and makes output suit CI logs: messages
+// without icons or colors, progress as JSON events and errors without the
+// usage text
+func applyCIMode(cmd *cobra.Command) {
+	_ = cmd.Flags().Set(constants.FlagNonInteractive, "true")
+	cmd.SilenceUsage = true
+	ui.DefaultOutput.NoColor = true
+	ui.DefaultOutput.Plain = true
+	ui.DefaultOutput.JSONProgress = true
+	lipgloss.SetColorProfile(termenv.Ascii)
+}
+
+// writeCIFile renders the compose file pinning the published ports of the
+// stack, replacing the previous one, and returns the pinned ports
+func writeCIFile(configPath string) (map[string][]string, error) {
+	if !utils.FileExists(constants.DockerComposeFile) {
+		return nil, fmt.Errorf("ci up needs a compose file; run '%s' first", constants.CmdInit)
+	}
+	if err := os.Remove(compose.CIFile()); err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to remove the previous CI file: %w", err)
+	}
+	dotEnv, err := loadProjectDotEnv(configPath)
+	if err != nil {
+		return nil, err
+	}
+
+	ports, err := compose.CIPorts(utils.EnvLookup(dotEnv), docker.ComposeFiles()...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to pin the published ports: %w", err)
+	}
+	data, err := compose.CIOverride(ports)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render the CI compose file: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(compose.CIFile()), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create %s: %w", filepath.Dir(compose.CIFile()), err)
+	}
+	if err := os.WriteFile(compose.CIFile(), data, 0644); err != nil {
+		return nil, err
+	}
+	return ports, nil
+}
+
+// dumpFailingLogs prints the full logs of the services that aren't running
+// and healthy, or of every service when their status is unknown
+func (h *CIUpHandler) dumpFailingLogs(ctx context.Context, cfg *ProjectConfig, serviceNames []string) {
+	ctx = context.WithoutCancel(ctx)
+	h.manager.SetProjectName(cfg.Project.Name)
+
+	failing := serviceNames
+	if statuses, err := h.manager.GetServiceStatus(ctx, serviceNames); err == nil {
+		failing = nil
+		for _, status := range statuses {
+			if status.State != types.ServiceStateRunning ||
+				status.Health == types.HealthStatusUnhealthy || status.Health == types.HealthStatusStarting {
+				failing = append(failing, status.Name)
+			}
+		}
+	}
+
+	for _, name := range failing {
+		_, _ = fmt.Fprintf(os.Stderr, "\n--- logs of %s ---\n", name)
+		options := types.LogOptions{Timestamps: true, NoColor: true}
+		if err := h.manager.StreamLogs(ctx, []string{name}, options, os.Stderr); err != nil {
+			ui.Warning("Failed to read the logs of %s: %v", name, err)
+		}
+	}
+}
+
+// teardownCIStack removes the containers, networks and volumes of a CI
+// stack and the file pinning its ports. It runs even once ctx is cancelled,
+// so an interrupted job cleans up after itself.
+func teardownCIStack(ctx context.Context, cfg *ProjectConfig, base *cliTypes.BaseCommand) error {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), ciTeardownTimeout)
+	defer cancel()
+
+	ui.Info("Tearing down the CI stack")
+	logger := base.Logger.(loggerAdapter)
+	dockerClient, err := docker.Shared(logger.SlogLogger())
+	if err != nil {
+		return fmt.Errorf("failed to create Docker client: %w", err)
+	}
+	defer func() {
+		if err := dockerClient.Close(); err != nil {
+			base.Logger.Error("Failed to close Docker client", "error", err)
+		}
+	}()
+
+	if err := detachSharedServices(ctx, dockerClient, cfg, nil); err != nil {
+		return err
+	}
+	options := types.StopOptions{Timeout: ciStopTimeout, Remove: true, RemoveVolumes: true}
+	if err := dockerClient.Containers().Stop(ctx, cfg.Project.Name, nil, options); err != nil {
+		return fmt.Errorf("failed to tear down the CI stack: %w", err)
+	}
+	if err := dockerClient.Volumes().Remove(ctx, cfg.Project.Name); err != nil {
+		return fmt.Errorf("failed to remove volumes: %w", err)
+	}
+	if err := os.Remove(compose.CIFile()); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove %s: %w", compose.CIFile(), err)
+	}
+	writeCIEvent(ciEvent{Event: "teardown"})
+	return nil
+}
+
+// writeCIEvent prints event as a JSON line
+func writeCIEvent(event ciEvent) {
+	event.Time = time.Now().UTC()
+	data, err := json.Marshal(event)
+	if err != nil {
+		return
+	}
+	fmt.Println(string(data))
+}
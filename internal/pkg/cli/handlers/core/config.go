@@ -1,9 +1,20 @@
 package core
 
 import (
+	"context"
 	"fmt"
 	"log/slog"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
 
+	initHandler "github.com/isaacgarza/dev-stack/internal/pkg/cli/handlers/init"
+	"github.com/isaacgarza/dev-stack/internal/pkg/compose"
+	pkgConfig "github.com/isaacgarza/dev-stack/internal/pkg/config"
+	"github.com/isaacgarza/dev-stack/internal/pkg/constants"
+	"github.com/isaacgarza/dev-stack/internal/pkg/types"
 	"github.com/isaacgarza/dev-stack/internal/pkg/utils"
 	"gopkg.in/yaml.v3"
 )
@@ -15,13 +26,114 @@ type loggerAdapter interface {
 
 // ProjectConfig represents the dev-stack project configuration
 type ProjectConfig struct {
+	// SchemaVersion is the layout version of the file; see
+	// 'dev-stack config migrate'
+	SchemaVersion int `yaml:"schema_version"`
+
 	Project struct {
 		Name        string `yaml:"name"`
 		Environment string `yaml:"environment"`
+		Protected   bool   `yaml:"protected"`
 	} `yaml:"project"`
 	Stack struct {
 		Enabled []string `yaml:"enabled"`
+		// MaxParallel caps how many services up starts at once
+		MaxParallel int `yaml:"max_parallel"`
 	} `yaml:"stack"`
+	Advanced struct {
+		EnvFiles bool `yaml:"env_files"`
+	} `yaml:"advanced"`
+	Images struct {
+		Mirrors []string `yaml:"mirrors"`
+		// RegistryMirrors rewrites image references from a registry to a
+		// mirror or pull-through cache, keyed by registry host
+		RegistryMirrors map[string]string `yaml:"registry_mirrors"`
+		PullConcurrency int               `yaml:"pull_concurrency"`
+		PullRetries     int               `yaml:"pull_retries"`
+		// PullPolicy names the images up pulls first: always, missing or
+		// never. Unset, every image is pulled.
+		PullPolicy string `yaml:"pull_policy"`
+		// Emulation says what to do about images the catalog lists as
+		// having no variant for some architecture: auto, the default, pins
+		// them to one they have; warn and off leave them to Docker
+		Emulation string `yaml:"emulation"`
+	} `yaml:"images"`
+	Services  types.ServicesConfig             `yaml:"services"`
+	Profiles  map[string]ProfileConfig         `yaml:"profiles"`
+	Readiness map[string]types.ReadinessConfig `yaml:"readiness"`
+	Shutdown  map[string]types.ShutdownConfig  `yaml:"shutdown"`
+	Backup    types.BackupConfig               `yaml:"backup"`
+	Hooks     types.HooksConfig                `yaml:"hooks"`
+	Workflows types.WorkflowsConfig            `yaml:"workflows"`
+	Tasks     types.TasksConfig                `yaml:"tasks"`
+	Dev       types.DevConfig                  `yaml:"dev"`
+	Proxy     types.ProxyConfig                `yaml:"proxy"`
+	Hostnames types.HostnamesConfig            `yaml:"hostnames"`
+	Timeouts  types.TimeoutConfig              `yaml:"timeouts"`
+	AWS       types.AWSConfig                  `yaml:"aws"`
+	Kafka     types.KafkaConfig                `yaml:"kafka"`
+	Tracing   types.TracingConfig              `yaml:"tracing"`
+	Logging   types.LoggingConfig              `yaml:"logging"`
+	Events    types.EventsConfig               `yaml:"events"`
+	CrashLoop types.CrashLoopConfig            `yaml:"crash_loop"`
+	Idle      types.IdleConfig                 `yaml:"idle"`
+	Security  types.SecurityConfig             `yaml:"security"`
+	Network   types.NetworkConfig              `yaml:"network"`
+	DNS       types.DNSConfig                  `yaml:"dns"`
+	HTTPProxy types.HTTPProxyConfig            `yaml:"http_proxy"`
+	Trust     types.TrustConfig                `yaml:"trust"`
+	// TaskImports are Makefiles and justfiles whose targets become tasks
+	TaskImports []string `yaml:"task_imports"`
+}
+
+// ProfileConfig represents a named profile in the project configuration
+type ProfileConfig struct {
+	Services  []string `yaml:"services"`
+	Protected bool     `yaml:"protected"`
+	// Resources limits each service of the profile while it is active
+	Resources types.ResourceLimits `yaml:"resources"`
+}
+
+// RegenerateCompose regenerates dev-stack/docker-compose.yml and the env
+// files for the enabled services of cfg, and the dev container exported
+// from them
+func RegenerateCompose(ctx context.Context, cfg *ProjectConfig) error {
+	err := initHandler.GenerateComposeFiles(ctx, cfg.Project.Name, cfg.Project.Environment, cfg.Stack.Enabled, initHandler.ComposeOptions{
+		EnvFiles:        cfg.Advanced.EnvFiles,
+		Versions:        cfg.Services.Versions(),
+		RegistryMirrors: cfg.Images.RegistryMirrors,
+		Resources:       cfg.Services.Resources(),
+		BuildArgs:       cfg.Services.BuildArgs(),
+		Mounts:          cfg.Services.Mounts(),
+		Platforms:       cfg.Services.Platforms(),
+		Emulation:       cfg.EmulationPolicy(),
+		Network:         cfg.Network,
+		DNS:             cfg.DNS,
+		ServiceDNS:      cfg.Services.DNS(),
+		HTTPProxy:       cfg.HTTPProxy,
+		Security:        cfg.Security,
+		Trust:           cfg.Trust,
+		Profiles:        profileResources(cfg.Profiles),
+		Proxy:           cfg.Proxy,
+		Hostnames:       cfg.Hostnames,
+		Tracing:         cfg.Tracing,
+		Logging:         cfg.Logging,
+	})
+	if err != nil {
+		return err
+	}
+	return SyncDevcontainer(cfg)
+}
+
+// profileResources returns the resource limits of the profiles that set any
+func profileResources(profiles map[string]ProfileConfig) map[string]initHandler.ProfileResources {
+	resources := make(map[string]initHandler.ProfileResources)
+	for name, profile := range profiles {
+		if !profile.Resources.IsZero() {
+			resources[name] = initHandler.ProfileResources{Services: profile.Services, Resources: profile.Resources}
+		}
+	}
+	return resources
 }
 
 // LoadProjectConfig loads the dev-stack project configuration
@@ -36,10 +148,171 @@ func LoadProjectConfig(configPath string) (*ProjectConfig, error) {
 		content += line + "\n"
 	}
 
+	dotEnv, err := loadProjectDotEnv(configPath)
+	if err != nil {
+		return nil, err
+	}
+	// ${PROJECT_ROOT} is the directory holding the dev-stack directory,
+	// unless the environment or .env sets it
+	if _, ok := dotEnv[compose.ProjectRootVar]; !ok {
+		if projectRoot, err := filepath.Abs(filepath.Dir(filepath.Dir(configPath))); err == nil {
+			if dotEnv == nil {
+				dotEnv = make(map[string]string)
+			}
+			dotEnv[compose.ProjectRootVar] = projectRoot
+		}
+	}
+
+	// Values are interpolated once parsed, so comments and keys are left
+	// alone
+	var document yaml.Node
+	if err := yaml.Unmarshal([]byte(content), &document); err != nil {
+		return nil, &types.ConfigInvalidError{Path: configPath, Err: fmt.Errorf("failed to parse config: %w", err)}
+	}
+	interpolate(&document, utils.EnvLookup(dotEnv))
+
 	var cfg ProjectConfig
-	if err := yaml.Unmarshal([]byte(content), &cfg); err != nil {
-		return nil, fmt.Errorf("failed to parse config: %w", err)
+	if len(document.Content) > 0 {
+		if err := document.Decode(&cfg); err != nil {
+			return nil, &types.ConfigInvalidError{Path: configPath, Err: fmt.Errorf("failed to parse config: %w", err)}
+		}
+	}
+	if err := cfg.validate(configPath); err != nil {
+		return nil, &types.ConfigInvalidError{Path: configPath, Err: err}
+	}
+
+	user, err := pkgConfig.LoadUserConfig()
+	if err != nil {
+		return nil, err
 	}
+	cfg.applyUserConfig(user)
 
 	return &cfg, nil
 }
+
+// interpolate expands the ${VAR} references in the values under node.
+// Single-quoted values are taken literally. Plain values that changed have
+// their type resolved again, so ${PORT:-5432} still reads as a number.
+func interpolate(node *yaml.Node, lookup func(string) (string, bool)) {
+	switch node.Kind {
+	case yaml.ScalarNode:
+		if node.Style&yaml.SingleQuotedStyle != 0 {
+			return
+		}
+		if value := utils.ExpandEnv(node.Value, lookup); value != node.Value {
+			node.Value = value
+			if node.Style == 0 {
+				node.Tag = ""
+			}
+		}
+	case yaml.MappingNode:
+		for i := 1; i < len(node.Content); i += 2 {
+			interpolate(node.Content[i], lookup)
+		}
+	default:
+		for _, child := range node.Content {
+			interpolate(child, lookup)
+		}
+	}
+}
+
+// validate checks the settings that have a fixed set of values or a
+// format of their own
+func (c *ProjectConfig) validate(configPath string) error {
+	if c.SchemaVersion > pkgConfig.SchemaVersion {
+		return fmt.Errorf("%s has schema version %d, newer than this dev-stack supports (%d); upgrade dev-stack", configPath, c.SchemaVersion, pkgConfig.SchemaVersion)
+	}
+	if policy := c.Images.PullPolicy; policy != "" && !slices.Contains(pullPolicies, policy) {
+		return fmt.Errorf("invalid images.pull_policy %q: must be one of %s", policy, strings.Join(pullPolicies, ", "))
+	}
+	if policy := c.Images.Emulation; policy != "" && !slices.Contains(emulationPolicies, policy) {
+		return fmt.Errorf("invalid images.emulation %q: must be one of %s", policy, strings.Join(emulationPolicies, ", "))
+	}
+	for _, name := range slices.Sorted(maps.Keys(c.Services.Platforms())) {
+		if err := types.ValidatePlatform(c.Services[name].Platform); err != nil {
+			return fmt.Errorf("services.%s.platform: %w", name, err)
+		}
+	}
+	if err := c.DNS.Validate(); err != nil {
+		return fmt.Errorf("dns.%w", err)
+	}
+	for _, name := range slices.Sorted(maps.Keys(c.Services.DNS())) {
+		if err := c.Services[name].DNS.Validate(); err != nil {
+			return fmt.Errorf("services.%s.dns.%w", name, err)
+		}
+	}
+	if err := c.HTTPProxy.Validate(); err != nil {
+		return err
+	}
+	if err := c.AWS.Validate(); err != nil {
+		return err
+	}
+	if err := c.Kafka.Validate(); err != nil {
+		return err
+	}
+	if err := c.Network.Validate(); err != nil {
+		return err
+	}
+	if err := c.Security.Validate(); err != nil {
+		return err
+	}
+	if err := c.Trust.Validate(); err != nil {
+		return err
+	}
+	if err := c.Logging.Validate(); err != nil {
+		return err
+	}
+	if err := c.Events.Validate(); err != nil {
+		return err
+	}
+	if err := c.Workflows.Validate(); err != nil {
+		return err
+	}
+	if err := c.Tasks.Validate(); err != nil {
+		return err
+	}
+	if err := types.ValidateTaskImports(c.TaskImports); err != nil {
+		return err
+	}
+	if err := c.Idle.Validate(); err != nil {
+		return err
+	}
+	return c.CrashLoop.Validate()
+}
+
+// applyUserConfig fills in the settings the project leaves to the user
+// configuration. Project registry mirrors win over the user's for the same
+// host, and DEV_STACK_BACKUP_DIR wins over both backup directories.
+func (c *ProjectConfig) applyUserConfig(user *pkgConfig.UserConfig) {
+	for host, mirror := range user.RegistryMirrors {
+		if _, ok := c.Images.RegistryMirrors[host]; ok {
+			continue
+		}
+		if c.Images.RegistryMirrors == nil {
+			c.Images.RegistryMirrors = make(map[string]string)
+		}
+		c.Images.RegistryMirrors[host] = mirror
+	}
+
+	if dir := os.Getenv(constants.EnvBackupDir); dir != "" {
+		c.Backup.Directory = dir
+	} else if c.Backup.Directory == "" {
+		c.Backup.Directory = user.BackupDir
+	}
+}
+
+// loadProjectDotEnv loads the optional .env file at the project root, which is
+// the parent of the dev-stack directory holding the config file
+func loadProjectDotEnv(configPath string) (map[string]string, error) {
+	projectRoot := filepath.Dir(filepath.Dir(configPath))
+	envPath := filepath.Join(projectRoot, constants.DotEnvFileName)
+	if !utils.FileExists(envPath) {
+		return nil, nil
+	}
+
+	values, err := utils.ParseEnvFile(envPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load %s: %w", envPath, err)
+	}
+	return values, nil
+}
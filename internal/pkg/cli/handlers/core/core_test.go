@@ -1,16 +1,30 @@
 package core
 
 import (
+	"bytes"
 	"context"
+	"errors"
+	"net"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 
+	"github.com/isaacgarza/dev-stack/internal/core/docker"
+	"github.com/isaacgarza/dev-stack/internal/core/events"
+	"github.com/isaacgarza/dev-stack/internal/core/localstack"
+	"github.com/isaacgarza/dev-stack/internal/core/projectstate"
+	"github.com/isaacgarza/dev-stack/internal/core/registry"
 	cliTypes "github.com/isaacgarza/dev-stack/internal/pkg/cli/types"
+	"github.com/isaacgarza/dev-stack/internal/pkg/constants"
+	"github.com/isaacgarza/dev-stack/internal/pkg/display"
+	"github.com/isaacgarza/dev-stack/internal/pkg/types"
 )
 
 // MockLogger implements the Logger interface for testing
@@ -31,13 +45,13 @@ func (m *MockLogger) Debug(msg string, args ...interface{}) {
 }
 
 func TestNewUpHandler(t *testing.T) {
-	handler := NewUpHandler()
+	handler := NewUpHandler(nil)
 	assert.NotNil(t, handler)
 	assert.IsType(t, &UpHandler{}, handler)
 }
 
 func TestUpHandler_ValidateArgs(t *testing.T) {
-	handler := NewUpHandler()
+	handler := NewUpHandler(nil)
 
 	t.Run("no args", func(t *testing.T) {
 		err := handler.ValidateArgs([]string{})
@@ -51,14 +65,14 @@ func TestUpHandler_ValidateArgs(t *testing.T) {
 }
 
 func TestUpHandler_GetRequiredFlags(t *testing.T) {
-	handler := NewUpHandler()
+	handler := NewUpHandler(nil)
 	flags := handler.GetRequiredFlags()
 	assert.Empty(t, flags)
 }
 
 func TestUpHandler_Handle_ConfigNotFound(t *testing.T) {
 	tmpDir := t.TempDir()
-	handler := NewUpHandler()
+	handler := NewUpHandler(nil)
 	mockLogger := &MockLogger{}
 
 	cmd := &cobra.Command{}
@@ -127,6 +141,254 @@ stack:
 		assert.Empty(t, cfg.Project.Name)
 		assert.Empty(t, cfg.Stack.Enabled)
 	})
+
+	t.Run("interpolates env and .env file", func(t *testing.T) {
+		projectDir := t.TempDir()
+		devStackDir := filepath.Join(projectDir, "dev-stack")
+		assert.NoError(t, os.MkdirAll(devStackDir, 0755))
+		assert.NoError(t, os.WriteFile(filepath.Join(projectDir, ".env"), []byte("PROJECT_NAME=from-dotenv\nSECOND=dotenv\n"), 0644))
+		t.Setenv("SECOND", "from-process")
+
+		configContent := `project:
+  name: ${PROJECT_NAME}
+  environment: ${DEV_STACK_TEST_UNSET:-staging}
+stack:
+  enabled:
+    - ${SECOND}`
+		configPath := filepath.Join(devStackDir, "dev-stack-config.yml")
+		assert.NoError(t, os.WriteFile(configPath, []byte(configContent), 0644))
+
+		cfg, err := LoadProjectConfig(configPath)
+		assert.NoError(t, err)
+		assert.Equal(t, "from-dotenv", cfg.Project.Name)
+		assert.Equal(t, "staging", cfg.Project.Environment)
+		assert.Equal(t, []string{"from-process"}, cfg.Stack.Enabled)
+	})
+
+	t.Run("interpolates values only", func(t *testing.T) {
+		devStackDir := filepath.Join(t.TempDir(), "dev-stack")
+		assert.NoError(t, os.MkdirAll(devStackDir, 0755))
+		t.Setenv("DEV_STACK_TEST_YAML", "x\n  bogus: [")
+
+		configContent := `# Comments such as ${DEV_STACK_TEST_YAML} are not expanded
+schema_version: ${DEV_STACK_TEST_UNSET:-1}
+project:
+  name: '${DEV_STACK_TEST_YAML}'
+  environment: "${DEV_STACK_TEST_UNSET:-staging}"`
+		configPath := filepath.Join(devStackDir, "dev-stack-config.yml")
+		assert.NoError(t, os.WriteFile(configPath, []byte(configContent), 0644))
+
+		cfg, err := LoadProjectConfig(configPath)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, cfg.SchemaVersion)
+		assert.Equal(t, "${DEV_STACK_TEST_YAML}", cfg.Project.Name)
+		assert.Equal(t, "staging", cfg.Project.Environment)
+	})
+
+	t.Run("project root", func(t *testing.T) {
+		projectDir := t.TempDir()
+		devStackDir := filepath.Join(projectDir, "dev-stack")
+		assert.NoError(t, os.MkdirAll(devStackDir, 0755))
+
+		configContent := "services:\n  api:\n    mounts:\n      - source: ${PROJECT_ROOT}/fixtures\n        target: /fixtures\n"
+		configPath := filepath.Join(devStackDir, "dev-stack-config.yml")
+		assert.NoError(t, os.WriteFile(configPath, []byte(configContent), 0644))
+
+		cfg, err := LoadProjectConfig(configPath)
+		assert.NoError(t, err)
+		assert.Equal(t, []types.BindMount{{Source: filepath.Join(projectDir, "fixtures"), Target: "/fixtures"}}, cfg.Services["api"].Mounts)
+	})
+
+	t.Run("user configuration beneath the project", func(t *testing.T) {
+		userPath := filepath.Join(t.TempDir(), "config.yaml")
+		assert.NoError(t, os.WriteFile(userPath, []byte("registry_mirrors:\n  docker.io: user-mirror\n  ghcr.io: ghcr-mirror\nbackup_dir: /user/backups\n"), 0644))
+		t.Setenv(constants.EnvUserConfig, userPath)
+
+		configPath := filepath.Join(tmpDir, "mirrors.yml")
+		assert.NoError(t, os.WriteFile(configPath, []byte("images:\n  registry_mirrors:\n    docker.io: project-mirror\n"), 0644))
+
+		cfg, err := LoadProjectConfig(configPath)
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]string{"docker.io": "project-mirror", "ghcr.io": "ghcr-mirror"}, cfg.Images.RegistryMirrors)
+		assert.Equal(t, "/user/backups", cfg.Backup.Directory)
+
+		t.Setenv(constants.EnvBackupDir, "/env/backups")
+		cfg, err = LoadProjectConfig(configPath)
+		assert.NoError(t, err)
+		assert.Equal(t, "/env/backups", cfg.Backup.Directory)
+	})
+
+	t.Run("pull policy", func(t *testing.T) {
+		configPath := filepath.Join(tmpDir, "pull.yml")
+		assert.NoError(t, os.WriteFile(configPath, []byte("images:\n  pull_policy: missing\n"), 0644))
+		cfg, err := LoadProjectConfig(configPath)
+		assert.NoError(t, err)
+		assert.Equal(t, constants.PullPolicyMissing, cfg.PullPolicy())
+
+		assert.Equal(t, constants.PullPolicyAlways, (&ProjectConfig{}).PullPolicy())
+
+		assert.NoError(t, os.WriteFile(configPath, []byte("images:\n  pull_policy: sometimes\n"), 0644))
+		_, err = LoadProjectConfig(configPath)
+		assert.EqualError(t, err, `invalid images.pull_policy "sometimes": must be one of always, missing, never`)
+	})
+
+	t.Run("platforms", func(t *testing.T) {
+		configPath := filepath.Join(tmpDir, "platforms.yml")
+		assert.NoError(t, os.WriteFile(configPath, []byte("images:\n  emulation: warn\nservices:\n  mysql:\n    platform: linux/amd64\n"), 0644))
+		cfg, err := LoadProjectConfig(configPath)
+		assert.NoError(t, err)
+		assert.Equal(t, constants.EmulationWarn, cfg.EmulationPolicy())
+		assert.Equal(t, map[string]string{"mysql": "linux/amd64"}, cfg.Services.Platforms())
+
+		assert.Equal(t, constants.EmulationAuto, (&ProjectConfig{}).EmulationPolicy())
+
+		assert.NoError(t, os.WriteFile(configPath, []byte("services:\n  mysql:\n    platform: amd64\n"), 0644))
+		_, err = LoadProjectConfig(configPath)
+		assert.ErrorContains(t, err, "services.mysql.platform: invalid platform")
+	})
+
+	t.Run("security profile", func(t *testing.T) {
+		configPath := filepath.Join(tmpDir, "security.yml")
+		assert.NoError(t, os.WriteFile(configPath, []byte("security:\n  profile: gvisor\n  exempt:\n    app: [read_only]\n"), 0644))
+		cfg, err := LoadProjectConfig(configPath)
+		assert.NoError(t, err)
+		assert.Equal(t, types.RuntimeGVisor, cfg.Security.RuntimeName())
+		assert.False(t, cfg.Security.Applies("app", types.SecurityReadOnly))
+
+		assert.NoError(t, os.WriteFile(configPath, []byte("security:\n  profile: hardened\n  exempt:\n    app: [seccomp]\n"), 0644))
+		_, err = LoadProjectConfig(configPath)
+		assert.ErrorContains(t, err, "security.exempt.app: invalid control")
+	})
+
+	t.Run("dns", func(t *testing.T) {
+		configPath := filepath.Join(tmpDir, "dns.yml")
+		assert.NoError(t, os.WriteFile(configPath, []byte("dns:\n  servers: [10.0.0.2]\nservices:\n  app:\n    dns:\n      extra_hosts: [\"git.corp:10.1.2.3\"]\n"), 0644))
+		cfg, err := LoadProjectConfig(configPath)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"10.0.0.2"}, cfg.DNS.Servers)
+		assert.Equal(t, map[string]types.DNSConfig{"app": {ExtraHosts: []string{"git.corp:10.1.2.3"}}}, cfg.Services.DNS())
+
+		assert.NoError(t, os.WriteFile(configPath, []byte("services:\n  app:\n    dns:\n      servers: [dns.corp]\n"), 0644))
+		_, err = LoadProjectConfig(configPath)
+		assert.ErrorContains(t, err, "services.app.dns.servers: \"dns.corp\" is not an IP address")
+	})
+
+	t.Run("http proxy", func(t *testing.T) {
+		configPath := filepath.Join(tmpDir, "http-proxy.yml")
+		assert.NoError(t, os.WriteFile(configPath, []byte("http_proxy:\n  from_env: true\n  https: http://proxy.corp:8080\n  no_proxy: [.corp.example.com]\n"), 0644))
+		cfg, err := LoadProjectConfig(configPath)
+		assert.NoError(t, err)
+		assert.Equal(t, types.HTTPProxyConfig{FromEnv: true, HTTPS: "http://proxy.corp:8080", NoProxy: []string{".corp.example.com"}}, cfg.HTTPProxy)
+
+		assert.NoError(t, os.WriteFile(configPath, []byte("http_proxy:\n  http: proxy.corp:8080\n"), 0644))
+		_, err = LoadProjectConfig(configPath)
+		assert.ErrorContains(t, err, "http_proxy.http: \"proxy.corp:8080\" is not an http:// or https:// URL")
+	})
+
+	t.Run("trust", func(t *testing.T) {
+		configPath := filepath.Join(tmpDir, "trust.yml")
+		assert.NoError(t, os.WriteFile(configPath, []byte("trust:\n  ca_bundles: [certs/corp-root.pem]\n  exempt: [proxy]\n"), 0644))
+		cfg, err := LoadProjectConfig(configPath)
+		assert.NoError(t, err)
+		assert.Equal(t, types.TrustConfig{CABundles: []string{"certs/corp-root.pem"}, Exempt: []string{"proxy"}}, cfg.Trust)
+
+		assert.NoError(t, os.WriteFile(configPath, []byte("trust:\n  exempt: [proxy]\n"), 0644))
+		_, err = LoadProjectConfig(configPath)
+		assert.ErrorContains(t, err, "only apply with trust.ca_bundles")
+	})
+
+	t.Run("aws resources", func(t *testing.T) {
+		configPath := filepath.Join(tmpDir, "aws.yml")
+		assert.NoError(t, os.WriteFile(configPath, []byte("aws:\n  queues:\n    - name: orders.fifo\n      fifo: true\n"), 0644))
+		cfg, err := LoadProjectConfig(configPath)
+		assert.NoError(t, err)
+		assert.Equal(t, []types.AWSQueue{{Name: "orders.fifo", FIFO: true}}, cfg.AWS.Queues)
+
+		assert.NoError(t, os.WriteFile(configPath, []byte("aws:\n  tables:\n    - name: users\n"), 0644))
+		_, err = LoadProjectConfig(configPath)
+		assert.EqualError(t, err, "aws.tables: table users has no partition_key")
+	})
+
+	t.Run("kafka topics", func(t *testing.T) {
+		configPath := filepath.Join(tmpDir, "kafka.yml")
+		assert.NoError(t, os.WriteFile(configPath, []byte("kafka:\n  topics:\n    - name: orders\n      partitions: 3\n      retention: 7d\n"), 0644))
+		cfg, err := LoadProjectConfig(configPath)
+		assert.NoError(t, err)
+		assert.Equal(t, []types.KafkaTopicConfig{{Name: "orders", Partitions: 3, Retention: "7d"}}, cfg.Kafka.Topics)
+
+		assert.NoError(t, os.WriteFile(configPath, []byte("kafka:\n  topics:\n    - name: orders\n      retention: soon\n"), 0644))
+		_, err = LoadProjectConfig(configPath)
+		assert.EqualError(t, err, `kafka.topics: topic orders: invalid retention "soon": expected a duration such as 12h or 7d, or forever`)
+	})
+}
+
+func TestWritePullDigests(t *testing.T) {
+	var buf bytes.Buffer
+	err := writePullDigests(&buf, &docker.PullSummary{Results: []docker.PullResult{
+		{Image: "redis:7", Digest: "sha256:abc"},
+		{Image: "app:dev"},
+		{Image: "postgres:16", Error: "manifest unknown"},
+	}})
+	assert.NoError(t, err)
+	assert.Equal(t, "IMAGE        DIGEST\n"+
+		"redis:7      sha256:abc\n"+
+		"app:dev      -\n"+
+		"postgres:16  failed: manifest unknown\n", buf.String())
+}
+
+func TestAWSResourceRows(t *testing.T) {
+	cfg := &ProjectConfig{AWS: types.AWSConfig{
+		Buckets: []types.AWSBucket{{Name: "uploads"}, {Name: "assets"}},
+		Queues:  []types.AWSQueue{{Name: "jobs"}},
+		Topics:  []types.AWSTopic{{Name: "events", Subscriptions: []string{"jobs"}}},
+	}}
+	resources := &localstack.Resources{
+		Buckets: []string{"scratch", "uploads"},
+		Queues:  []string{"jobs"},
+		Topics:  []string{"events"},
+	}
+
+	var buf bytes.Buffer
+	assert.NoError(t, writeAWSResources(&buf, awsResourceRows(cfg, resources)))
+	assert.Equal(t, "TYPE          NAME            STATUS\n"+
+		"bucket        scratch         undeclared\n"+
+		"bucket        uploads         provisioned\n"+
+		"bucket        assets          missing\n"+
+		"queue         jobs            provisioned\n"+
+		"topic         events          provisioned\n"+
+		"subscription  events -> jobs  missing\n", buf.String())
+}
+
+func TestTopicFromFlags(t *testing.T) {
+	cfg := &ProjectConfig{Kafka: types.KafkaConfig{Topics: []types.KafkaTopicConfig{
+		{Name: "orders", Partitions: 3, Config: map[string]string{"cleanup.policy": "delete"}},
+	}}}
+	newCmd := func() *cobra.Command {
+		cmd := &cobra.Command{}
+		cmd.Flags().Int("partitions", 0, "")
+		cmd.Flags().Int("replication-factor", 0, "")
+		cmd.Flags().String("retention", "", "")
+		cmd.Flags().String("config", "", "")
+		return cmd
+	}
+
+	cmd := newCmd()
+	assert.NoError(t, cmd.Flags().Set("retention", "1d"))
+	assert.NoError(t, cmd.Flags().Set("config", "cleanup.policy=compact, min.insync.replicas=1"))
+	topic, err := topicFromFlags(cmd, cfg, "orders")
+	assert.NoError(t, err)
+	assert.Equal(t, types.KafkaTopicConfig{
+		Name:       "orders",
+		Partitions: 3,
+		Retention:  "1d",
+		Config:     map[string]string{"cleanup.policy": "compact", "min.insync.replicas": "1"},
+	}, topic)
+	assert.Equal(t, "delete", cfg.Kafka.Topics[0].Config["cleanup.policy"])
+
+	cmd = newCmd()
+	assert.NoError(t, cmd.Flags().Set("config", "compact"))
+	_, err = topicFromFlags(cmd, cfg, "audit")
+	assert.EqualError(t, err, `invalid config "compact": expected key=value`)
 }
 
 func TestProjectConfig_Structure(t *testing.T) {
@@ -156,3 +418,789 @@ func TestMockLogger(t *testing.T) {
 
 	mockLogger.AssertExpectations(t)
 }
+
+func TestConfirmProtected(t *testing.T) {
+	newCmd := func() *cobra.Command {
+		cmd := &cobra.Command{}
+		cmd.Flags().Bool("i-know", false, "")
+		cmd.Flags().Bool("non-interactive", false, "")
+		cmd.Flags().String("profile", "", "")
+		return cmd
+	}
+
+	cfg := &ProjectConfig{}
+	cfg.Project.Name = "shared"
+	cfg.Profiles = map[string]ProfileConfig{"staging": {Protected: true}}
+
+	t.Run("unprotected context proceeds", func(t *testing.T) {
+		assert.NoError(t, ConfirmProtected(newCmd(), cfg, "drop data"))
+	})
+
+	t.Run("protected profile refuses non-interactive runs", func(t *testing.T) {
+		cmd := newCmd()
+		_ = cmd.Flags().Set("profile", "staging")
+		_ = cmd.Flags().Set("non-interactive", "true")
+
+		err := ConfirmProtected(cmd, cfg, "drop data")
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "--i-know")
+	})
+
+	t.Run("protected profile proceeds with --i-know", func(t *testing.T) {
+		cmd := newCmd()
+		_ = cmd.Flags().Set("profile", "staging")
+		_ = cmd.Flags().Set("i-know", "true")
+
+		assert.NoError(t, ConfirmProtected(cmd, cfg, "drop data"))
+	})
+
+	t.Run("protected project via environment profile", func(t *testing.T) {
+		t.Setenv("DEV_STACK_PROFILE", "staging")
+		cmd := newCmd()
+		_ = cmd.Flags().Set("non-interactive", "true")
+
+		assert.Error(t, ConfirmProtected(cmd, cfg, "drop data"))
+	})
+}
+
+func TestStatusTransitions(t *testing.T) {
+	previous := []display.ServiceStatus{
+		{Name: "postgres", State: "running", Health: "starting"},
+		{Name: "redis", State: "running", Health: "healthy"},
+		{Name: "kafka", State: "running", Health: "none"},
+	}
+	current := []display.ServiceStatus{
+		{Name: "postgres", State: "running", Health: "healthy"},
+		{Name: "redis", State: "running", Health: "healthy"},
+		{Name: "kafka", State: "exited", Health: "none"},
+		{Name: "jaeger", State: "running", Health: "none"},
+	}
+
+	assert.Nil(t, statusTransitions(nil, current))
+	assert.Equal(t, map[string]string{
+		"postgres": "starting → healthy",
+		"kafka":    "running → exited",
+		"jaeger":   "new",
+	}, statusTransitions(previous, current))
+}
+
+func TestUntilConditionMet(t *testing.T) {
+	healthy := []display.ServiceStatus{
+		{Name: "postgres", State: "running", Health: "healthy"},
+		{Name: "redis", State: "running", Health: "none"},
+	}
+	starting := []display.ServiceStatus{
+		{Name: "postgres", State: "running", Health: "starting"},
+	}
+	stopped := []display.ServiceStatus{
+		{Name: "postgres", State: "exited", Health: "none"},
+	}
+
+	tests := []struct {
+		name      string
+		condition string
+		statuses  []display.ServiceStatus
+		expected  []string
+		met       bool
+	}{
+		{"all healthy", untilHealthy, healthy, nil, true},
+		{"still starting", untilHealthy, starting, nil, false},
+		{"expected service missing", untilHealthy, healthy, []string{"postgres", "kafka"}, false},
+		{"no containers are not healthy", untilHealthy, nil, nil, false},
+		{"all stopped", untilStopped, stopped, nil, true},
+		{"no containers are stopped", untilStopped, nil, nil, true},
+		{"still running", untilStopped, healthy, nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.met, untilConditionMet(tt.condition, tt.statuses, tt.expected))
+		})
+	}
+}
+
+func TestReadinessTargets_ResolvePorts(t *testing.T) {
+	t.Chdir(t.TempDir())
+	require.NoError(t, os.WriteFile(constants.DotEnvFileName, []byte("GRAFANA_PORT=3300\n"), 0644))
+	cfg := &ProjectConfig{Readiness: map[string]types.ReadinessConfig{
+		"postgres": {Probes: []types.ReadinessProbe{{Type: types.ProbeTCP, Port: "${POSTGRES_PORT:-5432}"}}},
+	}}
+
+	targets, err := readinessTargets(cfg, []string{"grafana", "postgres"}, time.Minute)
+	require.NoError(t, err)
+	require.Len(t, targets, 2)
+	assert.Equal(t, "http://localhost:3300/api/health", targets[0].Readiness.Probes[0].URL)
+	assert.Equal(t, "5432", targets[1].Readiness.Probes[0].Port)
+	assert.Equal(t, "${POSTGRES_PORT:-5432}", cfg.Readiness["postgres"].Probes[0].Port)
+}
+
+func TestParseScaleTargets(t *testing.T) {
+	targets, err := parseScaleTargets([]string{"redis=3", "postgres=0"})
+	assert.NoError(t, err)
+	assert.Equal(t, []scaleTarget{{Service: "redis", Replicas: 3}, {Service: "postgres", Replicas: 0}}, targets)
+
+	for _, args := range [][]string{{"redis"}, {"=2"}, {"redis=two"}, {"redis=-1"}, {"redis=1", "redis=2"}} {
+		_, err := parseScaleTargets(args)
+		assert.Error(t, err, "args %v", args)
+	}
+}
+
+func TestToDisplayStatuses_FoldsReplicas(t *testing.T) {
+	statuses := []types.ServiceStatus{
+		{Name: "redis", State: types.ServiceStateRunning, Health: types.HealthStatusStarting, Labels: map[string]string{constants.ComposeNumberLabel: "2"}},
+		{Name: "redis", State: types.ServiceStateRunning, Health: types.HealthStatusHealthy, Labels: map[string]string{constants.ComposeNumberLabel: "1"},
+			Ports: []types.PortMapping{{Host: "6379", Container: "6379"}}},
+		{Name: "redis", State: types.ServiceStateStopped, Health: types.HealthStatusNone, Labels: map[string]string{constants.ComposeNumberLabel: "3"}},
+		{Name: "postgres", State: types.ServiceStateRunning, Health: types.HealthStatusHealthy},
+	}
+
+	rows := toDisplayStatuses(statuses)
+	assert.Len(t, rows, 2)
+	assert.Equal(t, "redis", rows[0].Name)
+	assert.Equal(t, 3, rows[0].Replicas)
+	assert.Equal(t, 2, rows[0].Running)
+	assert.Equal(t, "running", rows[0].State)
+	assert.Equal(t, "starting", rows[0].Health)
+	assert.Equal(t, []string{"6379:6379"}, rows[0].Ports)
+	assert.Equal(t, 1, rows[1].Replicas)
+
+	assert.False(t, untilConditionMet(untilHealthy, rows, nil))
+}
+
+func TestWriteEnv(t *testing.T) {
+	env := map[string]string{
+		"REDIS_URL":    "redis://:password@localhost:6379",
+		"DATABASE_URL": "postgresql://postgres:pa$s word@localhost:5432/local_dev",
+		"GREETING":     "it's",
+	}
+
+	tests := []struct {
+		name     string
+		format   string
+		export   bool
+		expected string
+	}{
+		{
+			name:   "dotenv",
+			format: envFormatDotenv,
+			expected: "DATABASE_URL=\"postgresql://postgres:pa\\$s word@localhost:5432/local_dev\"\n" +
+				"GREETING=\"it's\"\n" +
+				"REDIS_URL=redis://:password@localhost:6379\n",
+		},
+		{
+			name:   "shell export",
+			format: envFormatShell,
+			export: true,
+			expected: "export DATABASE_URL='postgresql://postgres:pa$s word@localhost:5432/local_dev'\n" +
+				"export GREETING='it'\\''s'\n" +
+				"export REDIS_URL=redis://:password@localhost:6379\n",
+		},
+		{
+			name:   "json",
+			format: envFormatJSON,
+			expected: "{\n" +
+				"  \"DATABASE_URL\": \"postgresql://postgres:pa$s word@localhost:5432/local_dev\",\n" +
+				"  \"GREETING\": \"it's\",\n" +
+				"  \"REDIS_URL\": \"redis://:password@localhost:6379\"\n" +
+				"}\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			assert.NoError(t, writeEnv(&buf, env, tt.format, tt.export))
+			assert.Equal(t, tt.expected, buf.String())
+		})
+	}
+}
+
+func TestValidateEnvFormat(t *testing.T) {
+	assert.NoError(t, validateEnvFormat(envFormatDotenv, true))
+	assert.NoError(t, validateEnvFormat(envFormatShell, false))
+	assert.NoError(t, validateEnvFormat(envFormatJSON, false))
+	assert.Error(t, validateEnvFormat(envFormatJSON, true))
+	assert.Error(t, validateEnvFormat("yaml", false))
+}
+
+func TestApplyComposeProfiles(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	services, err := applyComposeProfiles([]string{"postgres", "pgadmin"}, "")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"postgres", "pgadmin"}, services, "without a compose file services are kept")
+
+	require.NoError(t, os.MkdirAll(constants.DevStackDir, 0755))
+	require.NoError(t, os.WriteFile(constants.DockerComposeFile, []byte(`services:
+  postgres:
+    image: postgres:15
+  pgadmin:
+    image: dpage/pgadmin4
+    profiles: [tools]
+  adminer:
+    image: adminer
+    profiles: [tools, debug]
+`), 0644))
+
+	services, err = applyComposeProfiles([]string{"postgres", "pgadmin"}, "")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"postgres"}, services)
+
+	services, err = applyComposeProfiles([]string{"postgres", "pgadmin"}, "tools")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"postgres", "pgadmin", "adminer"}, services)
+
+	services, err = applyComposeProfiles([]string{"postgres"}, "debug")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"postgres", "adminer"}, services)
+}
+
+type fakeTagLister struct {
+	tags  map[string][]string
+	calls int
+}
+
+func (f *fakeTagLister) Tags(ctx context.Context, ref registry.Reference) ([]string, error) {
+	f.calls++
+	tags, ok := f.tags[ref.Repository]
+	if !ok {
+		return nil, errors.New("repository not found")
+	}
+	return tags, nil
+}
+
+func TestPlanImageUpdates(t *testing.T) {
+	lister := &fakeTagLister{tags: map[string][]string{
+		"library/postgres": {"15-alpine", "16-alpine", "16.1-alpine", "16"},
+		"library/redis":    {"7-alpine", "7.2-alpine"},
+		"minio/minio":      {"latest"},
+	}}
+	images := []serviceImage{
+		{Service: "postgres", Image: "postgres:15-alpine"},
+		{Service: "redis", Image: "redis:7.2-alpine", Pinned: true},
+		{Service: "minio", Image: "minio/minio:latest"},
+		{Service: "postgres-replica", Image: "postgres:15-alpine"},
+		{Service: "private", Image: "registry.example.com/team/app:1.0"},
+	}
+
+	changes, failures := planImageUpdates(context.Background(), newTagLookup(lister), images)
+
+	assert.Equal(t, []imageChange{
+		{Service: "postgres", From: "postgres:15-alpine", To: "postgres:16-alpine"},
+		{Service: "postgres-replica", From: "postgres:15-alpine", To: "postgres:16-alpine"},
+	}, changes)
+	require.Len(t, failures, 1)
+	assert.Contains(t, failures[0].Error(), "registry.example.com/team/app:1.0")
+	assert.Equal(t, 4, lister.calls, "tags are listed once per repository")
+}
+
+func TestServiceImage(t *testing.T) {
+	cfg := &ProjectConfig{Services: types.ServicesConfig{"postgres": {Version: "16-alpine"}}}
+	assert.Equal(t, "postgres:16-alpine", ServiceImage(cfg, "postgres"))
+	assert.Equal(t, "redis:7-alpine", ServiceImage(&ProjectConfig{}, "redis"))
+	assert.Empty(t, ServiceImage(cfg, "missing"))
+}
+
+func TestAppendImageChangelog(t *testing.T) {
+	path := filepath.Join(t.TempDir(), constants.ImageChangelogFileName)
+	first := time.Date(2026, 3, 1, 9, 30, 0, 0, time.UTC)
+
+	require.NoError(t, appendImageChangelog(path, []imageChange{{Service: "postgres", From: "postgres:15-alpine", To: "postgres:16-alpine"}}, first))
+	require.NoError(t, appendImageChangelog(path, []imageChange{{Service: "redis", From: "redis:7-alpine", To: "redis:8-alpine"}}, first.Add(time.Hour)))
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, `# Image changes
+
+## 2026-03-01 09:30
+
+- postgres: postgres:15-alpine -> postgres:16-alpine
+
+## 2026-03-01 10:30
+
+- redis: redis:7-alpine -> redis:8-alpine
+`, string(data))
+}
+
+func TestParseThresholds(t *testing.T) {
+	thresholds, err := parseThresholds("cpu=80, memory=90%,pids=500")
+	require.NoError(t, err)
+	assert.Equal(t, topThresholds{CPUPercent: 80, MemoryPercent: 90, PIDs: 500}, thresholds)
+
+	thresholds, err = parseThresholds("memory=512m")
+	require.NoError(t, err)
+	assert.Equal(t, topThresholds{MemoryBytes: 512 << 20}, thresholds)
+
+	thresholds, err = parseThresholds("")
+	require.NoError(t, err)
+	assert.Equal(t, topThresholds{}, thresholds)
+
+	for _, spec := range []string{"cpu", "cpu=-1", "memory=lots", "pids=0", "disk=10"} {
+		_, err := parseThresholds(spec)
+		assert.Error(t, err, spec)
+	}
+}
+
+func TestTopThresholds_Exceeded(t *testing.T) {
+	stats := []types.ServiceStats{
+		{Container: "demo-postgres", CPUPercent: 93.5, Memory: types.MemoryUsage{Used: 400 << 20, Limit: 512 << 20}, PIDs: 20},
+		{Container: "demo-redis", CPUPercent: 1.2, Memory: types.MemoryUsage{Used: 10 << 20, Limit: 256 << 20}, PIDs: 600},
+	}
+
+	thresholds := topThresholds{CPUPercent: 80, MemoryPercent: 75, PIDs: 500}
+	assert.Equal(t, []string{
+		"demo-postgres: CPU 93.50% exceeds 80%",
+		"demo-postgres: memory 78.12% of its limit exceeds 75%",
+		"demo-redis: 600 processes exceed 500",
+	}, thresholds.exceeded(stats))
+
+	assert.Equal(t, []string{"demo-postgres: memory 400MiB exceeds 256MiB"}, topThresholds{MemoryBytes: 256 << 20}.exceeded(stats))
+	assert.Empty(t, topThresholds{}.exceeded(stats))
+}
+
+func TestSortStats(t *testing.T) {
+	stats := []types.ServiceStats{
+		{Container: "demo-redis", CPUPercent: 5, Memory: types.MemoryUsage{Used: 30}, NetworkRx: 10},
+		{Container: "demo-kafka", CPUPercent: 1, Memory: types.MemoryUsage{Used: 90}, NetworkRx: 5},
+		{Container: "demo-postgres", CPUPercent: 20, Memory: types.MemoryUsage{Used: 60}, NetworkTx: 50},
+	}
+	containers := func() []string {
+		var names []string
+		for _, s := range stats {
+			names = append(names, s.Container)
+		}
+		return names
+	}
+
+	sortStats(stats, topSortCPU)
+	assert.Equal(t, []string{"demo-postgres", "demo-redis", "demo-kafka"}, containers())
+	sortStats(stats, topSortMemory)
+	assert.Equal(t, []string{"demo-kafka", "demo-postgres", "demo-redis"}, containers())
+	sortStats(stats, topSortNet)
+	assert.Equal(t, []string{"demo-postgres", "demo-redis", "demo-kafka"}, containers())
+	sortStats(stats, topSortName)
+	assert.Equal(t, []string{"demo-kafka", "demo-postgres", "demo-redis"}, containers())
+}
+
+func TestWriteStats_JSON(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, writeStats(&buf, "json", nil, nil))
+	assert.JSONEq(t, `{"services": [], "exceeded": []}`, buf.String())
+}
+
+func TestWriteDrift(t *testing.T) {
+	drifts := []types.ServiceDrift{
+		{Service: "redis", Status: types.DriftChanged, Changes: []types.DriftChange{
+			{Field: "image", Expected: "redis:7.2", Actual: "redis:7.0"},
+			{Field: "env.REDIS_ARGS", Expected: "--appendonly yes"},
+		}},
+		{Service: "worker", Status: types.DriftOrphaned},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, writeDrift(&buf, "table", drifts))
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 4)
+	assert.Equal(t, []string{"redis", "changed", "image", "redis:7.2", "redis:7.0"}, strings.Fields(lines[1]))
+	assert.Equal(t, []string{"env.REDIS_ARGS", "--appendonly", "yes", "-"}, strings.Fields(lines[2]))
+	assert.Equal(t, []string{"worker", "orphaned"}, strings.Fields(lines[3]))
+
+	buf.Reset()
+	require.NoError(t, writeDrift(&buf, "json", nil))
+	assert.JSONEq(t, `[]`, buf.String())
+}
+
+func TestSplitExecArgs(t *testing.T) {
+	service, command := splitExecArgs([]string{"postgres", "--", "psql", "-U", "postgres"})
+	assert.Equal(t, "postgres", service)
+	assert.Equal(t, []string{"psql", "-U", "postgres"}, command)
+
+	service, command = splitExecArgs([]string{"redis", "redis-cli", "--", "ping"})
+	assert.Equal(t, "redis", service)
+	assert.Equal(t, []string{"redis-cli", "--", "ping"}, command)
+
+	service, command = splitExecArgs(nil)
+	assert.Empty(t, service)
+	assert.Empty(t, command)
+}
+
+func TestExecOptions(t *testing.T) {
+	options := execOptions("root", "/app", "A=1, B=2", true, true, false, true)
+	assert.Equal(t, types.ExecOptions{
+		User:        "root",
+		WorkingDir:  "/app",
+		Env:         []string{"A=1", "B=2"},
+		Interactive: true,
+		TTY:         true,
+	}, options)
+
+	// Scripts get no TTY so stdout and stderr stay separate
+	options = execOptions("", "", "", true, true, false, false)
+	assert.True(t, options.Interactive)
+	assert.False(t, options.TTY)
+
+	options = execOptions("", "", "", true, true, true, true)
+	assert.Equal(t, types.ExecOptions{Detach: true}, options)
+}
+
+func TestJaegerPage(t *testing.T) {
+	assert.Equal(t, "http://localhost:16686/search", jaegerPage("http://localhost:16686", "", ""))
+	assert.Equal(t, "http://jaeger.localhost/search?service=orders+api", jaegerPage("http://jaeger.localhost", "orders api", ""))
+	assert.Equal(t, "http://localhost:16686/trace/4bf92f35", jaegerPage("http://localhost:16686", "", "4bf92f35"))
+}
+
+func TestReadsCollectedLogs(t *testing.T) {
+	cfg := &ProjectConfig{}
+	cfg.Stack.Enabled = []string{"postgres", "log-collector"}
+
+	collected, err := readsCollectedLogs("auto", cfg, false)
+	assert.NoError(t, err)
+	assert.True(t, collected)
+	collected, err = readsCollectedLogs("auto", cfg, true)
+	assert.NoError(t, err)
+	assert.False(t, collected)
+	_, err = readsCollectedLogs("collector", cfg, true)
+	assert.Error(t, err)
+
+	cfg.Stack.Enabled = []string{"postgres"}
+	collected, err = readsCollectedLogs("auto", cfg, false)
+	assert.NoError(t, err)
+	assert.False(t, collected)
+	_, err = readsCollectedLogs("collector", cfg, false)
+	assert.EqualError(t, err, "service log-collector is not enabled in this project")
+	_, err = readsCollectedLogs("files", cfg, false)
+	assert.Error(t, err)
+}
+
+func TestParseSince(t *testing.T) {
+	now := time.Date(2026, 10, 14, 12, 0, 0, 0, time.UTC)
+	since, err := parseSince("2d", now)
+	assert.NoError(t, err)
+	assert.Equal(t, now.Add(-48*time.Hour), since)
+
+	since, err = parseSince("2026-10-13T08:00:00Z", now)
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2026, 10, 13, 8, 0, 0, 0, time.UTC), since.UTC())
+
+	since, err = parseSince("", now)
+	assert.NoError(t, err)
+	assert.True(t, since.IsZero())
+
+	_, err = parseSince("yesterday", now)
+	assert.Error(t, err)
+}
+
+func TestWriteEvent(t *testing.T) {
+	at := time.Date(2026, 10, 14, 12, 0, 0, 0, time.UTC)
+	code := 137
+	var buf bytes.Buffer
+	require.NoError(t, writeEvent(&buf, types.ServiceEvent{Time: at, Service: "postgres", Action: types.EventDie, ExitCode: &code}, "text"))
+	require.NoError(t, writeEvent(&buf, types.ServiceEvent{Time: at, Service: "redis", Action: types.EventHealthStatus, Health: "unhealthy"}, "text"))
+	require.NoError(t, writeEvent(&buf, types.ServiceEvent{Time: at, Service: "redis", Action: types.EventStart}, "text"))
+	assert.Equal(t, "2026-10-14 12:00:00  postgres         die           exit code 137\n"+
+		"2026-10-14 12:00:00  redis            health_status unhealthy\n"+
+		"2026-10-14 12:00:00  redis            start\n", buf.String())
+
+	buf.Reset()
+	require.NoError(t, writeEvent(&buf, types.ServiceEvent{Time: at, Project: "shop", Service: "postgres", Container: "shop-postgres-1", Action: types.EventOOM}, "json"))
+	assert.JSONEq(t, `{"time":"2026-10-14T12:00:00Z","project":"shop","service":"postgres","container":"shop-postgres-1","action":"oom"}`, buf.String())
+}
+
+func TestParseEventFilter(t *testing.T) {
+	actions, err := parseEventFilter("die, oom")
+	require.NoError(t, err)
+	assert.Equal(t, []string{types.EventDie, types.EventOOM}, actions)
+
+	actions, err = parseEventFilter("")
+	require.NoError(t, err)
+	assert.Empty(t, actions)
+
+	_, err = parseEventFilter("die,restart")
+	assert.EqualError(t, err, `unknown event "restart" (supported: start, die, health_status, oom, pause, unpause)`)
+}
+
+// recordingNotifier records the alerts it is asked to show
+type recordingNotifier struct {
+	alerts []events.Alert
+	err    error
+}
+
+func (n *recordingNotifier) Notify(alert events.Alert) error {
+	n.alerts = append(n.alerts, alert)
+	return n.err
+}
+
+func TestEventAlerts(t *testing.T) {
+	notifier := &recordingNotifier{}
+	alerts := newEventAlerts(types.NotificationsConfig{Mute: []string{"redis"}}, notifier)
+	alerts.observe(types.ServiceEvent{Service: "postgres", Action: types.EventStart})
+	alerts.observe(types.ServiceEvent{Service: "redis", Action: types.EventOOM})
+	alerts.observe(types.ServiceEvent{Service: "postgres", Action: types.EventOOM})
+
+	notifier.err = errors.New("notify-send not found")
+	alerts.observe(types.ServiceEvent{Service: "postgres", Action: types.EventHealthStatus, Health: "unhealthy"})
+
+	require.Len(t, notifier.alerts, 2)
+	assert.Equal(t, "postgres ran out of memory", notifier.alerts[0].Title)
+	assert.Equal(t, "postgres is unhealthy", notifier.alerts[1].Title)
+}
+
+func TestToDisplayStatuses_CrashLoop(t *testing.T) {
+	statuses := []types.ServiceStatus{
+		{Name: "api", State: types.ServiceStateRunning, Labels: map[string]string{constants.ComposeNumberLabel: "1"}},
+		{Name: "api", State: types.ServiceStateCrashLooping, RestartCount: 7, ExitCode: 137, Labels: map[string]string{constants.ComposeNumberLabel: "2"}},
+		{Name: "db", State: types.ServiceStateCrashLooping, RestartCount: 4, ExitCode: 1},
+	}
+
+	rows := toDisplayStatuses(statuses)
+	assert.Len(t, rows, 2)
+	assert.Equal(t, "crash-looping", rows[0].State)
+	assert.Equal(t, 7, rows[0].Restarts)
+	assert.Equal(t, 137, rows[0].ExitCode)
+	assert.Equal(t, 1, rows[0].Running)
+	assert.Equal(t, 4, rows[1].Restarts)
+}
+
+func TestExecutionPlans(t *testing.T) {
+	t.Chdir(t.TempDir())
+	configPath := filepath.Join(constants.DevStackDir, constants.ConfigFileName)
+	cfg := &ProjectConfig{}
+	cfg.Project.Name = "demo"
+
+	_, err := downPlan(cfg, configPath, nil, false)
+	assert.ErrorContains(t, err, "generate compose")
+
+	require.NoError(t, os.MkdirAll(constants.DevStackDir, 0755))
+	require.NoError(t, os.WriteFile(constants.DockerComposeFile, []byte(`services:
+  db:
+    image: db:1
+    ports: ["5432:5432"]
+    volumes: [dbdata:/data]
+  api:
+    build: .
+    depends_on: [db]
+volumes:
+  dbdata: {}
+`), 0644))
+
+	plan, err := upPlan(cfg, configPath, []string{"api"}, false, false, false)
+	require.NoError(t, err)
+	assert.Equal(t, []PlanStep{
+		{Action: planBuild, Resource: planImages, Names: []string{"demo-api"}, Note: "unless already built"},
+		{Action: planCreate, Resource: planNetworks, Names: []string{"demo_default"}, Note: "unless they exist"},
+		{Action: planCreate, Resource: planVolumes, Names: []string{"demo_dbdata"}, Note: "unless they exist"},
+		{Action: planStart, Resource: planServices, Names: []string{"db"}, Ports: map[string][]string{"db": {"5432:5432"}}},
+		{Action: planStart, Resource: planServices, Names: []string{"api"}, Ports: map[string][]string{}},
+	}, plan.Steps)
+
+	plan, err = downPlan(cfg, configPath, nil, true)
+	require.NoError(t, err)
+	var out bytes.Buffer
+	require.NoError(t, WritePlan(&out, false, plan))
+	assert.Equal(t, `Dry run: dev-stack down would, for project demo:
+
+  1. Stop services (their containers are removed)
+       api
+  2. Stop services (their containers are removed)
+       db
+  3. Remove volumes
+       demo_dbdata
+  4. Remove networks
+       demo_default
+
+Nothing was changed. Run without --dry-run to carry out the plan.
+`, out.String())
+
+	plan, err = CleanupPlan(cfg, configPath, types.CleanupOptions{RemoveImages: true})
+	require.NoError(t, err)
+	require.Len(t, plan.Steps, 3)
+	assert.Equal(t, PlanStep{Action: planRemove, Resource: planImages, Names: []string{"demo-api"}}, plan.Steps[2])
+}
+
+func TestPruneHandler(t *testing.T) {
+	h := NewPruneHandler()
+	assert.NoError(t, h.ValidateArgs([]string{types.PruneBackups, types.PruneVersions}))
+	assert.ErrorContains(t, h.ValidateArgs([]string{"volumes"}), `unknown category "volumes"`)
+
+	assert.Equal(t, "shop-postgres-1 (shop)", describeReclaimable(types.Reclaimable{Category: types.PruneContainers, Name: "shop-postgres-1", Detail: "shop"}))
+	assert.Equal(t, "4f2c9e1a77aa (docker.io/library/postgres)", describeReclaimable(types.Reclaimable{Category: types.PruneImages, Name: "sha256:4f2c9e1a77aa0b3d", Detail: "docker.io/library/postgres"}))
+	assert.Equal(t, "backups/shop-postgres-20260101.sql", describeReclaimable(types.Reclaimable{Category: types.PruneBackups, Name: "backups/shop-postgres-20260101.sql", Detail: "shop"}))
+
+	report := pruneReport{Categories: []pruneCategory{
+		{Category: types.PruneBackups, Count: 1, Size: 2048, Items: []types.Reclaimable{{Category: types.PruneBackups, Name: "backups/shop-postgres-20260101.sql", Size: 2048}}},
+		{Category: types.PruneContainers, Note: "Docker isn't running"},
+	}, Size: 2048}
+	var out bytes.Buffer
+	writePruneReport(&out, report, "7d", true)
+	assert.Contains(t, out.String(), "backups of the project older than 7d")
+	assert.Contains(t, out.String(), "(skipped: Docker isn't running)")
+	assert.Contains(t, out.String(), "  backups/shop-postgres-20260101.sql  2.0 KB")
+	assert.True(t, hasReclaimable(report))
+}
+
+func TestStackConnectivity(t *testing.T) {
+	networks := map[string][]string{
+		"app":        {"app--postgres"},
+		"postgres":   {"app--postgres"},
+		"localstack": {"localstack"},
+	}
+
+	connectivity := stackConnectivity(networks, nil)
+	assert.Equal(t, []serviceConnectivity{
+		{Service: "app", Reaches: []string{"postgres"}, Networks: []string{"app--postgres"}},
+		{Service: "localstack", Reaches: []string{}, Networks: []string{"localstack"}},
+		{Service: "postgres", Reaches: []string{"app"}, Networks: []string{"app--postgres"}},
+	}, connectivity)
+	assert.Len(t, stackConnectivity(networks, []string{"postgres"}), 1)
+
+	var out bytes.Buffer
+	require.NoError(t, writeConnectivity(&out, connectivity))
+	assert.Contains(t, out.String(), "localstack  -          localstack")
+}
+
+func TestParseWorkflowArgs(t *testing.T) {
+	values, help, err := parseWorkflowArgs([]string{"--database", "orders", "--seed=small"})
+	require.NoError(t, err)
+	assert.False(t, help)
+	assert.Equal(t, map[string]string{"database": "orders", "seed": "small"}, values)
+
+	_, help, err = parseWorkflowArgs([]string{"--database", "orders", "--help"})
+	require.NoError(t, err)
+	assert.True(t, help)
+
+	_, _, err = parseWorkflowArgs([]string{"--database"})
+	assert.EqualError(t, err, "parameter --database needs a value")
+	_, _, err = parseWorkflowArgs([]string{"orders"})
+	assert.ErrorContains(t, err, `unexpected argument "orders"`)
+}
+
+func TestWorkflows(t *testing.T) {
+	workflows, err := Workflows(nil)
+	require.NoError(t, err)
+	require.Contains(t, workflows, "cleanup-reset")
+	assert.Equal(t, types.HookStep{Name: "Stop services and remove data", Command: "down --volumes"}, workflows["cleanup-reset"].Steps[0])
+
+	cfg := &ProjectConfig{Workflows: types.WorkflowsConfig{
+		"cleanup-reset": {Description: "Project reset", Steps: []types.HookStep{{Command: "down"}}},
+		"reset-db":      {Steps: []types.HookStep{{Run: "echo {{.database}}"}}},
+	}}
+	workflows, err = Workflows(cfg)
+	require.NoError(t, err)
+	assert.Equal(t, "Project reset", workflows["cleanup-reset"].Description)
+	assert.Contains(t, workflows, "reset-db")
+	assert.Contains(t, workflows, "quick-start")
+}
+
+func TestProjectTasks(t *testing.T) {
+	t.Chdir(t.TempDir())
+	require.NoError(t, os.WriteFile("Makefile", []byte("build: ## Build it\n\tgo build ./...\ntest:\n\tgo test ./...\n"), 0644))
+
+	tasks, err := ProjectTasks(&ProjectConfig{
+		TaskImports: []string{"Makefile"},
+		Tasks:       types.TasksConfig{"test": {Command: "make test", DependsOn: []string{"postgres"}}},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, types.TasksConfig{
+		"build": {Description: "Build it", Command: "make build"},
+		"test":  {Command: "make test", DependsOn: []string{"postgres"}},
+	}, tasks)
+
+	_, err = ProjectTasks(&ProjectConfig{TaskImports: []string{"justfile"}})
+	assert.ErrorContains(t, err, "failed to import tasks")
+}
+
+func TestRunsAsUp(t *testing.T) {
+	enabled := []string{"postgres", "redis"}
+	projectTasks := types.TasksConfig{"test": {Command: "go test ./..."}, "redis": {Command: "redis-cli"}}
+
+	assert.True(t, runsAsUp(nil, nil, enabled))
+	assert.True(t, runsAsUp([]string{"postgres"}, projectTasks, enabled))
+	assert.False(t, runsAsUp(nil, projectTasks, enabled))
+	assert.False(t, runsAsUp([]string{"test"}, projectTasks, enabled))
+	// Tasks win over services of the same name
+	assert.False(t, runsAsUp([]string{"postgres", "redis"}, projectTasks, enabled))
+	assert.False(t, runsAsUp([]string{"kafka"}, nil, enabled))
+}
+
+func TestJobSummary(t *testing.T) {
+	summary := jobSummary("dev-stack up: demo", []types.ServiceStatus{
+		{Name: "postgres", State: types.ServiceStateRunning, Health: types.HealthStatusHealthy, Ports: []types.PortMapping{{Host: "5432", Container: "5432"}}},
+		{Name: "worker|1", State: types.ServiceStateStopped, Health: types.HealthStatusNone},
+	})
+	assert.Equal(t, "### dev-stack up: demo\n\n"+
+		"| Service | State | Health | Ports |\n"+
+		"| --- | --- | --- | --- |\n"+
+		"| postgres | running | healthy | 5432:5432 |\n"+
+		"| worker\\|1 | exited | none |  |\n", summary)
+
+	assert.Equal(t, "### dev-stack down: demo\n\nNo services are running.\n", jobSummary("dev-stack down: demo", nil))
+}
+
+func TestShutdownSettings(t *testing.T) {
+	cfg := &ProjectConfig{}
+	cfg.Stack.Enabled = []string{"redis", "postgres"}
+	cfg.Shutdown = map[string]types.ShutdownConfig{
+		"postgres": {GracePeriod: "5s"},
+		"api":      {PreStop: [][]string{{"kill", "-USR1", "1"}}},
+	}
+	settings, err := shutdownSettings(cfg)
+	require.NoError(t, err)
+	assert.Equal(t, "30s", settings["redis"].GracePeriod)
+	assert.NotEmpty(t, settings["redis"].PreStop)
+	assert.Equal(t, types.ShutdownConfig{GracePeriod: "5s"}, settings["postgres"])
+	assert.Equal(t, [][]string{{"kill", "-USR1", "1"}}, settings["api"].PreStop)
+
+	cfg.Shutdown["postgres"] = types.ShutdownConfig{GracePeriod: "soon"}
+	_, err = shutdownSettings(cfg)
+	assert.ErrorContains(t, err, `invalid shutdown grace period "soon" for postgres`)
+}
+
+func TestReportShutdown(t *testing.T) {
+	report := &types.ShutdownReport{Services: []types.ServiceShutdown{
+		{Service: "api", Result: types.ShutdownKilled},
+		{Service: "postgres", Result: types.ShutdownRunning},
+	}}
+	assert.EqualError(t, reportShutdown(report), "services still running after their grace period: postgres; run with --force to kill them")
+
+	report.Services[1].Result = types.ShutdownStopped
+	assert.NoError(t, reportShutdown(report))
+}
+
+func TestWaitPortsReleased(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	address := listener.Addr().String()
+	time.AfterFunc(200*time.Millisecond, func() { _ = listener.Close() })
+
+	waitPortsReleased(context.Background(), []string{address}, time.Minute)
+	free, err := net.Listen("tcp", address)
+	require.NoError(t, err)
+	_ = free.Close()
+}
+
+func TestWakeIdle_NothingAsleep(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	woken, err := WakeIdle(context.Background(), nil, nil)
+	require.NoError(t, err)
+	assert.Empty(t, woken)
+	assert.NoFileExists(t, projectstate.Path(), "waking nothing wrote a state file")
+}
+
+func TestWriteBenchReport(t *testing.T) {
+	report := &types.BenchReport{Runs: 3, Total: 8 * time.Second, Services: []types.BenchTiming{
+		{Service: "postgres", Pull: 120 * time.Millisecond, Create: 80 * time.Millisecond, Start: 1500 * time.Millisecond, Healthy: 4 * time.Second},
+		{Service: "api", Start: 700 * time.Millisecond, Healthy: 1200 * time.Millisecond},
+	}}
+	baseline := &types.BenchReport{Total: 7 * time.Second, Services: []types.BenchTiming{
+		{Service: "postgres", Pull: 100 * time.Millisecond, Create: 80 * time.Millisecond, Start: time.Second, Healthy: 5 * time.Second},
+	}}
+
+	var out bytes.Buffer
+	require.NoError(t, writeBenchReport(&out, report, baseline, "dev-stack/bench/base.json", nil))
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	require.Len(t, lines, 3)
+	assert.Equal(t, []string{"SERVICE", "PULL", "CREATE", "START", "HEALTHY", "TOTAL"}, strings.Fields(lines[0]))
+	assert.Equal(t, "postgres 120ms (+20ms) 80ms (+0s) 1.5s (+500ms) 4s (-1s) 5.7s (-480ms)", strings.Join(strings.Fields(lines[1]), " "))
+	assert.Equal(t, "api 0s 0s 700ms 1.2s 1.9s", strings.Join(strings.Fields(lines[2]), " "))
+}
@@ -0,0 +1,155 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/isaacgarza/dev-stack/internal/core/daemon"
+	"github.com/isaacgarza/dev-stack/internal/core/services"
+	cliTypes "github.com/isaacgarza/dev-stack/internal/pkg/cli/types"
+	"github.com/isaacgarza/dev-stack/internal/pkg/constants"
+	"github.com/isaacgarza/dev-stack/internal/pkg/ui"
+	"github.com/isaacgarza/dev-stack/internal/pkg/utils"
+	"github.com/spf13/cobra"
+)
+
+// DaemonHandler handles the daemon command, serving the service manager
+// operations over a local HTTP API
+type DaemonHandler struct {
+	manager *services.Manager
+}
+
+// NewDaemonHandler creates a new daemon handler
+func NewDaemonHandler(manager *services.Manager) *DaemonHandler {
+	return &DaemonHandler{manager: manager}
+}
+
+// Handle executes the daemon command
+func (h *DaemonHandler) Handle(ctx context.Context, cmd *cobra.Command, args []string, base *cliTypes.BaseCommand) error {
+	listen, _ := cmd.Flags().GetString("listen")
+	token, _ := cmd.Flags().GetString("token")
+
+	configPath := filepath.Join(constants.DevStackDir, constants.ConfigFileName)
+	if !utils.FileExists(configPath) {
+		return errors.New(constants.ErrNotInitialized)
+	}
+	cfg, err := LoadProjectConfig(configPath)
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	stateFile := daemon.StateFile()
+	if state, err := daemon.ReadState(stateFile); err == nil && daemonResponds(state.Addr) {
+		return fmt.Errorf("a daemon is already running on %s (pid %d)", state.Addr, state.PID)
+	}
+
+	if token == "" {
+		token = os.Getenv(constants.EnvDaemonToken)
+	}
+	if token == "" {
+		if token, err = daemon.GenerateToken(); err != nil {
+			return err
+		}
+	}
+
+	listener, err := net.Listen("tcp", listen)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", listen, err)
+	}
+	addr := listener.Addr().String()
+	if tcpAddr, ok := listener.Addr().(*net.TCPAddr); ok && !tcpAddr.IP.IsLoopback() {
+		ui.Warning("Listening on %s exposes the API beyond this machine; anyone with the token can control the stack", addr)
+	}
+
+	if err := daemon.WriteState(stateFile, daemon.State{
+		Addr:      addr,
+		Token:     token,
+		PID:       os.Getpid(),
+		Project:   cfg.Project.Name,
+		StartedAt: time.Now().UTC(),
+	}); err != nil {
+		_ = listener.Close()
+		return err
+	}
+	defer func() {
+		if err := os.Remove(stateFile); err != nil && !os.IsNotExist(err) {
+			base.Logger.Error("Failed to remove daemon state", "error", err)
+		}
+	}()
+
+	h.manager.SetProjectName(cfg.Project.Name)
+	h.manager.SetCrashLoopPolicy(cfg.CrashLoop)
+	if err := ConfigureShutdown(h.manager, cfg); err != nil {
+		return err
+	}
+	logger := base.Logger.(loggerAdapter)
+	server := &http.Server{
+		Handler:           daemon.NewServer(h.manager, cfg.Project.Name, token, logger.SlogLogger()).Handler(),
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- server.Serve(listener)
+	}()
+
+	if cfg.Events.Notifications.Enabled {
+		dockerClient, err := newImagesDockerClient(base)
+		if err != nil {
+			return err
+		}
+		defer func() {
+			if err := dockerClient.Close(); err != nil {
+				base.Logger.Error("Failed to close Docker client", "error", err)
+			}
+		}()
+		go notifyOnEvents(ctx, dockerClient, cfg)
+		ui.Info("Showing desktop notifications when services fail")
+	}
+
+	ui.Info("Serving the dev-stack API of %s on http://%s/%s", cfg.Project.Name, addr, daemon.APIVersion)
+	ui.Info("Clients read the address and token from %s", stateFile)
+
+	select {
+	case err := <-errCh:
+		if !errors.Is(err, http.ErrServerClosed) {
+			return fmt.Errorf("daemon failed: %w", err)
+		}
+		return nil
+	case <-ctx.Done():
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		// Log streams never finish on their own, so close what is left
+		if err := server.Shutdown(shutdownCtx); err != nil {
+			return server.Close()
+		}
+		return nil
+	}
+}
+
+// ValidateArgs validates the command arguments
+func (h *DaemonHandler) ValidateArgs(args []string) error {
+	return nil
+}
+
+// GetRequiredFlags returns required flags for this command
+func (h *DaemonHandler) GetRequiredFlags() []string {
+	return []string{}
+}
+
+// daemonResponds reports whether a daemon answers its health route on addr
+func daemonResponds(addr string) bool {
+	client := &http.Client{Timeout: time.Second}
+	resp, err := client.Get("http://" + addr + "/" + daemon.APIVersion + "/health")
+	if err != nil {
+		return false
+	}
+	_ = resp.Body.Close()
+	return resp.StatusCode == http.StatusOK
+}
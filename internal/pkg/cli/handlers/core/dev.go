@@ -0,0 +1,225 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"text/tabwriter"
+	"time"
+
+	"github.com/isaacgarza/dev-stack/internal/core/docker"
+	"github.com/isaacgarza/dev-stack/internal/core/services"
+	"github.com/isaacgarza/dev-stack/internal/core/watch"
+	cliTypes "github.com/isaacgarza/dev-stack/internal/pkg/cli/types"
+	"github.com/isaacgarza/dev-stack/internal/pkg/constants"
+	"github.com/isaacgarza/dev-stack/internal/pkg/types"
+	"github.com/isaacgarza/dev-stack/internal/pkg/ui"
+	"github.com/isaacgarza/dev-stack/internal/pkg/utils"
+	"github.com/spf13/cobra"
+)
+
+// DevHandler handles the dev command
+type DevHandler struct {
+	manager *services.Manager
+}
+
+// devStats records the actions run for a service during a dev session
+type devStats struct {
+	runs     map[string]int
+	failures int
+	rebuilds []time.Duration
+}
+
+// NewDevHandler creates a new dev handler
+func NewDevHandler(manager *services.Manager) *DevHandler {
+	return &DevHandler{manager: manager}
+}
+
+// Handle executes the dev command
+func (h *DevHandler) Handle(ctx context.Context, cmd *cobra.Command, args []string, base *cliTypes.BaseCommand) error {
+	configPath := filepath.Join(constants.DevStackDir, constants.ConfigFileName)
+	if !utils.FileExists(configPath) {
+		return errors.New(constants.ErrNotInitialized)
+	}
+
+	cfg, err := LoadProjectConfig(configPath)
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	rules, err := devRules(cfg, args)
+	if err != nil {
+		return err
+	}
+
+	debounceValue, _ := cmd.Flags().GetString("debounce")
+	if debounceValue == "" {
+		debounceValue = cfg.Dev.Debounce
+	}
+	debounce := watch.DefaultDebounce
+	if debounceValue != "" {
+		if debounce, err = time.ParseDuration(debounceValue); err != nil {
+			return fmt.Errorf("invalid debounce %q: %w", debounceValue, err)
+		}
+	}
+
+	var serviceNames []string
+	for _, rule := range rules {
+		if !slices.Contains(serviceNames, rule.Service) {
+			serviceNames = append(serviceNames, rule.Service)
+		}
+	}
+
+	ui.Header("Starting dev mode")
+
+	h.manager.SetProjectName(cfg.Project.Name)
+	if err := h.manager.StartServices(ctx, serviceNames, types.StartOptions{Detach: true}); err != nil {
+		return err
+	}
+
+	watcher, err := watch.New(rules, debounce)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = watcher.Close() }()
+
+	for _, rule := range rules {
+		ui.Info("%s: %s on changes in %s", rule.Service, rule.Action, relativePath(rule.Path))
+	}
+	ui.Muted("Watching for changes, press Ctrl+C to stop")
+
+	stats := make(map[string]*devStats, len(serviceNames))
+	for _, serviceName := range serviceNames {
+		stats[serviceName] = &devStats{runs: map[string]int{}}
+	}
+
+	err = watcher.Run(ctx, func(batches []watch.Batch) {
+		for _, batch := range batches {
+			h.apply(ctx, batch, stats[batch.Service])
+		}
+	})
+
+	printDevSummary(serviceNames, stats)
+	return err
+}
+
+// apply runs one batch and records its outcome
+func (h *DevHandler) apply(ctx context.Context, batch watch.Batch, stats *devStats) {
+	ui.Info("%s: %d file(s) changed, running %s", batch.Service, len(batch.Paths), batch.Action)
+
+	start := time.Now()
+	err := h.manager.ApplyWatchBatch(ctx, batch)
+	elapsed := time.Since(start).Round(10 * time.Millisecond)
+	if err != nil {
+		if ctx.Err() == nil {
+			stats.failures++
+			ui.Error("%s: %s failed after %s: %v", batch.Service, batch.Action, elapsed, err)
+		}
+		return
+	}
+
+	stats.runs[batch.Action]++
+	if batch.Action == types.WatchActionRebuild {
+		stats.rebuilds = append(stats.rebuilds, elapsed)
+	}
+	ui.Success("%s: %s done in %s", batch.Service, batch.Action, elapsed)
+}
+
+// devRules collects the watch rules from the develop.watch sections of the
+// compose files and the project's dev.watch section, limited to serviceNames
+func devRules(cfg *ProjectConfig, serviceNames []string) ([]watch.Rule, error) {
+	var rules []watch.Rule
+	for _, composeFile := range docker.ComposeFiles() {
+		if !utils.FileExists(composeFile) {
+			continue
+		}
+		composeRules, err := watch.LoadCompose(composeFile)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, composeRules...)
+	}
+
+	configRules, err := watch.FromConfig(cfg.Dev.Watch, ".")
+	if err != nil {
+		return nil, err
+	}
+	rules = append(rules, configRules...)
+
+	if err := watch.Validate(rules); err != nil {
+		return nil, err
+	}
+
+	if len(serviceNames) > 0 {
+		var selected []watch.Rule
+		for _, serviceName := range serviceNames {
+			found := false
+			for _, rule := range rules {
+				if rule.Service == serviceName {
+					selected = append(selected, rule)
+					found = true
+				}
+			}
+			if !found {
+				return nil, fmt.Errorf("no watch rules for %s", serviceName)
+			}
+		}
+		rules = selected
+	}
+
+	if len(rules) == 0 {
+		return nil, fmt.Errorf("no watch rules found: add develop.watch to %s or dev.watch to %s", constants.DockerComposeFile, filepath.Join(constants.DevStackDir, constants.ConfigFileName))
+	}
+	return rules, nil
+}
+
+// printDevSummary prints the actions run per service and rebuild times
+func printDevSummary(serviceNames []string, stats map[string]*devStats) {
+	fmt.Println()
+	ui.Header("Dev session summary")
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "SERVICE\tSYNCS\tRESTARTS\tREBUILDS\tAVG REBUILD\tLAST REBUILD\tFAILURES")
+	for _, serviceName := range serviceNames {
+		s := stats[serviceName]
+		average, last := "-", "-"
+		if n := len(s.rebuilds); n > 0 {
+			var total time.Duration
+			for _, d := range s.rebuilds {
+				total += d
+			}
+			average = (total / time.Duration(n)).Round(10 * time.Millisecond).String()
+			last = s.rebuilds[n-1].String()
+		}
+		_, _ = fmt.Fprintf(w, "%s\t%d\t%d\t%d\t%s\t%s\t%d\n", serviceName,
+			s.runs[types.WatchActionSync]+s.runs[types.WatchActionSyncRestart],
+			s.runs[types.WatchActionRestart]+s.runs[types.WatchActionSyncRestart],
+			len(s.rebuilds), average, last, s.failures)
+	}
+	_ = w.Flush()
+}
+
+// relativePath shortens a path to be relative to the working directory
+func relativePath(path string) string {
+	wd, err := os.Getwd()
+	if err != nil {
+		return path
+	}
+	if rel, err := filepath.Rel(wd, path); err == nil {
+		return rel
+	}
+	return path
+}
+
+// ValidateArgs validates the command arguments
+func (h *DevHandler) ValidateArgs(args []string) error {
+	return nil
+}
+
+// GetRequiredFlags returns required flags for this command
+func (h *DevHandler) GetRequiredFlags() []string {
+	return []string{}
+}
@@ -0,0 +1,132 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/isaacgarza/dev-stack/internal/core/docker"
+	cliTypes "github.com/isaacgarza/dev-stack/internal/pkg/cli/types"
+	"github.com/isaacgarza/dev-stack/internal/pkg/constants"
+	"github.com/isaacgarza/dev-stack/internal/pkg/export"
+	"github.com/isaacgarza/dev-stack/internal/pkg/ui"
+	"github.com/isaacgarza/dev-stack/internal/pkg/utils"
+	"github.com/spf13/cobra"
+)
+
+// ExportDevcontainerHandler handles the export devcontainer command
+type ExportDevcontainerHandler struct{}
+
+// NewExportDevcontainerHandler creates a new export devcontainer handler
+func NewExportDevcontainerHandler() *ExportDevcontainerHandler {
+	return &ExportDevcontainerHandler{}
+}
+
+// Handle executes the export devcontainer command. It writes the
+// .devcontainer directory running the services up would start next to the
+// dev container, which generate compose keeps in sync afterwards.
+func (h *ExportDevcontainerHandler) Handle(ctx context.Context, cmd *cobra.Command, args []string, base *cliTypes.BaseCommand) error {
+	image, _ := cmd.Flags().GetString("image")
+	force, _ := cmd.Flags().GetBool("force")
+
+	cfg, err := loadExportConfig()
+	if err != nil {
+		return err
+	}
+	devcontainerFile := filepath.Join(export.DevcontainerDir, export.DevcontainerFile)
+	if utils.FileExists(devcontainerFile) && !force {
+		if _, err := export.ReadDevcontainerSelection(devcontainerFile); err != nil {
+			return fmt.Errorf("%s exists and wasn't written by dev-stack; use --force to replace it", devcontainerFile)
+		}
+	}
+
+	selection := export.DevcontainerSelection{Services: args, Profile: ActiveProfile(cmd), Image: image}
+	skipped, err := writeDevcontainer(cfg, selection)
+	if err != nil {
+		return err
+	}
+	if len(skipped) > 0 {
+		ui.Warning("Skipping services the compose files don't define: %s", strings.Join(skipped, ", "))
+	}
+	ui.Success("Wrote %s", export.DevcontainerDir)
+	ui.Info("Open the project in a dev container, or a codespace, to start it with the services")
+	return nil
+}
+
+// ValidateArgs validates the command arguments
+func (h *ExportDevcontainerHandler) ValidateArgs(args []string) error {
+	return nil
+}
+
+// GetRequiredFlags returns required flags for this command
+func (h *ExportDevcontainerHandler) GetRequiredFlags() []string {
+	return []string{}
+}
+
+// SyncDevcontainer exports the dev container again, from the services it
+// was exported from, when the project has one exported by dev-stack
+func SyncDevcontainer(cfg *ProjectConfig) error {
+	devcontainerFile := filepath.Join(export.DevcontainerDir, export.DevcontainerFile)
+	if !utils.FileExists(devcontainerFile) {
+		return nil
+	}
+	selection, err := export.ReadDevcontainerSelection(devcontainerFile)
+	if errors.Is(err, export.ErrNotExported) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", devcontainerFile, err)
+	}
+
+	skipped, err := writeDevcontainer(cfg, *selection)
+	if err != nil {
+		return fmt.Errorf("failed to update %s: %w", export.DevcontainerDir, err)
+	}
+	if len(skipped) > 0 {
+		ui.Warning("The dev container skips services the compose files don't define: %s", strings.Join(skipped, ", "))
+	}
+	ui.Info("Updated %s", export.DevcontainerDir)
+	return nil
+}
+
+// writeDevcontainer writes the files of the dev container exported from
+// selection and returns the selected services the compose files don't
+// define
+func writeDevcontainer(cfg *ProjectConfig, selection export.DevcontainerSelection) ([]string, error) {
+	definitions, skipped, err := exportDefinitions(cfg, selection.Services, selection.Profile)
+	if err != nil {
+		return nil, err
+	}
+
+	// The files under dev-stack/tmp only exist while a mode such as CI or
+	// ephemeral is on, so the dev container doesn't reference them
+	tmpDir := filepath.Join(constants.DevStackDir, constants.TmpDir)
+	var composeFiles []string
+	for _, file := range docker.ComposeFiles() {
+		if filepath.Dir(file) != tmpDir {
+			composeFiles = append(composeFiles, filepath.ToSlash(file))
+		}
+	}
+
+	files, err := export.Devcontainer(definitions, export.DevcontainerOptions{
+		Project:      cfg.Project.Name,
+		Selection:    selection,
+		ComposeFiles: composeFiles,
+		Seed:         utils.FileExists(filepath.Join(constants.DevStackDir, constants.SeedsDir)),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to render the dev container: %w", err)
+	}
+	if err := os.MkdirAll(export.DevcontainerDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create %s: %w", export.DevcontainerDir, err)
+	}
+	for name, data := range files {
+		if err := os.WriteFile(filepath.Join(export.DevcontainerDir, name), data, 0644); err != nil {
+			return nil, fmt.Errorf("failed to write %s: %w", name, err)
+		}
+	}
+	return skipped, nil
+}
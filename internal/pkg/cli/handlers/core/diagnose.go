@@ -0,0 +1,234 @@
+package core
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/docker/go-units"
+	"github.com/isaacgarza/dev-stack/internal/core/diagnose"
+	"github.com/isaacgarza/dev-stack/internal/core/docker"
+	cliTypes "github.com/isaacgarza/dev-stack/internal/pkg/cli/types"
+	pkgConfig "github.com/isaacgarza/dev-stack/internal/pkg/config"
+	"github.com/isaacgarza/dev-stack/internal/pkg/constants"
+	"github.com/isaacgarza/dev-stack/internal/pkg/types"
+	"github.com/isaacgarza/dev-stack/internal/pkg/ui"
+	"github.com/isaacgarza/dev-stack/internal/pkg/utils"
+	"github.com/isaacgarza/dev-stack/internal/pkg/version"
+	"github.com/spf13/cobra"
+)
+
+// maxDiagnosticFileSize truncates each file of a support bundle, so one
+// chatty log can't crowd out the rest
+const maxDiagnosticFileSize = 2 * 1024 * 1024
+
+// diagnosticCommandTimeout bounds each command whose output goes into a
+// support bundle
+const diagnosticCommandTimeout = 30 * time.Second
+
+// DiagnoseHandler handles the diagnose command, writing a support bundle to
+// attach to bug reports
+type DiagnoseHandler struct{}
+
+// NewDiagnoseHandler creates a new diagnose handler
+func NewDiagnoseHandler() *DiagnoseHandler {
+	return &DiagnoseHandler{}
+}
+
+// Handle executes the diagnose command. Whatever can't be collected, such
+// as logs while Docker isn't running, is noted in the bundle's manifest
+// instead of failing the command.
+func (h *DiagnoseHandler) Handle(ctx context.Context, cmd *cobra.Command, args []string, base *cliTypes.BaseCommand) (err error) {
+	output, _ := cmd.Flags().GetString("output")
+	logLines, _ := cmd.Flags().GetInt("log-lines")
+	maxSizeValue, _ := cmd.Flags().GetString("max-size")
+
+	maxSize, err := units.RAMInBytes(maxSizeValue)
+	if err != nil || maxSize <= 0 {
+		return fmt.Errorf("invalid max size %q: expected a size such as 20MB", maxSizeValue)
+	}
+	if logLines < 0 {
+		return fmt.Errorf("invalid log lines %d: must be positive", logLines)
+	}
+	name := "dev-stack-diagnostics-" + time.Now().Format("2006-01-02_15-04-05")
+	if output == "" {
+		output = name + ".tar.gz"
+	}
+
+	file, err := os.Create(output)
+	if err != nil {
+		return fmt.Errorf("failed to create support bundle: %w", err)
+	}
+	defer func() {
+		if closeErr := file.Close(); err == nil && closeErr != nil {
+			err = fmt.Errorf("failed to write support bundle: %w", closeErr)
+		}
+		if err != nil {
+			_ = os.Remove(output)
+		}
+	}()
+
+	ui.Info("Collecting diagnostics...")
+	bundle := diagnose.NewBundle(file, name, maxDiagnosticFileSize, int(maxSize))
+	if err := h.collect(ctx, bundle, base, args, logLines); err != nil {
+		return err
+	}
+	if err := bundle.Close(); err != nil {
+		return fmt.Errorf("failed to write support bundle: %w", err)
+	}
+
+	ui.Success("Wrote support bundle %s", output)
+	ui.Muted("Secrets are redacted, but review its contents before sharing it")
+	return nil
+}
+
+// collect adds the version, configuration, Docker details, service status,
+// logs and doctor report to bundle
+func (h *DiagnoseHandler) collect(ctx context.Context, bundle *diagnose.Bundle, base *cliTypes.BaseCommand, serviceNames []string, logLines int) error {
+	versionInfo := version.GetFormattedBuildInfo() + fmt.Sprintf("Runtime:    %s/%s\n", runtime.GOOS, runtime.GOARCH)
+	if err := bundle.Add("version.txt", versionInfo); err != nil {
+		return err
+	}
+
+	configPath := filepath.Join(constants.DevStackDir, constants.ConfigFileName)
+	files := map[string]string{
+		"config/" + constants.ConfigFileName: configPath,
+		"config/docker-compose.yml":          constants.DockerComposeFile,
+	}
+	if userConfig, err := pkgConfig.UserConfigPath(); err == nil {
+		files["config/user-config.yaml"] = userConfig
+	}
+	customServices, _ := filepath.Glob(filepath.Join(constants.DevStackDir, constants.CustomServicesDir, "*"+constants.ServiceConfigExtension))
+	for _, path := range customServices {
+		files["config/services/"+filepath.Base(path)] = path
+	}
+	crashLogs, _ := filepath.Glob(filepath.Join(constants.DevStackDir, constants.LogsDir, "crash-loop-*.log"))
+	for _, path := range crashLogs {
+		files["crash-loops/"+filepath.Base(path)] = path
+	}
+	for _, entry := range slices.Sorted(maps.Keys(files)) {
+		if err := bundle.AddFile(entry, files[entry]); err != nil {
+			return err
+		}
+	}
+
+	var dockerInfo strings.Builder
+	for _, command := range [][]string{
+		{constants.DockerCmd, constants.DockerVersionCmd},
+		{constants.DockerCmd, constants.DockerInfoCmd},
+		{constants.DockerCmd, constants.DockerComposeCmd, constants.DockerVersionCmd},
+	} {
+		fmt.Fprintf(&dockerInfo, "$ %s\n%s\n", strings.Join(command, " "), runDiagnosticCommand(ctx, command[0], command[1:]...))
+	}
+	if err := bundle.Add("docker.txt", dockerInfo.String()); err != nil {
+		return err
+	}
+
+	if err := h.collectServices(ctx, bundle, base, configPath, serviceNames, logLines); err != nil {
+		return err
+	}
+
+	executable, err := os.Executable()
+	if err != nil {
+		bundle.Skip("doctor.txt", err)
+		return nil
+	}
+	return bundle.Add("doctor.txt", runDiagnosticCommand(ctx, executable, constants.CmdNameDoctor, "--"+constants.FlagNoColor))
+}
+
+// collectServices adds the status and last log lines of the project's
+// services to bundle
+func (h *DiagnoseHandler) collectServices(ctx context.Context, bundle *diagnose.Bundle, base *cliTypes.BaseCommand, configPath string, serviceNames []string, logLines int) error {
+	if !utils.FileExists(configPath) {
+		bundle.Skip("status.json", errors.New(constants.ErrNotInitialized))
+		return nil
+	}
+	cfg, err := LoadProjectConfig(configPath)
+	if err != nil {
+		bundle.Skip("status.json", err)
+		return nil
+	}
+	if len(serviceNames) == 0 {
+		serviceNames = cfg.Stack.Enabled
+	}
+
+	dockerClient, err := newImagesDockerClient(base)
+	if err != nil {
+		bundle.Skip("status.json", err)
+		return nil
+	}
+	defer func() {
+		if err := dockerClient.Close(); err != nil {
+			base.Logger.Error("Failed to close Docker client", "error", err)
+		}
+	}()
+
+	statuses, err := dockerClient.Containers().List(ctx, cfg.Project.Name, serviceNames)
+	if err != nil {
+		bundle.Skip("status.json", err)
+		return nil
+	}
+	cfg.CrashLoop.MarkCrashLoops(statuses, time.Now())
+	data, err := json.MarshalIndent(statuses, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode service status: %w", err)
+	}
+	if err := bundle.Add("status.json", string(data)); err != nil {
+		return err
+	}
+
+	if logLines == 0 {
+		return nil
+	}
+	for _, service := range serviceNames {
+		if err := addServiceLogs(ctx, bundle, dockerClient, cfg.Project.Name, service, logLines); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// addServiceLogs adds the last logLines lines of a service's logs to bundle
+func addServiceLogs(ctx context.Context, bundle *diagnose.Bundle, dockerClient *docker.Client, projectName, service string, logLines int) error {
+	name := "logs/" + service + ".log"
+	var logs bytes.Buffer
+	options := types.LogOptions{Tail: strconv.Itoa(logLines), Timestamps: true, NoColor: true}
+	if err := dockerClient.Containers().StreamLogs(ctx, projectName, []string{service}, options, &logs, &logs); err != nil {
+		bundle.Skip(name, err)
+		return nil
+	}
+	return bundle.Add(name, logs.String())
+}
+
+// runDiagnosticCommand returns the combined output of a command, followed
+// by its error when it fails
+func runDiagnosticCommand(ctx context.Context, name string, args ...string) string {
+	ctx, cancel := context.WithTimeout(ctx, diagnosticCommandTimeout)
+	defer cancel()
+	output, err := exec.CommandContext(ctx, name, args...).CombinedOutput()
+	if err != nil {
+		return string(output) + fmt.Sprintf("\n[failed: %v]\n", err)
+	}
+	return string(output)
+}
+
+// ValidateArgs validates the command arguments
+func (h *DiagnoseHandler) ValidateArgs(args []string) error {
+	return nil
+}
+
+// GetRequiredFlags returns required flags for this command
+func (h *DiagnoseHandler) GetRequiredFlags() []string {
+	return []string{}
+}
@@ -0,0 +1,172 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"slices"
+	"text/tabwriter"
+
+	"github.com/isaacgarza/dev-stack/internal/core/docker"
+	handlerUtils "github.com/isaacgarza/dev-stack/internal/pkg/cli/handlers/utils"
+	cliTypes "github.com/isaacgarza/dev-stack/internal/pkg/cli/types"
+	"github.com/isaacgarza/dev-stack/internal/pkg/constants"
+	"github.com/isaacgarza/dev-stack/internal/pkg/types"
+	"github.com/isaacgarza/dev-stack/internal/pkg/ui"
+	"github.com/isaacgarza/dev-stack/internal/pkg/utils"
+	"github.com/spf13/cobra"
+)
+
+// DiffHandler handles the diff command
+type DiffHandler struct{}
+
+// NewDiffHandler creates a new diff handler
+func NewDiffHandler() *DiffHandler {
+	return &DiffHandler{}
+}
+
+// Handle executes the diff command
+func (h *DiffHandler) Handle(ctx context.Context, cmd *cobra.Command, args []string, base *cliTypes.BaseCommand) error {
+	format, _ := cmd.Flags().GetString("format")
+	reconcile, _ := cmd.Flags().GetBool("reconcile")
+	if handlerUtils.GetCIFlags(cmd).JSON {
+		format = "json"
+	}
+	if format != "table" && format != "json" {
+		return fmt.Errorf("invalid format %q (supported: table, json)", format)
+	}
+
+	configPath := filepath.Join(constants.DevStackDir, constants.ConfigFileName)
+	if !utils.FileExists(configPath) {
+		return errors.New(constants.ErrNotInitialized)
+	}
+	cfg, err := LoadProjectConfig(configPath)
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	logger := base.Logger.(loggerAdapter)
+	dockerClient, err := docker.Shared(logger.SlogLogger())
+	if err != nil {
+		return fmt.Errorf("failed to create Docker client: %w", err)
+	}
+	defer func() {
+		if err := dockerClient.Close(); err != nil {
+			base.Logger.Error("Failed to close Docker client", "error", err)
+		}
+	}()
+
+	drifts, err := dockerClient.Containers().Drift(ctx, cfg.Project.Name, args)
+	if err != nil {
+		return fmt.Errorf("failed to compare services: %w", err)
+	}
+	drifts = withoutShared(cfg, drifts)
+
+	if err := writeDrift(cmd.OutOrStdout(), format, drifts); err != nil {
+		return err
+	}
+	if !reconcile {
+		return nil
+	}
+
+	var changed []string
+	for _, drift := range drifts {
+		if drift.Status == types.DriftChanged {
+			changed = append(changed, drift.Service)
+		}
+	}
+	if len(changed) == 0 {
+		ui.Info("No running service needs to be recreated")
+		return nil
+	}
+
+	ui.Info("Recreating %d drifted service(s)...", len(changed))
+	options := types.StartOptions{ForceRecreate: true, NoDeps: true, Detach: true}
+	if err := dockerClient.Containers().Start(ctx, cfg.Project.Name, changed, options); err != nil {
+		return fmt.Errorf("failed to recreate services: %w", err)
+	}
+	for _, serviceName := range changed {
+		ui.Success("Recreated %s", serviceName)
+	}
+	return nil
+}
+
+// ValidateArgs validates the command arguments
+func (h *DiffHandler) ValidateArgs(args []string) error {
+	return nil
+}
+
+// GetRequiredFlags returns required flags for this command
+func (h *DiffHandler) GetRequiredFlags() []string {
+	return []string{}
+}
+
+// withoutShared drops the services the project shares with others, which
+// run in the shared project rather than the project's own
+func withoutShared(cfg *ProjectConfig, drifts []types.ServiceDrift) []types.ServiceDrift {
+	shared := sharedServices(cfg, cfg.Stack.Enabled)
+	var result []types.ServiceDrift
+	for _, drift := range drifts {
+		if !slices.Contains(shared, drift.Service) {
+			result = append(result, drift)
+		}
+	}
+	return result
+}
+
+// writeDrift prints the drift of each service as a table or as JSON
+func writeDrift(w io.Writer, format string, drifts []types.ServiceDrift) error {
+	if format == "json" {
+		if drifts == nil {
+			drifts = []types.ServiceDrift{}
+		}
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(drifts)
+	}
+
+	if len(drifts) == 0 {
+		ui.Success("Running services match their configuration")
+		return nil
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "SERVICE\tSTATUS\tFIELD\tEXPECTED\tACTUAL")
+	for _, drift := range drifts {
+		if len(drift.Changes) == 0 {
+			_, _ = fmt.Fprintf(tw, "%s\t%s\t\t\t\n", drift.Service, drift.Status)
+			continue
+		}
+		for i, change := range drift.Changes {
+			service, status := drift.Service, drift.Status
+			if i > 0 {
+				service, status = "", ""
+			}
+			_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", service, status, change.Field, orNone(change.Expected), orNone(change.Actual))
+		}
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	for _, drift := range drifts {
+		switch drift.Status {
+		case types.DriftMissing:
+			ui.Muted("%s is not running; start it with dev-stack up %s", drift.Service, drift.Service)
+		case types.DriftOrphaned:
+			ui.Muted("%s is no longer defined; dev-stack down removes its container", drift.Service)
+		}
+	}
+	return nil
+}
+
+// orNone shows empty values in tables
+func orNone(value string) string {
+	if value == "" {
+		return "-"
+	}
+	return value
+}
@@ -4,10 +4,16 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"os"
 	"path/filepath"
+	"slices"
 
 	"github.com/isaacgarza/dev-stack/internal/core/docker"
+	"github.com/isaacgarza/dev-stack/internal/core/projectstate"
+	"github.com/isaacgarza/dev-stack/internal/core/services"
+	handlerUtils "github.com/isaacgarza/dev-stack/internal/pkg/cli/handlers/utils"
 	cliTypes "github.com/isaacgarza/dev-stack/internal/pkg/cli/types"
+	"github.com/isaacgarza/dev-stack/internal/pkg/compose"
 	"github.com/isaacgarza/dev-stack/internal/pkg/constants"
 	"github.com/isaacgarza/dev-stack/internal/pkg/types"
 	"github.com/isaacgarza/dev-stack/internal/pkg/ui"
@@ -16,16 +22,30 @@ import (
 )
 
 // DownHandler handles the down command
-type DownHandler struct{}
+type DownHandler struct {
+	manager *services.Manager
+}
 
 // NewDownHandler creates a new down handler
-func NewDownHandler() *DownHandler {
-	return &DownHandler{}
+func NewDownHandler(manager *services.Manager) *DownHandler {
+	return &DownHandler{manager: manager}
 }
 
 // Handle executes the down command
 func (h *DownHandler) Handle(ctx context.Context, cmd *cobra.Command, args []string, base *cliTypes.BaseCommand) error {
-	ui.Header(constants.MsgStopping)
+	// A dry run prints the plan alone, which may be JSON
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+	if !dryRun {
+		ui.Header(constants.MsgStopping)
+	}
+
+	// A workspace root stops its projects rather than a stack of its own
+	if selection, _ := cmd.Flags().GetString("workspace"); selection != "" {
+		if dryRun {
+			return errors.New("--dry-run can't be combined with --workspace")
+		}
+		return downWorkspace(ctx, cmd, selection, base)
+	}
 
 	// Check if dev-stack is initialized
 	configPath := filepath.Join(constants.DevStackDir, constants.ConfigFileName)
@@ -39,9 +59,33 @@ func (h *DownHandler) Handle(ctx context.Context, cmd *cobra.Command, args []str
 		return fmt.Errorf("failed to load configuration: %w", err)
 	}
 
+	// Removing volumes destroys data, so guard it in protected contexts
+	removeVolumes, _ := cmd.Flags().GetBool("volumes")
+	if dryRun {
+		plan, err := downPlan(cfg, configPath, args, removeVolumes)
+		if err != nil {
+			return err
+		}
+		return WritePlan(cmd.OutOrStdout(), handlerUtils.GetCIFlags(cmd).JSON, plan)
+	}
+	if removeVolumes {
+		if err := ConfirmProtected(cmd, cfg, "remove service volumes"); err != nil {
+			return err
+		}
+	}
+
+	// Hold the project lock, so an up in another terminal doesn't start
+	// services while they are being removed
+	lock, err := projectstate.Acquire(projectstate.Path(), constants.CmdNameDown)
+	if err != nil {
+		return err
+	}
+	var stopped func(*projectstate.State)
+	defer func() { releaseProjectLock(lock, base, stopped) }()
+
 	// Create Docker client
 	logger := base.Logger.(loggerAdapter)
-	dockerClient, err := docker.NewClient(logger.SlogLogger())
+	dockerClient, err := docker.Shared(logger.SlogLogger())
 	if err != nil {
 		return fmt.Errorf("failed to create Docker client: %w", err)
 	}
@@ -50,30 +94,130 @@ func (h *DownHandler) Handle(ctx context.Context, cmd *cobra.Command, args []str
 			base.Logger.Error("Failed to close Docker client", "error", err)
 		}
 	}()
+	defer writeJobSummary(ctx, h.manager, cfg, constants.CmdNameDown)
 
 	// Parse flags
-	timeout, _ := cmd.Flags().GetInt("timeout")
+	timeout, _ := cmd.Flags().GetInt("stop-timeout")
+	force, _ := cmd.Flags().GetBool("force")
+	shutdown, err := shutdownSettings(cfg)
+	if err != nil {
+		return err
+	}
 
 	options := types.StopOptions{
-		Timeout: timeout,
-		Remove:  true,
+		Timeout:       timeout,
+		Remove:        true,
+		RemoveVolumes: removeVolumes,
 	}
 
-	// Determine services to stop
+	// Determine the services the hooks run for
 	serviceNames := args
 	if len(serviceNames) == 0 {
 		serviceNames = cfg.Stack.Enabled
 	}
 
-	// Stop services
-	if err := dockerClient.Containers().Stop(ctx, cfg.Project.Name, serviceNames, options); err != nil {
+	if err := ConfigureHooks(h.manager, cfg, configPath); err != nil {
+		return err
+	}
+	if err := h.manager.RunHooks(ctx, types.HookPreDown, serviceNames, nil); err != nil {
+		return err
+	}
+
+	// Stop the services gracefully, applications before the services they
+	// depend on, before anything is removed
+	endGroup := ui.Group("Stopping services")
+	board := ui.NewProgressBoard()
+	report, err := dockerClient.Containers().Shutdown(ctx, cfg.Project.Name, args, types.ShutdownOptions{
+		Timeout:  timeout,
+		Services: shutdown,
+		Force:    force,
+		Progress: board,
+	})
+	board.Stop()
+	endGroup()
+	if err != nil {
 		return fmt.Errorf("failed to stop services: %w", err)
 	}
+	if err := reportShutdown(report); err != nil {
+		return err
+	}
+
+	// Ephemeral stacks leave nothing behind: their anonymous volumes go with
+	// the containers, and taking the whole stack down ends ephemeral mode
+	if isEphemeral() {
+		options.RemoveVolumes = true
+		if len(args) == 0 {
+			if err := h.removeEphemeral(ctx, dockerClient, cfg.Project.Name); err != nil {
+				return err
+			}
+		}
+	}
+
+	// Shared services keep running until the last project using them is down
+	if err := detachSharedServices(ctx, dockerClient, cfg, args); err != nil {
+		return err
+	}
+
+	// Stop the named services, or take the whole project down like
+	// docker compose down when none are named
+	if err := dockerClient.Containers().Stop(ctx, cfg.Project.Name, args, options); err != nil {
+		return fmt.Errorf("failed to stop services: %w", err)
+	}
+
+	// Taking the whole stack down also detaches it from its workspace and
+	// releases the ports ci up pinned
+	if len(args) == 0 {
+		for _, file := range []string{compose.WorkspaceFile(), compose.CIFile()} {
+			if err := os.Remove(file); err != nil && !os.IsNotExist(err) {
+				return fmt.Errorf("failed to remove %s: %w", file, err)
+			}
+		}
+	}
+
+	// Named volumes outlive their containers; remove them when tearing down the whole stack
+	if removeVolumes && len(args) == 0 {
+		if err := dockerClient.Volumes().Remove(ctx, cfg.Project.Name); err != nil {
+			return fmt.Errorf("failed to remove volumes: %w", err)
+		}
+	}
+
+	stopped = func(state *projectstate.State) {
+		if len(args) == 0 {
+			*state = projectstate.State{Lock: state.Lock}
+			return
+		}
+		for _, name := range args {
+			delete(state.Idle, name)
+		}
+		state.Services = slices.DeleteFunc(state.Services, func(name string) bool {
+			return slices.Contains(args, name)
+		})
+	}
+
+	if err := h.manager.RunHooks(ctx, types.HookPostDown, serviceNames, nil); err != nil {
+		return err
+	}
 
 	ui.Success(constants.MsgStopSuccess)
 	return nil
 }
 
+// removeEphemeral removes the project's ephemeral stack, if it is running,
+// and the file that made it ephemeral
+func (h *DownHandler) removeEphemeral(ctx context.Context, client *docker.Client, projectName string) error {
+	stack, err := projectEphemeralStack(ctx, client, projectName)
+	if err != nil {
+		return fmt.Errorf("failed to list ephemeral stacks: %w", err)
+	}
+	if stack != nil {
+		return removeEphemeralStack(ctx, client, *stack)
+	}
+	if err := os.Remove(compose.EphemeralFile()); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove %s: %w", compose.EphemeralFile(), err)
+	}
+	return nil
+}
+
 // ValidateArgs validates the command arguments
 func (h *DownHandler) ValidateArgs(args []string) error {
 	return nil
@@ -0,0 +1,217 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/isaacgarza/dev-stack/internal/core/services"
+	handlerUtils "github.com/isaacgarza/dev-stack/internal/pkg/cli/handlers/utils"
+	"github.com/isaacgarza/dev-stack/internal/pkg/cli/types"
+	"github.com/isaacgarza/dev-stack/internal/pkg/constants"
+	pkgTypes "github.com/isaacgarza/dev-stack/internal/pkg/types"
+	"github.com/isaacgarza/dev-stack/internal/pkg/utils"
+	"github.com/spf13/cobra"
+)
+
+// Output formats supported by the env command
+const (
+	envFormatDotenv = "dotenv"
+	envFormatShell  = "shell"
+	envFormatJSON   = "json"
+)
+
+// EnvHandler handles the env command
+type EnvHandler struct {
+	manager *services.Manager
+}
+
+// NewEnvHandler creates a new env handler
+func NewEnvHandler(manager *services.Manager) *EnvHandler {
+	return &EnvHandler{manager: manager}
+}
+
+// Handle executes the env command. Only the variables are written to stdout
+// so the output can be redirected to a file or passed to eval.
+func (h *EnvHandler) Handle(ctx context.Context, cmd *cobra.Command, args []string, base *types.BaseCommand) error {
+	format, _ := cmd.Flags().GetString("format")
+	export, _ := cmd.Flags().GetBool("export")
+	if export && !cmd.Flags().Changed("format") {
+		format = envFormatShell
+	}
+	if err := validateEnvFormat(format, export); err != nil {
+		return err
+	}
+
+	configPath := filepath.Join(constants.DevStackDir, constants.ConfigFileName)
+	if !utils.FileExists(configPath) {
+		return errors.New(constants.ErrNotInitialized)
+	}
+
+	cfg, err := LoadProjectConfig(configPath)
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	for _, serviceName := range args {
+		if !slices.Contains(cfg.Stack.Enabled, serviceName) {
+			return &pkgTypes.ServiceNotFoundError{Service: serviceName, NotEnabled: true}
+		}
+	}
+	serviceNames := args
+	if len(serviceNames) == 0 {
+		serviceNames = cfg.Stack.Enabled
+	}
+
+	h.manager.SetProjectName(cfg.Project.Name)
+	if _, err := WakeIdle(ctx, h.manager, args); err != nil {
+		return err
+	}
+	running, err := RunningServices(ctx, h.manager, serviceNames)
+	if err != nil {
+		return err
+	}
+	if len(running) == 0 {
+		return fmt.Errorf("no running services; run '%s' first", constants.CmdUp)
+	}
+
+	env, err := ServiceConnectionEnv(configPath, running)
+	if err != nil {
+		return err
+	}
+	return writeEnv(os.Stdout, env, format, export)
+}
+
+// RunningServices returns the running services among serviceNames and their
+// dependencies, dependencies first. Dependencies are included because
+// services such as localstack-s3 are served by their localstack-core
+// dependency.
+func RunningServices(ctx context.Context, manager *services.Manager, serviceNames []string) ([]string, error) {
+	resolved, err := handlerUtils.NewServiceUtils().ResolveDependencies(serviceNames)
+	if err != nil {
+		resolved = serviceNames
+	}
+
+	statuses, err := manager.GetServiceStatus(ctx, resolved)
+	if err != nil {
+		return nil, err
+	}
+
+	var running []string
+	for _, serviceName := range resolved {
+		for _, status := range statuses {
+			if status.Name == serviceName && status.State.IsRunning() {
+				running = append(running, serviceName)
+				break
+			}
+		}
+	}
+	return running, nil
+}
+
+// RequireRunning returns an error telling to start serviceName unless its
+// container is running, for the commands that run something inside it
+func RequireRunning(ctx context.Context, manager *services.Manager, serviceName string) error {
+	statuses, err := manager.GetServiceStatus(ctx, []string{serviceName})
+	if err != nil {
+		return err
+	}
+	for _, status := range statuses {
+		if status.Name == serviceName && status.State.IsRunning() {
+			return nil
+		}
+	}
+	return fmt.Errorf("%s is not running; start it with '%s %s'", serviceName, constants.CmdUp, serviceName)
+}
+
+// validateEnvFormat rejects unknown formats and combinations that cannot be
+// exported
+func validateEnvFormat(format string, export bool) error {
+	switch format {
+	case envFormatDotenv, envFormatShell:
+	case envFormatJSON:
+		if export {
+			return fmt.Errorf("--export cannot be used with --format %s", envFormatJSON)
+		}
+	default:
+		return fmt.Errorf("unsupported format %q (supported: %s, %s, %s)", format, envFormatDotenv, envFormatShell, envFormatJSON)
+	}
+	return nil
+}
+
+// writeEnv writes env sorted by name in the given format. With export each
+// line is prefixed with "export " so it can be evaluated by a shell.
+func writeEnv(w io.Writer, env map[string]string, format string, export bool) error {
+	if format == envFormatJSON {
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(env)
+	}
+
+	keys := make([]string, 0, len(env))
+	for key := range env {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	prefix := ""
+	if export {
+		prefix = "export "
+	}
+	quote := quoteDotenv
+	if format == envFormatShell {
+		quote = quoteShell
+	}
+
+	for _, key := range keys {
+		if _, err := fmt.Fprintf(w, "%s%s=%s\n", prefix, key, quote(env[key])); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// quoteDotenv double-quotes values that contain anything beyond plain URL
+// and word characters
+func quoteDotenv(value string) string {
+	if value != "" && strings.IndexFunc(value, needsQuoting) < 0 {
+		return value
+	}
+	replacer := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "$", `\$`, "`", "\\`", "\n", `\n`)
+	return `"` + replacer.Replace(value) + `"`
+}
+
+// quoteShell single-quotes values for POSIX shells
+func quoteShell(value string) string {
+	if value != "" && strings.IndexFunc(value, needsQuoting) < 0 {
+		return value
+	}
+	return "'" + strings.ReplaceAll(value, "'", `'\''`) + "'"
+}
+
+func needsQuoting(r rune) bool {
+	switch {
+	case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+		return false
+	case strings.ContainsRune("_-.,:/@%+=", r):
+		return false
+	}
+	return true
+}
+
+// ValidateArgs validates the command arguments
+func (h *EnvHandler) ValidateArgs(args []string) error {
+	return nil
+}
+
+// GetRequiredFlags returns required flags for this command
+func (h *EnvHandler) GetRequiredFlags() []string {
+	return []string{}
+}
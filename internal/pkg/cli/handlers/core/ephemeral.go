@@ -0,0 +1,150 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/isaacgarza/dev-stack/internal/core/docker"
+	"github.com/isaacgarza/dev-stack/internal/core/projectstate"
+	"github.com/isaacgarza/dev-stack/internal/pkg/compose"
+	"github.com/isaacgarza/dev-stack/internal/pkg/constants"
+	"github.com/isaacgarza/dev-stack/internal/pkg/types"
+	"github.com/isaacgarza/dev-stack/internal/pkg/utils"
+)
+
+// reaperPIDFile records the background reaper watching the project's
+// ephemeral stack
+var reaperPIDFile = filepath.Join(constants.DevStackDir, constants.TmpDir, "reaper.pid")
+
+// writeEphemeralFile renders the compose file that makes the stack ephemeral
+// until expiresAt, replacing the one of a previous ephemeral run
+func writeEphemeralFile(expiresAt time.Time) error {
+	if !utils.FileExists(constants.DockerComposeFile) {
+		return fmt.Errorf("ephemeral stacks need a compose file; run '%s' first", constants.CmdInit)
+	}
+	if err := os.Remove(compose.EphemeralFile()); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove the previous ephemeral file: %w", err)
+	}
+
+	data, err := compose.EphemeralOverride(expiresAt, docker.ComposeFiles()...)
+	if err != nil {
+		return fmt.Errorf("failed to render the ephemeral compose file: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(compose.EphemeralFile()), 0755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", filepath.Dir(compose.EphemeralFile()), err)
+	}
+	return os.WriteFile(compose.EphemeralFile(), data, 0644)
+}
+
+// isEphemeral reports whether the project's stack runs in ephemeral mode
+func isEphemeral() bool {
+	return utils.FileExists(compose.EphemeralFile())
+}
+
+// projectEphemeralStack returns the ephemeral stack of a project, if any
+func projectEphemeralStack(ctx context.Context, client *docker.Client, projectName string) (*types.EphemeralStack, error) {
+	stacks, err := client.Containers().EphemeralStacks(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, stack := range stacks {
+		if stack.Project == docker.NormalizeProjectName(projectName) {
+			return &stack, nil
+		}
+	}
+	return nil, nil
+}
+
+// removeEphemeralStack removes a stack and, when it belongs to the project
+// in the working directory, the file that made it ephemeral
+func removeEphemeralStack(ctx context.Context, client *docker.Client, stack types.EphemeralStack) error {
+	if err := client.Containers().RemoveEphemeral(ctx, stack); err != nil {
+		return err
+	}
+
+	configPath := filepath.Join(constants.DevStackDir, constants.ConfigFileName)
+	if !utils.FileExists(configPath) {
+		return nil
+	}
+	cfg, err := LoadProjectConfig(configPath)
+	if err != nil || docker.NormalizeProjectName(cfg.Project.Name) != stack.Project {
+		return nil
+	}
+	if err := os.Remove(compose.EphemeralFile()); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove %s: %w", compose.EphemeralFile(), err)
+	}
+	return nil
+}
+
+// startReaper starts a background `dev-stack gc --watch` for the project,
+// unless one is already running. It removes the stack once its TTL expires
+// and exits when the stack is gone.
+func startReaper(ctx context.Context, projectName string) error {
+	return startBackground(ctx, reaperPIDFile, "reaper", constants.CmdNameGC, "--watch", "--project", projectName)
+}
+
+// startBackground starts dev-stack with args detached from the terminal,
+// logging to dev-stack/logs/<name>.log, unless the process recorded in
+// pidFile is still running
+func startBackground(ctx context.Context, pidFile, name string, args ...string) error {
+	if backgroundRunning(pidFile) {
+		return nil
+	}
+
+	executable, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("failed to locate dev-stack executable: %w", err)
+	}
+
+	logsDir := filepath.Join(constants.DevStackDir, constants.LogsDir)
+	if err := os.MkdirAll(logsDir, 0755); err != nil {
+		return fmt.Errorf("failed to create logs directory: %w", err)
+	}
+	logFile, err := os.OpenFile(filepath.Join(logsDir, name+".log"), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open %s log: %w", name, err)
+	}
+	defer func() { _ = logFile.Close() }()
+
+	// The process outlives the command, so it isn't killed once ctx is done
+	cmd := exec.CommandContext(context.WithoutCancel(ctx), executable, args...)
+	cmd.Stdout = logFile
+	cmd.Stderr = logFile
+	detach(cmd)
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to start %s: %w", name, err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(pidFile), 0755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", filepath.Dir(pidFile), err)
+	}
+	if err := os.WriteFile(pidFile, []byte(strconv.Itoa(cmd.Process.Pid)), 0644); err != nil {
+		return fmt.Errorf("failed to record %s: %w", name, err)
+	}
+	return cmd.Process.Release()
+}
+
+// backgroundRunning reports whether the process recorded in pidFile is
+// running
+func backgroundRunning(pidFile string) bool {
+	data, err := os.ReadFile(pidFile)
+	if err != nil {
+		return false
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	return err == nil && projectstate.ProcessAlive(pid)
+}
+
+// clearPIDFile removes pidFile when it records the running process, as a
+// background process started by startBackground does on the way out
+func clearPIDFile(pidFile string) {
+	if data, err := os.ReadFile(pidFile); err == nil && strings.TrimSpace(string(data)) == strconv.Itoa(os.Getpid()) {
+		_ = os.Remove(pidFile)
+	}
+}
@@ -0,0 +1,230 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/isaacgarza/dev-stack/internal/core/docker"
+	"github.com/isaacgarza/dev-stack/internal/core/events"
+	cliTypes "github.com/isaacgarza/dev-stack/internal/pkg/cli/types"
+	"github.com/isaacgarza/dev-stack/internal/pkg/constants"
+	"github.com/isaacgarza/dev-stack/internal/pkg/types"
+	"github.com/isaacgarza/dev-stack/internal/pkg/ui"
+	"github.com/isaacgarza/dev-stack/internal/pkg/utils"
+	"github.com/isaacgarza/dev-stack/internal/pkg/version"
+	"github.com/spf13/cobra"
+)
+
+// webhookTimeout bounds each delivery of an event to a webhook
+const webhookTimeout = 5 * time.Second
+
+// webhookQueueSize is how many events wait for delivery before new ones
+// are dropped, so a slow webhook never holds up the stream
+const webhookQueueSize = 64
+
+// EventsHandler handles the events command, streaming the lifecycle events
+// of the stack's containers
+type EventsHandler struct{}
+
+// NewEventsHandler creates a new events handler
+func NewEventsHandler() *EventsHandler {
+	return &EventsHandler{}
+}
+
+// Handle executes the events command. Streaming stops without an error on
+// an interrupt.
+func (h *EventsHandler) Handle(ctx context.Context, cmd *cobra.Command, args []string, base *cliTypes.BaseCommand) error {
+	format, _ := cmd.Flags().GetString("format")
+	since, _ := cmd.Flags().GetString("since")
+	filter, _ := cmd.Flags().GetString("event")
+	noWebhooks, _ := cmd.Flags().GetBool("no-webhooks")
+	notify, _ := cmd.Flags().GetBool("notify")
+
+	if format != "text" && format != "json" {
+		return fmt.Errorf("invalid format %q (supported: text, json)", format)
+	}
+	actions, err := parseEventFilter(filter)
+	if err != nil {
+		return err
+	}
+	startedAt := time.Now()
+	sinceTime, err := parseSince(since, startedAt)
+	if err != nil {
+		return err
+	}
+
+	configPath := filepath.Join(constants.DevStackDir, constants.ConfigFileName)
+	if !utils.FileExists(configPath) {
+		return errors.New(constants.ErrNotInitialized)
+	}
+	cfg, err := LoadProjectConfig(configPath)
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	dockerClient, err := newImagesDockerClient(base)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := dockerClient.Close(); err != nil {
+			base.Logger.Error("Failed to close Docker client", "error", err)
+		}
+	}()
+
+	var queue chan types.ServiceEvent
+	if !noWebhooks && len(cfg.Events.Webhooks) > 0 {
+		dispatcher := events.NewDispatcher(&http.Client{Timeout: webhookTimeout}, cfg.Events.Webhooks, version.GetUserAgent())
+		queue = make(chan types.ServiceEvent, webhookQueueSize)
+		defer close(queue)
+		go deliverEvents(ctx, dispatcher, queue)
+	}
+
+	var alerts *eventAlerts
+	if notify || cfg.Events.Notifications.Enabled {
+		alerts = newEventAlerts(cfg.Events.Notifications, events.NewDesktopNotifier())
+		alerts.markPaused(ctx, dockerClient, cfg.Project.Name)
+	}
+
+	if format == "text" {
+		ui.Muted("Streaming events of %s, press Ctrl+C to stop", cfg.Project.Name)
+	}
+	stream, errs := dockerClient.Containers().Events(ctx, cfg.Project.Name, args, sinceTime)
+	for event := range stream {
+		// Replayed events are history, not failures to alert about
+		if alerts != nil && !event.Time.Before(startedAt) {
+			alerts.observe(event)
+		}
+		if len(actions) > 0 && !slices.Contains(actions, event.Action) {
+			continue
+		}
+		if err := writeEvent(cmd.OutOrStdout(), event, format); err != nil {
+			return err
+		}
+		if queue != nil {
+			select {
+			case queue <- event:
+			default:
+				ui.Warning("Dropped a %s event of %s: webhooks are falling behind", event.Action, event.Service)
+			}
+		}
+	}
+	return <-errs
+}
+
+// deliverEvents sends queued events to the webhooks one at a time, in the
+// order they happened
+func deliverEvents(ctx context.Context, dispatcher *events.Dispatcher, queue <-chan types.ServiceEvent) {
+	for event := range queue {
+		if err := dispatcher.Dispatch(ctx, event); err != nil && ctx.Err() == nil {
+			ui.Warning("Failed to deliver a %s event of %s: %v", event.Action, event.Service, err)
+		}
+	}
+}
+
+// eventAlerts shows the alerts the events of the stack raise
+type eventAlerts struct {
+	rules    *events.AlertRules
+	notifier events.Notifier
+}
+
+// newEventAlerts creates the alerts of a notifications configuration
+func newEventAlerts(config types.NotificationsConfig, notifier events.Notifier) *eventAlerts {
+	return &eventAlerts{rules: events.NewAlertRules(config), notifier: notifier}
+}
+
+// markPaused keeps the services paused before the stream starts from
+// raising alerts. Failing to list them only costs the exclusion.
+func (a *eventAlerts) markPaused(ctx context.Context, dockerClient *docker.Client, projectName string) {
+	statuses, err := dockerClient.Containers().List(ctx, projectName, nil)
+	if err != nil {
+		return
+	}
+	for _, status := range statuses {
+		if status.State.IsPaused() {
+			a.rules.MarkPaused(status.Name)
+		}
+	}
+}
+
+// observe notifies the alert event raises, if any
+func (a *eventAlerts) observe(event types.ServiceEvent) {
+	alert, ok := a.rules.Observe(event)
+	if !ok {
+		return
+	}
+	if err := a.notifier.Notify(alert); err != nil {
+		ui.Warning("%s: %v", alert.Title, err)
+	}
+}
+
+// notifyOnEvents raises desktop notifications for the events of the
+// project until ctx is done. A failing event stream only warns, so the
+// caller keeps running without notifications.
+func notifyOnEvents(ctx context.Context, dockerClient *docker.Client, cfg *ProjectConfig) {
+	alerts := newEventAlerts(cfg.Events.Notifications, events.NewDesktopNotifier())
+	alerts.markPaused(ctx, dockerClient, cfg.Project.Name)
+	stream, errs := dockerClient.Containers().Events(ctx, cfg.Project.Name, nil, time.Time{})
+	for event := range stream {
+		alerts.observe(event)
+	}
+	if err := <-errs; err != nil {
+		ui.Warning("Desktop notifications stopped: %v", err)
+	}
+}
+
+// parseEventFilter parses the comma separated actions of --event
+func parseEventFilter(filter string) ([]string, error) {
+	var actions []string
+	for _, action := range strings.Split(filter, ",") {
+		action = strings.TrimSpace(action)
+		if action == "" {
+			continue
+		}
+		if !slices.Contains(types.ServiceEventActions(), action) {
+			return nil, fmt.Errorf("unknown event %q (supported: %s)", action, strings.Join(types.ServiceEventActions(), ", "))
+		}
+		actions = append(actions, action)
+	}
+	return actions, nil
+}
+
+// writeEvent prints an event as a line of text or as a JSON object per line
+func writeEvent(w io.Writer, event types.ServiceEvent, format string) error {
+	if format == "json" {
+		data, err := json.Marshal(event)
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(w, string(data))
+		return err
+	}
+
+	line := fmt.Sprintf("%s  %-16s %-14s", event.Time.Format(time.DateTime), event.Service, event.Action)
+	switch {
+	case event.Health != "":
+		line += event.Health
+	case event.ExitCode != nil:
+		line += fmt.Sprintf("exit code %d", *event.ExitCode)
+	}
+	_, err := fmt.Fprintln(w, strings.TrimRight(line, " "))
+	return err
+}
+
+// ValidateArgs validates the command arguments
+func (h *EventsHandler) ValidateArgs(args []string) error {
+	return nil
+}
+
+// GetRequiredFlags returns required flags for this command
+func (h *EventsHandler) GetRequiredFlags() []string {
+	return []string{}
+}
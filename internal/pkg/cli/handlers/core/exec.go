@@ -0,0 +1,117 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/isaacgarza/dev-stack/internal/core/services"
+	cliTypes "github.com/isaacgarza/dev-stack/internal/pkg/cli/types"
+	"github.com/isaacgarza/dev-stack/internal/pkg/constants"
+	"github.com/isaacgarza/dev-stack/internal/pkg/types"
+	"github.com/isaacgarza/dev-stack/internal/pkg/utils"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+// ExecHandler handles the exec command
+type ExecHandler struct {
+	manager *services.Manager
+}
+
+// NewExecHandler creates a new exec handler
+func NewExecHandler(manager *services.Manager) *ExecHandler {
+	return &ExecHandler{manager: manager}
+}
+
+// Handle executes the exec command. The exit code of the command becomes
+// the exit code of dev-stack.
+func (h *ExecHandler) Handle(ctx context.Context, cmd *cobra.Command, args []string, base *cliTypes.BaseCommand) error {
+	serviceName, command := splitExecArgs(args)
+	if err := h.ValidateArgs(append([]string{serviceName}, command...)); err != nil {
+		return err
+	}
+
+	configPath := filepath.Join(constants.DevStackDir, constants.ConfigFileName)
+	if !utils.FileExists(configPath) {
+		return errors.New(constants.ErrNotInitialized)
+	}
+	cfg, err := LoadProjectConfig(configPath)
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+	if !slices.Contains(cfg.Stack.Enabled, serviceName) {
+		return &types.ServiceNotFoundError{Service: serviceName, NotEnabled: true}
+	}
+
+	user, _ := cmd.Flags().GetString("user")
+	workdir, _ := cmd.Flags().GetString("workdir")
+	env, _ := cmd.Flags().GetString("env")
+	interactive, _ := cmd.Flags().GetBool("interactive")
+	tty, _ := cmd.Flags().GetBool("tty")
+	detach, _ := cmd.Flags().GetBool("detach")
+
+	options := execOptions(user, workdir, env, interactive, tty, detach,
+		term.IsTerminal(int(os.Stdin.Fd())) && term.IsTerminal(int(os.Stdout.Fd())))
+
+	h.manager.SetProjectName(cfg.Project.Name)
+	if err := RequireRunning(ctx, h.manager, serviceName); err != nil {
+		return err
+	}
+	err = h.manager.ExecCommand(ctx, serviceName, command, options)
+
+	// The command has already reported its failure on its own output
+	var exitErr *types.ExitError
+	if errors.As(err, &exitErr) {
+		cmd.SilenceErrors = true
+		cmd.SilenceUsage = true
+		return exitErr
+	}
+	return err
+}
+
+// ValidateArgs validates the command arguments
+func (h *ExecHandler) ValidateArgs(args []string) error {
+	if len(args) < 2 || args[0] == "" {
+		return fmt.Errorf("exec requires a service name and a command")
+	}
+	return nil
+}
+
+// GetRequiredFlags returns required flags for this command
+func (h *ExecHandler) GetRequiredFlags() []string {
+	return []string{}
+}
+
+// splitExecArgs separates the service from the command, dropping the --
+// that may separate them
+func splitExecArgs(args []string) (string, []string) {
+	if len(args) == 0 {
+		return "", nil
+	}
+	command := args[1:]
+	if len(command) > 0 && command[0] == "--" {
+		command = command[1:]
+	}
+	return args[0], command
+}
+
+// execOptions builds the exec options from the flags. A TTY is only
+// allocated when dev-stack runs in a terminal, so scripts piping input or
+// capturing output get separate stdout and stderr streams.
+func execOptions(user, workdir, env string, interactive, tty, detach, terminal bool) types.ExecOptions {
+	options := types.ExecOptions{
+		User:        user,
+		WorkingDir:  workdir,
+		Interactive: interactive && !detach,
+		TTY:         tty && terminal && !detach,
+		Detach:      detach,
+	}
+	if env != "" {
+		options.Env = utils.SplitAndTrim(env, ",")
+	}
+	return options
+}
@@ -0,0 +1,260 @@
+package core
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/isaacgarza/dev-stack/internal/core/docker"
+	cliTypes "github.com/isaacgarza/dev-stack/internal/pkg/cli/types"
+	"github.com/isaacgarza/dev-stack/internal/pkg/compose"
+	"github.com/isaacgarza/dev-stack/internal/pkg/constants"
+	"github.com/isaacgarza/dev-stack/internal/pkg/export"
+	"github.com/isaacgarza/dev-stack/internal/pkg/ui"
+	"github.com/isaacgarza/dev-stack/internal/pkg/utils"
+	"github.com/spf13/cobra"
+)
+
+// ExportKubernetesHandler handles the export kubernetes command
+type ExportKubernetesHandler struct{}
+
+// NewExportKubernetesHandler creates a new export kubernetes handler
+func NewExportKubernetesHandler() *ExportKubernetesHandler {
+	return &ExportKubernetesHandler{}
+}
+
+// Handle executes the export kubernetes command. It converts the services
+// up would start, with their dependencies, to Kubernetes manifests printed
+// or written to a file, or to a kustomize base written to a directory.
+func (h *ExportKubernetesHandler) Handle(ctx context.Context, cmd *cobra.Command, args []string, base *cliTypes.BaseCommand) error {
+	format, _ := cmd.Flags().GetString("format")
+	output, _ := cmd.Flags().GetString("output")
+	namespace, _ := cmd.Flags().GetString("namespace")
+	volumeSize, _ := cmd.Flags().GetString("volume-size")
+	if !slices.Contains(export.KubernetesFormats(), format) {
+		return fmt.Errorf("unsupported format %q (supported: %v)", format, export.KubernetesFormats())
+	}
+	if format == export.FormatKustomize && output == "" {
+		return errors.New("the kustomize format needs --output, the directory to write the base to")
+	}
+
+	cfg, err := loadExportConfig()
+	if err != nil {
+		return err
+	}
+	definitions, skipped, err := exportDefinitions(cfg, args, ActiveProfile(cmd))
+	if err != nil {
+		return err
+	}
+	if len(skipped) > 0 {
+		exportWarning(cmd, "Skipping services the compose files don't define: %s", strings.Join(skipped, ", "))
+	}
+	manifests, err := export.Kubernetes(definitions, export.KubernetesOptions{
+		Project:    cfg.Project.Name,
+		Namespace:  namespace,
+		VolumeSize: volumeSize,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to export the services: %w", err)
+	}
+	for _, warning := range manifests.Warnings {
+		exportWarning(cmd, "%s", warning)
+	}
+
+	if format == export.FormatKustomize {
+		files, err := manifests.Kustomize()
+		if err != nil {
+			return fmt.Errorf("failed to render the manifests: %w", err)
+		}
+		if err := os.MkdirAll(output, 0755); err != nil {
+			return fmt.Errorf("failed to create %s: %w", output, err)
+		}
+		for name, data := range files {
+			if err := os.WriteFile(filepath.Join(output, name), data, 0644); err != nil {
+				return fmt.Errorf("failed to write manifests: %w", err)
+			}
+		}
+		ui.Success("Wrote a kustomize base of %d services to %s", len(manifests.Services), output)
+		return nil
+	}
+
+	data, err := manifests.YAML()
+	if err != nil {
+		return fmt.Errorf("failed to render the manifests: %w", err)
+	}
+	if output == "" {
+		_, err := cmd.OutOrStdout().Write(data)
+		return err
+	}
+	if err := os.WriteFile(output, data, 0644); err != nil {
+		return fmt.Errorf("failed to write manifests: %w", err)
+	}
+	ui.Success("Wrote the manifests of %d services to %s", len(manifests.Services), output)
+	return nil
+}
+
+// ValidateArgs validates the command arguments
+func (h *ExportKubernetesHandler) ValidateArgs(args []string) error {
+	return nil
+}
+
+// GetRequiredFlags returns required flags for this command
+func (h *ExportKubernetesHandler) GetRequiredFlags() []string {
+	return []string{}
+}
+
+// loadExportConfig loads the configuration of the project to export,
+// which needs its compose files
+func loadExportConfig() (*ProjectConfig, error) {
+	configPath := filepath.Join(constants.DevStackDir, constants.ConfigFileName)
+	if !utils.FileExists(configPath) {
+		return nil, errors.New(constants.ErrNotInitialized)
+	}
+	cfg, err := LoadProjectConfig(configPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load configuration: %w", err)
+	}
+	if !utils.FileExists(constants.DockerComposeFile) {
+		return nil, fmt.Errorf("exporting needs a compose file; run '%s' first", constants.CmdInit)
+	}
+	return cfg, nil
+}
+
+// exportDefinitions returns the definitions, in the compose files, of the
+// services up would start given args and profile, together with the
+// services they depend on, and the services the compose files don't define
+func exportDefinitions(cfg *ProjectConfig, args []string, profile string) ([]compose.Definition, []string, error) {
+	serviceNames, err := selectedServices(cfg, args, profile)
+	if err != nil {
+		return nil, nil, err
+	}
+	dependencies, err := compose.ServiceDependencies(docker.ComposeFiles()...)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read service dependencies: %w", err)
+	}
+	waves, err := compose.StartupWaves(serviceNames, dependencies, true)
+	if err != nil {
+		return nil, nil, err
+	}
+	selected := slices.Concat(waves...)
+
+	dotEnv, err := loadProjectDotEnv(filepath.Join(constants.DevStackDir, constants.ConfigFileName))
+	if err != nil {
+		return nil, nil, err
+	}
+	definitions, err := compose.Definitions(utils.EnvLookup(dotEnv), docker.ComposeFiles()...)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read the compose files: %w", err)
+	}
+
+	var result []compose.Definition
+	for _, def := range definitions {
+		if slices.Contains(selected, def.Name) {
+			result = append(result, def)
+			selected = slices.DeleteFunc(selected, func(name string) bool { return name == def.Name })
+		}
+	}
+	if len(result) == 0 {
+		return nil, nil, errors.New("no services to export")
+	}
+	return result, selected, nil
+}
+
+// exportWarning reports what couldn't be exported on stderr, keeping stdout
+// for the exported files so they can be piped
+func exportWarning(cmd *cobra.Command, format string, args ...interface{}) {
+	_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Warning: "+format+"\n", args...)
+}
+
+// ExportNixHandler handles the export nix command
+type ExportNixHandler struct{}
+
+// NewExportNixHandler creates a new export nix handler
+func NewExportNixHandler() *ExportNixHandler {
+	return &ExportNixHandler{}
+}
+
+// Handle executes the export nix command. It writes a devenv.nix running
+// the services devenv has a service for, and reports how each service of
+// the stack maps.
+func (h *ExportNixHandler) Handle(ctx context.Context, cmd *cobra.Command, args []string, base *cliTypes.BaseCommand) error {
+	output, _ := cmd.Flags().GetString("output")
+	force, _ := cmd.Flags().GetBool("force")
+
+	cfg, err := loadExportConfig()
+	if err != nil {
+		return err
+	}
+	definitions, skipped, err := exportDefinitions(cfg, args, ActiveProfile(cmd))
+	if err != nil {
+		return err
+	}
+	if len(skipped) > 0 {
+		exportWarning(cmd, "Skipping services the compose files don't define: %s", strings.Join(skipped, ", "))
+	}
+
+	names := make([]string, 0, len(definitions))
+	for _, def := range definitions {
+		names = append(names, def.Name)
+	}
+	env, err := ServiceConnectionEnv(filepath.Join(constants.DevStackDir, constants.ConfigFileName), names)
+	if err != nil {
+		return err
+	}
+	data, mappings := export.Devenv(definitions, env)
+
+	report := cmd.OutOrStdout()
+	if output == "-" {
+		report = cmd.ErrOrStderr()
+		if _, err := cmd.OutOrStdout().Write(data); err != nil {
+			return err
+		}
+	} else {
+		if existing, err := os.ReadFile(output); err == nil && !force && !bytes.HasPrefix(existing, []byte(export.DevenvHeader)) {
+			return fmt.Errorf("%s exists and wasn't written by dev-stack; use --force to replace it", output)
+		}
+		if err := os.WriteFile(output, data, 0644); err != nil {
+			return fmt.Errorf("failed to write %s: %w", output, err)
+		}
+		ui.Success("Wrote %s", output)
+	}
+
+	w := tabwriter.NewWriter(report, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "SERVICE\tDEVENV\tNOTES")
+	unmapped := 0
+	for _, mapping := range mappings {
+		module := "services." + mapping.Module
+		if !mapping.Mapped() {
+			module = "-"
+			unmapped++
+		}
+		notes := strings.Join(mapping.Notes, "; ")
+		if notes == "" {
+			notes = "-"
+		}
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\n", mapping.Service, module, notes)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	if unmapped > 0 {
+		exportWarning(cmd, "%d of %d services have no devenv equivalent and were left out", unmapped, len(mappings))
+	}
+	return nil
+}
+
+// ValidateArgs validates the command arguments
+func (h *ExportNixHandler) ValidateArgs(args []string) error {
+	return nil
+}
+
+// GetRequiredFlags returns required flags for this command
+func (h *ExportNixHandler) GetRequiredFlags() []string {
+	return []string{}
+}
@@ -0,0 +1,146 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/isaacgarza/dev-stack/internal/core/docker"
+	cliTypes "github.com/isaacgarza/dev-stack/internal/pkg/cli/types"
+	"github.com/isaacgarza/dev-stack/internal/pkg/types"
+	"github.com/isaacgarza/dev-stack/internal/pkg/ui"
+	"github.com/spf13/cobra"
+)
+
+// GCHandler handles the gc command, removing ephemeral stacks whose TTL has
+// passed
+type GCHandler struct{}
+
+// NewGCHandler creates a new gc handler
+func NewGCHandler() *GCHandler {
+	return &GCHandler{}
+}
+
+// gcOptions controls a collection pass
+type gcOptions struct {
+	dryRun  bool
+	all     bool
+	project string
+}
+
+// Handle executes the gc command
+func (h *GCHandler) Handle(ctx context.Context, cmd *cobra.Command, args []string, base *cliTypes.BaseCommand) error {
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+	all, _ := cmd.Flags().GetBool("all")
+	watch, _ := cmd.Flags().GetBool("watch")
+	intervalValue, _ := cmd.Flags().GetString("interval")
+	project, _ := cmd.Flags().GetString("project")
+
+	interval, err := time.ParseDuration(intervalValue)
+	if err != nil || interval <= 0 {
+		return fmt.Errorf("invalid interval %q: must be a positive duration", intervalValue)
+	}
+
+	options := gcOptions{dryRun: dryRun, all: all}
+	if project != "" {
+		options.project = docker.NormalizeProjectName(project)
+	}
+
+	logger := base.Logger.(loggerAdapter)
+	dockerClient, err := docker.Shared(logger.SlogLogger())
+	if err != nil {
+		return fmt.Errorf("failed to create Docker client: %w", err)
+	}
+	defer func() {
+		if err := dockerClient.Close(); err != nil {
+			base.Logger.Error("Failed to close Docker client", "error", err)
+		}
+	}()
+
+	if !watch {
+		_, err := h.collect(ctx, dockerClient, options)
+		return err
+	}
+
+	// The reaper started by `up --ephemeral` clears its pid file on the way out
+	defer clearPIDFile(reaperPIDFile)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		remaining, err := h.collect(ctx, dockerClient, options)
+		if err != nil {
+			base.Logger.Error("Garbage collection failed", "error", err)
+		} else if options.project != "" && remaining == 0 {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// collect removes the expired ephemeral stacks matching options and
+// returns how many matching stacks are left running
+func (h *GCHandler) collect(ctx context.Context, client *docker.Client, options gcOptions) (int, error) {
+	stacks, err := client.Containers().EphemeralStacks(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	now := time.Now()
+	var remaining int
+	var failed []string
+	for _, stack := range stacks {
+		if options.project != "" && stack.Project != options.project {
+			continue
+		}
+		if !options.all && !stack.Expired(now) {
+			ui.Muted("%s expires in %s", stack.Project, stack.ExpiresAt.Sub(now).Round(time.Second))
+			remaining++
+			continue
+		}
+		if options.dryRun {
+			ui.Info("Would remove %s", describeStack(stack, now))
+			remaining++
+			continue
+		}
+		if err := removeEphemeralStack(ctx, client, stack); err != nil {
+			failed = append(failed, stack.Project)
+			remaining++
+			continue
+		}
+		ui.Success("Removed %s", describeStack(stack, now))
+	}
+
+	if len(failed) > 0 {
+		return remaining, fmt.Errorf("failed to remove ephemeral stacks: %s", strings.Join(failed, ", "))
+	}
+	return remaining, nil
+}
+
+// describeStack names a stack for gc output
+func describeStack(stack types.EphemeralStack, now time.Time) string {
+	if !stack.Expired(now) {
+		return fmt.Sprintf("%s (%d containers, expires in %s)", stack.Project, len(stack.Containers), stack.ExpiresAt.Sub(now).Round(time.Second))
+	}
+	return fmt.Sprintf("%s (%d containers, expired %s ago)", stack.Project, len(stack.Containers), now.Sub(stack.ExpiresAt).Round(time.Second))
+}
+
+// ValidateArgs validates the command arguments
+func (h *GCHandler) ValidateArgs(args []string) error {
+	if len(args) > 0 {
+		return fmt.Errorf("gc takes no arguments")
+	}
+	return nil
+}
+
+// GetRequiredFlags returns required flags for this command
+func (h *GCHandler) GetRequiredFlags() []string {
+	return []string{}
+}
@@ -0,0 +1,74 @@
+package core
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/isaacgarza/dev-stack/internal/pkg/cli/handlers/utils"
+	pkgConfig "github.com/isaacgarza/dev-stack/internal/pkg/config"
+	"github.com/isaacgarza/dev-stack/internal/pkg/constants"
+	"github.com/isaacgarza/dev-stack/internal/pkg/ui"
+	pkgUtils "github.com/isaacgarza/dev-stack/internal/pkg/utils"
+	"github.com/spf13/cobra"
+)
+
+// ActiveProfile returns the profile selected via --profile or
+// DEV_STACK_PROFILE, falling back to the default_profile user setting
+func ActiveProfile(cmd *cobra.Command) string {
+	if flag := cmd.Flags().Lookup("profile"); flag != nil && flag.Value.String() != "" {
+		return flag.Value.String()
+	}
+	if profile := os.Getenv(constants.EnvProfile); profile != "" {
+		return profile
+	}
+	if user, err := pkgConfig.LoadUserConfig(); err == nil {
+		return user.DefaultProfile
+	}
+	return ""
+}
+
+// IsProtected reports whether the project or the given profile is marked protected
+func (c *ProjectConfig) IsProtected(profile string) bool {
+	if c.Project.Protected {
+		return true
+	}
+	if p, ok := c.Profiles[profile]; ok {
+		return p.Protected
+	}
+	return false
+}
+
+// ConfirmProtected guards a destructive action when the active context is
+// protected. The action proceeds if --i-know is set; otherwise the user must
+// type the project name, and non-interactive runs are refused outright.
+func ConfirmProtected(cmd *cobra.Command, cfg *ProjectConfig, action string) error {
+	profile := ActiveProfile(cmd)
+	if !cfg.IsProtected(profile) {
+		return nil
+	}
+
+	target := cfg.Project.Name
+	if profile != "" {
+		target = fmt.Sprintf("%s (profile %s)", cfg.Project.Name, profile)
+	}
+
+	if iKnow, _ := cmd.Flags().GetBool(constants.FlagIKnow); iKnow {
+		ui.Warning("Proceeding to %s in protected context %s", action, target)
+		return nil
+	}
+
+	flags := utils.GetCIFlags(cmd)
+	if flags.NonInteractive || flags.Quiet || flags.JSON {
+		return fmt.Errorf("refusing to %s in protected context %s; pass --%s to confirm", action, target, constants.FlagIKnow)
+	}
+
+	ui.Warning("%s is protected. This will %s.", target, action)
+	answer, err := pkgUtils.PromptInput(fmt.Sprintf("Type the project name (%s) to continue", cfg.Project.Name))
+	if err != nil {
+		return fmt.Errorf("failed to read confirmation: %w", err)
+	}
+	if answer != cfg.Project.Name {
+		return fmt.Errorf("confirmation did not match; aborted %s", action)
+	}
+	return nil
+}
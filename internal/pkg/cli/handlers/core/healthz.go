@@ -0,0 +1,93 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"time"
+
+	"github.com/isaacgarza/dev-stack/internal/core/docker"
+	"github.com/isaacgarza/dev-stack/internal/core/healthz"
+	cliTypes "github.com/isaacgarza/dev-stack/internal/pkg/cli/types"
+	"github.com/isaacgarza/dev-stack/internal/pkg/constants"
+	"github.com/isaacgarza/dev-stack/internal/pkg/ui"
+	"github.com/isaacgarza/dev-stack/internal/pkg/utils"
+	"github.com/spf13/cobra"
+)
+
+// HealthzHandler handles the healthz command, serving the aggregated health
+// of the stack on a single HTTP endpoint
+type HealthzHandler struct{}
+
+// NewHealthzHandler creates a new healthz handler
+func NewHealthzHandler() *HealthzHandler {
+	return &HealthzHandler{}
+}
+
+// Handle executes the healthz command
+func (h *HealthzHandler) Handle(ctx context.Context, cmd *cobra.Command, args []string, base *cliTypes.BaseCommand) error {
+	listen, _ := cmd.Flags().GetString("listen")
+	projectName, _ := cmd.Flags().GetString("project")
+
+	// Outside a container the project name comes from the local configuration
+	if projectName == "" {
+		configPath := filepath.Join(constants.DevStackDir, constants.ConfigFileName)
+		if !utils.FileExists(configPath) {
+			return errors.New(constants.ErrNotInitialized)
+		}
+		cfg, err := LoadProjectConfig(configPath)
+		if err != nil {
+			return fmt.Errorf("failed to load configuration: %w", err)
+		}
+		projectName = cfg.Project.Name
+	}
+
+	logger := base.Logger.(loggerAdapter)
+	dockerClient, err := docker.Shared(logger.SlogLogger())
+	if err != nil {
+		return fmt.Errorf("failed to create Docker client: %w", err)
+	}
+	defer func() {
+		if err := dockerClient.Close(); err != nil {
+			base.Logger.Error("Failed to close Docker client", "error", err)
+		}
+	}()
+
+	aggregator := healthz.NewAggregator(dockerClient.Containers(), projectName, constants.ServiceHealthz)
+	server := &http.Server{
+		Addr:              listen,
+		Handler:           aggregator.Handler(),
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- server.ListenAndServe()
+	}()
+
+	ui.Info("Serving health of %s on %s/healthz", projectName, listen)
+
+	select {
+	case err := <-errCh:
+		if !errors.Is(err, http.ErrServerClosed) {
+			return fmt.Errorf("health server failed: %w", err)
+		}
+		return nil
+	case <-ctx.Done():
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		return server.Shutdown(shutdownCtx)
+	}
+}
+
+// ValidateArgs validates the command arguments
+func (h *HealthzHandler) ValidateArgs(args []string) error {
+	return nil
+}
+
+// GetRequiredFlags returns required flags for this command
+func (h *HealthzHandler) GetRequiredFlags() []string {
+	return []string{}
+}
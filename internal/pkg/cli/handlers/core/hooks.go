@@ -0,0 +1,70 @@
+package core
+
+import (
+	"fmt"
+
+	"github.com/isaacgarza/dev-stack/internal/core/hostnames"
+	"github.com/isaacgarza/dev-stack/internal/core/services"
+	"github.com/isaacgarza/dev-stack/internal/pkg/cli/handlers/utils"
+	pkgUtils "github.com/isaacgarza/dev-stack/internal/pkg/utils"
+)
+
+// ConfigureHooks validates the project's lifecycle hooks and hands them to
+// the manager together with the connection details of the enabled services
+func ConfigureHooks(manager *services.Manager, cfg *ProjectConfig, configPath string) error {
+	if len(cfg.Hooks) == 0 {
+		return nil
+	}
+	if manager == nil {
+		return fmt.Errorf("lifecycle hooks are not supported by this command")
+	}
+	if err := services.ValidateHooks(cfg.Hooks); err != nil {
+		return fmt.Errorf("invalid hooks configuration: %w", err)
+	}
+
+	env, err := ServiceConnectionEnv(configPath, cfg.Stack.Enabled)
+	if err != nil {
+		return err
+	}
+
+	manager.SetProjectName(cfg.Project.Name)
+	manager.SetHooks(cfg.Hooks, env)
+	return nil
+}
+
+// ServiceConnectionEnv returns the connection variables declared by the
+// given services, such as DATABASE_URL or REDIS_URL, with ${VAR:-default}
+// references resolved against the environment and the project's .env file.
+// When several services declare the same variable the first one wins.
+// Projects with hostnames enabled get them in place of localhost.
+func ServiceConnectionEnv(configPath string, serviceNames []string) (map[string]string, error) {
+	cfg, err := LoadProjectConfig(configPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load configuration: %w", err)
+	}
+	dotEnv, err := loadProjectDotEnv(configPath)
+	if err != nil {
+		return nil, err
+	}
+	lookup := pkgUtils.EnvLookup(dotEnv)
+
+	serviceUtils := utils.NewServiceUtils()
+	env := make(map[string]string)
+	for _, serviceName := range serviceNames {
+		serviceConfig, err := serviceUtils.LoadServiceConfig(serviceName)
+		if err != nil {
+			continue
+		}
+		for key, value := range serviceConfig.Environment {
+			if _, exists := env[key]; exists {
+				continue
+			}
+			value = pkgUtils.ExpandEnv(value, lookup)
+			if cfg.Hostnames.Enabled {
+				value = hostnames.Rewrite(value, hostnames.Hostname(serviceName, cfg.Project.Name, cfg.Hostnames.Domain))
+			}
+			env[key] = value
+		}
+	}
+	return env, nil
+}
@@ -0,0 +1,179 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"text/tabwriter"
+
+	"github.com/isaacgarza/dev-stack/internal/core/hostnames"
+	cliTypes "github.com/isaacgarza/dev-stack/internal/pkg/cli/types"
+	"github.com/isaacgarza/dev-stack/internal/pkg/constants"
+	"github.com/isaacgarza/dev-stack/internal/pkg/ui"
+	"github.com/isaacgarza/dev-stack/internal/pkg/utils"
+	"github.com/spf13/cobra"
+)
+
+// projectHostnames returns the hostnames of the project's enabled services
+func projectHostnames(cfg *ProjectConfig) []string {
+	names := make([]string, 0, len(cfg.Stack.Enabled))
+	for _, serviceName := range cfg.Stack.Enabled {
+		names = append(names, hostnames.Hostname(serviceName, cfg.Project.Name, cfg.Hostnames.Domain))
+	}
+	return names
+}
+
+// loadHostsConfig loads the project configuration the hosts commands work on
+func loadHostsConfig() (*ProjectConfig, error) {
+	configPath := filepath.Join(constants.DevStackDir, constants.ConfigFileName)
+	if !utils.FileExists(configPath) {
+		return nil, errors.New(constants.ErrNotInitialized)
+	}
+	cfg, err := LoadProjectConfig(configPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load configuration: %w", err)
+	}
+	return cfg, nil
+}
+
+// syncHostnames registers the project's hostnames in the hosts file, or
+// removes them when hostnames are disabled
+func syncHostnames(cfg *ProjectConfig) error {
+	var names []string
+	if cfg.Hostnames.Enabled {
+		names = projectHostnames(cfg)
+	}
+
+	path := hostnames.HostsFile()
+	changed, err := hostnames.Sync(path, cfg.Project.Name, names)
+	if err != nil {
+		if errors.Is(err, os.ErrPermission) {
+			return fmt.Errorf("%w; run 'sudo %s' to update it", err, constants.CmdRef(constants.CmdNameHostsSync))
+		}
+		return err
+	}
+	if changed && len(names) > 0 {
+		ui.Success("Registered %d hostname(s) in %s", len(names), path)
+	} else if changed {
+		ui.Success("Removed the hostnames of %s from %s", cfg.Project.Name, path)
+	}
+	return nil
+}
+
+// HostsSyncHandler handles the hosts sync command
+type HostsSyncHandler struct{}
+
+// NewHostsSyncHandler creates a new hosts sync handler
+func NewHostsSyncHandler() *HostsSyncHandler {
+	return &HostsSyncHandler{}
+}
+
+// Handle executes the hosts sync command
+func (h *HostsSyncHandler) Handle(ctx context.Context, cmd *cobra.Command, args []string, base *cliTypes.BaseCommand) error {
+	cfg, err := loadHostsConfig()
+	if err != nil {
+		return err
+	}
+	if !cfg.Hostnames.Enabled {
+		ui.Info("Hostnames are disabled; set hostnames.enabled in %s to register them", constants.ConfigFileName)
+	}
+	return syncHostnames(cfg)
+}
+
+// ValidateArgs validates the command arguments
+func (h *HostsSyncHandler) ValidateArgs(args []string) error {
+	return nil
+}
+
+// GetRequiredFlags returns required flags for this command
+func (h *HostsSyncHandler) GetRequiredFlags() []string {
+	return []string{}
+}
+
+// HostsRemoveHandler handles the hosts remove command
+type HostsRemoveHandler struct{}
+
+// NewHostsRemoveHandler creates a new hosts remove handler
+func NewHostsRemoveHandler() *HostsRemoveHandler {
+	return &HostsRemoveHandler{}
+}
+
+// Handle executes the hosts remove command
+func (h *HostsRemoveHandler) Handle(ctx context.Context, cmd *cobra.Command, args []string, base *cliTypes.BaseCommand) error {
+	cfg, err := loadHostsConfig()
+	if err != nil {
+		return err
+	}
+
+	path := hostnames.HostsFile()
+	changed, err := hostnames.Sync(path, cfg.Project.Name, nil)
+	if err != nil {
+		if errors.Is(err, os.ErrPermission) {
+			return fmt.Errorf("%w; run 'sudo %s' to update it", err, constants.CmdRef(constants.CmdNameHostsRemove))
+		}
+		return err
+	}
+	if !changed {
+		ui.Info("No hostnames of %s in %s", cfg.Project.Name, path)
+		return nil
+	}
+	ui.Success("Removed the hostnames of %s from %s", cfg.Project.Name, path)
+	return nil
+}
+
+// ValidateArgs validates the command arguments
+func (h *HostsRemoveHandler) ValidateArgs(args []string) error {
+	return nil
+}
+
+// GetRequiredFlags returns required flags for this command
+func (h *HostsRemoveHandler) GetRequiredFlags() []string {
+	return []string{}
+}
+
+// HostsListHandler handles the hosts list command
+type HostsListHandler struct{}
+
+// NewHostsListHandler creates a new hosts list handler
+func NewHostsListHandler() *HostsListHandler {
+	return &HostsListHandler{}
+}
+
+// Handle executes the hosts list command
+func (h *HostsListHandler) Handle(ctx context.Context, cmd *cobra.Command, args []string, base *cliTypes.BaseCommand) error {
+	cfg, err := loadHostsConfig()
+	if err != nil {
+		return err
+	}
+
+	path := hostnames.HostsFile()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	registered := hostnames.Registered(string(data), cfg.Project.Name)
+
+	w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "SERVICE\tHOSTNAME\tREGISTERED")
+	for i, hostname := range projectHostnames(cfg) {
+		status := "no"
+		if slices.Contains(registered, hostname) {
+			status = "yes"
+		}
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\n", cfg.Stack.Enabled[i], hostname, status)
+	}
+	return w.Flush()
+}
+
+// ValidateArgs validates the command arguments
+func (h *HostsListHandler) ValidateArgs(args []string) error {
+	return nil
+}
+
+// GetRequiredFlags returns required flags for this command
+func (h *HostsListHandler) GetRequiredFlags() []string {
+	return []string{}
+}
@@ -0,0 +1,64 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/isaacgarza/dev-stack/internal/core/daemon"
+	"github.com/isaacgarza/dev-stack/internal/core/services"
+	cliTypes "github.com/isaacgarza/dev-stack/internal/pkg/cli/types"
+	"github.com/isaacgarza/dev-stack/internal/pkg/constants"
+	"github.com/isaacgarza/dev-stack/internal/pkg/logger"
+	"github.com/isaacgarza/dev-stack/internal/pkg/utils"
+	"github.com/isaacgarza/dev-stack/internal/pkg/version"
+	"github.com/spf13/cobra"
+)
+
+// IDEServerHandler handles the ide-server command, speaking JSON-RPC over
+// stdio for editor plugins
+type IDEServerHandler struct {
+	manager *services.Manager
+}
+
+// NewIDEServerHandler creates a new ide-server handler
+func NewIDEServerHandler(manager *services.Manager) *IDEServerHandler {
+	return &IDEServerHandler{manager: manager}
+}
+
+// Handle executes the ide-server command
+func (h *IDEServerHandler) Handle(ctx context.Context, cmd *cobra.Command, args []string, base *cliTypes.BaseCommand) error {
+	configPath := filepath.Join(constants.DevStackDir, constants.ConfigFileName)
+	if !utils.FileExists(configPath) {
+		return errors.New(constants.ErrNotInitialized)
+	}
+	cfg, err := LoadProjectConfig(configPath)
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	// stdout carries the protocol, so everything else the process prints,
+	// including logs and docker compose output, goes to stderr
+	protocolOut := os.Stdout
+	os.Stdout = os.Stderr
+	logger.SetTextOutput(os.Stderr)
+	defer func() { os.Stdout = protocolOut }()
+
+	h.manager.SetProjectName(cfg.Project.Name)
+	slogLogger := base.Logger.(loggerAdapter).SlogLogger()
+	server := daemon.NewRPCServer(h.manager, cfg.Project.Name, version.GetAppVersion(), slogLogger)
+	slogLogger.Info("IDE server ready", "project", cfg.Project.Name, "protocol", daemon.RPCProtocolVersion)
+	return server.Serve(ctx, os.Stdin, protocolOut)
+}
+
+// ValidateArgs validates the command arguments
+func (h *IDEServerHandler) ValidateArgs(args []string) error {
+	return nil
+}
+
+// GetRequiredFlags returns required flags for this command
+func (h *IDEServerHandler) GetRequiredFlags() []string {
+	return []string{}
+}
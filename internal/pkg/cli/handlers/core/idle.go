@@ -0,0 +1,466 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"net"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/isaacgarza/dev-stack/internal/core/docker"
+	"github.com/isaacgarza/dev-stack/internal/core/idle"
+	"github.com/isaacgarza/dev-stack/internal/core/projectstate"
+	"github.com/isaacgarza/dev-stack/internal/core/services"
+	handlerUtils "github.com/isaacgarza/dev-stack/internal/pkg/cli/handlers/utils"
+	cliTypes "github.com/isaacgarza/dev-stack/internal/pkg/cli/types"
+	"github.com/isaacgarza/dev-stack/internal/pkg/constants"
+	"github.com/isaacgarza/dev-stack/internal/pkg/types"
+	"github.com/isaacgarza/dev-stack/internal/pkg/ui"
+	"github.com/isaacgarza/dev-stack/internal/pkg/utils"
+	"github.com/spf13/cobra"
+)
+
+// idlePIDFile records the background idle reaper watching the project's
+// services
+var idlePIDFile = filepath.Join(constants.DevStackDir, constants.TmpDir, "idle.pid")
+
+// Timing of the idle reaper
+const (
+	// idleSyncInterval is how often the reaper releases the ports of the
+	// services another command woke, and retries a busy project lock
+	idleSyncInterval = time.Second
+	// idleReleaseTimeout bounds how long waking a stopped service waits for
+	// the reaper to release its ports
+	idleReleaseTimeout = 5 * time.Second
+)
+
+// IdleHandler handles the idle command, which shows the services the idle
+// reaper put to sleep or, with --watch, runs the reaper
+type IdleHandler struct{}
+
+// NewIdleHandler creates a new idle handler
+func NewIdleHandler() *IdleHandler {
+	return &IdleHandler{}
+}
+
+// Handle executes the idle command
+func (h *IdleHandler) Handle(ctx context.Context, cmd *cobra.Command, args []string, base *cliTypes.BaseCommand) error {
+	watch, _ := cmd.Flags().GetBool("watch")
+	intervalValue, _ := cmd.Flags().GetString("interval")
+
+	interval, err := time.ParseDuration(intervalValue)
+	if err != nil || interval <= 0 {
+		return fmt.Errorf("invalid interval %q: must be a positive duration", intervalValue)
+	}
+
+	configPath := filepath.Join(constants.DevStackDir, constants.ConfigFileName)
+	if !utils.FileExists(configPath) {
+		return errors.New(constants.ErrNotInitialized)
+	}
+	cfg, err := LoadProjectConfig(configPath)
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	if !watch {
+		return showIdle(cfg, configPath)
+	}
+
+	logger := base.Logger.(loggerAdapter)
+	dockerClient, err := docker.Shared(logger.SlogLogger())
+	if err != nil {
+		return fmt.Errorf("failed to create Docker client: %w", err)
+	}
+	defer func() {
+		if err := dockerClient.Close(); err != nil {
+			base.Logger.Error("Failed to close Docker client", "error", err)
+		}
+	}()
+
+	// The reaper started by up clears its pid file on the way out
+	defer clearPIDFile(idlePIDFile)
+
+	shutdown, err := shutdownSettings(cfg)
+	if err != nil {
+		return err
+	}
+	reaper := &idleReaper{
+		containers: dockerClient.Containers(),
+		cfg:        cfg,
+		shutdown:   shutdown,
+		path:       projectstate.Path(),
+		tracker:    idle.NewTracker(cfg.Idle.IdleTimeout()),
+		logger:     logger.SlogLogger(),
+	}
+	return reaper.run(ctx, interval)
+}
+
+// showIdle prints the idle settings and the services asleep
+func showIdle(cfg *ProjectConfig, configPath string) error {
+	state, err := projectstate.Load(projectstate.Path())
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case !cfg.Idle.Enabled:
+		ui.Info("Idle reaper disabled; set idle.enabled in %s to enable it", configPath)
+	case backgroundRunning(idlePIDFile):
+		ui.Info("Idle reaper running: services idle for %s are %s", cfg.Idle.IdleTimeout(), idleVerb(cfg.Idle.IdleAction()))
+	default:
+		ui.Info("Idle reaper not running; run '%s' to start it", constants.CmdUp)
+	}
+
+	if len(state.Idle) == 0 {
+		ui.Info("No services asleep")
+		return nil
+	}
+	now := time.Now()
+	for _, name := range slices.Sorted(maps.Keys(state.Idle)) {
+		entry := state.Idle[name]
+		ui.Muted("%s %s %s ago", name, idleVerb(entry.Action), now.Sub(entry.Since).Round(time.Second))
+	}
+	ui.Info("Connecting to them, or running '%s', wakes them", constants.CmdUp)
+	return nil
+}
+
+// idleVerb describes what the reaper does for action
+func idleVerb(action string) string {
+	if action == types.IdleActionPause {
+		return "paused"
+	}
+	return "stopped"
+}
+
+// idleReaper puts the services of a project nothing connects to to sleep,
+// and starts stopped ones again on the next connection to their ports
+type idleReaper struct {
+	containers *docker.ContainerService
+	cfg        *ProjectConfig
+	shutdown   map[string]types.ShutdownConfig
+	path       string
+	tracker    *idle.Tracker
+	waker      *idle.Waker
+	logger     *slog.Logger
+}
+
+// run samples the connections every interval until ctx is done, or until the
+// project has neither running nor sleeping services
+func (r *idleReaper) run(ctx context.Context, interval time.Duration) error {
+	ctx, cancel := context.WithCancel(ctx)
+	r.waker = idle.NewWaker(ctx, r.wake, r.logger)
+	defer func() {
+		cancel()
+		r.waker.Close()
+	}()
+
+	syncTicker := time.NewTicker(idleSyncInterval)
+	defer syncTicker.Stop()
+	sample := time.NewTicker(interval)
+	defer sample.Stop()
+
+	for {
+		if done := r.sample(ctx); done {
+			return nil
+		}
+		for waiting := true; waiting; {
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-syncTicker.C:
+				r.sync()
+			case <-sample.C:
+				waiting = false
+			}
+		}
+	}
+}
+
+// sync holds the ports of the stopped services and releases those of the
+// services another command woke
+func (r *idleReaper) sync() {
+	state, err := projectstate.Load(r.path)
+	if err != nil {
+		r.logger.Error("Failed to load the project state", "error", err)
+		return
+	}
+	for _, name := range r.waker.Services() {
+		if _, ok := state.Idle[name]; !ok {
+			r.waker.Release(name)
+		}
+	}
+	for name, entry := range state.Idle {
+		if entry.Action == types.IdleActionStop && len(entry.Ports) > 0 {
+			if err := r.waker.Hold(name, entry.Ports); err != nil {
+				r.logger.Warn("Failed to listen on the ports of a stopped service", "service", name, "error", err)
+			}
+		}
+	}
+}
+
+// sample records the connections to the running services and puts those
+// idle for the timeout to sleep. It reports when there is nothing left to
+// watch.
+func (r *idleReaper) sample(ctx context.Context) bool {
+	activity, err := r.containers.Activity(ctx, r.cfg.Project.Name)
+	if err != nil {
+		r.logger.Error("Failed to sample connections", "error", err)
+		return false
+	}
+	state, err := projectstate.Load(r.path)
+	if err != nil {
+		r.logger.Error("Failed to load the project state", "error", err)
+		return false
+	}
+	if len(activity) == 0 && len(state.Idle) == 0 {
+		r.logger.Info("No services left to watch")
+		return true
+	}
+
+	// A service started by hand, as with docker start, is awake again
+	var awake []string
+	for _, service := range activity {
+		if _, ok := state.Idle[service.Service]; ok {
+			awake = append(awake, service.Service)
+		}
+	}
+	if len(awake) > 0 {
+		err := projectstate.Update(r.path, func(state *projectstate.State) error {
+			for _, name := range awake {
+				delete(state.Idle, name)
+			}
+			return nil
+		})
+		if err != nil {
+			r.logger.Error("Failed to update the project state", "error", err)
+		}
+	}
+
+	var sleepy []types.ServiceActivity
+	for _, name := range r.tracker.Observe(time.Now(), activity) {
+		if !r.cfg.Idle.Watches(name) {
+			continue
+		}
+		for _, service := range activity {
+			if service.Service == name {
+				sleepy = append(sleepy, service)
+			}
+		}
+	}
+	if len(sleepy) > 0 {
+		r.sleep(ctx, sleepy)
+	}
+	return false
+}
+
+// sleep stops or pauses the idle services, recording them in the project
+// state. A project locked by another command is left for the next sample.
+func (r *idleReaper) sleep(ctx context.Context, sleepy []types.ServiceActivity) {
+	lock, err := projectstate.Acquire(r.path, constants.CmdNameIdle)
+	if err != nil {
+		r.logger.Info("Project busy, leaving idle services running", "error", err)
+		return
+	}
+
+	names := make([]string, 0, len(sleepy))
+	for _, service := range sleepy {
+		names = append(names, service.Service)
+	}
+	action := r.cfg.Idle.IdleAction()
+	now := time.Now().UTC()
+	slept := make(map[string]projectstate.IdleService)
+
+	switch action {
+	case types.IdleActionPause:
+		paused, err := r.containers.Pause(ctx, r.cfg.Project.Name, names)
+		if err != nil {
+			r.logger.Error("Failed to pause idle services", "services", names, "error", err)
+		}
+		for _, name := range paused {
+			slept[name] = projectstate.IdleService{Action: action, Since: now}
+		}
+	default:
+		report, err := r.containers.Shutdown(ctx, r.cfg.Project.Name, names, types.ShutdownOptions{
+			Services: r.shutdown,
+			Force:    true,
+		})
+		if err != nil {
+			r.logger.Error("Failed to stop idle services", "services", names, "error", err)
+		}
+		if report != nil {
+			for _, service := range report.Services {
+				if service.Result == types.ShutdownRunning {
+					continue
+				}
+				for _, idleService := range sleepy {
+					if idleService.Service == service.Service {
+						slept[service.Service] = projectstate.IdleService{Action: action, Since: now, Ports: idleService.Ports}
+					}
+				}
+			}
+		}
+	}
+
+	err = lock.Release(func(state *projectstate.State) {
+		if state.Idle == nil {
+			state.Idle = make(map[string]projectstate.IdleService)
+		}
+		maps.Copy(state.Idle, slept)
+	})
+	if err != nil {
+		r.logger.Error("Failed to record idle services", "error", err)
+	}
+	if len(slept) == 0 {
+		return
+	}
+
+	sleptNames := slices.Sorted(maps.Keys(slept))
+	r.logger.Info("Put idle services to sleep", "action", action, "services", sleptNames)
+	ui.Info("Idle services %s: %s", idleVerb(action), strings.Join(sleptNames, ", "))
+	r.sync()
+}
+
+// wake starts a service stopped by the reaper, once no other command holds
+// the project lock
+func (r *idleReaper) wake(ctx context.Context, service string) error {
+	ctx, cancel := context.WithTimeout(ctx, idle.WakeTimeout)
+	defer cancel()
+
+	var lock *projectstate.Handle
+	for {
+		var err error
+		lock, err = projectstate.Acquire(r.path, constants.CmdNameIdle)
+		var locked *projectstate.LockedError
+		if err == nil {
+			break
+		}
+		if !errors.As(err, &locked) {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(idleSyncInterval):
+		}
+	}
+
+	err := r.containers.Start(ctx, r.cfg.Project.Name, []string{service}, types.StartOptions{NoDeps: true, Detach: true})
+	releaseErr := lock.Release(func(state *projectstate.State) {
+		if err == nil {
+			delete(state.Idle, service)
+		}
+	})
+	if err != nil {
+		return fmt.Errorf("failed to start %s: %w", service, err)
+	}
+	if releaseErr != nil {
+		return releaseErr
+	}
+	ui.Info("Woke %s", service)
+	return nil
+}
+
+// startIdleWatcher starts the idle reaper in the background unless one is
+// already running
+func startIdleWatcher(ctx context.Context) error {
+	return startBackground(ctx, idlePIDFile, "idle", constants.CmdNameIdle, "--watch")
+}
+
+// WakeIdle starts or resumes the services the idle reaper put to sleep
+// among serviceNames and their dependencies, or every one when none are
+// named, and returns those woken. Stopped services are waited on until
+// healthy, so the caller can connect to them.
+func WakeIdle(ctx context.Context, manager *services.Manager, serviceNames []string) ([]string, error) {
+	path := projectstate.Path()
+	state, err := projectstate.Load(path)
+	if err != nil || len(state.Idle) == 0 {
+		return nil, err
+	}
+
+	selected := serviceNames
+	if len(serviceNames) > 0 {
+		if resolved, err := handlerUtils.NewServiceUtils().ResolveDependencies(serviceNames); err == nil {
+			selected = resolved
+		}
+	}
+	asleep := make(map[string]projectstate.IdleService)
+	err = projectstate.Update(path, func(state *projectstate.State) error {
+		for name, entry := range state.Idle {
+			if len(selected) == 0 || slices.Contains(selected, name) {
+				asleep[name] = entry
+				delete(state.Idle, name)
+			}
+		}
+		return nil
+	})
+	if err != nil || len(asleep) == 0 {
+		return nil, err
+	}
+
+	var paused, stopped, ports []string
+	for _, name := range slices.Sorted(maps.Keys(asleep)) {
+		entry := asleep[name]
+		if entry.Action == types.IdleActionPause {
+			paused = append(paused, name)
+			continue
+		}
+		stopped = append(stopped, name)
+		ports = append(ports, entry.Ports...)
+	}
+
+	if len(paused) > 0 {
+		if _, err := manager.ResumeServices(ctx, paused); err != nil {
+			return nil, fmt.Errorf("failed to resume idle services: %w", err)
+		}
+	}
+	if len(stopped) > 0 {
+		waitPortsReleased(ctx, ports, idleReleaseTimeout)
+		options := types.StartOptions{NoDeps: true, Timeout: idle.WakeTimeout}
+		if err := manager.StartServices(ctx, stopped, options); err != nil {
+			return nil, fmt.Errorf("failed to wake idle services: %w", err)
+		}
+	}
+	return slices.Sorted(maps.Keys(asleep)), nil
+}
+
+// waitPortsReleased waits up to timeout for the idle reaper to stop
+// listening on addresses, so a service woken by another command can bind
+// them
+func waitPortsReleased(ctx context.Context, addresses []string, timeout time.Duration) {
+	deadline := time.Now().Add(timeout)
+	for _, address := range addresses {
+		for {
+			listener, err := net.Listen("tcp", address)
+			if err == nil {
+				_ = listener.Close()
+				break
+			}
+			if time.Now().After(deadline) {
+				return
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(100 * time.Millisecond):
+			}
+		}
+	}
+}
+
+// ValidateArgs validates the command arguments
+func (h *IdleHandler) ValidateArgs(args []string) error {
+	if len(args) > 0 {
+		return fmt.Errorf("idle takes no arguments")
+	}
+	return nil
+}
+
+// GetRequiredFlags returns required flags for this command
+func (h *IdleHandler) GetRequiredFlags() []string {
+	return []string{}
+}
@@ -0,0 +1,229 @@
+package core
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/isaacgarza/dev-stack/internal/core/docker"
+	cliTypes "github.com/isaacgarza/dev-stack/internal/pkg/cli/types"
+	"github.com/isaacgarza/dev-stack/internal/pkg/constants"
+	"github.com/isaacgarza/dev-stack/internal/pkg/ui"
+	pkgUtils "github.com/isaacgarza/dev-stack/internal/pkg/utils"
+	"github.com/spf13/cobra"
+)
+
+// ImagesExportHandler handles the images export command
+type ImagesExportHandler struct{}
+
+// NewImagesExportHandler creates a new images export handler
+func NewImagesExportHandler() *ImagesExportHandler {
+	return &ImagesExportHandler{}
+}
+
+// Handle executes the images export command
+func (h *ImagesExportHandler) Handle(ctx context.Context, cmd *cobra.Command, args []string, base *cliTypes.BaseCommand) error {
+	if err := h.ValidateArgs(args); err != nil {
+		return err
+	}
+	output, _ := cmd.Flags().GetString("output")
+	noPull, _ := cmd.Flags().GetBool("no-pull")
+
+	cfg, _, err := loadImagesConfig()
+	if err != nil {
+		return err
+	}
+
+	serviceNames := args
+	if len(serviceNames) == 0 {
+		serviceNames = cfg.Stack.Enabled
+	}
+	images := collectServiceImages(serviceNames, cfg.Services.Versions(), cfg.Images.RegistryMirrors)
+	if len(images) == 0 {
+		ui.Info("No images to export")
+		return nil
+	}
+
+	dockerClient, err := newImagesDockerClient(base)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := dockerClient.Close(); err != nil {
+			base.Logger.Error("Failed to close Docker client", "error", err)
+		}
+	}()
+
+	missing, err := dockerClient.Images().Missing(ctx, images)
+	if err != nil {
+		return err
+	}
+	if len(missing) > 0 && !noPull {
+		ui.Info("Pulling %d missing image(s)...", len(missing))
+		if _, err := pullImages(ctx, dockerClient, cfg, missing); err != nil {
+			return fmt.Errorf("failed to pull images: %w", err)
+		}
+		if missing, err = dockerClient.Images().Missing(ctx, missing); err != nil {
+			return err
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("images not available locally: %s", strings.Join(missing, ", "))
+	}
+
+	if err := writeImageArchive(ctx, dockerClient, images, output); err != nil {
+		return err
+	}
+
+	ui.Success("Exported %d image(s) to %s", len(images), output)
+	ui.Info("Load them on another machine with: %s %s", constants.CmdRef(constants.CmdNameImagesImport), output)
+	return nil
+}
+
+// ValidateArgs validates the command arguments
+func (h *ImagesExportHandler) ValidateArgs(args []string) error {
+	return nil
+}
+
+// GetRequiredFlags returns required flags for this command
+func (h *ImagesExportHandler) GetRequiredFlags() []string {
+	return []string{}
+}
+
+// ImagesImportHandler handles the images import command
+type ImagesImportHandler struct{}
+
+// NewImagesImportHandler creates a new images import handler
+func NewImagesImportHandler() *ImagesImportHandler {
+	return &ImagesImportHandler{}
+}
+
+// Handle executes the images import command
+func (h *ImagesImportHandler) Handle(ctx context.Context, cmd *cobra.Command, args []string, base *cliTypes.BaseCommand) error {
+	if err := h.ValidateArgs(args); err != nil {
+		return err
+	}
+	archivePath := args[0]
+
+	file, err := os.Open(archivePath)
+	if err != nil {
+		return fmt.Errorf("failed to open image archive: %w", err)
+	}
+	defer func() { _ = file.Close() }()
+
+	archive, err := decompressArchive(file)
+	if err != nil {
+		return err
+	}
+
+	dockerClient, err := newImagesDockerClient(base)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := dockerClient.Close(); err != nil {
+			base.Logger.Error("Failed to close Docker client", "error", err)
+		}
+	}()
+
+	ui.Info("Loading images from %s...", archivePath)
+	loaded, err := dockerClient.Images().Load(ctx, archive)
+	if err != nil {
+		return err
+	}
+
+	ui.Success("Imported %d image(s)", len(loaded))
+	for _, image := range loaded {
+		fmt.Fprintf(cmd.OutOrStdout(), "  %s\n", image)
+	}
+
+	// Point out stack images the archive did not cover, when run in a project
+	if cfg, _, err := loadImagesConfig(); err == nil {
+		images := collectServiceImages(cfg.Stack.Enabled, cfg.Services.Versions(), cfg.Images.RegistryMirrors)
+		if missing, err := dockerClient.Images().Missing(ctx, images); err == nil && len(missing) > 0 {
+			ui.Warning("Still missing for this stack: %s", strings.Join(missing, ", "))
+		}
+	}
+	return nil
+}
+
+// ValidateArgs validates the command arguments
+func (h *ImagesImportHandler) ValidateArgs(args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("images import requires the path of an image archive")
+	}
+	return nil
+}
+
+// GetRequiredFlags returns required flags for this command
+func (h *ImagesImportHandler) GetRequiredFlags() []string {
+	return []string{}
+}
+
+// newImagesDockerClient creates the Docker client used by images export and
+// import
+func newImagesDockerClient(base *cliTypes.BaseCommand) (*docker.Client, error) {
+	logger := base.Logger.(loggerAdapter)
+	dockerClient, err := docker.Shared(logger.SlogLogger())
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Docker client: %w", err)
+	}
+	return dockerClient, nil
+}
+
+// writeImageArchive saves images to path, gzip-compressed when path ends in
+// .gz or .tgz. A partially written archive is removed on failure.
+func writeImageArchive(ctx context.Context, dockerClient *docker.Client, images []string, path string) (err error) {
+	if pkgUtils.FileExists(path) {
+		ui.Warning("Overwriting %s", path)
+	}
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create image archive: %w", err)
+	}
+	defer func() {
+		if closeErr := file.Close(); err == nil && closeErr != nil {
+			err = fmt.Errorf("failed to write image archive: %w", closeErr)
+		}
+		if err != nil {
+			_ = os.Remove(path)
+		}
+	}()
+
+	var w io.Writer = file
+	if strings.HasSuffix(path, ".gz") || strings.HasSuffix(path, ".tgz") {
+		gz := gzip.NewWriter(file)
+		defer func() {
+			if closeErr := gz.Close(); err == nil && closeErr != nil {
+				err = fmt.Errorf("failed to write image archive: %w", closeErr)
+			}
+		}()
+		w = gz
+	}
+
+	ui.Info("Saving %d image(s) to %s...", len(images), path)
+	return dockerClient.Images().Save(ctx, images, w)
+}
+
+// decompressArchive returns r unchanged for a plain tar archive and a gzip
+// reader for a compressed one, detected from its magic bytes
+func decompressArchive(r io.Reader) (io.Reader, error) {
+	buffered := bufio.NewReader(r)
+	magic, err := buffered.Peek(2)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("failed to read image archive: %w", err)
+	}
+	if len(magic) == 2 && magic[0] == 0x1f && magic[1] == 0x8b {
+		gz, err := gzip.NewReader(buffered)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read compressed image archive: %w", err)
+		}
+		return gz, nil
+	}
+	return buffered, nil
+}
@@ -0,0 +1,387 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/isaacgarza/dev-stack/internal/core/registry"
+	"github.com/isaacgarza/dev-stack/internal/pkg/cli/handlers/utils"
+	cliTypes "github.com/isaacgarza/dev-stack/internal/pkg/cli/types"
+	pkgConfig "github.com/isaacgarza/dev-stack/internal/pkg/config"
+	"github.com/isaacgarza/dev-stack/internal/pkg/constants"
+	"github.com/isaacgarza/dev-stack/internal/pkg/types"
+	"github.com/isaacgarza/dev-stack/internal/pkg/ui"
+	pkgUtils "github.com/isaacgarza/dev-stack/internal/pkg/utils"
+	"github.com/spf13/cobra"
+)
+
+// registryTimeout bounds each request to an image registry
+const registryTimeout = 30 * time.Second
+
+// tagLister lists the tags of an image repository; it is satisfied by
+// registry.Client
+type tagLister interface {
+	Tags(ctx context.Context, ref registry.Reference) ([]string, error)
+}
+
+// serviceImage is the image a service runs
+type serviceImage struct {
+	Service string
+	Image   string
+	// Pinned is set when the tag comes from services.<name>.version
+	Pinned bool
+}
+
+// imageChange is one bump recorded in the image changelog
+type imageChange struct {
+	Service string
+	From    string
+	To      string
+}
+
+// ImagesOutdatedHandler handles the images outdated command
+type ImagesOutdatedHandler struct {
+	tags tagLister
+}
+
+// NewImagesOutdatedHandler creates a new images outdated handler
+func NewImagesOutdatedHandler() *ImagesOutdatedHandler {
+	return &ImagesOutdatedHandler{tags: registry.NewClient(&http.Client{Timeout: registryTimeout})}
+}
+
+// Handle executes the images outdated command
+func (h *ImagesOutdatedHandler) Handle(ctx context.Context, cmd *cobra.Command, args []string, base *cliTypes.BaseCommand) error {
+	cfg, _, err := loadImagesConfig()
+	if err != nil {
+		return err
+	}
+
+	serviceNames := args
+	if len(serviceNames) == 0 {
+		serviceNames = cfg.Stack.Enabled
+	}
+	images, err := serviceImages(serviceNames, cfg.Services)
+	if err != nil {
+		return err
+	}
+
+	ui.Header("Checking %d image(s) for newer tags", len(images))
+	lookup := newTagLookup(h.tags)
+
+	w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "SERVICE\tIMAGE\tLATEST\tPINNED")
+	outdated, failed := 0, 0
+	for _, image := range images {
+		latest, err := lookup.latest(ctx, image.Image)
+		switch {
+		case err != nil:
+			ui.Warning("Could not list tags of %s: %v", image.Image, err)
+			latest = "?"
+			failed++
+		case latest == "":
+			latest = "-"
+		default:
+			outdated++
+		}
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%t\n", image.Service, image.Image, latest, image.Pinned)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	switch {
+	case outdated > 0:
+		ui.Info("%d image(s) have newer tags. Run '%s' to bump them", outdated, constants.CmdRef(constants.CmdNameImagesUpdate))
+	case failed > 0:
+		ui.Warning("Could not check %d image(s)", failed)
+	default:
+		ui.Success("All images are up to date")
+	}
+	return nil
+}
+
+// ValidateArgs validates the command arguments
+func (h *ImagesOutdatedHandler) ValidateArgs(args []string) error {
+	return nil
+}
+
+// GetRequiredFlags returns required flags for this command
+func (h *ImagesOutdatedHandler) GetRequiredFlags() []string {
+	return []string{}
+}
+
+// ImagesPinHandler handles the images pin command
+type ImagesPinHandler struct{}
+
+// NewImagesPinHandler creates a new images pin handler
+func NewImagesPinHandler() *ImagesPinHandler {
+	return &ImagesPinHandler{}
+}
+
+// Handle executes the images pin command
+func (h *ImagesPinHandler) Handle(ctx context.Context, cmd *cobra.Command, args []string, base *cliTypes.BaseCommand) error {
+	if err := h.ValidateArgs(args); err != nil {
+		return err
+	}
+	serviceName := args[0]
+
+	cfg, configPath, err := loadImagesConfig()
+	if err != nil {
+		return err
+	}
+
+	images, err := serviceImages([]string{serviceName}, cfg.Services)
+	if err != nil {
+		return err
+	}
+	if len(images) == 0 {
+		return fmt.Errorf("service %s has no image to pin", serviceName)
+	}
+	current := images[0]
+
+	// Without a version the tag in use is pinned, so later changes to the
+	// service definition do not move it
+	version := registry.TagOf(current.Image)
+	if len(args) == 2 {
+		version = args[1]
+	}
+
+	change := imageChange{Service: serviceName, From: current.Image, To: registry.WithTag(current.Image, version)}
+	return applyImageChanges(ctx, cfg, configPath, []imageChange{change})
+}
+
+// ValidateArgs validates the command arguments
+func (h *ImagesPinHandler) ValidateArgs(args []string) error {
+	if len(args) == 0 || len(args) > 2 {
+		return fmt.Errorf("images pin requires a service name and an optional version")
+	}
+	return nil
+}
+
+// GetRequiredFlags returns required flags for this command
+func (h *ImagesPinHandler) GetRequiredFlags() []string {
+	return []string{}
+}
+
+// ImagesUpdateHandler handles the images update command
+type ImagesUpdateHandler struct {
+	tags tagLister
+}
+
+// NewImagesUpdateHandler creates a new images update handler
+func NewImagesUpdateHandler() *ImagesUpdateHandler {
+	return &ImagesUpdateHandler{tags: registry.NewClient(&http.Client{Timeout: registryTimeout})}
+}
+
+// Handle executes the images update command
+func (h *ImagesUpdateHandler) Handle(ctx context.Context, cmd *cobra.Command, args []string, base *cliTypes.BaseCommand) error {
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+
+	cfg, configPath, err := loadImagesConfig()
+	if err != nil {
+		return err
+	}
+
+	serviceNames := args
+	if len(serviceNames) == 0 {
+		serviceNames = cfg.Stack.Enabled
+	}
+	images, err := serviceImages(serviceNames, cfg.Services)
+	if err != nil {
+		return err
+	}
+
+	ui.Header("Updating image tags")
+	changes, failures := planImageUpdates(ctx, newTagLookup(h.tags), images)
+	for _, failure := range failures {
+		ui.Warning("%v", failure)
+	}
+
+	if len(changes) == 0 {
+		if len(failures) == 0 {
+			ui.Success("All images are up to date")
+		}
+		return nil
+	}
+
+	if dryRun {
+		ui.Info("Would update:")
+		ui.List(describeImageChanges(changes))
+		return nil
+	}
+	return applyImageChanges(ctx, cfg, configPath, changes)
+}
+
+// ValidateArgs validates the command arguments
+func (h *ImagesUpdateHandler) ValidateArgs(args []string) error {
+	return nil
+}
+
+// GetRequiredFlags returns required flags for this command
+func (h *ImagesUpdateHandler) GetRequiredFlags() []string {
+	return []string{}
+}
+
+// loadImagesConfig loads the project configuration along with its path
+func loadImagesConfig() (*ProjectConfig, string, error) {
+	configPath := filepath.Join(constants.DevStackDir, constants.ConfigFileName)
+	if !pkgUtils.FileExists(configPath) {
+		return nil, "", errors.New(constants.ErrNotInitialized)
+	}
+
+	cfg, err := LoadProjectConfig(configPath)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to load configuration: %w", err)
+	}
+	return cfg, configPath, nil
+}
+
+// serviceImages returns the images of the given services with their pinned
+// versions applied, skipping services that build their image or have none
+func serviceImages(serviceNames []string, settings types.ServicesConfig) ([]serviceImage, error) {
+	serviceUtils := utils.NewServiceUtils()
+
+	var images []serviceImage
+	for _, serviceName := range serviceNames {
+		serviceConfig, err := serviceUtils.LoadServiceConfig(serviceName)
+		if err != nil {
+			return nil, err
+		}
+
+		version := settings[serviceName].Version
+		if image := pinnedImage(serviceConfig, version); image != "" {
+			images = append(images, serviceImage{Service: serviceName, Image: image, Pinned: version != ""})
+		}
+	}
+	return images, nil
+}
+
+// planImageUpdates returns a change to the newest tag on the same track for
+// every image that has one. Images whose tags cannot be listed are reported
+// as failures and left alone.
+func planImageUpdates(ctx context.Context, lookup *tagLookup, images []serviceImage) ([]imageChange, []error) {
+	var changes []imageChange
+	var failures []error
+	for _, image := range images {
+		latest, err := lookup.latest(ctx, image.Image)
+		if err != nil {
+			failures = append(failures, fmt.Errorf("could not list tags of %s: %w", image.Image, err))
+			continue
+		}
+		if latest == "" {
+			continue
+		}
+		changes = append(changes, imageChange{Service: image.Service, From: image.Image, To: registry.WithTag(image.Image, latest)})
+	}
+	return changes, failures
+}
+
+// applyImageChanges pins each changed service to its new tag, regenerates
+// the compose files and records the changes in the image changelog
+func applyImageChanges(ctx context.Context, cfg *ProjectConfig, configPath string, changes []imageChange) error {
+	if cfg.Services == nil {
+		cfg.Services = types.ServicesConfig{}
+	}
+	for _, change := range changes {
+		version := registry.TagOf(change.To)
+		if err := pkgConfig.SetServiceVersion(configPath, change.Service, version); err != nil {
+			return fmt.Errorf("failed to update configuration: %w", err)
+		}
+		settings := cfg.Services[change.Service]
+		settings.Version = version
+		cfg.Services[change.Service] = settings
+	}
+
+	if err := RegenerateCompose(ctx, cfg); err != nil {
+		return fmt.Errorf("failed to regenerate compose files: %w", err)
+	}
+
+	var recorded []imageChange
+	for _, change := range changes {
+		if change.From != change.To {
+			recorded = append(recorded, change)
+		}
+	}
+	if len(recorded) > 0 {
+		changelogPath := filepath.Join(constants.DevStackDir, constants.ImageChangelogFileName)
+		if err := appendImageChangelog(changelogPath, recorded, time.Now()); err != nil {
+			return fmt.Errorf("failed to update image changelog: %w", err)
+		}
+		ui.SubHeader("Image changes")
+		ui.List(describeImageChanges(recorded))
+		ui.Muted("Recorded in %s", changelogPath)
+	}
+
+	for _, change := range changes {
+		ui.Success("Pinned %s to %s", change.Service, registry.TagOf(change.To))
+	}
+	ui.Info("Pull and restart with: %s", constants.CmdUp)
+	return nil
+}
+
+// appendImageChangelog appends a dated entry listing changes to the
+// changelog at path, creating it if needed
+func appendImageChangelog(path string, changes []imageChange, now time.Time) error {
+	_, statErr := os.Stat(path)
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = file.Close() }()
+
+	var entry strings.Builder
+	if errors.Is(statErr, os.ErrNotExist) {
+		entry.WriteString("# Image changes\n")
+	}
+	fmt.Fprintf(&entry, "\n## %s\n\n", now.Format("2006-01-02 15:04"))
+	for _, line := range describeImageChanges(changes) {
+		fmt.Fprintf(&entry, "- %s\n", line)
+	}
+	_, err = io.WriteString(file, entry.String())
+	return err
+}
+
+// describeImageChanges renders changes as "service: from -> to" lines
+func describeImageChanges(changes []imageChange) []string {
+	lines := make([]string, 0, len(changes))
+	for _, change := range changes {
+		lines = append(lines, fmt.Sprintf("%s: %s -> %s", change.Service, change.From, change.To))
+	}
+	return lines
+}
+
+// tagLookup caches repository tags so images sharing a repository are only
+// listed once
+type tagLookup struct {
+	lister tagLister
+	cache  map[string][]string
+}
+
+func newTagLookup(lister tagLister) *tagLookup {
+	return &tagLookup{lister: lister, cache: make(map[string][]string)}
+}
+
+// latest returns the newest tag on the same track as image's tag, or "" if
+// it is the newest or not a version
+func (l *tagLookup) latest(ctx context.Context, image string) (string, error) {
+	ref := registry.ParseReference(image)
+	key := ref.Registry + "/" + ref.Repository
+	tags, ok := l.cache[key]
+	if !ok {
+		var err error
+		tags, err = l.lister.Tags(ctx, ref)
+		if err != nil {
+			return "", err
+		}
+		l.cache[key] = tags
+	}
+	return registry.LatestTag(ref.Tag, tags), nil
+}
@@ -0,0 +1,146 @@
+package core
+
+import (
+	"context"
+
+	"github.com/isaacgarza/dev-stack/internal/core/docker"
+	"github.com/isaacgarza/dev-stack/internal/core/registry"
+	"github.com/isaacgarza/dev-stack/internal/pkg/cli/handlers/utils"
+	cliTypes "github.com/isaacgarza/dev-stack/internal/pkg/cli/types"
+	"github.com/isaacgarza/dev-stack/internal/pkg/constants"
+	"github.com/isaacgarza/dev-stack/internal/pkg/types"
+	"github.com/isaacgarza/dev-stack/internal/pkg/ui"
+)
+
+// collectServiceImages returns the images used by the given services,
+// including every container of multi-container services, as the generated
+// compose file references them: with pinned versions applied and rewritten
+// to the registry mirrors
+func collectServiceImages(serviceNames []string, versions, registryMirrors map[string]string) []string {
+	serviceUtils := utils.NewServiceUtils()
+
+	var images []string
+	for _, serviceName := range serviceNames {
+		serviceConfig, err := serviceUtils.LoadServiceConfig(serviceName)
+		if err != nil {
+			continue
+		}
+
+		if len(serviceConfig.Docker.Services) > 0 {
+			for _, svc := range serviceConfig.Docker.Services {
+				if svc.Image != "" && svc.Build.Context == "" {
+					images = append(images, registry.Rewrite(svc.Image, registryMirrors))
+				}
+			}
+			continue
+		}
+
+		if image := pinnedImage(serviceConfig, versions[serviceName]); image != "" {
+			images = append(images, registry.Rewrite(image, registryMirrors))
+		}
+	}
+	return images
+}
+
+// ProjectImages returns the images the enabled services of a project run
+func ProjectImages(cfg *ProjectConfig) []string {
+	return collectServiceImages(cfg.Stack.Enabled, cfg.Services.Versions(), cfg.Images.RegistryMirrors)
+}
+
+// ServiceImage returns the image a single-container service of a project
+// runs, as the generated compose file references it, or an empty string
+// for services built locally or running several containers
+func ServiceImage(cfg *ProjectConfig, serviceName string) string {
+	serviceConfig, err := utils.NewServiceUtils().LoadServiceConfig(serviceName)
+	if err != nil || len(serviceConfig.Docker.Services) > 0 {
+		return ""
+	}
+	images := collectServiceImages([]string{serviceName}, cfg.Services.Versions(), cfg.Images.RegistryMirrors)
+	if len(images) != 1 {
+		return ""
+	}
+	return images[0]
+}
+
+// pinnedImage returns the image a single-container service runs, using
+// version as its tag when set. Services built locally have none.
+func pinnedImage(serviceConfig *cliTypes.ServiceConfig, version string) string {
+	if serviceConfig.Defaults.Image == "" || serviceConfig.Docker.Build.Context != "" {
+		return ""
+	}
+	if version != "" {
+		return registry.WithTag(serviceConfig.Defaults.Image, version)
+	}
+	return serviceConfig.Defaults.Image
+}
+
+// pullPolicies are the values of images.pull_policy
+var pullPolicies = []string{constants.PullPolicyAlways, constants.PullPolicyMissing, constants.PullPolicyNever}
+
+// PullPolicy returns the images.pull_policy of the project, always when unset
+func (c *ProjectConfig) PullPolicy() string {
+	if c.Images.PullPolicy == "" {
+		return constants.PullPolicyAlways
+	}
+	return c.Images.PullPolicy
+}
+
+// emulationPolicies are the values of images.emulation
+var emulationPolicies = []string{constants.EmulationAuto, constants.EmulationWarn, constants.EmulationOff}
+
+// EmulationPolicy returns the images.emulation of the project, auto when
+// unset
+func (c *ProjectConfig) EmulationPolicy() string {
+	if c.Images.Emulation == "" {
+		return constants.EmulationAuto
+	}
+	return c.Images.Emulation
+}
+
+// pullServiceImages pre-pulls service images as the pull policy asks, so a
+// flaky network degrades to warnings instead of failing the whole stack
+// during compose up
+func pullServiceImages(ctx context.Context, dockerClient *docker.Client, cfg *ProjectConfig, serviceNames []string) error {
+	policy := cfg.PullPolicy()
+	if policy == constants.PullPolicyNever {
+		return nil
+	}
+	images := collectServiceImages(serviceNames, cfg.Services.Versions(), cfg.Images.RegistryMirrors)
+	if policy == constants.PullPolicyMissing {
+		missing, err := dockerClient.Images().Missing(ctx, images)
+		if err != nil {
+			return err
+		}
+		images = missing
+	}
+	if len(images) == 0 {
+		return nil
+	}
+
+	ui.Info("Pulling %d image(s)...", len(images))
+	summary, err := pullImages(ctx, dockerClient, cfg, images)
+	if err != nil {
+		return err
+	}
+
+	ui.Info("Images: %s", summary)
+	for _, result := range summary.Results {
+		if result.Error != "" {
+			ui.Warning("Could not pull %s (%s); using local copy if available", result.Image, result.Error)
+		}
+	}
+	return nil
+}
+
+// pullImages pulls images with the project's pull settings, showing their
+// progress as they download
+func pullImages(ctx context.Context, dockerClient *docker.Client, cfg *ProjectConfig, images []string) (*docker.PullSummary, error) {
+	board := ui.NewProgressBoard()
+	defer board.Stop()
+	return dockerClient.Images().Pull(ctx, images, types.PullOptions{
+		Concurrency: cfg.Images.PullConcurrency,
+		Retries:     cfg.Images.PullRetries,
+		Mirrors:     cfg.Images.Mirrors,
+		Progress:    board,
+	})
+}
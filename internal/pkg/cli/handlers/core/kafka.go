@@ -0,0 +1,416 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/isaacgarza/dev-stack/internal/core/docker"
+	"github.com/isaacgarza/dev-stack/internal/core/kafka"
+	cliTypes "github.com/isaacgarza/dev-stack/internal/pkg/cli/types"
+	"github.com/isaacgarza/dev-stack/internal/pkg/constants"
+	"github.com/isaacgarza/dev-stack/internal/pkg/types"
+	"github.com/isaacgarza/dev-stack/internal/pkg/ui"
+	"github.com/isaacgarza/dev-stack/internal/pkg/utils"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+// KafkaTopicsListHandler handles the kafka topics list command
+type KafkaTopicsListHandler struct{}
+
+// NewKafkaTopicsListHandler creates a new kafka topics list handler
+func NewKafkaTopicsListHandler() *KafkaTopicsListHandler {
+	return &KafkaTopicsListHandler{}
+}
+
+// Handle executes the kafka topics list command
+func (h *KafkaTopicsListHandler) Handle(ctx context.Context, cmd *cobra.Command, args []string, base *cliTypes.BaseCommand) error {
+	return withKafkaAdmin(base, func(cfg *ProjectConfig, _ *docker.Client, admin *kafka.Admin) error {
+		topics, err := admin.Topics(ctx)
+		if err != nil {
+			return err
+		}
+		var declared []string
+		for _, topic := range cfg.Kafka.Topics {
+			declared = append(declared, topic.Name)
+		}
+
+		resources := declaredStatuses(topics, declared)
+		if len(resources) == 0 {
+			ui.Info("The broker has no topics; declare them under kafka.topics in %s", constants.ConfigFileName)
+			return nil
+		}
+		w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+		_, _ = fmt.Fprintln(w, "TOPIC\tSTATUS")
+		for _, resource := range resources {
+			_, _ = fmt.Fprintf(w, "%s\t%s\n", resource.name, resource.status)
+		}
+		return w.Flush()
+	})
+}
+
+// ValidateArgs validates the command arguments
+func (h *KafkaTopicsListHandler) ValidateArgs(args []string) error {
+	return nil
+}
+
+// GetRequiredFlags returns required flags for this command
+func (h *KafkaTopicsListHandler) GetRequiredFlags() []string {
+	return []string{}
+}
+
+// KafkaTopicsCreateHandler handles the kafka topics create command
+type KafkaTopicsCreateHandler struct{}
+
+// NewKafkaTopicsCreateHandler creates a new kafka topics create handler
+func NewKafkaTopicsCreateHandler() *KafkaTopicsCreateHandler {
+	return &KafkaTopicsCreateHandler{}
+}
+
+// Handle executes the kafka topics create command
+func (h *KafkaTopicsCreateHandler) Handle(ctx context.Context, cmd *cobra.Command, args []string, base *cliTypes.BaseCommand) error {
+	if err := h.ValidateArgs(args); err != nil {
+		return err
+	}
+
+	return withKafkaAdmin(base, func(cfg *ProjectConfig, _ *docker.Client, admin *kafka.Admin) error {
+		topic, err := topicFromFlags(cmd, cfg, args[0])
+		if err != nil {
+			return err
+		}
+		if err := admin.Create(ctx, topic); err != nil {
+			return err
+		}
+		ui.Success("Created topic %s", topic.Name)
+		return nil
+	})
+}
+
+// ValidateArgs validates the command arguments
+func (h *KafkaTopicsCreateHandler) ValidateArgs(args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("kafka topics create requires a topic name")
+	}
+	return nil
+}
+
+// GetRequiredFlags returns required flags for this command
+func (h *KafkaTopicsCreateHandler) GetRequiredFlags() []string {
+	return []string{}
+}
+
+// topicFromFlags returns the topic to create: its declaration in the
+// project configuration, if any, with the settings passed as flags applied
+func topicFromFlags(cmd *cobra.Command, cfg *ProjectConfig, name string) (types.KafkaTopicConfig, error) {
+	topic := types.KafkaTopicConfig{Name: name}
+	for _, declared := range cfg.Kafka.Topics {
+		if declared.Name == name {
+			topic = declared
+		}
+	}
+
+	if cmd.Flags().Changed("partitions") {
+		topic.Partitions, _ = cmd.Flags().GetInt("partitions")
+	}
+	if cmd.Flags().Changed("replication-factor") {
+		topic.ReplicationFactor, _ = cmd.Flags().GetInt("replication-factor")
+	}
+	if retention, _ := cmd.Flags().GetString("retention"); retention != "" {
+		topic.Retention = retention
+	}
+	if configs, _ := cmd.Flags().GetString("config"); configs != "" {
+		topic.Config = maps.Clone(topic.Config)
+		if topic.Config == nil {
+			topic.Config = make(map[string]string)
+		}
+		for _, config := range utils.SplitAndTrim(configs, ",") {
+			key, value, ok := strings.Cut(config, "=")
+			if !ok || key == "" {
+				return topic, fmt.Errorf("invalid config %q: expected key=value", config)
+			}
+			topic.Config[key] = value
+		}
+	}
+
+	if err := (types.KafkaConfig{Topics: []types.KafkaTopicConfig{topic}}).Validate(); err != nil {
+		return topic, err
+	}
+	return topic, nil
+}
+
+// KafkaTopicsDeleteHandler handles the kafka topics delete command
+type KafkaTopicsDeleteHandler struct{}
+
+// NewKafkaTopicsDeleteHandler creates a new kafka topics delete handler
+func NewKafkaTopicsDeleteHandler() *KafkaTopicsDeleteHandler {
+	return &KafkaTopicsDeleteHandler{}
+}
+
+// Handle executes the kafka topics delete command
+func (h *KafkaTopicsDeleteHandler) Handle(ctx context.Context, cmd *cobra.Command, args []string, base *cliTypes.BaseCommand) error {
+	if err := h.ValidateArgs(args); err != nil {
+		return err
+	}
+
+	return withKafkaAdmin(base, func(cfg *ProjectConfig, _ *docker.Client, admin *kafka.Admin) error {
+		if err := ConfirmProtected(cmd, cfg, "delete the Kafka topics "+strings.Join(args, ", ")); err != nil {
+			return err
+		}
+		for _, name := range args {
+			if err := admin.Delete(ctx, name); err != nil {
+				return err
+			}
+			ui.Success("Deleted topic %s", name)
+			if slices.ContainsFunc(cfg.Kafka.Topics, func(topic types.KafkaTopicConfig) bool { return topic.Name == name }) {
+				ui.Warning("Topic %s is declared in %s; %s creates it again", name, constants.ConfigFileName, constants.CmdUp)
+			}
+		}
+		return nil
+	})
+}
+
+// ValidateArgs validates the command arguments
+func (h *KafkaTopicsDeleteHandler) ValidateArgs(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("kafka topics delete requires at least one topic name")
+	}
+	return nil
+}
+
+// GetRequiredFlags returns required flags for this command
+func (h *KafkaTopicsDeleteHandler) GetRequiredFlags() []string {
+	return []string{}
+}
+
+// KafkaTopicsDescribeHandler handles the kafka topics describe command
+type KafkaTopicsDescribeHandler struct{}
+
+// NewKafkaTopicsDescribeHandler creates a new kafka topics describe handler
+func NewKafkaTopicsDescribeHandler() *KafkaTopicsDescribeHandler {
+	return &KafkaTopicsDescribeHandler{}
+}
+
+// Handle executes the kafka topics describe command
+func (h *KafkaTopicsDescribeHandler) Handle(ctx context.Context, cmd *cobra.Command, args []string, base *cliTypes.BaseCommand) error {
+	if err := h.ValidateArgs(args); err != nil {
+		return err
+	}
+
+	return withKafkaAdmin(base, func(cfg *ProjectConfig, _ *docker.Client, admin *kafka.Admin) error {
+		topic, err := admin.Describe(ctx, args[0])
+		if err != nil {
+			return err
+		}
+		return writeTopicDescription(cmd.OutOrStdout(), topic)
+	})
+}
+
+// ValidateArgs validates the command arguments
+func (h *KafkaTopicsDescribeHandler) ValidateArgs(args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("kafka topics describe requires a topic name")
+	}
+	return nil
+}
+
+// GetRequiredFlags returns required flags for this command
+func (h *KafkaTopicsDescribeHandler) GetRequiredFlags() []string {
+	return []string{}
+}
+
+// writeTopicDescription prints the settings of a topic followed by its
+// partitions
+func writeTopicDescription(out io.Writer, topic *kafka.Topic) error {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintf(w, "Topic:\t%s\n", topic.Name)
+	_, _ = fmt.Fprintf(w, "Partitions:\t%d\n", topic.Partitions)
+	_, _ = fmt.Fprintf(w, "Replication factor:\t%d\n", topic.ReplicationFactor)
+	if retention, ok := topic.Configs["retention.ms"]; ok {
+		_, _ = fmt.Fprintf(w, "Retention:\t%s\n", types.RetentionOf(retention))
+	}
+	for _, key := range slices.Sorted(maps.Keys(topic.Configs)) {
+		if key != "retention.ms" {
+			_, _ = fmt.Fprintf(w, "%s:\t%s\n", key, topic.Configs[key])
+		}
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	_, _ = fmt.Fprintln(out)
+	w = tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "PARTITION\tLEADER\tREPLICAS\tISR")
+	for _, partition := range topic.Assignment {
+		_, _ = fmt.Fprintf(w, "%d\t%s\t%s\t%s\n", partition.ID, partition.Leader, partition.Replicas, partition.ISR)
+	}
+	return w.Flush()
+}
+
+// KafkaProduceHandler handles the kafka produce command
+type KafkaProduceHandler struct{}
+
+// NewKafkaProduceHandler creates a new kafka produce handler
+func NewKafkaProduceHandler() *KafkaProduceHandler {
+	return &KafkaProduceHandler{}
+}
+
+// Handle executes the kafka produce command. Messages given as arguments
+// are produced in order; without any, the lines of stdin are.
+func (h *KafkaProduceHandler) Handle(ctx context.Context, cmd *cobra.Command, args []string, base *cliTypes.BaseCommand) error {
+	if err := h.ValidateArgs(args); err != nil {
+		return err
+	}
+	key, _ := cmd.Flags().GetString("key")
+	topic, messages := args[0], args[1:]
+	if key != "" && len(messages) == 0 {
+		return fmt.Errorf("--key needs the messages as arguments")
+	}
+	if strings.ContainsAny(key, "\t\r\n") {
+		return fmt.Errorf("--key must not contain tabs or newlines")
+	}
+
+	return withKafkaAdmin(base, func(cfg *ProjectConfig, dockerClient *docker.Client, admin *kafka.Admin) error {
+		command := admin.ProduceCommand(topic, key != "")
+		projectName := serviceProject(cfg, kafka.ServiceName)
+
+		if len(messages) == 0 {
+			if term.IsTerminal(int(os.Stdin.Fd())) {
+				ui.Info("Type one message per line; press Ctrl-D to finish")
+			}
+			return interactiveExit(cmd, dockerClient.Containers().Exec(ctx, projectName, kafka.ServiceName, command, types.ExecOptions{Interactive: true}))
+		}
+
+		var lines strings.Builder
+		for _, message := range messages {
+			if key != "" {
+				lines.WriteString(key + "\t")
+			}
+			lines.WriteString(message + "\n")
+		}
+		if err := dockerClient.Containers().ExecStdin(ctx, projectName, kafka.ServiceName, command, strings.NewReader(lines.String()), types.ExecOptions{}); err != nil {
+			return fmt.Errorf("failed to produce to %s: %w", topic, err)
+		}
+		ui.Success("Produced %d message(s) to %s", len(messages), topic)
+		return nil
+	})
+}
+
+// ValidateArgs validates the command arguments
+func (h *KafkaProduceHandler) ValidateArgs(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("kafka produce requires a topic name")
+	}
+	for _, message := range args[1:] {
+		if strings.ContainsAny(message, "\r\n") {
+			return fmt.Errorf("messages must fit on a single line")
+		}
+	}
+	return nil
+}
+
+// GetRequiredFlags returns required flags for this command
+func (h *KafkaProduceHandler) GetRequiredFlags() []string {
+	return []string{}
+}
+
+// KafkaConsumeHandler handles the kafka consume command
+type KafkaConsumeHandler struct{}
+
+// NewKafkaConsumeHandler creates a new kafka consume handler
+func NewKafkaConsumeHandler() *KafkaConsumeHandler {
+	return &KafkaConsumeHandler{}
+}
+
+// Handle executes the kafka consume command, printing messages until
+// interrupted or the requested number arrived
+func (h *KafkaConsumeHandler) Handle(ctx context.Context, cmd *cobra.Command, args []string, base *cliTypes.BaseCommand) error {
+	if err := h.ValidateArgs(args); err != nil {
+		return err
+	}
+	var options kafka.ConsumeOptions
+	options.FromBeginning, _ = cmd.Flags().GetBool("from-beginning")
+	options.MaxMessages, _ = cmd.Flags().GetInt("max-messages")
+	options.Group, _ = cmd.Flags().GetString("group")
+
+	return withKafkaAdmin(base, func(cfg *ProjectConfig, dockerClient *docker.Client, admin *kafka.Admin) error {
+		command := admin.ConsumeCommand(args[0], options)
+		execOptions := types.ExecOptions{TTY: term.IsTerminal(int(os.Stdout.Fd()))}
+		return interactiveExit(cmd, dockerClient.Containers().Exec(ctx, serviceProject(cfg, kafka.ServiceName), kafka.ServiceName, command, execOptions))
+	})
+}
+
+// ValidateArgs validates the command arguments
+func (h *KafkaConsumeHandler) ValidateArgs(args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("kafka consume requires a topic name")
+	}
+	return nil
+}
+
+// GetRequiredFlags returns required flags for this command
+func (h *KafkaConsumeHandler) GetRequiredFlags() []string {
+	return []string{}
+}
+
+// interactiveExit passes on the exit code of a command run in a container,
+// which has already reported its failure on its own output
+func interactiveExit(cmd *cobra.Command, err error) error {
+	var exitErr *types.ExitError
+	if errors.As(err, &exitErr) {
+		cmd.SilenceErrors = true
+		cmd.SilenceUsage = true
+	}
+	return err
+}
+
+// withKafkaAdmin runs fn with an admin for the project's Kafka broker
+func withKafkaAdmin(base *cliTypes.BaseCommand, fn func(cfg *ProjectConfig, dockerClient *docker.Client, admin *kafka.Admin) error) error {
+	cfg, _, err := loadImagesConfig()
+	if err != nil {
+		return err
+	}
+
+	dockerClient, err := newImagesDockerClient(base)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := dockerClient.Close(); err != nil {
+			base.Logger.Error("Failed to close Docker client", "error", err)
+		}
+	}()
+
+	return fn(cfg, dockerClient, kafkaAdmin(dockerClient, cfg))
+}
+
+// kafkaAdmin returns an admin for the project's Kafka broker
+func kafkaAdmin(dockerClient *docker.Client, cfg *ProjectConfig) *kafka.Admin {
+	return kafka.NewAdmin(dockerClient.Containers(), serviceProject(cfg, kafka.ServiceName), "")
+}
+
+// reconcileKafkaTopics brings the declared topics in line once the broker
+// is ready, when any of serviceNames runs on it
+func reconcileKafkaTopics(ctx context.Context, dockerClient *docker.Client, cfg *ProjectConfig, serviceNames []string, timeout time.Duration) error {
+	if cfg.Kafka.IsZero() || !startsService(serviceNames, kafka.ServiceName) {
+		return nil
+	}
+	if err := waitForServices(ctx, dockerClient, cfg, []string{kafka.ServiceName}, timeout); err != nil {
+		return err
+	}
+
+	board := ui.NewProgressBoard()
+	err := kafkaAdmin(dockerClient, cfg).Reconcile(ctx, cfg.Kafka.Topics, board)
+	board.Stop()
+	if err != nil {
+		return fmt.Errorf("failed to reconcile Kafka topics: %w", err)
+	}
+	return nil
+}
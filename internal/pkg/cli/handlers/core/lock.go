@@ -0,0 +1,70 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"slices"
+
+	"github.com/isaacgarza/dev-stack/internal/core/projectstate"
+	cliTypes "github.com/isaacgarza/dev-stack/internal/pkg/cli/types"
+	"github.com/isaacgarza/dev-stack/internal/pkg/constants"
+	"github.com/isaacgarza/dev-stack/internal/pkg/ui"
+	"github.com/isaacgarza/dev-stack/internal/pkg/utils"
+	"github.com/spf13/cobra"
+)
+
+// releaseProjectLock releases the operation lock taken by up, down or
+// restart, recording what the operation changed with record when not nil.
+// The stack was already changed, so a failure is only logged.
+func releaseProjectLock(handle *projectstate.Handle, base *cliTypes.BaseCommand, record func(*projectstate.State)) {
+	if err := handle.Release(record); err != nil {
+		base.Logger.Error("Failed to release the project lock", "error", err)
+	}
+}
+
+// mergeServiceNames returns the services running once started join them,
+// sorted
+func mergeServiceNames(running, started []string) []string {
+	merged := slices.Concat(running, started)
+	slices.Sort(merged)
+	return slices.Compact(merged)
+}
+
+// UnlockHandler handles the unlock command, removing the operation lock a
+// killed up, down or restart left behind
+type UnlockHandler struct{}
+
+// NewUnlockHandler creates a new unlock handler
+func NewUnlockHandler() *UnlockHandler {
+	return &UnlockHandler{}
+}
+
+// Handle executes the unlock command
+func (h *UnlockHandler) Handle(ctx context.Context, cmd *cobra.Command, args []string, base *cliTypes.BaseCommand) error {
+	if !utils.FileExists(filepath.Join(constants.DevStackDir, constants.ConfigFileName)) {
+		return errors.New(constants.ErrNotInitialized)
+	}
+	force, _ := cmd.Flags().GetBool("force")
+
+	removed, err := projectstate.Unlock(projectstate.Path(), force)
+	if err != nil {
+		return err
+	}
+	if removed == nil {
+		ui.Info("The project is not locked")
+		return nil
+	}
+	ui.Success("Removed the lock of %s %s (pid %d on %s)", constants.AppName, removed.Operation, removed.PID, removed.Host)
+	return nil
+}
+
+// ValidateArgs validates the command arguments
+func (h *UnlockHandler) ValidateArgs(args []string) error {
+	return nil
+}
+
+// GetRequiredFlags returns required flags for this command
+func (h *UnlockHandler) GetRequiredFlags() []string {
+	return []string{}
+}
@@ -0,0 +1,180 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/isaacgarza/dev-stack/internal/core/docker"
+	"github.com/isaacgarza/dev-stack/internal/core/logstore"
+	"github.com/isaacgarza/dev-stack/internal/core/services"
+	cliTypes "github.com/isaacgarza/dev-stack/internal/pkg/cli/types"
+	"github.com/isaacgarza/dev-stack/internal/pkg/compose"
+	"github.com/isaacgarza/dev-stack/internal/pkg/constants"
+	"github.com/isaacgarza/dev-stack/internal/pkg/types"
+	"github.com/isaacgarza/dev-stack/internal/pkg/ui"
+	"github.com/isaacgarza/dev-stack/internal/pkg/utils"
+	"github.com/spf13/cobra"
+)
+
+// LogsHandler handles the logs command
+type LogsHandler struct {
+	manager *services.Manager
+}
+
+// NewLogsHandler creates a new logs handler
+func NewLogsHandler(manager *services.Manager) *LogsHandler {
+	return &LogsHandler{manager: manager}
+}
+
+// Handle executes the logs command. Following stops without an error on
+// an interrupt.
+func (h *LogsHandler) Handle(ctx context.Context, cmd *cobra.Command, args []string, base *cliTypes.BaseCommand) error {
+	configPath := filepath.Join(constants.DevStackDir, constants.ConfigFileName)
+	if !utils.FileExists(configPath) {
+		return errors.New(constants.ErrNotInitialized)
+	}
+	cfg, err := LoadProjectConfig(configPath)
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	follow, _ := cmd.Flags().GetBool("follow")
+	tail, _ := cmd.Flags().GetString("tail")
+	since, _ := cmd.Flags().GetString("since")
+	timestamps, _ := cmd.Flags().GetBool("timestamps")
+	noColor, _ := cmd.Flags().GetBool("no-color")
+	noPrefix, _ := cmd.Flags().GetBool("no-prefix")
+
+	source, _ := cmd.Flags().GetString("source")
+
+	collected, err := readsCollectedLogs(source, cfg, follow)
+	if err != nil {
+		return err
+	}
+	if collected {
+		return writeCollectedLogs(cmd.OutOrStdout(), args, since, tail, timestamps, noPrefix)
+	}
+
+	// Docker doesn't understand day and week durations
+	if duration, err := utils.ParseDuration(since); err == nil {
+		since = duration.String()
+	}
+
+	h.manager.SetProjectName(cfg.Project.Name)
+	return h.manager.GetLogs(ctx, args, types.LogOptions{
+		Follow:     follow,
+		Timestamps: timestamps,
+		Tail:       tail,
+		Since:      since,
+		NoColor:    noColor || ui.DefaultOutput.NoColor,
+		NoPrefix:   noPrefix,
+	})
+}
+
+// Sources of the logs command
+const (
+	logSourceAuto      = "auto"
+	logSourceDocker    = "docker"
+	logSourceCollector = "collector"
+)
+
+// readsCollectedLogs reports whether logs are read from the files the
+// log-collector service persists rather than from Docker. By default they
+// are whenever the stack enables it, except when following.
+func readsCollectedLogs(source string, cfg *ProjectConfig, follow bool) (bool, error) {
+	enabled := slices.Contains(cfg.Stack.Enabled, logstore.ServiceName)
+	switch source {
+	case logSourceAuto, "":
+		return enabled && !follow, nil
+	case logSourceDocker:
+		return false, nil
+	case logSourceCollector:
+		if !enabled {
+			return false, &types.ServiceNotFoundError{Service: logstore.ServiceName, NotEnabled: true}
+		}
+		if follow {
+			return false, fmt.Errorf("--follow reads from Docker and cannot be used with --source %s", logSourceCollector)
+		}
+		return true, nil
+	}
+	return false, fmt.Errorf("invalid source %q (supported: %s, %s, %s)", source, logSourceAuto, logSourceDocker, logSourceCollector)
+}
+
+// writeCollectedLogs prints the persisted logs of serviceNames
+func writeCollectedLogs(w io.Writer, serviceNames []string, since, tail string, timestamps, noPrefix bool) error {
+	sinceTime, err := parseSince(since, time.Now())
+	if err != nil {
+		return err
+	}
+	tailLines := 0
+	if tail != "" && tail != "all" {
+		tailLines, err = strconv.Atoi(tail)
+		if err != nil || tailLines < 0 {
+			return fmt.Errorf("invalid --tail %q: expected a number of lines or all", tail)
+		}
+	}
+
+	store := logstore.NewStore(filepath.Join(constants.DevStackDir, constants.LogsDir))
+	entries, err := store.Read(serviceNames, sinceTime, tailLines)
+	if err != nil {
+		return err
+	}
+	if len(entries) == 0 {
+		ui.Info("No logs collected yet; %s collects them while the stack runs", logstore.ServiceName)
+		return nil
+	}
+	return logstore.Write(w, entries, timestamps, noPrefix)
+}
+
+// parseSince resolves --since, a duration such as 2d or a timestamp, to
+// the time logs are shown from
+func parseSince(since string, now time.Time) (time.Time, error) {
+	if since == "" {
+		return time.Time{}, nil
+	}
+	if duration, err := utils.ParseDuration(since); err == nil {
+		return now.Add(-duration), nil
+	}
+	for _, layout := range []string{time.RFC3339Nano, "2006-01-02T15:04:05", "2006-01-02"} {
+		if t, err := time.ParseInLocation(layout, since, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid --since %q: expected a duration such as 2d or 30m, or a timestamp such as 2006-01-02T15:04:05", since)
+}
+
+// ValidateArgs validates the command arguments
+func (h *LogsHandler) ValidateArgs(args []string) error {
+	return nil
+}
+
+// GetRequiredFlags returns required flags for this command
+func (h *LogsHandler) GetRequiredFlags() []string {
+	return []string{}
+}
+
+// pruneCollectedLogs deletes the persisted logs older than the retention
+// when serviceNames start the log-collector service. The files belong to
+// the container's user, so they are deleted from within it. Failing to
+// prune only warns, as the stack is up by then.
+func pruneCollectedLogs(ctx context.Context, dockerClient *docker.Client, cfg *ProjectConfig, serviceNames []string) {
+	if !slices.Contains(serviceNames, logstore.ServiceName) {
+		return
+	}
+	retention := cfg.Logging.RetentionPeriod()
+	command := logstore.PruneCommand(compose.LogCollectorDir, retention)
+	result, err := dockerClient.Containers().ExecCapture(ctx, cfg.Project.Name, logstore.ServiceName, command)
+	if err == nil && result.ExitCode != 0 {
+		err = fmt.Errorf("%s", strings.TrimSpace(result.Stderr))
+	}
+	if err != nil {
+		ui.Warning("Failed to delete the logs older than %s: %v", utils.FormatDuration(retention), err)
+	}
+}
@@ -0,0 +1,125 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"path/filepath"
+	"slices"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/isaacgarza/dev-stack/internal/core/docker"
+	handlerUtils "github.com/isaacgarza/dev-stack/internal/pkg/cli/handlers/utils"
+	cliTypes "github.com/isaacgarza/dev-stack/internal/pkg/cli/types"
+	"github.com/isaacgarza/dev-stack/internal/pkg/compose"
+	"github.com/isaacgarza/dev-stack/internal/pkg/constants"
+	"github.com/isaacgarza/dev-stack/internal/pkg/types"
+	"github.com/isaacgarza/dev-stack/internal/pkg/ui"
+	"github.com/isaacgarza/dev-stack/internal/pkg/utils"
+	"github.com/spf13/cobra"
+)
+
+// serviceConnectivity is what a service of the stack can reach
+type serviceConnectivity struct {
+	Service  string   `json:"service"`
+	Reaches  []string `json:"reaches"`
+	Networks []string `json:"networks"`
+}
+
+// NetworkMapHandler handles the network map command
+type NetworkMapHandler struct{}
+
+// NewNetworkMapHandler creates a new network map handler
+func NewNetworkMapHandler() *NetworkMapHandler {
+	return &NetworkMapHandler{}
+}
+
+// Handle executes the network map command
+func (h *NetworkMapHandler) Handle(ctx context.Context, cmd *cobra.Command, args []string, base *cliTypes.BaseCommand) error {
+	format, _ := cmd.Flags().GetString("format")
+	if handlerUtils.GetCIFlags(cmd).JSON {
+		format = "json"
+	}
+	if format != "table" && format != "json" {
+		return fmt.Errorf("invalid format %q (supported: table, json)", format)
+	}
+
+	configPath := filepath.Join(constants.DevStackDir, constants.ConfigFileName)
+	if !utils.FileExists(configPath) || !utils.FileExists(constants.DockerComposeFile) {
+		return errors.New(constants.ErrNotInitialized)
+	}
+	cfg, err := LoadProjectConfig(configPath)
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	networks, err := compose.ServiceNetworks(docker.ComposeFiles()...)
+	if err != nil {
+		return fmt.Errorf("failed to read the networks of the stack: %w", err)
+	}
+	for _, name := range args {
+		if _, ok := networks[name]; !ok {
+			return &types.ServiceNotFoundError{Service: name, Available: slices.Sorted(maps.Keys(networks))}
+		}
+	}
+
+	connectivity := stackConnectivity(networks, args)
+	if format == "json" {
+		encoder := json.NewEncoder(cmd.OutOrStdout())
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(connectivity)
+	}
+
+	mode := cfg.Network.Mode
+	if mode == "" {
+		mode = types.NetworkModeShared
+	}
+	ui.Info("Network mode: %s", mode)
+	return writeConnectivity(cmd.OutOrStdout(), connectivity)
+}
+
+// ValidateArgs validates the command arguments
+func (h *NetworkMapHandler) ValidateArgs(args []string) error {
+	return nil
+}
+
+// GetRequiredFlags returns required flags for this command
+func (h *NetworkMapHandler) GetRequiredFlags() []string {
+	return []string{}
+}
+
+// stackConnectivity returns the services each of serviceNames, or every
+// service when empty, shares a network with
+func stackConnectivity(networks map[string][]string, serviceNames []string) []serviceConnectivity {
+	if len(serviceNames) == 0 {
+		serviceNames = slices.Sorted(maps.Keys(networks))
+	}
+	reachable := compose.Reachability(networks)
+	connectivity := make([]serviceConnectivity, 0, len(serviceNames))
+	for _, name := range serviceNames {
+		reaches := reachable[name]
+		if reaches == nil {
+			reaches = []string{}
+		}
+		connectivity = append(connectivity, serviceConnectivity{Service: name, Reaches: reaches, Networks: networks[name]})
+	}
+	return connectivity
+}
+
+// writeConnectivity prints what each service can reach as a table
+func writeConnectivity(w io.Writer, connectivity []serviceConnectivity) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "SERVICE\tCAN REACH\tNETWORKS")
+	for _, service := range connectivity {
+		reaches := strings.Join(service.Reaches, ", ")
+		if reaches == "" {
+			reaches = "-"
+		}
+		_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\n", service.Service, reaches, strings.Join(service.Networks, ", "))
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,118 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	cliTypes "github.com/isaacgarza/dev-stack/internal/pkg/cli/types"
+	"github.com/isaacgarza/dev-stack/internal/pkg/constants"
+	"github.com/isaacgarza/dev-stack/internal/pkg/types"
+	"github.com/isaacgarza/dev-stack/internal/pkg/ui"
+	"github.com/isaacgarza/dev-stack/internal/pkg/utils"
+	"github.com/spf13/cobra"
+)
+
+// PauseHandler handles the pause command, freezing running services in
+// place without stopping them
+type PauseHandler struct{}
+
+// NewPauseHandler creates a new pause handler
+func NewPauseHandler() *PauseHandler {
+	return &PauseHandler{}
+}
+
+// Handle executes the pause command
+func (h *PauseHandler) Handle(ctx context.Context, cmd *cobra.Command, args []string, base *cliTypes.BaseCommand) error {
+	return setServicesPaused(ctx, args, base, true)
+}
+
+// ValidateArgs validates the command arguments
+func (h *PauseHandler) ValidateArgs(args []string) error {
+	return nil
+}
+
+// GetRequiredFlags returns required flags for this command
+func (h *PauseHandler) GetRequiredFlags() []string {
+	return []string{}
+}
+
+// ResumeHandler handles the resume command, unfreezing paused services
+type ResumeHandler struct{}
+
+// NewResumeHandler creates a new resume handler
+func NewResumeHandler() *ResumeHandler {
+	return &ResumeHandler{}
+}
+
+// Handle executes the resume command
+func (h *ResumeHandler) Handle(ctx context.Context, cmd *cobra.Command, args []string, base *cliTypes.BaseCommand) error {
+	return setServicesPaused(ctx, args, base, false)
+}
+
+// ValidateArgs validates the command arguments
+func (h *ResumeHandler) ValidateArgs(args []string) error {
+	return nil
+}
+
+// GetRequiredFlags returns required flags for this command
+func (h *ResumeHandler) GetRequiredFlags() []string {
+	return []string{}
+}
+
+// setServicesPaused pauses or resumes the named services, or the whole
+// stack when none are named
+func setServicesPaused(ctx context.Context, args []string, base *cliTypes.BaseCommand, pause bool) error {
+	configPath := filepath.Join(constants.DevStackDir, constants.ConfigFileName)
+	if !utils.FileExists(configPath) {
+		return errors.New(constants.ErrNotInitialized)
+	}
+	cfg, err := LoadProjectConfig(configPath)
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+	for _, serviceName := range args {
+		if !slices.Contains(cfg.Stack.Enabled, serviceName) {
+			return &types.ServiceNotFoundError{Service: serviceName, NotEnabled: true}
+		}
+	}
+
+	dockerClient, err := newImagesDockerClient(base)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := dockerClient.Close(); err != nil {
+			base.Logger.Error("Failed to close Docker client", "error", err)
+		}
+	}()
+
+	setPaused := dockerClient.Containers().Unpause
+	if pause {
+		setPaused = dockerClient.Containers().Pause
+	}
+	changed, err := setPaused(ctx, cfg.Project.Name, args)
+	if err != nil {
+		return err
+	}
+	reportPaused(changed, pause)
+	return nil
+}
+
+// reportPaused tells which services were paused or resumed
+func reportPaused(changed []string, pause bool) {
+	switch {
+	case len(changed) == 0 && pause:
+		ui.Info("No running services to pause")
+	case len(changed) == 0:
+		ui.Info("No paused services to resume")
+	case pause:
+		ui.Success("Paused %s", strings.Join(changed, ", "))
+		ui.Info("Run '%s' to resume them", constants.CmdResume)
+	default:
+		ui.Success("Resumed %s", strings.Join(changed, ", "))
+	}
+}
@@ -0,0 +1,288 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"path/filepath"
+	"slices"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/isaacgarza/dev-stack/internal/core/docker"
+	"github.com/isaacgarza/dev-stack/internal/pkg/compose"
+	"github.com/isaacgarza/dev-stack/internal/pkg/constants"
+	"github.com/isaacgarza/dev-stack/internal/pkg/types"
+	"github.com/isaacgarza/dev-stack/internal/pkg/utils"
+)
+
+// Actions of a plan step
+const (
+	planPull   = "pull"
+	planBuild  = "build"
+	planCreate = "create"
+	planAttach = "attach"
+	planStart  = "start"
+	planStop   = "stop"
+	planDetach = "detach"
+	planRemove = "remove"
+)
+
+// Resources a plan step acts on
+const (
+	planImages   = "images"
+	planNetworks = "networks"
+	planVolumes  = "volumes"
+	planServices = "services"
+	planShared   = "shared services"
+)
+
+// ExecutionPlan is what up, down or cleanup would do, in order, as their
+// --dry-run prints it. It is read from the compose files without
+// contacting Docker, so resources that already exist are listed too.
+type ExecutionPlan struct {
+	Command string     `json:"command"`
+	Project string     `json:"project"`
+	Steps   []PlanStep `json:"steps"`
+}
+
+// PlanStep is one thing a plan does to some resources
+type PlanStep struct {
+	Action   string   `json:"action"`
+	Resource string   `json:"resource"`
+	Names    []string `json:"names"`
+	// Ports are the host port mappings each started service publishes
+	Ports map[string][]string `json:"ports,omitempty"`
+	Note  string              `json:"note,omitempty"`
+}
+
+// add appends a step unless it has nothing to act on
+func (p *ExecutionPlan) add(step PlanStep) {
+	if len(step.Names) > 0 {
+		p.Steps = append(p.Steps, step)
+	}
+}
+
+// upPlan returns what up would do to start serviceNames: pull the images,
+// create the networks and volumes the services use, then start them wave
+// after wave, the services of a wave together
+func upPlan(cfg *ProjectConfig, configPath string, serviceNames []string, noDeps, build, ephemeral bool) (*ExecutionPlan, error) {
+	resources, dependencies, err := loadPlanResources(cfg, configPath)
+	if err != nil {
+		return nil, err
+	}
+
+	plan := &ExecutionPlan{Command: constants.CmdUp, Project: cfg.Project.Name}
+	sharedNames := sharedServices(cfg, serviceNames)
+	if len(sharedNames) > 0 {
+		serviceNames = slices.DeleteFunc(slices.Clone(serviceNames), func(name string) bool {
+			return slices.Contains(sharedNames, name)
+		})
+		noDeps = true
+	}
+	plan.add(PlanStep{Action: planAttach, Resource: planShared, Names: sharedNames, Note: "started once for every project that uses them"})
+
+	policy := cfg.PullPolicy()
+	if policy != constants.PullPolicyNever {
+		pull := PlanStep{Action: planPull, Resource: planImages, Names: collectServiceImages(serviceNames, cfg.Services.Versions(), cfg.Images.RegistryMirrors)}
+		if policy == constants.PullPolicyMissing {
+			pull.Note = "only those not present locally"
+		}
+		plan.add(pull)
+	}
+
+	waves, err := compose.StartupWaves(serviceNames, dependencies, !noDeps)
+	if err != nil {
+		return nil, err
+	}
+	started := slices.Concat(waves...)
+
+	var builds, networks, volumes []string
+	for _, name := range started {
+		service := resources.Services[name]
+		if service.Build {
+			builds = append(builds, service.Image)
+		}
+		networks = appendMissing(networks, service.Networks...)
+		volumes = appendMissing(volumes, service.Volumes...)
+	}
+	buildStep := PlanStep{Action: planBuild, Resource: planImages, Names: builds, Note: "unless already built"}
+	if build {
+		buildStep.Note = ""
+	}
+	plan.add(buildStep)
+	plan.add(PlanStep{Action: planCreate, Resource: planNetworks, Names: networks, Note: "unless they exist"})
+	volumeStep := PlanStep{Action: planCreate, Resource: planVolumes, Names: volumes, Note: "unless they exist"}
+	if ephemeral {
+		volumeStep.Note = "fresh anonymous volumes replace the service data, removed with the stack once its TTL expires"
+	}
+	plan.add(volumeStep)
+
+	for _, wave := range waves {
+		step := PlanStep{Action: planStart, Resource: planServices, Names: wave, Ports: make(map[string][]string)}
+		for _, name := range wave {
+			if ports := resources.Services[name].Ports; len(ports) > 0 {
+				step.Ports[name] = ports
+			}
+		}
+		plan.add(step)
+	}
+	return plan, nil
+}
+
+// downPlan returns what down would do: stop and remove the containers of
+// the named services, or of the whole project when none are named, in the
+// reverse of their startup order
+func downPlan(cfg *ProjectConfig, configPath string, args []string, removeVolumes bool) (*ExecutionPlan, error) {
+	resources, dependencies, err := loadPlanResources(cfg, configPath)
+	if err != nil {
+		return nil, err
+	}
+
+	plan := &ExecutionPlan{Command: constants.CmdDown, Project: cfg.Project.Name}
+	serviceNames := args
+	if len(serviceNames) == 0 {
+		serviceNames = slices.Sorted(maps.Keys(resources.Services))
+	}
+	sharedNames := sharedServices(cfg, serviceNames)
+	serviceNames = slices.DeleteFunc(slices.Clone(serviceNames), func(name string) bool {
+		return slices.Contains(sharedNames, name)
+	})
+	plan.add(PlanStep{Action: planDetach, Resource: planShared, Names: sharedNames, Note: "stopped once no other project uses them"})
+
+	note := "their containers are removed"
+	if removeVolumes && len(args) > 0 {
+		note = "their containers and anonymous volumes are removed"
+	}
+	if err := addStopSteps(plan, serviceNames, dependencies, note); err != nil {
+		return nil, err
+	}
+
+	if len(args) == 0 {
+		if removeVolumes || isEphemeral() {
+			plan.add(PlanStep{Action: planRemove, Resource: planVolumes, Names: resources.Volumes})
+		}
+		plan.add(PlanStep{Action: planRemove, Resource: planNetworks, Names: resources.Networks})
+	}
+	return plan, nil
+}
+
+// CleanupPlan returns what cleanup would remove with options: the
+// containers of the project and, as requested, its volumes, the images
+// compose built for it and its networks
+func CleanupPlan(cfg *ProjectConfig, configPath string, options types.CleanupOptions) (*ExecutionPlan, error) {
+	resources, dependencies, err := loadPlanResources(cfg, configPath)
+	if err != nil {
+		return nil, err
+	}
+
+	plan := &ExecutionPlan{Command: constants.CmdCleanup, Project: cfg.Project.Name}
+	if err := addStopSteps(plan, slices.Sorted(maps.Keys(resources.Services)), dependencies, "their containers are removed"); err != nil {
+		return nil, err
+	}
+	if options.RemoveVolumes {
+		plan.add(PlanStep{Action: planRemove, Resource: planVolumes, Names: resources.Volumes})
+	}
+	if options.RemoveImages {
+		var builds []string
+		for _, name := range slices.Sorted(maps.Keys(resources.Services)) {
+			if service := resources.Services[name]; service.Build {
+				builds = append(builds, service.Image)
+			}
+		}
+		plan.add(PlanStep{Action: planRemove, Resource: planImages, Names: builds})
+	}
+	if options.RemoveNetworks {
+		plan.add(PlanStep{Action: planRemove, Resource: planNetworks, Names: resources.Networks})
+	}
+	return plan, nil
+}
+
+// addStopSteps adds the steps stopping serviceNames, dependents before the
+// services they depend on
+func addStopSteps(plan *ExecutionPlan, serviceNames []string, dependencies map[string]map[string]string, note string) error {
+	waves, err := compose.StartupWaves(serviceNames, dependencies, false)
+	if err != nil {
+		return err
+	}
+	slices.Reverse(waves)
+	for _, wave := range waves {
+		plan.add(PlanStep{Action: planStop, Resource: planServices, Names: wave, Note: note})
+	}
+	return nil
+}
+
+// loadPlanResources reads the services, volumes and networks and the
+// dependencies of the compose files, with the variables of the project's
+// .env file
+func loadPlanResources(cfg *ProjectConfig, configPath string) (*compose.Resources, map[string]map[string]string, error) {
+	if !utils.FileExists(constants.DockerComposeFile) {
+		return nil, nil, fmt.Errorf("%s not found; run '%s' first", filepath.Base(constants.DockerComposeFile), constants.CmdRef(constants.CmdNameGenerateCompose))
+	}
+	dotEnv, err := loadProjectDotEnv(configPath)
+	if err != nil {
+		return nil, nil, err
+	}
+	composeFiles := docker.ComposeFiles()
+	resources, err := compose.ProjectResources(cfg.Project.Name, utils.EnvLookup(dotEnv), composeFiles...)
+	if err != nil {
+		return nil, nil, err
+	}
+	dependencies, err := compose.ServiceDependencies(composeFiles...)
+	if err != nil {
+		return nil, nil, err
+	}
+	return resources, dependencies, nil
+}
+
+// WritePlan prints a plan as numbered steps, or as JSON
+func WritePlan(w io.Writer, jsonOutput bool, plan *ExecutionPlan) error {
+	if jsonOutput {
+		if plan.Steps == nil {
+			plan.Steps = []PlanStep{}
+		}
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(plan)
+	}
+
+	if _, err := fmt.Fprintf(w, "Dry run: %s would, for project %s:\n\n", plan.Command, plan.Project); err != nil {
+		return err
+	}
+	if len(plan.Steps) == 0 {
+		if _, err := fmt.Fprintln(w, "  Nothing to do"); err != nil {
+			return err
+		}
+	}
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for i, step := range plan.Steps {
+		header := fmt.Sprintf("%s %s", strings.ToUpper(step.Action[:1])+step.Action[1:], step.Resource)
+		if step.Note != "" {
+			header += " (" + step.Note + ")"
+		}
+		_, _ = fmt.Fprintf(tw, "  %d. %s\n", i+1, header)
+		for _, name := range step.Names {
+			if ports := step.Ports[name]; len(ports) > 0 {
+				_, _ = fmt.Fprintf(tw, "       %s\tports %s\n", name, strings.Join(ports, ", "))
+				continue
+			}
+			_, _ = fmt.Fprintf(tw, "       %s\n", name)
+		}
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	_, err := fmt.Fprintln(w, "\nNothing was changed. Run without --dry-run to carry out the plan.")
+	return err
+}
+
+// appendMissing appends the values not in s yet
+func appendMissing(s []string, values ...string) []string {
+	for _, value := range values {
+		if !slices.Contains(s, value) {
+			s = append(s, value)
+		}
+	}
+	return s
+}
@@ -0,0 +1,301 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/isaacgarza/dev-stack/internal/core/docker"
+	"github.com/isaacgarza/dev-stack/internal/core/prune"
+	handlerUtils "github.com/isaacgarza/dev-stack/internal/pkg/cli/handlers/utils"
+	cliTypes "github.com/isaacgarza/dev-stack/internal/pkg/cli/types"
+	"github.com/isaacgarza/dev-stack/internal/pkg/constants"
+	"github.com/isaacgarza/dev-stack/internal/pkg/services"
+	"github.com/isaacgarza/dev-stack/internal/pkg/types"
+	"github.com/isaacgarza/dev-stack/internal/pkg/ui"
+	"github.com/isaacgarza/dev-stack/internal/pkg/utils"
+	"github.com/isaacgarza/dev-stack/internal/pkg/version"
+	"github.com/spf13/cobra"
+)
+
+// defaultPruneBackupDir is where backups go when the project sets no
+// backup directory, as with backup --output
+const defaultPruneBackupDir = "./backups"
+
+// pruneDescriptions say what each prune category holds
+var pruneDescriptions = map[string]string{
+	types.PruneContainers: "stopped containers of dev-stack projects",
+	types.PruneImages:     "dangling images dev-stack pulled or built",
+	types.PruneBackups:    "backups of the project older than %s",
+	types.PruneSnapshots:  "snapshots of the project older than %s",
+	types.PruneVersions:   "installs of dev-stack versions not in use",
+}
+
+// PruneHandler handles the prune command, reclaiming the disk space
+// dev-stack uses on the host
+type PruneHandler struct{}
+
+// NewPruneHandler creates a new prune handler
+func NewPruneHandler() *PruneHandler {
+	return &PruneHandler{}
+}
+
+// pruneCategory is what prune found in one category
+type pruneCategory struct {
+	Category string              `json:"category"`
+	Count    int                 `json:"count"`
+	Size     int64               `json:"size"`
+	Items    []types.Reclaimable `json:"items"`
+	// Note says why the category couldn't be searched
+	Note string `json:"note,omitempty"`
+}
+
+// pruneReport is the space prune can reclaim, per category
+type pruneReport struct {
+	Categories []pruneCategory `json:"categories"`
+	Size       int64           `json:"size"`
+}
+
+// Handle executes the prune command
+func (h *PruneHandler) Handle(ctx context.Context, cmd *cobra.Command, args []string, base *cliTypes.BaseCommand) error {
+	all, _ := cmd.Flags().GetBool("all")
+	yes, _ := cmd.Flags().GetBool("yes")
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+	olderThanValue, _ := cmd.Flags().GetString("older-than")
+	flags := handlerUtils.GetCIFlags(cmd)
+
+	olderThan, err := utils.ParseDuration(olderThanValue)
+	if err != nil || olderThan <= 0 {
+		return fmt.Errorf("invalid --older-than %q: expected a duration such as 12h or 30d", olderThanValue)
+	}
+	categories := args
+	if len(categories) == 0 {
+		categories = types.PruneCategories
+	}
+
+	logger := base.Logger.(loggerAdapter)
+	dockerClient, err := docker.Shared(logger.SlogLogger())
+	if err != nil {
+		return fmt.Errorf("failed to create Docker client: %w", err)
+	}
+	defer func() {
+		if err := dockerClient.Close(); err != nil {
+			base.Logger.Error("Failed to close Docker client", "error", err)
+		}
+	}()
+
+	report := collectReclaimable(ctx, dockerClient, categories, time.Now().Add(-olderThan))
+	if flags.JSON {
+		encoder := json.NewEncoder(cmd.OutOrStdout())
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(report); err != nil {
+			return err
+		}
+	} else {
+		ui.Header("Reclaimable Space")
+		writePruneReport(cmd.OutOrStdout(), report, olderThanValue, dryRun)
+	}
+
+	if dryRun || !hasReclaimable(report) {
+		return nil
+	}
+	if !yes && (flags.JSON || flags.NonInteractive) {
+		ui.Info("Nothing was removed: pass --yes to reclaim without prompts")
+		return nil
+	}
+	return reclaim(ctx, dockerClient, report, all || len(args) > 0, yes)
+}
+
+// collectReclaimable searches categories for what can be removed. Backups
+// and snapshots belong to the project in the current directory and are
+// only searched inside one; a category that can't be searched gets a note
+// instead of failing the others.
+func collectReclaimable(ctx context.Context, client *docker.Client, categories []string, cutoff time.Time) pruneReport {
+	var cfg *ProjectConfig
+	configPath := filepath.Join(constants.DevStackDir, constants.ConfigFileName)
+	if utils.FileExists(configPath) {
+		cfg, _ = LoadProjectConfig(configPath)
+	}
+
+	report := pruneReport{Categories: []pruneCategory{}}
+	for _, category := range categories {
+		var items []types.Reclaimable
+		var err error
+		switch category {
+		case types.PruneContainers:
+			items, err = client.StoppedContainers(ctx)
+		case types.PruneImages:
+			items, err = client.DanglingImages(ctx, pulledImages(cfg))
+		case types.PruneBackups, types.PruneSnapshots:
+			if cfg == nil {
+				err = fmt.Errorf("not in a dev-stack project")
+			} else if category == types.PruneBackups {
+				dir := cfg.Backup.Directory
+				if dir == "" {
+					dir = defaultPruneBackupDir
+				}
+				items, err = prune.Backups(dir, cfg.Project.Name, cutoff)
+			} else {
+				items, err = prune.Snapshots(cutoff)
+			}
+		case types.PruneVersions:
+			items, err = staleVersions()
+		}
+
+		entry := pruneCategory{Category: category, Count: len(items), Size: types.ReclaimableSize(items), Items: items}
+		if entry.Items == nil {
+			entry.Items = []types.Reclaimable{}
+		}
+		if err != nil {
+			entry.Note = err.Error()
+		}
+		report.Categories = append(report.Categories, entry)
+		report.Size += entry.Size
+	}
+	return report
+}
+
+// pulledImages returns the images dev-stack pulls: those of every service
+// in the catalog and, inside a project, those at the versions and mirrors
+// it pins
+func pulledImages(cfg *ProjectConfig) []string {
+	var names []string
+	files, _ := services.ListServiceFiles()
+	for _, file := range files {
+		names = append(names, file.Name)
+	}
+	images := collectServiceImages(names, nil, nil)
+	if cfg != nil {
+		images = append(images, ProjectImages(cfg)...)
+	}
+	return images
+}
+
+// staleVersions returns the installs under ~/.dev-stack/versions of the
+// versions other than the running and the active one
+func staleVersions() ([]types.Reclaimable, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil, fmt.Errorf("failed to find home directory: %w", err)
+	}
+	configDir := filepath.Join(home, ".dev-stack")
+	keep := []string{version.GetShortVersion()}
+	if active, err := version.NewVersionRegistry(configDir).GetActiveVersion(); err == nil {
+		keep = append(keep, active.Version.String())
+	}
+	return prune.Versions(filepath.Join(configDir, "versions"), keep...)
+}
+
+// hasReclaimable reports whether any category found something, even if it
+// takes no space
+func hasReclaimable(report pruneReport) bool {
+	return slices.ContainsFunc(report.Categories, func(category pruneCategory) bool {
+		return category.Count > 0
+	})
+}
+
+// reclaim removes what the report found. Unless selected, each category is
+// offered first; unless yes, each item is confirmed before it is removed.
+func reclaim(ctx context.Context, client *docker.Client, report pruneReport, selected, yes bool) error {
+	var freed int64
+	var failed int
+	for _, category := range report.Categories {
+		if category.Count == 0 {
+			continue
+		}
+		if !selected && !ui.DefaultOutput.Confirm(fmt.Sprintf("Reclaim %s from %d %s?", utils.FormatBytes(uint64(category.Size)), category.Count, category.Category), false) {
+			continue
+		}
+		for _, item := range category.Items {
+			if !yes && !ui.DefaultOutput.Confirm(fmt.Sprintf("  Remove %s (%s)?", describeReclaimable(item), utils.FormatBytes(uint64(item.Size))), false) {
+				continue
+			}
+			var err error
+			if item.Category == types.PruneContainers || item.Category == types.PruneImages {
+				err = client.RemoveReclaimable(ctx, item)
+			} else {
+				err = prune.Remove(item)
+			}
+			if err != nil {
+				ui.Warning("%v", err)
+				failed++
+				continue
+			}
+			freed += item.Size
+		}
+	}
+
+	ui.Success("Reclaimed %s", utils.FormatBytes(uint64(freed)))
+	if failed > 0 {
+		return fmt.Errorf("failed to remove %d item(s)", failed)
+	}
+	return nil
+}
+
+// writePruneReport prints the space each category can reclaim and, for a
+// dry run, what it would remove
+func writePruneReport(w io.Writer, report pruneReport, olderThan string, dryRun bool) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "CATEGORY\tITEMS\tSIZE\tHOLDS")
+	for _, category := range report.Categories {
+		description := pruneDescriptions[category.Category]
+		if strings.Contains(description, "%s") {
+			description = fmt.Sprintf(description, olderThan)
+		}
+		if category.Note != "" {
+			description += " (skipped: " + category.Note + ")"
+		}
+		_, _ = fmt.Fprintf(tw, "%s\t%d\t%s\t%s\n", category.Category, category.Count, utils.FormatBytes(uint64(category.Size)), description)
+	}
+	_, _ = fmt.Fprintf(tw, "total\t\t%s\t\n", utils.FormatBytes(uint64(report.Size)))
+	_ = tw.Flush()
+
+	if !dryRun {
+		return
+	}
+	for _, category := range report.Categories {
+		if category.Count == 0 {
+			continue
+		}
+		_, _ = fmt.Fprintf(w, "\n%s:\n", category.Category)
+		for _, item := range category.Items {
+			_, _ = fmt.Fprintf(w, "  %s  %s\n", describeReclaimable(item), utils.FormatBytes(uint64(item.Size)))
+		}
+	}
+	_, _ = fmt.Fprintln(w, "\nNothing was removed. Run without --dry-run to reclaim it.")
+}
+
+// describeReclaimable names an item for prune output: containers with their
+// project, images by short ID with where they came from, files by path
+func describeReclaimable(item types.Reclaimable) string {
+	switch item.Category {
+	case types.PruneContainers:
+		return fmt.Sprintf("%s (%s)", item.Name, item.Detail)
+	case types.PruneImages:
+		id := strings.TrimPrefix(item.Name, "sha256:")
+		return fmt.Sprintf("%s (%s)", id[:min(12, len(id))], item.Detail)
+	}
+	return item.Name
+}
+
+// ValidateArgs checks that the arguments are prune categories
+func (h *PruneHandler) ValidateArgs(args []string) error {
+	for _, arg := range args {
+		if !slices.Contains(types.PruneCategories, arg) {
+			return fmt.Errorf("unknown category %q: expected one of %s", arg, strings.Join(types.PruneCategories, ", "))
+		}
+	}
+	return nil
+}
+
+// GetRequiredFlags returns required flags for this command
+func (h *PruneHandler) GetRequiredFlags() []string {
+	return []string{}
+}
@@ -0,0 +1,108 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/isaacgarza/dev-stack/internal/core/docker"
+	cliTypes "github.com/isaacgarza/dev-stack/internal/pkg/cli/types"
+	"github.com/isaacgarza/dev-stack/internal/pkg/constants"
+	"github.com/isaacgarza/dev-stack/internal/pkg/ui"
+	"github.com/spf13/cobra"
+)
+
+// PullHandler handles the pull command
+type PullHandler struct{}
+
+// NewPullHandler creates a new pull handler
+func NewPullHandler() *PullHandler {
+	return &PullHandler{}
+}
+
+// Handle executes the pull command
+func (h *PullHandler) Handle(ctx context.Context, cmd *cobra.Command, args []string, base *cliTypes.BaseCommand) error {
+	missingOnly, _ := cmd.Flags().GetBool("missing")
+
+	cfg, _, err := loadImagesConfig()
+	if err != nil {
+		return err
+	}
+	serviceNames, err := selectedServices(cfg, args, ActiveProfile(cmd))
+	if err != nil {
+		return err
+	}
+	images := collectServiceImages(serviceNames, cfg.Services.Versions(), cfg.Images.RegistryMirrors)
+	if len(images) == 0 {
+		ui.Info("No images to pull")
+		return nil
+	}
+
+	dockerClient, err := newImagesDockerClient(base)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := dockerClient.Close(); err != nil {
+			base.Logger.Error("Failed to close Docker client", "error", err)
+		}
+	}()
+
+	if missingOnly {
+		missing, err := dockerClient.Images().Missing(ctx, images)
+		if err != nil {
+			return err
+		}
+		if len(missing) == 0 {
+			ui.Success("All %d image(s) are present locally", len(images))
+			return nil
+		}
+		images = missing
+	}
+
+	ui.Info("Pulling %d image(s)...", len(images))
+	summary, err := pullImages(ctx, dockerClient, cfg, images)
+	if err != nil {
+		return fmt.Errorf("failed to pull images: %w", err)
+	}
+	if err := writePullDigests(cmd.OutOrStdout(), summary); err != nil {
+		return err
+	}
+
+	ui.Info("Images: %s", summary)
+	if failed := summary.FailedImages(); len(failed) > 0 {
+		return fmt.Errorf("failed to pull %s", strings.Join(failed, ", "))
+	}
+	ui.Info("Pin a service to the digest it pulled with: %s <service> <tag>@<digest>", constants.CmdRef(constants.CmdNameImagesPin))
+	return nil
+}
+
+// ValidateArgs validates the command arguments
+func (h *PullHandler) ValidateArgs(args []string) error {
+	return nil
+}
+
+// GetRequiredFlags returns required flags for this command
+func (h *PullHandler) GetRequiredFlags() []string {
+	return []string{}
+}
+
+// writePullDigests lists the images pulled with the digest each resolved to,
+// or the reason it failed
+func writePullDigests(out io.Writer, summary *docker.PullSummary) error {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "IMAGE\tDIGEST")
+	for _, result := range summary.Results {
+		digest := result.Digest
+		switch {
+		case result.Error != "":
+			digest = "failed: " + result.Error
+		case digest == "":
+			digest = "-"
+		}
+		_, _ = fmt.Fprintf(w, "%s\t%s\n", result.Image, digest)
+	}
+	return w.Flush()
+}
@@ -0,0 +1,121 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/isaacgarza/dev-stack/internal/core/docker"
+	"github.com/isaacgarza/dev-stack/internal/core/readiness"
+	"github.com/isaacgarza/dev-stack/internal/core/services"
+	"github.com/isaacgarza/dev-stack/internal/pkg/cli/handlers/utils"
+	"github.com/isaacgarza/dev-stack/internal/pkg/constants"
+	"github.com/isaacgarza/dev-stack/internal/pkg/types"
+	"github.com/isaacgarza/dev-stack/internal/pkg/ui"
+	pkgUtils "github.com/isaacgarza/dev-stack/internal/pkg/utils"
+)
+
+// parseServiceList splits a comma separated list of service names
+func parseServiceList(value string) []string {
+	var services []string
+	for _, name := range strings.Split(value, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			services = append(services, name)
+		}
+	}
+	return services
+}
+
+// readinessTargets resolves the readiness configuration for each service.
+// Project level overrides replace the probes shipped with the service
+// definition; services without a timeout use defaultTimeout. Variables in
+// the hosts, ports and URLs of probes are resolved with the project's .env
+// applied, as compose resolves the port mappings they refer to.
+func readinessTargets(cfg *ProjectConfig, serviceNames []string, defaultTimeout time.Duration) ([]readiness.Target, error) {
+	serviceUtils := utils.NewServiceUtils()
+	dotEnv, err := loadProjectDotEnv(filepath.Join(constants.DevStackDir, constants.ConfigFileName))
+	if err != nil {
+		return nil, err
+	}
+	lookup := pkgUtils.EnvLookup(dotEnv)
+
+	targets := make([]readiness.Target, 0, len(serviceNames))
+	for _, serviceName := range serviceNames {
+		var config types.ReadinessConfig
+		if override, ok := cfg.Readiness[serviceName]; ok {
+			config = override
+		} else if serviceConfig, err := serviceUtils.LoadServiceConfig(serviceName); err == nil {
+			config = serviceConfig.Readiness
+		}
+
+		timeout := defaultTimeout
+		if config.Timeout != "" {
+			parsed, err := time.ParseDuration(config.Timeout)
+			if err != nil {
+				return nil, fmt.Errorf("invalid readiness timeout for %s: %w", serviceName, err)
+			}
+			timeout = parsed
+		}
+
+		// The probes are copied, so the loaded service definition keeps its
+		// variables
+		probes := make([]types.ReadinessProbe, len(config.Probes))
+		for i, probe := range config.Probes {
+			probe.Host = pkgUtils.ExpandEnv(probe.Host, lookup)
+			probe.Port = pkgUtils.ExpandEnv(probe.Port, lookup)
+			probe.URL = pkgUtils.ExpandEnv(probe.URL, lookup)
+			probes[i] = probe
+		}
+		config.Probes = probes
+
+		targets = append(targets, readiness.Target{Service: serviceName, Readiness: config, Timeout: timeout})
+	}
+	return targets, nil
+}
+
+// waitForServices blocks until the readiness probes of every service pass
+// or their timeout expires
+func waitForServices(ctx context.Context, dockerClient *docker.Client, cfg *ProjectConfig, serviceNames []string, defaultTimeout time.Duration) error {
+	targets, err := readinessTargets(cfg, serviceNames, defaultTimeout)
+	if err != nil {
+		return err
+	}
+
+	ui.Info("Waiting for %s to become ready...", strings.Join(serviceNames, ", "))
+	checker := readiness.NewChecker(dockerClient.Containers(), cfg.Project.Name)
+
+	var notReady []string
+	for _, result := range checker.WaitForAll(ctx, targets) {
+		if result.Ready {
+			ui.Success("%s is ready (%s)", result.Service, result.Duration.Round(time.Millisecond))
+			continue
+		}
+		ui.Error("%s: %v", result.Service, result.Err)
+		notReady = append(notReady, result.Service)
+	}
+
+	if len(notReady) > 0 {
+		return fmt.Errorf("services not ready: %s", strings.Join(notReady, ", "))
+	}
+	return nil
+}
+
+// reportCrashLoops explains services failing to become ready by the crash
+// loops among them, saving their diagnostics and applying the crash-loop
+// policy of the project
+func reportCrashLoops(ctx context.Context, manager *services.Manager, cfg *ProjectConfig, serviceNames []string) {
+	if manager == nil {
+		return
+	}
+	manager.SetProjectName(cfg.Project.Name)
+	manager.SetCrashLoopPolicy(cfg.CrashLoop)
+	loops, err := manager.CheckCrashLoops(ctx, serviceNames)
+	if err != nil {
+		return
+	}
+	for _, loop := range loops {
+		ui.Error("%s", loop)
+	}
+}
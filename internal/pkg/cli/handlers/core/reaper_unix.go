@@ -0,0 +1,14 @@
+//go:build !windows
+
+package core
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// detach starts a background process in its own session, so it outlives
+// the terminal that ran `up`
+func detach(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setsid: true}
+}
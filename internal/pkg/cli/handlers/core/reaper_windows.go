@@ -0,0 +1,20 @@
+//go:build windows
+
+package core
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// detachedProcess is the DETACHED_PROCESS creation flag, which the syscall
+// package does not export
+const detachedProcess = 0x00000008
+
+// detach starts a background process without a console, so it outlives
+// the terminal that ran `up`
+func detach(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{
+		CreationFlags: syscall.CREATE_NEW_PROCESS_GROUP | detachedProcess,
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"path/filepath"
 
 	"github.com/isaacgarza/dev-stack/internal/core/docker"
+	"github.com/isaacgarza/dev-stack/internal/core/projectstate"
 	cliTypes "github.com/isaacgarza/dev-stack/internal/pkg/cli/types"
 	"github.com/isaacgarza/dev-stack/internal/pkg/constants"
 	"github.com/isaacgarza/dev-stack/internal/pkg/types"
@@ -39,9 +40,16 @@ func (h *RestartHandler) Handle(ctx context.Context, cmd *cobra.Command, args []
 		return fmt.Errorf("failed to load configuration: %w", err)
 	}
 
+	// Hold the project lock for the stop and start, like up and down
+	lock, err := projectstate.Acquire(projectstate.Path(), constants.CmdNameRestart)
+	if err != nil {
+		return err
+	}
+	defer releaseProjectLock(lock, base, nil)
+
 	// Create Docker client
 	logger := base.Logger.(loggerAdapter)
-	dockerClient, err := docker.NewClient(logger.SlogLogger())
+	dockerClient, err := docker.Shared(logger.SlogLogger())
 	if err != nil {
 		return fmt.Errorf("failed to create Docker client: %w", err)
 	}
@@ -52,7 +60,7 @@ func (h *RestartHandler) Handle(ctx context.Context, cmd *cobra.Command, args []
 	}()
 
 	// Parse flags
-	timeout, _ := cmd.Flags().GetInt("timeout")
+	timeout, _ := cmd.Flags().GetInt("stop-timeout")
 	build, _ := cmd.Flags().GetBool("build")
 
 	// Determine services to restart
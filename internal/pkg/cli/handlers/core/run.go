@@ -0,0 +1,221 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/isaacgarza/dev-stack/internal/core/services"
+	"github.com/isaacgarza/dev-stack/internal/core/tasks"
+	cliTypes "github.com/isaacgarza/dev-stack/internal/pkg/cli/types"
+	"github.com/isaacgarza/dev-stack/internal/pkg/constants"
+	"github.com/isaacgarza/dev-stack/internal/pkg/types"
+	"github.com/isaacgarza/dev-stack/internal/pkg/ui"
+	"github.com/isaacgarza/dev-stack/internal/pkg/utils"
+	"github.com/kballard/go-shellquote"
+	"github.com/spf13/cobra"
+)
+
+// TaskEnvVar is set to the running task for its command
+const TaskEnvVar = "DEV_STACK_TASK"
+
+// RunHandler handles the run command
+type RunHandler struct {
+	manager *services.Manager
+}
+
+// NewRunHandler creates a new run handler
+func NewRunHandler(manager *services.Manager) *RunHandler {
+	return &RunHandler{manager: manager}
+}
+
+// Handle executes the run command. Without arguments it lists the tasks;
+// otherwise it starts the services the named task depends on, waits for
+// them to be healthy and runs the task with the arguments after its name.
+// The exit code of the task becomes the exit code of dev-stack.
+func (h *RunHandler) Handle(ctx context.Context, cmd *cobra.Command, args []string, base *cliTypes.BaseCommand) error {
+	configPath := filepath.Join(constants.DevStackDir, constants.ConfigFileName)
+	if !utils.FileExists(configPath) {
+		return errors.New(constants.ErrNotInitialized)
+	}
+	cfg, err := LoadProjectConfig(configPath)
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+	projectTasks, err := ProjectTasks(cfg)
+	if err != nil {
+		return err
+	}
+	if runsAsUp(args, projectTasks, cfg.Stack.Enabled) {
+		ui.Warning("Starting services with run is deprecated and will be removed in the next release; use '%s' instead", constants.CmdUp)
+		return runUp(ctx, args)
+	}
+	if len(args) == 0 {
+		return writeTasks(cmd.OutOrStdout(), projectTasks)
+	}
+
+	name, extra := args[0], args[1:]
+	if len(extra) > 0 && extra[0] == "--" {
+		extra = extra[1:]
+	}
+	task, ok := projectTasks[name]
+	if !ok {
+		return fmt.Errorf("unknown task %q (available: %s)", name, strings.Join(projectTasks.Names(), ", "))
+	}
+	for _, service := range task.DependsOn {
+		if !slices.Contains(cfg.Stack.Enabled, service) {
+			return &types.ServiceNotFoundError{Service: service, NotEnabled: true}
+		}
+	}
+
+	skipServices, _ := cmd.Flags().GetBool("skip-services")
+	if len(task.DependsOn) > 0 && !skipServices {
+		if err := startTaskServices(ctx, task.DependsOn); err != nil {
+			return fmt.Errorf("failed to start the services of task %s: %w", name, err)
+		}
+	}
+
+	env, err := ServiceConnectionEnv(configPath, cfg.Stack.Enabled)
+	if err != nil {
+		return err
+	}
+	env[TaskEnvVar] = name
+	maps.Copy(env, task.Env)
+
+	root, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("failed to find the project root: %w", err)
+	}
+	command := task.Command
+	if len(extra) > 0 {
+		command += " " + shellquote.Join(extra...)
+	}
+
+	ui.Info("Running task %s: %s", name, command)
+	started := time.Now()
+	err = runTask(ctx, filepath.Join(root, task.WorkingDir), command, env)
+	elapsed := utils.FormatDuration(time.Since(started))
+
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		ui.Error("Task %s failed after %s", name, elapsed)
+		// The task has already reported its failure on its own output
+		cmd.SilenceErrors = true
+		cmd.SilenceUsage = true
+		return &types.ExitError{Command: name, Code: exitErr.ExitCode()}
+	}
+	if err != nil {
+		return fmt.Errorf("failed to run task %s: %w", name, err)
+	}
+	ui.Success("Task %s finished in %s", name, elapsed)
+	return nil
+}
+
+// ValidateArgs validates the command arguments
+func (h *RunHandler) ValidateArgs(args []string) error {
+	return nil
+}
+
+// GetRequiredFlags returns required flags for this command
+func (h *RunHandler) GetRequiredFlags() []string {
+	return []string{}
+}
+
+// ProjectTasks returns the tasks imported from the task_imports of cfg,
+// where the first file to define a task wins, and the tasks it declares,
+// which replace imported ones of the same name
+func ProjectTasks(cfg *ProjectConfig) (types.TasksConfig, error) {
+	root, err := os.Getwd()
+	if err != nil {
+		return nil, fmt.Errorf("failed to find the project root: %w", err)
+	}
+
+	projectTasks := make(types.TasksConfig)
+	for _, path := range cfg.TaskImports {
+		imported, err := tasks.Import(root, path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to import tasks: %w", err)
+		}
+		for name, task := range imported {
+			if _, exists := projectTasks[name]; !exists {
+				projectTasks[name] = task
+			}
+		}
+	}
+	maps.Copy(projectTasks, cfg.Tasks)
+	return projectTasks, nil
+}
+
+// runsAsUp reports whether run is used as the alias of up it used to be:
+// without arguments in a project that has no tasks, or with only enabled
+// services that aren't tasks
+func runsAsUp(args []string, projectTasks types.TasksConfig, enabled []string) bool {
+	if len(args) == 0 {
+		return len(projectTasks) == 0
+	}
+	for _, arg := range args {
+		if _, isTask := projectTasks[arg]; isTask || !slices.Contains(enabled, arg) {
+			return false
+		}
+	}
+	return true
+}
+
+// startTaskServices starts services with dev-stack up and waits until they
+// pass their readiness probes, so the task gets the same stack up would
+// start, hooks included
+func startTaskServices(ctx context.Context, serviceNames []string) error {
+	return runUp(ctx, append(slices.Clone(serviceNames), "--wait-for", strings.Join(serviceNames, ",")))
+}
+
+// runUp runs dev-stack up with args
+func runUp(ctx context.Context, args []string) error {
+	executable, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("failed to locate dev-stack executable: %w", err)
+	}
+	up := exec.CommandContext(ctx, executable, append([]string{constants.CmdNameUp}, args...)...)
+	up.Stdout = os.Stdout
+	up.Stderr = os.Stderr
+	return up.Run()
+}
+
+// runTask runs command with sh -c from dir, with env added to the
+// environment of the terminal
+func runTask(ctx context.Context, dir, command string, env map[string]string) error {
+	task := exec.CommandContext(ctx, "sh", "-c", command)
+	task.Dir = dir
+	task.Env = os.Environ()
+	for _, key := range slices.Sorted(maps.Keys(env)) {
+		task.Env = append(task.Env, key+"="+env[key])
+	}
+	task.Stdin = os.Stdin
+	task.Stdout = os.Stdout
+	task.Stderr = os.Stderr
+	return task.Run()
+}
+
+// writeTasks lists tasks with their descriptions and the services they
+// depend on
+func writeTasks(out io.Writer, projectTasks types.TasksConfig) error {
+	if len(projectTasks) == 0 {
+		_, err := fmt.Fprintln(out, "No tasks defined; add them under tasks or task_imports in dev-stack-config.yml")
+		return err
+	}
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "TASK\tDESCRIPTION\tSERVICES")
+	for _, name := range projectTasks.Names() {
+		task := projectTasks[name]
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\n", name, task.Description, strings.Join(task.DependsOn, ","))
+	}
+	return w.Flush()
+}
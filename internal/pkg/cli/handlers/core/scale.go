@@ -0,0 +1,127 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/isaacgarza/dev-stack/internal/core/services"
+	"github.com/isaacgarza/dev-stack/internal/pkg/cli/types"
+	"github.com/isaacgarza/dev-stack/internal/pkg/constants"
+	pkgTypes "github.com/isaacgarza/dev-stack/internal/pkg/types"
+	"github.com/isaacgarza/dev-stack/internal/pkg/ui"
+	"github.com/isaacgarza/dev-stack/internal/pkg/utils"
+	"github.com/spf13/cobra"
+)
+
+// ScaleHandler handles the scale command
+type ScaleHandler struct {
+	manager *services.Manager
+}
+
+// scaleTarget is a single service=replicas argument
+type scaleTarget struct {
+	Service  string
+	Replicas int
+}
+
+// NewScaleHandler creates a new scale handler
+func NewScaleHandler(manager *services.Manager) *ScaleHandler {
+	return &ScaleHandler{manager: manager}
+}
+
+// Handle executes the scale command
+func (h *ScaleHandler) Handle(ctx context.Context, cmd *cobra.Command, args []string, base *types.BaseCommand) error {
+	if err := h.ValidateArgs(args); err != nil {
+		return err
+	}
+	targets, err := parseScaleTargets(args)
+	if err != nil {
+		return err
+	}
+
+	configPath := filepath.Join(constants.DevStackDir, constants.ConfigFileName)
+	if !utils.FileExists(configPath) {
+		return errors.New(constants.ErrNotInitialized)
+	}
+
+	cfg, err := LoadProjectConfig(configPath)
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	for _, target := range targets {
+		if !slices.Contains(cfg.Stack.Enabled, target.Service) {
+			return &pkgTypes.ServiceNotFoundError{Service: target.Service, NotEnabled: true}
+		}
+	}
+
+	timeout, _ := cmd.Flags().GetInt("stop-timeout")
+	noRecreate, _ := cmd.Flags().GetBool("no-recreate")
+	options := pkgTypes.ScaleOptions{
+		Detach:     true,
+		Timeout:    time.Duration(timeout) * time.Second,
+		NoRecreate: noRecreate,
+	}
+
+	ui.Header("Scaling services")
+
+	h.manager.SetProjectName(cfg.Project.Name)
+	for _, target := range targets {
+		if err := h.manager.ScaleService(ctx, target.Service, target.Replicas, options); err != nil {
+			return fmt.Errorf("failed to scale %s: %w", target.Service, err)
+		}
+		ui.Success("Scaled %s to %d", target.Service, target.Replicas)
+	}
+
+	for _, target := range targets {
+		if target.Replicas > 1 {
+			ui.Info("Extra replicas join the service networks under the service name; host ports stay on the first container")
+			break
+		}
+	}
+	return nil
+}
+
+// parseScaleTargets parses service=replicas arguments, rejecting duplicates
+func parseScaleTargets(args []string) ([]scaleTarget, error) {
+	seen := make(map[string]bool, len(args))
+	targets := make([]scaleTarget, 0, len(args))
+	for _, arg := range args {
+		service, value, found := strings.Cut(arg, "=")
+		service = strings.TrimSpace(service)
+		if !found || service == "" {
+			return nil, fmt.Errorf("invalid scale argument %q, expected service=replicas", arg)
+		}
+
+		replicas, err := strconv.Atoi(strings.TrimSpace(value))
+		if err != nil || replicas < 0 {
+			return nil, fmt.Errorf("invalid replica count %q for %s", value, service)
+		}
+
+		if seen[service] {
+			return nil, fmt.Errorf("service %s specified more than once", service)
+		}
+		seen[service] = true
+		targets = append(targets, scaleTarget{Service: service, Replicas: replicas})
+	}
+	return targets, nil
+}
+
+// ValidateArgs validates the command arguments
+func (h *ScaleHandler) ValidateArgs(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("scale requires at least one service=replicas argument")
+	}
+	return nil
+}
+
+// GetRequiredFlags returns required flags for this command
+func (h *ScaleHandler) GetRequiredFlags() []string {
+	return []string{}
+}
@@ -0,0 +1,124 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/isaacgarza/dev-stack/internal/core/docker"
+	"github.com/isaacgarza/dev-stack/internal/core/shared"
+	"github.com/isaacgarza/dev-stack/internal/pkg/cli/handlers/utils"
+	"github.com/isaacgarza/dev-stack/internal/pkg/compose"
+	"github.com/isaacgarza/dev-stack/internal/pkg/ui"
+)
+
+// sharedServices returns the services among serviceNames that are shared
+// between projects, as declared by their definition or overridden in the
+// project's services settings
+func sharedServices(cfg *ProjectConfig, serviceNames []string) []string {
+	serviceUtils := utils.NewServiceUtils()
+
+	var result []string
+	for _, serviceName := range serviceNames {
+		isShared := false
+		if serviceConfig, err := serviceUtils.LoadServiceConfig(serviceName); err == nil {
+			isShared = serviceConfig.Shared
+		}
+		if settings, ok := cfg.Services[serviceName]; ok && settings.Shared != nil {
+			isShared = *settings.Shared
+		}
+		if isShared {
+			result = append(result, serviceName)
+		}
+	}
+	return result
+}
+
+// serviceProject returns the compose project a service of the project runs
+// in: the shared project when the service is shared
+func serviceProject(cfg *ProjectConfig, serviceName string) string {
+	if len(sharedServices(cfg, []string{serviceName})) > 0 {
+		return compose.SharedProject
+	}
+	return cfg.Project.Name
+}
+
+// attachSharedServices starts the shared services that are not running yet
+// and records the project as one of their users
+func attachSharedServices(ctx context.Context, client *docker.Client, cfg *ProjectConfig, serviceNames []string) error {
+	override, err := compose.SharedOverride(serviceNames, docker.ComposeFiles()...)
+	if err != nil {
+		return fmt.Errorf("failed to render the shared compose file: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(compose.SharedFile()), 0755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", filepath.Dir(compose.SharedFile()), err)
+	}
+	if err := os.WriteFile(compose.SharedFile(), override, 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", compose.SharedFile(), err)
+	}
+
+	statePath, err := shared.StateFile()
+	if err != nil {
+		return err
+	}
+	return shared.Update(statePath, func(state *shared.State) error {
+		for _, serviceName := range serviceNames {
+			started, err := client.Containers().StartShared(ctx, serviceName)
+			if err != nil {
+				return err
+			}
+			state.Attach(serviceName, docker.NormalizeProjectName(cfg.Project.Name))
+			if started {
+				ui.Info("Started shared %s", serviceName)
+				continue
+			}
+			ui.Info("Using shared %s (%d project(s))", serviceName, len(state.Services[serviceName].Projects))
+		}
+		return nil
+	})
+}
+
+// connectSharedServices makes the shared services reachable from the
+// project's networks, once the project's containers have created them
+func connectSharedServices(ctx context.Context, client *docker.Client, cfg *ProjectConfig, serviceNames []string) error {
+	for _, serviceName := range serviceNames {
+		if err := client.Containers().ConnectShared(ctx, cfg.Project.Name, serviceName); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// detachSharedServices removes the project from the users of its shared
+// services, limited to only when given, and stops each service the last
+// project detached from
+func detachSharedServices(ctx context.Context, client *docker.Client, cfg *ProjectConfig, only []string) error {
+	statePath, err := shared.StateFile()
+	if err != nil {
+		return err
+	}
+	project := docker.NormalizeProjectName(cfg.Project.Name)
+
+	return shared.Update(statePath, func(state *shared.State) error {
+		for _, serviceName := range state.ServicesOf(project) {
+			if len(only) > 0 && !slices.Contains(only, serviceName) {
+				continue
+			}
+			if err := client.Containers().DisconnectShared(ctx, cfg.Project.Name, serviceName); err != nil {
+				return err
+			}
+			remaining := state.Detach(serviceName, project)
+			if remaining > 0 {
+				ui.Info("Leaving shared %s running for %d other project(s)", serviceName, remaining)
+				continue
+			}
+			if err := client.Containers().RemoveShared(ctx, serviceName); err != nil {
+				return err
+			}
+			ui.Info("Stopped shared %s", serviceName)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,78 @@
+package core
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/isaacgarza/dev-stack/internal/core/services"
+	"github.com/isaacgarza/dev-stack/internal/pkg/cli/handlers/utils"
+	"github.com/isaacgarza/dev-stack/internal/pkg/types"
+	"github.com/isaacgarza/dev-stack/internal/pkg/ui"
+)
+
+// shutdownSettings resolves the shutdown settings of the enabled services
+// and of those the project configures. Project level settings replace
+// those of the service definition.
+func shutdownSettings(cfg *ProjectConfig) (map[string]types.ShutdownConfig, error) {
+	serviceNames := slices.Clone(cfg.Stack.Enabled)
+	for serviceName := range cfg.Shutdown {
+		if !slices.Contains(serviceNames, serviceName) {
+			serviceNames = append(serviceNames, serviceName)
+		}
+	}
+
+	serviceUtils := utils.NewServiceUtils()
+	settings := make(map[string]types.ShutdownConfig)
+	for _, serviceName := range serviceNames {
+		config, ok := cfg.Shutdown[serviceName]
+		if !ok {
+			serviceConfig, err := serviceUtils.LoadServiceConfig(serviceName)
+			if err != nil {
+				continue
+			}
+			config = serviceConfig.Shutdown
+		}
+		if config.GracePeriod != "" {
+			if grace, err := time.ParseDuration(config.GracePeriod); err != nil || grace < 0 {
+				return nil, fmt.Errorf("invalid shutdown grace period %q for %s: must be a duration such as 30s", config.GracePeriod, serviceName)
+			}
+		}
+		if config.GracePeriod != "" || len(config.PreStop) > 0 {
+			settings[serviceName] = config
+		}
+	}
+	return settings, nil
+}
+
+// ConfigureShutdown hands the shutdown settings of the project's services
+// to the manager, for the commands that stop services through it
+func ConfigureShutdown(manager *services.Manager, cfg *ProjectConfig) error {
+	if manager == nil {
+		return nil
+	}
+	settings, err := shutdownSettings(cfg)
+	if err != nil {
+		return err
+	}
+	manager.SetShutdown(settings)
+	return nil
+}
+
+// reportShutdown warns about the services that didn't stop cleanly and
+// fails when some are still running
+func reportShutdown(report *types.ShutdownReport) error {
+	for _, service := range report.Services {
+		if service.PreStopError != "" {
+			ui.Warning("%s: pre-stop command failed: %s", service.Service, service.PreStopError)
+		}
+	}
+	if killed := report.Named(types.ShutdownKilled); len(killed) > 0 {
+		ui.Warning("Killed after their grace period, so they didn't stop cleanly: %s", strings.Join(killed, ", "))
+	}
+	if running := report.Named(types.ShutdownRunning); len(running) > 0 {
+		return fmt.Errorf("services still running after their grace period: %s; run with --force to kill them", strings.Join(running, ", "))
+	}
+	return nil
+}
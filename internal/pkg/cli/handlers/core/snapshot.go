@@ -0,0 +1,397 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/isaacgarza/dev-stack/internal/core/docker"
+	"github.com/isaacgarza/dev-stack/internal/core/snapshot"
+	handlerUtils "github.com/isaacgarza/dev-stack/internal/pkg/cli/handlers/utils"
+	cliTypes "github.com/isaacgarza/dev-stack/internal/pkg/cli/types"
+	"github.com/isaacgarza/dev-stack/internal/pkg/constants"
+	"github.com/isaacgarza/dev-stack/internal/pkg/render"
+	"github.com/isaacgarza/dev-stack/internal/pkg/types"
+	"github.com/isaacgarza/dev-stack/internal/pkg/ui"
+	"github.com/isaacgarza/dev-stack/internal/pkg/utils"
+	"github.com/spf13/cobra"
+)
+
+// SnapshotCreateHandler handles the snapshot create command
+type SnapshotCreateHandler struct{}
+
+// NewSnapshotCreateHandler creates a new snapshot create handler
+func NewSnapshotCreateHandler() *SnapshotCreateHandler {
+	return &SnapshotCreateHandler{}
+}
+
+// Handle executes the snapshot create command
+func (h *SnapshotCreateHandler) Handle(ctx context.Context, cmd *cobra.Command, args []string, base *cliTypes.BaseCommand) error {
+	if err := h.ValidateArgs(args); err != nil {
+		return err
+	}
+	name := time.Now().Format("20060102-150405")
+	if len(args) == 1 {
+		name = args[0]
+	}
+	if err := snapshot.ValidateName(name); err != nil {
+		return err
+	}
+
+	force, _ := cmd.Flags().GetBool("force")
+	timeout, _ := cmd.Flags().GetInt("stop-timeout")
+
+	cfg, err := loadSnapshotConfig()
+	if err != nil {
+		return err
+	}
+	if utils.FileExists(snapshot.Dir(name)) && !force {
+		return fmt.Errorf("snapshot %s already exists; use --force to replace it", name)
+	}
+
+	dockerClient, err := newImagesDockerClient(base)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := dockerClient.Close(); err != nil {
+			base.Logger.Error("Failed to close Docker client", "error", err)
+		}
+	}()
+
+	serviceNames, err := runningServices(ctx, dockerClient, cfg.Project.Name)
+	if err != nil {
+		return err
+	}
+	if len(serviceNames) == 0 {
+		return fmt.Errorf("no running services to snapshot; run '%s' first", constants.CmdUp)
+	}
+
+	manifest := &snapshot.Manifest{Name: name, Project: cfg.Project.Name, CreatedAt: time.Now().UTC()}
+	for _, serviceName := range serviceNames {
+		mounts, err := dockerClient.Containers().VolumeMounts(ctx, cfg.Project.Name, serviceName)
+		if err != nil {
+			return err
+		}
+		if len(mounts) == 0 {
+			ui.Muted("Skipping %s: it has no volumes", serviceName)
+			continue
+		}
+		svc := snapshot.Service{Name: serviceName}
+		for _, mount := range mounts {
+			svc.Volumes = append(svc.Volumes, snapshot.Volume{
+				VolumeMount: mount,
+				Archive:     snapshot.ArchiveName(serviceName, mount.Destination),
+			})
+		}
+		manifest.Services = append(manifest.Services, svc)
+	}
+	if len(manifest.Services) == 0 {
+		return fmt.Errorf("none of the running services has volumes to snapshot")
+	}
+
+	ui.Header("Creating snapshot %s", name)
+
+	// Services are stopped while their volumes are copied so the archives
+	// hold a consistent state, and started again whatever happens
+	snapshotServices := manifest.ServiceNames()
+	ui.Info("Stopping %d service(s)...", len(snapshotServices))
+	if err := dockerClient.Containers().Stop(ctx, cfg.Project.Name, snapshotServices, types.StopOptions{Timeout: timeout}); err != nil {
+		return fmt.Errorf("failed to stop services: %w", err)
+	}
+	defer func() {
+		if err := dockerClient.Containers().Start(context.WithoutCancel(ctx), cfg.Project.Name, snapshotServices, types.StartOptions{Detach: true}); err != nil {
+			ui.Error("Failed to start services again: %v", err)
+		}
+	}()
+
+	if err := writeSnapshot(ctx, dockerClient, manifest); err != nil {
+		return err
+	}
+
+	ui.Success("Created snapshot %s (%s)", name, utils.FormatBytes(uint64(manifest.Size())))
+	ui.Info("Restore it with: %s %s", constants.CmdRef(constants.CmdNameSnapshotRestore), name)
+	return nil
+}
+
+// writeSnapshot copies the volumes in the manifest to the snapshot
+// directory. The snapshot is assembled next to its final location and only
+// replaces an existing one once complete.
+func writeSnapshot(ctx context.Context, dockerClient *docker.Client, manifest *snapshot.Manifest) error {
+	dir := snapshot.Dir(manifest.Name)
+	partial := dir + ".partial"
+	if err := os.RemoveAll(partial); err != nil {
+		return fmt.Errorf("failed to remove %s: %w", partial, err)
+	}
+	defer func() { _ = os.RemoveAll(partial) }()
+
+	for i := range manifest.Services {
+		svc := &manifest.Services[i]
+		for j := range svc.Volumes {
+			volume := &svc.Volumes[j]
+			ui.Info("Copying %s:%s...", svc.Name, volume.Destination)
+			size, err := exportVolume(ctx, dockerClient, manifest.Project, svc.Name, volume.Destination, filepath.Join(partial, volume.Archive))
+			if err != nil {
+				return err
+			}
+			volume.Size = size
+		}
+	}
+
+	if err := manifest.Save(partial); err != nil {
+		return err
+	}
+	if err := os.RemoveAll(dir); err != nil {
+		return fmt.Errorf("failed to replace snapshot %s: %w", manifest.Name, err)
+	}
+	if err := os.Rename(partial, dir); err != nil {
+		return fmt.Errorf("failed to store snapshot %s: %w", manifest.Name, err)
+	}
+	return nil
+}
+
+// exportVolume writes the archive of a service volume to target and returns
+// its size
+func exportVolume(ctx context.Context, dockerClient *docker.Client, projectName, serviceName, destination, target string) (int64, error) {
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return 0, fmt.Errorf("failed to create %s: %w", filepath.Dir(target), err)
+	}
+	file, err := os.Create(target)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create %s: %w", target, err)
+	}
+	defer func() { _ = file.Close() }()
+
+	if err := dockerClient.Containers().ExportVolume(ctx, projectName, serviceName, destination, file); err != nil {
+		return 0, err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		return 0, fmt.Errorf("failed to stat %s: %w", target, err)
+	}
+	if err := file.Close(); err != nil {
+		return 0, fmt.Errorf("failed to write %s: %w", target, err)
+	}
+	return info.Size(), nil
+}
+
+// ValidateArgs validates the command arguments
+func (h *SnapshotCreateHandler) ValidateArgs(args []string) error {
+	if len(args) > 1 {
+		return fmt.Errorf("snapshot create takes at most one snapshot name")
+	}
+	return nil
+}
+
+// GetRequiredFlags returns required flags for this command
+func (h *SnapshotCreateHandler) GetRequiredFlags() []string {
+	return []string{}
+}
+
+// SnapshotRestoreHandler handles the snapshot restore command
+type SnapshotRestoreHandler struct{}
+
+// NewSnapshotRestoreHandler creates a new snapshot restore handler
+func NewSnapshotRestoreHandler() *SnapshotRestoreHandler {
+	return &SnapshotRestoreHandler{}
+}
+
+// Handle executes the snapshot restore command
+func (h *SnapshotRestoreHandler) Handle(ctx context.Context, cmd *cobra.Command, args []string, base *cliTypes.BaseCommand) error {
+	if err := h.ValidateArgs(args); err != nil {
+		return err
+	}
+	timeout, _ := cmd.Flags().GetInt("stop-timeout")
+
+	cfg, err := loadSnapshotConfig()
+	if err != nil {
+		return err
+	}
+	manifest, err := snapshot.Load(args[0])
+	if err != nil {
+		return err
+	}
+	if manifest.Project != cfg.Project.Name {
+		ui.Warning("Snapshot %s was taken from project %s; restoring it into %s", manifest.Name, manifest.Project, cfg.Project.Name)
+	}
+
+	if err := ConfirmProtected(cmd, cfg, fmt.Sprintf("replace service data with snapshot %s", manifest.Name)); err != nil {
+		return err
+	}
+
+	dockerClient, err := newImagesDockerClient(base)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := dockerClient.Close(); err != nil {
+			base.Logger.Error("Failed to close Docker client", "error", err)
+		}
+	}()
+
+	ui.Header("Restoring snapshot %s", manifest.Name)
+	serviceNames := manifest.ServiceNames()
+	containers := dockerClient.Containers()
+
+	// Named volumes outlive their containers, so the current ones are
+	// removed explicitly for the data to start from the snapshot alone
+	var volumes []string
+	for _, serviceName := range serviceNames {
+		mounts, err := containers.VolumeMounts(ctx, cfg.Project.Name, serviceName)
+		if err != nil {
+			continue
+		}
+		for _, mount := range mounts {
+			if mount.Named && !slices.Contains(volumes, mount.Name) {
+				volumes = append(volumes, mount.Name)
+			}
+		}
+	}
+
+	ui.Info("Removing %d service(s)...", len(serviceNames))
+	if err := containers.Stop(ctx, cfg.Project.Name, serviceNames, types.StopOptions{
+		Timeout:       timeout,
+		Remove:        true,
+		RemoveVolumes: true,
+	}); err != nil {
+		return fmt.Errorf("failed to remove services: %w", err)
+	}
+	if err := dockerClient.Volumes().RemoveVolumes(ctx, volumes); err != nil {
+		return err
+	}
+
+	// Fresh containers get fresh volumes, which are filled before they start
+	if err := containers.Create(ctx, cfg.Project.Name, serviceNames); err != nil {
+		return fmt.Errorf("failed to recreate services: %w", err)
+	}
+	for _, svc := range manifest.Services {
+		for _, volume := range svc.Volumes {
+			ui.Info("Restoring %s:%s...", svc.Name, volume.Destination)
+			if err := importVolume(ctx, dockerClient, snapshot.Dir(manifest.Name), cfg.Project.Name, svc.Name, volume); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := containers.Start(ctx, cfg.Project.Name, serviceNames, types.StartOptions{Detach: true}); err != nil {
+		return fmt.Errorf("failed to start services: %w", err)
+	}
+
+	ui.Success("Restored snapshot %s", manifest.Name)
+	return nil
+}
+
+// importVolume extracts the archive of a volume into the service's new
+// container. The archive's top-level directory is the mount point itself,
+// so it is extracted into the parent directory.
+func importVolume(ctx context.Context, dockerClient *docker.Client, dir, projectName, serviceName string, volume snapshot.Volume) error {
+	archive := filepath.Join(dir, volume.Archive)
+	file, err := os.Open(archive)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", archive, err)
+	}
+	defer func() { _ = file.Close() }()
+
+	return dockerClient.Containers().CopyArchiveTo(ctx, projectName, serviceName, path.Dir(volume.Destination), file)
+}
+
+// ValidateArgs validates the command arguments
+func (h *SnapshotRestoreHandler) ValidateArgs(args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("snapshot restore requires the name of a snapshot")
+	}
+	return nil
+}
+
+// GetRequiredFlags returns required flags for this command
+func (h *SnapshotRestoreHandler) GetRequiredFlags() []string {
+	return []string{}
+}
+
+// SnapshotListHandler handles the snapshot list command
+type SnapshotListHandler struct{}
+
+// NewSnapshotListHandler creates a new snapshot list handler
+func NewSnapshotListHandler() *SnapshotListHandler {
+	return &SnapshotListHandler{}
+}
+
+// Handle executes the snapshot list command
+func (h *SnapshotListHandler) Handle(ctx context.Context, cmd *cobra.Command, args []string, base *cliTypes.BaseCommand) error {
+	output, err := handlerUtils.GetRenderOptions(cmd)
+	if err != nil {
+		return err
+	}
+	manifests, err := snapshot.List()
+	if err != nil {
+		return err
+	}
+	if len(manifests) == 0 && !output.Format.Structured() {
+		ui.Info("No snapshots; create one with '%s'", constants.CmdRef(constants.CmdNameSnapshotCreate))
+		return nil
+	}
+	return render.Render(cmd.OutOrStdout(), render.Table[snapshot.Manifest]{Columns: snapshotColumns, Rows: manifests}, output)
+}
+
+// snapshotColumns are the columns of the table of snapshots
+var snapshotColumns = []render.Column[snapshot.Manifest]{
+	{Name: "NAME", Value: func(m snapshot.Manifest) string { return m.Name }},
+	{Name: "CREATED", Value: func(m snapshot.Manifest) string { return m.CreatedAt.Local().Format("2006-01-02 15:04") }},
+	{Name: "SERVICES", Value: func(m snapshot.Manifest) string { return strconv.Itoa(len(m.Services)) }},
+	{Name: "SIZE", Value: func(m snapshot.Manifest) string { return utils.FormatBytes(uint64(m.Size())) }},
+	{Name: "VOLUMES", Wide: true, Value: func(m snapshot.Manifest) string {
+		volumes := 0
+		for _, service := range m.Services {
+			volumes += len(service.Volumes)
+		}
+		return strconv.Itoa(volumes)
+	}},
+	{Name: "PATH", Wide: true, Value: func(m snapshot.Manifest) string { return snapshot.Dir(m.Name) }},
+}
+
+// ValidateArgs validates the command arguments
+func (h *SnapshotListHandler) ValidateArgs(args []string) error {
+	return nil
+}
+
+// GetRequiredFlags returns required flags for this command
+func (h *SnapshotListHandler) GetRequiredFlags() []string {
+	return []string{}
+}
+
+// loadSnapshotConfig loads the project configuration snapshots work on
+func loadSnapshotConfig() (*ProjectConfig, error) {
+	configPath := filepath.Join(constants.DevStackDir, constants.ConfigFileName)
+	if !utils.FileExists(configPath) {
+		return nil, errors.New(constants.ErrNotInitialized)
+	}
+	cfg, err := LoadProjectConfig(configPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load configuration: %w", err)
+	}
+	return cfg, nil
+}
+
+// runningServices returns the project's running services, once each even
+// when scaled
+func runningServices(ctx context.Context, dockerClient *docker.Client, projectName string) ([]string, error) {
+	statuses, err := dockerClient.Containers().List(ctx, projectName, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list services: %w", err)
+	}
+
+	var names []string
+	for _, status := range statuses {
+		if status.State.IsRunning() && !slices.Contains(names, status.Name) {
+			names = append(names, status.Name)
+		}
+	}
+	slices.Sort(names)
+	return names, nil
+}
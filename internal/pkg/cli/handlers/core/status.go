@@ -4,31 +4,43 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"os"
 	"path/filepath"
+	"time"
 
 	"github.com/isaacgarza/dev-stack/internal/core/docker"
+	"github.com/isaacgarza/dev-stack/internal/core/projectstate"
+	"github.com/isaacgarza/dev-stack/internal/core/services"
 	"github.com/isaacgarza/dev-stack/internal/pkg/cli/handlers/utils"
 	"github.com/isaacgarza/dev-stack/internal/pkg/cli/types"
 	"github.com/isaacgarza/dev-stack/internal/pkg/constants"
+	"github.com/isaacgarza/dev-stack/internal/pkg/display"
+	"github.com/isaacgarza/dev-stack/internal/pkg/render"
 	"github.com/isaacgarza/dev-stack/internal/pkg/ui"
 	pkgUtils "github.com/isaacgarza/dev-stack/internal/pkg/utils"
 	"github.com/spf13/cobra"
 )
 
 // StatusHandler handles the status command
-type StatusHandler struct{}
+type StatusHandler struct {
+	manager *services.Manager
+}
 
 // NewStatusHandler creates a new status handler
-func NewStatusHandler() *StatusHandler {
-	return &StatusHandler{}
+func NewStatusHandler(manager *services.Manager) *StatusHandler {
+	return &StatusHandler{manager: manager}
 }
 
 // Handle executes the status command
 func (h *StatusHandler) Handle(ctx context.Context, cmd *cobra.Command, args []string, base *types.BaseCommand) error {
 	// Get CI-friendly flags
 	ciFlags := utils.GetCIFlags(cmd)
+	output, err := utils.GetRenderOptions(cmd)
+	if err != nil {
+		return err
+	}
 
-	if !ciFlags.Quiet {
+	if !ciFlags.Quiet && !output.Format.Structured() {
 		ui.Header(constants.MsgStatus)
 	}
 
@@ -48,7 +60,7 @@ func (h *StatusHandler) Handle(ctx context.Context, cmd *cobra.Command, args []s
 
 	// Create Docker client
 	logger := base.Logger.(loggerAdapter)
-	dockerClient, err := docker.NewClient(logger.SlogLogger())
+	dockerClient, err := docker.Shared(logger.SlogLogger())
 	if err != nil {
 		utils.HandleError(ciFlags, fmt.Errorf("failed to create Docker client: %w", err))
 		return nil
@@ -65,22 +77,116 @@ func (h *StatusHandler) Handle(ctx context.Context, cmd *cobra.Command, args []s
 		serviceNames = cfg.Stack.Enabled
 	}
 
+	h.manager.SetProjectName(cfg.Project.Name)
+	h.manager.SetCrashLoopPolicy(cfg.CrashLoop)
+	if noCache, _ := cmd.Flags().GetBool("no-cache"); noCache {
+		h.manager.SetStatusCacheTTL(0)
+	}
+
+	// Keep refreshing until interrupted or the --until condition is met
+	if watch, _ := cmd.Flags().GetBool("watch"); watch {
+		settings, err := h.watchSettings(cmd, ciFlags, output)
+		if err != nil {
+			return err
+		}
+		return h.watch(ctx, serviceNames, settings)
+	}
+
 	// Get service status
-	statuses, err := dockerClient.Containers().List(ctx, cfg.Project.Name, serviceNames)
+	statuses, err := h.manager.GetServiceStatus(ctx, serviceNames)
 	if err != nil {
-		utils.HandleError(ciFlags, fmt.Errorf("failed to get service status: %w", err))
+		utils.HandleError(ciFlags, err)
 		return nil
 	}
 
+	rows := toDisplayStatuses(statuses)
+
+	// Images built for another architecture explain slow services; not
+	// knowing which are is no reason to fail the command
+	emulated, err := dockerClient.EmulatedServices(ctx, cfg.Project.Name)
+	if err != nil {
+		base.Logger.Debug("Failed to check for emulated services", "error", err)
+		emulated = map[string]string{}
+	}
+	for i := range rows {
+		rows[i].Emulated = emulated[rows[i].Name]
+	}
+
 	// Handle CI-friendly output
-	utils.OutputResult(ciFlags, map[string]interface{}{
-		"services": statuses,
-		"count":    len(statuses),
-	}, constants.ExitSuccess)
+	if ciFlags.JSON {
+		replicas := make(map[string]int, len(rows))
+		for _, row := range rows {
+			replicas[row.Name] = row.Replicas
+		}
+		utils.OutputResult(ciFlags, map[string]interface{}{
+			"services": statuses,
+			"count":    len(statuses),
+			"replicas": replicas,
+			"emulated": emulated,
+		}, constants.ExitSuccess)
+		return nil
+	}
 
+	if ciFlags.Quiet {
+		return nil
+	}
+
+	if err := display.RenderStatus(os.Stdout, rows, display.StatusOptions{}, output); err != nil {
+		return err
+	}
+	if output.Format.Structured() {
+		return nil
+	}
+	if len(emulated) > 0 {
+		ui.Info("Services marked emulated run images built for another architecture than the Docker host, which makes them slower")
+		ui.Muted("Pin a version with a native image under services.<name>.version, or see 'dev-stack doctor'")
+	}
+	printStateNotes(configPath)
 	return nil
 }
 
+// printStateNotes tells when another command is changing the stack, or the
+// configuration changed since the last up applied it
+func printStateNotes(configPath string) {
+	state, err := projectstate.Load(projectstate.Path())
+	if err != nil {
+		return
+	}
+	if state.Lock != nil && !state.Lock.Stale() {
+		ui.Info("%s %s is changing the stack (pid %d, since %s)", constants.AppName, state.Lock.Operation, state.Lock.PID, state.Lock.StartedAt.Local().Format(time.Kitchen))
+	}
+	if state.ConfigHash == "" {
+		return
+	}
+	if hash, err := projectstate.HashConfig(configPath); err == nil && hash != state.ConfigHash {
+		ui.Warning("The configuration changed since the last up; run '%s' to apply it", constants.CmdUp)
+	}
+}
+
+// watchSettings reads the watch mode flags
+func (h *StatusHandler) watchSettings(cmd *cobra.Command, ciFlags utils.CIFlags, output render.Options) (statusWatch, error) {
+	intervalValue, _ := cmd.Flags().GetString("interval")
+	until, _ := cmd.Flags().GetString("until")
+	timeoutValue, _ := cmd.Flags().GetString("until-timeout")
+
+	interval, err := time.ParseDuration(intervalValue)
+	if err != nil || interval <= 0 {
+		return statusWatch{}, fmt.Errorf("invalid interval %q", intervalValue)
+	}
+
+	var timeout time.Duration
+	if timeoutValue != "" {
+		if timeout, err = time.ParseDuration(timeoutValue); err != nil {
+			return statusWatch{}, fmt.Errorf("invalid timeout %q: %w", timeoutValue, err)
+		}
+	}
+
+	// Only redraw in place for tables on an interactive terminal
+	clear := render.IsTerminal(os.Stdout) && !output.Format.Structured() && !ciFlags.NoColor
+
+	return statusWatch{output: output, interval: interval, until: until, timeout: timeout, clear: clear}, nil
+}
+
 // ValidateArgs validates the command arguments
 func (h *StatusHandler) ValidateArgs(args []string) error {
 	return nil
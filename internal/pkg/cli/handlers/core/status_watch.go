@@ -0,0 +1,230 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/isaacgarza/dev-stack/internal/core/docker"
+	"github.com/isaacgarza/dev-stack/internal/pkg/display"
+	"github.com/isaacgarza/dev-stack/internal/pkg/render"
+	"github.com/isaacgarza/dev-stack/internal/pkg/types"
+	"github.com/isaacgarza/dev-stack/internal/pkg/ui"
+)
+
+// Conditions accepted by status --until
+const (
+	untilHealthy = "healthy"
+	untilStopped = "stopped"
+)
+
+// statusWatch holds the settings of a status --watch run
+type statusWatch struct {
+	output   render.Options
+	interval time.Duration
+	until    string
+	timeout  time.Duration
+	clear    bool
+}
+
+// watch refreshes the status table until interrupted, the --until condition
+// is met or the --until-timeout expires. Reaching the timeout is an error so CI
+// scripts can rely on the exit code.
+func (h *StatusHandler) watch(ctx context.Context, serviceNames []string, settings statusWatch) error {
+	if settings.until != "" && settings.until != untilHealthy && settings.until != untilStopped {
+		return fmt.Errorf("invalid --until condition %q (supported: %s, %s)", settings.until, untilHealthy, untilStopped)
+	}
+
+	var deadline <-chan time.Time
+	if settings.timeout > 0 {
+		timer := time.NewTimer(settings.timeout)
+		defer timer.Stop()
+		deadline = timer.C
+	}
+
+	ticker := time.NewTicker(settings.interval)
+	defer ticker.Stop()
+
+	var previous []display.ServiceStatus
+	for {
+		statuses, err := h.manager.GetServiceStatus(ctx, serviceNames)
+		if err != nil && ctx.Err() == nil {
+			return err
+		}
+
+		if ctx.Err() == nil {
+			current := toDisplayStatuses(statuses)
+			changes := statusTransitions(previous, current)
+			previous = current
+
+			if settings.clear {
+				fmt.Print("\033[H\033[2J")
+			}
+			if err := display.RenderStatus(os.Stdout, current, display.StatusOptions{Changes: changes}, settings.output); err != nil {
+				return err
+			}
+			if settings.until != "" && untilConditionMet(settings.until, current, serviceNames) {
+				ui.Success("All services are %s", settings.until)
+				return nil
+			}
+			if settings.clear {
+				ui.Muted("Refreshing every %s, press Ctrl+C to stop", settings.interval)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-deadline:
+			return fmt.Errorf("timed out after %s waiting for services to be %s", settings.timeout, settings.until)
+		case <-ticker.C:
+		}
+	}
+}
+
+// toDisplayStatuses converts container statuses for the display formatters,
+// folding the replicas of a scaled service into a single row. The row shows
+// the primary container; health reflects the worst replica.
+func toDisplayStatuses(statuses []types.ServiceStatus) []display.ServiceStatus {
+	now := time.Now()
+	result := make([]display.ServiceStatus, 0, len(statuses))
+	index := make(map[string]int, len(statuses))
+	primary := make(map[string]int, len(statuses))
+	for _, status := range statuses {
+		number := docker.ContainerNumber(status.Labels)
+		running := 0
+		if status.State.IsRunning() {
+			running = 1
+		}
+
+		if i, ok := index[status.Name]; ok {
+			item := &result[i]
+			item.Replicas++
+			item.Running += running
+			if worseHealth(status.Health.String(), item.Health) {
+				item.Health = status.Health.String()
+			}
+			if number < primary[status.Name] && item.State != types.ServiceStateCrashLooping.String() {
+				primary[status.Name] = number
+				item.State = status.State.String()
+				item.Restarts = status.RestartCount
+				item.ExitCode = status.ExitCode
+			}
+			item.Ports = append(item.Ports, displayPorts(status.Ports)...)
+			// A crash-looping replica makes the whole service crash-looping
+			if status.State == types.ServiceStateCrashLooping && item.State != status.State.String() {
+				item.State = status.State.String()
+				item.Restarts = status.RestartCount
+				item.ExitCode = status.ExitCode
+			}
+			continue
+		}
+
+		item := display.ServiceStatus{
+			Name:      status.Name,
+			State:     status.State.String(),
+			Health:    status.Health.String(),
+			CreatedAt: status.CreatedAt,
+			UpdatedAt: now,
+			Replicas:  1,
+			Running:   running,
+			Restarts:  status.RestartCount,
+			ExitCode:  status.ExitCode,
+		}
+		if status.StartedAt != nil {
+			item.Uptime = now.Sub(*status.StartedAt)
+		}
+		item.Ports = displayPorts(status.Ports)
+		index[status.Name] = len(result)
+		primary[status.Name] = number
+		result = append(result, item)
+	}
+	return result
+}
+
+// displayPorts renders port mappings as host:container
+func displayPorts(ports []types.PortMapping) []string {
+	var result []string
+	for _, port := range ports {
+		result = append(result, port.Host+":"+port.Container)
+	}
+	return result
+}
+
+// worseHealth reports whether candidate is a worse health state than current
+func worseHealth(candidate, current string) bool {
+	rank := map[string]int{
+		types.HealthStatusUnhealthy.String(): 3,
+		types.HealthStatusStarting.String():  2,
+		types.HealthStatusHealthy.String():   1,
+	}
+	return rank[candidate] > rank[current]
+}
+
+// statusTransitions describes how each service's state or health changed
+// between two refreshes. The first refresh reports no changes.
+func statusTransitions(previous, current []display.ServiceStatus) map[string]string {
+	if previous == nil {
+		return nil
+	}
+
+	before := make(map[string]display.ServiceStatus, len(previous))
+	for _, status := range previous {
+		before[status.Name] = status
+	}
+
+	changes := make(map[string]string)
+	for _, status := range current {
+		old, ok := before[status.Name]
+		switch {
+		case !ok:
+			changes[status.Name] = "new"
+		case old.State != status.State && old.Health != status.Health:
+			changes[status.Name] = fmt.Sprintf("%s/%s → %s/%s", old.State, old.Health, status.State, status.Health)
+		case old.State != status.State:
+			changes[status.Name] = fmt.Sprintf("%s → %s", old.State, status.State)
+		case old.Health != status.Health:
+			changes[status.Name] = fmt.Sprintf("%s → %s", old.Health, status.Health)
+		case old.Replicas != status.Replicas:
+			changes[status.Name] = fmt.Sprintf("%d → %d replicas", old.Replicas, status.Replicas)
+		}
+	}
+	return changes
+}
+
+// untilConditionMet reports whether the watched services satisfy the
+// --until condition. Every expected service must have a container to count
+// as healthy; services without a container count as stopped.
+func untilConditionMet(condition string, statuses []display.ServiceStatus, expected []string) bool {
+	found := make(map[string]bool, len(statuses))
+	for _, status := range statuses {
+		found[status.Name] = true
+		switch condition {
+		case untilHealthy:
+			if status.State != types.ServiceStateRunning.String() || status.Running < status.Replicas {
+				return false
+			}
+			if status.Health != types.HealthStatusHealthy.String() && status.Health != types.HealthStatusNone.String() {
+				return false
+			}
+		case untilStopped:
+			if status.State == types.ServiceStateRunning.String() || status.Running > 0 {
+				return false
+			}
+		}
+	}
+
+	if condition == untilHealthy {
+		if len(statuses) == 0 {
+			return false
+		}
+		for _, name := range expected {
+			if !found[strings.TrimSpace(name)] {
+				return false
+			}
+		}
+	}
+	return true
+}
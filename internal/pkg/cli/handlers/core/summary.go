@@ -0,0 +1,52 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/isaacgarza/dev-stack/internal/core/services"
+	"github.com/isaacgarza/dev-stack/internal/pkg/types"
+	"github.com/isaacgarza/dev-stack/internal/pkg/ui"
+)
+
+// writeJobSummary adds the status and ports of the project's services to
+// the summary of the GitHub Actions job once command finishes. The summary
+// is a convenience, so failing to write it only warns.
+func writeJobSummary(ctx context.Context, manager *services.Manager, cfg *ProjectConfig, command string) {
+	if !ui.DefaultOutput.GitHub {
+		return
+	}
+	manager.SetProjectName(cfg.Project.Name)
+	statuses, err := manager.GetServiceStatus(context.WithoutCancel(ctx), nil)
+	if err != nil {
+		ui.Warning("Job summary not written: %v", err)
+		return
+	}
+	if err := ui.AppendJobSummary(jobSummary(fmt.Sprintf("dev-stack %s: %s", command, cfg.Project.Name), statuses)); err != nil {
+		ui.Warning("Job summary not written: %v", err)
+	}
+}
+
+// jobSummary renders statuses as a markdown table under title
+func jobSummary(title string, statuses []types.ServiceStatus) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "### %s\n\n", title)
+	if len(statuses) == 0 {
+		b.WriteString("No services are running.\n")
+		return b.String()
+	}
+	b.WriteString("| Service | State | Health | Ports |\n")
+	b.WriteString("| --- | --- | --- | --- |\n")
+	for _, status := range statuses {
+		fmt.Fprintf(&b, "| %s | %s | %s | %s |\n",
+			markdownCell(status.Name), markdownCell(status.State.String()), markdownCell(status.Health.String()),
+			markdownCell(strings.Join(displayPorts(status.Ports), ", ")))
+	}
+	return b.String()
+}
+
+// markdownCell escapes the pipes of a table cell
+func markdownCell(s string) string {
+	return strings.ReplaceAll(s, "|", `\|`)
+}
@@ -0,0 +1,302 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/docker/go-units"
+	"github.com/isaacgarza/dev-stack/internal/core/docker"
+	"github.com/isaacgarza/dev-stack/internal/pkg/cli/handlers/utils"
+	cliTypes "github.com/isaacgarza/dev-stack/internal/pkg/cli/types"
+	"github.com/isaacgarza/dev-stack/internal/pkg/constants"
+	"github.com/isaacgarza/dev-stack/internal/pkg/types"
+	"github.com/isaacgarza/dev-stack/internal/pkg/ui"
+	pkgUtils "github.com/isaacgarza/dev-stack/internal/pkg/utils"
+	"github.com/spf13/cobra"
+)
+
+// Columns top can sort by
+const (
+	topSortCPU    = "cpu"
+	topSortMemory = "memory"
+	topSortNet    = "net"
+	topSortBlock  = "block"
+	topSortPIDs   = "pids"
+	topSortName   = "name"
+)
+
+// topThresholds are the limits of top --threshold. Zero values are not
+// checked.
+type topThresholds struct {
+	CPUPercent    float64
+	MemoryPercent float64
+	MemoryBytes   int64
+	PIDs          uint64
+}
+
+// TopHandler handles the top command
+type TopHandler struct{}
+
+// NewTopHandler creates a new top handler
+func NewTopHandler() *TopHandler {
+	return &TopHandler{}
+}
+
+// Handle executes the top command
+func (h *TopHandler) Handle(ctx context.Context, cmd *cobra.Command, args []string, base *cliTypes.BaseCommand) error {
+	format, _ := cmd.Flags().GetString("format")
+	sortBy, _ := cmd.Flags().GetString("sort")
+	threshold, _ := cmd.Flags().GetString("threshold")
+	watch, _ := cmd.Flags().GetBool("watch")
+	intervalValue, _ := cmd.Flags().GetString("interval")
+	if utils.GetCIFlags(cmd).JSON {
+		format = "json"
+	}
+
+	if format != "table" && format != "json" {
+		return fmt.Errorf("invalid format %q (supported: table, json)", format)
+	}
+	if !slices.Contains(topSortColumns(), sortBy) {
+		return fmt.Errorf("invalid sort column %q (supported: %s)", sortBy, strings.Join(topSortColumns(), ", "))
+	}
+	thresholds, err := parseThresholds(threshold)
+	if err != nil {
+		return err
+	}
+	interval, err := time.ParseDuration(intervalValue)
+	if err != nil || interval <= 0 {
+		return fmt.Errorf("invalid interval %q", intervalValue)
+	}
+
+	configPath := filepath.Join(constants.DevStackDir, constants.ConfigFileName)
+	if !pkgUtils.FileExists(configPath) {
+		return errors.New(constants.ErrNotInitialized)
+	}
+	cfg, err := LoadProjectConfig(configPath)
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	logger := base.Logger.(loggerAdapter)
+	dockerClient, err := docker.Shared(logger.SlogLogger())
+	if err != nil {
+		return fmt.Errorf("failed to create Docker client: %w", err)
+	}
+	defer func() {
+		if err := dockerClient.Close(); err != nil {
+			base.Logger.Error("Failed to close Docker client", "error", err)
+		}
+	}()
+
+	// Only redraw in place for tables on an interactive terminal
+	clear := false
+	if info, err := os.Stdout.Stat(); err == nil && info.Mode()&os.ModeCharDevice != 0 {
+		clear = watch && format == "table"
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		stats, err := dockerClient.Containers().Stats(ctx, cfg.Project.Name, args)
+		if ctx.Err() != nil {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read service stats: %w", err)
+		}
+		sortStats(stats, sortBy)
+		exceeded := thresholds.exceeded(stats)
+
+		if clear {
+			fmt.Print("\033[H\033[2J")
+		}
+		if err := writeStats(cmd.OutOrStdout(), format, stats, exceeded); err != nil {
+			return err
+		}
+		if len(exceeded) > 0 {
+			if format == "table" {
+				for _, breach := range exceeded {
+					ui.Error("%s", breach)
+				}
+			}
+			return fmt.Errorf("%d threshold(s) exceeded", len(exceeded))
+		}
+		if !watch {
+			return nil
+		}
+		if clear {
+			ui.Muted("Refreshing every %s, press Ctrl+C to stop", interval)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// ValidateArgs validates the command arguments
+func (h *TopHandler) ValidateArgs(args []string) error {
+	return nil
+}
+
+// GetRequiredFlags returns required flags for this command
+func (h *TopHandler) GetRequiredFlags() []string {
+	return []string{}
+}
+
+// writeStats prints a stats sample as a table or as JSON
+func writeStats(w io.Writer, format string, stats []types.ServiceStats, exceeded []string) error {
+	if format == "json" {
+		if stats == nil {
+			stats = []types.ServiceStats{}
+		}
+		if exceeded == nil {
+			exceeded = []string{}
+		}
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(map[string]any{"services": stats, "exceeded": exceeded})
+	}
+
+	if len(stats) == 0 {
+		ui.Info("No running services")
+		return nil
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "SERVICE\tCONTAINER\tCPU %\tMEM USAGE / LIMIT\tMEM %\tNET I/O\tBLOCK I/O\tPIDS")
+	for _, s := range stats {
+		_, _ = fmt.Fprintf(tw, "%s\t%s\t%.2f%%\t%s / %s\t%.2f%%\t%s / %s\t%s / %s\t%d\n",
+			s.Service, s.Container, s.CPUPercent,
+			units.BytesSize(float64(s.Memory.Used)), units.BytesSize(float64(s.Memory.Limit)), s.Memory.Percent(),
+			units.HumanSize(float64(s.NetworkRx)), units.HumanSize(float64(s.NetworkTx)),
+			units.HumanSize(float64(s.BlockRead)), units.HumanSize(float64(s.BlockWrite)),
+			s.PIDs)
+	}
+	return tw.Flush()
+}
+
+// topSortColumns returns the columns top can sort by
+func topSortColumns() []string {
+	return []string{topSortCPU, topSortMemory, topSortNet, topSortBlock, topSortPIDs, topSortName}
+}
+
+// sortStats orders stats by column: names alphabetically, everything else
+// highest first
+func sortStats(stats []types.ServiceStats, column string) {
+	key := func(s types.ServiceStats) float64 {
+		switch column {
+		case topSortCPU:
+			return s.CPUPercent
+		case topSortMemory:
+			return float64(s.Memory.Used)
+		case topSortNet:
+			return float64(s.NetworkRx + s.NetworkTx)
+		case topSortBlock:
+			return float64(s.BlockRead + s.BlockWrite)
+		case topSortPIDs:
+			return float64(s.PIDs)
+		}
+		return 0
+	}
+
+	sort.SliceStable(stats, func(i, j int) bool {
+		if column == topSortName {
+			return stats[i].Container < stats[j].Container
+		}
+		return key(stats[i]) > key(stats[j])
+	})
+}
+
+// parseThresholds parses a comma-separated list of limits such as
+// cpu=80,memory=90%,pids=500. cpu is a percentage of one CPU, memory either
+// a percentage of the container's memory limit or a size such as 512m.
+func parseThresholds(spec string) (topThresholds, error) {
+	var thresholds topThresholds
+	if strings.TrimSpace(spec) == "" {
+		return thresholds, nil
+	}
+
+	for _, entry := range strings.Split(spec, ",") {
+		key, value, ok := strings.Cut(strings.TrimSpace(entry), "=")
+		value = strings.TrimSpace(value)
+		if !ok || value == "" {
+			return thresholds, fmt.Errorf("invalid threshold %q: expected <metric>=<limit>", entry)
+		}
+
+		switch strings.TrimSpace(key) {
+		case "cpu":
+			percent, err := parsePercent(value)
+			if err != nil {
+				return thresholds, fmt.Errorf("invalid cpu threshold %q: %w", value, err)
+			}
+			thresholds.CPUPercent = percent
+		case "memory":
+			if strings.HasSuffix(value, "%") {
+				percent, err := parsePercent(value)
+				if err != nil {
+					return thresholds, fmt.Errorf("invalid memory threshold %q: %w", value, err)
+				}
+				thresholds.MemoryPercent = percent
+				continue
+			}
+			bytes, err := units.RAMInBytes(value)
+			if err != nil || bytes <= 0 {
+				return thresholds, fmt.Errorf("invalid memory threshold %q: expected a percentage or a size such as 512m", value)
+			}
+			thresholds.MemoryBytes = bytes
+		case "pids":
+			pids, err := strconv.ParseUint(value, 10, 64)
+			if err != nil || pids == 0 {
+				return thresholds, fmt.Errorf("invalid pids threshold %q: expected a positive number", value)
+			}
+			thresholds.PIDs = pids
+		default:
+			return thresholds, fmt.Errorf("unknown threshold metric %q (supported: cpu, memory, pids)", key)
+		}
+	}
+	return thresholds, nil
+}
+
+// parsePercent parses a positive percentage with an optional % sign
+func parsePercent(value string) (float64, error) {
+	percent, err := strconv.ParseFloat(strings.TrimSuffix(value, "%"), 64)
+	if err != nil || percent <= 0 {
+		return 0, fmt.Errorf("expected a positive percentage")
+	}
+	return percent, nil
+}
+
+// exceeded describes every limit a container is over
+func (t topThresholds) exceeded(stats []types.ServiceStats) []string {
+	var breaches []string
+	for _, s := range stats {
+		if t.CPUPercent > 0 && s.CPUPercent > t.CPUPercent {
+			breaches = append(breaches, fmt.Sprintf("%s: CPU %.2f%% exceeds %g%%", s.Container, s.CPUPercent, t.CPUPercent))
+		}
+		if t.MemoryPercent > 0 && s.Memory.Limit > 0 && s.Memory.Percent() > t.MemoryPercent {
+			breaches = append(breaches, fmt.Sprintf("%s: memory %.2f%% of its limit exceeds %g%%", s.Container, s.Memory.Percent(), t.MemoryPercent))
+		}
+		if t.MemoryBytes > 0 && s.Memory.Used > uint64(t.MemoryBytes) {
+			breaches = append(breaches, fmt.Sprintf("%s: memory %s exceeds %s", s.Container,
+				units.BytesSize(float64(s.Memory.Used)), units.BytesSize(float64(t.MemoryBytes))))
+		}
+		if t.PIDs > 0 && s.PIDs > t.PIDs {
+			breaches = append(breaches, fmt.Sprintf("%s: %d processes exceed %d", s.Container, s.PIDs, t.PIDs))
+		}
+	}
+	return breaches
+}
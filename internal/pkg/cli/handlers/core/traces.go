@@ -0,0 +1,160 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"slices"
+	"time"
+
+	"github.com/isaacgarza/dev-stack/internal/core/tracing"
+	handlerUtils "github.com/isaacgarza/dev-stack/internal/pkg/cli/handlers/utils"
+	cliTypes "github.com/isaacgarza/dev-stack/internal/pkg/cli/types"
+	"github.com/isaacgarza/dev-stack/internal/pkg/types"
+	"github.com/isaacgarza/dev-stack/internal/pkg/ui"
+	"github.com/isaacgarza/dev-stack/internal/pkg/utils"
+	"github.com/spf13/cobra"
+)
+
+// defaultPingTimeout is how long traces ping waits for Jaeger to hold the
+// test span
+const defaultPingTimeout = 10 * time.Second
+
+// TracesOpenHandler handles the traces open command
+type TracesOpenHandler struct{}
+
+// NewTracesOpenHandler creates a new traces open handler
+func NewTracesOpenHandler() *TracesOpenHandler {
+	return &TracesOpenHandler{}
+}
+
+// Handle executes the traces open command
+func (h *TracesOpenHandler) Handle(ctx context.Context, cmd *cobra.Command, args []string, base *cliTypes.BaseCommand) error {
+	service, _ := cmd.Flags().GetString("service")
+	traceID, _ := cmd.Flags().GetString("trace")
+	if service != "" && traceID != "" {
+		return fmt.Errorf("--service and --trace cannot be used together")
+	}
+
+	cfg, lookup, err := loadTracingConfig()
+	if err != nil {
+		return err
+	}
+	urls, err := stackURLs(cfg, []string{tracing.ServiceName}, lookup)
+	if err != nil {
+		return err
+	}
+	if len(urls) == 0 {
+		return fmt.Errorf("service %s publishes no web interface", tracing.ServiceName)
+	}
+
+	target := jaegerPage(urls[0].URL, service, traceID)
+	fmt.Println(target)
+	if err := utils.OpenURL(target); err != nil {
+		ui.Muted("Could not open a browser: %v", err)
+	}
+	return nil
+}
+
+// ValidateArgs validates the command arguments
+func (h *TracesOpenHandler) ValidateArgs(args []string) error {
+	if len(args) > 0 {
+		return fmt.Errorf("traces open takes no arguments")
+	}
+	return nil
+}
+
+// GetRequiredFlags returns required flags for this command
+func (h *TracesOpenHandler) GetRequiredFlags() []string {
+	return []string{}
+}
+
+// jaegerPage returns the page of the Jaeger UI at base showing a trace, the
+// traces of a service, or the search page
+func jaegerPage(base, service, traceID string) string {
+	switch {
+	case traceID != "":
+		return base + "/trace/" + url.PathEscape(traceID)
+	case service != "":
+		return base + "/search?" + url.Values{"service": {service}}.Encode()
+	}
+	return base + "/search"
+}
+
+// TracesPingHandler handles the traces ping command
+type TracesPingHandler struct{}
+
+// NewTracesPingHandler creates a new traces ping handler
+func NewTracesPingHandler() *TracesPingHandler {
+	return &TracesPingHandler{}
+}
+
+// Handle executes the traces ping command
+func (h *TracesPingHandler) Handle(ctx context.Context, cmd *cobra.Command, args []string, base *cliTypes.BaseCommand) error {
+	serviceName, _ := cmd.Flags().GetString("service-name")
+	if serviceName == "" {
+		serviceName = tracing.DefaultPingServiceName
+	}
+	timeout := defaultPingTimeout
+	if value, _ := cmd.Flags().GetString("wait-timeout"); value != "" {
+		parsed, err := time.ParseDuration(value)
+		if err != nil || parsed <= 0 {
+			return fmt.Errorf("invalid --wait-timeout %q: expected a duration such as 10s or 1m", value)
+		}
+		timeout = parsed
+	}
+
+	_, lookup, err := loadTracingConfig()
+	if err != nil {
+		return err
+	}
+	serviceConfig, err := handlerUtils.NewServiceUtils().LoadServiceConfig(tracing.ServiceName)
+	if err != nil {
+		return fmt.Errorf("failed to load service %s: %w", tracing.ServiceName, err)
+	}
+	collectorURL := utils.ExpandEnv(serviceConfig.Environment["OTEL_EXPORTER_OTLP_ENDPOINT"], lookup)
+	queryURL := utils.ExpandEnv(serviceConfig.Environment["JAEGER_UI_URL"], lookup)
+	if collectorURL == "" || queryURL == "" {
+		return fmt.Errorf("service %s sets no OTEL_EXPORTER_OTLP_ENDPOINT or JAEGER_UI_URL", tracing.ServiceName)
+	}
+
+	ui.Muted("Sending a span as %s to %s", serviceName, collectorURL)
+	client := tracing.NewClient(nil, collectorURL, queryURL)
+	result, err := client.Ping(ctx, serviceName, timeout)
+	if err != nil {
+		return err
+	}
+	ui.Success("Trace %s reached Jaeger in %s", result.TraceID, result.Latency.Round(time.Millisecond))
+	ui.Info("View it at %s", client.TraceURL(result.TraceID, false))
+	return nil
+}
+
+// ValidateArgs validates the command arguments
+func (h *TracesPingHandler) ValidateArgs(args []string) error {
+	if len(args) > 0 {
+		return fmt.Errorf("traces ping takes no arguments")
+	}
+	return nil
+}
+
+// GetRequiredFlags returns required flags for this command
+func (h *TracesPingHandler) GetRequiredFlags() []string {
+	return []string{}
+}
+
+// loadTracingConfig loads the project configuration, which must enable
+// Jaeger, and a lookup resolving ports with its .env applied
+func loadTracingConfig() (*ProjectConfig, func(string) (string, bool), error) {
+	cfg, configPath, err := loadImagesConfig()
+	if err != nil {
+		return nil, nil, err
+	}
+	if !slices.Contains(cfg.Stack.Enabled, tracing.ServiceName) {
+		return nil, nil, &types.ServiceNotFoundError{Service: tracing.ServiceName, NotEnabled: true}
+	}
+	dotEnv, err := loadProjectDotEnv(configPath)
+	if err != nil {
+		return nil, nil, err
+	}
+	return cfg, utils.EnvLookup(dotEnv), nil
+}
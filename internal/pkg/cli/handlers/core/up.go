@@ -4,10 +4,18 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"os"
 	"path/filepath"
+	"slices"
+	"strings"
+	"time"
 
 	"github.com/isaacgarza/dev-stack/internal/core/docker"
+	"github.com/isaacgarza/dev-stack/internal/core/projectstate"
+	"github.com/isaacgarza/dev-stack/internal/core/services"
+	handlerUtils "github.com/isaacgarza/dev-stack/internal/pkg/cli/handlers/utils"
 	cliTypes "github.com/isaacgarza/dev-stack/internal/pkg/cli/types"
+	"github.com/isaacgarza/dev-stack/internal/pkg/compose"
 	"github.com/isaacgarza/dev-stack/internal/pkg/constants"
 	"github.com/isaacgarza/dev-stack/internal/pkg/types"
 	"github.com/isaacgarza/dev-stack/internal/pkg/ui"
@@ -16,16 +24,35 @@ import (
 )
 
 // UpHandler handles the up command
-type UpHandler struct{}
+type UpHandler struct {
+	manager *services.Manager
+}
 
 // NewUpHandler creates a new up handler
-func NewUpHandler() *UpHandler {
-	return &UpHandler{}
+func NewUpHandler(manager *services.Manager) *UpHandler {
+	return &UpHandler{manager: manager}
 }
 
 // Handle executes the up command
 func (h *UpHandler) Handle(ctx context.Context, cmd *cobra.Command, args []string, base *cliTypes.BaseCommand) error {
-	ui.Header(constants.MsgStarting)
+	// A dry run prints the plan alone, which may be JSON
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+	if !dryRun {
+		ui.Header(constants.MsgStarting)
+	}
+
+	// A workspace root starts its projects rather than a stack of its own
+	if selection, _ := cmd.Flags().GetString("workspace"); selection != "" {
+		if dryRun {
+			return errors.New("--dry-run can't be combined with --workspace")
+		}
+		timeoutValue, _ := cmd.Flags().GetString("wait-timeout")
+		timeout, err := time.ParseDuration(timeoutValue)
+		if err != nil {
+			return fmt.Errorf("invalid timeout %q: %w", timeoutValue, err)
+		}
+		return upWorkspace(ctx, cmd, selection, timeout, base)
+	}
 
 	// Check if dev-stack is initialized
 	configPath := filepath.Join(constants.DevStackDir, constants.ConfigFileName)
@@ -39,9 +66,23 @@ func (h *UpHandler) Handle(ctx context.Context, cmd *cobra.Command, args []strin
 		return fmt.Errorf("failed to load configuration: %w", err)
 	}
 
+	// A dry run only prints the plan, before anything touches Docker
+	if dryRun {
+		return h.printPlan(cmd, cfg, configPath, args)
+	}
+
+	// Hold the project lock, so a down in another terminal waits for the
+	// stack to be up; what was applied is recorded once it is
+	lock, err := projectstate.Acquire(projectstate.Path(), constants.CmdNameUp)
+	if err != nil {
+		return err
+	}
+	var applied func(*projectstate.State)
+	defer func() { releaseProjectLock(lock, base, applied) }()
+
 	// Create Docker client
 	logger := base.Logger.(loggerAdapter)
-	dockerClient, err := docker.NewClient(logger.SlogLogger())
+	dockerClient, err := docker.Shared(logger.SlogLogger())
 	if err != nil {
 		return fmt.Errorf("failed to create Docker client: %w", err)
 	}
@@ -50,33 +91,387 @@ func (h *UpHandler) Handle(ctx context.Context, cmd *cobra.Command, args []strin
 			base.Logger.Error("Failed to close Docker client", "error", err)
 		}
 	}()
+	defer writeJobSummary(ctx, h.manager, cfg, constants.CmdNameUp)
 
 	// Parse flags
 	build, _ := cmd.Flags().GetBool("build")
 	forceRecreate, _ := cmd.Flags().GetBool("force-recreate")
+	noDeps, _ := cmd.Flags().GetBool("no-deps")
+	maxParallel, _ := cmd.Flags().GetInt("max-parallel")
+	waitFor, _ := cmd.Flags().GetString("wait-for")
+	timeoutValue, _ := cmd.Flags().GetString("wait-timeout")
+	ephemeral, _ := cmd.Flags().GetBool("ephemeral")
+	ttlValue, _ := cmd.Flags().GetString("ttl")
+
+	timeout, err := time.ParseDuration(timeoutValue)
+	if err != nil {
+		return fmt.Errorf("invalid timeout %q: %w", timeoutValue, err)
+	}
+	ttl, err := time.ParseDuration(ttlValue)
+	if err != nil || ttl <= 0 {
+		return fmt.Errorf("invalid ttl %q: must be a positive duration", ttlValue)
+	}
+
+	if maxParallel <= 0 {
+		maxParallel = cfg.Stack.MaxParallel
+	}
 
 	options := types.StartOptions{
 		Build:         build,
 		ForceRecreate: forceRecreate,
+		NoDeps:        noDeps,
 		Detach:        true,
+		MaxParallel:   maxParallel,
+	}
+
+	// Reclaim expired ephemeral stacks before spending resources on a new one
+	reapExpiredStacks(ctx, dockerClient, base)
+
+	// Friendly hostnames are a convenience, so a hosts file that can't be
+	// written doesn't stop the stack
+	if cfg.Hostnames.Enabled {
+		if err := syncHostnames(cfg); err != nil {
+			ui.Warning("Hostnames not registered: %v", err)
+		}
+	}
+
+	// Label what the stack creates, so cleanup only removes the project's
+	// own resources and finds those of deleted projects
+	if err := writeLabelsFile(cfg.Project.Name); err != nil {
+		return err
+	}
+
+	// Ephemeral stacks start from fresh anonymous volumes labelled with their expiry
+	expiresAt := time.Now().Add(ttl)
+	if ephemeral {
+		if err := writeEphemeralFile(expiresAt); err != nil {
+			return err
+		}
+		options.RenewAnonVolumes = true
+	}
+
+	// Determine services to start, preferring the active profile's services
+	activeProfile := ActiveProfile(cmd)
+	if activeProfile != "" {
+		options.Profiles = []string{activeProfile}
+	}
+	serviceNames, err := selectedServices(cfg, args, activeProfile)
+	if err != nil {
+		return err
+	}
+	if cfg.PullPolicy() == constants.PullPolicyNever {
+		options.Pull = constants.PullPolicyNever
+	}
+
+	if err := ConfigureHooks(h.manager, cfg, configPath); err != nil {
+		return err
+	}
+	if err := h.manager.RunHooks(ctx, types.HookPreUp, serviceNames, nil); err != nil {
+		return err
+	}
+
+	// Wake the services the idle reaper put to sleep, rather than leave
+	// them paused or holding on to their ports
+	h.manager.SetProjectName(cfg.Project.Name)
+	woken, err := WakeIdle(ctx, h.manager, serviceNames)
+	if err != nil {
+		return err
+	}
+	if len(woken) > 0 {
+		ui.Info("Woke idle services: %s", strings.Join(woken, ", "))
+	}
+
+	// Shared services run once on the host; the project only attaches to
+	// them, so its own copies and dependencies on them are left out
+	hookServices := serviceNames
+	sharedNames := sharedServices(cfg, serviceNames)
+	if len(sharedNames) > 0 {
+		if err := attachSharedServices(ctx, dockerClient, cfg, sharedNames); err != nil {
+			return err
+		}
+		serviceNames = slices.DeleteFunc(slices.Clone(serviceNames), func(name string) bool {
+			return slices.Contains(sharedNames, name)
+		})
+		options.NoDeps = true
+	}
+
+	// Pull images up front so transient registry failures don't abort the stack
+	endGroup := ui.Group("Pulling images")
+	err = pullServiceImages(ctx, dockerClient, cfg, serviceNames)
+	endGroup()
+	if err != nil {
+		return fmt.Errorf("failed to pull images: %w", err)
 	}
 
-	// Determine services to start
-	serviceNames := args
-	if len(serviceNames) == 0 {
-		serviceNames = cfg.Stack.Enabled
+	// Leave the services that match their definition running, so an up
+	// after a change only touches what changed. Rebuilding, recreating and
+	// renewing volumes touch every service anyway.
+	startNames := serviceNames
+	if len(serviceNames) > 0 && !build && !forceRecreate && !ephemeral {
+		startNames = reconcileServices(ctx, dockerClient, cfg, serviceNames, &options, base)
 	}
 
 	// Start services
-	if err := dockerClient.Containers().Start(ctx, cfg.Project.Name, serviceNames, options); err != nil {
-		return fmt.Errorf("failed to start services: %w", err)
+	if len(startNames) > 0 || (len(serviceNames) == 0 && len(sharedNames) == 0) {
+		endGroup := ui.Group("Starting services")
+		board := ui.NewProgressBoard()
+		options.Progress = board
+		err := dockerClient.Containers().Start(ctx, cfg.Project.Name, startNames, options)
+		board.Stop()
+		endGroup()
+		if err != nil {
+			return fmt.Errorf("failed to start services: %w", err)
+		}
+	}
+	if len(sharedNames) > 0 {
+		if err := connectSharedServices(ctx, dockerClient, cfg, sharedNames); err != nil {
+			return err
+		}
+	}
+
+	// Block until the requested services pass their readiness probes
+	waitServices := slices.DeleteFunc(parseServiceList(waitFor), func(name string) bool {
+		return slices.Contains(sharedNames, name)
+	})
+	if len(waitServices) > 0 {
+		endGroup := ui.Group("Waiting for %s", strings.Join(waitServices, ", "))
+		err := waitForServices(ctx, dockerClient, cfg, waitServices, timeout)
+		endGroup()
+		if err != nil {
+			reportCrashLoops(ctx, h.manager, cfg, waitServices)
+			return err
+		}
+	}
+
+	// Create the declared AWS resources and Kafka topics once LocalStack and
+	// the broker are healthy
+	if err := provisionAWSResources(ctx, dockerClient, cfg, hookServices, timeout); err != nil {
+		return err
+	}
+	if err := reconcileKafkaTopics(ctx, dockerClient, cfg, hookServices, timeout); err != nil {
+		return err
+	}
+	pruneCollectedLogs(ctx, dockerClient, cfg, hookServices)
+
+	if err := h.manager.RunHooks(ctx, types.HookPostUp, hookServices, nil); err != nil {
+		return err
+	}
+
+	configHash, err := projectstate.HashConfig(configPath)
+	if err != nil {
+		return fmt.Errorf("failed to hash %s: %w", configPath, err)
+	}
+	applied = func(state *projectstate.State) {
+		state.ConfigHash = configHash
+		state.Profile = activeProfile
+		state.Services = mergeServiceNames(state.Services, hookServices)
+		state.AppliedAt = time.Now().UTC()
 	}
 
 	ui.Success(constants.MsgStartSuccess)
+	if ephemeral {
+		ui.Info("Ephemeral stack expires at %s", expiresAt.Format(time.Kitchen))
+		if err := startReaper(ctx, cfg.Project.Name); err != nil {
+			ui.Warning("Failed to start the background reaper, run '%s' to remove expired stacks: %v", constants.CmdGC, err)
+		}
+	} else if isEphemeral() {
+		ui.Info("Stack is ephemeral; run '%s' to make it persistent", constants.CmdDown)
+	}
+	if cfg.Idle.Enabled {
+		if err := startIdleWatcher(ctx); err != nil {
+			ui.Warning("Failed to start the idle reaper: %v", err)
+		}
+	}
 	ui.Info("Run '%s' to check service status", constants.CmdStatus)
 	return nil
 }
 
+// printPlan prints what up would do with the flags of cmd
+func (h *UpHandler) printPlan(cmd *cobra.Command, cfg *ProjectConfig, configPath string, args []string) error {
+	noDeps, _ := cmd.Flags().GetBool("no-deps")
+	build, _ := cmd.Flags().GetBool("build")
+	ephemeral, _ := cmd.Flags().GetBool("ephemeral")
+
+	serviceNames, err := selectedServices(cfg, args, ActiveProfile(cmd))
+	if err != nil {
+		return err
+	}
+	plan, err := upPlan(cfg, configPath, serviceNames, noDeps, build, ephemeral)
+	if err != nil {
+		return err
+	}
+	return WritePlan(cmd.OutOrStdout(), handlerUtils.GetCIFlags(cmd).JSON, plan)
+}
+
+// reapExpiredStacks removes the ephemeral stacks whose TTL has passed, of
+// every project. Failures are logged: they must not keep the stack from
+// starting.
+func reapExpiredStacks(ctx context.Context, client *docker.Client, base *cliTypes.BaseCommand) {
+	stacks, err := client.Containers().EphemeralStacks(ctx)
+	if err != nil {
+		base.Logger.Error("Failed to list ephemeral stacks", "error", err)
+		return
+	}
+	for _, stack := range stacks {
+		if !stack.Expired(time.Now()) {
+			continue
+		}
+		if err := removeEphemeralStack(ctx, client, stack); err != nil {
+			base.Logger.Error("Failed to remove expired stack", "project", stack.Project, "error", err)
+			continue
+		}
+		ui.Info("Removed expired ephemeral stack %s", stack.Project)
+	}
+}
+
+// writeLabelsFile renders the compose file labelling the resources of the
+// project with its name and directory, replacing the previous one so it
+// follows changes to the compose files
+func writeLabelsFile(projectName string) error {
+	if !utils.FileExists(constants.DockerComposeFile) {
+		return nil
+	}
+	if err := os.Remove(compose.LabelsFile()); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove the previous labels file: %w", err)
+	}
+	projectDir, err := filepath.Abs(".")
+	if err != nil {
+		return err
+	}
+
+	data, err := compose.LabelsOverride(projectName, projectDir, docker.ComposeFiles()...)
+	if err != nil {
+		return fmt.Errorf("failed to render the labels compose file: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(compose.LabelsFile()), 0755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", filepath.Dir(compose.LabelsFile()), err)
+	}
+	return os.WriteFile(compose.LabelsFile(), data, 0644)
+}
+
+// selectedServices returns the services named in args or, without any, the
+// services of the active profile, falling back to the enabled services,
+// with the compose profiles applied
+func selectedServices(cfg *ProjectConfig, args []string, activeProfile string) ([]string, error) {
+	if len(args) > 0 {
+		return args, nil
+	}
+	serviceNames := cfg.Stack.Enabled
+	if profile, ok := cfg.Profiles[activeProfile]; ok && len(profile.Services) > 0 {
+		serviceNames = profile.Services
+	}
+	return applyComposeProfiles(serviceNames, activeProfile)
+}
+
+// reconcileServices returns the services up has to create, recreate or
+// start among serviceNames and, unless options.NoDeps is set, the services
+// they depend on, and reports those left running. The others then start
+// without their dependencies, which are running already. When the
+// containers can't be compared, every service is started as before.
+func reconcileServices(ctx context.Context, client *docker.Client, cfg *ProjectConfig, serviceNames []string, options *types.StartOptions, base *cliTypes.BaseCommand) []string {
+	names := serviceNames
+	if !options.NoDeps {
+		dependencies, err := compose.ServiceDependencies(docker.ComposeFiles()...)
+		if err != nil {
+			base.Logger.Debug("Failed to read service dependencies, starting every service", "error", err)
+			return serviceNames
+		}
+		waves, err := compose.StartupWaves(serviceNames, dependencies, true)
+		if err != nil {
+			return serviceNames
+		}
+		names = slices.Concat(waves...)
+	}
+
+	plan, err := client.Containers().Reconcile(ctx, cfg.Project.Name, options.Profiles, names)
+	if err != nil {
+		base.Logger.Debug("Failed to compare services with their containers, starting every service", "error", err)
+		return serviceNames
+	}
+
+	var start, unchanged []string
+	for _, step := range plan {
+		switch step.Action {
+		case types.ReconcileUnchanged:
+			unchanged = append(unchanged, step.Service)
+			continue
+		case types.ReconcileRecreate:
+			if fields := changedFields(step.Changes); fields != "" {
+				ui.Info("Recreating %s: %s changed", step.Service, fields)
+			} else {
+				ui.Info("Recreating %s: its configuration changed", step.Service)
+			}
+		}
+		start = append(start, step.Service)
+	}
+	if len(unchanged) > 0 {
+		ui.Info("Up to date: %s", strings.Join(unchanged, ", "))
+	}
+	options.NoDeps = true
+	return start
+}
+
+// changedFields joins the fields of changes, without repeats
+func changedFields(changes []types.DriftChange) string {
+	var fields []string
+	for _, change := range changes {
+		if !slices.Contains(fields, change.Field) {
+			fields = append(fields, change.Field)
+		}
+	}
+	return strings.Join(fields, ", ")
+}
+
+// startsService reports whether name is among serviceNames or is a service
+// they depend on in the compose files
+func startsService(serviceNames []string, name string) bool {
+	if slices.Contains(serviceNames, name) {
+		return true
+	}
+	dependencies, err := compose.ServiceDependencies(docker.ComposeFiles()...)
+	if err != nil {
+		return false
+	}
+	waves, err := compose.StartupWaves(serviceNames, dependencies, true)
+	if err != nil {
+		return false
+	}
+	for _, wave := range waves {
+		if slices.Contains(wave, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// applyComposeProfiles honours the profiles of compose services: services
+// assigned to other profiles are left out, and services assigned to the active
+// profile are added. Services named on the command line always start.
+func applyComposeProfiles(serviceNames []string, activeProfile string) ([]string, error) {
+	if !utils.FileExists(constants.DockerComposeFile) {
+		return serviceNames, nil
+	}
+	profiles, err := compose.ServiceProfiles(docker.ComposeFiles()...)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []string
+	for _, serviceName := range serviceNames {
+		if serviceProfiles, ok := profiles[serviceName]; !ok || slices.Contains(serviceProfiles, activeProfile) {
+			result = append(result, serviceName)
+		}
+	}
+	var added []string
+	for serviceName, serviceProfiles := range profiles {
+		if activeProfile != "" && slices.Contains(serviceProfiles, activeProfile) && !slices.Contains(result, serviceName) {
+			added = append(added, serviceName)
+		}
+	}
+	slices.Sort(added)
+	return append(result, added...), nil
+}
+
 // ValidateArgs validates the command arguments
 func (h *UpHandler) ValidateArgs(args []string) error {
 	return nil
@@ -0,0 +1,156 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"path/filepath"
+	"slices"
+
+	handlerUtils "github.com/isaacgarza/dev-stack/internal/pkg/cli/handlers/utils"
+	cliTypes "github.com/isaacgarza/dev-stack/internal/pkg/cli/types"
+	"github.com/isaacgarza/dev-stack/internal/pkg/compose"
+	"github.com/isaacgarza/dev-stack/internal/pkg/constants"
+	"github.com/isaacgarza/dev-stack/internal/pkg/render"
+	"github.com/isaacgarza/dev-stack/internal/pkg/types"
+	"github.com/isaacgarza/dev-stack/internal/pkg/ui"
+	"github.com/isaacgarza/dev-stack/internal/pkg/utils"
+	"github.com/spf13/cobra"
+)
+
+// Host ports of the proxy service, as published by its definition
+const (
+	proxyHTTPPort  = "${PROXY_HTTP_PORT:-80}"
+	proxyHTTPSPort = "${PROXY_HTTPS_PORT:-443}"
+)
+
+// serviceURL is a URL a service of the stack answers on
+type serviceURL struct {
+	Service     string `json:"service"`
+	URL         string `json:"url"`
+	Description string `json:"description"`
+}
+
+// URLsHandler handles the urls command
+type URLsHandler struct{}
+
+// NewURLsHandler creates a new urls handler
+func NewURLsHandler() *URLsHandler {
+	return &URLsHandler{}
+}
+
+// Handle executes the urls command
+func (h *URLsHandler) Handle(ctx context.Context, cmd *cobra.Command, args []string, base *cliTypes.BaseCommand) error {
+	output, err := handlerUtils.GetRenderOptions(cmd)
+	if err != nil {
+		return err
+	}
+
+	configPath := filepath.Join(constants.DevStackDir, constants.ConfigFileName)
+	if !utils.FileExists(configPath) {
+		return errors.New(constants.ErrNotInitialized)
+	}
+	cfg, err := LoadProjectConfig(configPath)
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	for _, serviceName := range args {
+		if !slices.Contains(cfg.Stack.Enabled, serviceName) {
+			return &types.ServiceNotFoundError{Service: serviceName, NotEnabled: true}
+		}
+	}
+	serviceNames := args
+	if len(serviceNames) == 0 {
+		serviceNames = cfg.Stack.Enabled
+	}
+
+	dotEnv, err := loadProjectDotEnv(configPath)
+	if err != nil {
+		return err
+	}
+	urls, err := stackURLs(cfg, serviceNames, utils.EnvLookup(dotEnv))
+	if err != nil {
+		return err
+	}
+	return writeURLs(cmd.OutOrStdout(), output, urls)
+}
+
+// ValidateArgs validates the command arguments
+func (h *URLsHandler) ValidateArgs(args []string) error {
+	return nil
+}
+
+// GetRequiredFlags returns required flags for this command
+func (h *URLsHandler) GetRequiredFlags() []string {
+	return []string{}
+}
+
+// stackURLs returns the URLs of serviceNames: their route through the proxy
+// when the stack enables it, then the web interfaces they publish on the
+// host. Ports are resolved with lookup.
+func stackURLs(cfg *ProjectConfig, serviceNames []string, lookup func(string) (string, bool)) ([]serviceURL, error) {
+	proxied := slices.Contains(cfg.Stack.Enabled, constants.ServiceProxy)
+	scheme, port, defaultPort := "http", utils.ExpandEnv(proxyHTTPPort, lookup), "80"
+	certFile := filepath.Join(constants.DevStackDir, constants.ProxyDir, compose.ProxyCertFileName)
+	if cfg.Proxy.TLS && utils.FileExists(certFile) {
+		scheme, port, defaultPort = "https", utils.ExpandEnv(proxyHTTPSPort, lookup), "443"
+	}
+
+	var urls []serviceURL
+	for _, serviceName := range serviceNames {
+		serviceConfig, err := handlerUtils.NewServiceUtils().LoadServiceConfig(serviceName)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load service %s: %w", serviceName, err)
+		}
+
+		if proxied && serviceConfig.Proxy.Port > 0 {
+			route := scheme + "://" + cfg.Proxy.Host(serviceName)
+			if port != defaultPort {
+				route += ":" + port
+			}
+			urls = append(urls, serviceURL{Service: serviceName, URL: route, Description: "Proxy route"})
+		}
+		for _, web := range serviceConfig.WebInterfaces {
+			urls = append(urls, serviceURL{Service: serviceName, URL: utils.ExpandEnv(web.URL, lookup), Description: web.Name})
+		}
+	}
+	return urls, nil
+}
+
+// urlColumns are the columns of the table of URLs
+var urlColumns = []render.Column[serviceURL]{
+	{Name: "SERVICE", Value: func(u serviceURL) string { return u.Service }},
+	{Name: "URL", Value: func(u serviceURL) string { return u.URL }},
+	{Name: "DESCRIPTION", Value: func(u serviceURL) string { return u.Description }},
+	{Name: "PORT", Wide: true, Value: urlPort},
+}
+
+// writeURLs prints the URLs in the format of output
+func writeURLs(w io.Writer, output render.Options, urls []serviceURL) error {
+	if len(urls) == 0 && !output.Format.Structured() {
+		ui.Info("No service of the stack serves a web interface")
+		return nil
+	}
+	return render.Render(w, render.Table[serviceURL]{Columns: urlColumns, Rows: urls}, output)
+}
+
+// urlPort returns the host port a URL is published on
+func urlPort(u serviceURL) string {
+	parsed, err := url.Parse(u.URL)
+	if err != nil {
+		return ""
+	}
+	if port := parsed.Port(); port != "" {
+		return port
+	}
+	switch parsed.Scheme {
+	case "http":
+		return "80"
+	case "https":
+		return "443"
+	}
+	return ""
+}
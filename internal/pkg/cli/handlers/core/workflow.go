@@ -0,0 +1,193 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"maps"
+	"path/filepath"
+	"slices"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/isaacgarza/dev-stack/internal/core/services"
+	"github.com/isaacgarza/dev-stack/internal/pkg/cli/types"
+	"github.com/isaacgarza/dev-stack/internal/pkg/config"
+	"github.com/isaacgarza/dev-stack/internal/pkg/constants"
+	pkgTypes "github.com/isaacgarza/dev-stack/internal/pkg/types"
+	"github.com/isaacgarza/dev-stack/internal/pkg/utils"
+	"github.com/spf13/cobra"
+)
+
+// workflowParamEnvPrefix prefixes the variables passing the parameters of a
+// workflow to its steps, such as DEV_STACK_PARAM_DATABASE
+const workflowParamEnvPrefix = "DEV_STACK_PARAM_"
+
+// WorkflowHandler handles the workflow command
+type WorkflowHandler struct {
+	manager *services.Manager
+}
+
+// NewWorkflowHandler creates a new workflow handler
+func NewWorkflowHandler(manager *services.Manager) *WorkflowHandler {
+	return &WorkflowHandler{manager: manager}
+}
+
+// Handle executes the workflow command. Without arguments it lists the
+// workflows; otherwise it runs the named one with the parameters given as
+// --name value or --name=value after it.
+func (h *WorkflowHandler) Handle(ctx context.Context, cmd *cobra.Command, args []string, base *types.BaseCommand) error {
+	configPath := filepath.Join(constants.DevStackDir, constants.ConfigFileName)
+	var cfg *ProjectConfig
+	if utils.FileExists(configPath) {
+		var err error
+		if cfg, err = LoadProjectConfig(configPath); err != nil {
+			return fmt.Errorf("failed to load configuration: %w", err)
+		}
+	}
+
+	workflows, err := Workflows(cfg)
+	if err != nil {
+		return err
+	}
+	if len(args) == 0 {
+		return writeWorkflows(cmd.OutOrStdout(), workflows)
+	}
+
+	name := args[0]
+	workflow, ok := workflows[name]
+	if !ok {
+		return fmt.Errorf("unknown workflow %q (available: %s)", name, strings.Join(slices.Sorted(maps.Keys(workflows)), ", "))
+	}
+	values, help, err := parseWorkflowArgs(args[1:])
+	if err != nil {
+		return err
+	}
+	if help {
+		return writeWorkflowHelp(cmd.OutOrStdout(), name, workflow)
+	}
+
+	if err := services.ValidateWorkflow(name, workflow.Steps); err != nil {
+		return fmt.Errorf("invalid workflows configuration: %w", err)
+	}
+	params, err := workflow.ResolveParams(values)
+	if err != nil {
+		return fmt.Errorf("workflow %s: %w", name, err)
+	}
+	steps, err := workflow.RenderSteps(params)
+	if err != nil {
+		return fmt.Errorf("workflow %s: %w", name, err)
+	}
+
+	env := make(map[string]string)
+	if cfg != nil {
+		if env, err = ServiceConnectionEnv(configPath, cfg.Stack.Enabled); err != nil {
+			return err
+		}
+		h.manager.SetProjectName(cfg.Project.Name)
+	}
+	for param, value := range params {
+		env[workflowParamEnvPrefix+strings.ToUpper(param)] = value
+	}
+	return h.manager.RunWorkflow(ctx, name, steps, env)
+}
+
+// ValidateArgs validates the command arguments
+func (h *WorkflowHandler) ValidateArgs(args []string) error {
+	return nil
+}
+
+// GetRequiredFlags returns required flags for this command
+func (h *WorkflowHandler) GetRequiredFlags() []string {
+	return []string{}
+}
+
+// Workflows returns the built-in workflows of commands.yaml together with
+// those of the project, which replace built-in ones of the same name. cfg
+// may be nil outside a project.
+func Workflows(cfg *ProjectConfig) (map[string]pkgTypes.WorkflowConfig, error) {
+	commands, err := config.LoadDefault()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load built-in workflows: %w", err)
+	}
+
+	workflows := make(map[string]pkgTypes.WorkflowConfig, len(commands.Workflows))
+	for name, builtin := range commands.Workflows {
+		workflow := pkgTypes.WorkflowConfig{Description: builtin.Description}
+		for _, step := range builtin.Steps {
+			converted := pkgTypes.HookStep{Name: step.Description, Command: step.Command}
+			if step.Optional {
+				converted.OnFailure = pkgTypes.HookFailureContinue
+			}
+			workflow.Steps = append(workflow.Steps, converted)
+		}
+		workflows[name] = workflow
+	}
+	if cfg != nil {
+		maps.Copy(workflows, cfg.Workflows)
+	}
+	return workflows, nil
+}
+
+// parseWorkflowArgs parses the parameters given to a workflow, reporting
+// whether --help was among them
+func parseWorkflowArgs(args []string) (map[string]string, bool, error) {
+	values := make(map[string]string)
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--help" || arg == "-h" {
+			return nil, true, nil
+		}
+		name, ok := strings.CutPrefix(arg, "--")
+		if !ok || name == "" {
+			return nil, false, fmt.Errorf("unexpected argument %q: workflow parameters are given as --name value", arg)
+		}
+		if key, value, hasValue := strings.Cut(name, "="); hasValue {
+			values[key] = value
+			continue
+		}
+		if i+1 == len(args) || strings.HasPrefix(args[i+1], "--") {
+			return nil, false, fmt.Errorf("parameter --%s needs a value", name)
+		}
+		values[name] = args[i+1]
+		i++
+	}
+	return values, false, nil
+}
+
+// writeWorkflows lists workflows with their descriptions
+func writeWorkflows(out io.Writer, workflows map[string]pkgTypes.WorkflowConfig) error {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	for _, name := range slices.Sorted(maps.Keys(workflows)) {
+		_, _ = fmt.Fprintf(w, "%s\t%s\n", name, workflows[name].Description)
+	}
+	return w.Flush()
+}
+
+// writeWorkflowHelp describes a workflow, its parameters and its steps
+func writeWorkflowHelp(out io.Writer, name string, workflow pkgTypes.WorkflowConfig) error {
+	_, _ = fmt.Fprintf(out, "%s: %s\n", name, workflow.Description)
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	if len(workflow.Params) > 0 {
+		_, _ = fmt.Fprintln(w, "\nParameters:")
+		for _, param := range workflow.ParamNames() {
+			details := workflow.Params[param].Description
+			switch {
+			case workflow.Params[param].Required:
+				details += " (required)"
+			case workflow.Params[param].Default != "":
+				details += fmt.Sprintf(" (default: %s)", workflow.Params[param].Default)
+			}
+			_, _ = fmt.Fprintf(w, "  --%s\t%s\n", param, strings.TrimSpace(details))
+		}
+	}
+	_, _ = fmt.Fprintln(w, "\nSteps:")
+	for i, step := range workflow.Steps {
+		command := step.Run
+		if step.Command != "" {
+			command = "dev-stack " + step.Command
+		}
+		_, _ = fmt.Fprintf(w, "  %d.\t%s\n", i+1, command)
+	}
+	return w.Flush()
+}
@@ -0,0 +1,242 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/isaacgarza/dev-stack/internal/core/docker"
+	cliTypes "github.com/isaacgarza/dev-stack/internal/pkg/cli/types"
+	"github.com/isaacgarza/dev-stack/internal/pkg/compose"
+	pkgConfig "github.com/isaacgarza/dev-stack/internal/pkg/config"
+	"github.com/isaacgarza/dev-stack/internal/pkg/constants"
+	"github.com/isaacgarza/dev-stack/internal/pkg/types"
+	"github.com/isaacgarza/dev-stack/internal/pkg/ui"
+	"github.com/isaacgarza/dev-stack/internal/pkg/utils"
+	"github.com/spf13/cobra"
+)
+
+// workspaceAll selects every project of the workspace
+const workspaceAll = "all"
+
+// loadWorkspace loads the workspace in the working directory and resolves
+// the projects selected with --workspace
+func loadWorkspace(selection string) (*pkgConfig.Workspace, []string, error) {
+	ws, err := pkgConfig.LoadWorkspace(".")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	names := parseServiceList(selection)
+	if selection == workspaceAll {
+		names = ws.ProjectNames()
+	}
+	if len(names) == 0 {
+		return nil, nil, fmt.Errorf("--workspace needs project names or %q", workspaceAll)
+	}
+
+	for _, name := range names {
+		if _, ok := ws.Projects[name]; !ok {
+			return nil, nil, fmt.Errorf("unknown project %s (available: %s)", name, strings.Join(ws.ProjectNames(), ", "))
+		}
+	}
+	return ws, names, nil
+}
+
+// sharedStackConfig is the configuration of the stack running the shared
+// services of a workspace, at the workspace root
+func sharedStackConfig(ws *pkgConfig.Workspace) *ProjectConfig {
+	cfg := &ProjectConfig{}
+	cfg.Project.Name = ws.SharedProjectName()
+	cfg.Project.Environment = "local"
+	cfg.Stack.Enabled = ws.Shared
+	return cfg
+}
+
+// upWorkspace starts the shared services of the workspace, then the
+// selected projects after the projects they depend on. Every project joins
+// the workspace network, where the shared services answer to their service
+// names, and leaves its own copies of the shared services stopped.
+func upWorkspace(ctx context.Context, cmd *cobra.Command, selection string, timeout time.Duration, base *cliTypes.BaseCommand) error {
+	ws, names, err := loadWorkspace(selection)
+	if err != nil {
+		return err
+	}
+	order, err := ws.Resolve(names)
+	if err != nil {
+		return err
+	}
+	for _, name := range order {
+		if !utils.FileExists(filepath.Join(ws.ProjectDir(name), constants.DevStackDir, constants.ConfigFileName)) {
+			return fmt.Errorf("project %s is not initialized; run '%s' in %s", name, constants.CmdInit, ws.ProjectDir(name))
+		}
+	}
+
+	logger := base.Logger.(loggerAdapter)
+	dockerClient, err := docker.Shared(logger.SlogLogger())
+	if err != nil {
+		return fmt.Errorf("failed to create Docker client: %w", err)
+	}
+	defer func() {
+		if err := dockerClient.Close(); err != nil {
+			base.Logger.Error("Failed to close Docker client", "error", err)
+		}
+	}()
+
+	if len(ws.Shared) > 0 {
+		if utils.FileExists(filepath.Join(constants.DevStackDir, constants.ConfigFileName)) {
+			return fmt.Errorf("the workspace root holds the shared services and cannot be a %s project itself", constants.AppName)
+		}
+		ui.Info("Starting shared services: %s", strings.Join(ws.Shared, ", "))
+		shared := sharedStackConfig(ws)
+		if err := RegenerateCompose(ctx, shared); err != nil {
+			return fmt.Errorf("failed to generate the shared stack: %w", err)
+		}
+		if err := dockerClient.Containers().Start(ctx, shared.Project.Name, ws.Shared, types.StartOptions{Detach: true}); err != nil {
+			return fmt.Errorf("failed to start shared services: %w", err)
+		}
+		if err := waitForServices(ctx, dockerClient, shared, ws.Shared, timeout); err != nil {
+			return err
+		}
+	}
+	if err := dockerClient.Networks().Ensure(ctx, ws.Network()); err != nil {
+		return err
+	}
+
+	for _, name := range order {
+		ui.Header("Starting %s", name)
+		if err := startWorkspaceProject(ctx, cmd, ws, name); err != nil {
+			return err
+		}
+	}
+
+	ui.Success("Started workspace %s: %s", ws.Name, strings.Join(order, ", "))
+	return nil
+}
+
+// startWorkspaceProject joins a project to the workspace network and runs
+// its `up`, leaving out the services the workspace shares
+func startWorkspaceProject(ctx context.Context, cmd *cobra.Command, ws *pkgConfig.Workspace, name string) error {
+	dir := ws.ProjectDir(name)
+	cfg, err := LoadProjectConfig(filepath.Join(dir, constants.DevStackDir, constants.ConfigFileName))
+	if err != nil {
+		return fmt.Errorf("failed to load configuration of %s: %w", name, err)
+	}
+
+	var serviceNames []string
+	for _, serviceName := range cfg.Stack.Enabled {
+		if !slices.Contains(ws.Shared, serviceName) {
+			serviceNames = append(serviceNames, serviceName)
+		}
+	}
+	if len(serviceNames) == 0 {
+		ui.Muted("%s only uses shared services", name)
+		return nil
+	}
+
+	var composeFiles []string
+	for _, file := range []string{constants.DockerComposeFile, constants.DockerComposeOverrideFile} {
+		if path := filepath.Join(dir, file); utils.FileExists(path) {
+			composeFiles = append(composeFiles, path)
+		}
+	}
+	if len(composeFiles) == 0 {
+		return fmt.Errorf("project %s has no compose file; run '%s' in %s", name, constants.CmdRef(constants.CmdNameGenerateCompose), dir)
+	}
+	override, err := compose.WorkspaceOverride(ws.Network(), composeFiles...)
+	if err != nil {
+		return fmt.Errorf("failed to render the workspace file of %s: %w", name, err)
+	}
+	overridePath := filepath.Join(dir, compose.WorkspaceFile())
+	if err := os.MkdirAll(filepath.Dir(overridePath), 0755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", filepath.Dir(overridePath), err)
+	}
+	if err := os.WriteFile(overridePath, override, 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", overridePath, err)
+	}
+
+	// The shared services are not started, so dependencies on them are
+	// left to the shared stack
+	args := []string{constants.CmdNameUp, "--no-deps"}
+	for _, flag := range []string{"build", "force-recreate"} {
+		if value, _ := cmd.Flags().GetBool(flag); value {
+			args = append(args, "--"+flag)
+		}
+	}
+	return runInProject(ctx, dir, append(args, serviceNames...)...)
+}
+
+// downWorkspace stops the selected projects, dependents first, and the
+// shared services once the whole workspace is down
+func downWorkspace(ctx context.Context, cmd *cobra.Command, selection string, base *cliTypes.BaseCommand) error {
+	ws, names, err := loadWorkspace(selection)
+	if err != nil {
+		return err
+	}
+	order, err := ws.Resolve(ws.ProjectNames())
+	if err != nil {
+		return err
+	}
+	slices.Reverse(order)
+
+	var stopped []string
+	for _, name := range order {
+		if !slices.Contains(names, name) {
+			continue
+		}
+		dir := ws.ProjectDir(name)
+		if !utils.FileExists(filepath.Join(dir, constants.DevStackDir, constants.ConfigFileName)) {
+			continue
+		}
+		ui.Header("Stopping %s", name)
+		if err := runInProject(ctx, dir, constants.CmdNameDown); err != nil {
+			return err
+		}
+		stopped = append(stopped, name)
+	}
+
+	if len(names) == len(ws.Projects) && len(ws.Shared) > 0 && utils.FileExists(constants.DockerComposeFile) {
+		ui.Info("Stopping shared services: %s", strings.Join(ws.Shared, ", "))
+		logger := base.Logger.(loggerAdapter)
+		dockerClient, err := docker.Shared(logger.SlogLogger())
+		if err != nil {
+			return fmt.Errorf("failed to create Docker client: %w", err)
+		}
+		defer func() {
+			if err := dockerClient.Close(); err != nil {
+				base.Logger.Error("Failed to close Docker client", "error", err)
+			}
+		}()
+		timeout, _ := cmd.Flags().GetInt("stop-timeout")
+		if err := dockerClient.Containers().Stop(ctx, ws.SharedProjectName(), nil, types.StopOptions{Timeout: timeout, Remove: true}); err != nil {
+			return fmt.Errorf("failed to stop shared services: %w", err)
+		}
+	}
+
+	ui.Success("Stopped workspace %s: %s", ws.Name, strings.Join(stopped, ", "))
+	return nil
+}
+
+// runInProject runs dev-stack in a project directory with the terminal
+// attached
+func runInProject(ctx context.Context, dir string, args ...string) error {
+	executable, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("failed to locate dev-stack executable: %w", err)
+	}
+
+	cmd := exec.CommandContext(ctx, executable, args...)
+	cmd.Dir = dir
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("%s %s failed in %s: %w", constants.AppName, args[0], dir, err)
+	}
+	return nil
+}
@@ -0,0 +1,177 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/isaacgarza/dev-stack/internal/core/backup"
+	"github.com/isaacgarza/dev-stack/internal/core/services"
+	"github.com/isaacgarza/dev-stack/internal/pkg/cli/handlers/core"
+	"github.com/isaacgarza/dev-stack/internal/pkg/cli/types"
+	"github.com/isaacgarza/dev-stack/internal/pkg/constants"
+	pkgServices "github.com/isaacgarza/dev-stack/internal/pkg/services"
+	pkgTypes "github.com/isaacgarza/dev-stack/internal/pkg/types"
+	"github.com/isaacgarza/dev-stack/internal/pkg/ui"
+	"github.com/isaacgarza/dev-stack/internal/pkg/utils"
+	"github.com/spf13/cobra"
+)
+
+// BackupHandler handles the backup command
+type BackupHandler struct {
+	manager *services.Manager
+}
+
+// NewBackupHandler creates a new backup handler
+func NewBackupHandler(manager *services.Manager) *BackupHandler {
+	return &BackupHandler{manager: manager}
+}
+
+// Handle executes the backup command
+func (h *BackupHandler) Handle(ctx context.Context, cmd *cobra.Command, args []string, base *types.BaseCommand) error {
+	configPath := filepath.Join(constants.DevStackDir, constants.ConfigFileName)
+	if !utils.FileExists(configPath) {
+		return errors.New(constants.ErrNotInitialized)
+	}
+
+	cfg, err := core.LoadProjectConfig(configPath)
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	options, err := backupOptions(cmd, cfg.Backup)
+	if err != nil {
+		return err
+	}
+
+	target, remoteName, err := backupTarget(cmd, cfg.Backup)
+	if err != nil {
+		return err
+	}
+
+	serviceNames := args
+	if len(serviceNames) == 0 {
+		serviceNames = backupCapableServices(cfg.Stack.Enabled)
+		if len(serviceNames) == 0 {
+			ui.Warning("No enabled service supports backups")
+			return nil
+		}
+	}
+
+	if err := core.ConfigureHooks(h.manager, cfg, configPath); err != nil {
+		return err
+	}
+
+	ui.Header("Backing up services")
+
+	h.manager.SetProjectName(cfg.Project.Name)
+	if err := h.manager.RunHooks(ctx, pkgTypes.HookPreBackup, serviceNames, nil); err != nil {
+		return err
+	}
+
+	timestamp := time.Now().Format("20060102-150405")
+	var backupPaths []string
+	for _, serviceName := range serviceNames {
+		backupName := fmt.Sprintf("%s-%s-%s", cfg.Project.Name, serviceName, timestamp)
+		backupPath, err := h.manager.BackupService(ctx, serviceName, backupName, options)
+		if err != nil {
+			return fmt.Errorf("failed to back up %s: %w", serviceName, err)
+		}
+		ui.Success("Backed up %s to %s", serviceName, backupPath)
+		backupPaths = append(backupPaths, backupPath)
+
+		if target == nil {
+			continue
+		}
+		location, err := target.Upload(ctx, cfg.Project.Name+"/"+filepath.Base(backupPath), backupPath)
+		if err != nil {
+			return fmt.Errorf("failed to upload %s backup to %s: %w", serviceName, remoteName, err)
+		}
+		ui.Success("Uploaded %s backup to %s", serviceName, location)
+	}
+
+	extra := map[string]string{"DEV_STACK_BACKUP_FILES": strings.Join(backupPaths, ",")}
+	return h.manager.RunHooks(ctx, pkgTypes.HookPostBackup, serviceNames, extra)
+}
+
+// backupOptions reads the backup flags, falling back to the project's
+// backup settings for anything not given on the command line
+func backupOptions(cmd *cobra.Command, settings pkgTypes.BackupConfig) (pkgTypes.BackupOptions, error) {
+	outputDir, _ := cmd.Flags().GetString("output")
+	if !cmd.Flags().Changed("output") && settings.Directory != "" {
+		outputDir = settings.Directory
+	}
+
+	compress, _ := cmd.Flags().GetString("compress")
+	if !cmd.Flags().Changed("compress") && settings.Compress != "" {
+		compress = settings.Compress
+	}
+	if compress == "none" {
+		compress = pkgTypes.CompressionNone
+	}
+	if err := backup.ValidateCompression(compress); err != nil {
+		return pkgTypes.BackupOptions{}, err
+	}
+
+	database, _ := cmd.Flags().GetString("database")
+	user, _ := cmd.Flags().GetString("user")
+
+	return pkgTypes.BackupOptions{
+		OutputDir: outputDir,
+		Compress:  compress,
+		Database:  database,
+		User:      user,
+	}, nil
+}
+
+// backupTarget resolves the remote selected with --remote, or the project's
+// default remote. A nil target means backups stay local.
+func backupTarget(cmd *cobra.Command, settings pkgTypes.BackupConfig) (backup.Target, string, error) {
+	if localOnly, _ := cmd.Flags().GetBool("local-only"); localOnly {
+		return nil, "", nil
+	}
+
+	name, _ := cmd.Flags().GetString("remote")
+	if name == "" {
+		name = settings.DefaultRemote
+	}
+	if name == "" {
+		return nil, "", nil
+	}
+
+	remote, ok := settings.Remotes[name]
+	if !ok {
+		return nil, "", fmt.Errorf("backup remote %q is not defined in the backup.remotes configuration", name)
+	}
+
+	target, err := backup.NewTarget(remote)
+	if err != nil {
+		return nil, "", fmt.Errorf("invalid backup remote %q: %w", name, err)
+	}
+	return target, name, nil
+}
+
+// backupCapableServices filters services down to those with a backup operation
+func backupCapableServices(serviceNames []string) []string {
+	var result []string
+	for _, name := range serviceNames {
+		ops, err := pkgServices.LoadServiceOperations(name)
+		if err == nil && ops != nil && ops.Backup != nil {
+			result = append(result, name)
+		}
+	}
+	return result
+}
+
+// ValidateArgs validates the command arguments
+func (h *BackupHandler) ValidateArgs(args []string) error {
+	return nil
+}
+
+// GetRequiredFlags returns required flags for this command
+func (h *BackupHandler) GetRequiredFlags() []string {
+	return []string{}
+}
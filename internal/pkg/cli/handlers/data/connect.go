@@ -0,0 +1,158 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/isaacgarza/dev-stack/internal/core/services"
+	"github.com/isaacgarza/dev-stack/internal/pkg/cli/handlers/core"
+	"github.com/isaacgarza/dev-stack/internal/pkg/cli/types"
+	"github.com/isaacgarza/dev-stack/internal/pkg/constants"
+	pkgTypes "github.com/isaacgarza/dev-stack/internal/pkg/types"
+	"github.com/isaacgarza/dev-stack/internal/pkg/ui"
+	"github.com/isaacgarza/dev-stack/internal/pkg/utils"
+	"github.com/spf13/cobra"
+)
+
+// ConnectHandler handles the connect command
+type ConnectHandler struct {
+	manager *services.Manager
+}
+
+// NewConnectHandler creates a new connect handler
+func NewConnectHandler(manager *services.Manager) *ConnectHandler {
+	return &ConnectHandler{manager: manager}
+}
+
+// Handle executes the connect command
+func (h *ConnectHandler) Handle(ctx context.Context, cmd *cobra.Command, args []string, base *types.BaseCommand) error {
+	if err := h.ValidateArgs(args); err != nil {
+		return err
+	}
+	serviceName := args[0]
+
+	configPath := filepath.Join(constants.DevStackDir, constants.ConfigFileName)
+	if !utils.FileExists(configPath) {
+		return errors.New(constants.ErrNotInitialized)
+	}
+
+	cfg, err := core.LoadProjectConfig(configPath)
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+	if !slices.Contains(cfg.Stack.Enabled, serviceName) {
+		return &pkgTypes.ServiceNotFoundError{Service: serviceName, NotEnabled: true}
+	}
+
+	options, err := connectOptions(cmd)
+	if err != nil {
+		return err
+	}
+	options.Env, err = core.ServiceConnectionEnv(configPath, []string{serviceName})
+	if err != nil {
+		return err
+	}
+
+	h.manager.SetProjectName(cfg.Project.Name)
+	if _, err := core.WakeIdle(ctx, h.manager, []string{serviceName}); err != nil {
+		return err
+	}
+	if err := core.RequireRunning(ctx, h.manager, serviceName); err != nil {
+		return err
+	}
+
+	if printURL, _ := cmd.Flags().GetBool("url"); printURL {
+		info, err := h.manager.ConnectionInfo(ctx, serviceName, options)
+		if err != nil {
+			return err
+		}
+		if info.URL == "" {
+			return fmt.Errorf("service %s has no connection string", serviceName)
+		}
+		fmt.Println(info.URL)
+		return nil
+	}
+
+	if openUI, _ := cmd.Flags().GetBool("ui"); openUI {
+		info, err := h.manager.ConnectionInfo(ctx, serviceName, options)
+		if err != nil {
+			return err
+		}
+		if info.UIURL == "" {
+			return fmt.Errorf("service %s has no published web interface", serviceName)
+		}
+		fmt.Println(info.UIURL)
+		if err := utils.OpenURL(info.UIURL); err != nil {
+			ui.Muted("Could not open a browser: %v", err)
+		}
+		return nil
+	}
+
+	ui.Muted("Connecting to %s", serviceName)
+	err = h.manager.ConnectToService(ctx, serviceName, options)
+
+	// The client has already reported its failure on its own output
+	var exitErr *pkgTypes.ExitError
+	if errors.As(err, &exitErr) {
+		cmd.SilenceErrors = true
+		cmd.SilenceUsage = true
+		return exitErr
+	}
+	return err
+}
+
+// connectOptions reads the connect flags. Flags left unset are resolved
+// from the container and the service defaults.
+func connectOptions(cmd *cobra.Command) (pkgTypes.ConnectOptions, error) {
+	user, _ := cmd.Flags().GetString("user")
+	database, _ := cmd.Flags().GetString("database")
+	readOnly, _ := cmd.Flags().GetBool("read-only")
+	topic, _ := cmd.Flags().GetString("topic")
+	path, _ := cmd.Flags().GetString("path")
+	client, _ := cmd.Flags().GetString("client")
+	if path != "" && !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+
+	var host string
+	if cmd.Flags().Changed("host") {
+		host, _ = cmd.Flags().GetString("host")
+	}
+
+	var port string
+	if value, _ := cmd.Flags().GetInt("port"); value != 0 {
+		if value < 0 || value > 65535 {
+			return pkgTypes.ConnectOptions{}, fmt.Errorf("invalid port %d", value)
+		}
+		port = strconv.Itoa(value)
+	}
+
+	return pkgTypes.ConnectOptions{
+		User:     user,
+		Database: database,
+		Host:     host,
+		Port:     port,
+		ReadOnly: readOnly,
+		Topic:    topic,
+		Path:     path,
+		Client:   client,
+	}, nil
+}
+
+// ValidateArgs validates the command arguments
+func (h *ConnectHandler) ValidateArgs(args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("connect requires exactly one service name")
+	}
+	return nil
+}
+
+// GetRequiredFlags returns required flags for this command
+func (h *ConnectHandler) GetRequiredFlags() []string {
+	return []string{}
+}
@@ -0,0 +1,192 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/isaacgarza/dev-stack/internal/core/backup"
+	"github.com/isaacgarza/dev-stack/internal/core/services"
+	"github.com/isaacgarza/dev-stack/internal/pkg/cli/handlers/core"
+	"github.com/isaacgarza/dev-stack/internal/pkg/cli/types"
+	"github.com/isaacgarza/dev-stack/internal/pkg/constants"
+	pkgTypes "github.com/isaacgarza/dev-stack/internal/pkg/types"
+	"github.com/isaacgarza/dev-stack/internal/pkg/ui"
+	"github.com/isaacgarza/dev-stack/internal/pkg/utils"
+	"github.com/spf13/cobra"
+)
+
+// RestoreHandler handles the restore command
+type RestoreHandler struct {
+	manager *services.Manager
+}
+
+// NewRestoreHandler creates a new restore handler
+func NewRestoreHandler(manager *services.Manager) *RestoreHandler {
+	return &RestoreHandler{manager: manager}
+}
+
+// Handle executes the restore command
+func (h *RestoreHandler) Handle(ctx context.Context, cmd *cobra.Command, args []string, base *types.BaseCommand) error {
+	if err := h.ValidateArgs(args); err != nil {
+		return err
+	}
+	serviceName, backupFile := args[0], args[1]
+
+	configPath := filepath.Join(constants.DevStackDir, constants.ConfigFileName)
+	if !utils.FileExists(configPath) {
+		return errors.New(constants.ErrNotInitialized)
+	}
+
+	cfg, err := core.LoadProjectConfig(configPath)
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	clean, _ := cmd.Flags().GetBool("clean")
+	createDB, _ := cmd.Flags().GetBool("create-db")
+	singleTransaction, _ := cmd.Flags().GetBool("single-transaction")
+	validate, _ := cmd.Flags().GetBool("validate")
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+	testRestore, _ := cmd.Flags().GetBool("test")
+	database, _ := cmd.Flags().GetString("database")
+	user, _ := cmd.Flags().GetString("user")
+
+	options := pkgTypes.RestoreOptions{
+		Database:          database,
+		User:              user,
+		Clean:             clean,
+		CreateDB:          createDB,
+		SingleTransaction: singleTransaction,
+	}
+
+	if dryRun || testRestore {
+		return h.preflight(ctx, cfg, serviceName, backupFile, options, testRestore)
+	}
+
+	if validate {
+		inspection, err := backup.Inspect(backupFile)
+		if err != nil {
+			return fmt.Errorf("failed to inspect backup: %w", err)
+		}
+		if errs, _ := inspection.Check(serviceName, serviceVersion(cfg, serviceName)); len(errs) > 0 {
+			printIssues(nil, errs)
+			return fmt.Errorf("backup %s failed validation; use --validate=false to restore anyway", backupFile)
+		}
+		ui.Muted("Backup looks like %s; estimated restore time %s", describeBackup(inspection), inspection.Estimate)
+	}
+
+	action := fmt.Sprintf("overwrite %s data from %s", serviceName, backupFile)
+	if clean {
+		action = fmt.Sprintf("drop and restore %s data from %s", serviceName, backupFile)
+	}
+	if err := core.ConfirmProtected(cmd, cfg, action); err != nil {
+		return err
+	}
+
+	if err := core.ConfigureHooks(h.manager, cfg, configPath); err != nil {
+		return err
+	}
+	if err := core.ConfigureShutdown(h.manager, cfg); err != nil {
+		return err
+	}
+
+	ui.Header("Restoring %s", serviceName)
+
+	h.manager.SetProjectName(cfg.Project.Name)
+	hookServices := []string{serviceName}
+	hookEnv := map[string]string{"DEV_STACK_BACKUP_FILE": backupFile}
+	if err := h.manager.RunHooks(ctx, pkgTypes.HookPreRestore, hookServices, hookEnv); err != nil {
+		return err
+	}
+
+	if err := h.manager.RestoreService(ctx, serviceName, backupFile, options); err != nil {
+		return fmt.Errorf("failed to restore %s: %w", serviceName, err)
+	}
+
+	ui.Success("Restored %s from %s", serviceName, backupFile)
+	return h.manager.RunHooks(ctx, pkgTypes.HookPostRestore, hookServices, hookEnv)
+}
+
+// preflight inspects the backup and reports whether it can be restored into
+// the service. With test set the backup is also restored into a throwaway
+// container; the live service's data is never touched.
+func (h *RestoreHandler) preflight(ctx context.Context, cfg *core.ProjectConfig, serviceName, backupFile string, options pkgTypes.RestoreOptions, test bool) error {
+	ui.Header("Restore pre-flight: %s", serviceName)
+
+	inspection, err := backup.Inspect(backupFile)
+	if err != nil {
+		return fmt.Errorf("failed to inspect backup: %w", err)
+	}
+
+	ui.Info("Backup:      %s", inspection.Path)
+	ui.Info("Format:      %s", describeBackup(inspection))
+	ui.Info("Size:        %s (%s uncompressed)", utils.FormatBytes(uint64(inspection.Size)), utils.FormatBytes(uint64(inspection.RawSize)))
+	ui.Info("Estimate:    %s", inspection.Estimate)
+
+	errs, warnings := inspection.Check(serviceName, serviceVersion(cfg, serviceName))
+	printIssues(warnings, errs)
+	if len(errs) > 0 {
+		return fmt.Errorf("backup %s cannot be restored into %s", backupFile, serviceName)
+	}
+
+	if !test {
+		ui.Success("Backup can be restored into %s", serviceName)
+		return nil
+	}
+
+	ui.Info("Restoring into a throwaway %s container...", serviceName)
+	h.manager.SetProjectName(cfg.Project.Name)
+	elapsed, err := h.manager.TestRestore(ctx, serviceName, backupFile, options)
+	if err != nil {
+		return err
+	}
+
+	ui.Success("Test restore succeeded in %s", elapsed.Round(time.Millisecond))
+	return nil
+}
+
+// serviceVersion returns the version of the image the project runs the
+// service with, including a version it pins, or an empty string when it
+// cannot be determined
+func serviceVersion(cfg *core.ProjectConfig, serviceName string) string {
+	return backup.ImageVersion(core.ServiceImage(cfg, serviceName))
+}
+
+func describeBackup(inspection *backup.Inspection) string {
+	description := inspection.Format
+	if inspection.Engine != "" {
+		description = fmt.Sprintf("%s %s", inspection.Engine, description)
+		if inspection.Version != "" {
+			description = fmt.Sprintf("%s (version %s)", description, inspection.Version)
+		}
+	}
+	if inspection.Compression != "" {
+		description += ", " + inspection.Compression + " compressed"
+	}
+	return description
+}
+
+func printIssues(warnings, errs []string) {
+	for _, warning := range warnings {
+		ui.Warning("%s", warning)
+	}
+	for _, e := range errs {
+		ui.Error("%s", e)
+	}
+}
+
+// ValidateArgs validates the command arguments
+func (h *RestoreHandler) ValidateArgs(args []string) error {
+	if len(args) != 2 {
+		return fmt.Errorf("restore requires a service name and a backup file")
+	}
+	return nil
+}
+
+// GetRequiredFlags returns required flags for this command
+func (h *RestoreHandler) GetRequiredFlags() []string {
+	return []string{}
+}
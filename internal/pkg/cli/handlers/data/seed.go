@@ -0,0 +1,164 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+
+	"github.com/isaacgarza/dev-stack/internal/core/seed"
+	"github.com/isaacgarza/dev-stack/internal/core/services"
+	"github.com/isaacgarza/dev-stack/internal/pkg/cli/handlers/core"
+	handlerUtils "github.com/isaacgarza/dev-stack/internal/pkg/cli/handlers/utils"
+	"github.com/isaacgarza/dev-stack/internal/pkg/cli/types"
+	"github.com/isaacgarza/dev-stack/internal/pkg/constants"
+	pkgTypes "github.com/isaacgarza/dev-stack/internal/pkg/types"
+	"github.com/isaacgarza/dev-stack/internal/pkg/ui"
+	"github.com/isaacgarza/dev-stack/internal/pkg/utils"
+	"github.com/spf13/cobra"
+)
+
+// SeedHandler handles the seed command
+type SeedHandler struct {
+	manager *services.Manager
+}
+
+// NewSeedHandler creates a new seed handler
+func NewSeedHandler(manager *services.Manager) *SeedHandler {
+	return &SeedHandler{manager: manager}
+}
+
+// Handle executes the seed command
+func (h *SeedHandler) Handle(ctx context.Context, cmd *cobra.Command, args []string, base *types.BaseCommand) error {
+	configPath := filepath.Join(constants.DevStackDir, constants.ConfigFileName)
+	if !utils.FileExists(configPath) {
+		return errors.New(constants.ErrNotInitialized)
+	}
+
+	cfg, err := core.LoadProjectConfig(configPath)
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	seedsDir := filepath.Join(constants.DevStackDir, constants.SeedsDir)
+	fixtures, err := seed.Discover(seedsDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Errorf("no seeds found: create %s/<service>/ with fixture files", seedsDir)
+		}
+		return fmt.Errorf("failed to read seeds: %w", err)
+	}
+
+	serviceNames, err := seedTargets(args, fixtures, cfg.Stack.Enabled)
+	if err != nil {
+		return err
+	}
+	if len(serviceNames) == 0 {
+		ui.Warning("No seeds for enabled services in %s", seedsDir)
+		return nil
+	}
+
+	force, _ := cmd.Flags().GetBool("force")
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+	options := pkgTypes.SeedOptions{Force: force, DryRun: dryRun}
+
+	ui.Header("Seeding services")
+
+	h.manager.SetProjectName(cfg.Project.Name)
+	for _, serviceName := range seedOrder(serviceNames) {
+		entries, err := h.manager.SeedService(ctx, serviceName, fixtures[serviceName], options)
+		printSeedEntries(serviceName, entries, options)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// seedTargets returns the services to seed. Services named on the command
+// line must be enabled and have seeds; without names every enabled service
+// with a seeds directory is seeded.
+func seedTargets(args []string, fixtures map[string][]seed.Fixture, enabled []string) ([]string, error) {
+	if len(args) > 0 {
+		for _, serviceName := range args {
+			if !slices.Contains(enabled, serviceName) {
+				return nil, &pkgTypes.ServiceNotFoundError{Service: serviceName, NotEnabled: true}
+			}
+			if len(fixtures[serviceName]) == 0 {
+				return nil, fmt.Errorf("no seeds for %s in %s", serviceName, filepath.Join(constants.DevStackDir, constants.SeedsDir, serviceName))
+			}
+		}
+		return args, nil
+	}
+
+	var serviceNames []string
+	for serviceName := range fixtures {
+		if !slices.Contains(enabled, serviceName) {
+			ui.Warning("Skipping seeds for %s: service is not enabled", serviceName)
+			continue
+		}
+		serviceNames = append(serviceNames, serviceName)
+	}
+	sort.Strings(serviceNames)
+	return serviceNames, nil
+}
+
+// seedOrder orders services so dependencies are seeded before the services
+// that depend on them
+func seedOrder(serviceNames []string) []string {
+	resolved, err := handlerUtils.NewServiceUtils().ResolveDependencies(serviceNames)
+	if err != nil {
+		return serviceNames
+	}
+
+	var ordered []string
+	for _, serviceName := range resolved {
+		if slices.Contains(serviceNames, serviceName) {
+			ordered = append(ordered, serviceName)
+		}
+	}
+	return ordered
+}
+
+func printSeedEntries(serviceName string, entries []seed.Entry, options pkgTypes.SeedOptions) {
+	if len(entries) == 0 {
+		ui.Muted("%s: no matching seed files", serviceName)
+		return
+	}
+
+	for _, entry := range entries {
+		if options.DryRun && options.Force && entry.Status != seed.StatusPending {
+			ui.Info("%s: would re-apply %s", serviceName, entry.Name)
+			continue
+		}
+
+		switch entry.Status {
+		case seed.StatusSeeded:
+			ui.Success("%s: applied %s", serviceName, entry.Name)
+		case seed.StatusApplied:
+			ui.Muted("%s: %s already applied", serviceName, entry.Name)
+		case seed.StatusChanged:
+			ui.Warning("%s: %s changed since it was applied; use --force to re-apply", serviceName, entry.Name)
+		case seed.StatusPending:
+			if options.DryRun {
+				ui.Info("%s: would apply %s", serviceName, entry.Name)
+			} else {
+				ui.Muted("%s: %s not applied", serviceName, entry.Name)
+			}
+		}
+	}
+}
+
+// ValidateArgs validates the command arguments
+func (h *SeedHandler) ValidateArgs(args []string) error {
+	return nil
+}
+
+// GetRequiredFlags returns required flags for this command
+func (h *SeedHandler) GetRequiredFlags() []string {
+	return []string{}
+}
@@ -0,0 +1,183 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/isaacgarza/dev-stack/internal/core/docker"
+	"github.com/isaacgarza/dev-stack/internal/core/services"
+	"github.com/isaacgarza/dev-stack/internal/pkg/cli/handlers/core"
+	"github.com/isaacgarza/dev-stack/internal/pkg/cli/types"
+	"github.com/isaacgarza/dev-stack/internal/pkg/constants"
+	pkgTypes "github.com/isaacgarza/dev-stack/internal/pkg/types"
+	"github.com/isaacgarza/dev-stack/internal/pkg/ui"
+	"github.com/isaacgarza/dev-stack/internal/pkg/utils"
+	"github.com/spf13/cobra"
+)
+
+// VolumeBackupHandler handles the volume backup command, archiving the
+// files of named volumes whatever the service using them
+type VolumeBackupHandler struct {
+	manager *services.Manager
+}
+
+// NewVolumeBackupHandler creates a new volume backup handler
+func NewVolumeBackupHandler(manager *services.Manager) *VolumeBackupHandler {
+	return &VolumeBackupHandler{manager: manager}
+}
+
+// Handle executes the volume backup command
+func (h *VolumeBackupHandler) Handle(ctx context.Context, cmd *cobra.Command, args []string, base *types.BaseCommand) error {
+	if err := h.ValidateArgs(args); err != nil {
+		return err
+	}
+	cfg, err := loadVolumeProject()
+	if err != nil {
+		return err
+	}
+
+	backupSettings, err := backupOptions(cmd, cfg.Backup)
+	if err != nil {
+		return err
+	}
+	target, remoteName, err := backupTarget(cmd, cfg.Backup)
+	if err != nil {
+		return err
+	}
+	stop, _ := cmd.Flags().GetBool("stop")
+	timeout, _ := cmd.Flags().GetInt("stop-timeout")
+	options := pkgTypes.VolumeBackupOptions{
+		OutputDir:    backupSettings.OutputDir,
+		Compress:     backupSettings.Compress,
+		StopServices: stop,
+		Timeout:      timeout,
+	}
+
+	ui.Header("Backing up volumes")
+
+	h.manager.SetProjectName(cfg.Project.Name)
+	timestamp := time.Now().Format("20060102-150405")
+	for _, arg := range args {
+		volumeNames, err := h.manager.ResolveVolumes(ctx, arg)
+		if err != nil {
+			return err
+		}
+		for _, volumeName := range volumeNames {
+			backupName := fmt.Sprintf("%s-%s-%s", cfg.Project.Name, volumeBackupLabel(cfg.Project.Name, volumeName), timestamp)
+			backupPath, err := h.manager.BackupVolume(ctx, volumeName, backupName, options)
+			if err != nil {
+				return fmt.Errorf("failed to back up volume %s: %w", volumeName, err)
+			}
+			ui.Success("Backed up volume %s to %s", volumeName, backupPath)
+
+			if target == nil {
+				continue
+			}
+			location, err := target.Upload(ctx, cfg.Project.Name+"/"+filepath.Base(backupPath), backupPath)
+			if err != nil {
+				return fmt.Errorf("failed to upload backup of volume %s to %s: %w", volumeName, remoteName, err)
+			}
+			ui.Success("Uploaded backup of volume %s to %s", volumeName, location)
+		}
+	}
+	return nil
+}
+
+// ValidateArgs checks that at least one service or volume is named
+func (h *VolumeBackupHandler) ValidateArgs(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("volume backup requires a service or volume name")
+	}
+	return nil
+}
+
+// GetRequiredFlags returns required flags for this command
+func (h *VolumeBackupHandler) GetRequiredFlags() []string {
+	return []string{}
+}
+
+// VolumeRestoreHandler handles the volume restore command
+type VolumeRestoreHandler struct {
+	manager *services.Manager
+}
+
+// NewVolumeRestoreHandler creates a new volume restore handler
+func NewVolumeRestoreHandler(manager *services.Manager) *VolumeRestoreHandler {
+	return &VolumeRestoreHandler{manager: manager}
+}
+
+// Handle executes the volume restore command
+func (h *VolumeRestoreHandler) Handle(ctx context.Context, cmd *cobra.Command, args []string, base *types.BaseCommand) error {
+	if err := h.ValidateArgs(args); err != nil {
+		return err
+	}
+	targetName, backupFile := args[0], args[1]
+	if !utils.FileExists(backupFile) {
+		return fmt.Errorf("backup file %s does not exist", backupFile)
+	}
+
+	cfg, err := loadVolumeProject()
+	if err != nil {
+		return err
+	}
+	timeout, _ := cmd.Flags().GetInt("stop-timeout")
+
+	h.manager.SetProjectName(cfg.Project.Name)
+	volumeNames, err := h.manager.ResolveVolumes(ctx, targetName)
+	if err != nil {
+		return err
+	}
+	if len(volumeNames) > 1 {
+		return fmt.Errorf("%s mounts %d volumes (%s): name the volume to restore", targetName, len(volumeNames), strings.Join(volumeNames, ", "))
+	}
+	volumeName := volumeNames[0]
+
+	if err := core.ConfirmProtected(cmd, cfg, fmt.Sprintf("replace the contents of volume %s with %s", volumeName, backupFile)); err != nil {
+		return err
+	}
+
+	ui.Header("Restoring volume %s", volumeName)
+	if err := h.manager.RestoreVolume(ctx, volumeName, backupFile, pkgTypes.VolumeBackupOptions{Timeout: timeout}); err != nil {
+		return fmt.Errorf("failed to restore volume %s: %w", volumeName, err)
+	}
+
+	ui.Success("Restored volume %s from %s", volumeName, backupFile)
+	return nil
+}
+
+// ValidateArgs validates the command arguments
+func (h *VolumeRestoreHandler) ValidateArgs(args []string) error {
+	if len(args) != 2 {
+		return fmt.Errorf("volume restore requires a service or volume name and a backup file")
+	}
+	return nil
+}
+
+// GetRequiredFlags returns required flags for this command
+func (h *VolumeRestoreHandler) GetRequiredFlags() []string {
+	return []string{}
+}
+
+// loadVolumeProject loads the configuration of the project in the current
+// directory, whose volumes the volume commands work on
+func loadVolumeProject() (*core.ProjectConfig, error) {
+	configPath := filepath.Join(constants.DevStackDir, constants.ConfigFileName)
+	if !utils.FileExists(configPath) {
+		return nil, errors.New(constants.ErrNotInitialized)
+	}
+	cfg, err := core.LoadProjectConfig(configPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load configuration: %w", err)
+	}
+	return cfg, nil
+}
+
+// volumeBackupLabel names a volume in its backup file, without the compose
+// project prefix the backup name already carries
+func volumeBackupLabel(projectName, volumeName string) string {
+	return strings.TrimPrefix(volumeName, docker.NormalizeProjectName(projectName)+"_")
+}
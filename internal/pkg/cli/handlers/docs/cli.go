@@ -0,0 +1,90 @@
+package docs
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/isaacgarza/dev-stack/internal/pkg/cli/types"
+	"github.com/isaacgarza/dev-stack/internal/pkg/constants"
+	"github.com/isaacgarza/dev-stack/internal/pkg/ui"
+	"github.com/spf13/cobra"
+	"github.com/spf13/cobra/doc"
+)
+
+// Formats of the CLI reference
+const (
+	FormatMan      = "man"
+	FormatMarkdown = "markdown"
+	FormatReST     = "rest"
+)
+
+// CLIFormats returns the formats docs cli writes
+func CLIFormats() []string {
+	return []string{FormatMan, FormatMarkdown, FormatReST}
+}
+
+// manSection is the section of the manual the pages of the commands go in
+const manSection = "1"
+
+// CLIHandler handles the docs cli command
+type CLIHandler struct{}
+
+// NewCLIHandler creates a new docs cli handler
+func NewCLIHandler() *CLIHandler {
+	return &CLIHandler{}
+}
+
+// Handle executes the docs cli command. It writes a page per command of the
+// command tree the binary runs, built from commands.yaml, so packages ship
+// a reference that always matches the commands.
+func (h *CLIHandler) Handle(ctx context.Context, cmd *cobra.Command, args []string, base *types.BaseCommand) error {
+	format, _ := cmd.Flags().GetString("format")
+	output, _ := cmd.Flags().GetString("output")
+	if !slices.Contains(CLIFormats(), format) {
+		return fmt.Errorf("unsupported format %q (supported: %v)", format, CLIFormats())
+	}
+
+	if err := os.MkdirAll(output, 0755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", output, err)
+	}
+	if err := GenerateCLIDocs(cmd.Root(), format, output); err != nil {
+		return fmt.Errorf("failed to generate the CLI reference: %w", err)
+	}
+	ui.Success("Wrote the %s reference of %s to %s", format, constants.AppName, output)
+	return nil
+}
+
+// ValidateArgs validates the command arguments
+func (h *CLIHandler) ValidateArgs(args []string) error {
+	return nil
+}
+
+// GetRequiredFlags returns required flags for this command
+func (h *CLIHandler) GetRequiredFlags() []string {
+	return []string{}
+}
+
+// GenerateCLIDocs writes the pages of root and its commands to dir in
+// format. The pages carry no generation date, except the man pages, which
+// take theirs from SOURCE_DATE_EPOCH when it is set, so builds of the same
+// commands produce the same files.
+func GenerateCLIDocs(root *cobra.Command, format, dir string) error {
+	root.DisableAutoGenTag = true
+	switch format {
+	case FormatMan:
+		// Each page is titled after its command, such as DEV-STACK-UP
+		return doc.GenManTree(root, &doc.GenManHeader{
+			Section: manSection,
+			Source:  strings.TrimSpace(root.Name() + " " + root.Version),
+			Manual:  root.Name() + " Manual",
+		}, dir)
+	case FormatMarkdown:
+		return doc.GenMarkdownTree(root, dir)
+	case FormatReST:
+		return doc.GenReSTTree(root, dir)
+	}
+	return fmt.Errorf("unsupported format %q", format)
+}
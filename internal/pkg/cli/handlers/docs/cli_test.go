@@ -0,0 +1,56 @@
+package docs
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// commandTree returns a root command named dev-stack with a nested
+// subcommand
+func commandTree() *cobra.Command {
+	root := &cobra.Command{Use: "dev-stack", Version: "1.2.3"}
+	export := &cobra.Command{Use: "export", Short: "Export the stack", Run: func(*cobra.Command, []string) {}}
+	export.AddCommand(&cobra.Command{Use: "nix", Short: "Write a devenv module", Run: func(*cobra.Command, []string) {}})
+	root.AddCommand(export)
+	return root
+}
+
+func TestGenerateCLIDocs(t *testing.T) {
+	files := map[string][]string{
+		FormatMan:      {"dev-stack.1", "dev-stack-export.1", "dev-stack-export-nix.1"},
+		FormatMarkdown: {"dev-stack.md", "dev-stack_export.md", "dev-stack_export_nix.md"},
+		FormatReST:     {"dev-stack.rst", "dev-stack_export.rst", "dev-stack_export_nix.rst"},
+	}
+
+	for _, format := range CLIFormats() {
+		t.Run(format, func(t *testing.T) {
+			dir := t.TempDir()
+			require.NoError(t, GenerateCLIDocs(commandTree(), format, dir))
+			for _, name := range files[format] {
+				data, err := os.ReadFile(filepath.Join(dir, name))
+				require.NoError(t, err, name)
+				// Pages don't change from one run to the next
+				assert.NotContains(t, string(data), "Auto generated")
+			}
+		})
+	}
+}
+
+func TestGenerateCLIDocs_ManHeader(t *testing.T) {
+	t.Setenv("SOURCE_DATE_EPOCH", "0")
+	dir := t.TempDir()
+	require.NoError(t, GenerateCLIDocs(commandTree(), FormatMan, dir))
+
+	page, err := os.ReadFile(filepath.Join(dir, "dev-stack-export-nix.1"))
+	require.NoError(t, err)
+	assert.Contains(t, string(page), `.TH "DEV-STACK-EXPORT-NIX" "1" "Jan 1970" "dev-stack 1.2.3" "dev-stack Manual"`)
+}
+
+func TestGenerateCLIDocs_UnsupportedFormat(t *testing.T) {
+	assert.Error(t, GenerateCLIDocs(commandTree(), "html", t.TempDir()))
+}
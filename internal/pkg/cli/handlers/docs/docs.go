@@ -0,0 +1,87 @@
+package docs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/isaacgarza/dev-stack/internal/pkg/cli/handlers/core"
+	"github.com/isaacgarza/dev-stack/internal/pkg/cli/handlers/generate"
+	"github.com/isaacgarza/dev-stack/internal/pkg/cli/types"
+	"github.com/isaacgarza/dev-stack/internal/pkg/constants"
+	"github.com/isaacgarza/dev-stack/internal/pkg/diagram"
+	"github.com/isaacgarza/dev-stack/internal/pkg/ui"
+	"github.com/spf13/cobra"
+)
+
+// DocsHandler handles the docs command
+type DocsHandler struct{}
+
+// NewDocsHandler creates a new docs handler
+func NewDocsHandler() *DocsHandler {
+	return &DocsHandler{}
+}
+
+// Handle executes the docs command
+func (h *DocsHandler) Handle(ctx context.Context, cmd *cobra.Command, args []string, base *types.BaseCommand) error {
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+
+	ui.Header("Generating Documentation")
+
+	configPath := filepath.Join(constants.DevStackDir, constants.ConfigFileName)
+	if _, err := os.Stat(configPath); err != nil {
+		return errors.New(constants.ErrNotInitialized)
+	}
+
+	cfg, err := core.LoadProjectConfig(configPath)
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	if err := h.generateArchitecture(cfg, dryRun); err != nil {
+		return err
+	}
+
+	ui.Success("Documentation generated")
+	return nil
+}
+
+// generateArchitecture regenerates the stack architecture diagram page
+func (h *DocsHandler) generateArchitecture(cfg *core.ProjectConfig, dryRun bool) error {
+	topology, err := generate.BuildTopology(cfg)
+	if err != nil {
+		return fmt.Errorf("failed to build topology: %w", err)
+	}
+
+	content := fmt.Sprintf("# %s Architecture\n\n<!-- Generated by %s. Do not edit. -->\n\n```mermaid\n%s```\n",
+		cfg.Project.Name, constants.CmdRef(constants.CmdNameDocs), diagram.RenderMermaid(topology))
+	path := filepath.Join(constants.DevStackDir, constants.DocsDir, constants.ArchitectureDocFileName)
+
+	if dryRun {
+		ui.Info("Would write %s", path)
+		fmt.Print(content)
+		return nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create docs directory: %w", err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+
+	ui.Success("Updated %s", path)
+	return nil
+}
+
+// ValidateArgs validates the command arguments
+func (h *DocsHandler) ValidateArgs(args []string) error {
+	return nil
+}
+
+// GetRequiredFlags returns required flags for this command
+func (h *DocsHandler) GetRequiredFlags() []string {
+	return []string{}
+}
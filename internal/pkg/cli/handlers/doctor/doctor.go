@@ -3,23 +3,46 @@ package doctor
 import (
 	"context"
 	"fmt"
+	"maps"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
 
+	"github.com/docker/go-units"
+	"github.com/isaacgarza/dev-stack/internal/core/docker"
+
+	"github.com/isaacgarza/dev-stack/internal/pkg/cli/handlers/core"
+	"github.com/isaacgarza/dev-stack/internal/pkg/cli/handlers/utils"
 	"github.com/isaacgarza/dev-stack/internal/pkg/cli/types"
+	"github.com/isaacgarza/dev-stack/internal/pkg/compose"
 	"github.com/isaacgarza/dev-stack/internal/pkg/constants"
+	pkgTypes "github.com/isaacgarza/dev-stack/internal/pkg/types"
 	"github.com/isaacgarza/dev-stack/internal/pkg/ui"
+	pkgUtils "github.com/isaacgarza/dev-stack/internal/pkg/utils"
 	"github.com/spf13/cobra"
 )
 
+const (
+	maxMapCountFile = "/proc/sys/vm/max_map_count"
+	minMaxMapCount  = 262144
+)
+
 type DoctorHandler struct {
 	output *ui.Output
+	// checks collect the messages of each check when doctor reports them
+	// with --output rather than as it goes
+	checks []doctorCheck
 }
 
 func NewDoctorHandler() *DoctorHandler {
+	output := ui.NewOutput()
+	output.GitHub = ui.GitHubActions()
 	return &DoctorHandler{
-		output: ui.NewOutput(),
+		output: output,
 	}
 }
 
@@ -32,13 +55,43 @@ func (h *DoctorHandler) GetRequiredFlags() []string {
 }
 
 func (h *DoctorHandler) Handle(ctx context.Context, cmd *cobra.Command, args []string, base *types.BaseCommand) error {
+	// The checks print as they run, unless an output format asks for a
+	// report once they are done
+	outputValue, _ := cmd.Flags().GetString(constants.FlagOutput)
+	formatValue, _ := cmd.Flags().GetString(constants.FlagFormat)
+	report := outputValue != "" || formatValue != "" || utils.GetCIFlags(cmd).JSON
+	output, err := utils.GetRenderOptions(cmd)
+	if err != nil {
+		return err
+	}
+	if report {
+		h.output.Record = h.record
+	}
+
 	h.output.Header("🩺 " + constants.AppNameTitle + " Health Check")
 
 	allGood := true &&
-		h.checkDocker() &&
-		h.checkDockerCompose() &&
+		h.checkDocker(ctx) &&
+		h.checkDockerCompose(ctx) &&
+		h.checkTools(ctx) &&
 		h.checkProjectInit() &&
-		h.checkConfiguration()
+		h.checkConfiguration() &&
+		h.checkServices() &&
+		h.checkResources(ctx) &&
+		h.checkBindMounts() &&
+		h.checkPlatform(ctx) &&
+		h.checkSecurity(ctx) &&
+		h.checkHTTPProxy(ctx)
+
+	if report {
+		if err := h.writeReport(cmd.OutOrStdout(), output, allGood); err != nil {
+			return err
+		}
+		if !allGood {
+			return fmt.Errorf("health check failed")
+		}
+		return nil
+	}
 
 	if allGood {
 		h.output.Success("All checks passed! Your %s is healthy.", constants.AppNameLower)
@@ -49,8 +102,8 @@ func (h *DoctorHandler) Handle(ctx context.Context, cmd *cobra.Command, args []s
 	}
 }
 
-func (h *DoctorHandler) checkDocker() bool {
-	h.output.Info("Checking Docker installation...")
+func (h *DoctorHandler) checkDocker(ctx context.Context) bool {
+	defer h.section("Checking Docker installation...")()
 
 	if !h.isCommandAvailable(constants.DockerCmd) {
 		h.output.Error("Docker not found")
@@ -58,22 +111,32 @@ func (h *DoctorHandler) checkDocker() bool {
 		return false
 	}
 
+	// The engine dev-stack connects to, from DOCKER_HOST or the current
+	// docker context
+	endpoint, err := docker.ResolveEndpoint()
+	if err != nil {
+		h.output.Error("%v", err)
+		return false
+	}
+
 	// Check if Docker daemon is running
-	cmd := exec.Command(constants.DockerCmd, constants.DockerInfoCmd)
+	cmd := exec.CommandContext(ctx, constants.DockerCmd, constants.DockerInfoCmd)
 	if err := cmd.Run(); err != nil {
 		h.output.Error("Docker daemon not running")
 		h.output.Muted("Start Docker daemon")
+		h.output.Muted("Endpoint: %s", endpoint)
 		return false
 	}
 
 	h.output.Success("Docker is available and running")
+	h.output.Muted("Endpoint: %s", endpoint)
 	return true
 }
 
-func (h *DoctorHandler) checkDockerCompose() bool {
-	h.output.Info("Checking Docker Compose...")
+func (h *DoctorHandler) checkDockerCompose(ctx context.Context) bool {
+	defer h.section("Checking Docker Compose...")()
 
-	if !h.hasDockerComposePlugin() {
+	if !h.hasDockerComposePlugin(ctx) {
 		h.output.Error("Docker Compose not found")
 		h.output.Muted("Docker Compose is now integrated into Docker CLI")
 		h.output.Muted("Update Docker to get 'docker compose' command")
@@ -85,7 +148,7 @@ func (h *DoctorHandler) checkDockerCompose() bool {
 }
 
 func (h *DoctorHandler) checkProjectInit() bool {
-	h.output.Info("Checking project initialization...")
+	defer h.section("Checking project initialization...")()
 
 	configPath := filepath.Join(constants.DevStackDir, constants.ConfigFileName)
 	configPathYAML := filepath.Join(constants.DevStackDir, constants.ConfigFileNameYAML)
@@ -103,7 +166,7 @@ func (h *DoctorHandler) checkProjectInit() bool {
 }
 
 func (h *DoctorHandler) checkConfiguration() bool {
-	h.output.Info("Checking configuration validity...")
+	defer h.section("Checking configuration validity...")()
 
 	// Check if dev-stack directory exists
 	if _, err := os.Stat(constants.DevStackDir); os.IsNotExist(err) {
@@ -120,16 +183,237 @@ func (h *DoctorHandler) checkConfiguration() bool {
 		return false
 	}
 
+	data, err := os.ReadFile(composePath)
+	if err != nil {
+		h.output.Error("Cannot read docker compose file: %v", err)
+		return false
+	}
+	if err := compose.Validate(data); err != nil {
+		h.output.FileError(composePath, "Docker compose file is invalid: %v", err)
+		h.output.Muted("Run '%s' to regenerate it", constants.CmdRef(constants.CmdNameGenerateCompose))
+		return false
+	}
+
 	h.output.Success("Configuration is valid")
 	return true
 }
 
+// checkServices verifies every enabled service has a definition, that no two
+// services publish the same host port, and that the host meets the needs of
+// services such as Elasticsearch
+func (h *DoctorHandler) checkServices() bool {
+	defer h.section("Checking enabled services...")()
+
+	configPath := filepath.Join(constants.DevStackDir, constants.ConfigFileName)
+	cfg, err := core.LoadProjectConfig(configPath)
+	if err != nil {
+		h.output.FileError(configPath, "Cannot load configuration: %v", err)
+		return false
+	}
+
+	serviceUtils := utils.NewServiceUtils()
+	ok := true
+	owners := map[string]string{}
+	for _, serviceName := range cfg.Stack.Enabled {
+		serviceConfig, err := serviceUtils.LoadServiceConfig(serviceName)
+		if err != nil {
+			h.output.FileError(configPath, "Unknown service %s", serviceName)
+			h.output.Muted("Run '%s' to see the available services", constants.CmdRef(constants.CmdNameServices))
+			ok = false
+			continue
+		}
+
+		for _, port := range publishedPorts(serviceConfig) {
+			if owner, taken := owners[port]; taken {
+				h.output.FileError(configPath, "%s and %s both publish host port %s", owner, serviceName, port)
+				h.output.Muted("Change the published port of one of them or disable it")
+				ok = false
+				continue
+			}
+			owners[port] = serviceName
+		}
+
+		if serviceName == "elasticsearch" {
+			if err := checkMaxMapCount(maxMapCountFile); err != nil {
+				h.output.Error("%v", err)
+				h.output.Muted("Raise it with 'sudo sysctl -w vm.max_map_count=%d'", minMaxMapCount)
+				ok = false
+			}
+		}
+	}
+
+	if ok {
+		h.output.Success("Enabled services are ready to start")
+	}
+	return ok
+}
+
+// hostCapacity is what the Docker host offers containers
+type hostCapacity struct {
+	CPUs   int
+	Memory int64
+}
+
+// checkResources compares the resource limits of the stack, and of each
+// profile with its own limits, to the capacity of the Docker host
+func (h *DoctorHandler) checkResources(ctx context.Context) bool {
+	defer h.section("Checking resource limits...")()
+
+	host, err := dockerHostCapacity(ctx)
+	if err != nil {
+		h.output.Warning("Cannot read the capacity of the Docker host: %v", err)
+		return true
+	}
+
+	profileFiles, err := compose.ProfileFiles()
+	if err != nil {
+		h.output.Error("Cannot list profile compose files: %v", err)
+		return false
+	}
+	configurations := map[string][]string{"": docker.ComposeFiles()}
+	for profile, file := range profileFiles {
+		configurations[profile] = append(docker.ComposeFiles(), file)
+	}
+
+	ok := true
+	for _, profile := range slices.Sorted(maps.Keys(configurations)) {
+		resources, err := compose.ServiceResources(configurations[profile]...)
+		if err != nil {
+			h.output.Error("Cannot read resource limits: %v", err)
+			ok = false
+			continue
+		}
+
+		problems, warnings := checkCapacity(resources, host)
+		scope := ""
+		if profile != "" {
+			scope = fmt.Sprintf("profile %s: ", profile)
+		}
+		for _, problem := range problems {
+			h.output.Error("%s%s", scope, problem)
+			ok = false
+		}
+		for _, warning := range warnings {
+			h.output.Warning("%s%s", scope, warning)
+		}
+	}
+
+	if !ok {
+		h.output.Muted("Lower the limits under services.<name>.resources or profiles.<name>.resources, or give Docker more resources")
+		return false
+	}
+	h.output.Success("Resource limits fit the Docker host (%d CPUs, %s)", host.CPUs, units.BytesSize(float64(host.Memory)))
+	return true
+}
+
+// checkCapacity returns the limits that exceed what the host offers.
+// Limits no container could reach, and memory limits that add up to more
+// than the host has, are problems; CPU limits that add up to more CPUs than
+// the host has only slow services down under load and are warnings.
+func checkCapacity(resources map[string]pkgTypes.ResourceLimits, host hostCapacity) ([]string, []string) {
+	var problems, warnings []string
+	var totalCPUs float64
+	var totalMemory int64
+	for _, name := range slices.Sorted(maps.Keys(resources)) {
+		limits := resources[name]
+		cpus, err := limits.CPUs()
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("%s: %v", name, err))
+		} else if host.CPUs > 0 && cpus > float64(host.CPUs) {
+			problems = append(problems, fmt.Sprintf("%s is limited to %g CPUs, but the Docker host has %d", name, cpus, host.CPUs))
+		}
+		memory, err := limits.MemoryBytes()
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("%s: %v", name, err))
+		} else if host.Memory > 0 && memory > host.Memory {
+			problems = append(problems, fmt.Sprintf("%s is limited to %s of memory, but the Docker host has %s", name, units.BytesSize(float64(memory)), units.BytesSize(float64(host.Memory))))
+		}
+		totalCPUs += cpus
+		totalMemory += memory
+	}
+
+	if host.Memory > 0 && totalMemory > host.Memory {
+		problems = append(problems, fmt.Sprintf("memory limits add up to %s, but the Docker host has %s", units.BytesSize(float64(totalMemory)), units.BytesSize(float64(host.Memory))))
+	}
+	if host.CPUs > 0 && totalCPUs > float64(host.CPUs) {
+		warnings = append(warnings, fmt.Sprintf("CPU limits add up to %g CPUs, but the Docker host has %d", totalCPUs, host.CPUs))
+	}
+	return problems, warnings
+}
+
+// dockerHostCapacity reads the CPUs and memory of the Docker host, which
+// for Docker Desktop is its VM rather than the machine
+func dockerHostCapacity(ctx context.Context) (hostCapacity, error) {
+	output, err := exec.CommandContext(ctx, constants.DockerCmd, constants.DockerInfoCmd, "--format", "{{.NCPU}} {{.MemTotal}}").Output()
+	if err != nil {
+		return hostCapacity{}, err
+	}
+	var host hostCapacity
+	if _, err := fmt.Sscan(string(output), &host.CPUs, &host.Memory); err != nil {
+		return hostCapacity{}, fmt.Errorf("unexpected docker info output %q", strings.TrimSpace(string(output)))
+	}
+	return host, nil
+}
+
+// publishedPorts returns the host ports a service publishes, with ${VAR:-default}
+// references resolved against the environment
+func publishedPorts(serviceConfig *types.ServiceConfig) []string {
+	var ports []string
+	if serviceConfig.Defaults.Port > 0 {
+		ports = append(ports, strconv.Itoa(serviceConfig.Defaults.Port))
+	}
+	for _, mapping := range serviceConfig.Docker.Ports {
+		mapping = pkgUtils.ExpandEnv(mapping, os.LookupEnv)
+		if i := strings.LastIndex(mapping, ":"); i > 0 {
+			mapping = mapping[:i]
+		}
+		if i := strings.LastIndex(mapping, ":"); i >= 0 {
+			mapping = mapping[i+1:] // drop a host IP
+		}
+		ports = append(ports, mapping)
+	}
+	return ports
+}
+
+// checkMaxMapCount verifies vm.max_map_count allows Elasticsearch to start.
+// Docker Desktop runs containers in a VM it configures itself, so only Linux
+// hosts are checked.
+func checkMaxMapCount(path string) error {
+	if runtime.GOOS != "linux" {
+		return nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	count, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil {
+		return nil
+	}
+	if count < minMaxMapCount {
+		return fmt.Errorf("vm.max_map_count is %d; Elasticsearch needs at least %d", count, minMaxMapCount)
+	}
+	return nil
+}
+
+// section prints the title of a check and groups the output of the check in
+// GitHub Actions jobs, until the returned function is called
+func (h *DoctorHandler) section(title string) func() {
+	if h.output.Record != nil {
+		h.startCheck(title)
+		return func() {}
+	}
+	end := h.output.Group("%s", strings.TrimSuffix(title, "..."))
+	h.output.Info("%s", title)
+	return end
+}
+
 func (h *DoctorHandler) isCommandAvailable(command string) bool {
 	_, err := exec.LookPath(command)
 	return err == nil
 }
 
-func (h *DoctorHandler) hasDockerComposePlugin() bool {
-	cmd := exec.Command(constants.DockerCmd, constants.DockerComposeCmd, constants.DockerVersionCmd)
+func (h *DoctorHandler) hasDockerComposePlugin(ctx context.Context) bool {
+	cmd := exec.CommandContext(ctx, constants.DockerCmd, constants.DockerComposeCmd, constants.DockerVersionCmd)
 	return cmd.Run() == nil
 }
@@ -0,0 +1,203 @@
+package doctor
+
+import (
+	"bytes"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/isaacgarza/dev-stack/internal/pkg/cli/types"
+	"github.com/isaacgarza/dev-stack/internal/pkg/render"
+	pkgTypes "github.com/isaacgarza/dev-stack/internal/pkg/types"
+)
+
+func TestPublishedPorts(t *testing.T) {
+	t.Setenv("RABBITMQ_MANAGEMENT_PORT", "25672")
+
+	serviceConfig := &types.ServiceConfig{}
+	serviceConfig.Defaults.Port = 5672
+	serviceConfig.Docker.Ports = []string{
+		"${RABBITMQ_MANAGEMENT_PORT:-15672}:15672",
+		"${RABBITMQ_PROMETHEUS_PORT:-15692}:15692",
+		"127.0.0.1:4369:4369",
+	}
+
+	assert.Equal(t, []string{"5672", "25672", "15692", "4369"}, publishedPorts(serviceConfig))
+}
+
+func TestCheckMaxMapCount(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("vm.max_map_count is only checked on Linux")
+	}
+
+	path := filepath.Join(t.TempDir(), "max_map_count")
+	require.NoError(t, os.WriteFile(path, []byte("65530\n"), 0644))
+	assert.ErrorContains(t, checkMaxMapCount(path), "vm.max_map_count is 65530")
+
+	require.NoError(t, os.WriteFile(path, []byte("262144\n"), 0644))
+	assert.NoError(t, checkMaxMapCount(path))
+
+	assert.NoError(t, checkMaxMapCount(filepath.Join(t.TempDir(), "missing")))
+}
+
+func TestCheckCapacity(t *testing.T) {
+	host := hostCapacity{CPUs: 4, Memory: 2 << 30}
+
+	problems, warnings := checkCapacity(map[string]pkgTypes.ResourceLimits{
+		"postgres": {CPU: "2", Memory: "512m"},
+		"redis":    {CPU: "1", Memory: "256m"},
+	}, host)
+	assert.Empty(t, problems)
+	assert.Empty(t, warnings)
+
+	problems, warnings = checkCapacity(map[string]pkgTypes.ResourceLimits{
+		"elasticsearch": {CPU: "6", Memory: "1536m"},
+		"kafka":         {Memory: "1g"},
+		"redis":         {Memory: "lots"},
+	}, host)
+	assert.Equal(t, []string{
+		"elasticsearch is limited to 6 CPUs, but the Docker host has 4",
+		`redis: invalid memory "lots": expected a size such as 512m or 1g`,
+		"memory limits add up to 2.5GiB, but the Docker host has 2GiB",
+	}, problems)
+	assert.Equal(t, []string{"CPU limits add up to 6 CPUs, but the Docker host has 4"}, warnings)
+}
+
+func TestCheckMinimum(t *testing.T) {
+	assert.NoError(t, checkMinimum("Docker engine", "27.3.1", minDockerVersion))
+	assert.NoError(t, checkMinimum("Docker engine", "20.10.24+dfsg1", minDockerVersion))
+	assert.NoError(t, checkMinimum("Docker Compose", "v2.29.7", minComposeVersion))
+	assert.NoError(t, checkMinimum("Docker Compose", "2.29.7-desktop.1", minComposeVersion))
+	assert.NoError(t, checkMinimum("Docker Compose", "unknown", minComposeVersion))
+
+	assert.EqualError(t, checkMinimum("Docker engine", "19.03", minDockerVersion), "Docker engine 19.03 is older than the required 20.10.0")
+	assert.EqualError(t, checkMinimum("Docker Compose", "2.17.3", minComposeVersion), "Docker Compose 2.17.3 is older than the required 2.20.0")
+}
+
+func TestEmulatedImages(t *testing.T) {
+	architectures := map[string]string{
+		"postgres:16": "arm64",
+		"mcr.microsoft.com/mssql/server:2022-latest": "amd64",
+		"oracle:19":    "amd64",
+		"localstack:3": "",
+	}
+
+	assert.Equal(t, []string{"mcr.microsoft.com/mssql/server:2022-latest", "oracle:19"}, emulatedImages("arm64", architectures))
+	assert.Equal(t, []string{"postgres:16"}, emulatedImages("amd64", architectures))
+	assert.True(t, isAppleSilicon("darwin", "arm64"))
+	assert.False(t, isAppleSilicon("linux", "arm64"))
+}
+
+func TestFileSharingProblems(t *testing.T) {
+	paths := []string{"/Users/dev/api/data", "/opt/fixtures", "/tmp", `\\wsl$\Ubuntu\home\dev`, `\\nas\share\data`, `C:\Users\dev\api`}
+
+	darwin := fileSharingProblems("darwin", paths)
+	require.Len(t, darwin, 4)
+	assert.Contains(t, darwin[0], "/opt/fixtures")
+
+	windows := fileSharingProblems("windows", paths)
+	require.Len(t, windows, 2)
+	assert.Contains(t, windows[0], "WSL distribution")
+	assert.Contains(t, windows[1], "network share")
+
+	assert.Empty(t, fileSharingProblems("linux", paths))
+}
+
+func TestParseDaemonSecurity(t *testing.T) {
+	options, runtimes, err := parseDaemonSecurity(`["name=seccomp,profile=builtin","name=userns"] {"io.containerd.runc.v2":{"path":"runc"},"runsc":{"path":"/usr/local/bin/runsc"}}`)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"name=seccomp,profile=builtin", "name=userns"}, options)
+	assert.Equal(t, []string{"io.containerd.runc.v2", "runsc"}, runtimes)
+
+	_, _, err = parseDaemonSecurity("not json")
+	assert.Error(t, err)
+}
+
+func TestSecurityProblems(t *testing.T) {
+	services := []string{"postgres", "redis"}
+	defaults := []string{"name=seccomp,profile=builtin"}
+	runc := []string{"runc"}
+
+	hardened := pkgTypes.SecurityConfig{Profile: pkgTypes.SecurityProfileHardened}
+	assert.Empty(t, securityProblems(hardened, services, append(defaults, "name=userns"), runc))
+	problems := securityProblems(hardened, services, defaults, runc)
+	require.Len(t, problems, 1)
+	assert.Contains(t, problems[0], "postgres, redis run as root")
+
+	hardened.Exempt = map[string][]string{"postgres": {pkgTypes.SecurityUserns}, "redis": {pkgTypes.SecurityUserns}}
+	assert.Empty(t, securityProblems(hardened, services, defaults, runc))
+
+	gvisor := pkgTypes.SecurityConfig{Profile: pkgTypes.SecurityProfileGVisor}
+	problems = securityProblems(gvisor, services, append(defaults, "name=userns"), runc)
+	require.Len(t, problems, 1)
+	assert.Contains(t, problems[0], "no runsc runtime")
+
+	// sysbox-runc remaps users on its own
+	sysbox := pkgTypes.SecurityConfig{Profile: pkgTypes.SecurityProfileSysbox}
+	assert.Empty(t, securityProblems(sysbox, services, defaults, []string{"runc", pkgTypes.RuntimeSysbox}))
+}
+
+func TestImageRegistries(t *testing.T) {
+	images := []string{"postgres:16", "ghcr.io/org/api:1", "redis:7", "mirror.corp:5000/library/kafka:3"}
+	assert.Equal(t, []string{"docker.io", "ghcr.io", "mirror.corp:5000"}, imageRegistries(images))
+}
+
+func TestProxyFunc(t *testing.T) {
+	proxy := proxyFunc(pkgTypes.HTTPProxyConfig{
+		HTTP:    "http://proxy.corp:8080",
+		HTTPS:   "http://secure-proxy.corp:8443",
+		NoProxy: []string{".corp.example.com"},
+	})
+	proxyOf := func(target string) string {
+		req, err := http.NewRequest(http.MethodGet, target, nil)
+		require.NoError(t, err)
+		u, err := proxy(req)
+		require.NoError(t, err)
+		if u == nil {
+			return ""
+		}
+		return u.String()
+	}
+
+	assert.Equal(t, "http://secure-proxy.corp:8443", proxyOf("https://registry-1.docker.io/v2/"))
+	assert.Equal(t, "http://proxy.corp:8080", proxyOf("http://example.com/"))
+	assert.Equal(t, "", proxyOf("https://registry.corp.example.com/v2/"))
+}
+
+func TestRecordChecks(t *testing.T) {
+	handler := NewDoctorHandler()
+	handler.output.Record = handler.record
+
+	func() {
+		defer handler.section("Checking Docker installation...")()
+		handler.output.Success("Docker is available and running")
+		handler.output.Muted("Endpoint: unix:///var/run/docker.sock")
+	}()
+	func() {
+		defer handler.section("Checking tool versions...")()
+		handler.output.Success("git is available")
+		handler.output.Warning("Docker Compose 2.20 is older than 2.24")
+		handler.output.Error("make not found")
+	}()
+
+	require.Len(t, handler.checks, 2)
+	assert.Equal(t, "Docker installation", handler.checks[0].Name)
+	assert.Equal(t, checkOK, handler.checks[0].Status)
+	assert.Equal(t, "Tool versions", handler.checks[1].Name)
+	assert.Equal(t, checkFailed, handler.checks[1].Status)
+
+	summary, details := handler.checks[1].summary()
+	assert.Equal(t, "make not found", summary)
+	assert.Equal(t, []string{"git is available", "Docker Compose 2.20 is older than 2.24"}, details)
+
+	var out bytes.Buffer
+	require.NoError(t, handler.writeReport(&out, render.Options{Format: render.FormatTable}, false))
+	assert.Equal(t, "CHECK                STATUS     MESSAGE\n"+
+		"Docker installation  ✅ ok      Docker is available and running\n"+
+		"Tool versions        ❌ failed  make not found\n", out.String())
+}
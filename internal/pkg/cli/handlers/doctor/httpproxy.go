@@ -0,0 +1,90 @@
+package doctor
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/isaacgarza/dev-stack/internal/core/registry"
+	"github.com/isaacgarza/dev-stack/internal/pkg/cli/handlers/core"
+	"github.com/isaacgarza/dev-stack/internal/pkg/constants"
+	pkgTypes "github.com/isaacgarza/dev-stack/internal/pkg/types"
+	"golang.org/x/net/http/httpproxy"
+)
+
+// registryPingTimeout bounds how long a registry gets to answer through
+// the proxy
+const registryPingTimeout = 10 * time.Second
+
+// checkHTTPProxy checks that the registries the images of the project come
+// from answer through the HTTP proxy the project configures, and warns when
+// the Docker daemon, which pulls them, has no proxy of its own
+func (h *DoctorHandler) checkHTTPProxy(ctx context.Context) bool {
+	defer h.section("Checking HTTP proxy...")()
+
+	cfg, err := core.LoadProjectConfig(filepath.Join(constants.DevStackDir, constants.ConfigFileName))
+	if err != nil {
+		h.output.Error("Cannot load configuration: %v", err)
+		return false
+	}
+	proxy := cfg.HTTPProxy.Resolve(os.Getenv)
+	if proxy.IsZero() {
+		h.output.Success("No HTTP proxy configured")
+		return true
+	}
+
+	client := registry.NewClient(&http.Client{
+		Transport: &http.Transport{Proxy: proxyFunc(proxy)},
+	})
+	ok := true
+	for _, host := range imageRegistries(core.ProjectImages(cfg)) {
+		pingCtx, cancel := context.WithTimeout(ctx, registryPingTimeout)
+		err := client.Ping(pingCtx, host)
+		cancel()
+		if err != nil {
+			h.output.Error("Cannot reach %s through the proxy: %v", host, err)
+			ok = false
+		}
+	}
+	if !ok {
+		h.output.Muted("Check the http_proxy URLs, and list internal registries under http_proxy.no_proxy")
+		return false
+	}
+
+	if daemonProxy, err := dockerOutput(ctx, constants.DockerInfoCmd, "--format", "{{.HTTPSProxy}}"); err == nil && daemonProxy == "" {
+		h.output.Warning("The Docker daemon has no HTTPS proxy, so image pulls bypass it")
+		h.output.Muted("Set the proxy in the Docker Desktop settings, or under proxies in daemon.json")
+	}
+	h.output.Success("Image registries answer through the proxy")
+	return true
+}
+
+// proxyFunc returns the proxy of each request the way tools in containers
+// pick it from the proxy variables, skipping the proxy for no_proxy hosts
+func proxyFunc(proxy pkgTypes.HTTPProxyConfig) func(*http.Request) (*url.URL, error) {
+	lookup := (&httpproxy.Config{
+		HTTPProxy:  proxy.HTTP,
+		HTTPSProxy: proxy.HTTPS,
+		NoProxy:    strings.Join(proxy.NoProxy, ","),
+	}).ProxyFunc()
+	return func(req *http.Request) (*url.URL, error) {
+		return lookup(req.URL)
+	}
+}
+
+// imageRegistries returns the registries images are pulled from, sorted
+func imageRegistries(images []string) []string {
+	var registries []string
+	for _, image := range images {
+		if host := registry.ParseReference(image).Registry; !slices.Contains(registries, host) {
+			registries = append(registries, host)
+		}
+	}
+	slices.Sort(registries)
+	return registries
+}
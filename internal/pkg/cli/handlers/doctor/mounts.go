@@ -0,0 +1,109 @@
+package doctor
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+
+	"github.com/isaacgarza/dev-stack/internal/pkg/cli/handlers/core"
+	"github.com/isaacgarza/dev-stack/internal/pkg/compose"
+	"github.com/isaacgarza/dev-stack/internal/pkg/constants"
+)
+
+// macOSSharedDirs are the directories Docker Desktop shares with its VM by
+// default on macOS
+var macOSSharedDirs = []string{"/Users", "/Volumes", "/private", "/tmp", "/var/folders"}
+
+// checkBindMounts checks that the host paths of services.<name>.mounts
+// exist, can be read, and are shared with the Docker VM on macOS and
+// Windows. Paths outside the shared directories only warn, since the
+// sharing settings can't be read from here.
+func (h *DoctorHandler) checkBindMounts() bool {
+	defer h.section("Checking bind mounts...")()
+
+	configPath := filepath.Join(constants.DevStackDir, constants.ConfigFileName)
+	cfg, err := core.LoadProjectConfig(configPath)
+	if err != nil {
+		h.output.FileError(configPath, "Cannot load configuration: %v", err)
+		return false
+	}
+	mounts := cfg.Services.Mounts()
+	if len(mounts) == 0 {
+		h.output.Success("No bind mounts configured")
+		return true
+	}
+	projectRoot, err := os.Getwd()
+	if err != nil {
+		h.output.Error("Cannot find the project root: %v", err)
+		return false
+	}
+	home, _ := os.UserHomeDir()
+
+	ok := true
+	var paths []string
+	for _, serviceName := range slices.Sorted(maps.Keys(mounts)) {
+		for _, mount := range mounts[serviceName] {
+			path := compose.HostPath(mount.Source, projectRoot, home)
+			if err := checkHostPath(path); err != nil {
+				// Generation creates the paths of mounts that ask for it
+				if os.IsNotExist(err) && mount.Create {
+					continue
+				}
+				h.output.FileError(configPath, "services.%s.mounts: %v", serviceName, err)
+				ok = false
+				continue
+			}
+			paths = append(paths, path)
+		}
+	}
+
+	for _, problem := range fileSharingProblems(runtime.GOOS, paths) {
+		h.output.Warning("%s", problem)
+	}
+	if !ok {
+		h.output.Muted("Create the missing paths, set create: true on the mount, or fix their permissions")
+		return false
+	}
+	h.output.Success("Bind mount host paths are readable")
+	return true
+}
+
+// checkHostPath checks that a host path exists and the current user can
+// read it, as Docker Desktop needs to share it
+func checkHostPath(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	return f.Close()
+}
+
+// fileSharingProblems returns the host paths Docker Desktop is unlikely to
+// share with its VM on goos: on macOS those outside the directories shared
+// by default, on Windows network shares and WSL distributions
+func fileSharingProblems(goos string, paths []string) []string {
+	var problems []string
+	for _, path := range paths {
+		switch goos {
+		case "darwin":
+			shared := slices.ContainsFunc(macOSSharedDirs, func(dir string) bool {
+				return path == dir || strings.HasPrefix(path, dir+"/")
+			})
+			if !shared {
+				problems = append(problems, fmt.Sprintf("%s is outside the directories Docker Desktop shares by default; add it under Settings > Resources > File sharing", path))
+			}
+		case "windows":
+			lower := strings.ToLower(path)
+			if strings.HasPrefix(lower, `\\wsl$\`) || strings.HasPrefix(lower, `\\wsl.localhost\`) {
+				problems = append(problems, fmt.Sprintf("%s is inside a WSL distribution; run dev-stack from that distribution instead, or move the files to a Windows drive", path))
+			} else if strings.HasPrefix(path, `\\`) {
+				problems = append(problems, fmt.Sprintf("%s is on a network share, which Docker Desktop can't bind mount; copy the files to a local drive", path))
+			}
+		}
+	}
+	return problems
+}
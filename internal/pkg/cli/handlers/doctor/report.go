@@ -0,0 +1,135 @@
+package doctor
+
+import (
+	"io"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/isaacgarza/dev-stack/internal/pkg/render"
+	"github.com/isaacgarza/dev-stack/internal/pkg/ui"
+)
+
+// Outcomes of a check, from its worst message
+const (
+	checkOK      = "ok"
+	checkWarning = "warning"
+	checkFailed  = "failed"
+)
+
+// doctorCheck is a check with the messages it printed, as doctor reports
+// it with --output
+type doctorCheck struct {
+	Name     string       `json:"name" yaml:"name"`
+	Status   string       `json:"status" yaml:"status"`
+	Messages []ui.Message `json:"messages" yaml:"messages"`
+}
+
+// doctorReport is what doctor reports as JSON or YAML
+type doctorReport struct {
+	Healthy bool          `json:"healthy" yaml:"healthy"`
+	Checks  []doctorCheck `json:"checks" yaml:"checks"`
+}
+
+// checkColumns are the columns of the report table
+var checkColumns = []render.Column[doctorCheck]{
+	{Name: "CHECK", Value: func(c doctorCheck) string { return c.Name }},
+	{Name: "STATUS", Value: func(c doctorCheck) string { return checkIcon(c.Status) + " " + c.Status }, Style: styleCheck},
+	{Name: "MESSAGE", Value: func(c doctorCheck) string {
+		summary, _ := c.summary()
+		return summary
+	}},
+	{Name: "DETAILS", Wide: true, Value: func(c doctorCheck) string {
+		_, details := c.summary()
+		return strings.Join(details, "; ")
+	}},
+}
+
+// startCheck begins recording the check titled like "Checking tool
+// versions..."
+func (h *DoctorHandler) startCheck(title string) {
+	name := strings.TrimSuffix(strings.TrimPrefix(title, "Checking "), "...")
+	if first, size := utf8.DecodeRuneInString(name); size > 0 {
+		name = string(unicode.ToUpper(first)) + name[size:]
+	}
+	h.checks = append(h.checks, doctorCheck{Name: name, Status: checkOK, Messages: []ui.Message{}})
+}
+
+// record adds a message to the running check; errors fail it and warnings
+// downgrade it
+func (h *DoctorHandler) record(message ui.Message) {
+	if len(h.checks) == 0 {
+		return
+	}
+	check := &h.checks[len(h.checks)-1]
+	check.Messages = append(check.Messages, message)
+	switch {
+	case message.Level == ui.LevelError:
+		check.Status = checkFailed
+	case message.Level == ui.LevelWarning && check.Status == checkOK:
+		check.Status = checkWarning
+	}
+}
+
+// writeReport prints the recorded checks in the format of output
+func (h *DoctorHandler) writeReport(w io.Writer, output render.Options, healthy bool) error {
+	return render.Render(w, render.Table[doctorCheck]{
+		Columns: checkColumns,
+		Rows:    h.checks,
+		Data:    doctorReport{Healthy: healthy, Checks: h.checks},
+	}, output)
+}
+
+// summary returns the message that sums a check up, the first one of its
+// worst level, and the other messages
+func (c doctorCheck) summary() (string, []string) {
+	level := ui.LevelSuccess
+	switch c.Status {
+	case checkFailed:
+		level = ui.LevelError
+	case checkWarning:
+		level = ui.LevelWarning
+	}
+
+	summary := -1
+	for i, message := range c.Messages {
+		if message.Level == level {
+			summary = i
+			break
+		}
+	}
+	if summary < 0 && len(c.Messages) > 0 {
+		summary = 0
+	}
+
+	var details []string
+	for i, message := range c.Messages {
+		if i != summary {
+			details = append(details, message.Text)
+		}
+	}
+	if summary < 0 {
+		return "", details
+	}
+	return c.Messages[summary].Text, details
+}
+
+func checkIcon(status string) string {
+	switch status {
+	case checkOK:
+		return "✅"
+	case checkWarning:
+		return "⚠️"
+	}
+	return "❌"
+}
+
+func styleCheck(c doctorCheck, value string) string {
+	switch c.Status {
+	case checkOK:
+		return ui.SuccessText.Render(value)
+	case checkWarning:
+		return ui.WarningText.Render(value)
+	}
+	return ui.ErrorText.Render(value)
+}
@@ -0,0 +1,92 @@
+package doctor
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/isaacgarza/dev-stack/internal/pkg/cli/handlers/core"
+	"github.com/isaacgarza/dev-stack/internal/pkg/constants"
+	pkgTypes "github.com/isaacgarza/dev-stack/internal/pkg/types"
+)
+
+// checkSecurity checks that the Docker daemon can enforce the security
+// profile of the project: that it remaps users for the services that
+// aren't exempt, and has the runtime of the profile registered
+func (h *DoctorHandler) checkSecurity(ctx context.Context) bool {
+	defer h.section("Checking security profile...")()
+
+	cfg, err := core.LoadProjectConfig(filepath.Join(constants.DevStackDir, constants.ConfigFileName))
+	if err != nil {
+		h.output.Error("Cannot load configuration: %v", err)
+		return false
+	}
+	if !cfg.Security.Enabled() {
+		h.output.Success("No security profile configured")
+		return true
+	}
+
+	output, err := dockerOutput(ctx, constants.DockerInfoCmd, "--format", "{{json .SecurityOptions}} {{json .Runtimes}}")
+	if err != nil {
+		h.output.Warning("Cannot read the security options of the Docker daemon: %v", err)
+		return true
+	}
+	options, runtimes, err := parseDaemonSecurity(output)
+	if err != nil {
+		h.output.Warning("%v", err)
+		return true
+	}
+
+	problems := securityProblems(cfg.Security, cfg.Stack.Enabled, options, runtimes)
+	for _, problem := range problems {
+		h.output.Error("%s", problem)
+	}
+	if len(problems) > 0 {
+		h.output.Muted("Exempt the services that can't run under a control with security.exempt.<name>")
+		return false
+	}
+	h.output.Success("The Docker daemon enforces the %s security profile", cfg.Security.Profile)
+	return true
+}
+
+// parseDaemonSecurity parses the security options and the names of the
+// runtimes docker info reports
+func parseDaemonSecurity(output string) ([]string, []string, error) {
+	rawOptions, rawRuntimes, found := strings.Cut(output, " ")
+	var options []string
+	var runtimes map[string]json.RawMessage
+	if !found || json.Unmarshal([]byte(rawOptions), &options) != nil || json.Unmarshal([]byte(rawRuntimes), &runtimes) != nil {
+		return nil, nil, fmt.Errorf("unexpected docker info output %q", output)
+	}
+	return options, slices.Sorted(maps.Keys(runtimes)), nil
+}
+
+// securityProblems returns what keeps the daemon, with the given security
+// options and runtimes, from enforcing the security profile on services.
+// Containers of the sysbox runtime always get a user namespace of their own.
+func securityProblems(security pkgTypes.SecurityConfig, services, options, runtimes []string) []string {
+	var problems []string
+	runtime := security.RuntimeName()
+	if runtime != "" && !slices.Contains(runtimes, runtime) {
+		problems = append(problems, fmt.Sprintf("the Docker daemon has no %s runtime; install it and register it under runtimes in daemon.json", runtime))
+	}
+
+	remapped := slices.ContainsFunc(options, func(option string) bool { return strings.Contains(option, "name=userns") })
+	if remapped {
+		return problems
+	}
+	var unmapped []string
+	for _, service := range services {
+		if security.Applies(service, pkgTypes.SecurityUserns) && !(runtime == pkgTypes.RuntimeSysbox && security.Applies(service, pkgTypes.SecurityRuntime)) {
+			unmapped = append(unmapped, service)
+		}
+	}
+	if len(unmapped) > 0 {
+		problems = append(problems, fmt.Sprintf("the Docker daemon doesn't remap users, so %s run as root on the host; set userns-remap in daemon.json", strings.Join(unmapped, ", ")))
+	}
+	return problems
+}
@@ -0,0 +1,148 @@
+package doctor
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+
+	"github.com/isaacgarza/dev-stack/internal/pkg/cli/handlers/core"
+	"github.com/isaacgarza/dev-stack/internal/pkg/constants"
+	"github.com/isaacgarza/dev-stack/internal/pkg/version"
+)
+
+// Oldest tool versions dev-stack supports
+const (
+	minDockerVersion  = "20.10.0"
+	minComposeVersion = "2.20.0"
+)
+
+// checkTools verifies the Docker engine and Compose plugin are recent
+// enough, and that git is available to clone project templates
+func (h *DoctorHandler) checkTools(ctx context.Context) bool {
+	defer h.section("Checking tool versions...")()
+
+	ok := true
+	engine, err := dockerOutput(ctx, constants.DockerVersionCmd, "--format", "{{.Server.Version}}")
+	if err != nil {
+		h.output.Warning("Cannot read the Docker engine version: %v", err)
+	} else if err := checkMinimum("Docker engine", engine, minDockerVersion); err != nil {
+		h.output.Error("%v", err)
+		h.output.Muted("Upgrade Docker to %s or newer: %s", minDockerVersion, constants.DockerInstallURL)
+		ok = false
+	}
+
+	compose, err := dockerOutput(ctx, constants.DockerComposeCmd, constants.DockerVersionCmd, "--short")
+	if err != nil {
+		h.output.Warning("Cannot read the Docker Compose version: %v", err)
+	} else if err := checkMinimum("Docker Compose", compose, minComposeVersion); err != nil {
+		h.output.Error("%v", err)
+		h.output.Muted("Update the Docker Compose plugin to %s or newer: %s", minComposeVersion, constants.DockerComposeInstallURL)
+		ok = false
+	}
+
+	if !h.isCommandAvailable(constants.GitCmd) {
+		h.output.Warning("git not found; '%s --template' needs it to clone template repositories", constants.CmdInit)
+		h.output.Muted("Install git: %s", constants.GitInstallURL)
+	}
+
+	if ok && engine != "" && compose != "" {
+		h.output.Success("Docker %s and Docker Compose %s are supported", engine, compose)
+	}
+	return ok
+}
+
+// checkPlatform warns about pulled images built for another architecture
+// than the Docker host, which run under emulation, such as amd64-only
+// images on Apple Silicon. Emulated images work, slowly, so this never
+// fails the health check.
+func (h *DoctorHandler) checkPlatform(ctx context.Context) bool {
+	defer h.section("Checking image platforms...")()
+
+	hostArch, err := dockerOutput(ctx, constants.DockerVersionCmd, "--format", "{{.Server.Arch}}")
+	if err != nil {
+		h.output.Warning("Cannot read the Docker host architecture: %v", err)
+		return true
+	}
+	cfg, err := core.LoadProjectConfig(filepath.Join(constants.DevStackDir, constants.ConfigFileName))
+	if err != nil {
+		h.output.Error("Cannot load configuration: %v", err)
+		return false
+	}
+
+	architectures := map[string]string{}
+	for _, image := range core.ProjectImages(cfg) {
+		// Images that aren't pulled yet can't be inspected
+		if arch, err := dockerOutput(ctx, "image", "inspect", "--format", "{{.Architecture}}", image); err == nil {
+			architectures[image] = arch
+		}
+	}
+
+	emulated := emulatedImages(hostArch, architectures)
+	if len(emulated) == 0 {
+		h.output.Success("Pulled images match the %s Docker host", hostArch)
+		return true
+	}
+	for _, image := range emulated {
+		h.output.Warning("%s is built for %s only and runs under emulation on this %s host, which is slower and can crash", image, architectures[image], hostArch)
+	}
+	h.output.Muted("Pin a version with an %s variant under services.<name>.version, or mirror one under images.registry_mirrors", hostArch)
+	if isAppleSilicon(runtime.GOOS, runtime.GOARCH) {
+		h.output.Muted("On Apple Silicon, enable 'Use Rosetta for x86_64/amd64 emulation' in the Docker Desktop settings to speed them up")
+	}
+	return true
+}
+
+// checkMinimum returns an error when the version of a tool is older than
+// minimum. Versions that can't be parsed are accepted.
+func checkMinimum(tool, actual, minimum string) error {
+	cmp, err := version.CompareVersions(normalizeVersion(actual), minimum)
+	if err != nil || cmp >= 0 {
+		return nil
+	}
+	return fmt.Errorf("%s %s is older than the required %s", tool, actual, minimum)
+}
+
+// normalizeVersion turns the versions tools report, such as v2.29.7 or
+// 2.24, into semantic versions
+func normalizeVersion(v string) string {
+	v = strings.TrimPrefix(strings.TrimSpace(v), "v")
+	release, suffix, found := strings.Cut(v, "-")
+	if strings.Count(release, ".") == 1 {
+		release += ".0"
+	}
+	if found {
+		return release + "-" + suffix
+	}
+	return release
+}
+
+// emulatedImages returns the images of architectures, which maps each image
+// to the architecture it was built for, that don't match the host's
+func emulatedImages(hostArch string, architectures map[string]string) []string {
+	var emulated []string
+	for _, image := range slices.Sorted(maps.Keys(architectures)) {
+		if arch := architectures[image]; arch != "" && arch != hostArch {
+			emulated = append(emulated, image)
+		}
+	}
+	return emulated
+}
+
+// isAppleSilicon reports whether dev-stack runs on an Apple Silicon Mac
+func isAppleSilicon(goos, goarch string) bool {
+	return goos == "darwin" && goarch == "arm64"
+}
+
+// dockerOutput runs a docker command and returns its trimmed output
+func dockerOutput(ctx context.Context, args ...string) (string, error) {
+	output, err := exec.CommandContext(ctx, constants.DockerCmd, args...).Output()
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(output)), nil
+}
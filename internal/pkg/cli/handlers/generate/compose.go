@@ -0,0 +1,50 @@
+package generate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/isaacgarza/dev-stack/internal/pkg/cli/handlers/core"
+	"github.com/isaacgarza/dev-stack/internal/pkg/cli/types"
+	"github.com/isaacgarza/dev-stack/internal/pkg/constants"
+	"github.com/spf13/cobra"
+)
+
+// ComposeHandler handles the generate compose command
+type ComposeHandler struct{}
+
+// NewComposeHandler creates a new compose handler
+func NewComposeHandler() *ComposeHandler {
+	return &ComposeHandler{}
+}
+
+// Handle executes the generate compose command
+func (h *ComposeHandler) Handle(ctx context.Context, cmd *cobra.Command, args []string, base *types.BaseCommand) error {
+	configPath := filepath.Join(constants.DevStackDir, constants.ConfigFileName)
+	if _, err := os.Stat(configPath); err != nil {
+		return errors.New(constants.ErrNotInitialized)
+	}
+
+	cfg, err := core.LoadProjectConfig(configPath)
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	if err := core.RegenerateCompose(ctx, cfg); err != nil {
+		return fmt.Errorf("failed to regenerate compose files: %w", err)
+	}
+	return nil
+}
+
+// ValidateArgs validates the command arguments
+func (h *ComposeHandler) ValidateArgs(args []string) error {
+	return nil
+}
+
+// GetRequiredFlags returns required flags for this command
+func (h *ComposeHandler) GetRequiredFlags() []string {
+	return []string{}
+}
@@ -0,0 +1,111 @@
+package generate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/isaacgarza/dev-stack/internal/pkg/cli/handlers/core"
+	"github.com/isaacgarza/dev-stack/internal/pkg/cli/handlers/utils"
+	"github.com/isaacgarza/dev-stack/internal/pkg/cli/types"
+	"github.com/isaacgarza/dev-stack/internal/pkg/constants"
+	"github.com/isaacgarza/dev-stack/internal/pkg/diagram"
+	"github.com/isaacgarza/dev-stack/internal/pkg/ui"
+	"github.com/spf13/cobra"
+)
+
+// DiagramHandler handles the generate diagram command
+type DiagramHandler struct{}
+
+// NewDiagramHandler creates a new diagram handler
+func NewDiagramHandler() *DiagramHandler {
+	return &DiagramHandler{}
+}
+
+// Handle executes the generate diagram command
+func (h *DiagramHandler) Handle(ctx context.Context, cmd *cobra.Command, args []string, base *types.BaseCommand) error {
+	format, _ := cmd.Flags().GetString("format")
+	output, _ := cmd.Flags().GetString("output")
+
+	configPath := filepath.Join(constants.DevStackDir, constants.ConfigFileName)
+	if _, err := os.Stat(configPath); err != nil {
+		return errors.New(constants.ErrNotInitialized)
+	}
+
+	cfg, err := core.LoadProjectConfig(configPath)
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	topology, err := BuildTopology(cfg)
+	if err != nil {
+		return fmt.Errorf("failed to build topology: %w", err)
+	}
+
+	rendered, err := diagram.Render(topology, format)
+	if err != nil {
+		return err
+	}
+
+	if output == "" {
+		_, err := fmt.Fprint(cmd.OutOrStdout(), rendered)
+		return err
+	}
+
+	if err := os.WriteFile(output, []byte(rendered), 0644); err != nil {
+		return fmt.Errorf("failed to write diagram: %w", err)
+	}
+	ui.Success("Wrote %s diagram to %s", format, output)
+	return nil
+}
+
+// ValidateArgs validates the command arguments
+func (h *DiagramHandler) ValidateArgs(args []string) error {
+	return nil
+}
+
+// GetRequiredFlags returns required flags for this command
+func (h *DiagramHandler) GetRequiredFlags() []string {
+	return []string{}
+}
+
+// BuildTopology builds the topology of the enabled services of a project
+// from their service definitions. Nodes show the images the generated
+// compose file runs, with pinned versions applied.
+func BuildTopology(cfg *core.ProjectConfig) (*diagram.Topology, error) {
+	serviceUtils := utils.NewServiceUtils()
+	dependencies, err := serviceUtils.LoadAllServiceDependencies()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load dependencies: %w", err)
+	}
+
+	topology := &diagram.Topology{Project: cfg.Project.Name}
+	for _, serviceName := range cfg.Stack.Enabled {
+		serviceConfig, err := serviceUtils.LoadServiceConfig(serviceName)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load service %s: %w", serviceName, err)
+		}
+
+		// Services running several containers keep the image of their
+		// definition
+		image := core.ServiceImage(cfg, serviceName)
+		if image == "" {
+			image = serviceConfig.Defaults.Image
+		}
+		node := diagram.Node{
+			Name:      serviceName,
+			Image:     image,
+			Networks:  serviceConfig.Docker.Networks,
+			DependsOn: dependencies[serviceName],
+		}
+		if serviceConfig.Defaults.Port > 0 {
+			node.Ports = []string{strconv.Itoa(serviceConfig.Defaults.Port)}
+		}
+		topology.Nodes = append(topology.Nodes, node)
+	}
+
+	return topology, nil
+}
@@ -0,0 +1,28 @@
+package generate
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/isaacgarza/dev-stack/internal/pkg/cli/handlers/core"
+	"github.com/isaacgarza/dev-stack/internal/pkg/types"
+)
+
+func TestBuildTopology(t *testing.T) {
+	cfg := &core.ProjectConfig{Services: types.ServicesConfig{"postgres": {Version: "16"}}}
+	cfg.Project.Name = "shop"
+	cfg.Stack.Enabled = []string{"postgres", "redis"}
+
+	topology, err := BuildTopology(cfg)
+	require.NoError(t, err)
+	assert.Equal(t, "shop", topology.Project)
+	require.Len(t, topology.Nodes, 2)
+
+	// A pinned service shows the image up runs, not the catalog default
+	assert.Equal(t, "postgres", topology.Nodes[0].Name)
+	assert.Equal(t, "postgres:16", topology.Nodes[0].Image)
+	assert.Equal(t, []string{"5432"}, topology.Nodes[0].Ports)
+	assert.Equal(t, "redis:7-alpine", topology.Nodes[1].Image)
+}
@@ -0,0 +1,171 @@
+package init
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+
+	"github.com/isaacgarza/dev-stack/internal/pkg/cli/handlers/utils"
+	"github.com/isaacgarza/dev-stack/internal/pkg/constants"
+)
+
+// environmentOptions are the environments a project can be initialized for
+var environmentOptions = []string{constants.DefaultEnvironment}
+
+// Keys of the validation and advanced options, as set by the prompts
+var (
+	validationKeys = []string{"schema", "health", "dependencies"}
+	advancedKeys   = []string{"monitoring", "logging", "devtools", "testing", constants.AdvancedEnvFiles}
+)
+
+// Answers holds the answers to the init prompts, given up front to
+// initialize a project without prompting
+type Answers struct {
+	Name        string          `yaml:"name"`
+	Environment string          `yaml:"environment"`
+	Services    []string        `yaml:"services"`
+	Validation  map[string]bool `yaml:"validation"`
+	Advanced    map[string]bool `yaml:"advanced"`
+	// Template is the project template to initialize from, and Variables
+	// the values of its variables
+	Template  string            `yaml:"template"`
+	Variables map[string]string `yaml:"variables"`
+}
+
+// answersFromFlags reads the answers of the init flags, over those of the
+// answers file when one is given. It reports whether init runs without
+// prompts: with --yes, --non-interactive or an answers file, unanswered
+// questions take their defaults and the confirmation is skipped.
+func answersFromFlags(cmd *cobra.Command) (*Answers, bool, error) {
+	answersFile, _ := cmd.Flags().GetString("answers-file")
+	yes, _ := cmd.Flags().GetBool("yes")
+
+	answers := &Answers{}
+	if answersFile != "" {
+		loaded, err := loadAnswers(answersFile)
+		if err != nil {
+			return nil, false, err
+		}
+		answers = loaded
+	}
+
+	if name, _ := cmd.Flags().GetString("name"); name != "" {
+		answers.Name = name
+	}
+	if environment, _ := cmd.Flags().GetString("environment"); environment != "" {
+		answers.Environment = environment
+	}
+	if services, _ := cmd.Flags().GetString("services"); services != "" {
+		answers.Services = splitList(services)
+	}
+	if template, _ := cmd.Flags().GetString("template"); template != "" {
+		answers.Template = template
+	}
+	if variables, _ := cmd.Flags().GetString("template-vars"); variables != "" {
+		if answers.Variables == nil {
+			answers.Variables = make(map[string]string)
+		}
+		for _, variable := range splitList(variables) {
+			name, value, ok := strings.Cut(variable, "=")
+			if !ok || name == "" {
+				return nil, false, fmt.Errorf("invalid template variable %q, expected name=value", variable)
+			}
+			answers.Variables[name] = value
+		}
+	}
+
+	return answers, yes || answersFile != "" || utils.GetCIFlags(cmd).NonInteractive, nil
+}
+
+// splitList splits a comma-separated flag value, dropping empty items
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// complete checks the answers, first filling in the defaults when init
+// runs without prompts
+func (a *Answers) complete(nonInteractive bool) error {
+	if nonInteractive {
+		if err := a.applyDefaults(); err != nil {
+			return err
+		}
+	}
+	return a.validateOptions()
+}
+
+// loadAnswers reads an answers file
+func loadAnswers(path string) (*Answers, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read answers file: %w", err)
+	}
+	var answers Answers
+	if err := yaml.Unmarshal(data, &answers); err != nil {
+		return nil, fmt.Errorf("failed to parse answers file %s: %w", path, err)
+	}
+	return &answers, nil
+}
+
+// applyDefaults fills in the answers a user accepting every default would
+// give. Services have no default and must be answered.
+func (a *Answers) applyDefaults() error {
+	if a.Name == "" {
+		currentDir, err := filepath.Abs(".")
+		if err != nil {
+			return fmt.Errorf("failed to get current directory: %w", err)
+		}
+		a.Name = filepath.Base(currentDir)
+	}
+	if a.Environment == "" {
+		a.Environment = constants.DefaultEnvironment
+	}
+	if len(a.Services) == 0 {
+		return fmt.Errorf("no services given; pass --services, list them in the answers file or use a template that does")
+	}
+	if a.Validation == nil {
+		a.Validation = make(map[string]bool)
+	}
+	if a.Advanced == nil {
+		a.Advanced = make(map[string]bool)
+	}
+	return nil
+}
+
+// validateOptions checks the environment and the option keys of the
+// answers; the name and services are validated like prompted ones
+func (a *Answers) validateOptions() error {
+	if a.Environment != "" && !slices.Contains(environmentOptions, a.Environment) {
+		return fmt.Errorf("unsupported environment %q (supported: %s)", a.Environment, strings.Join(environmentOptions, ", "))
+	}
+	if err := checkKeys("validation", a.Validation, validationKeys); err != nil {
+		return err
+	}
+	return checkKeys("advanced", a.Advanced, advancedKeys)
+}
+
+// checkKeys rejects options that no prompt sets
+func checkKeys(section string, options map[string]bool, known []string) error {
+	var unknown []string
+	for key := range options {
+		if !slices.Contains(known, key) {
+			unknown = append(unknown, key)
+		}
+	}
+	if len(unknown) == 0 {
+		return nil
+	}
+	sort.Strings(unknown)
+	return fmt.Errorf("unknown %s options %s (supported: %s)", section, strings.Join(unknown, ", "), strings.Join(known, ", "))
+}
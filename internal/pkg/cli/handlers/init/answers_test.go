@@ -0,0 +1,162 @@
+package init
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// completeAnswers reads the answers of cmd and completes them, as init does
+// for a project without a template
+func completeAnswers(cmd *cobra.Command) (*Answers, bool, error) {
+	answers, nonInteractive, err := answersFromFlags(cmd)
+	if err != nil {
+		return nil, false, err
+	}
+	return answers, nonInteractive, answers.complete(nonInteractive)
+}
+
+// newAnswersCmd returns a command with the init flags set to flags
+func newAnswersCmd(t *testing.T, flags map[string]string) *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Flags().String("name", "", "")
+	cmd.Flags().String("environment", "", "")
+	cmd.Flags().String("services", "", "")
+	cmd.Flags().String("answers-file", "", "")
+	cmd.Flags().String("template", "", "")
+	cmd.Flags().String("template-vars", "", "")
+	cmd.Flags().Bool("yes", false, "")
+	for name, value := range flags {
+		require.NoError(t, cmd.Flags().Set(name, value))
+	}
+	return cmd
+}
+
+func TestAnswersFromFlags_Interactive(t *testing.T) {
+	answers, nonInteractive, err := completeAnswers(newAnswersCmd(t, map[string]string{
+		"services": "postgres, redis,",
+	}))
+	require.NoError(t, err)
+	assert.False(t, nonInteractive)
+	assert.Empty(t, answers.Name)
+	assert.Empty(t, answers.Environment)
+	assert.Equal(t, []string{"postgres", "redis"}, answers.Services)
+}
+
+func TestAnswersFromFlags_YesAppliesDefaults(t *testing.T) {
+	cleanup := setupTestDir(t)
+	defer cleanup()
+
+	answers, nonInteractive, err := completeAnswers(newAnswersCmd(t, map[string]string{
+		"services": "postgres",
+		"yes":      "true",
+	}))
+	require.NoError(t, err)
+	assert.True(t, nonInteractive)
+
+	dir, err := filepath.Abs(".")
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Base(dir), answers.Name)
+	assert.Equal(t, "local", answers.Environment)
+	assert.NotNil(t, answers.Validation)
+	assert.NotNil(t, answers.Advanced)
+}
+
+func TestAnswersFromFlags_YesRequiresServices(t *testing.T) {
+	_, _, err := completeAnswers(newAnswersCmd(t, map[string]string{"yes": "true"}))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "no services given")
+}
+
+func TestAnswersFromFlags_AnswersFile(t *testing.T) {
+	cleanup := setupTestDir(t)
+	defer cleanup()
+
+	createTestFile(t, "answers.yaml", `name: fromfile
+environment: local
+services: [postgres, redis]
+advanced:
+  env_files: true
+`)
+
+	answers, nonInteractive, err := completeAnswers(newAnswersCmd(t, map[string]string{
+		"answers-file": "answers.yaml",
+		"name":         "fromflag",
+	}))
+	require.NoError(t, err)
+	assert.True(t, nonInteractive)
+	assert.Equal(t, "fromflag", answers.Name)
+	assert.Equal(t, []string{"postgres", "redis"}, answers.Services)
+	assert.Equal(t, map[string]bool{"env_files": true}, answers.Advanced)
+}
+
+func TestAnswersFromFlags_Template(t *testing.T) {
+	cleanup := setupTestDir(t)
+	defer cleanup()
+
+	createTestFile(t, "answers.yaml", `template: github.com/acme/template-spring
+variables:
+  package: com.acme
+  port: "8080"
+`)
+
+	answers, _, err := answersFromFlags(newAnswersCmd(t, map[string]string{
+		"answers-file":  "answers.yaml",
+		"template-vars": "port=9090, group=billing",
+	}))
+	require.NoError(t, err)
+	assert.Equal(t, "github.com/acme/template-spring", answers.Template)
+	assert.Equal(t, map[string]string{"package": "com.acme", "port": "9090", "group": "billing"}, answers.Variables)
+
+	_, _, err = answersFromFlags(newAnswersCmd(t, map[string]string{"template-vars": "port"}))
+	assert.ErrorContains(t, err, `invalid template variable "port"`)
+}
+
+func TestAnswersFromFlags_Invalid(t *testing.T) {
+	cleanup := setupTestDir(t)
+	defer cleanup()
+
+	tests := []struct {
+		name    string
+		answers string
+		flags   map[string]string
+		wantErr string
+	}{
+		{
+			name:    "missing file",
+			flags:   map[string]string{"answers-file": "missing.yaml"},
+			wantErr: "failed to read answers file",
+		},
+		{
+			name:    "malformed file",
+			answers: "services: [postgres",
+			wantErr: "failed to parse answers file",
+		},
+		{
+			name:    "unsupported environment",
+			answers: "services: [postgres]\nenvironment: staging\n",
+			wantErr: `unsupported environment "staging"`,
+		},
+		{
+			name:    "unknown option",
+			answers: "services: [postgres]\nadvanced:\n  tracing: true\n",
+			wantErr: "unknown advanced options tracing",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			flags := tt.flags
+			if flags == nil {
+				createTestFile(t, "answers.yaml", tt.answers)
+				flags = map[string]string{"answers-file": "answers.yaml"}
+			}
+			_, _, err := completeAnswers(newAnswersCmd(t, flags))
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
@@ -0,0 +1,39 @@
+package init
+
+import (
+	"github.com/isaacgarza/dev-stack/internal/pkg/cli/types"
+	pkgTypes "github.com/isaacgarza/dev-stack/internal/pkg/types"
+)
+
+// resolveDNS returns the DNS settings of each container of the stack, keyed
+// by container name: those of the project merged with the service's, with
+// the extra hosts of the service definition first. Containers of services
+// the project sets nothing for are left out and keep their definition's
+// extra hosts.
+func (h *InitHandler) resolveDNS(templateServices []struct {
+	Name   string
+	Config *types.ServiceConfig
+}) map[string]*pkgTypes.DNSConfig {
+	containers := make(map[string]*pkgTypes.DNSConfig)
+	for _, svc := range templateServices {
+		settings := h.compose.DNS.Merge(h.compose.ServiceDNS[svc.Name])
+		if settings.IsZero() {
+			continue
+		}
+
+		if len(svc.Config.Docker.Services) == 0 {
+			containers[svc.Name] = containerDNS(settings, svc.Config.Docker.ExtraHosts)
+		}
+		for name, container := range svc.Config.Docker.Services {
+			containers[name] = containerDNS(settings, container.ExtraHosts)
+		}
+	}
+	return containers
+}
+
+// containerDNS returns settings with the extra hosts of a container's
+// definition ahead of its own
+func containerDNS(settings pkgTypes.DNSConfig, extraHosts []string) *pkgTypes.DNSConfig {
+	merged := pkgTypes.DNSConfig{ExtraHosts: extraHosts}.Merge(settings)
+	return &merged
+}
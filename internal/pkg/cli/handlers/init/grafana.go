@@ -0,0 +1,76 @@
+package init
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/isaacgarza/dev-stack/internal/config"
+	"github.com/isaacgarza/dev-stack/internal/pkg/compose"
+	"github.com/isaacgarza/dev-stack/internal/pkg/constants"
+)
+
+// dashboardsDir is the directory of config.EmbeddedDashboardsFS holding the
+// starter dashboards
+const dashboardsDir = "dashboards"
+
+// generateGrafanaFiles writes the provisioning of the grafana service under
+// dev-stack/grafana: a datasource for each service of the stack Grafana can
+// read and the starter dashboards of those services. Dashboards of
+// services no longer in the stack are removed. Stacks without grafana get
+// no files.
+func (h *InitHandler) generateGrafanaFiles(services []string) error {
+	if !slices.Contains(services, constants.ServiceGrafana) {
+		return nil
+	}
+
+	grafanaDir := filepath.Join(constants.DevStackDir, constants.GrafanaDir)
+	datasources, err := compose.GrafanaDatasourcesConfig(services)
+	if err != nil {
+		return fmt.Errorf("failed to render the Grafana datasources: %w", err)
+	}
+	providers, err := compose.GrafanaProvidersConfig()
+	if err != nil {
+		return fmt.Errorf("failed to render the Grafana dashboard providers: %w", err)
+	}
+
+	header := "# Grafana provisioning, generated from dev-stack-config.yml\n"
+	files := map[string][]byte{
+		compose.GrafanaDatasourcesFileName: append([]byte(header), datasources...),
+		compose.GrafanaProvidersFileName:   append([]byte(header), providers...),
+	}
+
+	dashboards, err := fs.ReadDir(config.EmbeddedDashboardsFS, dashboardsDir)
+	if err != nil {
+		return fmt.Errorf("failed to read the starter dashboards: %w", err)
+	}
+	for _, entry := range dashboards {
+		serviceName := strings.TrimSuffix(entry.Name(), ".json")
+		target := filepath.Join(grafanaDir, compose.GrafanaDashboardsDir, entry.Name())
+		if !slices.Contains(services, serviceName) {
+			if err := os.Remove(target); err != nil && !os.IsNotExist(err) {
+				return err
+			}
+			continue
+		}
+		content, err := fs.ReadFile(config.EmbeddedDashboardsFS, dashboardsDir+"/"+entry.Name())
+		if err != nil {
+			return fmt.Errorf("failed to read the %s dashboard: %w", serviceName, err)
+		}
+		files[filepath.Join(compose.GrafanaDashboardsDir, entry.Name())] = content
+	}
+
+	for name, content := range files {
+		path := filepath.Join(grafanaDir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return fmt.Errorf("failed to create %s: %w", filepath.Dir(path), err)
+		}
+		if err := os.WriteFile(path, content, 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
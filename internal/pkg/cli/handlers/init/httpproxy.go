@@ -0,0 +1,56 @@
+package init
+
+import (
+	"maps"
+	"os"
+	"slices"
+
+	"github.com/isaacgarza/dev-stack/internal/pkg/cli/handlers/utils"
+	"github.com/isaacgarza/dev-stack/internal/pkg/cli/types"
+)
+
+// directHosts are reached without the proxy from every container, along
+// with the services of the stack
+var directHosts = []string{"localhost", "127.0.0.1", "::1", "host.docker.internal"}
+
+// httpProxyEnv returns the proxy variables of the containers of services,
+// or nil when the project routes nothing through a proxy. The containers of
+// the stack and the host are reached directly.
+func (h *InitHandler) httpProxyEnv(services []string) map[string]string {
+	proxy := h.compose.HTTPProxy.Resolve(os.Getenv)
+	if proxy.IsZero() {
+		return nil
+	}
+
+	hosts := slices.Clone(directHosts)
+	for _, name := range services {
+		hosts = append(hosts, name)
+		if serviceConfig, err := utils.NewServiceUtils().LoadServiceConfig(name); err == nil {
+			hosts = append(hosts, slices.Sorted(maps.Keys(serviceConfig.Docker.Services))...)
+		}
+	}
+	return proxy.Env(hosts)
+}
+
+// resolveHTTPProxy adds the proxy variables to the environment of each
+// container of a service and to the build arguments of the images it
+// builds. Variables and arguments the definition or the project set are
+// kept.
+func resolveHTTPProxy(serviceConfig *types.ServiceConfig, proxyEnv map[string]string) {
+	if len(proxyEnv) == 0 {
+		return
+	}
+	if len(serviceConfig.Docker.Services) == 0 {
+		serviceConfig.Docker.Environment = withEnv(serviceConfig.Docker.Environment, proxyEnv)
+	}
+	if serviceConfig.Docker.Build.Context != "" {
+		serviceConfig.Docker.Build.Args = mergeBuildArgs(proxyEnv, serviceConfig.Docker.Build.Args)
+	}
+	for name, svc := range serviceConfig.Docker.Services {
+		svc.Environment = withEnv(svc.Environment, proxyEnv)
+		if svc.Build.Context != "" {
+			svc.Build.Args = mergeBuildArgs(proxyEnv, svc.Build.Args)
+		}
+		serviceConfig.Docker.Services[name] = svc
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"github.com/isaacgarza/dev-stack/internal/pkg/cli/handlers/utils"
 	"github.com/isaacgarza/dev-stack/internal/pkg/cli/types"
 	"github.com/isaacgarza/dev-stack/internal/pkg/constants"
+	pkgTypes "github.com/isaacgarza/dev-stack/internal/pkg/types"
 	"github.com/isaacgarza/dev-stack/internal/pkg/ui"
 	"github.com/spf13/cobra"
 )
@@ -14,6 +15,72 @@ import (
 // InitHandler handles the init command
 type InitHandler struct {
 	serviceUtils *utils.ServiceUtils
+	compose      ComposeOptions
+}
+
+// ComposeOptions controls optional docker-compose generation features
+type ComposeOptions struct {
+	// EnvFiles references per-service env fragments via env_file instead of
+	// inlining environment values into docker-compose.yml
+	EnvFiles bool
+	// Versions pins service images to a tag, keyed by service name
+	Versions map[string]string
+	// RegistryMirrors maps upstream registries such as docker.io to the
+	// mirror or pull-through cache image references are rewritten to
+	RegistryMirrors map[string]string
+	// Resources overrides the resource limits of services, keyed by service
+	// name
+	Resources map[string]pkgTypes.ResourceLimits
+	// BuildArgs adds build arguments to the images services build, keyed
+	// by service name
+	BuildArgs map[string]map[string]string
+	// Mounts binds host paths into the containers of services, keyed by
+	// service name
+	Mounts map[string][]pkgTypes.BindMount
+	// Platforms sets the platform of services, keyed by service name
+	Platforms map[string]string
+	// Emulation is the images.emulation policy for images the catalog
+	// lists as having no variant for some architecture
+	Emulation string
+	// Network connects only the services its allow rules name, in
+	// isolated mode
+	Network pkgTypes.NetworkConfig
+	// DNS sets the nameservers, search domains and extra hosts of every
+	// container of the stack
+	DNS pkgTypes.DNSConfig
+	// ServiceDNS overrides DNS for services, keyed by service name
+	ServiceDNS map[string]pkgTypes.DNSConfig
+	// HTTPProxy routes the outbound traffic of containers and image builds
+	// through an HTTP proxy
+	HTTPProxy pkgTypes.HTTPProxyConfig
+	// Security is the security profile applied to the containers of the
+	// stack, with the services exempt from each of its controls
+	Security pkgTypes.SecurityConfig
+	// Trust adds certificate authorities to the trust stores of the
+	// containers of the stack
+	Trust pkgTypes.TrustConfig
+	// Profiles holds the resource limits of profiles, keyed by profile name,
+	// which are written to a compose file per profile
+	Profiles map[string]ProfileResources
+	// Proxy configures the host names and TLS of the routes the proxy
+	// service serves, when the stack enables it
+	Proxy pkgTypes.ProxyConfig
+	// Hostnames replaces localhost in the generated env file with the
+	// hostnames registered for each service, when enabled
+	Hostnames pkgTypes.HostnamesConfig
+	// Tracing adds the OpenTelemetry exporter variables to the services
+	// that build their image, when the stack enables jaeger
+	Tracing pkgTypes.TracingConfig
+	// Logging bounds the logs Docker keeps for each container when the
+	// stack enables the log-collector service
+	Logging pkgTypes.LoggingConfig
+}
+
+// ProfileResources is the resource limits a profile applies to its services
+type ProfileResources struct {
+	// Services lists the services of the profile; none means every service
+	Services  []string
+	Resources pkgTypes.ResourceLimits
 }
 
 // NewInitHandler creates a new InitHandler
@@ -39,16 +106,46 @@ func (h *InitHandler) Handle(ctx context.Context, cmd *cobra.Command, args []str
 		return fmt.Errorf("directory validation failed: %w", err)
 	}
 
-	// Prompt for project details
-	projectName, environment, err := h.promptForProjectDetails()
+	answers, nonInteractive, err := answersFromFlags(cmd)
+	if err != nil {
+		return err
+	}
+
+	// Fetch the project template, whose manifest answers what wasn't given
+	var template *projectTemplate
+	if answers.Template != "" {
+		if template, err = fetchTemplate(ctx, answers.Template); err != nil {
+			return fmt.Errorf("failed to fetch template: %w", err)
+		}
+		if len(answers.Services) == 0 {
+			answers.Services = template.manifest.Services
+		}
+		if answers.Environment == "" {
+			answers.Environment = template.manifest.Environment
+		}
+	}
+	if err := answers.complete(nonInteractive); err != nil {
+		return err
+	}
+
+	// Prompt for project details that weren't given as answers
+	projectName, environment, err := h.promptForProjectDetails(answers.Name, answers.Environment)
 	if err != nil {
 		return fmt.Errorf("failed to get project details: %w", err)
 	}
 
+	if template != nil {
+		if err := template.resolve(projectName, environment, answers.Variables, nonInteractive); err != nil {
+			return fmt.Errorf("failed to apply template: %w", err)
+		}
+	}
+
 	// Prompt for services
-	services, err := h.promptForServices()
-	if err != nil {
-		return fmt.Errorf("failed to select services: %w", err)
+	services := answers.Services
+	if len(services) == 0 {
+		if services, err = h.promptForServices(); err != nil {
+			return fmt.Errorf("failed to select services: %w", err)
+		}
 	}
 
 	// Validate selected services
@@ -57,20 +154,27 @@ func (h *InitHandler) Handle(ctx context.Context, cmd *cobra.Command, args []str
 	}
 
 	// Prompt for advanced options
-	validation, advanced, err := h.promptForAdvancedOptions()
-	if err != nil {
-		return fmt.Errorf("failed to get advanced options: %w", err)
+	validation, advanced := answers.Validation, answers.Advanced
+	if !nonInteractive {
+		if validation, advanced, err = h.promptForAdvancedOptions(); err != nil {
+			return fmt.Errorf("failed to get advanced options: %w", err)
+		}
 	}
 
 	// Confirm initialization
-	confirmed, err := h.confirmInitialization(projectName, environment, services, validation, advanced)
-	if err != nil {
-		return fmt.Errorf("failed to get confirmation: %w", err)
+	h.printSummary(projectName, environment, services, validation, advanced)
+	if template != nil {
+		ui.Info("  Template: %s", template.ref)
 	}
-
-	if !confirmed {
-		ui.Info("Initialization cancelled")
-		return nil
+	if !nonInteractive {
+		confirmed, err := h.confirmInitialization()
+		if err != nil {
+			return fmt.Errorf("failed to get confirmation: %w", err)
+		}
+		if !confirmed {
+			ui.Info("Initialization cancelled")
+			return nil
+		}
 	}
 
 	// Create directory structure
@@ -84,10 +188,17 @@ func (h *InitHandler) Handle(ctx context.Context, cmd *cobra.Command, args []str
 	}
 
 	// Generate initial compose files
-	if err := h.generateInitialComposeFiles(services, projectName, environment, validation, advanced); err != nil {
+	if err := h.generateInitialComposeFiles(ctx, services, projectName, environment, validation, advanced); err != nil {
 		return fmt.Errorf("failed to generate compose files: %w", err)
 	}
 
+	// Copy the template's files and hooks into the project
+	if template != nil {
+		if err := h.applyTemplate(template, force); err != nil {
+			return fmt.Errorf("failed to apply template: %w", err)
+		}
+	}
+
 	// Create .gitignore entries
 	if err := h.createGitignoreEntries(); err != nil {
 		ui.Warning("Failed to update .gitignore: %v", err)
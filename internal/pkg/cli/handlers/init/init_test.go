@@ -3,6 +3,7 @@ package init
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/isaacgarza/dev-stack/internal/pkg/constants"
@@ -75,6 +76,24 @@ func TestCreateGitignoreEntries_ExistingContent(t *testing.T) {
 	assert.Contains(t, string(content), constants.DevStackDir+"/")
 }
 
+func TestCreateGitignoreEntries_CRLF(t *testing.T) {
+	handler := NewInitHandler()
+	cleanup := setupTestDir(t)
+	defer cleanup()
+
+	// A .gitignore saved on Windows, without a final line ending
+	createTestFile(t, constants.GitignoreFileName, "node_modules/\r\n*.log")
+
+	err := handler.createGitignoreEntries()
+	assert.NoError(t, err)
+
+	content, err := os.ReadFile(constants.GitignoreFileName)
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(string(content), "node_modules/\r\n*.log\r\n"))
+	assert.Contains(t, string(content), constants.DevStackDir+"/data/\r\n")
+	assert.NotContains(t, strings.ReplaceAll(string(content), "\r\n", ""), "\n")
+}
+
 func TestCreateReadme_WithServices(t *testing.T) {
 	handler := NewInitHandler()
 	cleanup := setupTestDir(t)
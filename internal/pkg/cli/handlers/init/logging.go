@@ -0,0 +1,39 @@
+package init
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/isaacgarza/dev-stack/internal/core/logstore"
+	"github.com/isaacgarza/dev-stack/internal/pkg/compose"
+	"github.com/isaacgarza/dev-stack/internal/pkg/constants"
+	pkgTypes "github.com/isaacgarza/dev-stack/internal/pkg/types"
+)
+
+// generateLogCollectorFiles writes the configuration of the log-collector
+// service under dev-stack/logs and returns the logging options of every
+// container, when the stack enables it. Other stacks keep Docker's logging
+// defaults and get a nil configuration.
+func (h *InitHandler) generateLogCollectorFiles(projectName string, services []string) (*pkgTypes.LoggingConfig, error) {
+	if !slices.Contains(services, logstore.ServiceName) {
+		return nil, nil
+	}
+
+	content, err := compose.LogCollectorConfig(projectName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render the log-collector configuration: %w", err)
+	}
+	logsDir := filepath.Join(constants.DevStackDir, constants.LogsDir)
+	if err := os.MkdirAll(logsDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create %s: %w", logsDir, err)
+	}
+	header := "# Log collector configuration, generated from dev-stack-config.yml\n"
+	if err := os.WriteFile(filepath.Join(logsDir, compose.LogCollectorConfigFileName), append([]byte(header), content...), 0644); err != nil {
+		return nil, err
+	}
+
+	logging := h.compose.Logging.WithDefaults()
+	return &logging, nil
+}
@@ -0,0 +1,92 @@
+package init
+
+import (
+	"maps"
+	"slices"
+
+	"github.com/isaacgarza/dev-stack/internal/pkg/cli/types"
+	"github.com/isaacgarza/dev-stack/internal/pkg/compose"
+	"github.com/isaacgarza/dev-stack/internal/pkg/constants"
+	pkgTypes "github.com/isaacgarza/dev-stack/internal/pkg/types"
+	"github.com/isaacgarza/dev-stack/internal/pkg/ui"
+)
+
+// resolveNetworks returns, in isolated network mode, the networks of each
+// container of the stack keyed by container name, and the keys of the
+// networks to declare. Containers are connected to those their service's
+// allow rules name, to their depends_on targets, and the proxy to the
+// services it routes, whose labels are pointed at the network they share.
+// Containers of one service are always connected. In shared mode it
+// returns nil, leaving the networks of the service definitions.
+func (h *InitHandler) resolveNetworks(projectName string, templateServices []struct {
+	Name   string
+	Config *types.ServiceConfig
+}, dependsOn map[string]types.DependsOn, labels map[string]map[string]string) (map[string][]string, []string) {
+	if !h.compose.Network.Isolated() {
+		return nil, nil
+	}
+
+	containers := make(map[string][]string)
+	groups := make(map[string][]string)
+	var all []string
+	for _, svc := range templateServices {
+		names := []string{svc.Name}
+		if len(svc.Config.Docker.Services) > 0 {
+			names = slices.Sorted(maps.Keys(svc.Config.Docker.Services))
+			groups[svc.Name] = names
+		}
+		containers[svc.Name] = names
+		all = append(all, names...)
+	}
+	// lookup returns the containers of a service, or the container itself
+	lookup := func(name string) []string {
+		if names, ok := containers[name]; ok {
+			return names
+		}
+		if slices.Contains(all, name) {
+			return []string{name}
+		}
+		return nil
+	}
+
+	var links [][2]string
+	for _, service := range slices.Sorted(maps.Keys(h.compose.Network.Allow)) {
+		from := lookup(service)
+		if from == nil {
+			ui.Warning("network.allow.%s: %s is not part of the stack", service, service)
+			continue
+		}
+		for _, peer := range h.compose.Network.Allow[service] {
+			to := all
+			if peer != pkgTypes.NetworkAllowAll {
+				if to = lookup(peer); to == nil {
+					ui.Warning("network.allow.%s: %s is not part of the stack", service, peer)
+					continue
+				}
+			}
+			for _, a := range from {
+				for _, b := range to {
+					links = append(links, [2]string{a, b})
+				}
+			}
+		}
+	}
+	for _, name := range slices.Sorted(maps.Keys(dependsOn)) {
+		for _, dependency := range dependsOn[name] {
+			links = append(links, [2]string{name, dependency.Name})
+		}
+	}
+	for _, name := range slices.Sorted(maps.Keys(labels)) {
+		links = append(links, [2]string{constants.ServiceProxy, name})
+		labels[name]["traefik.docker.network"] = projectName + "-" + compose.LinkNetwork(constants.ServiceProxy, name)
+	}
+
+	networks := compose.IsolationNetworks(all, groups, links)
+	attachments := make(map[string][]string)
+	for _, key := range slices.Sorted(maps.Keys(networks)) {
+		for _, name := range networks[key] {
+			attachments[name] = append(attachments[name], key)
+		}
+	}
+	return attachments, slices.Sorted(maps.Keys(networks))
+}
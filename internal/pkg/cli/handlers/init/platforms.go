@@ -0,0 +1,69 @@
+package init
+
+import (
+	"maps"
+	"slices"
+
+	"github.com/isaacgarza/dev-stack/internal/core/registry"
+	"github.com/isaacgarza/dev-stack/internal/pkg/cli/types"
+	"github.com/isaacgarza/dev-stack/internal/pkg/constants"
+	"github.com/isaacgarza/dev-stack/internal/pkg/ui"
+)
+
+// devArchitectures are the architectures developer machines run Docker on.
+// Images the compatibility table lists without one of them are pinned, so
+// the generated file is the same on Intel and Apple Silicon machines.
+var devArchitectures = []string{"amd64", "arm64"}
+
+// resolvePlatforms returns the platform of each container of the stack,
+// keyed by container name: the one the project sets for the service, or
+// under the auto emulation policy the first architecture the service's
+// compatibility table lists for an image missing a variant for amd64 or
+// arm64. Images with no variant for hostArch are reported, as they run
+// under emulation.
+func (h *InitHandler) resolvePlatforms(templateServices []struct {
+	Name   string
+	Config *types.ServiceConfig
+}, hostArch string) map[string]string {
+	platforms := make(map[string]string)
+	for _, svc := range templateServices {
+		images := map[string]string{svc.Name: svc.Config.Defaults.Image}
+		if len(svc.Config.Docker.Services) > 0 {
+			images = make(map[string]string, len(svc.Config.Docker.Services))
+			for name, container := range svc.Config.Docker.Services {
+				images[name] = container.Image
+			}
+		}
+
+		for _, name := range slices.Sorted(maps.Keys(images)) {
+			if platform := h.compose.Platforms[svc.Name]; platform != "" {
+				platforms[name] = platform
+				continue
+			}
+			image := images[name]
+			if image == "" || h.compose.Emulation == constants.EmulationOff {
+				continue
+			}
+			architectures := svc.Config.Platforms.Architectures(registry.ParseReference(image).Tag)
+			if len(architectures) == 0 {
+				continue
+			}
+
+			platform := "linux/" + architectures[0]
+			pinned := h.compose.Emulation != constants.EmulationWarn &&
+				slices.ContainsFunc(devArchitectures, func(arch string) bool { return !slices.Contains(architectures, arch) })
+			if pinned {
+				platforms[name] = platform
+			}
+			if slices.Contains(architectures, hostArch) {
+				continue
+			}
+			if pinned {
+				ui.Info("%s has no %s image: %s runs as %s under emulation, which is slower", image, hostArch, name, platform)
+			} else {
+				ui.Warning("%s has no %s image: %s may fail to pull, or run slowly under emulation; set services.%s.platform to %s or images.emulation to auto", image, hostArch, name, svc.Name, platform)
+			}
+		}
+	}
+	return platforms
+}
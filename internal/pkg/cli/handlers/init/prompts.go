@@ -7,43 +7,49 @@ import (
 
 	"github.com/AlecAivazis/survey/v2"
 	"github.com/isaacgarza/dev-stack/internal/pkg/cli/handlers/utils"
+	"github.com/isaacgarza/dev-stack/internal/pkg/constants"
 	"github.com/isaacgarza/dev-stack/internal/pkg/ui"
 )
 
-// promptForProjectDetails prompts user for project configuration
-func (h *InitHandler) promptForProjectDetails() (string, string, error) {
-	var projectName, environment string
-
-	// Get current directory name as default project name
-	currentDir, err := filepath.Abs(".")
-	if err != nil {
-		return "", "", fmt.Errorf("failed to get current directory: %w", err)
-	}
-	defaultName := filepath.Base(currentDir)
+// promptForProjectDetails prompts user for the project configuration that
+// wasn't given yet
+func (h *InitHandler) promptForProjectDetails(projectName, environment string) (string, string, error) {
+	if projectName == "" {
+		// Get current directory name as default project name
+		currentDir, err := filepath.Abs(".")
+		if err != nil {
+			return "", "", fmt.Errorf("failed to get current directory: %w", err)
+		}
+		defaultName := filepath.Base(currentDir)
 
-	// Project name prompt
-	namePrompt := &survey.Input{
-		Message: "Project name:",
-		Default: defaultName,
-		Help:    "Enter a name for your project (letters, numbers, hyphens, underscores only)",
-	}
+		// Project name prompt
+		namePrompt := &survey.Input{
+			Message: "Project name:",
+			Default: defaultName,
+			Help:    "Enter a name for your project (letters, numbers, hyphens, underscores only)",
+		}
 
-	if err := survey.AskOne(namePrompt, &projectName, survey.WithValidator(func(ans interface{}) error {
-		return h.validateProjectName(ans.(string))
-	})); err != nil {
-		return "", "", fmt.Errorf("failed to get project name: %w", err)
+		if err := survey.AskOne(namePrompt, &projectName, survey.WithValidator(func(ans interface{}) error {
+			return h.validateProjectName(ans.(string))
+		})); err != nil {
+			return "", "", fmt.Errorf("failed to get project name: %w", err)
+		}
+	} else if err := h.validateProjectName(projectName); err != nil {
+		return "", "", fmt.Errorf("invalid project name: %w", err)
 	}
 
-	// Environment prompt
-	envPrompt := &survey.Select{
-		Message: "Environment:",
-		Options: []string{"local"},
-		Default: "local",
-		Help:    "Select the environment for this project",
-	}
+	if environment == "" {
+		// Environment prompt
+		envPrompt := &survey.Select{
+			Message: "Environment:",
+			Options: environmentOptions,
+			Default: constants.DefaultEnvironment,
+			Help:    "Select the environment for this project",
+		}
 
-	if err := survey.AskOne(envPrompt, &environment); err != nil {
-		return "", "", fmt.Errorf("failed to get environment: %w", err)
+		if err := survey.AskOne(envPrompt, &environment); err != nil {
+			return "", "", fmt.Errorf("failed to get environment: %w", err)
+		}
 	}
 
 	return projectName, environment, nil
@@ -163,6 +169,7 @@ func (h *InitHandler) promptForAdvancedOptions() (map[string]bool, map[string]bo
 		"Enable logging aggregation",
 		"Enable development tools",
 		"Enable testing framework",
+		"Use per-service env files",
 	}
 
 	var selectedAdvanced []string
@@ -186,14 +193,16 @@ func (h *InitHandler) promptForAdvancedOptions() (map[string]bool, map[string]bo
 			advanced["devtools"] = true
 		case "Enable testing framework":
 			advanced["testing"] = true
+		case "Use per-service env files":
+			advanced[constants.AdvancedEnvFiles] = true
 		}
 	}
 
 	return validation, advanced, nil
 }
 
-// confirmInitialization shows a summary and asks for confirmation
-func (h *InitHandler) confirmInitialization(projectName, environment string, services []string, validation, advanced map[string]bool) (bool, error) {
+// printSummary shows the answers init is about to apply
+func (h *InitHandler) printSummary(projectName, environment string, services []string, validation, advanced map[string]bool) {
 	ui.Info("Initialization Summary:")
 	ui.Info("  Project: %s", projectName)
 	ui.Info("  Environment: %s", environment)
@@ -214,7 +223,10 @@ func (h *InitHandler) confirmInitialization(projectName, environment string, ser
 		}
 		ui.Info("  Advanced: %s", strings.Join(advancedFeatures, ", "))
 	}
+}
 
+// confirmInitialization asks for confirmation of the summary
+func (h *InitHandler) confirmInitialization() (bool, error) {
 	var confirm bool
 	confirmPrompt := &survey.Confirm{
 		Message: "Proceed with initialization?",
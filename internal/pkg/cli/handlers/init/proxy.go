@@ -0,0 +1,129 @@
+package init
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/isaacgarza/dev-stack/internal/pkg/cli/types"
+	"github.com/isaacgarza/dev-stack/internal/pkg/compose"
+	"github.com/isaacgarza/dev-stack/internal/pkg/constants"
+	"github.com/isaacgarza/dev-stack/internal/pkg/ui"
+)
+
+// mkcertCmd issues the certificates of the proxy's local TLS
+const mkcertCmd = "mkcert"
+
+// generateProxyFiles writes the proxy's configuration under dev-stack/proxy
+// and returns the routing labels of each service with a proxy port, keyed
+// by compose service. Stacks without the proxy service get no labels. When
+// local TLS is requested but no certificate can be issued, the routes are
+// served over HTTP only.
+func (h *InitHandler) generateProxyFiles(ctx context.Context, projectName string, templateServices []struct {
+	Name   string
+	Config *types.ServiceConfig
+}) (map[string]map[string]string, error) {
+	if !slices.ContainsFunc(templateServices, func(svc struct {
+		Name   string
+		Config *types.ServiceConfig
+	}) bool {
+		return svc.Name == constants.ServiceProxy
+	}) {
+		return nil, nil
+	}
+
+	var routed []string
+	ports := make(map[string]int)
+	for _, svc := range templateServices {
+		if svc.Config.Proxy.Port > 0 && svc.Name != constants.ServiceProxy && len(svc.Config.Docker.Services) == 0 {
+			routed = append(routed, svc.Name)
+			ports[svc.Name] = svc.Config.Proxy.Port
+		}
+	}
+
+	hosts := make([]string, 0, len(routed))
+	for _, name := range routed {
+		hosts = append(hosts, h.compose.Proxy.Host(name))
+	}
+
+	proxyDir := filepath.Join(constants.DevStackDir, constants.ProxyDir)
+	tls := h.compose.Proxy.TLS
+	if tls {
+		if err := ensureProxyCertificate(ctx, proxyDir, hosts); err != nil {
+			ui.Warning("Serving the proxy over HTTP only: %v", err)
+			tls = false
+		}
+	}
+
+	content, err := compose.ProxyDynamicConfig(tls)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render the proxy configuration: %w", err)
+	}
+	if err := os.MkdirAll(proxyDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create %s: %w", proxyDir, err)
+	}
+	header := "# Proxy configuration, generated from dev-stack-config.yml\n"
+	if err := os.WriteFile(filepath.Join(proxyDir, compose.ProxyDynamicFileName), append([]byte(header), content...), 0644); err != nil {
+		return nil, err
+	}
+
+	labels := make(map[string]map[string]string, len(routed))
+	for i, name := range routed {
+		labels[name] = compose.ProxyLabels(projectName, name, hosts[i], ports[name], tls)
+	}
+	return labels, nil
+}
+
+// ensureProxyCertificate issues a certificate for hosts with mkcert, unless
+// the existing one already covers all of them
+func ensureProxyCertificate(ctx context.Context, proxyDir string, hosts []string) error {
+	certFile := filepath.Join(proxyDir, compose.ProxyCertFileName)
+	keyFile := filepath.Join(proxyDir, compose.ProxyKeyFileName)
+	if certificateCovers(certFile, hosts) {
+		return nil
+	}
+
+	if _, err := exec.LookPath(mkcertCmd); err != nil {
+		return errors.New("local TLS needs mkcert; install it from https://github.com/FiloSottile/mkcert and run 'mkcert -install'")
+	}
+	if err := os.MkdirAll(filepath.Dir(certFile), 0700); err != nil {
+		return fmt.Errorf("failed to create %s: %w", filepath.Dir(certFile), err)
+	}
+
+	args := append([]string{"-cert-file", certFile, "-key-file", keyFile}, hosts...)
+	if output, err := exec.CommandContext(ctx, mkcertCmd, args...).CombinedOutput(); err != nil {
+		return fmt.Errorf("mkcert failed: %w: %s", err, strings.TrimSpace(string(output)))
+	}
+	ui.Success("Issued a local certificate for %s", strings.Join(hosts, ", "))
+	return nil
+}
+
+// certificateCovers reports whether the PEM certificate at path is valid for
+// every host
+func certificateCovers(path string, hosts []string) bool {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return false
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return false
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return false
+	}
+	for _, host := range hosts {
+		if cert.VerifyHostname(host) != nil {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,40 @@
+package init
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCertificateCovers(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "dev-stack"},
+		DNSNames:     []string{"jaeger.localhost", "kafka-ui.localhost"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "local.pem")
+	require.NoError(t, os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0644))
+
+	assert.True(t, certificateCovers(path, []string{"jaeger.localhost"}))
+	assert.True(t, certificateCovers(path, []string{"jaeger.localhost", "kafka-ui.localhost"}))
+	assert.False(t, certificateCovers(path, []string{"jaeger.localhost", "kibana.localhost"}))
+	assert.False(t, certificateCovers(filepath.Join(t.TempDir(), "missing.pem"), []string{"jaeger.localhost"}))
+}
@@ -0,0 +1,79 @@
+package init
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/isaacgarza/dev-stack/internal/pkg/cli/types"
+	"github.com/isaacgarza/dev-stack/internal/pkg/compose"
+	pkgTypes "github.com/isaacgarza/dev-stack/internal/pkg/types"
+)
+
+// noNewPrivileges is the security option that keeps processes from gaining
+// privileges through setuid binaries
+const noNewPrivileges = "no-new-privileges:true"
+
+// resolveSecurity returns the settings the security profile of the project
+// gives each container of the stack, keyed by container name. Containers of
+// a service share its exemptions. Services exempt from every control are
+// left out.
+func (h *InitHandler) resolveSecurity(templateServices []struct {
+	Name   string
+	Config *types.ServiceConfig
+}) map[string]compose.ContainerSecurity {
+	containers := make(map[string]compose.ContainerSecurity)
+	policy := h.compose.Security
+	if !policy.Enabled() {
+		return containers
+	}
+
+	for _, svc := range templateServices {
+		var settings compose.ContainerSecurity
+		if policy.Applies(svc.Name, pkgTypes.SecurityReadOnly) {
+			settings.ReadOnly = true
+			settings.Tmpfs = policy.TmpfsPaths()
+		}
+		if policy.Applies(svc.Name, pkgTypes.SecurityNoNewPrivileges) {
+			settings.SecurityOpt = []string{noNewPrivileges}
+		}
+		if policy.Applies(svc.Name, pkgTypes.SecurityCapDrop) {
+			settings.CapDrop = []string{"ALL"}
+			settings.CapAdd = svc.Config.Security.CapAdd
+		}
+		// Remapping is a daemon setting, which containers can only opt out of
+		if !policy.Applies(svc.Name, pkgTypes.SecurityUserns) {
+			settings.UsernsMode = "host"
+		}
+		if policy.Applies(svc.Name, pkgTypes.SecurityRuntime) {
+			settings.Runtime = policy.RuntimeName()
+		}
+		if settings.IsZero() {
+			continue
+		}
+
+		if len(svc.Config.Docker.Services) == 0 {
+			containers[svc.Name] = settings
+		}
+		for name := range svc.Config.Docker.Services {
+			containers[name] = settings
+		}
+	}
+	return containers
+}
+
+// writeSecurityFile writes the compose file applying the security profile
+// to the containers of the project, or removes it when there is none
+func writeSecurityFile(containers map[string]compose.ContainerSecurity) error {
+	if len(containers) == 0 {
+		if err := os.Remove(compose.SecurityFile()); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+	content, err := compose.SecurityOverride(containers)
+	if err != nil {
+		return fmt.Errorf("failed to render the security profile: %w", err)
+	}
+	header := "# Settings of security.profile, generated from dev-stack-config.yml\n"
+	return os.WriteFile(compose.SecurityFile(), append([]byte(header), content...), 0644)
+}
@@ -3,9 +3,11 @@ package init
 import (
 	"fmt"
 	"os"
+	"strings"
 
 	"github.com/isaacgarza/dev-stack/internal/pkg/constants"
 	"github.com/isaacgarza/dev-stack/internal/pkg/ui"
+	"github.com/isaacgarza/dev-stack/internal/pkg/utils"
 )
 
 // createDirectoryStructure creates the necessary directory structure
@@ -64,17 +66,21 @@ func (h *InitHandler) createGitignoreEntries() error {
 		return nil
 	}
 
-	// Append entries
+	// Append entries with the file's line ending, finishing its last line
+	// first if it has no line ending
 	file, err := os.OpenFile(gitignorePath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
 	if err != nil {
 		return fmt.Errorf("failed to open .gitignore: %w", err)
 	}
 	defer func() { _ = file.Close() }()
 
-	for _, entry := range constants.GitignoreEntries {
-		if _, err := file.WriteString(entry + "\n"); err != nil {
-			return fmt.Errorf("failed to write to .gitignore: %w", err)
-		}
+	ending := utils.LineEnding(existingStr)
+	entries := utils.JoinLines(constants.GitignoreEntries, ending)
+	if existingStr != "" && !strings.HasSuffix(existingStr, "\n") {
+		entries = ending + entries
+	}
+	if _, err := file.WriteString(entries); err != nil {
+		return fmt.Errorf("failed to write to .gitignore: %w", err)
 	}
 
 	ui.Success("Updated .gitignore with dev-stack entries")
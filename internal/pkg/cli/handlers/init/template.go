@@ -0,0 +1,155 @@
+package init
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/AlecAivazis/survey/v2"
+	"gopkg.in/yaml.v3"
+
+	"github.com/isaacgarza/dev-stack/internal/core/services"
+	"github.com/isaacgarza/dev-stack/internal/core/templates"
+	"github.com/isaacgarza/dev-stack/internal/pkg/constants"
+	pkgTypes "github.com/isaacgarza/dev-stack/internal/pkg/types"
+	"github.com/isaacgarza/dev-stack/internal/pkg/ui"
+)
+
+// projectTemplate is a template a project is initialized from
+type projectTemplate struct {
+	ref      string
+	dir      string
+	manifest *templates.Manifest
+	vars     map[string]string
+}
+
+// fetchTemplate fetches the template ref refers to, from the template cache
+// when it was cloned before, and reads its manifest
+func fetchTemplate(ctx context.Context, ref string) (*projectTemplate, error) {
+	source, err := templates.ParseRef(ref)
+	if err != nil {
+		return nil, err
+	}
+
+	dir := source.Dir
+	if source.URL != "" {
+		cacheDir, err := templates.CacheDir()
+		if err != nil {
+			return nil, err
+		}
+		var cached bool
+		if dir, cached = templates.Cached(source, cacheDir); cached {
+			if err := templates.Update(ctx, dir, source.Revision); err != nil {
+				ui.Warning("Using the cached copy of %s: %v", ref, err)
+			}
+		} else {
+			ui.Info("Fetching template %s", ref)
+			if dir, err = templates.Clone(ctx, source, cacheDir); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	manifest, err := templates.LoadManifest(dir)
+	if err != nil {
+		return nil, err
+	}
+	return &projectTemplate{ref: ref, dir: dir, manifest: manifest}, nil
+}
+
+// resolve sets the template's variables and renders its manifest with them,
+// prompting for the variables that weren't given unless init runs without
+// prompts
+func (t *projectTemplate) resolve(projectName, environment string, given map[string]string, nonInteractive bool) error {
+	var ask func(templates.Variable, string) (string, error)
+	if !nonInteractive {
+		ask = promptForTemplateVariable
+	}
+
+	builtins := map[string]string{
+		templates.VarProjectName: projectName,
+		templates.VarEnvironment: environment,
+	}
+	vars, err := t.manifest.ResolveVariables(builtins, given, ask)
+	if err != nil {
+		return err
+	}
+
+	manifest, err := templates.RenderManifest(t.dir, vars)
+	if err != nil {
+		return err
+	}
+	if err := services.ValidateHooks(manifest.Hooks); err != nil {
+		return fmt.Errorf("invalid template hooks: %w", err)
+	}
+	t.manifest, t.vars = manifest, vars
+	return nil
+}
+
+// promptForTemplateVariable asks for the value of a template variable
+func promptForTemplateVariable(variable templates.Variable, defaultValue string) (string, error) {
+	message := variable.Name
+	if variable.Description != "" {
+		message = variable.Description
+	}
+
+	var value string
+	prompt := &survey.Input{
+		Message: message + ":",
+		Default: defaultValue,
+	}
+	var opts []survey.AskOpt
+	if variable.Required {
+		opts = append(opts, survey.WithValidator(survey.Required))
+	}
+	if err := survey.AskOne(prompt, &value, opts...); err != nil {
+		return "", fmt.Errorf("failed to get template variable %s: %w", variable.Name, err)
+	}
+	return value, nil
+}
+
+// applyTemplate copies the template's overlays and seed data into the
+// project and adds its hooks to the configuration file
+func (h *InitHandler) applyTemplate(t *projectTemplate, force bool) error {
+	written, err := t.manifest.Apply(t.dir, ".", filepath.Join(constants.DevStackDir, constants.SeedsDir), t.vars, force)
+	for _, path := range written {
+		ui.Success("Created %s", path)
+	}
+	if err != nil {
+		return err
+	}
+
+	if len(t.manifest.Hooks) > 0 {
+		configPath := filepath.Join(constants.DevStackDir, constants.ConfigFileName)
+		if err := appendHooks(configPath, t.manifest.Hooks); err != nil {
+			return fmt.Errorf("failed to add the template hooks: %w", err)
+		}
+		ui.Success("Added the template hooks to %s", configPath)
+	}
+	return nil
+}
+
+// appendHooks adds a hooks section to the configuration file at path, which
+// init generates without one
+func appendHooks(path string, hooks pkgTypes.HooksConfig) error {
+	var buf bytes.Buffer
+	buf.WriteString("\n# Lifecycle hooks from the project template\n")
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(map[string]pkgTypes.HooksConfig{"hooks": hooks}); err != nil {
+		return err
+	}
+	if err := encoder.Close(); err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = file.Close() }()
+	_, err = file.Write(buf.Bytes())
+	return err
+}
@@ -1,9 +1,27 @@
 package init
 
 import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
+	"github.com/isaacgarza/dev-stack/internal/core/trust"
+	"github.com/isaacgarza/dev-stack/internal/pkg/cli/types"
+	"github.com/isaacgarza/dev-stack/internal/pkg/compose"
+	"github.com/isaacgarza/dev-stack/internal/pkg/constants"
+	pkgTypes "github.com/isaacgarza/dev-stack/internal/pkg/types"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
 )
 
 func TestGenerateInitEnvFile(t *testing.T) {
@@ -54,7 +72,7 @@ func TestGenerateInitDockerCompose(t *testing.T) {
 	projectConfig.Project.Environment = TestEnvironmentLocal
 	projectConfig.Stack.Enabled = []string{TestServicePostgres}
 
-	err = handler.generateInitDockerCompose([]string{TestServicePostgres}, projectConfig)
+	err = handler.generateInitDockerCompose(context.Background(), []string{TestServicePostgres}, projectConfig)
 	if err != nil {
 		t.Logf("Expected error in test environment: %v", err)
 	}
@@ -65,7 +83,7 @@ func TestGenerateInitialComposeFiles(t *testing.T) {
 	cleanup := setupTestDir(t)
 	defer cleanup()
 
-	err := handler.generateInitialComposeFiles([]string{TestServicePostgres}, TestProjectName, TestEnvironmentLocal,
+	err := handler.generateInitialComposeFiles(context.Background(), []string{TestServicePostgres}, TestProjectName, TestEnvironmentLocal,
 		map[string]bool{"skip_warnings": false},
 		map[string]bool{"auto_start": true})
 
@@ -73,3 +91,813 @@ func TestGenerateInitialComposeFiles(t *testing.T) {
 		t.Logf("Expected error in test environment: %v", err)
 	}
 }
+
+func TestGenerateInitialComposeFiles_EnvFiles(t *testing.T) {
+	handler := NewInitHandler()
+	cleanup := setupTestDir(t)
+	defer cleanup()
+
+	err := handler.createDirectoryStructure()
+	require.NoError(t, err)
+
+	err = handler.generateInitialComposeFiles(context.Background(), []string{TestServicePostgres}, TestProjectName, TestEnvironmentLocal,
+		map[string]bool{},
+		map[string]bool{constants.AdvancedEnvFiles: true})
+	require.NoError(t, err)
+
+	compose, err := os.ReadFile(filepath.Join(constants.DevStackDir, constants.DockerComposeFileName))
+	require.NoError(t, err)
+	assert.Contains(t, string(compose), "env/"+TestServicePostgres+".env")
+	assert.NotContains(t, string(compose), "POSTGRES_PASSWORD=")
+
+	fragment, err := os.ReadFile(filepath.Join(constants.DevStackDir, constants.EnvDir, TestServicePostgres+".env"))
+	require.NoError(t, err)
+	assert.Contains(t, string(fragment), "POSTGRES_PASSWORD=")
+}
+
+func TestGenerateInitialComposeFiles_Healthz(t *testing.T) {
+	handler := NewInitHandler()
+	cleanup := setupTestDir(t)
+	defer cleanup()
+
+	err := handler.createDirectoryStructure()
+	require.NoError(t, err)
+
+	err = handler.generateInitialComposeFiles(context.Background(), []string{TestServicePostgres, constants.ServiceHealthz}, TestProjectName, TestEnvironmentLocal,
+		map[string]bool{}, map[string]bool{})
+	require.NoError(t, err)
+
+	compose, err := os.ReadFile(filepath.Join(constants.DevStackDir, constants.DockerComposeFileName))
+	require.NoError(t, err)
+	assert.Contains(t, string(compose), "/var/run/docker.sock:/var/run/docker.sock:ro")
+	assert.Contains(t, string(compose), `command: ["healthz", "--listen", ":8099", "--project", "`+TestProjectName+`"]`)
+	assert.Contains(t, string(compose), "user: root")
+}
+
+func TestGenerateInitialComposeFiles_ComposeSpec(t *testing.T) {
+	handler := NewInitHandler()
+	cleanup := setupTestDir(t)
+	defer cleanup()
+
+	err := handler.createDirectoryStructure()
+	require.NoError(t, err)
+
+	services := []string{TestServicePostgres, "zookeeper", "kafka-broker", "kafka-ui", "kafka-topics", "localstack-core", "localstack-s3"}
+	err = handler.generateInitialComposeFiles(context.Background(), services, TestProjectName, TestEnvironmentLocal,
+		map[string]bool{}, map[string]bool{})
+	require.NoError(t, err)
+
+	data, err := os.ReadFile(filepath.Join(constants.DevStackDir, constants.DockerComposeFileName))
+	require.NoError(t, err)
+
+	var compose struct {
+		Version  string `yaml:"version"`
+		Services map[string]struct {
+			Command   interface{} `yaml:"command"`
+			DependsOn map[string]struct {
+				Condition string `yaml:"condition"`
+			} `yaml:"depends_on"`
+		} `yaml:"services"`
+	}
+	require.NoError(t, yaml.Unmarshal(data, &compose))
+
+	assert.Empty(t, compose.Version)
+	assert.NotContains(t, compose.Services, "localstack-s3")
+	assert.Contains(t, compose.Services, "localstack-core")
+	assert.Equal(t, constants.ConditionServiceHealthy, compose.Services["kafka-broker"].DependsOn["zookeeper"].Condition)
+	assert.Equal(t, constants.ConditionServiceHealthy, compose.Services["kafka-ui"].DependsOn["kafka-broker"].Condition)
+	assert.Empty(t, compose.Services[TestServicePostgres].DependsOn)
+	assert.Contains(t, compose.Services[TestServicePostgres].Command, "-c max_connections=200")
+	assert.Contains(t, compose.Services["kafka-topics"].Command, "\n")
+}
+
+func TestGenerateInitialComposeFiles_CatalogServices(t *testing.T) {
+	handler := NewInitHandler()
+	cleanup := setupTestDir(t)
+	defer cleanup()
+
+	require.NoError(t, handler.createDirectoryStructure())
+
+	services := []string{"rabbitmq", "elasticsearch", "kibana", "minio", "clickhouse", "nats"}
+	err := handler.generateInitialComposeFiles(context.Background(), services, TestProjectName, TestEnvironmentLocal,
+		map[string]bool{}, map[string]bool{})
+	require.NoError(t, err)
+
+	data, err := os.ReadFile(filepath.Join(constants.DevStackDir, constants.DockerComposeFileName))
+	require.NoError(t, err)
+
+	var compose struct {
+		Services map[string]struct {
+			Ports     []string `yaml:"ports"`
+			Volumes   []string `yaml:"volumes"`
+			DependsOn map[string]struct {
+				Condition string `yaml:"condition"`
+			} `yaml:"depends_on"`
+		} `yaml:"services"`
+	}
+	require.NoError(t, yaml.Unmarshal(data, &compose))
+
+	for _, service := range services {
+		assert.Contains(t, compose.Services, service)
+	}
+	assert.Equal(t, []string{"5672:5672", "${RABBITMQ_MANAGEMENT_PORT:-15672}:15672"}, compose.Services["rabbitmq"].Ports)
+	assert.Equal(t, []string{"8123:8123", "${CLICKHOUSE_NATIVE_PORT:-19000}:9000"}, compose.Services["clickhouse"].Ports)
+	assert.Equal(t, []string{TestProjectName + "-minio-data:/data"}, compose.Services["minio"].Volumes)
+	assert.Equal(t, constants.ConditionServiceHealthy, compose.Services["kibana"].DependsOn["elasticsearch"].Condition)
+}
+
+func TestGenerateComposeFiles_RegistryMirrors(t *testing.T) {
+	handler := NewInitHandler()
+	cleanup := setupTestDir(t)
+	defer cleanup()
+
+	require.NoError(t, handler.createDirectoryStructure())
+
+	err := GenerateComposeFiles(context.Background(), TestProjectName, TestEnvironmentLocal, []string{TestServicePostgres}, ComposeOptions{
+		Versions:        map[string]string{TestServicePostgres: "16.2"},
+		RegistryMirrors: map[string]string{"docker.io": "registry.internal:5000"},
+	})
+	require.NoError(t, err)
+
+	data, err := os.ReadFile(filepath.Join(constants.DevStackDir, constants.DockerComposeFileName))
+	require.NoError(t, err)
+
+	var compose struct {
+		Services map[string]struct {
+			Image string `yaml:"image"`
+		} `yaml:"services"`
+	}
+	require.NoError(t, yaml.Unmarshal(data, &compose))
+	assert.Equal(t, "registry.internal:5000/library/postgres:16.2", compose.Services[TestServicePostgres].Image)
+}
+
+func TestGenerateComposeFiles_Resources(t *testing.T) {
+	handler := NewInitHandler()
+	cleanup := setupTestDir(t)
+	defer cleanup()
+
+	require.NoError(t, handler.createDirectoryStructure())
+
+	options := ComposeOptions{
+		Resources: map[string]pkgTypes.ResourceLimits{"redis": {CPU: "0.5"}},
+		Profiles: map[string]ProfileResources{
+			"ci": {Services: []string{TestServicePostgres}, Resources: pkgTypes.ResourceLimits{Memory: "256m", PIDs: 200}},
+		},
+	}
+	err := GenerateComposeFiles(context.Background(), TestProjectName, TestEnvironmentLocal, []string{TestServicePostgres, "redis"}, options)
+	require.NoError(t, err)
+
+	type limits struct {
+		Deploy struct {
+			Resources struct {
+				Limits map[string]any `yaml:"limits"`
+			} `yaml:"resources"`
+		} `yaml:"deploy"`
+		MemLimit string `yaml:"mem_limit"`
+	}
+	var compose struct {
+		Services map[string]limits `yaml:"services"`
+	}
+	data, err := os.ReadFile(filepath.Join(constants.DevStackDir, constants.DockerComposeFileName))
+	require.NoError(t, err)
+	require.NoError(t, yaml.Unmarshal(data, &compose))
+
+	assert.Equal(t, map[string]any{"memory": "512m"}, compose.Services[TestServicePostgres].Deploy.Resources.Limits)
+	assert.Equal(t, map[string]any{"cpus": "0.5", "memory": "256m"}, compose.Services["redis"].Deploy.Resources.Limits)
+	assert.Empty(t, compose.Services[TestServicePostgres].MemLimit)
+
+	profileFile := filepath.Join(constants.DevStackDir, "docker-compose.profile-ci.yml")
+	data, err = os.ReadFile(profileFile)
+	require.NoError(t, err)
+	compose.Services = nil
+	require.NoError(t, yaml.Unmarshal(data, &compose))
+	assert.NotContains(t, compose.Services, "redis")
+	assert.Equal(t, map[string]any{"memory": "256m", "pids": 200}, compose.Services[TestServicePostgres].Deploy.Resources.Limits)
+
+	// Profiles that no longer set limits lose their file
+	require.NoError(t, GenerateComposeFiles(context.Background(), TestProjectName, TestEnvironmentLocal, []string{TestServicePostgres}, ComposeOptions{}))
+	assert.NoFileExists(t, profileFile)
+
+	options.Resources = map[string]pkgTypes.ResourceLimits{"redis": {Memory: "lots"}}
+	err = GenerateComposeFiles(context.Background(), TestProjectName, TestEnvironmentLocal, []string{"redis"}, options)
+	assert.ErrorContains(t, err, `services.redis.resources: invalid memory "lots"`)
+}
+
+func TestGenerateComposeFiles_BuildArgs(t *testing.T) {
+	handler := NewInitHandler()
+	cleanup := setupTestDir(t)
+	defer cleanup()
+
+	require.NoError(t, handler.createDirectoryStructure())
+	servicesDir := filepath.Join(constants.DevStackDir, constants.CustomServicesDir)
+	require.NoError(t, os.MkdirAll(servicesDir, 0755))
+	createTestFile(t, filepath.Join(servicesDir, "api.yaml"), `name: api
+description: Application under development
+defaults:
+  port: 8000
+docker:
+  build:
+    context: ..
+    dockerfile: Dockerfile.dev
+    target: dev
+    args:
+      GO_VERSION: "1.24"
+      DEBUG: "false"
+    cache_from:
+      - ghcr.io/acme/api:main
+`)
+
+	err := GenerateComposeFiles(context.Background(), TestProjectName, TestEnvironmentLocal, []string{"api"}, ComposeOptions{
+		BuildArgs: map[string]map[string]string{"api": {"DEBUG": "true", "NPM_TOKEN": "${NPM_TOKEN}"}},
+	})
+	require.NoError(t, err)
+
+	data, err := os.ReadFile(filepath.Join(constants.DevStackDir, constants.DockerComposeFileName))
+	require.NoError(t, err)
+
+	var compose struct {
+		Services map[string]struct {
+			Build struct {
+				Context    string            `yaml:"context"`
+				Dockerfile string            `yaml:"dockerfile"`
+				Target     string            `yaml:"target"`
+				Args       map[string]string `yaml:"args"`
+				CacheFrom  []string          `yaml:"cache_from"`
+			} `yaml:"build"`
+		} `yaml:"services"`
+	}
+	require.NoError(t, yaml.Unmarshal(data, &compose))
+
+	build := compose.Services["api"].Build
+	assert.Equal(t, "..", build.Context)
+	assert.Equal(t, "Dockerfile.dev", build.Dockerfile)
+	assert.Equal(t, "dev", build.Target)
+	assert.Equal(t, map[string]string{"GO_VERSION": "1.24", "DEBUG": "true", "NPM_TOKEN": "${NPM_TOKEN}"}, build.Args)
+	assert.Equal(t, []string{"ghcr.io/acme/api:main"}, build.CacheFrom)
+}
+
+func TestGenerateComposeFiles_Mounts(t *testing.T) {
+	handler := NewInitHandler()
+	cleanup := setupTestDir(t)
+	defer cleanup()
+
+	require.NoError(t, handler.createDirectoryStructure())
+	projectRoot, err := os.Getwd()
+	require.NoError(t, err)
+	require.NoError(t, os.MkdirAll("fixtures", 0755))
+
+	options := ComposeOptions{Mounts: map[string][]pkgTypes.BindMount{"redis": {
+		{Source: "${PROJECT_ROOT}/fixtures", Target: "/fixtures", ReadOnly: true},
+		{Source: "data/redis", Target: "/data", Create: true},
+	}}}
+	require.NoError(t, GenerateComposeFiles(context.Background(), TestProjectName, TestEnvironmentLocal, []string{"redis"}, options))
+
+	data, err := os.ReadFile(compose.MountsFile())
+	require.NoError(t, err)
+	var mounts struct {
+		Services map[string]struct {
+			Volumes []map[string]interface{} `yaml:"volumes"`
+		} `yaml:"services"`
+	}
+	require.NoError(t, yaml.Unmarshal(data, &mounts))
+	assert.Equal(t, []map[string]interface{}{
+		{"type": "bind", "source": filepath.Join(projectRoot, "fixtures"), "target": "/fixtures", "read_only": true},
+		{"type": "bind", "source": filepath.Join(projectRoot, "data", "redis"), "target": "/data"},
+	}, mounts.Services["redis"].Volumes)
+	assert.DirExists(t, filepath.Join("data", "redis"))
+
+	options.Mounts = map[string][]pkgTypes.BindMount{"redis": {{Source: "missing", Target: "/missing"}}}
+	err = GenerateComposeFiles(context.Background(), TestProjectName, TestEnvironmentLocal, []string{"redis"}, options)
+	assert.ErrorContains(t, err, "services.redis.mounts[0]: host path")
+
+	// Without mounts the file is removed
+	require.NoError(t, GenerateComposeFiles(context.Background(), TestProjectName, TestEnvironmentLocal, []string{"redis"}, ComposeOptions{}))
+	assert.NoFileExists(t, compose.MountsFile())
+}
+
+func TestGenerateComposeFiles_Platforms(t *testing.T) {
+	handler := NewInitHandler()
+	cleanup := setupTestDir(t)
+	defer cleanup()
+
+	require.NoError(t, handler.createDirectoryStructure())
+	composePlatforms := func(options ComposeOptions) map[string]string {
+		require.NoError(t, GenerateComposeFiles(context.Background(), TestProjectName, TestEnvironmentLocal, []string{"mysql", "redis"}, options))
+		data, err := os.ReadFile(filepath.Join(constants.DevStackDir, constants.DockerComposeFileName))
+		require.NoError(t, err)
+		var compose struct {
+			Services map[string]struct {
+				Platform string `yaml:"platform"`
+			} `yaml:"services"`
+		}
+		require.NoError(t, yaml.Unmarshal(data, &compose))
+		platforms := map[string]string{}
+		for name, service := range compose.Services {
+			if service.Platform != "" {
+				platforms[name] = service.Platform
+			}
+		}
+		return platforms
+	}
+
+	// The default mysql image has an arm64 variant, 5.7 doesn't
+	assert.Empty(t, composePlatforms(ComposeOptions{}))
+	pinned := map[string]string{"mysql": "5.7"}
+	assert.Equal(t, map[string]string{"mysql": "linux/amd64"}, composePlatforms(ComposeOptions{Versions: pinned}))
+	assert.Empty(t, composePlatforms(ComposeOptions{Versions: pinned, Emulation: constants.EmulationWarn}))
+	assert.Empty(t, composePlatforms(ComposeOptions{Versions: pinned, Emulation: constants.EmulationOff}))
+
+	// A platform the project sets always applies
+	assert.Equal(t, map[string]string{"redis": "linux/arm64"}, composePlatforms(ComposeOptions{
+		Platforms: map[string]string{"redis": "linux/arm64"},
+		Emulation: constants.EmulationOff,
+	}))
+}
+
+func TestGenerateComposeFiles_Security(t *testing.T) {
+	handler := NewInitHandler()
+	cleanup := setupTestDir(t)
+	defer cleanup()
+
+	require.NoError(t, handler.createDirectoryStructure())
+	require.NoError(t, GenerateComposeFiles(context.Background(), TestProjectName, TestEnvironmentLocal, []string{"postgres", "redis"}, ComposeOptions{}))
+	assert.NoFileExists(t, compose.SecurityFile())
+
+	require.NoError(t, GenerateComposeFiles(context.Background(), TestProjectName, TestEnvironmentLocal, []string{"postgres", "redis"}, ComposeOptions{
+		Security: pkgTypes.SecurityConfig{
+			Profile: pkgTypes.SecurityProfileGVisor,
+			Exempt:  map[string][]string{"redis": {pkgTypes.SecurityReadOnly, pkgTypes.SecurityUserns}},
+		},
+	}))
+	data, err := os.ReadFile(compose.SecurityFile())
+	require.NoError(t, err)
+	var file struct {
+		Services map[string]compose.ContainerSecurity `yaml:"services"`
+	}
+	require.NoError(t, yaml.Unmarshal(data, &file))
+
+	assert.Equal(t, compose.ContainerSecurity{
+		ReadOnly:    true,
+		Tmpfs:       pkgTypes.DefaultSecurityTmpfs,
+		SecurityOpt: []string{noNewPrivileges},
+		CapDrop:     []string{"ALL"},
+		CapAdd:      []string{"CHOWN", "DAC_OVERRIDE", "FOWNER", "SETGID", "SETUID"},
+		Runtime:     pkgTypes.RuntimeGVisor,
+	}, file.Services["postgres"])
+	assert.Equal(t, compose.ContainerSecurity{
+		SecurityOpt: []string{noNewPrivileges},
+		CapDrop:     []string{"ALL"},
+		CapAdd:      []string{"CHOWN", "SETGID", "SETUID"},
+		UsernsMode:  "host",
+		Runtime:     pkgTypes.RuntimeGVisor,
+	}, file.Services["redis"])
+
+	// Turning the profile off removes the file
+	require.NoError(t, GenerateComposeFiles(context.Background(), TestProjectName, TestEnvironmentLocal, []string{"postgres"}, ComposeOptions{
+		Security: pkgTypes.SecurityConfig{Profile: pkgTypes.SecurityProfileNone},
+	}))
+	assert.NoFileExists(t, compose.SecurityFile())
+}
+
+func TestGenerateComposeFiles_NetworkIsolation(t *testing.T) {
+	handler := NewInitHandler()
+	cleanup := setupTestDir(t)
+	defer cleanup()
+
+	require.NoError(t, handler.createDirectoryStructure())
+	composeNetworks := func(network pkgTypes.NetworkConfig) map[string][]string {
+		require.NoError(t, GenerateComposeFiles(context.Background(), TestProjectName, TestEnvironmentLocal, []string{"postgres", "redis", "mysql"}, ComposeOptions{Network: network}))
+		networks, err := compose.ServiceNetworks(filepath.Join(constants.DevStackDir, constants.DockerComposeFileName))
+		require.NoError(t, err)
+		return networks
+	}
+
+	networks := composeNetworks(pkgTypes.NetworkConfig{})
+	assert.Equal(t, []string{"dev-stack"}, networks["postgres"])
+	assert.Equal(t, []string{"dev-stack"}, networks["mysql"])
+
+	networks = composeNetworks(pkgTypes.NetworkConfig{
+		Mode:  pkgTypes.NetworkModeIsolated,
+		Allow: map[string][]string{"redis": {"postgres"}},
+	})
+	assert.Equal(t, map[string][]string{
+		"postgres": {"postgres--redis"},
+		"redis":    {"postgres--redis"},
+		"mysql":    {"mysql"},
+	}, networks)
+	assert.Equal(t, map[string][]string{"postgres": {"redis"}, "redis": {"postgres"}, "mysql": nil}, compose.Reachability(networks))
+}
+
+func TestGenerateComposeFiles_DNS(t *testing.T) {
+	handler := NewInitHandler()
+	cleanup := setupTestDir(t)
+	defer cleanup()
+
+	require.NoError(t, handler.createDirectoryStructure())
+	require.NoError(t, GenerateComposeFiles(context.Background(), TestProjectName, TestEnvironmentLocal, []string{"postgres", "redis", "prometheus"}, ComposeOptions{
+		DNS: pkgTypes.DNSConfig{
+			Servers:    []string{"10.0.0.2"},
+			Search:     []string{"corp.example.com"},
+			ExtraHosts: []string{"git.corp.example.com:10.1.2.3"},
+		},
+		ServiceDNS: map[string]pkgTypes.DNSConfig{
+			"redis": {Servers: []string{"10.0.0.53"}, ExtraHosts: []string{"vault:host-gateway"}},
+		},
+	}))
+	data, err := os.ReadFile(filepath.Join(constants.DevStackDir, constants.DockerComposeFileName))
+	require.NoError(t, err)
+	var compose struct {
+		Services map[string]struct {
+			DNS        []string `yaml:"dns"`
+			DNSSearch  []string `yaml:"dns_search"`
+			ExtraHosts []string `yaml:"extra_hosts"`
+		} `yaml:"services"`
+	}
+	require.NoError(t, yaml.Unmarshal(data, &compose))
+
+	postgres := compose.Services["postgres"]
+	assert.Equal(t, []string{"10.0.0.2"}, postgres.DNS)
+	assert.Equal(t, []string{"corp.example.com"}, postgres.DNSSearch)
+	assert.Equal(t, []string{"git.corp.example.com:10.1.2.3"}, postgres.ExtraHosts)
+
+	redis := compose.Services["redis"]
+	assert.Equal(t, []string{"10.0.0.53"}, redis.DNS)
+	assert.Equal(t, []string{"corp.example.com"}, redis.DNSSearch)
+	assert.Equal(t, []string{"git.corp.example.com:10.1.2.3", "vault:host-gateway"}, redis.ExtraHosts)
+
+	// The extra hosts of the service definition are kept
+	assert.Equal(t, []string{"host.docker.internal:host-gateway", "git.corp.example.com:10.1.2.3"}, compose.Services["prometheus"].ExtraHosts)
+}
+
+func TestGenerateComposeFiles_TracingEnv(t *testing.T) {
+	handler := NewInitHandler()
+	cleanup := setupTestDir(t)
+	defer cleanup()
+
+	require.NoError(t, handler.createDirectoryStructure())
+	servicesDir := filepath.Join(constants.DevStackDir, constants.CustomServicesDir)
+	require.NoError(t, os.MkdirAll(servicesDir, 0755))
+	createTestFile(t, filepath.Join(servicesDir, "api.yaml"), `name: api
+description: Application under development
+docker:
+  build:
+    context: ..
+  environment:
+    - OTEL_SERVICE_NAME=orders-api
+`)
+
+	composeEnvironment := func(services []string, tracing pkgTypes.TracingConfig) []string {
+		require.NoError(t, GenerateComposeFiles(context.Background(), TestProjectName, TestEnvironmentLocal, services, ComposeOptions{Tracing: tracing}))
+		data, err := os.ReadFile(filepath.Join(constants.DevStackDir, constants.DockerComposeFileName))
+		require.NoError(t, err)
+		var compose struct {
+			Services map[string]struct {
+				Environment []string `yaml:"environment"`
+			} `yaml:"services"`
+		}
+		require.NoError(t, yaml.Unmarshal(data, &compose))
+		return compose.Services["api"].Environment
+	}
+
+	assert.Equal(t, []string{
+		"OTEL_SERVICE_NAME=orders-api",
+		"OTEL_EXPORTER_OTLP_ENDPOINT=http://jaeger:4318",
+		"OTEL_EXPORTER_OTLP_PROTOCOL=http/protobuf",
+		"OTEL_TRACES_EXPORTER=otlp",
+	}, composeEnvironment([]string{"api", "jaeger"}, pkgTypes.TracingConfig{InjectEnv: true}))
+
+	// Nothing is injected without Jaeger or for services left out
+	assert.Equal(t, []string{"OTEL_SERVICE_NAME=orders-api"}, composeEnvironment([]string{"api"}, pkgTypes.TracingConfig{InjectEnv: true}))
+	assert.Equal(t, []string{"OTEL_SERVICE_NAME=orders-api"}, composeEnvironment([]string{"api", "jaeger"}, pkgTypes.TracingConfig{InjectEnv: true, Services: []string{"worker"}}))
+}
+
+func TestGenerateComposeFiles_HTTPProxy(t *testing.T) {
+	handler := NewInitHandler()
+	cleanup := setupTestDir(t)
+	defer cleanup()
+
+	require.NoError(t, handler.createDirectoryStructure())
+	servicesDir := filepath.Join(constants.DevStackDir, constants.CustomServicesDir)
+	require.NoError(t, os.MkdirAll(servicesDir, 0755))
+	createTestFile(t, filepath.Join(servicesDir, "api.yaml"), `name: api
+description: Application under development
+docker:
+  build:
+    context: ..
+    args:
+      HTTPS_PROXY: http://build-proxy:3128
+  environment:
+    - HTTP_PROXY=http://api-proxy:3128
+`)
+	t.Setenv("HTTPS_PROXY", "http://env-proxy:3128")
+	t.Setenv("NO_PROXY", "")
+	t.Setenv("no_proxy", "")
+
+	require.NoError(t, GenerateComposeFiles(context.Background(), TestProjectName, TestEnvironmentLocal, []string{"api", "redis"}, ComposeOptions{
+		HTTPProxy: pkgTypes.HTTPProxyConfig{FromEnv: true, HTTP: "http://proxy.corp:8080", NoProxy: []string{".corp.example.com"}},
+	}))
+	data, err := os.ReadFile(filepath.Join(constants.DevStackDir, constants.DockerComposeFileName))
+	require.NoError(t, err)
+	var compose struct {
+		Services map[string]struct {
+			Build struct {
+				Args map[string]string `yaml:"args"`
+			} `yaml:"build"`
+			Environment []string `yaml:"environment"`
+		} `yaml:"services"`
+	}
+	require.NoError(t, yaml.Unmarshal(data, &compose))
+
+	noProxy := "localhost,127.0.0.1,::1,host.docker.internal,api,redis,.corp.example.com"
+	redis := compose.Services["redis"].Environment
+	assert.Contains(t, redis, "HTTP_PROXY=http://proxy.corp:8080")
+	assert.Contains(t, redis, "https_proxy=http://env-proxy:3128")
+	assert.Contains(t, redis, "NO_PROXY="+noProxy)
+
+	// What the definition sets is kept
+	api := compose.Services["api"]
+	assert.Contains(t, api.Environment, "HTTP_PROXY=http://api-proxy:3128")
+	assert.NotContains(t, api.Environment, "HTTP_PROXY=http://proxy.corp:8080")
+	assert.Equal(t, "http://build-proxy:3128", api.Build.Args["HTTPS_PROXY"])
+	assert.Equal(t, "http://proxy.corp:8080", api.Build.Args["HTTP_PROXY"])
+	assert.Equal(t, noProxy, api.Build.Args["no_proxy"])
+
+	// Nothing is injected without a proxy
+	require.NoError(t, GenerateComposeFiles(context.Background(), TestProjectName, TestEnvironmentLocal, []string{"redis"}, ComposeOptions{
+		HTTPProxy: pkgTypes.HTTPProxyConfig{NoProxy: []string{".corp.example.com"}},
+	}))
+	data, err = os.ReadFile(filepath.Join(constants.DevStackDir, constants.DockerComposeFileName))
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "NO_PROXY")
+}
+
+// writeTestCertificate writes a self-signed certificate authority as PEM
+func writeTestCertificate(t *testing.T, path, name string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: name},
+		NotBefore:             time.Now(),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0644))
+}
+
+func TestGenerateComposeFiles_Trust(t *testing.T) {
+	handler := NewInitHandler()
+	cleanup := setupTestDir(t)
+	defer cleanup()
+
+	require.NoError(t, handler.createDirectoryStructure())
+	servicesDir := filepath.Join(constants.DevStackDir, constants.CustomServicesDir)
+	require.NoError(t, os.MkdirAll(servicesDir, 0755))
+	createTestFile(t, filepath.Join(servicesDir, "api.yaml"), `name: api
+description: Application under development
+trust:
+  cacerts: /opt/java/openjdk/lib/security/cacerts
+docker:
+  build:
+    context: ..
+  environment:
+    - NODE_EXTRA_CA_CERTS=/app/certs/extra.pem
+`)
+	require.NoError(t, os.MkdirAll("certs", 0755))
+	writeTestCertificate(t, filepath.Join("certs", "public.pem"), "Public Root")
+	writeTestCertificate(t, filepath.Join("certs", "corp.pem"), "Corp Root CA")
+
+	options := ComposeOptions{Trust: pkgTypes.TrustConfig{
+		CABundles:    []string{"certs/corp.pem"},
+		SystemBundle: "${PROJECT_ROOT}/certs/public.pem",
+		Exempt:       []string{"redis"},
+	}}
+	require.NoError(t, GenerateComposeFiles(context.Background(), TestProjectName, TestEnvironmentLocal, []string{"api", "postgres", "redis", "mysql"}, options))
+
+	projectRoot, err := os.Getwd()
+	require.NoError(t, err)
+	bundle := filepath.Join(projectRoot, constants.DevStackDir, constants.CertsDir, "ca-bundle.pem")
+	keystore := filepath.Join(projectRoot, constants.DevStackDir, constants.CertsDir, "cacerts")
+	certs, err := trust.ReadBundles(bundle)
+	require.NoError(t, err)
+	require.Len(t, certs, 2)
+	assert.Equal(t, "Public Root", certs[0].Subject.CommonName)
+	assert.Equal(t, "Corp Root CA", certs[1].Subject.CommonName)
+	assert.FileExists(t, keystore)
+
+	data, err := os.ReadFile(compose.TrustFile())
+	require.NoError(t, err)
+	var trustFile struct {
+		Services map[string]struct {
+			Volumes []struct {
+				Source   string `yaml:"source"`
+				Target   string `yaml:"target"`
+				ReadOnly bool   `yaml:"read_only"`
+			} `yaml:"volumes"`
+			Environment map[string]string `yaml:"environment"`
+		} `yaml:"services"`
+	}
+	require.NoError(t, yaml.Unmarshal(data, &trustFile))
+	assert.NotContains(t, trustFile.Services, "redis")
+
+	// postgres:15-alpine keeps its store where Alpine does, mysql:8-oracle
+	// where RHEL does
+	postgres := trustFile.Services["postgres"]
+	require.Len(t, postgres.Volumes, 1)
+	assert.Equal(t, bundle, postgres.Volumes[0].Source)
+	assert.Equal(t, "/etc/ssl/certs/ca-certificates.crt", postgres.Volumes[0].Target)
+	assert.True(t, postgres.Volumes[0].ReadOnly)
+	assert.Equal(t, "/etc/ssl/certs/ca-certificates.crt", postgres.Environment["REQUESTS_CA_BUNDLE"])
+	assert.Equal(t, "/etc/pki/tls/certs/ca-bundle.crt", trustFile.Services["mysql"].Volumes[0].Target)
+
+	api := trustFile.Services["api"]
+	require.Len(t, api.Volumes, 2)
+	assert.Equal(t, keystore, api.Volumes[1].Source)
+	assert.Equal(t, "/opt/java/openjdk/lib/security/cacerts", api.Volumes[1].Target)
+	assert.NotContains(t, api.Environment, "NODE_EXTRA_CA_CERTS")
+
+	// The stores are removed with the setting
+	require.NoError(t, GenerateComposeFiles(context.Background(), TestProjectName, TestEnvironmentLocal, []string{"api", "postgres"}, ComposeOptions{}))
+	assert.NoFileExists(t, compose.TrustFile())
+	assert.NoDirExists(t, filepath.Join(constants.DevStackDir, constants.CertsDir))
+
+	options.Trust.CABundles = []string{"certs/missing.pem"}
+	assert.ErrorContains(t, GenerateComposeFiles(context.Background(), TestProjectName, TestEnvironmentLocal, []string{"postgres"}, options), "missing.pem")
+}
+
+func TestGenerateComposeFiles_Grafana(t *testing.T) {
+	handler := NewInitHandler()
+	cleanup := setupTestDir(t)
+	defer cleanup()
+	require.NoError(t, handler.createDirectoryStructure())
+
+	grafanaDir := filepath.Join(constants.DevStackDir, constants.GrafanaDir)
+	require.NoError(t, GenerateComposeFiles(context.Background(), TestProjectName, TestEnvironmentLocal, []string{"prometheus", "jaeger", "grafana"}, ComposeOptions{}))
+
+	datasources, err := os.ReadFile(filepath.Join(grafanaDir, compose.GrafanaDatasourcesFileName))
+	require.NoError(t, err)
+	assert.Contains(t, string(datasources), "url: http://prometheus:9090")
+	assert.Contains(t, string(datasources), "url: http://jaeger:16686")
+	assert.FileExists(t, filepath.Join(grafanaDir, compose.GrafanaProvidersFileName))
+	assert.FileExists(t, filepath.Join(grafanaDir, compose.GrafanaDashboardsDir, "prometheus.json"))
+	assert.FileExists(t, filepath.Join(grafanaDir, compose.GrafanaDashboardsDir, "jaeger.json"))
+
+	data, err := os.ReadFile(filepath.Join(constants.DevStackDir, constants.DockerComposeFileName))
+	require.NoError(t, err)
+	var composeFile struct {
+		Services map[string]struct {
+			Volumes []string `yaml:"volumes"`
+		} `yaml:"services"`
+	}
+	require.NoError(t, yaml.Unmarshal(data, &composeFile))
+	assert.Equal(t, []string{"./grafana:/etc/grafana/provisioning:ro", TestProjectName + "-grafana-data:/var/lib/grafana"}, composeFile.Services["grafana"].Volumes)
+
+	// The dashboards of services leaving the stack are removed
+	require.NoError(t, GenerateComposeFiles(context.Background(), TestProjectName, TestEnvironmentLocal, []string{"prometheus", "grafana"}, ComposeOptions{}))
+	assert.NoFileExists(t, filepath.Join(grafanaDir, compose.GrafanaDashboardsDir, "jaeger.json"))
+	datasources, err = os.ReadFile(filepath.Join(grafanaDir, compose.GrafanaDatasourcesFileName))
+	require.NoError(t, err)
+	assert.Contains(t, string(datasources), "deleteDatasources:\n  - name: Jaeger")
+}
+
+func TestGenerateComposeFiles_LogCollector(t *testing.T) {
+	handler := NewInitHandler()
+	cleanup := setupTestDir(t)
+	defer cleanup()
+	require.NoError(t, handler.createDirectoryStructure())
+
+	type logging struct {
+		Driver  string            `yaml:"driver"`
+		Options map[string]string `yaml:"options"`
+	}
+	composeLogging := func(services []string) map[string]*logging {
+		require.NoError(t, GenerateComposeFiles(context.Background(), TestProjectName, TestEnvironmentLocal, services, ComposeOptions{
+			Logging: pkgTypes.LoggingConfig{MaxSize: "50m"},
+		}))
+		data, err := os.ReadFile(filepath.Join(constants.DevStackDir, constants.DockerComposeFileName))
+		require.NoError(t, err)
+		var composeFile struct {
+			Services map[string]struct {
+				Logging *logging `yaml:"logging"`
+			} `yaml:"services"`
+		}
+		require.NoError(t, yaml.Unmarshal(data, &composeFile))
+		result := make(map[string]*logging)
+		for name, service := range composeFile.Services {
+			result[name] = service.Logging
+		}
+		return result
+	}
+
+	want := &logging{Driver: "json-file", Options: map[string]string{"max-size": "50m", "max-file": "3", "labels": "com.docker.compose.service"}}
+	assert.Equal(t, map[string]*logging{"redis": want, "log-collector": want}, composeLogging([]string{"redis", "log-collector"}))
+	assert.FileExists(t, filepath.Join(constants.DevStackDir, constants.LogsDir, compose.LogCollectorConfigFileName))
+
+	// Without the collector Docker's defaults are kept
+	assert.Equal(t, map[string]*logging{"redis": nil}, composeLogging([]string{"redis"}))
+}
+
+func TestResolveDependsOn(t *testing.T) {
+	worker := &types.ServiceConfig{}
+	worker.Docker.DependsOn = types.DependsOn{
+		{Name: "cache"},
+		{Name: "migrate", Condition: constants.ConditionServiceCompleted},
+		{Name: "missing"},
+	}
+	cache := &types.ServiceConfig{}
+	migrate := &types.ServiceConfig{}
+	migrate.Docker.HealthCheck.Test = []string{"CMD", "true"}
+
+	dependsOn, err := resolveDependsOn([]struct {
+		Name   string
+		Config *types.ServiceConfig
+	}{
+		{Name: "worker", Config: worker},
+		{Name: "cache", Config: cache},
+		{Name: "migrate", Config: migrate},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, types.DependsOn{
+		{Name: "cache", Condition: constants.ConditionServiceStarted},
+		{Name: "migrate", Condition: constants.ConditionServiceCompleted},
+	}, dependsOn["worker"])
+	assert.NotContains(t, dependsOn, "cache")
+}
+
+func TestGenerateComposeFiles_Proxy(t *testing.T) {
+	handler := NewInitHandler()
+	cleanup := setupTestDir(t)
+	defer cleanup()
+
+	require.NoError(t, handler.createDirectoryStructure())
+
+	// Without mkcert on the PATH the routes fall back to HTTP
+	t.Setenv("PATH", t.TempDir())
+	services := []string{constants.ServiceProxy, "jaeger", TestServicePostgres}
+	err := GenerateComposeFiles(context.Background(), TestProjectName, TestEnvironmentLocal, services, ComposeOptions{
+		Proxy: pkgTypes.ProxyConfig{Domain: "demo.test", TLS: true},
+	})
+	require.NoError(t, err)
+
+	data, err := os.ReadFile(filepath.Join(constants.DevStackDir, constants.DockerComposeFileName))
+	require.NoError(t, err)
+
+	var compose struct {
+		Services map[string]struct {
+			Labels  map[string]string `yaml:"labels"`
+			Volumes []string          `yaml:"volumes"`
+		} `yaml:"services"`
+	}
+	require.NoError(t, yaml.Unmarshal(data, &compose))
+
+	router := "traefik.http.routers." + TestProjectName + "-jaeger"
+	jaeger := compose.Services["jaeger"].Labels
+	assert.Equal(t, "Host(`jaeger.demo.test`)", jaeger[router+".rule"])
+	assert.Equal(t, "16686", jaeger["traefik.http.services."+TestProjectName+"-jaeger.loadbalancer.server.port"])
+	assert.NotContains(t, jaeger, router+"-secure.tls")
+	assert.Empty(t, compose.Services[TestServicePostgres].Labels)
+	assert.Empty(t, compose.Services[constants.ServiceProxy].Labels)
+	assert.Contains(t, compose.Services[constants.ServiceProxy].Volumes, "./proxy:/etc/traefik/proxy:ro")
+
+	assert.FileExists(t, filepath.Join(constants.DevStackDir, constants.ProxyDir, "dynamic.yml"))
+}
+
+func TestGenerateComposeFiles_NoProxy(t *testing.T) {
+	handler := NewInitHandler()
+	cleanup := setupTestDir(t)
+	defer cleanup()
+
+	require.NoError(t, handler.createDirectoryStructure())
+
+	err := GenerateComposeFiles(context.Background(), TestProjectName, TestEnvironmentLocal, []string{"jaeger"}, ComposeOptions{})
+	require.NoError(t, err)
+
+	data, err := os.ReadFile(filepath.Join(constants.DevStackDir, constants.DockerComposeFileName))
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "traefik")
+	assert.NoDirExists(t, filepath.Join(constants.DevStackDir, constants.ProxyDir))
+}
+
+func TestGenerateComposeFiles_Hostnames(t *testing.T) {
+	handler := NewInitHandler()
+	cleanup := setupTestDir(t)
+	defer cleanup()
+
+	require.NoError(t, handler.createDirectoryStructure())
+
+	err := GenerateComposeFiles(context.Background(), TestProjectName, TestEnvironmentLocal, []string{TestServicePostgres}, ComposeOptions{
+		Hostnames: pkgTypes.HostnamesConfig{Enabled: true},
+	})
+	require.NoError(t, err)
+
+	data, err := os.ReadFile(filepath.Join(constants.DevStackDir, constants.EnvGeneratedFileName))
+	require.NoError(t, err)
+	hostname := TestServicePostgres + "." + TestProjectName + ".test"
+	assert.Contains(t, string(data), "POSTGRES_HOST="+hostname+"\n")
+	assert.Contains(t, string(data), "@"+hostname+":${POSTGRES_PORT:-5432}")
+	assert.NotContains(t, string(data), "localhost")
+}
@@ -1,16 +1,29 @@
 package init
 
 import (
+	"context"
 	"fmt"
+	"maps"
 	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"sort"
+	"strconv"
 	"strings"
 	"text/template"
 	"time"
 
 	"github.com/isaacgarza/dev-stack/internal/config"
+	"github.com/isaacgarza/dev-stack/internal/core/hostnames"
+	"github.com/isaacgarza/dev-stack/internal/core/registry"
+	"github.com/isaacgarza/dev-stack/internal/core/tracing"
 	"github.com/isaacgarza/dev-stack/internal/pkg/cli/handlers/utils"
 	"github.com/isaacgarza/dev-stack/internal/pkg/cli/types"
+	"github.com/isaacgarza/dev-stack/internal/pkg/compose"
 	pkgConfig "github.com/isaacgarza/dev-stack/internal/pkg/config"
+	"github.com/isaacgarza/dev-stack/internal/pkg/constants"
+	pkgTypes "github.com/isaacgarza/dev-stack/internal/pkg/types"
 	"github.com/isaacgarza/dev-stack/internal/pkg/ui"
 )
 
@@ -19,8 +32,17 @@ func (h *InitHandler) generateConfig(name, environment string, services []string
 	return pkgConfig.GenerateConfig(name, environment, services, validation, advanced), nil
 }
 
+// GenerateComposeFiles regenerates dev-stack/docker-compose.yml and the env
+// files for the given services, as init does for a new project
+func GenerateComposeFiles(ctx context.Context, projectName, environment string, services []string, options ComposeOptions) error {
+	handler := NewInitHandler()
+	handler.compose = options
+	advanced := map[string]bool{constants.AdvancedEnvFiles: options.EnvFiles}
+	return handler.generateInitialComposeFiles(ctx, services, projectName, environment, nil, advanced)
+}
+
 // generateInitialComposeFiles generates initial compose files during init
-func (h *InitHandler) generateInitialComposeFiles(services []string, projectName, environment string, validation, advanced map[string]bool) error {
+func (h *InitHandler) generateInitialComposeFiles(ctx context.Context, services []string, projectName, environment string, validation, advanced map[string]bool) error {
 	// Create a temporary project config structure
 	projectConfig := struct {
 		Project struct {
@@ -45,13 +67,22 @@ func (h *InitHandler) generateInitialComposeFiles(services []string, projectName
 		},
 	}
 
+	h.compose.EnvFiles = advanced[constants.AdvancedEnvFiles]
+
 	// Generate .env.generated
 	if err := h.generateInitEnvFile(services, &projectConfig); err != nil {
 		return fmt.Errorf("failed to generate .env file: %w", err)
 	}
 
+	// Generate per-service env fragments referenced by env_file
+	if h.compose.EnvFiles {
+		if err := h.generateInitEnvFragments(services); err != nil {
+			return fmt.Errorf("failed to generate env fragments: %w", err)
+		}
+	}
+
 	// Generate docker-compose.yml
-	if err := h.generateInitDockerCompose(services, &projectConfig); err != nil {
+	if err := h.generateInitDockerCompose(ctx, services, &projectConfig); err != nil {
 		return fmt.Errorf("failed to generate docker-compose.yml: %w", err)
 	}
 
@@ -112,6 +143,12 @@ func (h *InitHandler) generateInitEnvFile(services []string, projectConfig inter
 			ui.Warning("Failed to load config for %s: %v", serviceName, err)
 			continue
 		}
+		if h.compose.Hostnames.Enabled {
+			hostname := hostnames.Hostname(serviceName, pc.Project.Name, h.compose.Hostnames.Domain)
+			for key, value := range serviceConfig.Environment {
+				serviceConfig.Environment[key] = hostnames.Rewrite(value, hostname)
+			}
+		}
 		templateServices = append(templateServices, struct {
 			Name   string
 			Config *types.ServiceConfig
@@ -145,8 +182,179 @@ func (h *InitHandler) generateInitEnvFile(services []string, projectConfig inter
 	return os.WriteFile("dev-stack/.env.generated", []byte(result.String()), 0644)
 }
 
+// generateInitEnvFragments writes one env file per compose service under
+// dev-stack/env so docker-compose.yml can reference values via env_file
+func (h *InitHandler) generateInitEnvFragments(services []string) error {
+	envDir := filepath.Join(constants.DevStackDir, constants.EnvDir)
+	if err := os.MkdirAll(envDir, 0700); err != nil {
+		return fmt.Errorf("failed to create env directory: %w", err)
+	}
+
+	proxyEnv := h.httpProxyEnv(services)
+	for _, serviceName := range services {
+		serviceConfig, err := utils.NewServiceUtils().LoadServiceConfig(serviceName)
+		if err != nil {
+			ui.Warning("Failed to load config for %s: %v", serviceName, err)
+			continue
+		}
+		h.resolveTracingEnv(serviceName, serviceConfig, services)
+		resolveHTTPProxy(serviceConfig, proxyEnv)
+
+		fragments := map[string][]string{}
+		if len(serviceConfig.Docker.Services) > 0 {
+			for name, svc := range serviceConfig.Docker.Services {
+				fragments[name] = svc.Environment
+			}
+		} else {
+			fragments[serviceName] = serviceConfig.Docker.Environment
+		}
+
+		for name, environment := range fragments {
+			var content strings.Builder
+			content.WriteString(fmt.Sprintf("# Generated by dev-stack for %s\n", name))
+			for _, entry := range environment {
+				content.WriteString(entry + "\n")
+			}
+
+			path := filepath.Join(envDir, name+".env")
+			if err := os.WriteFile(path, []byte(content.String()), 0600); err != nil {
+				return fmt.Errorf("failed to write env fragment %s: %w", path, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// resolveImages applies the pinned version of a service to its image and
+// rewrites its image references to the configured registry mirrors
+func (h *InitHandler) resolveImages(serviceName string, serviceConfig *types.ServiceConfig) {
+	if image := serviceConfig.Defaults.Image; image != "" {
+		if version := h.compose.Versions[serviceName]; version != "" {
+			image = registry.WithTag(image, version)
+		}
+		serviceConfig.Defaults.Image = registry.Rewrite(image, h.compose.RegistryMirrors)
+	}
+	for name, svc := range serviceConfig.Docker.Services {
+		if svc.Image != "" {
+			svc.Image = registry.Rewrite(svc.Image, h.compose.RegistryMirrors)
+			serviceConfig.Docker.Services[name] = svc
+		}
+	}
+}
+
+// resolveBuildArgs adds the build arguments the project configures for a
+// service to each image it builds
+func (h *InitHandler) resolveBuildArgs(serviceName string, serviceConfig *types.ServiceConfig) {
+	args := h.compose.BuildArgs[serviceName]
+	if len(args) == 0 {
+		return
+	}
+	if serviceConfig.Docker.Build.Context != "" {
+		serviceConfig.Docker.Build.Args = mergeBuildArgs(serviceConfig.Docker.Build.Args, args)
+	}
+	for name, svc := range serviceConfig.Docker.Services {
+		if svc.Build.Context != "" {
+			svc.Build.Args = mergeBuildArgs(svc.Build.Args, args)
+			serviceConfig.Docker.Services[name] = svc
+		}
+	}
+}
+
+// resolveTracingEnv adds the OpenTelemetry exporter variables to each
+// container of a service that builds its image, when the project asks for
+// them and the stack runs Jaeger. Variables the definition sets are kept.
+func (h *InitHandler) resolveTracingEnv(serviceName string, serviceConfig *types.ServiceConfig, services []string) {
+	if !h.compose.Tracing.Injects(serviceName) || !slices.Contains(services, tracing.ServiceName) {
+		return
+	}
+	if serviceConfig.Docker.Build.Context != "" {
+		serviceConfig.Docker.Environment = withEnv(serviceConfig.Docker.Environment, tracing.ExporterEnv(serviceName))
+	}
+	for name, svc := range serviceConfig.Docker.Services {
+		if svc.Build.Context != "" {
+			svc.Environment = withEnv(svc.Environment, tracing.ExporterEnv(name))
+			serviceConfig.Docker.Services[name] = svc
+		}
+	}
+}
+
+// resolveCommand fills in the placeholders of the command of a service:
+// {{.ProjectName}}, the compose project, and {{.Port}}, the default port of
+// the service
+func resolveCommand(serviceConfig *types.ServiceConfig, projectName string) error {
+	data := struct {
+		ProjectName string
+		Port        int
+	}{projectName, serviceConfig.Defaults.Port}
+	render := func(value string) (string, error) {
+		if !strings.Contains(value, "{{") {
+			return value, nil
+		}
+		tmpl, err := template.New("command").Option("missingkey=error").Parse(value)
+		if err != nil {
+			return "", fmt.Errorf("invalid command of %s: %w", serviceConfig.Name, err)
+		}
+		var out strings.Builder
+		if err := tmpl.Execute(&out, data); err != nil {
+			return "", fmt.Errorf("invalid command of %s: %w", serviceConfig.Name, err)
+		}
+		return out.String(), nil
+	}
+
+	switch command := serviceConfig.Docker.Command.(type) {
+	case string:
+		resolved, err := render(command)
+		if err != nil {
+			return err
+		}
+		serviceConfig.Docker.Command = resolved
+	case []interface{}:
+		resolved := make([]interface{}, len(command))
+		for i, item := range command {
+			value, ok := item.(string)
+			if !ok {
+				resolved[i] = item
+				continue
+			}
+			var err error
+			if resolved[i], err = render(value); err != nil {
+				return err
+			}
+		}
+		serviceConfig.Docker.Command = resolved
+	}
+	return nil
+}
+
+// withEnv appends variables to environment entries, skipping those already
+// set
+func withEnv(environment []string, env map[string]string) []string {
+	set := make(map[string]bool, len(environment))
+	for _, entry := range environment {
+		name, _, _ := strings.Cut(entry, "=")
+		set[name] = true
+	}
+	merged := slices.Clone(environment)
+	for _, name := range slices.Sorted(maps.Keys(env)) {
+		if !set[name] {
+			merged = append(merged, name+"="+env[name])
+		}
+	}
+	return merged
+}
+
+// mergeBuildArgs returns the build arguments of a definition with those of
+// the project configuration over them
+func mergeBuildArgs(definition, project map[string]string) map[string]string {
+	merged := make(map[string]string, len(definition)+len(project))
+	maps.Copy(merged, definition)
+	maps.Copy(merged, project)
+	return merged
+}
+
 // generateInitDockerCompose generates docker-compose.yml during init using template
-func (h *InitHandler) generateInitDockerCompose(services []string, projectConfig interface{}) error {
+func (h *InitHandler) generateInitDockerCompose(ctx context.Context, services []string, projectConfig interface{}) error {
 	pc := projectConfig.(*struct {
 		Project struct {
 			Name        string
@@ -189,11 +397,28 @@ func (h *InitHandler) generateInitDockerCompose(services []string, projectConfig
 				if i > 0 {
 					result += ", "
 				}
-				result += fmt.Sprintf(`"%s"`, item)
+				result += strconv.Quote(item)
 			}
 			result += "]"
 			return result
 		},
+		// toYamlCommand renders a command given as a string or a list as a
+		// single-line YAML value; multi-line strings keep their newlines as
+		// escapes
+		"toYamlCommand": func(command interface{}) string {
+			switch value := command.(type) {
+			case string:
+				return strconv.Quote(strings.TrimSpace(value))
+			case []interface{}:
+				items := make([]string, 0, len(value))
+				for _, item := range value {
+					items = append(items, strconv.Quote(fmt.Sprint(item)))
+				}
+				return "[" + strings.Join(items, ", ") + "]"
+			default:
+				return fmt.Sprint(value)
+			}
+		},
 	}).Parse(string(templateContent))
 	if err != nil {
 		return fmt.Errorf("failed to parse docker-compose template: %w", err)
@@ -205,6 +430,8 @@ func (h *InitHandler) generateInitDockerCompose(services []string, projectConfig
 		Config *types.ServiceConfig
 	}
 	var volumes []string
+	secretSet := make(map[string]bool)
+	proxyEnv := h.httpProxyEnv(services)
 
 	for _, serviceName := range services {
 		serviceConfig, err := utils.NewServiceUtils().LoadServiceConfig(serviceName)
@@ -213,6 +440,20 @@ func (h *InitHandler) generateInitDockerCompose(services []string, projectConfig
 			continue
 		}
 
+		h.resolveImages(serviceName, serviceConfig)
+		h.resolveBuildArgs(serviceName, serviceConfig)
+		h.resolveTracingEnv(serviceName, serviceConfig, services)
+		resolveHTTPProxy(serviceConfig, proxyEnv)
+		if err := resolveCommand(serviceConfig, pc.Project.Name); err != nil {
+			return err
+		}
+
+		// Services such as localstack-s3 only configure their dependency and
+		// have no container of their own
+		if !hasContainer(serviceConfig) {
+			continue
+		}
+
 		templateServices = append(templateServices, struct {
 			Name   string
 			Config *types.ServiceConfig
@@ -226,7 +467,60 @@ func (h *InitHandler) generateInitDockerCompose(services []string, projectConfig
 			volumeName := fmt.Sprintf("%s-%s", pc.Project.Name, volume.Name)
 			volumes = append(volumes, volumeName)
 		}
+
+		// Collect BuildKit secrets
+		for _, secret := range serviceConfig.Docker.Build.Secrets {
+			secretSet[secret] = true
+		}
+		for _, svc := range serviceConfig.Docker.Services {
+			for _, secret := range svc.Build.Secrets {
+				secretSet[secret] = true
+			}
+		}
+	}
+
+	dependsOn, err := resolveDependsOn(templateServices)
+	if err != nil {
+		return err
+	}
+
+	if err := h.validateResources(); err != nil {
+		return err
+	}
+	resources := make(map[string]*pkgTypes.ResourceLimits)
+	for _, svc := range templateServices {
+		for name, limits := range containerResources(svc.Name, svc.Config, h.compose.Resources[svc.Name]) {
+			resources[name] = &limits
+		}
+	}
+	mounts, err := h.resolveMounts(templateServices)
+	if err != nil {
+		return err
 	}
+	trustStores, err := h.resolveTrust(templateServices)
+	if err != nil {
+		return err
+	}
+	platforms := h.resolvePlatforms(templateServices, runtime.GOARCH)
+
+	labels, err := h.generateProxyFiles(ctx, pc.Project.Name, templateServices)
+	if err != nil {
+		return err
+	}
+	if err := h.generateGrafanaFiles(services); err != nil {
+		return err
+	}
+	logging, err := h.generateLogCollectorFiles(pc.Project.Name, services)
+	if err != nil {
+		return err
+	}
+	networks, isolationNetworks := h.resolveNetworks(pc.Project.Name, templateServices, dependsOn, labels)
+
+	secrets := make([]string, 0, len(secretSet))
+	for secret := range secretSet {
+		secrets = append(secrets, secret)
+	}
+	sort.Strings(secrets)
 
 	data := struct {
 		ProjectName string
@@ -234,11 +528,32 @@ func (h *InitHandler) generateInitDockerCompose(services []string, projectConfig
 			Name   string
 			Config *types.ServiceConfig
 		}
-		Volumes []string
+		DependsOn map[string]types.DependsOn
+		Resources map[string]*pkgTypes.ResourceLimits
+		Platforms map[string]string
+		Labels    map[string]map[string]string
+		Networks  map[string][]string
+		DNS       map[string]*pkgTypes.DNSConfig
+		// IsolationNetworks are the networks of the isolated network mode
+		IsolationNetworks []string
+		Volumes           []string
+		Secrets           []string
+		EnvFiles          bool
+		Logging           *pkgTypes.LoggingConfig
 	}{
-		ProjectName: pc.Project.Name,
-		Services:    templateServices,
-		Volumes:     volumes,
+		ProjectName:       pc.Project.Name,
+		Services:          templateServices,
+		DependsOn:         dependsOn,
+		Resources:         resources,
+		Platforms:         platforms,
+		Labels:            labels,
+		Networks:          networks,
+		DNS:               h.resolveDNS(templateServices),
+		IsolationNetworks: isolationNetworks,
+		Volumes:           volumes,
+		Secrets:           secrets,
+		EnvFiles:          h.compose.EnvFiles,
+		Logging:           logging,
 	}
 
 	// Execute template
@@ -247,5 +562,259 @@ func (h *InitHandler) generateInitDockerCompose(services []string, projectConfig
 		return fmt.Errorf("failed to execute docker-compose template: %w", err)
 	}
 
-	return os.WriteFile("dev-stack/docker-compose.yml", []byte(result.String()), 0644)
+	if err := compose.Validate([]byte(result.String())); err != nil {
+		return fmt.Errorf("generated docker-compose.yml does not follow the Compose Specification: %w", err)
+	}
+
+	if err := os.WriteFile("dev-stack/docker-compose.yml", []byte(result.String()), 0644); err != nil {
+		return err
+	}
+	if err := writeMountsFile(mounts); err != nil {
+		return err
+	}
+	if err := writeSecurityFile(h.resolveSecurity(templateServices)); err != nil {
+		return err
+	}
+	if err := writeTrustFile(trustStores); err != nil {
+		return err
+	}
+	return h.generateProfileComposeFiles(templateServices)
+}
+
+// resolveMounts returns the bind mounts of each container of the stack,
+// with their sources expanded to host paths. Missing host paths are created
+// for mounts that set create and are an error otherwise.
+func (h *InitHandler) resolveMounts(templateServices []struct {
+	Name   string
+	Config *types.ServiceConfig
+}) (map[string][]pkgTypes.BindMount, error) {
+	mounts := make(map[string][]pkgTypes.BindMount)
+	if len(h.compose.Mounts) == 0 {
+		return mounts, nil
+	}
+	projectRoot, err := os.Getwd()
+	if err != nil {
+		return nil, fmt.Errorf("failed to find the project root: %w", err)
+	}
+	home, _ := os.UserHomeDir()
+
+	for _, svc := range templateServices {
+		var resolved []pkgTypes.BindMount
+		for i, mount := range h.compose.Mounts[svc.Name] {
+			if err := mount.Validate(); err != nil {
+				return nil, fmt.Errorf("services.%s.mounts[%d]: %w", svc.Name, i, err)
+			}
+			mount.Source = compose.HostPath(mount.Source, projectRoot, home)
+			if err := ensureHostPath(mount); err != nil {
+				return nil, fmt.Errorf("services.%s.mounts[%d]: %w", svc.Name, i, err)
+			}
+			resolved = append(resolved, mount)
+		}
+		if len(resolved) == 0 {
+			continue
+		}
+		if len(svc.Config.Docker.Services) == 0 {
+			mounts[svc.Name] = resolved
+		}
+		for name := range svc.Config.Docker.Services {
+			mounts[name] = resolved
+		}
+	}
+	return mounts, nil
+}
+
+// ensureHostPath checks that the source of a bind mount exists, creating it
+// as a directory when the mount asks for it
+func ensureHostPath(mount pkgTypes.BindMount) error {
+	_, err := os.Stat(mount.Source)
+	if err == nil {
+		return nil
+	}
+	if !os.IsNotExist(err) {
+		return fmt.Errorf("cannot read host path %s: %w", mount.Source, err)
+	}
+	if !mount.Create {
+		return fmt.Errorf("host path %s does not exist; create it or set create: true", mount.Source)
+	}
+	if err := os.MkdirAll(mount.Source, 0755); err != nil {
+		return fmt.Errorf("failed to create host path %s: %w", mount.Source, err)
+	}
+	ui.Info("Created %s for a bind mount", mount.Source)
+	return nil
+}
+
+// writeMountsFile writes the compose file binding the host paths of the
+// project into its containers, or removes it when there are none
+func writeMountsFile(mounts map[string][]pkgTypes.BindMount) error {
+	if len(mounts) == 0 {
+		if err := os.Remove(compose.MountsFile()); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+	content, err := compose.MountsOverride(mounts)
+	if err != nil {
+		return fmt.Errorf("failed to render bind mounts: %w", err)
+	}
+	header := "# Bind mounts of services.<name>.mounts, generated from dev-stack-config.yml\n"
+	return os.WriteFile(compose.MountsFile(), append([]byte(header), content...), 0644)
+}
+
+// validateResources checks the resource limits of services and profiles
+func (h *InitHandler) validateResources() error {
+	for name, limits := range h.compose.Resources {
+		if err := limits.Validate(); err != nil {
+			return fmt.Errorf("services.%s.resources: %w", name, err)
+		}
+	}
+	for name, profile := range h.compose.Profiles {
+		if err := profile.Resources.Validate(); err != nil {
+			return fmt.Errorf("profiles.%s.resources: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// generateProfileComposeFiles writes a compose file with the resource limits
+// of each profile that sets any, and removes those of profiles that no
+// longer do. Service overrides take precedence over the profile's limits.
+func (h *InitHandler) generateProfileComposeFiles(templateServices []struct {
+	Name   string
+	Config *types.ServiceConfig
+}) error {
+	existing, err := compose.ProfileFiles()
+	if err != nil {
+		return err
+	}
+
+	for _, profileName := range slices.Sorted(maps.Keys(h.compose.Profiles)) {
+		profile := h.compose.Profiles[profileName]
+		if profile.Resources.IsZero() {
+			continue
+		}
+
+		resources := make(map[string]pkgTypes.ResourceLimits)
+		for _, svc := range templateServices {
+			if len(profile.Services) > 0 && !slices.Contains(profile.Services, svc.Name) {
+				continue
+			}
+			maps.Copy(resources, containerResources(svc.Name, svc.Config, profile.Resources, h.compose.Resources[svc.Name]))
+		}
+		if len(resources) == 0 {
+			continue
+		}
+
+		content, err := compose.ResourceFile(resources)
+		if err != nil {
+			return fmt.Errorf("failed to render resource limits of profile %s: %w", profileName, err)
+		}
+		header := fmt.Sprintf("# Resource limits of the %s profile, generated from dev-stack-config.yml\n", profileName)
+		path := compose.ProfileFile(profileName)
+		if err := os.WriteFile(path, append([]byte(header), content...), 0644); err != nil {
+			return err
+		}
+		delete(existing, profileName)
+	}
+
+	// Remove the files of profiles that no longer set resource limits
+	for _, path := range existing {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// containerResources returns the resource limits of each container of a
+// service: the memory_limit of its definition with the given limits applied
+// over it in order. Containers without limits are left out.
+func containerResources(serviceName string, serviceConfig *types.ServiceConfig, layers ...pkgTypes.ResourceLimits) map[string]pkgTypes.ResourceLimits {
+	defaults := map[string]string{}
+	if len(serviceConfig.Docker.Services) > 0 {
+		for name, svc := range serviceConfig.Docker.Services {
+			defaults[name] = svc.MemoryLimit
+		}
+	} else {
+		defaults[serviceName] = serviceConfig.Docker.MemoryLimit
+	}
+
+	resources := make(map[string]pkgTypes.ResourceLimits)
+	for name, memory := range defaults {
+		limits := pkgTypes.ResourceLimits{Memory: memory}
+		for _, layer := range layers {
+			limits = limits.Merge(layer)
+		}
+		if !limits.IsZero() {
+			resources[name] = limits
+		}
+	}
+	return resources
+}
+
+// hasContainer reports whether a service definition produces a compose
+// service
+func hasContainer(serviceConfig *types.ServiceConfig) bool {
+	docker := serviceConfig.Docker
+	return serviceConfig.Defaults.Image != "" || docker.Build.Context != "" ||
+		docker.Extends.Service != "" || len(docker.Services) > 0
+}
+
+// resolveDependsOn works out the depends_on entries of every compose
+// service. Besides the entries a definition declares, a service depends on
+// the required dependencies of its service definition. Dependencies without
+// a condition wait for a passing health check when they have one, and for
+// the container to start otherwise. Dependencies that are not part of the
+// stack are dropped.
+func resolveDependsOn(templateServices []struct {
+	Name   string
+	Config *types.ServiceConfig
+}) (map[string]types.DependsOn, error) {
+	required, err := utils.NewServiceUtils().LoadAllServiceDependencies()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load service dependencies: %w", err)
+	}
+
+	healthChecked := map[string]bool{}
+	declared := map[string]types.DependsOn{}
+	for _, svc := range templateServices {
+		if len(svc.Config.Docker.Services) > 0 {
+			for name, dockerService := range svc.Config.Docker.Services {
+				healthChecked[name] = len(dockerService.HealthCheck.Test) > 0
+				declared[name] = dockerService.DependsOn
+			}
+			continue
+		}
+
+		healthChecked[svc.Name] = len(svc.Config.Docker.HealthCheck.Test) > 0
+		dependencies := svc.Config.Docker.DependsOn
+		for _, name := range required[svc.Name] {
+			if !slices.ContainsFunc(dependencies, func(d types.Dependency) bool { return d.Name == name }) {
+				dependencies = append(dependencies, types.Dependency{Name: name})
+			}
+		}
+		declared[svc.Name] = dependencies
+	}
+
+	result := make(map[string]types.DependsOn, len(declared))
+	for name, dependencies := range declared {
+		var resolved types.DependsOn
+		for _, dependency := range dependencies {
+			hasHealthCheck, ok := healthChecked[dependency.Name]
+			if !ok {
+				ui.Warning("%s depends on %s, which is not part of the stack", name, dependency.Name)
+				continue
+			}
+			if dependency.Condition == "" {
+				dependency.Condition = constants.ConditionServiceStarted
+				if hasHealthCheck {
+					dependency.Condition = constants.ConditionServiceHealthy
+				}
+			}
+			resolved = append(resolved, dependency)
+		}
+		if len(resolved) > 0 {
+			result[name] = resolved
+		}
+	}
+	return result, nil
 }
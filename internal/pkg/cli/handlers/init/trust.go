@@ -0,0 +1,146 @@
+package init
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/isaacgarza/dev-stack/internal/core/trust"
+	"github.com/isaacgarza/dev-stack/internal/pkg/cli/types"
+	"github.com/isaacgarza/dev-stack/internal/pkg/compose"
+)
+
+// Files of the trust stores, under dev-stack/certs
+const (
+	trustBundleFile   = "ca-bundle.pem"
+	trustKeystoreFile = "cacerts"
+)
+
+// resolveTrust writes the trust stores of the project, the public roots of
+// the host or trust.system_bundle followed by the authorities of
+// trust.ca_bundles, and returns how each container of the stack is given
+// them, keyed by container name. Exempt services are left out. The Java
+// keystore is only written when a service runs a JDK whose keystore it
+// knows.
+func (h *InitHandler) resolveTrust(templateServices []struct {
+	Name   string
+	Config *types.ServiceConfig
+}) (map[string]compose.ContainerTrust, error) {
+	containers := make(map[string]compose.ContainerTrust)
+	policy := h.compose.Trust
+	if !policy.Enabled() {
+		return containers, nil
+	}
+
+	projectRoot, err := os.Getwd()
+	if err != nil {
+		return nil, fmt.Errorf("failed to find the project root: %w", err)
+	}
+	home, _ := os.UserHomeDir()
+	system := policy.SystemBundle
+	if system == "" {
+		if system = trust.SystemBundle(); system == "" {
+			return nil, errors.New("trust.ca_bundles: no bundle of public roots found on this host; set trust.system_bundle to one")
+		}
+	}
+	paths := []string{compose.HostPath(system, projectRoot, home)}
+	for _, path := range policy.CABundles {
+		paths = append(paths, compose.HostPath(path, projectRoot, home))
+	}
+	certs, err := trust.ReadBundles(paths...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the certificate bundles of trust: %w", err)
+	}
+
+	dir := filepath.Join(projectRoot, compose.TrustStoresDir())
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create %s: %w", dir, err)
+	}
+	bundle := filepath.Join(dir, trustBundleFile)
+	if err := os.WriteFile(bundle, trust.EncodePEM(certs), 0644); err != nil {
+		return nil, err
+	}
+	keystore := filepath.Join(dir, trustKeystoreFile)
+
+	needsKeystore := false
+	for _, svc := range templateServices {
+		if !policy.Applies(svc.Name) {
+			continue
+		}
+
+		type container struct {
+			image       string
+			environment []string
+		}
+		serviceContainers := map[string]container{}
+		if len(svc.Config.Docker.Services) == 0 {
+			serviceContainers[svc.Name] = container{svc.Config.Defaults.Image, svc.Config.Docker.Environment}
+		}
+		for name, definition := range svc.Config.Docker.Services {
+			serviceContainers[name] = container{definition.Image, definition.Environment}
+		}
+
+		for name, c := range serviceContainers {
+			store := svc.Config.Trust.Store
+			if store == "" {
+				store = trust.GuessStore(c.image)
+			}
+			storePath, ok := trust.StorePath(store)
+			if !ok {
+				return nil, fmt.Errorf("service %s: unknown trust.store %q: must be one of %s, %s or %s", svc.Name, store, trust.StoreDebian, trust.StoreAlpine, trust.StoreRHEL)
+			}
+
+			settings := compose.ContainerTrust{
+				Stores:      map[string]string{storePath: bundle},
+				Environment: unsetEnv(trust.Env(storePath), c.environment),
+			}
+			if cacerts := svc.Config.Trust.Cacerts; cacerts != "" {
+				settings.Stores[cacerts] = keystore
+				needsKeystore = true
+			}
+			containers[name] = settings
+		}
+	}
+
+	if !needsKeystore {
+		if err := os.Remove(keystore); err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+		return containers, nil
+	}
+	if err := os.WriteFile(keystore, trust.EncodeJKS(certs, trust.KeystorePassword), 0644); err != nil {
+		return nil, err
+	}
+	return containers, nil
+}
+
+// unsetEnv returns the variables of env that environment entries don't set
+func unsetEnv(env map[string]string, environment []string) map[string]string {
+	unset := maps.Clone(env)
+	for _, entry := range environment {
+		name, _, _ := strings.Cut(entry, "=")
+		delete(unset, name)
+	}
+	return unset
+}
+
+// writeTrustFile writes the compose file mounting the trust stores of the
+// project into its containers, or removes it and the stores when there are
+// none
+func writeTrustFile(containers map[string]compose.ContainerTrust) error {
+	if len(containers) == 0 {
+		if err := os.Remove(compose.TrustFile()); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return os.RemoveAll(compose.TrustStoresDir())
+	}
+	content, err := compose.TrustOverride(containers)
+	if err != nil {
+		return fmt.Errorf("failed to render the trust stores: %w", err)
+	}
+	header := "# Trust stores of trust.ca_bundles, generated from dev-stack-config.yml\n"
+	return os.WriteFile(compose.TrustFile(), append([]byte(header), content...), 0644)
+}
@@ -3,8 +3,7 @@ package init
 import (
 	"fmt"
 	"os"
-	"path/filepath"
-	"strings"
+	"os/exec"
 
 	"github.com/isaacgarza/dev-stack/internal/pkg/cli/handlers/utils"
 	"github.com/isaacgarza/dev-stack/internal/pkg/ui"
@@ -119,32 +118,13 @@ func (h *InitHandler) findTemplateFile(candidates []string, templateType string)
 	return "", fmt.Errorf("no %s found in any of the expected locations", templateType)
 }
 
-// isCommandAvailable checks if a command is available in PATH
+// isCommandAvailable checks if a command is available in PATH. LookPath
+// also tries the PATHEXT extensions on Windows, where commands are .exe,
+// .cmd or .bat files.
 func (h *InitHandler) isCommandAvailable(command string) bool {
 	if command == "" {
 		return false
 	}
-
-	_, err := os.Stat(filepath.Join("/usr/bin", command))
-	if err == nil {
-		return true
-	}
-
-	_, err = os.Stat(filepath.Join("/usr/local/bin", command))
-	if err == nil {
-		return true
-	}
-
-	// Check PATH
-	path := os.Getenv("PATH")
-	for _, dir := range strings.Split(path, ":") {
-		if dir == "" {
-			continue
-		}
-		if _, err := os.Stat(filepath.Join(dir, command)); err == nil {
-			return true
-		}
-	}
-
-	return false
+	_, err := exec.LookPath(command)
+	return err == nil
 }
@@ -0,0 +1,103 @@
+package release
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/isaacgarza/dev-stack/internal/pkg/cli/types"
+	"github.com/isaacgarza/dev-stack/internal/pkg/constants"
+	"github.com/isaacgarza/dev-stack/internal/pkg/release"
+	"github.com/isaacgarza/dev-stack/internal/pkg/ui"
+	"github.com/isaacgarza/dev-stack/internal/pkg/utils"
+	"github.com/spf13/cobra"
+)
+
+// ManifestsHandler handles the release manifests command
+type ManifestsHandler struct{}
+
+// NewManifestsHandler creates a new release manifests handler
+func NewManifestsHandler() *ManifestsHandler {
+	return &ManifestsHandler{}
+}
+
+// Handle executes the release manifests command. It renders the Homebrew
+// formula, the Scoop manifest and the nfpm configs of the binaries in the
+// build directory and writes them under the output directory.
+func (h *ManifestsHandler) Handle(ctx context.Context, cmd *cobra.Command, args []string, base *types.BaseCommand) error {
+	releaseVersion, _ := cmd.Flags().GetString("version")
+	dist, _ := cmd.Flags().GetString("dist")
+	output, _ := cmd.Flags().GetString("output")
+	manDir, _ := cmd.Flags().GetString("man")
+	maintainer, _ := cmd.Flags().GetString("maintainer")
+
+	// The version defaults to the one of the command metadata, which the
+	// release bumps
+	if releaseVersion == "" {
+		releaseVersion = cmd.Root().Version
+	}
+	releaseVersion = strings.TrimPrefix(releaseVersion, "v")
+	if releaseVersion == "" {
+		return errors.New("no release version; pass it with --version")
+	}
+
+	artifacts, err := release.FindArtifacts(dist, constants.AppName)
+	if err != nil {
+		return fmt.Errorf("failed to read the release binaries: %w", err)
+	}
+	if manDir == "" {
+		manDir = filepath.Join(dist, "man")
+	}
+	if !utils.FileExists(manDir) {
+		manDir = ""
+	}
+
+	files, err := release.Manifests(release.Metadata{
+		Name:        constants.AppName,
+		Description: cmd.Root().Short,
+		Homepage:    constants.AppHomepage,
+		Repository:  constants.AppRepository,
+		License:     constants.AppLicense,
+		Maintainer:  maintainer,
+		Version:     releaseVersion,
+		Artifacts:   artifacts,
+		ManDir:      manDir,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to render the manifests: %w", err)
+	}
+
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		file := filepath.Join(output, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+			return fmt.Errorf("failed to create %s: %w", filepath.Dir(file), err)
+		}
+		if err := os.WriteFile(file, files[name], 0644); err != nil {
+			return fmt.Errorf("failed to write %s: %w", file, err)
+		}
+		ui.Success("Wrote %s", file)
+	}
+	if manDir == "" {
+		ui.Info("The deb and rpm packages have no man pages; generate them with '%s docs cli --format man -o %s'", constants.AppName, filepath.Join(dist, "man"))
+	}
+	return nil
+}
+
+// ValidateArgs validates the command arguments
+func (h *ManifestsHandler) ValidateArgs(args []string) error {
+	return nil
+}
+
+// GetRequiredFlags returns required flags for this command
+func (h *ManifestsHandler) GetRequiredFlags() []string {
+	return []string{}
+}
@@ -3,10 +3,13 @@ package services
 import (
 	"context"
 	"fmt"
+	"maps"
+	"slices"
+	"strings"
 
 	"github.com/isaacgarza/dev-stack/internal/pkg/cli/handlers/utils"
 	"github.com/isaacgarza/dev-stack/internal/pkg/cli/types"
-	"github.com/isaacgarza/dev-stack/internal/pkg/display"
+	"github.com/isaacgarza/dev-stack/internal/pkg/render"
 	"github.com/isaacgarza/dev-stack/internal/pkg/ui"
 	"github.com/spf13/cobra"
 )
@@ -19,12 +22,32 @@ func NewDepsHandler() *DepsHandler {
 	return &DepsHandler{}
 }
 
+// serviceDependencies is a service and the services it requires
+type serviceDependencies struct {
+	Service      string   `json:"service" yaml:"service"`
+	Dependencies []string `json:"dependencies" yaml:"dependencies"`
+}
+
+// dependencyColumns are the columns of the table of dependencies
+var dependencyColumns = []render.Column[serviceDependencies]{
+	{Name: "SERVICE", Value: func(d serviceDependencies) string { return d.Service }},
+	{Name: "DEPENDENCIES", Value: func(d serviceDependencies) string {
+		if len(d.Dependencies) == 0 {
+			return "None"
+		}
+		return strings.Join(d.Dependencies, ", ")
+	}},
+}
+
 // Handle executes the deps command
 func (h *DepsHandler) Handle(ctx context.Context, cmd *cobra.Command, args []string, base *types.BaseCommand) error {
-	ui.Header("Service Dependencies")
-
-	// Get output format
-	format, _ := cmd.Flags().GetString("output")
+	output, err := utils.GetRenderOptions(cmd)
+	if err != nil {
+		return err
+	}
+	if !output.Format.Structured() {
+		ui.Header("Service Dependencies")
+	}
 
 	// Load service dependencies
 	serviceUtils := utils.NewServiceUtils()
@@ -33,49 +56,20 @@ func (h *DepsHandler) Handle(ctx context.Context, cmd *cobra.Command, args []str
 		return fmt.Errorf("failed to load dependencies: %w", err)
 	}
 
-	if len(dependencies) == 0 {
+	if len(dependencies) == 0 && !output.Format.Structured() {
 		ui.Info("No service dependencies found")
 		return nil
 	}
 
-	// Create display data
-	var displayData []map[string]interface{}
-	for serviceName, deps := range dependencies {
-		if len(deps) == 0 {
-			displayData = append(displayData, map[string]interface{}{
-				"Service":      serviceName,
-				"Dependencies": "None",
-			})
-		} else {
-			for _, dep := range deps {
-				displayData = append(displayData, map[string]interface{}{
-					"Service":      serviceName,
-					"Dependencies": dep,
-				})
-			}
+	rows := make([]serviceDependencies, 0, len(dependencies))
+	for _, serviceName := range slices.Sorted(maps.Keys(dependencies)) {
+		deps := dependencies[serviceName]
+		if deps == nil {
+			deps = []string{}
 		}
+		rows = append(rows, serviceDependencies{Service: serviceName, Dependencies: deps})
 	}
-
-	// Display results
-	formatter, err := display.CreateFormatter(format, cmd.OutOrStdout())
-	if err != nil {
-		return fmt.Errorf("failed to create formatter: %w", err)
-	}
-
-	// Convert to ServiceStatus format for display
-	var services []display.ServiceStatus
-	for _, item := range displayData {
-		services = append(services, display.ServiceStatus{
-			Name:  item["Service"].(string),
-			State: item["Dependencies"].(string),
-		})
-	}
-
-	if err := formatter.FormatStatus(services, display.StatusOptions{}); err != nil {
-		return fmt.Errorf("failed to format output: %w", err)
-	}
-
-	return nil
+	return render.Render(cmd.OutOrStdout(), render.Table[serviceDependencies]{Columns: dependencyColumns, Rows: rows}, output)
 }
 
 // ValidateArgs validates the command arguments
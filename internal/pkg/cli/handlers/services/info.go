@@ -0,0 +1,117 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/isaacgarza/dev-stack/internal/pkg/cli/handlers/utils"
+	"github.com/isaacgarza/dev-stack/internal/pkg/cli/types"
+	"github.com/isaacgarza/dev-stack/internal/pkg/ui"
+	"github.com/spf13/cobra"
+)
+
+// InfoHandler handles the services info command
+type InfoHandler struct{}
+
+// NewInfoHandler creates a new services info handler
+func NewInfoHandler() *InfoHandler {
+	return &InfoHandler{}
+}
+
+// Handle executes the services info command
+func (h *InfoHandler) Handle(ctx context.Context, cmd *cobra.Command, args []string, base *types.BaseCommand) error {
+	if err := h.ValidateArgs(args); err != nil {
+		return err
+	}
+	serviceName := args[0]
+
+	serviceUtils := utils.NewServiceUtils()
+	info, err := serviceUtils.GetServiceInfo(serviceName)
+	if err != nil {
+		return err
+	}
+	serviceConfig, err := serviceUtils.LoadServiceConfig(serviceName)
+	if err != nil {
+		return err
+	}
+
+	ui.Header("Service: %s", info.Name)
+	ui.Info("%s", info.Description)
+	ui.Muted("Category: %s", info.Category)
+	ui.Muted("Source: %s", describeSource(info))
+	if serviceConfig.Defaults.Image != "" {
+		ui.Muted("Image: %s", serviceConfig.Defaults.Image)
+	}
+
+	if ports := servicePorts(serviceConfig); len(ports) > 0 {
+		ui.SubHeader("Ports")
+		ui.List(ports)
+	}
+
+	if len(serviceConfig.Environment) > 0 {
+		ui.SubHeader("Environment")
+		ui.List(environmentEntries(serviceConfig.Environment))
+	}
+
+	if len(info.Dependencies) > 0 {
+		ui.SubHeader("Dependencies")
+		ui.List(info.Dependencies)
+	}
+
+	if len(info.Options) > 0 {
+		ui.SubHeader("Options")
+		ui.List(info.Options)
+	}
+
+	if len(info.Examples) > 0 {
+		ui.SubHeader("Examples")
+		ui.List(info.Examples)
+	}
+
+	if info.UsageNotes != "" {
+		ui.SubHeader("Usage Notes")
+		ui.Info("%s", info.UsageNotes)
+	}
+
+	if len(info.Links) > 0 {
+		ui.SubHeader("Links")
+		ui.List(info.Links)
+	}
+
+	return nil
+}
+
+// ValidateArgs validates the command arguments
+func (h *InfoHandler) ValidateArgs(args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("services info requires a service name")
+	}
+	return nil
+}
+
+// GetRequiredFlags returns required flags for this command
+func (h *InfoHandler) GetRequiredFlags() []string {
+	return []string{}
+}
+
+// servicePorts lists the ports a service publishes on the host
+func servicePorts(serviceConfig *types.ServiceConfig) []string {
+	var ports []string
+	if serviceConfig.Defaults.Port != 0 {
+		ports = append(ports, strconv.Itoa(serviceConfig.Defaults.Port))
+	}
+	return append(ports, serviceConfig.Docker.Ports...)
+}
+
+// environmentEntries renders the environment a service provides as sorted
+// KEY=value entries
+func environmentEntries(environment map[string]string) []string {
+	entries := make([]string, 0, len(environment))
+	for key, value := range environment {
+		entries = append(entries, key+"="+value)
+	}
+	sort.Strings(entries)
+	return entries
+}
@@ -0,0 +1,95 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/isaacgarza/dev-stack/internal/pkg/cli/handlers/utils"
+	"github.com/isaacgarza/dev-stack/internal/pkg/cli/types"
+	"github.com/isaacgarza/dev-stack/internal/pkg/ui"
+	"github.com/spf13/cobra"
+)
+
+// SearchHandler handles the services search command
+type SearchHandler struct{}
+
+// NewSearchHandler creates a new services search handler
+func NewSearchHandler() *SearchHandler {
+	return &SearchHandler{}
+}
+
+// Handle executes the services search command
+func (h *SearchHandler) Handle(ctx context.Context, cmd *cobra.Command, args []string, base *types.BaseCommand) error {
+	if err := h.ValidateArgs(args); err != nil {
+		return err
+	}
+	query := strings.Join(args, " ")
+
+	output, err := utils.GetRenderOptions(cmd)
+	if err != nil {
+		return err
+	}
+	showSource, _ := cmd.Flags().GetBool("source")
+
+	categories, err := utils.NewServiceUtils().GetServicesByCategory()
+	if err != nil {
+		return fmt.Errorf("failed to load services: %w", err)
+	}
+
+	var matches []types.ServiceInfo
+	for _, services := range categories {
+		for _, service := range services {
+			if matchesQuery(service, query) {
+				matches = append(matches, service)
+			}
+		}
+	}
+
+	if len(matches) == 0 && !output.Format.Structured() {
+		ui.Info("No services match %q", query)
+		return nil
+	}
+
+	// Services whose name matches come first
+	needle := strings.ToLower(query)
+	sort.Slice(matches, func(i, j int) bool {
+		iName := strings.Contains(matches[i].Name, needle)
+		jName := strings.Contains(matches[j].Name, needle)
+		if iName != jName {
+			return iName
+		}
+		return matches[i].Name < matches[j].Name
+	})
+
+	if !output.Format.Structured() {
+		ui.Header("Services matching %q", query)
+	}
+	return renderServices(cmd.OutOrStdout(), output, matches, showSource)
+}
+
+// ValidateArgs validates the command arguments
+func (h *SearchHandler) ValidateArgs(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("services search requires a search term")
+	}
+	return nil
+}
+
+// GetRequiredFlags returns required flags for this command
+func (h *SearchHandler) GetRequiredFlags() []string {
+	return []string{}
+}
+
+// matchesQuery reports whether query appears, ignoring case, in the
+// service's name, category, description or usage notes
+func matchesQuery(service types.ServiceInfo, query string) bool {
+	query = strings.ToLower(query)
+	for _, field := range []string{service.Name, service.Category, service.Description, service.UsageNotes} {
+		if strings.Contains(strings.ToLower(field), query) {
+			return true
+		}
+	}
+	return false
+}
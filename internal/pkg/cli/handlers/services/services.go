@@ -3,10 +3,14 @@ package services
 import (
 	"context"
 	"fmt"
+	"io"
+	"sort"
+	"strings"
 
 	"github.com/isaacgarza/dev-stack/internal/pkg/cli/handlers/utils"
 	"github.com/isaacgarza/dev-stack/internal/pkg/cli/types"
-	"github.com/isaacgarza/dev-stack/internal/pkg/display"
+	"github.com/isaacgarza/dev-stack/internal/pkg/render"
+	pkgServices "github.com/isaacgarza/dev-stack/internal/pkg/services"
 	"github.com/isaacgarza/dev-stack/internal/pkg/ui"
 	"github.com/spf13/cobra"
 )
@@ -21,10 +25,16 @@ func NewServicesHandler() *ServicesHandler {
 
 // Handle executes the services command
 func (h *ServicesHandler) Handle(ctx context.Context, cmd *cobra.Command, args []string, base *types.BaseCommand) error {
-	ui.Header("Available Services")
+	output, err := utils.GetRenderOptions(cmd)
+	if err != nil {
+		return err
+	}
+	if !output.Format.Structured() {
+		ui.Header("Available Services")
+	}
 
-	// Get output format
-	format, _ := cmd.Flags().GetString("output")
+	category, _ := cmd.Flags().GetString("category")
+	showSource, _ := cmd.Flags().GetBool("source")
 
 	// Load services by category
 	serviceUtils := utils.NewServiceUtils()
@@ -33,43 +43,84 @@ func (h *ServicesHandler) Handle(ctx context.Context, cmd *cobra.Command, args [
 		return fmt.Errorf("failed to load services: %w", err)
 	}
 
-	if len(categories) == 0 {
+	if category != "" {
+		if _, ok := categories[category]; !ok {
+			return fmt.Errorf("unknown category %s", category)
+		}
+		categories = map[string][]types.ServiceInfo{category: categories[category]}
+	}
+
+	if len(categories) == 0 && !output.Format.Structured() {
 		ui.Info("No services available")
 		return nil
 	}
 
-	// Create display data
-	var displayData []map[string]interface{}
-	for categoryName, services := range categories {
-		for _, service := range services {
-			displayData = append(displayData, map[string]interface{}{
-				"Category":    categoryName,
-				"Name":        service.Name,
-				"Description": service.Description,
-			})
-		}
+	var services []types.ServiceInfo
+	for _, categoryName := range sortedCategories(categories) {
+		services = append(services, categories[categoryName]...)
 	}
+	return renderServices(cmd.OutOrStdout(), output, services, showSource)
+}
 
-	// Display results
-	formatter, err := display.CreateFormatter(format, cmd.OutOrStdout())
-	if err != nil {
-		return fmt.Errorf("failed to create formatter: %w", err)
-	}
+// serviceRow is a service of the catalog as services list and search
+// print it as JSON or YAML
+type serviceRow struct {
+	Name         string   `json:"name" yaml:"name"`
+	Category     string   `json:"category" yaml:"category"`
+	Description  string   `json:"description" yaml:"description"`
+	Source       string   `json:"source" yaml:"source"`
+	Overrides    bool     `json:"overrides,omitempty" yaml:"overrides,omitempty"`
+	Dependencies []string `json:"dependencies" yaml:"dependencies"`
+}
 
-	// Convert to ServiceStatus format for display
-	var services []display.ServiceStatus
-	for _, item := range displayData {
-		services = append(services, display.ServiceStatus{
-			Name:  item["Name"].(string),
-			State: item["Description"].(string),
+// renderServices prints services in the format of output. The source of
+// each definition is a wide column unless showSource.
+func renderServices(w io.Writer, output render.Options, services []types.ServiceInfo, showSource bool) error {
+	rows := make([]serviceRow, 0, len(services))
+	for _, service := range services {
+		dependencies := service.Dependencies
+		if dependencies == nil {
+			dependencies = []string{}
+		}
+		rows = append(rows, serviceRow{
+			Name:         service.Name,
+			Category:     service.Category,
+			Description:  service.Description,
+			Source:       service.Source,
+			Overrides:    service.Overrides,
+			Dependencies: dependencies,
 		})
 	}
 
-	if err := formatter.FormatStatus(services, display.StatusOptions{}); err != nil {
-		return fmt.Errorf("failed to format output: %w", err)
+	return render.Render(w, render.Table[types.ServiceInfo]{
+		Columns: []render.Column[types.ServiceInfo]{
+			{Name: "SERVICE", Value: func(s types.ServiceInfo) string { return s.Name }},
+			{Name: "CATEGORY", Value: func(s types.ServiceInfo) string { return s.Category }},
+			{Name: "DESCRIPTION", Value: func(s types.ServiceInfo) string { return s.Description }},
+			{Name: "SOURCE", Wide: !showSource, Value: describeSource},
+			{Name: "DEPENDENCIES", Wide: true, Value: func(s types.ServiceInfo) string { return strings.Join(s.Dependencies, ", ") }},
+		},
+		Rows:  services,
+		Data:  rows,
+		Empty: "No services found",
+	}, output)
+}
+
+// describeSource says where a service definition was loaded from
+func describeSource(service types.ServiceInfo) string {
+	if service.Overrides {
+		return fmt.Sprintf("%s, overrides %s", service.Source, pkgServices.SourceBuiltin)
 	}
+	return service.Source
+}
 
-	return nil
+func sortedCategories(categories map[string][]types.ServiceInfo) []string {
+	names := make([]string, 0, len(categories))
+	for name := range categories {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
 }
 
 // ValidateArgs validates the command arguments
@@ -0,0 +1,47 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/isaacgarza/dev-stack/internal/pkg/cli/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMatchesQuery(t *testing.T) {
+	service := types.ServiceInfo{
+		Name:        "kafka-ui",
+		Category:    "messaging",
+		Description: "Web UI for Kafka cluster management",
+		UsageNotes:  "Browse topics and consumer groups",
+	}
+
+	tests := []struct {
+		query    string
+		expected bool
+	}{
+		{query: "kafka", expected: true},
+		{query: "KAFKA", expected: true},
+		{query: "messaging", expected: true},
+		{query: "cluster management", expected: true},
+		{query: "consumer", expected: true},
+		{query: "postgres", expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			assert.Equal(t, tt.expected, matchesQuery(service, tt.query))
+		})
+	}
+}
+
+func TestRequiredBy(t *testing.T) {
+	dependencies := map[string][]string{
+		"kibana":       {"elasticsearch"},
+		"kafka-ui":     {"kafka-broker"},
+		"kafka-broker": {"zookeeper"},
+	}
+
+	assert.Equal(t, []string{"kibana"}, requiredBy("elasticsearch", []string{"redis", "kibana"}, dependencies))
+	assert.Equal(t, []string{"kafka-broker"}, requiredBy("zookeeper", []string{"kafka-broker", "kafka-ui"}, dependencies))
+	assert.Empty(t, requiredBy("elasticsearch", []string{"redis"}, dependencies))
+}
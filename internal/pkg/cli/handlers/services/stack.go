@@ -0,0 +1,203 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/isaacgarza/dev-stack/internal/pkg/cli/handlers/core"
+	"github.com/isaacgarza/dev-stack/internal/pkg/cli/handlers/utils"
+	"github.com/isaacgarza/dev-stack/internal/pkg/cli/types"
+	pkgConfig "github.com/isaacgarza/dev-stack/internal/pkg/config"
+	"github.com/isaacgarza/dev-stack/internal/pkg/constants"
+	pkgTypes "github.com/isaacgarza/dev-stack/internal/pkg/types"
+	"github.com/isaacgarza/dev-stack/internal/pkg/ui"
+	pkgUtils "github.com/isaacgarza/dev-stack/internal/pkg/utils"
+	"github.com/spf13/cobra"
+)
+
+// AddHandler handles the services add command
+type AddHandler struct{}
+
+// NewAddHandler creates a new services add handler
+func NewAddHandler() *AddHandler {
+	return &AddHandler{}
+}
+
+// Handle executes the services add command
+func (h *AddHandler) Handle(ctx context.Context, cmd *cobra.Command, args []string, base *types.BaseCommand) error {
+	if err := h.ValidateArgs(args); err != nil {
+		return err
+	}
+
+	configPath, cfg, err := loadStackConfig()
+	if err != nil {
+		return err
+	}
+
+	serviceUtils := utils.NewServiceUtils()
+	for _, serviceName := range args {
+		if _, err := serviceUtils.GetServiceInfo(serviceName); err != nil {
+			return fmt.Errorf("%w. Run '%s' to see the available services", err, constants.CmdRef(constants.CmdNameServicesList))
+		}
+	}
+
+	resolved, err := serviceUtils.ResolveDependencies(args)
+	if err != nil {
+		return fmt.Errorf("failed to resolve dependencies: %w", err)
+	}
+
+	enabled := slices.Clone(cfg.Stack.Enabled)
+	var added, dependencies []string
+	for _, serviceName := range resolved {
+		if slices.Contains(enabled, serviceName) {
+			continue
+		}
+		enabled = append(enabled, serviceName)
+		if slices.Contains(args, serviceName) {
+			added = append(added, serviceName)
+		} else {
+			dependencies = append(dependencies, serviceName)
+		}
+	}
+
+	if len(added) == 0 && len(dependencies) == 0 {
+		ui.Info("%s already enabled", strings.Join(args, ", "))
+		return nil
+	}
+
+	if err := updateStack(ctx, configPath, cfg, enabled); err != nil {
+		return err
+	}
+
+	if len(added) > 0 {
+		ui.Success("Added %s to the stack", strings.Join(added, ", "))
+	}
+	if len(dependencies) > 0 {
+		ui.Info("Also added required dependencies: %s", strings.Join(dependencies, ", "))
+	}
+	ui.Info("Start them with: %s %s", constants.CmdUp, strings.Join(append(added, dependencies...), " "))
+	return nil
+}
+
+// ValidateArgs validates the command arguments
+func (h *AddHandler) ValidateArgs(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("services add requires at least one service name")
+	}
+	return nil
+}
+
+// GetRequiredFlags returns required flags for this command
+func (h *AddHandler) GetRequiredFlags() []string {
+	return []string{}
+}
+
+// RemoveHandler handles the services remove command
+type RemoveHandler struct{}
+
+// NewRemoveHandler creates a new services remove handler
+func NewRemoveHandler() *RemoveHandler {
+	return &RemoveHandler{}
+}
+
+// Handle executes the services remove command
+func (h *RemoveHandler) Handle(ctx context.Context, cmd *cobra.Command, args []string, base *types.BaseCommand) error {
+	if err := h.ValidateArgs(args); err != nil {
+		return err
+	}
+	force, _ := cmd.Flags().GetBool("force")
+
+	configPath, cfg, err := loadStackConfig()
+	if err != nil {
+		return err
+	}
+
+	for _, serviceName := range args {
+		if !slices.Contains(cfg.Stack.Enabled, serviceName) {
+			return &pkgTypes.ServiceNotFoundError{Service: serviceName, NotEnabled: true}
+		}
+	}
+
+	var remaining []string
+	for _, serviceName := range cfg.Stack.Enabled {
+		if !slices.Contains(args, serviceName) {
+			remaining = append(remaining, serviceName)
+		}
+	}
+
+	if !force {
+		dependencies, err := utils.NewServiceUtils().LoadAllServiceDependencies()
+		if err != nil {
+			return fmt.Errorf("failed to load dependencies: %w", err)
+		}
+		for _, serviceName := range args {
+			if dependents := requiredBy(serviceName, remaining, dependencies); len(dependents) > 0 {
+				return fmt.Errorf("service %s is required by %s; remove them too or use --force", serviceName, strings.Join(dependents, ", "))
+			}
+		}
+	}
+
+	if err := updateStack(ctx, configPath, cfg, remaining); err != nil {
+		return err
+	}
+
+	ui.Success("Removed %s from the stack", strings.Join(args, ", "))
+	ui.Info("Stop their containers with: %s %s", constants.CmdDown, strings.Join(args, " "))
+	return nil
+}
+
+// ValidateArgs validates the command arguments
+func (h *RemoveHandler) ValidateArgs(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("services remove requires at least one service name")
+	}
+	return nil
+}
+
+// GetRequiredFlags returns required flags for this command
+func (h *RemoveHandler) GetRequiredFlags() []string {
+	return []string{}
+}
+
+// loadStackConfig loads the project configuration that services add and
+// remove update
+func loadStackConfig() (string, *core.ProjectConfig, error) {
+	configPath := filepath.Join(constants.DevStackDir, constants.ConfigFileName)
+	if !pkgUtils.FileExists(configPath) {
+		return "", nil, errors.New(constants.ErrNotInitialized)
+	}
+
+	cfg, err := core.LoadProjectConfig(configPath)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to load configuration: %w", err)
+	}
+	return configPath, cfg, nil
+}
+
+// updateStack writes the enabled services to the configuration and
+// regenerates the compose files for them
+func updateStack(ctx context.Context, configPath string, cfg *core.ProjectConfig, enabled []string) error {
+	if err := pkgConfig.SetEnabledServices(configPath, enabled); err != nil {
+		return fmt.Errorf("failed to update configuration: %w", err)
+	}
+	cfg.Stack.Enabled = enabled
+	if err := core.RegenerateCompose(ctx, cfg); err != nil {
+		return fmt.Errorf("failed to regenerate compose files: %w", err)
+	}
+	return nil
+}
+
+// requiredBy returns the services among enabled that require serviceName
+func requiredBy(serviceName string, enabled []string, dependencies map[string][]string) []string {
+	var dependents []string
+	for _, name := range enabled {
+		if slices.Contains(dependencies[name], serviceName) {
+			dependents = append(dependents, name)
+		}
+	}
+	return dependents
+}
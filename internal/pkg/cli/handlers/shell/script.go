@@ -0,0 +1,199 @@
+package shell
+
+import (
+	"fmt"
+	"strings"
+
+	pkgTypes "github.com/isaacgarza/dev-stack/internal/pkg/types"
+)
+
+// scriptWriter builds the commands evaluated by the shell hook, using the
+// syntax of the target shell
+type scriptWriter struct {
+	shell pkgTypes.ShellType
+	b     strings.Builder
+}
+
+func newScriptWriter(shell pkgTypes.ShellType) *scriptWriter {
+	return &scriptWriter{shell: shell}
+}
+
+// set exports a variable
+func (w *scriptWriter) set(key, value string) {
+	if w.shell == pkgTypes.ShellTypeFish {
+		fmt.Fprintf(&w.b, "set -gx %s %s;\n", key, w.quote(value))
+		return
+	}
+	fmt.Fprintf(&w.b, "export %s=%s;\n", key, w.quote(value))
+}
+
+// unset removes a variable
+func (w *scriptWriter) unset(key string) {
+	if w.shell == pkgTypes.ShellTypeFish {
+		fmt.Fprintf(&w.b, "set -e %s;\n", key)
+		return
+	}
+	fmt.Fprintf(&w.b, "unset %s;\n", key)
+}
+
+func (w *scriptWriter) unsetAll(keys []string) {
+	for _, key := range keys {
+		w.unset(key)
+	}
+}
+
+// startAndReactivate runs the up command and activates the project again
+// once it succeeds, so the new services' variables are exported
+func (w *scriptWriter) startAndReactivate(up, root string) {
+	activate := fmt.Sprintf("command dev-stack shell-init %s --activate %s", w.shell, w.quote(root))
+	if w.shell == pkgTypes.ShellTypeFish {
+		fmt.Fprintf(&w.b, "%s >&2; and %s | source;\n", up, activate)
+		return
+	}
+	fmt.Fprintf(&w.b, "%s >&2 && eval \"$(%s)\";\n", up, activate)
+}
+
+// quote quotes a value as a single word
+func (w *scriptWriter) quote(value string) string {
+	if w.shell == pkgTypes.ShellTypeFish {
+		return "'" + strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(value) + "'"
+	}
+	return "'" + strings.ReplaceAll(value, "'", `'\''`) + "'"
+}
+
+func (w *scriptWriter) String() string {
+	return w.b.String()
+}
+
+// hookTemplates hold the shell hooks. Each looks for the project config in
+// the current directory and its parents, and only calls dev-stack when the
+// project changes. A dev-stack wrapper function refreshes the variables and
+// prompt after every dev-stack command, such as up or down.
+var hookTemplates = map[pkgTypes.ShellType]string{
+	pkgTypes.ShellTypeBash: `# dev-stack shell integration for bash
+_dev_stack_hook() {
+  local previous_exit=$?
+  local dir="$PWD" root=""
+  while [ -n "$dir" ]; do
+    if [ -f "$dir/{{.Marker}}" ]; then
+      root="$dir"
+      break
+    fi
+    dir="${dir%/*}"
+  done
+  if [ "$root" != "${DEV_STACK_PROJECT_ROOT:-}" ]; then
+    if [ -n "$root" ]; then
+      eval "$(command dev-stack shell-init bash --activate{{.ActivateFlags}} "$root")"
+    else
+      eval "$(command dev-stack shell-init bash --deactivate)"
+    fi
+  fi
+  return $previous_exit
+}
+
+dev-stack() {
+  command dev-stack "$@"
+  local exit_code=$?
+  if [ -n "${DEV_STACK_PROJECT_ROOT:-}" ]; then
+    eval "$(command dev-stack shell-init bash --activate "$DEV_STACK_PROJECT_ROOT")"
+  fi
+  return $exit_code
+}
+
+case ";${PROMPT_COMMAND:-};" in
+  *";_dev_stack_hook;"*) ;;
+  *) PROMPT_COMMAND="_dev_stack_hook${PROMPT_COMMAND:+;$PROMPT_COMMAND}" ;;
+esac
+{{- if .Prompt}}
+
+case "$PS1" in
+  *'DEV_STACK_PROMPT'*) ;;
+  *) PS1='${DEV_STACK_PROMPT:+$DEV_STACK_PROMPT }'"$PS1" ;;
+esac
+{{- end}}
+`,
+
+	pkgTypes.ShellTypeZsh: `# dev-stack shell integration for zsh
+_dev_stack_hook() {
+  local dir="$PWD" root=""
+  while [[ -n "$dir" ]]; do
+    if [[ -f "$dir/{{.Marker}}" ]]; then
+      root="$dir"
+      break
+    fi
+    dir="${dir%/*}"
+  done
+  if [[ "$root" != "${DEV_STACK_PROJECT_ROOT:-}" ]]; then
+    if [[ -n "$root" ]]; then
+      eval "$(command dev-stack shell-init zsh --activate{{.ActivateFlags}} "$root")"
+    else
+      eval "$(command dev-stack shell-init zsh --deactivate)"
+    fi
+  fi
+}
+
+dev-stack() {
+  command dev-stack "$@"
+  local exit_code=$?
+  if [[ -n "${DEV_STACK_PROJECT_ROOT:-}" ]]; then
+    eval "$(command dev-stack shell-init zsh --activate "$DEV_STACK_PROJECT_ROOT")"
+  fi
+  return $exit_code
+}
+
+autoload -Uz add-zsh-hook
+add-zsh-hook chpwd _dev_stack_hook
+_dev_stack_hook
+{{- if .Prompt}}
+
+setopt prompt_subst
+if [[ "$PROMPT" != *DEV_STACK_PROMPT* ]]; then
+  PROMPT='${DEV_STACK_PROMPT:+$DEV_STACK_PROMPT }'"$PROMPT"
+fi
+{{- end}}
+`,
+
+	pkgTypes.ShellTypeFish: `# dev-stack shell integration for fish
+function __dev_stack_hook --on-variable PWD
+    set -l dir $PWD
+    set -l root ""
+    while test -n "$dir"
+        if test -f "$dir/{{.Marker}}"
+            set root $dir
+            break
+        end
+        set dir (string replace -r '/[^/]*$' '' -- $dir)
+    end
+    if test "$root" != "$DEV_STACK_PROJECT_ROOT"
+        if test -n "$root"
+            command dev-stack shell-init fish --activate{{.ActivateFlags}} $root | source
+        else
+            command dev-stack shell-init fish --deactivate | source
+        end
+    end
+end
+
+function dev-stack
+    command dev-stack $argv
+    set -l exit_code $status
+    if test -n "$DEV_STACK_PROJECT_ROOT"
+        command dev-stack shell-init fish --activate $DEV_STACK_PROJECT_ROOT | source
+    end
+    return $exit_code
+end
+
+__dev_stack_hook
+{{- if .Prompt}}
+
+if not functions -q __dev_stack_original_prompt
+    functions -c fish_prompt __dev_stack_original_prompt
+    function fish_prompt
+        if test -n "$DEV_STACK_PROMPT"
+            echo -n "$DEV_STACK_PROMPT "
+        end
+        __dev_stack_original_prompt
+    end
+end
+{{- end}}
+`,
+}
@@ -0,0 +1,233 @@
+package shell
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/isaacgarza/dev-stack/internal/core/services"
+	"github.com/isaacgarza/dev-stack/internal/pkg/cli/handlers/core"
+	"github.com/isaacgarza/dev-stack/internal/pkg/cli/types"
+	"github.com/isaacgarza/dev-stack/internal/pkg/constants"
+	pkgTypes "github.com/isaacgarza/dev-stack/internal/pkg/types"
+	"github.com/isaacgarza/dev-stack/internal/pkg/utils"
+	"github.com/spf13/cobra"
+)
+
+// Variables maintained in the shell by the hook
+const (
+	envProjectRoot  = "DEV_STACK_PROJECT_ROOT"
+	envExportedVars = "DEV_STACK_ENV_VARS"
+	envPrompt       = "DEV_STACK_PROMPT"
+)
+
+// ShellInitHandler handles the shell-init command
+type ShellInitHandler struct {
+	manager *services.Manager
+}
+
+// NewShellInitHandler creates a new shell-init handler
+func NewShellInitHandler(manager *services.Manager) *ShellInitHandler {
+	return &ShellInitHandler{manager: manager}
+}
+
+// Handle executes the shell-init command. Without --activate or
+// --deactivate it prints the hook to be evaluated from the shell's rc file;
+// the hook calls back into this command when the project changes.
+func (h *ShellInitHandler) Handle(ctx context.Context, cmd *cobra.Command, args []string, base *types.BaseCommand) error {
+	activate, _ := cmd.Flags().GetBool("activate")
+	deactivate, _ := cmd.Flags().GetBool("deactivate")
+	if err := h.validate(args, activate, deactivate); err != nil {
+		return err
+	}
+	shell := pkgTypes.ShellType(args[0])
+	autoStart, _ := cmd.Flags().GetBool("auto-start")
+	profile, _ := cmd.Flags().GetString("profile")
+
+	switch {
+	case deactivate:
+		w := newScriptWriter(shell)
+		deactivateScript(w, previousVars())
+		_, err := fmt.Fprint(os.Stdout, w.String())
+		return err
+	case activate:
+		w := newScriptWriter(shell)
+		h.activate(ctx, w, args[1], autoStart, profile)
+		_, err := fmt.Fprint(os.Stdout, w.String())
+		return err
+	}
+
+	prompt, _ := cmd.Flags().GetBool("prompt")
+	script, err := hookScript(shell, autoStart, profile, prompt)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprint(os.Stdout, script)
+	return err
+}
+
+// validate checks the shell argument and the project root passed with
+// --activate
+func (h *ShellInitHandler) validate(args []string, activate, deactivate bool) error {
+	if err := h.ValidateArgs(args); err != nil {
+		return err
+	}
+	if activate && deactivate {
+		return fmt.Errorf("--activate and --deactivate cannot be used together")
+	}
+	if activate != (len(args) == 2) {
+		return fmt.Errorf("a project directory is required with --activate, and only with --activate")
+	}
+	return nil
+}
+
+// activate writes the commands that export the project's connection
+// variables and prompt status. Problems are reported on stderr rather than
+// failing, so the shell records the project and does not retry on every
+// prompt.
+func (h *ShellInitHandler) activate(ctx context.Context, w *scriptWriter, root string, autoStart bool, profile string) {
+	previous := previousVars()
+	name := filepath.Base(root)
+	w.set(envProjectRoot, root)
+
+	fail := func(format string, args ...any) {
+		fmt.Fprintf(os.Stderr, "dev-stack: "+format+"\n", args...)
+		w.unsetAll(previous)
+		w.unset(envExportedVars)
+		w.set(envPrompt, "["+name+"]")
+	}
+
+	if err := os.Chdir(root); err != nil {
+		fail("failed to enter %s: %v", root, err)
+		return
+	}
+	configPath := filepath.Join(constants.DevStackDir, constants.ConfigFileName)
+	if !utils.FileExists(configPath) {
+		deactivateScript(w, previous)
+		return
+	}
+	cfg, err := core.LoadProjectConfig(configPath)
+	if err != nil {
+		fail("failed to load configuration: %v", err)
+		return
+	}
+	name = cfg.Project.Name
+
+	h.manager.SetProjectName(cfg.Project.Name)
+	running, err := core.RunningServices(ctx, h.manager, cfg.Stack.Enabled)
+	if err != nil {
+		fail("%v", err)
+		return
+	}
+	env, err := core.ServiceConnectionEnv(configPath, running)
+	if err != nil {
+		fail("%v", err)
+		return
+	}
+
+	keys := make([]string, 0, len(env))
+	for key := range env {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range previous {
+		if _, ok := env[key]; !ok {
+			w.unset(key)
+		}
+	}
+	for _, key := range keys {
+		w.set(key, env[key])
+	}
+	if len(keys) > 0 {
+		w.set(envExportedVars, strings.Join(keys, " "))
+	} else {
+		w.unset(envExportedVars)
+	}
+	w.set(envPrompt, promptStatus(name, cfg.Stack.Enabled, running))
+
+	if autoStart && len(running) == 0 {
+		up := "command dev-stack up"
+		if profile != "" {
+			up += " --profile " + w.quote(profile)
+		}
+		w.startAndReactivate(up, root)
+	}
+}
+
+// deactivateScript removes everything a previous activation exported
+func deactivateScript(w *scriptWriter, previous []string) {
+	w.unsetAll(previous)
+	w.unset(envExportedVars)
+	w.unset(envProjectRoot)
+	w.unset(envPrompt)
+}
+
+// previousVars returns the variables exported by the last activation
+func previousVars() []string {
+	return strings.Fields(os.Getenv(envExportedVars))
+}
+
+// promptStatus summarises the stack for the prompt, e.g. "[myapp 2/3]"
+func promptStatus(projectName string, enabled, running []string) string {
+	count := 0
+	for _, serviceName := range enabled {
+		if slices.Contains(running, serviceName) {
+			count++
+		}
+	}
+	return fmt.Sprintf("[%s %d/%d]", projectName, count, len(enabled))
+}
+
+// hookScript renders the hook installed in the shell's rc file
+func hookScript(shell pkgTypes.ShellType, autoStart bool, profile string, prompt bool) (string, error) {
+	source, ok := hookTemplates[shell]
+	if !ok {
+		return "", unsupportedShell(string(shell))
+	}
+
+	activateFlags := ""
+	if autoStart {
+		activateFlags += " --auto-start"
+		if profile != "" {
+			activateFlags += " --profile " + newScriptWriter(shell).quote(profile)
+		}
+	}
+
+	tmpl, err := template.New(string(shell)).Parse(source)
+	if err != nil {
+		return "", err
+	}
+	var b strings.Builder
+	err = tmpl.Execute(&b, map[string]any{
+		"Marker":        constants.DevStackDir + "/" + constants.ConfigFileName,
+		"ActivateFlags": activateFlags,
+		"Prompt":        prompt,
+	})
+	return b.String(), err
+}
+
+// ValidateArgs validates the command arguments
+func (h *ShellInitHandler) ValidateArgs(args []string) error {
+	if len(args) == 0 || len(args) > 2 {
+		return fmt.Errorf("shell-init requires a shell (%s, %s or %s)", pkgTypes.ShellTypeBash, pkgTypes.ShellTypeZsh, pkgTypes.ShellTypeFish)
+	}
+	if _, ok := hookTemplates[pkgTypes.ShellType(args[0])]; !ok {
+		return unsupportedShell(args[0])
+	}
+	return nil
+}
+
+func unsupportedShell(name string) error {
+	return fmt.Errorf("unsupported shell: %s (supported: %s, %s, %s)", name, pkgTypes.ShellTypeBash, pkgTypes.ShellTypeZsh, pkgTypes.ShellTypeFish)
+}
+
+// GetRequiredFlags returns required flags for this command
+func (h *ShellInitHandler) GetRequiredFlags() []string {
+	return []string{}
+}
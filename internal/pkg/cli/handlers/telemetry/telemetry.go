@@ -0,0 +1,204 @@
+// Package telemetry implements the telemetry commands, which opt in to
+// and out of the anonymous usage metrics
+package telemetry
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/spf13/cobra"
+
+	"github.com/isaacgarza/dev-stack/internal/core/telemetry"
+	"github.com/isaacgarza/dev-stack/internal/pkg/cli/handlers/utils"
+	cliTypes "github.com/isaacgarza/dev-stack/internal/pkg/cli/types"
+	pkgConfig "github.com/isaacgarza/dev-stack/internal/pkg/config"
+	"github.com/isaacgarza/dev-stack/internal/pkg/constants"
+	"github.com/isaacgarza/dev-stack/internal/pkg/ui"
+)
+
+// collected describes what an event holds, for telemetry on and status
+const collected = "Each command records its name (without arguments), duration, error category, OS, architecture and dev-stack version."
+
+// OnHandler handles the telemetry on command
+type OnHandler struct{}
+
+// NewOnHandler creates a new telemetry on handler
+func NewOnHandler() *OnHandler {
+	return &OnHandler{}
+}
+
+// Handle executes the telemetry on command, setting the endpoint when
+// --endpoint is given
+func (h *OnHandler) Handle(ctx context.Context, cmd *cobra.Command, args []string, base *cliTypes.BaseCommand) error {
+	endpoint, _ := cmd.Flags().GetString("endpoint")
+
+	user, path, err := loadUserConfig()
+	if err != nil {
+		return err
+	}
+	if err := user.Set(constants.UserKeyUsageMetrics, "true"); err != nil {
+		return err
+	}
+	if endpoint != "" {
+		if err := user.Set(constants.UserKeyUsageEndpoint, endpoint); err != nil {
+			return err
+		}
+	}
+	if err := user.Save(path); err != nil {
+		return err
+	}
+
+	ui.Success("Usage metrics enabled")
+	ui.Muted(collected)
+	if user.UsageMetricsEndpoint() == "" {
+		ui.Muted("No endpoint is set, so metrics are only kept locally. Set one with --endpoint.")
+	}
+	if !user.UsageMetricsEnabled() {
+		ui.Warning("%s is set, so no metrics are recorded", constants.EnvDoNotTrack)
+	}
+	return nil
+}
+
+// ValidateArgs validates the command arguments
+func (h *OnHandler) ValidateArgs(args []string) error {
+	return nil
+}
+
+// GetRequiredFlags returns required flags for this command
+func (h *OnHandler) GetRequiredFlags() []string {
+	return []string{}
+}
+
+// OffHandler handles the telemetry off command
+type OffHandler struct{}
+
+// NewOffHandler creates a new telemetry off handler
+func NewOffHandler() *OffHandler {
+	return &OffHandler{}
+}
+
+// Handle executes the telemetry off command, also discarding the metrics
+// not sent yet
+func (h *OffHandler) Handle(ctx context.Context, cmd *cobra.Command, args []string, base *cliTypes.BaseCommand) error {
+	user, path, err := loadUserConfig()
+	if err != nil {
+		return err
+	}
+	if err := user.Set(constants.UserKeyUsageMetrics, "false"); err != nil {
+		return err
+	}
+	if err := user.Save(path); err != nil {
+		return err
+	}
+
+	bufferPath, err := pkgConfig.UsageMetricsPath()
+	if err != nil {
+		return err
+	}
+	if err := telemetry.NewBuffer(bufferPath).Clear(); err != nil {
+		return err
+	}
+	ui.Success("Usage metrics disabled and the buffered metrics removed")
+	return nil
+}
+
+// ValidateArgs validates the command arguments
+func (h *OffHandler) ValidateArgs(args []string) error {
+	return nil
+}
+
+// GetRequiredFlags returns required flags for this command
+func (h *OffHandler) GetRequiredFlags() []string {
+	return []string{}
+}
+
+// StatusHandler handles the telemetry status command
+type StatusHandler struct{}
+
+// NewStatusHandler creates a new telemetry status handler
+func NewStatusHandler() *StatusHandler {
+	return &StatusHandler{}
+}
+
+// usageStatus is the state of the usage metrics, as printed by telemetry
+// status
+type usageStatus struct {
+	Enabled  bool   `json:"enabled"`
+	Source   string `json:"source"`
+	Endpoint string `json:"endpoint"`
+	Buffer   string `json:"buffer"`
+	Buffered int    `json:"buffered"`
+}
+
+// Handle executes the telemetry status command
+func (h *StatusHandler) Handle(ctx context.Context, cmd *cobra.Command, args []string, base *cliTypes.BaseCommand) error {
+	user, _, err := loadUserConfig()
+	if err != nil {
+		return err
+	}
+	bufferPath, err := pkgConfig.UsageMetricsPath()
+	if err != nil {
+		return err
+	}
+	events, err := telemetry.NewBuffer(bufferPath).Events()
+	if err != nil {
+		return err
+	}
+	setting, err := user.Get(constants.UserKeyUsageMetrics)
+	if err != nil {
+		return err
+	}
+
+	status := usageStatus{
+		Enabled:  user.UsageMetricsEnabled(),
+		Source:   setting.Source,
+		Endpoint: user.UsageMetricsEndpoint(),
+		Buffer:   bufferPath,
+		Buffered: len(events),
+	}
+	ciFlags := utils.GetCIFlags(cmd)
+	if ciFlags.JSON {
+		utils.OutputResult(ciFlags, status, constants.ExitSuccess)
+		return nil
+	}
+	return writeStatus(cmd.OutOrStdout(), status)
+}
+
+// writeStatus prints the state of the usage metrics
+func writeStatus(w io.Writer, status usageStatus) error {
+	state := "disabled"
+	if status.Enabled {
+		state = "enabled"
+	}
+	endpoint := status.Endpoint
+	if endpoint == "" {
+		endpoint = "none, metrics are only kept locally"
+	}
+	_, err := fmt.Fprintf(w, "Usage metrics: %s (%s)\nEndpoint:      %s\nBuffered:      %d events in %s\n\n%s\n",
+		state, status.Source, endpoint, status.Buffered, status.Buffer, collected)
+	return err
+}
+
+// ValidateArgs validates the command arguments
+func (h *StatusHandler) ValidateArgs(args []string) error {
+	return nil
+}
+
+// GetRequiredFlags returns required flags for this command
+func (h *StatusHandler) GetRequiredFlags() []string {
+	return []string{}
+}
+
+// loadUserConfig reads the user configuration, returning its path
+func loadUserConfig() (*pkgConfig.UserConfig, string, error) {
+	path, err := pkgConfig.UserConfigPath()
+	if err != nil {
+		return nil, "", err
+	}
+	user, err := pkgConfig.LoadUserConfigFile(path)
+	if err != nil {
+		return nil, "", err
+	}
+	return user, path, nil
+}
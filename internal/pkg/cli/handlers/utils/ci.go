@@ -6,6 +6,7 @@ import (
 	"os"
 
 	"github.com/isaacgarza/dev-stack/internal/pkg/constants"
+	"github.com/isaacgarza/dev-stack/internal/pkg/types"
 	"github.com/spf13/cobra"
 )
 
@@ -51,19 +52,22 @@ func OutputResult(flags CIFlags, result interface{}, exitCode int) {
 	}
 }
 
-// HandleError handles errors in CI-friendly way
+// HandleError handles errors in CI-friendly way, exiting with the exit
+// code of the kind of err
 func HandleError(flags CIFlags, err error) {
+	kind := types.ErrorKindOf(err)
 	if flags.JSON {
 		errorResult := map[string]interface{}{
 			"error":     err.Error(),
-			"exit_code": constants.ExitError,
+			"kind":      kind,
+			"exit_code": kind.ExitCode(),
 		}
 		_ = json.NewEncoder(os.Stdout).Encode(errorResult)
 	} else if !flags.Quiet {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 	}
 
-	os.Exit(constants.ExitError)
+	os.Exit(kind.ExitCode())
 }
 
 func outputJSON(result interface{}, exitCode int) {
@@ -0,0 +1,32 @@
+package utils
+
+import (
+	"github.com/isaacgarza/dev-stack/internal/pkg/constants"
+	"github.com/isaacgarza/dev-stack/internal/pkg/render"
+	"github.com/spf13/cobra"
+)
+
+// GetRenderOptions reads how a command prints its rows: --output, or the
+// older --format where the command still has it, and --columns. --json
+// selects JSON whatever the other flags say, and colors follow --no-color
+// and whether the command prints to a terminal.
+func GetRenderOptions(cmd *cobra.Command) (render.Options, error) {
+	value, _ := cmd.Flags().GetString(constants.FlagOutput)
+	if value == "" {
+		value, _ = cmd.Flags().GetString(constants.FlagFormat)
+	}
+	if GetCIFlags(cmd).JSON {
+		value = string(render.FormatJSON)
+	}
+	format, err := render.ParseFormat(value)
+	if err != nil {
+		return render.Options{}, err
+	}
+
+	columns, _ := cmd.Flags().GetString(constants.FlagColumns)
+	return render.Options{
+		Format:  format,
+		Columns: render.ParseColumns(columns),
+		Color:   render.Colored(cmd.OutOrStdout()),
+	}, nil
+}
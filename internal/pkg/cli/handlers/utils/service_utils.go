@@ -2,11 +2,10 @@ package utils
 
 import (
 	"fmt"
-	"strings"
 
-	"github.com/isaacgarza/dev-stack/internal/config"
 	"github.com/isaacgarza/dev-stack/internal/pkg/cli/types"
-	"github.com/isaacgarza/dev-stack/internal/pkg/constants"
+	"github.com/isaacgarza/dev-stack/internal/pkg/services"
+	pkgTypes "github.com/isaacgarza/dev-stack/internal/pkg/types"
 	"gopkg.in/yaml.v3"
 )
 
@@ -18,22 +17,21 @@ func NewServiceUtils() *ServiceUtils {
 	return &ServiceUtils{}
 }
 
-// GetServicesByCategory loads services organized by category
+// GetServicesByCategory loads services organized by category, with the
+// project's own definitions in dev-stack/services alongside the built-in ones
 func (u *ServiceUtils) GetServicesByCategory() (map[string][]types.ServiceInfo, error) {
-	categories, err := u.getCategories()
+	files, err := services.ListServiceFiles()
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("failed to read services directory: %w", err)
 	}
 
 	result := make(map[string][]types.ServiceInfo)
-	for _, category := range categories {
-		services, err := u.getServicesInCategory(category)
+	for _, file := range files {
+		serviceInfo, err := u.parseServiceInfo(file)
 		if err != nil {
 			continue
 		}
-		if len(services) > 0 {
-			result[category] = services
-		}
+		result[file.Category] = append(result[file.Category], serviceInfo)
 	}
 
 	return result, nil
@@ -44,39 +42,54 @@ func (u *ServiceUtils) LoadServicesByCategory() (map[string][]types.ServiceInfo,
 	return u.GetServicesByCategory()
 }
 
+// GetServiceInfo loads the catalog entry of a single service
+func (u *ServiceUtils) GetServiceInfo(serviceName string) (types.ServiceInfo, error) {
+	file, err := services.FindServiceFile(serviceName)
+	if err != nil {
+		return types.ServiceInfo{}, &pkgTypes.ServiceNotFoundError{Service: serviceName}
+	}
+
+	serviceInfo, err := u.parseServiceInfo(file)
+	if err != nil {
+		return types.ServiceInfo{}, fmt.Errorf("failed to parse service config for %s: %w", serviceName, err)
+	}
+	return serviceInfo, nil
+}
+
 // LoadServiceConfig loads a service configuration
 func (u *ServiceUtils) LoadServiceConfig(serviceName string) (*types.ServiceConfig, error) {
-	categories, err := u.getCategories()
+	file, err := services.FindServiceFile(serviceName)
 	if err != nil {
-		return nil, err
+		return nil, &pkgTypes.ServiceNotFoundError{Service: serviceName}
 	}
 
-	for _, category := range categories {
-		config, err := u.loadServiceFromCategory(category, serviceName)
-		if err == nil {
-			return config, nil
-		}
+	data, err := file.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read service config for %s: %w", serviceName, err)
+	}
+
+	var serviceConfig types.ServiceConfig
+	if err := yaml.Unmarshal(data, &serviceConfig); err != nil {
+		return nil, fmt.Errorf("failed to parse service config for %s: %w", serviceName, err)
 	}
 
-	return nil, fmt.Errorf("service %s not found", serviceName)
+	return &serviceConfig, nil
 }
 
 // LoadAllServiceDependencies loads dependencies for all services
 func (u *ServiceUtils) LoadAllServiceDependencies() (map[string][]string, error) {
-	categories, err := u.getCategories()
+	files, err := services.ListServiceFiles()
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("failed to read services directory: %w", err)
 	}
 
 	result := make(map[string][]string)
-	for _, category := range categories {
-		deps, err := u.getDependenciesInCategory(category)
+	for _, file := range files {
+		serviceData, err := parseServiceData(file)
 		if err != nil {
 			continue
 		}
-		for service, serviceDeps := range deps {
-			result[service] = serviceDeps
-		}
+		result[file.Name] = getDependencies(serviceData)
 	}
 
 	return result, nil
@@ -124,60 +137,17 @@ func (u *ServiceUtils) ResolveDependencies(selectedServices []string) ([]string,
 }
 
 // Helper methods
-func (u *ServiceUtils) getCategories() ([]string, error) {
-	entries, err := config.EmbeddedServicesFS.ReadDir("services")
-	if err != nil {
-		return nil, fmt.Errorf("failed to read services directory: %w", err)
-	}
-
-	var categories []string
-	for _, entry := range entries {
-		if entry.IsDir() {
-			categories = append(categories, entry.Name())
-		}
-	}
-	return categories, nil
-}
-
-func (u *ServiceUtils) getServicesInCategory(category string) ([]types.ServiceInfo, error) {
-	categoryPath := fmt.Sprintf("services/%s", category)
-	entries, err := config.EmbeddedServicesFS.ReadDir(categoryPath)
-	if err != nil {
-		return nil, err
-	}
-
-	var services []types.ServiceInfo
-	for _, entry := range entries {
-		if entry.IsDir() || !strings.HasSuffix(entry.Name(), constants.ServiceConfigExtension) {
-			continue
-		}
-
-		serviceName := strings.TrimSuffix(entry.Name(), constants.ServiceConfigExtension)
-		serviceInfo, err := u.parseServiceInfo(categoryPath, entry.Name(), serviceName, category)
-		if err != nil {
-			continue
-		}
-		services = append(services, serviceInfo)
-	}
-
-	return services, nil
-}
-
-func (u *ServiceUtils) parseServiceInfo(categoryPath, fileName, serviceName, category string) (types.ServiceInfo, error) {
-	serviceFile := fmt.Sprintf("%s/%s", categoryPath, fileName)
-	data, err := config.EmbeddedServicesFS.ReadFile(serviceFile)
+func (u *ServiceUtils) parseServiceInfo(file services.ServiceFile) (types.ServiceInfo, error) {
+	serviceData, err := parseServiceData(file)
 	if err != nil {
 		return types.ServiceInfo{}, err
 	}
 
-	var serviceData map[string]interface{}
-	if err := yaml.Unmarshal(data, &serviceData); err != nil {
-		return types.ServiceInfo{}, err
-	}
-
 	return types.ServiceInfo{
-		Name:         serviceName,
-		Category:     category,
+		Name:         file.Name,
+		Category:     file.Category,
+		Source:       file.Source,
+		Overrides:    file.Overrides,
 		Description:  getString(serviceData, "description"),
 		UsageNotes:   getString(serviceData, "usage_notes"),
 		Dependencies: getDependencies(serviceData),
@@ -187,48 +157,9 @@ func (u *ServiceUtils) parseServiceInfo(categoryPath, fileName, serviceName, cat
 	}, nil
 }
 
-func (u *ServiceUtils) loadServiceFromCategory(category, serviceName string) (*types.ServiceConfig, error) {
-	servicePath := fmt.Sprintf("services/%s/%s%s", category, serviceName, constants.ServiceConfigExtension)
-	data, err := config.EmbeddedServicesFS.ReadFile(servicePath)
-	if err != nil {
-		return nil, err
-	}
-
-	var serviceConfig types.ServiceConfig
-	if err := yaml.Unmarshal(data, &serviceConfig); err != nil {
-		return nil, fmt.Errorf("failed to parse service config for %s: %w", serviceName, err)
-	}
-
-	return &serviceConfig, nil
-}
-
-func (u *ServiceUtils) getDependenciesInCategory(category string) (map[string][]string, error) {
-	categoryPath := fmt.Sprintf("services/%s", category)
-	entries, err := config.EmbeddedServicesFS.ReadDir(categoryPath)
-	if err != nil {
-		return nil, err
-	}
-
-	result := make(map[string][]string)
-	for _, entry := range entries {
-		if entry.IsDir() || !strings.HasSuffix(entry.Name(), constants.ServiceConfigExtension) {
-			continue
-		}
-
-		serviceName := strings.TrimSuffix(entry.Name(), constants.ServiceConfigExtension)
-		deps, err := u.parseServiceDependencies(categoryPath, entry.Name())
-		if err != nil {
-			continue
-		}
-		result[serviceName] = deps
-	}
-
-	return result, nil
-}
-
-func (u *ServiceUtils) parseServiceDependencies(categoryPath, fileName string) ([]string, error) {
-	serviceFile := fmt.Sprintf("%s/%s", categoryPath, fileName)
-	data, err := config.EmbeddedServicesFS.ReadFile(serviceFile)
+// Helper functions
+func parseServiceData(file services.ServiceFile) (map[string]interface{}, error) {
+	data, err := file.Read()
 	if err != nil {
 		return nil, err
 	}
@@ -237,11 +168,9 @@ func (u *ServiceUtils) parseServiceDependencies(categoryPath, fileName string) (
 	if err := yaml.Unmarshal(data, &serviceData); err != nil {
 		return nil, err
 	}
-
-	return getDependencies(serviceData), nil
+	return serviceData, nil
 }
 
-// Helper functions
 func getString(data map[string]interface{}, key string) string {
 	if val, exists := data[key]; exists {
 		if str, ok := val.(string); ok {
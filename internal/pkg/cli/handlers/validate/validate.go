@@ -2,14 +2,19 @@ package validate
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"os"
+	"path/filepath"
 
 	"github.com/isaacgarza/dev-stack/internal/pkg/cli/handlers/utils"
 	"github.com/isaacgarza/dev-stack/internal/pkg/cli/types"
 	"github.com/isaacgarza/dev-stack/internal/pkg/config"
 	"github.com/isaacgarza/dev-stack/internal/pkg/constants"
+	"github.com/isaacgarza/dev-stack/internal/pkg/render"
+	"github.com/isaacgarza/dev-stack/internal/pkg/ui"
+	"github.com/pmezard/go-difflib/difflib"
 	"github.com/spf13/cobra"
 )
 
@@ -24,17 +29,71 @@ func NewValidateHandler() *ValidateHandler {
 // Handle executes the validate command
 func (h *ValidateHandler) Handle(ctx context.Context, cmd *cobra.Command, args []string, base *types.BaseCommand) error {
 	flags := utils.GetCIFlags(cmd)
-
-	// Load configuration
-	loader := config.NewLoader("")
-	commandConfig, err := loader.Load()
+	output, err := utils.GetRenderOptions(cmd)
 	if err != nil {
-		utils.HandleError(flags, fmt.Errorf("failed to load configuration: %w", err))
-		return nil
+		return err
+	}
+	// Errors are reported as JSON along with the results
+	flags.JSON = output.Format == render.FormatJSON
+
+	fix, _ := cmd.Flags().GetBool("fix")
+	write, _ := cmd.Flags().GetBool("write")
+	if write && !fix {
+		return errors.New("--write needs --fix")
+	}
+
+	var path string
+	if len(args) > 0 {
+		path = args[0]
 	}
+	loader := config.NewLoader(path)
 
-	// Validate configuration
-	result := commandConfig.Validate()
+	// Initialized projects without a commands file validate their project
+	// configuration
+	configPath, _ := loader.GetConfigPath()
+	projectPath := filepath.Join(constants.DevStackDir, constants.ConfigFileName)
+	validatesProject := false
+	if path == "" && configPath == "" {
+		if _, err := os.Stat(projectPath); err == nil {
+			validatesProject = true
+			configPath = projectPath
+		}
+	}
+
+	var fixes *fixResult
+	var result *config.ValidationResult
+	if validatesProject {
+		var err error
+		if result, fixes, err = h.validateProject(projectPath, fix, write); err != nil {
+			utils.HandleError(flags, err)
+			return nil
+		}
+	} else {
+		// Fix the file first, since files that fail to load are the ones
+		// most in need of fixes
+		var commandConfig *config.CommandConfig
+		if fix {
+			var err error
+			if fixes, err = h.fix(loader, write); err != nil {
+				utils.HandleError(flags, err)
+				return nil
+			}
+			if commandConfig, err = config.LoadFromBytes(fixes.content); err != nil {
+				utils.HandleError(flags, fmt.Errorf("failed to load configuration: %w", err))
+				return nil
+			}
+		} else {
+			var err error
+			if commandConfig, err = loader.Load(); err != nil {
+				if ui.DefaultOutput.GitHub && !flags.JSON {
+					ui.FileError(configPath, "%v", err)
+				}
+				utils.HandleError(flags, fmt.Errorf("failed to load configuration: %w", err))
+				return nil
+			}
+		}
+		result = commandConfig.Validate()
+	}
 
 	// Handle CI exit codes
 	exitCode := constants.ExitSuccess
@@ -46,10 +105,19 @@ func (h *ValidateHandler) Handle(ctx context.Context, cmd *cobra.Command, args [
 	}
 
 	// Output results
-	if flags.JSON {
-		h.outputJSON(*result, exitCode)
+	if output.Format.Structured() {
+		h.outputData(cmd.OutOrStdout(), output.Format, *result, fixes, exitCode)
 	} else if !flags.Quiet {
-		h.outputTable(*result, exitCode)
+		if fixes != nil {
+			h.outputFixes(fixes)
+		}
+		// Findings annotate the file in GitHub Actions jobs
+		if err := h.outputTable(cmd.OutOrStdout(), *result, configPath, output); err != nil {
+			return err
+		}
+		if exitCode != constants.ExitSuccess {
+			os.Exit(exitCode)
+		}
 	} else if exitCode != constants.ExitSuccess {
 		// Quiet mode with error
 		os.Exit(exitCode)
@@ -58,44 +126,231 @@ func (h *ValidateHandler) Handle(ctx context.Context, cmd *cobra.Command, args [
 	return nil
 }
 
-func (h *ValidateHandler) outputJSON(result config.ValidationResult, exitCode int) {
+// fixResult is what validate --fix did to a commands file or project
+// configuration
+type fixResult struct {
+	path    string
+	content []byte
+	fixes   []config.Fix
+	diff    string
+	written bool
+}
+
+// fixContent returns content with the safe fixes applied, checking
+// references against the service catalog in services
+type fixContent func(data []byte, services []string) ([]byte, []config.Fix, error)
+
+// fix applies the safe fixes to the commands file of loader, writing them
+// back only with write
+func (h *ValidateHandler) fix(loader *config.Loader, write bool) (*fixResult, error) {
+	path, err := loader.GetConfigPath()
+	if err != nil {
+		return nil, fmt.Errorf("no commands file to fix: %w", err)
+	}
+	return h.fixFile(path, write, config.FixCommands)
+}
+
+// validateProject validates the project configuration at path, fixing it
+// first with fix
+func (h *ValidateHandler) validateProject(path string, fix, write bool) (*config.ValidationResult, *fixResult, error) {
+	var fixes *fixResult
+	var data []byte
+	if fix {
+		var err error
+		if fixes, err = h.fixProject(path, write); err != nil {
+			return nil, nil, err
+		}
+		data = fixes.content
+	} else {
+		var err error
+		if data, err = os.ReadFile(path); err != nil {
+			return nil, nil, fmt.Errorf("failed to read config file %s: %w", path, err)
+		}
+	}
+
+	result, err := config.ValidateProjectConfig(data, catalogServices())
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load configuration: %w", err)
+	}
+	return result, fixes, nil
+}
+
+// fixProject applies the safe fixes to the project configuration at path,
+// naming a project without a name after its directory
+func (h *ValidateHandler) fixProject(path string, write bool) (*fixResult, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve %s: %w", path, err)
+	}
+	name := filepath.Base(filepath.Dir(filepath.Dir(abs)))
+	return h.fixFile(path, write, func(data []byte, services []string) ([]byte, []config.Fix, error) {
+		return config.FixProjectConfig(data, name, services)
+	})
+}
+
+// catalogServices returns the names of the services in the catalog, or nil
+// when it doesn't load
+func catalogServices() []string {
+	byCategory, err := utils.NewServiceUtils().GetServicesByCategory()
+	if err != nil {
+		return nil
+	}
+	var services []string
+	for _, infos := range byCategory {
+		for _, info := range infos {
+			services = append(services, info.Name)
+		}
+	}
+	return services
+}
+
+// fixFile applies the fixes of apply to the file at path, writing them
+// back only with write
+func (h *ValidateHandler) fixFile(path string, write bool, apply fixContent) (*fixResult, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file %s: %w", path, err)
+	}
+
+	// References are only checked against the service catalog when it loads
+	content, fixes, err := apply(data, catalogServices())
+	if err != nil {
+		return nil, err
+	}
+	result := &fixResult{path: path, content: content, fixes: fixes}
+	if len(fixes) == 0 {
+		return result, nil
+	}
+
+	if result.diff, err = difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        difflib.SplitLines(string(data)),
+		B:        difflib.SplitLines(string(content)),
+		FromFile: path,
+		ToFile:   path + " (fixed)",
+		Context:  3,
+	}); err != nil {
+		return nil, fmt.Errorf("failed to diff the fixes: %w", err)
+	}
+
+	if write {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read config file %s: %w", path, err)
+		}
+		if err := os.WriteFile(path, content, info.Mode().Perm()); err != nil {
+			return nil, fmt.Errorf("failed to write config file %s: %w", path, err)
+		}
+		result.written = true
+	}
+	return result, nil
+}
+
+// outputFixes prints the fixes with a diff of the changes they make
+func (h *ValidateHandler) outputFixes(result *fixResult) {
+	if len(result.fixes) == 0 {
+		fmt.Printf("✅ Nothing to fix in %s\n", result.path)
+		return
+	}
+
+	fmt.Printf("🔧 %d fixes for %s:\n", len(result.fixes), result.path)
+	for _, fix := range result.fixes {
+		fmt.Printf("  - %s: %s\n", fix.Field, fix.Message)
+	}
+	fmt.Println()
+	fmt.Print(result.diff)
+	fmt.Println()
+
+	if result.written {
+		fmt.Printf("✅ Wrote the fixes to %s\n", result.path)
+	} else {
+		fmt.Println("ℹ️  Run with --write to apply the fixes; the results below are for the fixed file")
+	}
+}
+
+// outputData prints the results as JSON or YAML
+func (h *ValidateHandler) outputData(w io.Writer, format render.Format, result config.ValidationResult, fixes *fixResult, exitCode int) {
 	output := map[string]interface{}{
 		"valid":     result.Valid,
 		"errors":    h.formatErrors(result.Errors),
 		"warnings":  h.formatWarnings(result.Warnings),
 		"exit_code": exitCode,
 	}
+	if fixes != nil {
+		applied := fixes.fixes
+		if applied == nil {
+			applied = []config.Fix{}
+		}
+		output["fixes"] = applied
+		output["diff"] = fixes.diff
+		output["written"] = fixes.written
+	}
 
-	_ = json.NewEncoder(os.Stdout).Encode(output)
+	_ = render.Data(w, format, output)
 
 	if exitCode != constants.ExitSuccess {
 		os.Exit(exitCode)
 	}
 }
 
-func (h *ValidateHandler) outputTable(result config.ValidationResult, exitCode int) {
+// Severities of the findings
+const (
+	severityError   = "error"
+	severityWarning = "warning"
+)
+
+// finding is an error or warning of the validation, as the table lists it
+type finding struct {
+	severity string
+	config.ValidationError
+}
+
+// findingColumns are the columns of the table of findings
+var findingColumns = []render.Column[finding]{
+	{Name: "SEVERITY", Value: func(f finding) string { return f.severity }, Style: func(f finding, value string) string {
+		if f.severity == severityError {
+			return ui.ErrorText.Render(value)
+		}
+		return ui.WarningText.Render(value)
+	}},
+	{Name: "FIELD", Value: func(f finding) string { return f.Field }},
+	{Name: "MESSAGE", Value: func(f finding) string { return f.Message }},
+	{Name: "CODE", Wide: true, Value: func(f finding) string { return f.Code }},
+}
+
+// outputTable prints the findings under a line counting them, or as
+// annotations of path in GitHub Actions jobs
+func (h *ValidateHandler) outputTable(w io.Writer, result config.ValidationResult, path string, output render.Options) error {
 	if result.Valid && len(result.Warnings) == 0 {
-		fmt.Println("✅ Configuration is valid")
-		return
+		_, _ = fmt.Fprintln(w, "✅ Configuration is valid")
+		return nil
 	}
 
 	if !result.Valid {
-		fmt.Printf("❌ Configuration validation failed with %d errors:\n", len(result.Errors))
-		for _, err := range result.Errors {
-			fmt.Printf("  - %s: %s\n", err.Field, err.Message)
-		}
+		_, _ = fmt.Fprintf(w, "❌ Configuration validation failed with %d errors\n", len(result.Errors))
 	}
-
 	if len(result.Warnings) > 0 {
-		fmt.Printf("⚠️  %d warnings:\n", len(result.Warnings))
+		_, _ = fmt.Fprintf(w, "⚠️  %d warnings\n", len(result.Warnings))
+	}
+
+	if ui.DefaultOutput.GitHub {
+		for _, err := range result.Errors {
+			ui.FileError(path, "%s: %s", err.Field, err.Message)
+		}
 		for _, warning := range result.Warnings {
-			fmt.Printf("  - %s: %s\n", warning.Field, warning.Message)
+			ui.FileWarning(path, "%s: %s", warning.Field, warning.Message)
 		}
+		return nil
 	}
 
-	if exitCode != constants.ExitSuccess {
-		os.Exit(exitCode)
+	findings := make([]finding, 0, len(result.Errors)+len(result.Warnings))
+	for _, err := range result.Errors {
+		findings = append(findings, finding{severity: severityError, ValidationError: err})
 	}
+	for _, warning := range result.Warnings {
+		findings = append(findings, finding{severity: severityWarning, ValidationError: warning})
+	}
+	_, _ = fmt.Fprintln(w)
+	return render.Render(w, render.Table[finding]{Columns: findingColumns, Rows: findings}, output)
 }
 
 func (h *ValidateHandler) formatErrors(errors []config.ValidationError) []map[string]string {
@@ -122,6 +377,9 @@ func (h *ValidateHandler) formatWarnings(warnings []config.ValidationError) []ma
 
 // ValidateArgs validates the command arguments
 func (h *ValidateHandler) ValidateArgs(args []string) error {
+	if len(args) > 1 {
+		return errors.New("validate takes at most one file")
+	}
 	return nil
 }
 
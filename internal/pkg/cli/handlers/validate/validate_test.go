@@ -0,0 +1,124 @@
+package validate
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/isaacgarza/dev-stack/internal/pkg/config"
+	"github.com/isaacgarza/dev-stack/internal/pkg/constants"
+	"github.com/isaacgarza/dev-stack/internal/pkg/render"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const brokenCommands = `metadata:
+  description: "Test CLI"
+categories:
+  core:
+    commands: ["up", "launch"]
+commands:
+  up:
+    category: "core"
+    description: "Start services"
+`
+
+func TestValidateHandler_Fix(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "commands.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(brokenCommands), 0644))
+	handler := NewValidateHandler()
+
+	// Without write the file is only previewed
+	result, err := handler.fix(config.NewLoader(path), false)
+	require.NoError(t, err)
+	assert.Len(t, result.fixes, 3)
+	assert.False(t, result.written)
+	assert.Contains(t, result.diff, `-    commands: ["up", "launch"]`)
+	assert.Contains(t, result.diff, `+    commands: ["up"]`)
+	assert.Contains(t, result.diff, `+    usage: "up"`)
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, brokenCommands, string(data))
+
+	_, err = config.LoadFromBytes(result.content)
+	require.NoError(t, err)
+
+	result, err = handler.fix(config.NewLoader(path), true)
+	require.NoError(t, err)
+	assert.True(t, result.written)
+	data, err = os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, string(result.content), string(data))
+
+	// A fixed file has nothing left to fix
+	result, err = handler.fix(config.NewLoader(path), true)
+	require.NoError(t, err)
+	assert.Empty(t, result.fixes)
+	assert.False(t, result.written)
+}
+
+func TestValidateHandler_FixProject(t *testing.T) {
+	root := filepath.Join(t.TempDir(), "shop")
+	path := filepath.Join(constants.DevStackDir, constants.ConfigFileName)
+	require.NoError(t, os.MkdirAll(filepath.Join(root, constants.DevStackDir), 0755))
+	t.Chdir(root)
+	broken := "project:\n  environment: local\nstack:\n  enabled:\n    - postgres\n    - not-a-service\n"
+	require.NoError(t, os.WriteFile(path, []byte(broken), 0644))
+	handler := NewValidateHandler()
+
+	// The missing name and the unknown service are errors until fixed
+	result, fixes, err := handler.validateProject(path, false, false)
+	require.NoError(t, err)
+	assert.Nil(t, fixes)
+	assert.False(t, result.Valid)
+	assert.Len(t, result.Errors, 2)
+
+	// Without write the file is only previewed
+	result, fixes, err = handler.validateProject(path, true, false)
+	require.NoError(t, err)
+	assert.True(t, result.Valid)
+	assert.Len(t, fixes.fixes, 2)
+	assert.False(t, fixes.written)
+	assert.Contains(t, fixes.diff, `+  name: "shop"`)
+	assert.Contains(t, fixes.diff, "-    - not-a-service")
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, broken, string(data))
+
+	_, fixes, err = handler.validateProject(path, true, true)
+	require.NoError(t, err)
+	assert.True(t, fixes.written)
+	data, err = os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, string(fixes.content), string(data))
+}
+
+func TestValidateHandler_ValidateArgs(t *testing.T) {
+	handler := NewValidateHandler()
+	assert.NoError(t, handler.ValidateArgs(nil))
+	assert.NoError(t, handler.ValidateArgs([]string{"commands.yaml"}))
+	assert.Error(t, handler.ValidateArgs([]string{"a.yaml", "b.yaml"}))
+}
+
+func TestValidateHandler_OutputTable(t *testing.T) {
+	handler := NewValidateHandler()
+	result := config.ValidationResult{
+		Valid:    false,
+		Errors:   []config.ValidationError{{Field: "commands.up.usage", Message: "usage is required", Code: "required"}},
+		Warnings: []config.ValidationError{{Field: "commands.down", Message: "no examples", Code: "examples"}},
+	}
+
+	var out bytes.Buffer
+	require.NoError(t, handler.outputTable(&out, result, "commands.yaml", render.Options{Format: render.FormatWide}))
+	assert.Equal(t, "❌ Configuration validation failed with 1 errors\n"+
+		"⚠️  1 warnings\n"+
+		"\n"+
+		"SEVERITY  FIELD              MESSAGE            CODE\n"+
+		"error     commands.up.usage  usage is required  required\n"+
+		"warning   commands.down      no examples        examples\n", out.String())
+
+	out.Reset()
+	require.NoError(t, handler.outputTable(&out, config.ValidationResult{Valid: true}, "commands.yaml", render.Options{}))
+	assert.Equal(t, "✅ Configuration is valid\n", out.String())
+}
@@ -35,8 +35,10 @@ func NewEnforcementHandler(manager version.VersionManager) *EnforcementHandler {
 
 	enforcer := version.NewVersionEnforcer(manager, policy)
 
-	// Use proper config path with constants
-	configPath := filepath.Join(os.Getenv("HOME"), ".dev-stack", constants.NotificationConfigFile)
+	// Use proper config path with constants. HOME isn't set on Windows,
+	// where the home directory is USERPROFILE.
+	home, _ := os.UserHomeDir()
+	configPath := filepath.Join(home, ".dev-stack", constants.NotificationConfigFile)
 	notifier := version.NewUpdateNotifier(manager, configPath)
 
 	return &EnforcementHandler{
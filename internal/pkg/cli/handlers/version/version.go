@@ -0,0 +1,132 @@
+package version
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/isaacgarza/dev-stack/internal/pkg/cli/handlers/utils"
+	"github.com/isaacgarza/dev-stack/internal/pkg/cli/types"
+	"github.com/isaacgarza/dev-stack/internal/pkg/constants"
+	"github.com/isaacgarza/dev-stack/internal/pkg/ui"
+	"github.com/isaacgarza/dev-stack/internal/pkg/version"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// updateCheckTimeout bounds the request for the latest release
+	updateCheckTimeout = 10 * time.Second
+	// updateCheckTTL is how long an explicit check trusts the previous
+	// answer; running version --check-updates in a loop shouldn't spend
+	// the 60 requests an hour GitHub allows anonymous clients
+	updateCheckTTL = time.Hour
+)
+
+// versionReport is the json and yaml output of the version command
+type versionReport struct {
+	*version.BuildInfo `yaml:",inline"`
+	Update             *version.UpdateCheck `json:"update,omitempty" yaml:"update,omitempty"`
+}
+
+// VersionHandler handles the version command
+type VersionHandler struct {
+	checker *version.UpdateChecker
+}
+
+// NewVersionHandler creates a new version handler
+func NewVersionHandler() *VersionHandler {
+	// HOME isn't set on Windows, where the home directory is USERPROFILE
+	cacheFile := ""
+	if home, err := os.UserHomeDir(); err == nil {
+		cacheFile = filepath.Join(home, ".dev-stack", constants.UpdateCheckCacheFile)
+	}
+	return &VersionHandler{
+		checker: version.NewUpdateChecker(&http.Client{Timeout: updateCheckTimeout}, constants.AppRepository, cacheFile, updateCheckTTL),
+	}
+}
+
+// Handle executes the version command
+func (h *VersionHandler) Handle(ctx context.Context, cmd *cobra.Command, args []string, base *types.BaseCommand) error {
+	full, _ := cmd.Flags().GetBool("full")
+	checkUpdates, _ := cmd.Flags().GetBool("check-updates")
+	format, _ := cmd.Flags().GetString("format")
+	if utils.GetCIFlags(cmd).JSON {
+		format = "json"
+	}
+
+	report := versionReport{BuildInfo: version.GetBuildInfo()}
+	if !full {
+		// The module dependencies are only listed with --full
+		report.ModuleInfo = nil
+	}
+	if checkUpdates {
+		check, err := h.checker.Check(ctx, report.Version, false)
+		if err != nil {
+			var rateLimited *version.RateLimitError
+			if errors.As(err, &rateLimited) {
+				return err
+			}
+			return fmt.Errorf("failed to check for updates: %w", err)
+		}
+		report.Update = check
+	}
+
+	out := cmd.OutOrStdout()
+	switch format {
+	case "json":
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(report)
+	case "yaml":
+		return yaml.NewEncoder(out).Encode(report)
+	case "table", "":
+		writeTable(out, report, full)
+		return nil
+	}
+	return fmt.Errorf("unsupported format %q (supported: table, json, yaml)", format)
+}
+
+// writeTable prints the version, the build information with full, and the
+// result of the update check
+func writeTable(out io.Writer, report versionReport, full bool) {
+	if full {
+		_, _ = fmt.Fprint(out, version.GetFormattedBuildInfo())
+	} else {
+		_, _ = fmt.Fprintln(out, version.GetFullVersion())
+	}
+
+	check := report.Update
+	if check == nil {
+		return
+	}
+	_, _ = fmt.Fprintln(out)
+	switch {
+	case check.UpdateAvailable:
+		ui.Warning("dev-stack %s is available (you have %s)", check.Latest, check.Current)
+		ui.Muted("Release notes: %s", check.URL)
+	case version.IsDevBuild():
+		ui.Info("This is a development build; the latest release is %s", check.Latest)
+	default:
+		ui.Success("dev-stack is up to date (latest release %s)", check.Latest)
+	}
+	if check.Cached {
+		ui.Muted("As of the check at %s", check.CheckedAt.Local().Format("Jan 2 15:04"))
+	}
+}
+
+// ValidateArgs validates the command arguments
+func (h *VersionHandler) ValidateArgs(args []string) error {
+	return nil
+}
+
+// GetRequiredFlags returns required flags for this command
+func (h *VersionHandler) GetRequiredFlags() []string {
+	return []string{}
+}
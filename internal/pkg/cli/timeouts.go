@@ -0,0 +1,41 @@
+package cli
+
+import (
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/isaacgarza/dev-stack/internal/core/docker"
+	"github.com/isaacgarza/dev-stack/internal/pkg/cli/handlers/core"
+	"github.com/isaacgarza/dev-stack/internal/pkg/constants"
+	"github.com/isaacgarza/dev-stack/internal/pkg/types"
+	"github.com/isaacgarza/dev-stack/internal/pkg/utils"
+	"github.com/spf13/cobra"
+)
+
+// applyTimeouts bounds Docker operations by the timeouts of the project
+// configuration, or by the global --timeout for every class of operation.
+// No command has a --timeout of its own, so it applies to all of them; the
+// waits and grace periods of commands such as up and down are set by
+// --wait-timeout and --stop-timeout.
+func applyTimeouts(cmd *cobra.Command) error {
+	var timeouts types.TimeoutConfig
+	configPath := filepath.Join(constants.DevStackDir, constants.ConfigFileName)
+	if utils.FileExists(configPath) {
+		// A configuration that doesn't load is reported by the command itself
+		if cfg, err := core.LoadProjectConfig(configPath); err == nil {
+			timeouts = cfg.Timeouts
+		}
+	}
+
+	if flag := cmd.Root().PersistentFlags().Lookup(constants.FlagTimeout); flag != nil && flag.Changed {
+		timeout, err := time.ParseDuration(flag.Value.String())
+		if err != nil || timeout <= 0 {
+			return fmt.Errorf("invalid --%s %q: must be a duration such as 90s or 5m", constants.FlagTimeout, flag.Value.String())
+		}
+		timeouts = types.TimeoutConfig{Start: timeout, Stop: timeout, Exec: timeout, Pull: timeout}
+	}
+
+	docker.SetTimeouts(timeouts)
+	return nil
+}
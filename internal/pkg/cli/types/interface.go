@@ -8,6 +8,8 @@ import (
 
 	"github.com/spf13/cobra"
 	"gopkg.in/yaml.v3"
+
+	"github.com/isaacgarza/dev-stack/internal/pkg/types"
 )
 
 // CommandHandler defines the interface for all command handlers
@@ -84,7 +86,7 @@ func (b *BaseCommand) ValidateServices(serviceNames []string) error {
 			for name := range servicesConfig {
 				availableServices = append(availableServices, name)
 			}
-			return fmt.Errorf("unknown service '%s'. Available services: %v", serviceName, availableServices)
+			return &types.ServiceNotFoundError{Service: serviceName, Available: availableServices}
 		}
 	}
 
@@ -1,5 +1,12 @@
 package types
 
+import (
+	"fmt"
+
+	"github.com/isaacgarza/dev-stack/internal/pkg/types"
+	"gopkg.in/yaml.v3"
+)
+
 // ServiceConfig represents the structure of service.yaml files
 type ServiceConfig struct {
 	Name        string `yaml:"name"`
@@ -11,12 +18,18 @@ type ServiceConfig struct {
 	Environment map[string]string `yaml:"environment"`
 	Docker      struct {
 		// Single service configuration (legacy)
-		Restart     string      `yaml:"restart,omitempty"`
-		Command     interface{} `yaml:"command,omitempty"` // Can be string or []string
-		Networks    []string    `yaml:"networks,omitempty"`
-		MemoryLimit string      `yaml:"memory_limit,omitempty"`
-		Environment []string    `yaml:"environment,omitempty"`
-		ExtraHosts  []string    `yaml:"extra_hosts,omitempty"`
+		Restart     string              `yaml:"restart,omitempty"`
+		Command     interface{}         `yaml:"command,omitempty"` // Can be string or []string
+		Networks    []string            `yaml:"networks,omitempty"`
+		MemoryLimit string              `yaml:"memory_limit,omitempty"`
+		Environment []string            `yaml:"environment,omitempty"`
+		ExtraHosts  []string            `yaml:"extra_hosts,omitempty"`
+		Ports       []string            `yaml:"ports,omitempty"` // Published alongside defaults.port
+		DependsOn   DependsOn           `yaml:"depends_on,omitempty"`
+		Profiles    []string            `yaml:"profiles,omitempty"`
+		Extends     ExtendsConfig       `yaml:"extends,omitempty"`
+		Build       BuildConfig         `yaml:"build,omitempty"`
+		Develop     types.DevelopConfig `yaml:"develop,omitempty"`
 		HealthCheck struct {
 			Test        []string `yaml:"test"`
 			Interval    string   `yaml:"interval"`
@@ -24,6 +37,12 @@ type ServiceConfig struct {
 			Retries     int      `yaml:"retries"`
 			StartPeriod string   `yaml:"start_period"`
 		} `yaml:"health_check,omitempty"`
+		// User is the user the container runs as, such as root for a
+		// service reading the Docker socket
+		User string `yaml:"user,omitempty"`
+		// Binds are host paths mounted into the container, in compose's
+		// short syntax, alongside the named volumes
+		Binds []string `yaml:"binds,omitempty"`
 
 		// Multi-service configuration (new)
 		Services map[string]DockerService `yaml:"services,omitempty"`
@@ -32,18 +51,62 @@ type ServiceConfig struct {
 		Name  string `yaml:"name"`
 		Mount string `yaml:"mount"`
 	} `yaml:"volumes"`
+	Readiness types.ReadinessConfig `yaml:"readiness,omitempty"`
+	// Shutdown is how down stops the service, overridden by the shutdown
+	// section of the project configuration
+	Shutdown types.ShutdownConfig `yaml:"shutdown,omitempty"`
+	// Proxy routes the service through the built-in reverse proxy when the
+	// stack enables it
+	Proxy struct {
+		// Port is the container port of the service's web interface
+		Port int `yaml:"port"`
+	} `yaml:"proxy,omitempty"`
+	WebInterfaces []WebInterface `yaml:"web_interfaces,omitempty"`
+	// Shared services run once on the host for every project that enables
+	// them, instead of once per project
+	Shared bool `yaml:"shared,omitempty"`
+	// Platforms is the compatibility table of the service's images: the
+	// architectures the versions it lists are published for
+	Platforms types.PlatformTable `yaml:"platforms,omitempty"`
+	// Security is what the service needs under a security profile
+	Security struct {
+		// CapAdd are the capabilities the service keeps when all others
+		// are dropped, such as those its entrypoint needs to hand its data
+		// directory to an unprivileged user
+		CapAdd []string `yaml:"cap_add,omitempty"`
+	} `yaml:"security,omitempty"`
+	// Trust is where the images of the service keep the certificates they
+	// trust
+	Trust struct {
+		// Store is the family of the trust store, debian, alpine or rhel;
+		// unset, it is guessed from the image tag
+		Store string `yaml:"store,omitempty"`
+		// Cacerts is the keystore of the JDK the image runs, if any
+		Cacerts string `yaml:"cacerts,omitempty"`
+	} `yaml:"trust,omitempty"`
+}
+
+// WebInterface is a browser URL a service serves on the host
+type WebInterface struct {
+	Name        string `yaml:"name"`
+	URL         string `yaml:"url"`
+	Description string `yaml:"description,omitempty"`
 }
 
 // DockerService represents a single service in multi-service configuration
 type DockerService struct {
-	Image       string      `yaml:"image"`
-	Restart     string      `yaml:"restart,omitempty"`
-	Command     interface{} `yaml:"command,omitempty"` // Can be string or []string
-	Networks    []string    `yaml:"networks,omitempty"`
-	MemoryLimit string      `yaml:"memory_limit,omitempty"`
-	Environment []string    `yaml:"environment,omitempty"`
-	ExtraHosts  []string    `yaml:"extra_hosts,omitempty"`
-	DependsOn   []string    `yaml:"depends_on,omitempty"`
+	Image       string              `yaml:"image"`
+	Restart     string              `yaml:"restart,omitempty"`
+	Command     interface{}         `yaml:"command,omitempty"` // Can be string or []string
+	Networks    []string            `yaml:"networks,omitempty"`
+	MemoryLimit string              `yaml:"memory_limit,omitempty"`
+	Environment []string            `yaml:"environment,omitempty"`
+	ExtraHosts  []string            `yaml:"extra_hosts,omitempty"`
+	DependsOn   DependsOn           `yaml:"depends_on,omitempty"`
+	Profiles    []string            `yaml:"profiles,omitempty"`
+	Extends     ExtendsConfig       `yaml:"extends,omitempty"`
+	Build       BuildConfig         `yaml:"build,omitempty"`
+	Develop     types.DevelopConfig `yaml:"develop,omitempty"`
 	HealthCheck struct {
 		Test        []string `yaml:"test"`
 		Interval    string   `yaml:"interval"`
@@ -53,11 +116,79 @@ type DockerService struct {
 	} `yaml:"health_check,omitempty"`
 }
 
+// BuildConfig represents a local image build for a service
+type BuildConfig struct {
+	Context    string `yaml:"context,omitempty"`
+	Dockerfile string `yaml:"dockerfile,omitempty"`
+	// Target is the stage of a multi-stage Dockerfile to build
+	Target string `yaml:"target,omitempty"`
+	// Args are build arguments; services.<name>.build_args in the project
+	// configuration adds to and overrides them
+	Args map[string]string `yaml:"args,omitempty"`
+	// CacheFrom lists images to reuse build cache from, such as an image
+	// CI pushes for every main branch build
+	CacheFrom []string `yaml:"cache_from,omitempty"`
+	// Secrets lists environment variables exposed to the build as BuildKit
+	// secrets (RUN --mount=type=secret,id=NAME) rather than build args
+	Secrets []string `yaml:"secrets,omitempty"`
+}
+
+// ExtendsConfig points a service at another service definition it inherits
+// from, optionally in another compose file
+type ExtendsConfig struct {
+	File    string `yaml:"file,omitempty"`
+	Service string `yaml:"service,omitempty"`
+}
+
+// Dependency is a service another service waits for before starting
+type Dependency struct {
+	Name string
+	// Condition is a compose depends_on condition; empty picks
+	// service_healthy for dependencies with a health check and
+	// service_started otherwise
+	Condition string
+}
+
+// DependsOn lists service dependencies. It accepts the compose short form,
+// a list of service names, and the long form mapping each name to its
+// condition.
+type DependsOn []Dependency
+
+// UnmarshalYAML decodes both depends_on forms, keeping the long form's order
+func (d *DependsOn) UnmarshalYAML(node *yaml.Node) error {
+	switch node.Kind {
+	case yaml.SequenceNode:
+		var names []string
+		if err := node.Decode(&names); err != nil {
+			return err
+		}
+		for _, name := range names {
+			*d = append(*d, Dependency{Name: name})
+		}
+		return nil
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			var options struct {
+				Condition string `yaml:"condition"`
+			}
+			if err := node.Content[i+1].Decode(&options); err != nil {
+				return err
+			}
+			*d = append(*d, Dependency{Name: node.Content[i].Value, Condition: options.Condition})
+		}
+		return nil
+	default:
+		return fmt.Errorf("depends_on must be a list or a mapping, line %d", node.Line)
+	}
+}
+
 // ServiceInfo represents service information for display
 type ServiceInfo struct {
 	Name         string
 	Description  string
 	Category     string
+	Source       string // built-in or project
+	Overrides    bool   // a project definition replacing a built-in one
 	Dependencies []string
 	Options      []string
 	Examples     []string
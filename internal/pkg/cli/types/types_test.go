@@ -0,0 +1,50 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func TestDependsOnUnmarshalYAML(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected DependsOn
+		wantErr  bool
+	}{
+		{
+			name:     "short form",
+			input:    "depends_on: [zookeeper, postgres]",
+			expected: DependsOn{{Name: "zookeeper"}, {Name: "postgres"}},
+		},
+		{
+			name:  "long form",
+			input: "depends_on:\n  migrate:\n    condition: service_completed_successfully\n  postgres: {}\n",
+			expected: DependsOn{
+				{Name: "migrate", Condition: "service_completed_successfully"},
+				{Name: "postgres"},
+			},
+		},
+		{
+			name:    "scalar",
+			input:   "depends_on: postgres",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var service DockerService
+			err := yaml.Unmarshal([]byte(tt.input), &service)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, service.DependsOn)
+		})
+	}
+}
@@ -0,0 +1,54 @@
+package cli
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/isaacgarza/dev-stack/internal/core/telemetry"
+	"github.com/isaacgarza/dev-stack/internal/pkg/config"
+	"github.com/isaacgarza/dev-stack/internal/pkg/version"
+	"github.com/spf13/cobra"
+)
+
+// usageFlushBatch is how many usage events are buffered before they are
+// sent, so most commands don't wait on the endpoint
+const usageFlushBatch = 20
+
+// usageFlushTimeout bounds sending a batch of usage events
+const usageFlushTimeout = 2 * time.Second
+
+// recordUsage buffers the usage event of a command that ran for duration
+// and returned err, when the user opted in to usage metrics, then sends
+// the buffer once a batch is full. Failures are only logged at debug
+// level: metrics never get in the way of a command.
+func recordUsage(cmd *cobra.Command, duration time.Duration, err error, log *slog.Logger) {
+	user, loadErr := config.LoadUserConfig()
+	if loadErr != nil || !user.UsageMetricsEnabled() {
+		return
+	}
+	path, pathErr := config.UsageMetricsPath()
+	if pathErr != nil {
+		return
+	}
+
+	buffer := telemetry.NewBuffer(path)
+	if err := buffer.Append(telemetry.NewEvent(cmd.CommandPath(), duration, err, version.GetShortVersion())); err != nil {
+		log.Debug("Failed to record usage metrics", "error", err)
+		return
+	}
+
+	endpoint := user.UsageMetricsEndpoint()
+	if endpoint == "" {
+		return
+	}
+	if events, err := buffer.Events(); err != nil || len(events) < usageFlushBatch {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), usageFlushTimeout)
+	defer cancel()
+	if _, err := buffer.Flush(ctx, &http.Client{Timeout: usageFlushTimeout}, endpoint, version.GetUserAgent()); err != nil {
+		log.Debug("Failed to send usage metrics", "error", err)
+	}
+}
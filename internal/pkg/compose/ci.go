@@ -0,0 +1,96 @@
+package compose
+
+import (
+	"bytes"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/isaacgarza/dev-stack/internal/pkg/constants"
+)
+
+// ciFileName is the compose file, under dev-stack/tmp, that pins the ports
+// of a CI stack
+const ciFileName = "docker-compose.ci.yml"
+
+// CIFile returns the path of the compose file that pins the published ports
+// of a stack started by ci up, merged over the others while it exists
+func CIFile() string {
+	return filepath.Join(constants.DevStackDir, constants.TmpDir, ciFileName)
+}
+
+// CIPorts returns the port mappings each service of the compose files
+// publishes, host first, with ${VAR:-default} references resolved with
+// lookup. Ports published on a random host port get their container port,
+// so a CI stack publishes the same ports on every run and the connection
+// variables of its services point at them.
+func CIPorts(lookup func(string) (string, bool), composeFiles ...string) (map[string][]string, error) {
+	resources, err := ProjectResources("", lookup, composeFiles...)
+	if err != nil {
+		return nil, err
+	}
+
+	ports := make(map[string][]string)
+	for name, svc := range resources.Services {
+		for _, mapping := range svc.Ports {
+			pinned := pinPort(mapping)
+			if !slices.Contains(ports[name], pinned) {
+				ports[name] = append(ports[name], pinned)
+			}
+		}
+	}
+	return ports, nil
+}
+
+// pinPort returns a port mapping, such as 127.0.0.1:5432:5432/tcp, with
+// its container port as host port when it has none
+func pinPort(mapping string) string {
+	spec, protocol, _ := strings.Cut(mapping, "/")
+	fields := strings.Split(spec, ":")
+	if len(fields) > 1 && fields[len(fields)-2] != "" {
+		return mapping
+	}
+	container := fields[len(fields)-1]
+	pinned := container + ":" + container
+	if len(fields) > 2 {
+		pinned = strings.Join(fields[:len(fields)-2], ":") + ":" + pinned
+	}
+	if protocol != "" {
+		pinned += "/" + protocol
+	}
+	return pinned
+}
+
+// CIOverride renders a compose file replacing the published ports of each
+// service with ports. Compose adds the ports of later files to those of
+// earlier ones, so the lists are tagged !override, which Docker Compose
+// 2.24 and newer understand.
+func CIOverride(ports map[string][]string) ([]byte, error) {
+	services := &yaml.Node{Kind: yaml.MappingNode}
+	for _, name := range sortedKeys(ports) {
+		list := &yaml.Node{Kind: yaml.SequenceNode, Tag: "!override"}
+		for _, mapping := range ports[name] {
+			list.Content = append(list.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: mapping, Style: yaml.DoubleQuotedStyle})
+		}
+		service := &yaml.Node{Kind: yaml.MappingNode, Content: []*yaml.Node{
+			{Kind: yaml.ScalarNode, Value: "ports"}, list,
+		}}
+		services.Content = append(services.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: name}, service)
+	}
+	root := &yaml.Node{Kind: yaml.MappingNode, Content: []*yaml.Node{
+		{Kind: yaml.ScalarNode, Value: "services"}, services,
+	}}
+
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(root); err != nil {
+		return nil, err
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
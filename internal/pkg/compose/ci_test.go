@@ -0,0 +1,68 @@
+package compose
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCIPorts(t *testing.T) {
+	dir := t.TempDir()
+	base := filepath.Join(dir, "docker-compose.yml")
+	override := filepath.Join(dir, "docker-compose.override.yml")
+	require.NoError(t, os.WriteFile(base, []byte(`services:
+  postgres:
+    image: postgres:16
+    ports: ["5432:5432"]
+  kafka:
+    image: apache/kafka
+    ports:
+      - "${KAFKA_PORT:-9092}:9092"
+      - target: 29092
+        published: 29092
+        host_ip: 127.0.0.1
+  dns:
+    image: coredns/coredns
+    ports: ["53:53/udp", "8080", "127.0.0.1::8081"]
+  worker:
+    image: busybox
+`), 0644))
+	require.NoError(t, os.WriteFile(override, []byte(`services:
+  postgres:
+    ports: ["127.0.0.1:6432-6433:6432-6433"]
+`), 0644))
+	lookup := func(key string) (string, bool) {
+		if key == "KAFKA_PORT" {
+			return "19092", true
+		}
+		return "", false
+	}
+
+	ports, err := CIPorts(lookup, base, override)
+	require.NoError(t, err)
+	assert.Equal(t, map[string][]string{
+		"postgres": {"5432:5432", "127.0.0.1:6432-6433:6432-6433"},
+		"kafka":    {"19092:9092", "127.0.0.1:29092:29092"},
+		"dns":      {"53:53/udp", "8080:8080", "127.0.0.1:8081:8081"},
+	}, ports)
+}
+
+func TestCIOverride(t *testing.T) {
+	data, err := CIOverride(map[string][]string{
+		"redis":    {"6479:6379"},
+		"postgres": {"5532:5432", "127.0.0.1:5533:5433"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, `services:
+  postgres:
+    ports: !override
+      - "5532:5432"
+      - "127.0.0.1:5533:5433"
+  redis:
+    ports: !override
+      - "6479:6379"
+`, string(data))
+}
@@ -0,0 +1,395 @@
+package compose
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/kballard/go-shellquote"
+	"gopkg.in/yaml.v3"
+
+	"github.com/isaacgarza/dev-stack/internal/pkg/types"
+	"github.com/isaacgarza/dev-stack/internal/pkg/utils"
+)
+
+// Definition is a service of the compose files as far as running it
+// somewhere else than compose is concerned, with ${VAR:-default} references
+// resolved
+type Definition struct {
+	Name  string
+	Image string
+	// Build is set when compose builds the image of the service
+	Build       bool
+	Entrypoint  []string
+	Command     []string
+	Environment map[string]string
+	Ports       []PortSpec
+	Mounts      []MountSpec
+	// Networks are the keys of the networks the service joins
+	Networks    []string
+	Healthcheck *Healthcheck
+	Limits      types.ResourceLimits
+}
+
+// PortSpec is a single port the service publishes. Published is 0 when
+// compose picks a random host port.
+type PortSpec struct {
+	HostIP    string
+	Published int
+	Target    int
+	Protocol  string
+}
+
+// MountSpec is a volumes entry of a service. Source is the volume key for
+// named volumes, the host path for bind mounts and empty for anonymous
+// volumes and tmpfs.
+type MountSpec struct {
+	Type     string
+	Source   string
+	Target   string
+	ReadOnly bool
+}
+
+// Mount types of compose
+const (
+	MountVolume = "volume"
+	MountBind   = "bind"
+	MountTmpfs  = "tmpfs"
+)
+
+// Healthcheck is the healthcheck of a service. Test is the command form
+// compose uses, such as [CMD-SHELL, pg_isready].
+type Healthcheck struct {
+	Test        []string
+	Interval    string
+	Timeout     string
+	Retries     int
+	StartPeriod string
+}
+
+// definitionService is a service of a compose file as far as definitions
+// are concerned
+type definitionService struct {
+	Image       string    `yaml:"image"`
+	Build       any       `yaml:"build"`
+	Entrypoint  yaml.Node `yaml:"entrypoint"`
+	Command     yaml.Node `yaml:"command"`
+	Environment yaml.Node `yaml:"environment"`
+	Ports       []any     `yaml:"ports"`
+	Volumes     []any     `yaml:"volumes"`
+	Networks    yaml.Node `yaml:"networks"`
+	Healthcheck *struct {
+		Test        yaml.Node `yaml:"test"`
+		Interval    string    `yaml:"interval"`
+		Timeout     string    `yaml:"timeout"`
+		Retries     int       `yaml:"retries"`
+		StartPeriod string    `yaml:"start_period"`
+		Disable     bool      `yaml:"disable"`
+	} `yaml:"healthcheck"`
+}
+
+// Definitions returns the services of the compose files, sorted by name,
+// with ${VAR:-default} references resolved with lookup. Files are merged in
+// order: later files replace the image, command, entrypoint, networks and
+// healthcheck of a service, override single environment variables and add
+// to its ports and volumes. The env_file entries of services aren't read.
+func Definitions(lookup func(string) (string, bool), composeFiles ...string) ([]Definition, error) {
+	expand := func(s string) string { return utils.ExpandEnv(s, lookup) }
+
+	definitions := make(map[string]*Definition)
+	for _, composeFile := range composeFiles {
+		data, err := os.ReadFile(composeFile)
+		if err != nil {
+			return nil, err
+		}
+
+		var f struct {
+			Services map[string]definitionService `yaml:"services"`
+		}
+		if err := yaml.Unmarshal(data, &f); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", composeFile, err)
+		}
+
+		for name, svc := range f.Services {
+			def, ok := definitions[name]
+			if !ok {
+				def = &Definition{Name: name, Environment: make(map[string]string)}
+				definitions[name] = def
+			}
+			if err := def.merge(svc, expand, lookup); err != nil {
+				return nil, fmt.Errorf("service %s in %s: %w", name, composeFile, err)
+			}
+		}
+	}
+
+	resources, err := ServiceResources(composeFiles...)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]Definition, 0, len(definitions))
+	for _, name := range sortedKeys(definitions) {
+		def := definitions[name]
+		def.Limits = resources[name]
+		if len(def.Networks) == 0 {
+			def.Networks = []string{defaultNetwork}
+		}
+		result = append(result, *def)
+	}
+	return result, nil
+}
+
+// merge applies what svc sets over the definition
+func (d *Definition) merge(svc definitionService, expand func(string) string, lookup func(string) (string, bool)) error {
+	if svc.Image != "" {
+		d.Image = expand(svc.Image)
+	}
+	if svc.Build != nil {
+		d.Build = true
+	}
+
+	var err error
+	if svc.Entrypoint.Kind != 0 {
+		if d.Entrypoint, err = commandArgs(svc.Entrypoint, expand); err != nil {
+			return fmt.Errorf("invalid entrypoint: %w", err)
+		}
+	}
+	if svc.Command.Kind != 0 {
+		if d.Command, err = commandArgs(svc.Command, expand); err != nil {
+			return fmt.Errorf("invalid command: %w", err)
+		}
+	}
+	if err := mergeEnvironment(d.Environment, svc.Environment, expand, lookup); err != nil {
+		return err
+	}
+
+	for _, port := range svc.Ports {
+		specs, err := parsePortMapping(portMapping(port, expand))
+		if err != nil {
+			return err
+		}
+		for _, spec := range specs {
+			if !slices.Contains(d.Ports, spec) {
+				d.Ports = append(d.Ports, spec)
+			}
+		}
+	}
+	for _, volume := range svc.Volumes {
+		mount, ok := mountSpec(volume, expand)
+		if !ok {
+			continue
+		}
+		// A later entry for the same container path replaces the earlier one
+		d.Mounts = slices.DeleteFunc(d.Mounts, func(m MountSpec) bool { return m.Target == mount.Target })
+		d.Mounts = append(d.Mounts, mount)
+	}
+
+	if svc.Networks.Kind != 0 {
+		d.Networks = networkKeys(svc.Networks)
+	}
+
+	if check := svc.Healthcheck; check != nil {
+		if check.Disable {
+			d.Healthcheck = nil
+			return nil
+		}
+		test, err := healthcheckTest(check.Test, expand)
+		if err != nil {
+			return err
+		}
+		if len(test) > 0 && test[0] == "NONE" {
+			d.Healthcheck = nil
+			return nil
+		}
+		d.Healthcheck = &Healthcheck{
+			Test:        test,
+			Interval:    check.Interval,
+			Timeout:     check.Timeout,
+			Retries:     check.Retries,
+			StartPeriod: check.StartPeriod,
+		}
+	}
+	return nil
+}
+
+// commandArgs returns a command or entrypoint, in its string or list form,
+// as arguments. Strings are split like a shell splits them.
+func commandArgs(node yaml.Node, expand func(string) string) ([]string, error) {
+	switch node.Kind {
+	case yaml.ScalarNode:
+		return shellquote.Split(expand(node.Value))
+	case yaml.SequenceNode:
+		var args []string
+		if err := node.Decode(&args); err != nil {
+			return nil, err
+		}
+		for i, arg := range args {
+			args[i] = expand(arg)
+		}
+		return args, nil
+	}
+	return nil, fmt.Errorf("expected a string or a list, line %d", node.Line)
+}
+
+// healthcheckTest returns the test of a healthcheck in its list form; a
+// string is a command run by the shell of the container
+func healthcheckTest(node yaml.Node, expand func(string) string) ([]string, error) {
+	switch node.Kind {
+	case 0:
+		return nil, nil
+	case yaml.ScalarNode:
+		return []string{"CMD-SHELL", expand(node.Value)}, nil
+	case yaml.SequenceNode:
+		var test []string
+		if err := node.Decode(&test); err != nil {
+			return nil, fmt.Errorf("invalid healthcheck test: %w", err)
+		}
+		for i, arg := range test {
+			test[i] = expand(arg)
+		}
+		return test, nil
+	}
+	return nil, fmt.Errorf("invalid healthcheck test, line %d", node.Line)
+}
+
+// mergeEnvironment sets the variables of an environment entry, in its list
+// or map form, in env. Variables without a value take theirs from lookup
+// and are left out when it has none, like compose does.
+func mergeEnvironment(env map[string]string, node yaml.Node, expand func(string) string, lookup func(string) (string, bool)) error {
+	set := func(key string, value *string) {
+		if value == nil {
+			if v, ok := lookup(key); ok {
+				env[key] = v
+			}
+			return
+		}
+		env[key] = expand(*value)
+	}
+
+	switch node.Kind {
+	case 0:
+		return nil
+	case yaml.SequenceNode:
+		var entries []string
+		if err := node.Decode(&entries); err != nil {
+			return fmt.Errorf("invalid environment: %w", err)
+		}
+		for _, entry := range entries {
+			if key, value, found := strings.Cut(entry, "="); found {
+				set(key, &value)
+			} else {
+				set(entry, nil)
+			}
+		}
+		return nil
+	case yaml.MappingNode:
+		var entries map[string]*string
+		if err := node.Decode(&entries); err != nil {
+			return fmt.Errorf("invalid environment: %w", err)
+		}
+		for key, value := range entries {
+			set(key, value)
+		}
+		return nil
+	}
+	return fmt.Errorf("invalid environment, line %d", node.Line)
+}
+
+// mountSpec returns a volumes entry, in its short or long syntax, as a mount
+func mountSpec(volume any, expand func(string) string) (MountSpec, bool) {
+	switch v := volume.(type) {
+	case string:
+		source, rest, found := SplitVolumeSpec(expand(v))
+		if !found {
+			return MountSpec{Type: MountVolume, Target: source}, true
+		}
+		target, mode, _ := strings.Cut(rest, ":")
+		mount := MountSpec{Type: MountVolume, Source: source, Target: target, ReadOnly: slices.Contains(strings.Split(mode, ","), "ro")}
+		if IsHostPath(source) {
+			mount.Type = MountBind
+		}
+		return mount, true
+	case map[string]any:
+		mount := MountSpec{Type: MountVolume}
+		if t, ok := v["type"].(string); ok {
+			mount.Type = t
+		}
+		if source, ok := v["source"].(string); ok {
+			mount.Source = expand(source)
+		}
+		target, _ := v["target"].(string)
+		mount.Target = expand(target)
+		mount.ReadOnly, _ = v["read_only"].(bool)
+		return mount, mount.Target != ""
+	}
+	return MountSpec{}, false
+}
+
+// parsePortMapping returns the ports a mapping such as
+// 127.0.0.1:8000-8001:8000-8001/udp publishes
+func parsePortMapping(mapping string) ([]PortSpec, error) {
+	if mapping == "" {
+		return nil, nil
+	}
+	spec, protocol, _ := strings.Cut(mapping, "/")
+	if protocol == "" {
+		protocol = "tcp"
+	}
+
+	hostIP, published := "", ""
+	target := spec
+	if i := strings.LastIndex(spec, ":"); i >= 0 {
+		target = spec[i+1:]
+		published = spec[:i]
+		if j := strings.LastIndex(published, ":"); j >= 0 {
+			hostIP, published = published[:j], published[j+1:]
+		}
+	}
+
+	targets, err := portRange(target)
+	if err != nil || len(targets) == 0 {
+		return nil, fmt.Errorf("invalid port mapping %q", mapping)
+	}
+	var hosts []int
+	if published != "" {
+		if hosts, err = portRange(published); err != nil || (len(hosts) != len(targets) && len(hosts) != 1) {
+			return nil, fmt.Errorf("invalid port mapping %q", mapping)
+		}
+	}
+
+	specs := make([]PortSpec, len(targets))
+	for i, port := range targets {
+		specs[i] = PortSpec{HostIP: hostIP, Target: port, Protocol: protocol}
+		switch {
+		case len(hosts) == len(targets):
+			specs[i].Published = hosts[i]
+		case len(hosts) == 1 && len(targets) == 1:
+			specs[i].Published = hosts[0]
+		}
+	}
+	return specs, nil
+}
+
+// portRange returns the ports of a port or a range such as 8000-8001
+func portRange(s string) ([]int, error) {
+	first, last, isRange := strings.Cut(s, "-")
+	start, err := strconv.Atoi(first)
+	if err != nil {
+		return nil, err
+	}
+	end := start
+	if isRange {
+		if end, err = strconv.Atoi(last); err != nil {
+			return nil, err
+		}
+	}
+	if start < 1 || end < start || end > 65535 {
+		return nil, fmt.Errorf("invalid port range %s", s)
+	}
+	ports := make([]int, 0, end-start+1)
+	for port := start; port <= end; port++ {
+		ports = append(ports, port)
+	}
+	return ports, nil
+}
@@ -0,0 +1,117 @@
+package compose
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/isaacgarza/dev-stack/internal/pkg/types"
+)
+
+func TestDefinitions(t *testing.T) {
+	dir := t.TempDir()
+	base := filepath.Join(dir, "docker-compose.yml")
+	override := filepath.Join(dir, "docker-compose.override.yml")
+	require.NoError(t, os.WriteFile(base, []byte(`services:
+  postgres:
+    image: postgres:${POSTGRES_VERSION:-15}
+    environment:
+      - POSTGRES_USER=${POSTGRES_USER:-postgres}
+      - POSTGRES_DB=app
+      - TZ
+    ports: ["5432:5432"]
+    networks: [backend]
+    command: "postgres\n  -c max_connections=200"
+    volumes:
+      - pg-data:/var/lib/postgresql/data
+      - ./init.sql:/docker-entrypoint-initdb.d/init.sql:ro
+    deploy:
+      resources:
+        limits:
+          memory: 512m
+    healthcheck:
+      test: ["CMD-SHELL", "pg_isready -U ${POSTGRES_USER:-postgres}"]
+      interval: 10s
+      retries: 5
+  worker:
+    build: .
+    entrypoint: ["/bin/worker"]
+    environment:
+      QUEUE: jobs
+    ports: ["127.0.0.1::8080", "9000-9001:9000-9001/udp"]
+    volumes:
+      - /cache
+      - type: tmpfs
+        target: /tmp
+    healthcheck:
+      test: "curl -f localhost:8080"
+`), 0644))
+	require.NoError(t, os.WriteFile(override, []byte(`services:
+  postgres:
+    environment:
+      POSTGRES_DB: orders
+    volumes:
+      - pg-other:/var/lib/postgresql/data
+  worker:
+    healthcheck:
+      disable: true
+`), 0644))
+	lookup := func(key string) (string, bool) {
+		switch key {
+		case "POSTGRES_USER":
+			return "app", true
+		case "TZ":
+			return "UTC", true
+		}
+		return "", false
+	}
+
+	definitions, err := Definitions(lookup, base, override)
+	require.NoError(t, err)
+	assert.Equal(t, []Definition{
+		{
+			Name:        "postgres",
+			Image:       "postgres:15",
+			Command:     []string{"postgres", "-c", "max_connections=200"},
+			Environment: map[string]string{"POSTGRES_USER": "app", "POSTGRES_DB": "orders", "TZ": "UTC"},
+			Ports:       []PortSpec{{Published: 5432, Target: 5432, Protocol: "tcp"}},
+			Mounts: []MountSpec{
+				{Type: MountBind, Source: "./init.sql", Target: "/docker-entrypoint-initdb.d/init.sql", ReadOnly: true},
+				{Type: MountVolume, Source: "pg-other", Target: "/var/lib/postgresql/data"},
+			},
+			Networks:    []string{"backend"},
+			Healthcheck: &Healthcheck{Test: []string{"CMD-SHELL", "pg_isready -U app"}, Interval: "10s", Retries: 5},
+			Limits:      types.ResourceLimits{Memory: "512m"},
+		},
+		{
+			Name:        "worker",
+			Build:       true,
+			Entrypoint:  []string{"/bin/worker"},
+			Environment: map[string]string{"QUEUE": "jobs"},
+			Ports: []PortSpec{
+				{HostIP: "127.0.0.1", Target: 8080, Protocol: "tcp"},
+				{Published: 9000, Target: 9000, Protocol: "udp"},
+				{Published: 9001, Target: 9001, Protocol: "udp"},
+			},
+			Mounts: []MountSpec{
+				{Type: MountVolume, Target: "/cache"},
+				{Type: MountTmpfs, Target: "/tmp"},
+			},
+			Networks: []string{"default"},
+		},
+	}, definitions)
+}
+
+func TestParsePortMapping(t *testing.T) {
+	specs, err := parsePortMapping("[::1]:5432:5432")
+	require.NoError(t, err)
+	assert.Equal(t, []PortSpec{{HostIP: "[::1]", Published: 5432, Target: 5432, Protocol: "tcp"}}, specs)
+
+	for _, mapping := range []string{"a:5432", "8000-8001:8000-8002", "0", "70000"} {
+		_, err := parsePortMapping(mapping)
+		assert.Error(t, err, mapping)
+	}
+}
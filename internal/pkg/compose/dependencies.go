@@ -0,0 +1,153 @@
+package compose
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/isaacgarza/dev-stack/internal/pkg/constants"
+)
+
+// ServiceDependencies returns every service of the compose files with the
+// services it depends on, each mapped to its depends_on condition.
+// Dependencies listed without a condition wait for the dependency to
+// start. Files are merged in order, later files adding dependencies.
+func ServiceDependencies(composeFiles ...string) (map[string]map[string]string, error) {
+	dependencies := make(map[string]map[string]string)
+	for _, composeFile := range composeFiles {
+		data, err := os.ReadFile(composeFile)
+		if err != nil {
+			return nil, err
+		}
+
+		var f file
+		if err := yaml.Unmarshal(data, &f); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", composeFile, err)
+		}
+
+		for name, svc := range f.Services {
+			if dependencies[name] == nil {
+				dependencies[name] = make(map[string]string)
+			}
+			conditions, err := dependsOn(svc.DependsOn)
+			if err != nil {
+				return nil, fmt.Errorf("%s: services.%s.depends_on: %w", composeFile, name, err)
+			}
+			for dep, condition := range conditions {
+				dependencies[name][dep] = condition
+			}
+		}
+	}
+	return dependencies, nil
+}
+
+// dependsOn decodes a depends_on entry in its short or long syntax
+func dependsOn(node yaml.Node) (map[string]string, error) {
+	conditions := make(map[string]string)
+	switch node.Kind {
+	case 0:
+	case yaml.SequenceNode:
+		var names []string
+		if err := node.Decode(&names); err != nil {
+			return nil, fmt.Errorf("must list service names")
+		}
+		for _, name := range names {
+			conditions[name] = constants.ConditionServiceStarted
+		}
+	case yaml.MappingNode:
+		var long map[string]struct {
+			Condition string `yaml:"condition"`
+		}
+		if err := node.Decode(&long); err != nil {
+			return nil, fmt.Errorf("must map service names to their conditions")
+		}
+		for name, options := range long {
+			condition := options.Condition
+			if condition == "" {
+				condition = constants.ConditionServiceStarted
+			}
+			conditions[name] = condition
+		}
+	default:
+		return nil, fmt.Errorf("must be a list or a mapping")
+	}
+	return conditions, nil
+}
+
+// StartupWaves groups services into the order they start in: each wave
+// only holds services whose dependencies started in earlier waves, so the
+// services of a wave can start together. With withDeps, the services the
+// named ones depend on, directly or not, are started too; otherwise only
+// the order among the named services is kept. Dependencies the compose
+// files don't define are left to docker compose to report.
+func StartupWaves(serviceNames []string, dependencies map[string]map[string]string, withDeps bool) ([][]string, error) {
+	selected := make(map[string]bool)
+	var add func(name string)
+	add = func(name string) {
+		if selected[name] {
+			return
+		}
+		selected[name] = true
+		if !withDeps {
+			return
+		}
+		for dep := range dependencies[name] {
+			if _, ok := dependencies[dep]; ok {
+				add(dep)
+			}
+		}
+	}
+	for _, name := range serviceNames {
+		add(name)
+	}
+
+	depth := make(map[string]int)
+	state := make(map[string]int) // 0 unvisited, 1 visiting, 2 done
+	var visit func(name string, path []string) error
+	visit = func(name string, path []string) error {
+		switch state[name] {
+		case 1:
+			return fmt.Errorf("dependency cycle: %s", strings.Join(append(path, name), " -> "))
+		case 2:
+			return nil
+		}
+
+		state[name] = 1
+		deps := make([]string, 0, len(dependencies[name]))
+		for dep := range dependencies[name] {
+			if selected[dep] {
+				deps = append(deps, dep)
+			}
+		}
+		sort.Strings(deps)
+		for _, dep := range deps {
+			if err := visit(dep, append(path, name)); err != nil {
+				return err
+			}
+			depth[name] = max(depth[name], depth[dep]+1)
+		}
+		state[name] = 2
+		return nil
+	}
+
+	names := make([]string, 0, len(selected))
+	for name := range selected {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var waves [][]string
+	for _, name := range names {
+		if err := visit(name, nil); err != nil {
+			return nil, err
+		}
+		for len(waves) <= depth[name] {
+			waves = append(waves, nil)
+		}
+		waves[depth[name]] = append(waves[depth[name]], name)
+	}
+	return waves, nil
+}
@@ -0,0 +1,71 @@
+package compose
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/isaacgarza/dev-stack/internal/pkg/constants"
+)
+
+func TestServiceDependencies(t *testing.T) {
+	dir := t.TempDir()
+	base := filepath.Join(dir, "docker-compose.yml")
+	override := filepath.Join(dir, "docker-compose.override.yml")
+	require.NoError(t, os.WriteFile(base, []byte(`services:
+  postgres:
+    image: postgres:16
+  app:
+    image: app
+    depends_on:
+      postgres:
+        condition: service_healthy
+`), 0644))
+	require.NoError(t, os.WriteFile(override, []byte(`services:
+  app:
+    depends_on: [redis]
+  redis:
+    image: redis:7
+`), 0644))
+
+	dependencies, err := ServiceDependencies(base, override)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]map[string]string{
+		"postgres": {},
+		"redis":    {},
+		"app": {
+			"postgres": constants.ConditionServiceHealthy,
+			"redis":    constants.ConditionServiceStarted,
+		},
+	}, dependencies)
+
+	require.NoError(t, os.WriteFile(override, []byte("services:\n  app:\n    depends_on: redis\n"), 0644))
+	_, err = ServiceDependencies(base, override)
+	assert.ErrorContains(t, err, "services.app.depends_on: must be a list or a mapping")
+}
+
+func TestStartupWaves(t *testing.T) {
+	dependencies := map[string]map[string]string{
+		"postgres": {},
+		"redis":    {},
+		"migrate":  {"postgres": constants.ConditionServiceHealthy},
+		"app":      {"migrate": constants.ConditionServiceCompleted, "redis": constants.ConditionServiceStarted, "external": constants.ConditionServiceStarted},
+		"worker":   {"app": constants.ConditionServiceStarted},
+	}
+
+	waves, err := StartupWaves([]string{"worker"}, dependencies, true)
+	require.NoError(t, err)
+	assert.Equal(t, [][]string{{"postgres", "redis"}, {"migrate"}, {"app"}, {"worker"}}, waves)
+
+	// Without dependencies, only the order among the named services is kept
+	waves, err = StartupWaves([]string{"worker", "postgres", "app"}, dependencies, false)
+	require.NoError(t, err)
+	assert.Equal(t, [][]string{{"app", "postgres"}, {"worker"}}, waves)
+
+	dependencies["postgres"] = map[string]string{"worker": constants.ConditionServiceStarted}
+	_, err = StartupWaves([]string{"worker"}, dependencies, true)
+	assert.ErrorContains(t, err, "dependency cycle: app -> migrate -> postgres -> worker -> app")
+}
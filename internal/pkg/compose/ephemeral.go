@@ -0,0 +1,140 @@
+package compose
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/isaacgarza/dev-stack/internal/pkg/constants"
+)
+
+// Labels set on the containers of ephemeral stacks
+const (
+	EphemeralLabel = "dev-stack.ephemeral"
+	ExpiresAtLabel = "dev-stack.expires-at"
+)
+
+// ephemeralFileName is the compose file, under dev-stack/tmp, that makes the
+// stack ephemeral
+const ephemeralFileName = "docker-compose.ephemeral.yml"
+
+// EphemeralFile returns the path of the compose file that makes the stack
+// ephemeral, merged over the generated file while it exists
+func EphemeralFile() string {
+	return filepath.Join(constants.DevStackDir, constants.TmpDir, ephemeralFileName)
+}
+
+// ephemeralFile is a compose file as far as ephemeral stacks are concerned
+type ephemeralFile struct {
+	Services map[string]ephemeralService `yaml:"services"`
+}
+
+type ephemeralService struct {
+	Labels  map[string]string `yaml:"labels"`
+	Volumes []ephemeralVolume `yaml:"volumes,omitempty"`
+}
+
+// ephemeralVolume is an anonymous volume. Compose merges service volumes by
+// target, so it replaces the named volume mounted at the same path.
+type ephemeralVolume struct {
+	Type   string `yaml:"type"`
+	Target string `yaml:"target"`
+}
+
+// EphemeralOverride renders a compose file that labels every service of the
+// compose files with expiresAt and replaces their named volumes with
+// anonymous ones. The stack then starts from empty storage, and removing
+// its containers with their volumes leaves nothing behind. Bind mounts are
+// kept.
+func EphemeralOverride(expiresAt time.Time, composeFiles ...string) ([]byte, error) {
+	targets := make(map[string]map[string]bool)
+	for _, composeFile := range composeFiles {
+		data, err := os.ReadFile(composeFile)
+		if err != nil {
+			return nil, err
+		}
+
+		var f file
+		if err := yaml.Unmarshal(data, &f); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", composeFile, err)
+		}
+
+		for name, svc := range f.Services {
+			if targets[name] == nil {
+				targets[name] = make(map[string]bool)
+			}
+			for _, volume := range svc.Volumes {
+				if target, named := namedVolumeTarget(volume); named {
+					targets[name][target] = true
+				}
+			}
+		}
+	}
+
+	labels := map[string]string{
+		EphemeralLabel: "true",
+		ExpiresAtLabel: expiresAt.UTC().Format(time.RFC3339),
+	}
+	override := ephemeralFile{Services: make(map[string]ephemeralService, len(targets))}
+	for name, serviceTargets := range targets {
+		svc := ephemeralService{Labels: labels}
+		for _, target := range sortedKeys(serviceTargets) {
+			svc.Volumes = append(svc.Volumes, ephemeralVolume{Type: "volume", Target: target})
+		}
+		override.Services[name] = svc
+	}
+
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(override); err != nil {
+		return nil, err
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// namedVolumeTarget returns the mount path of a service volume entry and
+// whether the entry mounts a named volume rather than a host path
+func namedVolumeTarget(volume any) (string, bool) {
+	var source, target string
+	switch value := volume.(type) {
+	case string:
+		var rest string
+		var found bool
+		if source, rest, found = SplitVolumeSpec(value); !found {
+			return "", false
+		}
+		target, _, _ = strings.Cut(rest, ":")
+	case map[string]any:
+		if kind, _ := value["type"].(string); kind != "volume" {
+			return "", false
+		}
+		source, _ = value["source"].(string)
+		target, _ = value["target"].(string)
+	}
+	if source == "" || target == "" || IsHostPath(source) || strings.HasPrefix(source, "$") {
+		return "", false
+	}
+	return target, true
+}
+
+// ParseExpiresAt reads the expiry label of an ephemeral container
+func ParseExpiresAt(labels map[string]string) (time.Time, bool) {
+	value, ok := labels[ExpiresAtLabel]
+	if !ok {
+		return time.Time{}, false
+	}
+	expiresAt, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return expiresAt, true
+}
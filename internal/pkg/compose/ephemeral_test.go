@@ -0,0 +1,69 @@
+package compose
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func TestEphemeralOverride(t *testing.T) {
+	dir := t.TempDir()
+	base := filepath.Join(dir, "docker-compose.yml")
+	override := filepath.Join(dir, "docker-compose.override.yml")
+	require.NoError(t, os.WriteFile(base, []byte(`services:
+  postgres:
+    image: postgres:15
+    volumes:
+      - postgres_data:/var/lib/postgresql/data
+      - ./init:/docker-entrypoint-initdb.d:ro
+  redis:
+    image: redis:7
+volumes:
+  postgres_data:
+`), 0644))
+	require.NoError(t, os.WriteFile(override, []byte(`services:
+  redis:
+    volumes:
+      - type: volume
+        source: redis_data
+        target: /data
+      - type: bind
+        source: ./redis.conf
+        target: /usr/local/etc/redis/redis.conf
+`), 0644))
+
+	expiresAt := time.Date(2026, 1, 2, 15, 4, 5, 0, time.FixedZone("CET", 3600))
+	data, err := EphemeralOverride(expiresAt, base, override)
+	require.NoError(t, err)
+
+	var rendered ephemeralFile
+	require.NoError(t, yaml.Unmarshal(data, &rendered))
+
+	labels := map[string]string{EphemeralLabel: "true", ExpiresAtLabel: "2026-01-02T14:04:05Z"}
+	assert.Equal(t, ephemeralFile{Services: map[string]ephemeralService{
+		"postgres": {Labels: labels, Volumes: []ephemeralVolume{{Type: "volume", Target: "/var/lib/postgresql/data"}}},
+		"redis":    {Labels: labels, Volumes: []ephemeralVolume{{Type: "volume", Target: "/data"}}},
+	}}, rendered)
+
+	parsed, ok := ParseExpiresAt(rendered.Services["postgres"].Labels)
+	require.True(t, ok)
+	assert.True(t, parsed.Equal(expiresAt))
+}
+
+func TestEphemeralOverride_MissingFile(t *testing.T) {
+	_, err := EphemeralOverride(time.Now(), filepath.Join(t.TempDir(), "missing.yml"))
+	assert.Error(t, err)
+}
+
+func TestParseExpiresAt(t *testing.T) {
+	_, ok := ParseExpiresAt(map[string]string{})
+	assert.False(t, ok)
+
+	_, ok = ParseExpiresAt(map[string]string{ExpiresAtLabel: "tomorrow"})
+	assert.False(t, ok)
+}
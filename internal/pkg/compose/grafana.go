@@ -0,0 +1,134 @@
+package compose
+
+import (
+	"bytes"
+	"maps"
+	"path"
+	"slices"
+
+	"gopkg.in/yaml.v3"
+)
+
+// GrafanaProvisioningDir is where the grafana container mounts
+// dev-stack/grafana
+const GrafanaProvisioningDir = "/etc/grafana/provisioning"
+
+// Files under dev-stack/grafana
+const (
+	GrafanaDatasourcesFileName = "datasources/dev-stack.yml"
+	GrafanaProvidersFileName   = "dashboards/dev-stack.yml"
+	// GrafanaDashboardsDir holds a dashboard file per service
+	GrafanaDashboardsDir = "dashboards/dev-stack"
+)
+
+// grafanaFolder is the folder of the Grafana UI the dashboards are listed in
+const grafanaFolder = "dev-stack"
+
+// GrafanaDatasource is a datasource Grafana reads from a service of the
+// stack. The UID is fixed so dashboards can reference it.
+type GrafanaDatasource struct {
+	Name      string `yaml:"name"`
+	Type      string `yaml:"type"`
+	UID       string `yaml:"uid"`
+	Access    string `yaml:"access"`
+	URL       string `yaml:"url"`
+	IsDefault bool   `yaml:"isDefault,omitempty"`
+	Editable  bool   `yaml:"editable"`
+}
+
+// grafanaDatasources are the datasources of the services Grafana can read,
+// keyed by service name, reached on the stack's network
+var grafanaDatasources = map[string]GrafanaDatasource{
+	"prometheus": {Name: "Prometheus", Type: "prometheus", UID: "prometheus", Access: "proxy", URL: "http://prometheus:9090", IsDefault: true},
+	"jaeger":     {Name: "Jaeger", Type: "jaeger", UID: "jaeger", Access: "proxy", URL: "http://jaeger:16686"},
+	"loki":       {Name: "Loki", Type: "loki", UID: "loki", Access: "proxy", URL: "http://loki:3100"},
+}
+
+// GrafanaDatasources returns the datasources of the given services, in the
+// order of serviceNames
+func GrafanaDatasources(serviceNames []string) []GrafanaDatasource {
+	var datasources []GrafanaDatasource
+	for _, serviceName := range serviceNames {
+		if datasource, ok := grafanaDatasources[serviceName]; ok {
+			datasources = append(datasources, datasource)
+		}
+	}
+	// Without Prometheus the first datasource is the default
+	if len(datasources) > 0 && !slices.ContainsFunc(datasources, func(d GrafanaDatasource) bool { return d.IsDefault }) {
+		datasources[0].IsDefault = true
+	}
+	return datasources
+}
+
+// grafanaDatasourcesFile is a datasources provisioning file
+type grafanaDatasourcesFile struct {
+	APIVersion  int                 `yaml:"apiVersion"`
+	Datasources []GrafanaDatasource `yaml:"datasources"`
+	// DeleteDatasources removes the datasources of services no longer in
+	// the stack
+	DeleteDatasources []grafanaDatasourceRef `yaml:"deleteDatasources,omitempty"`
+}
+
+type grafanaDatasourceRef struct {
+	Name  string `yaml:"name"`
+	OrgID int    `yaml:"orgId"`
+}
+
+// GrafanaDatasourcesConfig renders the provisioning file of the datasources
+// of serviceNames, deleting the other known datasources
+func GrafanaDatasourcesConfig(serviceNames []string) ([]byte, error) {
+	file := grafanaDatasourcesFile{APIVersion: 1, Datasources: GrafanaDatasources(serviceNames)}
+	if file.Datasources == nil {
+		file.Datasources = []GrafanaDatasource{}
+	}
+	for _, serviceName := range slices.Sorted(maps.Keys(grafanaDatasources)) {
+		if !slices.Contains(serviceNames, serviceName) {
+			file.DeleteDatasources = append(file.DeleteDatasources, grafanaDatasourceRef{Name: grafanaDatasources[serviceName].Name, OrgID: 1})
+		}
+	}
+	return encodeYAML(file)
+}
+
+// grafanaProvidersFile is a dashboard providers provisioning file
+type grafanaProvidersFile struct {
+	APIVersion int                     `yaml:"apiVersion"`
+	Providers  []grafanaDashboardsFrom `yaml:"providers"`
+}
+
+type grafanaDashboardsFrom struct {
+	Name                  string            `yaml:"name"`
+	Folder                string            `yaml:"folder"`
+	Type                  string            `yaml:"type"`
+	DisableDeletion       bool              `yaml:"disableDeletion"`
+	AllowUIUpdates        bool              `yaml:"allowUiUpdates"`
+	UpdateIntervalSeconds int               `yaml:"updateIntervalSeconds"`
+	Options               map[string]string `yaml:"options"`
+}
+
+// GrafanaProvidersConfig renders the provisioning file loading the
+// dashboards of GrafanaDashboardsDir
+func GrafanaProvidersConfig() ([]byte, error) {
+	return encodeYAML(grafanaProvidersFile{APIVersion: 1, Providers: []grafanaDashboardsFrom{{
+		Name:                  "dev-stack",
+		Folder:                grafanaFolder,
+		Type:                  "file",
+		DisableDeletion:       true,
+		AllowUIUpdates:        true,
+		UpdateIntervalSeconds: 30,
+		Options:               map[string]string{"path": path.Join(GrafanaProvisioningDir, GrafanaDashboardsDir)},
+	}}})
+}
+
+// encodeYAML encodes value as YAML indented by two spaces
+func encodeYAML(value any) ([]byte, error) {
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(value); err != nil {
+		return nil, err
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,43 @@
+package compose
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGrafanaDatasources(t *testing.T) {
+	datasources := GrafanaDatasources([]string{"postgres", "jaeger", "prometheus", "grafana"})
+	require.Len(t, datasources, 2)
+	assert.Equal(t, "http://jaeger:16686", datasources[0].URL)
+	assert.False(t, datasources[0].IsDefault)
+	assert.True(t, datasources[1].IsDefault)
+
+	// Without Prometheus the first datasource is the default
+	datasources = GrafanaDatasources([]string{"jaeger"})
+	require.Len(t, datasources, 1)
+	assert.True(t, datasources[0].IsDefault)
+
+	assert.Empty(t, GrafanaDatasources([]string{"redis"}))
+}
+
+func TestGrafanaDatasourcesConfig(t *testing.T) {
+	content, err := GrafanaDatasourcesConfig([]string{"prometheus"})
+	require.NoError(t, err)
+	assert.Equal(t, `apiVersion: 1
+datasources:
+  - name: Prometheus
+    type: prometheus
+    uid: prometheus
+    access: proxy
+    url: http://prometheus:9090
+    isDefault: true
+    editable: false
+deleteDatasources:
+  - name: Jaeger
+    orgId: 1
+  - name: Loki
+    orgId: 1
+`, string(content))
+}
@@ -0,0 +1,136 @@
+package compose
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/isaacgarza/dev-stack/internal/pkg/constants"
+)
+
+// Labels set on every container, volume, network and built image of a
+// project, so cleanup only removes what dev-stack created for it
+const (
+	ProjectLabel    = "dev-stack.project"
+	ProjectDirLabel = "dev-stack.project-dir"
+)
+
+// labelsFileName is the compose file, under dev-stack/tmp, that labels the
+// resources of the project
+const labelsFileName = "docker-compose.labels.yml"
+
+// LabelsFile returns the path of the compose file that labels the resources
+// of the project, merged over the generated file while it exists
+func LabelsFile() string {
+	return filepath.Join(constants.DevStackDir, constants.TmpDir, labelsFileName)
+}
+
+// labelsOverride is a compose file setting labels and nothing else
+type labelsOverride struct {
+	Services map[string]labelledService `yaml:"services"`
+	Volumes  map[string]labelled        `yaml:"volumes,omitempty"`
+	Networks map[string]labelled        `yaml:"networks,omitempty"`
+}
+
+type labelledService struct {
+	Labels map[string]string `yaml:"labels"`
+	Build  *labelled         `yaml:"build,omitempty"`
+}
+
+type labelled struct {
+	Labels map[string]string `yaml:"labels"`
+}
+
+// LabelsOverride renders a compose file that labels every service of the
+// compose files, the images compose builds for them and the volumes and
+// networks they declare with the project and its directory. External
+// volumes and networks are left alone, and the default network is labelled
+// when services are on it.
+func LabelsOverride(projectName, projectDir string, composeFiles ...string) ([]byte, error) {
+	labels := labelled{Labels: map[string]string{
+		ProjectLabel:    projectName,
+		ProjectDirLabel: projectDir,
+	}}
+	override := labelsOverride{
+		Services: make(map[string]labelledService),
+		Volumes:  make(map[string]labelled),
+		Networks: make(map[string]labelled),
+	}
+
+	// Whether each service is on the default network, which compose only
+	// creates for the services on it
+	onDefault := make(map[string]bool)
+	externalVolumes := make(map[string]bool)
+	externalNetworks := make(map[string]bool)
+	for _, composeFile := range composeFiles {
+		data, err := os.ReadFile(composeFile)
+		if err != nil {
+			return nil, err
+		}
+
+		var f planFile
+		if err := yaml.Unmarshal(data, &f); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", composeFile, err)
+		}
+
+		for name, svc := range f.Services {
+			labelledSvc, ok := override.Services[name]
+			if !ok {
+				labelledSvc = labelledService{Labels: labels.Labels}
+			}
+			if svc.Build != nil {
+				labelledSvc.Build = &labels
+			}
+			override.Services[name] = labelledSvc
+
+			if svc.Networks.Kind != 0 {
+				onDefault[name] = slices.Contains(networkKeys(svc.Networks), defaultNetwork)
+			} else if _, ok := onDefault[name]; !ok {
+				onDefault[name] = true
+			}
+		}
+		for key, volume := range f.Volumes {
+			if volume.External != nil {
+				externalVolumes[key] = isExternal(volume)
+			}
+			override.Volumes[key] = labels
+		}
+		for key, network := range f.Networks {
+			if network.External != nil {
+				externalNetworks[key] = isExternal(network)
+			}
+			override.Networks[key] = labels
+		}
+	}
+	for key := range externalVolumes {
+		if externalVolumes[key] {
+			delete(override.Volumes, key)
+		}
+	}
+	for key := range externalNetworks {
+		if externalNetworks[key] {
+			delete(override.Networks, key)
+		}
+	}
+	for _, on := range onDefault {
+		if on && !externalNetworks[defaultNetwork] {
+			override.Networks[defaultNetwork] = labels
+			break
+		}
+	}
+
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(override); err != nil {
+		return nil, err
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,52 @@
+package compose
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func TestLabelsOverride(t *testing.T) {
+	dir := t.TempDir()
+	base := filepath.Join(dir, "docker-compose.yml")
+	override := filepath.Join(dir, "docker-compose.override.yml")
+	require.NoError(t, os.WriteFile(base, []byte(`services:
+  postgres:
+    image: postgres:16
+    volumes: [pgdata:/var/lib/postgresql/data]
+  api:
+    build: .
+    networks: [backend]
+volumes:
+  pgdata: {}
+  cache:
+    external: true
+networks:
+  backend: {}
+`), 0644))
+	require.NoError(t, os.WriteFile(override, []byte(`services:
+  postgres:
+    networks: [backend]
+networks:
+  backend:
+    external: true
+`), 0644))
+
+	data, err := LabelsOverride("demo", "/src/demo", base, override)
+	require.NoError(t, err)
+
+	var rendered labelsOverride
+	require.NoError(t, yaml.Unmarshal(data, &rendered))
+	labels := map[string]string{ProjectLabel: "demo", ProjectDirLabel: "/src/demo"}
+	assert.Equal(t, labelsOverride{
+		Services: map[string]labelledService{
+			"postgres": {Labels: labels},
+			"api":      {Labels: labels, Build: &labelled{Labels: labels}},
+		},
+		Volumes: map[string]labelled{"pgdata": {Labels: labels}},
+	}, rendered, "external resources and the unused default network are left alone")
+}
@@ -0,0 +1,76 @@
+package compose
+
+import "path"
+
+// LogCollectorDir is where the log-collector container mounts dev-stack/logs
+const LogCollectorDir = "/var/log/dev-stack"
+
+// LogCollectorConfigFileName is the Vector configuration under dev-stack/logs
+const LogCollectorConfigFileName = "vector.yaml"
+
+// logServiceLabel is the compose label naming the service of a container
+const logServiceLabel = "com.docker.compose.service"
+
+// vectorConfig is the part of the Vector configuration dev-stack generates
+type vectorConfig struct {
+	DataDir    string                    `yaml:"data_dir"`
+	Sources    map[string]vectorSource   `yaml:"sources"`
+	Transforms map[string]vectorRemap    `yaml:"transforms"`
+	Sinks      map[string]vectorFileSink `yaml:"sinks"`
+}
+
+type vectorSource struct {
+	Type              string   `yaml:"type"`
+	IncludeLabels     []string `yaml:"include_labels"`
+	ExcludeContainers []string `yaml:"exclude_containers"`
+}
+
+type vectorRemap struct {
+	Type   string   `yaml:"type"`
+	Inputs []string `yaml:"inputs"`
+	Source string   `yaml:"source"`
+}
+
+type vectorFileSink struct {
+	Type     string            `yaml:"type"`
+	Inputs   []string          `yaml:"inputs"`
+	Path     string            `yaml:"path"`
+	Encoding map[string]string `yaml:"encoding"`
+}
+
+// logEntryRemap reduces the events of Vector's docker_logs source to the
+// fields of a persisted log entry
+const logEntryRemap = `. = {
+  "timestamp": .timestamp,
+  "service": .label."` + logServiceLabel + `",
+  "container": .container_name,
+  "stream": .stream,
+  "message": .message
+}
+`
+
+// LogCollectorConfig renders the Vector configuration of the log-collector
+// service of a project: the output of the project's other containers is
+// written as JSON lines to a file per service and day under
+// LogCollectorDir.
+func LogCollectorConfig(projectName string) ([]byte, error) {
+	return encodeYAML(vectorConfig{
+		DataDir: "/var/lib/vector",
+		Sources: map[string]vectorSource{"containers": {
+			Type:              "docker_logs",
+			IncludeLabels:     []string{"com.docker.compose.project=" + projectName},
+			ExcludeContainers: []string{projectName + "-log-collector"},
+		}},
+		Transforms: map[string]vectorRemap{"entries": {
+			Type:   "remap",
+			Inputs: []string{"containers"},
+			Source: logEntryRemap,
+		}},
+		Sinks: map[string]vectorFileSink{"files": {
+			Type:     "file",
+			Inputs:   []string{"entries"},
+			Path:     path.Join(LogCollectorDir, "{{ service }}", "%Y-%m-%d.log"),
+			Encoding: map[string]string{"codec": "json"},
+		}},
+	})
+}
@@ -0,0 +1,22 @@
+package compose
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func TestLogCollectorConfig(t *testing.T) {
+	data, err := LogCollectorConfig("shop")
+	require.NoError(t, err)
+
+	var config vectorConfig
+	require.NoError(t, yaml.Unmarshal(data, &config))
+	assert.Equal(t, []string{"com.docker.compose.project=shop"}, config.Sources["containers"].IncludeLabels)
+	assert.Equal(t, []string{"shop-log-collector"}, config.Sources["containers"].ExcludeContainers)
+	assert.Equal(t, []string{"entries"}, config.Sinks["files"].Inputs)
+	assert.Equal(t, "/var/log/dev-stack/{{ service }}/%Y-%m-%d.log", config.Sinks["files"].Path)
+	assert.Contains(t, config.Transforms["entries"].Source, `"service": .label."com.docker.compose.service"`)
+}
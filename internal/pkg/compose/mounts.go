@@ -0,0 +1,130 @@
+package compose
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/isaacgarza/dev-stack/internal/pkg/constants"
+	"github.com/isaacgarza/dev-stack/internal/pkg/types"
+	"github.com/isaacgarza/dev-stack/internal/pkg/utils"
+)
+
+// Variables expanded in the host paths of bind mounts besides those of the
+// environment
+const (
+	ProjectRootVar = "PROJECT_ROOT"
+	HomeVar        = "HOME"
+)
+
+// mountsFileName is the compose file, under dev-stack, that binds the host
+// paths of services.<name>.mounts into their containers
+const mountsFileName = "docker-compose.mounts.yml"
+
+// MountsFile returns the path of the compose file holding the bind mounts
+// of the project, merged over the generated file while it exists
+func MountsFile() string {
+	return filepath.Join(constants.DevStackDir, mountsFileName)
+}
+
+// HostPath expands ${PROJECT_ROOT}, ${HOME} and environment variables in
+// the source of a bind mount and makes it an absolute path, relative paths
+// being relative to projectRoot
+func HostPath(source, projectRoot, home string) string {
+	expanded := utils.ExpandEnv(source, func(name string) (string, bool) {
+		switch name {
+		case ProjectRootVar:
+			return projectRoot, true
+		case HomeVar:
+			if home != "" {
+				return home, true
+			}
+		}
+		return os.LookupEnv(name)
+	})
+	if !filepath.IsAbs(expanded) {
+		expanded = filepath.Join(projectRoot, expanded)
+	}
+	return filepath.Clean(expanded)
+}
+
+// mountsFile is a compose file setting bind mounts and nothing else
+type mountsFile struct {
+	Services map[string]mountedService `yaml:"services"`
+}
+
+type mountedService struct {
+	Volumes []bindVolume `yaml:"volumes"`
+}
+
+type bindVolume struct {
+	Type     string `yaml:"type"`
+	Source   string `yaml:"source"`
+	Target   string `yaml:"target"`
+	ReadOnly bool   `yaml:"read_only,omitempty"`
+}
+
+// MountsOverride renders a compose file that binds the given mounts, whose
+// sources are already host paths, into the containers they are keyed by.
+// Compose merges these volumes with those of the generated file by target,
+// and, unlike the short syntax, never creates a missing source.
+func MountsOverride(mounts map[string][]types.BindMount) ([]byte, error) {
+	f := mountsFile{Services: make(map[string]mountedService, len(mounts))}
+	for name, serviceMounts := range mounts {
+		var svc mountedService
+		for _, mount := range serviceMounts {
+			svc.Volumes = append(svc.Volumes, bindVolume{Type: "bind", Source: mount.Source, Target: mount.Target, ReadOnly: mount.ReadOnly})
+		}
+		f.Services[name] = svc
+	}
+
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(f); err != nil {
+		return nil, err
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// SplitVolumeSpec splits a short volumes entry, source:target[:mode], into
+// its source and the rest. The colon after the drive letter of a Windows
+// path such as C:\data or C:/data belongs to the source.
+func SplitVolumeSpec(spec string) (source, rest string, found bool) {
+	offset := 0
+	if hasDriveLetter(spec) {
+		offset = 2
+	}
+	i := strings.Index(spec[offset:], ":")
+	if i < 0 {
+		return spec, "", false
+	}
+	return spec[:offset+i], spec[offset+i+1:], true
+}
+
+// IsHostPath reports whether the source of a short volumes entry is a path
+// on the host rather than the name of a volume: relative, absolute or under
+// ~, or on Windows a drive or UNC path
+func IsHostPath(source string) bool {
+	return strings.HasPrefix(source, ".") || strings.HasPrefix(source, "/") || strings.HasPrefix(source, "~") ||
+		strings.HasPrefix(source, `\\`) || hasDriveLetter(source)
+}
+
+// hasDriveLetter reports whether path starts with a Windows drive, such as
+// C: followed by a separator or nothing
+func hasDriveLetter(path string) bool {
+	if len(path) < 2 || path[1] != ':' {
+		return false
+	}
+	letter := path[0] | 0x20
+	if letter < 'a' || letter > 'z' {
+		return false
+	}
+	return len(path) == 2 || path[2] == '\\' || path[2] == '/'
+}
@@ -0,0 +1,66 @@
+package compose
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHostPath(t *testing.T) {
+	t.Setenv("FIXTURES_DIR", "/srv/fixtures")
+	root := filepath.FromSlash("/work/api")
+	home := filepath.FromSlash("/home/dev")
+
+	tests := []struct {
+		source string
+		want   string
+	}{
+		{"${PROJECT_ROOT}/data", "/work/api/data"},
+		{"${HOME}/.aws", "/home/dev/.aws"},
+		{"./data/../uploads", "/work/api/uploads"},
+		{"data", "/work/api/data"},
+		{"${FIXTURES_DIR}", "/srv/fixtures"},
+		{"${UNSET_DIR:-/tmp/cache}", "/tmp/cache"},
+		{"/var/lib/app", "/var/lib/app"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.source, func(t *testing.T) {
+			assert.Equal(t, filepath.FromSlash(tt.want), HostPath(tt.source, root, home))
+		})
+	}
+}
+
+func TestSplitVolumeSpec(t *testing.T) {
+	tests := []struct {
+		spec   string
+		source string
+		rest   string
+		found  bool
+	}{
+		{"pgdata:/var/lib/postgresql/data", "pgdata", "/var/lib/postgresql/data", true},
+		{"./data:/data:ro", "./data", "/data:ro", true},
+		{`C:\Users\dev\data:/data`, `C:\Users\dev\data`, "/data", true},
+		{"d:/work/seed:/seed:ro", "d:/work/seed", "/seed:ro", true},
+		{`\\server\share:/share`, `\\server\share`, "/share", true},
+		{"/data", "/data", "", false},
+		{`C:\data`, `C:\data`, "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			source, rest, found := SplitVolumeSpec(tt.spec)
+			assert.Equal(t, tt.source, source)
+			assert.Equal(t, tt.rest, rest)
+			assert.Equal(t, tt.found, found)
+		})
+	}
+}
+
+func TestIsHostPath(t *testing.T) {
+	for _, source := range []string{"./data", "../shared", "/srv/data", "~/cache", `C:\data`, "c:/data", "D:", `\\wsl.localhost\Ubuntu\home`} {
+		assert.True(t, IsHostPath(source), source)
+	}
+	for _, source := range []string{"pgdata", "app_redis-data", "c", "cache:"} {
+		assert.False(t, IsHostPath(source), source)
+	}
+}
@@ -0,0 +1,137 @@
+package compose
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultNetwork is the network Compose attaches services that list none to
+const DefaultNetwork = "default"
+
+// IsolationNetworks returns the networks that let exactly the given pairs
+// of services reach each other, keyed by network with the services attached
+// to it. Each link gets a network of its own, named after both services,
+// joined by the members of the groups they belong to, which are sets of
+// services always connected, such as the containers of one service. A
+// group or service left without a network gets one to itself, so it isn't
+// put on the default network with the rest.
+func IsolationNetworks(services []string, groups map[string][]string, links [][2]string) map[string][]string {
+	groupOf := make(map[string]string)
+	for name, members := range groups {
+		for _, member := range members {
+			groupOf[member] = name
+		}
+	}
+	// unit returns the group of a service, or the service itself
+	unit := func(service string) string {
+		if group, ok := groupOf[service]; ok {
+			return group
+		}
+		return service
+	}
+	unitMembers := func(unit string) []string {
+		if members, ok := groups[unit]; ok {
+			return members
+		}
+		return []string{unit}
+	}
+
+	networks := make(map[string][]string)
+	attached := make(map[string]bool)
+	for _, link := range links {
+		a, b := unit(link[0]), unit(link[1])
+		if a == b {
+			continue
+		}
+		key := LinkNetwork(a, b)
+		if _, ok := networks[key]; ok {
+			continue
+		}
+		networks[key] = append(slices.Clone(unitMembers(a)), unitMembers(b)...)
+		attached[a], attached[b] = true, true
+	}
+	for _, service := range services {
+		if u := unit(service); !attached[u] {
+			networks[u] = unitMembers(u)
+			attached[u] = true
+		}
+	}
+	for key := range networks {
+		slices.Sort(networks[key])
+	}
+	return networks
+}
+
+// LinkNetwork returns the key of the network IsolationNetworks connects two
+// services on, the same whichever comes first
+func LinkNetwork(a, b string) string {
+	if b < a {
+		a, b = b, a
+	}
+	return a + "--" + b
+}
+
+// ServiceNetworks returns every service of the compose files with the
+// networks it is attached to. Files are merged in order, later files adding
+// networks; services attached to none are on the default network.
+func ServiceNetworks(composeFiles ...string) (map[string][]string, error) {
+	networks := make(map[string]map[string]bool)
+	for _, composeFile := range composeFiles {
+		data, err := os.ReadFile(composeFile)
+		if err != nil {
+			return nil, err
+		}
+
+		var f file
+		if err := yaml.Unmarshal(data, &f); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", composeFile, err)
+		}
+
+		for name, svc := range f.Services {
+			if networks[name] == nil {
+				networks[name] = make(map[string]bool)
+			}
+			for _, key := range networkKeys(svc.Networks) {
+				networks[name][key] = true
+			}
+		}
+	}
+
+	result := make(map[string][]string, len(networks))
+	for name, serviceNetworks := range networks {
+		if len(serviceNetworks) == 0 {
+			serviceNetworks[DefaultNetwork] = true
+		}
+		result[name] = slices.Sorted(maps.Keys(serviceNetworks))
+	}
+	return result, nil
+}
+
+// Reachability returns, for each service of networks, the other services it
+// shares a network with and so can reach, sorted by name
+func Reachability(networks map[string][]string) map[string][]string {
+	members := make(map[string][]string)
+	for service, serviceNetworks := range networks {
+		for _, network := range serviceNetworks {
+			members[network] = append(members[network], service)
+		}
+	}
+
+	reachable := make(map[string][]string, len(networks))
+	for service, serviceNetworks := range networks {
+		peers := make(map[string]bool)
+		for _, network := range serviceNetworks {
+			for _, peer := range members[network] {
+				if peer != service {
+					peers[peer] = true
+				}
+			}
+		}
+		reachable[service] = slices.Sorted(maps.Keys(peers))
+	}
+	return reachable
+}
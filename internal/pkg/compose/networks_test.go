@@ -0,0 +1,65 @@
+package compose
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsolationNetworks(t *testing.T) {
+	services := []string{"app", "postgres", "localstack", "broker", "zookeeper"}
+	groups := map[string][]string{"kafka": {"broker", "zookeeper"}}
+	links := [][2]string{{"app", "postgres"}, {"postgres", "app"}, {"app", "broker"}, {"broker", "zookeeper"}}
+
+	assert.Equal(t, map[string][]string{
+		"app--postgres": {"app", "postgres"},
+		"app--kafka":    {"app", "broker", "zookeeper"},
+		"localstack":    {"localstack"},
+	}, IsolationNetworks(services, groups, links))
+
+	// A group without links still connects its members
+	assert.Equal(t, map[string][]string{
+		"app":   {"app"},
+		"kafka": {"broker", "zookeeper"},
+	}, IsolationNetworks([]string{"app", "broker", "zookeeper"}, groups, nil))
+	assert.Equal(t, LinkNetwork("b", "a"), LinkNetwork("a", "b"))
+}
+
+func TestServiceNetworks(t *testing.T) {
+	dir := t.TempDir()
+	base := filepath.Join(dir, "docker-compose.yml")
+	override := filepath.Join(dir, "docker-compose.override.yml")
+	require.NoError(t, os.WriteFile(base, []byte(`services:
+  app:
+    image: app
+    networks: [app--postgres]
+  postgres:
+    image: postgres
+    networks:
+      app--postgres: {}
+  localstack:
+    image: localstack
+`), 0644))
+	require.NoError(t, os.WriteFile(override, []byte("services:\n  localstack:\n    networks: [shared]\n  app:\n    networks: [shared]\n"), 0644))
+
+	networks, err := ServiceNetworks(base)
+	require.NoError(t, err)
+	assert.Equal(t, []string{DefaultNetwork}, networks["localstack"])
+
+	networks, err = ServiceNetworks(base, override)
+	require.NoError(t, err)
+	assert.Equal(t, map[string][]string{
+		"app":        {"app--postgres", "shared"},
+		"postgres":   {"app--postgres"},
+		"localstack": {"shared"},
+	}, networks)
+
+	assert.Equal(t, map[string][]string{
+		"app":        {"localstack", "postgres"},
+		"postgres":   {"app"},
+		"localstack": {"app"},
+	}, Reachability(networks))
+}
@@ -0,0 +1,226 @@
+package compose
+
+import (
+	"fmt"
+	"os"
+	"slices"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/isaacgarza/dev-stack/internal/pkg/utils"
+)
+
+// defaultNetwork is the network compose puts services on that list none
+const defaultNetwork = "default"
+
+// ServicePlan is what a service of the compose files uses on the host, with
+// volumes and networks named as Docker names them
+type ServicePlan struct {
+	Name  string `json:"name"`
+	Image string `json:"image,omitempty"`
+	// Build is set when compose builds the image of the service
+	Build bool `json:"build,omitempty"`
+	// Ports are the port mappings the service publishes, host first
+	Ports    []string `json:"ports,omitempty"`
+	Volumes  []string `json:"volumes,omitempty"`
+	Networks []string `json:"networks,omitempty"`
+}
+
+// Resources are the services of the compose files with the volumes and
+// networks of the project. External volumes and networks aren't listed,
+// since compose neither creates nor removes them.
+type Resources struct {
+	Services map[string]ServicePlan
+	Volumes  []string
+	Networks []string
+}
+
+// planService is a service of a compose file as far as planning is concerned
+type planService struct {
+	Image    string    `yaml:"image"`
+	Build    any       `yaml:"build"`
+	Ports    []any     `yaml:"ports"`
+	Volumes  []any     `yaml:"volumes"`
+	Networks yaml.Node `yaml:"networks"`
+}
+
+// planResource is a top-level volume or network of a compose file
+type planResource struct {
+	Name     string `yaml:"name"`
+	External any    `yaml:"external"`
+}
+
+// merge returns r with the attributes override sets replacing its own
+func (r planResource) merge(override planResource) planResource {
+	if override.Name != "" {
+		r.Name = override.Name
+	}
+	if override.External != nil {
+		r.External = override.External
+	}
+	return r
+}
+
+// planFile is a compose file as far as planning is concerned
+type planFile struct {
+	Services map[string]planService  `yaml:"services"`
+	Volumes  map[string]planResource `yaml:"volumes"`
+	Networks map[string]planResource `yaml:"networks"`
+}
+
+// ProjectResources returns what the compose files define for projectName,
+// with ${VAR:-default} references resolved with lookup. Files are merged in
+// order: later files override the image of a service and add to its ports,
+// volumes and networks.
+func ProjectResources(projectName string, lookup func(string) (string, bool), composeFiles ...string) (*Resources, error) {
+	services := make(map[string]planService)
+	volumes := make(map[string]planResource)
+	networks := make(map[string]planResource)
+	for _, composeFile := range composeFiles {
+		data, err := os.ReadFile(composeFile)
+		if err != nil {
+			return nil, err
+		}
+
+		var f planFile
+		if err := yaml.Unmarshal(data, &f); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", composeFile, err)
+		}
+
+		for name, svc := range f.Services {
+			merged := services[name]
+			if svc.Image != "" {
+				merged.Image = svc.Image
+			}
+			if svc.Build != nil {
+				merged.Build = svc.Build
+			}
+			merged.Ports = append(merged.Ports, svc.Ports...)
+			merged.Volumes = append(merged.Volumes, svc.Volumes...)
+			if svc.Networks.Kind != 0 {
+				merged.Networks = svc.Networks
+			}
+			services[name] = merged
+		}
+		for key, volume := range f.Volumes {
+			volumes[key] = volumes[key].merge(volume)
+		}
+		for key, network := range f.Networks {
+			networks[key] = networks[key].merge(network)
+		}
+	}
+
+	expand := func(s string) string { return utils.ExpandEnv(s, lookup) }
+	resourceName := func(key string, resource planResource) string {
+		if resource.Name != "" {
+			return expand(resource.Name)
+		}
+		if isExternal(resource) {
+			return key
+		}
+		return projectName + "_" + key
+	}
+
+	resources := &Resources{Services: make(map[string]ServicePlan, len(services))}
+	usesDefault := false
+	for name, svc := range services {
+		plan := ServicePlan{Name: name, Image: expand(svc.Image), Build: svc.Build != nil}
+		if plan.Build && plan.Image == "" {
+			plan.Image = projectName + "-" + name
+		}
+		for _, port := range svc.Ports {
+			if mapping := portMapping(port, expand); mapping != "" && !slices.Contains(plan.Ports, mapping) {
+				plan.Ports = append(plan.Ports, mapping)
+			}
+		}
+		for _, mount := range svc.Volumes {
+			key := namedVolume(mount, expand)
+			if key == "" {
+				continue
+			}
+			if volume := resourceName(key, volumes[key]); !slices.Contains(plan.Volumes, volume) {
+				plan.Volumes = append(plan.Volumes, volume)
+			}
+		}
+		keys := networkKeys(svc.Networks)
+		if len(keys) == 0 {
+			keys = []string{defaultNetwork}
+		}
+		usesDefault = usesDefault || slices.Contains(keys, defaultNetwork)
+		for _, key := range keys {
+			plan.Networks = append(plan.Networks, resourceName(key, networks[key]))
+		}
+		resources.Services[name] = plan
+	}
+
+	for _, key := range sortedKeys(volumes) {
+		if !isExternal(volumes[key]) {
+			resources.Volumes = append(resources.Volumes, resourceName(key, volumes[key]))
+		}
+	}
+	// compose only creates the default network for the services on it
+	if _, ok := networks[defaultNetwork]; !ok && usesDefault {
+		networks[defaultNetwork] = planResource{}
+	}
+	for _, key := range sortedKeys(networks) {
+		if !isExternal(networks[key]) {
+			resources.Networks = append(resources.Networks, resourceName(key, networks[key]))
+		}
+	}
+	return resources, nil
+}
+
+// portMapping returns a ports entry, in its short or long syntax, as the
+// mapping it publishes, such as 127.0.0.1:5432:5432. Ports published on a
+// random host port are returned as their container port.
+func portMapping(port any, expand func(string) string) string {
+	switch p := port.(type) {
+	case string:
+		return expand(p)
+	case int:
+		return fmt.Sprint(p)
+	case map[string]any:
+		target := expand(fmt.Sprint(p["target"]))
+		published, ok := p["published"]
+		if !ok {
+			return target
+		}
+		mapping := expand(fmt.Sprint(published)) + ":" + target
+		if hostIP, ok := p["host_ip"].(string); ok && hostIP != "" {
+			mapping = expand(hostIP) + ":" + mapping
+		}
+		return mapping
+	}
+	return ""
+}
+
+// namedVolume returns the key of the named volume a volumes entry mounts,
+// or an empty string for bind mounts, anonymous volumes and tmpfs
+func namedVolume(mount any, expand func(string) string) string {
+	switch m := mount.(type) {
+	case string:
+		source, _, found := SplitVolumeSpec(expand(m))
+		if !found || IsHostPath(source) {
+			return ""
+		}
+		return source
+	case map[string]any:
+		if m["type"] != "volume" {
+			return ""
+		}
+		source, _ := m["source"].(string)
+		return source
+	}
+	return ""
+}
+
+// isExternal reports whether a top-level volume or network is external
+func isExternal(resource planResource) bool {
+	switch external := resource.External.(type) {
+	case bool:
+		return external
+	case map[string]any:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,73 @@
+package compose
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProjectResources(t *testing.T) {
+	dir := t.TempDir()
+	base := filepath.Join(dir, "docker-compose.yml")
+	override := filepath.Join(dir, "docker-compose.override.yml")
+	require.NoError(t, os.WriteFile(base, []byte(`services:
+  postgres:
+    image: postgres:${POSTGRES_VERSION:-16}
+    ports:
+      - "${POSTGRES_PORT:-5432}:5432"
+    volumes:
+      - pgdata:/var/lib/postgresql/data
+      - ./init:/docker-entrypoint-initdb.d
+      - /var/lib/anonymous
+  app:
+    build:
+      context: .
+    networks: [backend]
+volumes:
+  pgdata: {}
+  cache:
+    external: true
+networks:
+  backend:
+    name: corp-backend
+`), 0644))
+	require.NoError(t, os.WriteFile(override, []byte(`services:
+  postgres:
+    ports:
+      - target: 5433
+        published: 15433
+        host_ip: 127.0.0.1
+    volumes:
+      - type: volume
+        source: cache
+        target: /cache
+`), 0644))
+
+	lookup := func(name string) (string, bool) {
+		if name == "POSTGRES_PORT" {
+			return "15432", true
+		}
+		return "", false
+	}
+	resources, err := ProjectResources("demo", lookup, base, override)
+	require.NoError(t, err)
+
+	assert.Equal(t, ServicePlan{
+		Name:     "postgres",
+		Image:    "postgres:16",
+		Ports:    []string{"15432:5432", "127.0.0.1:15433:5433"},
+		Volumes:  []string{"demo_pgdata", "cache"},
+		Networks: []string{"demo_default"},
+	}, resources.Services["postgres"])
+	assert.Equal(t, ServicePlan{
+		Name:     "app",
+		Image:    "demo-app",
+		Build:    true,
+		Networks: []string{"corp-backend"},
+	}, resources.Services["app"])
+	assert.Equal(t, []string{"demo_pgdata"}, resources.Volumes, "external volumes are left out")
+	assert.Equal(t, []string{"corp-backend", "demo_default"}, resources.Networks)
+}
@@ -0,0 +1,39 @@
+package compose
+
+import (
+	"fmt"
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ServiceProfiles returns the profiles of each service that is assigned to
+// at least one. Files are merged in order, later files overriding the
+// profiles of a service.
+func ServiceProfiles(composeFiles ...string) (map[string][]string, error) {
+	profiles := make(map[string][]string)
+	for _, composeFile := range composeFiles {
+		data, err := os.ReadFile(composeFile)
+		if err != nil {
+			return nil, err
+		}
+
+		var f file
+		if err := yaml.Unmarshal(data, &f); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", composeFile, err)
+		}
+
+		for name, svc := range f.Services {
+			if svc.Profiles != nil {
+				profiles[name] = svc.Profiles
+			}
+		}
+	}
+
+	for name, serviceProfiles := range profiles {
+		if len(serviceProfiles) == 0 {
+			delete(profiles, name)
+		}
+	}
+	return profiles, nil
+}
@@ -0,0 +1,79 @@
+package compose
+
+import (
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// Traefik entry points, as configured in the proxy service definition
+const (
+	proxyEntryPointHTTP  = "web"
+	proxyEntryPointHTTPS = "websecure"
+)
+
+// ProxyConfigDir is where the proxy container mounts dev-stack/proxy
+const ProxyConfigDir = "/etc/traefik/proxy"
+
+// Files under dev-stack/proxy
+const (
+	ProxyDynamicFileName = "dynamic.yml"
+	ProxyCertFileName    = "certs/local.pem"
+	ProxyKeyFileName     = "certs/local-key.pem"
+)
+
+// ProxyLabels returns the Traefik labels that route host to port of a
+// service on the project's network. With tls the route is served on the
+// HTTPS entry point as well.
+func ProxyLabels(projectName, serviceName, host string, port int, tls bool) map[string]string {
+	name := strings.ReplaceAll(projectName+"-"+serviceName, ".", "-")
+	rule := fmt.Sprintf("Host(`%s`)", host)
+	labels := map[string]string{
+		"traefik.enable":         "true",
+		"traefik.docker.network": projectName + "-network",
+		"traefik.http.services." + name + ".loadbalancer.server.port": strconv.Itoa(port),
+	}
+
+	routers := map[string]string{name: proxyEntryPointHTTP}
+	if tls {
+		routers[name+"-secure"] = proxyEntryPointHTTPS
+	}
+	for router, entryPoint := range routers {
+		prefix := "traefik.http.routers." + router
+		labels[prefix+".rule"] = rule
+		labels[prefix+".entrypoints"] = entryPoint
+		labels[prefix+".service"] = name
+		if entryPoint == proxyEntryPointHTTPS {
+			labels[prefix+".tls"] = "true"
+		}
+	}
+	return labels
+}
+
+// proxyDynamicConfig is the Traefik file provider configuration
+type proxyDynamicConfig struct {
+	TLS *proxyTLS `yaml:"tls,omitempty"`
+}
+
+type proxyTLS struct {
+	Certificates []proxyCertificate `yaml:"certificates"`
+}
+
+type proxyCertificate struct {
+	CertFile string `yaml:"certFile"`
+	KeyFile  string `yaml:"keyFile"`
+}
+
+// ProxyDynamicConfig renders the proxy's file provider configuration, which
+// serves the local certificate when tls is set
+func ProxyDynamicConfig(tls bool) ([]byte, error) {
+	var config proxyDynamicConfig
+	if tls {
+		config.TLS = &proxyTLS{Certificates: []proxyCertificate{{
+			CertFile: path.Join(ProxyConfigDir, ProxyCertFileName),
+			KeyFile:  path.Join(ProxyConfigDir, ProxyKeyFileName),
+		}}}
+	}
+	return encodeYAML(config)
+}
@@ -0,0 +1,44 @@
+package compose
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func TestProxyLabels(t *testing.T) {
+	labels := ProxyLabels("demo", "jaeger", "jaeger.localhost", 16686, false)
+	assert.Equal(t, map[string]string{
+		"traefik.enable":                                             "true",
+		"traefik.docker.network":                                     "demo-network",
+		"traefik.http.routers.demo-jaeger.rule":                      "Host(`jaeger.localhost`)",
+		"traefik.http.routers.demo-jaeger.entrypoints":               "web",
+		"traefik.http.routers.demo-jaeger.service":                   "demo-jaeger",
+		"traefik.http.services.demo-jaeger.loadbalancer.server.port": "16686",
+	}, labels)
+
+	labels = ProxyLabels("demo.app", "jaeger", "jaeger.localhost", 16686, true)
+	assert.Equal(t, "websecure", labels["traefik.http.routers.demo-app-jaeger-secure.entrypoints"])
+	assert.Equal(t, "true", labels["traefik.http.routers.demo-app-jaeger-secure.tls"])
+	assert.Equal(t, "demo-app-jaeger", labels["traefik.http.routers.demo-app-jaeger-secure.service"])
+	assert.Equal(t, "web", labels["traefik.http.routers.demo-app-jaeger.entrypoints"])
+	assert.NotContains(t, labels, "traefik.http.routers.demo-app-jaeger.tls")
+}
+
+func TestProxyDynamicConfig(t *testing.T) {
+	data, err := ProxyDynamicConfig(false)
+	require.NoError(t, err)
+	assert.Equal(t, "{}\n", string(data))
+
+	data, err = ProxyDynamicConfig(true)
+	require.NoError(t, err)
+	var config proxyDynamicConfig
+	require.NoError(t, yaml.Unmarshal(data, &config))
+	require.NotNil(t, config.TLS)
+	assert.Equal(t, []proxyCertificate{{
+		CertFile: "/etc/traefik/proxy/certs/local.pem",
+		KeyFile:  "/etc/traefik/proxy/certs/local-key.pem",
+	}}, config.TLS.Certificates)
+}
@@ -0,0 +1,113 @@
+package compose
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/isaacgarza/dev-stack/internal/pkg/constants"
+	"github.com/isaacgarza/dev-stack/internal/pkg/types"
+)
+
+// profileFilePrefix starts the name of the compose files generated for
+// profiles that set resource limits
+const profileFilePrefix = "docker-compose.profile-"
+
+// ProfileFile returns the path of the compose file holding the resource
+// limits of profile, merged over the generated file when the profile is active
+func ProfileFile(profile string) string {
+	return filepath.Join(constants.DevStackDir, profileFilePrefix+profile+".yml")
+}
+
+// ProfileFiles returns the profile compose files in the project, keyed by
+// profile name
+func ProfileFiles() (map[string]string, error) {
+	paths, err := filepath.Glob(filepath.Join(constants.DevStackDir, profileFilePrefix+"*.yml"))
+	if err != nil {
+		return nil, err
+	}
+	files := make(map[string]string, len(paths))
+	for _, path := range paths {
+		profile := strings.TrimSuffix(strings.TrimPrefix(filepath.Base(path), profileFilePrefix), ".yml")
+		files[profile] = path
+	}
+	return files, nil
+}
+
+// resourceFile is a compose file as far as resource limits are concerned
+type resourceFile struct {
+	Services map[string]resourceService `yaml:"services"`
+}
+
+type resourceService struct {
+	Deploy struct {
+		Resources struct {
+			Limits struct {
+				CPUs   string `yaml:"cpus,omitempty"`
+				Memory string `yaml:"memory,omitempty"`
+				PIDs   int64  `yaml:"pids,omitempty"`
+			} `yaml:"limits,omitempty"`
+		} `yaml:"resources,omitempty"`
+	} `yaml:"deploy,omitempty"`
+}
+
+// ServiceResources returns the deploy.resources.limits of each service that
+// sets any. Files are merged in order, later files overriding single limits.
+func ServiceResources(composeFiles ...string) (map[string]types.ResourceLimits, error) {
+	resources := make(map[string]types.ResourceLimits)
+	for _, composeFile := range composeFiles {
+		data, err := os.ReadFile(composeFile)
+		if err != nil {
+			return nil, err
+		}
+
+		var f resourceFile
+		if err := yaml.Unmarshal(data, &f); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", composeFile, err)
+		}
+
+		for name, svc := range f.Services {
+			limits := svc.Deploy.Resources.Limits
+			resources[name] = resources[name].Merge(types.ResourceLimits{
+				CPU:    limits.CPUs,
+				Memory: limits.Memory,
+				PIDs:   limits.PIDs,
+			})
+		}
+	}
+
+	for name, limits := range resources {
+		if limits.IsZero() {
+			delete(resources, name)
+		}
+	}
+	return resources, nil
+}
+
+// ResourceFile renders a compose file that sets the resource limits of the
+// given services and nothing else
+func ResourceFile(resources map[string]types.ResourceLimits) ([]byte, error) {
+	f := resourceFile{Services: make(map[string]resourceService, len(resources))}
+	for name, limits := range resources {
+		var svc resourceService
+		svc.Deploy.Resources.Limits.CPUs = limits.CPU
+		svc.Deploy.Resources.Limits.Memory = limits.Memory
+		svc.Deploy.Resources.Limits.PIDs = limits.PIDs
+		f.Services[name] = svc
+	}
+
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(f); err != nil {
+		return nil, err
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,53 @@
+package compose
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/isaacgarza/dev-stack/internal/pkg/constants"
+	"github.com/isaacgarza/dev-stack/internal/pkg/types"
+)
+
+func TestServiceResources(t *testing.T) {
+	dir := t.TempDir()
+	base := filepath.Join(dir, "docker-compose.yml")
+	profile := filepath.Join(dir, "docker-compose.profile-ci.yml")
+	require.NoError(t, os.WriteFile(base, []byte(`services:
+  postgres:
+    image: postgres:15
+    deploy:
+      resources:
+        limits:
+          cpus: "2"
+          memory: 512m
+  redis:
+    image: redis:7
+`), 0644))
+
+	rendered, err := ResourceFile(map[string]types.ResourceLimits{"postgres": {Memory: "256m", PIDs: 100}})
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(profile, rendered, 0644))
+
+	resources, err := ServiceResources(base)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]types.ResourceLimits{"postgres": {CPU: "2", Memory: "512m"}}, resources)
+
+	resources, err = ServiceResources(base, profile)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]types.ResourceLimits{"postgres": {CPU: "2", Memory: "256m", PIDs: 100}}, resources)
+}
+
+func TestProfileFiles(t *testing.T) {
+	t.Chdir(t.TempDir())
+	require.NoError(t, os.MkdirAll(constants.DevStackDir, 0755))
+	require.NoError(t, os.WriteFile(ProfileFile("ci"), []byte("services: {}\n"), 0644))
+	require.NoError(t, os.WriteFile(constants.DockerComposeFile, []byte("services: {}\n"), 0644))
+
+	files, err := ProfileFiles()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"ci": filepath.Join(constants.DevStackDir, "docker-compose.profile-ci.yml")}, files)
+}
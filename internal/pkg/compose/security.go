@@ -0,0 +1,59 @@
+package compose
+
+import (
+	"bytes"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/isaacgarza/dev-stack/internal/pkg/constants"
+)
+
+// securityFileName is the compose file, under dev-stack, that applies the
+// security profile of the project to its containers
+const securityFileName = "docker-compose.security.yml"
+
+// SecurityFile returns the path of the compose file holding the security
+// settings of the project, merged over the generated file while it exists
+func SecurityFile() string {
+	return filepath.Join(constants.DevStackDir, securityFileName)
+}
+
+// ContainerSecurity is what the security profile sets on a container
+type ContainerSecurity struct {
+	ReadOnly    bool     `yaml:"read_only,omitempty"`
+	Tmpfs       []string `yaml:"tmpfs,omitempty"`
+	SecurityOpt []string `yaml:"security_opt,omitempty"`
+	CapDrop     []string `yaml:"cap_drop,omitempty"`
+	CapAdd      []string `yaml:"cap_add,omitempty"`
+	// UsernsMode is host for containers exempt from the user namespace
+	// remapping of the daemon
+	UsernsMode string `yaml:"userns_mode,omitempty"`
+	Runtime    string `yaml:"runtime,omitempty"`
+}
+
+// IsZero reports whether the settings change nothing
+func (s ContainerSecurity) IsZero() bool {
+	return !s.ReadOnly && len(s.Tmpfs) == 0 && len(s.SecurityOpt) == 0 && len(s.CapDrop) == 0 &&
+		len(s.CapAdd) == 0 && s.UsernsMode == "" && s.Runtime == ""
+}
+
+type securityFile struct {
+	Services map[string]ContainerSecurity `yaml:"services"`
+}
+
+// SecurityOverride renders a compose file that applies the given security
+// settings to the containers they are keyed by. Compose merges the lists
+// with those of the generated file and replaces its other settings.
+func SecurityOverride(containers map[string]ContainerSecurity) ([]byte, error) {
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(securityFile{Services: containers}); err != nil {
+		return nil, err
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
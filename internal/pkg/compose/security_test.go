@@ -0,0 +1,32 @@
+package compose
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSecurityOverride(t *testing.T) {
+	data, err := SecurityOverride(map[string]ContainerSecurity{
+		"postgres": {ReadOnly: true, Tmpfs: []string{"/tmp"}, CapDrop: []string{"ALL"}, CapAdd: []string{"CHOWN"}},
+		"redis":    {UsernsMode: "host", Runtime: "runsc"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, `services:
+  postgres:
+    read_only: true
+    tmpfs:
+      - /tmp
+    cap_drop:
+      - ALL
+    cap_add:
+      - CHOWN
+  redis:
+    userns_mode: host
+    runtime: runsc
+`, string(data))
+
+	assert.True(t, ContainerSecurity{}.IsZero())
+	assert.False(t, ContainerSecurity{UsernsMode: "host"}.IsZero())
+}
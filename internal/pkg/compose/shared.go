@@ -0,0 +1,139 @@
+package compose
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/isaacgarza/dev-stack/internal/pkg/constants"
+)
+
+// SharedProject is the compose project that services shared between
+// projects run in
+const SharedProject = "dev-stack-shared"
+
+// SharedLabel is set on the containers of shared services, to the service
+// name
+const SharedLabel = "dev-stack.shared"
+
+// sharedFileName is the compose file, under dev-stack/tmp, that starts the
+// project's shared services in the shared project
+const sharedFileName = "docker-compose.shared.yml"
+
+// SharedFile returns the path of the compose file that moves the project's
+// shared services into the shared project. Unlike the workspace and
+// ephemeral files it is only merged when starting shared services.
+func SharedFile() string {
+	return filepath.Join(constants.DevStackDir, constants.TmpDir, sharedFileName)
+}
+
+// SharedContainerName returns the container name of a shared service
+func SharedContainerName(serviceName string) string {
+	return SharedProject + "-" + serviceName
+}
+
+// sharedOverride is a compose file renaming services, volumes and networks
+// so they no longer belong to the project that starts them
+type sharedOverride struct {
+	Services map[string]sharedService `yaml:"services"`
+	Volumes  map[string]namedResource `yaml:"volumes,omitempty"`
+	Networks map[string]namedResource `yaml:"networks,omitempty"`
+}
+
+type sharedService struct {
+	ContainerName string            `yaml:"container_name"`
+	Labels        map[string]string `yaml:"labels"`
+	Volumes       []sharedVolume    `yaml:"volumes,omitempty"`
+}
+
+type sharedVolume struct {
+	Type   string `yaml:"type"`
+	Source string `yaml:"source"`
+	Target string `yaml:"target"`
+}
+
+type namedResource struct {
+	Name   string            `yaml:"name,omitempty"`
+	Labels map[string]string `yaml:"labels,omitempty"`
+}
+
+// SharedOverride renders a compose file that turns the services of the
+// compose files into shared services: their containers, named volumes and
+// networks get names without the project's, so every project finds the
+// same container and data however it was started
+func SharedOverride(serviceNames []string, composeFiles ...string) ([]byte, error) {
+	override := sharedOverride{
+		Services: make(map[string]sharedService, len(serviceNames)),
+		Volumes:  make(map[string]namedResource),
+		Networks: make(map[string]namedResource),
+	}
+
+	// Shared resources belong to the shared project rather than to the
+	// project whose labels file labelled them
+	sharedOwner := map[string]string{ProjectLabel: SharedProject, ProjectDirLabel: ""}
+	targets := make(map[string]map[string]bool)
+	for _, composeFile := range composeFiles {
+		data, err := os.ReadFile(composeFile)
+		if err != nil {
+			return nil, err
+		}
+
+		var f file
+		if err := yaml.Unmarshal(data, &f); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", composeFile, err)
+		}
+
+		for _, name := range serviceNames {
+			svc, ok := f.Services[name]
+			if !ok {
+				continue
+			}
+			if targets[name] == nil {
+				targets[name] = make(map[string]bool)
+			}
+			for _, volume := range svc.Volumes {
+				if target, named := namedVolumeTarget(volume); named {
+					targets[name][target] = true
+				}
+			}
+		}
+		for key := range f.Networks {
+			override.Networks[key] = namedResource{Name: SharedProject + "-" + key, Labels: sharedOwner}
+		}
+	}
+
+	if _, ok := override.Networks[defaultNetwork]; !ok {
+		override.Networks[defaultNetwork] = namedResource{Labels: sharedOwner}
+	}
+
+	for _, name := range serviceNames {
+		if _, ok := targets[name]; !ok {
+			return nil, fmt.Errorf("service %s is not in the compose file", name)
+		}
+		svc := sharedService{
+			ContainerName: SharedContainerName(name),
+			Labels:        map[string]string{SharedLabel: name, ProjectLabel: SharedProject, ProjectDirLabel: ""},
+		}
+		for _, target := range sortedKeys(targets[name]) {
+			key := name + "-" + strings.Trim(strings.ReplaceAll(target, "/", "-"), "-")
+			svc.Volumes = append(svc.Volumes, sharedVolume{Type: "volume", Source: key, Target: target})
+			override.Volumes[key] = namedResource{Name: SharedProject + "-" + key}
+		}
+		override.Services[name] = svc
+	}
+
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(override); err != nil {
+		return nil, err
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
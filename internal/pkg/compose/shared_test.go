@@ -0,0 +1,70 @@
+package compose
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func TestSharedOverride(t *testing.T) {
+	dir := t.TempDir()
+	base := filepath.Join(dir, "docker-compose.yml")
+	require.NoError(t, os.WriteFile(base, []byte(`services:
+  postgres:
+    image: postgres:15
+    volumes:
+      - postgres_data:/var/lib/postgresql/data
+      - ./init:/docker-entrypoint-initdb.d:ro
+    networks:
+      - dev-stack
+  app:
+    image: app:latest
+networks:
+  dev-stack:
+    name: demo-network
+volumes:
+  postgres_data:
+`), 0644))
+
+	data, err := SharedOverride([]string{"postgres"}, base)
+	require.NoError(t, err)
+
+	var override sharedOverride
+	require.NoError(t, yaml.Unmarshal(data, &override))
+
+	require.Contains(t, override.Services, "postgres")
+	assert.NotContains(t, override.Services, "app")
+
+	postgres := override.Services["postgres"]
+	assert.Equal(t, "dev-stack-shared-postgres", postgres.ContainerName)
+	assert.Equal(t, map[string]string{SharedLabel: "postgres", ProjectLabel: SharedProject, ProjectDirLabel: ""}, postgres.Labels)
+	assert.Equal(t, []sharedVolume{{
+		Type:   "volume",
+		Source: "postgres-var-lib-postgresql-data",
+		Target: "/var/lib/postgresql/data",
+	}}, postgres.Volumes)
+
+	assert.Equal(t, map[string]namedResource{
+		"postgres-var-lib-postgresql-data": {Name: "dev-stack-shared-postgres-var-lib-postgresql-data"},
+	}, override.Volumes)
+	assert.Equal(t, map[string]namedResource{
+		"dev-stack": {Name: "dev-stack-shared-dev-stack", Labels: map[string]string{ProjectLabel: SharedProject, ProjectDirLabel: ""}},
+		"default":   {Labels: map[string]string{ProjectLabel: SharedProject, ProjectDirLabel: ""}},
+	}, override.Networks)
+}
+
+func TestSharedOverrideUnknownService(t *testing.T) {
+	base := filepath.Join(t.TempDir(), "docker-compose.yml")
+	require.NoError(t, os.WriteFile(base, []byte("services:\n  redis:\n    image: redis:7\n"), 0644))
+
+	_, err := SharedOverride([]string{"postgres"}, base)
+	assert.ErrorContains(t, err, "postgres")
+}
+
+func TestSharedContainerName(t *testing.T) {
+	assert.Equal(t, "dev-stack-shared-redis", SharedContainerName("redis"))
+}
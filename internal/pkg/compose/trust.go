@@ -0,0 +1,69 @@
+package compose
+
+import (
+	"bytes"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/isaacgarza/dev-stack/internal/pkg/constants"
+)
+
+// trustFileName is the compose file, under dev-stack, that mounts the trust
+// stores of the project over those of its containers
+const trustFileName = "docker-compose.trust.yml"
+
+// TrustFile returns the path of the compose file holding the trust stores
+// of the project, merged over the generated file while it exists
+func TrustFile() string {
+	return filepath.Join(constants.DevStackDir, trustFileName)
+}
+
+// TrustStoresDir returns the directory the trust stores of the project are
+// written to
+func TrustStoresDir() string {
+	return filepath.Join(constants.DevStackDir, constants.CertsDir)
+}
+
+// ContainerTrust is how a container is given the trust stores of the
+// project: the host files mounted over its own, keyed by the path they
+// replace, and the variables pointing libraries with their own store at
+// them
+type ContainerTrust struct {
+	Stores      map[string]string
+	Environment map[string]string
+}
+
+type trustFile struct {
+	Services map[string]trustedService `yaml:"services"`
+}
+
+type trustedService struct {
+	Volumes     []bindVolume      `yaml:"volumes,omitempty"`
+	Environment map[string]string `yaml:"environment,omitempty"`
+}
+
+// TrustOverride renders a compose file that mounts the trust stores of the
+// containers they are keyed by, read only. Compose merges these volumes and
+// variables with those of the generated file.
+func TrustOverride(containers map[string]ContainerTrust) ([]byte, error) {
+	f := trustFile{Services: make(map[string]trustedService, len(containers))}
+	for name, container := range containers {
+		svc := trustedService{Environment: container.Environment}
+		for _, target := range sortedKeys(container.Stores) {
+			svc.Volumes = append(svc.Volumes, bindVolume{Type: "bind", Source: container.Stores[target], Target: target, ReadOnly: true})
+		}
+		f.Services[name] = svc
+	}
+
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(f); err != nil {
+		return nil, err
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,35 @@
+package compose
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTrustOverride(t *testing.T) {
+	data, err := TrustOverride(map[string]ContainerTrust{
+		"elasticsearch": {
+			Stores: map[string]string{
+				"/usr/share/elasticsearch/jdk/lib/security/cacerts": "/app/dev-stack/certs/cacerts",
+				"/etc/ssl/certs/ca-certificates.crt":                "/app/dev-stack/certs/ca-bundle.pem",
+			},
+			Environment: map[string]string{"SSL_CERT_FILE": "/etc/ssl/certs/ca-certificates.crt"},
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, `services:
+  elasticsearch:
+    volumes:
+      - type: bind
+        source: /app/dev-stack/certs/ca-bundle.pem
+        target: /etc/ssl/certs/ca-certificates.crt
+        read_only: true
+      - type: bind
+        source: /app/dev-stack/certs/cacerts
+        target: /usr/share/elasticsearch/jdk/lib/security/cacerts
+        read_only: true
+    environment:
+      SSL_CERT_FILE: /etc/ssl/certs/ca-certificates.crt
+`, string(data))
+}
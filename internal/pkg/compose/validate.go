@@ -0,0 +1,319 @@
+// Package compose checks generated compose files against the Compose
+// Specification
+package compose
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/isaacgarza/dev-stack/internal/pkg/constants"
+	"github.com/isaacgarza/dev-stack/internal/pkg/types"
+)
+
+// ValidationError lists every problem found in a compose file
+type ValidationError struct {
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	return "invalid compose file: " + strings.Join(e.Problems, "; ")
+}
+
+var (
+	serviceNamePattern = regexp.MustCompile(`^[a-zA-Z0-9._-]+$`)
+	profilePattern     = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_.-]+$`)
+)
+
+// topLevelKeys are the top-level elements of the Compose Specification
+var topLevelKeys = map[string]bool{
+	"name": true, "services": true, "networks": true, "volumes": true,
+	"secrets": true, "configs": true, "include": true, "models": true,
+}
+
+// serviceKeys are the service attributes of the Compose Specification
+var serviceKeys = map[string]bool{}
+
+func init() {
+	for _, key := range strings.Fields(`annotations attach blkio_config build cap_add cap_drop cgroup
+		cgroup_parent command configs container_name cpu_count cpu_percent cpu_period cpu_quota
+		cpu_rt_period cpu_rt_runtime cpu_shares cpus cpuset credential_spec depends_on deploy develop
+		device_cgroup_rules devices dns dns_opt dns_search domainname entrypoint env_file environment
+		expose extends external_links extra_hosts gpus group_add healthcheck hostname image init ipc
+		isolation labels label_file links logging mac_address mem_limit mem_reservation mem_swappiness
+		memswap_limit models network_mode networks oom_kill_disable oom_score_adj pid pids_limit
+		platform ports post_start pre_stop privileged profiles provider pull_policy read_only restart
+		runtime scale secrets security_opt shm_size stdin_open stop_grace_period stop_signal
+		storage_opt sysctls tmpfs tty ulimits use_api_socket user userns_mode uts volumes
+		volumes_from working_dir`) {
+		serviceKeys[key] = true
+	}
+}
+
+// service holds the attributes that are cross-checked between services
+type service struct {
+	Image     string    `yaml:"image"`
+	Build     any       `yaml:"build"`
+	Extends   any       `yaml:"extends"`
+	Profiles  []string  `yaml:"profiles"`
+	DependsOn yaml.Node `yaml:"depends_on"`
+	Networks  yaml.Node `yaml:"networks"`
+	Volumes   []any     `yaml:"volumes"`
+	Develop   struct {
+		Watch []types.WatchRule `yaml:"watch"`
+	} `yaml:"develop"`
+}
+
+// file is a compose file as far as validation is concerned
+type file struct {
+	Services map[string]service `yaml:"services"`
+	Networks map[string]any     `yaml:"networks"`
+	Volumes  map[string]any     `yaml:"volumes"`
+}
+
+// Validate checks a compose file for the mistakes the Compose Specification
+// rejects: the obsolete version key, unknown attributes, services without an
+// image, and profiles, depends_on, extends, networks and volumes that don't
+// resolve. It is not a full schema check; `docker compose config` remains
+// the reference.
+func Validate(data []byte) error {
+	var raw map[string]yaml.Node
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return fmt.Errorf("failed to parse compose file: %w", err)
+	}
+
+	var f file
+	if err := yaml.Unmarshal(data, &f); err != nil {
+		return fmt.Errorf("failed to parse compose file: %w", err)
+	}
+
+	v := &validator{file: f}
+	v.checkTopLevel(raw)
+
+	if servicesNode, ok := raw["services"]; ok {
+		var rawServices map[string]map[string]any
+		if err := servicesNode.Decode(&rawServices); err != nil {
+			v.add("services must map service names to their definitions")
+		}
+		for _, name := range sortedKeys(rawServices) {
+			for _, key := range sortedKeys(rawServices[name]) {
+				if !serviceKeys[key] && !strings.HasPrefix(key, "x-") {
+					v.add("services.%s: unknown attribute %q", name, key)
+				}
+			}
+		}
+	}
+
+	for _, name := range sortedKeys(f.Services) {
+		v.checkService(name, f.Services[name])
+	}
+
+	if len(v.problems) > 0 {
+		return &ValidationError{Problems: v.problems}
+	}
+	return nil
+}
+
+type validator struct {
+	file     file
+	problems []string
+}
+
+func (v *validator) add(format string, args ...any) {
+	v.problems = append(v.problems, fmt.Sprintf(format, args...))
+}
+
+func (v *validator) checkTopLevel(raw map[string]yaml.Node) {
+	for _, key := range sortedKeys(raw) {
+		switch {
+		case key == "version":
+			v.add("the top-level version key is obsolete in the Compose Specification")
+		case !topLevelKeys[key] && !strings.HasPrefix(key, "x-"):
+			v.add("unknown top-level element %q", key)
+		}
+	}
+	if _, ok := raw["services"]; !ok {
+		if _, ok := raw["include"]; !ok {
+			v.add("no services defined")
+		}
+	}
+}
+
+func (v *validator) checkService(name string, svc service) {
+	prefix := "services." + name
+	if !serviceNamePattern.MatchString(name) {
+		v.add("%s: invalid service name", prefix)
+	}
+	if svc.Image == "" && svc.Build == nil && svc.Extends == nil {
+		v.add("%s: needs an image, a build or extends", prefix)
+	}
+
+	for _, profile := range svc.Profiles {
+		if !profilePattern.MatchString(profile) {
+			v.add("%s.profiles: invalid profile name %q", prefix, profile)
+		}
+	}
+
+	v.checkExtends(prefix, name, svc.Extends)
+	v.checkDependsOn(prefix, name, svc)
+	v.checkNetworks(prefix, svc.Networks)
+	v.checkVolumes(prefix, svc.Volumes)
+
+	for i, rule := range svc.Develop.Watch {
+		if rule.Path == "" {
+			v.add("%s.develop.watch[%d]: path is required", prefix, i)
+		}
+		switch rule.Action {
+		case types.WatchActionSync, types.WatchActionSyncRestart:
+			if rule.Target == "" {
+				v.add("%s.develop.watch[%d]: %s needs a target", prefix, i, rule.Action)
+			}
+		case types.WatchActionRestart, types.WatchActionRebuild:
+		default:
+			v.add("%s.develop.watch[%d]: unknown action %q", prefix, i, rule.Action)
+		}
+	}
+}
+
+func (v *validator) checkExtends(prefix, name string, extends any) {
+	switch value := extends.(type) {
+	case nil:
+	case string:
+		v.checkExtendsTarget(prefix, name, "", value)
+	case map[string]any:
+		target, _ := value["service"].(string)
+		file, _ := value["file"].(string)
+		if target == "" {
+			v.add("%s.extends: service is required", prefix)
+			return
+		}
+		v.checkExtendsTarget(prefix, name, file, target)
+	default:
+		v.add("%s.extends: must be a service name or a mapping", prefix)
+	}
+}
+
+func (v *validator) checkExtendsTarget(prefix, name, file, target string) {
+	if file != "" {
+		return
+	}
+	if target == name {
+		v.add("%s.extends: a service cannot extend itself", prefix)
+	} else if _, ok := v.file.Services[target]; !ok {
+		v.add("%s.extends: service %s is not defined", prefix, target)
+	}
+}
+
+func (v *validator) checkDependsOn(prefix, name string, svc service) {
+	node := svc.DependsOn
+	conditions := map[string]string{}
+	var names []string
+
+	switch node.Kind {
+	case 0:
+		return
+	case yaml.SequenceNode:
+		if err := node.Decode(&names); err != nil {
+			v.add("%s.depends_on: must list service names", prefix)
+			return
+		}
+	case yaml.MappingNode:
+		var long map[string]struct {
+			Condition string `yaml:"condition"`
+		}
+		if err := node.Decode(&long); err != nil {
+			v.add("%s.depends_on: must map service names to their conditions", prefix)
+			return
+		}
+		names = sortedKeys(long)
+		for dep, options := range long {
+			conditions[dep] = options.Condition
+		}
+	default:
+		v.add("%s.depends_on: must be a list or a mapping", prefix)
+		return
+	}
+
+	for _, dep := range names {
+		_, ok := v.file.Services[dep]
+		switch {
+		case dep == name:
+			v.add("%s.depends_on: a service cannot depend on itself", prefix)
+			continue
+		case !ok:
+			v.add("%s.depends_on: service %s is not defined", prefix, dep)
+			continue
+		}
+
+		switch conditions[dep] {
+		case "", constants.ConditionServiceStarted, constants.ConditionServiceHealthy, constants.ConditionServiceCompleted:
+		default:
+			v.add("%s.depends_on: unknown condition %q for %s", prefix, conditions[dep], dep)
+		}
+	}
+}
+
+func (v *validator) checkNetworks(prefix string, node yaml.Node) {
+	var names []string
+	switch node.Kind {
+	case 0:
+		return
+	case yaml.SequenceNode:
+		if err := node.Decode(&names); err != nil {
+			v.add("%s.networks: must list network names", prefix)
+			return
+		}
+	case yaml.MappingNode:
+		var networks map[string]any
+		if err := node.Decode(&networks); err != nil {
+			v.add("%s.networks: must map network names to their options", prefix)
+			return
+		}
+		names = sortedKeys(networks)
+	default:
+		v.add("%s.networks: must be a list or a mapping", prefix)
+		return
+	}
+
+	for _, network := range names {
+		if _, ok := v.file.Networks[network]; !ok && network != "default" {
+			v.add("%s.networks: network %s is not defined", prefix, network)
+		}
+	}
+}
+
+func (v *validator) checkVolumes(prefix string, volumes []any) {
+	for _, volume := range volumes {
+		var source string
+		switch value := volume.(type) {
+		case string:
+			var found bool
+			if source, _, found = SplitVolumeSpec(value); !found {
+				continue
+			}
+		case map[string]any:
+			if kind, _ := value["type"].(string); kind != "volume" {
+				continue
+			}
+			source, _ = value["source"].(string)
+		}
+		if source == "" || IsHostPath(source) || strings.HasPrefix(source, "$") {
+			continue
+		}
+		if _, ok := v.file.Volumes[source]; !ok {
+			v.add("%s.volumes: volume %s is not defined", prefix, source)
+		}
+	}
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
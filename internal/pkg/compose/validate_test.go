@@ -0,0 +1,167 @@
+package compose
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const validCompose = `name: demo
+services:
+  base:
+    image: alpine:3
+    profiles: [tools]
+  postgres:
+    image: postgres:15
+    networks: [dev-stack]
+    volumes:
+      - demo-postgres-data:/var/lib/postgresql/data
+      - ./init.sql:/docker-entrypoint-initdb.d/init.sql:ro
+      - C:\Users\dev\seed:/seed:ro
+    healthcheck:
+      test: ["CMD", "pg_isready"]
+  migrate:
+    extends: base
+    command: ["migrate"]
+    depends_on:
+      postgres:
+        condition: service_healthy
+  api:
+    build:
+      context: ..
+    networks:
+      dev-stack: {}
+    depends_on:
+      - postgres
+    develop:
+      watch:
+        - action: sync
+          path: ../src
+          target: /app/src
+    x-owner: platform
+volumes:
+  demo-postgres-data:
+networks:
+  dev-stack:
+x-defaults: {}
+`
+
+func TestValidate(t *testing.T) {
+	require.NoError(t, Validate([]byte(validCompose)))
+
+	tests := []struct {
+		name    string
+		compose string
+		problem string
+	}{
+		{
+			name:    "version key",
+			compose: "version: \"3.8\"\nservices:\n  a:\n    image: alpine\n",
+			problem: "version key is obsolete",
+		},
+		{
+			name:    "unknown top-level element",
+			compose: "services:\n  a:\n    image: alpine\nmetadata:\n  generator: dev-stack\n",
+			problem: `unknown top-level element "metadata"`,
+		},
+		{
+			name:    "no services",
+			compose: "networks:\n  dev-stack:\n",
+			problem: "no services defined",
+		},
+		{
+			name:    "unknown attribute",
+			compose: "services:\n  a:\n    image: alpine\n    memory_limit: 1g\n",
+			problem: `services.a: unknown attribute "memory_limit"`,
+		},
+		{
+			name:    "missing image",
+			compose: "services:\n  a:\n    command: [sleep, \"1\"]\n",
+			problem: "needs an image, a build or extends",
+		},
+		{
+			name:    "invalid profile",
+			compose: "services:\n  a:\n    image: alpine\n    profiles: [\"-debug\"]\n",
+			problem: `invalid profile name "-debug"`,
+		},
+		{
+			name:    "undefined dependency",
+			compose: "services:\n  a:\n    image: alpine\n    depends_on: [b]\n",
+			problem: "service b is not defined",
+		},
+		{
+			name:    "self dependency",
+			compose: "services:\n  a:\n    image: alpine\n    depends_on: [a]\n",
+			problem: "cannot depend on itself",
+		},
+		{
+			name:    "unknown condition",
+			compose: "services:\n  a:\n    image: alpine\n    depends_on:\n      b:\n        condition: service_ready\n  b:\n    image: alpine\n",
+			problem: `unknown condition "service_ready"`,
+		},
+		{
+			name:    "undefined extends",
+			compose: "services:\n  a:\n    extends:\n      service: base\n",
+			problem: "service base is not defined",
+		},
+		{
+			name:    "undefined network",
+			compose: "services:\n  a:\n    image: alpine\n    networks: [backend]\n",
+			problem: "network backend is not defined",
+		},
+		{
+			name:    "undefined volume",
+			compose: "services:\n  a:\n    image: alpine\n    volumes:\n      - data:/data\n",
+			problem: "volume data is not defined",
+		},
+		{
+			name:    "watch without target",
+			compose: "services:\n  a:\n    image: alpine\n    develop:\n      watch:\n        - action: sync\n          path: ./src\n",
+			problem: "sync needs a target",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Validate([]byte(tt.compose))
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.problem)
+		})
+	}
+
+	assert.Error(t, Validate([]byte("services: [")))
+}
+
+func TestValidateCollectsAllProblems(t *testing.T) {
+	err := Validate([]byte("version: \"3\"\nservices:\n  a:\n    depends_on: [b]\n"))
+	var validationErr *ValidationError
+	require.ErrorAs(t, err, &validationErr)
+	assert.Len(t, validationErr.Problems, 3)
+}
+
+func TestServiceProfiles(t *testing.T) {
+	composeFile := filepath.Join(t.TempDir(), "docker-compose.yml")
+	require.NoError(t, os.WriteFile(composeFile, []byte(`services:
+  postgres:
+    image: postgres:15
+  pgadmin:
+    image: dpage/pgadmin4
+    profiles: [tools, debug]
+  adminer:
+    image: adminer
+    profiles: [tools]
+`), 0644))
+
+	profiles, err := ServiceProfiles(composeFile)
+	require.NoError(t, err)
+	assert.Equal(t, map[string][]string{
+		"pgadmin": {"tools", "debug"},
+		"adminer": {"tools"},
+	}, profiles)
+
+	_, err = ServiceProfiles(filepath.Join(t.TempDir(), "missing.yml"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,109 @@
+package compose
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/isaacgarza/dev-stack/internal/pkg/constants"
+)
+
+// WorkspaceNetwork is the network key the workspace file attaches services to
+const WorkspaceNetwork = "workspace"
+
+// workspaceFileName is the compose file, under dev-stack/tmp, that joins a
+// workspace project to the workspace network
+const workspaceFileName = "docker-compose.workspace.yml"
+
+// WorkspaceFile returns the path of the compose file that joins the project
+// to its workspace, merged over the generated file while it exists
+func WorkspaceFile() string {
+	return filepath.Join(constants.DevStackDir, constants.TmpDir, workspaceFileName)
+}
+
+// workspaceOverride is a compose file joining every service to an external
+// network
+type workspaceOverride struct {
+	Services map[string]workspaceService `yaml:"services"`
+	Networks map[string]externalNetwork  `yaml:"networks"`
+}
+
+type workspaceService struct {
+	Networks []string `yaml:"networks"`
+}
+
+type externalNetwork struct {
+	Name     string `yaml:"name"`
+	External bool   `yaml:"external"`
+}
+
+// WorkspaceOverride renders a compose file that attaches every service of
+// the compose files to the external network, in addition to the networks
+// it is on already. Services without networks are on the default network,
+// which is kept explicitly since listing any network replaces it.
+func WorkspaceOverride(network string, composeFiles ...string) ([]byte, error) {
+	networks := make(map[string]map[string]bool)
+	for _, composeFile := range composeFiles {
+		data, err := os.ReadFile(composeFile)
+		if err != nil {
+			return nil, err
+		}
+
+		var f file
+		if err := yaml.Unmarshal(data, &f); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", composeFile, err)
+		}
+
+		for name, svc := range f.Services {
+			if networks[name] == nil {
+				networks[name] = make(map[string]bool)
+			}
+			for _, key := range networkKeys(svc.Networks) {
+				networks[name][key] = true
+			}
+		}
+	}
+
+	override := workspaceOverride{
+		Services: make(map[string]workspaceService, len(networks)),
+		Networks: map[string]externalNetwork{WorkspaceNetwork: {Name: network, External: true}},
+	}
+	for name, serviceNetworks := range networks {
+		if len(serviceNetworks) == 0 {
+			serviceNetworks["default"] = true
+		}
+		serviceNetworks[WorkspaceNetwork] = true
+		override.Services[name] = workspaceService{Networks: sortedKeys(serviceNetworks)}
+	}
+
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(override); err != nil {
+		return nil, err
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// networkKeys returns the networks of a service, written as a list or as a
+// mapping
+func networkKeys(node yaml.Node) []string {
+	var keys []string
+	switch node.Kind {
+	case yaml.SequenceNode:
+		for _, item := range node.Content {
+			keys = append(keys, item.Value)
+		}
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			keys = append(keys, node.Content[i].Value)
+		}
+	}
+	return keys
+}
@@ -0,0 +1,47 @@
+package compose
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func TestWorkspaceOverride(t *testing.T) {
+	dir := t.TempDir()
+	base := filepath.Join(dir, "docker-compose.yml")
+	require.NoError(t, os.WriteFile(base, []byte(`services:
+  api:
+    image: api:dev
+    networks:
+      - dev-stack
+  worker:
+    image: worker:dev
+    networks:
+      dev-stack:
+        aliases: [jobs]
+      backend: {}
+  cron:
+    image: cron:dev
+networks:
+  dev-stack:
+  backend:
+`), 0644))
+
+	data, err := WorkspaceOverride("shop-shared-network", base)
+	require.NoError(t, err)
+
+	var rendered workspaceOverride
+	require.NoError(t, yaml.Unmarshal(data, &rendered))
+	assert.Equal(t, workspaceOverride{
+		Services: map[string]workspaceService{
+			"api":    {Networks: []string{"dev-stack", WorkspaceNetwork}},
+			"worker": {Networks: []string{"backend", "dev-stack", WorkspaceNetwork}},
+			"cron":   {Networks: []string{"default", WorkspaceNetwork}},
+		},
+		Networks: map[string]externalNetwork{WorkspaceNetwork: {Name: "shop-shared-network", External: true}},
+	}, rendered)
+}
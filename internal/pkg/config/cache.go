@@ -0,0 +1,114 @@
+package config
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/gob"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/isaacgarza/dev-stack/internal/pkg/constants"
+	"github.com/isaacgarza/dev-stack/internal/pkg/version"
+)
+
+// commandCacheFormat is part of the hash of a cached command
+// configuration. Bump it when CommandConfig or postProcessConfig change, so
+// development builds, which all report the same version, don't read a
+// cache an older build wrote.
+const commandCacheFormat = 1
+
+// commandCacheFileName is the file under the cache directory holding the
+// parsed command configuration
+const commandCacheFileName = "commands.gob"
+
+// commandCache is the cached form of a parsed, post-processed and valid
+// command configuration
+type commandCache struct {
+	// Hash identifies the YAML and the binary the configuration was parsed
+	// from and by
+	Hash   string
+	Config CommandConfig
+}
+
+// CommandCachePath returns the file caching the parsed command
+// configuration: dev-stack/commands.gob under XDG_CACHE_HOME or ~/.cache
+func CommandCachePath() (string, error) {
+	dir := os.Getenv(constants.EnvCacheHome)
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("failed to locate the cache directory: %w", err)
+		}
+		dir = filepath.Join(home, ".cache")
+	}
+	return filepath.Join(dir, constants.AppName, commandCacheFileName), nil
+}
+
+// commandsHash returns the hash a configuration parsed from data is cached
+// under
+func commandsHash(data []byte) string {
+	hash := sha256.New()
+	fmt.Fprintf(hash, "%d\x00%s\x00", commandCacheFormat, version.GetAppVersion())
+	hash.Write(data)
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// loadCachedCommands returns the cached configuration when it was parsed
+// from YAML with hash, and false when there is none, it is stale or the
+// cache is disabled
+func loadCachedCommands(hash string) (*CommandConfig, bool) {
+	if os.Getenv(constants.EnvNoCommandCache) != "" {
+		return nil, false
+	}
+	path, err := CommandCachePath()
+	if err != nil {
+		return nil, false
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, false
+	}
+	var cache commandCache
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&cache); err != nil || cache.Hash != hash {
+		return nil, false
+	}
+	return &cache.Config, true
+}
+
+// saveCachedCommands caches config as parsed from YAML with hash. The
+// cache only makes later runs faster, so failing to write it is ignored.
+func saveCachedCommands(hash string, config *CommandConfig) {
+	if os.Getenv(constants.EnvNoCommandCache) != "" {
+		return
+	}
+	path, err := CommandCachePath()
+	if err != nil {
+		return
+	}
+	var data bytes.Buffer
+	if err := gob.NewEncoder(&data).Encode(commandCache{Hash: hash, Config: *config}); err != nil {
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return
+	}
+
+	// Write a temporary file and rename it over the cache, so concurrent
+	// runs never read a partial one
+	tmp, err := os.CreateTemp(filepath.Dir(path), commandCacheFileName+".*")
+	if err != nil {
+		return
+	}
+	_, err = tmp.Write(data.Bytes())
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), path)
+	}
+	if err != nil {
+		_ = os.Remove(tmp.Name())
+	}
+}
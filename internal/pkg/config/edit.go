@@ -0,0 +1,108 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/isaacgarza/dev-stack/internal/pkg/utils"
+)
+
+// The project configuration is edited line by line rather than re-encoded,
+// since encoding a parsed document drops blank lines and reflows comments
+
+// readConfigLines reads the project configuration at path as lines along
+// with its parsed top-level mapping, which is nil for an empty file
+func readConfigLines(path string) ([]string, *yaml.Node, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read config: %w", err)
+	}
+
+	var document yaml.Node
+	if err := yaml.Unmarshal(data, &document); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse config: %w", err)
+	}
+
+	lines := utils.SplitLines(string(data))
+
+	if len(document.Content) == 0 {
+		return lines, nil, nil
+	}
+	root := document.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return nil, nil, fmt.Errorf("failed to parse config: top level is not a mapping")
+	}
+	return lines, root, nil
+}
+
+// writeConfigLines writes lines back to the configuration at path, keeping
+// its permissions and line endings
+func writeConfigLines(path string, lines []string) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return fmt.Errorf("failed to read config: %w", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read config: %w", err)
+	}
+	content := utils.JoinLines(lines, utils.LineEnding(string(data)))
+	if err := os.WriteFile(path, []byte(content), info.Mode().Perm()); err != nil {
+		return fmt.Errorf("failed to write config: %w", err)
+	}
+	return nil
+}
+
+// appendSection appends a top-level section to lines, separated from the
+// content before it by a blank line
+func appendSection(lines []string, section ...string) []string {
+	if len(lines) > 0 && lines[len(lines)-1] != "" {
+		lines = append(lines, "")
+	}
+	return append(lines, section...)
+}
+
+// isNull reports whether node is an empty value, as in a bare "stack:" line
+func isNull(node *yaml.Node) bool {
+	return node.Kind == yaml.ScalarNode && node.Tag == "!!null"
+}
+
+// indentOf returns the indentation of a node's first line
+func indentOf(node *yaml.Node) string {
+	return strings.Repeat(" ", node.Column-1)
+}
+
+// childIndent returns the indentation of the entries of mapping, whose key
+// is key, placing them two spaces in from the key when it has none
+func childIndent(key, mapping *yaml.Node) string {
+	if mapping != nil && mapping.Kind == yaml.MappingNode && len(mapping.Content) > 0 {
+		return indentOf(mapping.Content[0])
+	}
+	return indentOf(key) + "  "
+}
+
+// mappingEntry returns the key and value nodes of key in mapping, or nils
+// if mapping is nil or has no such key
+func mappingEntry(mapping *yaml.Node, key string) (*yaml.Node, *yaml.Node) {
+	if mapping == nil {
+		return nil, nil
+	}
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			return mapping.Content[i], mapping.Content[i+1]
+		}
+	}
+	return nil, nil
+}
+
+// splice replaces the 1-based lines first to last with replacement. A last
+// before first inserts replacement before line first.
+func splice(lines []string, first, last int, replacement []string) []string {
+	result := make([]string, 0, len(lines)+len(replacement))
+	result = append(result, lines[:first-1]...)
+	result = append(result, replacement...)
+	return append(result, lines[last:]...)
+}
@@ -0,0 +1,516 @@
+package config
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/isaacgarza/dev-stack/internal/pkg/utils"
+)
+
+// DefaultMetadataVersion is the version FixCommands gives a commands file
+// that has none
+const DefaultMetadataVersion = "2.0"
+
+// flagTypeAliases maps common misspellings of flag types, in lower case,
+// to the supported type
+var flagTypeAliases = map[string]FlagType{
+	"boolean":     FlagTypeBool,
+	"integer":     FlagTypeInt,
+	"str":         FlagTypeString,
+	"number":      FlagTypeFloat,
+	"float64":     FlagTypeFloat,
+	"[]string":    FlagTypeStringArray,
+	"stringslice": FlagTypeStringArray,
+	"[]int":       FlagTypeIntArray,
+	"intslice":    FlagTypeIntArray,
+}
+
+// Fix is a change FixCommands makes to a commands file, or
+// MigrateProjectConfig to a project configuration
+type Fix struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// FixCommands applies safe fixes to the content of a commands file and
+// returns the fixed content with the fixes made:
+//   - missing metadata.version, descriptions, usages and flag types are added
+//   - flag types and names are normalized, such as Boolean to bool and
+//     dry_run to dry-run
+//   - categories and related commands naming undefined commands, and
+//     profiles naming services that are not in services, lose the reference
+//
+// Like the project configuration, the file is edited line by line, so its
+// comments and layout are kept. Fixes that can't be made that way, such as
+// inside flow mappings, are left for the user. A nil services skips the
+// profile check.
+func FixCommands(data []byte, services []string) ([]byte, []Fix, error) {
+	var document yaml.Node
+	if err := yaml.Unmarshal(data, &document); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse config YAML: %w", err)
+	}
+	if len(document.Content) == 0 || document.Content[0].Kind != yaml.MappingNode {
+		return nil, nil, fmt.Errorf("failed to parse config YAML: top level is not a mapping")
+	}
+	root := document.Content[0]
+
+	f := newFixer(data)
+	f.fixMetadata(root)
+
+	_, commands := mappingEntry(root, "commands")
+	defined := mappingKeys(commands)
+	if commands != nil && commands.Kind == yaml.MappingNode {
+		for i := 0; i+1 < len(commands.Content); i += 2 {
+			f.fixCommand("commands."+commands.Content[i].Value, commands.Content[i], commands.Content[i+1], defined)
+		}
+	}
+
+	_, global := mappingEntry(root, "global")
+	_, globalFlags := mappingEntry(global, "flags")
+	f.fixFlags("global.flags", globalFlags)
+
+	_, categories := mappingEntry(root, "categories")
+	forEachEntry(categories, func(name string, category *yaml.Node) {
+		_, list := mappingEntry(category, "commands")
+		f.removeReferences("categories."+name+".commands", list, "undefined command", defined)
+	})
+
+	if services != nil {
+		_, profiles := mappingEntry(root, "profiles")
+		forEachEntry(profiles, func(name string, profile *yaml.Node) {
+			_, list := mappingEntry(profile, "services")
+			f.removeReferences("profiles."+name+".services", list, "unknown service", services)
+		})
+	}
+
+	if len(f.fixes) == 0 {
+		return data, nil, nil
+	}
+	return f.content(), f.fixes, nil
+}
+
+// span replaces the runes start to end of a line with text
+type span struct {
+	start, end int
+	text       string
+}
+
+// fixer collects the line edits of the fixes
+type fixer struct {
+	lines []string
+	// ending is the line ending of the file, kept when it is written back
+	ending string
+	// spans are replacements within a line, after lines inserted after a
+	// line and drop lines removed, all keyed by 1-based line; after[0]
+	// inserts at the top
+	spans map[int][]span
+	after map[int][]string
+	drop  map[int]bool
+	fixes []Fix
+}
+
+// newFixer returns a fixer editing the lines of data
+func newFixer(data []byte) *fixer {
+	return &fixer{
+		lines:  utils.SplitLines(string(data)),
+		ending: utils.LineEnding(string(data)),
+		spans:  make(map[int][]span),
+		after:  make(map[int][]string),
+		drop:   make(map[int]bool),
+	}
+}
+
+// fix records a fix
+func (f *fixer) fix(field, format string, args ...interface{}) {
+	f.fixes = append(f.fixes, Fix{Field: field, Message: fmt.Sprintf(format, args...)})
+}
+
+// render returns the lines with the edits applied
+func (f *fixer) render() []string {
+	result := append([]string{}, f.after[0]...)
+	for i, line := range f.lines {
+		number := i + 1
+		if !f.drop[number] {
+			runes := []rune(line)
+			spans := f.spans[number]
+			sort.Slice(spans, func(a, b int) bool { return spans[a].start > spans[b].start })
+			for _, s := range spans {
+				runes = append(runes[:s.start:s.start], append([]rune(s.text), runes[s.end:]...)...)
+			}
+			result = append(result, string(runes))
+		}
+		result = append(result, f.after[number]...)
+	}
+	return result
+}
+
+// content returns the edited file content
+func (f *fixer) content() []byte {
+	return []byte(utils.JoinLines(f.render(), f.ending))
+}
+
+// insertField adds "key: value" as the first entry of mapping, whose key
+// is key. Flow mappings are left alone.
+func (f *fixer) insertField(key, mapping *yaml.Node, field, value string) bool {
+	if mapping.Kind == yaml.MappingNode && mapping.Style&yaml.FlowStyle != 0 {
+		return false
+	}
+	if mapping.Kind == yaml.MappingNode && len(mapping.Content) > 0 && mapping.Content[0].Line == key.Line {
+		return false
+	}
+	line := childIndent(key, mapping) + field + ": " + value
+	f.after[key.Line] = append(f.after[key.Line], line)
+	return true
+}
+
+// replaceScalar replaces a single-line scalar with value, keeping its
+// quoting style
+func (f *fixer) replaceScalar(node *yaml.Node, value string) bool {
+	if node.Line < 1 || node.Line > len(f.lines) {
+		return false
+	}
+	line := []rune(f.lines[node.Line-1])
+	start := node.Column - 1
+	end := scalarEnd(line, start, node)
+	if end < 0 {
+		return false
+	}
+	text := quoteLike(node, value)
+	if end == start {
+		// An empty value is written quoted, with the space a bare "key:"
+		// lacks
+		text = strconv.Quote(value)
+		if start > 0 && line[start-1] == ':' {
+			text = " " + text
+		}
+	}
+	f.spans[node.Line] = append(f.spans[node.Line], span{start: start, end: end, text: text})
+	return true
+}
+
+// fixMetadata adds a missing metadata.version
+func (f *fixer) fixMetadata(root *yaml.Node) {
+	key, metadata := mappingEntry(root, "metadata")
+	version := strconv.Quote(DefaultMetadataVersion)
+	switch {
+	case metadata == nil:
+		first := root.Content[0].Line - 1
+		f.after[first] = append(f.after[first], "metadata:", "  version: "+version, "")
+	case isNull(metadata) || metadata.Kind == yaml.MappingNode:
+		if _, value := mappingEntry(metadata, "version"); value == nil {
+			if !f.insertField(key, metadata, "version", version) {
+				return
+			}
+		} else if value.Value != "" || !f.replaceScalar(value, DefaultMetadataVersion) {
+			return
+		}
+	default:
+		return
+	}
+	f.fix("metadata.version", "added missing version %s", version)
+}
+
+// fixCommand fixes a command definition and its subcommands
+func (f *fixer) fixCommand(field string, key, command *yaml.Node, defined []string) {
+	if command.Kind != yaml.MappingNode {
+		return
+	}
+	name := key.Value
+
+	_, description := mappingEntry(command, "description")
+	if description == nil || description.Value == "" {
+		_, long := mappingEntry(command, "long_description")
+		if summary := firstSentence(long); summary != "" && f.setField(key, command, description, "description", summary) {
+			f.fix(field+".description", "added missing description from long_description")
+		}
+	}
+
+	_, usage := mappingEntry(command, "usage")
+	if usage == nil || usage.Value == "" {
+		value := name
+		if _, flags := mappingEntry(command, "flags"); flags != nil && len(flags.Content) > 0 {
+			value += " [flags]"
+		}
+		if f.setField(key, command, usage, "usage", value) {
+			f.fix(field+".usage", "added missing usage %q", value)
+		}
+	}
+
+	_, flags := mappingEntry(command, "flags")
+	f.fixFlags(field+".flags", flags)
+
+	_, related := mappingEntry(command, "related_commands")
+	f.removeReferences(field+".related_commands", related, "undefined command", defined)
+
+	_, subcommands := mappingEntry(command, "subcommands")
+	forEachEntryKey(subcommands, func(subKey, subcommand *yaml.Node) {
+		f.fixCommand(field+".subcommands."+subKey.Value, subKey, subcommand, defined)
+	})
+}
+
+// setField sets an empty field of mapping to value, adding the field when
+// node, its current value, is nil
+func (f *fixer) setField(key, mapping, node *yaml.Node, field, value string) bool {
+	if node == nil {
+		return f.insertField(key, mapping, field, strconv.Quote(value))
+	}
+	return f.replaceScalar(node, value)
+}
+
+// fixFlags adds missing flag types and normalizes flag types and names
+func (f *fixer) fixFlags(field string, flags *yaml.Node) {
+	if flags == nil || flags.Kind != yaml.MappingNode {
+		return
+	}
+	names := mappingKeys(flags)
+
+	forEachEntryKey(flags, func(key, flag *yaml.Node) {
+		flagField := field + "." + key.Value
+
+		if normalized := kebabCase(key.Value); normalized != key.Value && normalized != "" && !slices.Contains(names, normalized) {
+			if f.replaceScalar(key, normalized) {
+				names = append(names, normalized)
+				f.fix(flagField, "renamed flag to %s", normalized)
+			}
+		}
+
+		if flag.Kind != yaml.MappingNode {
+			return
+		}
+		_, flagType := mappingEntry(flag, "type")
+		if flagType == nil || flagType.Value == "" {
+			_, defaultValue := mappingEntry(flag, "default")
+			inferred := inferFlagType(defaultValue)
+			if f.setField(key, flag, flagType, "type", string(inferred)) {
+				f.fix(flagField+".type", "added missing type %s", inferred)
+			}
+			return
+		}
+		if !isValidFlagType(flagType.Value) {
+			if normalized, ok := normalizeFlagType(flagType.Value); ok && f.replaceScalar(flagType, string(normalized)) {
+				f.fix(flagField+".type", "normalized type %s to %s", flagType.Value, normalized)
+			}
+		}
+	})
+}
+
+// removeReferences removes the items of list that are not in known
+func (f *fixer) removeReferences(field string, list *yaml.Node, kind string, known []string) {
+	if list == nil || list.Kind != yaml.SequenceNode {
+		return
+	}
+
+	var kept, removed []*yaml.Node
+	for _, item := range list.Content {
+		if item.Kind == yaml.ScalarNode && !slices.Contains(known, item.Value) {
+			removed = append(removed, item)
+		} else {
+			kept = append(kept, item)
+		}
+	}
+	if len(removed) == 0 {
+		return
+	}
+
+	if list.Style&yaml.FlowStyle != 0 {
+		line := []rune(f.lines[list.Line-1])
+		start := list.Column - 1
+		end := flowSequenceEnd(line, start)
+		if end < 0 {
+			return
+		}
+		items := make([]string, 0, len(kept))
+		for _, item := range kept {
+			if item.Line != list.Line {
+				return
+			}
+			items = append(items, quoteLike(item, item.Value))
+		}
+		f.spans[list.Line] = append(f.spans[list.Line], span{start: start, end: end, text: "[" + strings.Join(items, ", ") + "]"})
+	} else {
+		for _, item := range removed {
+			if !strings.HasPrefix(strings.TrimSpace(f.lines[item.Line-1]), "-") {
+				return
+			}
+		}
+		for _, item := range removed {
+			f.drop[item.Line] = true
+		}
+	}
+
+	for _, item := range removed {
+		f.fix(field, "removed %s %s", kind, item.Value)
+	}
+}
+
+// mappingKeys returns the keys of a mapping node
+func mappingKeys(mapping *yaml.Node) []string {
+	var keys []string
+	forEachEntry(mapping, func(key string, _ *yaml.Node) {
+		keys = append(keys, key)
+	})
+	return keys
+}
+
+// forEachEntry calls fn for each entry of a mapping node
+func forEachEntry(mapping *yaml.Node, fn func(key string, value *yaml.Node)) {
+	forEachEntryKey(mapping, func(key, value *yaml.Node) {
+		fn(key.Value, value)
+	})
+}
+
+// forEachEntryKey calls fn with the key and value nodes of each entry of a
+// mapping node
+func forEachEntryKey(mapping *yaml.Node, fn func(key, value *yaml.Node)) {
+	if mapping == nil || mapping.Kind != yaml.MappingNode {
+		return
+	}
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		fn(mapping.Content[i], mapping.Content[i+1])
+	}
+}
+
+// scalarEnd returns the index after a single-line scalar starting at start,
+// or -1 when it doesn't end on the line
+func scalarEnd(line []rune, start int, node *yaml.Node) int {
+	if start < 0 || start > len(line) {
+		return -1
+	}
+	switch {
+	case node.Style&yaml.DoubleQuotedStyle != 0:
+		for i := start + 1; i < len(line); i++ {
+			if line[i] == '\\' {
+				i++
+			} else if line[i] == '"' {
+				return i + 1
+			}
+		}
+		return -1
+	case node.Style&yaml.SingleQuotedStyle != 0:
+		for i := start + 1; i < len(line); i++ {
+			if line[i] == '\'' {
+				if i+1 < len(line) && line[i+1] == '\'' {
+					i++
+					continue
+				}
+				return i + 1
+			}
+		}
+		return -1
+	case node.Style&(yaml.LiteralStyle|yaml.FoldedStyle) != 0:
+		return -1
+	}
+	if node.Value == "" || node.Tag == "!!null" {
+		// An empty plain scalar, as in "version:", takes no space on the line
+		return start
+	}
+	end := start + len([]rune(node.Value))
+	if end > len(line) || string(line[start:end]) != node.Value {
+		return -1
+	}
+	return end
+}
+
+// flowSequenceEnd returns the index after the flow sequence starting at
+// start, or -1 when it doesn't end on the line
+func flowSequenceEnd(line []rune, start int) int {
+	if start >= len(line) || line[start] != '[' {
+		return -1
+	}
+	var quote rune
+	for i := start + 1; i < len(line); i++ {
+		switch {
+		case quote != 0:
+			if line[i] == quote {
+				quote = 0
+			}
+		case line[i] == '"' || line[i] == '\'':
+			quote = line[i]
+		case line[i] == ']':
+			return i + 1
+		}
+	}
+	return -1
+}
+
+// quoteLike renders value with the quoting style of node
+func quoteLike(node *yaml.Node, value string) string {
+	switch {
+	case node.Style&yaml.DoubleQuotedStyle != 0:
+		return strconv.Quote(value)
+	case node.Style&yaml.SingleQuotedStyle != 0:
+		return "'" + strings.ReplaceAll(value, "'", "''") + "'"
+	}
+	return value
+}
+
+// firstSentence returns the first sentence of a long description, on one
+// line
+func firstSentence(node *yaml.Node) string {
+	if node == nil {
+		return ""
+	}
+	text := strings.Join(strings.Fields(node.Value), " ")
+	if end := strings.Index(text, ". "); end >= 0 {
+		return text[:end+1]
+	}
+	return text
+}
+
+// kebabCase returns a flag name in the lower-case, hyphenated form flags
+// use, such as dry-run for dryRun or dry_run
+func kebabCase(name string) string {
+	var builder strings.Builder
+	runes := []rune(name)
+	for i, r := range runes {
+		switch {
+		case r == '_' || r == ' ' || r == '-':
+			builder.WriteRune('-')
+		case unicode.IsUpper(r):
+			if i > 0 && (unicode.IsLower(runes[i-1]) || unicode.IsDigit(runes[i-1])) {
+				builder.WriteRune('-')
+			}
+			builder.WriteRune(unicode.ToLower(r))
+		default:
+			builder.WriteRune(r)
+		}
+	}
+	kebab := builder.String()
+	for strings.Contains(kebab, "--") {
+		kebab = strings.ReplaceAll(kebab, "--", "-")
+	}
+	return strings.Trim(kebab, "-")
+}
+
+// normalizeFlagType returns the supported flag type a type was meant to be
+func normalizeFlagType(flagType string) (FlagType, bool) {
+	lower := strings.ToLower(strings.TrimSpace(flagType))
+	for _, valid := range []FlagType{FlagTypeBool, FlagTypeString, FlagTypeInt, FlagTypeFloat, FlagTypeDuration, FlagTypeStringArray, FlagTypeIntArray} {
+		if lower == strings.ToLower(string(valid)) {
+			return valid, true
+		}
+	}
+	normalized, ok := flagTypeAliases[lower]
+	return normalized, ok
+}
+
+// inferFlagType returns the flag type of a default value
+func inferFlagType(node *yaml.Node) FlagType {
+	if node != nil && node.Kind == yaml.ScalarNode {
+		switch node.Tag {
+		case "!!bool":
+			return FlagTypeBool
+		case "!!int":
+			return FlagTypeInt
+		case "!!float":
+			return FlagTypeFloat
+		}
+	}
+	return FlagTypeString
+}
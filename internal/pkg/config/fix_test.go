@@ -0,0 +1,196 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const brokenCommands = `# Commands
+metadata:
+  description: "Test CLI"
+
+categories:
+  core:
+    name: "Core"
+    # Lifecycle commands
+    commands: ["up", "launch", 'down']
+  data:
+    name: "Data"
+    commands:
+      - backup
+      - archive
+
+commands:
+  up:
+    category: "core"
+    description: "Start services"
+    usage: "up [service...]"
+    flags:
+      dryRun:
+        type: "Boolean"
+        default: false
+      build_args:
+        default: ""
+      retries:
+        default: 3
+    related_commands: ["down", "launch"]
+
+  down:
+    category: "core"
+    long_description: |
+      Stop the running services. Volumes are kept.
+    flags:
+      volumes:
+        type: bool
+        default: false
+
+  backup:
+    category: "data"
+    description: "Back up data"
+    usage: "backup"
+    subcommands:
+      create:
+        description: "Create a backup"
+        flags:
+          out_dir:
+            type: string
+
+profiles:
+  web:
+    services: ["postgres", "mongo", "redis"]
+`
+
+const fixedCommands = `# Commands
+metadata:
+  version: "2.0"
+  description: "Test CLI"
+
+categories:
+  core:
+    name: "Core"
+    # Lifecycle commands
+    commands: ["up", 'down']
+  data:
+    name: "Data"
+    commands:
+      - backup
+
+commands:
+  up:
+    category: "core"
+    description: "Start services"
+    usage: "up [service...]"
+    flags:
+      dry-run:
+        type: "bool"
+        default: false
+      build-args:
+        type: "string"
+        default: ""
+      retries:
+        type: "int"
+        default: 3
+    related_commands: ["down"]
+
+  down:
+    description: "Stop the running services."
+    usage: "down [flags]"
+    category: "core"
+    long_description: |
+      Stop the running services. Volumes are kept.
+    flags:
+      volumes:
+        type: bool
+        default: false
+
+  backup:
+    category: "data"
+    description: "Back up data"
+    usage: "backup"
+    subcommands:
+      create:
+        usage: "create [flags]"
+        description: "Create a backup"
+        flags:
+          out-dir:
+            type: string
+
+profiles:
+  web:
+    services: ["postgres", "redis"]
+`
+
+func TestFixCommands(t *testing.T) {
+	fixed, fixes, err := FixCommands([]byte(brokenCommands), []string{"postgres", "redis"})
+	require.NoError(t, err)
+	assert.Equal(t, fixedCommands, string(fixed))
+
+	fields := make([]string, len(fixes))
+	for i, fix := range fixes {
+		fields[i] = fix.Field
+	}
+	assert.ElementsMatch(t, []string{
+		"metadata.version",
+		"commands.up.flags.dryRun",
+		"commands.up.flags.dryRun.type",
+		"commands.up.flags.build_args",
+		"commands.up.flags.build_args.type",
+		"commands.up.flags.retries.type",
+		"commands.up.related_commands",
+		"commands.down.description",
+		"commands.down.usage",
+		"commands.backup.subcommands.create.usage",
+		"commands.backup.subcommands.create.flags.out_dir",
+		"categories.core.commands",
+		"categories.data.commands",
+		"profiles.web.services",
+	}, fields)
+
+	// The fixed file loads and validates, and fixing it again changes nothing
+	config, err := LoadFromBytes(fixed)
+	require.NoError(t, err)
+	assert.True(t, config.Validate().Valid)
+
+	again, fixes, err := FixCommands(fixed, []string{"postgres", "redis"})
+	require.NoError(t, err)
+	assert.Empty(t, fixes)
+	assert.Equal(t, string(fixed), string(again))
+}
+
+func TestFixCommands_EmptyValues(t *testing.T) {
+	fixed, fixes, err := FixCommands([]byte("metadata:\n  version:\ncommands:\n  up:\n    description: \"\"\n    long_description: Start services.\n    usage: up\n"), nil)
+	require.NoError(t, err)
+	assert.Len(t, fixes, 2)
+	assert.Equal(t, "metadata:\n  version: \"2.0\"\ncommands:\n  up:\n    description: \"Start services.\"\n    long_description: Start services.\n    usage: up\n", string(fixed))
+}
+
+func TestFixCommands_SkipsProfilesWithoutServices(t *testing.T) {
+	_, fixes, err := FixCommands([]byte(fixedCommands+"  api:\n    services: [\"graphite\"]\n"), nil)
+	require.NoError(t, err)
+	assert.Empty(t, fixes)
+}
+
+func TestFixCommands_Invalid(t *testing.T) {
+	_, _, err := FixCommands([]byte("commands: [up"), nil)
+	assert.Error(t, err)
+
+	_, _, err = FixCommands([]byte("- up\n"), nil)
+	assert.Error(t, err)
+}
+
+func TestKebabCase(t *testing.T) {
+	tests := map[string]string{
+		"dry-run":   "dry-run",
+		"dryRun":    "dry-run",
+		"dry_run":   "dry-run",
+		"Dry__Run":  "dry-run",
+		"no-color":  "no-color",
+		"tlsCACert": "tls-cacert",
+		"http2Port": "http2-port",
+	}
+	for name, want := range tests {
+		assert.Equal(t, want, kebabCase(name), name)
+	}
+}
@@ -1,20 +1,30 @@
 package config
 
 import (
+	"bytes"
 	"fmt"
 	"strings"
 
+	"gopkg.in/yaml.v3"
+
 	"github.com/isaacgarza/dev-stack/internal/pkg/constants"
 )
 
 // GenerateConfig generates a dev-stack configuration file
 func GenerateConfig(projectName, environment string, services []string, validation, advanced map[string]bool) string {
+	return GenerateConfigWithOverrides(projectName, environment, services, nil, validation, advanced)
+}
+
+// GenerateConfigWithOverrides generates a dev-stack configuration file with
+// the given per-service overrides filled in
+func GenerateConfigWithOverrides(projectName, environment string, services []string, overrides map[string]map[string]interface{}, validation, advanced map[string]bool) string {
 	var builder strings.Builder
 
 	// Header comment
 	builder.WriteString(fmt.Sprintf("# %s Configuration\n", constants.AppNameTitle))
 	builder.WriteString(fmt.Sprintf("# Generated for project: %s\n", projectName))
 	builder.WriteString(fmt.Sprintf("# Documentation: %s\n\n", constants.ConfigDocsURL))
+	builder.WriteString(fmt.Sprintf("%s: %d\n\n", constants.SchemaVersionKey, SchemaVersion))
 
 	// Project section
 	builder.WriteString(fmt.Sprintf("%s:\n", constants.ProjectSection))
@@ -32,7 +42,7 @@ func GenerateConfig(projectName, environment string, services []string, validati
 	// Overrides section
 	builder.WriteString("# Service-specific overrides\n")
 	builder.WriteString(fmt.Sprintf("# Service configuration options: %s\n", constants.ServiceConfigURL))
-	builder.WriteString(fmt.Sprintf("%s: {}\n\n", constants.OverridesSection))
+	builder.WriteString(renderOverrides(overrides))
 
 	// Validation section
 	builder.WriteString(fmt.Sprintf("%s:\n", constants.ValidationSection))
@@ -44,6 +54,7 @@ func GenerateConfig(projectName, environment string, services []string, validati
 	builder.WriteString(fmt.Sprintf("  auto_start: %t\n", getBoolValue(advanced, "auto_start", constants.DefaultAutoStart)))
 	builder.WriteString(fmt.Sprintf("  pull_latest_images: %t\n", getBoolValue(advanced, "pull_latest_images", constants.DefaultPullLatestImages)))
 	builder.WriteString(fmt.Sprintf("  cleanup_on_recreate: %t\n", getBoolValue(advanced, "cleanup_on_recreate", constants.DefaultCleanupOnRecreate)))
+	builder.WriteString(fmt.Sprintf("  %s: %t\n", constants.AdvancedEnvFiles, getBoolValue(advanced, constants.AdvancedEnvFiles, constants.DefaultEnvFiles)))
 
 	return builder.String()
 }
@@ -55,3 +66,28 @@ func getBoolValue(m map[string]bool, key string, defaultValue bool) bool {
 	}
 	return defaultValue
 }
+
+// renderOverrides renders the overrides section, falling back to an empty
+// mapping when there is nothing to override or it cannot be encoded
+func renderOverrides(overrides map[string]map[string]interface{}) string {
+	empty := fmt.Sprintf("%s: {}\n\n", constants.OverridesSection)
+	if len(overrides) == 0 {
+		return empty
+	}
+
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(overrides); err != nil {
+		return empty
+	}
+	_ = encoder.Close()
+
+	var builder strings.Builder
+	builder.WriteString(fmt.Sprintf("%s:\n", constants.OverridesSection))
+	for _, line := range strings.Split(strings.TrimRight(buf.String(), "\n"), "\n") {
+		builder.WriteString("  " + line + "\n")
+	}
+	builder.WriteString("\n")
+	return builder.String()
+}
@@ -13,6 +13,9 @@ import (
 type Loader struct {
 	configPath string
 	cache      *CommandConfig
+	// fromCache is set when the configuration was read from the command
+	// cache instead of parsed
+	fromCache bool
 }
 
 // NewLoader creates a new configuration loader
@@ -44,6 +47,15 @@ func (l *Loader) Load() (*CommandConfig, error) {
 		}
 	}
 
+	// Parsing the YAML takes most of the startup of a command, so reuse the
+	// configuration an earlier run parsed from the same YAML
+	hash := commandsHash(data)
+	if cached, ok := loadCachedCommands(hash); ok {
+		l.cache = cached
+		l.fromCache = true
+		return cached, nil
+	}
+
 	// Parse YAML
 	var config CommandConfig
 	if err := yaml.Unmarshal(data, &config); err != nil {
@@ -60,10 +72,24 @@ func (l *Loader) Load() (*CommandConfig, error) {
 		return nil, fmt.Errorf("configuration post-processing failed: %w", err)
 	}
 
+	// Only cache valid configurations, so the warnings about an invalid one
+	// are shown on every run
+	if config.Validate().Valid {
+		saveCachedCommands(hash, &config)
+	}
+
 	l.cache = &config
+	l.fromCache = false
 	return &config, nil
 }
 
+// FromCache reports whether the last configuration loaded was read from
+// the command cache. Cached configurations were valid when they were
+// parsed, so they need no validation.
+func (l *Loader) FromCache() bool {
+	return l.fromCache
+}
+
 // LoadFromPath loads configuration from a specific path
 func (l *Loader) LoadFromPath(path string) (*CommandConfig, error) {
 	oldPath := l.configPath
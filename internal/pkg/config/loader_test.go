@@ -7,6 +7,9 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	embedded "github.com/isaacgarza/dev-stack/internal/config"
+	"github.com/isaacgarza/dev-stack/internal/pkg/constants"
 )
 
 func TestNewLoader(t *testing.T) {
@@ -185,6 +188,49 @@ help: {}
 	assert.Same(t, config1, config2)
 }
 
+func TestLoader_Load_CommandCache(t *testing.T) {
+	t.Setenv(constants.EnvCacheHome, t.TempDir())
+	configFile := filepath.Join(t.TempDir(), "commands.yaml")
+	require.NoError(t, os.WriteFile(configFile, embedded.EmbeddedCommandsYAML, 0644))
+
+	loader := NewLoader(configFile)
+	parsed, err := loader.Load()
+	require.NoError(t, err)
+	assert.False(t, loader.FromCache())
+	cachePath, err := CommandCachePath()
+	require.NoError(t, err)
+	assert.FileExists(t, cachePath)
+
+	loader = NewLoader(configFile)
+	cached, err := loader.Load()
+	require.NoError(t, err)
+	assert.True(t, loader.FromCache())
+	assert.Equal(t, parsed.Metadata, cached.Metadata)
+	require.Len(t, cached.Commands, len(parsed.Commands))
+	for name, command := range parsed.Commands {
+		assert.Equal(t, command.Usage, cached.Commands[name].Usage)
+		assert.Len(t, cached.Commands[name].Subcommands, len(command.Subcommands))
+		for flagName, flag := range command.Flags {
+			// Defaults keep their types, such as int
+			assert.Equal(t, flag, cached.Commands[name].Flags[flagName], "%s --%s", name, flagName)
+		}
+	}
+	assert.True(t, cached.Validate().Valid)
+
+	// A changed configuration is parsed again
+	require.NoError(t, os.WriteFile(configFile, append(embedded.EmbeddedCommandsYAML, "\n# changed\n"...), 0644))
+	loader = NewLoader(configFile)
+	_, err = loader.Load()
+	require.NoError(t, err)
+	assert.False(t, loader.FromCache())
+
+	t.Setenv(constants.EnvNoCommandCache, "1")
+	loader = NewLoader(configFile)
+	_, err = loader.Load()
+	require.NoError(t, err)
+	assert.False(t, loader.FromCache())
+}
+
 func TestLoader_resolveConfigPath(t *testing.T) {
 	tests := []struct {
 		name        string
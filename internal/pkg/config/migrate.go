@@ -0,0 +1,330 @@
+package config
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/isaacgarza/dev-stack/internal/pkg/constants"
+)
+
+// SchemaVersion is the layout version of the project configurations this
+// version of dev-stack writes. Configurations without a schema_version
+// predate it and are version 1.
+const SchemaVersion = 2
+
+// MigrationReport describes how MigrateProjectConfig upgraded a project
+// configuration
+type MigrationReport struct {
+	From    int   `json:"from"`
+	To      int   `json:"to"`
+	Changes []Fix `json:"changes"`
+}
+
+// migration upgrades a project configuration from schema version from to
+// the next one
+type migration struct {
+	from  int
+	apply func(f *fixer, root *yaml.Node)
+}
+
+// migrations run one after the other, so a configuration several versions
+// behind goes through each of them
+var migrations = []migration{
+	{from: 1, apply: migrateV1},
+}
+
+// ProjectSchemaVersion returns the schema version of project configuration
+// content
+func ProjectSchemaVersion(data []byte) (int, error) {
+	root, err := parseMapping(data)
+	if err != nil {
+		return 0, err
+	}
+	return schemaVersion(root)
+}
+
+// MigrateProjectConfig upgrades the content of a project configuration to
+// the current schema version and returns the upgraded content with a
+// report of the changes. Content already at the current version is
+// returned as it is. Like SetEnabledServices it edits the file line by
+// line, keeping its comments and layout.
+func MigrateProjectConfig(data []byte) ([]byte, *MigrationReport, error) {
+	report := &MigrationReport{}
+	for {
+		root, err := parseMapping(data)
+		if err != nil {
+			return nil, nil, err
+		}
+		version, err := schemaVersion(root)
+		if err != nil {
+			return nil, nil, err
+		}
+		if report.From == 0 {
+			report.From = version
+		}
+		report.To = version
+
+		if version > SchemaVersion {
+			return nil, nil, fmt.Errorf("the configuration has schema version %d, but this dev-stack only knows up to %d; upgrade dev-stack", version, SchemaVersion)
+		}
+		if version == SchemaVersion {
+			return data, report, nil
+		}
+
+		index := slices.IndexFunc(migrations, func(m migration) bool { return m.from == version })
+		if index < 0 {
+			return nil, nil, fmt.Errorf("no migration from schema version %d", version)
+		}
+		f := newFixer(data)
+		migrations[index].apply(f, root)
+		if err := f.setSchemaVersion(root, version+1); err != nil {
+			return nil, nil, err
+		}
+		report.Changes = append(report.Changes, f.fixes...)
+		data = f.content()
+	}
+}
+
+// parseMapping parses configuration content whose top level is a mapping
+func parseMapping(data []byte) (*yaml.Node, error) {
+	var document yaml.Node
+	if err := yaml.Unmarshal(data, &document); err != nil {
+		return nil, fmt.Errorf("failed to parse config: %w", err)
+	}
+	if len(document.Content) == 0 || document.Content[0].Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("failed to parse config: top level is not a mapping")
+	}
+	return document.Content[0], nil
+}
+
+// schemaVersion returns the schema version recorded in a configuration
+func schemaVersion(root *yaml.Node) (int, error) {
+	_, value := mappingEntry(root, constants.SchemaVersionKey)
+	if value == nil || isNull(value) {
+		return 1, nil
+	}
+	version, err := strconv.Atoi(value.Value)
+	if err != nil || version < 1 {
+		return 0, fmt.Errorf("invalid %s %q: must be a positive number", constants.SchemaVersionKey, value.Value)
+	}
+	return version, nil
+}
+
+// setSchemaVersion records version in the configuration, adding the key
+// at the top when it has none
+func (f *fixer) setSchemaVersion(root *yaml.Node, version int) error {
+	key, value := mappingEntry(root, constants.SchemaVersionKey)
+	text := strconv.Itoa(version)
+	switch {
+	case key == nil && root.Style&yaml.FlowStyle != 0:
+		return fmt.Errorf("cannot update %s: write the configuration as a block mapping", constants.SchemaVersionKey)
+	case key == nil:
+		// Comments right above the first key belong to it, so the version
+		// goes before them
+		at := root.Content[0].Line - 1
+		for at > 0 && strings.HasPrefix(strings.TrimSpace(f.lines[at-1]), "#") {
+			at--
+		}
+		f.after[at] = append(f.after[at], constants.SchemaVersionKey+": "+text, "")
+	case !f.replaceScalar(value, text):
+		return fmt.Errorf("cannot update %s: write it as a plain number", constants.SchemaVersionKey)
+	}
+	f.fix(constants.SchemaVersionKey, "set schema version %d", version)
+	return nil
+}
+
+// migrateV1 moves service lists to where version 2 reads them: the flat
+// services list, or services.enabled, to stack.enabled, and profiles
+// written as a list of services to profiles.<name>.services
+func migrateV1(f *fixer, root *yaml.Node) {
+	servicesKey, services := mappingEntry(root, constants.ServicesSection)
+	switch {
+	case services != nil && services.Kind == yaml.SequenceNode:
+		f.moveToStack(constants.ServicesSection, root, root, servicesKey, services, services, lastLine(services))
+	case services != nil && services.Kind == yaml.MappingNode:
+		enabledKey, enabled := mappingEntry(services, "enabled")
+		if enabled == nil || enabled.Kind != yaml.SequenceNode {
+			break
+		}
+		field := constants.ServicesSection + ".enabled"
+		if len(services.Content) == 2 {
+			// Nothing else is left in the section
+			f.moveToStack(field, root, root, servicesKey, services, enabled, lastLine(services))
+		} else {
+			f.moveToStack(field, root, services, enabledKey, enabled, enabled, lastLine(services))
+		}
+	}
+
+	_, profiles := mappingEntry(root, constants.ProfilesSection)
+	if profiles == nil || profiles.Style&yaml.FlowStyle != 0 {
+		return
+	}
+	forEachEntryKey(profiles, func(key, profile *yaml.Node) {
+		if profile.Kind == yaml.SequenceNode && f.wrapProfile(key, profile) {
+			f.fix(constants.ProfilesSection+"."+key.Value, "moved the service list to %s.%s.services", constants.ProfilesSection, key.Value)
+		}
+	})
+}
+
+// moveToStack adds the services of list to stack.enabled and removes the
+// entry of parent whose key and value are key and value. A new stack
+// section goes after line at, the end of the services section.
+func (f *fixer) moveToStack(field string, root, parent, key, value, list *yaml.Node, at int) {
+	var names []string
+	for _, item := range list.Content {
+		if item.Kind != yaml.ScalarNode {
+			return
+		}
+		names = append(names, item.Value)
+	}
+	if !f.removable(parent, key) {
+		return
+	}
+
+	added, ok := f.addEnabled(root, names, at)
+	if !ok {
+		return
+	}
+	for line := key.Line; line <= lastLine(value); line++ {
+		f.drop[line] = true
+	}
+
+	stackField := constants.StackSection + ".enabled"
+	if len(added) == 0 {
+		f.fix(field, "removed the service list, already in %s", stackField)
+	} else {
+		f.fix(field, "moved %s to %s", strings.Join(added, ", "), stackField)
+	}
+}
+
+// addEnabled adds the names missing from stack.enabled to it, creating the
+// stack section after line at when there is none, and returns the names
+// added. Flow mappings can't be edited.
+func (f *fixer) addEnabled(root *yaml.Node, names []string, at int) ([]string, bool) {
+	stackKey, stack := mappingEntry(root, constants.StackSection)
+	_, enabled := mappingEntry(stack, "enabled")
+
+	var existing []string
+	if enabled != nil && enabled.Kind == yaml.SequenceNode {
+		for _, item := range enabled.Content {
+			existing = append(existing, item.Value)
+		}
+	}
+	var added []string
+	for _, name := range names {
+		if !slices.Contains(existing, name) && !slices.Contains(added, name) {
+			added = append(added, name)
+		}
+	}
+
+	switch {
+	case stackKey == nil:
+		section := append([]string{constants.StackSection + ":"}, renderEnabled("  ", added)...)
+		f.after[at] = append(f.after[at], section...)
+		return added, true
+	case len(added) == 0:
+		return added, true
+	case isNull(stack):
+		f.after[stackKey.Line] = append(f.after[stackKey.Line], renderEnabled(childIndent(stackKey, nil), added)...)
+		return added, true
+	case stack.Kind != yaml.MappingNode || stack.Style&yaml.FlowStyle != 0:
+		return nil, false
+	}
+
+	enabledKey, _ := mappingEntry(stack, "enabled")
+	switch {
+	case enabledKey == nil:
+		f.after[stackKey.Line] = append(f.after[stackKey.Line], renderEnabled(childIndent(stackKey, stack), added)...)
+	case isNull(enabled):
+		for _, name := range added {
+			f.after[enabledKey.Line] = append(f.after[enabledKey.Line], indentOf(enabledKey)+"  - "+name)
+		}
+	case enabled.Kind != yaml.SequenceNode:
+		return nil, false
+	case enabled.Style&yaml.FlowStyle != 0:
+		line := []rune(f.lines[enabled.Line-1])
+		end := flowSequenceEnd(line, enabled.Column-1)
+		if end < 0 || lastLine(enabled) != enabled.Line {
+			return nil, false
+		}
+		items := make([]string, 0, len(enabled.Content)+len(added))
+		for _, item := range enabled.Content {
+			items = append(items, quoteLike(item, item.Value))
+		}
+		items = append(items, added...)
+		f.spans[enabled.Line] = append(f.spans[enabled.Line], span{start: enabled.Column - 1, end: end, text: "[" + strings.Join(items, ", ") + "]"})
+	default:
+		last := enabled.Content[len(enabled.Content)-1]
+		itemLine := f.lines[last.Line-1]
+		prefix := itemLine[:len(itemLine)-len(strings.TrimLeft(itemLine, " "))]
+		for _, name := range added {
+			f.after[lastLine(enabled)] = append(f.after[lastLine(enabled)], prefix+"- "+name)
+		}
+	}
+	return added, true
+}
+
+// wrapProfile rewrites a profile written as a list of services to a
+// mapping with the list under services, keeping the items as written
+func (f *fixer) wrapProfile(key, profile *yaml.Node) bool {
+	if !f.removable(nil, key) {
+		return false
+	}
+	indent := indentOf(key)
+	line := []rune(f.lines[key.Line-1])
+
+	if profile.Style&yaml.FlowStyle != 0 {
+		start := profile.Column - 1
+		end := flowSequenceEnd(line, start)
+		if profile.Line != key.Line || end < 0 {
+			return false
+		}
+		f.drop[key.Line] = true
+		f.after[key.Line] = append(f.after[key.Line],
+			strings.TrimRight(string(line[:start]), " "),
+			indent+"  services: "+string(line[start:]),
+		)
+		return true
+	}
+
+	var items []string
+	for _, item := range profile.Content {
+		text := strings.TrimSpace(f.lines[item.Line-1])
+		if item.Kind != yaml.ScalarNode || item.Line == key.Line || !strings.HasPrefix(text, "-") {
+			return false
+		}
+		items = append(items, text)
+	}
+	f.after[key.Line] = append(f.after[key.Line], indent+"  services:")
+	for i, item := range profile.Content {
+		f.drop[item.Line] = true
+		f.after[key.Line] = append(f.after[key.Line], indent+"    "+items[i])
+	}
+	return true
+}
+
+// removable reports whether the entry whose key is key starts its own
+// line, in a block mapping parent when one is given, so its lines can be
+// dropped or rewritten
+func (f *fixer) removable(parent, key *yaml.Node) bool {
+	if parent != nil && parent.Style&yaml.FlowStyle != 0 {
+		return false
+	}
+	if key.Line < 1 || key.Line > len(f.lines) {
+		return false
+	}
+	return strings.TrimSpace(f.lines[key.Line-1][:min(key.Column-1, len(f.lines[key.Line-1]))]) == ""
+}
+
+// lastLine returns the last line a node or any of its children is on
+func lastLine(node *yaml.Node) int {
+	last := node.Line
+	for _, child := range node.Content {
+		last = max(last, lastLine(child))
+	}
+	return last
+}
@@ -0,0 +1,160 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+const legacyProjectConfig = `# Dev Stack Configuration
+# Generated for project: shop
+
+project:
+  name: shop
+  environment: local
+
+# Services to run
+services:
+  - postgres
+  - redis   # cache
+  - kafka
+
+profiles:
+  minimal: [postgres]
+  # Everything for the event pipeline
+  events:
+    - postgres
+    - kafka
+  ci:
+    services: [postgres]
+    protected: true
+`
+
+const migratedProjectConfig = `# Dev Stack Configuration
+# Generated for project: shop
+
+schema_version: 2
+
+project:
+  name: shop
+  environment: local
+
+# Services to run
+stack:
+  enabled:
+    - postgres
+    - redis
+    - kafka
+
+profiles:
+  minimal:
+    services: [postgres]
+  # Everything for the event pipeline
+  events:
+    services:
+      - postgres
+      - kafka
+  ci:
+    services: [postgres]
+    protected: true
+`
+
+func TestMigrateProjectConfig(t *testing.T) {
+	migrated, report, err := MigrateProjectConfig([]byte(legacyProjectConfig))
+	require.NoError(t, err)
+	assert.Equal(t, migratedProjectConfig, string(migrated))
+	assert.Equal(t, 1, report.From)
+	assert.Equal(t, SchemaVersion, report.To)
+
+	fields := make([]string, len(report.Changes))
+	for i, change := range report.Changes {
+		fields[i] = change.Field
+	}
+	assert.Equal(t, []string{"services", "profiles.minimal", "profiles.events", "schema_version"}, fields)
+
+	// The result reads as the current layout, and migrating it again
+	// changes nothing
+	var cfg struct {
+		Stack struct {
+			Enabled []string `yaml:"enabled"`
+		} `yaml:"stack"`
+		Profiles map[string]struct {
+			Services []string `yaml:"services"`
+		} `yaml:"profiles"`
+	}
+	require.NoError(t, yaml.Unmarshal(migrated, &cfg))
+	assert.Equal(t, []string{"postgres", "redis", "kafka"}, cfg.Stack.Enabled)
+	assert.Equal(t, []string{"postgres", "kafka"}, cfg.Profiles["events"].Services)
+
+	again, report, err := MigrateProjectConfig(migrated)
+	require.NoError(t, err)
+	assert.Empty(t, report.Changes)
+	assert.Equal(t, string(migrated), string(again))
+}
+
+func TestMigrateProjectConfig_ServicesEnabled(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		expected string
+	}{
+		{
+			name:     "merged into stack.enabled",
+			content:  "stack:\n  enabled:\n    - postgres\nservices:\n  enabled: [postgres, redis]\n",
+			expected: "schema_version: 2\n\nstack:\n  enabled:\n    - postgres\n    - redis\n",
+		},
+		{
+			name:     "flow stack.enabled",
+			content:  "stack:\n  enabled: [postgres]\nservices:\n  enabled:\n    - redis\n",
+			expected: "schema_version: 2\n\nstack:\n  enabled: [postgres, redis]\n",
+		},
+		{
+			name:     "other service settings kept",
+			content:  "services:\n  enabled:\n    - postgres\n  postgres:\n    version: \"16\"\nadvanced:\n  env_files: true\n",
+			expected: "schema_version: 2\n\nservices:\n  postgres:\n    version: \"16\"\nstack:\n  enabled:\n    - postgres\nadvanced:\n  env_files: true\n",
+		},
+		{
+			name:     "already enabled",
+			content:  "stack:\n  enabled: [postgres]\nservices: [postgres]\n",
+			expected: "schema_version: 2\n\nstack:\n  enabled: [postgres]\n",
+		},
+		{
+			name:     "current layout",
+			content:  "project:\n  name: shop\nstack:\n  enabled: [postgres]\n",
+			expected: "schema_version: 2\n\nproject:\n  name: shop\nstack:\n  enabled: [postgres]\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			migrated, _, err := MigrateProjectConfig([]byte(tt.content))
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, string(migrated))
+		})
+	}
+}
+
+func TestMigrateProjectConfig_Versions(t *testing.T) {
+	version, err := ProjectSchemaVersion([]byte("project:\n  name: shop\n"))
+	require.NoError(t, err)
+	assert.Equal(t, 1, version)
+
+	version, err = ProjectSchemaVersion([]byte("schema_version: 2\n"))
+	require.NoError(t, err)
+	assert.Equal(t, 2, version)
+
+	migrated, _, err := MigrateProjectConfig([]byte("schema_version: 1\nservices: [redis]\n"))
+	require.NoError(t, err)
+	assert.Equal(t, "schema_version: 2\nstack:\n  enabled:\n    - redis\n", string(migrated))
+
+	_, _, err = MigrateProjectConfig([]byte("schema_version: 99\n"))
+	assert.ErrorContains(t, err, "upgrade dev-stack")
+
+	_, _, err = MigrateProjectConfig([]byte("schema_version: two\n"))
+	assert.Error(t, err)
+
+	_, _, err = MigrateProjectConfig([]byte("- postgres\n"))
+	assert.Error(t, err)
+}
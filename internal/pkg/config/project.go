@@ -0,0 +1,132 @@
+package config
+
+import (
+	"slices"
+	"strconv"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/isaacgarza/dev-stack/internal/pkg/constants"
+)
+
+// FixProjectConfig applies safe fixes to the content of a project
+// configuration and returns the fixed content with the fixes made:
+//   - a missing project.name is set to name, and a missing
+//     project.environment to the default environment
+//   - stack.enabled and profiles naming services that are not in services
+//     lose the reference
+//
+// Like FixCommands it edits the file line by line, keeping its comments and
+// layout. A nil services skips the service checks.
+func FixProjectConfig(data []byte, name string, services []string) ([]byte, []Fix, error) {
+	root, err := parseMapping(data)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	f := newFixer(data)
+	f.fixProject(root, name)
+
+	if services != nil {
+		_, stack := mappingEntry(root, constants.StackSection)
+		_, enabled := mappingEntry(stack, "enabled")
+		f.removeReferences(constants.StackSection+".enabled", enabled, "unknown service", services)
+
+		_, profiles := mappingEntry(root, constants.ProfilesSection)
+		forEachEntry(profiles, func(profileName string, profile *yaml.Node) {
+			_, list := mappingEntry(profile, "services")
+			f.removeReferences(constants.ProfilesSection+"."+profileName+".services", list, "unknown service", services)
+		})
+	}
+
+	if len(f.fixes) == 0 {
+		return data, nil, nil
+	}
+	return f.content(), f.fixes, nil
+}
+
+// fixProject adds a missing project section, name or environment
+func (f *fixer) fixProject(root *yaml.Node, name string) {
+	if len(root.Content) == 0 {
+		return
+	}
+	key, project := mappingEntry(root, constants.ProjectSection)
+	nameField := constants.ProjectSection + ".name"
+	environmentField := constants.ProjectSection + ".environment"
+
+	if project == nil {
+		// The section goes first, after the schema version when there is one
+		at := root.Content[0].Line - 1
+		section := []string{constants.ProjectSection + ":", "  name: " + strconv.Quote(name), "  environment: " + constants.DefaultEnvironment, ""}
+		if root.Content[0].Value == constants.SchemaVersionKey {
+			at = lastLine(root.Content[1])
+			section = append([]string{""}, section[:len(section)-1]...)
+		}
+		f.after[at] = append(f.after[at], section...)
+		f.fix(nameField, "added missing name %q", name)
+		f.fix(environmentField, "added missing environment %s", constants.DefaultEnvironment)
+		return
+	}
+	if !isNull(project) && project.Kind != yaml.MappingNode {
+		return
+	}
+
+	_, projectName := mappingEntry(project, "name")
+	if projectName == nil || projectName.Value == "" {
+		if f.setField(key, project, projectName, "name", name) {
+			f.fix(nameField, "added missing name %q", name)
+		}
+	}
+	_, environment := mappingEntry(project, "environment")
+	if environment == nil || environment.Value == "" {
+		if f.setField(key, project, environment, "environment", constants.DefaultEnvironment) {
+			f.fix(environmentField, "added missing environment %s", constants.DefaultEnvironment)
+		}
+	}
+}
+
+// ValidateProjectConfig checks the content of a project configuration for
+// the problems FixProjectConfig fixes. A nil services skips the service
+// checks.
+func ValidateProjectConfig(data []byte, services []string) (*ValidationResult, error) {
+	root, err := parseMapping(data)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ValidationResult{Valid: true}
+	_, project := mappingEntry(root, constants.ProjectSection)
+	if _, name := mappingEntry(project, "name"); name == nil || name.Value == "" {
+		result.addError(constants.ProjectSection+".name", "Project name is required", "MISSING_PROJECT_NAME")
+	}
+	if _, environment := mappingEntry(project, "environment"); environment == nil || environment.Value == "" {
+		result.addError(constants.ProjectSection+".environment", "Environment is required", "MISSING_ENVIRONMENT")
+	}
+
+	if services != nil {
+		_, stack := mappingEntry(root, constants.StackSection)
+		_, enabled := mappingEntry(stack, "enabled")
+		checkServices(result, constants.StackSection+".enabled", enabled, services)
+
+		_, profiles := mappingEntry(root, constants.ProfilesSection)
+		forEachEntry(profiles, func(profileName string, profile *yaml.Node) {
+			_, list := mappingEntry(profile, "services")
+			checkServices(result, constants.ProfilesSection+"."+profileName+".services", list, services)
+		})
+	}
+
+	result.Valid = len(result.Errors) == 0
+	return result, nil
+}
+
+// checkServices adds an error for each service of list not in services
+func checkServices(result *ValidationResult, field string, list *yaml.Node, services []string) {
+	if list == nil || list.Kind != yaml.SequenceNode {
+		return
+	}
+	for _, item := range list.Content {
+		if item.Kind == yaml.ScalarNode && !slices.Contains(services, item.Value) {
+			result.addError(field, "Service '"+item.Value+"' is not defined", "UNDEFINED_SERVICE")
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const brokenProjectConfig = `schema_version: 2
+
+# The project
+project:
+  environment: local
+
+stack:
+  enabled:
+    - postgres
+    - mongo-db
+    - redis
+
+profiles:
+  api:
+    services: [postgres, kafka-old]
+`
+
+func TestFixProjectConfig(t *testing.T) {
+	fixed, fixes, err := FixProjectConfig([]byte(brokenProjectConfig), "shop", []string{"postgres", "redis"})
+	require.NoError(t, err)
+	assert.Equal(t, []Fix{
+		{Field: "project.name", Message: `added missing name "shop"`},
+		{Field: "stack.enabled", Message: "removed unknown service mongo-db"},
+		{Field: "profiles.api.services", Message: "removed unknown service kafka-old"},
+	}, fixes)
+	assert.Equal(t, `schema_version: 2
+
+# The project
+project:
+  name: "shop"
+  environment: local
+
+stack:
+  enabled:
+    - postgres
+    - redis
+
+profiles:
+  api:
+    services: [postgres]
+`, string(fixed))
+
+	result, err := ValidateProjectConfig(fixed, []string{"postgres", "redis"})
+	require.NoError(t, err)
+	assert.True(t, result.Valid)
+
+	// A fixed configuration has nothing left to fix
+	again, fixes, err := FixProjectConfig(fixed, "shop", []string{"postgres", "redis"})
+	require.NoError(t, err)
+	assert.Empty(t, fixes)
+	assert.Equal(t, string(fixed), string(again))
+}
+
+func TestFixProjectConfig_MissingProject(t *testing.T) {
+	fixed, fixes, err := FixProjectConfig([]byte("schema_version: 2\nstack:\n  enabled: [redis]\n"), "shop", nil)
+	require.NoError(t, err)
+	assert.Len(t, fixes, 2)
+	assert.Equal(t, "schema_version: 2\n\nproject:\n  name: \"shop\"\n  environment: local\nstack:\n  enabled: [redis]\n", string(fixed))
+
+	fixed, _, err = FixProjectConfig([]byte("project:\nstack:\n  enabled: [redis]\n"), "shop", nil)
+	require.NoError(t, err)
+	assert.Equal(t, "project:\n  name: \"shop\"\n  environment: \"local\"\nstack:\n  enabled: [redis]\n", string(fixed))
+}
+
+func TestValidateProjectConfig(t *testing.T) {
+	result, err := ValidateProjectConfig([]byte(brokenProjectConfig), []string{"postgres", "redis"})
+	require.NoError(t, err)
+	assert.False(t, result.Valid)
+	fields := make([]string, 0, len(result.Errors))
+	for _, finding := range result.Errors {
+		fields = append(fields, finding.Field)
+	}
+	assert.Equal(t, []string{"project.name", "stack.enabled", "profiles.api.services"}, fields)
+
+	// Without a catalog only the required fields are checked
+	result, err = ValidateProjectConfig([]byte(brokenProjectConfig), nil)
+	require.NoError(t, err)
+	assert.Len(t, result.Errors, 1)
+
+	_, err = ValidateProjectConfig([]byte("- not a mapping\n"), nil)
+	assert.Error(t, err)
+}
@@ -0,0 +1,537 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/isaacgarza/dev-stack/internal/pkg/utils"
+)
+
+// ConfigEntry is a setting of a configuration file, as listed by
+// ListConfigValues
+type ConfigEntry struct {
+	Path  string `json:"path"`
+	Value string `json:"value"`
+}
+
+// ParseConfigPath splits a dotted configuration path such as
+// services.postgres.version into its keys. A key holding dots is written
+// in double quotes, as in images.registry_mirrors."docker.io". Numbers
+// index lists.
+func ParseConfigPath(path string) ([]string, error) {
+	var keys []string
+	for rest := path; ; {
+		var key string
+		if strings.HasPrefix(rest, `"`) {
+			end := strings.Index(rest[1:], `"`)
+			if end < 0 {
+				return nil, fmt.Errorf("invalid path %q: unterminated quote", path)
+			}
+			key, rest = rest[1:end+1], rest[end+2:]
+			if rest != "" && !strings.HasPrefix(rest, ".") {
+				return nil, fmt.Errorf("invalid path %q: expected a dot after %q", path, key)
+			}
+		} else {
+			end := strings.Index(rest, ".")
+			if end < 0 {
+				end = len(rest)
+			}
+			key, rest = rest[:end], rest[end:]
+		}
+		if key == "" {
+			return nil, fmt.Errorf("invalid path %q: empty key", path)
+		}
+		keys = append(keys, key)
+		if rest == "" {
+			return keys, nil
+		}
+		rest = rest[1:]
+	}
+}
+
+// FormatConfigPath joins keys into a dotted path, quoting keys that hold
+// dots
+func FormatConfigPath(keys []string) string {
+	parts := make([]string, len(keys))
+	for i, key := range keys {
+		parts[i] = key
+		if strings.Contains(key, ".") {
+			parts[i] = `"` + key + `"`
+		}
+	}
+	return strings.Join(parts, ".")
+}
+
+// ConfigValue returns the node at path in configuration content, or nil
+// when the path is not set
+func ConfigValue(data []byte, path []string) (*yaml.Node, error) {
+	root, err := parseDocument(data)
+	if err != nil {
+		return nil, err
+	}
+	node := root
+	for i, key := range path {
+		switch {
+		case node == nil || isNull(node):
+			return nil, nil
+		case node.Kind == yaml.MappingNode:
+			_, node = mappingEntry(node, key)
+		case node.Kind == yaml.SequenceNode:
+			index, err := listIndex(path[:i], key, node)
+			if err != nil {
+				return nil, err
+			}
+			if index >= len(node.Content) {
+				return nil, nil
+			}
+			node = node.Content[index]
+		default:
+			return nil, fmt.Errorf("%s is a value, not a mapping", FormatConfigPath(path[:i]))
+		}
+	}
+	return node, nil
+}
+
+// ListConfigValues returns every setting of configuration content, with
+// lists of plain values on one line
+func ListConfigValues(data []byte) ([]ConfigEntry, error) {
+	root, err := parseDocument(data)
+	if err != nil {
+		return nil, err
+	}
+	var entries []ConfigEntry
+	var walk func(path []string, node *yaml.Node)
+	walk = func(path []string, node *yaml.Node) {
+		switch {
+		case node.Kind == yaml.MappingNode && len(node.Content) > 0:
+			forEachEntry(node, func(key string, value *yaml.Node) {
+				walk(append(path[:len(path):len(path)], key), value)
+			})
+		case node.Kind == yaml.SequenceNode && !scalarList(node):
+			for i, item := range node.Content {
+				walk(append(path[:len(path):len(path)], strconv.Itoa(i)), item)
+			}
+		default:
+			entries = append(entries, ConfigEntry{Path: FormatConfigPath(path), Value: inlineValue(node)})
+		}
+	}
+	if root != nil {
+		walk(nil, root)
+	}
+	return entries, nil
+}
+
+// SetConfigValue sets path in configuration content to value, adding the
+// keys it lacks, and returns the new content. Like SetEnabledServices it
+// edits the file line by line: only the lines of the setting change, and
+// comments elsewhere are kept.
+func SetConfigValue(data []byte, path []string, value *yaml.Node) ([]byte, error) {
+	root, err := parseDocument(data)
+	if err != nil {
+		return nil, err
+	}
+	if root == nil {
+		lines, err := renderEntry(path, value, "")
+		if err != nil {
+			return nil, err
+		}
+		var existing []string
+		if content := strings.TrimRight(string(data), "\r\n"); content != "" {
+			existing = utils.SplitLines(content)
+		}
+		return []byte(utils.JoinLines(appendSection(existing, lines...), utils.LineEnding(string(data)))), nil
+	}
+
+	f := newFixer(data)
+	var key *yaml.Node
+	node := root
+	for i, segment := range path {
+		field := FormatConfigPath(path[:i])
+		switch {
+		case isNull(node) && key == nil:
+			return nil, fmt.Errorf("cannot set %s: %s is empty", FormatConfigPath(path), field)
+		case isNull(node):
+			// A bare "key:" takes the rest of the path as its entries
+			lines, err := renderEntry(path[i:], value, childIndent(key, nil))
+			if err != nil {
+				return nil, err
+			}
+			f.clearNull(key, node)
+			f.after[key.Line] = append(f.after[key.Line], lines...)
+			return f.content(), nil
+
+		case node.Kind == yaml.MappingNode:
+			entryKey, entryValue := mappingEntry(node, segment)
+			if entryKey != nil {
+				key, node = entryKey, entryValue
+				continue
+			}
+			if node.Style&yaml.FlowStyle != 0 || len(node.Content) == 0 {
+				return nil, fmt.Errorf("cannot update %s: write it as a block mapping", field)
+			}
+			lines, err := renderEntry(path[i:], value, indentOf(node.Content[0]))
+			if err != nil {
+				return nil, err
+			}
+			if node == root {
+				// New top-level sections go at the end, after a blank line
+				at := len(f.lines)
+				if f.lines[at-1] != "" {
+					lines = append([]string{""}, lines...)
+				}
+				f.after[at] = append(f.after[at], lines...)
+			} else {
+				at := f.entryEnd(node.Content[len(node.Content)-2], node.Content[len(node.Content)-1])
+				f.after[at] = append(f.after[at], lines...)
+			}
+			return f.content(), nil
+
+		case node.Kind == yaml.SequenceNode:
+			index, err := listIndex(path[:i], segment, node)
+			if err != nil {
+				return nil, err
+			}
+			if index < len(node.Content) {
+				key, node = nil, node.Content[index]
+				continue
+			}
+			if index > len(node.Content) {
+				return nil, fmt.Errorf("cannot set %s: %s has %d items", FormatConfigPath(path[:i+1]), field, len(node.Content))
+			}
+			if node.Style&yaml.FlowStyle != 0 || len(node.Content) == 0 || len(path) > i+1 {
+				return nil, fmt.Errorf("cannot append to %s: write it as a block list", field)
+			}
+			last := node.Content[len(node.Content)-1]
+			lines, err := renderItem(value, f.dashIndent(last))
+			if err != nil {
+				return nil, err
+			}
+			at := f.entryEnd(nil, last)
+			f.after[at] = append(f.after[at], lines...)
+			return f.content(), nil
+
+		default:
+			return nil, fmt.Errorf("cannot set %s: %s is a value, not a mapping", FormatConfigPath(path), field)
+		}
+	}
+
+	if err := f.replaceValue(key, node, value); err != nil {
+		return nil, fmt.Errorf("cannot set %s: %w", FormatConfigPath(path), err)
+	}
+	return f.content(), nil
+}
+
+// UnsetConfigValue removes path from configuration content and returns
+// the new content. Mappings and lists left empty are removed with it.
+func UnsetConfigValue(data []byte, path []string) ([]byte, error) {
+	root, err := parseDocument(data)
+	if err != nil {
+		return nil, err
+	}
+
+	// Each step is an entry on the way to the setting: the key and value
+	// of a mapping entry, or a list item without a key
+	type step struct {
+		parent, key, value *yaml.Node
+	}
+	var steps []step
+	node := root
+	for i, segment := range path {
+		switch {
+		case node == nil || isNull(node):
+			return nil, fmt.Errorf("%s is not set", FormatConfigPath(path))
+		case node.Kind == yaml.MappingNode:
+			key, value := mappingEntry(node, segment)
+			if key == nil {
+				return nil, fmt.Errorf("%s is not set", FormatConfigPath(path))
+			}
+			steps = append(steps, step{parent: node, key: key, value: value})
+			node = value
+		case node.Kind == yaml.SequenceNode:
+			index, err := listIndex(path[:i], segment, node)
+			if err != nil {
+				return nil, err
+			}
+			if index >= len(node.Content) {
+				return nil, fmt.Errorf("%s is not set", FormatConfigPath(path))
+			}
+			steps = append(steps, step{parent: node, value: node.Content[index]})
+			node = node.Content[index]
+		default:
+			return nil, fmt.Errorf("%s is not set", FormatConfigPath(path))
+		}
+	}
+
+	// Removing the only entry of a mapping or list removes it as well
+	target := len(steps) - 1
+	for target > 0 && len(steps[target].parent.Content) == entrySize(steps[target].parent) {
+		target--
+	}
+	removed := steps[target]
+	if removed.parent.Style&yaml.FlowStyle != 0 {
+		return nil, fmt.Errorf("cannot unset %s: write %s as a block mapping or list", FormatConfigPath(path), FormatConfigPath(path[:target]))
+	}
+
+	f := newFixer(data)
+	start := removed.value.Line
+	ownLine := strings.HasPrefix(strings.TrimSpace(f.lines[start-1]), "-")
+	if removed.key != nil {
+		start = removed.key.Line
+		ownLine = f.removable(removed.parent, removed.key)
+	}
+	if !ownLine {
+		return nil, fmt.Errorf("cannot unset %s: it does not start its own line", FormatConfigPath(path))
+	}
+	// Comments right above the entry go with it
+	if first := removed.key; (first != nil && first.HeadComment != "") || (first == nil && removed.value.HeadComment != "") {
+		for start > 1 && strings.HasPrefix(strings.TrimSpace(f.lines[start-2]), "#") {
+			start--
+		}
+	}
+	end := f.entryEnd(removed.key, removed.value)
+	for line := start; line <= end; line++ {
+		f.drop[line] = true
+	}
+	// A section between blank lines leaves one of them
+	if start > 1 && f.lines[start-2] == "" {
+		if end == len(f.lines) {
+			f.drop[start-1] = true
+		} else if f.lines[end] == "" {
+			f.drop[end+1] = true
+		}
+	}
+	return f.content(), nil
+}
+
+// parseDocument parses configuration content whose top level is a
+// mapping, returning nil for content without any
+func parseDocument(data []byte) (*yaml.Node, error) {
+	var document yaml.Node
+	if err := yaml.Unmarshal(data, &document); err != nil {
+		return nil, fmt.Errorf("failed to parse config: %w", err)
+	}
+	if len(document.Content) == 0 || isNull(document.Content[0]) {
+		return nil, nil
+	}
+	if document.Content[0].Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("failed to parse config: top level is not a mapping")
+	}
+	return document.Content[0], nil
+}
+
+// listIndex parses key as an index into list, which is at path
+func listIndex(path []string, key string, list *yaml.Node) (int, error) {
+	index, err := strconv.Atoi(key)
+	if err != nil || index < 0 {
+		return 0, fmt.Errorf("%s is a list of %d items; index it with a number, not %q", FormatConfigPath(path), len(list.Content), key)
+	}
+	return index, nil
+}
+
+// entrySize returns the number of nodes an entry of node takes: a key and
+// a value in a mapping, an item in a list
+func entrySize(node *yaml.Node) int {
+	if node.Kind == yaml.MappingNode {
+		return 2
+	}
+	return 1
+}
+
+// replaceValue replaces value, the value of key or a list item when key is
+// nil, with replacement. Plain values on a single line are replaced in
+// place; anything else rewrites the lines of the entry.
+func (f *fixer) replaceValue(key, value, replacement *yaml.Node) error {
+	if value.Kind == yaml.ScalarNode && replacement.Kind == yaml.ScalarNode && !isNull(value) {
+		line := []rune(f.lines[value.Line-1])
+		end := scalarEnd(line, value.Column-1, value)
+		if text, ok := scalarText(value, replacement); ok && end > value.Column-1 {
+			f.spans[value.Line] = append(f.spans[value.Line], span{start: value.Column - 1, end: end, text: text})
+			return nil
+		}
+	}
+
+	var lines []string
+	var err error
+	start := value.Line
+	if key != nil {
+		if !f.removable(nil, key) {
+			return fmt.Errorf("it does not start its own line")
+		}
+		start = key.Line
+		lines, err = renderValue(f.keyText(key), replacement, indentOf(key))
+	} else {
+		if !strings.HasPrefix(strings.TrimSpace(f.lines[value.Line-1]), "-") {
+			return fmt.Errorf("it does not start its own line")
+		}
+		lines, err = renderItem(replacement, f.dashIndent(value))
+	}
+	if err != nil {
+		return err
+	}
+	for line := start; line <= f.entryEnd(key, value); line++ {
+		f.drop[line] = true
+	}
+	f.after[start] = append(f.after[start], lines...)
+	return nil
+}
+
+// scalarText renders replacement to take the place of the scalar value on
+// its line, keeping the quotes of a string
+func scalarText(value, replacement *yaml.Node) (string, bool) {
+	if value.Style&(yaml.DoubleQuotedStyle|yaml.SingleQuotedStyle) != 0 && replacement.Tag == "!!str" {
+		return quoteLike(value, replacement.Value), true
+	}
+	lines, err := encodeNode(replacement)
+	if err != nil || len(lines) != 1 {
+		return "", false
+	}
+	return lines[0], true
+}
+
+// clearNull removes the text of a null value written out, as in "key: ~",
+// so entries can go under its key
+func (f *fixer) clearNull(key, value *yaml.Node) {
+	if value.Value == "" || value.Line != key.Line {
+		return
+	}
+	line := []rune(f.lines[value.Line-1])
+	start := len([]rune(strings.TrimRight(string(line[:value.Column-1]), " ")))
+	f.spans[value.Line] = append(f.spans[value.Line], span{start: start, end: value.Column - 1 + len([]rune(value.Value))})
+}
+
+// entryEnd returns the last line of the entry whose key is key, or of a
+// list item when key is nil. Besides the lines of its nodes, the entry
+// takes the lines indented deeper than it, such as the body of a block
+// scalar.
+func (f *fixer) entryEnd(key, value *yaml.Node) int {
+	column := len(f.dashIndent(value))
+	if key != nil {
+		column = key.Column - 1
+	}
+	end := lastLine(value)
+	for line := end + 1; line <= len(f.lines); line++ {
+		text := f.lines[line-1]
+		trimmed := strings.TrimLeft(text, " ")
+		switch {
+		case trimmed == "":
+			continue
+		case len(text)-len(trimmed) > column && !strings.HasPrefix(trimmed, "#"):
+			end = line
+			continue
+		}
+		break
+	}
+	return end
+}
+
+// dashIndent returns the indentation of the dash of a block list item
+func (f *fixer) dashIndent(item *yaml.Node) string {
+	line := f.lines[item.Line-1]
+	return line[:len(line)-len(strings.TrimLeft(line, " "))]
+}
+
+// keyText returns a key as it is written, keeping its quotes
+func (f *fixer) keyText(key *yaml.Node) string {
+	line := []rune(f.lines[key.Line-1])
+	if end := scalarEnd(line, key.Column-1, key); end > key.Column-1 {
+		return string(line[key.Column-1 : end])
+	}
+	return key.Value
+}
+
+// renderEntry renders the entry setting path to value at indent, nesting
+// each key of path under the one before it
+func renderEntry(path []string, value *yaml.Node, indent string) ([]string, error) {
+	for i := len(path) - 1; i > 0; i-- {
+		value = &yaml.Node{Kind: yaml.MappingNode, Content: []*yaml.Node{
+			{Kind: yaml.ScalarNode, Value: path[i]},
+			value,
+		}}
+	}
+	key, err := encodeNode(&yaml.Node{Kind: yaml.ScalarNode, Value: path[0]})
+	if err != nil {
+		return nil, err
+	}
+	return renderValue(key[0], value, indent)
+}
+
+// renderValue renders "key: value" at indent, with block mappings and
+// lists on the lines below the key
+func renderValue(key string, value *yaml.Node, indent string) ([]string, error) {
+	lines, err := encodeNode(value)
+	if err != nil {
+		return nil, err
+	}
+	if (value.Kind == yaml.MappingNode || value.Kind == yaml.SequenceNode) && value.Style&yaml.FlowStyle == 0 && len(value.Content) > 0 {
+		result := []string{indent + key + ":"}
+		return append(result, indentLines(lines, indent+"  ")...), nil
+	}
+	result := []string{indent + key + ": " + lines[0]}
+	return append(result, indentLines(lines[1:], indent)...), nil
+}
+
+// renderItem renders a list item holding value, its dash at indent
+func renderItem(value *yaml.Node, indent string) ([]string, error) {
+	lines, err := encodeNode(value)
+	if err != nil {
+		return nil, err
+	}
+	result := []string{indent + "- " + lines[0]}
+	return append(result, indentLines(lines[1:], indent+"  ")...), nil
+}
+
+// encodeNode encodes node as YAML lines, with two-space indentation
+func encodeNode(node *yaml.Node) ([]string, error) {
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(node); err != nil {
+		return nil, fmt.Errorf("failed to encode value: %w", err)
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, fmt.Errorf("failed to encode value: %w", err)
+	}
+	return strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n"), nil
+}
+
+// indentLines prefixes each non-empty line with indent
+func indentLines(lines []string, indent string) []string {
+	result := make([]string, len(lines))
+	for i, line := range lines {
+		if line != "" {
+			result[i] = indent + line
+		}
+	}
+	return result
+}
+
+// scalarList reports whether node is a list of plain values
+func scalarList(node *yaml.Node) bool {
+	for _, item := range node.Content {
+		if item.Kind != yaml.ScalarNode {
+			return false
+		}
+	}
+	return true
+}
+
+// inlineValue renders a value on one line, as ListConfigValues shows it
+func inlineValue(node *yaml.Node) string {
+	switch {
+	case isNull(node):
+		return ""
+	case node.Kind == yaml.ScalarNode:
+		return node.Value
+	}
+	flow := *node
+	flow.Style = yaml.FlowStyle
+	lines, err := encodeNode(&flow)
+	if err != nil {
+		return ""
+	}
+	return strings.Join(lines, " ")
+}
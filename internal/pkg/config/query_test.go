@@ -0,0 +1,252 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+const queryConfig = `# Dev Stack Configuration
+project:
+  name: shop # the project
+  environment: local
+
+services:
+  postgres:
+    version: "15"
+
+overrides:
+  redis:
+    # Published on the host
+    ports:
+      - "6379:6379"
+    memory: 256m
+  kafka:
+    ports: ["9092:9092"]
+
+profiles:
+  dev:
+    services: [postgres, redis]
+`
+
+func scalar(tag, value string) *yaml.Node {
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: tag, Value: value}
+}
+
+func TestParseConfigPath(t *testing.T) {
+	keys, err := ParseConfigPath("services.postgres.version")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"services", "postgres", "version"}, keys)
+
+	keys, err = ParseConfigPath(`images.registry_mirrors."docker.io"`)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"images", "registry_mirrors", "docker.io"}, keys)
+	assert.Equal(t, `images.registry_mirrors."docker.io"`, FormatConfigPath(keys))
+
+	for _, path := range []string{"", "services..version", `images."docker.io`, `"a"b`} {
+		_, err := ParseConfigPath(path)
+		assert.Error(t, err, path)
+	}
+}
+
+func TestConfigValue(t *testing.T) {
+	node, err := ConfigValue([]byte(queryConfig), []string{"profiles", "dev", "services"})
+	require.NoError(t, err)
+	var services []string
+	require.NoError(t, node.Decode(&services))
+	assert.Equal(t, []string{"postgres", "redis"}, services)
+
+	node, err = ConfigValue([]byte(queryConfig), []string{"overrides", "redis", "ports", "0"})
+	require.NoError(t, err)
+	assert.Equal(t, "6379:6379", node.Value)
+
+	node, err = ConfigValue([]byte(queryConfig), []string{"services", "redis", "version"})
+	require.NoError(t, err)
+	assert.Nil(t, node)
+
+	_, err = ConfigValue([]byte(queryConfig), []string{"project", "name", "first"})
+	assert.ErrorContains(t, err, "project.name is a value")
+	_, err = ConfigValue([]byte(queryConfig), []string{"profiles", "dev", "services", "first"})
+	assert.ErrorContains(t, err, "index it with a number")
+}
+
+func TestListConfigValues(t *testing.T) {
+	entries, err := ListConfigValues([]byte(queryConfig))
+	require.NoError(t, err)
+	assert.Equal(t, []ConfigEntry{
+		{Path: "project.name", Value: "shop"},
+		{Path: "project.environment", Value: "local"},
+		{Path: "services.postgres.version", Value: "15"},
+		{Path: "overrides.redis.ports", Value: `["6379:6379"]`},
+		{Path: "overrides.redis.memory", Value: "256m"},
+		{Path: "overrides.kafka.ports", Value: `["9092:9092"]`},
+		{Path: "profiles.dev.services", Value: "[postgres, redis]"},
+	}, entries)
+}
+
+func TestSetConfigValue(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		path     []string
+		value    *yaml.Node
+		expected string
+	}{
+		{
+			name:     "scalar in place keeps quotes and comments",
+			content:  "services:\n  postgres:\n    version: \"15\" # pinned\n",
+			path:     []string{"services", "postgres", "version"},
+			value:    scalar("!!str", "16"),
+			expected: "services:\n  postgres:\n    version: \"16\" # pinned\n",
+		},
+		{
+			name:     "plain scalar",
+			content:  "project:\n  name: shop # the project\n",
+			path:     []string{"project", "name"},
+			value:    scalar("!!str", "store"),
+			expected: "project:\n  name: store # the project\n",
+		},
+		{
+			name:     "string that needs quotes",
+			content:  "project:\n  name: shop\n",
+			path:     []string{"project", "name"},
+			value:    scalar("!!str", "true"),
+			expected: "project:\n  name: \"true\"\n",
+		},
+		{
+			name:     "missing keys added at the end of their mapping",
+			content:  "services:\n  postgres:\n    version: \"15\"\n\n# Profiles\nprofiles: {}\n",
+			path:     []string{"services", "redis", "version"},
+			value:    scalar("!!str", "7"),
+			expected: "services:\n  postgres:\n    version: \"15\"\n  redis:\n    version: \"7\"\n\n# Profiles\nprofiles: {}\n",
+		},
+		{
+			name:     "new top-level section",
+			content:  "project:\n  name: shop\n",
+			path:     []string{"advanced", "env_files"},
+			value:    scalar("!!bool", "true"),
+			expected: "project:\n  name: shop\n\nadvanced:\n  env_files: true\n",
+		},
+		{
+			name:     "under an empty key",
+			content:  "services:\n  postgres: ~ # later\nstack: {}\n",
+			path:     []string{"services", "postgres", "version"},
+			value:    scalar("!!str", "16"),
+			expected: "services:\n  postgres: # later\n    version: \"16\"\nstack: {}\n",
+		},
+		{
+			name:    "list replaced",
+			content: "profiles:\n  dev:\n    services: [postgres]\n    protected: false\n",
+			path:    []string{"profiles", "dev", "services"},
+			value: &yaml.Node{Kind: yaml.SequenceNode, Content: []*yaml.Node{
+				scalar("!!str", "postgres"), scalar("!!str", "redis"),
+			}},
+			expected: "profiles:\n  dev:\n    services:\n      - postgres\n      - redis\n    protected: false\n",
+		},
+		{
+			name:     "block list replaced by a scalar",
+			content:  "overrides:\n  redis:\n    ports:\n      - \"6379:6379\"\n    memory: 256m\n",
+			path:     []string{"overrides", "redis", "ports"},
+			value:    scalar("!!str", "6380:6379"),
+			expected: "overrides:\n  redis:\n    ports: 6380:6379\n    memory: 256m\n",
+		},
+		{
+			name:     "list item",
+			content:  "stack:\n  enabled:\n    - postgres\n    - redis # cache\n",
+			path:     []string{"stack", "enabled", "1"},
+			value:    scalar("!!str", "valkey"),
+			expected: "stack:\n  enabled:\n    - postgres\n    - valkey # cache\n",
+		},
+		{
+			name:     "list item appended",
+			content:  "stack:\n  enabled:\n    - postgres\nproject:\n  name: shop\n",
+			path:     []string{"stack", "enabled", "1"},
+			value:    scalar("!!str", "redis"),
+			expected: "stack:\n  enabled:\n    - postgres\n    - redis\nproject:\n  name: shop\n",
+		},
+		{
+			name:     "CRLF line endings kept",
+			content:  "project:\r\n  name: shop # the project\r\n",
+			path:     []string{"project", "environment"},
+			value:    scalar("!!str", "local"),
+			expected: "project:\r\n  name: shop # the project\r\n  environment: local\r\n",
+		},
+		{
+			name:     "block scalar replaced",
+			content:  "project:\n  notes: |\n    first\n    second\n  name: shop\n",
+			path:     []string{"project", "notes"},
+			value:    scalar("!!str", "short"),
+			expected: "project:\n  notes: short\n  name: shop\n",
+		},
+		{
+			name:     "empty file",
+			content:  "# Dev Stack Configuration\n",
+			path:     []string{"project", "name"},
+			value:    scalar("!!str", "shop"),
+			expected: "# Dev Stack Configuration\n\nproject:\n  name: shop\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			content, err := SetConfigValue([]byte(tt.content), tt.path, tt.value)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, string(content))
+		})
+	}
+}
+
+func TestSetConfigValue_Errors(t *testing.T) {
+	content := []byte("project:\n  name: shop\nprofiles: {dev: {services: [postgres]}}\nstack:\n  enabled: [postgres]\n")
+	value := scalar("!!str", "x")
+
+	_, err := SetConfigValue(content, []string{"project", "name", "first"}, value)
+	assert.ErrorContains(t, err, "project.name is a value")
+	_, err = SetConfigValue(content, []string{"profiles", "ci"}, value)
+	assert.ErrorContains(t, err, "write it as a block mapping")
+	_, err = SetConfigValue(content, []string{"stack", "enabled", "1"}, value)
+	assert.ErrorContains(t, err, "write it as a block list")
+	_, err = SetConfigValue(content, []string{"stack", "enabled", "5"}, value)
+	assert.ErrorContains(t, err, "has 1 items")
+}
+
+func TestUnsetConfigValue(t *testing.T) {
+	content, err := UnsetConfigValue([]byte(queryConfig), []string{"overrides", "redis", "ports"})
+	require.NoError(t, err)
+	assert.Equal(t, `# Dev Stack Configuration
+project:
+  name: shop # the project
+  environment: local
+
+services:
+  postgres:
+    version: "15"
+
+overrides:
+  redis:
+    memory: 256m
+  kafka:
+    ports: ["9092:9092"]
+
+profiles:
+  dev:
+    services: [postgres, redis]
+`, string(content))
+
+	// Mappings left empty go as well
+	content, err = UnsetConfigValue([]byte(queryConfig), []string{"services", "postgres", "version"})
+	require.NoError(t, err)
+	assert.NotContains(t, string(content), "postgres:")
+	assert.Contains(t, string(content), "environment: local\n\noverrides:")
+
+	content, err = UnsetConfigValue([]byte("stack:\n  enabled:\n    - postgres\n    - redis\n"), []string{"stack", "enabled", "0"})
+	require.NoError(t, err)
+	assert.Equal(t, "stack:\n  enabled:\n    - redis\n", string(content))
+
+	_, err = UnsetConfigValue([]byte(queryConfig), []string{"overrides", "postgres"})
+	assert.ErrorContains(t, err, "overrides.postgres is not set")
+	_, err = UnsetConfigValue([]byte(queryConfig), []string{"profiles", "dev", "services", "0"})
+	assert.ErrorContains(t, err, "block mapping or list")
+}
@@ -0,0 +1,67 @@
+package config
+
+import (
+	"fmt"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/isaacgarza/dev-stack/internal/pkg/constants"
+)
+
+// SetEnabledServices rewrites stack.enabled in the project configuration at
+// path. Only the lines of the enabled list change, so the rest of the file
+// keeps its comments and layout.
+func SetEnabledServices(path string, services []string) error {
+	lines, root, err := readConfigLines(path)
+	if err != nil {
+		return err
+	}
+
+	stackKey, stack := mappingEntry(root, constants.StackSection)
+	switch {
+	case stackKey == nil:
+		// No stack section yet: append one
+		lines = appendSection(lines, append([]string{constants.StackSection + ":"}, renderEnabled("  ", services)...)...)
+
+	case isNull(stack):
+		// An empty "stack:" section
+		lines = splice(lines, stackKey.Line, stackKey.Line, append(
+			[]string{indentOf(stackKey) + constants.StackSection + ":"},
+			renderEnabled(childIndent(stackKey, nil), services)...,
+		))
+
+	case stack.Kind != yaml.MappingNode:
+		return fmt.Errorf("failed to parse config: %s is not a mapping", constants.StackSection)
+
+	case stack.Style&yaml.FlowStyle != 0:
+		return fmt.Errorf("cannot update %s: write it as a block mapping", constants.StackSection)
+
+	default:
+		enabledKey, enabled := mappingEntry(stack, "enabled")
+		if enabledKey == nil {
+			lines = splice(lines, stackKey.Line+1, stackKey.Line, renderEnabled(childIndent(stackKey, stack), services))
+			break
+		}
+		last := enabledKey.Line
+		for _, item := range enabled.Content {
+			if item.Line > last {
+				last = item.Line
+			}
+		}
+		lines = splice(lines, enabledKey.Line, last, renderEnabled(indentOf(enabledKey), services))
+	}
+
+	return writeConfigLines(path, lines)
+}
+
+// renderEnabled renders an enabled list whose key is indented by indent
+func renderEnabled(indent string, services []string) []string {
+	if len(services) == 0 {
+		return []string{indent + "enabled: []"}
+	}
+	rendered := []string{indent + "enabled:"}
+	for _, service := range services {
+		rendered = append(rendered, fmt.Sprintf("%s  - %s", indent, service))
+	}
+	return rendered
+}
@@ -0,0 +1,101 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetEnabledServices(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		services []string
+		expected string
+	}{
+		{
+			name: "replaces enabled services and keeps comments",
+			content: `# Dev Stack Configuration
+project:
+  name: demo # the project name
+
+stack:
+  enabled:
+    - postgres
+    - redis
+
+overrides: {}
+`,
+			services: []string{"postgres", "redis", "rabbitmq"},
+			expected: `# Dev Stack Configuration
+project:
+  name: demo # the project name
+
+stack:
+  enabled:
+    - postgres
+    - redis
+    - rabbitmq
+
+overrides: {}
+`,
+		},
+		{
+			name:     "adds a missing stack section",
+			content:  "project:\n  name: demo\n",
+			services: []string{"redis"},
+			expected: "project:\n  name: demo\n\nstack:\n  enabled:\n    - redis\n",
+		},
+		{
+			name:     "fills an empty stack section",
+			content:  "stack:\n",
+			services: []string{"redis"},
+			expected: "stack:\n  enabled:\n    - redis\n",
+		},
+		{
+			name:     "adds enabled to a stack section without it",
+			content:  "stack:\n  # nothing yet\n  profile: dev\nvalidation: {}\n",
+			services: []string{"redis"},
+			expected: "stack:\n  enabled:\n    - redis\n  # nothing yet\n  profile: dev\nvalidation: {}\n",
+		},
+		{
+			name:     "replaces a flow sequence",
+			content:  "stack:\n  enabled: [postgres, redis]\n",
+			services: []string{"postgres"},
+			expected: "stack:\n  enabled:\n    - postgres\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "dev-stack-config.yaml")
+			require.NoError(t, os.WriteFile(path, []byte(tt.content), 0644))
+
+			require.NoError(t, SetEnabledServices(path, tt.services))
+
+			data, err := os.ReadFile(path)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, string(data))
+		})
+	}
+}
+
+func TestSetEnabledServices_Errors(t *testing.T) {
+	dir := t.TempDir()
+
+	err := SetEnabledServices(filepath.Join(dir, "missing.yaml"), []string{"redis"})
+	assert.Error(t, err)
+
+	path := filepath.Join(dir, "list.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("- redis\n"), 0644))
+	err = SetEnabledServices(path, []string{"redis"})
+	assert.ErrorContains(t, err, "not a mapping")
+
+	path = filepath.Join(dir, "flow.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("stack: {enabled: [redis]}\n"), 0644))
+	err = SetEnabledServices(path, []string{"postgres"})
+	assert.ErrorContains(t, err, "block mapping")
+}
@@ -38,17 +38,24 @@ type Category struct {
 
 // Command represents a complete command definition
 type Command struct {
-	Category        string           `yaml:"category"`
-	Description     string           `yaml:"description"`
-	LongDescription string           `yaml:"long_description"`
-	Usage           string           `yaml:"usage"`
-	Aliases         []string         `yaml:"aliases"`
-	Examples        []Example        `yaml:"examples"`
-	Flags           map[string]Flag  `yaml:"flags"`
-	RelatedCommands []string         `yaml:"related_commands"`
-	Tips            []string         `yaml:"tips"`
-	Hidden          bool             `yaml:"hidden,omitempty"`
-	Deprecated      *DeprecationInfo `yaml:"deprecated,omitempty"`
+	Category        string             `yaml:"category"`
+	Description     string             `yaml:"description"`
+	LongDescription string             `yaml:"long_description"`
+	Usage           string             `yaml:"usage"`
+	Aliases         []string           `yaml:"aliases"`
+	Examples        []Example          `yaml:"examples"`
+	Flags           map[string]Flag    `yaml:"flags"`
+	RelatedCommands []string           `yaml:"related_commands"`
+	Tips            []string           `yaml:"tips"`
+	Hidden          bool               `yaml:"hidden,omitempty"`
+	Deprecated      *DeprecationInfo   `yaml:"deprecated,omitempty"`
+	Subcommands     map[string]Command `yaml:"subcommands,omitempty"`
+	// PassthroughArgs stops flag parsing at the first argument, so flags
+	// after it reach the command being wrapped
+	PassthroughArgs bool `yaml:"passthrough_args,omitempty"`
+	// Completion names what each positional argument completes to, such as
+	// running services; the last entry also applies to the arguments after it
+	Completion []string `yaml:"completion,omitempty"`
 }
 
 // Flag represents a command line flag definition
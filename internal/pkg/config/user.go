@@ -0,0 +1,350 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/isaacgarza/dev-stack/internal/pkg/constants"
+)
+
+// Sources of an effective user setting, besides the name of the
+// environment variable that set it
+const (
+	SourceDefault = "default"
+	SourceGlobal  = "global"
+)
+
+// userKeys are the single-valued user settings, in the order they are
+// listed. Registry mirrors are keyed by host as registry_mirrors.<host>.
+var userKeys = []string{
+	constants.UserKeyDefaultProfile,
+	constants.UserKeyTelemetry,
+	constants.UserKeyColor,
+	constants.UserKeyBackupDir,
+	constants.UserKeyUsageMetrics,
+	constants.UserKeyUsageEndpoint,
+}
+
+// colorModes are the values of the color setting
+var colorModes = []string{constants.ColorAuto, constants.ColorAlways, constants.ColorNever}
+
+// UserConfig holds the settings of the user configuration file,
+// ~/.config/dev-stack/config.yaml, which apply to every project. Settings
+// resolve in this order: command-line flags, environment variables, the
+// project configuration, the user configuration, then built-in defaults.
+type UserConfig struct {
+	// RegistryMirrors rewrites image references from a registry to a
+	// mirror, keyed by registry host. A project mirror for the same host
+	// wins.
+	RegistryMirrors map[string]string `yaml:"registry_mirrors,omitempty" json:"registry_mirrors,omitempty"`
+	// DefaultProfile is the profile used when neither --profile nor
+	// DEV_STACK_PROFILE selects one
+	DefaultProfile string `yaml:"default_profile,omitempty" json:"default_profile,omitempty"`
+	// Telemetry set to false keeps the command completion records out of
+	// the JSON logs
+	Telemetry *bool `yaml:"telemetry,omitempty" json:"telemetry,omitempty"`
+	// Color is auto, always or never
+	Color string `yaml:"color,omitempty" json:"color,omitempty"`
+	// BackupDir is the backup directory of projects that don't set
+	// backup.directory
+	BackupDir string `yaml:"backup_dir,omitempty" json:"backup_dir,omitempty"`
+	// UsageMetrics set to true records anonymous usage metrics and sends
+	// them to UsageEndpoint. Unset, nothing is collected.
+	UsageMetrics  *bool  `yaml:"usage_metrics,omitempty" json:"usage_metrics,omitempty"`
+	UsageEndpoint string `yaml:"usage_metrics_endpoint,omitempty" json:"usage_metrics_endpoint,omitempty"`
+}
+
+// UserSetting is the effective value of a user setting. Source is
+// SourceDefault, SourceGlobal, or the environment variable it was read
+// from.
+type UserSetting struct {
+	Key    string `json:"key"`
+	Value  string `json:"value"`
+	Source string `json:"source"`
+}
+
+// userConfigPath is the user configuration file given with --config
+var userConfigPath string
+
+// SetUserConfigPath makes path the user configuration file, as given with
+// the --config flag
+func SetUserConfigPath(path string) {
+	userConfigPath = path
+}
+
+// UserConfigPath returns the path of the user configuration file: the one
+// given with --config, DEV_STACK_CONFIG when set, otherwise
+// dev-stack/config.yaml under XDG_CONFIG_HOME or ~/.config
+func UserConfigPath() (string, error) {
+	if userConfigPath != "" {
+		return userConfigPath, nil
+	}
+	if path := os.Getenv(constants.EnvUserConfig); path != "" {
+		return path, nil
+	}
+	dir := os.Getenv(constants.EnvConfigHome)
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("failed to locate the user configuration: %w", err)
+		}
+		dir = filepath.Join(home, ".config")
+	}
+	return filepath.Join(dir, constants.AppName, constants.UserConfigFileName), nil
+}
+
+// UsageMetricsPath returns the file buffering the usage metrics, next to
+// the user configuration
+func UsageMetricsPath() (string, error) {
+	path, err := UserConfigPath()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(filepath.Dir(path), constants.UsageMetricsFileName), nil
+}
+
+// LoadUserConfig reads the user configuration file. A missing file is an
+// empty configuration.
+func LoadUserConfig() (*UserConfig, error) {
+	path, err := UserConfigPath()
+	if err != nil {
+		return nil, err
+	}
+	return LoadUserConfigFile(path)
+}
+
+// LoadUserConfigFile reads the user configuration at path
+func LoadUserConfigFile(path string) (*UserConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return &UserConfig{}, nil
+		}
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	var cfg UserConfig
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&cfg); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	if cfg.Color != "" && !slices.Contains(colorModes, cfg.Color) {
+		return nil, fmt.Errorf("invalid %s: %s must be one of %s", path, constants.UserKeyColor, strings.Join(colorModes, ", "))
+	}
+	return &cfg, nil
+}
+
+// Save writes the user configuration to path, creating its directory
+func (c *UserConfig) Save(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", filepath.Dir(path), err)
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("# dev-stack user configuration, applied beneath every project's configuration\n")
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(c); err != nil {
+		return fmt.Errorf("failed to encode the user configuration: %w", err)
+	}
+	if err := encoder.Close(); err != nil {
+		return fmt.Errorf("failed to encode the user configuration: %w", err)
+	}
+	if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
+
+// Set sets the user setting key from its text form. An empty value clears
+// it, back to its default.
+func (c *UserConfig) Set(key, value string) error {
+	if host, ok := mirrorHost(key); ok {
+		if value == "" {
+			delete(c.RegistryMirrors, host)
+			return nil
+		}
+		if c.RegistryMirrors == nil {
+			c.RegistryMirrors = make(map[string]string)
+		}
+		c.RegistryMirrors[host] = value
+		return nil
+	}
+
+	switch key {
+	case constants.UserKeyDefaultProfile:
+		c.DefaultProfile = value
+	case constants.UserKeyTelemetry:
+		if value == "" {
+			c.Telemetry = nil
+			return nil
+		}
+		enabled, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("invalid %s %q: must be true or false", key, value)
+		}
+		c.Telemetry = &enabled
+	case constants.UserKeyColor:
+		if value != "" && !slices.Contains(colorModes, value) {
+			return fmt.Errorf("invalid %s %q: must be one of %s", key, value, strings.Join(colorModes, ", "))
+		}
+		c.Color = value
+	case constants.UserKeyBackupDir:
+		c.BackupDir = value
+	case constants.UserKeyUsageMetrics:
+		if value == "" {
+			c.UsageMetrics = nil
+			return nil
+		}
+		enabled, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("invalid %s %q: must be true or false", key, value)
+		}
+		c.UsageMetrics = &enabled
+	case constants.UserKeyUsageEndpoint:
+		if value != "" && !strings.HasPrefix(value, "http://") && !strings.HasPrefix(value, "https://") {
+			return fmt.Errorf("invalid %s %q: must be an http or https URL", key, value)
+		}
+		c.UsageEndpoint = value
+	default:
+		return unknownUserKey(key)
+	}
+	return nil
+}
+
+// Get returns the effective value of the user setting key
+func (c *UserConfig) Get(key string) (UserSetting, error) {
+	if host, ok := mirrorHost(key); ok {
+		if mirror, ok := c.RegistryMirrors[host]; ok {
+			return UserSetting{Key: key, Value: mirror, Source: SourceGlobal}, nil
+		}
+		return UserSetting{Key: key, Source: SourceDefault}, nil
+	}
+	if !slices.Contains(userKeys, key) {
+		return UserSetting{}, unknownUserKey(key)
+	}
+	return c.setting(key), nil
+}
+
+// Settings returns the effective value of every user setting: the
+// registry mirrors by host, then the other settings
+func (c *UserConfig) Settings() []UserSetting {
+	hosts := make([]string, 0, len(c.RegistryMirrors))
+	for host := range c.RegistryMirrors {
+		hosts = append(hosts, host)
+	}
+	sort.Strings(hosts)
+
+	settings := make([]UserSetting, 0, len(hosts)+len(userKeys))
+	for _, host := range hosts {
+		settings = append(settings, UserSetting{
+			Key:    constants.UserKeyRegistryMirrors + "." + host,
+			Value:  c.RegistryMirrors[host],
+			Source: SourceGlobal,
+		})
+	}
+	for _, key := range userKeys {
+		settings = append(settings, c.setting(key))
+	}
+	return settings
+}
+
+// TelemetryEnabled reports whether command completion records are logged
+func (c *UserConfig) TelemetryEnabled() bool {
+	enabled, err := strconv.ParseBool(c.setting(constants.UserKeyTelemetry).Value)
+	return err != nil || enabled
+}
+
+// UsageMetricsEnabled reports whether anonymous usage metrics are
+// recorded: only once the user opted in, and never with DO_NOT_TRACK set
+func (c *UserConfig) UsageMetricsEnabled() bool {
+	enabled, err := strconv.ParseBool(c.setting(constants.UserKeyUsageMetrics).Value)
+	return err == nil && enabled
+}
+
+// UsageMetricsEndpoint returns the URL usage metrics are sent to, empty
+// when they are only kept locally
+func (c *UserConfig) UsageMetricsEndpoint() string {
+	return c.setting(constants.UserKeyUsageEndpoint).Value
+}
+
+// ColorMode returns the effective color mode: NO_COLOR forces never
+func (c *UserConfig) ColorMode() string {
+	return c.setting(constants.UserKeyColor).Value
+}
+
+// setting resolves one of userKeys from the environment, the file, then
+// its default
+func (c *UserConfig) setting(key string) UserSetting {
+	switch key {
+	case constants.UserKeyDefaultProfile:
+		return resolveSetting(key, c.DefaultProfile, constants.EnvProfile, os.Getenv(constants.EnvProfile), "")
+	case constants.UserKeyTelemetry:
+		stored := ""
+		if c.Telemetry != nil {
+			stored = strconv.FormatBool(*c.Telemetry)
+		}
+		env := ""
+		if enabled, err := strconv.ParseBool(os.Getenv(constants.EnvTelemetry)); err == nil {
+			env = strconv.FormatBool(enabled)
+		}
+		return resolveSetting(key, stored, constants.EnvTelemetry, env, "true")
+	case constants.UserKeyColor:
+		env := ""
+		if os.Getenv(constants.EnvNoColor) != "" {
+			env = constants.ColorNever
+		}
+		return resolveSetting(key, c.Color, constants.EnvNoColor, env, constants.ColorAuto)
+	case constants.UserKeyUsageMetrics:
+		stored := ""
+		if c.UsageMetrics != nil {
+			stored = strconv.FormatBool(*c.UsageMetrics)
+		}
+		if doNotTrack, err := strconv.ParseBool(os.Getenv(constants.EnvDoNotTrack)); err == nil && doNotTrack {
+			return UserSetting{Key: key, Value: "false", Source: constants.EnvDoNotTrack}
+		}
+		env := ""
+		if enabled, err := strconv.ParseBool(os.Getenv(constants.EnvUsageMetrics)); err == nil {
+			env = strconv.FormatBool(enabled)
+		}
+		return resolveSetting(key, stored, constants.EnvUsageMetrics, env, "false")
+	case constants.UserKeyUsageEndpoint:
+		return resolveSetting(key, c.UsageEndpoint, constants.EnvUsageEndpoint, os.Getenv(constants.EnvUsageEndpoint), "")
+	default:
+		return resolveSetting(key, c.BackupDir, constants.EnvBackupDir, os.Getenv(constants.EnvBackupDir), "")
+	}
+}
+
+// resolveSetting picks the value set in the environment variable env,
+// then the stored one, then the fallback
+func resolveSetting(key, stored, env, envValue, fallback string) UserSetting {
+	switch {
+	case envValue != "":
+		return UserSetting{Key: key, Value: envValue, Source: env}
+	case stored != "":
+		return UserSetting{Key: key, Value: stored, Source: SourceGlobal}
+	default:
+		return UserSetting{Key: key, Value: fallback, Source: SourceDefault}
+	}
+}
+
+// mirrorHost returns the registry host of a registry_mirrors.<host> key
+func mirrorHost(key string) (string, bool) {
+	host, ok := strings.CutPrefix(key, constants.UserKeyRegistryMirrors+".")
+	return host, ok && host != ""
+}
+
+func unknownUserKey(key string) error {
+	return fmt.Errorf("unknown user setting %q; known settings are %s.<host>, %s", key, constants.UserKeyRegistryMirrors, strings.Join(userKeys, ", "))
+}
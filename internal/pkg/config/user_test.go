@@ -0,0 +1,138 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/isaacgarza/dev-stack/internal/pkg/constants"
+)
+
+func TestUserConfigPath(t *testing.T) {
+	t.Setenv(constants.EnvUserConfig, "")
+	t.Setenv(constants.EnvConfigHome, "/xdg")
+	path, err := UserConfigPath()
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join("/xdg", "dev-stack", "config.yaml"), path)
+
+	t.Setenv(constants.EnvUserConfig, "/custom/config.yaml")
+	path, err = UserConfigPath()
+	require.NoError(t, err)
+	assert.Equal(t, "/custom/config.yaml", path)
+
+	SetUserConfigPath("/flag/config.yaml")
+	t.Cleanup(func() { SetUserConfigPath("") })
+	path, err = UserConfigPath()
+	require.NoError(t, err)
+	assert.Equal(t, "/flag/config.yaml", path)
+}
+
+func TestLoadUserConfigFile(t *testing.T) {
+	dir := t.TempDir()
+
+	cfg, err := LoadUserConfigFile(filepath.Join(dir, "missing.yaml"))
+	require.NoError(t, err)
+	assert.Equal(t, &UserConfig{}, cfg)
+
+	path := filepath.Join(dir, "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("default_profile: minimal\ntelemetry: false\ncolor: never\n"), 0644))
+	cfg, err = LoadUserConfigFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "minimal", cfg.DefaultProfile)
+	assert.Equal(t, constants.ColorNever, cfg.Color)
+
+	require.NoError(t, os.WriteFile(path, []byte("colour: never\n"), 0644))
+	_, err = LoadUserConfigFile(path)
+	assert.ErrorContains(t, err, "colour")
+
+	require.NoError(t, os.WriteFile(path, []byte("color: sometimes\n"), 0644))
+	_, err = LoadUserConfigFile(path)
+	assert.ErrorContains(t, err, "must be one of")
+}
+
+func TestUserConfig_SetAndSave(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "dev-stack", "config.yaml")
+	cfg := &UserConfig{}
+	require.NoError(t, cfg.Set("registry_mirrors.docker.io", "mirror.example"))
+	require.NoError(t, cfg.Set("telemetry", "false"))
+	require.NoError(t, cfg.Set("backup_dir", "/backups"))
+	assert.Error(t, cfg.Set("telemetry", "sometimes"))
+	assert.Error(t, cfg.Set("color", "sometimes"))
+	assert.ErrorContains(t, cfg.Set("registry_mirrors", "mirror.example"), "unknown user setting")
+	require.NoError(t, cfg.Save(path))
+
+	loaded, err := LoadUserConfigFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, cfg, loaded)
+
+	require.NoError(t, loaded.Set("registry_mirrors.docker.io", ""))
+	require.NoError(t, loaded.Set("telemetry", ""))
+	assert.Empty(t, loaded.RegistryMirrors)
+	assert.Nil(t, loaded.Telemetry)
+}
+
+func TestUserConfig_Settings(t *testing.T) {
+	for _, env := range []string{constants.EnvProfile, constants.EnvTelemetry, constants.EnvNoColor, constants.EnvBackupDir, constants.EnvUsageMetrics, constants.EnvUsageEndpoint, constants.EnvDoNotTrack} {
+		t.Setenv(env, "")
+	}
+	disabled := false
+	cfg := &UserConfig{
+		RegistryMirrors: map[string]string{"ghcr.io": "b", "docker.io": "a"},
+		Telemetry:       &disabled,
+		Color:           constants.ColorAlways,
+	}
+
+	assert.Equal(t, []UserSetting{
+		{Key: "registry_mirrors.docker.io", Value: "a", Source: SourceGlobal},
+		{Key: "registry_mirrors.ghcr.io", Value: "b", Source: SourceGlobal},
+		{Key: "default_profile", Value: "", Source: SourceDefault},
+		{Key: "telemetry", Value: "false", Source: SourceGlobal},
+		{Key: "color", Value: "always", Source: SourceGlobal},
+		{Key: "backup_dir", Value: "", Source: SourceDefault},
+		{Key: "usage_metrics", Value: "false", Source: SourceDefault},
+		{Key: "usage_metrics_endpoint", Value: "", Source: SourceDefault},
+	}, cfg.Settings())
+	assert.False(t, cfg.TelemetryEnabled())
+	assert.Equal(t, constants.ColorAlways, cfg.ColorMode())
+
+	// The environment wins over the file
+	t.Setenv(constants.EnvTelemetry, "1")
+	t.Setenv(constants.EnvNoColor, "1")
+	t.Setenv(constants.EnvProfile, "ci")
+	assert.True(t, cfg.TelemetryEnabled())
+	assert.Equal(t, constants.ColorNever, cfg.ColorMode())
+	setting, err := cfg.Get("default_profile")
+	require.NoError(t, err)
+	assert.Equal(t, UserSetting{Key: "default_profile", Value: "ci", Source: constants.EnvProfile}, setting)
+
+	setting, err = cfg.Get("registry_mirrors.quay.io")
+	require.NoError(t, err)
+	assert.Equal(t, SourceDefault, setting.Source)
+	_, err = cfg.Get("colour")
+	assert.Error(t, err)
+
+	assert.True(t, (&UserConfig{}).TelemetryEnabled())
+}
+
+func TestUserConfig_UsageMetrics(t *testing.T) {
+	for _, env := range []string{constants.EnvUsageMetrics, constants.EnvUsageEndpoint, constants.EnvDoNotTrack} {
+		t.Setenv(env, "")
+	}
+	cfg := &UserConfig{}
+	assert.False(t, cfg.UsageMetricsEnabled(), "usage metrics are opt-in")
+
+	require.NoError(t, cfg.Set("usage_metrics", "true"))
+	require.NoError(t, cfg.Set("usage_metrics_endpoint", "https://metrics.example/v1"))
+	assert.Error(t, cfg.Set("usage_metrics_endpoint", "metrics.example"))
+	assert.True(t, cfg.UsageMetricsEnabled())
+	assert.Equal(t, "https://metrics.example/v1", cfg.UsageMetricsEndpoint())
+
+	t.Setenv(constants.EnvDoNotTrack, "1")
+	assert.False(t, cfg.UsageMetricsEnabled())
+	setting, err := cfg.Get("usage_metrics")
+	require.NoError(t, err)
+	assert.Equal(t, constants.EnvDoNotTrack, setting.Source)
+}
@@ -0,0 +1,72 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/isaacgarza/dev-stack/internal/pkg/constants"
+)
+
+// SetServiceVersion sets services.<service>.version in the project
+// configuration at path, pinning the service's image tag. Only the lines of
+// that setting change.
+func SetServiceVersion(path, service, version string) error {
+	lines, root, err := readConfigLines(path)
+	if err != nil {
+		return err
+	}
+
+	setting := func(indent string) string {
+		return indent + "version: " + strconv.Quote(version)
+	}
+
+	servicesKey, services := mappingEntry(root, constants.ServicesSection)
+	switch {
+	case servicesKey == nil:
+		lines = appendSection(lines, constants.ServicesSection+":", "  "+service+":", setting("    "))
+		return writeConfigLines(path, lines)
+
+	case isNull(services):
+		indent := childIndent(servicesKey, nil)
+		lines = splice(lines, servicesKey.Line, servicesKey.Line, []string{
+			indentOf(servicesKey) + constants.ServicesSection + ":",
+			indent + service + ":",
+			setting(indent + "  "),
+		})
+		return writeConfigLines(path, lines)
+
+	case services.Kind != yaml.MappingNode || services.Style&yaml.FlowStyle != 0:
+		return fmt.Errorf("cannot update %s: write it as a block mapping", constants.ServicesSection)
+	}
+
+	serviceKey, settings := mappingEntry(services, service)
+	switch {
+	case serviceKey == nil:
+		indent := childIndent(servicesKey, services)
+		lines = splice(lines, servicesKey.Line+1, servicesKey.Line, []string{
+			indent + service + ":",
+			setting(indent + "  "),
+		})
+
+	case isNull(settings):
+		lines = splice(lines, serviceKey.Line, serviceKey.Line, []string{
+			indentOf(serviceKey) + service + ":",
+			setting(childIndent(serviceKey, nil)),
+		})
+
+	case settings.Kind != yaml.MappingNode || settings.Style&yaml.FlowStyle != 0:
+		return fmt.Errorf("cannot update %s.%s: write it as a block mapping", constants.ServicesSection, service)
+
+	default:
+		versionKey, value := mappingEntry(settings, "version")
+		if versionKey == nil {
+			lines = splice(lines, serviceKey.Line+1, serviceKey.Line, []string{setting(childIndent(serviceKey, settings))})
+			break
+		}
+		lines = splice(lines, versionKey.Line, value.Line, []string{setting(indentOf(versionKey))})
+	}
+
+	return writeConfigLines(path, lines)
+}
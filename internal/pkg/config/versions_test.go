@@ -0,0 +1,77 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetServiceVersion(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		service  string
+		expected string
+	}{
+		{
+			name:     "adds a services section",
+			content:  "stack:\n  enabled:\n    - postgres\n",
+			service:  "postgres",
+			expected: "stack:\n  enabled:\n    - postgres\n\nservices:\n  postgres:\n    version: \"16.2\"\n",
+		},
+		{
+			name:     "fills an empty services section",
+			content:  "services:\n\nadvanced: {}\n",
+			service:  "postgres",
+			expected: "services:\n  postgres:\n    version: \"16.2\"\n\nadvanced: {}\n",
+		},
+		{
+			name:     "adds a service to existing settings",
+			content:  "services:\n    redis:\n        version: \"7.2\"\n",
+			service:  "postgres",
+			expected: "services:\n    postgres:\n      version: \"16.2\"\n    redis:\n        version: \"7.2\"\n",
+		},
+		{
+			name:     "adds a version to a service",
+			content:  "services:\n  postgres:\n    # tuned for tests\n    memory: 1g\n",
+			service:  "postgres",
+			expected: "services:\n  postgres:\n    version: \"16.2\"\n    # tuned for tests\n    memory: 1g\n",
+		},
+		{
+			name:     "fills an empty service",
+			content:  "services:\n  postgres:\n  redis:\n    version: \"7\"\n",
+			service:  "postgres",
+			expected: "services:\n  postgres:\n    version: \"16.2\"\n  redis:\n    version: \"7\"\n",
+		},
+		{
+			name:     "replaces a pinned version",
+			content:  "# pins\nservices:\n  postgres:\n    version: 15\n    memory: 1g\n\nstack:\n  enabled: [postgres]\n",
+			service:  "postgres",
+			expected: "# pins\nservices:\n  postgres:\n    version: \"16.2\"\n    memory: 1g\n\nstack:\n  enabled: [postgres]\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "dev-stack-config.yml")
+			require.NoError(t, os.WriteFile(path, []byte(tt.content), 0644))
+
+			require.NoError(t, SetServiceVersion(path, tt.service, "16.2"))
+
+			data, err := os.ReadFile(path)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, string(data))
+		})
+	}
+}
+
+func TestSetServiceVersion_FlowStyle(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "dev-stack-config.yml")
+	require.NoError(t, os.WriteFile(path, []byte("services: {postgres: {version: \"15\"}}\n"), 0644))
+
+	err := SetServiceVersion(path, "postgres", "16.2")
+	assert.ErrorContains(t, err, "block mapping")
+}
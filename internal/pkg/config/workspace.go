@@ -0,0 +1,166 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/isaacgarza/dev-stack/internal/pkg/constants"
+)
+
+// Workspace is a repository holding several dev-stack projects, declared in
+// dev-stack.workspace.yaml at its root. Shared services run once for the
+// whole workspace and every project reaches them over the workspace network.
+type Workspace struct {
+	Name     string
+	Root     string
+	Shared   []string
+	Projects map[string]WorkspaceProject
+}
+
+// WorkspaceProject is a project of a workspace. Path is relative to the
+// workspace root and defaults to the project's name.
+type WorkspaceProject struct {
+	Path      string   `yaml:"path"`
+	DependsOn []string `yaml:"depends_on"`
+}
+
+// workspaceFile is the layout of dev-stack.workspace.yaml
+type workspaceFile struct {
+	Name   string `yaml:"name"`
+	Shared struct {
+		Services []string `yaml:"services"`
+	} `yaml:"shared"`
+	Projects map[string]WorkspaceProject `yaml:"projects"`
+}
+
+// LoadWorkspace reads the workspace file in dir
+func LoadWorkspace(dir string) (*Workspace, error) {
+	path := filepath.Join(dir, constants.WorkspaceFileName)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("no %s in %s", constants.WorkspaceFileName, dir)
+		}
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	var file workspaceFile
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+
+	root, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+	ws := &Workspace{
+		Name:     file.Name,
+		Root:     root,
+		Shared:   file.Shared.Services,
+		Projects: file.Projects,
+	}
+	if ws.Name == "" {
+		ws.Name = filepath.Base(root)
+	}
+	for name, project := range ws.Projects {
+		if project.Path == "" {
+			project.Path = name
+			ws.Projects[name] = project
+		}
+	}
+
+	if err := ws.validate(); err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", path, err)
+	}
+	return ws, nil
+}
+
+// validate checks that the workspace declares projects whose dependencies
+// exist and don't form a cycle
+func (w *Workspace) validate() error {
+	if len(w.Projects) == 0 {
+		return fmt.Errorf("no projects declared")
+	}
+	for _, name := range w.ProjectNames() {
+		for _, dep := range w.Projects[name].DependsOn {
+			if _, ok := w.Projects[dep]; !ok {
+				return fmt.Errorf("project %s depends on unknown project %s", name, dep)
+			}
+		}
+	}
+	_, err := w.Resolve(w.ProjectNames())
+	return err
+}
+
+// ProjectNames returns the names of the workspace's projects, sorted
+func (w *Workspace) ProjectNames() []string {
+	names := make([]string, 0, len(w.Projects))
+	for name := range w.Projects {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ProjectDir returns the directory of a project
+func (w *Workspace) ProjectDir(name string) string {
+	return filepath.Join(w.Root, w.Projects[name].Path)
+}
+
+// SharedProjectName is the compose project the shared services run in
+func (w *Workspace) SharedProjectName() string {
+	return w.Name + "-shared"
+}
+
+// Network is the Docker network joining the shared services and every
+// project's services
+func (w *Workspace) Network() string {
+	return w.SharedProjectName() + "-network"
+}
+
+// Resolve returns the named projects together with the projects they depend
+// on, directly or not, in the order they must start: every project comes
+// after its dependencies
+func (w *Workspace) Resolve(names []string) ([]string, error) {
+	var order []string
+	state := make(map[string]int) // 0 unvisited, 1 visiting, 2 done
+
+	var visit func(name string, path []string) error
+	visit = func(name string, path []string) error {
+		project, ok := w.Projects[name]
+		if !ok {
+			return fmt.Errorf("unknown project %s (available: %s)", name, strings.Join(w.ProjectNames(), ", "))
+		}
+		switch state[name] {
+		case 1:
+			return fmt.Errorf("dependency cycle: %s", strings.Join(append(path, name), " -> "))
+		case 2:
+			return nil
+		}
+
+		state[name] = 1
+		deps := slices.Clone(project.DependsOn)
+		sort.Strings(deps)
+		for _, dep := range deps {
+			if err := visit(dep, append(path, name)); err != nil {
+				return err
+			}
+		}
+		state[name] = 2
+		order = append(order, name)
+		return nil
+	}
+
+	for _, name := range names {
+		if err := visit(name, nil); err != nil {
+			return nil, err
+		}
+	}
+	return order, nil
+}
@@ -0,0 +1,88 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/isaacgarza/dev-stack/internal/pkg/constants"
+)
+
+func writeWorkspace(t *testing.T, content string) string {
+	t.Helper()
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, constants.WorkspaceFileName), []byte(content), 0644))
+	return dir
+}
+
+func TestLoadWorkspace(t *testing.T) {
+	dir := writeWorkspace(t, `name: shop
+shared:
+  services: [postgres]
+projects:
+  api:
+    depends_on: [worker]
+  web:
+    path: frontend
+    depends_on: [api]
+  worker: {}
+`)
+
+	ws, err := LoadWorkspace(dir)
+	require.NoError(t, err)
+	assert.Equal(t, "shop", ws.Name)
+	assert.Equal(t, []string{"postgres"}, ws.Shared)
+	assert.Equal(t, []string{"api", "web", "worker"}, ws.ProjectNames())
+	assert.Equal(t, filepath.Join(dir, "api"), ws.ProjectDir("api"))
+	assert.Equal(t, filepath.Join(dir, "frontend"), ws.ProjectDir("web"))
+	assert.Equal(t, "shop-shared", ws.SharedProjectName())
+	assert.Equal(t, "shop-shared-network", ws.Network())
+
+	order, err := ws.Resolve([]string{"web"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"worker", "api", "web"}, order)
+
+	order, err = ws.Resolve([]string{"worker", "api"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"worker", "api"}, order)
+
+	_, err = ws.Resolve([]string{"billing"})
+	assert.ErrorContains(t, err, "unknown project billing")
+}
+
+func TestLoadWorkspace_DefaultName(t *testing.T) {
+	dir := writeWorkspace(t, "projects:\n  api: {}\n")
+
+	ws, err := LoadWorkspace(dir)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Base(dir), ws.Name)
+}
+
+func TestLoadWorkspace_Invalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		err     string
+	}{
+		{name: "no projects", content: "name: shop\n", err: "no projects declared"},
+		{name: "unknown dependency", content: "projects:\n  api:\n    depends_on: [db]\n", err: "api depends on unknown project db"},
+		{
+			name:    "cycle",
+			content: "projects:\n  api:\n    depends_on: [worker]\n  worker:\n    depends_on: [api]\n",
+			err:     "dependency cycle: api -> worker -> api",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := LoadWorkspace(writeWorkspace(t, tt.content))
+			assert.ErrorContains(t, err, tt.err)
+		})
+	}
+
+	_, err := LoadWorkspace(t.TempDir())
+	assert.ErrorContains(t, err, "no "+constants.WorkspaceFileName)
+}
@@ -7,6 +7,12 @@ const (
 	AppNameTitle = "Dev Stack" // Title case for headers
 	AppNameLower = "dev stack" // Sentence case for messages
 
+	// Where dev-stack is developed and released, for package manifests
+	AppRepository = "isaacgarza/dev-stack"
+	AppHomepage   = "https://github.com/" + AppRepository
+	AppLicense    = "MIT"
+	AppMaintainer = "Isaac Garza"
+
 	// Common messages
 	MsgInitializing = "Initializing " + AppNameTitle
 	MsgStarting     = "Starting " + AppNameTitle
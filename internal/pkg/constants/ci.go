@@ -4,6 +4,18 @@ package constants
 const (
 	ExitSuccess = 0
 	ExitError   = 1
+	// ExitInterrupted is the status of a command stopped by an interrupt,
+	// as shells report for SIGINT
+	ExitInterrupted = 130
+)
+
+// Exit codes of the error kinds, stable so scripts can rely on them
+const (
+	ExitDockerUnavailable = 10
+	ExitPortConflict      = 11
+	ExitServiceNotFound   = 12
+	ExitConfigInvalid     = 13
+	ExitTimeout           = 14
 )
 
 // Standard flag names (following cobra/viper conventions)
@@ -11,6 +23,35 @@ const (
 	FlagQuiet          = "quiet"
 	FlagJSON           = "json"
 	FlagNoColor        = "no-color"
+	FlagNoProgress     = "no-progress"
 	FlagNonInteractive = "non-interactive"
 	FlagStrict         = "strict"
+	FlagIKnow          = "i-know"
+	FlagLogFormat      = "log-format"
+	FlagConfig         = "config"
+	FlagTimeout        = "timeout"
+	FlagErrorFormat    = "error-format"
+	FlagNoDelegate     = "no-delegate"
+	FlagOutput         = "output"
+	FlagColumns        = "columns"
+	// FlagFormat is the older name of FlagOutput on some commands
+	FlagFormat = "format"
+)
+
+// Environment variables
+const (
+	EnvProfile        = "DEV_STACK_PROFILE"
+	EnvDaemonToken    = "DEV_STACK_DAEMON_TOKEN"
+	EnvUserConfig     = "DEV_STACK_CONFIG"
+	EnvTelemetry      = "DEV_STACK_TELEMETRY"
+	EnvBackupDir      = "DEV_STACK_BACKUP_DIR"
+	EnvUsageMetrics   = "DEV_STACK_USAGE_METRICS"
+	EnvUsageEndpoint  = "DEV_STACK_USAGE_METRICS_ENDPOINT"
+	EnvNoDelegate     = "DEV_STACK_NO_DELEGATE"
+	EnvDelegated      = "DEV_STACK_DELEGATED" // Set for the version a command was delegated to
+	EnvDoNotTrack     = "DO_NOT_TRACK"
+	EnvNoColor        = "NO_COLOR"
+	EnvConfigHome     = "XDG_CONFIG_HOME"
+	EnvCacheHome      = "XDG_CACHE_HOME"
+	EnvNoCommandCache = "DEV_STACK_NO_COMMAND_CACHE" // Set to parse commands.yaml on every run
 )
@@ -14,6 +14,8 @@ const (
 	CmdNameConflicts  = "conflicts"
 	CmdNameLogs       = "logs"
 	CmdNameExec       = "exec"
+	CmdNameWorkflow   = "workflow"
+	CmdNameRun        = "run"
 	CmdNameConnect    = "connect"
 	CmdNameBackup     = "backup"
 	CmdNameRestore    = "restore"
@@ -23,6 +25,94 @@ const (
 	CmdNameValidate   = "validate"
 	CmdNameVersion    = "version"
 	CmdNameDocs       = "docs"
+	CmdNameGenerate   = "generate"
+	CmdNameHealthz    = "healthz"
+	CmdNameAdopt      = "adopt"
+	CmdNameSeed       = "seed"
+	CmdNameEnv        = "env"
+	CmdNameShellInit  = "shell-init"
+	CmdNameDev        = "dev"
+	CmdNameImages     = "images"
+	CmdNameTop        = "top"
+	CmdNameDaemon     = "daemon"
+	CmdNameIDEServer  = "ide-server"
+	CmdNameGC         = "gc"
+	CmdNameSnapshot   = "snapshot"
+	CmdNameURLs       = "urls"
+	CmdNameHosts      = "hosts"
+	CmdNameDiff       = "diff"
+	CmdNameConfig     = "config"
+	CmdNamePull       = "pull"
+	CmdNameBuild      = "build"
+	CmdNameAWS        = "aws"
+	CmdNameKafka      = "kafka"
+	CmdNameTraces     = "traces"
+	CmdNameEvents     = "events"
+	CmdNameDiagnose   = "diagnose"
+	CmdNameTelemetry  = "telemetry"
+	CmdNamePrune      = "prune"
+	CmdNameVolume     = "volume"
+	CmdNameNetwork    = "network"
+	CmdNameCI         = "ci"
+	CmdNameExport     = "export"
+	CmdNameRelease    = "release"
+	CmdNameUnlock     = "unlock"
+	CmdNamePause      = "pause"
+	CmdNameResume     = "resume"
+	CmdNameIdle       = "idle"
+	CmdNameBench      = "bench"
+)
+
+// Subcommand paths, as passed to the handler lookup
+const (
+	CmdNameGenerateDiagram    = CmdNameGenerate + " diagram"
+	CmdNameGenerateCompose    = CmdNameGenerate + " compose"
+	CmdNameServicesList       = CmdNameServices + " list"
+	CmdNameServicesInfo       = CmdNameServices + " info"
+	CmdNameServicesSearch     = CmdNameServices + " search"
+	CmdNameServicesAdd        = CmdNameServices + " add"
+	CmdNameServicesRemove     = CmdNameServices + " remove"
+	CmdNameImagesOutdated     = CmdNameImages + " outdated"
+	CmdNameImagesPin          = CmdNameImages + " pin"
+	CmdNameImagesUpdate       = CmdNameImages + " update"
+	CmdNameImagesExport       = CmdNameImages + " export"
+	CmdNameImagesImport       = CmdNameImages + " import"
+	CmdNameSnapshotCreate     = CmdNameSnapshot + " create"
+	CmdNameSnapshotRestore    = CmdNameSnapshot + " restore"
+	CmdNameSnapshotList       = CmdNameSnapshot + " list"
+	CmdNameVolumeBackup       = CmdNameVolume + " backup"
+	CmdNameVolumeRestore      = CmdNameVolume + " restore"
+	CmdNameNetworkMap         = CmdNameNetwork + " map"
+	CmdNameHostsSync          = CmdNameHosts + " sync"
+	CmdNameHostsRemove        = CmdNameHosts + " remove"
+	CmdNameHostsList          = CmdNameHosts + " list"
+	CmdNameConfigMigrate      = CmdNameConfig + " migrate"
+	CmdNameConfigGet          = CmdNameConfig + " get"
+	CmdNameConfigSet          = CmdNameConfig + " set"
+	CmdNameConfigUnset        = CmdNameConfig + " unset"
+	CmdNameConfigList         = CmdNameConfig + " list"
+	CmdNameTelemetryOn        = CmdNameTelemetry + " on"
+	CmdNameTelemetryOff       = CmdNameTelemetry + " off"
+	CmdNameTelemetryStatus    = CmdNameTelemetry + " status"
+	CmdNameAWSLs              = CmdNameAWS + " ls"
+	CmdNameKafkaTopics        = CmdNameKafka + " topics"
+	CmdNameKafkaProduce       = CmdNameKafka + " produce"
+	CmdNameKafkaConsume       = CmdNameKafka + " consume"
+	CmdNameTracesOpen         = CmdNameTraces + " open"
+	CmdNameTracesPing         = CmdNameTraces + " ping"
+	CmdNameCIUp               = CmdNameCI + " up"
+	CmdNameExportKubernetes   = CmdNameExport + " kubernetes"
+	CmdNameExportDevcontainer = CmdNameExport + " devcontainer"
+	CmdNameExportNix          = CmdNameExport + " nix"
+	CmdNameCompletionInstall  = CmdNameCompletion + " install"
+	CmdNameDocsCLI            = CmdNameDocs + " cli"
+	CmdNameReleaseManifests   = CmdNameRelease + " manifests"
+	CmdNameBenchUp            = CmdNameBench + " up"
+
+	CmdNameKafkaTopicsList     = CmdNameKafkaTopics + " list"
+	CmdNameKafkaTopicsCreate   = CmdNameKafkaTopics + " create"
+	CmdNameKafkaTopicsDelete   = CmdNameKafkaTopics + " delete"
+	CmdNameKafkaTopicsDescribe = CmdNameKafkaTopics + " describe"
 )
 
 // Shell types for completion
@@ -39,11 +129,14 @@ const (
 	DockerInfoCmd    = "info"
 	DockerComposeCmd = "compose"
 	DockerVersionCmd = "version"
+	GitCmd           = "git"
 )
 
 // URLs
 const (
-	DockerInstallURL = "https://docs.docker.com/get-docker/"
+	DockerInstallURL        = "https://docs.docker.com/get-docker/"
+	DockerComposeInstallURL = "https://docs.docker.com/compose/install/"
+	GitInstallURL           = "https://git-scm.com/downloads"
 )
 
 // Command reference builders
@@ -53,10 +146,15 @@ func CmdRef(cmdName string) string {
 
 // Common command references
 var (
-	CmdUp     = CmdRef(CmdNameUp)
-	CmdDown   = CmdRef(CmdNameDown)
-	CmdStatus = CmdRef(CmdNameStatus)
-	CmdInit   = CmdRef(CmdNameInit)
+	CmdUp      = CmdRef(CmdNameUp)
+	CmdDown    = CmdRef(CmdNameDown)
+	CmdStatus  = CmdRef(CmdNameStatus)
+	CmdInit    = CmdRef(CmdNameInit)
+	CmdGC      = CmdRef(CmdNameGC)
+	CmdCleanup = CmdRef(CmdNameCleanup)
+	CmdUnlock  = CmdRef(CmdNameUnlock)
+	CmdResume  = CmdRef(CmdNameResume)
+	CmdIdle    = CmdRef(CmdNameIdle)
 )
 
 // Error messages
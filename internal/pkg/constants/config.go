@@ -13,8 +13,14 @@ const (
 	OverridesSection  = "overrides"
 	ValidationSection = "validation"
 	AdvancedSection   = "advanced"
+	ServicesSection   = "services"
+	ProfilesSection   = "profiles"
 )
 
+// SchemaVersionKey is the top-level key recording the layout version of a
+// project configuration
+const SchemaVersionKey = "schema_version"
+
 // Default configuration values
 const (
 	DefaultSkipWarnings      = false
@@ -22,4 +28,44 @@ const (
 	DefaultAutoStart         = true
 	DefaultPullLatestImages  = true
 	DefaultCleanupOnRecreate = false
+	DefaultEnvFiles          = false
+)
+
+// Advanced option keys
+const (
+	AdvancedEnvFiles = "env_files"
+)
+
+// User configuration keys, the settings of ~/.config/dev-stack/config.yaml
+const (
+	UserKeyRegistryMirrors = "registry_mirrors"
+	UserKeyDefaultProfile  = "default_profile"
+	UserKeyTelemetry       = "telemetry"
+	UserKeyColor           = "color"
+	UserKeyBackupDir       = "backup_dir"
+	UserKeyUsageMetrics    = "usage_metrics"
+	UserKeyUsageEndpoint   = "usage_metrics_endpoint"
+)
+
+// Color modes of the color user setting
+const (
+	ColorAuto   = "auto"
+	ColorAlways = "always"
+	ColorNever  = "never"
+)
+
+// Policies of images.pull_policy, naming the images up pulls before
+// starting the stack
+const (
+	PullPolicyAlways  = "always"
+	PullPolicyMissing = "missing"
+	PullPolicyNever   = "never"
+)
+
+// Policies of images.emulation, saying what generating the compose file
+// does about images with no variant for the host architecture
+const (
+	EmulationAuto = "auto" // pin them to a platform they are published for
+	EmulationWarn = "warn" // only warn about them
+	EmulationOff  = "off"  // leave them alone
 )
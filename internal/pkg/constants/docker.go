@@ -2,9 +2,15 @@ package constants
 
 // Docker container states
 const (
-	StateRunning = "running"
-	StateStopped = "exited"
-	StateCreated = "created"
+	StateRunning    = "running"
+	StateStopped    = "exited"
+	StateCreated    = "created"
+	StateRestarting = "restarting"
+	StatePaused     = "paused"
+
+	// StateCrashLooping is reported by dev-stack, not Docker, for a
+	// container that keeps exiting and being restarted
+	StateCrashLooping = "crash-looping"
 )
 
 // Health statuses
@@ -17,11 +23,29 @@ const (
 
 // Docker Compose labels
 const (
-	ComposeProjectLabel = "com.docker.compose.project"
-	ComposeServiceLabel = "com.docker.compose.service"
+	ComposeProjectLabel    = "com.docker.compose.project"
+	ComposeServiceLabel    = "com.docker.compose.service"
+	ComposeNumberLabel     = "com.docker.compose.container-number"
+	ComposeOneoffLabel     = "com.docker.compose.oneoff"
+	ComposeConfigHashLabel = "com.docker.compose.config-hash"
+)
+
+// Docker Compose depends_on conditions
+const (
+	ConditionServiceStarted   = "service_started"
+	ConditionServiceHealthy   = "service_healthy"
+	ConditionServiceCompleted = "service_completed_successfully"
+)
+
+// Built-in service names referenced by the CLI
+const (
+	ServiceHealthz = "healthz"
+	ServiceProxy   = "proxy"
+	ServiceGrafana = "grafana"
 )
 
 // Docker file paths
 const (
-	DockerComposeFile = DevStackDir + "/" + DockerComposeFileName
+	DockerComposeFile         = DevStackDir + "/" + DockerComposeFileName
+	DockerComposeOverrideFile = DevStackDir + "/" + DockerComposeOverrideFileName
 )
@@ -2,24 +2,42 @@ package constants
 
 // File names
 const (
-	ConfigFileName           = "dev-stack-config.yml"
-	ConfigFileNameYAML       = "dev-stack-config.yaml"
-	ConfigFileNameHidden     = ".dev-stack-config.yml"
-	ConfigFileNameHiddenYAML = ".dev-stack-config.yaml"
-	DockerComposeFileName    = "docker-compose.yml"
-	EnvGeneratedFileName     = ".env.generated"
-	GitignoreFileName        = ".gitignore"
-	ReadmeFileName           = "README.md"
-	ServiceConfigExtension   = ".yaml"
+	ConfigFileName                = "dev-stack-config.yml"
+	ConfigFileNameYAML            = "dev-stack-config.yaml"
+	ConfigFileNameHidden          = ".dev-stack-config.yml"
+	ConfigFileNameHiddenYAML      = ".dev-stack-config.yaml"
+	DockerComposeFileName         = "docker-compose.yml"
+	DockerComposeOverrideFileName = "docker-compose.override.yml"
+	EnvGeneratedFileName          = ".env.generated"
+	DotEnvFileName                = ".env"
+	GitignoreFileName             = ".gitignore"
+	ReadmeFileName                = "README.md"
+	ArchitectureDocFileName       = "architecture.md"
+	ImageChangelogFileName        = "image-changelog.md"
+	DaemonStateFileName           = "daemon.json"
+	StateFileName                 = "state.json"
+	WorkspaceFileName             = "dev-stack.workspace.yaml"
+	UserConfigFileName            = "config.yaml"
+	UsageMetricsFileName          = "usage-metrics.jsonl"
+	ServiceConfigExtension        = ".yaml"
 )
 
 // Directory names
 const (
-	DevStackDir = "dev-stack"
-	DataDir     = "data"
-	LogsDir     = "logs"
-	TmpDir      = "tmp"
-	ServicesDir = "internal/config/services"
+	DevStackDir       = "dev-stack"
+	DataDir           = "data"
+	LogsDir           = "logs"
+	TmpDir            = "tmp"
+	EnvDir            = "env"
+	DocsDir           = "docs"
+	SeedsDir          = "seeds"
+	SnapshotsDir      = "snapshots"
+	BenchDir          = "bench"
+	ProxyDir          = "proxy"
+	GrafanaDir        = "grafana"
+	CertsDir          = "certs"
+	ServicesDir       = "internal/config/services"
+	CustomServicesDir = "services"
 )
 
 // Template file names
@@ -39,7 +57,14 @@ var GitignoreEntries = []string{
 	"",
 	"# Dev Stack",
 	DevStackDir + "/" + EnvGeneratedFileName,
+	DevStackDir + "/" + StateFileName,
+	DevStackDir + "/" + EnvDir + "/",
 	DevStackDir + "/" + DataDir + "/",
 	DevStackDir + "/" + LogsDir + "/",
 	DevStackDir + "/" + TmpDir + "/",
+	DevStackDir + "/" + SnapshotsDir + "/",
+	DevStackDir + "/" + BenchDir + "/",
+	DevStackDir + "/" + ProxyDir + "/",
+	DevStackDir + "/" + GrafanaDir + "/",
+	DevStackDir + "/" + CertsDir + "/",
 }
@@ -8,6 +8,7 @@ const (
 	VersionFileNameYAML    = ".dev-stack-version.yml"
 	VersionFileNameYAML2   = ".dev-stack-version.yaml"
 	NotificationConfigFile = "notifications.json"
+	UpdateCheckCacheFile   = "update-check.json"
 )
 
 // Version constraint operators
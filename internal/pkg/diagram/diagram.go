@@ -0,0 +1,169 @@
+package diagram
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Supported diagram formats
+const (
+	FormatMermaid  = "mermaid"
+	FormatPlantUML = "plantuml"
+	FormatSVG      = "svg"
+)
+
+// Node represents a single service in the stack topology
+type Node struct {
+	Name      string
+	Image     string
+	Ports     []string
+	Networks  []string
+	DependsOn []string
+}
+
+// Topology describes the services of a stack and how they relate
+type Topology struct {
+	Project string
+	Nodes   []Node
+}
+
+// Formats returns the supported output formats
+func Formats() []string {
+	return []string{FormatMermaid, FormatPlantUML, FormatSVG}
+}
+
+// Render renders the topology in the requested format
+func Render(topology *Topology, format string) (string, error) {
+	switch format {
+	case FormatMermaid:
+		return RenderMermaid(topology), nil
+	case FormatPlantUML:
+		return RenderPlantUML(topology), nil
+	case FormatSVG:
+		return RenderSVG(topology), nil
+	default:
+		return "", fmt.Errorf("unsupported diagram format %q (supported: %s)", format, strings.Join(Formats(), ", "))
+	}
+}
+
+// RenderMermaid renders the topology as a Mermaid flowchart
+func RenderMermaid(topology *Topology) string {
+	var b strings.Builder
+	b.WriteString("flowchart LR\n")
+
+	grouped, ungrouped := topology.groupByNetwork()
+	for _, network := range sortedKeys(grouped) {
+		b.WriteString(fmt.Sprintf("  subgraph %s[\"network: %s\"]\n", nodeID("net_"+network), network))
+		for _, node := range grouped[network] {
+			b.WriteString(fmt.Sprintf("    %s[\"%s\"]\n", nodeID(node.Name), mermaidLabel(node)))
+		}
+		b.WriteString("  end\n")
+	}
+	for _, node := range ungrouped {
+		b.WriteString(fmt.Sprintf("  %s[\"%s\"]\n", nodeID(node.Name), mermaidLabel(node)))
+	}
+
+	for _, edge := range topology.edges() {
+		b.WriteString(fmt.Sprintf("  %s --> %s\n", nodeID(edge[0]), nodeID(edge[1])))
+	}
+
+	return b.String()
+}
+
+// RenderPlantUML renders the topology as a PlantUML component diagram
+func RenderPlantUML(topology *Topology) string {
+	var b strings.Builder
+	b.WriteString("@startuml\n")
+	if topology.Project != "" {
+		b.WriteString(fmt.Sprintf("title %s stack\n", topology.Project))
+	}
+
+	grouped, ungrouped := topology.groupByNetwork()
+	for _, network := range sortedKeys(grouped) {
+		b.WriteString(fmt.Sprintf("node \"%s\" as %s {\n", network, nodeID("net_"+network)))
+		for _, node := range grouped[network] {
+			b.WriteString(fmt.Sprintf("  component \"%s\" as %s\n", plantUMLLabel(node), nodeID(node.Name)))
+		}
+		b.WriteString("}\n")
+	}
+	for _, node := range ungrouped {
+		b.WriteString(fmt.Sprintf("component \"%s\" as %s\n", plantUMLLabel(node), nodeID(node.Name)))
+	}
+
+	for _, edge := range topology.edges() {
+		b.WriteString(fmt.Sprintf("%s --> %s\n", nodeID(edge[0]), nodeID(edge[1])))
+	}
+
+	b.WriteString("@enduml\n")
+	return b.String()
+}
+
+// groupByNetwork groups nodes by their first network; nodes without a
+// network are returned separately
+func (t *Topology) groupByNetwork() (map[string][]Node, []Node) {
+	grouped := make(map[string][]Node)
+	var ungrouped []Node
+	for _, node := range t.Nodes {
+		if len(node.Networks) == 0 {
+			ungrouped = append(ungrouped, node)
+			continue
+		}
+		grouped[node.Networks[0]] = append(grouped[node.Networks[0]], node)
+	}
+	return grouped, ungrouped
+}
+
+// edges returns dependency edges between nodes present in the topology
+func (t *Topology) edges() [][2]string {
+	present := make(map[string]bool, len(t.Nodes))
+	for _, node := range t.Nodes {
+		present[node.Name] = true
+	}
+
+	var edges [][2]string
+	for _, node := range t.Nodes {
+		for _, dep := range node.DependsOn {
+			if present[dep] {
+				edges = append(edges, [2]string{node.Name, dep})
+			}
+		}
+	}
+	return edges
+}
+
+// nodeID converts a name into an identifier accepted by both Mermaid and PlantUML
+func nodeID(name string) string {
+	return strings.NewReplacer("-", "_", ".", "_", " ", "_").Replace(name)
+}
+
+func mermaidLabel(node Node) string {
+	parts := []string{node.Name}
+	if node.Image != "" {
+		parts = append(parts, node.Image)
+	}
+	if len(node.Ports) > 0 {
+		parts = append(parts, ":"+strings.Join(node.Ports, ", :"))
+	}
+	return strings.Join(parts, "<br/>")
+}
+
+func plantUMLLabel(node Node) string {
+	parts := []string{node.Name}
+	if node.Image != "" {
+		parts = append(parts, node.Image)
+	}
+	if len(node.Ports) > 0 {
+		parts = append(parts, ":"+strings.Join(node.Ports, ", :"))
+	}
+	return strings.Join(parts, "\\n")
+}
+
+func sortedKeys(m map[string][]Node) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,60 @@
+package diagram
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testTopology() *Topology {
+	return &Topology{
+		Project: "demo",
+		Nodes: []Node{
+			{Name: "zookeeper", Image: "confluentinc/cp-zookeeper", Ports: []string{"2181"}, Networks: []string{"dev-stack"}},
+			{Name: "kafka-broker", Ports: []string{"9092"}, Networks: []string{"dev-stack"}, DependsOn: []string{"zookeeper"}},
+			{Name: "tool", DependsOn: []string{"missing"}},
+		},
+	}
+}
+
+func TestRenderMermaid(t *testing.T) {
+	out := RenderMermaid(testTopology())
+
+	assert.True(t, strings.HasPrefix(out, "flowchart LR\n"))
+	assert.Contains(t, out, `subgraph net_dev_stack["network: dev-stack"]`)
+	assert.Contains(t, out, `zookeeper["zookeeper<br/>confluentinc/cp-zookeeper<br/>:2181"]`)
+	assert.Contains(t, out, "kafka_broker --> zookeeper")
+	assert.NotContains(t, out, "missing", "edges to services outside the stack are dropped")
+}
+
+func TestRenderPlantUML(t *testing.T) {
+	out := RenderPlantUML(testTopology())
+
+	assert.True(t, strings.HasPrefix(out, "@startuml\n"))
+	assert.True(t, strings.HasSuffix(out, "@enduml\n"))
+	assert.Contains(t, out, "title demo stack")
+	assert.Contains(t, out, "kafka_broker --> zookeeper")
+}
+
+func TestRenderSVG(t *testing.T) {
+	out := RenderSVG(testTopology())
+
+	assert.True(t, strings.HasPrefix(out, "<svg "))
+	assert.Equal(t, 3, strings.Count(out, "<rect "))
+	assert.Equal(t, 1, strings.Count(out, "<line "))
+}
+
+func TestLayers(t *testing.T) {
+	layers := testTopology().layers()
+	require.Len(t, layers, 2)
+	assert.ElementsMatch(t, []string{"zookeeper", "tool"}, layers[0])
+	assert.Equal(t, []string{"kafka-broker"}, layers[1])
+}
+
+func TestRender_UnsupportedFormat(t *testing.T) {
+	_, err := Render(testTopology(), "graphviz")
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "unsupported diagram format")
+}
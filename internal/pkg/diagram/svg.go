@@ -0,0 +1,123 @@
+package diagram
+
+import (
+	"fmt"
+	"html"
+	"strings"
+)
+
+// SVG layout dimensions
+const (
+	svgBoxWidth  = 200
+	svgBoxHeight = 64
+	svgColumnGap = 80
+	svgRowGap    = 32
+	svgMargin    = 24
+	svgTitleSize = 32
+)
+
+// RenderSVG renders the topology as a standalone SVG image. Services are laid
+// out in columns by dependency depth so arrows always point right-to-left
+// towards the services they depend on.
+func RenderSVG(topology *Topology) string {
+	columns := topology.layers()
+
+	rows := 0
+	for _, column := range columns {
+		if len(column) > rows {
+			rows = len(column)
+		}
+	}
+
+	width := svgMargin*2 + len(columns)*svgBoxWidth + max(len(columns)-1, 0)*svgColumnGap
+	height := svgMargin*2 + svgTitleSize + rows*svgBoxHeight + max(rows-1, 0)*svgRowGap
+
+	positions := make(map[string][2]int)
+	for col, column := range columns {
+		for row, name := range column {
+			x := svgMargin + col*(svgBoxWidth+svgColumnGap)
+			y := svgMargin + svgTitleSize + row*(svgBoxHeight+svgRowGap)
+			positions[name] = [2]int{x, y}
+		}
+	}
+
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf(`<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" font-family="sans-serif">`+"\n", width, height, width, height))
+	b.WriteString(`  <defs><marker id="arrow" markerWidth="10" markerHeight="10" refX="9" refY="3" orient="auto"><path d="M0,0 L0,6 L9,3 z" fill="#555"/></marker></defs>` + "\n")
+	if topology.Project != "" {
+		b.WriteString(fmt.Sprintf(`  <text x="%d" y="%d" font-size="16" font-weight="bold">%s stack</text>`+"\n", svgMargin, svgMargin+16, html.EscapeString(topology.Project)))
+	}
+
+	for _, edge := range topology.edges() {
+		from, to := positions[edge[0]], positions[edge[1]]
+		b.WriteString(fmt.Sprintf(`  <line x1="%d" y1="%d" x2="%d" y2="%d" stroke="#555" marker-end="url(#arrow)"/>`+"\n",
+			from[0], from[1]+svgBoxHeight/2, to[0]+svgBoxWidth, to[1]+svgBoxHeight/2))
+	}
+
+	for _, node := range topology.Nodes {
+		pos := positions[node.Name]
+		b.WriteString(fmt.Sprintf(`  <rect x="%d" y="%d" width="%d" height="%d" rx="6" fill="#eef4ff" stroke="#3366cc"/>`+"\n",
+			pos[0], pos[1], svgBoxWidth, svgBoxHeight))
+		b.WriteString(fmt.Sprintf(`  <text x="%d" y="%d" font-size="14" font-weight="bold">%s</text>`+"\n",
+			pos[0]+10, pos[1]+20, html.EscapeString(node.Name)))
+
+		var details []string
+		if len(node.Ports) > 0 {
+			details = append(details, ":"+strings.Join(node.Ports, ", :"))
+		}
+		if len(node.Networks) > 0 {
+			details = append(details, strings.Join(node.Networks, ", "))
+		}
+		if node.Image != "" {
+			b.WriteString(fmt.Sprintf(`  <text x="%d" y="%d" font-size="11" fill="#333">%s</text>`+"\n",
+				pos[0]+10, pos[1]+38, html.EscapeString(node.Image)))
+		}
+		if len(details) > 0 {
+			b.WriteString(fmt.Sprintf(`  <text x="%d" y="%d" font-size="11" fill="#666">%s</text>`+"\n",
+				pos[0]+10, pos[1]+54, html.EscapeString(strings.Join(details, " · "))))
+		}
+	}
+
+	b.WriteString("</svg>\n")
+	return b.String()
+}
+
+// layers groups node names into columns by dependency depth; services with
+// no dependencies inside the topology sit in the leftmost column
+func (t *Topology) layers() [][]string {
+	deps := make(map[string][]string, len(t.Nodes))
+	for _, edge := range t.edges() {
+		deps[edge[0]] = append(deps[edge[0]], edge[1])
+	}
+
+	depth := make(map[string]int, len(t.Nodes))
+	visiting := make(map[string]bool)
+	var visit func(name string) int
+	visit = func(name string) int {
+		if d, ok := depth[name]; ok {
+			return d
+		}
+		if visiting[name] {
+			return 0
+		}
+		visiting[name] = true
+		d := 0
+		for _, dep := range deps[name] {
+			d = max(d, visit(dep)+1)
+		}
+		visiting[name] = false
+		depth[name] = d
+		return d
+	}
+
+	maxDepth := 0
+	for _, node := range t.Nodes {
+		maxDepth = max(maxDepth, visit(node.Name))
+	}
+
+	columns := make([][]string, maxDepth+1)
+	for _, node := range t.Nodes {
+		columns[depth[node.Name]] = append(columns[depth[node.Name]], node.Name)
+	}
+	return columns
+}
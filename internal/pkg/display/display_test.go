@@ -5,6 +5,8 @@ import (
 	"strings"
 	"testing"
 	"time"
+
+	"github.com/isaacgarza/dev-stack/internal/pkg/render"
 )
 
 func TestTableFormatter(t *testing.T) {
@@ -48,6 +50,75 @@ func TestTableFormatter(t *testing.T) {
 	if !strings.Contains(output, "Resource Summary") {
 		t.Error("Output should contain resource summary")
 	}
+	if strings.Contains(output, "Paused:") {
+		t.Error("Output should leave out the paused count without paused services")
+	}
+}
+
+func TestTableFormatter_Paused(t *testing.T) {
+	var buf bytes.Buffer
+	formatter := NewTableFormatter(&buf)
+
+	services := []ServiceStatus{
+		{Name: "redis", State: "running", Health: "healthy"},
+		{Name: "kafka", State: "paused", Health: "none"},
+	}
+	if err := formatter.FormatStatus(services, StatusOptions{}); err != nil {
+		t.Fatalf("FormatStatus failed: %v", err)
+	}
+
+	output := buf.String()
+	if !strings.Contains(output, "⏸️") {
+		t.Error("Output should mark the paused service")
+	}
+	if !strings.Contains(output, "Running: 1") || !strings.Contains(output, "Paused: 1") {
+		t.Errorf("Output should count running and paused services apart, got:\n%s", output)
+	}
+}
+
+func TestTableFormatter_Changes(t *testing.T) {
+	var buf bytes.Buffer
+	formatter := NewTableFormatter(&buf)
+
+	services := []ServiceStatus{
+		{Name: "redis", State: "running", Health: "healthy"},
+		{Name: "postgres", State: "running", Health: "starting"},
+	}
+
+	options := StatusOptions{Changes: map[string]string{"redis": "starting → healthy"}}
+	if err := formatter.FormatStatus(services, options); err != nil {
+		t.Errorf("FormatStatus failed: %v", err)
+	}
+
+	output := buf.String()
+	if !strings.Contains(output, "starting → healthy") {
+		t.Error("Output should highlight the redis transition")
+	}
+	if strings.Count(output, "◀") != 1 {
+		t.Error("Only changed services should be highlighted")
+	}
+}
+
+func TestTableFormatter_Replicas(t *testing.T) {
+	var buf bytes.Buffer
+	formatter := NewTableFormatter(&buf)
+
+	services := []ServiceStatus{
+		{Name: "redis", State: "running", Health: "healthy", Replicas: 3, Running: 2},
+		{Name: "postgres", State: "running", Health: "healthy", Replicas: 1, Running: 1},
+	}
+
+	if err := formatter.FormatStatus(services, StatusOptions{}); err != nil {
+		t.Errorf("FormatStatus failed: %v", err)
+	}
+
+	output := buf.String()
+	if !strings.Contains(output, "redis (2/3)") {
+		t.Error("Output should show the redis replica count")
+	}
+	if strings.Contains(output, "postgres (") {
+		t.Error("Unscaled services should not show a replica count")
+	}
 }
 
 func TestJSONFormatter(t *testing.T) {
@@ -208,3 +279,91 @@ func TestValidationFormatting(t *testing.T) {
 		t.Error("Output should contain summary")
 	}
 }
+
+func TestTableFormatter_CrashLoop(t *testing.T) {
+	var buf bytes.Buffer
+	formatter := NewTableFormatter(&buf)
+
+	services := []ServiceStatus{
+		{Name: "api", State: "crash-looping", Health: "none", Restarts: 6, ExitCode: 1},
+		{Name: "postgres", State: "running", Health: "healthy", Restarts: 2},
+	}
+
+	if err := formatter.FormatStatus(services, StatusOptions{}); err != nil {
+		t.Errorf("FormatStatus failed: %v", err)
+	}
+
+	output := buf.String()
+	if !strings.Contains(output, "6 restarts, last exit code 1") {
+		t.Error("Output should show the restarts and exit code of the crash loop")
+	}
+	if strings.Count(output, "restarts, last exit code") != 1 {
+		t.Error("Only crash-looping services should show their restarts")
+	}
+}
+
+func TestTableFormatter_Emulated(t *testing.T) {
+	var buf bytes.Buffer
+	formatter := NewTableFormatter(&buf)
+
+	services := []ServiceStatus{
+		{Name: "mysql", State: "running", Health: "healthy", Emulated: "amd64"},
+		{Name: "redis", State: "running", Health: "healthy"},
+	}
+
+	if err := formatter.FormatStatus(services, StatusOptions{Compact: true}); err != nil {
+		t.Errorf("FormatStatus failed: %v", err)
+	}
+
+	output := buf.String()
+	if !strings.Contains(output, "amd64 (emulated)") {
+		t.Error("Output should show the architecture of emulated services")
+	}
+	if strings.Count(output, "(emulated)") != 1 {
+		t.Error("Only emulated services should be marked")
+	}
+}
+
+func TestRenderStatus(t *testing.T) {
+	services := []ServiceStatus{
+		{Name: "kafka", State: "crash-looping", Health: "unhealthy", Ports: []string{"9092:9092", "9093:9093"}, Restarts: 6, ExitCode: 1},
+	}
+
+	var buf bytes.Buffer
+	if err := RenderStatus(&buf, services, StatusOptions{}, render.Options{Format: render.FormatTable}); err != nil {
+		t.Fatalf("RenderStatus failed: %v", err)
+	}
+	table := buf.String()
+	if !strings.Contains(table, "9092:9092,...") || strings.Contains(table, "EXIT CODE") {
+		t.Errorf("Tables should shorten the ports and leave out the wide columns:\n%s", table)
+	}
+
+	buf.Reset()
+	if err := RenderStatus(&buf, services, StatusOptions{}, render.Options{Format: render.FormatWide}); err != nil {
+		t.Fatalf("RenderStatus failed: %v", err)
+	}
+	wide := buf.String()
+	if !strings.Contains(wide, "9092:9092,9093:9093") || !strings.Contains(wide, "EXIT CODE") {
+		t.Errorf("Wide tables should show all ports and the wide columns:\n%s", wide)
+	}
+
+	buf.Reset()
+	if err := RenderStatus(&buf, services, StatusOptions{Quiet: true}, render.Options{Columns: []string{"service", "restarts"}}); err != nil {
+		t.Fatalf("RenderStatus failed: %v", err)
+	}
+	if buf.String() != "SERVICE  RESTARTS\nkafka    6\n" {
+		t.Errorf("Unexpected columns:\n%s", buf.String())
+	}
+
+	if err := RenderStatus(&buf, services, StatusOptions{}, render.Options{Columns: []string{"cpu"}}); err == nil {
+		t.Error("Unknown columns should be an error")
+	}
+
+	buf.Reset()
+	if err := RenderStatus(&buf, services, StatusOptions{}, render.Options{Format: render.FormatJSON}); err != nil {
+		t.Fatalf("RenderStatus failed: %v", err)
+	}
+	if !strings.Contains(buf.String(), `"exit_code": 1`) || !strings.Contains(buf.String(), `"summary"`) {
+		t.Errorf("JSON should keep the shape of the status formatter:\n%s", buf.String())
+	}
+}
@@ -34,6 +34,16 @@ type ServiceStatus struct {
 	CreatedAt time.Time     `json:"created_at" yaml:"created_at"`
 	UpdatedAt time.Time     `json:"updated_at" yaml:"updated_at"`
 	Uptime    time.Duration `json:"uptime" yaml:"uptime"`
+	// Replicas and Running count the containers of a scaled service
+	Replicas int `json:"replicas,omitempty" yaml:"replicas,omitempty"`
+	Running  int `json:"running,omitempty" yaml:"running,omitempty"`
+	// Restarts and ExitCode report how often Docker restarted the service
+	// and how its last run ended
+	Restarts int `json:"restarts,omitempty" yaml:"restarts,omitempty"`
+	ExitCode int `json:"exit_code,omitempty" yaml:"exit_code,omitempty"`
+	// Emulated is the architecture of the service's image when it runs
+	// under emulation on the Docker host
+	Emulated string `json:"emulated,omitempty" yaml:"emulated,omitempty"`
 }
 
 type ValidationResult struct {
@@ -104,6 +114,9 @@ type StatusOptions struct {
 	Quiet   bool
 	Compact bool
 	NoLogs  bool
+	// Changes maps service names to a description of the state or health
+	// transition since the previous refresh, for highlighting in watch mode
+	Changes map[string]string
 }
 
 type ValidationOptions struct {
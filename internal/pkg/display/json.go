@@ -17,16 +17,7 @@ func NewJSONFormatter(writer io.Writer) *JSONFormatter {
 
 // FormatStatus formats service status as JSON
 func (f *JSONFormatter) FormatStatus(services []ServiceStatus, options StatusOptions) error {
-	output := map[string]interface{}{
-		"services": services,
-		"summary": map[string]interface{}{
-			"total":   len(services),
-			"running": f.countByState(services, "running"),
-			"healthy": f.countByHealth(services, "healthy"),
-		},
-	}
-
-	return f.writeJSON(output)
+	return f.writeJSON(statusData(services, options.Changes))
 }
 
 // FormatValidation formats validation results as JSON
@@ -50,23 +41,3 @@ func (f *JSONFormatter) writeJSON(data interface{}) error {
 	encoder.SetIndent("", "  ")
 	return encoder.Encode(data)
 }
-
-func (f *JSONFormatter) countByState(services []ServiceStatus, state string) int {
-	count := 0
-	for _, service := range services {
-		if service.State == state {
-			count++
-		}
-	}
-	return count
-}
-
-func (f *JSONFormatter) countByHealth(services []ServiceStatus, health string) int {
-	count := 0
-	for _, service := range services {
-		if service.Health == health {
-			count++
-		}
-	}
-	return count
-}
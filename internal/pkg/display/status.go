@@ -0,0 +1,227 @@
+package display
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/isaacgarza/dev-stack/internal/pkg/render"
+	"github.com/isaacgarza/dev-stack/internal/pkg/ui"
+)
+
+// maxPortsWidth is how much of the port list the status table shows
+// before shortening it; -o wide shows all of it
+const maxPortsWidth = 10
+
+// compactStatusColumns are the columns of the compact status table
+var compactStatusColumns = []string{"service", "state", "health", "notes"}
+
+// RenderStatus prints the status of services in the format of
+// renderOptions. Tables end with the resource summary unless quiet.
+func RenderStatus(w io.Writer, services []ServiceStatus, options StatusOptions, renderOptions render.Options) error {
+	table := StatusTable(services, options, renderOptions.Format == render.FormatWide)
+	if renderOptions.Format.Structured() {
+		return render.Render(w, table, renderOptions)
+	}
+	if len(services) == 0 {
+		_, err := fmt.Fprintln(w, table.Empty)
+		return err
+	}
+
+	if options.Compact && len(renderOptions.Columns) == 0 {
+		renderOptions.Columns = compactStatusColumns
+	}
+	if err := render.Render(w, table, renderOptions); err != nil {
+		return err
+	}
+	if !options.Quiet && !options.Compact {
+		writeResourceSummary(w, services)
+	}
+	return nil
+}
+
+// StatusTable returns the status of services as rows of the renderer.
+// Ports are shortened unless wide.
+func StatusTable(services []ServiceStatus, options StatusOptions, wide bool) render.Table[ServiceStatus] {
+	return render.Table[ServiceStatus]{
+		Columns: []render.Column[ServiceStatus]{
+			{Name: "SERVICE", Value: formatName},
+			{Name: "STATE", Value: func(s ServiceStatus) string { return stateIcon(s.State) + " " + s.State }, Style: styleState},
+			{Name: "HEALTH", Value: func(s ServiceStatus) string { return healthIcon(s.Health) + " " + s.Health }, Style: styleHealth},
+			{Name: "UPTIME", Value: func(s ServiceStatus) string { return formatDuration(s.Uptime) }},
+			{Name: "PORTS", Value: func(s ServiceStatus) string { return formatPorts(s.Ports, wide) }},
+			{Name: "UPDATED", Value: func(s ServiceStatus) string { return s.UpdatedAt.Format("15:04:05") }},
+			{Name: "RESTARTS", Wide: true, Value: func(s ServiceStatus) string { return fmt.Sprint(s.Restarts) }},
+			{Name: "EXIT CODE", Wide: true, Value: func(s ServiceStatus) string { return fmt.Sprint(s.ExitCode) }},
+			{Name: "CREATED", Wide: true, Value: func(s ServiceStatus) string { return formatTime(s.CreatedAt) }},
+			{
+				Name:  "NOTES",
+				Value: func(s ServiceStatus) string { return formatNotes(s, options.Changes[s.Name]) },
+				Style: func(s ServiceStatus, value string) string { return ui.WarningText.Render(value) },
+			},
+		},
+		Rows:  services,
+		Data:  statusData(services, options.Changes),
+		Empty: "No services found",
+	}
+}
+
+// statusData is the status of services as JSON and YAML print it
+func statusData(services []ServiceStatus, changes map[string]string) map[string]interface{} {
+	if services == nil {
+		services = []ServiceStatus{}
+	}
+	output := map[string]interface{}{
+		"services": services,
+		"summary": map[string]interface{}{
+			"total":   len(services),
+			"running": countStatuses(services, func(s ServiceStatus) bool { return s.State == "running" }),
+			"healthy": countStatuses(services, func(s ServiceStatus) bool { return s.Health == "healthy" }),
+		},
+	}
+	if len(changes) > 0 {
+		output["changes"] = changes
+	}
+	return output
+}
+
+func countStatuses(services []ServiceStatus, match func(ServiceStatus) bool) int {
+	count := 0
+	for _, service := range services {
+		if match(service) {
+			count++
+		}
+	}
+	return count
+}
+
+func writeResourceSummary(w io.Writer, services []ServiceStatus) {
+	running := countStatuses(services, func(s ServiceStatus) bool { return s.State == "running" })
+	paused := countStatuses(services, func(s ServiceStatus) bool { return s.State == "paused" })
+	healthy := countStatuses(services, func(s ServiceStatus) bool { return s.Health == "healthy" })
+
+	//nolint:errcheck
+	fmt.Fprintln(w)
+	//nolint:errcheck
+	fmt.Fprintln(w, "Resource Summary:")
+	//nolint:errcheck
+	fmt.Fprintf(w, "  Total Services: %d\n", len(services))
+	//nolint:errcheck
+	fmt.Fprintf(w, "  Running: %d\n", running)
+	if paused > 0 {
+		//nolint:errcheck
+		fmt.Fprintf(w, "  Paused: %d\n", paused)
+	}
+	//nolint:errcheck
+	fmt.Fprintf(w, "  Healthy: %d\n", healthy)
+}
+
+// formatName renders the service name with a running/total replica count
+// for scaled services
+func formatName(service ServiceStatus) string {
+	if service.Replicas <= 1 {
+		return service.Name
+	}
+	return fmt.Sprintf("%s (%d/%d)", service.Name, service.Running, service.Replicas)
+}
+
+// formatPorts renders the port mappings, shortened unless wide
+func formatPorts(ports []string, wide bool) string {
+	joined := strings.Join(ports, ",")
+	if !wide && len(joined) > maxPortsWidth {
+		return joined[:maxPortsWidth] + "..."
+	}
+	return joined
+}
+
+// formatNotes renders what the row of a service needs to point out: a
+// crash loop, emulation and, in watch mode, the change since the last
+// refresh
+func formatNotes(service ServiceStatus, change string) string {
+	var notes []string
+	if service.State == "crash-looping" {
+		notes = append(notes, fmt.Sprintf("%d restarts, last exit code %d", service.Restarts, service.ExitCode))
+	}
+	if service.Emulated != "" {
+		notes = append(notes, "🐢 "+service.Emulated+" (emulated)")
+	}
+	if change != "" {
+		notes = append(notes, "◀ "+change)
+	}
+	return strings.Join(notes, "  ")
+}
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Local().Format("2006-01-02 15:04")
+}
+
+func styleState(service ServiceStatus, value string) string {
+	switch service.State {
+	case "running":
+		return ui.SuccessText.Render(value)
+	case "stopped", "exited", "crash-looping":
+		return ui.ErrorText.Render(value)
+	case "starting", "restarting", "paused":
+		return ui.WarningText.Render(value)
+	}
+	return ui.MutedText.Render(value)
+}
+
+func styleHealth(service ServiceStatus, value string) string {
+	switch service.Health {
+	case "healthy":
+		return ui.SuccessText.Render(value)
+	case "unhealthy":
+		return ui.ErrorText.Render(value)
+	case "starting":
+		return ui.WarningText.Render(value)
+	}
+	return ui.MutedText.Render(value)
+}
+
+func stateIcon(state string) string {
+	switch state {
+	case "running":
+		return "🟢"
+	case "stopped", "exited":
+		return "🔴"
+	case "starting":
+		return "🟡"
+	case "restarting", "crash-looping":
+		return "🔁"
+	case "paused":
+		return "⏸️"
+	default:
+		return "⚪"
+	}
+}
+
+func healthIcon(health string) string {
+	switch health {
+	case "healthy":
+		return "✅"
+	case "unhealthy":
+		return "❌"
+	case "starting":
+		return "🟡"
+	default:
+		return "❓"
+	}
+}
+
+func formatDuration(d time.Duration) string {
+	if d < time.Minute {
+		return fmt.Sprintf("%ds", int(d.Seconds()))
+	}
+	if d < time.Hour {
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	}
+	if d < 24*time.Hour {
+		return fmt.Sprintf("%dh", int(d.Hours()))
+	}
+	return fmt.Sprintf("%dd", int(d.Hours()/24))
+}
@@ -4,7 +4,8 @@ import (
 	"fmt"
 	"io"
 	"strings"
-	"time"
+
+	"github.com/isaacgarza/dev-stack/internal/pkg/render"
 )
 
 // TableFormatter implements table-based output formatting
@@ -19,16 +20,7 @@ func NewTableFormatter(writer io.Writer) *TableFormatter {
 
 // FormatStatus formats service status as a table
 func (f *TableFormatter) FormatStatus(services []ServiceStatus, options StatusOptions) error {
-	if len(services) == 0 {
-		//nolint:errcheck
-		fmt.Fprintln(f.writer, "No services found")
-		return nil
-	}
-
-	if options.Compact {
-		return f.formatCompactStatus(services)
-	}
-	return f.formatDetailedStatus(services, options.Quiet)
+	return RenderStatus(f.writer, services, options, render.Options{Format: render.FormatTable})
 }
 
 // FormatValidation formats validation results as a table
@@ -137,7 +129,7 @@ func (f *TableFormatter) FormatHealth(report HealthReport, options HealthOptions
 	fmt.Fprintln(f.writer, strings.Repeat("-", 90))
 
 	for _, check := range report.Checks {
-		icon := f.getHealthIcon(check.Status)
+		icon := healthIcon(check.Status)
 		//nolint:errcheck
 		fmt.Fprintf(f.writer, "%-25s %-10s %-15s %-40s\n",
 			check.Name, icon+" "+check.Status, check.Category, check.Message)
@@ -152,54 +144,6 @@ func (f *TableFormatter) FormatHealth(report HealthReport, options HealthOptions
 }
 
 // Helper methods
-func (f *TableFormatter) formatCompactStatus(services []ServiceStatus) error {
-	//nolint:errcheck
-	fmt.Fprintf(f.writer, "%-20s %-10s %-12s\n", "SERVICE", "STATE", "HEALTH")
-	//nolint:errcheck
-	fmt.Fprintln(f.writer, strings.Repeat("-", 42))
-
-	for _, service := range services {
-		stateIcon := f.getStateIcon(service.State)
-		healthIcon := f.getHealthIcon(service.Health)
-
-		//nolint:errcheck
-		fmt.Fprintf(f.writer, "%-20s %-10s %-12s\n",
-			service.Name, stateIcon+" "+service.State, healthIcon+" "+service.Health)
-	}
-
-	return nil
-}
-
-func (f *TableFormatter) formatDetailedStatus(services []ServiceStatus, quiet bool) error {
-	//nolint:errcheck
-	fmt.Fprintf(f.writer, "%-15s %-10s %-10s %-8s %-12s %-10s\n",
-		"SERVICE", "STATE", "HEALTH", "UPTIME", "PORTS", "UPDATED")
-	//nolint:errcheck
-	fmt.Fprintln(f.writer, strings.Repeat("-", 75))
-
-	for _, service := range services {
-		stateIcon := f.getStateIcon(service.State)
-		healthIcon := f.getHealthIcon(service.Health)
-		uptime := f.formatDuration(service.Uptime)
-		ports := strings.Join(service.Ports, ",")
-		if len(ports) > 10 {
-			ports = ports[:10] + "..."
-		}
-		updated := service.UpdatedAt.Format("15:04:05")
-
-		//nolint:errcheck
-		fmt.Fprintf(f.writer, "%-15s %-10s %-10s %-8s %-12s %-10s\n",
-			service.Name, stateIcon+" "+service.State, healthIcon+" "+service.Health,
-			uptime, ports, updated)
-	}
-
-	if !quiet {
-		f.formatResourceSummary(services)
-	}
-
-	return nil
-}
-
 func (f *TableFormatter) formatValidationSummary(summary ValidationSummary) {
 	//nolint:errcheck
 	fmt.Fprintln(f.writer, "Summary:")
@@ -214,69 +158,3 @@ func (f *TableFormatter) formatValidationSummary(summary ValidationSummary) {
 	//nolint:errcheck
 	fmt.Fprintf(f.writer, "  Coverage: %d%%\n", summary.CoveragePercentage)
 }
-
-func (f *TableFormatter) formatResourceSummary(services []ServiceStatus) {
-	running := 0
-	healthy := 0
-
-	for _, service := range services {
-		if service.State == "running" {
-			running++
-		}
-		if service.Health == "healthy" {
-			healthy++
-		}
-	}
-
-	//nolint:errcheck
-	fmt.Fprintln(f.writer)
-	//nolint:errcheck
-	fmt.Fprintln(f.writer, "Resource Summary:")
-	//nolint:errcheck
-	fmt.Fprintf(f.writer, "  Total Services: %d\n", len(services))
-	//nolint:errcheck
-	fmt.Fprintf(f.writer, "  Running: %d\n", running)
-	//nolint:errcheck
-	fmt.Fprintf(f.writer, "  Healthy: %d\n", healthy)
-}
-
-func (f *TableFormatter) getStateIcon(state string) string {
-	switch state {
-	case "running":
-		return "🟢"
-	case "stopped", "exited":
-		return "🔴"
-	case "starting":
-		return "🟡"
-	case "paused":
-		return "⏸️"
-	default:
-		return "⚪"
-	}
-}
-
-func (f *TableFormatter) getHealthIcon(health string) string {
-	switch health {
-	case "healthy":
-		return "✅"
-	case "unhealthy":
-		return "❌"
-	case "starting":
-		return "🟡"
-	default:
-		return "❓"
-	}
-}
-
-func (f *TableFormatter) formatDuration(d time.Duration) string {
-	if d < time.Minute {
-		return fmt.Sprintf("%ds", int(d.Seconds()))
-	}
-	if d < time.Hour {
-		return fmt.Sprintf("%dm", int(d.Minutes()))
-	}
-	if d < 24*time.Hour {
-		return fmt.Sprintf("%dh", int(d.Hours()))
-	}
-	return fmt.Sprintf("%dd", int(d.Hours()/24))
-}
@@ -18,16 +18,7 @@ func NewYAMLFormatter(writer io.Writer) *YAMLFormatter {
 
 // FormatStatus formats service status as YAML
 func (f *YAMLFormatter) FormatStatus(services []ServiceStatus, options StatusOptions) error {
-	output := map[string]interface{}{
-		"services": services,
-		"summary": map[string]interface{}{
-			"total":   len(services),
-			"running": f.countByState(services, "running"),
-			"healthy": f.countByHealth(services, "healthy"),
-		},
-	}
-
-	return f.writeYAML(output)
+	return f.writeYAML(statusData(services, options.Changes))
 }
 
 // FormatValidation formats validation results as YAML
@@ -53,23 +44,3 @@ func (f *YAMLFormatter) writeYAML(data interface{}) error {
 	}()
 	return encoder.Encode(data)
 }
-
-func (f *YAMLFormatter) countByState(services []ServiceStatus, state string) int {
-	count := 0
-	for _, service := range services {
-		if service.State == state {
-			count++
-		}
-	}
-	return count
-}
-
-func (f *YAMLFormatter) countByHealth(services []ServiceStatus, health string) int {
-	count := 0
-	for _, service := range services {
-		if service.Health == health {
-			count++
-		}
-	}
-	return count
-}
@@ -0,0 +1,191 @@
+package export
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"slices"
+
+	"github.com/isaacgarza/dev-stack/internal/pkg/compose"
+)
+
+// Files of a dev container export, in the .devcontainer directory
+const (
+	DevcontainerDir         = ".devcontainer"
+	DevcontainerFile        = "devcontainer.json"
+	DevcontainerComposeFile = "docker-compose.devcontainer.yml"
+)
+
+// DefaultDevcontainerImage is the image of the dev container itself
+const DefaultDevcontainerImage = "mcr.microsoft.com/devcontainers/base:ubuntu"
+
+// devcontainerService is the compose service of the dev container
+const devcontainerService = "devcontainer"
+
+// dockerOutsideOfDocker is the dev container feature giving the container
+// the Docker daemon of the host, so dev-stack runs inside it
+const dockerOutsideOfDocker = "ghcr.io/devcontainers/features/docker-outside-of-docker:1"
+
+// installCommand installs dev-stack in the dev container when it is missing
+const installCommand = "command -v dev-stack >/dev/null || curl -fsSL https://raw.githubusercontent.com/isaacgarza/dev-stack/main/install.sh | bash"
+
+// DevcontainerSelection is what a dev container was exported from, kept in
+// the dev-stack customizations of devcontainer.json so the export can be
+// refreshed when the compose files change
+type DevcontainerSelection struct {
+	// Services are the services named when exporting; none means those of
+	// the profile or the enabled ones
+	Services []string `json:"services,omitempty"`
+	Profile  string   `json:"profile,omitempty"`
+	Image    string   `json:"image"`
+}
+
+// DevcontainerOptions configures a dev container export
+type DevcontainerOptions struct {
+	Project   string
+	Selection DevcontainerSelection
+	// ComposeFiles are the compose files of the project, relative to the
+	// project root, that the dev container runs the services from
+	ComposeFiles []string
+	// Seed runs dev-stack seed once the container is created
+	Seed bool
+}
+
+type devcontainerConfig struct {
+	Name              string                    `json:"name"`
+	DockerComposeFile []string                  `json:"dockerComposeFile"`
+	Service           string                    `json:"service"`
+	RunServices       []string                  `json:"runServices"`
+	WorkspaceFolder   string                    `json:"workspaceFolder"`
+	ShutdownAction    string                    `json:"shutdownAction"`
+	Features          map[string]map[string]any `json:"features"`
+	ForwardPorts      []string                  `json:"forwardPorts,omitempty"`
+	PortsAttributes   map[string]portAttributes `json:"portsAttributes,omitempty"`
+	PostCreateCommand string                    `json:"postCreateCommand"`
+	Customizations    struct {
+		DevStack *DevcontainerSelection `json:"dev-stack"`
+	} `json:"customizations"`
+}
+
+type portAttributes struct {
+	Label string `json:"label"`
+}
+
+type devcontainerCompose struct {
+	Name     string                        `yaml:"name"`
+	Services map[string]devcontainerDetail `yaml:"services"`
+}
+
+type devcontainerDetail struct {
+	Image     string                       `yaml:"image"`
+	Command   string                       `yaml:"command"`
+	Volumes   []string                     `yaml:"volumes"`
+	Networks  []string                     `yaml:"networks"`
+	DependsOn map[string]map[string]string `yaml:"depends_on"`
+}
+
+// Devcontainer renders a devcontainer.json, for VS Code Dev Containers and
+// GitHub Codespaces, and the compose file of the dev container, keyed by
+// their names in the .devcontainer directory. The dev container runs on
+// the compose project of the stack, next to the services it depends on,
+// joins their networks and waits for them to be healthy. Their ports are
+// forwarded, and dev-stack is installed in the container on creation.
+func Devcontainer(definitions []compose.Definition, options DevcontainerOptions) (map[string][]byte, error) {
+	if options.Selection.Image == "" {
+		options.Selection.Image = DefaultDevcontainerImage
+	}
+	workspace := path.Join("/workspaces", options.Project)
+
+	config := devcontainerConfig{
+		Name:            options.Project,
+		Service:         devcontainerService,
+		RunServices:     []string{devcontainerService},
+		WorkspaceFolder: workspace,
+		ShutdownAction:  "stopCompose",
+		Features:        map[string]map[string]any{dockerOutsideOfDocker: {}},
+		PortsAttributes: make(map[string]portAttributes),
+	}
+	config.Customizations.DevStack = &options.Selection
+	for _, file := range options.ComposeFiles {
+		config.DockerComposeFile = append(config.DockerComposeFile, path.Join("..", file))
+	}
+	config.DockerComposeFile = append(config.DockerComposeFile, DevcontainerComposeFile)
+	config.PostCreateCommand = installCommand
+	if options.Seed {
+		config.PostCreateCommand += " && dev-stack seed"
+	}
+
+	// Relative paths resolve against the directory of the first compose
+	// file, dev-stack, so .. is the project root
+	detail := devcontainerDetail{
+		Image:     options.Selection.Image,
+		Command:   "sleep infinity",
+		Volumes:   []string{"..:" + workspace + ":cached"},
+		DependsOn: make(map[string]map[string]string),
+	}
+	for _, def := range definitions {
+		config.RunServices = append(config.RunServices, def.Name)
+		condition := "service_started"
+		if def.Healthcheck != nil {
+			condition = "service_healthy"
+		}
+		detail.DependsOn[def.Name] = map[string]string{"condition": condition}
+		for _, network := range def.Networks {
+			if !slices.Contains(detail.Networks, network) {
+				detail.Networks = append(detail.Networks, network)
+			}
+		}
+		for _, port := range def.Ports {
+			forward := fmt.Sprintf("%s:%d", def.Name, port.Target)
+			if port.Protocol == "tcp" && !slices.Contains(config.ForwardPorts, forward) {
+				config.ForwardPorts = append(config.ForwardPorts, forward)
+				config.PortsAttributes[forward] = portAttributes{Label: def.Name}
+			}
+		}
+	}
+	slices.Sort(detail.Networks)
+
+	var devcontainerJSON bytes.Buffer
+	encoder := json.NewEncoder(&devcontainerJSON)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(config); err != nil {
+		return nil, err
+	}
+	composeFile, err := encodeObjects([]devcontainerCompose{{
+		Name:     options.Project,
+		Services: map[string]devcontainerDetail{devcontainerService: detail},
+	}})
+	if err != nil {
+		return nil, err
+	}
+	return map[string][]byte{
+		DevcontainerFile:        devcontainerJSON.Bytes(),
+		DevcontainerComposeFile: composeFile,
+	}, nil
+}
+
+// ErrNotExported is returned by ReadDevcontainerSelection for a
+// devcontainer.json that dev-stack didn't write
+var ErrNotExported = errors.New("devcontainer.json wasn't exported by dev-stack")
+
+// ReadDevcontainerSelection returns what the devcontainer.json file was
+// exported from
+func ReadDevcontainerSelection(file string) (*DevcontainerSelection, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	var config devcontainerConfig
+	if err := json.Unmarshal(data, &config); err != nil {
+		// devcontainer.json allows comments, which dev-stack never writes
+		return nil, ErrNotExported
+	}
+	if config.Customizations.DevStack == nil {
+		return nil, ErrNotExported
+	}
+	return config.Customizations.DevStack, nil
+}
@@ -0,0 +1,102 @@
+package export
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/isaacgarza/dev-stack/internal/pkg/compose"
+)
+
+func TestDevcontainer(t *testing.T) {
+	definitions := []compose.Definition{
+		{
+			Name:        "postgres",
+			Image:       "postgres:15",
+			Ports:       []compose.PortSpec{{Published: 5432, Target: 5432, Protocol: "tcp"}},
+			Networks:    []string{"dev-stack"},
+			Healthcheck: &compose.Healthcheck{Test: []string{"CMD", "pg_isready"}},
+		},
+		{
+			Name:     "dns",
+			Image:    "coredns/coredns",
+			Ports:    []compose.PortSpec{{Published: 53, Target: 53, Protocol: "udp"}},
+			Networks: []string{"backend", "dev-stack"},
+		},
+	}
+
+	files, err := Devcontainer(definitions, DevcontainerOptions{
+		Project:      "demo",
+		Selection:    DevcontainerSelection{Services: []string{"postgres", "dns"}},
+		ComposeFiles: []string{"dev-stack/docker-compose.yml", "dev-stack/docker-compose.override.yml"},
+		Seed:         true,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, `{
+  "name": "demo",
+  "dockerComposeFile": [
+    "../dev-stack/docker-compose.yml",
+    "../dev-stack/docker-compose.override.yml",
+    "docker-compose.devcontainer.yml"
+  ],
+  "service": "devcontainer",
+  "runServices": [
+    "devcontainer",
+    "postgres",
+    "dns"
+  ],
+  "workspaceFolder": "/workspaces/demo",
+  "shutdownAction": "stopCompose",
+  "features": {
+    "ghcr.io/devcontainers/features/docker-outside-of-docker:1": {}
+  },
+  "forwardPorts": [
+    "postgres:5432"
+  ],
+  "portsAttributes": {
+    "postgres:5432": {
+      "label": "postgres"
+    }
+  },
+  "postCreateCommand": "command -v dev-stack >/dev/null || curl -fsSL https://raw.githubusercontent.com/isaacgarza/dev-stack/main/install.sh | bash && dev-stack seed",
+  "customizations": {
+    "dev-stack": {
+      "services": [
+        "postgres",
+        "dns"
+      ],
+      "image": "mcr.microsoft.com/devcontainers/base:ubuntu"
+    }
+  }
+}
+`, string(files[DevcontainerFile]))
+	assert.Equal(t, `name: demo
+services:
+  devcontainer:
+    image: mcr.microsoft.com/devcontainers/base:ubuntu
+    command: sleep infinity
+    volumes:
+      - ..:/workspaces/demo:cached
+    networks:
+      - backend
+      - dev-stack
+    depends_on:
+      dns:
+        condition: service_started
+      postgres:
+        condition: service_healthy
+`, string(files[DevcontainerComposeFile]))
+
+	path := filepath.Join(t.TempDir(), DevcontainerFile)
+	require.NoError(t, os.WriteFile(path, files[DevcontainerFile], 0644))
+	selection, err := ReadDevcontainerSelection(path)
+	require.NoError(t, err)
+	assert.Equal(t, &DevcontainerSelection{Services: []string{"postgres", "dns"}, Image: DefaultDevcontainerImage}, selection)
+
+	require.NoError(t, os.WriteFile(path, []byte("// hand written\n{\"image\": \"go\"}\n"), 0644))
+	_, err = ReadDevcontainerSelection(path)
+	assert.ErrorIs(t, err, ErrNotExported)
+}
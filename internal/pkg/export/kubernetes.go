@@ -0,0 +1,473 @@
+// Package export converts the services of a stack to the formats of other
+// platforms, so the same definitions run outside compose
+package export
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"regexp"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/isaacgarza/dev-stack/internal/pkg/compose"
+)
+
+// Kubernetes export formats
+const (
+	FormatManifests = "manifests"
+	FormatKustomize = "kustomize"
+)
+
+// DefaultVolumeSize is the storage requested for each named volume
+const DefaultVolumeSize = "1Gi"
+
+// KustomizationFile is the file listing the manifests of a kustomize export
+const KustomizationFile = "kustomization.yaml"
+
+// KubernetesFormats returns the supported Kubernetes export formats
+func KubernetesFormats() []string {
+	return []string{FormatManifests, FormatKustomize}
+}
+
+// KubernetesOptions configures a Kubernetes export
+type KubernetesOptions struct {
+	// Project labels the objects as part of the project
+	Project string
+	// Namespace is set on the objects when not empty
+	Namespace string
+	// VolumeSize is the storage each PersistentVolumeClaim requests
+	VolumeSize string
+}
+
+// KubernetesExport holds the Kubernetes objects of each exported service
+// and what couldn't be exported
+type KubernetesExport struct {
+	Services []ServiceObjects
+	Warnings []string
+	options  KubernetesOptions
+}
+
+// ServiceObjects are the Kubernetes objects running a service
+type ServiceObjects struct {
+	Name    string
+	Objects []Object
+}
+
+// Object is a Kubernetes object as rendered to YAML
+type Object struct {
+	APIVersion string            `yaml:"apiVersion"`
+	Kind       string            `yaml:"kind"`
+	Metadata   Metadata          `yaml:"metadata"`
+	Data       map[string]string `yaml:"data,omitempty"`
+	Spec       any               `yaml:"spec,omitempty"`
+}
+
+// Metadata is the metadata of a Kubernetes object
+type Metadata struct {
+	Name      string            `yaml:"name,omitempty"`
+	Namespace string            `yaml:"namespace,omitempty"`
+	Labels    map[string]string `yaml:"labels,omitempty"`
+}
+
+type deploymentSpec struct {
+	Replicas int         `yaml:"replicas"`
+	Selector selector    `yaml:"selector"`
+	Strategy *strategy   `yaml:"strategy,omitempty"`
+	Template podTemplate `yaml:"template"`
+}
+
+type selector struct {
+	MatchLabels map[string]string `yaml:"matchLabels"`
+}
+
+type strategy struct {
+	Type string `yaml:"type"`
+}
+
+type podTemplate struct {
+	Metadata Metadata `yaml:"metadata"`
+	Spec     podSpec  `yaml:"spec"`
+}
+
+type podSpec struct {
+	Containers []container `yaml:"containers"`
+	Volumes    []volume    `yaml:"volumes,omitempty"`
+}
+
+type container struct {
+	Name           string          `yaml:"name"`
+	Image          string          `yaml:"image"`
+	Command        []string        `yaml:"command,omitempty"`
+	Args           []string        `yaml:"args,omitempty"`
+	EnvFrom        []envSource     `yaml:"envFrom,omitempty"`
+	Ports          []containerPort `yaml:"ports,omitempty"`
+	VolumeMounts   []volumeMount   `yaml:"volumeMounts,omitempty"`
+	Resources      *resources      `yaml:"resources,omitempty"`
+	ReadinessProbe *probe          `yaml:"readinessProbe,omitempty"`
+}
+
+type envSource struct {
+	ConfigMapRef struct {
+		Name string `yaml:"name"`
+	} `yaml:"configMapRef"`
+}
+
+type containerPort struct {
+	Name          string `yaml:"name"`
+	ContainerPort int    `yaml:"containerPort"`
+	Protocol      string `yaml:"protocol"`
+}
+
+type volumeMount struct {
+	Name      string `yaml:"name"`
+	MountPath string `yaml:"mountPath"`
+	ReadOnly  bool   `yaml:"readOnly,omitempty"`
+}
+
+type volume struct {
+	Name                  string       `yaml:"name"`
+	PersistentVolumeClaim *claimSource `yaml:"persistentVolumeClaim,omitempty"`
+	EmptyDir              *emptyDir    `yaml:"emptyDir,omitempty"`
+}
+
+type emptyDir struct {
+	Medium string `yaml:"medium,omitempty"`
+}
+
+type claimSource struct {
+	ClaimName string `yaml:"claimName"`
+}
+
+type resources struct {
+	Limits map[string]string `yaml:"limits"`
+}
+
+type probe struct {
+	Exec struct {
+		Command []string `yaml:"command"`
+	} `yaml:"exec"`
+	InitialDelaySeconds int `yaml:"initialDelaySeconds,omitempty"`
+	PeriodSeconds       int `yaml:"periodSeconds,omitempty"`
+	TimeoutSeconds      int `yaml:"timeoutSeconds,omitempty"`
+	FailureThreshold    int `yaml:"failureThreshold,omitempty"`
+}
+
+type serviceSpec struct {
+	Selector map[string]string `yaml:"selector"`
+	Ports    []servicePort     `yaml:"ports"`
+}
+
+type servicePort struct {
+	Name       string `yaml:"name"`
+	Port       int    `yaml:"port"`
+	TargetPort int    `yaml:"targetPort"`
+	Protocol   string `yaml:"protocol"`
+}
+
+type claimSpec struct {
+	AccessModes []string `yaml:"accessModes"`
+	Resources   struct {
+		Requests map[string]string `yaml:"requests"`
+	} `yaml:"resources"`
+}
+
+// invalidNameChars are the characters Kubernetes names can't hold
+var invalidNameChars = regexp.MustCompile(`[^a-z0-9-]+`)
+
+// Kubernetes converts service definitions to Deployments, Services,
+// ConfigMaps and PersistentVolumeClaims. Service objects are named after
+// the compose services, so services reach each other by the same host names
+// and container ports as on the compose network. Bind mounts and services
+// that only build their image can't be exported and are listed as warnings.
+func Kubernetes(definitions []compose.Definition, options KubernetesOptions) (*KubernetesExport, error) {
+	if options.VolumeSize == "" {
+		options.VolumeSize = DefaultVolumeSize
+	}
+	export := &KubernetesExport{options: options}
+	claims := make(map[string]bool)
+	for _, def := range definitions {
+		if def.Image == "" {
+			export.warn("%s: the image is built by compose; push it to a registry and set image to export the service", def.Name)
+			continue
+		}
+		objects, err := export.serviceObjects(def, claims)
+		if err != nil {
+			return nil, fmt.Errorf("service %s: %w", def.Name, err)
+		}
+		export.Services = append(export.Services, ServiceObjects{Name: def.Name, Objects: objects})
+	}
+	return export, nil
+}
+
+// serviceObjects returns the objects running def, with a claim for each
+// named volume not claimed by an earlier service
+func (e *KubernetesExport) serviceObjects(def compose.Definition, claims map[string]bool) ([]Object, error) {
+	name := objectName(def.Name)
+	labels := e.labels(def.Name)
+	podLabels := map[string]string{"app.kubernetes.io/name": name}
+
+	var objects []Object
+	c := container{Name: name, Image: def.Image, Command: def.Entrypoint, Args: def.Command}
+	if len(def.Environment) > 0 {
+		objects = append(objects, Object{
+			APIVersion: "v1",
+			Kind:       "ConfigMap",
+			Metadata:   e.metadata(name+"-env", labels),
+			Data:       def.Environment,
+		})
+		var source envSource
+		source.ConfigMapRef.Name = name + "-env"
+		c.EnvFrom = []envSource{source}
+	}
+
+	var ports []servicePort
+	for _, port := range def.Ports {
+		protocol := strings.ToUpper(port.Protocol)
+		portName := fmt.Sprintf("%s-%d", strings.ToLower(protocol), port.Target)
+		if containsPort(c.Ports, portName) {
+			continue
+		}
+		c.Ports = append(c.Ports, containerPort{Name: portName, ContainerPort: port.Target, Protocol: protocol})
+		ports = append(ports, servicePort{Name: portName, Port: port.Target, TargetPort: port.Target, Protocol: protocol})
+	}
+
+	spec := deploymentSpec{
+		Replicas: 1,
+		Selector: selector{MatchLabels: podLabels},
+		Template: podTemplate{Metadata: Metadata{Labels: labels}},
+	}
+	for i, mount := range def.Mounts {
+		switch mount.Type {
+		case compose.MountVolume:
+			volumeName := fmt.Sprintf("scratch-%d", i)
+			v := volume{Name: volumeName, EmptyDir: &emptyDir{}}
+			if mount.Source != "" {
+				claim := objectName(mount.Source)
+				volumeName = claim
+				v = volume{Name: claim}
+				v.PersistentVolumeClaim = &claimSource{ClaimName: claim}
+				if !claims[claim] {
+					claims[claim] = true
+					objects = append(objects, e.claim(claim))
+				}
+				// A ReadWriteOnce claim can't be mounted by two pods on
+				// different nodes, so the old pod goes before the new one
+				spec.Strategy = &strategy{Type: "Recreate"}
+			}
+			spec.Template.Spec.Volumes = append(spec.Template.Spec.Volumes, v)
+			c.VolumeMounts = append(c.VolumeMounts, volumeMount{Name: volumeName, MountPath: mount.Target, ReadOnly: mount.ReadOnly})
+		case compose.MountTmpfs:
+			volumeName := fmt.Sprintf("tmp-%d", i)
+			spec.Template.Spec.Volumes = append(spec.Template.Spec.Volumes, volume{Name: volumeName, EmptyDir: &emptyDir{Medium: "Memory"}})
+			c.VolumeMounts = append(c.VolumeMounts, volumeMount{Name: volumeName, MountPath: mount.Target})
+		default:
+			e.warn("%s: the %s mount of %s at %s isn't exported; copy the files into the image or a ConfigMap", def.Name, mount.Type, mount.Source, mount.Target)
+		}
+	}
+
+	limits, err := resourceLimits(def)
+	if err != nil {
+		return nil, err
+	}
+	if len(limits) > 0 {
+		c.Resources = &resources{Limits: limits}
+	}
+	if c.ReadinessProbe, err = readinessProbe(def.Healthcheck); err != nil {
+		return nil, err
+	}
+
+	spec.Template.Spec.Containers = []container{c}
+	objects = append(objects, Object{
+		APIVersion: "apps/v1",
+		Kind:       "Deployment",
+		Metadata:   e.metadata(name, labels),
+		Spec:       spec,
+	})
+	if len(ports) > 0 {
+		objects = append(objects, Object{
+			APIVersion: "v1",
+			Kind:       "Service",
+			Metadata:   e.metadata(name, labels),
+			Spec:       serviceSpec{Selector: podLabels, Ports: ports},
+		})
+	}
+	return objects, nil
+}
+
+// claim returns the PersistentVolumeClaim of a named volume
+func (e *KubernetesExport) claim(name string) Object {
+	var spec claimSpec
+	spec.AccessModes = []string{"ReadWriteOnce"}
+	spec.Resources.Requests = map[string]string{"storage": e.options.VolumeSize}
+	return Object{
+		APIVersion: "v1",
+		Kind:       "PersistentVolumeClaim",
+		Metadata:   e.metadata(name, e.labels("")),
+		Spec:       spec,
+	}
+}
+
+// labels returns the labels of the objects of service, or of the objects
+// shared by services when service is empty
+func (e *KubernetesExport) labels(service string) map[string]string {
+	labels := map[string]string{"app.kubernetes.io/managed-by": "dev-stack"}
+	if service != "" {
+		labels["app.kubernetes.io/name"] = objectName(service)
+	}
+	if e.options.Project != "" {
+		labels["app.kubernetes.io/part-of"] = e.options.Project
+	}
+	return labels
+}
+
+func (e *KubernetesExport) metadata(name string, labels map[string]string) Metadata {
+	return Metadata{Name: name, Namespace: e.options.Namespace, Labels: labels}
+}
+
+func (e *KubernetesExport) warn(format string, args ...interface{}) {
+	e.Warnings = append(e.Warnings, fmt.Sprintf(format, args...))
+}
+
+// YAML renders every object as a single multi-document YAML stream
+func (e *KubernetesExport) YAML() ([]byte, error) {
+	var objects []Object
+	for _, svc := range e.Services {
+		objects = append(objects, svc.Objects...)
+	}
+	return encodeObjects(objects)
+}
+
+// Kustomize renders a file of objects per service and the kustomization
+// listing them, keyed by file name
+func (e *KubernetesExport) Kustomize() (map[string][]byte, error) {
+	files := make(map[string][]byte, len(e.Services)+1)
+	kustomization := struct {
+		APIVersion string   `yaml:"apiVersion"`
+		Kind       string   `yaml:"kind"`
+		Namespace  string   `yaml:"namespace,omitempty"`
+		Resources  []string `yaml:"resources"`
+	}{APIVersion: "kustomize.config.k8s.io/v1beta1", Kind: "Kustomization", Namespace: e.options.Namespace, Resources: []string{}}
+
+	for _, svc := range e.Services {
+		data, err := encodeObjects(svc.Objects)
+		if err != nil {
+			return nil, err
+		}
+		file := objectName(svc.Name) + ".yaml"
+		files[file] = data
+		kustomization.Resources = append(kustomization.Resources, file)
+	}
+
+	data, err := encodeObjects([]any{kustomization})
+	if err != nil {
+		return nil, err
+	}
+	files[KustomizationFile] = data
+	return files, nil
+}
+
+// encodeObjects renders objects as YAML documents
+func encodeObjects[T any](objects []T) ([]byte, error) {
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	for _, object := range objects {
+		if err := encoder.Encode(object); err != nil {
+			return nil, err
+		}
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// objectName turns a compose name into a valid Kubernetes object name
+func objectName(name string) string {
+	name = invalidNameChars.ReplaceAllString(strings.ToLower(name), "-")
+	return strings.Trim(name, "-")
+}
+
+func containsPort(ports []containerPort, name string) bool {
+	for _, port := range ports {
+		if port.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// resourceLimits returns the compose limits of def as Kubernetes limits.
+// Process limits have no container equivalent and are left out.
+func resourceLimits(def compose.Definition) (map[string]string, error) {
+	limits := make(map[string]string)
+	if def.Limits.CPU != "" {
+		limits["cpu"] = def.Limits.CPU
+	}
+	if def.Limits.Memory != "" {
+		memory, err := memoryQuantity(def.Limits.Memory)
+		if err != nil {
+			return nil, err
+		}
+		limits["memory"] = memory
+	}
+	return limits, nil
+}
+
+// memoryQuantity converts a compose memory size, such as 512m or 1gb, to a
+// Kubernetes quantity such as 512Mi
+func memoryQuantity(size string) (string, error) {
+	s := strings.TrimSuffix(strings.ToLower(strings.TrimSpace(size)), "b")
+	units := map[string]string{"k": "Ki", "m": "Mi", "g": "Gi", "t": "Ti"}
+	number, unit := s, ""
+	if n := len(s); n > 0 {
+		if suffix, ok := units[s[n-1:]]; ok {
+			number, unit = s[:n-1], suffix
+		}
+	}
+	if number == "" || strings.TrimLeft(number, "0123456789") != "" {
+		return "", fmt.Errorf("invalid memory limit %q", size)
+	}
+	return number + unit, nil
+}
+
+// readinessProbe converts a compose healthcheck to a readiness probe, so
+// the Service only routes to the pod once it is healthy
+func readinessProbe(check *compose.Healthcheck) (*probe, error) {
+	if check == nil || len(check.Test) == 0 {
+		return nil, nil
+	}
+
+	p := &probe{FailureThreshold: check.Retries}
+	switch check.Test[0] {
+	case "CMD":
+		p.Exec.Command = check.Test[1:]
+	case "CMD-SHELL":
+		p.Exec.Command = []string{"sh", "-c", strings.Join(check.Test[1:], " ")}
+	default:
+		return nil, fmt.Errorf("unsupported healthcheck test %q", check.Test[0])
+	}
+
+	durations := []struct {
+		value  string
+		target *int
+	}{
+		{check.Interval, &p.PeriodSeconds},
+		{check.Timeout, &p.TimeoutSeconds},
+		{check.StartPeriod, &p.InitialDelaySeconds},
+	}
+	for _, d := range durations {
+		if d.value == "" {
+			continue
+		}
+		duration, err := time.ParseDuration(d.value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid healthcheck duration %q: %w", d.value, err)
+		}
+		*d.target = int(math.Ceil(duration.Seconds()))
+	}
+	return p, nil
+}
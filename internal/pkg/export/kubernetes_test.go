@@ -0,0 +1,206 @@
+package export
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/isaacgarza/dev-stack/internal/pkg/compose"
+	"github.com/isaacgarza/dev-stack/internal/pkg/types"
+)
+
+func testDefinitions() []compose.Definition {
+	return []compose.Definition{
+		{
+			Name:        "postgres",
+			Image:       "postgres:15",
+			Command:     []string{"postgres", "-c", "max_connections=200"},
+			Environment: map[string]string{"POSTGRES_DB": "app"},
+			Ports:       []compose.PortSpec{{Published: 5432, Target: 5432, Protocol: "tcp"}},
+			Mounts: []compose.MountSpec{
+				{Type: compose.MountVolume, Source: "pg_data", Target: "/var/lib/postgresql/data"},
+				{Type: compose.MountBind, Source: "./init.sql", Target: "/docker-entrypoint-initdb.d/init.sql"},
+			},
+			Healthcheck: &compose.Healthcheck{Test: []string{"CMD-SHELL", "pg_isready"}, Interval: "10s", Timeout: "1500ms", Retries: 5},
+			Limits:      types.ResourceLimits{Memory: "512m", CPU: "0.5"},
+		},
+		{Name: "app", Build: true},
+		{Name: "worker", Image: "busybox", Mounts: []compose.MountSpec{{Type: compose.MountTmpfs, Target: "/tmp"}}},
+	}
+}
+
+func TestKubernetes(t *testing.T) {
+	export, err := Kubernetes(testDefinitions(), KubernetesOptions{Project: "demo", Namespace: "dev"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"postgres: the bind mount of ./init.sql at /docker-entrypoint-initdb.d/init.sql isn't exported; copy the files into the image or a ConfigMap",
+		"app: the image is built by compose; push it to a registry and set image to export the service",
+	}, export.Warnings)
+
+	data, err := export.YAML()
+	require.NoError(t, err)
+	assert.Equal(t, `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: postgres-env
+  namespace: dev
+  labels:
+    app.kubernetes.io/managed-by: dev-stack
+    app.kubernetes.io/name: postgres
+    app.kubernetes.io/part-of: demo
+data:
+  POSTGRES_DB: app
+---
+apiVersion: v1
+kind: PersistentVolumeClaim
+metadata:
+  name: pg-data
+  namespace: dev
+  labels:
+    app.kubernetes.io/managed-by: dev-stack
+    app.kubernetes.io/part-of: demo
+spec:
+  accessModes:
+    - ReadWriteOnce
+  resources:
+    requests:
+      storage: 1Gi
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: postgres
+  namespace: dev
+  labels:
+    app.kubernetes.io/managed-by: dev-stack
+    app.kubernetes.io/name: postgres
+    app.kubernetes.io/part-of: demo
+spec:
+  replicas: 1
+  selector:
+    matchLabels:
+      app.kubernetes.io/name: postgres
+  strategy:
+    type: Recreate
+  template:
+    metadata:
+      labels:
+        app.kubernetes.io/managed-by: dev-stack
+        app.kubernetes.io/name: postgres
+        app.kubernetes.io/part-of: demo
+    spec:
+      containers:
+        - name: postgres
+          image: postgres:15
+          args:
+            - postgres
+            - -c
+            - max_connections=200
+          envFrom:
+            - configMapRef:
+                name: postgres-env
+          ports:
+            - name: tcp-5432
+              containerPort: 5432
+              protocol: TCP
+          volumeMounts:
+            - name: pg-data
+              mountPath: /var/lib/postgresql/data
+          resources:
+            limits:
+              cpu: "0.5"
+              memory: 512Mi
+          readinessProbe:
+            exec:
+              command:
+                - sh
+                - -c
+                - pg_isready
+            periodSeconds: 10
+            timeoutSeconds: 2
+            failureThreshold: 5
+      volumes:
+        - name: pg-data
+          persistentVolumeClaim:
+            claimName: pg-data
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: postgres
+  namespace: dev
+  labels:
+    app.kubernetes.io/managed-by: dev-stack
+    app.kubernetes.io/name: postgres
+    app.kubernetes.io/part-of: demo
+spec:
+  selector:
+    app.kubernetes.io/name: postgres
+  ports:
+    - name: tcp-5432
+      port: 5432
+      targetPort: 5432
+      protocol: TCP
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: worker
+  namespace: dev
+  labels:
+    app.kubernetes.io/managed-by: dev-stack
+    app.kubernetes.io/name: worker
+    app.kubernetes.io/part-of: demo
+spec:
+  replicas: 1
+  selector:
+    matchLabels:
+      app.kubernetes.io/name: worker
+  template:
+    metadata:
+      labels:
+        app.kubernetes.io/managed-by: dev-stack
+        app.kubernetes.io/name: worker
+        app.kubernetes.io/part-of: demo
+    spec:
+      containers:
+        - name: worker
+          image: busybox
+          volumeMounts:
+            - name: tmp-0
+              mountPath: /tmp
+      volumes:
+        - name: tmp-0
+          emptyDir:
+            medium: Memory
+`, string(data))
+}
+
+func TestKubernetes_Kustomize(t *testing.T) {
+	export, err := Kubernetes(testDefinitions(), KubernetesOptions{Namespace: "dev", VolumeSize: "5Gi"})
+	require.NoError(t, err)
+
+	files, err := export.Kustomize()
+	require.NoError(t, err)
+	assert.Len(t, files, 3)
+	assert.Equal(t, `apiVersion: kustomize.config.k8s.io/v1beta1
+kind: Kustomization
+namespace: dev
+resources:
+  - postgres.yaml
+  - worker.yaml
+`, string(files[KustomizationFile]))
+	assert.Contains(t, string(files["postgres.yaml"]), "storage: 5Gi")
+	assert.NotContains(t, string(files["postgres.yaml"]), "part-of")
+}
+
+func TestMemoryQuantity(t *testing.T) {
+	for size, want := range map[string]string{"512m": "512Mi", "1GB": "1Gi", "64k": "64Ki", "1048576": "1048576"} {
+		quantity, err := memoryQuantity(size)
+		require.NoError(t, err, size)
+		assert.Equal(t, want, quantity, size)
+	}
+	_, err := memoryQuantity("1.5g")
+	assert.Error(t, err)
+}
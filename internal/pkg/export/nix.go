@@ -0,0 +1,259 @@
+package export
+
+import (
+	"fmt"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/isaacgarza/dev-stack/internal/pkg/compose"
+)
+
+// DevenvFile is the devenv module a Nix export writes
+const DevenvFile = "devenv.nix"
+
+// DevenvHeader starts the devenv modules dev-stack writes
+const DevenvHeader = "# Generated by dev-stack export nix"
+
+// NixMapping reports how a service of the stack maps to devenv. Module is
+// the devenv service running it natively, or empty when there is none.
+type NixMapping struct {
+	Service string
+	Module  string
+	Notes   []string
+}
+
+// Mapped reports whether devenv runs the service
+func (m NixMapping) Mapped() bool {
+	return m.Module != ""
+}
+
+// nixAttr is an attribute of a devenv service, with its value already
+// written as Nix
+type nixAttr struct {
+	name  string
+	value string
+}
+
+// nixMapper converts a service definition to the attributes of a devenv
+// service, noting what is lost on the way
+type nixMapper func(def compose.Definition, mapping *NixMapping) []nixAttr
+
+// nixModules maps image repositories to the devenv service that runs them
+var nixModules = map[string]struct {
+	module string
+	mapper nixMapper
+}{
+	"postgres":      {"postgres", postgresAttrs},
+	"postgis":       {"postgres", postgresAttrs},
+	"redis":         {"redis", redisAttrs},
+	"mysql":         {"mysql", mysqlAttrs},
+	"mariadb":       {"mysql", mysqlAttrs},
+	"mongo":         {"mongodb", mongodbAttrs},
+	"rabbitmq":      {"rabbitmq", rabbitmqAttrs},
+	"elasticsearch": {"elasticsearch", portAttrs("port", 9200)},
+	"memcached":     {"memcached", portAttrs("port", 11211)},
+	"minio":         {"minio", minioAttrs},
+	"mailpit":       {"mailpit", noAttrs},
+}
+
+// Devenv renders a devenv.nix running the services that have a devenv
+// equivalent as native processes, under process-compose, with env set in
+// the shell, and reports how each service maps. Services devenv has no
+// module for are left out of the file and reported unmapped.
+func Devenv(definitions []compose.Definition, env map[string]string) ([]byte, []NixMapping) {
+	var b strings.Builder
+	b.WriteString(DevenvHeader + "; run the services with 'devenv up'\n")
+	b.WriteString("{ pkgs, ... }:\n\n{\n")
+
+	var mappings []NixMapping
+	for _, def := range definitions {
+		mapping := NixMapping{Service: def.Name}
+		module, ok := nixModules[imageRepository(def.Image)]
+		if !ok {
+			mapping.Notes = append(mapping.Notes, unmappedReason(def))
+			mappings = append(mappings, mapping)
+			continue
+		}
+
+		mapping.Module = module.module
+		attrs := append([]nixAttr{{"enable", "true"}}, module.mapper(def, &mapping)...)
+		for _, mount := range def.Mounts {
+			if mount.Type == compose.MountBind {
+				mapping.Notes = append(mapping.Notes, fmt.Sprintf("bind mount %s at %s isn't mapped", mount.Source, mount.Target))
+			}
+		}
+		if len(def.Command) > 0 || len(def.Entrypoint) > 0 {
+			mapping.Notes = append(mapping.Notes, "the command of the container isn't mapped; set the settings of the devenv service instead")
+		}
+		mappings = append(mappings, mapping)
+
+		fmt.Fprintf(&b, "  # %s\n  services.%s = {\n", def.Name, module.module)
+		for _, attr := range attrs {
+			fmt.Fprintf(&b, "    %s = %s;\n", attr.name, attr.value)
+		}
+		b.WriteString("  };\n\n")
+	}
+
+	if len(env) > 0 {
+		b.WriteString("  # Connection variables of the services\n  env = {\n")
+		keys := make([]string, 0, len(env))
+		for key := range env {
+			keys = append(keys, key)
+		}
+		slices.Sort(keys)
+		for _, key := range keys {
+			fmt.Fprintf(&b, "    %s = %s;\n", nixName(key), nixString(env[key]))
+		}
+		b.WriteString("  };\n")
+	}
+	b.WriteString("}\n")
+	return []byte(b.String()), mappings
+}
+
+// unmappedReason explains why a service has no devenv equivalent
+func unmappedReason(def compose.Definition) string {
+	if def.Image == "" {
+		return "the image is built by compose; add a process running the application"
+	}
+	return fmt.Sprintf("devenv has no service for %s; keep running it with dev-stack or add a process", def.Image)
+}
+
+// imageRepository returns the last path element of the repository of an
+// image, such as postgres for docker.io/library/postgres:15-alpine
+func imageRepository(image string) string {
+	image, _, _ = strings.Cut(image, "@")
+	repository := path.Base(image)
+	repository, _, _ = strings.Cut(repository, ":")
+	return repository
+}
+
+// imageMajor returns the major version the tag of image starts with, or 0
+func imageMajor(image string) int {
+	_, tag, found := strings.Cut(path.Base(image), ":")
+	if !found {
+		return 0
+	}
+	end := strings.IndexFunc(tag, func(r rune) bool { return r < '0' || r > '9' })
+	if end < 0 {
+		end = len(tag)
+	}
+	major, _ := strconv.Atoi(tag[:end])
+	return major
+}
+
+// hostPort returns the port clients on the host reach target on: the
+// published port, or target itself when it is published on a random port
+// or not at all
+func hostPort(def compose.Definition, target int) int {
+	for _, port := range def.Ports {
+		if port.Target == target && port.Published != 0 {
+			return port.Published
+		}
+	}
+	return target
+}
+
+func postgresAttrs(def compose.Definition, mapping *NixMapping) []nixAttr {
+	var attrs []nixAttr
+	if major := imageMajor(def.Image); major > 0 {
+		attrs = append(attrs, nixAttr{"package", fmt.Sprintf("pkgs.postgresql_%d", major)})
+	}
+	if imageRepository(def.Image) == "postgis" {
+		mapping.Notes = append(mapping.Notes, "add postgis with extensions = extensions: [ extensions.postgis ]")
+	}
+	attrs = append(attrs,
+		nixAttr{"listen_addresses", nixString("127.0.0.1")},
+		nixAttr{"port", strconv.Itoa(hostPort(def, 5432))},
+	)
+	if db := def.Environment["POSTGRES_DB"]; db != "" {
+		attrs = append(attrs, nixAttr{"initialDatabases", fmt.Sprintf("[ { name = %s; } ]", nixString(db))})
+	}
+	if user := def.Environment["POSTGRES_USER"]; user != "" {
+		script := fmt.Sprintf("CREATE ROLE %q WITH LOGIN SUPERUSER PASSWORD '%s';", user, strings.ReplaceAll(def.Environment["POSTGRES_PASSWORD"], "'", "''"))
+		attrs = append(attrs, nixAttr{"initialScript", nixString(script)})
+	}
+	return attrs
+}
+
+func redisAttrs(def compose.Definition, mapping *NixMapping) []nixAttr {
+	attrs := []nixAttr{{"port", strconv.Itoa(hostPort(def, 6379))}}
+	if password := def.Environment["REDIS_PASSWORD"]; password != "" {
+		attrs = append(attrs, nixAttr{"extraConfig", nixString("requirepass " + password)})
+	}
+	return attrs
+}
+
+func mysqlAttrs(def compose.Definition, mapping *NixMapping) []nixAttr {
+	pkg := "pkgs.mysql80"
+	if imageRepository(def.Image) == "mariadb" {
+		pkg = "pkgs.mariadb"
+	}
+	attrs := []nixAttr{
+		{"package", pkg},
+		{"settings.mysqld.port", strconv.Itoa(hostPort(def, 3306))},
+	}
+	db := def.Environment["MYSQL_DATABASE"]
+	if db != "" {
+		attrs = append(attrs, nixAttr{"initialDatabases", fmt.Sprintf("[ { name = %s; } ]", nixString(db))})
+	}
+	if user := def.Environment["MYSQL_USER"]; user != "" {
+		permissions := ""
+		if db != "" {
+			permissions = fmt.Sprintf(" ensurePermissions = { %s = \"ALL PRIVILEGES\"; };", nixString(db+".*"))
+		}
+		attrs = append(attrs, nixAttr{"ensureUsers", fmt.Sprintf("[ { name = %s; password = %s;%s } ]",
+			nixString(user), nixString(def.Environment["MYSQL_PASSWORD"]), permissions)})
+	}
+	return attrs
+}
+
+func mongodbAttrs(def compose.Definition, mapping *NixMapping) []nixAttr {
+	if _, ok := def.Environment["MONGO_INITDB_ROOT_USERNAME"]; ok {
+		mapping.Notes = append(mapping.Notes, "the root user isn't created; set initDatabaseUsername and initDatabasePassword")
+	}
+	return []nixAttr{{"additionalArgs", fmt.Sprintf("[ \"--port\" \"%d\" \"--noauth\" ]", hostPort(def, 27017))}}
+}
+
+func rabbitmqAttrs(def compose.Definition, mapping *NixMapping) []nixAttr {
+	attrs := []nixAttr{{"port", strconv.Itoa(hostPort(def, 5672))}}
+	if strings.Contains(def.Image, "management") {
+		attrs = append(attrs, nixAttr{"managementPlugin.enable", "true"})
+	}
+	return attrs
+}
+
+func minioAttrs(def compose.Definition, mapping *NixMapping) []nixAttr {
+	return []nixAttr{
+		{"listenAddress", nixString(fmt.Sprintf("127.0.0.1:%d", hostPort(def, 9000)))},
+		{"consoleAddress", nixString(fmt.Sprintf("127.0.0.1:%d", hostPort(def, 9001)))},
+	}
+}
+
+// portAttrs maps the default port of a service to the attribute name
+func portAttrs(name string, port int) nixMapper {
+	return func(def compose.Definition, mapping *NixMapping) []nixAttr {
+		return []nixAttr{{name, strconv.Itoa(hostPort(def, port))}}
+	}
+}
+
+func noAttrs(def compose.Definition, mapping *NixMapping) []nixAttr {
+	return nil
+}
+
+// nixString writes s as a Nix string
+func nixString(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "${", `\${`, "\n", `\n`, "\r", `\r`, "\t", `\t`).Replace(s) + `"`
+}
+
+// nixName writes an attribute name, quoted unless it is a plain identifier
+func nixName(name string) string {
+	for i, r := range name {
+		if r == '_' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || i > 0 && (r == '-' || r == '\'' || r >= '0' && r <= '9') {
+			continue
+		}
+		return nixString(name)
+	}
+	return name
+}
@@ -0,0 +1,67 @@
+package export
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/isaacgarza/dev-stack/internal/pkg/compose"
+)
+
+func TestDevenv(t *testing.T) {
+	definitions := []compose.Definition{
+		{
+			Name:        "postgres",
+			Image:       "docker.io/library/postgres:16-alpine",
+			Environment: map[string]string{"POSTGRES_DB": "app", "POSTGRES_USER": "app", "POSTGRES_PASSWORD": "it's"},
+			Ports:       []compose.PortSpec{{Published: 15432, Target: 5432, Protocol: "tcp"}},
+			Mounts:      []compose.MountSpec{{Type: compose.MountBind, Source: "./init", Target: "/docker-entrypoint-initdb.d"}},
+		},
+		{Name: "kafka", Image: "apache/kafka:3.7.0"},
+		{Name: "app", Build: true},
+		{Name: "queue", Image: "rabbitmq:3-management"},
+	}
+
+	data, mappings := Devenv(definitions, map[string]string{"DATABASE_URL": "postgres://app@localhost:15432/app", "PRICE": "${cost}"})
+	assert.Equal(t, `# Generated by dev-stack export nix; run the services with 'devenv up'
+{ pkgs, ... }:
+
+{
+  # postgres
+  services.postgres = {
+    enable = true;
+    package = pkgs.postgresql_16;
+    listen_addresses = "127.0.0.1";
+    port = 15432;
+    initialDatabases = [ { name = "app"; } ];
+    initialScript = "CREATE ROLE \"app\" WITH LOGIN SUPERUSER PASSWORD 'it''s';";
+  };
+
+  # queue
+  services.rabbitmq = {
+    enable = true;
+    port = 5672;
+    managementPlugin.enable = true;
+  };
+
+  # Connection variables of the services
+  env = {
+    DATABASE_URL = "postgres://app@localhost:15432/app";
+    PRICE = "\${cost}";
+  };
+}
+`, string(data))
+	assert.Equal(t, []NixMapping{
+		{Service: "postgres", Module: "postgres", Notes: []string{"bind mount ./init at /docker-entrypoint-initdb.d isn't mapped"}},
+		{Service: "kafka", Notes: []string{"devenv has no service for apache/kafka:3.7.0; keep running it with dev-stack or add a process"}},
+		{Service: "app", Notes: []string{"the image is built by compose; add a process running the application"}},
+		{Service: "queue", Module: "rabbitmq"},
+	}, mappings)
+	assert.False(t, mappings[1].Mapped())
+}
+
+func TestNixName(t *testing.T) {
+	assert.Equal(t, "DATABASE_URL", nixName("DATABASE_URL"))
+	assert.Equal(t, `"1PASSWORD"`, nixName("1PASSWORD"))
+	assert.Equal(t, `"a.b"`, nixName("a.b"))
+}
@@ -0,0 +1,139 @@
+package logger
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"sync"
+)
+
+// Output formats accepted by SetFormat
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+)
+
+// EnvCorrelationID lets a parent process or script supply the correlation ID
+// attached to every record of a command run
+const EnvCorrelationID = "DEV_STACK_CORRELATION_ID"
+
+var (
+	formatMu      sync.RWMutex
+	currentFormat = FormatText
+
+	// Text logs stay on stdout alongside command output; JSON logs go to
+	// stderr so they can be shipped without mixing with command output
+	textOutput io.Writer = os.Stdout
+	jsonOutput io.Writer = os.Stderr
+)
+
+// SetFormat selects the output format used by loggers created with New,
+// including loggers created before the call
+func SetFormat(format string) error {
+	switch format {
+	case "":
+		format = FormatText
+	case FormatText, FormatJSON:
+	default:
+		return fmt.Errorf("invalid log format %q (supported: %s, %s)", format, FormatText, FormatJSON)
+	}
+
+	formatMu.Lock()
+	defer formatMu.Unlock()
+	currentFormat = format
+	return nil
+}
+
+// Format returns the output format currently used by New loggers
+func Format() string {
+	formatMu.RLock()
+	defer formatMu.RUnlock()
+	return currentFormat
+}
+
+// SetTextOutput redirects text logs of loggers created with New, for
+// commands whose stdout carries a protocol rather than command output
+func SetTextOutput(w io.Writer) {
+	formatMu.Lock()
+	defer formatMu.Unlock()
+	textOutput = w
+}
+
+// NewCorrelationID returns the correlation ID for a command run, taken from
+// DEV_STACK_CORRELATION_ID when set and randomly generated otherwise
+func NewCorrelationID() string {
+	if id := os.Getenv(EnvCorrelationID); id != "" {
+		return id
+	}
+
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "unknown"
+	}
+	return hex.EncodeToString(buf)
+}
+
+// formatHandler resolves the configured format each time a record is
+// handled, so loggers built while the CLI is assembled still honor
+// --log-format once flags are parsed
+type formatHandler struct {
+	opts *slog.HandlerOptions
+	ops  []handlerOp
+}
+
+// handlerOp replays a WithAttrs or WithGroup call on the resolved handler
+type handlerOp struct {
+	group string
+	attrs []slog.Attr
+}
+
+func (h *formatHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= h.opts.Level.Level()
+}
+
+func (h *formatHandler) Handle(ctx context.Context, record slog.Record) error {
+	return h.resolve().Handle(ctx, record)
+}
+
+func (h *formatHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return h.with(handlerOp{attrs: attrs})
+}
+
+func (h *formatHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	return h.with(handlerOp{group: name})
+}
+
+func (h *formatHandler) with(op handlerOp) *formatHandler {
+	ops := make([]handlerOp, len(h.ops), len(h.ops)+1)
+	copy(ops, h.ops)
+	return &formatHandler{opts: h.opts, ops: append(ops, op)}
+}
+
+func (h *formatHandler) resolve() slog.Handler {
+	formatMu.RLock()
+	format, textOut, jsonOut := currentFormat, textOutput, jsonOutput
+	formatMu.RUnlock()
+
+	var handler slog.Handler
+	if format == FormatJSON {
+		handler = slog.NewJSONHandler(jsonOut, h.opts)
+	} else {
+		handler = slog.NewTextHandler(textOut, h.opts)
+	}
+
+	for _, op := range h.ops {
+		if op.group != "" {
+			handler = handler.WithGroup(op.group)
+		} else {
+			handler = handler.WithAttrs(op.attrs)
+		}
+	}
+	return handler
+}
@@ -227,13 +227,13 @@ func LogError(err error, msg string, args ...any) {
 	GetLogger().Error(msg, allArgs...)
 }
 
-// New creates a new logger with the specified level
+// New creates a new logger with the specified level, writing in the format
+// selected with SetFormat
 func New(level slog.Level) *slog.Logger {
 	opts := &slog.HandlerOptions{
 		Level: level,
 	}
-	handler := slog.NewTextHandler(os.Stdout, opts)
-	return slog.New(handler)
+	return slog.New(&formatHandler{opts: opts})
 }
 
 // NewContextLogger creates a new logger with context fields
@@ -479,3 +479,40 @@ func TestConfigValidation(t *testing.T) {
 		}
 	})
 }
+
+func TestSetFormat(t *testing.T) {
+	originalText, originalJSON := textOutput, jsonOutput
+	defer func() {
+		textOutput, jsonOutput = originalText, originalJSON
+		_ = SetFormat(FormatText)
+	}()
+
+	var textBuf, jsonBuf bytes.Buffer
+	textOutput, jsonOutput = &textBuf, &jsonBuf
+
+	// Created before the format is chosen, as the CLI does before parsing flags
+	logger := New(slog.LevelInfo).With("command", "dev-stack up")
+
+	logger.Info("text message")
+	assert.Contains(t, textBuf.String(), "command=\"dev-stack up\"")
+	assert.Empty(t, jsonBuf.String())
+
+	assert.NoError(t, SetFormat(FormatJSON))
+	assert.Equal(t, FormatJSON, Format())
+	logger.WithGroup("docker").Info("json message", "exit_status", 0)
+	assert.Contains(t, jsonBuf.String(), `"command":"dev-stack up"`)
+	assert.Contains(t, jsonBuf.String(), `"docker":{"exit_status":0}`)
+
+	assert.Error(t, SetFormat("xml"))
+	assert.Equal(t, FormatJSON, Format())
+}
+
+func TestNewCorrelationID(t *testing.T) {
+	t.Setenv(EnvCorrelationID, "")
+	first, second := NewCorrelationID(), NewCorrelationID()
+	assert.Len(t, first, 32)
+	assert.NotEqual(t, first, second)
+
+	t.Setenv(EnvCorrelationID, "ci-run-42")
+	assert.Equal(t, "ci-run-42", NewCorrelationID())
+}
@@ -0,0 +1,375 @@
+// Package release renders the package manager manifests of a dev-stack
+// release: the Homebrew formula, the Scoop manifest and the nfpm configs
+// the deb and rpm packages are built from. They are rendered from the
+// binaries of the build, so every install channel ships the same version
+// and checksums.
+package release
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Paths of the manifests, relative to the output directory, matching the
+// layout of a Homebrew tap and a Scoop bucket
+const (
+	FormulaDir = "Formula"
+	BucketDir  = "bucket"
+	NFPMDir    = "nfpm"
+)
+
+// Metadata is what the manifests of a release are rendered from
+type Metadata struct {
+	Name        string
+	Description string
+	Homepage    string
+	// Repository is the GitHub repository the binaries are released from,
+	// as owner/name
+	Repository string
+	License    string
+	Maintainer string
+	// Version is the released version, without a leading v
+	Version   string
+	Artifacts []Artifact
+	// ManDir is the directory of the man pages the deb and rpm packages
+	// install, or empty when there are none
+	ManDir string
+}
+
+// Artifact is a released binary. Path is where the build left it, and File
+// the name it is released under.
+type Artifact struct {
+	OS     string
+	Arch   string
+	Path   string
+	File   string
+	SHA256 string
+}
+
+// URL returns where the release publishes the artifact
+func (m Metadata) URL(a Artifact) string {
+	return fmt.Sprintf("https://github.com/%s/releases/download/v%s/%s", m.Repository, m.Version, a.File)
+}
+
+// artifact returns the artifact built for goos and goarch
+func (m Metadata) artifact(goos, goarch string) (Artifact, bool) {
+	for _, a := range m.Artifacts {
+		if a.OS == goos && a.Arch == goarch {
+			return a, true
+		}
+	}
+	return Artifact{}, false
+}
+
+// ErrNoArtifacts is returned by FindArtifacts when the build directory has
+// no binaries of the release
+var ErrNoArtifacts = errors.New("no release binaries found")
+
+// FindArtifacts returns the binaries named <name>-<os>-<arch>, with .exe
+// on Windows, in dir, as the cross-platform build writes them, with their
+// checksums
+func FindArtifacts(dir, name string) ([]Artifact, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var artifacts []Artifact
+	for _, entry := range entries {
+		platform, ok := strings.CutPrefix(entry.Name(), name+"-")
+		if entry.IsDir() || !ok {
+			continue
+		}
+		goos, goarch, ok := strings.Cut(strings.TrimSuffix(platform, ".exe"), "-")
+		if !ok || strings.Contains(goarch, "-") || strings.Contains(goarch, ".") {
+			continue
+		}
+		file := filepath.Join(dir, entry.Name())
+		sum, err := fileSHA256(file)
+		if err != nil {
+			return nil, err
+		}
+		artifacts = append(artifacts, Artifact{OS: goos, Arch: goarch, Path: file, File: entry.Name(), SHA256: sum})
+	}
+	if len(artifacts) == 0 {
+		return nil, fmt.Errorf("%w in %s", ErrNoArtifacts, dir)
+	}
+	return artifacts, nil
+}
+
+func fileSHA256(file string) (string, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = f.Close() }()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", fmt.Errorf("failed to hash %s: %w", file, err)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// Manifests renders every manifest the artifacts allow, keyed by its path
+// relative to the output directory: the formula needs a macOS or Linux
+// binary, the Scoop manifest a Windows one and each nfpm config a Linux
+// one
+func Manifests(m Metadata) (map[string][]byte, error) {
+	if m.Version == "" || strings.HasPrefix(m.Version, "v") {
+		return nil, fmt.Errorf("invalid release version %q", m.Version)
+	}
+
+	files := make(map[string][]byte)
+	if formula, ok, err := HomebrewFormula(m); err != nil {
+		return nil, err
+	} else if ok {
+		files[path.Join(FormulaDir, m.Name+".rb")] = formula
+	}
+	if manifest, ok, err := ScoopManifest(m); err != nil {
+		return nil, err
+	} else if ok {
+		files[path.Join(BucketDir, m.Name+".json")] = manifest
+	}
+	for _, a := range m.Artifacts {
+		if a.OS != "linux" {
+			continue
+		}
+		config, err := NFPMConfig(m, a)
+		if err != nil {
+			return nil, err
+		}
+		files[path.Join(NFPMDir, fmt.Sprintf("nfpm-%s-%s.yaml", a.OS, a.Arch))] = config
+	}
+	return files, nil
+}
+
+// formulaTemplate is the Homebrew formula. The binaries are released bare,
+// so the formula renames the download; completions and man pages come
+// from the installed binary itself.
+const formulaTemplate = `# Generated by {{.Name}} release manifests; do not edit
+class {{.Class}} < Formula
+  desc "{{.Description}}"
+  homepage "{{.Homepage}}"
+  version "{{.Version}}"
+  license "{{.License}}"
+{{range .Platforms}}
+  on_{{.Block}} do
+{{- range .Arches}}
+    on_{{.Block}} do
+      url "{{.URL}}"
+      sha256 "{{.SHA256}}"
+    end
+{{- end}}
+  end
+{{end}}
+  def install
+    bin.install Dir["{{.Name}}-*"].first => "{{.Name}}"
+    generate_completions_from_executable(bin/"{{.Name}}", "completion")
+    man1.mkpath
+    system bin/"{{.Name}}", "docs", "cli", "--format", "man", "--output", man1
+  end
+
+  test do
+    assert_match version.to_s, shell_output("#{bin}/{{.Name}} --version")
+  end
+end
+`
+
+type formulaPlatform struct {
+	Block  string
+	Arches []formulaArch
+}
+
+type formulaArch struct {
+	Block  string
+	URL    string
+	SHA256 string
+}
+
+// HomebrewFormula renders the Homebrew formula installing the macOS and
+// Linux binaries, and reports false when there are none
+func HomebrewFormula(m Metadata) ([]byte, bool, error) {
+	var platforms []formulaPlatform
+	for _, goos := range []string{"darwin", "linux"} {
+		platform := formulaPlatform{Block: map[string]string{"darwin": "macos", "linux": "linux"}[goos]}
+		for _, goarch := range []string{"amd64", "arm64"} {
+			if a, ok := m.artifact(goos, goarch); ok {
+				platform.Arches = append(platform.Arches, formulaArch{
+					Block:  map[string]string{"amd64": "intel", "arm64": "arm"}[goarch],
+					URL:    m.URL(a),
+					SHA256: a.SHA256,
+				})
+			}
+		}
+		if len(platform.Arches) > 0 {
+			platforms = append(platforms, platform)
+		}
+	}
+	if len(platforms) == 0 {
+		return nil, false, nil
+	}
+
+	tmpl, err := template.New("formula").Parse(formulaTemplate)
+	if err != nil {
+		return nil, false, err
+	}
+	var b bytes.Buffer
+	err = tmpl.Execute(&b, map[string]any{
+		"Name":        m.Name,
+		"Class":       formulaClass(m.Name),
+		"Description": strings.ReplaceAll(m.Description, `"`, `\"`),
+		"Homepage":    m.Homepage,
+		"Version":     m.Version,
+		"License":     m.License,
+		"Platforms":   platforms,
+	})
+	return b.Bytes(), err == nil, err
+}
+
+// formulaClass returns the class name Homebrew expects for a formula, such
+// as DevStack for dev-stack
+func formulaClass(name string) string {
+	var b strings.Builder
+	for _, part := range strings.FieldsFunc(name, func(r rune) bool { return r == '-' || r == '_' || r == '.' }) {
+		b.WriteString(strings.ToUpper(part[:1]) + part[1:])
+	}
+	return b.String()
+}
+
+type scoopManifest struct {
+	Version      string                       `json:"version"`
+	Description  string                       `json:"description"`
+	Homepage     string                       `json:"homepage"`
+	License      string                       `json:"license"`
+	Architecture map[string]scoopArchitecture `json:"architecture"`
+	Bin          string                       `json:"bin"`
+	Checkver     string                       `json:"checkver"`
+	Autoupdate   struct {
+		Architecture map[string]scoopArchitecture `json:"architecture"`
+	} `json:"autoupdate"`
+}
+
+type scoopArchitecture struct {
+	URL  string `json:"url"`
+	Hash string `json:"hash,omitempty"`
+}
+
+// scoopArchitectures maps Go architectures to Scoop's
+var scoopArchitectures = map[string]string{"amd64": "64bit", "386": "32bit", "arm64": "arm64"}
+
+// ScoopManifest renders the Scoop manifest installing the Windows
+// binaries, and reports false when there are none. Scoop checks GitHub for
+// new releases and fills the version into the autoupdate URLs.
+func ScoopManifest(m Metadata) ([]byte, bool, error) {
+	manifest := scoopManifest{
+		Version:      m.Version,
+		Description:  m.Description,
+		Homepage:     m.Homepage,
+		License:      m.License,
+		Architecture: make(map[string]scoopArchitecture),
+		Bin:          m.Name + ".exe",
+		Checkver:     "github",
+	}
+	manifest.Autoupdate.Architecture = make(map[string]scoopArchitecture)
+	for _, a := range m.Artifacts {
+		arch, ok := scoopArchitectures[a.Arch]
+		if a.OS != "windows" || !ok {
+			continue
+		}
+		// The fragment renames the download to the command name
+		rename := "#/" + manifest.Bin
+		manifest.Architecture[arch] = scoopArchitecture{URL: m.URL(a) + rename, Hash: a.SHA256}
+		manifest.Autoupdate.Architecture[arch] = scoopArchitecture{
+			URL: strings.Replace(m.URL(a), "/v"+m.Version+"/", "/v$version/", 1) + rename,
+		}
+	}
+	if len(manifest.Architecture) == 0 {
+		return nil, false, nil
+	}
+
+	var b bytes.Buffer
+	encoder := json.NewEncoder(&b)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "    ")
+	if err := encoder.Encode(manifest); err != nil {
+		return nil, false, err
+	}
+	return b.Bytes(), true, nil
+}
+
+type nfpmConfig struct {
+	Name        string        `yaml:"name"`
+	Arch        string        `yaml:"arch"`
+	Platform    string        `yaml:"platform"`
+	Version     string        `yaml:"version"`
+	Section     string        `yaml:"section"`
+	Priority    string        `yaml:"priority"`
+	Maintainer  string        `yaml:"maintainer"`
+	Description string        `yaml:"description"`
+	Homepage    string        `yaml:"homepage"`
+	License     string        `yaml:"license"`
+	Suggests    []string      `yaml:"suggests"`
+	Contents    []nfpmContent `yaml:"contents"`
+}
+
+type nfpmContent struct {
+	Src      string        `yaml:"src"`
+	Dst      string        `yaml:"dst"`
+	FileInfo *nfpmFileInfo `yaml:"file_info,omitempty"`
+}
+
+type nfpmFileInfo struct {
+	Mode yaml.Node `yaml:"mode"`
+}
+
+// NFPMConfig renders the nfpm config packaging the Linux binary a as deb
+// and rpm packages, with the man pages of m.ManDir when there are any.
+// Paths are relative to where nfpm runs, as the build left them.
+func NFPMConfig(m Metadata, a Artifact) ([]byte, error) {
+	config := nfpmConfig{
+		Name:        m.Name,
+		Arch:        a.Arch,
+		Platform:    a.OS,
+		Version:     m.Version,
+		Section:     "devel",
+		Priority:    "optional",
+		Maintainer:  m.Maintainer,
+		Description: m.Description,
+		Homepage:    m.Homepage,
+		License:     m.License,
+		// Services run on Docker, which distributions package differently
+		Suggests: []string{"docker"},
+		Contents: []nfpmContent{{
+			Src:      filepath.ToSlash(a.Path),
+			Dst:      "/usr/bin/" + m.Name,
+			FileInfo: &nfpmFileInfo{Mode: yaml.Node{Kind: yaml.ScalarNode, Tag: "!!int", Value: "0755"}},
+		}},
+	}
+	if m.ManDir != "" {
+		config.Contents = append(config.Contents, nfpmContent{
+			Src: filepath.ToSlash(filepath.Join(m.ManDir, "*.1")),
+			Dst: "/usr/share/man/man1/",
+		})
+	}
+
+	var b bytes.Buffer
+	b.WriteString("# Generated by " + m.Name + " release manifests; do not edit\n")
+	encoder := yaml.NewEncoder(&b)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(config); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), encoder.Close()
+}
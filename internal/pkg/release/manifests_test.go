@@ -0,0 +1,150 @@
+package release
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+// buildDir writes fake release binaries for platforms to a temporary
+// directory, next to files that aren't binaries of the release
+func buildDir(t *testing.T, platforms ...string) string {
+	dir := t.TempDir()
+	for _, platform := range platforms {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "dev-stack-"+platform), []byte(platform), 0755))
+	}
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "checksums.txt"), nil, 0644))
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "man"), 0755))
+	return dir
+}
+
+func metadata(t *testing.T, dir string) Metadata {
+	artifacts, err := FindArtifacts(dir, "dev-stack")
+	require.NoError(t, err)
+	return Metadata{
+		Name:        "dev-stack",
+		Description: "Development stack management tool",
+		Homepage:    "https://github.com/isaacgarza/dev-stack",
+		Repository:  "isaacgarza/dev-stack",
+		License:     "MIT",
+		Maintainer:  "Isaac Garza",
+		Version:     "1.4.0",
+		Artifacts:   artifacts,
+	}
+}
+
+func TestFindArtifacts(t *testing.T) {
+	dir := buildDir(t, "linux-amd64", "windows-amd64.exe")
+
+	artifacts, err := FindArtifacts(dir, "dev-stack")
+	require.NoError(t, err)
+	require.Len(t, artifacts, 2)
+	assert.Equal(t, Artifact{
+		OS:   "linux",
+		Arch: "amd64",
+		Path: filepath.Join(dir, "dev-stack-linux-amd64"),
+		File: "dev-stack-linux-amd64",
+		// sha256 of "linux-amd64"
+		SHA256: "abb35c616421af72198ad7c2aeeef38516f08f6a7afb2a728cf0068a8a712ddc",
+	}, artifacts[0])
+	assert.Equal(t, "windows", artifacts[1].OS)
+	assert.Equal(t, "amd64", artifacts[1].Arch)
+
+	_, err = FindArtifacts(t.TempDir(), "dev-stack")
+	assert.ErrorIs(t, err, ErrNoArtifacts)
+}
+
+func TestManifests(t *testing.T) {
+	m := metadata(t, buildDir(t, "darwin-arm64", "linux-amd64", "linux-arm64", "windows-amd64.exe"))
+
+	files, err := Manifests(m)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{
+		"Formula/dev-stack.rb",
+		"bucket/dev-stack.json",
+		"nfpm/nfpm-linux-amd64.yaml",
+		"nfpm/nfpm-linux-arm64.yaml",
+	}, keys(files))
+
+	m.Version = "v1.4.0"
+	_, err = Manifests(m)
+	assert.Error(t, err, "versions are given without the v of the tag")
+}
+
+func TestManifests_OnlyTheChannelsOfTheBinaries(t *testing.T) {
+	files, err := Manifests(metadata(t, buildDir(t, "windows-amd64.exe")))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"bucket/dev-stack.json"}, keys(files))
+}
+
+func TestHomebrewFormula(t *testing.T) {
+	m := metadata(t, buildDir(t, "darwin-amd64", "darwin-arm64"))
+
+	formula, ok, err := HomebrewFormula(m)
+	require.NoError(t, err)
+	require.True(t, ok)
+	assert.Contains(t, string(formula), "class DevStack < Formula\n")
+	assert.Contains(t, string(formula), `  version "1.4.0"`)
+	assert.Contains(t, string(formula), "  on_macos do\n    on_intel do\n      url \"https://github.com/isaacgarza/dev-stack/releases/download/v1.4.0/dev-stack-darwin-amd64\"\n")
+	assert.Contains(t, string(formula), "    on_arm do\n")
+	assert.NotContains(t, string(formula), "on_linux", "no Linux binaries were built")
+	assert.Contains(t, string(formula), m.Artifacts[1].SHA256)
+}
+
+func TestScoopManifest(t *testing.T) {
+	m := metadata(t, buildDir(t, "windows-amd64.exe", "linux-amd64"))
+
+	data, ok, err := ScoopManifest(m)
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	var manifest scoopManifest
+	require.NoError(t, json.Unmarshal(data, &manifest))
+	assert.Equal(t, "1.4.0", manifest.Version)
+	assert.Equal(t, "dev-stack.exe", manifest.Bin)
+	assert.Equal(t, map[string]scoopArchitecture{"64bit": {
+		URL:  "https://github.com/isaacgarza/dev-stack/releases/download/v1.4.0/dev-stack-windows-amd64.exe#/dev-stack.exe",
+		Hash: m.Artifacts[1].SHA256,
+	}}, manifest.Architecture)
+	assert.Equal(t, "https://github.com/isaacgarza/dev-stack/releases/download/v$version/dev-stack-windows-amd64.exe#/dev-stack.exe",
+		manifest.Autoupdate.Architecture["64bit"].URL)
+}
+
+func TestNFPMConfig(t *testing.T) {
+	dir := buildDir(t, "linux-arm64")
+	m := metadata(t, dir)
+	m.ManDir = filepath.Join(dir, "man")
+
+	data, err := NFPMConfig(m, m.Artifacts[0])
+	require.NoError(t, err)
+
+	var config map[string]any
+	require.NoError(t, yaml.Unmarshal(data, &config))
+	assert.Equal(t, "arm64", config["arch"])
+	assert.Equal(t, "1.4.0", config["version"])
+	assert.Equal(t, "Isaac Garza", config["maintainer"])
+	assert.Equal(t, []any{
+		map[string]any{
+			"src":       filepath.ToSlash(filepath.Join(dir, "dev-stack-linux-arm64")),
+			"dst":       "/usr/bin/dev-stack",
+			"file_info": map[string]any{"mode": 0755},
+		},
+		map[string]any{
+			"src": filepath.ToSlash(filepath.Join(dir, "man", "*.1")),
+			"dst": "/usr/share/man/man1/",
+		},
+	}, config["contents"])
+}
+
+func keys(files map[string][]byte) []string {
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	return names
+}
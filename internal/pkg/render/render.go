@@ -0,0 +1,233 @@
+// Package render prints what commands report: as an aligned table for
+// people, or as JSON or YAML for scripts. A command describes its rows once
+// with typed columns, and the same model serves every format, -o wide and
+// --columns.
+package render
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"gopkg.in/yaml.v3"
+)
+
+// Format is an output format of the renderer
+type Format string
+
+// Output formats
+const (
+	FormatTable Format = "table"
+	// FormatWide is a table with the wide columns too
+	FormatWide Format = "wide"
+	FormatJSON Format = "json"
+	FormatYAML Format = "yaml"
+)
+
+// columnGap separates the columns of a table
+const columnGap = "  "
+
+var headerStyle = lipgloss.NewStyle().Bold(true)
+
+// ParseFormat returns the format named value; empty is a table
+func ParseFormat(value string) (Format, error) {
+	switch strings.ToLower(value) {
+	case "", string(FormatTable):
+		return FormatTable, nil
+	case string(FormatWide):
+		return FormatWide, nil
+	case string(FormatJSON):
+		return FormatJSON, nil
+	case string(FormatYAML), "yml":
+		return FormatYAML, nil
+	}
+	return "", fmt.Errorf("unsupported output format %q (supported: table, wide, json, yaml)", value)
+}
+
+// Structured reports whether f prints data for scripts rather than a table
+func (f Format) Structured() bool {
+	return f == FormatJSON || f == FormatYAML
+}
+
+// Column is a column of a table of rows of type T
+type Column[T any] struct {
+	// Name is the header of the column, and what --columns selects it by
+	Name string
+	// Wide columns only show with -o wide, or when --columns selects them
+	Wide bool
+	// Value renders the cell of a row
+	Value func(row T) string
+	// Style, when set, colors the cell of a row on a color terminal
+	Style func(row T, value string) string
+}
+
+// Table is what a command reports: rows and the columns that show them
+type Table[T any] struct {
+	Columns []Column[T]
+	Rows    []T
+	// Data is what JSON and YAML print, for commands whose output has an
+	// established shape; without it they print the rows
+	Data any
+	// Empty is printed instead of a table without rows
+	Empty string
+}
+
+// Options are how to render a table
+type Options struct {
+	Format Format
+	// Columns selects the columns of a table by name, in order, including
+	// wide ones. Names match regardless of case, and dashes or
+	// underscores match spaces.
+	Columns []string
+	// Color styles the cells of a table
+	Color bool
+}
+
+// Render prints table to w in the format of options
+func Render[T any](w io.Writer, table Table[T], options Options) error {
+	switch options.Format {
+	case FormatJSON, FormatYAML:
+		data := table.Data
+		if data == nil {
+			data = table.Rows
+			if table.Rows == nil {
+				data = []T{}
+			}
+		}
+		return Data(w, options.Format, data)
+	}
+
+	columns, err := selectColumns(table.Columns, options)
+	if err != nil {
+		return err
+	}
+	if len(table.Rows) == 0 && table.Empty != "" {
+		_, err := fmt.Fprintln(w, table.Empty)
+		return err
+	}
+
+	// Widths are measured on the plain values, so styles and wide
+	// characters don't throw the alignment off
+	cells := make([][]string, len(table.Rows))
+	widths := make([]int, len(columns))
+	for i, column := range columns {
+		widths[i] = lipgloss.Width(column.Name)
+	}
+	for r, row := range table.Rows {
+		cells[r] = make([]string, len(columns))
+		for i, column := range columns {
+			cells[r][i] = column.Value(row)
+			widths[i] = max(widths[i], lipgloss.Width(cells[r][i]))
+		}
+	}
+
+	header := make([]string, len(columns))
+	for i, column := range columns {
+		header[i] = column.Name
+		if options.Color {
+			header[i] = headerStyle.Render(column.Name)
+		}
+	}
+	if err := writeRow(w, header, widths); err != nil {
+		return err
+	}
+	for r, row := range table.Rows {
+		styled := make([]string, len(columns))
+		for i, column := range columns {
+			styled[i] = cells[r][i]
+			if options.Color && column.Style != nil {
+				styled[i] = column.Style(row, cells[r][i])
+			}
+		}
+		if err := writeRow(w, styled, widths); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Data prints data as JSON or YAML
+func Data(w io.Writer, format Format, data any) error {
+	if format == FormatYAML {
+		encoder := yaml.NewEncoder(w)
+		if err := encoder.Encode(data); err != nil {
+			return err
+		}
+		return encoder.Close()
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(data)
+}
+
+// writeRow prints the cells of a row padded to widths, without trailing
+// spaces
+func writeRow(w io.Writer, cells []string, widths []int) error {
+	var line strings.Builder
+	for i, cell := range cells {
+		line.WriteString(cell)
+		if i < len(cells)-1 {
+			line.WriteString(strings.Repeat(" ", widths[i]-lipgloss.Width(cell)))
+			line.WriteString(columnGap)
+		}
+	}
+	_, err := fmt.Fprintln(w, strings.TrimRight(line.String(), " "))
+	return err
+}
+
+// selectColumns returns the columns options show: those --columns names,
+// or the narrow ones, and the wide ones too for -o wide
+func selectColumns[T any](columns []Column[T], options Options) ([]Column[T], error) {
+	if len(options.Columns) == 0 {
+		var selected []Column[T]
+		for _, column := range columns {
+			if !column.Wide || options.Format == FormatWide {
+				selected = append(selected, column)
+			}
+		}
+		return selected, nil
+	}
+
+	selected := make([]Column[T], 0, len(options.Columns))
+	for _, name := range options.Columns {
+		found := false
+		for _, column := range columns {
+			if columnKey(column.Name) == columnKey(name) {
+				selected = append(selected, column)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("unknown column %q (available: %s)", name, strings.Join(ColumnNames(columns), ", "))
+		}
+	}
+	return selected, nil
+}
+
+// ColumnNames returns the names --columns selects columns by
+func ColumnNames[T any](columns []Column[T]) []string {
+	names := make([]string, 0, len(columns))
+	for _, column := range columns {
+		names = append(names, columnKey(column.Name))
+	}
+	return names
+}
+
+// columnKey is the name of a column as --columns takes it
+func columnKey(name string) string {
+	return strings.ToLower(strings.NewReplacer(" ", "-", "_", "-").Replace(strings.TrimSpace(name)))
+}
+
+// ParseColumns splits the value of --columns, a comma-separated list
+func ParseColumns(value string) []string {
+	var columns []string
+	for _, name := range strings.Split(value, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			columns = append(columns, name)
+		}
+	}
+	return columns
+}
@@ -0,0 +1,89 @@
+package render
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type port struct {
+	Service  string `json:"service" yaml:"service"`
+	Host     string `json:"host" yaml:"host"`
+	Protocol string `json:"protocol" yaml:"protocol"`
+}
+
+var portColumns = []Column[port]{
+	{Name: "SERVICE", Value: func(p port) string { return p.Service }},
+	{Name: "HOST PORT", Value: func(p port) string { return p.Host }},
+	{Name: "PROTOCOL", Wide: true, Value: func(p port) string { return p.Protocol }},
+}
+
+func renderPorts(t *testing.T, table Table[port], options Options) string {
+	t.Helper()
+	var out bytes.Buffer
+	require.NoError(t, Render(&out, table, options))
+	return out.String()
+}
+
+func TestRender_Table(t *testing.T) {
+	table := Table[port]{Columns: portColumns, Rows: []port{
+		{Service: "postgres", Host: "5432", Protocol: "tcp"},
+		{Service: "🟢 redis", Host: "", Protocol: "tcp"},
+	}}
+
+	assert.Equal(t, "SERVICE   HOST PORT\npostgres  5432\n🟢 redis\n", renderPorts(t, table, Options{Format: FormatTable}))
+	assert.Equal(t, "SERVICE   HOST PORT  PROTOCOL\npostgres  5432       tcp\n🟢 redis             tcp\n", renderPorts(t, table, Options{Format: FormatWide}))
+	assert.Equal(t, "PROTOCOL  SERVICE\ntcp       postgres\ntcp       🟢 redis\n", renderPorts(t, table, Options{Columns: []string{"protocol", "Service"}}))
+	assert.Equal(t, "HOST PORT\n5432\n\n", renderPorts(t, table, Options{Columns: []string{"host_port"}}))
+
+	err := Render(&bytes.Buffer{}, table, Options{Columns: []string{"state"}})
+	assert.EqualError(t, err, `unknown column "state" (available: service, host-port, protocol)`)
+
+	table.Rows = nil
+	assert.Equal(t, "SERVICE  HOST PORT\n", renderPorts(t, table, Options{}))
+	table.Empty = "No ports"
+	assert.Equal(t, "No ports\n", renderPorts(t, table, Options{}))
+}
+
+func TestRender_Color(t *testing.T) {
+	columns := []Column[port]{{
+		Name:  "SERVICE",
+		Value: func(p port) string { return p.Service },
+		Style: func(p port, value string) string { return "\x1b[32m" + value + "\x1b[0m" },
+	}, portColumns[1]}
+	table := Table[port]{Columns: columns, Rows: []port{{Service: "db", Host: "5432"}}}
+
+	// Styles don't count towards the width of a column
+	out := renderPorts(t, table, Options{Color: true})
+	assert.Contains(t, out, "\x1b[32mdb\x1b[0m       5432\n")
+	assert.Equal(t, "SERVICE  HOST PORT\ndb       5432\n", renderPorts(t, table, Options{}))
+}
+
+func TestRender_Data(t *testing.T) {
+	table := Table[port]{Columns: portColumns, Rows: []port{{Service: "postgres", Host: "5432", Protocol: "tcp"}}}
+
+	assert.JSONEq(t, `[{"service": "postgres", "host": "5432", "protocol": "tcp"}]`, renderPorts(t, table, Options{Format: FormatJSON}))
+	assert.Equal(t, "- service: postgres\n  host: \"5432\"\n  protocol: tcp\n", renderPorts(t, table, Options{Format: FormatYAML}))
+
+	// Columns only select what tables show
+	table.Data = map[string]int{"count": 1}
+	assert.JSONEq(t, `{"count": 1}`, renderPorts(t, table, Options{Format: FormatJSON, Columns: []string{"service"}}))
+
+	assert.JSONEq(t, `[]`, renderPorts(t, Table[port]{Columns: portColumns}, Options{Format: FormatJSON}))
+}
+
+func TestParseFormat(t *testing.T) {
+	for value, expected := range map[string]Format{"": FormatTable, "table": FormatTable, "WIDE": FormatWide, "json": FormatJSON, "yml": FormatYAML} {
+		format, err := ParseFormat(value)
+		require.NoError(t, err)
+		assert.Equal(t, expected, format)
+	}
+
+	_, err := ParseFormat("csv")
+	assert.EqualError(t, err, `unsupported output format "csv" (supported: table, wide, json, yaml)`)
+
+	assert.Equal(t, []string{"service", "health"}, ParseColumns(" service, ,health,"))
+	assert.Nil(t, ParseColumns(""))
+}
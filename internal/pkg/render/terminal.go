@@ -0,0 +1,22 @@
+package render
+
+import (
+	"io"
+
+	"golang.org/x/term"
+
+	"github.com/isaacgarza/dev-stack/internal/pkg/ui"
+)
+
+// IsTerminal reports whether w is an interactive terminal
+func IsTerminal(w io.Writer) bool {
+	file, ok := w.(interface{ Fd() uintptr })
+	return ok && term.IsTerminal(int(file.Fd()))
+}
+
+// Colored reports whether tables written to w get colors: never when
+// --no-color, the color setting or NO_COLOR turned them off, and otherwise
+// only on a terminal, so piped tables stay plain text
+func Colored(w io.Writer) bool {
+	return !ui.DefaultOutput.NoColor && IsTerminal(w)
+}
@@ -0,0 +1,188 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/isaacgarza/dev-stack/internal/config"
+	"github.com/isaacgarza/dev-stack/internal/pkg/constants"
+	"gopkg.in/yaml.v3"
+)
+
+// Where a service definition comes from
+const (
+	SourceBuiltin = "built-in"
+	SourceProject = "project"
+)
+
+// customCategory is the category of project definitions that name none
+const customCategory = "custom"
+
+// ServiceFile locates a service definition in the built-in catalog or in the
+// project's dev-stack/services directory
+type ServiceFile struct {
+	Name     string
+	Category string
+	Source   string
+	// Path is the definition's path in the embedded filesystem for built-in
+	// services, and on disk for project services
+	Path string
+	// Overrides is set on project definitions that replace a built-in one
+	Overrides bool
+}
+
+// Read returns the definition's YAML
+func (f ServiceFile) Read() ([]byte, error) {
+	if f.Source == SourceProject {
+		return os.ReadFile(f.Path)
+	}
+	return config.EmbeddedServicesFS.ReadFile(f.Path)
+}
+
+// ProjectServicesDir returns the directory projects put their own service
+// definitions in
+func ProjectServicesDir() string {
+	return filepath.Join(constants.DevStackDir, constants.CustomServicesDir)
+}
+
+// ListServiceFiles returns every service definition sorted by name. A project
+// definition replaces the built-in one of the same name.
+func ListServiceFiles() ([]ServiceFile, error) {
+	files, err := builtinServiceFiles()
+	if err != nil {
+		return nil, err
+	}
+
+	project, err := projectServiceFiles(ProjectServicesDir())
+	if err != nil {
+		return nil, err
+	}
+
+	byName := make(map[string]int, len(files))
+	for i, file := range files {
+		byName[file.Name] = i
+	}
+	for _, file := range project {
+		if i, ok := byName[file.Name]; ok {
+			if files[i].Source == SourceBuiltin {
+				file.Overrides = true
+			}
+			files[i] = file
+			continue
+		}
+		byName[file.Name] = len(files)
+		files = append(files, file)
+	}
+
+	sort.Slice(files, func(i, j int) bool { return files[i].Name < files[j].Name })
+	return files, nil
+}
+
+// FindServiceFile returns the definition of a service, preferring the
+// project's own over the built-in one
+func FindServiceFile(serviceName string) (ServiceFile, error) {
+	files, err := ListServiceFiles()
+	if err != nil {
+		return ServiceFile{}, err
+	}
+	for _, file := range files {
+		if file.Name == serviceName {
+			return file, nil
+		}
+	}
+	return ServiceFile{}, fmt.Errorf("service file not found for %s", serviceName)
+}
+
+func builtinServiceFiles() ([]ServiceFile, error) {
+	categories, err := config.EmbeddedServicesFS.ReadDir("services")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read embedded services: %w", err)
+	}
+
+	var files []ServiceFile
+	for _, category := range categories {
+		if !category.IsDir() {
+			continue
+		}
+		categoryPath := path.Join("services", category.Name())
+		entries, err := config.EmbeddedServicesFS.ReadDir(categoryPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read embedded services: %w", err)
+		}
+		for _, entry := range entries {
+			if entry.IsDir() || !strings.HasSuffix(entry.Name(), constants.ServiceConfigExtension) {
+				continue
+			}
+			files = append(files, ServiceFile{
+				Name:     strings.TrimSuffix(entry.Name(), constants.ServiceConfigExtension),
+				Category: category.Name(),
+				Source:   SourceBuiltin,
+				Path:     path.Join(categoryPath, entry.Name()),
+			})
+		}
+	}
+	return files, nil
+}
+
+// projectServiceFiles reads definitions from dir, either directly in it or
+// in category subdirectories as in the built-in catalog. Definitions at the
+// top level take their category from the file, then default to "custom".
+func projectServiceFiles(dir string) ([]ServiceFile, error) {
+	var files []ServiceFile
+	err := filepath.WalkDir(dir, func(filePath string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) && filePath == dir {
+				return fs.SkipAll
+			}
+			return err
+		}
+		if entry.IsDir() {
+			if filePath != dir && filepath.Dir(filePath) != dir {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if !strings.HasSuffix(entry.Name(), constants.ServiceConfigExtension) {
+			return nil
+		}
+
+		file := ServiceFile{
+			Name:   strings.TrimSuffix(entry.Name(), constants.ServiceConfigExtension),
+			Source: SourceProject,
+			Path:   filePath,
+		}
+		if parent := filepath.Dir(filePath); parent != dir {
+			file.Category = filepath.Base(parent)
+		} else {
+			file.Category = declaredCategory(filePath)
+		}
+		files = append(files, file)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read project services in %s: %w", dir, err)
+	}
+	return files, nil
+}
+
+// declaredCategory returns the category a definition file names, or the
+// custom category if it names none or cannot be parsed
+func declaredCategory(filePath string) string {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return customCategory
+	}
+	var definition struct {
+		Category string `yaml:"category"`
+	}
+	if err := yaml.Unmarshal(data, &definition); err != nil || definition.Category == "" {
+		return customCategory
+	}
+	return definition.Category
+}
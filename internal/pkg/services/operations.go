@@ -2,12 +2,11 @@ package services
 
 import (
 	"fmt"
-	"os"
-	"path/filepath"
+	"slices"
+	"sort"
 	"strings"
 	"text/template"
 
-	"github.com/isaacgarza/dev-stack/internal/pkg/constants"
 	"gopkg.in/yaml.v3"
 )
 
@@ -16,6 +15,7 @@ type ServiceOperations struct {
 	Connect *ConnectOperation `yaml:"connect,omitempty"`
 	Backup  *BackupOperation  `yaml:"backup,omitempty"`
 	Restore *RestoreOperation `yaml:"restore,omitempty"`
+	Seed    *SeedOperation    `yaml:"seed,omitempty"`
 }
 
 // ConnectOperation defines how to connect to a service
@@ -23,6 +23,49 @@ type ConnectOperation struct {
 	Command  []string            `yaml:"command"`
 	Args     map[string][]string `yaml:"args,omitempty"`
 	Defaults map[string]string   `yaml:"defaults,omitempty"`
+	// Env maps parameters to the container environment variables holding
+	// them, such as password: POSTGRES_PASSWORD, so the client uses the
+	// settings the container was started with instead of the defaults
+	Env map[string]string `yaml:"env,omitempty"`
+	// ClientEnv is the environment of the client, rendered like the
+	// command. It keeps passwords off the command line.
+	ClientEnv map[string]string `yaml:"client_env,omitempty"`
+	// Port is the container port clients on the host connect to; the port
+	// it is published on is available as {{.HostPort}}
+	Port string `yaml:"port,omitempty"`
+	// URL is the connection string for clients on the host
+	URL string `yaml:"url,omitempty"`
+	// Clients are alternatives to Command, tried in order before it
+	Clients []ConnectClient `yaml:"clients,omitempty"`
+	// UI is the service's web interface
+	UI *ConnectUI `yaml:"ui,omitempty"`
+}
+
+// ConnectClientContainer names the operation's own command when selecting a
+// client
+const ConnectClientContainer = "container"
+
+// ConnectClient is a client connect can use instead of the operation's
+// command, with its own command, arguments and environment rendered from
+// the same parameters
+type ConnectClient struct {
+	Name string `yaml:"name"`
+	// Host runs the client on the host rather than in the container; it is
+	// only used when its executable is installed
+	Host bool `yaml:"host,omitempty"`
+	// When names a parameter the client needs, such as topic; the client is
+	// skipped while that parameter is empty
+	When      string              `yaml:"when,omitempty"`
+	Command   []string            `yaml:"command"`
+	Args      map[string][]string `yaml:"args,omitempty"`
+	ClientEnv map[string]string   `yaml:"client_env,omitempty"`
+}
+
+// ConnectUI is a web interface of a service. URL is rendered with the port
+// Port is published on as {{.HostPort}}.
+type ConnectUI struct {
+	Port string `yaml:"port"`
+	URL  string `yaml:"url"`
 }
 
 // BackupOperation defines how to backup a service
@@ -33,6 +76,12 @@ type BackupOperation struct {
 	Args      map[string][]string `yaml:"args,omitempty"`
 	Defaults  map[string]string   `yaml:"defaults,omitempty"`
 	Extension string              `yaml:"extension"`
+	// File is a path inside the container copied out once the commands have
+	// run. Without it the backup is the stdout of the last command.
+	File string `yaml:"file,omitempty"`
+	// Archive copies File as a tar archive of the directory instead of as a
+	// single file
+	Archive bool `yaml:"archive,omitempty"`
 }
 
 // RestoreOperation defines how to restore a service
@@ -44,6 +93,23 @@ type RestoreOperation struct {
 	Args            map[string][]string   `yaml:"args,omitempty"`
 	Defaults        map[string]string     `yaml:"defaults,omitempty"`
 	RequiresRestart bool                  `yaml:"requires_restart,omitempty"`
+	// File is a path inside the container the backup is copied to before the
+	// commands run. Without it the backup is piped to the last command's stdin.
+	File string `yaml:"file,omitempty"`
+	// Archive extracts the backup, a tar archive of File, in place of the
+	// directory instead of copying it as a single file
+	Archive bool `yaml:"archive,omitempty"`
+}
+
+// SeedOperation defines how fixtures from dev-stack/seeds are applied
+type SeedOperation struct {
+	Type       string            `yaml:"type"` // "command" or "kafka"
+	Extensions []string          `yaml:"extensions"`
+	Command    []string          `yaml:"command,omitempty"` // fixture is piped to stdin
+	Defaults   map[string]string `yaml:"defaults,omitempty"`
+	// MarkerFile records applied fixtures; keep it on the data volume so the
+	// markers are reset together with the data
+	MarkerFile string `yaml:"marker_file"`
 }
 
 // ServiceConfig represents a service configuration with operations
@@ -55,49 +121,155 @@ type ServiceConfig struct {
 // LoadServiceOperations loads operations for a service from its YAML file
 func LoadServiceOperations(serviceName string) (*ServiceOperations, error) {
 	// Find service YAML file
-	serviceFile, err := findServiceFile(serviceName)
+	serviceFile, err := FindServiceFile(serviceName)
 	if err != nil {
 		return nil, fmt.Errorf("service file not found for %s: %w", serviceName, err)
 	}
 
-	data, err := os.ReadFile(serviceFile)
+	data, err := serviceFile.Read()
 	if err != nil {
-		return nil, fmt.Errorf("failed to read service file %s: %w", serviceFile, err)
+		return nil, fmt.Errorf("failed to read service file %s: %w", serviceFile.Path, err)
 	}
 
-	var config ServiceConfig
-	if err := yaml.Unmarshal(data, &config); err != nil {
-		return nil, fmt.Errorf("failed to parse service config %s: %w", serviceFile, err)
+	var serviceConfig ServiceConfig
+	if err := yaml.Unmarshal(data, &serviceConfig); err != nil {
+		return nil, fmt.Errorf("failed to parse service config %s: %w", serviceFile.Path, err)
 	}
 
-	return config.Operations, nil
+	return serviceConfig.Operations, nil
 }
 
-// BuildConnectCommand builds a connection command for a service
+// Params resolves the connection parameters: the operation's defaults,
+// overridden by the values lookup finds for the variables in Env, overridden
+// by the non-empty options
+func (op *ConnectOperation) Params(options map[string]string, lookup func(string) (string, bool)) map[string]string {
+	if op == nil {
+		return nil
+	}
+	resolved := make(map[string]string, len(op.Env))
+	for param, variable := range op.Env {
+		if value, ok := lookup(variable); ok && value != "" {
+			resolved[param] = value
+		}
+	}
+	return mergeParams(mergeParams(op.Defaults, resolved), options)
+}
+
+// BuildCommand builds a connection command for a service
 func (op *ConnectOperation) BuildCommand(options map[string]string) []string {
 	if op == nil {
 		return nil
 	}
+	return buildConnectCommand(op.Command, op.Args, mergeParams(op.Defaults, options))
+}
+
+// BuildEnv renders the client environment as KEY=value pairs, leaving out
+// variables that render empty
+func (op *ConnectOperation) BuildEnv(options map[string]string) []string {
+	if op == nil {
+		return nil
+	}
+	return buildConnectEnv(op.ClientEnv, mergeParams(op.Defaults, options))
+}
 
-	cmd := make([]string, len(op.Command))
-	copy(cmd, op.Command)
+// BuildURL renders the connection string for clients on the host, or
+// returns an empty string when the service has none
+func (op *ConnectOperation) BuildURL(options map[string]string) string {
+	if op == nil || op.URL == "" {
+		return ""
+	}
+	return renderTemplate(op.URL, mergeParams(op.Defaults, options))
+}
 
-	// Apply defaults first
-	params := make(map[string]string)
-	for k, v := range op.Defaults {
-		params[k] = v
+// BuildUIURL renders the URL of the web interface given the host port it is
+// published on, or returns an empty string when the service has none
+func (op *ConnectOperation) BuildUIURL(options map[string]string, hostPort string) string {
+	if op == nil || op.UI == nil || op.UI.URL == "" {
+		return ""
 	}
-	// Override with provided options
-	for k, v := range options {
-		params[k] = v
+	params := mergeParams(op.Defaults, options)
+	params["hostPort"] = hostPort
+	return renderTemplate(op.UI.URL, params)
+}
+
+// SelectClient picks the client to connect with. A name selects that
+// client, or the operation's command for ConnectClientContainer. Otherwise
+// the first usable client is picked: its When parameter is set and, for a
+// host client, installed reports its executable as available. A nil client
+// means the operation's command.
+func (op *ConnectOperation) SelectClient(name string, options map[string]string, installed func(string) bool) (*ConnectClient, error) {
+	if op == nil || name == ConnectClientContainer {
+		return nil, nil
+	}
+	params := mergeParams(op.Defaults, options)
+
+	missing := ""
+	for i := range op.Clients {
+		client := &op.Clients[i]
+		if name != "" && client.Name != name {
+			continue
+		}
+		if client.When != "" && params[client.When] == "" {
+			missing = client.When
+			continue
+		}
+		if client.Host && (len(client.Command) == 0 || !installed(client.Command[0])) {
+			if name != "" {
+				return nil, fmt.Errorf("client %s is not installed", name)
+			}
+			continue
+		}
+		return client, nil
+	}
+
+	if name != "" && missing != "" {
+		return nil, fmt.Errorf("client %s needs the %s parameter", name, missing)
+	}
+	if name != "" {
+		return nil, fmt.Errorf("unknown client %s (available: %s)", name, strings.Join(op.ClientNames(), ", "))
+	}
+	return nil, nil
+}
+
+// ClientNames returns the names the clients can be selected with
+func (op *ConnectOperation) ClientNames() []string {
+	names := []string{ConnectClientContainer}
+	for _, client := range op.Clients {
+		if !slices.Contains(names, client.Name) {
+			names = append(names, client.Name)
+		}
+	}
+	return names
+}
+
+// BuildCommand builds the client's command from resolved parameters
+func (c *ConnectClient) BuildCommand(params map[string]string) []string {
+	return buildConnectCommand(c.Command, c.Args, params)
+}
+
+// BuildEnv renders the client's environment from resolved parameters
+func (c *ConnectClient) BuildEnv(params map[string]string) []string {
+	return buildConnectEnv(c.ClientEnv, params)
+}
+
+// buildConnectCommand renders a client command and appends the arguments of
+// the parameters that are set. Parts of the command that are templates
+// rendering empty are left out.
+func buildConnectCommand(command []string, args map[string][]string, params map[string]string) []string {
+	cmd := make([]string, 0, len(command))
+	for _, part := range command {
+		rendered := renderTemplate(part, params)
+		if rendered == "" && strings.Contains(part, "{{") {
+			continue
+		}
+		cmd = append(cmd, rendered)
 	}
 
-	// Add arguments based on parameters
-	for param, value := range params {
-		if argTemplate, exists := op.Args[param]; exists && value != "" {
+	// Add arguments in a stable order
+	for _, param := range sortedKeys(params) {
+		if argTemplate, exists := args[param]; exists && params[param] != "" {
 			for _, arg := range argTemplate {
-				rendered := strings.ReplaceAll(arg, "{{."+strings.ToUpper(param[:1])+param[1:]+"}}", value)
-				cmd = append(cmd, rendered)
+				cmd = append(cmd, renderTemplate(arg, params))
 			}
 		}
 	}
@@ -105,19 +277,25 @@ func (op *ConnectOperation) BuildCommand(options map[string]string) []string {
 	return cmd
 }
 
+// buildConnectEnv renders a client environment as sorted KEY=value pairs,
+// leaving out variables that render empty
+func buildConnectEnv(clientEnv map[string]string, params map[string]string) []string {
+	var env []string
+	for _, key := range sortedKeys(clientEnv) {
+		if value := renderTemplate(clientEnv[key], params); value != "" {
+			env = append(env, key+"="+value)
+		}
+	}
+	return env
+}
+
 // BuildBackupCommand builds a backup command for a service
 func (op *BackupOperation) BuildCommand(options map[string]string) ([][]string, error) {
 	if op == nil {
 		return nil, fmt.Errorf("no backup operation defined")
 	}
 
-	params := make(map[string]string)
-	for k, v := range op.Defaults {
-		params[k] = v
-	}
-	for k, v := range options {
-		params[k] = v
-	}
+	params := mergeParams(op.Defaults, options)
 
 	var commands [][]string
 
@@ -133,10 +311,13 @@ func (op *BackupOperation) BuildCommand(options map[string]string) ([][]string,
 	} else if len(op.Command) > 0 {
 		// Single command
 		cmd := make([]string, len(op.Command))
-		copy(cmd, op.Command)
+		for i, part := range op.Command {
+			cmd[i] = renderTemplate(part, params)
+		}
 
-		// Add arguments
-		for param, value := range params {
+		// Add arguments in a stable order
+		for _, param := range sortedKeys(params) {
+			value := params[param]
 			if argTemplate, exists := op.Args[param]; exists && value != "" {
 				for _, arg := range argTemplate {
 					rendered := renderTemplate(arg, params)
@@ -150,46 +331,151 @@ func (op *BackupOperation) BuildCommand(options map[string]string) ([][]string,
 	return commands, nil
 }
 
-// GetBackupExtension returns the file extension for backups
-func (op *BackupOperation) GetBackupExtension() string {
-	if op == nil || op.Extension == "" {
-		return "backup"
+// BuildPreCommands renders the named group of pre-commands, such as "clean"
+func (op *RestoreOperation) BuildPreCommands(name string, options map[string]string) [][]string {
+	if op == nil {
+		return nil
 	}
-	return op.Extension
+	params := mergeParams(op.Defaults, options)
+
+	var commands [][]string
+	for _, cmdTemplate := range op.PreCommands[name] {
+		cmd := make([]string, len(cmdTemplate))
+		for i, part := range cmdTemplate {
+			cmd[i] = renderTemplate(part, params)
+		}
+		commands = append(commands, cmd)
+	}
+	return commands
 }
 
-// findServiceFile finds the YAML file for a service
-func findServiceFile(serviceName string) (string, error) {
-	// Search in all service directories
-	servicesDirs := []string{
-		filepath.Join(constants.ServicesDir, "cache"),
-		filepath.Join(constants.ServicesDir, "database"),
-		filepath.Join(constants.ServicesDir, "messaging"),
-		filepath.Join(constants.ServicesDir, "observability"),
-		filepath.Join(constants.ServicesDir, "cloud"),
+// BuildCommand builds the restore commands for a service
+func (op *RestoreOperation) BuildCommand(options map[string]string) ([][]string, error) {
+	if op == nil {
+		return nil, fmt.Errorf("no restore operation defined")
 	}
+	params := mergeParams(op.Defaults, options)
 
-	for _, dir := range servicesDirs {
-		serviceFile := filepath.Join(dir, serviceName+".yaml")
-		if _, err := os.Stat(serviceFile); err == nil {
-			return serviceFile, nil
+	var commands [][]string
+	if op.Type == "custom" && len(op.Commands) > 0 {
+		for _, cmdTemplate := range op.Commands {
+			cmd := make([]string, len(cmdTemplate))
+			for i, part := range cmdTemplate {
+				cmd[i] = renderTemplate(part, params)
+			}
+			commands = append(commands, cmd)
+		}
+	} else if len(op.Command) > 0 {
+		cmd := make([]string, len(op.Command))
+		for i, part := range op.Command {
+			cmd[i] = renderTemplate(part, params)
+		}
+		for _, param := range sortedKeys(params) {
+			if argTemplate, exists := op.Args[param]; exists && params[param] != "" {
+				for _, arg := range argTemplate {
+					cmd = append(cmd, renderTemplate(arg, params))
+				}
+			}
 		}
+		commands = append(commands, cmd)
+	}
+
+	return commands, nil
+}
+
+// BuildCommand builds the command a fixture is piped to. The fixture's file
+// name is available as {{.File}} and its name without extension as {{.Name}}.
+func (op *SeedOperation) BuildCommand(options map[string]string) []string {
+	if op == nil {
+		return nil
 	}
+	params := mergeParams(op.Defaults, options)
 
-	return "", fmt.Errorf("service file not found for %s", serviceName)
+	cmd := make([]string, len(op.Command))
+	for i, part := range op.Command {
+		cmd[i] = renderTemplate(part, params)
+	}
+	return cmd
+}
+
+// GetBackupExtension returns the file extension for backups
+func (op *BackupOperation) GetBackupExtension() string {
+	if op == nil || op.Extension == "" {
+		return "backup"
+	}
+	return op.Extension
 }
 
-// renderTemplate renders a template string with parameters
+// renderTemplate renders a template string with parameters. Parameters are
+// passed with lowercase keys while templates reference them capitalised, as
+// in {{.User}}.
 func renderTemplate(templateStr string, params map[string]string) string {
-	tmpl, err := template.New("cmd").Parse(templateStr)
+	tmpl, err := template.New("cmd").Option("missingkey=zero").Funcs(templateFuncs).Parse(templateStr)
 	if err != nil {
 		return templateStr // Return original if parsing fails
 	}
 
+	data := make(map[string]string, len(params))
+	for key, value := range params {
+		if key != "" {
+			data[strings.ToUpper(key[:1])+key[1:]] = value
+		}
+	}
+
 	var result strings.Builder
-	if err := tmpl.Execute(&result, params); err != nil {
+	if err := tmpl.Execute(&result, data); err != nil {
 		return templateStr // Return original if execution fails
 	}
 
 	return result.String()
 }
+
+// templateFuncs are the functions available to operation templates
+var templateFuncs = template.FuncMap{
+	"listener": listenerAddress,
+}
+
+// listenerAddress returns the host:port of the named listener in a Kafka
+// style listener list such as "INTERNAL://kafka:29092,HOST://localhost:9092",
+// falling back to the first listener
+func listenerAddress(name, listeners string) string {
+	first := ""
+	for _, listener := range strings.Split(listeners, ",") {
+		listenerName, address, ok := strings.Cut(strings.TrimSpace(listener), "://")
+		if !ok {
+			continue
+		}
+		if listenerName == name {
+			return address
+		}
+		if first == "" {
+			first = address
+		}
+	}
+	return first
+}
+
+// mergeParams overlays the provided options on an operation's defaults,
+// ignoring empty options so defaults still apply
+func mergeParams(defaults, options map[string]string) map[string]string {
+	params := make(map[string]string, len(defaults)+len(options))
+	for k, v := range defaults {
+		params[k] = v
+	}
+	for k, v := range options {
+		if v != "" {
+			params[k] = v
+		}
+	}
+	return params
+}
+
+// sortedKeys returns the keys of a parameter map in sorted order
+func sortedKeys(params map[string]string) []string {
+	keys := make([]string, 0, len(params))
+	for key := range params {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -8,6 +8,8 @@ import (
 	"strings"
 
 	"gopkg.in/yaml.v3"
+
+	"github.com/isaacgarza/dev-stack/internal/pkg/types"
 )
 
 // ServiceRegistry manages service definitions and validation
@@ -30,6 +32,9 @@ type ServiceDefinition struct {
 	HealthCheck  HealthCheckConfig `yaml:"health_check,omitempty"`
 	Dependencies []string          `yaml:"dependencies,omitempty"`
 	Tags         []string          `yaml:"tags,omitempty"`
+
+	// Source is SourceProject for definitions read from dev-stack/services
+	Source string `yaml:"-"`
 }
 
 // HealthCheckConfig represents health check configuration
@@ -83,9 +88,62 @@ func (r *ServiceRegistry) Load() error {
 		if err := r.validateServiceDefinition(name, definition); err != nil {
 			return fmt.Errorf("invalid service definition for %s: %w", name, err)
 		}
+		definition.Source = SourceBuiltin
 		r.services[name] = definition
 	}
 
+	return r.loadProjectServices(ProjectServicesDir())
+}
+
+// loadProjectServices merges the definitions in dir over the manifest's,
+// replacing services of the same name
+func (r *ServiceRegistry) loadProjectServices(dir string) error {
+	files, err := projectServiceFiles(dir)
+	if err != nil {
+		return err
+	}
+
+	for _, file := range files {
+		data, err := file.Read()
+		if err != nil {
+			return fmt.Errorf("failed to read service definition %s: %w", file.Path, err)
+		}
+
+		var serviceFile struct {
+			Description  string   `yaml:"description"`
+			Options      []string `yaml:"options"`
+			Examples     []string `yaml:"examples"`
+			UsageNotes   string   `yaml:"usage_notes"`
+			Links        []string `yaml:"links"`
+			Dependencies struct {
+				Required []string `yaml:"required"`
+				Provides []string `yaml:"provides"`
+			} `yaml:"dependencies"`
+			Defaults struct {
+				Port int `yaml:"port"`
+			} `yaml:"defaults"`
+		}
+		if err := yaml.Unmarshal(data, &serviceFile); err != nil {
+			return fmt.Errorf("failed to parse service definition %s: %w", file.Path, err)
+		}
+
+		definition := ServiceDefinition{
+			Description:  serviceFile.Description,
+			Options:      serviceFile.Options,
+			Examples:     serviceFile.Examples,
+			UsageNotes:   serviceFile.UsageNotes,
+			Links:        serviceFile.Links,
+			Category:     file.Category,
+			DefaultPort:  serviceFile.Defaults.Port,
+			Dependencies: serviceFile.Dependencies.Required,
+			Tags:         serviceFile.Dependencies.Provides,
+			Source:       SourceProject,
+		}
+		if err := r.validateServiceDefinition(file.Name, definition); err != nil {
+			return fmt.Errorf("invalid service definition for %s: %w", file.Name, err)
+		}
+		r.services[file.Name] = definition
+	}
 	return nil
 }
 
@@ -152,7 +210,7 @@ func (r *ServiceRegistry) GetServicesByTag(tag string) []string {
 func (r *ServiceRegistry) ValidateService(name string) error {
 	if _, exists := r.services[name]; !exists {
 		available := r.GetServiceNames()
-		return fmt.Errorf("unknown service '%s'. Available services: %v", name, available)
+		return &types.ServiceNotFoundError{Service: name, Available: available}
 	}
 	return nil
 }
@@ -171,7 +229,7 @@ func (r *ServiceRegistry) ValidateServices(names []string) error {
 func (r *ServiceRegistry) GetServiceDependencies(name string) ([]string, error) {
 	service, exists := r.GetService(name)
 	if !exists {
-		return nil, fmt.Errorf("service %s not found", name)
+		return nil, &types.ServiceNotFoundError{Service: name}
 	}
 	return service.Dependencies, nil
 }
@@ -245,7 +303,7 @@ func (r *ServiceRegistry) SearchServices(query string) []string {
 func (r *ServiceRegistry) GetServiceInfo(name string) (string, error) {
 	service, exists := r.GetService(name)
 	if !exists {
-		return "", fmt.Errorf("service %s not found", name)
+		return "", &types.ServiceNotFoundError{Service: name}
 	}
 
 	var info strings.Builder
@@ -0,0 +1,109 @@
+package types
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Attribute types a DynamoDB key can have
+var awsKeyTypes = []string{"S", "N", "B"}
+
+// AWSConfig declares the AWS resources a project needs. They are created
+// in LocalStack once it is healthy; resources that already exist are left
+// as they are.
+type AWSConfig struct {
+	// Region resources are created in; defaults to us-east-1
+	Region  string      `yaml:"region,omitempty" json:"region,omitempty"`
+	Buckets []AWSBucket `yaml:"buckets,omitempty" json:"buckets,omitempty"`
+	Queues  []AWSQueue  `yaml:"queues,omitempty" json:"queues,omitempty"`
+	Tables  []AWSTable  `yaml:"tables,omitempty" json:"tables,omitempty"`
+	Topics  []AWSTopic  `yaml:"topics,omitempty" json:"topics,omitempty"`
+}
+
+// AWSBucket is an S3 bucket
+type AWSBucket struct {
+	Name       string `yaml:"name" json:"name"`
+	Versioning bool   `yaml:"versioning,omitempty" json:"versioning,omitempty"`
+}
+
+// AWSQueue is an SQS queue. FIFO queue names end in .fifo.
+type AWSQueue struct {
+	Name string `yaml:"name" json:"name"`
+	FIFO bool   `yaml:"fifo,omitempty" json:"fifo,omitempty"`
+	// VisibilityTimeout in seconds; 0 keeps the SQS default
+	VisibilityTimeout int `yaml:"visibility_timeout,omitempty" json:"visibility_timeout,omitempty"`
+}
+
+// AWSTable is a DynamoDB table billed per request
+type AWSTable struct {
+	Name         string `yaml:"name" json:"name"`
+	PartitionKey AWSKey `yaml:"partition_key" json:"partition_key"`
+	SortKey      AWSKey `yaml:"sort_key,omitempty" json:"sort_key,omitempty"`
+}
+
+// AWSKey is a key attribute of a DynamoDB table
+type AWSKey struct {
+	Name string `yaml:"name" json:"name"`
+	// Type is S, N or B; defaults to S
+	Type string `yaml:"type,omitempty" json:"type,omitempty"`
+}
+
+// AWSTopic is an SNS topic, optionally delivering to SQS queues
+type AWSTopic struct {
+	Name string `yaml:"name" json:"name"`
+	// Subscriptions name the queues subscribed to the topic
+	Subscriptions []string `yaml:"subscriptions,omitempty" json:"subscriptions,omitempty"`
+}
+
+// IsZero reports whether no resources are declared
+func (c AWSConfig) IsZero() bool {
+	return len(c.Buckets) == 0 && len(c.Queues) == 0 && len(c.Tables) == 0 && len(c.Topics) == 0
+}
+
+// KeyType returns the attribute type of the key
+func (k AWSKey) KeyType() string {
+	if k.Type == "" {
+		return "S"
+	}
+	return k.Type
+}
+
+// Validate checks that every resource is named and well-formed
+func (c AWSConfig) Validate() error {
+	for _, bucket := range c.Buckets {
+		if bucket.Name == "" {
+			return fmt.Errorf("aws.buckets: a bucket has no name")
+		}
+	}
+	for _, queue := range c.Queues {
+		if queue.Name == "" {
+			return fmt.Errorf("aws.queues: a queue has no name")
+		}
+		if queue.FIFO != strings.HasSuffix(queue.Name, ".fifo") {
+			return fmt.Errorf("aws.queues: queue %s: only FIFO queue names end in .fifo", queue.Name)
+		}
+		if queue.VisibilityTimeout < 0 {
+			return fmt.Errorf("aws.queues: queue %s: invalid visibility_timeout %d", queue.Name, queue.VisibilityTimeout)
+		}
+	}
+	for _, table := range c.Tables {
+		if table.Name == "" {
+			return fmt.Errorf("aws.tables: a table has no name")
+		}
+		if table.PartitionKey.Name == "" {
+			return fmt.Errorf("aws.tables: table %s has no partition_key", table.Name)
+		}
+		for _, key := range []AWSKey{table.PartitionKey, table.SortKey} {
+			if key.Type != "" && !slices.Contains(awsKeyTypes, key.Type) {
+				return fmt.Errorf("aws.tables: table %s: invalid key type %q: must be one of %s", table.Name, key.Type, strings.Join(awsKeyTypes, ", "))
+			}
+		}
+	}
+	for _, topic := range c.Topics {
+		if topic.Name == "" {
+			return fmt.Errorf("aws.topics: a topic has no name")
+		}
+	}
+	return nil
+}
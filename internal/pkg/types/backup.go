@@ -0,0 +1,47 @@
+package types
+
+// Backup compression algorithms
+const (
+	CompressionNone = ""
+	CompressionGzip = "gzip"
+	CompressionZstd = "zstd"
+)
+
+// Backup remote target types
+const (
+	RemoteS3  = "s3"
+	RemoteDir = "dir"
+)
+
+// BackupConfig holds the backup settings of a project
+type BackupConfig struct {
+	// Directory is the default host directory for backup files
+	Directory string `yaml:"directory,omitempty" json:"directory,omitempty"`
+	// Compress is the default compression algorithm (gzip or zstd)
+	Compress string `yaml:"compress,omitempty" json:"compress,omitempty"`
+	// Remotes are named upload targets; DefaultRemote is used when the
+	// backup command does not name one
+	Remotes       map[string]BackupRemote `yaml:"remotes,omitempty" json:"remotes,omitempty"`
+	DefaultRemote string                  `yaml:"default_remote,omitempty" json:"default_remote,omitempty"`
+}
+
+// BackupRemote describes a place backups are uploaded to after being
+// written locally
+type BackupRemote struct {
+	Type string `yaml:"type" json:"type"`
+
+	// s3: S3 or MinIO compatible object storage. Endpoint defaults to AWS
+	// for the region; credentials fall back to AWS_ACCESS_KEY_ID,
+	// AWS_SECRET_ACCESS_KEY and AWS_SESSION_TOKEN.
+	Endpoint     string `yaml:"endpoint,omitempty" json:"endpoint,omitempty"`
+	Region       string `yaml:"region,omitempty" json:"region,omitempty"`
+	Bucket       string `yaml:"bucket,omitempty" json:"bucket,omitempty"`
+	Prefix       string `yaml:"prefix,omitempty" json:"prefix,omitempty"`
+	AccessKey    string `yaml:"access_key,omitempty" json:"-"`
+	SecretKey    string `yaml:"secret_key,omitempty" json:"-"`
+	SessionToken string `yaml:"session_token,omitempty" json:"-"`
+	PathStyle    bool   `yaml:"path_style,omitempty" json:"path_style,omitempty"`
+
+	// dir: a directory, typically a mounted network share
+	Path string `yaml:"path,omitempty" json:"path,omitempty"`
+}
@@ -0,0 +1,91 @@
+package types
+
+import "time"
+
+// Phases of bringing a service up that a startup benchmark times
+const (
+	BenchPhasePull    = "pull"
+	BenchPhaseCreate  = "create"
+	BenchPhaseStart   = "start"
+	BenchPhaseHealthy = "healthy"
+	// BenchPhaseTotal is the sum of the other phases
+	BenchPhaseTotal = "total"
+)
+
+// BenchPhases are the phases of a service start, in order
+var BenchPhases = []string{BenchPhasePull, BenchPhaseCreate, BenchPhaseStart, BenchPhaseHealthy}
+
+// BenchTiming is how long each phase of bringing a service up took
+type BenchTiming struct {
+	Service string        `json:"service"`
+	Pull    time.Duration `json:"pull"`
+	Create  time.Duration `json:"create"`
+	Start   time.Duration `json:"start"`
+	// Healthy is the time from started to passing the readiness probes
+	Healthy time.Duration `json:"healthy"`
+}
+
+// Phase returns the duration of a phase, or the total
+func (t BenchTiming) Phase(phase string) time.Duration {
+	switch phase {
+	case BenchPhasePull:
+		return t.Pull
+	case BenchPhaseCreate:
+		return t.Create
+	case BenchPhaseStart:
+		return t.Start
+	case BenchPhaseHealthy:
+		return t.Healthy
+	case BenchPhaseTotal:
+		return t.Pull + t.Create + t.Start + t.Healthy
+	}
+	return 0
+}
+
+// SetPhase sets the duration of a phase
+func (t *BenchTiming) SetPhase(phase string, duration time.Duration) {
+	switch phase {
+	case BenchPhasePull:
+		t.Pull = duration
+	case BenchPhaseCreate:
+		t.Create = duration
+	case BenchPhaseStart:
+		t.Start = duration
+	case BenchPhaseHealthy:
+		t.Healthy = duration
+	}
+}
+
+// BenchReport is the result of a startup benchmark: the median timings of
+// each service over the runs, in startup order
+type BenchReport struct {
+	Project   string    `json:"project"`
+	Profile   string    `json:"profile,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	Runs      int       `json:"runs"`
+	// Cold is set when the images were removed before each run, so the
+	// pulls downloaded them
+	Cold     bool          `json:"cold,omitempty"`
+	Services []BenchTiming `json:"services"`
+	// Total is the median time a run took to bring the whole stack up
+	Total time.Duration `json:"total"`
+}
+
+// Service returns the timings of a service
+func (r *BenchReport) Service(name string) (BenchTiming, bool) {
+	for _, timing := range r.Services {
+		if timing.Service == name {
+			return timing, true
+		}
+	}
+	return BenchTiming{}, false
+}
+
+// BenchRegression is a phase that got slower than in the baseline
+type BenchRegression struct {
+	// Service is empty for the time of the whole stack
+	Service  string        `json:"service,omitempty"`
+	Phase    string        `json:"phase"`
+	Baseline time.Duration `json:"baseline"`
+	Current  time.Duration `json:"current"`
+}
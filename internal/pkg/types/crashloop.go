@@ -0,0 +1,88 @@
+package types
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/isaacgarza/dev-stack/internal/pkg/utils"
+)
+
+// Defaults of the crash-loop policy
+const (
+	DefaultCrashLoopThreshold = 3
+	DefaultCrashLoopWindow    = 5 * time.Minute
+	DefaultCrashLoopLogLines  = 100
+)
+
+// CrashLoopConfig configures how containers that keep exiting and being
+// restarted are detected and handled
+type CrashLoopConfig struct {
+	// Threshold is how many restarts mark a container as crash-looping
+	// when the last one happened within Window, such as 5m
+	Threshold int    `yaml:"threshold,omitempty" json:"threshold,omitempty"`
+	Window    string `yaml:"window,omitempty" json:"window,omitempty"`
+	// LogLines is how many of the last log lines go into the diagnostics
+	// of a crash-looping container
+	LogLines int `yaml:"log_lines,omitempty" json:"log_lines,omitempty"`
+	// MaxRestarts stops a crash-looping container once Docker restarted it
+	// that many times. Zero leaves it to its restart policy.
+	MaxRestarts int `yaml:"max_restarts,omitempty" json:"max_restarts,omitempty"`
+}
+
+// Validate checks the thresholds and window
+func (c CrashLoopConfig) Validate() error {
+	if c.Threshold < 0 || c.LogLines < 0 || c.MaxRestarts < 0 {
+		return fmt.Errorf("crash_loop: threshold, log_lines and max_restarts must be positive")
+	}
+	if c.Window != "" {
+		if window, err := utils.ParseDuration(c.Window); err != nil || window <= 0 {
+			return fmt.Errorf("crash_loop.window: invalid duration %q: expected a duration such as 5m", c.Window)
+		}
+	}
+	return nil
+}
+
+// WithDefaults returns the configuration with the defaults in place of the
+// settings that aren't set
+func (c CrashLoopConfig) WithDefaults() CrashLoopConfig {
+	if c.Threshold <= 0 {
+		c.Threshold = DefaultCrashLoopThreshold
+	}
+	if c.LogLines <= 0 {
+		c.LogLines = DefaultCrashLoopLogLines
+	}
+	return c
+}
+
+// WindowDuration returns the period a restart counts as recent for
+func (c CrashLoopConfig) WindowDuration() time.Duration {
+	if window, err := utils.ParseDuration(c.Window); err == nil && window > 0 {
+		return window
+	}
+	return DefaultCrashLoopWindow
+}
+
+// IsCrashLooping reports whether a container restarted at least Threshold
+// times and is restarting now or last started within the window. Docker
+// counts restarts over the life of the container, so a container that
+// settled down long ago doesn't count.
+func (c CrashLoopConfig) IsCrashLooping(status ServiceStatus, now time.Time) bool {
+	c = c.WithDefaults()
+	if status.RestartCount < c.Threshold {
+		return false
+	}
+	if status.State == ServiceStateRestarting {
+		return true
+	}
+	return status.StartedAt != nil && now.Sub(*status.StartedAt) < c.WindowDuration()
+}
+
+// MarkCrashLoops sets the state of the crash-looping statuses to
+// crash-looping
+func (c CrashLoopConfig) MarkCrashLoops(statuses []ServiceStatus, now time.Time) {
+	for i := range statuses {
+		if c.IsCrashLooping(statuses[i], now) {
+			statuses[i].State = ServiceStateCrashLooping
+		}
+	}
+}
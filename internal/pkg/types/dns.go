@@ -0,0 +1,85 @@
+package types
+
+import (
+	"fmt"
+	"net"
+	"strings"
+)
+
+// hostGateway is the extra_hosts address Docker replaces with the host's
+const hostGateway = "host-gateway"
+
+// DNSConfig sets how containers resolve host names, for networks such as
+// corporate VPNs whose internal names Docker's resolver doesn't know
+type DNSConfig struct {
+	// Servers are the nameservers containers query instead of the ones
+	// Docker passes on from the host
+	Servers []string `yaml:"servers,omitempty" json:"servers,omitempty"`
+	// Search are the domains tried for names without a dot, such as
+	// corp.example.com for git resolving to git.corp.example.com
+	Search []string `yaml:"search,omitempty" json:"search,omitempty"`
+	// ExtraHosts are host:ip entries added to /etc/hosts of containers;
+	// host-gateway stands for the address of the host
+	ExtraHosts []string `yaml:"extra_hosts,omitempty" json:"extra_hosts,omitempty"`
+}
+
+// IsZero reports whether no setting is set
+func (c DNSConfig) IsZero() bool {
+	return len(c.Servers) == 0 && len(c.Search) == 0 && len(c.ExtraHosts) == 0
+}
+
+// Merge returns the configuration with the servers and search domains of
+// override in place of those of c when it sets them, and the extra hosts of
+// both
+func (c DNSConfig) Merge(override DNSConfig) DNSConfig {
+	merged := DNSConfig{Servers: c.Servers, Search: c.Search}
+	if len(override.Servers) > 0 {
+		merged.Servers = override.Servers
+	}
+	if len(override.Search) > 0 {
+		merged.Search = override.Search
+	}
+	merged.ExtraHosts = append(append([]string{}, c.ExtraHosts...), override.ExtraHosts...)
+	if len(merged.ExtraHosts) == 0 {
+		merged.ExtraHosts = nil
+	}
+	return merged
+}
+
+// Validate checks that servers are IP addresses, search domains are domain
+// names, and extra hosts are written host:ip
+func (c DNSConfig) Validate() error {
+	for _, server := range c.Servers {
+		if net.ParseIP(server) == nil {
+			return fmt.Errorf("servers: %q is not an IP address", server)
+		}
+	}
+	for _, domain := range c.Search {
+		if domain == "" || strings.ContainsAny(domain, " /:") {
+			return fmt.Errorf("search: %q is not a domain name", domain)
+		}
+	}
+	for _, entry := range c.ExtraHosts {
+		if err := validateExtraHost(entry); err != nil {
+			return fmt.Errorf("extra_hosts: %w", err)
+		}
+	}
+	return nil
+}
+
+// validateExtraHost checks an extra_hosts entry, written host:ip or
+// host=ip, with IPv6 addresses optionally in brackets
+func validateExtraHost(entry string) error {
+	host, address, found := strings.Cut(entry, "=")
+	if !found {
+		host, address, found = strings.Cut(entry, ":")
+	}
+	address = strings.TrimSuffix(strings.TrimPrefix(address, "["), "]")
+	if !found || host == "" {
+		return fmt.Errorf("%q must be written host:ip", entry)
+	}
+	if address != hostGateway && net.ParseIP(address) == nil {
+		return fmt.Errorf("%q: %q is not an IP address or %s", entry, address, hostGateway)
+	}
+	return nil
+}
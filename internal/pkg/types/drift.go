@@ -0,0 +1,54 @@
+package types
+
+// Drift statuses of a service
+const (
+	// DriftChanged is a running service whose container differs from its
+	// definition
+	DriftChanged = "changed"
+	// DriftMissing is a defined service that has no running container
+	DriftMissing = "missing"
+	// DriftOrphaned is a running container of a service that is no longer
+	// defined
+	DriftOrphaned = "orphaned"
+)
+
+// ServiceDrift is how the running container of a service differs from what
+// the project's compose files define for it
+type ServiceDrift struct {
+	Service string        `json:"service"`
+	Status  string        `json:"status"`
+	Changes []DriftChange `json:"changes,omitempty"`
+}
+
+// DriftChange is a setting of a container that differs from its
+// definition. Field is image, env.<NAME>, port or volume:<target>; an empty
+// Expected or Actual means the setting is only on the other side.
+type DriftChange struct {
+	Field    string `json:"field"`
+	Expected string `json:"expected"`
+	Actual   string `json:"actual"`
+}
+
+// Reconcile actions, what up does to a service to bring it to its
+// definition
+const (
+	// ReconcileUnchanged is a running service that matches its definition
+	// and is left alone
+	ReconcileUnchanged = "unchanged"
+	// ReconcileRecreate is a service whose definition or image changed
+	// since its container was created
+	ReconcileRecreate = "recreate"
+	// ReconcileStart is a service whose container is current but stopped
+	ReconcileStart = "start"
+	// ReconcileCreate is a service without a container
+	ReconcileCreate = "create"
+)
+
+// ServiceReconcile is what up does to a service. Changes lists the
+// settings that differ for a recreated service, when they are among those
+// the drift check compares.
+type ServiceReconcile struct {
+	Service string        `json:"service"`
+	Action  string        `json:"action"`
+	Changes []DriftChange `json:"changes,omitempty"`
+}
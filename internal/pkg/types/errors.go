@@ -0,0 +1,198 @@
+package types
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/isaacgarza/dev-stack/internal/pkg/constants"
+)
+
+// ErrorKind classifies the failures scripts can act on. Each kind exits
+// with its own status, listed by dev-stack help exit-codes.
+type ErrorKind string
+
+// Error kinds
+const (
+	ErrorKindGeneric           ErrorKind = "error"
+	ErrorKindDockerUnavailable ErrorKind = "docker_unavailable"
+	ErrorKindPortConflict      ErrorKind = "port_conflict"
+	ErrorKindServiceNotFound   ErrorKind = "service_not_found"
+	ErrorKindConfigInvalid     ErrorKind = "config_invalid"
+	ErrorKindTimeout           ErrorKind = "timeout"
+	ErrorKindInterrupted       ErrorKind = "interrupted"
+)
+
+// ErrorKinds returns the kinds in the order of their exit codes
+func ErrorKinds() []ErrorKind {
+	return []ErrorKind{
+		ErrorKindGeneric,
+		ErrorKindDockerUnavailable,
+		ErrorKindPortConflict,
+		ErrorKindServiceNotFound,
+		ErrorKindConfigInvalid,
+		ErrorKindTimeout,
+		ErrorKindInterrupted,
+	}
+}
+
+// ExitCode returns the exit status of errors of the kind
+func (k ErrorKind) ExitCode() int {
+	switch k {
+	case ErrorKindDockerUnavailable:
+		return constants.ExitDockerUnavailable
+	case ErrorKindPortConflict:
+		return constants.ExitPortConflict
+	case ErrorKindServiceNotFound:
+		return constants.ExitServiceNotFound
+	case ErrorKindConfigInvalid:
+		return constants.ExitConfigInvalid
+	case ErrorKindTimeout:
+		return constants.ExitTimeout
+	case ErrorKindInterrupted:
+		return constants.ExitInterrupted
+	default:
+		return constants.ExitError
+	}
+}
+
+// Description explains what errors of the kind mean
+func (k ErrorKind) Description() string {
+	switch k {
+	case ErrorKindDockerUnavailable:
+		return "The Docker daemon can't be reached: start Docker, or check DOCKER_HOST"
+	case ErrorKindPortConflict:
+		return "A port a service publishes is already in use on the host"
+	case ErrorKindServiceNotFound:
+		return "A service isn't in the catalog or isn't enabled in the project"
+	case ErrorKindConfigInvalid:
+		return "The project configuration doesn't parse or fails validation"
+	case ErrorKindTimeout:
+		return "An operation didn't finish in time"
+	case ErrorKindInterrupted:
+		return "The command was stopped by Ctrl+C or SIGTERM"
+	default:
+		return "Any other failure"
+	}
+}
+
+// KindedError is implemented by the errors of a known kind
+type KindedError interface {
+	error
+	Kind() ErrorKind
+}
+
+// dockerUnavailableMessages are how the Docker SDK and CLI report a daemon
+// they can't reach
+var dockerUnavailableMessages = []string{
+	"cannot connect to the docker daemon",
+	"is the docker daemon running",
+	"error during connect",
+}
+
+// ErrorKindOf returns the kind of err: that of the first KindedError in
+// its chain, otherwise timeout or interrupted for context errors and
+// docker_unavailable for the errors of an unreachable daemon
+func ErrorKindOf(err error) ErrorKind {
+	if err == nil {
+		return ""
+	}
+	var kinded KindedError
+	if errors.As(err, &kinded) {
+		return kinded.Kind()
+	}
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return ErrorKindTimeout
+	case errors.Is(err, context.Canceled):
+		return ErrorKindInterrupted
+	}
+	message := strings.ToLower(err.Error())
+	for _, unavailable := range dockerUnavailableMessages {
+		if strings.Contains(message, unavailable) {
+			return ErrorKindDockerUnavailable
+		}
+	}
+	return ErrorKindGeneric
+}
+
+// DockerUnavailableError reports that the Docker daemon can't be reached
+type DockerUnavailableError struct {
+	Err error `json:"-"`
+}
+
+func (e *DockerUnavailableError) Error() string {
+	return fmt.Sprintf("Docker is not available: %v", e.Err)
+}
+
+func (e *DockerUnavailableError) Unwrap() error {
+	return e.Err
+}
+
+// Kind returns ErrorKindDockerUnavailable
+func (e *DockerUnavailableError) Kind() ErrorKind {
+	return ErrorKindDockerUnavailable
+}
+
+// PortConflictError reports a host port a service can't publish because
+// something else listens on it
+type PortConflictError struct {
+	Service string `json:"service,omitempty"`
+	Port    string `json:"port"`
+}
+
+func (e *PortConflictError) Error() string {
+	if e.Service == "" {
+		return fmt.Sprintf("port %s is already in use", e.Port)
+	}
+	return fmt.Sprintf("port %s of %s is already in use", e.Port, e.Service)
+}
+
+// Kind returns ErrorKindPortConflict
+func (e *PortConflictError) Kind() ErrorKind {
+	return ErrorKindPortConflict
+}
+
+// ServiceNotFoundError reports a service that isn't in the catalog, or
+// that the project doesn't enable when NotEnabled is set
+type ServiceNotFoundError struct {
+	Service    string   `json:"service"`
+	NotEnabled bool     `json:"not_enabled,omitempty"`
+	Available  []string `json:"available,omitempty"`
+}
+
+func (e *ServiceNotFoundError) Error() string {
+	if e.NotEnabled {
+		return fmt.Sprintf("service %s is not enabled in this project", e.Service)
+	}
+	if len(e.Available) > 0 {
+		return fmt.Sprintf("unknown service '%s'. Available services: %v", e.Service, e.Available)
+	}
+	return fmt.Sprintf("service %s not found", e.Service)
+}
+
+// Kind returns ErrorKindServiceNotFound
+func (e *ServiceNotFoundError) Kind() ErrorKind {
+	return ErrorKindServiceNotFound
+}
+
+// ConfigInvalidError reports a configuration file that doesn't parse or
+// fails validation
+type ConfigInvalidError struct {
+	Path string `json:"path"`
+	Err  error  `json:"-"`
+}
+
+func (e *ConfigInvalidError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *ConfigInvalidError) Unwrap() error {
+	return e.Err
+}
+
+// Kind returns ErrorKindConfigInvalid
+func (e *ConfigInvalidError) Kind() ErrorKind {
+	return ErrorKindConfigInvalid
+}
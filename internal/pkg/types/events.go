@@ -0,0 +1,132 @@
+package types
+
+import (
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/isaacgarza/dev-stack/internal/pkg/utils"
+)
+
+// Service event actions, named after the Docker events they come from
+const (
+	EventStart        = "start"
+	EventDie          = "die"
+	EventHealthStatus = "health_status"
+	EventOOM          = "oom"
+	EventPause        = "pause"
+	EventUnpause      = "unpause"
+)
+
+// ServiceEventActions returns the actions reported by the events command
+func ServiceEventActions() []string {
+	return []string{EventStart, EventDie, EventHealthStatus, EventOOM, EventPause, EventUnpause}
+}
+
+// ServiceEvent is a lifecycle event of a container of the stack
+type ServiceEvent struct {
+	Time      time.Time `json:"time"`
+	Project   string    `json:"project"`
+	Service   string    `json:"service"`
+	Container string    `json:"container"`
+	Action    string    `json:"action"`
+	// Health is the new health status of health_status events
+	Health string `json:"health,omitempty"`
+	// ExitCode is the exit code of die events
+	ExitCode *int `json:"exit_code,omitempty"`
+}
+
+// Defaults of the desktop notifications
+const (
+	DefaultRestartThreshold = 3
+	DefaultRestartWindow    = 10 * time.Minute
+)
+
+// EventsConfig configures what is done with the lifecycle events of the
+// stack while dev-stack events or dev-stack daemon runs
+type EventsConfig struct {
+	Webhooks      []WebhookConfig     `yaml:"webhooks,omitempty" json:"webhooks,omitempty"`
+	Notifications NotificationsConfig `yaml:"notifications,omitempty" json:"notifications,omitempty"`
+}
+
+// NotificationsConfig configures the desktop notifications raised when a
+// service becomes unhealthy, runs out of memory or keeps restarting
+type NotificationsConfig struct {
+	Enabled bool `yaml:"enabled,omitempty" json:"enabled,omitempty"`
+	// RestartThreshold is how many times a service may exit within
+	// RestartWindow, such as 10m, before it is reported as flapping
+	RestartThreshold int    `yaml:"restart_threshold,omitempty" json:"restart_threshold,omitempty"`
+	RestartWindow    string `yaml:"restart_window,omitempty" json:"restart_window,omitempty"`
+	// Mute lists services never notified about
+	Mute []string `yaml:"mute,omitempty" json:"mute,omitempty"`
+}
+
+// WebhookConfig posts each matching event as JSON to URL. Empty Events or
+// Services match every event or service.
+type WebhookConfig struct {
+	URL      string            `yaml:"url" json:"url"`
+	Events   []string          `yaml:"events,omitempty" json:"events,omitempty"`
+	Services []string          `yaml:"services,omitempty" json:"services,omitempty"`
+	Headers  map[string]string `yaml:"headers,omitempty" json:"headers,omitempty"`
+}
+
+// Validate checks the URL and events of every webhook
+func (c EventsConfig) Validate() error {
+	for i, webhook := range c.Webhooks {
+		parsed, err := url.Parse(webhook.URL)
+		if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			return fmt.Errorf("events.webhooks[%d]: invalid url %q: expected an http or https URL", i, webhook.URL)
+		}
+		for _, event := range webhook.Events {
+			if !slices.Contains(ServiceEventActions(), event) {
+				return fmt.Errorf("events.webhooks[%d]: unknown event %q (supported: %s)", i, event, strings.Join(ServiceEventActions(), ", "))
+			}
+		}
+	}
+	return c.Notifications.Validate()
+}
+
+// Validate checks the restart threshold and window
+func (c NotificationsConfig) Validate() error {
+	if c.RestartThreshold < 0 {
+		return fmt.Errorf("events.notifications.restart_threshold must be positive")
+	}
+	if c.RestartWindow != "" {
+		if window, err := utils.ParseDuration(c.RestartWindow); err != nil || window <= 0 {
+			return fmt.Errorf("events.notifications.restart_window: invalid duration %q: expected a duration such as 10m", c.RestartWindow)
+		}
+	}
+	return nil
+}
+
+// Threshold returns how many exits within the window make a service
+// flapping
+func (c NotificationsConfig) Threshold() int {
+	if c.RestartThreshold > 0 {
+		return c.RestartThreshold
+	}
+	return DefaultRestartThreshold
+}
+
+// Window returns the period restarts are counted over
+func (c NotificationsConfig) Window() time.Duration {
+	if window, err := utils.ParseDuration(c.RestartWindow); err == nil && window > 0 {
+		return window
+	}
+	return DefaultRestartWindow
+}
+
+// Muted reports whether notifications about a service are muted
+func (c NotificationsConfig) Muted(serviceName string) bool {
+	return slices.Contains(c.Mute, serviceName)
+}
+
+// Matches reports whether the webhook is sent event
+func (w WebhookConfig) Matches(event ServiceEvent) bool {
+	if len(w.Events) > 0 && !slices.Contains(w.Events, event.Action) {
+		return false
+	}
+	return len(w.Services) == 0 || slices.Contains(w.Services, event.Service)
+}
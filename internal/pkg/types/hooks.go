@@ -0,0 +1,48 @@
+package types
+
+// Lifecycle hook events
+const (
+	HookPreUp       = "pre_up"
+	HookPostUp      = "post_up"
+	HookPreDown     = "pre_down"
+	HookPostDown    = "post_down"
+	HookPreBackup   = "pre_backup"
+	HookPostBackup  = "post_backup"
+	HookPreRestore  = "pre_restore"
+	HookPostRestore = "post_restore"
+)
+
+// Hook failure policies
+const (
+	HookFailureAbort    = "abort"
+	HookFailureContinue = "continue"
+)
+
+// HookEvents returns the supported hook events in lifecycle order
+func HookEvents() []string {
+	return []string{
+		HookPreUp, HookPostUp,
+		HookPreDown, HookPostDown,
+		HookPreBackup, HookPostBackup,
+		HookPreRestore, HookPostRestore,
+	}
+}
+
+// HooksConfig maps hook events to the steps run for them
+type HooksConfig map[string][]HookStep
+
+// HookStep is a single hook step. Exactly one of Run or Command is set.
+type HookStep struct {
+	Name string `yaml:"name,omitempty" json:"name,omitempty"`
+
+	// Run is a shell command executed with sh -c from the project root
+	Run string `yaml:"run,omitempty" json:"run,omitempty"`
+
+	// Command is a dev-stack command line without the program name, such
+	// as "seed postgres"
+	Command string `yaml:"command,omitempty" json:"command,omitempty"`
+
+	Timeout   string            `yaml:"timeout,omitempty" json:"timeout,omitempty"`
+	OnFailure string            `yaml:"on_failure,omitempty" json:"on_failure,omitempty"`
+	Env       map[string]string `yaml:"env,omitempty" json:"env,omitempty"`
+}
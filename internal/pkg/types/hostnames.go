@@ -0,0 +1,12 @@
+package types
+
+// HostnamesConfig registers friendly hostnames, such as
+// postgres.myproject.test, for the services of a project
+type HostnamesConfig struct {
+	// Enabled points <service>.<project>.<domain> at localhost in the hosts
+	// file and uses those hostnames in the generated env files
+	Enabled bool `yaml:"enabled,omitempty" json:"enabled,omitempty"`
+
+	// Domain hostnames are registered under; defaults to test
+	Domain string `yaml:"domain,omitempty" json:"domain,omitempty"`
+}
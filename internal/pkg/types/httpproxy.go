@@ -0,0 +1,115 @@
+package types
+
+import (
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+)
+
+// HTTPProxyConfig sends the outbound traffic of containers, and of the
+// builds of their images, through an HTTP proxy, for networks that only
+// reach the internet through one
+type HTTPProxyConfig struct {
+	// FromEnv takes the HTTP_PROXY, HTTPS_PROXY and NO_PROXY variables
+	// dev-stack runs with, or their lowercase forms, for the settings left
+	// unset
+	FromEnv bool `yaml:"from_env,omitempty" json:"from_env,omitempty"`
+	// HTTP is the proxy URL of plain HTTP requests
+	HTTP string `yaml:"http,omitempty" json:"http,omitempty"`
+	// HTTPS is the proxy URL of HTTPS requests
+	HTTPS string `yaml:"https,omitempty" json:"https,omitempty"`
+	// NoProxy lists the hosts, domains and CIDR ranges reached directly.
+	// The services of the stack are always reached directly.
+	NoProxy []string `yaml:"no_proxy,omitempty" json:"no_proxy,omitempty"`
+}
+
+// IsZero reports whether no proxy is set
+func (c HTTPProxyConfig) IsZero() bool {
+	return c.HTTP == "" && c.HTTPS == ""
+}
+
+// Resolve returns the settings in effect: those of the configuration and,
+// with from_env, those getenv returns for the proxies it leaves unset and
+// the hosts it doesn't list
+func (c HTTPProxyConfig) Resolve(getenv func(string) string) HTTPProxyConfig {
+	resolved := HTTPProxyConfig{HTTP: c.HTTP, HTTPS: c.HTTPS, NoProxy: slices.Clone(c.NoProxy)}
+	if !c.FromEnv {
+		return resolved
+	}
+
+	lookup := func(name string) string {
+		if value := getenv(name); value != "" {
+			return value
+		}
+		return getenv(strings.ToLower(name))
+	}
+	if resolved.HTTP == "" {
+		resolved.HTTP = lookup("HTTP_PROXY")
+	}
+	if resolved.HTTPS == "" {
+		resolved.HTTPS = lookup("HTTPS_PROXY")
+	}
+	resolved.NoProxy = appendHosts(resolved.NoProxy, strings.Split(lookup("NO_PROXY"), ","))
+	return resolved
+}
+
+// Env returns the proxy variables of containers, in upper and lower case as
+// tools read either, with hosts reached directly ahead of the configured
+// no_proxy
+func (c HTTPProxyConfig) Env(hosts []string) map[string]string {
+	if c.IsZero() {
+		return nil
+	}
+
+	env := make(map[string]string)
+	for name, value := range map[string]string{
+		"HTTP_PROXY":  c.HTTP,
+		"HTTPS_PROXY": c.HTTPS,
+		"NO_PROXY":    strings.Join(appendHosts(slices.Clone(hosts), c.NoProxy), ","),
+	} {
+		if value != "" {
+			env[name] = value
+			env[strings.ToLower(name)] = value
+		}
+	}
+	return env
+}
+
+// Validate checks that the proxies are absolute http or https URLs
+func (c HTTPProxyConfig) Validate() error {
+	for _, proxy := range []struct{ field, value string }{{"http", c.HTTP}, {"https", c.HTTPS}} {
+		field, value := proxy.field, proxy.value
+		if value == "" {
+			continue
+		}
+		u, err := url.Parse(value)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("http_proxy.%s: %q is not an http:// or https:// URL", field, redactURL(value))
+		}
+	}
+	for _, host := range c.NoProxy {
+		if strings.TrimSpace(host) == "" || strings.Contains(host, ",") {
+			return fmt.Errorf("http_proxy.no_proxy: %q is not a host name", host)
+		}
+	}
+	return nil
+}
+
+// appendHosts appends the hosts not yet listed, skipping blank entries
+func appendHosts(hosts, more []string) []string {
+	for _, host := range more {
+		if host = strings.TrimSpace(host); host != "" && !slices.Contains(hosts, host) {
+			hosts = append(hosts, host)
+		}
+	}
+	return hosts
+}
+
+// redactURL hides the password of a proxy URL so it isn't printed
+func redactURL(value string) string {
+	if u, err := url.Parse(value); err == nil {
+		return u.Redacted()
+	}
+	return value
+}
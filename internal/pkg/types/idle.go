@@ -0,0 +1,91 @@
+package types
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/isaacgarza/dev-stack/internal/pkg/utils"
+)
+
+// What the idle reaper does to a service nothing connects to
+const (
+	// IdleActionStop stops the service, freeing its memory. The reaper
+	// listens on its ports meanwhile and starts it on the next connection.
+	IdleActionStop = "stop"
+	// IdleActionPause freezes the service, which resumes faster but keeps
+	// its memory
+	IdleActionPause = "pause"
+)
+
+// DefaultIdleTimeout is how long a service may go without connections
+// before the idle reaper puts it to sleep
+const DefaultIdleTimeout = 30 * time.Minute
+
+// IdleConfig configures the idle reaper, which stops or pauses the services
+// nothing has connected to for a while to save battery
+type IdleConfig struct {
+	Enabled bool `yaml:"enabled,omitempty" json:"enabled,omitempty"`
+	// Timeout is how long a service may go without connections to its
+	// published ports, such as 30m
+	Timeout string `yaml:"timeout,omitempty" json:"timeout,omitempty"`
+	// Action is stop, the default, or pause
+	Action string `yaml:"action,omitempty" json:"action,omitempty"`
+	// Services limits the reaper to these services; by default it watches
+	// every service with published ports
+	Services []string `yaml:"services,omitempty" json:"services,omitempty"`
+	// Exclude lists services the reaper never puts to sleep
+	Exclude []string `yaml:"exclude,omitempty" json:"exclude,omitempty"`
+}
+
+// Validate checks the timeout and action
+func (c IdleConfig) Validate() error {
+	if c.Timeout != "" {
+		if timeout, err := utils.ParseDuration(c.Timeout); err != nil || timeout <= 0 {
+			return fmt.Errorf("idle.timeout: invalid duration %q: expected a duration such as 30m", c.Timeout)
+		}
+	}
+	if c.Action != "" && c.Action != IdleActionStop && c.Action != IdleActionPause {
+		return fmt.Errorf("idle.action: invalid action %q: must be %s or %s", c.Action, IdleActionStop, IdleActionPause)
+	}
+	return nil
+}
+
+// IdleTimeout returns how long a service may go without connections
+func (c IdleConfig) IdleTimeout() time.Duration {
+	if timeout, err := utils.ParseDuration(c.Timeout); err == nil && timeout > 0 {
+		return timeout
+	}
+	return DefaultIdleTimeout
+}
+
+// IdleAction returns what is done to idle services
+func (c IdleConfig) IdleAction() string {
+	if c.Action == "" {
+		return IdleActionStop
+	}
+	return c.Action
+}
+
+// Watches reports whether the reaper may put a service to sleep
+func (c IdleConfig) Watches(serviceName string) bool {
+	if slices.Contains(c.Exclude, serviceName) {
+		return false
+	}
+	return len(c.Services) == 0 || slices.Contains(c.Services, serviceName)
+}
+
+// ServiceActivity is a sample of the connections to the published ports of
+// the running containers of a service
+type ServiceActivity struct {
+	Service string `json:"service"`
+	// Connections counts the established connections to the published
+	// ports from outside the containers
+	Connections int `json:"connections"`
+	// Unknown is set when the connections of a container couldn't be read,
+	// as in an image without cat
+	Unknown bool `json:"unknown,omitempty"`
+	// Ports are the host addresses the ports are published on, such as
+	// 127.0.0.1:5432
+	Ports []string `json:"ports"`
+}
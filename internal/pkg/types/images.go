@@ -0,0 +1,121 @@
+package types
+
+import "gopkg.in/yaml.v3"
+
+// ServiceSettings holds the per-service settings of a project, keyed by
+// service name under services: in the project configuration
+type ServiceSettings struct {
+	// Version pins the service image to this tag instead of the one in the
+	// service definition
+	Version string `yaml:"version,omitempty" json:"version,omitempty"`
+	// Resources overrides the resource limits of the service's containers
+	Resources ResourceLimits `yaml:"resources,omitempty" json:"resources,omitempty"`
+	// Shared overrides whether the service definition shares the service
+	// between projects
+	Shared *bool `yaml:"shared,omitempty" json:"shared,omitempty"`
+	// BuildArgs are passed to the builds of the service's images, over the
+	// build arguments of the service definition
+	BuildArgs map[string]string `yaml:"build_args,omitempty" json:"build_args,omitempty"`
+	// Mounts bind host paths into the service's containers
+	Mounts []BindMount `yaml:"mounts,omitempty" json:"mounts,omitempty"`
+	// Platform runs the service's containers on this platform, such as
+	// linux/amd64, whatever the host architecture
+	Platform string `yaml:"platform,omitempty" json:"platform,omitempty"`
+	// DNS sets nameservers and search domains of the service's containers
+	// in place of those of the dns section, and adds extra hosts to it
+	DNS DNSConfig `yaml:"dns,omitempty" json:"dns,omitempty"`
+}
+
+// UnmarshalYAML decodes service settings, ignoring values that are not a
+// mapping so older configurations with a services list still load
+func (s *ServiceSettings) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind != yaml.MappingNode {
+		return nil
+	}
+	type plain ServiceSettings
+	return node.Decode((*plain)(s))
+}
+
+// ServicesConfig maps service names to their settings
+type ServicesConfig map[string]ServiceSettings
+
+// UnmarshalYAML decodes the services section, ignoring it when it is not a
+// mapping of service names, as in configurations written as
+// services: {enabled: [...]}
+func (c *ServicesConfig) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind != yaml.MappingNode {
+		return nil
+	}
+	settings := make(map[string]ServiceSettings)
+	if err := node.Decode(&settings); err != nil {
+		return err
+	}
+	*c = settings
+	return nil
+}
+
+// Versions returns the pinned image tag of each service that has one
+func (c ServicesConfig) Versions() map[string]string {
+	versions := make(map[string]string)
+	for name, settings := range c {
+		if settings.Version != "" {
+			versions[name] = settings.Version
+		}
+	}
+	return versions
+}
+
+// Resources returns the resource limits of each service that sets any
+func (c ServicesConfig) Resources() map[string]ResourceLimits {
+	resources := make(map[string]ResourceLimits)
+	for name, settings := range c {
+		if !settings.Resources.IsZero() {
+			resources[name] = settings.Resources
+		}
+	}
+	return resources
+}
+
+// BuildArgs returns the build arguments of each service that sets any
+func (c ServicesConfig) BuildArgs() map[string]map[string]string {
+	args := make(map[string]map[string]string)
+	for name, settings := range c {
+		if len(settings.BuildArgs) > 0 {
+			args[name] = settings.BuildArgs
+		}
+	}
+	return args
+}
+
+// Mounts returns the bind mounts of each service that declares any
+func (c ServicesConfig) Mounts() map[string][]BindMount {
+	mounts := make(map[string][]BindMount)
+	for name, settings := range c {
+		if len(settings.Mounts) > 0 {
+			mounts[name] = settings.Mounts
+		}
+	}
+	return mounts
+}
+
+// Platforms returns the platform of each service that sets one
+func (c ServicesConfig) Platforms() map[string]string {
+	platforms := make(map[string]string)
+	for name, settings := range c {
+		if settings.Platform != "" {
+			platforms[name] = settings.Platform
+		}
+	}
+	return platforms
+}
+
+// DNS returns the DNS settings of each service that sets any
+func (c ServicesConfig) DNS() map[string]DNSConfig {
+	dns := make(map[string]DNSConfig)
+	for name, settings := range c {
+		if !settings.DNS.IsZero() {
+			dns[name] = settings.DNS
+		}
+	}
+	return dns
+}
@@ -0,0 +1,99 @@
+package types
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/isaacgarza/dev-stack/internal/pkg/utils"
+)
+
+// RetentionForever keeps the messages of a topic indefinitely
+const RetentionForever = "forever"
+
+// KafkaConfig declares the topics a project needs on its Kafka broker. They
+// are reconciled once the broker is ready: missing topics are created and
+// existing ones get the declared partitions and configs.
+type KafkaConfig struct {
+	Topics []KafkaTopicConfig `yaml:"topics,omitempty" json:"topics,omitempty"`
+}
+
+// KafkaTopicConfig is a topic and the settings it should have
+type KafkaTopicConfig struct {
+	Name string `yaml:"name" json:"name"`
+	// Partitions defaults to the broker's num.partitions; Kafka can add
+	// partitions to a topic but never remove them
+	Partitions        int `yaml:"partitions,omitempty" json:"partitions,omitempty"`
+	ReplicationFactor int `yaml:"replication_factor,omitempty" json:"replication_factor,omitempty"`
+	// Retention keeps messages for a duration such as 12h or 7d, or forever
+	Retention string `yaml:"retention,omitempty" json:"retention,omitempty"`
+	// Config sets other topic configs, such as cleanup.policy
+	Config map[string]string `yaml:"config,omitempty" json:"config,omitempty"`
+}
+
+// IsZero reports whether no topics are declared
+func (c KafkaConfig) IsZero() bool {
+	return len(c.Topics) == 0
+}
+
+// Validate checks that every topic is named once and well-formed
+func (c KafkaConfig) Validate() error {
+	seen := make(map[string]bool)
+	for _, topic := range c.Topics {
+		if topic.Name == "" {
+			return fmt.Errorf("kafka.topics: a topic has no name")
+		}
+		if seen[topic.Name] {
+			return fmt.Errorf("kafka.topics: topic %s is declared twice", topic.Name)
+		}
+		seen[topic.Name] = true
+		if topic.Partitions < 0 || topic.ReplicationFactor < 0 {
+			return fmt.Errorf("kafka.topics: topic %s: partitions and replication_factor must be positive", topic.Name)
+		}
+		if _, err := topic.Configs(); err != nil {
+			return fmt.Errorf("kafka.topics: topic %s: %w", topic.Name, err)
+		}
+	}
+	return nil
+}
+
+// Configs returns the topic configs to set, with the retention as
+// retention.ms
+func (t KafkaTopicConfig) Configs() (map[string]string, error) {
+	configs := make(map[string]string, len(t.Config)+1)
+	for key, value := range t.Config {
+		configs[key] = value
+	}
+
+	switch t.Retention {
+	case "":
+	case RetentionForever:
+		configs["retention.ms"] = "-1"
+	default:
+		retention, err := utils.ParseDuration(t.Retention)
+		if err != nil || retention <= 0 {
+			return nil, fmt.Errorf("invalid retention %q: expected a duration such as 12h or 7d, or %s", t.Retention, RetentionForever)
+		}
+		configs["retention.ms"] = strconv.FormatInt(retention.Milliseconds(), 10)
+	}
+	return configs, nil
+}
+
+// RetentionOf renders a retention.ms value the way topics declare it
+func RetentionOf(retentionMS string) string {
+	ms, err := strconv.ParseInt(retentionMS, 10, 64)
+	switch {
+	case err != nil:
+		return retentionMS
+	case ms < 0:
+		return RetentionForever
+	}
+	retention := time.Duration(ms) * time.Millisecond
+	switch {
+	case retention > 0 && retention%(24*time.Hour) == 0:
+		return fmt.Sprintf("%dd", retention/(24*time.Hour))
+	case retention > 0 && retention%time.Hour == 0:
+		return fmt.Sprintf("%dh", retention/time.Hour)
+	}
+	return retention.String()
+}
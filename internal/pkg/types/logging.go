@@ -0,0 +1,66 @@
+package types
+
+import (
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/isaacgarza/dev-stack/internal/pkg/utils"
+)
+
+// Defaults of the logging configuration
+const (
+	DefaultLogRetention = 7 * 24 * time.Hour
+	DefaultLogMaxSize   = "10m"
+	DefaultLogMaxFiles  = 3
+)
+
+// logSizePattern matches the sizes of the json-file logging driver
+var logSizePattern = regexp.MustCompile(`^[0-9]+[kmg]?$`)
+
+// LoggingConfig configures how the logs of the stack's containers are kept
+type LoggingConfig struct {
+	// Retention is how long the log-collector service keeps the logs it
+	// persists, such as 48h or 14d
+	Retention string `yaml:"retention,omitempty" json:"retention,omitempty"`
+	// MaxSize and MaxFiles bound the logs Docker keeps for each container
+	// before rotating them
+	MaxSize  string `yaml:"max_size,omitempty" json:"max_size,omitempty"`
+	MaxFiles int    `yaml:"max_files,omitempty" json:"max_files,omitempty"`
+}
+
+// Validate checks the retention and rotation settings
+func (c LoggingConfig) Validate() error {
+	if c.Retention != "" {
+		if retention, err := utils.ParseDuration(c.Retention); err != nil || retention <= 0 {
+			return fmt.Errorf("logging.retention: invalid duration %q: expected a duration such as 48h or 14d", c.Retention)
+		}
+	}
+	if c.MaxSize != "" && !logSizePattern.MatchString(c.MaxSize) {
+		return fmt.Errorf("logging.max_size: invalid size %q: expected a number of bytes with an optional k, m or g suffix", c.MaxSize)
+	}
+	if c.MaxFiles < 0 {
+		return fmt.Errorf("logging.max_files must be positive")
+	}
+	return nil
+}
+
+// WithDefaults returns the configuration with the defaults in place of the
+// settings that aren't set
+func (c LoggingConfig) WithDefaults() LoggingConfig {
+	if c.MaxSize == "" {
+		c.MaxSize = DefaultLogMaxSize
+	}
+	if c.MaxFiles <= 0 {
+		c.MaxFiles = DefaultLogMaxFiles
+	}
+	return c
+}
+
+// RetentionPeriod returns how long persisted logs are kept
+func (c LoggingConfig) RetentionPeriod() time.Duration {
+	if retention, err := utils.ParseDuration(c.Retention); err == nil && retention > 0 {
+		return retention
+	}
+	return DefaultLogRetention
+}
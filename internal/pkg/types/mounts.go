@@ -0,0 +1,37 @@
+package types
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+// BindMount mounts a directory or file of the host into the containers of
+// a service
+type BindMount struct {
+	// Source is the host path. ${PROJECT_ROOT} and ${HOME} are expanded, as
+	// are environment variables, and relative paths are relative to the
+	// project root.
+	Source string `yaml:"source" json:"source"`
+	// Target is the absolute path in the container
+	Target   string `yaml:"target" json:"target"`
+	ReadOnly bool   `yaml:"read_only,omitempty" json:"read_only,omitempty"`
+	// Create creates Source as a directory when it doesn't exist, instead
+	// of failing compose file generation
+	Create bool `yaml:"create,omitempty" json:"create,omitempty"`
+}
+
+// Validate checks that the mount names a host path and an absolute
+// container path
+func (m BindMount) Validate() error {
+	if m.Source == "" {
+		return fmt.Errorf("missing source: expected a host path")
+	}
+	if !path.IsAbs(m.Target) {
+		return fmt.Errorf("invalid target %q: expected an absolute path in the container", m.Target)
+	}
+	if strings.Contains(m.Target, ":") {
+		return fmt.Errorf("invalid target %q: container paths can't contain ':'", m.Target)
+	}
+	return nil
+}
@@ -0,0 +1,54 @@
+package types
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// Network modes of the stack
+const (
+	// NetworkModeShared puts every service on the project network
+	NetworkModeShared = "shared"
+	// NetworkModeIsolated only connects the services allow rules and
+	// dependencies name
+	NetworkModeIsolated = "isolated"
+)
+
+// NetworkAllowAll is the allow rule peer that stands for every service
+const NetworkAllowAll = "*"
+
+// NetworkModes are the values of network.mode
+var NetworkModes = []string{NetworkModeShared, NetworkModeIsolated}
+
+// NetworkConfig controls which services of the stack can reach each other
+type NetworkConfig struct {
+	// Mode is shared, the default, or isolated
+	Mode string `yaml:"mode,omitempty" json:"mode,omitempty"`
+	// Allow lists, for each service by name, the services it can reach in
+	// isolated mode. Docker networks connect both ways, so the peers can
+	// reach it as well.
+	Allow map[string][]string `yaml:"allow,omitempty" json:"allow,omitempty"`
+}
+
+// Isolated reports whether services are only connected as allowed
+func (c NetworkConfig) Isolated() bool {
+	return c.Mode == NetworkModeIsolated
+}
+
+// Validate checks the mode and that allow rules are set in isolated mode
+func (c NetworkConfig) Validate() error {
+	if c.Mode != "" && !slices.Contains(NetworkModes, c.Mode) {
+		return fmt.Errorf("network.mode: invalid mode %q: must be one of %s", c.Mode, strings.Join(NetworkModes, ", "))
+	}
+	if len(c.Allow) > 0 && !c.Isolated() {
+		return fmt.Errorf("network.allow only applies when network.mode is %s", NetworkModeIsolated)
+	}
+	for _, service := range slices.Sorted(maps.Keys(c.Allow)) {
+		if slices.Contains(c.Allow[service], service) {
+			return fmt.Errorf("network.allow.%s: a service always reaches itself", service)
+		}
+	}
+	return nil
+}
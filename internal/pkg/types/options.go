@@ -9,6 +9,45 @@ type StartOptions struct {
 	NoDeps        bool
 	Detach        bool
 	Timeout       time.Duration
+	// Profiles are compose profiles to enable, so services assigned to
+	// them start alongside the named services
+	Profiles []string
+	// RenewAnonVolumes recreates anonymous volumes instead of reusing those
+	// of the previous containers
+	RenewAnonVolumes bool
+	// MaxParallel caps how many services start at once within a wave of
+	// the dependency graph
+	MaxParallel int
+	// Pull is the docker compose pull policy for images that are missing
+	// when containers are created; empty leaves the compose default
+	Pull string
+	// Progress, when set, receives the build steps and the startup of each
+	// service
+	Progress ProgressReporter
+}
+
+// BuildOptions defines options for building service images
+type BuildOptions struct {
+	// NoCache runs every build step again instead of reusing the cache
+	NoCache bool
+	// Pull fetches newer versions of the base images before building
+	Pull bool
+	// Profiles are compose profiles to enable, so services assigned to
+	// them can be built
+	Profiles []string
+	// Progress, when set, receives the build steps
+	Progress ProgressReporter
+}
+
+// PullOptions defines options for pulling images
+type PullOptions struct {
+	Concurrency int
+	Retries     int
+	RetryDelay  time.Duration
+	Mirrors     []string
+	Platform    string
+	// Progress, when set, receives the download of each image
+	Progress ProgressReporter
 }
 
 // StopOptions defines options for stopping services
@@ -34,6 +73,8 @@ type LogOptions struct {
 	Timestamps bool
 	Tail       string
 	Since      string
+	NoColor    bool
+	NoPrefix   bool
 }
 
 // ConnectOptions defines options for connecting to services
@@ -43,6 +84,15 @@ type ConnectOptions struct {
 	Host     string
 	Port     string
 	ReadOnly bool
+	// Topic and Path are used by the clients of message brokers and HTTP
+	// services, such as the Kafka topic to consume
+	Topic string
+	Path  string
+	// Client selects one of the service's clients by name
+	Client string
+	// Env holds the project's connection variables for the service, used
+	// for settings the container environment does not carry
+	Env map[string]string
 }
 
 // ScaleOptions defines options for scaling services
@@ -55,7 +105,7 @@ type ScaleOptions struct {
 // BackupOptions defines options for backing up service data
 type BackupOptions struct {
 	OutputDir string
-	Compress  bool
+	Compress  string
 	Format    string
 	Database  string
 	User      string
@@ -63,6 +113,18 @@ type BackupOptions struct {
 	Clean     bool
 }
 
+// VolumeBackupOptions defines options for backing up and restoring named
+// volumes
+type VolumeBackupOptions struct {
+	OutputDir string
+	Compress  string
+	// StopServices stops the services using a volume while it is backed up,
+	// so the archive holds a consistent state; restores always stop them
+	StopServices bool
+	// Timeout is how many seconds services get to stop
+	Timeout int
+}
+
 // RestoreOptions defines options for restoring service data
 type RestoreOptions struct {
 	Database          string
@@ -73,6 +135,12 @@ type RestoreOptions struct {
 	SingleTransaction bool
 }
 
+// SeedOptions defines options for seeding service data
+type SeedOptions struct {
+	Force  bool
+	DryRun bool
+}
+
 // CleanupOptions defines options for cleaning up resources
 type CleanupOptions struct {
 	RemoveVolumes  bool
@@ -0,0 +1,18 @@
+package types
+
+// OwnedResources are the Docker resources dev-stack labelled as created for
+// a project
+type OwnedResources struct {
+	Project string `json:"project"`
+	// Dir is the project directory the resources were created from
+	Dir        string   `json:"dir,omitempty"`
+	Containers []string `json:"containers,omitempty"`
+	Volumes    []string `json:"volumes,omitempty"`
+	Networks   []string `json:"networks,omitempty"`
+	Images     []string `json:"images,omitempty"`
+}
+
+// IsEmpty reports whether there are no resources
+func (r OwnedResources) IsEmpty() bool {
+	return len(r.Containers) == 0 && len(r.Volumes) == 0 && len(r.Networks) == 0 && len(r.Images) == 0
+}
@@ -0,0 +1,48 @@
+package types
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// PlatformSupport is an entry of the compatibility table of a service
+// definition: the architectures the image is published for at some
+// versions
+type PlatformSupport struct {
+	// Versions are image tags, each also matching the tags it is a release
+	// prefix of: 5.7 matches 5.7.44 and 5.7-debian but not 5.70
+	Versions      []string `yaml:"versions" json:"versions"`
+	Architectures []string `yaml:"architectures" json:"architectures"`
+}
+
+// PlatformTable is the compatibility table of a service definition. Tags
+// it doesn't list are taken to be published for every architecture.
+type PlatformTable []PlatformSupport
+
+// Architectures returns the architectures the image is published for at
+// tag, or nil when the table doesn't list the tag
+func (t PlatformTable) Architectures(tag string) []string {
+	for _, entry := range t {
+		if slices.ContainsFunc(entry.Versions, func(version string) bool { return matchesVersion(tag, version) }) {
+			return entry.Architectures
+		}
+	}
+	return nil
+}
+
+// matchesVersion reports whether tag is version or a release of it
+func matchesVersion(tag, version string) bool {
+	rest, found := strings.CutPrefix(tag, version)
+	return found && (rest == "" || strings.ContainsRune(".-_", rune(rest[0])))
+}
+
+// ValidatePlatform checks that a platform is written os/arch, optionally
+// followed by a variant, as in linux/amd64 or linux/arm/v7
+func ValidatePlatform(platform string) error {
+	parts := strings.Split(platform, "/")
+	if len(parts) < 2 || len(parts) > 3 || slices.Contains(parts, "") {
+		return fmt.Errorf("invalid platform %q: expected os/arch, such as linux/amd64", platform)
+	}
+	return nil
+}
@@ -0,0 +1,14 @@
+package types
+
+// ProgressReporter receives the progress of the tasks of a long operation,
+// such as the images of a pull or the services of a startup. Tasks report
+// from several goroutines at once.
+type ProgressReporter interface {
+	// Update reports what a task is doing; total is 0 while its size is
+	// unknown
+	Update(task, status string, current, total int64)
+	// Done reports a task that finished
+	Done(task, status string)
+	// Fail reports a task that failed
+	Fail(task, status string)
+}
@@ -0,0 +1,25 @@
+package types
+
+// DefaultProxyDomain is the domain services are routed under by default.
+// Browsers resolve *.localhost to the loopback interface without any DNS
+// setup.
+const DefaultProxyDomain = "localhost"
+
+// ProxyConfig configures the routes of the built-in reverse proxy
+type ProxyConfig struct {
+	// Domain services are routed under, as <service>.<domain>
+	Domain string `yaml:"domain,omitempty" json:"domain,omitempty"`
+
+	// TLS also serves every route over HTTPS, with a certificate issued by
+	// mkcert's local certificate authority
+	TLS bool `yaml:"tls,omitempty" json:"tls,omitempty"`
+}
+
+// Host returns the host name a service is routed under
+func (c ProxyConfig) Host(serviceName string) string {
+	domain := c.Domain
+	if domain == "" {
+		domain = DefaultProxyDomain
+	}
+	return serviceName + "." + domain
+}
@@ -0,0 +1,38 @@
+package types
+
+import "time"
+
+// Categories of what prune reclaims
+const (
+	PruneContainers = "containers"
+	PruneImages     = "images"
+	PruneBackups    = "backups"
+	PruneSnapshots  = "snapshots"
+	PruneVersions   = "versions"
+)
+
+// PruneCategories lists the prune categories in the order they are reported
+var PruneCategories = []string{PruneContainers, PruneImages, PruneBackups, PruneSnapshots, PruneVersions}
+
+// Reclaimable is something prune can remove, with the disk space removing
+// it frees
+type Reclaimable struct {
+	Category string `json:"category"`
+	// Name is the name of a container, the ID of an image, or the path of a
+	// backup, snapshot or version install
+	Name string `json:"name"`
+	// Detail says what the item belongs to, such as the project of a
+	// container or the repository of an image
+	Detail    string    `json:"detail,omitempty"`
+	Size      int64     `json:"size"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// ReclaimableSize returns the total size of items
+func ReclaimableSize(items []Reclaimable) int64 {
+	var size int64
+	for _, item := range items {
+		size += item.Size
+	}
+	return size
+}
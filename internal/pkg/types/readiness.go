@@ -0,0 +1,46 @@
+package types
+
+// Readiness probe types
+const (
+	ProbeTCP        = "tcp"
+	ProbeHTTP       = "http"
+	ProbeExec       = "exec"
+	ProbeSQL        = "sql"
+	ProbeKafkaTopic = "kafka_topic"
+)
+
+// ReadinessConfig describes how to decide that a service is ready to accept
+// traffic. All probes must pass before the service is considered ready.
+type ReadinessConfig struct {
+	Timeout string           `yaml:"timeout,omitempty" json:"timeout,omitempty"`
+	Probes  []ReadinessProbe `yaml:"probes,omitempty" json:"probes,omitempty"`
+}
+
+// ReadinessProbe is a single readiness check
+type ReadinessProbe struct {
+	Type string `yaml:"type" json:"type"`
+
+	// tcp: host port to connect to (host defaults to localhost). Like the
+	// URL, it may reference ${VAR:-default}, for the variable the compose
+	// port mapping of the service takes the host port from.
+	Host string `yaml:"host,omitempty" json:"host,omitempty"`
+	Port string `yaml:"port,omitempty" json:"port,omitempty"`
+
+	// http: URL to GET and the status code to expect (defaults to 200)
+	URL            string `yaml:"url,omitempty" json:"url,omitempty"`
+	ExpectedStatus int    `yaml:"expected_status,omitempty" json:"expected_status,omitempty"`
+
+	// exec/sql: command run inside the service container; exit code 0 passes
+	Command []string `yaml:"command,omitempty" json:"command,omitempty"`
+
+	// kafka_topic: topic that must exist on the broker
+	Topic           string `yaml:"topic,omitempty" json:"topic,omitempty"`
+	BootstrapServer string `yaml:"bootstrap_server,omitempty" json:"bootstrap_server,omitempty"`
+}
+
+// ExecResult captures the outcome of a non-interactive command run inside a container
+type ExecResult struct {
+	ExitCode int
+	Stdout   string
+	Stderr   string
+}
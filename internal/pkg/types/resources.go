@@ -0,0 +1,76 @@
+package types
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/docker/go-units"
+)
+
+// ResourceLimits caps what a service container may use. Compose file
+// generation maps it to deploy.resources.limits.
+type ResourceLimits struct {
+	// CPU is the number of CPUs, such as 0.5 or 2
+	CPU string `yaml:"cpu,omitempty" json:"cpu,omitempty"`
+	// Memory is a byte size such as 512m or 1g
+	Memory string `yaml:"memory,omitempty" json:"memory,omitempty"`
+	// PIDs caps the number of processes
+	PIDs int64 `yaml:"pids,omitempty" json:"pids,omitempty"`
+}
+
+// IsZero reports whether no limit is set
+func (r ResourceLimits) IsZero() bool {
+	return r == ResourceLimits{}
+}
+
+// Merge returns r with the limits set in override replacing its own
+func (r ResourceLimits) Merge(override ResourceLimits) ResourceLimits {
+	if override.CPU != "" {
+		r.CPU = override.CPU
+	}
+	if override.Memory != "" {
+		r.Memory = override.Memory
+	}
+	if override.PIDs != 0 {
+		r.PIDs = override.PIDs
+	}
+	return r
+}
+
+// CPUs returns the CPU limit, or 0 when none is set
+func (r ResourceLimits) CPUs() (float64, error) {
+	if r.CPU == "" {
+		return 0, nil
+	}
+	cpus, err := strconv.ParseFloat(r.CPU, 64)
+	if err != nil || cpus <= 0 {
+		return 0, fmt.Errorf("invalid cpu %q: expected a positive number of CPUs", r.CPU)
+	}
+	return cpus, nil
+}
+
+// MemoryBytes returns the memory limit in bytes, or 0 when none is set
+func (r ResourceLimits) MemoryBytes() (int64, error) {
+	if r.Memory == "" {
+		return 0, nil
+	}
+	bytes, err := units.RAMInBytes(r.Memory)
+	if err != nil || bytes <= 0 {
+		return 0, fmt.Errorf("invalid memory %q: expected a size such as 512m or 1g", r.Memory)
+	}
+	return bytes, nil
+}
+
+// Validate checks that the limits are well-formed
+func (r ResourceLimits) Validate() error {
+	if _, err := r.CPUs(); err != nil {
+		return err
+	}
+	if _, err := r.MemoryBytes(); err != nil {
+		return err
+	}
+	if r.PIDs < 0 {
+		return fmt.Errorf("invalid pids %d: expected a positive number", r.PIDs)
+	}
+	return nil
+}
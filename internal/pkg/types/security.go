@@ -0,0 +1,129 @@
+package types
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// Security profiles of the stack's containers
+const (
+	SecurityProfileNone     = "none"
+	SecurityProfileHardened = "hardened"
+	SecurityProfileGVisor   = "gvisor"
+	SecurityProfileSysbox   = "sysbox"
+)
+
+// Controls a security profile applies, each of which services can be
+// exempted from
+const (
+	SecurityUserns          = "userns"
+	SecurityReadOnly        = "read_only"
+	SecurityNoNewPrivileges = "no_new_privileges"
+	SecurityCapDrop         = "cap_drop"
+	SecurityRuntime         = "runtime"
+)
+
+// OCI runtimes of the gvisor and sysbox profiles
+const (
+	RuntimeGVisor = "runsc"
+	RuntimeSysbox = "sysbox-runc"
+)
+
+// DefaultSecurityTmpfs are the paths kept writable in containers whose root
+// filesystem is read-only, where most images write sockets and pid files
+var DefaultSecurityTmpfs = []string{"/tmp", "/run"}
+
+// SecurityProfiles are the values of security.profile
+var SecurityProfiles = []string{SecurityProfileNone, SecurityProfileHardened, SecurityProfileGVisor, SecurityProfileSysbox}
+
+// SecurityControls are the controls services can be exempted from
+var SecurityControls = []string{SecurityUserns, SecurityReadOnly, SecurityNoNewPrivileges, SecurityCapDrop, SecurityRuntime}
+
+// SecurityConfig hardens the containers of the stack, for machines whose
+// security policy doesn't allow containers the default privileges
+type SecurityConfig struct {
+	// Profile is none, the default; hardened, which expects the daemon to
+	// remap users, mounts root filesystems read-only, sets no-new-privileges
+	// and drops all capabilities; or gvisor and sysbox, which also run
+	// containers under the runsc or sysbox-runc runtime
+	Profile string `yaml:"profile,omitempty" json:"profile,omitempty"`
+	// Runtime replaces the OCI runtime of the profile, such as runsc-kvm
+	// for a gVisor runtime registered under another name
+	Runtime string `yaml:"runtime,omitempty" json:"runtime,omitempty"`
+	// Tmpfs are the paths mounted writable in read-only containers,
+	// DefaultSecurityTmpfs when unset
+	Tmpfs []string `yaml:"tmpfs,omitempty" json:"tmpfs,omitempty"`
+	// Exempt lists the controls each service, by name, runs without
+	Exempt map[string][]string `yaml:"exempt,omitempty" json:"exempt,omitempty"`
+}
+
+// Enabled reports whether the configuration sets a profile
+func (c SecurityConfig) Enabled() bool {
+	return c.Profile != "" && c.Profile != SecurityProfileNone
+}
+
+// RuntimeName returns the OCI runtime containers run under, or an empty
+// string for Docker's default
+func (c SecurityConfig) RuntimeName() string {
+	if !c.Enabled() {
+		return ""
+	}
+	if c.Runtime != "" {
+		return c.Runtime
+	}
+	switch c.Profile {
+	case SecurityProfileGVisor:
+		return RuntimeGVisor
+	case SecurityProfileSysbox:
+		return RuntimeSysbox
+	}
+	return ""
+}
+
+// TmpfsPaths returns the paths mounted writable in read-only containers
+func (c SecurityConfig) TmpfsPaths() []string {
+	if len(c.Tmpfs) > 0 {
+		return c.Tmpfs
+	}
+	return DefaultSecurityTmpfs
+}
+
+// Applies reports whether service runs with control under the profile
+func (c SecurityConfig) Applies(service, control string) bool {
+	if !c.Enabled() {
+		return false
+	}
+	if control == SecurityRuntime && c.RuntimeName() == "" {
+		return false
+	}
+	return !slices.Contains(c.Exempt[service], control)
+}
+
+// Validate checks the profile, the tmpfs paths and the exemptions
+func (c SecurityConfig) Validate() error {
+	if c.Profile != "" && !slices.Contains(SecurityProfiles, c.Profile) {
+		return fmt.Errorf("security.profile: invalid profile %q: must be one of %s", c.Profile, strings.Join(SecurityProfiles, ", "))
+	}
+	if !c.Enabled() && (c.Runtime != "" || len(c.Exempt) > 0) {
+		return fmt.Errorf("security.runtime and security.exempt need a security.profile")
+	}
+	for _, path := range c.Tmpfs {
+		if !strings.HasPrefix(path, "/") {
+			return fmt.Errorf("security.tmpfs: %q must be an absolute container path", path)
+		}
+	}
+	for _, service := range slices.Sorted(maps.Keys(c.Exempt)) {
+		for _, control := range c.Exempt[service] {
+			if !slices.Contains(SecurityControls, control) {
+				return fmt.Errorf("security.exempt.%s: invalid control %q: must be one of %s", service, control, strings.Join(SecurityControls, ", "))
+			}
+		}
+		// sysbox-runc always runs containers in a user namespace
+		if c.RuntimeName() == RuntimeSysbox && !c.Applies(service, SecurityUserns) && c.Applies(service, SecurityRuntime) {
+			return fmt.Errorf("security.exempt.%s: %s always remaps users; exempt the service from %s as well", service, RuntimeSysbox, SecurityRuntime)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+package types
+
+import "time"
+
+// Shutdown results of a service
+const (
+	// ShutdownStopped is a service whose containers exited within their
+	// grace period
+	ShutdownStopped = "stopped"
+	// ShutdownKilled is a service killed once its grace period was over
+	ShutdownKilled = "killed"
+	// ShutdownRunning is a service still running after its grace period,
+	// left running since killing wasn't asked for
+	ShutdownRunning = "running"
+)
+
+// ShutdownConfig describes how a service is stopped: the commands that put
+// its data on disk first, and how long it may take to exit
+type ShutdownConfig struct {
+	// GracePeriod is how long the service has to exit once sent its stop
+	// signal, such as 30s; unset, the --timeout of the command applies
+	GracePeriod string `yaml:"grace_period,omitempty" json:"grace_period,omitempty"`
+	// PreStop are commands run in order inside the running container
+	// before it is stopped, such as one flushing a cache to disk
+	PreStop [][]string `yaml:"pre_stop,omitempty" json:"pre_stop,omitempty"`
+}
+
+// ShutdownOptions controls how services are stopped one dependency level
+// at a time
+type ShutdownOptions struct {
+	// Timeout is the grace period, in seconds, of services without one
+	Timeout int
+	// Services are the shutdown settings of services, by name
+	Services map[string]ShutdownConfig
+	// Force kills the services still running after their grace period
+	Force bool
+	// Progress reports each service as it stops
+	Progress ProgressReporter
+}
+
+// ServiceShutdown is how a service stopped
+type ServiceShutdown struct {
+	Service  string        `json:"service"`
+	Result   string        `json:"result"`
+	Duration time.Duration `json:"duration"`
+	// PreStopError is set when a pre-stop command failed; the service is
+	// stopped regardless
+	PreStopError string `json:"pre_stop_error,omitempty"`
+}
+
+// ShutdownReport lists how each service stopped, in the order they were
+// stopped
+type ShutdownReport struct {
+	Services []ServiceShutdown `json:"services"`
+}
+
+// Named returns the services with result
+func (r *ShutdownReport) Named(result string) []string {
+	var names []string
+	for _, service := range r.Services {
+		if service.Result == result {
+			names = append(names, service.Service)
+		}
+	}
+	return names
+}
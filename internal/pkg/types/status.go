@@ -9,6 +9,10 @@ const (
 	ServiceStateRunning ServiceState = constants.StateRunning
 	ServiceStateStopped ServiceState = constants.StateStopped
 	ServiceStateCreated ServiceState = constants.StateCreated
+	ServiceStatePaused  ServiceState = constants.StatePaused
+
+	ServiceStateRestarting   ServiceState = constants.StateRestarting
+	ServiceStateCrashLooping ServiceState = constants.StateCrashLooping
 )
 
 // String returns the string representation of the service state
@@ -26,6 +30,11 @@ func (s ServiceState) IsStopped() bool {
 	return s == ServiceStateStopped
 }
 
+// IsPaused returns true if the service is paused
+func (s ServiceState) IsPaused() bool {
+	return s == ServiceStatePaused
+}
+
 // HealthStatus represents the health status of a service
 type HealthStatus string
 
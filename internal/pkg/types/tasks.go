@@ -0,0 +1,62 @@
+package types
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// TasksConfig maps task names to the tasks dev-stack run runs
+type TasksConfig map[string]TaskConfig
+
+// TaskConfig is a shell command run once the services it depends on are up
+// and healthy
+type TaskConfig struct {
+	Description string `yaml:"description,omitempty" json:"description,omitempty"`
+	// Command is run with sh -c
+	Command string `yaml:"command" json:"command"`
+	// WorkingDir is where the command runs, relative to the project root
+	WorkingDir string            `yaml:"working_dir,omitempty" json:"working_dir,omitempty"`
+	Env        map[string]string `yaml:"env,omitempty" json:"env,omitempty"`
+	// DependsOn lists the services started and waited for first
+	DependsOn []string `yaml:"depends_on,omitempty" json:"depends_on,omitempty"`
+}
+
+// Names returns the names of the tasks, sorted
+func (c TasksConfig) Names() []string {
+	return slices.Sorted(maps.Keys(c))
+}
+
+// Validate checks that every task has a command and a working directory
+// inside the project
+func (c TasksConfig) Validate() error {
+	for _, name := range c.Names() {
+		task := c[name]
+		if strings.TrimSpace(task.Command) == "" {
+			return fmt.Errorf("tasks.%s: command is required", name)
+		}
+		if dir := task.WorkingDir; dir != "" && (filepath.IsAbs(dir) || !filepath.IsLocal(filepath.Clean(dir))) {
+			return fmt.Errorf("tasks.%s: working_dir %q must be a directory inside the project", name, dir)
+		}
+		for _, service := range task.DependsOn {
+			if strings.TrimSpace(service) == "" {
+				return fmt.Errorf("tasks.%s: depends_on has an empty service name", name)
+			}
+		}
+	}
+	return nil
+}
+
+// ValidateTaskImports checks the Makefiles and justfiles tasks are imported
+// from
+func ValidateTaskImports(paths []string) error {
+	for _, path := range paths {
+		if strings.TrimSpace(path) == "" {
+			return errors.New("task_imports: empty path")
+		}
+	}
+	return nil
+}
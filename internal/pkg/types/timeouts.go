@@ -0,0 +1,43 @@
+package types
+
+import "time"
+
+// Default timeouts of Docker operations
+const (
+	DefaultStartTimeout = 10 * time.Minute
+	DefaultStopTimeout  = 2 * time.Minute
+	DefaultExecTimeout  = 30 * time.Minute
+	DefaultPullTimeout  = 10 * time.Minute
+)
+
+// TimeoutConfig bounds how long each class of Docker operation may run
+// before dev-stack gives up on it. Unset timeouts use the defaults.
+type TimeoutConfig struct {
+	// Start bounds docker compose up, restart and build
+	Start time.Duration `yaml:"start,omitempty" json:"start,omitempty"`
+	// Stop bounds stopping and removing containers
+	Stop time.Duration `yaml:"stop,omitempty" json:"stop,omitempty"`
+	// Exec bounds non-interactive commands run in a container, such as
+	// backups, restores and seeds. Interactive sessions are never cut short.
+	Exec time.Duration `yaml:"exec,omitempty" json:"exec,omitempty"`
+	// Pull bounds pulling each image, retries and mirrors included
+	Pull time.Duration `yaml:"pull,omitempty" json:"pull,omitempty"`
+}
+
+// WithDefaults returns the timeouts with the defaults in place of the ones
+// that aren't set
+func (c TimeoutConfig) WithDefaults() TimeoutConfig {
+	if c.Start <= 0 {
+		c.Start = DefaultStartTimeout
+	}
+	if c.Stop <= 0 {
+		c.Stop = DefaultStopTimeout
+	}
+	if c.Exec <= 0 {
+		c.Exec = DefaultExecTimeout
+	}
+	if c.Pull <= 0 {
+		c.Pull = DefaultPullTimeout
+	}
+	return c
+}
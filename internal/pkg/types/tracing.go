@@ -0,0 +1,20 @@
+package types
+
+import "slices"
+
+// TracingConfig configures how the project's application services report
+// traces to the stack's Jaeger
+type TracingConfig struct {
+	// InjectEnv adds the OpenTelemetry exporter variables pointing at Jaeger
+	// to the environment of the services that build their image, when the
+	// stack enables jaeger
+	InjectEnv bool `yaml:"inject_env,omitempty" json:"inject_env,omitempty"`
+	// Services limits the injection to these services
+	Services []string `yaml:"services,omitempty" json:"services,omitempty"`
+}
+
+// Injects reports whether the exporter variables are added to a service
+// that builds its image
+func (c TracingConfig) Injects(serviceName string) bool {
+	return c.InjectEnv && (len(c.Services) == 0 || slices.Contains(c.Services, serviceName))
+}
@@ -0,0 +1,45 @@
+package types
+
+import (
+	"errors"
+	"slices"
+	"strings"
+)
+
+// TrustConfig adds certificate authorities to the trust stores of the
+// containers of the stack, so they trust endpoints with certificates of an
+// internal authority, such as those a TLS-inspecting proxy presents
+type TrustConfig struct {
+	// CABundles are PEM files of the authorities to trust, relative to the
+	// project root
+	CABundles []string `yaml:"ca_bundles,omitempty" json:"ca_bundles,omitempty"`
+	// SystemBundle is the PEM file of the public roots the authorities are
+	// added to, which defaults to that of the host
+	SystemBundle string `yaml:"system_bundle,omitempty" json:"system_bundle,omitempty"`
+	// Exempt lists the services that keep the trust store of their image
+	Exempt []string `yaml:"exempt,omitempty" json:"exempt,omitempty"`
+}
+
+// Enabled reports whether the project adds certificate authorities
+func (c TrustConfig) Enabled() bool {
+	return len(c.CABundles) > 0
+}
+
+// Applies reports whether the trust store of a service is replaced
+func (c TrustConfig) Applies(service string) bool {
+	return c.Enabled() && !slices.Contains(c.Exempt, service)
+}
+
+// Validate checks that bundle paths are set, and that bundles are listed
+// for the settings that refine them
+func (c TrustConfig) Validate() error {
+	for _, path := range c.CABundles {
+		if strings.TrimSpace(path) == "" {
+			return errors.New("trust.ca_bundles: empty path")
+		}
+	}
+	if !c.Enabled() && (c.SystemBundle != "" || len(c.Exempt) > 0) {
+		return errors.New("trust.system_bundle and trust.exempt only apply with trust.ca_bundles")
+	}
+	return nil
+}
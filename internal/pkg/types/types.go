@@ -127,6 +127,10 @@ type ServiceStatus struct {
 	Labels    map[string]string `json:"labels"`
 	CreatedAt time.Time         `json:"created_at"`
 	StartedAt *time.Time        `json:"started_at,omitempty"`
+	// RestartCount is how many times Docker restarted the container and
+	// ExitCode how its last run ended
+	RestartCount int `json:"restart_count"`
+	ExitCode     int `json:"exit_code"`
 }
 
 // MemoryUsage represents memory usage statistics
@@ -135,6 +139,27 @@ type MemoryUsage struct {
 	Limit uint64 `json:"limit"`
 }
 
+// Percent returns the memory used as a percentage of the limit
+func (m MemoryUsage) Percent() float64 {
+	if m.Limit == 0 {
+		return 0
+	}
+	return float64(m.Used) / float64(m.Limit) * 100
+}
+
+// ServiceStats is a live resource usage sample of a service container
+type ServiceStats struct {
+	Service    string      `json:"service"`
+	Container  string      `json:"container"`
+	CPUPercent float64     `json:"cpu_percent"`
+	Memory     MemoryUsage `json:"memory"`
+	NetworkRx  uint64      `json:"network_rx_bytes"`
+	NetworkTx  uint64      `json:"network_tx_bytes"`
+	BlockRead  uint64      `json:"block_read_bytes"`
+	BlockWrite uint64      `json:"block_write_bytes"`
+	PIDs       uint64      `json:"pids"`
+}
+
 // StackStatus represents the overall status of a development stack
 type StackStatus struct {
 	Project     string          `json:"project"`
@@ -223,6 +248,51 @@ func (e Error) Unwrap() error {
 	return e.Cause
 }
 
+// ExitError reports that a command run in a container exited non-zero, so
+// dev-stack can exit with the same code
+type ExitError struct {
+	Command string
+	Code    int
+}
+
+func (e *ExitError) Error() string {
+	return fmt.Sprintf("%s exited with code %d", e.Command, e.Code)
+}
+
+// ConnectionInfo describes how to connect to a running service: the client
+// to run, in its container unless Host is set, the connection string for
+// clients on the host and the URL of its web interface
+type ConnectionInfo struct {
+	Client  string
+	Host    bool
+	Command []string
+	Env     []string
+	URL     string
+	UIURL   string
+}
+
+// EphemeralStack is a compose project started with an expiry, whose
+// containers are removed together with their volumes once it has passed
+type EphemeralStack struct {
+	Project    string    `json:"project"`
+	ExpiresAt  time.Time `json:"expires_at"`
+	Containers []string  `json:"containers"`
+}
+
+// Expired reports whether the stack's TTL has run out at now
+func (s EphemeralStack) Expired(now time.Time) bool {
+	return !now.Before(s.ExpiresAt)
+}
+
+// VolumeMount is a Docker volume mounted into a service's container. Named
+// volumes are declared in the compose file and outlive their containers;
+// anonymous ones are removed with them.
+type VolumeMount struct {
+	Name        string `json:"name"`
+	Destination string `json:"destination"`
+	Named       bool   `json:"named"`
+}
+
 // NewError creates a new Error with the given code and message
 func NewError(code, message string) Error {
 	return Error{
@@ -0,0 +1,33 @@
+package types
+
+// Watch actions, matching the compose develop.watch actions
+const (
+	WatchActionSync        = "sync"
+	WatchActionRestart     = "restart"
+	WatchActionSyncRestart = "sync+restart"
+	WatchActionRebuild     = "rebuild"
+)
+
+// DevConfig configures the dev command
+type DevConfig struct {
+	// Debounce is how long to wait for changes to settle, such as "500ms"
+	Debounce string `yaml:"debounce,omitempty" json:"debounce,omitempty"`
+
+	// Watch maps services to watch rules, in addition to the develop.watch
+	// sections of the compose file
+	Watch map[string][]WatchRule `yaml:"watch,omitempty" json:"watch,omitempty"`
+}
+
+// DevelopConfig is the compose develop section of a service
+type DevelopConfig struct {
+	Watch []WatchRule `yaml:"watch,omitempty" json:"watch,omitempty"`
+}
+
+// WatchRule triggers an action when files under Path change. Sync actions
+// copy changed files to Target inside the container.
+type WatchRule struct {
+	Path   string   `yaml:"path" json:"path"`
+	Action string   `yaml:"action" json:"action"`
+	Target string   `yaml:"target,omitempty" json:"target,omitempty"`
+	Ignore []string `yaml:"ignore,omitempty" json:"ignore,omitempty"`
+}
@@ -0,0 +1,156 @@
+package types
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+	"text/template"
+)
+
+// workflowParamPattern is what parameter names look like: they are passed
+// as --name and referenced as {{.name}} in steps
+var workflowParamPattern = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+// WorkflowsConfig maps workflow names to the workflows a project defines
+type WorkflowsConfig map[string]WorkflowConfig
+
+// WorkflowConfig is a named sequence of steps run by dev-stack workflow,
+// with the parameters its steps reference
+type WorkflowConfig struct {
+	Description string                   `yaml:"description,omitempty" json:"description,omitempty"`
+	Params      map[string]WorkflowParam `yaml:"params,omitempty" json:"params,omitempty"`
+	// Steps run in order like those of hooks
+	Steps []HookStep `yaml:"steps" json:"steps"`
+}
+
+// WorkflowParam is a parameter of a workflow, given as --<name> <value>
+type WorkflowParam struct {
+	Description string `yaml:"description,omitempty" json:"description,omitempty"`
+	Required    bool   `yaml:"required,omitempty" json:"required,omitempty"`
+	// Default is the value of the parameter when it isn't given
+	Default string `yaml:"default,omitempty" json:"default,omitempty"`
+}
+
+// ParamNames returns the names of the parameters of the workflow, sorted
+func (w WorkflowConfig) ParamNames() []string {
+	return slices.Sorted(maps.Keys(w.Params))
+}
+
+// Validate checks the parameter names of the workflow, and that its steps
+// only reference parameters it declares
+func (w WorkflowConfig) Validate() error {
+	if len(w.Steps) == 0 {
+		return errors.New("has no steps")
+	}
+	for _, name := range w.ParamNames() {
+		if !workflowParamPattern.MatchString(name) {
+			return fmt.Errorf("params.%s: names are lowercase letters, digits and underscores", name)
+		}
+		if w.Params[name].Required && w.Params[name].Default != "" {
+			return fmt.Errorf("params.%s: a required parameter has no default", name)
+		}
+	}
+
+	// Every parameter is set when steps render, so a reference to an
+	// undeclared one fails against the declared ones
+	placeholders := make(map[string]string, len(w.Params))
+	for name := range w.Params {
+		placeholders[name] = ""
+	}
+	_, err := w.RenderSteps(placeholders)
+	return err
+}
+
+// ResolveParams returns the value of every parameter of the workflow from
+// values, falling back to their defaults. Values for undeclared parameters
+// and missing required ones are errors.
+func (w WorkflowConfig) ResolveParams(values map[string]string) (map[string]string, error) {
+	for _, name := range slices.Sorted(maps.Keys(values)) {
+		if _, ok := w.Params[name]; !ok {
+			return nil, fmt.Errorf("unknown parameter --%s%s", name, w.paramsHint())
+		}
+	}
+
+	resolved := make(map[string]string, len(w.Params))
+	var missing []string
+	for _, name := range w.ParamNames() {
+		value, ok := values[name]
+		if !ok {
+			value = w.Params[name].Default
+		}
+		if w.Params[name].Required && value == "" {
+			missing = append(missing, "--"+name)
+		}
+		resolved[name] = value
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("missing required parameter %s", strings.Join(missing, ", "))
+	}
+	return resolved, nil
+}
+
+// RenderSteps returns the steps of the workflow with {{.name}} references
+// in their run, command and env replaced by the values of params
+func (w WorkflowConfig) RenderSteps(params map[string]string) ([]HookStep, error) {
+	render := func(text string) (string, error) {
+		if !strings.Contains(text, "{{") {
+			return text, nil
+		}
+		tmpl, err := template.New("step").Option("missingkey=error").Parse(text)
+		if err != nil {
+			return "", err
+		}
+		var rendered strings.Builder
+		if err := tmpl.Execute(&rendered, params); err != nil {
+			return "", err
+		}
+		return rendered.String(), nil
+	}
+
+	steps := make([]HookStep, 0, len(w.Steps))
+	for i, step := range w.Steps {
+		var err error
+		if step.Run, err = render(step.Run); err != nil {
+			return nil, fmt.Errorf("steps[%d].run: %w", i, err)
+		}
+		if step.Command, err = render(step.Command); err != nil {
+			return nil, fmt.Errorf("steps[%d].command: %w", i, err)
+		}
+		if len(step.Env) > 0 {
+			env := make(map[string]string, len(step.Env))
+			for key, value := range step.Env {
+				if env[key], err = render(value); err != nil {
+					return nil, fmt.Errorf("steps[%d].env.%s: %w", i, key, err)
+				}
+			}
+			step.Env = env
+		}
+		steps = append(steps, step)
+	}
+	return steps, nil
+}
+
+// paramsHint lists the parameters of the workflow for error messages
+func (w WorkflowConfig) paramsHint() string {
+	if len(w.Params) == 0 {
+		return " (the workflow takes none)"
+	}
+	flags := make([]string, 0, len(w.Params))
+	for _, name := range w.ParamNames() {
+		flags = append(flags, "--"+name)
+	}
+	return " (supported: " + strings.Join(flags, ", ") + ")"
+}
+
+// Validate checks the workflows of the project
+func (c WorkflowsConfig) Validate() error {
+	for _, name := range slices.Sorted(maps.Keys(c)) {
+		if err := c[name].Validate(); err != nil {
+			return fmt.Errorf("workflows.%s: %w", name, err)
+		}
+	}
+	return nil
+}
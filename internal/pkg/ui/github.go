@@ -0,0 +1,114 @@
+package ui
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Environment variables GitHub Actions sets in the steps of a job
+const (
+	envGitHubActions     = "GITHUB_ACTIONS"
+	envGitHubStepSummary = "GITHUB_STEP_SUMMARY"
+	envGitHubWorkspace   = "GITHUB_WORKSPACE"
+)
+
+// GitHubActions reports whether dev-stack runs in a GitHub Actions job
+func GitHubActions() bool {
+	return os.Getenv(envGitHubActions) == "true"
+}
+
+// Group starts a collapsible group of log lines in GitHub Actions jobs and
+// returns the function ending it. Elsewhere both do nothing.
+func (o *Output) Group(title string, args ...interface{}) func() {
+	if !o.GitHub || o.Record != nil {
+		return func() {}
+	}
+	fmt.Printf("::group::%s\n", escapeData(fmt.Sprintf(title, args...)))
+	return func() { fmt.Println("::endgroup::") }
+}
+
+// FileError prints an error about file, which GitHub Actions jobs show as
+// an annotation of the file
+func (o *Output) FileError(file, msg string, args ...interface{}) {
+	if !o.GitHub || o.Record != nil {
+		o.Error(msg, args...)
+		return
+	}
+	fmt.Fprintln(os.Stderr, workflowCommand("error", file, fmt.Sprintf(msg, args...)))
+}
+
+// FileWarning prints a warning about file, which GitHub Actions jobs show
+// as an annotation of the file
+func (o *Output) FileWarning(file, msg string, args ...interface{}) {
+	if !o.GitHub || o.Record != nil {
+		o.Warning(msg, args...)
+		return
+	}
+	if !o.Quiet {
+		fmt.Println(workflowCommand("warning", file, fmt.Sprintf(msg, args...)))
+	}
+}
+
+// workflowCommand renders the GitHub Actions command annotating file, or
+// the job when file is empty, with message
+func workflowCommand(level, file, message string) string {
+	properties := ""
+	if file != "" {
+		properties = " file=" + escapeProperty(annotationPath(file))
+	}
+	return fmt.Sprintf("::%s%s::%s", level, properties, escapeData(message))
+}
+
+// annotationPath returns file relative to the workspace of the job, as
+// annotations name files, when it is inside the workspace
+func annotationPath(file string) string {
+	workspace := os.Getenv(envGitHubWorkspace)
+	abs, err := filepath.Abs(file)
+	if workspace == "" || err != nil {
+		return file
+	}
+	rel, err := filepath.Rel(workspace, abs)
+	if err != nil || !filepath.IsLocal(rel) {
+		return file
+	}
+	return filepath.ToSlash(rel)
+}
+
+// escapeData escapes the message of a workflow command, which ends at the
+// end of the line
+func escapeData(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A").Replace(s)
+}
+
+// escapeProperty escapes a property of a workflow command, where colons
+// and commas separate properties
+func escapeProperty(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A", ":", "%3A", ",", "%2C").Replace(s)
+}
+
+// Group, FileError and FileWarning on the default output
+func Group(title string, args ...interface{}) func()  { return DefaultOutput.Group(title, args...) }
+func FileError(file, msg string, args ...interface{}) { DefaultOutput.FileError(file, msg, args...) }
+func FileWarning(file, msg string, args ...interface{}) {
+	DefaultOutput.FileWarning(file, msg, args...)
+}
+
+// AppendJobSummary adds markdown to the summary of the GitHub Actions job
+// step. Outside GitHub Actions it does nothing.
+func AppendJobSummary(markdown string) error {
+	path := os.Getenv(envGitHubStepSummary)
+	if path == "" {
+		return nil
+	}
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open the job summary: %w", err)
+	}
+	if _, err := fmt.Fprintln(file, markdown); err != nil {
+		_ = file.Close()
+		return fmt.Errorf("failed to write the job summary: %w", err)
+	}
+	return file.Close()
+}
@@ -12,8 +12,36 @@ import (
 type Output struct {
 	Quiet   bool
 	NoColor bool
+	// NoProgress prints progress as plain lines even on a terminal
+	NoProgress bool
+	// Plain prints messages without icons, so logs read the same in any
+	// viewer. It applies on top of NoColor.
+	Plain bool
+	// JSONProgress prints progress as a JSON event per line
+	JSONProgress bool
+	// GitHub prints errors and warnings as GitHub Actions workflow
+	// commands, which the job shows as annotations, and turns on groups
+	GitHub bool
+	// Record, when set, receives the messages instead of the terminal, for
+	// commands that report them as data. Headers and groups are dropped.
+	Record func(Message)
 }
 
+// Message is a message Output recorded instead of printing it
+type Message struct {
+	Level string `json:"level" yaml:"level"`
+	Text  string `json:"text" yaml:"text"`
+}
+
+// Levels of recorded messages
+const (
+	LevelSuccess = "success"
+	LevelError   = "error"
+	LevelWarning = "warning"
+	LevelInfo    = "info"
+	LevelMuted   = "muted"
+)
+
 // NewOutput creates a new output handler
 func NewOutput() *Output {
 	return &Output{}
@@ -21,12 +49,15 @@ func NewOutput() *Output {
 
 // Success prints a success message
 func (o *Output) Success(msg string, args ...interface{}) {
+	if o.recorded(LevelSuccess, msg, args) {
+		return
+	}
 	if o.Quiet {
 		return
 	}
 	formatted := fmt.Sprintf(msg, args...)
 	if o.NoColor {
-		fmt.Printf("✅ %s\n", formatted)
+		fmt.Printf("%s%s\n", o.symbol("✅ ", "[ok] "), formatted)
 	} else {
 		fmt.Println(SuccessStyle.Render("✅ " + formatted))
 	}
@@ -34,9 +65,14 @@ func (o *Output) Success(msg string, args ...interface{}) {
 
 // Error prints an error message
 func (o *Output) Error(msg string, args ...interface{}) {
+	if o.recorded(LevelError, msg, args) {
+		return
+	}
 	formatted := fmt.Sprintf(msg, args...)
-	if o.NoColor {
-		fmt.Fprintf(os.Stderr, "❌ %s\n", formatted)
+	if o.GitHub {
+		fmt.Fprintln(os.Stderr, workflowCommand("error", "", formatted))
+	} else if o.NoColor {
+		fmt.Fprintf(os.Stderr, "%s%s\n", o.symbol("❌ ", "[error] "), formatted)
 	} else {
 		fmt.Fprintln(os.Stderr, ErrorStyle.Render("❌ "+formatted))
 	}
@@ -44,12 +80,17 @@ func (o *Output) Error(msg string, args ...interface{}) {
 
 // Warning prints a warning message
 func (o *Output) Warning(msg string, args ...interface{}) {
+	if o.recorded(LevelWarning, msg, args) {
+		return
+	}
 	if o.Quiet {
 		return
 	}
 	formatted := fmt.Sprintf(msg, args...)
-	if o.NoColor {
-		fmt.Printf("⚠️  %s\n", formatted)
+	if o.GitHub {
+		fmt.Println(workflowCommand("warning", "", formatted))
+	} else if o.NoColor {
+		fmt.Printf("%s%s\n", o.symbol("⚠️  ", "[warn] "), formatted)
 	} else {
 		fmt.Println(WarningStyle.Render("⚠️  " + formatted))
 	}
@@ -57,12 +98,15 @@ func (o *Output) Warning(msg string, args ...interface{}) {
 
 // Info prints an info message
 func (o *Output) Info(msg string, args ...interface{}) {
+	if o.recorded(LevelInfo, msg, args) {
+		return
+	}
 	if o.Quiet {
 		return
 	}
 	formatted := fmt.Sprintf(msg, args...)
 	if o.NoColor {
-		fmt.Printf("ℹ️  %s\n", formatted)
+		fmt.Printf("%s%s\n", o.symbol("ℹ️  ", "[info] "), formatted)
 	} else {
 		fmt.Println(InfoStyle.Render("ℹ️  " + formatted))
 	}
@@ -70,7 +114,7 @@ func (o *Output) Info(msg string, args ...interface{}) {
 
 // Header prints a styled header
 func (o *Output) Header(msg string, args ...interface{}) {
-	if o.Quiet {
+	if o.Quiet || o.Record != nil {
 		return
 	}
 	formatted := fmt.Sprintf(msg, args...)
@@ -83,7 +127,7 @@ func (o *Output) Header(msg string, args ...interface{}) {
 
 // SubHeader prints a styled sub-header
 func (o *Output) SubHeader(msg string, args ...interface{}) {
-	if o.Quiet {
+	if o.Quiet || o.Record != nil {
 		return
 	}
 	formatted := fmt.Sprintf(msg, args...)
@@ -101,7 +145,7 @@ func (o *Output) List(items []string) {
 	}
 	for _, item := range items {
 		if o.NoColor {
-			fmt.Printf("  • %s\n", item)
+			fmt.Printf("  %s %s\n", o.symbol("•", "-"), item)
 		} else {
 			fmt.Println(ListItemStyle.Render("• " + item))
 		}
@@ -124,6 +168,9 @@ func (o *Output) Progress(msg string, fn func() error) error {
 
 // Muted prints muted text
 func (o *Output) Muted(msg string, args ...interface{}) {
+	if o.recorded(LevelMuted, msg, args) {
+		return
+	}
 	if o.Quiet {
 		return
 	}
@@ -137,7 +184,7 @@ func (o *Output) Muted(msg string, args ...interface{}) {
 
 // Box prints content in a styled box
 func (o *Output) Box(title, content string) {
-	if o.Quiet {
+	if o.Quiet || o.Record != nil {
 		return
 	}
 	if o.NoColor {
@@ -148,6 +195,23 @@ func (o *Output) Box(title, content string) {
 	}
 }
 
+// recorded passes a message to Record, and reports whether Record took it
+func (o *Output) recorded(level, msg string, args []interface{}) bool {
+	if o.Record == nil {
+		return false
+	}
+	o.Record(Message{Level: level, Text: fmt.Sprintf(msg, args...)})
+	return true
+}
+
+// symbol returns icon, or label for plain output
+func (o *Output) symbol(icon, label string) string {
+	if o.Plain {
+		return label
+	}
+	return icon
+}
+
 // Global output instance
 var DefaultOutput = NewOutput()
 
@@ -162,3 +226,4 @@ func List(items []string)                        { DefaultOutput.List(items) }
 func Progress(msg string, fn func() error) error { return DefaultOutput.Progress(msg, fn) }
 func Muted(msg string, args ...interface{})      { DefaultOutput.Muted(msg, args...) }
 func Box(title, content string)                  { DefaultOutput.Box(title, content) }
+func NewProgressBoard() *ProgressBoard           { return DefaultOutput.NewProgressBoard() }
@@ -0,0 +1,239 @@
+package ui
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/term"
+)
+
+// progressRedrawInterval limits how often a live board redraws for updates
+// that don't change the state of a task
+const progressRedrawInterval = 100 * time.Millisecond
+
+// progressBarWidth is the number of cells of a progress bar
+const progressBarWidth = 20
+
+// progressNameWidth caps the column task names are padded to
+const progressNameWidth = 32
+
+// Task states of a progress board
+const (
+	taskRunning = iota
+	taskDone
+	taskFailed
+)
+
+// ProgressBoard renders the tasks of a long operation, such as image
+// pulls, build steps or services starting. On a terminal each task keeps a
+// line that is redrawn in place, with a bar once its size is known.
+// Otherwise, or with --no-progress, every new status of a task is printed
+// as a line of its own, or as a JSON event for machines. It is safe for concurrent use and implements
+// types.ProgressReporter.
+type ProgressBoard struct {
+	mu       sync.Mutex
+	out      io.Writer
+	live     bool
+	json     bool
+	plain    bool
+	width    int
+	tasks    []*progressTask
+	index    map[string]*progressTask
+	drawn    int
+	lastDraw time.Time
+}
+
+// progressEvent is a new status of a task, as JSON boards print it
+type progressEvent struct {
+	Time    time.Time `json:"time"`
+	Event   string    `json:"event"`
+	Task    string    `json:"task"`
+	State   string    `json:"state"`
+	Status  string    `json:"status"`
+	Current int64     `json:"current,omitempty"`
+	Total   int64     `json:"total,omitempty"`
+}
+
+// progressStates name the task states in JSON events
+var progressStates = map[int]string{taskRunning: "running", taskDone: "done", taskFailed: "failed"}
+
+type progressTask struct {
+	name           string
+	status         string
+	current, total int64
+	state          int
+}
+
+// newProgressBoard returns a board writing to out, redrawn in place when
+// live. Lines are cut to width columns when it is positive.
+func newProgressBoard(out io.Writer, live bool, width int) *ProgressBoard {
+	return &ProgressBoard{out: out, live: live, width: width, index: make(map[string]*progressTask)}
+}
+
+// NewProgressBoard returns a board for the output, live when stdout is a
+// terminal and progress isn't turned off
+func (o *Output) NewProgressBoard() *ProgressBoard {
+	if o.Quiet {
+		return newProgressBoard(io.Discard, false, 0)
+	}
+	if o.JSONProgress {
+		board := newProgressBoard(os.Stdout, false, 0)
+		board.json = true
+		return board
+	}
+	fd := int(os.Stdout.Fd())
+	live := !o.NoProgress && !o.Plain && term.IsTerminal(fd)
+	width := 0
+	if live {
+		width, _, _ = term.GetSize(fd)
+	}
+	board := newProgressBoard(os.Stdout, live, width)
+	board.plain = o.Plain
+	return board
+}
+
+// Update reports what a task is doing; total is 0 while its size is
+// unknown
+func (b *ProgressBoard) Update(task, status string, current, total int64) {
+	b.set(task, status, current, total, taskRunning)
+}
+
+// Done reports a task that finished
+func (b *ProgressBoard) Done(task, status string) {
+	b.set(task, status, 0, 0, taskDone)
+}
+
+// Fail reports a task that failed
+func (b *ProgressBoard) Fail(task, status string) {
+	b.set(task, status, 0, 0, taskFailed)
+}
+
+// Stop draws the final state of the board. Output printed afterwards goes
+// below it.
+func (b *ProgressBoard) Stop() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.live && len(b.tasks) > 0 {
+		b.redraw()
+	}
+}
+
+func (b *ProgressBoard) set(name, status string, current, total int64, state int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	task, ok := b.index[name]
+	if !ok {
+		task = &progressTask{name: name}
+		b.index[name] = task
+		b.tasks = append(b.tasks, task)
+	}
+	changed := !ok || task.state != state || task.status != status
+	task.status, task.current, task.total, task.state = status, current, total, state
+
+	if b.json {
+		if changed {
+			b.writeEvent(task)
+		}
+		return
+	}
+	if !b.live {
+		if changed {
+			_, _ = fmt.Fprintln(b.out, b.line(task, 0))
+		}
+		return
+	}
+	if changed || time.Since(b.lastDraw) >= progressRedrawInterval {
+		b.redraw()
+	}
+}
+
+// writeEvent prints the status of a task as a JSON event
+func (b *ProgressBoard) writeEvent(task *progressTask) {
+	data, err := json.Marshal(progressEvent{
+		Time:    time.Now().UTC(),
+		Event:   "progress",
+		Task:    task.name,
+		State:   progressStates[task.state],
+		Status:  task.status,
+		Current: task.current,
+		Total:   task.total,
+	})
+	if err != nil {
+		return
+	}
+	_, _ = fmt.Fprintln(b.out, string(data))
+}
+
+// redraw moves back over the lines drawn last time and draws every task
+func (b *ProgressBoard) redraw() {
+	var buf strings.Builder
+	if b.drawn > 0 {
+		fmt.Fprintf(&buf, "\x1b[%dA", b.drawn)
+	}
+	nameWidth := 0
+	for _, task := range b.tasks {
+		nameWidth = max(nameWidth, min(len(task.name), progressNameWidth))
+	}
+	for _, task := range b.tasks {
+		buf.WriteString("\r\x1b[2K")
+		buf.WriteString(b.line(task, nameWidth))
+		buf.WriteString("\n")
+	}
+	_, _ = io.WriteString(b.out, buf.String())
+	b.drawn = len(b.tasks)
+	b.lastDraw = time.Now()
+}
+
+// line renders a task, its name padded to nameWidth
+func (b *ProgressBoard) line(task *progressTask, nameWidth int) string {
+	icon := "⏳"
+	switch task.state {
+	case taskDone:
+		icon = "✅"
+	case taskFailed:
+		icon = "❌"
+	}
+	if b.plain {
+		icon = "[" + progressStates[task.state] + "]"
+	}
+
+	if !b.live {
+		return fmt.Sprintf("%s %s: %s", icon, task.name, task.status)
+	}
+
+	detail := task.status
+	if task.state == taskRunning && task.total > 0 {
+		detail = fmt.Sprintf("%s %3d%%  %s", progressBar(task.current, task.total), percent(task.current, task.total), task.status)
+	}
+	return truncate(fmt.Sprintf("%s %-*s  %s", icon, nameWidth, task.name, detail), b.width)
+}
+
+// progressBar draws current out of total as a bar
+func progressBar(current, total int64) string {
+	filled := int(min(current, total) * progressBarWidth / total)
+	if filled >= progressBarWidth {
+		return "[" + strings.Repeat("=", progressBarWidth) + "]"
+	}
+	return "[" + strings.Repeat("=", filled) + ">" + strings.Repeat(" ", progressBarWidth-filled-1) + "]"
+}
+
+func percent(current, total int64) int64 {
+	return min(current, total) * 100 / total
+}
+
+// truncate cuts a line to fit width columns, so it never wraps and throws
+// off the redraw. The icon is two columns wide. Width 0 leaves the line as
+// it is.
+func truncate(line string, width int) string {
+	runes := []rune(line)
+	if width <= 2 || len(runes) < width-1 {
+		return line
+	}
+	return string(runes[:width-2])
+}
@@ -36,6 +36,13 @@ var (
 	MutedStyle = baseStyle.
 			Foreground(mutedColor)
 
+	// Text styles color a value inline, such as the cell of a table, so
+	// they add no padding
+	SuccessText = lipgloss.NewStyle().Foreground(successColor)
+	WarningText = lipgloss.NewStyle().Foreground(warningColor)
+	ErrorText   = lipgloss.NewStyle().Foreground(errorColor)
+	MutedText   = lipgloss.NewStyle().Foreground(mutedColor)
+
 	// Header styles
 	HeaderStyle = lipgloss.NewStyle().
 			Foreground(primaryColor).
@@ -2,8 +2,10 @@ package ui
 
 import (
 	"bytes"
+	"encoding/json"
 	"io"
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
@@ -292,6 +294,35 @@ func TestOutput_SubHeader(t *testing.T) {
 	})
 }
 
+func TestOutput_Record(t *testing.T) {
+	oldStdout, oldStderr := os.Stdout, os.Stderr
+	r, w, _ := os.Pipe()
+	os.Stdout, os.Stderr = w, w
+
+	var messages []Message
+	output := &Output{GitHub: true, Record: func(message Message) { messages = append(messages, message) }}
+	end := output.Group("Docker")
+	output.Header("Health Check")
+	output.Success("Docker is %s", "running")
+	output.FileError("dev-stack/docker-compose.yml", "Compose file is invalid")
+	output.Warning("Docker is old")
+	output.Muted("Endpoint: %s", "unix:///var/run/docker.sock")
+	end()
+
+	w.Close()
+	os.Stdout, os.Stderr = oldStdout, oldStderr
+	var buf bytes.Buffer
+	io.Copy(&buf, r)
+
+	assert.Empty(t, buf.String())
+	assert.Equal(t, []Message{
+		{Level: LevelSuccess, Text: "Docker is running"},
+		{Level: LevelError, Text: "Compose file is invalid"},
+		{Level: LevelWarning, Text: "Docker is old"},
+		{Level: LevelMuted, Text: "Endpoint: unix:///var/run/docker.sock"},
+	}, messages)
+}
+
 func TestOutput_List(t *testing.T) {
 	// Capture stdout
 	oldStdout := os.Stdout
@@ -679,3 +710,126 @@ func TestStyleConsistency(t *testing.T) {
 		}
 	})
 }
+
+func TestProgressBoard(t *testing.T) {
+	t.Run("plain lines for new statuses", func(t *testing.T) {
+		var buf bytes.Buffer
+		board := newProgressBoard(&buf, false, 0)
+		board.Update("redis:7", "pulling", 0, 0)
+		board.Update("redis:7", "1/2 layers", 10, 100)
+		board.Update("redis:7", "1/2 layers", 50, 100)
+		board.Done("redis:7", "pulled in 2s")
+		board.Fail("postgres:16", "manifest unknown")
+		board.Stop()
+
+		assert.Equal(t, "⏳ redis:7: pulling\n"+
+			"⏳ redis:7: 1/2 layers\n"+
+			"✅ redis:7: pulled in 2s\n"+
+			"❌ postgres:16: manifest unknown\n", buf.String())
+	})
+
+	t.Run("live board redraws in place", func(t *testing.T) {
+		var buf bytes.Buffer
+		board := newProgressBoard(&buf, true, 0)
+		board.Update("redis", "starting", 0, 0)
+		board.Update("api", "building [1/4] FROM golang", 1, 4)
+		board.Done("redis", "started")
+
+		api := "\r\x1b[2K⏳ api    [=====>              ]  25%  building [1/4] FROM golang\n"
+		assert.Equal(t, "\r\x1b[2K⏳ redis  starting\n"+
+			"\x1b[1A\r\x1b[2K⏳ redis  starting\n"+api+
+			"\x1b[2A\r\x1b[2K✅ redis  started\n"+api, buf.String())
+	})
+
+	t.Run("plain lines without icons", func(t *testing.T) {
+		var buf bytes.Buffer
+		board := newProgressBoard(&buf, false, 0)
+		board.plain = true
+		board.Update("redis", "starting", 0, 0)
+		board.Fail("redis", "exited with code 1")
+
+		assert.Equal(t, "[running] redis: starting\n[failed] redis: exited with code 1\n", buf.String())
+	})
+
+	t.Run("json events for new statuses", func(t *testing.T) {
+		var buf bytes.Buffer
+		board := newProgressBoard(&buf, false, 0)
+		board.json = true
+		board.Update("redis:7", "1/2 layers", 10, 100)
+		board.Update("redis:7", "1/2 layers", 50, 100)
+		board.Done("redis:7", "pulled in 2s")
+
+		lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+		assert.Len(t, lines, 2)
+		var event progressEvent
+		assert.NoError(t, json.Unmarshal([]byte(lines[0]), &event))
+		assert.Equal(t, progressEvent{Time: event.Time, Event: "progress", Task: "redis:7", State: "running", Status: "1/2 layers", Current: 10, Total: 100}, event)
+		var done progressEvent
+		assert.NoError(t, json.Unmarshal([]byte(lines[1]), &done))
+		assert.Equal(t, "done", done.State)
+		assert.Zero(t, done.Total)
+	})
+
+	t.Run("bars and truncation", func(t *testing.T) {
+		assert.Equal(t, ">                   ", progressBar(0, 10)[1:21])
+		assert.Equal(t, "[====================]", progressBar(10, 10))
+		assert.Equal(t, "[====================]", progressBar(12, 10))
+		assert.Equal(t, "⏳ abc", truncate("⏳ abcdef", 7))
+		assert.Equal(t, "short", truncate("short", 80))
+		assert.Equal(t, "short", truncate("short", 0))
+	})
+}
+
+func TestOutput_NewProgressBoard(t *testing.T) {
+	board := (&Output{Quiet: true}).NewProgressBoard()
+	assert.Equal(t, io.Discard, board.out)
+	assert.False(t, board.live)
+
+	board = (&Output{JSONProgress: true}).NewProgressBoard()
+	assert.True(t, board.json)
+	assert.False(t, board.live)
+}
+
+func TestGitHubActions(t *testing.T) {
+	t.Run("workflow commands", func(t *testing.T) {
+		t.Setenv("GITHUB_WORKSPACE", "")
+		assert.Equal(t, "::error::compose failed%0Aexit 1", workflowCommand("error", "", "compose failed\nexit 1"))
+		assert.Equal(t, "::warning file=dev-stack/a%3Ab%2Cc.yml::50%25 used", workflowCommand("warning", "dev-stack/a:b,c.yml", "50% used"))
+	})
+
+	t.Run("files relative to the workspace", func(t *testing.T) {
+		workspace := t.TempDir()
+		t.Setenv("GITHUB_WORKSPACE", workspace)
+		assert.Equal(t, "dev-stack/commands.yaml", annotationPath(filepath.Join(workspace, "dev-stack", "commands.yaml")))
+		outside := filepath.Join(filepath.Dir(workspace), "other.yml")
+		assert.Equal(t, outside, annotationPath(outside))
+	})
+
+	t.Run("groups only in jobs", func(t *testing.T) {
+		oldStdout := os.Stdout
+		r, w, _ := os.Pipe()
+		os.Stdout = w
+
+		(&Output{}).Group("Pulling images")()
+		(&Output{GitHub: true}).Group("Starting %s", "postgres")()
+
+		_ = w.Close()
+		os.Stdout = oldStdout
+		var buf bytes.Buffer
+		_, _ = io.Copy(&buf, r)
+		assert.Equal(t, "::group::Starting postgres\n::endgroup::\n", buf.String())
+	})
+
+	t.Run("job summary", func(t *testing.T) {
+		t.Setenv("GITHUB_STEP_SUMMARY", "")
+		assert.NoError(t, AppendJobSummary("ignored"))
+
+		path := filepath.Join(t.TempDir(), "summary.md")
+		t.Setenv("GITHUB_STEP_SUMMARY", path)
+		assert.NoError(t, AppendJobSummary("### up"))
+		assert.NoError(t, AppendJobSummary("### down"))
+		data, err := os.ReadFile(path)
+		assert.NoError(t, err)
+		assert.Equal(t, "### up\n### down\n", string(data))
+	})
+}
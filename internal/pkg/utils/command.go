@@ -44,6 +44,20 @@ func IsCommandAvailable(name string) bool {
 	return err == nil
 }
 
+// OpenURL opens a URL in the default browser
+func OpenURL(url string) error {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case OSDarwin:
+		cmd = exec.Command("open", url)
+	case OSWindows:
+		cmd = exec.Command("rundll32", "url.dll,FileProtocolHandler", url)
+	default:
+		cmd = exec.Command("xdg-open", url)
+	}
+	return cmd.Start()
+}
+
 // GetProcessPID gets the PID of a running process by name (Linux/macOS only)
 func GetProcessPID(name string) (int, error) {
 	var cmd *exec.Cmd
@@ -0,0 +1,97 @@
+package utils
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// ParseEnvFile reads a dotenv-style file into a map.
+// Blank lines and lines starting with # are ignored, an optional leading
+// "export " is stripped and surrounding quotes are removed from values.
+func ParseEnvFile(filename string) (map[string]string, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = file.Close() }()
+
+	values := make(map[string]string)
+	scanner := bufio.NewScanner(file)
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		line = strings.TrimPrefix(line, "export ")
+		key, value, found := strings.Cut(line, "=")
+		if !found {
+			return nil, fmt.Errorf("%s:%d: expected KEY=VALUE", filename, lineNumber)
+		}
+
+		key = strings.TrimSpace(key)
+		if key == "" {
+			return nil, fmt.Errorf("%s:%d: empty variable name", filename, lineNumber)
+		}
+		values[key] = TrimQuotes(strings.TrimSpace(value))
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", filename, err)
+	}
+
+	return values, nil
+}
+
+// ExpandEnv replaces ${VAR} and ${VAR:-default} references in s using lookup.
+// ${VAR:-default} falls back to default when VAR is unset or empty, and $$
+// produces a literal dollar sign. Unset variables without a default expand
+// to an empty string.
+func ExpandEnv(s string, lookup func(string) (string, bool)) string {
+	var result strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '$' || i+1 >= len(s) {
+			result.WriteByte(s[i])
+			continue
+		}
+
+		switch s[i+1] {
+		case '$':
+			result.WriteByte('$')
+			i++
+		case '{':
+			end := strings.IndexByte(s[i+2:], '}')
+			if end < 0 {
+				result.WriteByte(s[i])
+				continue
+			}
+			expr := s[i+2 : i+2+end]
+			name, fallback, hasDefault := strings.Cut(expr, ":-")
+			value, ok := lookup(name)
+			if hasDefault && (!ok || value == "") {
+				value = fallback
+			}
+			result.WriteString(value)
+			i += end + 2
+		default:
+			result.WriteByte(s[i])
+		}
+	}
+	return result.String()
+}
+
+// EnvLookup returns a lookup function that prefers the process environment
+// and falls back to the provided values (typically parsed from a .env file)
+func EnvLookup(fallback map[string]string) func(string) (string, bool) {
+	return func(name string) (string, bool) {
+		if value, ok := os.LookupEnv(name); ok {
+			return value, true
+		}
+		value, ok := fallback[name]
+		return value, ok
+	}
+}
@@ -0,0 +1,40 @@
+package utils
+
+import "strings"
+
+// Line endings of text files
+const (
+	LF   = "\n"
+	CRLF = "\r\n"
+)
+
+// LineEnding returns the line ending content uses, going by its first line:
+// CRLF for files written by Windows editors, LF otherwise
+func LineEnding(content string) string {
+	if line, _, found := strings.Cut(content, LF); found && strings.HasSuffix(line, "\r") {
+		return CRLF
+	}
+	return LF
+}
+
+// SplitLines splits content into lines without their LF or CRLF endings. A
+// final line ending doesn't start another line, and empty content has no
+// lines.
+func SplitLines(content string) []string {
+	if content == "" {
+		return nil
+	}
+	lines := strings.Split(strings.TrimSuffix(content, LF), LF)
+	for i, line := range lines {
+		lines[i] = strings.TrimSuffix(line, "\r")
+	}
+	return lines
+}
+
+// JoinLines joins lines into content, ending each of them with ending
+func JoinLines(lines []string, ending string) string {
+	if len(lines) == 0 {
+		return ""
+	}
+	return strings.Join(lines, ending) + ending
+}
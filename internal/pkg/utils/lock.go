@@ -0,0 +1,65 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/isaacgarza/dev-stack/internal/pkg/constants"
+)
+
+// FileLock is a lock file guarding updates of a file shared by concurrent
+// dev-stack processes
+type FileLock struct {
+	// Retry is how often a held lock is checked again
+	Retry time.Duration
+	// Timeout is how long to wait for a held lock
+	Timeout time.Duration
+	// Stale is how old a lock left by a crashed process may get before it
+	// is broken
+	Stale time.Duration
+}
+
+// Acquire creates the lock file at path, waiting while another process
+// holds it, and returns the function releasing it
+func (l FileLock) Acquire(path string) (func(), error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create %s: %w", filepath.Dir(path), err)
+	}
+
+	deadline := time.Now().Add(l.Timeout)
+	for {
+		file, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+		if err == nil {
+			_ = file.Close()
+			return func() { _ = os.Remove(path) }, nil
+		}
+		if !errors.Is(err, os.ErrExist) {
+			return nil, fmt.Errorf("failed to lock %s: %w", path, err)
+		}
+		if info, err := os.Stat(path); err == nil && time.Since(info.ModTime()) > l.Stale {
+			_ = os.Remove(path)
+			continue
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("timed out waiting for %s; remove it if no other %s command is running", path, constants.AppName)
+		}
+		time.Sleep(l.Retry)
+	}
+}
+
+// WriteFileAtomic writes content to a file through a temporary file next to
+// it, so readers see either the old content or the new one, creating
+// directories if needed
+func WriteFileAtomic(filename string, content []byte, perm os.FileMode) error {
+	if err := os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", filepath.Dir(filename), err)
+	}
+	tmp := filename + ".tmp"
+	if err := os.WriteFile(tmp, content, perm); err != nil {
+		return fmt.Errorf("failed to write %s: %w", tmp, err)
+	}
+	return os.Rename(tmp, filename)
+}
@@ -94,6 +94,50 @@ func TestEnsureDir(t *testing.T) {
 	}
 }
 
+func TestFileLock(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state", "state.json.lock")
+	lock := FileLock{Retry: time.Millisecond, Timeout: 20 * time.Millisecond, Stale: time.Hour}
+
+	unlock, err := lock.Acquire(path)
+	if err != nil {
+		t.Fatalf("Acquire failed: %v", err)
+	}
+	if _, err := lock.Acquire(path); err == nil || !strings.Contains(err.Error(), "timed out waiting for") {
+		t.Errorf("Acquire should time out while the lock is held, got %v", err)
+	}
+
+	// A lock older than Stale is broken
+	lock.Stale = 0
+	if _, err := lock.Acquire(path); err != nil {
+		t.Errorf("Acquire should break a stale lock: %v", err)
+	}
+
+	unlock()
+	if FileExists(path) {
+		t.Errorf("unlock should remove the lock file")
+	}
+}
+
+func TestWriteFileAtomic(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state", "state.json")
+
+	for _, content := range []string{"first\n", "second\n"} {
+		if err := WriteFileAtomic(path, []byte(content), 0644); err != nil {
+			t.Fatalf("WriteFileAtomic failed: %v", err)
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("Failed to read file: %v", err)
+		}
+		if string(data) != content {
+			t.Errorf("Expected %q, got %q", content, data)
+		}
+	}
+	if FileExists(path + ".tmp") {
+		t.Errorf("WriteFileAtomic should not leave the temporary file behind")
+	}
+}
+
 func TestGenerateRandomString(t *testing.T) {
 	length := 16
 	str, err := GenerateRandomString(length)
@@ -244,6 +288,30 @@ func TestFormatDuration(t *testing.T) {
 	}
 }
 
+func TestParseDuration(t *testing.T) {
+	tests := []struct {
+		value string
+		want  time.Duration
+	}{
+		{"90s", 90 * time.Second},
+		{"12h", 12 * time.Hour},
+		{"2d", 48 * time.Hour},
+		{"1w", 7 * 24 * time.Hour},
+	}
+	for _, test := range tests {
+		got, err := ParseDuration(test.value)
+		if err != nil || got != test.want {
+			t.Errorf("ParseDuration(%q) = %v, %v; want %v", test.value, got, err, test.want)
+		}
+	}
+
+	for _, value := range []string{"", "d", "1.5d", "-2d", "soon"} {
+		if _, err := ParseDuration(value); err == nil {
+			t.Errorf("ParseDuration(%q) should fail", value)
+		}
+	}
+}
+
 func TestIsCommandAvailable(t *testing.T) {
 	// Test with a command that should exist on most systems
 	if !IsCommandAvailable("go") {
@@ -499,3 +567,103 @@ type testError struct {
 func (e *testError) Error() string {
 	return e.msg
 }
+
+func TestExpandEnv(t *testing.T) {
+	lookup := func(name string) (string, bool) {
+		values := map[string]string{"USER": "dev", "EMPTY": ""}
+		value, ok := values[name]
+		return value, ok
+	}
+
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"plain", "plain"},
+		{"${USER}", "dev"},
+		{"${MISSING}", ""},
+		{"${MISSING:-fallback}", "fallback"},
+		{"${EMPTY:-fallback}", "fallback"},
+		{"${USER:-fallback}", "dev"},
+		{"cost: $$5", "cost: $5"},
+		{"$USER", "$USER"},
+		{"${unterminated", "${unterminated"},
+	}
+
+	for _, tt := range tests {
+		if result := ExpandEnv(tt.input, lookup); result != tt.expected {
+			t.Errorf("ExpandEnv(%q) = %q, expected %q", tt.input, result, tt.expected)
+		}
+	}
+}
+
+func TestParseEnvFile(t *testing.T) {
+	tmpFile := filepath.Join(t.TempDir(), ".env")
+	content := "# comment\n\nexport API_KEY=\"secret\"\nPORT=5432\n"
+	if err := os.WriteFile(tmpFile, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write env file: %v", err)
+	}
+
+	values, err := ParseEnvFile(tmpFile)
+	if err != nil {
+		t.Fatalf("ParseEnvFile failed: %v", err)
+	}
+	if values["API_KEY"] != "secret" || values["PORT"] != "5432" || len(values) != 2 {
+		t.Errorf("Unexpected values: %v", values)
+	}
+
+	if err := os.WriteFile(tmpFile, []byte("not-a-pair\n"), 0644); err != nil {
+		t.Fatalf("Failed to write env file: %v", err)
+	}
+	if _, err := ParseEnvFile(tmpFile); err == nil {
+		t.Errorf("ParseEnvFile should fail on malformed lines")
+	}
+}
+
+func TestLineEnding(t *testing.T) {
+	tests := []struct {
+		content  string
+		expected string
+	}{
+		{"", LF},
+		{"single line", LF},
+		{"a\nb\n", LF},
+		{"a\r\nb\r\n", CRLF},
+		{"a\r\nb\n", CRLF},
+	}
+
+	for _, test := range tests {
+		if result := LineEnding(test.content); result != test.expected {
+			t.Errorf("LineEnding(%q) = %q, expected %q", test.content, result, test.expected)
+		}
+	}
+}
+
+func TestSplitLines(t *testing.T) {
+	tests := []struct {
+		content  string
+		expected []string
+	}{
+		{"", nil},
+		{"a", []string{"a"}},
+		{"a\nb\n", []string{"a", "b"}},
+		{"a\r\n\r\nb\r\n", []string{"a", "", "b"}},
+	}
+
+	for _, test := range tests {
+		result := SplitLines(test.content)
+		if strings.Join(result, "|") != strings.Join(test.expected, "|") || len(result) != len(test.expected) {
+			t.Errorf("SplitLines(%q) = %q, expected %q", test.content, result, test.expected)
+		}
+	}
+}
+
+func TestJoinLines(t *testing.T) {
+	content := "key: value\r\n\r\nother: 1\r\n"
+	if result := JoinLines(SplitLines(content), LineEnding(content)); result != content {
+		t.Errorf("JoinLines should round-trip CRLF content, got %q", result)
+	}
+	if result := JoinLines(nil, LF); result != "" {
+		t.Errorf("JoinLines(nil) = %q, expected empty content", result)
+	}
+}
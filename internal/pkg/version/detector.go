@@ -6,6 +6,7 @@ import (
 	"path/filepath"
 	"strings"
 
+	"github.com/isaacgarza/dev-stack/internal/pkg/constants"
 	"gopkg.in/yaml.v3"
 )
 
@@ -38,13 +39,18 @@ func NewVersionDetector() *VersionDetector {
 // DetectProjectVersion detects the required version for a project
 func (d *VersionDetector) DetectProjectVersion(projectPath string) (*VersionConstraint, error) {
 	// First, try to find a version file
-	versionFile, err := d.findVersionFile(projectPath)
+	versionFile, path, err := d.findVersionFile(projectPath)
 	if err != nil {
 		return nil, err
 	}
 
 	if versionFile != nil {
-		return d.parseVersionConstraintFromFile(versionFile)
+		constraint, err := d.parseVersionConstraintFromFile(versionFile)
+		if err != nil {
+			return nil, err
+		}
+		constraint.Source = path
+		return constraint, nil
 	}
 
 	// If no version file found, try to detect from other configuration files
@@ -65,8 +71,9 @@ func (d *VersionDetector) DetectProjectVersion(projectPath string) (*VersionCons
 	}, nil
 }
 
-// findVersionFile searches for version files in the project
-func (d *VersionDetector) findVersionFile(projectPath string) (*VersionFile, error) {
+// findVersionFile searches for version files in the project and returns
+// the first one that parses along with its path
+func (d *VersionDetector) findVersionFile(projectPath string) (*VersionFile, string, error) {
 	for _, searchPath := range d.searchPaths {
 		fullSearchPath := filepath.Join(projectPath, searchPath)
 
@@ -83,12 +90,12 @@ func (d *VersionDetector) findVersionFile(projectPath string) (*VersionFile, err
 				if err != nil {
 					continue // Try next file
 				}
-				return versionFile, nil
+				return versionFile, filePath, nil
 			}
 		}
 	}
 
-	return nil, nil // No version file found
+	return nil, "", nil // No version file found
 }
 
 // parseVersionFile parses a version file
@@ -219,8 +226,11 @@ func (d *VersionDetector) parseVersionConstraintFromFile(versionFile *VersionFil
 
 // detectFromConfigFiles tries to detect version requirements from other config files
 func (d *VersionDetector) detectFromConfigFiles(projectPath string) (*VersionConstraint, error) {
-	// Check for dev-stack configuration files
+	// Check for dev-stack configuration files, starting with the project
+	// configuration init writes
 	configPaths := []string{
+		filepath.Join(constants.DevStackDir, constants.ConfigFileName),
+		filepath.Join(constants.DevStackDir, constants.ConfigFileNameYAML),
 		"dev-stack-config.yaml",
 		"dev-stack-config.yml",
 		".dev-stack.yaml",
@@ -237,6 +247,7 @@ func (d *VersionDetector) detectFromConfigFiles(projectPath string) (*VersionCon
 				continue // Try next config file
 			}
 			if constraint != nil {
+				constraint.Source = fullPath
 				return constraint, nil
 			}
 		}
@@ -0,0 +1,11 @@
+//go:build !windows
+
+package version
+
+import "syscall"
+
+// execVersion replaces the process with the binary, which gets the
+// terminal, the signals and the exit status of the command as they are
+func execVersion(binaryPath string, args, env []string) error {
+	return syscall.Exec(binaryPath, append([]string{binaryPath}, args...), env)
+}
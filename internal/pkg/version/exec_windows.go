@@ -0,0 +1,33 @@
+//go:build windows
+
+package version
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"os/signal"
+)
+
+// execVersion runs the binary and exits with its status, as Windows can't
+// replace a process
+func execVersion(binaryPath string, args, env []string) error {
+	cmd := exec.Command(binaryPath, args...)
+	cmd.Env = env
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+
+	// Ctrl+C reaches the binary through the console; this process waits
+	// for it to stop
+	signal.Ignore(os.Interrupt)
+	if err := cmd.Run(); err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			os.Exit(exitErr.ExitCode())
+		}
+		return err
+	}
+	os.Exit(0)
+	return nil
+}
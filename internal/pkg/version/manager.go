@@ -1,6 +1,9 @@
 package version
 
 import (
+	"os"
+	"path/filepath"
+	"slices"
 	"sort"
 )
 
@@ -53,9 +56,33 @@ func (m *DefaultVersionManager) ListAvailableVersions() ([]Version, error) {
 	return nil, NewVersionError(ErrVersionInstall, "unsupported installer type", nil)
 }
 
-// ListInstalledVersions lists all installed versions
+// ListInstalledVersions lists all installed versions: those of the
+// registry and the binaries put under <installDir>/versions/<version> by
+// hand, such as a release download
 func (m *DefaultVersionManager) ListInstalledVersions() ([]InstalledVersion, error) {
-	return m.registry.ListInstalledVersions()
+	installed, err := m.registry.ListInstalledVersions()
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := os.ReadDir(filepath.Join(m.installDir, "versions"))
+	if err != nil {
+		return installed, nil
+	}
+	for _, entry := range entries {
+		version, err := ParseVersion(entry.Name())
+		if err != nil || !entry.IsDir() {
+			continue
+		}
+		path := GetVersionBinaryPath(m.installDir, *version)
+		if !isExecutable(path) || slices.ContainsFunc(installed, func(v InstalledVersion) bool {
+			return v.Version.Compare(*version) == 0
+		}) {
+			continue
+		}
+		installed = append(installed, InstalledVersion{Version: *version, Path: path, Source: "local"})
+	}
+	return installed, nil
 }
 
 // GetActiveVersion returns the currently active version
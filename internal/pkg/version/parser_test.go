@@ -139,6 +139,11 @@ func TestVersionCompare(t *testing.T) {
 		{"1.0.0-beta", "1.0.0-alpha", 1},
 		{"1.0.0-alpha.1", "1.0.0-alpha.2", -1},
 		{"1.0.0-alpha.2", "1.0.0-alpha.1", 1},
+		{"1.0.0-rc.2", "1.0.0-rc.10", -1},
+		{"1.0.0-alpha", "1.0.0-alpha.1", -1},
+		{"1.0.0-alpha.1", "1.0.0-alpha.beta", -1},
+		{"1.0.0-beta.11", "1.0.0-rc.1", -1},
+		{"v1.0.0", "1.0.0", 0},
 	}
 
 	for _, tt := range tests {
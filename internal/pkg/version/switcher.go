@@ -3,8 +3,11 @@ package version
 import (
 	"fmt"
 	"os"
-	"os/exec"
 	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/isaacgarza/dev-stack/internal/pkg/constants"
 )
 
 // VersionSwitcher handles automatic version delegation based on project requirements
@@ -21,37 +24,17 @@ func NewVersionSwitcher(manager VersionManager) *VersionSwitcher {
 	}
 }
 
-// DelegateToCorrectVersion determines the correct version and delegates execution
+// DelegateToCorrectVersion re-executes the command args name, os.Args
+// included, with the installed version the project requires when the
+// running binary doesn't satisfy it. It doesn't return once it delegated;
+// it returns nil, leaving the command to this binary, when there is
+// nothing to delegate, and an *UnsatisfiedVersionError when no installed
+// version satisfies the project.
 func (s *VersionSwitcher) DelegateToCorrectVersion(args []string) error {
-	// Get current working directory for project context
-	cwd, err := os.Getwd()
-	if err != nil {
-		return fmt.Errorf("failed to get current directory: %w", err)
+	delegate, resolved, err := s.ShouldDelegate(args)
+	if err != nil || !delegate {
+		return err
 	}
-
-	// Find project root by looking for version files or git root
-	projectRoot := s.findProjectRoot(cwd)
-
-	// Detect required version for the project
-	constraint, err := s.manager.DetectProjectVersion(projectRoot)
-	if err != nil {
-		// If we can't detect version requirements, use active version
-		return s.delegateToActiveVersion(args)
-	}
-
-	// If no specific constraint, use active version
-	if constraint.Original == "*" {
-		return s.delegateToActiveVersion(args)
-	}
-
-	// Try to resolve to an installed version
-	resolved, err := s.manager.ResolveVersion(*constraint)
-	if err != nil {
-		// No installed version matches, try to install one
-		return s.handleMissingVersion(*constraint, args)
-	}
-
-	// Delegate to the resolved version
 	return s.delegateToVersion(resolved, args)
 }
 
@@ -102,16 +85,6 @@ func (s *VersionSwitcher) findProjectRoot(startPath string) string {
 	return startPath
 }
 
-// delegateToActiveVersion delegates to the currently active version
-func (s *VersionSwitcher) delegateToActiveVersion(args []string) error {
-	active, err := s.manager.GetActiveVersion()
-	if err != nil {
-		return fmt.Errorf("no active version set and no project-specific version found")
-	}
-
-	return s.delegateToVersion(active, args)
-}
-
 // delegateToVersion delegates execution to a specific version
 func (s *VersionSwitcher) delegateToVersion(installedVersion *InstalledVersion, args []string) error {
 	// Check if the binary exists
@@ -140,60 +113,13 @@ func (s *VersionSwitcher) delegateToVersion(installedVersion *InstalledVersion,
 	return s.executeVersion(installedVersion.Path, execArgs)
 }
 
-// executeVersion executes a specific version binary
+// executeVersion executes a specific version binary in place of this one,
+// telling it not to delegate again
 func (s *VersionSwitcher) executeVersion(binaryPath string, args []string) error {
-	// Use exec.Command for better control and error handling
-	cmd := exec.Command(binaryPath, args...)
-
-	// Pass through environment variables
-	cmd.Env = os.Environ()
-
-	// Connect stdin, stdout, stderr
-	cmd.Stdin = os.Stdin
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
-
-	// Set the working directory to current directory
-	if cwd, err := os.Getwd(); err == nil {
-		cmd.Dir = cwd
-	}
-
-	// Run the command and wait for completion
-	if err := cmd.Run(); err != nil {
-		if exitError, ok := err.(*exec.ExitError); ok {
-			// Exit with the same code as the delegated binary
-			os.Exit(exitError.ExitCode())
-		}
+	env := append(os.Environ(), constants.EnvDelegated+"="+binaryPath)
+	if err := execVersion(binaryPath, args, env); err != nil {
 		return fmt.Errorf("failed to execute version %s: %w", binaryPath, err)
 	}
-
-	// Exit successfully
-	os.Exit(0)
-	return nil
-}
-
-// handleMissingVersion handles the case when no installed version matches requirements
-func (s *VersionSwitcher) handleMissingVersion(constraint VersionConstraint, args []string) error {
-	// Check if this is a version management command
-	if s.isVersionManagementCommand(args) {
-		// Allow version management commands to run with any available version
-		return s.delegateToActiveVersion(args)
-	}
-
-	// For regular commands, suggest installing the required version
-	fmt.Fprintf(os.Stderr, "Error: No installed version satisfies requirement: %s\n", constraint.Original)
-	fmt.Fprintf(os.Stderr, "Run 'dev-stack versions install %s' to install a compatible version.\n", constraint.Original)
-
-	// Try to suggest the best available version
-	available, err := s.manager.ListAvailableVersions()
-	if err == nil {
-		bestMatch := s.findBestAvailableMatch(constraint, available)
-		if bestMatch != nil {
-			fmt.Fprintf(os.Stderr, "Suggested version: %s\n", bestMatch.String())
-		}
-	}
-
-	os.Exit(1)
 	return nil
 }
 
@@ -304,17 +230,19 @@ func (s *VersionSwitcher) CheckVersionCompatibility(projectPath string) (*Compat
 	return result, nil
 }
 
-// getCurrentVersion gets the version of the currently executing binary
+// getCurrentVersion gets the version of the currently executing binary.
+// Development builds, such as those git describe names after the commits
+// since a tag, have none.
 func (s *VersionSwitcher) getCurrentVersion() (Version, error) {
-	// This would typically be set at build time
-	// For now, we'll try to determine it from the active version
-	active, err := s.manager.GetActiveVersion()
+	current := GetShortVersion()
+	if IsDevBuild() || describeSuffix.MatchString(current) {
+		return Version{}, NewVersionError(ErrVersionNotFound, "development build "+current+" has no release version", nil)
+	}
+	version, err := ParseVersion(current)
 	if err != nil {
-		// Fallback to a default version
-		return Version{Major: 0, Minor: 1, Patch: 0, Original: "0.1.0"}, nil
+		return Version{}, err
 	}
-
-	return active.Version, nil
+	return *version, nil
 }
 
 // CompatibilityResult represents the result of a version compatibility check
@@ -326,10 +254,12 @@ type CompatibilityResult struct {
 	RecommendedVersion *Version          `json:"recommended_version,omitempty"`
 }
 
-// ShouldDelegate determines if we should delegate to a different version
+// ShouldDelegate determines if we should delegate to a different version.
+// Commands run with --no-delegate or DEV_STACK_NO_DELEGATE, commands
+// already delegated, version commands and development builds never are.
 func (s *VersionSwitcher) ShouldDelegate(args []string) (bool, *InstalledVersion, error) {
 	// Skip delegation for version management commands
-	if s.isVersionManagementCommand(args) {
+	if delegationDisabled(args) || s.isVersionManagementCommand(args) {
 		return false, nil, nil
 	}
 
@@ -344,15 +274,18 @@ func (s *VersionSwitcher) ShouldDelegate(args []string) (bool, *InstalledVersion
 
 	// Detect required version for the project
 	constraint, err := s.manager.DetectProjectVersion(projectRoot)
-	if err != nil || constraint.Original == "*" {
+	if err != nil {
+		return false, nil, fmt.Errorf("failed to read the dev-stack version %s requires: %w", projectRoot, err)
+	}
+	if constraint.Original == "*" {
 		// No specific requirements, don't delegate
 		return false, nil, nil
 	}
 
-	// Get current version
+	// Development builds run whatever the project pins
 	currentVersion, err := s.getCurrentVersion()
 	if err != nil {
-		return false, nil, err
+		return false, nil, nil
 	}
 
 	// Check if current version satisfies the constraint
@@ -363,7 +296,7 @@ func (s *VersionSwitcher) ShouldDelegate(args []string) (bool, *InstalledVersion
 	// Try to resolve to a different installed version
 	resolved, err := s.manager.ResolveVersion(*constraint)
 	if err != nil {
-		return false, nil, err
+		return false, nil, s.unsatisfiedVersion(*constraint, currentVersion)
 	}
 
 	// Check if the resolved version is different from current
@@ -373,3 +306,84 @@ func (s *VersionSwitcher) ShouldDelegate(args []string) (bool, *InstalledVersion
 
 	return true, resolved, nil
 }
+
+// unsatisfiedVersion describes a constraint no installed version satisfies
+func (s *VersionSwitcher) unsatisfiedVersion(constraint VersionConstraint, current Version) error {
+	err := &UnsatisfiedVersionError{
+		Constraint: constraint.Original,
+		Source:     constraint.Source,
+		Current:    current.String(),
+	}
+	if installed, listErr := s.manager.ListInstalledVersions(); listErr == nil {
+		for _, v := range installed {
+			err.Installed = append(err.Installed, v.Version.String())
+		}
+	}
+	if installDir, dirErr := GetDefaultInstallDir(); dirErr == nil {
+		version := "<version>"
+		if constraint.Operator == "=" || constraint.Operator == "==" {
+			version = constraint.Version.String()
+		}
+		err.InstallPath = filepath.Join(installDir, "versions", version, GetPlatformBinaryName("dev-stack"))
+	}
+	return err
+}
+
+// UnsatisfiedVersionError is returned when a project requires a version of
+// dev-stack that neither the running binary nor an installed one is
+type UnsatisfiedVersionError struct {
+	Constraint string
+	// Source is the file requiring the version
+	Source    string
+	Current   string
+	Installed []string
+	// InstallPath is where a binary of the version is picked up from
+	InstallPath string
+}
+
+func (e *UnsatisfiedVersionError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "this project requires dev-stack %s", e.Constraint)
+	if e.Source != "" {
+		fmt.Fprintf(&b, " (%s)", e.Source)
+	}
+	fmt.Fprintf(&b, ", but this is dev-stack %s", e.Current)
+	if len(e.Installed) > 0 {
+		fmt.Fprintf(&b, " and none of the installed versions (%s) satisfies it", strings.Join(e.Installed, ", "))
+	} else {
+		b.WriteString(" and no other version is installed")
+	}
+	if e.InstallPath != "" {
+		fmt.Fprintf(&b, "\nInstall a matching release binary as %s", e.InstallPath)
+	}
+	fmt.Fprintf(&b, "\nor run with --%s to use this version anyway", constants.FlagNoDelegate)
+	return b.String()
+}
+
+// delegationDisabled reports whether --no-delegate or the environment turn
+// delegation off, or this binary is the version a command was delegated to
+func delegationDisabled(args []string) bool {
+	if os.Getenv(constants.EnvDelegated) != "" {
+		return true
+	}
+	if disabled, err := strconv.ParseBool(os.Getenv(constants.EnvNoDelegate)); err == nil && disabled {
+		return true
+	}
+	for _, arg := range args {
+		if arg == "--" {
+			break
+		}
+		value, ok := strings.CutPrefix(arg, "--"+constants.FlagNoDelegate)
+		if !ok {
+			continue
+		}
+		if value == "" {
+			return true
+		}
+		if value, ok := strings.CutPrefix(value, "="); ok {
+			disabled, err := strconv.ParseBool(value)
+			return err == nil && disabled
+		}
+	}
+	return false
+}
@@ -0,0 +1,133 @@
+package version
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// pinnedProject makes a project requiring the version in its
+// .dev-stack-version the working directory, runs the test as dev-stack
+// 1.0.0 and returns its version switcher and install directory
+func pinnedProject(t *testing.T, required string) (*VersionSwitcher, string) {
+	t.Helper()
+	project := t.TempDir()
+	require.NoError(t, os.Mkdir(filepath.Join(project, ".git"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(project, ".dev-stack-version"), []byte(required+"\n"), 0644))
+	t.Chdir(project)
+
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+	t.Setenv("DEV_STACK_NO_DELEGATE", "")
+	t.Setenv("DEV_STACK_DELEGATED", "")
+
+	original := AppVersion
+	AppVersion = "1.0.0"
+	t.Cleanup(func() { AppVersion = original })
+
+	installDir := filepath.Join(home, ".dev-stack")
+	manager := NewDefaultVersionManager(installDir, filepath.Join(home, ".config", "dev-stack"))
+	return NewVersionSwitcher(manager), installDir
+}
+
+// installVersion puts a binary of version where the manager finds it
+func installVersion(t *testing.T, installDir, version string) string {
+	t.Helper()
+	path := filepath.Join(installDir, "versions", version, GetPlatformBinaryName("dev-stack"))
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	require.NoError(t, os.WriteFile(path, []byte("#!/bin/sh\n"), 0755))
+	return path
+}
+
+func TestVersionSwitcher_ShouldDelegate(t *testing.T) {
+	switcher, installDir := pinnedProject(t, "^1.2.0")
+	installVersion(t, installDir, "1.1.0")
+	newest := installVersion(t, installDir, "1.3.0")
+	installVersion(t, installDir, "1.2.5")
+
+	delegate, resolved, err := switcher.ShouldDelegate([]string{"dev-stack", "up"})
+	require.NoError(t, err)
+	assert.True(t, delegate)
+	assert.Equal(t, newest, resolved.Path)
+	assert.Equal(t, "1.3.0", resolved.Version.String())
+}
+
+func TestVersionSwitcher_ShouldDelegate_Satisfied(t *testing.T) {
+	switcher, _ := pinnedProject(t, ">=1.0.0")
+
+	delegate, _, err := switcher.ShouldDelegate([]string{"dev-stack", "up"})
+	require.NoError(t, err)
+	assert.False(t, delegate)
+}
+
+func TestVersionSwitcher_ShouldDelegate_NotInstalled(t *testing.T) {
+	switcher, installDir := pinnedProject(t, "1.2.0")
+	installVersion(t, installDir, "1.1.0")
+
+	_, _, err := switcher.ShouldDelegate([]string{"dev-stack", "up"})
+	var unsatisfied *UnsatisfiedVersionError
+	require.ErrorAs(t, err, &unsatisfied)
+	assert.Equal(t, "1.2.0", unsatisfied.Constraint)
+	assert.Equal(t, "1.0.0", unsatisfied.Current)
+	assert.Equal(t, []string{"1.1.0"}, unsatisfied.Installed)
+	assert.Equal(t, filepath.Join(installDir, "versions", "1.2.0", GetPlatformBinaryName("dev-stack")), unsatisfied.InstallPath)
+	assert.Contains(t, err.Error(), ".dev-stack-version")
+	assert.Contains(t, err.Error(), "--no-delegate")
+}
+
+func TestVersionSwitcher_ShouldDelegate_Disabled(t *testing.T) {
+	switcher, _ := pinnedProject(t, "2.0.0")
+
+	tests := []struct {
+		name string
+		args []string
+		env  map[string]string
+		want bool
+	}{
+		{name: "flag", args: []string{"dev-stack", "--no-delegate", "up"}},
+		{name: "flag value", args: []string{"dev-stack", "up", "--no-delegate=true"}},
+		{name: "environment", args: []string{"dev-stack", "up"}, env: map[string]string{"DEV_STACK_NO_DELEGATE": "1"}},
+		{name: "already delegated", args: []string{"dev-stack", "up"}, env: map[string]string{"DEV_STACK_DELEGATED": "/usr/bin/dev-stack"}},
+		{name: "version command", args: []string{"dev-stack", "version"}},
+		{name: "flag of the command in the container", args: []string{"dev-stack", "exec", "app", "--", "tool", "--no-delegate"}, want: true},
+		{name: "flag turned off", args: []string{"dev-stack", "up", "--no-delegate=false"}, want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for key, value := range tt.env {
+				t.Setenv(key, value)
+			}
+			_, _, err := switcher.ShouldDelegate(tt.args)
+			// 2.0.0 isn't installed, so the project fails the commands
+			// that delegate
+			assert.Equal(t, tt.want, err != nil, "err = %v", err)
+		})
+	}
+}
+
+func TestVersionSwitcher_ShouldDelegate_DevBuild(t *testing.T) {
+	switcher, _ := pinnedProject(t, "2.0.0")
+	AppVersion = "v1.0.0-3-gabc1234-dirty"
+
+	delegate, _, err := switcher.ShouldDelegate([]string{"dev-stack", "up"})
+	require.NoError(t, err)
+	assert.False(t, delegate)
+}
+
+func TestVersionDetector_DetectProjectVersion_ProjectConfig(t *testing.T) {
+	project := t.TempDir()
+	config := filepath.Join(project, "dev-stack", "dev-stack-config.yml")
+	require.NoError(t, os.MkdirAll(filepath.Dir(config), 0755))
+	require.NoError(t, os.WriteFile(config, []byte("dev_stack_version: \"~1.4.0\"\nproject:\n  name: demo\n"), 0644))
+
+	constraint, err := NewVersionDetector().DetectProjectVersion(project)
+	require.NoError(t, err)
+	assert.Equal(t, "~", constraint.Operator)
+	assert.Equal(t, "1.4.0", constraint.Version.String())
+	assert.Equal(t, config, constraint.Source)
+}
@@ -1,7 +1,10 @@
 package version
 
 import (
+	"cmp"
 	"fmt"
+	"strconv"
+	"strings"
 	"time"
 )
 
@@ -65,14 +68,38 @@ func (v Version) Compare(other Version) int {
 	if v.PreRelease != "" && other.PreRelease == "" {
 		return -1 // pre-release < release
 	}
-	if v.PreRelease != other.PreRelease {
-		if v.PreRelease < other.PreRelease {
-			return -1
+	return comparePreRelease(v.PreRelease, other.PreRelease)
+}
+
+// comparePreRelease compares pre-release versions by the precedence of
+// semantic versioning: dot-separated identifiers from left to right,
+// numeric ones numerically and lower than alphanumeric ones, so that
+// 1.0.0-rc.2 < 1.0.0-rc.10 and a longer set of identifiers wins a tie
+func comparePreRelease(a, b string) int {
+	if a == b {
+		return 0
+	}
+	left, right := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < len(left) && i < len(right); i++ {
+		if result := compareIdentifier(left[i], right[i]); result != 0 {
+			return result
 		}
-		return 1
 	}
+	return cmp.Compare(len(left), len(right))
+}
 
-	return 0
+func compareIdentifier(a, b string) int {
+	aNum, aErr := strconv.ParseUint(a, 10, 64)
+	bNum, bErr := strconv.ParseUint(b, 10, 64)
+	switch {
+	case aErr == nil && bErr == nil:
+		return cmp.Compare(aNum, bNum)
+	case aErr == nil:
+		return -1
+	case bErr == nil:
+		return 1
+	}
+	return strings.Compare(a, b)
 }
 
 // VersionConstraint represents a version constraint like ">=1.0.0", "~1.2.3", etc.
@@ -80,6 +107,8 @@ type VersionConstraint struct {
 	Operator string  `json:"operator"`
 	Version  Version `json:"version"`
 	Original string  `json:"original"`
+	// Source is the file a project requires the version in
+	Source string `json:"source,omitempty"`
 }
 
 // Satisfies checks if a version satisfies the constraint
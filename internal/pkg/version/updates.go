@@ -0,0 +1,267 @@
+package version
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// gitHubAPI is the base URL of the GitHub REST API
+const gitHubAPI = "https://api.github.com"
+
+// defaultRateLimitWait is how long GitHub asks clients to wait after a
+// secondary rate limit that names no reset time
+const defaultRateLimitWait = time.Minute
+
+// ErrNoReleases is returned when the repository has published no release
+var ErrNoReleases = errors.New("no releases have been published")
+
+// describeSuffix matches what git describe appends to the tag of a build
+// made commits after it, such as -3-gabc1234-dirty
+var describeSuffix = regexp.MustCompile(`-\d+-g[0-9a-f]+(-dirty)?$`)
+
+// Release is the part of a GitHub release the update check reads
+type Release struct {
+	TagName     string    `json:"tag_name"`
+	HTMLURL     string    `json:"html_url"`
+	PublishedAt time.Time `json:"published_at"`
+}
+
+// UpdateCheck is the result of checking the latest release
+type UpdateCheck struct {
+	Current         string    `json:"current" yaml:"current"`
+	Latest          string    `json:"latest" yaml:"latest"`
+	URL             string    `json:"url" yaml:"url"`
+	PublishedAt     time.Time `json:"published_at" yaml:"published_at"`
+	UpdateAvailable bool      `json:"update_available" yaml:"update_available"`
+	CheckedAt       time.Time `json:"checked_at" yaml:"checked_at"`
+	// Cached is set when the result comes from an earlier check
+	Cached bool `json:"cached" yaml:"cached"`
+}
+
+// RateLimitError is returned when GitHub rejects the request because the
+// client exhausted its rate limit
+type RateLimitError struct {
+	Reset time.Time
+}
+
+func (e *RateLimitError) Error() string {
+	return fmt.Sprintf("GitHub API rate limit exceeded; retry after %s (set GITHUB_TOKEN for a higher limit)",
+		e.Reset.Local().Format(time.Kitchen))
+}
+
+// updateCache is the last answer of GitHub, kept between runs
+type updateCache struct {
+	Release   Release   `json:"release"`
+	ETag      string    `json:"etag,omitempty"`
+	CheckedAt time.Time `json:"checked_at"`
+	// RetryAfter is set while GitHub rate limits the client
+	RetryAfter time.Time `json:"retry_after,omitempty"`
+}
+
+// UpdateChecker checks the latest GitHub release of a repository. Answers
+// are cached for ttl and revalidated with their ETag, which GitHub doesn't
+// count against the rate limit when nothing changed.
+type UpdateChecker struct {
+	client     *http.Client
+	repository string
+	cacheFile  string
+	ttl        time.Duration
+	token      string
+	baseURL    string
+	now        func() time.Time
+}
+
+// NewUpdateChecker creates an update checker for the owner/name repository
+// caching its answers in cacheFile. A nil client uses http.DefaultClient;
+// an empty cacheFile disables the cache. GITHUB_TOKEN or GH_TOKEN, when
+// set, authenticates the requests.
+func NewUpdateChecker(client *http.Client, repository, cacheFile string, ttl time.Duration) *UpdateChecker {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	token := os.Getenv("GITHUB_TOKEN")
+	if token == "" {
+		token = os.Getenv("GH_TOKEN")
+	}
+	return &UpdateChecker{
+		client:     client,
+		repository: repository,
+		cacheFile:  cacheFile,
+		ttl:        ttl,
+		token:      token,
+		baseURL:    gitHubAPI,
+		now:        time.Now,
+	}
+}
+
+// Check compares current with the latest release. The cached answer is
+// used while it is younger than the TTL, unless force is set, and while
+// GitHub rate limits the client; without one a *RateLimitError is
+// returned.
+func (c *UpdateChecker) Check(ctx context.Context, current string, force bool) (*UpdateCheck, error) {
+	now := c.now()
+	cache := c.loadCache()
+
+	if cache != nil && cache.Release.TagName != "" {
+		if (!force && now.Sub(cache.CheckedAt) < c.ttl) || now.Before(cache.RetryAfter) {
+			return c.result(current, cache, true), nil
+		}
+	}
+	if cache != nil && now.Before(cache.RetryAfter) {
+		return nil, &RateLimitError{Reset: cache.RetryAfter}
+	}
+
+	etag := ""
+	if cache != nil && cache.Release.TagName != "" {
+		etag = cache.ETag
+	}
+	release, newETag, err := c.fetchLatest(ctx, etag)
+
+	var rateLimited *RateLimitError
+	switch {
+	case errors.As(err, &rateLimited):
+		if cache == nil {
+			cache = &updateCache{}
+		}
+		cache.RetryAfter = rateLimited.Reset
+		c.saveCache(cache)
+		if cache.Release.TagName != "" {
+			return c.result(current, cache, true), nil
+		}
+		return nil, err
+	case err != nil:
+		return nil, err
+	case release == nil:
+		// Not modified since the cached answer
+		cache.CheckedAt = now
+		cache.RetryAfter = time.Time{}
+	default:
+		cache = &updateCache{Release: *release, ETag: newETag, CheckedAt: now}
+	}
+	c.saveCache(cache)
+	return c.result(current, cache, false), nil
+}
+
+// fetchLatest gets the latest release. It returns a nil release when etag
+// still matches it.
+func (c *UpdateChecker) fetchLatest(ctx context.Context, etag string) (*Release, string, error) {
+	url := fmt.Sprintf("%s/repos/%s/releases/latest", c.baseURL, c.repository)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, "", err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("X-GitHub-Api-Version", "2022-11-28")
+	req.Header.Set("User-Agent", GetUserAgent())
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+	if etag != "" {
+		req.Header.Set("If-None-Match", etag)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to reach GitHub: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		var release Release
+		if err := json.NewDecoder(resp.Body).Decode(&release); err != nil {
+			return nil, "", fmt.Errorf("failed to decode the latest release of %s: %w", c.repository, err)
+		}
+		return &release, resp.Header.Get("ETag"), nil
+	case http.StatusNotModified:
+		return nil, etag, nil
+	case http.StatusNotFound:
+		return nil, "", fmt.Errorf("%s: %w", c.repository, ErrNoReleases)
+	case http.StatusForbidden, http.StatusTooManyRequests:
+		if reset, ok := c.rateLimitReset(resp); ok {
+			return nil, "", &RateLimitError{Reset: reset}
+		}
+	}
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	return nil, "", fmt.Errorf("GitHub answered %s: %s", resp.Status, strings.TrimSpace(string(body)))
+}
+
+// rateLimitReset reports whether a 403 or 429 response is a rate limit and
+// when it lifts: after Retry-After seconds, at X-RateLimit-Reset when the
+// primary limit is exhausted, or after a minute for other secondary limits
+func (c *UpdateChecker) rateLimitReset(resp *http.Response) (time.Time, bool) {
+	if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil {
+		return c.now().Add(time.Duration(seconds) * time.Second), true
+	}
+	if resp.Header.Get("X-RateLimit-Remaining") == "0" {
+		if reset, err := strconv.ParseInt(resp.Header.Get("X-RateLimit-Reset"), 10, 64); err == nil {
+			return time.Unix(reset, 0), true
+		}
+		return c.now().Add(defaultRateLimitWait), true
+	}
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return c.now().Add(defaultRateLimitWait), true
+	}
+	return time.Time{}, false
+}
+
+// result compares current with the cached release. Development builds and
+// versions that aren't semantic are never reported as outdated.
+func (c *UpdateChecker) result(current string, cache *updateCache, cached bool) *UpdateCheck {
+	latest := strings.TrimPrefix(cache.Release.TagName, "v")
+	check := &UpdateCheck{
+		Current:     current,
+		Latest:      latest,
+		URL:         cache.Release.HTMLURL,
+		PublishedAt: cache.Release.PublishedAt,
+		CheckedAt:   cache.CheckedAt,
+		Cached:      cached,
+	}
+
+	// A build made commits after a tag is at least as new as the tag
+	base := describeSuffix.ReplaceAllString(current, "")
+	if result, err := CompareVersions(base, latest); err == nil && current != devVersion {
+		check.UpdateAvailable = result < 0
+	}
+	return check
+}
+
+func (c *UpdateChecker) loadCache() *updateCache {
+	if c.cacheFile == "" {
+		return nil
+	}
+	data, err := os.ReadFile(c.cacheFile)
+	if err != nil {
+		return nil
+	}
+	var cache updateCache
+	if err := json.Unmarshal(data, &cache); err != nil {
+		return nil // A corrupt cache is refetched
+	}
+	return &cache
+}
+
+func (c *UpdateChecker) saveCache(cache *updateCache) {
+	if c.cacheFile == "" {
+		return
+	}
+	data, err := json.MarshalIndent(cache, "", "  ")
+	if err != nil {
+		return
+	}
+	// The cache only saves requests, so failing to write it is not an error
+	if err := os.MkdirAll(filepath.Dir(c.cacheFile), 0755); err != nil {
+		return
+	}
+	_ = os.WriteFile(c.cacheFile, data, 0644)
+}
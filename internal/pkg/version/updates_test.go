@@ -0,0 +1,172 @@
+package version
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// releasesServer answers the latest release request with handler and counts
+// the requests it receives
+func releasesServer(t *testing.T, handler http.HandlerFunc) (*UpdateChecker, *int) {
+	t.Helper()
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.URL.Path != "/repos/isaacgarza/dev-stack/releases/latest" {
+			t.Errorf("unexpected request for %s", r.URL.Path)
+		}
+		handler(w, r)
+	}))
+	t.Cleanup(server.Close)
+
+	t.Setenv("GITHUB_TOKEN", "")
+	t.Setenv("GH_TOKEN", "")
+	checker := NewUpdateChecker(server.Client(), "isaacgarza/dev-stack", filepath.Join(t.TempDir(), "update-check.json"), time.Hour)
+	checker.baseURL = server.URL
+	return checker, &requests
+}
+
+func latestRelease(tag string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") == `"`+tag+`"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"`+tag+`"`)
+		_, _ = w.Write([]byte(`{"tag_name":"` + tag + `","html_url":"https://github.com/isaacgarza/dev-stack/releases/tag/` + tag + `","published_at":"2026-01-02T03:04:05Z"}`))
+	}
+}
+
+func TestUpdateCheckerCheck(t *testing.T) {
+	tests := []struct {
+		current string
+		want    bool
+	}{
+		{"0.1.0", true},
+		{"v0.1.0", true},
+		{"1.2.0", false},
+		{"1.3.0", false},
+		{"1.2.0-rc.1", true},
+		{"v1.1.0-4-gabc1234-dirty", true},
+		{"v1.2.0-4-gabc1234", false},
+		{"dev", false},
+		{"abc1234", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.current, func(t *testing.T) {
+			checker, _ := releasesServer(t, latestRelease("v1.2.0"))
+			check, err := checker.Check(context.Background(), tt.current, false)
+			if err != nil {
+				t.Fatalf("Check() error = %v", err)
+			}
+			if check.Latest != "1.2.0" {
+				t.Errorf("Latest = %q, want 1.2.0", check.Latest)
+			}
+			if check.UpdateAvailable != tt.want {
+				t.Errorf("UpdateAvailable = %v, want %v", check.UpdateAvailable, tt.want)
+			}
+		})
+	}
+}
+
+func TestUpdateCheckerCheck_Cache(t *testing.T) {
+	checker, requests := releasesServer(t, latestRelease("v1.2.0"))
+	now := time.Date(2026, 1, 10, 0, 0, 0, 0, time.UTC)
+	checker.now = func() time.Time { return now }
+
+	check, err := checker.Check(context.Background(), "1.0.0", false)
+	if err != nil || check.Cached {
+		t.Fatalf("first Check() = %+v, %v; want a fresh answer", check, err)
+	}
+
+	check, err = checker.Check(context.Background(), "1.0.0", false)
+	if err != nil || !check.Cached || *requests != 1 {
+		t.Fatalf("Check() within the TTL = %+v, %v after %d requests; want the cached answer", check, err, *requests)
+	}
+
+	// Past the TTL the answer is revalidated with its ETag
+	now = now.Add(2 * time.Hour)
+	check, err = checker.Check(context.Background(), "1.0.0", false)
+	if err != nil || check.Cached || !check.CheckedAt.Equal(now) || *requests != 2 {
+		t.Fatalf("Check() past the TTL = %+v, %v after %d requests; want a revalidated answer", check, err, *requests)
+	}
+	if check.Latest != "1.2.0" || !check.UpdateAvailable {
+		t.Errorf("revalidated Check() = %+v, want 1.2.0 available", check)
+	}
+
+	if _, err := checker.Check(context.Background(), "1.0.0", true); err != nil || *requests != 3 {
+		t.Errorf("forced Check() made %d requests, err %v; want 3", *requests, err)
+	}
+}
+
+func TestUpdateCheckerCheck_RateLimit(t *testing.T) {
+	reset := time.Date(2026, 1, 10, 1, 0, 0, 0, time.UTC)
+	limited := false
+	checker, requests := releasesServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if limited {
+			w.Header().Set("X-RateLimit-Remaining", "0")
+			w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(reset.Unix(), 10))
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		latestRelease("v1.2.0")(w, r)
+	})
+	now := time.Date(2026, 1, 10, 0, 0, 0, 0, time.UTC)
+	checker.now = func() time.Time { return now }
+
+	// Without a cached answer the rate limit is an error
+	limited = true
+	_, err := checker.Check(context.Background(), "1.0.0", false)
+	var rateLimited *RateLimitError
+	if !errors.As(err, &rateLimited) || !rateLimited.Reset.Equal(reset) {
+		t.Fatalf("Check() error = %v, want a rate limit until %s", err, reset)
+	}
+	// and GitHub isn't asked again before the reset
+	if _, err := checker.Check(context.Background(), "1.0.0", true); !errors.As(err, &rateLimited) || *requests != 1 {
+		t.Fatalf("Check() before the reset = %v after %d requests, want the rate limit without a request", err, *requests)
+	}
+
+	now = reset.Add(time.Second)
+	limited = false
+	if _, err := checker.Check(context.Background(), "1.0.0", false); err != nil {
+		t.Fatalf("Check() after the reset error = %v", err)
+	}
+
+	// With one, the cached answer is served
+	limited = true
+	check, err := checker.Check(context.Background(), "1.0.0", true)
+	if err != nil || !check.Cached || !check.UpdateAvailable {
+		t.Errorf("rate limited Check() = %+v, %v; want the cached answer", check, err)
+	}
+}
+
+func TestUpdateCheckerCheck_NoReleases(t *testing.T) {
+	checker, _ := releasesServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	})
+	if _, err := checker.Check(context.Background(), "1.0.0", false); !errors.Is(err, ErrNoReleases) {
+		t.Errorf("Check() error = %v, want ErrNoReleases", err)
+	}
+}
+
+func TestUpdateCheckerCheck_Token(t *testing.T) {
+	var authorization string
+	checker, _ := releasesServer(t, func(w http.ResponseWriter, r *http.Request) {
+		authorization = r.Header.Get("Authorization")
+		latestRelease("v1.2.0")(w, r)
+	})
+	checker.token = "secret"
+	if _, err := checker.Check(context.Background(), "1.0.0", false); err != nil {
+		t.Fatalf("Check() error = %v", err)
+	}
+	if authorization != "Bearer secret" {
+		t.Errorf("Authorization = %q, want the token", authorization)
+	}
+}
@@ -2,95 +2,145 @@ package version
 
 import (
 	"fmt"
+	"regexp"
 	"runtime"
 	"runtime/debug"
+	"strings"
+	"sync"
 	"time"
 )
 
 var (
-	// These variables are set by the build process using ldflags
-	AppVersion = "dev"
-	GitCommit  = "unknown"
-	BuildDate  = "unknown"
-	BuildBy    = "unknown"
+	// These variables are set by the build process using ldflags. Builds
+	// that don't set them, such as go install, fall back to the build
+	// information the Go toolchain embeds in the binary.
+	AppVersion = devVersion
+	GitCommit  = unknown
+	BuildDate  = unknown
+	BuildBy    = unknown
+)
+
+const (
+	devVersion = "dev"
+	unknown    = "unknown"
+	// develVersion is the version Go reports for the main module of binaries
+	// built from a checkout
+	develVersion = "(devel)"
 )
 
 // BuildInfo contains comprehensive build information
 type BuildInfo struct {
-	Version    string           `json:"version"`
-	GitCommit  string           `json:"git_commit"`
-	BuildDate  string           `json:"build_date"`
-	BuildBy    string           `json:"build_by"`
-	GoVersion  string           `json:"go_version"`
-	Platform   string           `json:"platform"`
-	Arch       string           `json:"arch"`
-	BuildTime  time.Time        `json:"build_time"`
-	ModuleInfo *debug.BuildInfo `json:"module_info,omitempty"`
+	Version    string           `json:"version" yaml:"version"`
+	GitCommit  string           `json:"git_commit" yaml:"git_commit"`
+	Modified   bool             `json:"modified" yaml:"modified"`
+	BuildDate  string           `json:"build_date" yaml:"build_date"`
+	BuildBy    string           `json:"build_by" yaml:"build_by"`
+	GoVersion  string           `json:"go_version" yaml:"go_version"`
+	Platform   string           `json:"platform" yaml:"platform"`
+	Arch       string           `json:"arch" yaml:"arch"`
+	BuildTime  time.Time        `json:"build_time" yaml:"build_time"`
+	ModuleInfo *debug.BuildInfo `json:"module_info,omitempty" yaml:"-"`
 }
 
-// GetBuildInfo returns comprehensive build information
-func GetBuildInfo() *BuildInfo {
-	buildTime, _ := time.Parse(time.RFC3339, BuildDate)
-	if buildTime.IsZero() {
-		buildTime = time.Now()
+// pseudoVersion matches the versions Go stamps builds of commits that
+// aren't tagged with, such as v0.0.0-20240102150405-abcdef123456+dirty
+var pseudoVersion = regexp.MustCompile(`[-.]\d{14}-[0-9a-f]{12}(\+dirty)?$`)
+
+// moduleInfo returns the build information embedded in the binary, or nil
+// when it was built without module support
+var moduleInfo = sync.OnceValue(func() *debug.BuildInfo {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return nil
+	}
+	return info
+})
+
+// buildSetting returns a setting the toolchain stamped the binary with,
+// such as vcs.revision, or "" when it is not set
+func buildSetting(key string) string {
+	info := moduleInfo()
+	if info == nil {
+		return ""
 	}
+	for _, setting := range info.Settings {
+		if setting.Key == key {
+			return setting.Value
+		}
+	}
+	return ""
+}
 
+// GetBuildInfo returns comprehensive build information
+func GetBuildInfo() *BuildInfo {
 	info := &BuildInfo{
-		Version:   AppVersion,
-		GitCommit: GitCommit,
-		BuildDate: BuildDate,
-		BuildBy:   BuildBy,
-		GoVersion: runtime.Version(),
-		Platform:  runtime.GOOS,
-		Arch:      runtime.GOARCH,
-		BuildTime: buildTime,
+		Version:    GetShortVersion(),
+		GitCommit:  GitCommit,
+		BuildDate:  BuildDate,
+		BuildBy:    BuildBy,
+		GoVersion:  runtime.Version(),
+		Platform:   runtime.GOOS,
+		Arch:       runtime.GOARCH,
+		ModuleInfo: moduleInfo(),
 	}
 
-	// Try to get module information
-	if buildInfo, ok := debug.ReadBuildInfo(); ok {
-		info.ModuleInfo = buildInfo
+	// The toolchain stamps builds from a git checkout with the commit
+	if info.GitCommit == unknown || info.GitCommit == "" {
+		if revision := buildSetting("vcs.revision"); revision != "" {
+			info.GitCommit = revision
+		}
 	}
+	if info.BuildDate == unknown || info.BuildDate == "" {
+		if commitTime := buildSetting("vcs.time"); commitTime != "" {
+			info.BuildDate = commitTime
+		}
+	}
+	info.Modified = buildSetting("vcs.modified") == "true"
+	if info.ModuleInfo != nil && info.ModuleInfo.GoVersion != "" {
+		info.GoVersion = info.ModuleInfo.GoVersion
+	}
+	info.BuildTime, _ = time.Parse(time.RFC3339, info.BuildDate)
 
 	return info
 }
 
-// GetAppVersion returns the application version string
+// GetAppVersion returns the application version string: the one set with
+// ldflags, else the version of the module go install built
 func GetAppVersion() string {
-	if AppVersion == "dev" {
-		// Try to get version from module info
-		if buildInfo, ok := debug.ReadBuildInfo(); ok {
-			return buildInfo.Main.Version
-		}
+	if AppVersion != devVersion && AppVersion != "" {
+		return AppVersion
+	}
+	if info := moduleInfo(); info != nil && info.Main.Version != "" {
+		return info.Main.Version
 	}
 	return AppVersion
 }
 
-// GetShortVersion returns a short version string
+// GetShortVersion returns a short version string. Builds of a checkout and
+// of untagged commits, which Go gives a pseudo-version, are dev builds.
 func GetShortVersion() string {
 	version := GetAppVersion()
-	if version == "(devel)" || version == "dev" {
-		return "dev"
+	if version == develVersion || version == "" || pseudoVersion.MatchString(version) {
+		return devVersion
 	}
-	return version
+	return strings.TrimPrefix(version, "v")
 }
 
 // GetFullVersion returns a detailed version string
 func GetFullVersion() string {
 	info := GetBuildInfo()
 
-	version := info.Version
-	if version == "dev" || version == "(devel)" {
-		version = "dev"
-	}
-
-	result := fmt.Sprintf("dev-stack %s", version)
+	result := fmt.Sprintf("dev-stack %s", info.Version)
 
-	if info.GitCommit != "unknown" && info.GitCommit != "" {
-		if len(info.GitCommit) > 7 {
-			result += fmt.Sprintf(" (%s)", info.GitCommit[:7])
-		} else {
-			result += fmt.Sprintf(" (%s)", info.GitCommit)
+	if info.GitCommit != unknown && info.GitCommit != "" {
+		commit := info.GitCommit
+		if len(commit) > 7 {
+			commit = commit[:7]
 		}
+		if info.Modified {
+			commit += "-dirty"
+		}
+		result += fmt.Sprintf(" (%s)", commit)
 	}
 
 	return result
@@ -100,8 +150,13 @@ func GetFullVersion() string {
 func GetFormattedBuildInfo() string {
 	info := GetBuildInfo()
 
+	commit := info.GitCommit
+	if info.Modified {
+		commit += " (modified)"
+	}
+
 	result := fmt.Sprintf("Version:    %s\n", info.Version)
-	result += fmt.Sprintf("Git Commit: %s\n", info.GitCommit)
+	result += fmt.Sprintf("Git Commit: %s\n", commit)
 	result += fmt.Sprintf("Build Date: %s\n", info.BuildDate)
 	result += fmt.Sprintf("Built By:   %s\n", info.BuildBy)
 	result += fmt.Sprintf("Go Version: %s\n", info.GoVersion)
@@ -110,9 +165,10 @@ func GetFormattedBuildInfo() string {
 	return result
 }
 
-// IsDevBuild returns true if this is a development build
+// IsDevBuild returns true if this is a development build, which carries no
+// version to compare with releases
 func IsDevBuild() bool {
-	return AppVersion == "dev" || AppVersion == "(devel)"
+	return GetShortVersion() == devVersion
 }
 
 // GetUserAgent returns a user agent string for HTTP requests
@@ -122,14 +178,16 @@ func GetUserAgent() string {
 
 // IsAppVersionCompatible checks if the current version is compatible with required version
 func IsAppVersionCompatible(requiredVersion string) bool {
-	currentVersion := GetShortVersion()
-
 	// Development builds are always compatible
 	if IsDevBuild() {
 		return true
 	}
 
-	// For now, use simple string comparison
-	// In production, you'd implement proper semantic versioning
-	return currentVersion >= requiredVersion
+	result, err := CompareVersions(GetShortVersion(), requiredVersion)
+	if err != nil {
+		// Versions that aren't semantic, such as a bare commit from git
+		// describe, can't be held to a requirement
+		return true
+	}
+	return result >= 0
 }
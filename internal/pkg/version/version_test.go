@@ -0,0 +1,41 @@
+package version
+
+import "testing"
+
+func TestGetShortVersion(t *testing.T) {
+	tests := []struct {
+		appVersion string
+		want       string
+	}{
+		{"v1.2.3", "1.2.3"},
+		{"1.2.3-rc.1", "1.2.3-rc.1"},
+		{"v0.1.0-3-gabc1234-dirty", "0.1.0-3-gabc1234-dirty"},
+		{"v0.0.0-20240102150405-abcdef123456", "dev"},
+		{"v1.2.4-0.20240102150405-abcdef123456+dirty", "dev"},
+		{"(devel)", "dev"},
+	}
+
+	original := AppVersion
+	t.Cleanup(func() { AppVersion = original })
+	for _, tt := range tests {
+		t.Run(tt.appVersion, func(t *testing.T) {
+			AppVersion = tt.appVersion
+			if got := GetShortVersion(); got != tt.want {
+				t.Errorf("GetShortVersion() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsAppVersionCompatible(t *testing.T) {
+	original := AppVersion
+	t.Cleanup(func() { AppVersion = original })
+
+	AppVersion = "v1.10.0"
+	if !IsAppVersionCompatible("1.9.0") {
+		t.Error("1.10.0 should satisfy 1.9.0")
+	}
+	if IsAppVersionCompatible("1.11.0") {
+		t.Error("1.10.0 should not satisfy 1.11.0")
+	}
+}
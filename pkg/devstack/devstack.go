@@ -0,0 +1,421 @@
+// Package devstack starts the services of a dev-stack project from Go code,
+// so integration tests can run against the services the project already
+// defines instead of setting them up again with testcontainers.
+//
+// A stack is started with the dev-stack binary, in CI mode: it waits until
+// every service passes its readiness probe, publishes each port on the
+// same host port on every run and starts on fresh volumes, leaving the data
+// of the project's named volumes alone. Tests get the host ports and
+// connection variables of each service:
+//
+//	func TestOrders(t *testing.T) {
+//		stack := devstack.StartT(t, devstack.WithServices("postgres"))
+//		db, err := sql.Open("pgx", stack.Env()["DATABASE_URL"])
+//		...
+//	}
+//
+// The stack is the project's own, so starting one replaces a stack the
+// project is running, and stopping it takes the services down.
+package devstack
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/isaacgarza/dev-stack/internal/pkg/constants"
+)
+
+// BinaryEnvVar names the dev-stack binary to run, when it isn't the one on
+// the PATH
+const BinaryEnvVar = "DEV_STACK_BIN"
+
+// stopTimeout bounds how long StartT waits for the stack to stop once the
+// test is done
+const stopTimeout = 2 * time.Minute
+
+// Option configures the stack Start starts
+type Option func(*options)
+
+type options struct {
+	services []string
+	profile  string
+	dir      string
+	binary   string
+	timeout  time.Duration
+	output   io.Writer
+}
+
+// WithServices starts the named services, and the services they depend on,
+// instead of those of the active profile or the enabled ones
+func WithServices(names ...string) Option {
+	return func(o *options) { o.services = append(o.services, names...) }
+}
+
+// WithProfile starts the services of a profile of the project
+func WithProfile(name string) Option {
+	return func(o *options) { o.profile = name }
+}
+
+// WithDir sets the project directory, or a directory inside it. By default
+// the project is found from the working directory, which for tests is the
+// directory of their package.
+func WithDir(dir string) Option {
+	return func(o *options) { o.dir = dir }
+}
+
+// WithBinary sets the dev-stack binary to run
+func WithBinary(path string) Option {
+	return func(o *options) { o.binary = path }
+}
+
+// WithTimeout sets how long each service gets to become ready, 30 seconds
+// by default unless the service sets its own readiness timeout
+func WithTimeout(timeout time.Duration) Option {
+	return func(o *options) { o.timeout = timeout }
+}
+
+// WithOutput copies the output of dev-stack to w
+func WithOutput(w io.Writer) Option {
+	return func(o *options) { o.output = w }
+}
+
+// Port is a port a service publishes on the host
+type Port struct {
+	HostIP    string
+	Host      int
+	Container int
+	Protocol  string
+}
+
+// Service is a service of a started stack
+type Service struct {
+	Name  string
+	Ports []Port
+	// Env holds the connection variables of the service, such as
+	// DATABASE_URL
+	Env map[string]string
+}
+
+// HostPort returns the host port publishing the container port of the
+// service, or 0 when the port isn't published
+func (s Service) HostPort(container int) int {
+	for _, port := range s.Ports {
+		if port.Container == container {
+			return port.Host
+		}
+	}
+	return 0
+}
+
+// Addr returns the host:port address reaching the container port of the
+// service, or an empty string when the port isn't published
+func (s Service) Addr(container int) string {
+	for _, port := range s.Ports {
+		if port.Container != container {
+			continue
+		}
+		host := strings.Trim(port.HostIP, "[]")
+		if host == "" || host == "0.0.0.0" || host == "::" {
+			host = "localhost"
+		}
+		return net.JoinHostPort(host, strconv.Itoa(port.Host))
+	}
+	return ""
+}
+
+// Stack is a started stack
+type Stack struct {
+	dir      string
+	binary   string
+	output   io.Writer
+	services map[string]Service
+
+	stopOnce sync.Once
+	stopErr  error
+}
+
+// Start starts the services of the project, waits until they are ready and
+// returns the stack. When a service fails to start the stack is torn down
+// and the error carries the output of dev-stack, with the logs of the
+// failing services.
+func Start(ctx context.Context, opts ...Option) (*Stack, error) {
+	o := options{output: io.Discard}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	binary, err := resolveBinary(o.binary)
+	if err != nil {
+		return nil, err
+	}
+	start := o.dir
+	if start == "" {
+		if start, err = os.Getwd(); err != nil {
+			return nil, err
+		}
+	}
+	dir, err := FindProject(start)
+	if err != nil {
+		return nil, err
+	}
+
+	args := append([]string{constants.CmdNameCI, constants.CmdNameUp}, o.services...)
+	args = append(args, "--ephemeral")
+	if o.profile != "" {
+		args = append(args, "--profile", o.profile)
+	}
+	if o.timeout > 0 {
+		args = append(args, "--timeout", o.timeout.String())
+	}
+
+	var output bytes.Buffer
+	up := exec.CommandContext(ctx, binary, args...)
+	up.Dir = dir
+	up.Stderr = io.MultiWriter(&output, o.output)
+	stdout, err := up.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := up.Start(); err != nil {
+		return nil, fmt.Errorf("failed to run %s: %w", binary, err)
+	}
+	ready, scanErr := scanEvents(io.TeeReader(stdout, io.MultiWriter(&output, o.output)))
+	if err := up.Wait(); err != nil {
+		return nil, fmt.Errorf("failed to start the stack: %w\n%s", err, output.String())
+	}
+	if scanErr != nil {
+		return nil, scanErr
+	}
+	if ready == nil {
+		return nil, fmt.Errorf("dev-stack reported no ready event\n%s", output.String())
+	}
+
+	// The stack is up from here on, so failures take it down again
+	stack := &Stack{dir: dir, binary: binary, output: o.output, services: make(map[string]Service)}
+	for _, name := range ready.Services {
+		service := Service{Name: name}
+		for _, mapping := range ready.Ports[name] {
+			ports, err := parsePorts(mapping)
+			if err != nil {
+				return nil, errors.Join(fmt.Errorf("service %s: %w", name, err), stack.Stop(ctx))
+			}
+			service.Ports = append(service.Ports, ports...)
+		}
+		if service.Env, err = stack.serviceEnv(ctx, name); err != nil {
+			return nil, errors.Join(err, stack.Stop(ctx))
+		}
+		stack.services[name] = service
+	}
+	return stack, nil
+}
+
+// StartT starts a stack like Start for the test and stops it once the test
+// and its subtests are done. The test fails when the stack doesn't start,
+// with the output of dev-stack logged.
+func StartT(t testing.TB, opts ...Option) *Stack {
+	t.Helper()
+	stack, err := Start(context.Background(), opts...)
+	if err != nil {
+		t.Fatalf("devstack: %v", err)
+	}
+	t.Cleanup(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), stopTimeout)
+		defer cancel()
+		if err := stack.Stop(ctx); err != nil {
+			t.Errorf("devstack: %v", err)
+		}
+	})
+	return stack
+}
+
+// Dir returns the project directory of the stack
+func (s *Stack) Dir() string {
+	return s.dir
+}
+
+// Services returns the names of the started services
+func (s *Stack) Services() []string {
+	return slices.Sorted(maps.Keys(s.services))
+}
+
+// Service returns a started service
+func (s *Stack) Service(name string) (Service, error) {
+	service, ok := s.services[name]
+	if !ok {
+		return Service{}, fmt.Errorf("service %s isn't part of the stack (started: %s)", name, strings.Join(s.Services(), ", "))
+	}
+	return service, nil
+}
+
+// Env returns the connection variables of every started service. When
+// several services declare the same variable the first one by name wins.
+func (s *Stack) Env() map[string]string {
+	env := make(map[string]string)
+	for _, name := range s.Services() {
+		for key, value := range s.services[name].Env {
+			if _, exists := env[key]; !exists {
+				env[key] = value
+			}
+		}
+	}
+	return env
+}
+
+// Stop takes the stack down and removes its volumes. Later calls return
+// the result of the first.
+func (s *Stack) Stop(ctx context.Context) error {
+	s.stopOnce.Do(func() {
+		var output bytes.Buffer
+		down := exec.CommandContext(ctx, s.binary, constants.CmdNameDown, "--"+constants.FlagNonInteractive, "--"+constants.FlagNoColor)
+		down.Dir = s.dir
+		down.Stdout = io.MultiWriter(&output, s.output)
+		down.Stderr = down.Stdout
+		if err := down.Run(); err != nil {
+			s.stopErr = fmt.Errorf("failed to stop the stack: %w\n%s", err, output.String())
+		}
+	})
+	return s.stopErr
+}
+
+// serviceEnv returns the connection variables of a running service
+func (s *Stack) serviceEnv(ctx context.Context, name string) (map[string]string, error) {
+	env := exec.CommandContext(ctx, s.binary, constants.CmdNameEnv, name, "--format", "json")
+	env.Dir = s.dir
+	env.Stderr = s.output
+	data, err := env.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the connection variables of %s: %w", name, err)
+	}
+	vars := make(map[string]string)
+	if err := json.Unmarshal(data, &vars); err != nil {
+		return nil, fmt.Errorf("failed to read the connection variables of %s: %w", name, err)
+	}
+	return vars, nil
+}
+
+// FindProject returns the dev-stack project directory containing dir
+func FindProject(dir string) (string, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+	for current := dir; ; {
+		if _, err := os.Stat(filepath.Join(current, constants.DevStackDir, constants.ConfigFileName)); err == nil {
+			return current, nil
+		}
+		parent := filepath.Dir(current)
+		if parent == current {
+			return "", fmt.Errorf("no dev-stack project found in %s or its parents", dir)
+		}
+		current = parent
+	}
+}
+
+// resolveBinary returns the dev-stack binary to run: binary when given,
+// then the one named by DEV_STACK_BIN, then the one on the PATH
+func resolveBinary(binary string) (string, error) {
+	if binary == "" {
+		binary = os.Getenv(BinaryEnvVar)
+	}
+	if binary == "" {
+		binary = constants.AppName
+	}
+	path, err := exec.LookPath(binary)
+	if err != nil {
+		return "", fmt.Errorf("dev-stack binary not found; install it or set %s: %w", BinaryEnvVar, err)
+	}
+	return path, nil
+}
+
+// readyEvent is the event ci up prints once the stack is ready
+type readyEvent struct {
+	Event    string              `json:"event"`
+	Services []string            `json:"services"`
+	Ports    map[string][]string `json:"ports"`
+}
+
+// scanEvents reads the output of ci up to its end and returns its ready
+// event, if any
+func scanEvents(r io.Reader) (*readyEvent, error) {
+	var ready *readyEvent
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if !bytes.HasPrefix(line, []byte("{")) {
+			continue
+		}
+		var event readyEvent
+		if err := json.Unmarshal(line, &event); err == nil && event.Event == "ready" {
+			ready = &event
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read the output of dev-stack: %w", err)
+	}
+	return ready, nil
+}
+
+// parsePorts parses a port mapping such as 127.0.0.1:5432:5432/tcp, where
+// ranges such as 8000-8001:8000-8001 publish a port each
+func parsePorts(mapping string) ([]Port, error) {
+	spec, protocol, _ := strings.Cut(mapping, "/")
+	if protocol == "" {
+		protocol = "tcp"
+	}
+	fields := strings.Split(spec, ":")
+	if len(fields) < 2 {
+		return nil, fmt.Errorf("invalid port mapping %q", mapping)
+	}
+	hostIP := strings.Join(fields[:len(fields)-2], ":")
+	hostFirst, hostLast, err := parseRange(fields[len(fields)-2])
+	if err != nil {
+		return nil, fmt.Errorf("invalid port mapping %q: %w", mapping, err)
+	}
+	containerFirst, containerLast, err := parseRange(fields[len(fields)-1])
+	if err != nil {
+		return nil, fmt.Errorf("invalid port mapping %q: %w", mapping, err)
+	}
+	if hostLast-hostFirst != containerLast-containerFirst {
+		return nil, fmt.Errorf("invalid port mapping %q: ranges differ in size", mapping)
+	}
+
+	var ports []Port
+	for offset := 0; offset <= hostLast-hostFirst; offset++ {
+		ports = append(ports, Port{HostIP: hostIP, Host: hostFirst + offset, Container: containerFirst + offset, Protocol: protocol})
+	}
+	return ports, nil
+}
+
+// parseRange parses a port, or a range of ports such as 8000-8010
+func parseRange(value string) (int, int, error) {
+	first, last, isRange := strings.Cut(value, "-")
+	start, err := strconv.Atoi(first)
+	if err != nil {
+		return 0, 0, errors.New("ports must be numbers")
+	}
+	if !isRange {
+		return start, start, nil
+	}
+	end, err := strconv.Atoi(last)
+	if err != nil || end < start {
+		return 0, 0, fmt.Errorf("invalid port range %s", value)
+	}
+	return start, end, nil
+}
@@ -0,0 +1,117 @@
+package devstack
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeBinary answers ci up, env and down like dev-stack would for a stack
+// of postgres and a worker publishing no ports
+const fakeBinary = `#!/bin/sh
+echo "$@" >> calls.log
+case "$1" in
+ci)
+	echo '=== Starting Dev Stack ==='
+	echo '{"time":"2026-01-05T10:00:00Z","event":"progress","task":"postgres","state":"done","status":"started"}'
+	echo '{"time":"2026-01-05T10:00:02Z","event":"ready","services":["postgres","worker"],"ports":{"postgres":["127.0.0.1:5432:5432","8000-8001:8000-8001/udp"]}}'
+	;;
+env)
+	if [ "$2" = postgres ]; then
+		echo '{"DATABASE_URL": "postgres://localhost:5432/app"}'
+	else
+		echo '{}'
+	fi
+	;;
+down)
+	echo '[ok] Stopped'
+	;;
+esac
+`
+
+func newProject(t *testing.T) (string, string) {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("the fake dev-stack binary is a shell script")
+	}
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "dev-stack"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "dev-stack", "dev-stack-config.yml"), []byte("project:\n  name: demo\n"), 0644))
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "internal", "orders"), 0755))
+	binary := filepath.Join(t.TempDir(), "dev-stack")
+	require.NoError(t, os.WriteFile(binary, []byte(fakeBinary), 0755))
+	return dir, binary
+}
+
+func TestStart(t *testing.T) {
+	dir, binary := newProject(t)
+
+	var output strings.Builder
+	stack, err := Start(context.Background(),
+		WithDir(filepath.Join(dir, "internal", "orders")), WithBinary(binary), WithServices("postgres", "worker"),
+		WithProfile("api"), WithOutput(&output))
+	require.NoError(t, err)
+	assert.Equal(t, dir, stack.Dir())
+	assert.Equal(t, []string{"postgres", "worker"}, stack.Services())
+	assert.Contains(t, output.String(), `"event":"ready"`)
+
+	postgres, err := stack.Service("postgres")
+	require.NoError(t, err)
+	assert.Equal(t, []Port{
+		{HostIP: "127.0.0.1", Host: 5432, Container: 5432, Protocol: "tcp"},
+		{Host: 8000, Container: 8000, Protocol: "udp"},
+		{Host: 8001, Container: 8001, Protocol: "udp"},
+	}, postgres.Ports)
+	assert.Equal(t, 8001, postgres.HostPort(8001))
+	assert.Equal(t, "127.0.0.1:5432", postgres.Addr(5432))
+	assert.Equal(t, "localhost:8000", postgres.Addr(8000))
+	assert.Zero(t, postgres.HostPort(9999))
+	assert.Empty(t, postgres.Addr(9999))
+	assert.Equal(t, map[string]string{"DATABASE_URL": "postgres://localhost:5432/app"}, stack.Env())
+
+	_, err = stack.Service("redis")
+	assert.EqualError(t, err, "service redis isn't part of the stack (started: postgres, worker)")
+
+	require.NoError(t, stack.Stop(context.Background()))
+	require.NoError(t, stack.Stop(context.Background()))
+	calls, err := os.ReadFile(filepath.Join(dir, "calls.log"))
+	require.NoError(t, err)
+	assert.Equal(t, "ci up postgres worker --ephemeral --profile api\n"+
+		"env postgres --format json\n"+
+		"env worker --format json\n"+
+		"down --non-interactive --no-color\n", string(calls))
+}
+
+func TestStart_Failures(t *testing.T) {
+	dir, binary := newProject(t)
+
+	failing := filepath.Join(t.TempDir(), "dev-stack")
+	require.NoError(t, os.WriteFile(failing, []byte("#!/bin/sh\necho '--- logs of postgres ---' >&2\nexit 1\n"), 0755))
+	_, err := Start(context.Background(), WithDir(dir), WithBinary(failing))
+	assert.ErrorContains(t, err, "failed to start the stack: exit status 1\n--- logs of postgres ---")
+
+	_, err = Start(context.Background(), WithDir(t.TempDir()), WithBinary(binary))
+	assert.ErrorContains(t, err, "no dev-stack project found")
+
+	t.Setenv(BinaryEnvVar, filepath.Join(t.TempDir(), "missing"))
+	_, err = Start(context.Background(), WithDir(dir))
+	assert.ErrorContains(t, err, "dev-stack binary not found")
+}
+
+func TestParsePorts(t *testing.T) {
+	ports, err := parsePorts("[::1]:5432:5432")
+	require.NoError(t, err)
+	assert.Equal(t, []Port{{HostIP: "[::1]", Host: 5432, Container: 5432, Protocol: "tcp"}}, ports)
+	assert.Equal(t, "[::1]:5432", Service{Ports: ports}.Addr(5432))
+
+	for _, mapping := range []string{"5432", "a:5432", "8000-8001:8000", "8001-8000:8001-8000"} {
+		_, err := parsePorts(mapping)
+		assert.Error(t, err, mapping)
+	}
+}